@@ -14,7 +14,11 @@ function App() {
     const saved = localStorage.getItem('dashboard-view-preference')
     return (saved === 'cards' || saved === 'table') ? saved : 'table'
   })
-  const { services, loading, error } = useServices()
+  const { services, loading, error, restartService } = useServices()
+
+  const handleRestart = (name: string) => {
+    restartService(name).catch((err: Error) => window.alert(`Failed to restart ${name}: ${err.message}`))
+  }
 
   // Scroll to top when view changes
   useEffect(() => {
@@ -112,11 +116,11 @@ function App() {
             </div>
           ) : (
             viewMode === 'table' ? (
-              <ServiceTable services={services} onViewLogs={() => setActiveView('console')} />
+              <ServiceTable services={services} onViewLogs={() => setActiveView('console')} onRestart={handleRestart} />
             ) : (
               <div className="grid grid-cols-1 md:grid-cols-2 lg:grid-cols-3 gap-4">
                 {services.map((service: Service) => (
-                  <ServiceCard key={service.name} service={service} />
+                  <ServiceCard key={service.name} service={service} onRestart={handleRestart} />
                 ))}
               </div>
             )
@@ -1,5 +1,6 @@
-import { describe, it, expect } from 'vitest'
+import { describe, it, expect, vi } from 'vitest'
 import { render, screen } from '@testing-library/react'
+import userEvent from '@testing-library/user-event'
 import { ServiceCard } from '@/components/ServiceCard'
 import {
   mockServices,
@@ -37,6 +38,16 @@ describe('ServiceCard', () => {
     expect(link).toHaveAttribute('target', '_blank')
   })
 
+  it('should call onRestart when restart button is clicked', async () => {
+    const user = userEvent.setup()
+    const onRestart = vi.fn()
+
+    render(<ServiceCard service={mockServices[0]} onRestart={onRestart} />)
+    await user.click(screen.getByTitle('Restart'))
+
+    expect(onRestart).toHaveBeenCalledWith('api')
+  })
+
   it('should display port number when available', () => {
     render(<ServiceCard service={mockServices[0]} />)
 
@@ -1,12 +1,13 @@
-import { Activity, Server, CheckCircle, XCircle, Clock, AlertCircle, StopCircle, ExternalLink, Code, Layers } from 'lucide-react'
+import { Activity, Server, CheckCircle, XCircle, Clock, AlertCircle, StopCircle, ExternalLink, Code, Layers, RotateCw } from 'lucide-react'
 import { Badge } from '@/components/ui/badge'
 import type { Service } from '@/types'
 
 interface ServiceCardProps {
   service: Service
+  onRestart?: (serviceName: string) => void
 }
 
-export function ServiceCard({ service }: ServiceCardProps) {
+export function ServiceCard({ service, onRestart }: ServiceCardProps) {
   // Get status and health from local (with fallbacks)
   const status = service.local?.status || service.status || 'not-running'
   const health = service.local?.health || service.health || 'unknown'
@@ -88,6 +89,16 @@ export function ServiceCard({ service }: ServiceCardProps) {
               {status}
             </span>
           </Badge>
+          {onRestart && (
+            <button
+              onClick={() => onRestart(service.name)}
+              disabled={status === 'starting' || status === 'stopping'}
+              className="ml-2 p-2 rounded-lg hover:bg-white/10 transition-colors group/restart disabled:opacity-50"
+              title="Restart"
+            >
+              <RotateCw className="w-4 h-4 text-muted-foreground group-hover/restart:text-primary transition-colors" />
+            </button>
+          )}
         </div>
 
         {/* Local URL Link (if available) */}
@@ -76,6 +76,24 @@ describe('ServiceTable', () => {
     expect(onViewLogs).toHaveBeenCalledWith('api')
   })
 
+  it('should call onRestart when restart button is clicked', async () => {
+    const user = userEvent.setup()
+    const onRestart = vi.fn()
+
+    render(<ServiceTable services={mockServices} onRestart={onRestart} />)
+
+    const restartButtons = screen.getAllByTitle('Restart')
+    await user.click(restartButtons[0])
+
+    expect(onRestart).toHaveBeenCalledWith('api')
+  })
+
+  it('should not show restart buttons without onRestart callback', () => {
+    render(<ServiceTable services={mockServices} />)
+
+    expect(screen.queryByTitle('Restart')).not.toBeInTheDocument()
+  })
+
   it('should display formatted start times', () => {
     render(<ServiceTable services={mockServices} />)
 
@@ -5,9 +5,10 @@ import type { Service } from '@/types'
 interface ServiceTableProps {
   services: Service[]
   onViewLogs?: (serviceName: string) => void
+  onRestart?: (serviceName: string) => void
 }
 
-export function ServiceTable({ services, onViewLogs }: ServiceTableProps) {
+export function ServiceTable({ services, onViewLogs, onRestart }: ServiceTableProps) {
   return (
     <div className="bg-[#1a1a1a] rounded-lg overflow-hidden border border-white/10">
       <Table>
@@ -28,6 +29,7 @@ export function ServiceTable({ services, onViewLogs }: ServiceTableProps) {
               key={service.name} 
               service={service}
               onViewLogs={onViewLogs}
+              onRestart={onRestart}
             />
           ))}
         </TableBody>
@@ -1,4 +1,4 @@
-import { Server, MoreVertical, FileText, ExternalLink } from 'lucide-react'
+import { Server, MoreVertical, FileText, ExternalLink, RotateCw } from 'lucide-react'
 import { TableRow, TableCell } from '@/components/ui/table'
 import { StatusCell } from '@/components/StatusCell'
 import type { Service } from '@/types'
@@ -6,9 +6,10 @@ import type { Service } from '@/types'
 interface ServiceTableRowProps {
   service: Service
   onViewLogs?: (serviceName: string) => void
+  onRestart?: (serviceName: string) => void
 }
 
-export function ServiceTableRow({ service, onViewLogs }: ServiceTableRowProps) {
+export function ServiceTableRow({ service, onViewLogs, onRestart }: ServiceTableRowProps) {
   // Get status and health from local (with fallbacks)
   const status = service.local?.status || service.status || 'not-running'
   const health = service.local?.health || service.health || 'unknown'
@@ -115,6 +116,16 @@ export function ServiceTableRow({ service, onViewLogs }: ServiceTableRowProps) {
           >
             <FileText className="w-4 h-4 text-muted-foreground group-hover:text-primary transition-colors" />
           </button>
+          {onRestart && (
+            <button
+              onClick={() => onRestart(service.name)}
+              disabled={status === 'starting' || status === 'stopping'}
+              className="p-2 rounded-lg hover:bg-white/10 transition-colors group disabled:opacity-50"
+              title="Restart"
+            >
+              <RotateCw className="w-4 h-4 text-muted-foreground group-hover:text-primary transition-colors" />
+            </button>
+          )}
           <button
             className="p-2 rounded-lg hover:bg-white/10 transition-colors group"
             title="More Options"
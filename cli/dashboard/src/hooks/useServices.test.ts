@@ -327,4 +327,35 @@ describe('useServices', () => {
 
     expect(closeMock).toHaveBeenCalled()
   })
+
+  it('should restart a service and fetch services again', async () => {
+    const mockFetch = vi.fn(() => createMockFetchResponse(mockServices))
+    globalThis.fetch = mockFetch as any
+
+    const { result } = renderHook(() => useServices())
+
+    await waitFor(() => {
+      expect(result.current.loading).toBe(false)
+    })
+
+    await result.current.restartService('my api')
+
+    expect(mockFetch).toHaveBeenCalledWith('/api/services/restart?name=my%20api', { method: 'POST' })
+    expect(mockFetch).toHaveBeenLastCalledWith('/api/services')
+  })
+
+  it('should reject when a restart fails', async () => {
+    const mockFetch = vi.fn((url: string) => url.startsWith('/api/services/restart')
+      ? Promise.resolve({ ok: false, statusText: 'Conflict', text: () => Promise.resolve('service api is not running\n') })
+      : createMockFetchResponse(mockServices))
+    globalThis.fetch = mockFetch as any
+
+    const { result } = renderHook(() => useServices())
+
+    await waitFor(() => {
+      expect(result.current.loading).toBe(false)
+    })
+
+    await expect(result.current.restartService('api')).rejects.toThrow('service api is not running')
+  })
 })
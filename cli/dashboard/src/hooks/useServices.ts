@@ -62,6 +62,13 @@ export function useServices() {
     }
   }, [])
 
+  // Asks the session to restart a service; rejects with the reason the session gave
+  const restartService = useCallback(async (name: string) => {
+    const response = await fetch(`${API_BASE}/api/services/restart?name=${encodeURIComponent(name)}`, { method: 'POST' })
+    if (!response.ok) throw new Error((await response.text()).trim() || response.statusText)
+    await fetchServices()
+  }, [fetchServices])
+
   useEffect(() => {
     fetchServices()
 
@@ -114,5 +121,5 @@ export function useServices() {
     }
   }, [fetchServices])
 
-  return { services, loading, error, connected: connected || useMock, refetch: fetchServices }
+  return { services, loading, error, connected: connected || useMock, refetch: fetchServices, restartService }
 }
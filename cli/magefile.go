@@ -184,6 +184,14 @@ func TestIntegration() error {
 	return sh.RunV("go", args...)
 }
 
+// TestCompat clones the gallery templates in src/internal/service/testdata/compat/templates.yaml
+// and checks that service detection finds the services each declares. It needs git and network
+// access. Set AZD_APP_COMPAT_TEMPLATES to the path of another template list to check those instead.
+func TestCompat() error {
+	fmt.Println("Running template compatibility tests...")
+	return sh.RunV("go", "test", "-v", "-tags=compat", "-timeout=30m", "-run=TestTemplateCompat", "./src/internal/service")
+}
+
 // TestAll runs all tests (unit + integration).
 func TestAll() error {
 	fmt.Println("Running all tests...")
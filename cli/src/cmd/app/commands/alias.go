@@ -0,0 +1,127 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jongio/azd-app/cli/src/internal/alias"
+	"github.com/jongio/azd-app/cli/src/internal/detector"
+	"github.com/jongio/azd-app/cli/src/internal/service"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// cobraBuiltins are commands cobra adds itself, which aliases cannot replace.
+var cobraBuiltins = map[string]bool{
+	"help":             true,
+	"completion":       true,
+	"__complete":       true,
+	"__completeNoDesc": true,
+}
+
+// ApplyAliases registers the user's aliases on rootCmd, so they appear in help and shell
+// completion, and returns args with any alias expanded into its command.
+// Aliases come from the user config file and .azdapp.yaml in the current project.
+func ApplyAliases(rootCmd *cobra.Command, args []string) ([]string, error) {
+	projectDir := ""
+	if cwd, err := os.Getwd(); err == nil {
+		projectDir = cwd
+		if azureYamlPath, err := detector.FindAzureYaml(cwd); err == nil && azureYamlPath != "" {
+			projectDir = filepath.Dir(azureYamlPath)
+		}
+	}
+
+	aliases, err := alias.Load(projectDir)
+	if err != nil {
+		return nil, err
+	}
+	if len(aliases) == 0 {
+		return args, nil
+	}
+
+	builtins := make(map[string]bool)
+	for name := range cobraBuiltins {
+		builtins[name] = true
+	}
+	for _, cmd := range rootCmd.Commands() {
+		builtins[cmd.Name()] = true
+		for _, name := range cmd.Aliases {
+			builtins[name] = true
+		}
+	}
+	isBuiltin := func(name string) bool { return builtins[name] }
+
+	names := make([]string, 0, len(aliases))
+	for name := range aliases {
+		if !isBuiltin(name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		rootCmd.AddCommand(newAliasCommand(name, aliases[name]))
+	}
+
+	// Leading global flags that take a separate value, e.g. "-o json up"
+	flagsWithValue := make(map[string]bool)
+	rootCmd.PersistentFlags().VisitAll(func(flag *pflag.Flag) {
+		if flag.Value.Type() != "bool" {
+			flagsWithValue["--"+flag.Name] = true
+			if flag.Shorthand != "" {
+				flagsWithValue["-"+flag.Shorthand] = true
+			}
+		}
+	})
+
+	return alias.Expand(args, aliases, flagsWithValue, isBuiltin)
+}
+
+// newAliasCommand creates a placeholder command for an alias. Aliases are expanded before
+// the command line is parsed, so the command only runs if expansion was skipped.
+func newAliasCommand(name, definition string) *cobra.Command {
+	return &cobra.Command{
+		Use:                name,
+		Short:              fmt.Sprintf("Alias for '%s'", definition),
+		DisableFlagParsing: true,
+		SilenceUsage:       true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return fmt.Errorf("alias %q could not be expanded", name)
+		},
+	}
+}
+
+// completeServiceNames completes comma-separated service names from azure.yaml in the
+// current workspace, for flags like --service.
+func completeServiceNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	azureYaml, err := service.ParseAzureYaml(cwd)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	// Complete the last name in a comma-separated list, skipping names already given
+	prefix := ""
+	if i := strings.LastIndex(toComplete, ","); i >= 0 {
+		prefix = toComplete[:i+1]
+	}
+	given := make(map[string]bool)
+	for _, name := range strings.Split(prefix, ",") {
+		given[name] = true
+	}
+
+	var completions []string
+	for name := range azureYaml.Services {
+		if !given[name] && strings.HasPrefix(prefix+name, toComplete) {
+			completions = append(completions, prefix+name)
+		}
+	}
+	sort.Strings(completions)
+	return completions, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+}
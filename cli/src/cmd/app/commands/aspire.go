@@ -0,0 +1,393 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/jongio/azd-app/cli/src/internal/aspire"
+	"github.com/jongio/azd-app/cli/src/internal/azureyaml"
+	"github.com/jongio/azd-app/cli/src/internal/detector"
+	"github.com/jongio/azd-app/cli/src/internal/output"
+	"github.com/jongio/azd-app/cli/src/internal/service"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	aspireFromSource bool
+	aspireSyncWrite  bool
+)
+
+// NewAspireCommand creates the aspire command.
+func NewAspireCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "aspire",
+		Short: "Inspect the Aspire AppHost of the workspace",
+	}
+
+	model := &cobra.Command{
+		Use:   "model",
+		Short: "Show the resources the Aspire AppHost defines and how they connect",
+		Long: `Shows the resource model of the Aspire AppHost of the workspace: its projects, containers,
+executables, parameters, connection strings, and Azure resources, with their endpoints and the
+resources each refers to through WithReference, WaitFor, or environment variables.
+
+The model comes from the manifest the AppHost publishes when run with --publisher manifest,
+which builds and runs the AppHost project, so the workspace must be trusted. The manifest is
+written to the workspace cache. Without the .NET SDK, when the AppHost fails to build, or with
+--from-source, the model is read from the AppHost source instead, without running it; that
+misses resources the source doesn't state literally, such as ones added in loops or helpers.`,
+		Args: cobra.NoArgs,
+		RunE: runAspireModel,
+	}
+	model.Flags().BoolVar(&aspireFromSource, "from-source", false, "Read the model from the AppHost source without running the AppHost")
+
+	sync := &cobra.Command{
+		Use:   "sync",
+		Short: "Reconcile azure.yaml services with the resources of the Aspire AppHost",
+		Long: `Compares the services in azure.yaml with the resources of the Aspire AppHost, so azd and
+azd app agree on the services without listing them twice by hand. Each resource that runs as a
+service maps to a service of the same name:
+
+  - projects become dotnet services of their project directory
+  - containers built from a Dockerfile become docker services of their build context
+  - prebuilt containers become services of their image
+  - Node.js and Python executables become services of their working directory
+
+References between those resources become uses. Parameters, connection strings, and Azure
+resources are not services. The command reports resources without a service, services whose
+project, language, image, Dockerfile, or uses don't match their resource, and services the
+AppHost doesn't define; the service of the AppHost itself is left alone.
+
+With --write, azure.yaml is updated in place: missing services are added, and the differing
+fields of mismatched services are set from their resource. Services the AppHost doesn't define
+are only reported. Comments and formatting of the rest of the file are preserved.
+
+The resources come from the AppHost manifest, like 'azd app aspire model', so the workspace
+must be trusted unless --from-source is set.`,
+		Args: cobra.NoArgs,
+		RunE: runAspireSync,
+	}
+	sync.Flags().BoolVar(&aspireSyncWrite, "write", false, "Apply the changes to azure.yaml")
+	sync.Flags().BoolVar(&aspireFromSource, "from-source", false, "Read the model from the AppHost source without running the AppHost")
+
+	projects := &cobra.Command{
+		Use:   "projects",
+		Short: "Show which .NET projects the Aspire AppHost runs, and which it misses",
+		Long: `Classifies the .NET projects of the workspace by their role in Aspire, from their project
+files, without building anything:
+
+  apphost           an Aspire AppHost
+  service-defaults  the shared ServiceDefaults project, with IsAspireSharedProject set or named
+                    like Shop.ServiceDefaults
+  managed           a project an AppHost references, so the AppHost runs it
+  orphan            a project that references the ServiceDefaults project or an Aspire client
+                    integration, such as Aspire.Npgsql, but that no AppHost references; its
+                    service discovery, telemetry, and connection strings are never configured
+  standalone        a project that doesn't use Aspire and that no AppHost references
+
+Test projects and other class libraries are left out. Orphans are reported as warnings.`,
+		Args: cobra.NoArgs,
+		RunE: runAspireProjects,
+	}
+
+	cmd.AddCommand(model, sync, projects)
+	return cmd
+}
+
+// runAspireProjects executes the aspire projects command.
+func runAspireProjects(cmd *cobra.Command, args []string) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+	workspace := cwd
+	if azureYamlPath, err := detector.FindAzureYaml(cwd); err == nil && azureYamlPath != "" {
+		workspace = filepath.Dir(azureYamlPath)
+	}
+	projects, err := detector.FindDotnetProjects(workspace)
+	if err != nil {
+		return fmt.Errorf("failed to detect .NET projects: %w", err)
+	}
+	roles := detector.AspireRoles(projects)
+	if roles == nil {
+		roles = []detector.AspireProjectRole{}
+	}
+
+	if output.IsJSON() {
+		return output.PrintJSON(roles)
+	}
+	printAspireProjects(workspace, roles)
+	return nil
+}
+
+// printAspireProjects prints the role of each project, and warns about orphans.
+func printAspireProjects(workspace string, roles []detector.AspireProjectRole) {
+	output.Section("🧩", "Aspire roles of the .NET projects")
+	if len(roles) == 0 {
+		output.Info("No .NET projects found")
+		return
+	}
+
+	table := output.NewTable("PROJECT", "ROLE", "SERVICE DEFAULTS", "INTEGRATIONS")
+	var orphans []detector.AspireProjectRole
+	for _, role := range roles {
+		defaults, integrations := "-", "-"
+		if role.ServiceDefaults != "" {
+			defaults = "yes"
+		}
+		if len(role.Integrations) > 0 {
+			integrations = strings.Join(role.Integrations, ", ")
+		}
+		table.AddRow(relativePath(workspace, role.Path), role.Role, defaults, integrations)
+		if role.Role == detector.AspireRoleOrphan {
+			orphans = append(orphans, role)
+		}
+	}
+	table.Print()
+
+	if len(orphans) == 0 {
+		return
+	}
+	output.Newline()
+	for _, role := range orphans {
+		output.Warning("%s uses Aspire, but no AppHost references it", relativePath(workspace, role.Path))
+	}
+	output.Info("💡 Add a ProjectReference to it and an AddProject call to the AppHost, or remove its Aspire references")
+}
+
+// runAspireModel executes the aspire model command.
+func runAspireModel(cmd *cobra.Command, args []string) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+	workspace := cwd
+	if azureYamlPath, err := detector.FindAzureYaml(cwd); err == nil && azureYamlPath != "" {
+		workspace = filepath.Dir(azureYamlPath)
+	}
+	model, err := loadAspireModel(workspace)
+	if err != nil {
+		return err
+	}
+
+	if output.IsJSON() {
+		return output.PrintJSON(model)
+	}
+	printAspireModel(workspace, model)
+	return nil
+}
+
+// loadAspireModel returns the model of the AppHost in workspace, from its manifest or, with
+// --from-source, its source.
+func loadAspireModel(workspace string) (*aspire.Model, error) {
+	appHost, err := detector.FindAppHost(workspace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search for Aspire AppHost: %w", err)
+	}
+	if appHost == nil {
+		return nil, fmt.Errorf("no Aspire AppHost found in %s", workspace)
+	}
+
+	if !aspireFromSource {
+		// Publishing the manifest builds and runs the AppHost
+		if err := requireWorkspaceTrust(workspace); err != nil {
+			return nil, err
+		}
+		if !output.IsJSON() {
+			output.Step("🧩", "Publishing the manifest of %s...", filepath.Base(appHost.ProjectFile))
+		}
+	}
+	return aspire.Load(commandContext(), workspace, *appHost, aspire.LoadOptions{FromSource: aspireFromSource})
+}
+
+// aspireSyncResult is the JSON output of the aspire sync command.
+type aspireSyncResult struct {
+	AzureYaml string                 `json:"azureYaml"`
+	AppHost   string                 `json:"appHost"`
+	Source    string                 `json:"source"`
+	Changes   []service.AspireChange `json:"changes"`
+	Written   bool                   `json:"written"`
+	Warnings  []string               `json:"warnings,omitempty"`
+}
+
+// runAspireSync executes the aspire sync command.
+func runAspireSync(cmd *cobra.Command, args []string) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+	azureYamlPath, err := detector.FindAzureYaml(cwd)
+	if err != nil {
+		return fmt.Errorf("error searching for azure.yaml: %w", err)
+	}
+	if azureYamlPath == "" {
+		return fmt.Errorf("azure.yaml not found - run 'azd app init' to create it")
+	}
+
+	doc, err := azureyaml.Load(azureYamlPath)
+	if err != nil {
+		return err
+	}
+	azureYamlDir := filepath.Dir(azureYamlPath)
+	model, err := loadAspireModel(azureYamlDir)
+	if err != nil {
+		return err
+	}
+
+	result := aspireSyncResult{
+		AzureYaml: azureYamlPath,
+		AppHost:   model.AppHost,
+		Source:    model.Source,
+		Changes:   service.DiffAspire(doc.Project, azureYamlDir, model),
+		Warnings:  model.Warnings,
+	}
+	if result.Changes == nil {
+		result.Changes = []service.AspireChange{}
+	}
+	if aspireSyncWrite && aspireSyncApplicable(result.Changes) {
+		service.ApplyAspire(doc.Project, result.Changes)
+		if err := doc.Save(); err != nil {
+			return err
+		}
+		result.Written = true
+	}
+
+	if output.IsJSON() {
+		return output.PrintJSON(result)
+	}
+	printAspireSyncResult(cwd, result)
+	return nil
+}
+
+// aspireSyncApplicable reports whether --write changes anything: services the AppHost doesn't
+// define are only reported.
+func aspireSyncApplicable(changes []service.AspireChange) bool {
+	for _, change := range changes {
+		if change.Kind != service.AspireNotInAppHost {
+			return true
+		}
+	}
+	return false
+}
+
+// printAspireSyncResult prints the changes and whether they were applied.
+func printAspireSyncResult(cwd string, result aspireSyncResult) {
+	for _, warning := range result.Warnings {
+		output.Warning("%s", warning)
+	}
+	output.Section("🧩", fmt.Sprintf("Syncing %s with %s", relPath(cwd, result.AzureYaml), relPath(cwd, result.AppHost)))
+	if len(result.Changes) == 0 {
+		output.Success("azure.yaml matches the resources of the AppHost")
+		return
+	}
+
+	applied := 0
+	for _, change := range result.Changes {
+		switch change.Kind {
+		case service.AspireNewService:
+			applied++
+			output.ItemWarning("%s: resource %s is not in azure.yaml %s", change.Service, change.Resource, output.Muted("(add %s)", describeAspireChange(change)))
+		case service.AspireMismatch:
+			applied++
+			output.ItemWarning("%s: %s differ from resource %s %s", change.Service, strings.Join(change.Fields, ", "), change.Resource, output.Muted("(set from %s)", describeAspireChange(change)))
+		case service.AspireNotInAppHost:
+			output.Item("%s: not defined by the AppHost %s", change.Service, output.Muted("(left as is)"))
+		}
+	}
+
+	output.Newline()
+	if result.Written {
+		output.Success("Updated %s with %d change(s)", relPath(cwd, result.AzureYaml), applied)
+		return
+	}
+	if applied > 0 {
+		output.Info("💡 Run 'azd app aspire sync --write' to apply these changes")
+	}
+}
+
+// describeAspireChange returns what the service of a resource runs, for display, e.g.
+// "dotnet ./src/Api".
+func describeAspireChange(change service.AspireChange) string {
+	if change.Image != "" {
+		return change.Image
+	}
+	return change.Language + " " + change.Project
+}
+
+// printAspireModel prints the resources of model.
+func printAspireModel(workspace string, model *aspire.Model) {
+	for _, warning := range model.Warnings {
+		output.Warning("%s", warning)
+	}
+	source := "its manifest"
+	if model.Source == aspire.SourceCode {
+		source = "its source"
+	}
+	output.Section("🧩", fmt.Sprintf("Resources of %s, from %s", relativePath(workspace, model.AppHost), source))
+
+	table := output.NewTable("RESOURCE", "KIND", "DETAIL", "ENDPOINTS", "REFERENCES")
+	for _, r := range model.Resources {
+		references := strings.Join(r.References, ", ")
+		if references == "" {
+			references = "-"
+		}
+		table.AddRow(r.Name, r.Kind, describeAspireResource(workspace, r), describeAspireBindings(r.Bindings), references)
+	}
+	table.Print()
+}
+
+// describeAspireResource returns what a resource runs or holds, for display.
+func describeAspireResource(workspace string, r aspire.Resource) string {
+	switch {
+	case r.Project != "":
+		return relativePath(workspace, r.Project)
+	case r.Image != "":
+		return r.Image
+	case r.Build != nil:
+		return "build " + relativePath(workspace, r.Build.Context)
+	case r.Command != "":
+		return strings.TrimSpace(r.Command + " " + strings.Join(r.Args, " "))
+	case r.Parent != "":
+		return "in " + r.Parent
+	case r.Secret:
+		return "secret"
+	case r.Kind == aspire.KindAzure || r.Kind == aspire.KindOther || r.Kind == aspire.KindContainer:
+		return r.Type
+	}
+	return "-"
+}
+
+// describeAspireBindings returns the endpoints of a resource for display, e.g.
+// http:8080 (external).
+func describeAspireBindings(bindings []aspire.Binding) string {
+	if len(bindings) == 0 {
+		return "-"
+	}
+	parts := make([]string, 0, len(bindings))
+	for _, b := range bindings {
+		part := b.Name
+		if port := b.Port; port != 0 || b.TargetPort != 0 {
+			if port == 0 {
+				port = b.TargetPort
+			}
+			part += ":" + strconv.Itoa(port)
+		}
+		if b.External {
+			part += " (external)"
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// relativePath returns path relative to workspace when it is inside it.
+func relativePath(workspace, path string) string {
+	if rel, err := filepath.Rel(workspace, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return path
+}
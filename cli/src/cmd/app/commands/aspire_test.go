@@ -0,0 +1,33 @@
+package commands
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/jongio/azd-app/cli/src/internal/aspire"
+)
+
+func TestDescribeAspireResource(t *testing.T) {
+	workspace := filepath.Join(string(filepath.Separator), "w")
+	tests := []struct {
+		resource aspire.Resource
+		want     string
+	}{
+		{aspire.Resource{Kind: aspire.KindProject, Project: filepath.Join(workspace, "Api", "Api.csproj")}, filepath.Join("Api", "Api.csproj")},
+		{aspire.Resource{Kind: aspire.KindContainer, Build: &aspire.Build{Context: filepath.Join(workspace, "web")}}, "build web"},
+		{aspire.Resource{Kind: aspire.KindContainer, Type: "AddRedis"}, "AddRedis"},
+		{aspire.Resource{Kind: aspire.KindExecutable, Command: "npm", Args: []string{"run", "dev"}}, "npm run dev"},
+		{aspire.Resource{Kind: aspire.KindValue, Parent: "pg"}, "in pg"},
+		{aspire.Resource{Kind: aspire.KindParameter}, "-"},
+	}
+	for _, tt := range tests {
+		if got := describeAspireResource(workspace, tt.resource); got != tt.want {
+			t.Errorf("describeAspireResource(%+v) = %q, want %q", tt.resource, got, tt.want)
+		}
+	}
+
+	bindings := []aspire.Binding{{Name: "http", TargetPort: 8080, External: true}, {Name: "https"}}
+	if got := describeAspireBindings(bindings); got != "http:8080 (external), https" {
+		t.Errorf("describeAspireBindings() = %q", got)
+	}
+}
@@ -0,0 +1,198 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/jongio/azd-app/cli/src/internal/audit"
+	"github.com/jongio/azd-app/cli/src/internal/output"
+	"github.com/jongio/azd-app/cli/src/internal/registry"
+	"github.com/jongio/azd-app/cli/src/internal/smoke"
+	"github.com/jongio/azd-app/cli/src/internal/statedir"
+	"github.com/jongio/azd-app/cli/src/internal/workerpool"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	auditURL        string
+	auditMinScore   int
+	auditCategories []string
+)
+
+// NewAuditCommand creates the audit command.
+func NewAuditCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "audit",
+		Short: "Audit running services against quality checks",
+		Long:  `Runs quality audits against running services, such as Lighthouse scores for web frontends`,
+	}
+
+	cmd.AddCommand(newAuditWebCommand())
+
+	return cmd
+}
+
+// newAuditWebCommand creates the audit web subcommand.
+func newAuditWebCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "web <service>...",
+		Short: "Run a Lighthouse audit against running frontends",
+		Long: `Runs Lighthouse in headless Chrome against running frontend services and reports
+performance, accessibility, best-practices, and SEO scores.
+
+The service URL is read from the service registry written by 'azd app run'.
+Use --min-score to fail when any category scores below a quality bar.
+
+With several services, every service is audited even when others fail, and a summary is
+printed at the end. The exit code is 1 when every audit failed and 3 when only some did.`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: runAuditWeb,
+	}
+
+	cmd.Flags().StringVar(&auditURL, "url", "", "URL to audit instead of the registered service URL (single service only)")
+	cmd.Flags().IntVar(&auditMinScore, "min-score", 0, "Minimum score (0-100) required for every category")
+	cmd.Flags().StringSliceVar(&auditCategories, "categories", audit.DefaultCategories, "Lighthouse categories to audit")
+
+	return cmd
+}
+
+// auditBatchResult is the JSON output of an audit of several services.
+type auditBatchResult struct {
+	Results []*audit.Result `json:"results"`
+	Summary batchSummary    `json:"summary"`
+}
+
+// runAuditWeb executes the audit web command.
+func runAuditWeb(cmd *cobra.Command, args []string) error {
+	if auditMinScore < 0 || auditMinScore > 100 {
+		return fmt.Errorf("--min-score must be between 0 and 100")
+	}
+	if auditURL != "" && len(args) > 1 {
+		return fmt.Errorf("--url can only be used when auditing a single service")
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+
+	// Lighthouse finds Chrome on its own, so a missing browser here is not fatal
+	chromePath, _ := smoke.FindChrome()
+	outDir, err := statedir.DataDir(cwd, "audit")
+	if err != nil {
+		return err
+	}
+
+	results := make([]*audit.Result, len(args))
+	tasks := make([]workerpool.Task, len(args))
+	for i, serviceName := range args {
+		tasks[i] = workerpool.Task{Name: serviceName, Run: func(ctx context.Context) error {
+			result, err := auditService(ctx, cwd, chromePath, serviceName, outDir)
+			results[i] = result
+			return err
+		}}
+	}
+	// Lighthouse scores depend on the CPU available, so services are audited one at a time
+	err = workerpool.Run(commandContext(), tasks, workerpool.Options{Workers: 1})
+
+	if len(args) == 1 {
+		if output.IsJSON() && results[0] != nil {
+			if err := output.PrintJSON(results[0]); err != nil {
+				return err
+			}
+		}
+		if taskErrs := workerpool.Errors(err); len(taskErrs) > 0 {
+			return taskErrs[0].Err
+		}
+		return err
+	}
+
+	summary := newBatchSummary(args, workerpool.Errors(err))
+	if output.IsJSON() {
+		batch := auditBatchResult{Results: []*audit.Result{}, Summary: summary}
+		for _, result := range results {
+			if result != nil {
+				batch.Results = append(batch.Results, result)
+			}
+		}
+		if err := output.PrintJSON(batch); err != nil {
+			return err
+		}
+	} else {
+		summary.print()
+	}
+	if err != nil {
+		return fmt.Errorf("audit failed for %d of %d services: %w", summary.Failed, summary.Total, err)
+	}
+	return nil
+}
+
+// auditService audits one service and prints its scores. The result is nil if the audit did
+// not run, and the error reports a failed audit or a category below --min-score.
+func auditService(ctx context.Context, cwd, chromePath, serviceName, outDir string) (*audit.Result, error) {
+	url, err := resolveServiceURL(cwd, serviceName, auditURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if !output.IsJSON() {
+		output.Section("🔦", fmt.Sprintf("Auditing %s (%s)", serviceName, url))
+	}
+
+	result, err := audit.Run(ctx, chromePath, serviceName, url, outDir, auditCategories)
+	if err != nil {
+		return nil, fmt.Errorf("audit failed: %w", err)
+	}
+	result.MinScore = auditMinScore
+
+	if !output.IsJSON() {
+		printAuditResult(result)
+	}
+
+	if failing := result.Failing(); len(failing) > 0 {
+		return result, fmt.Errorf("%d of %d categories scored below %d", len(failing), len(result.Scores), auditMinScore)
+	}
+	return result, nil
+}
+
+// resolveServiceURL returns the URL of a running service, preferring an explicit override
+// and falling back to the URL in the service registry.
+func resolveServiceURL(projectDir, serviceName, override string) (string, error) {
+	if override != "" {
+		return override, nil
+	}
+
+	reg := registry.GetRegistry(projectDir)
+	entry, exists := reg.GetService(serviceName)
+	if !exists {
+		return "", fmt.Errorf("service '%s' is not running (start it with 'azd app run' or pass --url)", serviceName)
+	}
+
+	if entry.URL != "" {
+		return entry.URL, nil
+	}
+	if entry.Port > 0 {
+		return fmt.Sprintf("http://localhost:%d", entry.Port), nil
+	}
+	return "", fmt.Errorf("service '%s' has no URL registered (pass --url)", serviceName)
+}
+
+// printAuditResult prints category scores with pass/fail markers.
+func printAuditResult(result *audit.Result) {
+	for _, score := range result.Scores {
+		switch {
+		case result.MinScore > 0 && score.Score < result.MinScore:
+			output.ItemError("%s: %d (below %d)", score.Title, score.Score, result.MinScore)
+		case score.Score >= 90:
+			output.ItemSuccess("%s: %d", score.Title, score.Score)
+		default:
+			output.ItemWarning("%s: %d", score.Title, score.Score)
+		}
+	}
+	if result.Report != "" {
+		output.Item("Report: %s", result.Report)
+	}
+	output.Newline()
+}
@@ -0,0 +1,78 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jongio/azd-app/cli/src/internal/registry"
+)
+
+func TestNewAuditCommand(t *testing.T) {
+	cmd := NewAuditCommand()
+
+	if cmd.Use != "audit" {
+		t.Errorf("Use = %q, want %q", cmd.Use, "audit")
+	}
+
+	web, _, err := cmd.Find([]string{"web"})
+	if err != nil || web.Name() != "web" {
+		t.Fatalf("Expected web subcommand, got %v (err %v)", web, err)
+	}
+
+	for _, flag := range []string{"url", "min-score", "categories"} {
+		if web.Flags().Lookup(flag) == nil {
+			t.Errorf("Expected flag %q to exist", flag)
+		}
+	}
+}
+
+func TestResolveServiceURL(t *testing.T) {
+	projectDir := t.TempDir()
+	reg := registry.GetRegistry(projectDir)
+
+	if err := reg.Register(&registry.ServiceRegistryEntry{Name: "web", URL: "http://localhost:5173"}); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if err := reg.Register(&registry.ServiceRegistryEntry{Name: "admin", Port: 4200}); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		service  string
+		override string
+		want     string
+		wantErr  bool
+	}{
+		{"registered url", "web", "", "http://localhost:5173", false},
+		{"port fallback", "admin", "", "http://localhost:4200", false},
+		{"override", "web", "http://localhost:9999", "http://localhost:9999", false},
+		{"override for unknown service", "missing", "http://localhost:9999", "http://localhost:9999", false},
+		{"unknown service", "missing", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveServiceURL(projectDir, tt.service, tt.override)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolveServiceURL() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("resolveServiceURL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunAuditWebURLWithSeveralServices(t *testing.T) {
+	cmd := newAuditWebCommand()
+	if err := cmd.Flags().Set("url", "http://localhost:9999"); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { auditURL = "" }()
+
+	err := runAuditWeb(cmd, []string{"web", "admin"})
+	if err == nil || !strings.Contains(err.Error(), "single service") {
+		t.Errorf("runAuditWeb() = %v, want an error about --url with several services", err)
+	}
+}
@@ -0,0 +1,97 @@
+package commands
+
+import (
+	"errors"
+
+	"github.com/jongio/azd-app/cli/src/internal/output"
+	"github.com/jongio/azd-app/cli/src/internal/workerpool"
+)
+
+// Exit codes of azd app.
+const (
+	ExitFailure        = 1 // The command failed, or every unit of a batch failed
+	ExitPartialFailure = 3 // Some units of a batch failed and the others succeeded
+)
+
+// ExitCode returns the process exit code for the error returned by a command.
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var batchErr *workerpool.Error
+	if errors.As(err, &batchErr) && batchErr.Partial() {
+		return ExitPartialFailure
+	}
+	return ExitFailure
+}
+
+// batchUnit is the outcome of one unit of work of a batch command, such as a service or project.
+type batchUnit struct {
+	Name  string `json:"name"`
+	Error string `json:"error,omitempty"`
+}
+
+// batchSummary is the outcome of every unit of work of a batch command.
+type batchSummary struct {
+	Total     int         `json:"total"`
+	Succeeded int         `json:"succeeded"`
+	Failed    int         `json:"failed"`
+	Units     []batchUnit `json:"units"`
+
+	failures []*workerpool.TaskError
+}
+
+// newBatchSummary summarizes the units named in names, in order. Units with an error in
+// failures failed, and the others succeeded.
+func newBatchSummary(names []string, failures []*workerpool.TaskError) batchSummary {
+	failed := make(map[string]error, len(failures))
+	for _, failure := range failures {
+		failed[failure.Name] = failure.Err
+	}
+
+	summary := batchSummary{Total: len(names), Units: make([]batchUnit, 0, len(names)), failures: failures}
+	for _, name := range names {
+		unit := batchUnit{Name: name}
+		if err, ok := failed[name]; ok {
+			unit.Error = err.Error()
+			summary.Failed++
+		} else {
+			summary.Succeeded++
+		}
+		summary.Units = append(summary.Units, unit)
+	}
+	return summary
+}
+
+// Err returns the error of the batch: nil when every unit succeeded, and otherwise a
+// *workerpool.Error, whose exit code tells a partial failure from a total one.
+func (s batchSummary) Err() error {
+	if s.Failed == 0 {
+		return nil
+	}
+	return &workerpool.Error{Total: s.Total, Succeeded: s.Succeeded, Failed: s.failures}
+}
+
+// print prints a table of the units and whether each succeeded.
+func (s batchSummary) print() {
+	output.Section("📋", "Summary")
+	table := output.NewTable()
+	table.Indent, table.Gap = "   ", 1
+	for _, unit := range s.Units {
+		if unit.Error != "" {
+			table.AddRow(output.Red+"✗"+output.Reset, unit.Name, unit.Error)
+		} else {
+			table.AddRow(output.Green+"✓"+output.Reset, unit.Name, "ok")
+		}
+	}
+	table.Print()
+	output.Newline()
+	switch {
+	case s.Failed == 0:
+		output.Success("%d of %d succeeded", s.Succeeded, s.Total)
+	case s.Succeeded == 0:
+		output.Error("All %d failed", s.Total)
+	default:
+		output.Warning("%d of %d succeeded, %d failed", s.Succeeded, s.Total, s.Failed)
+	}
+}
@@ -0,0 +1,55 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/jongio/azd-app/cli/src/internal/workerpool"
+)
+
+func TestNewBatchSummary(t *testing.T) {
+	failures := []*workerpool.TaskError{{Name: "web", Err: errors.New("npm install failed")}}
+	summary := newBatchSummary([]string{"api", "web", "worker"}, failures)
+
+	if summary.Total != 3 || summary.Succeeded != 2 || summary.Failed != 1 {
+		t.Errorf("summary counts = %d/%d/%d, want 3/2/1", summary.Total, summary.Succeeded, summary.Failed)
+	}
+	want := []batchUnit{{Name: "api"}, {Name: "web", Error: "npm install failed"}, {Name: "worker"}}
+	if !reflect.DeepEqual(summary.Units, want) {
+		t.Errorf("units = %+v, want %+v", summary.Units, want)
+	}
+	if ExitCode(summary.Err()) != ExitPartialFailure {
+		t.Errorf("ExitCode() = %d, want %d for a partial failure", ExitCode(summary.Err()), ExitPartialFailure)
+	}
+
+	if err := newBatchSummary([]string{"api"}, nil).Err(); err != nil {
+		t.Errorf("Err() = %v, want nil when every unit succeeded", err)
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	partial := &workerpool.Error{Total: 3, Succeeded: 1, Failed: []*workerpool.TaskError{{Name: "a", Err: errors.New("x")}}}
+	total := &workerpool.Error{Total: 1, Failed: []*workerpool.TaskError{{Name: "a", Err: errors.New("x")}}}
+
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "success", err: nil, want: 0},
+		{name: "plain error", err: errors.New("boom"), want: ExitFailure},
+		{name: "total failure", err: total, want: ExitFailure},
+		{name: "partial failure", err: partial, want: ExitPartialFailure},
+		{name: "wrapped partial failure", err: fmt.Errorf("command deps failed: %w", partial), want: ExitPartialFailure},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExitCode(tt.err); got != tt.want {
+				t.Errorf("ExitCode(%v) = %d, want %d", tt.err, got, tt.want)
+			}
+		})
+	}
+}
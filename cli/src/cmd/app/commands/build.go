@@ -0,0 +1,414 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jongio/azd-app/cli/src/internal/audit"
+	"github.com/jongio/azd-app/cli/src/internal/azureyaml"
+	"github.com/jongio/azd-app/cli/src/internal/container"
+	"github.com/jongio/azd-app/cli/src/internal/detector"
+	"github.com/jongio/azd-app/cli/src/internal/imagebuild"
+	"github.com/jongio/azd-app/cli/src/internal/output"
+	"github.com/jongio/azd-app/cli/src/internal/service"
+	"github.com/jongio/azd-app/cli/src/internal/statedir"
+	"github.com/jongio/azd-app/cli/src/internal/workerpool"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	buildPlatforms   []string
+	buildWorkflow    bool
+	buildWrite       bool
+	buildScan        imageScanFlags
+	buildConcurrency int
+	buildTag         string
+	buildManifest    string
+	buildPush        bool
+	buildRegistry    string
+	buildEnvironment string
+	buildTimeout     time.Duration
+)
+
+// NewBuildCommand creates the build command.
+func NewBuildCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "build [service...]",
+		Short: "Build the container images of services, for one or several platforms",
+		Long: `Builds the container image of every service with a Dockerfile, or of the services named,
+with docker buildx. Platforms come from --platform, else docker.platforms of the service,
+else build.platforms of azure.yaml, else docker.platform; without any, images are built for
+the platform of the engine. An image for one platform is loaded into Docker; a multi-arch
+image, such as linux/amd64 and linux/arm64, stays in the build cache of the azd-app builder.
+
+A container service without a Dockerfile is built with Cloud Native Buildpacks by the pack
+CLI, with a builder chosen from its language: Oryx for Node.js, Python, and PHP, and Paketo
+for .NET, Java, and Go, or docker.builder of the service. Buildpacks build for one platform.
+
+Builds of a service share a layer cache, set with build.cache in azure.yaml: local (default,
+in the workspace state directory), gha, registry:<repository>, or none. Up to --concurrency
+images, or build.concurrency of azure.yaml, are built at once, default 4, and the BuildKit
+progress of each build is shown with the name of its service.
+
+Images are tagged <project>-<service>:<tag>, where the tag is the short SHA of the git commit,
+with a -dirty suffix when the working tree has changes, or --tag; outside a git checkout it is
+latest. The digest of each image is printed once all builds finish, and a JSON manifest of the
+images built is written to the workspace state directory, and to --manifest when set.
+
+With --scan, each image is scanned for vulnerabilities with Trivy or Grype once it is built,
+and the build of a service fails when its image has vulnerabilities of --scan-severity or
+above. Scanner reports are written to the workspace state directory.
+
+With --push, images are pushed to the container registry of --registry, else the one that
+'azd provision' created for the azd environment (AZURE_CONTAINER_REGISTRY_ENDPOINT), in the
+repository azd deploys to, <project>/<service>-<environment>. The engine signs in to an Azure
+container registry with 'az acr login'; sign in to other registries with 'docker login'. The
+digest of each image pushed is recorded in the manifest, and with an azd environment, the image
+pinned to its digest is set as SERVICE_<NAME>_IMAGE_NAME, so 'azd deploy' deploys the exact
+image that was built.
+
+With --workflow, a GitHub Actions workflow that builds the same images is shown instead, and
+written to .github/workflows/azd-app-build.yml with --write.`,
+		RunE: runBuild,
+	}
+
+	cmd.Flags().StringSliceVar(&buildPlatforms, "platform", nil, "Platforms to build for, e.g. linux/amd64,linux/arm64 (default: from azure.yaml)")
+	cmd.Flags().BoolVar(&buildWorkflow, "workflow", false, "Show a GitHub Actions workflow that builds the images instead of building them")
+	cmd.Flags().BoolVar(&buildWrite, "write", false, "With --workflow, write the workflow to "+imagebuild.WorkflowFile)
+	cmd.Flags().IntVar(&buildConcurrency, "concurrency", 0, "Images built at once (default: build.concurrency of azure.yaml, else 4)")
+	cmd.Flags().StringVar(&buildTag, "tag", "", "Tag of the images (default: the short SHA of the git commit)")
+	cmd.Flags().StringVar(&buildManifest, "manifest", "", "Also write the JSON manifest of the images built to this file")
+	cmd.Flags().BoolVar(&buildPush, "push", false, "Push the images to the container registry and record their digests")
+	cmd.Flags().StringVar(&buildRegistry, "registry", "", "With --push, the registry to push to, e.g. contoso.azurecr.io (default: the registry of the azd environment)")
+	cmd.Flags().StringVarP(&buildEnvironment, "environment", "e", "", "With --push, the azd environment whose registry images are pushed to and in which they're recorded (default: AZURE_ENV_NAME, or the environment selected with 'azd env select')")
+	cmd.Flags().DurationVar(&buildTimeout, "timeout", 0, "Maximum time for building, scanning, and pushing all images, e.g. 30m; builds still running are stopped (default: no limit)")
+	buildScan.register(cmd)
+
+	return cmd
+}
+
+// buildOutput is the JSON output of the build command.
+type buildOutput struct {
+	Tag         string               `json:"tag"`
+	Registry    string               `json:"registry,omitempty"`    // With --push
+	Environment string               `json:"environment,omitempty"` // With --push, the azd environment images are recorded in
+	Manifest    string               `json:"manifest,omitempty"`    // Path of the manifest in the workspace state
+	Results     []imagebuild.Result  `json:"results"`
+	Scans       []*audit.ImageResult `json:"scans,omitempty"`
+	Summary     batchSummary         `json:"summary"`
+}
+
+// runBuild executes the build command.
+func runBuild(cmd *cobra.Command, args []string) error {
+	if buildWrite && !buildWorkflow {
+		return fmt.Errorf("--write can only be used with --workflow")
+	}
+	if buildScan.enabled && buildWorkflow {
+		return fmt.Errorf("--scan can't be used with --workflow")
+	}
+	if buildConcurrency < 0 {
+		return fmt.Errorf("--concurrency must be at least 1")
+	}
+	if buildPush && buildWorkflow {
+		return fmt.Errorf("--push can't be used with --workflow")
+	}
+	if !buildPush && (buildRegistry != "" || buildEnvironment != "") {
+		return fmt.Errorf("--registry and --environment can only be used with --push")
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+	azureYamlPath, err := detector.FindAzureYaml(cwd)
+	if err != nil {
+		return fmt.Errorf("failed to find azure.yaml: %w", err)
+	}
+	if azureYamlPath == "" {
+		return fmt.Errorf("azure.yaml not found in %s or parent directories", cwd)
+	}
+	workspace := filepath.Dir(azureYamlPath)
+
+	targets, cache, err := imagebuild.LoadTargets(workspace, buildPlatforms)
+	if err != nil {
+		return err
+	}
+	if targets, err = selectBuildTargets(targets, args); err != nil {
+		return err
+	}
+
+	if buildWorkflow {
+		return printBuildWorkflow(workspace, targets, cache)
+	}
+	if len(targets) == 0 {
+		return fmt.Errorf("no service in azure.yaml has a Dockerfile, or a language that buildpacks build")
+	}
+	doc, err := azureyaml.Load(azureYamlPath)
+	if err != nil {
+		return err
+	}
+	concurrency, err := resolveBuildConcurrency(doc.Project.Build, buildConcurrency)
+	if err != nil {
+		return err
+	}
+
+	var registry, environment string
+	if buildPush {
+		if registry, environment, err = resolvePushRegistry(workspace, buildRegistry, buildEnvironment); err != nil {
+			return err
+		}
+	}
+
+	// Dockerfiles and buildpacks run commands of the workspace during the build
+	if err := requireWorkspaceTrust(workspace); err != nil {
+		return err
+	}
+	ctx, cancel := withTimeout(commandContext(), buildTimeout, "build")
+	defer cancel()
+	tag := buildTag
+	if tag == "" {
+		if tag, err = imagebuild.GitTag(ctx, workspace); err != nil {
+			tag = "latest"
+		}
+	}
+	for i := range targets {
+		if buildPush {
+			targets[i].Image = fmt.Sprintf("%s/%s:%s", registry, imagebuild.Repository(doc.Project.Name, targets[i].Service, environment), tag)
+			targets[i].Push = true
+		} else {
+			targets[i].Image += ":" + tag
+		}
+	}
+	engine, err := container.Current()
+	if err != nil {
+		return err
+	}
+	if err := engine.Check(ctx); err != nil {
+		return err
+	}
+	if err := imagebuild.EnsureBuilder(ctx, engine); err != nil {
+		return err
+	}
+	if buildPush && imagebuild.IsAzureRegistry(registry) {
+		if err := imagebuild.Login(ctx, registry); err != nil {
+			return err
+		}
+	}
+	scanner, err := buildScan.newImageScanner(workspace, engine)
+	if err != nil {
+		return err
+	}
+	logDir, err := statedir.DataDir(workspace, "build")
+	if err != nil {
+		return fmt.Errorf("failed to create log directory: %w", err)
+	}
+
+	results := make([]imagebuild.Result, len(targets))
+	scans := make([]*audit.ImageResult, len(targets))
+	tasks := make([]workerpool.Task, len(targets))
+	names := make([]string, len(targets))
+	progress := buildProgress()
+	var recordMu sync.Mutex // 'azd env set' rewrites the .env file of the environment
+	for i, target := range targets {
+		names[i] = target.Service
+		tasks[i] = workerpool.Task{Name: target.Service, Run: func(ctx context.Context) error {
+			if !output.IsJSON() {
+				output.Step("🔨", "Building %s for %s...", target.Service, describePlatforms(target.Platforms))
+			}
+			results[i] = imagebuild.Build(ctx, engine, target, cache, filepath.Join(logDir, target.Service+".log"), progress(target.Service))
+			if results[i].Error != "" {
+				err := contextError(ctx, errors.New(results[i].Error))
+				results[i].Error = err.Error()
+				return err
+			}
+			if scanner != nil {
+				scan, err := scanner.scan(ctx, results[i])
+				scans[i] = scan
+				if err != nil {
+					return err
+				}
+			}
+			if !results[i].Pushed || environment == "" {
+				return nil
+			}
+			recordMu.Lock()
+			defer recordMu.Unlock()
+			return imagebuild.RecordImage(ctx, workspace, environment, target.Service, imagebuild.PinnedReference(results[i].Image, results[i].Digest))
+		}}
+	}
+	err = workerpool.Run(ctx, tasks, workerpool.Options{Workers: concurrency})
+
+	var extra []string
+	if buildManifest != "" {
+		extra = append(extra, buildManifest)
+	}
+	manifest, manifestErr := imagebuild.WriteManifest(workspace, imagebuild.NewManifest(tag, results), extra...)
+	if manifestErr != nil {
+		return manifestErr
+	}
+
+	summary := newBatchSummary(names, workerpool.Errors(err))
+	if output.IsJSON() {
+		out := buildOutput{Tag: tag, Registry: registry, Environment: environment, Manifest: manifest, Results: results, Scans: completedScans(scans), Summary: summary}
+		if err := output.PrintJSON(out); err != nil {
+			return err
+		}
+	} else {
+		printBuildResults(results)
+		if scanner != nil {
+			printImageScans(scans)
+		}
+		output.Item("Manifest: %s", manifest)
+		if environment != "" && summary.Succeeded > 0 {
+			output.Item("Images pinned to their digests in azd environment %s; 'azd deploy' deploys them", environment)
+		}
+		summary.print()
+	}
+	if err != nil {
+		return fmt.Errorf("build failed for %d of %d services: %w", summary.Failed, summary.Total, err)
+	}
+	return nil
+}
+
+// resolvePushRegistry returns the registry images are pushed to, and the azd environment they
+// are recorded in: the registry of flag when set, else the one of the azd environment. An
+// explicit registry doesn't need an azd environment; without one, images aren't recorded.
+func resolvePushRegistry(workspace, registryFlag, environmentFlag string) (string, string, error) {
+	environment := service.AzdEnvironmentName(workspace, environmentFlag)
+	if registryFlag != "" {
+		registry, err := imagebuild.NormalizeRegistry(registryFlag)
+		if err != nil {
+			return "", "", fmt.Errorf("invalid --registry: %w", err)
+		}
+		if environment != "" {
+			if _, err := service.LoadAzdEnvironment(workspace, environment); err != nil {
+				if environmentFlag != "" {
+					return "", "", err
+				}
+				environment = "" // A default that doesn't exist yet, e.g. before 'azd env new'
+			}
+		}
+		return registry, environment, nil
+	}
+	if environment == "" {
+		return "", "", fmt.Errorf("no azd environment to push to; create one with 'azd env new', or pass --environment or --registry")
+	}
+	values, err := service.LoadAzdEnvironment(workspace, environment)
+	if err != nil {
+		return "", "", err
+	}
+	registry, err := imagebuild.RegistryEndpoint(values)
+	if err != nil {
+		return "", "", fmt.Errorf("azd environment %s: %w", environment, err)
+	}
+	return registry, environment, nil
+}
+
+// resolveBuildConcurrency returns how many images are built at once: flag when set, else the
+// concurrency of the build settings, else imagebuild.DefaultConcurrency.
+func resolveBuildConcurrency(settings *azureyaml.BuildSettings, flag int) (int, error) {
+	if flag > 0 {
+		return flag, nil
+	}
+	if settings != nil && settings.Concurrency != 0 {
+		if settings.Concurrency < 0 {
+			return 0, fmt.Errorf("build.concurrency in azure.yaml must be at least 1")
+		}
+		return settings.Concurrency, nil
+	}
+	return imagebuild.DefaultConcurrency, nil
+}
+
+// buildProgress returns the progress function of the build of each service, which shows the
+// BuildKit output with the name of the service, or nil ones in JSON output, where stdout
+// carries the result.
+func buildProgress() func(service string) func(line string) {
+	if output.IsJSON() {
+		return func(string) func(string) { return nil }
+	}
+	logger := service.NewServiceLogger(false)
+	return func(name string) func(line string) {
+		return func(line string) {
+			if line != "" {
+				logger.LogService(name, line)
+			}
+		}
+	}
+}
+
+// selectBuildTargets returns the targets of the services named, in the order named, or all
+// targets when none are.
+func selectBuildTargets(targets []imagebuild.Target, names []string) ([]imagebuild.Target, error) {
+	if len(names) == 0 {
+		return targets, nil
+	}
+	selected := make([]imagebuild.Target, 0, len(names))
+	for _, name := range names {
+		i := slices.IndexFunc(targets, func(t imagebuild.Target) bool { return t.Service == name })
+		if i < 0 {
+			return nil, fmt.Errorf("service %s is not in azure.yaml, or has no Dockerfile and no language that buildpacks build", name)
+		}
+		selected = append(selected, targets[i])
+	}
+	return selected, nil
+}
+
+// printBuildWorkflow shows the GitHub Actions workflow of targets, and writes it with --write.
+func printBuildWorkflow(workspace string, targets []imagebuild.Target, cache imagebuild.Cache) error {
+	if len(targets) == 0 {
+		return fmt.Errorf("no service in azure.yaml has a Dockerfile, or a language that buildpacks build")
+	}
+	workflow := imagebuild.Workflow(workspace, targets, cache)
+	if !buildWrite {
+		_, _ = fmt.Fprint(output.Writer(), workflow)
+		return nil
+	}
+	if err := writeWorkspaceFile(workspace, imagebuild.WorkflowFile, []byte(workflow), 0644); err != nil {
+		return err
+	}
+	if output.IsJSON() {
+		return output.PrintJSON(map[string]string{"workflow": imagebuild.WorkflowFile})
+	}
+	output.Success("Wrote %s", imagebuild.WorkflowFile)
+	return nil
+}
+
+// printBuildResults prints the images built and their digests.
+func printBuildResults(results []imagebuild.Result) {
+	output.Newline()
+	table := output.NewTable("SERVICE", "IMAGE", "PLATFORMS", "DIGEST")
+	for _, result := range results {
+		if result.Service == "" {
+			continue // Not built, because the build was stopped
+		}
+		digest := result.Digest
+		switch {
+		case result.Error != "":
+			digest = "failed"
+		case digest == "":
+			digest = "-"
+		case result.Pushed:
+			digest += " " + output.Muted("(pushed)")
+		case !result.Loaded:
+			digest += " " + output.Muted("(in build cache)")
+		}
+		table.AddRow(result.Service, result.Image, describePlatforms(result.Platforms), digest)
+	}
+	table.Print()
+}
+
+// describePlatforms returns the platforms of a build for display.
+func describePlatforms(platforms []string) string {
+	if len(platforms) == 0 {
+		return "the engine platform"
+	}
+	return strings.Join(platforms, ", ")
+}
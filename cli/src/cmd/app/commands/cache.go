@@ -0,0 +1,253 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/jongio/azd-app/cli/src/internal/cache"
+	"github.com/jongio/azd-app/cli/src/internal/gc"
+	"github.com/jongio/azd-app/cli/src/internal/output"
+	"github.com/jongio/azd-app/cli/src/internal/statedir"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	cacheCleanAll bool
+	cacheGCDryRun bool
+)
+
+// legacyStateDirs are the folders under .azure where caches and logs were kept before
+// they moved to the per-workspace state directory. They are removed by cache clean.
+var legacyStateDirs = []string{"cache", "logs"}
+
+// NewCacheCommand creates the cache command.
+func NewCacheCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Show and clean cached state and logs",
+		Long: `Caches and service logs are kept outside the project, in a directory per workspace
+under the user cache directory (override with AZD_APP_CACHE_DIR). Each running session
+writes its logs to its own folder, so several workspaces and several concurrent sessions
+never share files. Records that outlive a session, such as the service registry, port
+assignments, and snapshots, are kept there too.`,
+	}
+
+	cmd.AddCommand(
+		newCacheListCommand(),
+		newCacheCleanCommand(),
+		newCacheGCCommand(),
+	)
+
+	return cmd
+}
+
+// newCacheListCommand creates the cache list subcommand.
+func newCacheListCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List the state kept for each workspace and its size",
+		Args:  cobra.NoArgs,
+		RunE:  runCacheList,
+	}
+}
+
+// newCacheCleanCommand creates the cache clean subcommand.
+func newCacheCleanCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "clean",
+		Short: "Remove the cached state of this workspace",
+		Long: `Removes the cache and the logs of finished sessions for the current workspace, or for
+every workspace with --all. Logs of sessions that are still running are kept, as are the
+records kept across sessions (service registry, port assignments, snapshots, histories).`,
+		Args: cobra.NoArgs,
+		RunE: runCacheClean,
+	}
+
+	cmd.Flags().BoolVar(&cacheCleanAll, "all", false, "Clean the state of every workspace and the HTTP cache")
+
+	return cmd
+}
+
+// newCacheGCCommand creates the cache gc subcommand.
+func newCacheGCCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "gc",
+		Short: "Remove old logs, caches, and snapshots according to your policies",
+		Long: `Removes logs of finished sessions, workspace caches, and database snapshots of the
+current project that are older or larger than the limits in the gc section of the user
+config file (see 'azd app cache'). The caches and logs of workspaces that no longer exist are
+always removed; their records, such as snapshots and secrets, are kept for 'azd app cache clean'. This also runs automatically, at most once per interval, when services start.`,
+		Args: cobra.NoArgs,
+		RunE: runCacheGC,
+	}
+
+	cmd.Flags().BoolVar(&cacheGCDryRun, "dry-run", false, "Show what would be removed without removing it")
+
+	return cmd
+}
+
+// runCacheList executes the cache list command.
+func runCacheList(cmd *cobra.Command, args []string) error {
+	root, err := statedir.Root()
+	if err != nil {
+		return err
+	}
+	workspaces, err := statedir.List()
+	if err != nil {
+		return err
+	}
+
+	var total int64
+	for _, ws := range workspaces {
+		total += ws.Size
+	}
+
+	if output.IsJSON() {
+		if workspaces == nil {
+			workspaces = []statedir.Workspace{}
+		}
+		return output.PrintJSON(map[string]interface{}{
+			"root":       root,
+			"totalSize":  total,
+			"workspaces": workspaces,
+		})
+	}
+
+	if len(workspaces) == 0 {
+		output.Info("No cached state in %s", root)
+		return nil
+	}
+
+	current := ""
+	if cwd, err := os.Getwd(); err == nil {
+		current, _ = statedir.WorkspaceDir(cache.WorkspaceRoot(cwd))
+	}
+
+	output.Section("🗄️", fmt.Sprintf("Cached state in %s", root))
+	for _, ws := range workspaces {
+		path := ws.Path
+		if path == "" {
+			path = filepath.Base(ws.Dir)
+		}
+		if ws.Dir == current {
+			path += " (current)"
+		}
+		details := fmt.Sprintf("%s  last used %s", statedir.FormatSize(ws.Size), ws.LastUsed.Local().Format("2006-01-02 15:04"))
+		if ws.Sessions > 0 {
+			details += fmt.Sprintf("  %d running session(s)", ws.Sessions)
+		}
+		output.Label(path, details)
+	}
+	output.Newline()
+	output.Item("Total: %s in %d workspace(s)", statedir.FormatSize(total), len(workspaces))
+	return nil
+}
+
+// runCacheClean executes the cache clean command.
+func runCacheClean(cmd *cobra.Command, args []string) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+	workspace := cache.WorkspaceRoot(cwd)
+
+	var freed int64
+	if cacheCleanAll {
+		freed, err = statedir.CleanAll()
+	} else {
+		freed, err = statedir.Clean(workspace)
+	}
+	freed += cleanLegacyState(workspace)
+	if err != nil {
+		return err
+	}
+
+	if output.IsJSON() {
+		return output.PrintJSON(map[string]interface{}{
+			"all":   cacheCleanAll,
+			"freed": freed,
+		})
+	}
+
+	if freed == 0 {
+		output.Info("Nothing to clean")
+		return nil
+	}
+	output.Success("Freed %s", statedir.FormatSize(freed))
+	return nil
+}
+
+// runCacheGC executes the cache gc command.
+func runCacheGC(cmd *cobra.Command, args []string) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+
+	policies, err := gc.LoadPolicies()
+	if err != nil {
+		return err
+	}
+	report, err := gc.Run(cache.WorkspaceRoot(cwd), policies, cacheGCDryRun)
+	if err != nil {
+		return err
+	}
+
+	if output.IsJSON() {
+		return output.PrintJSON(report)
+	}
+
+	freed, removed, kept := "Freed", "removed", "kept"
+	if report.DryRun {
+		freed, removed, kept = "Would free", "would remove", "keep"
+	}
+	output.Section("🧹", "Cache garbage collection")
+	for _, category := range report.Categories {
+		details := fmt.Sprintf("%s %d, %s %d (%s)", removed, len(category.Removed), kept, category.Kept, statedir.FormatSize(category.KeptSize))
+		if category.Unlimited {
+			details += ", no policy set"
+		}
+		output.Label(category.Name, details)
+		for _, name := range category.Removed {
+			output.Item("%s", name)
+		}
+		for _, msg := range category.Errors {
+			output.ItemError("%s", msg)
+		}
+	}
+	output.Newline()
+	output.Success("%s %s", freed, statedir.FormatSize(report.Freed))
+	return nil
+}
+
+// collectGarbage runs automatic garbage collection before services start.
+// Failures are reported as warnings and never stop the session.
+func collectGarbage(projectDir string) {
+	report, err := gc.Auto(projectDir)
+	if output.IsJSON() {
+		return
+	}
+	if err != nil {
+		output.Warning("Skipped cleanup of old logs and caches: %v", err)
+		return
+	}
+	if report != nil && report.Freed > 0 {
+		output.Info("Removed %s of old logs and caches", statedir.FormatSize(report.Freed))
+	}
+}
+
+// cleanLegacyState removes caches and logs left under .azure by earlier versions
+// and returns the number of bytes freed.
+func cleanLegacyState(workspace string) int64 {
+	var freed int64
+	for _, name := range legacyStateDirs {
+		dir := filepath.Join(workspace, ".azure", name)
+		size := statedir.DirSize(dir)
+		if err := os.RemoveAll(dir); err == nil {
+			freed += size
+		}
+	}
+	return freed
+}
@@ -0,0 +1,56 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jongio/azd-app/cli/src/internal/statedir"
+)
+
+func TestCacheClean(t *testing.T) {
+	t.Setenv(statedir.RootEnvVar, t.TempDir())
+
+	workspace, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to resolve temp directory: %v", err)
+	}
+	legacy := filepath.Join(workspace, ".azure", "logs", "api.log")
+	registry := filepath.Join(workspace, ".azure", "services.json")
+	for _, path := range []string{legacy, registry} {
+		if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("data"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cacheDir, err := statedir.CacheDir(workspace)
+	if err != nil {
+		t.Fatalf("CacheDir failed: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(cacheDir, "reqs_cache.json"), []byte("{}"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+	if err := os.Chdir(workspace); err != nil {
+		t.Fatal(err)
+	}
+
+	cacheCleanAll = false
+	if err := runCacheClean(NewCacheCommand(), nil); err != nil {
+		t.Fatalf("cache clean failed: %v", err)
+	}
+
+	if _, err := os.Stat(cacheDir); !os.IsNotExist(err) {
+		t.Error("workspace cache was not removed")
+	}
+	if _, err := os.Stat(legacy); !os.IsNotExist(err) {
+		t.Error("legacy .azure/logs was not removed")
+	}
+	if _, err := os.Stat(registry); err != nil {
+		t.Errorf("service registry should be kept: %v", err)
+	}
+}
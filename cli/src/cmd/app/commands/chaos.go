@@ -0,0 +1,236 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jongio/azd-app/cli/src/internal/chaos"
+	"github.com/jongio/azd-app/cli/src/internal/output"
+	"github.com/jongio/azd-app/cli/src/internal/registry"
+	"github.com/jongio/azd-app/cli/src/internal/service"
+)
+
+// processTarget applies chaos actions to the processes of a run session.
+type processTarget struct {
+	mu         sync.Mutex                         // Guards stopped and paused
+	processMu  *sync.Mutex                        // Lock of the session that guards processes
+	processes  map[string]*service.ServiceProcess // Shared with the session, e.g. its supervisor
+	stopped    map[string]bool
+	paused     map[string]*os.Process
+	projectDir string
+	reg        *registry.ServiceRegistry
+}
+
+// newProcessTarget creates a chaos target for the processes started by run. processMu is the
+// lock the session holds whenever it reads or replaces processes.
+func newProcessTarget(processes map[string]*service.ServiceProcess, processMu *sync.Mutex, projectDir string) *processTarget {
+	return &processTarget{
+		processMu:  processMu,
+		processes:  processes,
+		stopped:    make(map[string]bool),
+		paused:     make(map[string]*os.Process),
+		projectDir: projectDir,
+		reg:        registry.GetRegistry(projectDir),
+	}
+}
+
+// Services returns the services that are still running.
+func (t *processTarget) Services() []string {
+	t.processMu.Lock()
+	all := make([]string, 0, len(t.processes))
+	for name := range t.processes {
+		all = append(all, name)
+	}
+	t.processMu.Unlock()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	names := make([]string, 0, len(all))
+	for _, name := range all {
+		if !t.stopped[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Kill terminates a service and leaves it stopped for the rest of the session.
+func (t *processTarget) Kill(name string) error {
+	process, err := t.process(name)
+	if err != nil {
+		return err
+	}
+
+	t.resume(name)
+	if err := process.Process.Kill(); err != nil {
+		return fmt.Errorf("failed to kill %s: %w", name, err)
+	}
+	<-process.Exited()
+
+	t.mu.Lock()
+	t.stopped[name] = true
+	t.mu.Unlock()
+
+	_ = t.reg.UpdateStatus(name, "stopped", "unhealthy")
+	return nil
+}
+
+// Pause suspends a service and resumes it after d.
+func (t *processTarget) Pause(name string, d time.Duration) error {
+	process, err := t.process(name)
+	if err != nil {
+		return err
+	}
+
+	if err := chaos.Suspend(process.Process); err != nil {
+		return fmt.Errorf("failed to pause %s: %w", name, err)
+	}
+	t.mu.Lock()
+	t.paused[name] = process.Process
+	t.mu.Unlock()
+	_ = t.reg.UpdateStatus(name, "running", "unhealthy")
+
+	time.AfterFunc(d, func() { t.resume(name) })
+	return nil
+}
+
+// resume continues a paused service, if it is still paused.
+func (t *processTarget) resume(name string) {
+	t.mu.Lock()
+	process, paused := t.paused[name]
+	delete(t.paused, name)
+	t.mu.Unlock()
+
+	if paused && chaos.Resume(process) == nil {
+		_ = t.reg.UpdateStatus(name, "running", "healthy")
+	}
+}
+
+// Restart stops a service and starts it again.
+func (t *processTarget) Restart(name string) error {
+	process, err := t.process(name)
+	if err != nil {
+		return err
+	}
+
+	// A suspended process can't handle the stop signal
+	t.resume(name)
+
+	restarted, err := service.RestartService(commandContext(), process, t.projectDir)
+	if err != nil {
+		t.mu.Lock()
+		t.stopped[name] = true
+		t.mu.Unlock()
+		_ = t.reg.UpdateStatus(name, "error", "unknown")
+		return fmt.Errorf("failed to restart %s: %w", name, err)
+	}
+	restarted.Ready = true
+
+	t.processMu.Lock()
+	t.processes[name] = restarted
+	t.processMu.Unlock()
+
+	if entry, exists := t.reg.GetService(name); exists {
+		entry.PID = restarted.Process.Pid
+		entry.StartTime = time.Now()
+		_ = t.reg.Register(entry)
+	}
+	_ = t.reg.UpdateStatus(name, "running", "healthy")
+	return nil
+}
+
+// release resumes paused services and removes services stopped by chaos from the
+// session so shutdown can stop the rest cleanly.
+func (t *processTarget) release() {
+	t.mu.Lock()
+	paused := make([]string, 0, len(t.paused))
+	for name := range t.paused {
+		paused = append(paused, name)
+	}
+	t.mu.Unlock()
+	for _, name := range paused {
+		t.resume(name)
+	}
+
+	t.mu.Lock()
+	stopped := make([]string, 0, len(t.stopped))
+	for name := range t.stopped {
+		stopped = append(stopped, name)
+	}
+	t.mu.Unlock()
+
+	t.processMu.Lock()
+	for _, name := range stopped {
+		delete(t.processes, name)
+	}
+	t.processMu.Unlock()
+	for _, name := range stopped {
+		_ = t.reg.Unregister(name)
+	}
+}
+
+// process returns the running process for a service.
+func (t *processTarget) process(name string) (*service.ServiceProcess, error) {
+	t.processMu.Lock()
+	process, exists := t.processes[name]
+	t.processMu.Unlock()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !exists || t.stopped[name] || process.Process == nil {
+		return nil, fmt.Errorf("service %s is not running", name)
+	}
+	return process, nil
+}
+
+// startChaos disrupts random services in the background until the returned stop function is called.
+// The stop function waits for any in-progress action to finish before returning.
+func startChaos(session *runSession) (func(), error) {
+	actions, err := chaos.ParseActions(runChaosActions)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := chaos.Config{
+		Interval:      runChaosInterval,
+		PauseDuration: runChaosPause,
+		Actions:       actions,
+		Exclude:       runChaosExclude,
+	}
+
+	names := make([]string, len(actions))
+	for i, action := range actions {
+		names[i] = string(action)
+	}
+	output.Warning("🐒 Chaos enabled: %s every %s", strings.Join(names, "/"), cfg.Interval)
+	if len(cfg.Exclude) > 0 {
+		output.Item("Excluded: %s", strings.Join(cfg.Exclude, ", "))
+	}
+
+	target := newProcessTarget(session.result.Processes, &session.mu, session.cwd)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		chaos.Run(ctx, target, cfg, func(event chaos.Event) {
+			if event.Error != "" {
+				output.ItemWarning("chaos: %s %s failed: %s", event.Action, event.Service, event.Error)
+				return
+			}
+			output.ItemWarning("chaos: %s %s", event.Action, event.Service)
+		})
+	}()
+
+	return func() {
+		cancel()
+		<-done
+		target.release()
+	}, nil
+}
@@ -0,0 +1,129 @@
+package commands
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jongio/azd-app/cli/src/internal/service"
+)
+
+func startSleepService(t *testing.T, name, projectDir string) *service.ServiceProcess {
+	t.Helper()
+	process, err := service.StartService(context.Background(), &service.ServiceRuntime{
+		Name:       name,
+		Command:    "sleep",
+		Args:       []string{"30"},
+		WorkingDir: projectDir,
+	}, nil, projectDir)
+	if err != nil {
+		t.Skipf("sleep not available: %v", err)
+	}
+	return process
+}
+
+func TestProcessTarget(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test uses the sleep command")
+	}
+
+	projectDir := t.TempDir()
+	processes := map[string]*service.ServiceProcess{
+		"api": startSleepService(t, "api", projectDir),
+		"web": startSleepService(t, "web", projectDir),
+	}
+	target := newProcessTarget(processes, &sync.Mutex{}, projectDir)
+	defer func() {
+		target.release()
+		for _, p := range processes {
+			_ = p.Process.Kill()
+			_, _ = p.Process.Wait()
+		}
+	}()
+
+	// Restart replaces the process
+	oldPID := processes["api"].Process.Pid
+	if err := target.Restart("api"); err != nil {
+		t.Fatalf("Restart() error = %v", err)
+	}
+	if processes["api"].Process.Pid == oldPID {
+		t.Error("Restart() did not start a new process")
+	}
+
+	// Pause suspends and releases on cleanup
+	if err := target.Pause("api", time.Hour); err != nil {
+		t.Fatalf("Pause() error = %v", err)
+	}
+
+	// Kill removes the service from the running set
+	if err := target.Kill("web"); err != nil {
+		t.Fatalf("Kill() error = %v", err)
+	}
+	if got := target.Services(); len(got) != 1 || got[0] != "api" {
+		t.Errorf("Services() = %v, want [api]", got)
+	}
+	if err := target.Kill("web"); err == nil {
+		t.Error("Kill() on a stopped service expected error")
+	}
+
+	target.release()
+	if _, exists := processes["web"]; exists {
+		t.Error("release() should remove killed services from the session")
+	}
+	if len(target.paused) != 0 {
+		t.Error("release() should resume paused services")
+	}
+}
+
+// TestChaosWithSupervisor runs chaos restarts while the supervisor watches the same services,
+// both replacing processes in the session map; run with -race.
+func TestChaosWithSupervisor(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping process test in short mode")
+	}
+	if runtime.GOOS == "windows" {
+		t.Skip("test uses the sleep command")
+	}
+
+	oldActions, oldInterval := runChaosActions, runChaosInterval
+	runChaosActions, runChaosInterval = []string{"restart"}, 20*time.Millisecond
+	t.Cleanup(func() { runChaosActions, runChaosInterval = oldActions, oldInterval })
+
+	projectDir := t.TempDir()
+	processes := make(map[string]*service.ServiceProcess)
+	for _, name := range []string{"api", "web"} {
+		process := startSleepService(t, name, projectDir)
+		process.Runtime.Restart = service.RestartPolicy{Mode: service.RestartAlways}
+		processes[name] = process
+	}
+	started := make(map[string]*service.ServiceProcess, len(processes))
+	for name, process := range processes {
+		started[name] = process
+	}
+	session := &runSession{
+		cwd:    projectDir,
+		logger: service.NewServiceLogger(false),
+		result: &service.OrchestrationResult{Processes: processes},
+	}
+	stopSupervisor := startSupervisor(session, func(err error) { t.Errorf("session failed: %v", err) })
+	stopChaos, err := startChaos(session)
+	if err != nil {
+		t.Fatalf("startChaos() error = %v", err)
+	}
+
+	time.Sleep(300 * time.Millisecond)
+	stopChaos()
+	stopSupervisor()
+
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	for name, process := range session.result.Processes {
+		if process == started[name] {
+			t.Errorf("chaos never restarted %s", name)
+		}
+		_ = process.Process.Kill()
+		_, _ = process.Process.Wait()
+	}
+}
@@ -1,9 +1,13 @@
 package commands
 
 import (
+	"encoding/json"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
+	"github.com/jongio/azd-app/cli/src/internal/output"
 	"github.com/spf13/cobra"
 )
 
@@ -252,3 +256,125 @@ func TestCommandUsageText(t *testing.T) {
 		})
 	}
 }
+
+// TestJSONOutputContract checks that in JSON mode a command writes exactly one JSON document
+// to stdout, so pipelines can parse it, and everything else to stderr.
+func TestJSONOutputContract(t *testing.T) {
+	dir := t.TempDir()
+	azureYaml := "name: shop\nservices:\n  api:\n    project: ./api\n    host: containerapp\n  web:\n    project: ./web\n    host: containerapp\n    uses:\n      - api\nreqs:\n  - name: go\n    minVersion: 1.0.0\n"
+	if err := os.WriteFile(filepath.Join(dir, "azure.yaml"), []byte(azureYaml), 0600); err != nil {
+		t.Fatal(err)
+	}
+	for _, project := range []string{"api", "web"} {
+		if err := os.Mkdir(filepath.Join(dir, project), 0750); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Chdir(dir)
+	SetTrustWorkspace(true)
+	t.Cleanup(func() { SetTrustWorkspace(false) })
+
+	if err := output.SetFormat("json"); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = output.SetFormat("default") })
+
+	tests := []struct {
+		name    string
+		cmd     func() *cobra.Command
+		mayFail bool // Fails when a check fails, after printing its report
+	}{
+		{name: "version", cmd: NewVersionCommand},
+		{name: "graph", cmd: NewGraphCommand},
+		{name: "detect", cmd: NewDetectCommand},
+		{name: "doctor", cmd: NewDoctorCommand, mayFail: true},
+		{name: "status", cmd: NewStatusCommand},
+		{name: "reqs", cmd: NewReqsCommand},
+		{name: "info", cmd: NewInfoCommand},
+		{name: "scorecard", cmd: NewScorecardCommand},
+		{name: "sync", cmd: NewSyncCommand},
+		{name: "stats", cmd: NewStatsCommand},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmp := t.TempDir()
+			stdout, err := os.Create(filepath.Join(tmp, "stdout"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			oldStdout := os.Stdout
+			os.Stdout = stdout
+			cmd := tt.cmd()
+			cmd.SetArgs(nil)
+			runErr := cmd.Execute()
+			os.Stdout = oldStdout
+			_ = stdout.Close()
+			if runErr != nil && !tt.mayFail {
+				t.Fatalf("%s failed: %v", tt.name, runErr)
+			}
+
+			data, err := os.ReadFile(stdout.Name())
+			if err != nil {
+				t.Fatal(err)
+			}
+			decoder := json.NewDecoder(strings.NewReader(string(data)))
+			var doc interface{}
+			if err := decoder.Decode(&doc); err != nil {
+				t.Fatalf("stdout is not JSON: %v\n%s", err, data)
+			}
+			if decoder.More() {
+				t.Errorf("stdout has more than one JSON document:\n%s", data)
+			}
+		})
+	}
+}
+
+// TestTableOutputStreams covers the other half of the contract: in table mode the report goes
+// to stdout, and warnings to stderr.
+func TestTableOutputStreams(t *testing.T) {
+	dir := t.TempDir()
+	// The services have no projects, which detect warns about
+	azureYaml := "name: shop\nservices:\n  api:\n    project: ./api\n    host: containerapp\n"
+	if err := os.WriteFile(filepath.Join(dir, "azure.yaml"), []byte(azureYaml), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(dir)
+
+	if err := output.SetFormat("table"); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = output.SetFormat("default") })
+
+	tmp := t.TempDir()
+	stdout, err := os.Create(filepath.Join(tmp, "stdout"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	stderr, err := os.Create(filepath.Join(tmp, "stderr"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldStdout, oldStderr := os.Stdout, os.Stderr
+	os.Stdout, os.Stderr = stdout, stderr
+	cmd := NewDetectCommand()
+	cmd.SetArgs(nil)
+	runErr := cmd.Execute()
+	os.Stdout, os.Stderr = oldStdout, oldStderr
+	_ = stdout.Close()
+	_ = stderr.Close()
+	if runErr != nil {
+		t.Fatalf("detect failed: %v", runErr)
+	}
+
+	out, _ := os.ReadFile(stdout.Name())
+	errOut, _ := os.ReadFile(stderr.Name())
+	if !strings.Contains(string(out), "No projects found") {
+		t.Errorf("stdout = %q, want the report", out)
+	}
+	if strings.Contains(string(out), "No project found for") {
+		t.Errorf("stdout = %q, want no warnings", out)
+	}
+	if !strings.Contains(string(errOut), "No project found for the azure.yaml services api") {
+		t.Errorf("stderr = %q, want the warning", errOut)
+	}
+}
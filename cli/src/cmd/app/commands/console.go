@@ -0,0 +1,724 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/jongio/azd-app/cli/src/internal/alias"
+	"github.com/jongio/azd-app/cli/src/internal/console"
+	"github.com/jongio/azd-app/cli/src/internal/output"
+	"github.com/jongio/azd-app/cli/src/internal/security"
+	"github.com/jongio/azd-app/cli/src/internal/service"
+	"github.com/jongio/azd-app/cli/src/internal/statedir"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+const consolePrompt = "azd-app> "
+
+var (
+	consoleEnvFile string
+	consoleStart   bool
+	consoleScript  string
+)
+
+// consoleCommand is a command available inside the console.
+type consoleCommand struct {
+	name    string
+	usage   string
+	summary string
+	run     func(s *consoleSession, args []string) error
+}
+
+// errConsoleExit ends the console session.
+var errConsoleExit = errors.New("exit")
+
+// consoleCommandTable returns the console commands in the order help lists them.
+func consoleCommandTable() []consoleCommand {
+	return []consoleCommand{
+		{"start", "start [service...]", "Start services (all when none are given)", (*consoleSession).start},
+		{"stop", "stop [service...]", "Stop services (all running when none are given)", (*consoleSession).stop},
+		{"restart", "restart [service...]", "Restart services with the current session environment", (*consoleSession).restart},
+		{"status", "status", "Show the state, URL, and PID of each service", (*consoleSession).status},
+		{"logs", "logs [service...] [-n lines] [-f]", "Show recent logs, or follow them until Ctrl+C", (*consoleSession).logs},
+		{"env", "env [set KEY=VALUE... | unset KEY...]", "Show or change variables applied to services started from now on", (*consoleSession).envCommand},
+		{"sleep", "sleep <duration>", "Wait, e.g. for a service to warm up in a script", (*consoleSession).sleep},
+		{"history", "history", "Show command history; recall entries with !!, !n, or !prefix", (*consoleSession).showHistory},
+		{"help", "help", "Show this help", (*consoleSession).help},
+		{"exit", "exit", "Stop all services and leave the console", (*consoleSession).exit},
+	}
+}
+
+// NewConsoleCommand creates the console command.
+func NewConsoleCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "console",
+		Short: "Control services interactively in a live session",
+		Long: `Opens an interactive console where services from azure.yaml can be started, stopped,
+restarted, and inspected without re-running the CLI. Services started in the console run until
+they are stopped or the console exits.
+
+Commands are read one per line. With a terminal, Up/Down recall history and Tab completes
+commands and service names. Commands can also be piped in or read from --script, which stops
+at the first failing command.`,
+		Example: `  azd app console
+  azd app console --start --env-file .env.local
+  azd app console --script smoke.txt`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE:         runConsole,
+	}
+
+	cmd.Flags().StringVar(&consoleEnvFile, "env-file", "", "Load environment variables from .env file into the session")
+	cmd.Flags().BoolVar(&consoleStart, "start", false, "Start all services when the console opens")
+	cmd.Flags().StringVar(&consoleScript, "script", "", "Read commands from a file instead of the terminal")
+
+	return cmd
+}
+
+// consoleSession holds the services and environment of a console.
+type consoleSession struct {
+	mu         sync.Mutex
+	cwd        string
+	projectDir string
+	services   map[string]service.Service
+	azureYaml  *service.AzureYaml
+	processes  map[string]*service.ServiceProcess
+	env        map[string]string
+	logger     *service.ServiceLogger
+	history    *console.History
+	reader     *console.Reader
+	cancel     context.CancelFunc
+	depsReady  bool
+	out        io.Writer
+}
+
+// runConsole executes the console command.
+func runConsole(cmd *cobra.Command, args []string) error {
+	if output.IsJSON() {
+		return fmt.Errorf("the console does not support JSON output")
+	}
+
+	azureYamlPath, err := findAzureYaml()
+	if err != nil {
+		return err
+	}
+	azureYaml, err := service.ParseAzureYaml(azureYamlPath)
+	if err != nil {
+		return fmt.Errorf("failed to parse azure.yaml: %w", err)
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+
+	session := &consoleSession{
+		cwd:        cwd,
+		projectDir: filepath.Dir(azureYamlPath),
+		services:   azureYaml.Services,
+		azureYaml:  azureYaml,
+		processes:  make(map[string]*service.ServiceProcess),
+		env:        make(map[string]string),
+		logger:     service.NewServiceLogger(false),
+		out:        os.Stdout,
+	}
+	if session.services == nil {
+		session.services = make(map[string]service.Service)
+	}
+	if consoleEnvFile != "" {
+		envVars, err := service.LoadDotEnv(consoleEnvFile)
+		if err != nil {
+			return fmt.Errorf("failed to load env file: %w", err)
+		}
+		session.env = envVars
+	}
+
+	historyDir, err := statedir.DataDir(session.projectDir, "console")
+	if err == nil {
+		session.history, err = console.LoadHistory(filepath.Join(historyDir, "history"), console.DefaultHistorySize)
+	}
+	if err != nil {
+		output.Warning("Console history unavailable: %v", err)
+		session.history, _ = console.LoadHistory("", console.DefaultHistorySize)
+	}
+	collectGarbage(session.projectDir)
+
+	in := io.Reader(os.Stdin)
+	if consoleScript != "" {
+		script, err := openConsoleScript(consoleScript)
+		if err != nil {
+			return err
+		}
+		defer script.Close()
+		in = script
+	}
+	session.reader = console.NewReader(in, os.Stdout, session.history, session.complete)
+
+	stopSignals := session.handleSignals()
+	defer stopSignals()
+
+	return session.loop()
+}
+
+// openConsoleScript opens a file of console commands.
+func openConsoleScript(path string) (*os.File, error) {
+	if err := security.ValidatePath(path); err != nil {
+		return nil, fmt.Errorf("invalid script path: %w", err)
+	}
+	// #nosec G304 -- Path validated by security.ValidatePath
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open script: %w", err)
+	}
+	return file, nil
+}
+
+// loop reads and runs commands until exit or end of input, then stops all services.
+func (s *consoleSession) loop() error {
+	interactive := s.reader.Interactive()
+	if interactive {
+		output.Section("💻", "azd app console")
+		output.Item("Type 'help' for commands, Tab to complete, 'exit' to quit")
+		output.Newline()
+	}
+
+	var loopErr error
+	if consoleStart {
+		loopErr = s.start(nil)
+	}
+
+	for loopErr == nil {
+		line, err := s.reader.ReadLine(consolePrompt)
+		if errors.Is(err, console.ErrInterrupted) {
+			continue
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			loopErr = fmt.Errorf("failed to read command: %w", err)
+			break
+		}
+
+		err = s.execute(line, interactive)
+		if errors.Is(err, errConsoleExit) {
+			break
+		}
+		if err != nil {
+			// Scripts stop at the first failing command
+			if !interactive {
+				loopErr = err
+				break
+			}
+			output.Error("%v", err)
+		}
+	}
+
+	if interactive {
+		if err := s.history.Save(); err != nil {
+			output.Warning("Failed to save console history: %v", err)
+		}
+	}
+	s.shutdown()
+	return loopErr
+}
+
+// execute runs one console line. Blank lines and comments are ignored.
+func (s *consoleSession) execute(line string, interactive bool) error {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return nil
+	}
+
+	expanded, err := s.history.Expand(line)
+	if err != nil {
+		return err
+	}
+	if expanded != line {
+		fmt.Fprintln(s.out, expanded)
+	}
+	if interactive {
+		s.history.Add(expanded)
+	}
+
+	words, err := alias.Split(expanded)
+	if err != nil {
+		return err
+	}
+	name := strings.ToLower(words[0])
+	if name == "quit" {
+		name = "exit"
+	}
+	for _, command := range consoleCommandTable() {
+		if command.name == name {
+			return command.run(s, words[1:])
+		}
+	}
+	return fmt.Errorf("unknown command %q (type 'help' for commands)", words[0])
+}
+
+// handleSignals keeps Ctrl+C from ending the console: it cancels a running command such as
+// 'logs -f' instead. Scripts are still stopped, after their services are shut down.
+// The returned function stops signal handling.
+func (s *consoleSession) handleSignals() func() {
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	done := make(chan struct{})
+
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case sig := <-sigChan:
+				s.mu.Lock()
+				cancel := s.cancel
+				s.mu.Unlock()
+
+				switch {
+				case cancel != nil:
+					cancel()
+				case sig == syscall.SIGTERM || !s.reader.Interactive():
+					s.shutdown()
+					os.Exit(130)
+				default:
+					fmt.Fprintln(s.out, "\nType 'exit' to stop services and leave the console")
+				}
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(sigChan)
+		close(done)
+	}
+}
+
+// interruptible returns a context that Ctrl+C cancels, and a function to release it.
+func (s *consoleSession) interruptible() (context.Context, func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.mu.Lock()
+	s.cancel = cancel
+	s.mu.Unlock()
+
+	return ctx, func() {
+		s.mu.Lock()
+		s.cancel = nil
+		s.mu.Unlock()
+		cancel()
+	}
+}
+
+// start starts the named services, or all services, that are not already running.
+func (s *consoleSession) start(args []string) error {
+	names, err := s.serviceNames(args, s.allServices())
+	if err != nil {
+		return err
+	}
+
+	toStart := make(map[string]service.Service)
+	for _, name := range names {
+		if !s.isRunning(name) {
+			toStart[name] = s.services[name]
+		}
+	}
+	if len(toStart) == 0 {
+		output.Info("Requested services are already running")
+		return nil
+	}
+
+	// Check requirements and install dependencies once per session
+	if !s.depsReady {
+		if err := cmdOrchestrator.Run("run"); err != nil {
+			return fmt.Errorf("failed to execute command dependencies: %w", err)
+		}
+		s.depsReady = true
+	}
+
+	runtimes, err := detectServiceRuntimes(toStart, s.projectDir, runtimeModeAzd)
+	if err != nil {
+		return err
+	}
+	if err := prepareRuntimes(s.azureYaml, s.projectDir, runtimes); err != nil {
+		return err
+	}
+	env := s.sessionEnv()
+	for _, runtime := range runtimes {
+		// Session variables override those from azure.yaml
+		merged := make(map[string]string, len(runtime.Env)+len(env))
+		for key, value := range runtime.Env {
+			merged[key] = value
+		}
+		for key, value := range env {
+			merged[key] = value
+		}
+		runtime.Env = merged
+	}
+
+	result, err := service.OrchestrateServices(commandContext(), runtimes, env, s.logger)
+	if err != nil {
+		return fmt.Errorf("service orchestration failed: %w", err)
+	}
+
+	s.mu.Lock()
+	for name, process := range result.Processes {
+		s.processes[name] = process
+	}
+	s.mu.Unlock()
+	return nil
+}
+
+// stop stops the named services, or all running services.
+func (s *consoleSession) stop(args []string) error {
+	names, err := s.serviceNames(args, s.runningServices())
+	if err != nil {
+		return err
+	}
+
+	toStop := make(map[string]*service.ServiceProcess)
+	s.mu.Lock()
+	for _, name := range names {
+		if process, ok := s.processes[name]; ok {
+			toStop[name] = process
+			delete(s.processes, name)
+		}
+	}
+	s.mu.Unlock()
+
+	if len(toStop) == 0 {
+		output.Info("No running services to stop")
+		return nil
+	}
+
+	service.StopAllServices(toStop)
+	for _, name := range sortedKeys(toStop) {
+		output.ItemSuccess("Stopped %s", name)
+	}
+	return nil
+}
+
+// restart restarts the named services, or all running services, applying the current
+// session environment.
+func (s *consoleSession) restart(args []string) error {
+	names, err := s.serviceNames(args, s.runningServices())
+	if err != nil {
+		return err
+	}
+
+	env := s.sessionEnv()
+	target := newProcessTarget(s.processes, &s.mu, s.cwd)
+	for _, name := range names {
+		s.mu.Lock()
+		process, ok := s.processes[name]
+		if ok {
+			merged := make(map[string]string, len(process.Env)+len(env))
+			for key, value := range process.Env {
+				merged[key] = value
+			}
+			for key, value := range env {
+				merged[key] = value
+			}
+			process.Env = merged
+		}
+		s.mu.Unlock()
+
+		if !ok {
+			return fmt.Errorf("service %s is not running (use 'start %s')", name, name)
+		}
+		if err := target.Restart(name); err != nil {
+			s.mu.Lock()
+			delete(s.processes, name)
+			s.mu.Unlock()
+			return err
+		}
+		output.ItemSuccess("Restarted %s → %s", name, output.URL(fmt.Sprintf("http://localhost:%d", process.Port)))
+	}
+	return nil
+}
+
+// status lists every service in azure.yaml with its state in this session.
+func (s *consoleSession) status(args []string) error {
+	if len(args) > 0 {
+		return fmt.Errorf("status takes no arguments")
+	}
+	if len(s.services) == 0 {
+		output.Info("No services defined in azure.yaml")
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	table := output.NewTable()
+	table.Indent, table.Gap = "   ", 1
+	for _, name := range s.allServices() {
+		process, ok := s.processes[name]
+		if !ok {
+			table.AddRow(" ", name, "stopped")
+			continue
+		}
+		state := service.GetProcessStatus(process)
+		url := fmt.Sprintf("http://localhost:%d", process.Port)
+		if state == "stopped" {
+			table.AddRow(output.Red+"✗"+output.Reset, name, "exited")
+			continue
+		}
+		table.AddRow(output.Green+"✓"+output.Reset, name, state, output.URL(url), fmt.Sprintf("(pid %d)", process.Process.Pid))
+	}
+	table.Print()
+	return nil
+}
+
+// logs shows recent log lines of services started in this session, optionally
+// following new lines until Ctrl+C.
+func (s *consoleSession) logs(args []string) error {
+	flags := pflag.NewFlagSet("logs", pflag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	tail := flags.IntP("tail", "n", 20, "Number of lines to show")
+	follow := flags.BoolP("follow", "f", false, "Follow log output")
+	if err := flags.Parse(args); err != nil {
+		return fmt.Errorf("logs: %w", err)
+	}
+
+	logManager := service.GetLogManager(s.cwd)
+	names, err := s.serviceNames(flags.Args(), logManager.GetServiceNames())
+	if err != nil {
+		return err
+	}
+
+	var entries []service.LogEntry
+	buffers := make(map[string]*service.LogBuffer)
+	for _, name := range names {
+		if buffer, ok := logManager.GetBuffer(name); ok {
+			buffers[name] = buffer
+			entries = append(entries, buffer.GetRecent(*tail)...)
+		}
+	}
+	if len(buffers) == 0 {
+		output.Info("No logs yet - start services with 'start'")
+		return nil
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Timestamp.Before(entries[j].Timestamp)
+	})
+	displayLogsText(entries, os.Stdout, true, false)
+
+	if !*follow {
+		return nil
+	}
+
+	output.Info("Following logs, press Ctrl+C to return to the console")
+	ctx, release := s.interruptible()
+	defer release()
+
+	merged := make(chan service.LogEntry, 100)
+	for _, buffer := range buffers {
+		ch := buffer.Subscribe()
+		defer buffer.Unsubscribe(ch)
+		go func(ch chan service.LogEntry) {
+			for entry := range ch {
+				select {
+				case merged <- entry:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}(ch)
+	}
+
+	for {
+		select {
+		case entry := <-merged:
+			displayLogsText([]service.LogEntry{entry}, os.Stdout, true, false)
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// envCommand shows or changes the session environment.
+func (s *consoleSession) envCommand(args []string) error {
+	if len(args) == 0 || args[0] == "list" {
+		env := s.sessionEnv()
+		if len(env) == 0 {
+			output.Info("No session variables set (use 'env set KEY=VALUE')")
+			return nil
+		}
+		for _, key := range sortedKeys(env) {
+			output.Label(key, env[key])
+		}
+		return nil
+	}
+
+	if len(args) < 2 {
+		return fmt.Errorf("usage: env [set KEY=VALUE... | unset KEY...]")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch args[0] {
+	case "set":
+		for _, pair := range args[1:] {
+			key, value, ok := strings.Cut(pair, "=")
+			if !ok || key == "" {
+				return fmt.Errorf("invalid variable %q (expected KEY=VALUE)", pair)
+			}
+			s.env[key] = value
+		}
+	case "unset":
+		for _, key := range args[1:] {
+			delete(s.env, key)
+		}
+	default:
+		return fmt.Errorf("unknown env command %q (expected set or unset)", args[0])
+	}
+	if len(s.processes) > 0 {
+		output.Item("Restart running services to apply the change")
+	}
+	return nil
+}
+
+// sleep waits for a duration, or until Ctrl+C.
+func (s *consoleSession) sleep(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: sleep <duration>")
+	}
+	duration, err := time.ParseDuration(args[0])
+	if err != nil {
+		return fmt.Errorf("invalid duration: %w", err)
+	}
+
+	ctx, release := s.interruptible()
+	defer release()
+	select {
+	case <-time.After(duration):
+	case <-ctx.Done():
+	}
+	return nil
+}
+
+// showHistory prints the numbered command history.
+func (s *consoleSession) showHistory(args []string) error {
+	for i, entry := range s.history.Entries() {
+		fmt.Fprintf(s.out, "%5d  %s\n", i+1, entry)
+	}
+	return nil
+}
+
+// help lists the console commands.
+func (s *consoleSession) help(args []string) error {
+	for _, command := range consoleCommandTable() {
+		fmt.Fprintf(s.out, "  %-40s %s\n", command.usage, command.summary)
+	}
+	return nil
+}
+
+// exit ends the session.
+func (s *consoleSession) exit(args []string) error {
+	return errConsoleExit
+}
+
+// shutdown stops every service started in the session.
+func (s *consoleSession) shutdown() {
+	s.mu.Lock()
+	processes := s.processes
+	s.processes = make(map[string]*service.ServiceProcess)
+	s.mu.Unlock()
+
+	if len(processes) == 0 {
+		return
+	}
+	output.Warning("🛑 Stopping services...")
+	service.StopAllServices(processes)
+	output.Success("All services stopped")
+}
+
+// complete suggests console commands, service names, and env keys.
+func (s *consoleSession) complete(words []string, prefix string) []string {
+	if len(words) == 0 {
+		var names []string
+		for _, command := range consoleCommandTable() {
+			names = append(names, command.name)
+		}
+		return names
+	}
+
+	switch words[0] {
+	case "start":
+		return s.allServices()
+	case "stop", "restart":
+		return s.runningServices()
+	case "logs":
+		return service.GetLogManager(s.cwd).GetServiceNames()
+	case "env":
+		if len(words) == 1 {
+			return []string{"list", "set", "unset"}
+		}
+		if words[1] == "unset" {
+			return sortedKeys(s.sessionEnv())
+		}
+	}
+	return nil
+}
+
+// serviceNames validates the services named in args, returning defaults when none are given.
+func (s *consoleSession) serviceNames(args []string, defaults []string) ([]string, error) {
+	if len(args) == 0 {
+		return defaults, nil
+	}
+
+	var names []string
+	for _, arg := range args {
+		for _, name := range strings.Split(arg, ",") {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+			if _, ok := s.services[name]; !ok {
+				return nil, fmt.Errorf("service '%s' not found (available: %s)", name, strings.Join(s.allServices(), ", "))
+			}
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// allServices returns the names of all services in azure.yaml.
+func (s *consoleSession) allServices() []string {
+	return sortedKeys(s.services)
+}
+
+// runningServices returns the names of services started in this session.
+func (s *consoleSession) runningServices() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return sortedKeys(s.processes)
+}
+
+// isRunning reports whether a service was started in this session and has not exited.
+func (s *consoleSession) isRunning(name string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	process, ok := s.processes[name]
+	return ok && service.GetProcessStatus(process) != "stopped"
+}
+
+// sessionEnv returns a copy of the session environment.
+func (s *consoleSession) sessionEnv() map[string]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	env := make(map[string]string, len(s.env))
+	for key, value := range s.env {
+		env[key] = value
+	}
+	return env
+}
@@ -0,0 +1,107 @@
+package commands
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/jongio/azd-app/cli/src/internal/console"
+	"github.com/jongio/azd-app/cli/src/internal/service"
+)
+
+func newTestConsoleSession(t *testing.T) *consoleSession {
+	t.Helper()
+	history, err := console.LoadHistory("", console.DefaultHistorySize)
+	if err != nil {
+		t.Fatalf("LoadHistory failed: %v", err)
+	}
+	return &consoleSession{
+		cwd:        t.TempDir(),
+		projectDir: t.TempDir(),
+		services: map[string]service.Service{
+			"api": {Language: "python"},
+			"web": {Language: "js"},
+		},
+		processes: make(map[string]*service.ServiceProcess),
+		env:       make(map[string]string),
+		history:   history,
+		out:       &bytes.Buffer{},
+	}
+}
+
+func TestConsoleExecute(t *testing.T) {
+	s := newTestConsoleSession(t)
+
+	if err := s.execute(`env set GREETING="hello world" MODE=dev`, true); err != nil {
+		t.Fatalf("env set failed: %v", err)
+	}
+	if s.env["GREETING"] != "hello world" || s.env["MODE"] != "dev" {
+		t.Errorf("env = %v", s.env)
+	}
+	if err := s.execute("env unset MODE", true); err != nil {
+		t.Fatalf("env unset failed: %v", err)
+	}
+	if _, ok := s.env["MODE"]; ok {
+		t.Error("MODE should be unset")
+	}
+
+	tests := []struct {
+		line    string
+		wantErr string
+	}{
+		{"", ""},
+		{"# comment", ""},
+		{"env set NOEQUALS", "invalid variable"},
+		{"env frob X", "unknown env command"},
+		{"stop nope", "service 'nope' not found"},
+		{"restart api", "service api is not running"},
+		{"sleep", "usage: sleep"},
+		{"sleep 1ms", ""},
+		{"bogus", "unknown command"},
+		{"!missing", "no history entry"},
+	}
+	for _, tt := range tests {
+		err := s.execute(tt.line, true)
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Errorf("execute(%q) error = %v", tt.line, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("execute(%q) error = %v, want %q", tt.line, err, tt.wantErr)
+		}
+	}
+
+	if err := s.execute("quit", true); err != errConsoleExit {
+		t.Errorf("quit should end the session, got %v", err)
+	}
+
+	history := s.history.Entries()
+	if len(history) == 0 || history[0] != `env set GREETING="hello world" MODE=dev` {
+		t.Errorf("interactive commands should be recorded in history, got %q", history)
+	}
+}
+
+func TestConsoleComplete(t *testing.T) {
+	s := newTestConsoleSession(t)
+	s.env["TOKEN"] = "x"
+
+	tests := []struct {
+		words []string
+		want  []string
+	}{
+		{nil, []string{"start", "stop", "restart", "status", "logs", "env", "sleep", "history", "help", "exit"}},
+		{[]string{"start"}, []string{"api", "web"}},
+		{[]string{"stop"}, []string{}},
+		{[]string{"env"}, []string{"list", "set", "unset"}},
+		{[]string{"env", "unset"}, []string{"TOKEN"}},
+		{[]string{"sleep"}, nil},
+	}
+	for _, tt := range tests {
+		got := s.complete(tt.words, "")
+		if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+			t.Errorf("complete(%q) = %q, want %q", tt.words, got, tt.want)
+		}
+	}
+}
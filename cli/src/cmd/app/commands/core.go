@@ -1,22 +1,24 @@
 package commands
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/signal"
 	"path/filepath"
 	"syscall"
 
+	"github.com/jongio/azd-app/cli/src/internal/azureyaml"
 	"github.com/jongio/azd-app/cli/src/internal/cache"
 	"github.com/jongio/azd-app/cli/src/internal/dashboard"
 	"github.com/jongio/azd-app/cli/src/internal/detector"
+	"github.com/jongio/azd-app/cli/src/internal/httpclient"
 	"github.com/jongio/azd-app/cli/src/internal/installer"
 	"github.com/jongio/azd-app/cli/src/internal/orchestrator"
 	"github.com/jongio/azd-app/cli/src/internal/output"
-	"github.com/jongio/azd-app/cli/src/internal/security"
+	"github.com/jongio/azd-app/cli/src/internal/retry"
 	"github.com/jongio/azd-app/cli/src/internal/service"
-
-	"gopkg.in/yaml.v3"
+	"github.com/jongio/azd-app/cli/src/internal/workerpool"
 )
 
 // Global orchestrator instance shared across all commands.
@@ -35,10 +37,20 @@ func init() {
 	cmdOrchestrator = orchestrator.NewOrchestrator()
 
 	// Register commands with their dependencies
-	// reqs has no dependencies
+	// trust has no dependencies
+	if err := cmdOrchestrator.Register(&orchestrator.Command{
+		Name:    "trust",
+		Execute: executeTrust,
+	}); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to register trust command: %v\n", err)
+		os.Exit(1)
+	}
+
+	// reqs depends on trust, so no workspace command runs before the workspace is trusted
 	if err := cmdOrchestrator.Register(&orchestrator.Command{
-		Name:    "reqs",
-		Execute: executeReqs,
+		Name:         "reqs",
+		Dependencies: []string{"trust"},
+		Execute:      executeReqs,
 	}); err != nil {
 		fmt.Fprintf(os.Stderr, "Failed to register reqs command: %v\n", err)
 		os.Exit(1)
@@ -70,6 +82,9 @@ func executeReqs() error {
 	if !output.IsJSON() {
 		output.Section("🔍", "Checking reqs...")
 	}
+	if sandboxMode {
+		return executeSandboxReqs()
+	}
 
 	// Get current working directory
 	cwd, err := os.Getwd()
@@ -87,23 +102,19 @@ func executeReqs() error {
 		return fmt.Errorf("no azure.yaml found in current directory or parents - run 'azd app reqs --generate' to create one")
 	}
 
-	// Validate path to azure.yaml
-	if err := security.ValidatePath(azureYamlPath); err != nil {
-		return fmt.Errorf("invalid path: %w", err)
-	}
-
-	// #nosec G304 -- Path validated by security.ValidatePath above
-	data, err := os.ReadFile(azureYamlPath)
+	doc, err := azureyaml.Load(azureYamlPath)
 	if err != nil {
-		return fmt.Errorf("failed to read azure.yaml: %w", err)
+		return err
 	}
+	reqs := doc.Project.Reqs
 
-	var azureYaml AzureYaml
-	if err := yaml.Unmarshal(data, &azureYaml); err != nil {
-		return fmt.Errorf("failed to parse azure.yaml: %w", err)
+	// Schema issues are reported but do not fail the check; azd enforces them on deploy
+	issues := doc.Validate()
+	if !output.IsJSON() {
+		printSchemaIssues(issues)
 	}
 
-	if len(azureYaml.Reqs) == 0 {
+	if len(reqs) == 0 {
 		return fmt.Errorf("no reqs defined in azure.yaml - run 'azd app reqs --generate' to add them")
 	}
 
@@ -162,7 +173,7 @@ func executeReqs() error {
 			}
 		} else {
 			// Cache miss or invalid - perform fresh check
-			results, allSatisfied = performReqsCheck(azureYaml.Reqs)
+			results, allSatisfied = performReqsCheck(reqs)
 
 			// Save to cache
 			cacheResults := make([]cache.CachedReqResult, len(results))
@@ -184,7 +195,7 @@ func executeReqs() error {
 		}
 	} else {
 		// No cache available - perform fresh check
-		results, allSatisfied = performReqsCheck(azureYaml.Reqs)
+		results, allSatisfied = performReqsCheck(reqs)
 	}
 
 	// JSON output
@@ -192,6 +203,7 @@ func executeReqs() error {
 		return output.PrintJSON(map[string]interface{}{
 			"satisfied": allSatisfied,
 			"reqs":      results,
+			"schema":    issues,
 		})
 	}
 
@@ -240,92 +252,142 @@ func executeDeps() error {
 		searchRoot = filepath.Dir(azureYamlPath)
 	}
 
+	if sandboxMode {
+		return executeSandboxDeps(searchRoot)
+	}
+
+	// Failed installs are retried as the install retry policies of azure.yaml say
+	policies, err := newInstallPolicies(azureYamlPath)
+	if err != nil {
+		return err
+	}
+
+	// Installs stop when --timeout or --deadline passes; each unfinished install reports why
+	ctx, cancel := withTimeout(commandContext(), depsTimeout, "dependency installation")
+	defer cancel()
+
 	hasProjects := false
 	var results []map[string]interface{}
+	var names []string // Project of each result
+	var failures []*workerpool.TaskError
 
-	// Step 1: Find and install Node.js projects (search from azure.yaml directory)
+	// Detect all project types in a single pass over the azure.yaml directory
+	scan, err := detector.ScanWorkspace(searchRoot)
+	if err != nil {
+		scan = &detector.WorkspaceScan{}
+	}
+
+	// Step 1: Install Node.js projects
 	//nolint:dupl // Similar code pattern repeated for each project type for clarity
-	nodeProjects, err := detector.FindNodeProjects(searchRoot)
-	if err == nil && len(nodeProjects) > 0 {
+	nodeProjects := scan.NodeProjects
+	if len(nodeProjects) > 0 {
 		hasProjects = true
 		if !output.IsJSON() {
 			output.Step("📦", "Found %s Node.js project(s)", output.Count(len(nodeProjects)))
 		}
+		nodeDirs := make(map[string]bool, len(nodeProjects))
 		for _, nodeProject := range nodeProjects {
+			nodeDirs[nodeProject.Dir] = true
+		}
+		nodeResults := make([]map[string]interface{}, len(nodeProjects))
+		var tasks []workerpool.Task
+		for i, nodeProject := range nodeProjects {
 			result := map[string]interface{}{
 				"type":    "node",
 				"dir":     nodeProject.Dir,
 				"manager": nodeProject.PackageManager,
 			}
-			if err := installer.InstallNodeDependencies(nodeProject); err != nil {
+			nodeResults[i] = result
+			names = append(names, nodeProject.Dir)
+			// Workspace members are installed by the install at the workspace root
+			if nodeProject.WorkspaceRoot != "" && nodeDirs[nodeProject.WorkspaceRoot] {
 				if !output.IsJSON() {
-					output.ItemWarning("Failed to install for %s: %v", nodeProject.Dir, err)
+					output.Item("Skipping %s (installed with workspace %s)", nodeProject.Dir, nodeProject.WorkspaceRoot)
 				}
-				result["success"] = false
-				result["error"] = err.Error()
-			} else {
 				result["success"] = true
+				result["workspaceRoot"] = nodeProject.WorkspaceRoot
+				continue
 			}
-			results = append(results, result)
+			tasks = append(tasks, workerpool.Task{Name: nodeProject.Dir, Run: func(context.Context) error {
+				err := policies.install(ctx, nodeProject.Dir, func(ctx context.Context) error {
+					return installer.InstallNodeDependencies(ctx, nodeProject)
+				})
+				recordInstall(result, err, "Failed to install for %s: %v", nodeProject.Dir)
+				return err
+			}})
 		}
+		err := workerpool.Run(ctx, tasks, workerpool.Options{Workers: installWorkers()})
+		failures = append(failures, workerpool.Errors(err)...)
+		results = append(results, nodeResults...)
 		if !output.IsJSON() {
 			output.Newline()
 		}
 	}
 
-	// Step 2: Find and install Python projects (search from azure.yaml directory)
+	// Step 2: Install Python projects
 	//nolint:dupl // Similar code pattern repeated for each project type for clarity
-	pythonProjects, err := detector.FindPythonProjects(searchRoot)
-	if err == nil && len(pythonProjects) > 0 {
+	pythonProjects := scan.PythonProjects
+	if len(pythonProjects) > 0 {
 		hasProjects = true
 		if !output.IsJSON() {
 			output.Step("🐍", "Found %s Python project(s)", output.Count(len(pythonProjects)))
 		}
-		for _, pyProject := range pythonProjects {
+		pyResults := make([]map[string]interface{}, len(pythonProjects))
+		tasks := make([]workerpool.Task, 0, len(pythonProjects))
+		for i, pyProject := range pythonProjects {
 			result := map[string]interface{}{
 				"type":    "python",
 				"dir":     pyProject.Dir,
 				"manager": pyProject.PackageManager,
 			}
-			if err := installer.SetupPythonVirtualEnv(pyProject); err != nil {
-				if !output.IsJSON() {
-					output.ItemWarning("Failed to setup environment for %s: %v", pyProject.Dir, err)
-				}
-				result["success"] = false
-				result["error"] = err.Error()
-			} else {
-				result["success"] = true
-			}
-			results = append(results, result)
+			pyResults[i] = result
+			names = append(names, pyProject.Dir)
+			tasks = append(tasks, workerpool.Task{Name: pyProject.Dir, Run: func(context.Context) error {
+				err := policies.install(ctx, pyProject.Dir, func(ctx context.Context) error {
+					return installer.SetupPythonVirtualEnv(ctx, pyProject)
+				})
+				recordInstall(result, err, "Failed to setup environment for %s: %v", pyProject.Dir)
+				return err
+			}})
 		}
+		err := workerpool.Run(ctx, tasks, workerpool.Options{Workers: installWorkers()})
+		failures = append(failures, workerpool.Errors(err)...)
+		results = append(results, pyResults...)
 		if !output.IsJSON() {
 			output.Newline()
 		}
 	}
 
-	// Step 3: Find and install .NET projects (search from azure.yaml directory)
-	dotnetProjects, err := detector.FindDotnetProjects(searchRoot)
-	if err == nil && len(dotnetProjects) > 0 {
+	// Step 3: Restore .NET projects
+	dotnetProjects := scan.DotnetProjects
+	if scoped, err := findSolutionDotnetProjects(scan.Root); err == nil {
+		dotnetProjects = scoped
+	}
+	if len(dotnetProjects) > 0 {
 		hasProjects = true
 		if !output.IsJSON() {
 			output.Step("🔷", "Found %s .NET project(s)", output.Count(len(dotnetProjects)))
 		}
+		tasks := make([]workerpool.Task, 0, len(dotnetProjects))
 		for _, dotnetProject := range dotnetProjects {
 			result := map[string]interface{}{
 				"type": "dotnet",
 				"path": dotnetProject.Path,
 			}
-			if err := installer.RestoreDotnetProject(dotnetProject); err != nil {
-				if !output.IsJSON() {
-					output.ItemWarning("Failed to restore %s: %v", dotnetProject.Path, err)
-				}
-				result["success"] = false
-				result["error"] = err.Error()
-			} else {
-				result["success"] = true
-			}
 			results = append(results, result)
+			names = append(names, dotnetProject.Path)
+			tasks = append(tasks, workerpool.Task{Name: dotnetProject.Path, Run: func(context.Context) error {
+				err := policies.install(ctx, filepath.Dir(dotnetProject.Path), func(ctx context.Context) error {
+					return installer.RestoreDotnetProject(ctx, dotnetProject)
+				})
+				recordInstall(result, err, "Failed to restore %s: %v", dotnetProject.Path)
+				return err
+			}})
 		}
+		// Projects of a solution share referenced projects, whose obj folders a parallel
+		// restore would write at the same time
+		err := workerpool.Run(ctx, tasks, workerpool.Options{Workers: 1})
+		failures = append(failures, workerpool.Errors(err)...)
 		if !output.IsJSON() {
 			output.Newline()
 		}
@@ -343,25 +405,124 @@ func executeDeps() error {
 		return nil
 	}
 
+	// Every project is installed even when others fail; the summary reports each one
+	summary := newBatchSummary(names, failures)
+	var installErr error
+	if summary.Failed > 0 {
+		installErr = fmt.Errorf("failed to install dependencies: %w", summary.Err())
+	}
+
 	if output.IsJSON() {
-		// Check if any project failed
-		allSuccess := true
-		for _, result := range results {
-			if success, ok := result["success"].(bool); ok && !success {
-				allSuccess = false
-				break
-			}
-		}
-		return output.PrintJSON(map[string]interface{}{
-			"success":  allSuccess,
+		if err := output.PrintJSON(map[string]interface{}{
+			"success":  summary.Failed == 0,
 			"projects": results,
-		})
+			"summary":  summary,
+		}); err != nil {
+			return err
+		}
+		return installErr
 	}
 
+	if installErr != nil {
+		summary.print()
+		return installErr
+	}
 	output.Success("Dependencies installed successfully!")
 	return nil
 }
 
+// ConfigureNetworkRetry sets the retry policy of requests to remote services from the retry
+// section of the nearest azure.yaml. Without azure.yaml, or when it cannot be parsed, the
+// default policy is kept.
+func ConfigureNetworkRetry() error {
+	azureYamlPath, err := findAzureYaml()
+	if err != nil {
+		return nil
+	}
+	azureYaml, err := service.ParseAzureYaml(azureYamlPath)
+	if err != nil {
+		return nil
+	}
+	policy, err := service.NetworkRetryPolicy(azureYaml)
+	if err != nil {
+		return fmt.Errorf("invalid retry policy in azure.yaml: %w", err)
+	}
+	httpclient.SetDefaultRetry(policy)
+	return nil
+}
+
+// installPolicies are the install retry policies of a workspace.
+type installPolicies struct {
+	project  retry.Policy            // Policy of projects that are not a service
+	services map[string]retry.Policy // Policy of the project directory of each service
+}
+
+// newInstallPolicies resolves the install retry policies of the services in azure.yaml. Without
+// azure.yaml, or when it cannot be parsed, the default policy is used.
+func newInstallPolicies(azureYamlPath string) (*installPolicies, error) {
+	policies := &installPolicies{project: retry.DefaultInstall, services: make(map[string]retry.Policy)}
+	if azureYamlPath == "" {
+		return policies, nil
+	}
+	azureYaml, err := service.ParseAzureYaml(azureYamlPath)
+	if err != nil {
+		return policies, nil
+	}
+
+	resolved, err := service.ResolveRetryPolicies(azureYaml, "")
+	if err != nil {
+		return nil, fmt.Errorf("invalid retry policy in azure.yaml: %w", err)
+	}
+	policies.project = resolved.Install
+	for name, svc := range azureYaml.Services {
+		resolved, err := service.ResolveRetryPolicies(azureYaml, name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid retry policy in azure.yaml: %w", err)
+		}
+		dir := filepath.Join(filepath.Dir(azureYamlPath), service.GetServiceProjectDir(svc, "."))
+		policies.services[filepath.Clean(dir)] = resolved.Install
+	}
+	return policies, nil
+}
+
+// install runs the install of the project in dir, retrying it as the policy of the project says.
+// Errors caused by the end of ctx say why it ended.
+func (p *installPolicies) install(ctx context.Context, dir string, install func(ctx context.Context) error) error {
+	policy, ok := p.services[filepath.Clean(dir)]
+	if !ok {
+		policy = p.project
+	}
+	return retry.Do(ctx, policy, func(ctx context.Context, attempt int) error {
+		if attempt > 1 && !output.IsJSON() {
+			output.Item("Retrying %s (attempt %d of %d)", dir, attempt, policy.MaxAttempts)
+		}
+		return contextError(ctx, install(ctx))
+	})
+}
+
+// installWorkers returns the number of installs run at once. In human output each install
+// streams its tool's output to the terminal, so they run one at a time.
+func installWorkers() int {
+	if output.IsJSON() {
+		return 0
+	}
+	return 1
+}
+
+// recordInstall sets the outcome of an install in its JSON result, and prints the warning
+// format, with the project, if it failed.
+func recordInstall(result map[string]interface{}, err error, format string, project string) {
+	if err == nil {
+		result["success"] = true
+		return
+	}
+	if !output.IsJSON() {
+		output.ItemWarning(format, project, err)
+	}
+	result["success"] = false
+	result["error"] = err.Error()
+}
+
 // executeRun is the function executed by the orchestrator for the run command.
 // This ensures deps (and transitively reqs) are run before starting services.
 func executeRun() error {
@@ -412,7 +573,7 @@ func _runAzureYamlServices(azureYaml *service.AzureYaml, azureYamlPath string) e
 
 	// Orchestrate services (using empty env vars)
 	envVars := make(map[string]string)
-	result, err := service.OrchestrateServices(runtimes, envVars, logger)
+	result, err := service.OrchestrateServices(commandContext(), runtimes, envVars, logger)
 	if err != nil {
 		return fmt.Errorf("service orchestration failed: %w", err)
 	}
@@ -0,0 +1,224 @@
+package commands
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/jongio/azd-app/cli/src/internal/daemon"
+	"github.com/jongio/azd-app/cli/src/internal/output"
+	"github.com/jongio/azd-app/cli/src/internal/registry"
+	"github.com/jongio/azd-app/cli/src/internal/service"
+
+	"github.com/spf13/cobra"
+)
+
+// stopTimeout is how long stop waits for the background session to shut down before it is killed.
+const stopTimeout = 30 * time.Second
+
+// detachLogLines is the number of lines of the session log shown when it fails to start.
+const detachLogLines = 20
+
+// NewStatusCommand creates the status command.
+func NewStatusCommand() *cobra.Command {
+	return withYAMLOutput(&cobra.Command{
+		Use:   "status",
+		Short: "Show the background session started with 'run --detach'",
+		Long: `Shows whether a background session started with 'azd app run --detach' is running for the
+current workspace, with its PID, log file, and the status, PID, and URL of each service.
+With --output json or yaml, scripts and editor tasks can check the "running" field.`,
+		Args: cobra.NoArgs,
+		RunE: runStatus,
+	})
+}
+
+// NewStopCommand creates the stop command.
+func NewStopCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "stop",
+		Short: "Stop the background session started with 'run --detach'",
+		Long: `Stops the background session of the current workspace and its services, as Ctrl+C stops a
+session in the foreground. A session still running 30 seconds later is killed together with
+its services. On Windows, the session and its services are killed right away.`,
+		Args: cobra.NoArgs,
+		RunE: runStop,
+	}
+}
+
+// runDetached starts the run command in the background and waits until its services are ready.
+func runDetached() error {
+	// Ask about trust here, where there is a terminal; the session inherits the decision
+	if err := executeTrust(); err != nil {
+		return err
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+
+	// The session starts in the workspace directory, so a relative --workspace would not resolve
+	args := append(append([]string(nil), os.Args[1:]...), "--workspace", cwd)
+	state, err := daemon.Start(cwd, args)
+	if err != nil {
+		return err
+	}
+	output.Info("Starting background session (PID %d)...", state.PID)
+
+	ctx, stop := signal.NotifyContext(commandContext(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	ready, err := daemon.Wait(ctx, cwd, state.PID)
+	if err != nil {
+		if ctx.Err() != nil {
+			output.Warning("Stopped waiting; the session keeps starting in the background")
+			output.Item("Check it with 'azd app status', stop it with 'azd app stop'")
+			return nil
+		}
+		for _, line := range tailLines(state.LogFile, detachLogLines) {
+			fmt.Fprintln(output.ErrWriter(), line)
+		}
+		return fmt.Errorf("%w (log: %s)", err, state.LogFile)
+	}
+
+	if output.IsJSON() {
+		return output.PrintJSON(ready)
+	}
+	output.Success("Services are running in the background (PID %d)", ready.PID)
+	for _, svc := range ready.Services {
+		if svc.URL != "" {
+			output.Item("%s%s%s → %s", output.Cyan, output.PadRight(svc.Name, 15), output.Reset, svc.URL)
+		} else {
+			output.Item("%s%s%s", output.Cyan, svc.Name, output.Reset)
+		}
+	}
+	output.Label("Logs", ready.LogFile)
+	output.Newline()
+	output.Info("💡 Follow output with 'azd app logs -f', stop with 'azd app stop'")
+	return nil
+}
+
+// markDetachedReady records the services of a background session once they are ready.
+func markDetachedReady(cwd string, processes map[string]*service.ServiceProcess) {
+	if !daemon.IsChild() {
+		return
+	}
+	services := make([]daemon.Service, 0, len(processes))
+	for name, process := range processes {
+		svc := daemon.Service{Name: name, Port: process.Port}
+		if process.Process != nil {
+			svc.PID = process.Process.Pid
+		}
+		if process.Port > 0 {
+			svc.URL = fmt.Sprintf("http://localhost:%d", process.Port)
+		}
+		services = append(services, svc)
+	}
+	sort.Slice(services, func(i, j int) bool { return services[i].Name < services[j].Name })
+	if err := daemon.MarkReady(cwd, services); err != nil {
+		output.Warning("Failed to record background session: %v", err)
+	}
+}
+
+// statusSchemaVersion is the version of statusResult, raised when a change would break its readers.
+const statusSchemaVersion = 1
+
+// statusResult is the JSON and YAML output of the status command.
+type statusResult struct {
+	SchemaVersion int                              `json:"schemaVersion"`
+	Running       bool                             `json:"running"`
+	Session       *daemon.State                    `json:"session,omitempty"`
+	Services      []*registry.ServiceRegistryEntry `json:"services,omitempty"`
+}
+
+// runStatus executes the status command.
+func runStatus(cmd *cobra.Command, args []string) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+	state, err := daemon.Load(cwd)
+	if err != nil {
+		return err
+	}
+
+	result := statusResult{SchemaVersion: statusSchemaVersion, Running: state != nil, Session: state}
+	if state != nil {
+		// The registry has the current PIDs and health, also of services restarted since
+		result.Services = registry.GetRegistry(cwd).ListAll()
+		sort.Slice(result.Services, func(i, j int) bool { return result.Services[i].Name < result.Services[j].Name })
+	}
+
+	if output.IsStructured() {
+		return output.PrintStructured(result)
+	}
+	if state == nil {
+		output.Info("No background session is running")
+		output.Item("Start one with 'azd app run --detach'")
+		return nil
+	}
+
+	status := "starting"
+	if state.Ready {
+		status = "running"
+	}
+	output.Success("Background session %s (PID %d, up %s)", status, state.PID, time.Since(state.StartedAt).Round(time.Second))
+	output.Label("Command", "azd app "+strings.Join(state.Args, " "))
+	output.Label("Logs", state.LogFile)
+	output.Newline()
+	for _, svc := range result.Services {
+		output.Item("%s%s%s %-10s PID %-7d %s", output.Cyan, output.PadRight(svc.Name, 15), output.Reset, svc.Status, svc.PID, svc.URL)
+	}
+	return nil
+}
+
+// runStop executes the stop command.
+func runStop(cmd *cobra.Command, args []string) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+
+	if !output.IsJSON() {
+		if state, err := daemon.Load(cwd); err == nil && state != nil {
+			output.Info("Stopping background session (PID %d)...", state.PID)
+		}
+	}
+	state, err := daemon.Stop(cwd, stopTimeout)
+	if err != nil {
+		return err
+	}
+
+	if output.IsJSON() {
+		return output.PrintJSON(map[string]interface{}{"stopped": state != nil, "session": state})
+	}
+	if state == nil {
+		output.Info("No background session is running")
+		return nil
+	}
+	output.Success("Background session stopped")
+	return nil
+}
+
+// tailLines returns the last n lines of the file at path, or none when it can't be read.
+func tailLines(path string, n int) []string {
+	// #nosec G304 -- Log file in the workspace state directory
+	file, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if len(lines) == n {
+			lines = lines[1:]
+		}
+		lines = append(lines, scanner.Text())
+	}
+	return lines
+}
@@ -0,0 +1,208 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jongio/azd-app/cli/src/internal/output"
+	"github.com/jongio/azd-app/cli/src/internal/snapshot"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	dataProject string
+	dataVolumes []string
+)
+
+// NewDataCommand creates the data command.
+func NewDataCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "data",
+		Short: "Snapshot and restore local database volumes",
+		Long: `Saves and restores the Docker volumes of local containers (databases, emulators)
+so you can keep a known-good dataset and reset quickly after destructive testing.
+
+Snapshots are stored in the workspace state directory (see 'azd app cache'), outside the
+project.`,
+	}
+
+	cmd.AddCommand(
+		newDataSnapshotCommand(),
+		newDataRestoreCommand(),
+		newDataListCommand(),
+		newDataDeleteCommand(),
+	)
+
+	return cmd
+}
+
+// newDataSnapshotCommand creates the data snapshot subcommand.
+func newDataSnapshotCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "snapshot <name>",
+		Short: "Save the current contents of database volumes",
+		Long: `Archives every Docker volume of the Compose project (or the volumes given with --volume)
+into a named snapshot. Containers using a volume are paused while it is archived.`,
+		Args: cobra.ExactArgs(1),
+		RunE: runDataSnapshot,
+	}
+
+	cmd.Flags().StringVar(&dataProject, "project", "", "Docker Compose project name (defaults to the current directory name)")
+	cmd.Flags().StringSliceVar(&dataVolumes, "volume", nil, "Volume to include (repeatable; defaults to all project volumes)")
+
+	return cmd
+}
+
+// newDataRestoreCommand creates the data restore subcommand.
+func newDataRestoreCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "restore <name>",
+		Short: "Replace database volumes with a saved snapshot",
+		Long: `Replaces the contents of each volume in the snapshot with the saved data.
+Containers using a volume are stopped during the restore and started again afterwards.`,
+		Args: cobra.ExactArgs(1),
+		RunE: runDataRestore,
+	}
+}
+
+// newDataListCommand creates the data list subcommand.
+func newDataListCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List saved snapshots",
+		Args:  cobra.NoArgs,
+		RunE:  runDataList,
+	}
+}
+
+// newDataDeleteCommand creates the data delete subcommand.
+func newDataDeleteCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "delete <name>",
+		Short: "Delete a saved snapshot",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runDataDelete,
+	}
+}
+
+// runDataSnapshot executes the data snapshot command.
+func runDataSnapshot(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	if err := snapshot.ValidateName(name); err != nil {
+		return err
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+
+	project := dataProject
+	if project == "" {
+		project = snapshot.ComposeProjectName(cwd)
+	}
+
+	ctx := commandContext()
+	volumes := dataVolumes
+	if len(volumes) == 0 {
+		volumes, err = snapshot.ListVolumes(ctx, project)
+		if err != nil {
+			return err
+		}
+		if len(volumes) == 0 {
+			return fmt.Errorf("no docker volumes found for compose project '%s' (use --project or --volume)", project)
+		}
+	}
+
+	if !output.IsJSON() {
+		output.Section("💾", fmt.Sprintf("Saving snapshot '%s'", name))
+		output.Item("Volumes: %s", strings.Join(volumes, ", "))
+	}
+
+	manifest, err := snapshot.Save(ctx, cwd, name, project, volumes)
+	if err != nil {
+		return err
+	}
+
+	if output.IsJSON() {
+		return output.PrintJSON(manifest)
+	}
+	output.Success("Snapshot '%s' saved (%d volume(s))", name, len(manifest.Volumes))
+	return nil
+}
+
+// runDataRestore executes the data restore command.
+func runDataRestore(cmd *cobra.Command, args []string) error {
+	name := args[0]
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+
+	if !output.IsJSON() {
+		output.Section("♻️", fmt.Sprintf("Restoring snapshot '%s'", name))
+	}
+
+	manifest, err := snapshot.Restore(commandContext(), cwd, name)
+	if err != nil {
+		return err
+	}
+
+	if output.IsJSON() {
+		return output.PrintJSON(manifest)
+	}
+	output.Success("Restored %d volume(s) from '%s'", len(manifest.Volumes), name)
+	return nil
+}
+
+// runDataList executes the data list command.
+func runDataList(cmd *cobra.Command, args []string) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+
+	manifests, err := snapshot.List(cwd)
+	if err != nil {
+		return err
+	}
+
+	if output.IsJSON() {
+		if manifests == nil {
+			manifests = []*snapshot.Manifest{}
+		}
+		return output.PrintJSON(manifests)
+	}
+
+	if len(manifests) == 0 {
+		output.Info("No snapshots found")
+		output.Item("Create one with: azd app data snapshot <name>")
+		return nil
+	}
+
+	for _, m := range manifests {
+		output.Label(m.Name, fmt.Sprintf("%s  %s", m.Created.Local().Format("2006-01-02 15:04"), strings.Join(m.Volumes, ", ")))
+	}
+	return nil
+}
+
+// runDataDelete executes the data delete command.
+func runDataDelete(cmd *cobra.Command, args []string) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+
+	if err := snapshot.Delete(cwd, args[0]); err != nil {
+		return err
+	}
+
+	if output.IsJSON() {
+		return output.PrintJSON(map[string]interface{}{"deleted": args[0]})
+	}
+	output.Success("Snapshot '%s' deleted", args[0])
+	return nil
+}
@@ -0,0 +1,33 @@
+package commands
+
+import (
+	"testing"
+)
+
+func TestNewDataCommand(t *testing.T) {
+	cmd := NewDataCommand()
+
+	if cmd.Use != "data" {
+		t.Errorf("Use = %q, want %q", cmd.Use, "data")
+	}
+
+	for _, name := range []string{"snapshot", "restore", "list", "delete"} {
+		sub, _, err := cmd.Find([]string{name})
+		if err != nil || sub.Name() != name {
+			t.Errorf("Expected %q subcommand, got %v (err %v)", name, sub, err)
+		}
+	}
+
+	snapshotCmd, _, _ := cmd.Find([]string{"snapshot"})
+	for _, flag := range []string{"project", "volume"} {
+		if snapshotCmd.Flags().Lookup(flag) == nil {
+			t.Errorf("Expected snapshot flag %q to exist", flag)
+		}
+	}
+}
+
+func TestRunDataSnapshotRejectsInvalidName(t *testing.T) {
+	if err := runDataSnapshot(nil, []string{"../escape"}); err == nil {
+		t.Error("runDataSnapshot() expected error for invalid name")
+	}
+}
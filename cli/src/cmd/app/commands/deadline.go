@@ -0,0 +1,56 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// deadlineGrace is how long a command may take to tear down after --deadline passes before
+// the process exits anyway.
+const deadlineGrace = 30 * time.Second
+
+var (
+	// invocation is the context of the whole invocation, canceled when --deadline passes.
+	invocation       = context.Background()
+	invocationCancel context.CancelFunc
+)
+
+// SetDeadline bounds the invocation to d and returns the bounded context for the command.
+// Commands stop their work and tear down what they started when it is done. If the command
+// has not returned deadlineGrace later, the process exits.
+func SetDeadline(parent context.Context, d time.Duration) context.Context {
+	invocation, invocationCancel = context.WithTimeoutCause(parent, d, fmt.Errorf("deadline of %s exceeded", d))
+	time.AfterFunc(d+deadlineGrace, func() {
+		fmt.Fprintf(os.Stderr, "deadline of %s exceeded and the command did not stop within %s\n", d, deadlineGrace)
+		os.Exit(ExitFailure)
+	})
+	return invocation
+}
+
+// commandContext returns the context of the invocation, bounded by --deadline if set.
+func commandContext() context.Context {
+	return invocation
+}
+
+// withTimeout bounds ctx to timeout, if positive. The cause of a timeout says what timed out.
+func withTimeout(ctx context.Context, timeout time.Duration, what string) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeoutCause(ctx, timeout, fmt.Errorf("%s timed out after %s", what, timeout))
+}
+
+// contextError returns why ctx is done, such as an exceeded --deadline or --timeout, in place
+// of err when err was caused by ctx ending, e.g. a killed process. Other errors are returned as is.
+func contextError(ctx context.Context, err error) error {
+	if err == nil || ctx.Err() == nil {
+		return err
+	}
+	if cause := context.Cause(ctx); cause != nil && !errors.Is(err, cause) {
+		return cause
+	}
+	return err
+}
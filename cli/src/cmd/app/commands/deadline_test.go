@@ -0,0 +1,56 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithTimeout(t *testing.T) {
+	ctx, cancel := withTimeout(context.Background(), 0, "install")
+	defer cancel()
+	if _, ok := ctx.Deadline(); ok {
+		t.Error("a zero timeout should not set a deadline")
+	}
+
+	ctx, cancel = withTimeout(context.Background(), time.Millisecond, "install")
+	defer cancel()
+	<-ctx.Done()
+	if cause := context.Cause(ctx); cause == nil || cause.Error() != "install timed out after 1ms" {
+		t.Errorf("cause = %v, want the install timeout", cause)
+	}
+}
+
+func TestContextError(t *testing.T) {
+	killed := errors.New("signal: killed")
+
+	timedOut, cancel := withTimeout(context.Background(), time.Millisecond, "build")
+	defer cancel()
+	<-timedOut.Done()
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		err  error
+		want string
+	}{
+		{name: "no error", ctx: timedOut, err: nil, want: ""},
+		{name: "context still running", ctx: context.Background(), err: killed, want: "signal: killed"},
+		{name: "killed by timeout", ctx: timedOut, err: killed, want: "build timed out after 1ms"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := contextError(tt.ctx, tt.err)
+			got := ""
+			if err != nil {
+				got = err.Error()
+			}
+			if !strings.Contains(got, tt.want) || (tt.want == "") != (err == nil) {
+				t.Errorf("contextError() = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
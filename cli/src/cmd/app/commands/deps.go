@@ -1,7 +1,9 @@
 package commands
 
 import (
+	"context"
 	"fmt"
+	"time"
 
 	"github.com/jongio/azd-app/cli/src/internal/detector"
 	"github.com/jongio/azd-app/cli/src/internal/installer"
@@ -10,9 +12,12 @@ import (
 	"github.com/spf13/cobra"
 )
 
+// depsTimeout bounds dependency installation; 0 means no limit.
+var depsTimeout time.Duration
+
 // NewDepsCommand creates the deps command.
 func NewDepsCommand() *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "deps",
 		Short: "Install dependencies for all detected projects",
 		Long:  `Automatically detects and installs dependencies for Node.js (npm/pnpm/yarn), Python (uv/poetry/pip), and .NET projects`,
@@ -34,6 +39,10 @@ func NewDepsCommand() *cobra.Command {
 			return cmdOrchestrator.Run("deps")
 		},
 	}
+
+	cmd.Flags().DurationVar(&depsTimeout, "timeout", 0, "Maximum time for installing all dependencies, e.g. 10m (default: no limit)")
+
+	return cmd
 }
 
 // installNodeServiceDepsWithResult installs Node.js dependencies and returns structured result.
@@ -51,7 +60,7 @@ func _installNodeServiceDepsWithResult(serviceName, serviceDir string) (map[stri
 		output.Item("Installing: %s (%s)", serviceDir, packageManager)
 	}
 
-	err := installer.InstallNodeDependencies(nodeProject)
+	err := installer.InstallNodeDependencies(context.Background(), nodeProject)
 	result := map[string]interface{}{
 		"service": serviceName,
 		"type":    "node",
@@ -79,7 +88,7 @@ func _installPythonServiceDepsWithResult(serviceName, serviceDir string) (map[st
 		output.Item("%s (%s)", serviceDir, packageManager)
 	}
 
-	err := installer.SetupPythonVirtualEnv(pythonProject)
+	err := installer.SetupPythonVirtualEnv(context.Background(), pythonProject)
 	result := map[string]interface{}{
 		"service": serviceName,
 		"type":    "python",
@@ -93,10 +102,20 @@ func _installPythonServiceDepsWithResult(serviceName, serviceDir string) (map[st
 	return result, err
 }
 
+// findSolutionDotnetProjects finds .NET projects in dir. When dir contains solutions,
+// only the solutions and the projects they reference are returned.
+func findSolutionDotnetProjects(dir string) ([]types.DotnetProject, error) {
+	solutions, err := detector.FindDotnetSolutions(dir)
+	if err != nil {
+		return nil, err
+	}
+	return detector.FindDotnetProjects(dir, solutions...)
+}
+
 // installDotnetServiceDepsWithResult installs .NET dependencies and returns structured result.
 func _installDotnetServiceDepsWithResult(serviceName, serviceDir string) (map[string]interface{}, error) {
 	// Find .NET projects in the service directory
-	dotnetProjects, err := detector.FindDotnetProjects(serviceDir)
+	dotnetProjects, err := findSolutionDotnetProjects(serviceDir)
 	if err != nil || len(dotnetProjects) == 0 {
 		errMsg := fmt.Errorf("no .NET projects found in %s", serviceDir)
 		return map[string]interface{}{
@@ -115,7 +134,7 @@ func _installDotnetServiceDepsWithResult(serviceName, serviceDir string) (map[st
 
 	// Install dependencies for all .NET projects in the service directory
 	for _, dotnetProject := range dotnetProjects {
-		if err := installer.RestoreDotnetProject(dotnetProject); err != nil {
+		if err := installer.RestoreDotnetProject(context.Background(), dotnetProject); err != nil {
 			errResult := fmt.Errorf("failed to restore %s: %w", dotnetProject.Path, err)
 			return map[string]interface{}{
 				"service": serviceName,
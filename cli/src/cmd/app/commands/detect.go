@@ -0,0 +1,295 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jongio/azd-app/cli/src/internal/detector"
+	"github.com/jongio/azd-app/cli/src/internal/output"
+	"github.com/jongio/azd-app/cli/src/internal/service"
+
+	"github.com/spf13/cobra"
+)
+
+// Kinds of projects detect lists.
+const (
+	detectKindNode         = "node"
+	detectKindPython       = "python"
+	detectKindDotnet       = "dotnet"
+	detectKindSolution     = "solution"
+	detectKindDockerfile   = "dockerfile"
+	detectKindFunctions    = "functions"
+	detectKindStaticWebApp = "staticwebapp"
+)
+
+// detectSchemaVersion is the version of detectResult, raised when a change would break its readers.
+const detectSchemaVersion = 1
+
+var detectExplain bool
+
+// NewDetectCommand creates the detect command.
+func NewDetectCommand() *cobra.Command {
+	cmd := withYAMLOutput(&cobra.Command{
+		Use:   "detect",
+		Short: "List the projects the detectors find in the workspace",
+		Long: `Lists every project the detectors find in the workspace, the directory of azure.yaml or
+else the current directory: Node.js, Python, and .NET projects, solutions, Dockerfiles,
+Azure Functions apps, and Static Web Apps, with the azure.yaml service each one maps to.
+
+With --explain, each project also shows the file it was matched by, and the directories
+detection didn't descend into are listed with why: a dependency or build folder, a pattern
+of .gitignore, .azdignore, or detector.ignore, or a nested workspace with its own azure.yaml.`,
+		Args: cobra.NoArgs,
+		RunE: runDetect,
+	})
+	cmd.Flags().BoolVar(&detectExplain, "explain", false, "Show the file each project was matched by, and why skipped directories were skipped")
+	return cmd
+}
+
+// detectResult is the JSON and YAML output of the detect command.
+type detectResult struct {
+	SchemaVersion int                   `json:"schemaVersion"`
+	Root          string                `json:"root"`
+	AzureYaml     string                `json:"azureYaml,omitempty"`
+	Projects      []detectedProject     `json:"projects"`
+	Unmatched     []string              `json:"unmatchedServices,omitempty"` // Services of azure.yaml without a detected project
+	Skipped       []detector.SkippedDir `json:"skipped,omitempty"`           // With --explain, with relative paths
+}
+
+// detectedProject is a project a detector found. Paths are relative to the root.
+type detectedProject struct {
+	Kind           string   `json:"kind"`
+	Path           string   `json:"path"`
+	Framework      string   `json:"framework,omitempty"`
+	PackageManager string   `json:"packageManager,omitempty"`
+	Marker         string   `json:"marker"` // File the project was matched by
+	Services       []string `json:"services,omitempty"`
+}
+
+// runDetect executes the detect command.
+func runDetect(_ *cobra.Command, _ []string) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+	root := cwd
+	azureYamlPath, err := detector.FindAzureYaml(cwd)
+	if err != nil {
+		return fmt.Errorf("error searching for azure.yaml: %w", err)
+	}
+	if azureYamlPath != "" {
+		root = filepath.Dir(azureYamlPath)
+	}
+
+	scan, err := detector.ScanWorkspace(root)
+	if err != nil {
+		return fmt.Errorf("failed to scan workspace: %w", err)
+	}
+
+	result := detectResult{SchemaVersion: detectSchemaVersion, Root: scan.Root, AzureYaml: azureYamlPath, Projects: detectedProjects(scan)}
+	if azureYamlPath != "" {
+		azureYaml, err := service.ParseAzureYaml(azureYamlPath)
+		if err != nil {
+			output.Warning("Projects aren't mapped to services: %v", err)
+		} else {
+			result.Unmatched = mapServices(result.Projects, scan.Root, azureYaml.Services)
+		}
+	}
+	if detectExplain {
+		for _, skipped := range scan.Skipped {
+			skipped.Path = detectPath(scan.Root, skipped.Path)
+			if skipped.Reason == detector.SkipNestedWorkspace {
+				skipped.Rule = detectPath(scan.Root, skipped.Rule)
+			}
+			if skipped.Source != "" {
+				skipped.Source = detectPath(scan.Root, skipped.Source)
+			}
+			result.Skipped = append(result.Skipped, skipped)
+		}
+	}
+
+	if output.IsStructured() {
+		return output.PrintStructured(result)
+	}
+	printDetectResult(result)
+	return nil
+}
+
+// detectedProjects returns every project of scan, sorted by path and kind.
+func detectedProjects(scan *detector.WorkspaceScan) []detectedProject {
+	projects := []detectedProject{}
+	add := func(kind, path, framework, packageManager, marker string) {
+		projects = append(projects, detectedProject{
+			Kind:           kind,
+			Path:           detectPath(scan.Root, path),
+			Framework:      framework,
+			PackageManager: packageManager,
+			Marker:         detectPath(scan.Root, marker),
+		})
+	}
+
+	for _, p := range scan.NodeProjects {
+		add(detectKindNode, p.Dir, p.Framework, p.PackageManager, filepath.Join(p.Dir, "package.json"))
+	}
+	for _, p := range scan.PythonProjects {
+		add(detectKindPython, p.Dir, p.Framework, p.PackageManager, pythonMarker(p.Dir))
+	}
+	for _, p := range scan.DotnetProjects {
+		if detector.IsDotnetSolution(p.Path) {
+			add(detectKindSolution, p.Path, "", "", p.Path)
+			continue
+		}
+		framework := ""
+		if p.IsAppHost {
+			framework = "Aspire"
+		}
+		add(detectKindDotnet, filepath.Dir(p.Path), framework, "", p.Path)
+	}
+	for _, d := range scan.Dockerfiles {
+		add(detectKindDockerfile, filepath.Dir(d.Path), "", "", d.Path)
+	}
+	for _, f := range scan.Functions {
+		add(detectKindFunctions, f.Dir, f.Runtime, "", functionsMarker(f.Dir))
+	}
+	for _, a := range scan.StaticWebApps {
+		add(detectKindStaticWebApp, a.Dir, a.Framework, "", filepath.Join(a.Dir, a.Source))
+	}
+
+	sort.SliceStable(projects, func(i, j int) bool {
+		if projects[i].Path != projects[j].Path {
+			return projects[i].Path < projects[j].Path
+		}
+		return projects[i].Kind < projects[j].Kind
+	})
+	return projects
+}
+
+// pythonMarker returns the first file of dir that marks it as a Python project, as the walk
+// visits them.
+func pythonMarker(dir string) string {
+	entries, _ := os.ReadDir(dir)
+	for _, entry := range entries {
+		if !entry.IsDir() && detector.IsPythonProjectFile(entry.Name()) {
+			return filepath.Join(dir, entry.Name())
+		}
+	}
+	return dir
+}
+
+// functionsMarker returns the file that marks dir as an Azure Functions app.
+func functionsMarker(dir string) string {
+	for _, name := range []string{"host.json", "function_app.py"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			return filepath.Join(dir, name)
+		}
+	}
+	return dir
+}
+
+// mapServices sets the services of azure.yaml whose project is the directory or project file
+// of each project, and returns the services that match no project, sorted.
+func mapServices(projects []detectedProject, root string, services map[string]service.Service) []string {
+	var unmatched []string
+	for name, svc := range services {
+		if svc.Project == "" {
+			continue
+		}
+		target := detectPath(root, svc.Project)
+		matched := false
+		for i := range projects {
+			if projects[i].Path == target || projects[i].Marker == target {
+				projects[i].Services = append(projects[i].Services, name)
+				matched = true
+			}
+		}
+		if !matched {
+			unmatched = append(unmatched, name)
+		}
+	}
+	for i := range projects {
+		sort.Strings(projects[i].Services)
+	}
+	sort.Strings(unmatched)
+	return unmatched
+}
+
+// detectPath returns path relative to root with forward slashes, or path when it isn't below
+// root.
+func detectPath(root, path string) string {
+	return filepath.ToSlash(relativePath(root, path))
+}
+
+// printDetectResult prints the projects, and with --explain why they were matched and which
+// directories were skipped.
+func printDetectResult(result detectResult) {
+	output.Section("🔍", fmt.Sprintf("Projects in %s", result.Root))
+	if len(result.Projects) == 0 {
+		output.Info("No projects found")
+	} else if !detectExplain {
+		table := output.NewTable("KIND", "PATH", "FRAMEWORK", "SERVICE")
+		for _, p := range result.Projects {
+			table.AddRow(p.Kind, p.Path, detectDetails(p), strings.Join(p.Services, ", "))
+		}
+		table.Print()
+	} else {
+		for _, p := range result.Projects {
+			line := fmt.Sprintf("%s %s", p.Kind, output.Highlight("%s", p.Path))
+			if details := detectDetails(p); details != "" {
+				line += " " + output.Muted("(%s)", details)
+			}
+			output.Item("%s", line)
+			output.Item("  matched by %s", p.Marker)
+			if len(p.Services) > 0 {
+				output.Item("  service %s in azure.yaml", strings.Join(p.Services, ", "))
+			} else if result.AzureYaml != "" {
+				output.Item("  %s", output.Muted("no service in azure.yaml"))
+			}
+		}
+	}
+
+	if len(result.Unmatched) > 0 {
+		output.Newline()
+		output.Warning("No project found for the azure.yaml services %s", strings.Join(result.Unmatched, ", "))
+	}
+
+	if !detectExplain {
+		output.Newline()
+		output.Info("💡 Use --explain to see why projects were matched and directories skipped")
+		return
+	}
+	output.Section("🚫", "Skipped directories")
+	if len(result.Skipped) == 0 {
+		output.Info("No directories were skipped")
+		return
+	}
+	for _, s := range result.Skipped {
+		output.Item("%s %s", s.Path, output.Muted("%s", skipReason(s)))
+	}
+}
+
+// detectDetails returns the framework and package manager of p.
+func detectDetails(p detectedProject) string {
+	var details []string
+	for _, d := range []string{p.Framework, p.PackageManager} {
+		if d != "" {
+			details = append(details, d)
+		}
+	}
+	return strings.Join(details, ", ")
+}
+
+// skipReason describes why the walk skipped s.
+func skipReason(s detector.SkippedDir) string {
+	switch s.Reason {
+	case detector.SkipBuiltIn:
+		return "dependency, build, or VCS folder"
+	case detector.SkipIgnored:
+		return fmt.Sprintf("ignored by %q in %s", s.Rule, s.Source)
+	case detector.SkipNestedWorkspace:
+		return fmt.Sprintf("nested workspace with its own %s, which owns its projects", s.Rule)
+	}
+	return s.Reason
+}
@@ -0,0 +1,60 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/jongio/azd-app/cli/src/internal/detector"
+	"github.com/jongio/azd-app/cli/src/internal/service"
+)
+
+func TestDetectedProjects(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"web/package.json": `{"name": "web"}`,
+		"api/Api.csproj":   `<Project Sdk="Microsoft.NET.Sdk.Web"><PropertyGroup><TargetFramework>net8.0</TargetFramework></PropertyGroup></Project>`,
+		"worker/setup.py":  "",
+		"worker/Pipfile":   "",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	scan, err := detector.ScanWorkspace(root)
+	if err != nil {
+		t.Fatalf("ScanWorkspace() error = %v", err)
+	}
+	projects := detectedProjects(scan)
+
+	var got [][2]string
+	for _, p := range projects {
+		got = append(got, [2]string{p.Path, p.Marker})
+	}
+	// The first Python file the walk visits marks the project
+	want := [][2]string{{"api", "api/Api.csproj"}, {"web", "web/package.json"}, {"worker", "worker/Pipfile"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("projects = %v, want %v", got, want)
+	}
+
+	unmatched := mapServices(projects, root, map[string]service.Service{
+		"api":   {Project: filepath.Join(root, "api", "Api.csproj")},
+		"web":   {Project: filepath.Join(root, "web")},
+		"site":  {Project: filepath.Join(root, "web")},
+		"gone":  {Project: filepath.Join(root, "gone")},
+		"infra": {},
+	})
+	if !reflect.DeepEqual(unmatched, []string{"gone"}) {
+		t.Errorf("unmatched = %v, want [gone]", unmatched)
+	}
+	if !reflect.DeepEqual(projects[0].Services, []string{"api"}) || !reflect.DeepEqual(projects[1].Services, []string{"site", "web"}) || projects[2].Services != nil {
+		t.Errorf("services = %v, %v, %v", projects[0].Services, projects[1].Services, projects[2].Services)
+	}
+}
@@ -0,0 +1,159 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/jongio/azd-app/cli/src/internal/container"
+	"github.com/jongio/azd-app/cli/src/internal/detector"
+	"github.com/jongio/azd-app/cli/src/internal/doctor"
+	"github.com/jongio/azd-app/cli/src/internal/output"
+
+	"github.com/spf13/cobra"
+)
+
+// NewDoctorCommand creates the doctor command.
+func NewDoctorCommand() *cobra.Command {
+	return withYAMLOutput(&cobra.Command{
+		Use:   "doctor",
+		Short: "Check that the tools the workspace needs are installed",
+		Long: `Checks the tools the projects of the workspace need, in the versions they pin: node and
+its package manager from the engines of package.json, the .NET SDK from global.json or the
+target frameworks, Python from requires-python or .python-version, a running container
+engine for Dockerfiles, Azure Functions Core Tools for function apps, and azd.
+
+Each missing or mismatched tool is reported with how to install or update it, and the
+command exits with code 1, so a CI job can run it before anything else.`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE:         runDoctor,
+	})
+}
+
+// runDoctor executes the doctor command.
+func runDoctor(_ *cobra.Command, _ []string) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+	workspace := cwd
+	if azureYamlPath, err := detector.FindAzureYaml(cwd); err == nil && azureYamlPath != "" {
+		workspace = filepath.Dir(azureYamlPath)
+	}
+
+	report, err := doctor.Run(workspace, localToolchain())
+	if err != nil {
+		return err
+	}
+	if output.IsStructured() {
+		if err := output.PrintStructured(report); err != nil {
+			return err
+		}
+	} else {
+		printDoctorReport(report)
+	}
+	if report.Failed > 0 {
+		return fmt.Errorf("%d of %d tool checks failed", report.Failed, len(report.Checks))
+	}
+	return nil
+}
+
+// localToolchain finds the tools installed on this machine, as reqs does.
+func localToolchain() doctor.Toolchain {
+	return doctor.Toolchain{
+		Version: func(tool string) (string, bool) {
+			installed, version := getInstalledVersion(Prerequisite{Name: tool})
+			// Many Linux and macOS machines only have python3
+			if !installed && tool == doctor.ToolPython {
+				installed, version = getInstalledVersion(Prerequisite{Name: "python3", Args: []string{"--version"}, Command: "python3", VersionField: 1})
+			}
+			return version, installed
+		},
+		DotnetSDKs: installedDotnetSDKs,
+		ContainerEngine: func() (string, error) {
+			engine, err := container.Current()
+			if err != nil {
+				return "", err
+			}
+			return engine.String(), engine.Check(context.Background())
+		},
+	}
+}
+
+// installedDotnetSDKs returns the versions dotnet --list-sdks lists, e.g. "8.0.404".
+func installedDotnetSDKs() []string {
+	// #nosec G204 -- Fixed command and arguments
+	out, err := exec.Command("dotnet", "--list-sdks").Output()
+	if err != nil {
+		return nil
+	}
+	var sdks []string
+	for _, line := range strings.Split(string(out), "\n") {
+		// "8.0.404 [/usr/share/dotnet/sdk]"
+		if fields := strings.Fields(line); len(fields) > 0 {
+			sdks = append(sdks, fields[0])
+		}
+	}
+	return sdks
+}
+
+// printDoctorReport prints each check, and how to fix the failed ones.
+func printDoctorReport(report doctor.Report) {
+	output.Section("🩺", "Workspace prerequisites")
+
+	table := output.NewTable("TOOL", "REQUIRED", "INSTALLED", "STATUS", "SOURCE")
+	for _, c := range report.Checks {
+		required := c.Constraint
+		if required == "" {
+			required = "any"
+		}
+		if c.RollForward != "" {
+			required += " (rollForward " + c.RollForward + ")"
+		}
+		installed := c.Version
+		if installed == "" {
+			installed = "-"
+		}
+		table.AddRow(c.Tool, required, installed, doctorStatus(c.Status), c.Source)
+	}
+	table.Print()
+
+	var problems []doctor.Check
+	for _, c := range report.Checks {
+		if c.Status != doctor.StatusOK {
+			problems = append(problems, c)
+		}
+	}
+	if len(problems) == 0 {
+		output.Newline()
+		output.Success("Every tool the workspace needs is installed")
+		return
+	}
+	output.Section("💡", "Fixes")
+	for _, c := range problems {
+		line := c.Message
+		if c.Fix != "" {
+			line += " " + output.Muted("→ %s", c.Fix)
+		}
+		if c.Failed() {
+			output.ItemError("%s", line)
+		} else {
+			output.ItemWarning("%s", line)
+		}
+	}
+}
+
+// doctorStatus returns the status of a check as shown in the table.
+func doctorStatus(status string) string {
+	switch status {
+	case doctor.StatusOK:
+		return output.Green + "✓ ok" + output.Reset
+	case doctor.StatusUnchecked:
+		return output.Yellow + "? " + status + output.Reset
+	}
+	return output.Red + "✗ " + status + output.Reset
+}
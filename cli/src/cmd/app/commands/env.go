@@ -0,0 +1,106 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/jongio/azd-app/cli/src/internal/envhistory"
+	"github.com/jongio/azd-app/cli/src/internal/output"
+
+	"github.com/spf13/cobra"
+)
+
+var envHistoryKey string
+
+// NewEnvCommand creates the env command.
+func NewEnvCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "env",
+		Short: "Inspect service environment variables",
+	}
+
+	cmd.AddCommand(newEnvHistoryCommand())
+
+	return cmd
+}
+
+// newEnvHistoryCommand creates the env history subcommand.
+func newEnvHistoryCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "history <service>",
+		Short: "Show how a service's environment changed across runs",
+		Long: `Shows when environment variables of a service appeared, changed, or were removed
+across 'azd app run' invocations, to help track down configuration drift.
+
+Values are stored as hashes keyed per machine in the workspace state directory (see
+'azd app cache'), never in plain text. Hashes of secret-looking keys (tokens, passwords,
+keys, connection strings) are not shown.`,
+		Args: cobra.ExactArgs(1),
+		RunE: runEnvHistory,
+	}
+
+	cmd.Flags().StringVar(&envHistoryKey, "key", "", "Only show changes to this variable")
+
+	return cmd
+}
+
+// runEnvHistory executes the env history command.
+func runEnvHistory(cmd *cobra.Command, args []string) error {
+	serviceName := args[0]
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+
+	history, err := envhistory.Load(cwd, serviceName)
+	if err != nil {
+		return err
+	}
+	entries := history.Entries(envHistoryKey)
+
+	if output.IsJSON() {
+		if entries == nil {
+			entries = []envhistory.Entry{}
+		}
+		return output.PrintJSON(map[string]interface{}{
+			"service": serviceName,
+			"entries": entries,
+		})
+	}
+
+	if len(entries) == 0 {
+		output.Info("No environment history for '%s'", serviceName)
+		output.Item("History is recorded each time 'azd app run' starts the service")
+		return nil
+	}
+
+	output.Section("🕰️", fmt.Sprintf("Environment history for %s", serviceName))
+	for _, entry := range entries {
+		output.Label(entry.Time.Local().Format("2006-01-02 15:04:05"), fmt.Sprintf("%d change(s)", len(entry.Changes)))
+		for _, change := range entry.Changes {
+			printEnvChange(change)
+		}
+	}
+	return nil
+}
+
+// printEnvChange prints a single environment change with hashes unless the key is secret.
+func printEnvChange(change envhistory.Change) {
+	detail := ""
+	switch {
+	case change.Secret:
+		detail = " [redacted]"
+	case change.Kind == envhistory.Changed:
+		detail = fmt.Sprintf(" (%s → %s)", change.OldHash, change.NewHash)
+	}
+
+	switch change.Kind {
+	case envhistory.Added:
+		output.ItemSuccess("+ %s%s", change.Key, detail)
+	case envhistory.Removed:
+		output.ItemError("- %s%s", change.Key, detail)
+	default:
+		output.ItemWarning("~ %s%s", change.Key, detail)
+	}
+}
@@ -0,0 +1,30 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/jongio/azd-app/cli/src/internal/output"
+
+	"github.com/spf13/cobra"
+)
+
+// yamlOutputAnnotation marks the commands that print their results as YAML with --output yaml.
+// The others only print JSON, so they reject it rather than printing nothing on stdout.
+const yamlOutputAnnotation = "output-yaml"
+
+// withYAMLOutput marks cmd as printing its results as YAML with --output yaml.
+func withYAMLOutput(cmd *cobra.Command) *cobra.Command {
+	if cmd.Annotations == nil {
+		cmd.Annotations = make(map[string]string)
+	}
+	cmd.Annotations[yamlOutputAnnotation] = "true"
+	return cmd
+}
+
+// CheckOutputFormat returns an error when the output format is YAML and cmd can't print it.
+func CheckOutputFormat(cmd *cobra.Command) error {
+	if output.GetFormat() != output.FormatYAML || cmd.Annotations[yamlOutputAnnotation] != "" {
+		return nil
+	}
+	return fmt.Errorf("'azd %s' doesn't support --output yaml; use --output json", cmd.CommandPath())
+}
@@ -0,0 +1,40 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/jongio/azd-app/cli/src/internal/output"
+
+	"github.com/spf13/cobra"
+)
+
+func TestCheckOutputFormat(t *testing.T) {
+	defer output.SetFormat("default") // Reset
+
+	tests := []struct {
+		cmd     *cobra.Command
+		wantErr bool
+	}{
+		{NewDetectCommand(), false},
+		{NewDoctorCommand(), false},
+		{NewGraphCommand(), false},
+		{NewStatusCommand(), false},
+		{NewVersionCommand(), false},
+		{NewRunCommand(), true},
+	}
+	for _, tt := range tests {
+		if err := output.SetFormat("yaml"); err != nil {
+			t.Fatal(err)
+		}
+		if err := CheckOutputFormat(tt.cmd); (err != nil) != tt.wantErr {
+			t.Errorf("CheckOutputFormat(%s) error = %v, wantErr %v", tt.cmd.Name(), err, tt.wantErr)
+		}
+		// Every command prints JSON
+		if err := output.SetFormat("json"); err != nil {
+			t.Fatal(err)
+		}
+		if err := CheckOutputFormat(tt.cmd); err != nil {
+			t.Errorf("CheckOutputFormat(%s) with json error = %v", tt.cmd.Name(), err)
+		}
+	}
+}
@@ -57,8 +57,10 @@ func runGenerate(config GenerateConfig) error {
 		output.Item("Supported project types:")
 		output.Item("  • Node.js (package.json)")
 		output.Item("  • Python (requirements.txt, pyproject.toml)")
-		output.Item("  • .NET (.csproj, .sln)")
+		output.Item("  • .NET (.csproj, .fsproj, .vbproj, .sln)")
 		output.Item("  • .NET Aspire (AppHost.cs)")
+		output.Item("  • Azure Functions (host.json)")
+		output.Item("  • Azure Static Web Apps (staticwebapp.config.json, swa-cli.config.json)")
 		output.Item("  • Docker Compose (docker-compose.yml or package.json scripts)")
 		output.Newline()
 		output.Item("Make sure you're in a valid project directory.")
@@ -71,6 +73,9 @@ func runGenerate(config GenerateConfig) error {
 	// Display detected reqs with versions
 	displayDetectedReqs(requirements)
 
+	// Suggest Static Web Apps hosting for frontend-only projects
+	displayStaticWebAppCandidates(config.WorkingDir)
+
 	// Find or create azure.yaml
 	azureYamlPath, created, err := findOrCreateAzureYaml(config.WorkingDir, config.DryRun)
 	if err != nil {
@@ -159,6 +164,22 @@ func detectProjectReqs(projectDir string) ([]DetectedRequirement, error) {
 		}
 	}
 
+	// Detect Azure Functions apps, which run with Azure Functions Core Tools
+	if hasFunctionProject(projectDir) {
+		foundSources["Azure Functions"] = true
+		if req := detectTool("func", "host.json"); req.Name != "" {
+			requirements = append(requirements, req)
+		}
+	}
+
+	// Detect Static Web Apps configured for the SWA CLI, which emulates them locally
+	if source := staticWebAppConfigSource(projectDir); source != "" {
+		foundSources["Static Web Apps"] = true
+		if req := detectTool("swa", source); req.Name != "" {
+			requirements = append(requirements, req)
+		}
+	}
+
 	// Detect Docker
 	if hasDockerConfig(projectDir) {
 		foundSources["Docker"] = true
@@ -218,8 +239,25 @@ func hasAspireProject(dir string) bool {
 	return aspireProject != nil
 }
 
+func hasFunctionProject(dir string) bool {
+	projects, _ := detector.FindFunctionProjects(dir)
+	return len(projects) > 0
+}
+
+// staticWebAppConfigSource returns the SWA config file of the first Static Web App in dir
+// that has one, or "" if no app is configured for Static Web Apps.
+func staticWebAppConfigSource(dir string) string {
+	apps, _ := detector.FindStaticWebApps(dir)
+	for _, app := range apps {
+		if app.Source != "package.json" {
+			return filepath.Base(app.Source)
+		}
+	}
+	return ""
+}
+
 func hasDockerConfig(dir string) bool {
-	files := []string{"Dockerfile", "docker-compose.yml", "docker-compose.yaml", "compose.yml", "compose.yaml"}
+	files := []string{"Dockerfile", "Containerfile", "docker-compose.yml", "docker-compose.yaml", "compose.yml", "compose.yaml"}
 	for _, file := range files {
 		path := filepath.Join(dir, file)
 		if err := security.ValidatePath(path); err != nil {
@@ -569,6 +607,10 @@ func displayDetectedDependencies(requirements []DetectedRequirement) {
 			if !sources[".NET Aspire project"] {
 				sources[".NET project"] = true
 			}
+		} else if strings.Contains(req.Source, "host.json") {
+			sources["Azure Functions app"] = true
+		} else if strings.Contains(req.Source, "staticwebapp.config.json") || strings.Contains(req.Source, "swa-cli.config.json") {
+			sources["Azure Static Web App"] = true
 		} else if strings.Contains(req.Source, "docker") || strings.Contains(req.Source, "Dockerfile") {
 			sources["Docker configuration"] = true
 		} else if strings.Contains(req.Source, "requirements.txt") || strings.Contains(req.Source, "pyproject.toml") {
@@ -594,6 +636,28 @@ func displayDetectedDependencies(requirements []DetectedRequirement) {
 	output.Newline()
 }
 
+// displayStaticWebAppCandidates lists frontends that azure.yaml can host on Static Web Apps.
+func displayStaticWebAppCandidates(projectDir string) {
+	apps, err := detector.FindStaticWebApps(projectDir)
+	if err != nil || len(apps) == 0 {
+		return
+	}
+
+	output.Item("Static Web Apps candidates (host: %s):", detector.HostStaticWebApp)
+	for _, app := range apps {
+		project := "."
+		if rel, err := filepath.Rel(projectDir, filepath.Join(app.Dir, app.AppLocation)); err == nil && rel != "." {
+			project = "./" + filepath.ToSlash(rel)
+		}
+		details := fmt.Sprintf("project: %s, dist: %s", project, app.OutputLocation)
+		if app.Framework != "" {
+			details += fmt.Sprintf(" (%s)", app.Framework)
+		}
+		output.ItemSuccess("%s", details)
+	}
+	output.Newline()
+}
+
 func displayDetectedReqs(reqs []DetectedRequirement) {
 	hasUninstalled := false
 	installedCount := 0
@@ -0,0 +1,167 @@
+package commands
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/jongio/azd-app/cli/src/internal/output"
+	"github.com/jongio/azd-app/cli/src/internal/service"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	graphFormatText = "text"
+	graphFormatDot  = "dot"
+	graphFormatJSON = "json"
+)
+
+var graphFormat string
+
+// NewGraphCommand creates the graph command.
+func NewGraphCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "graph",
+		Short: "Show the dependency graph of the services in azure.yaml",
+		Long: `Shows which services and resources each service depends on, where each dependency was
+found, and the order in which run starts the services.
+
+Dependencies are collected from uses and dependsOn in azure.yaml, WithReference and WaitFor
+calls in an Aspire AppHost, and environment variables in azure.yaml or a service's .env file
+that refer to another service: SERVICE_<NAME>_URL and the other variables azd sets, URLs whose
+host is the service name, and connection strings such as Host=db;Port=5432. A discovered
+dependency that would create a cycle is skipped.
+
+Use --format dot to render the graph with Graphviz, e.g. azd app graph --format dot | dot -Tsvg.`,
+		Args: cobra.NoArgs,
+		RunE: runGraph,
+	}
+	withYAMLOutput(cmd)
+
+	cmd.Flags().StringVar(&graphFormat, "format", graphFormatText, "Output format (text, dot, json)")
+
+	return cmd
+}
+
+// graphSchemaVersion is the version of graphResult, raised when a change would break its readers.
+const graphSchemaVersion = 1
+
+// graphResult is the JSON and YAML output of the graph command.
+type graphResult struct {
+	SchemaVersion int                  `json:"schemaVersion"`
+	AzureYaml     string               `json:"azureYaml"`
+	Services      []string             `json:"services"`
+	Resources     []string             `json:"resources"`
+	Dependencies  []service.Dependency `json:"dependencies"`
+	StartOrder    [][]string           `json:"startOrder"`
+}
+
+// runGraph executes the graph command.
+func runGraph(cmd *cobra.Command, args []string) error {
+	format := graphFormat
+	if output.IsStructured() {
+		format = graphFormatJSON
+	}
+	if format != graphFormatText && format != graphFormatDot && format != graphFormatJSON {
+		return fmt.Errorf("invalid --format value: %s (must be %s, %s, or %s)", format, graphFormatText, graphFormatDot, graphFormatJSON)
+	}
+
+	azureYamlPath, err := findAzureYaml()
+	if err != nil {
+		return err
+	}
+	azureYaml, err := service.ParseAzureYaml(azureYamlPath)
+	if err != nil {
+		return fmt.Errorf("failed to parse azure.yaml: %w", err)
+	}
+	graph, deps, err := service.BuildServiceGraph(azureYaml, filepath.Dir(azureYamlPath))
+	if err != nil {
+		return fmt.Errorf("failed to build service dependency graph: %w", err)
+	}
+
+	result := graphResult{
+		SchemaVersion: graphSchemaVersion,
+		AzureYaml:     azureYamlPath,
+		Services:      sortedKeys(azureYaml.Services),
+		Resources:     sortedKeys(azureYaml.Resources),
+		Dependencies:  deps,
+		StartOrder:    service.TopologicalSort(graph),
+	}
+	if result.Dependencies == nil {
+		result.Dependencies = []service.Dependency{}
+	}
+
+	switch format {
+	case graphFormatJSON:
+		return output.PrintStructured(result)
+	case graphFormatDot:
+		fmt.Print(formatGraphDot(result))
+		return nil
+	default:
+		printGraph(result)
+		return nil
+	}
+}
+
+// printGraph prints the dependencies of each service and resource, and the start order.
+func printGraph(result graphResult) {
+	output.Section("🔗", "Service dependencies")
+	for _, name := range append(append([]string{}, result.Services...), result.Resources...) {
+		var uses []string
+		for _, dep := range result.Dependencies {
+			if dep.From == name && dep.Skipped == "" {
+				uses = append(uses, fmt.Sprintf("%s (%s)", dep.To, describeDependency(dep)))
+			}
+		}
+		if len(uses) == 0 {
+			output.Item("%s", name)
+		} else {
+			output.Item("%s → %s", name, strings.Join(uses, ", "))
+		}
+	}
+	for _, dep := range result.Dependencies {
+		if dep.Skipped != "" {
+			output.ItemWarning("%s → %s (%s) skipped: %s", dep.From, dep.To, describeDependency(dep), dep.Skipped)
+		}
+	}
+
+	if len(result.StartOrder) > 0 {
+		output.Newline()
+		output.Step("🚀", "Start order")
+		for i, level := range result.StartOrder {
+			output.Item("%d. %s", i+1, strings.Join(level, ", "))
+		}
+	}
+}
+
+// describeDependency returns where a dependency was found, e.g. "env API_URL".
+func describeDependency(dep service.Dependency) string {
+	if dep.Detail == "" {
+		return dep.Source
+	}
+	return dep.Source + " " + dep.Detail
+}
+
+// formatGraphDot returns the graph in the Graphviz DOT language. Edges point from a service to
+// the services and resources it depends on; skipped dependencies are dotted.
+func formatGraphDot(result graphResult) string {
+	var b strings.Builder
+	b.WriteString("digraph services {\n")
+	b.WriteString("  rankdir=LR;\n")
+	for _, name := range result.Services {
+		fmt.Fprintf(&b, "  %q;\n", name)
+	}
+	for _, name := range result.Resources {
+		fmt.Fprintf(&b, "  %q [shape=cylinder];\n", name)
+	}
+	for _, dep := range result.Dependencies {
+		if dep.Skipped != "" {
+			fmt.Fprintf(&b, "  %q -> %q [label=%q, style=dotted];\n", dep.From, dep.To, describeDependency(dep)+" (skipped)")
+		} else {
+			fmt.Fprintf(&b, "  %q -> %q [label=%q];\n", dep.From, dep.To, describeDependency(dep))
+		}
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
@@ -0,0 +1,33 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/jongio/azd-app/cli/src/internal/service"
+)
+
+func TestFormatGraphDot(t *testing.T) {
+	result := graphResult{
+		Services:  []string{"api", "web"},
+		Resources: []string{"db"},
+		Dependencies: []service.Dependency{
+			{From: "api", To: "db", Source: service.DependencySourceAzureYaml, Detail: "uses"},
+			{From: "web", To: "api", Source: service.DependencySourceEnv, Detail: "API_URL"},
+			{From: "api", To: "web", Source: service.DependencySourceEnv, Skipped: "would create a dependency cycle"},
+		},
+	}
+
+	want := `digraph services {
+  rankdir=LR;
+  "api";
+  "web";
+  "db" [shape=cylinder];
+  "api" -> "db" [label="azure.yaml uses"];
+  "web" -> "api" [label="env API_URL"];
+  "api" -> "web" [label="env (skipped)", style=dotted];
+}
+`
+	if got := formatGraphDot(result); got != want {
+		t.Errorf("formatGraphDot() =\n%s\nwant\n%s", got, want)
+	}
+}
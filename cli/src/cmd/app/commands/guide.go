@@ -0,0 +1,172 @@
+package commands
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jongio/azd-app/cli/src/internal/guide"
+	"github.com/jongio/azd-app/cli/src/internal/output"
+	"github.com/jongio/azd-app/cli/src/internal/service"
+)
+
+// shouldRunGuided reports whether 'azd app run' should walk the user through setup
+// instead of starting right away: when --guided is set, or when the user accepts the
+// offer on the first run of a template. Never prompts outside an interactive terminal.
+func shouldRunGuided(azureYamlPath string, prompter *guide.Prompter) (bool, error) {
+	interactive := guide.IsInteractive() && !output.IsJSON()
+	if runGuided {
+		if !interactive {
+			return false, fmt.Errorf("--guided requires an interactive terminal")
+		}
+		return true, nil
+	}
+	if !interactive || runDryRun || !guide.IsFirstRun(filepath.Dir(azureYamlPath)) {
+		return false, nil
+	}
+
+	output.Info("👋 This looks like the first run of this app.")
+	return prompter.Confirm("Walk through setup step by step?", true), nil
+}
+
+// runGuidedSetup shows detected services, missing tools with install commands, and
+// environment variables that need values, then starts the app if the user agrees.
+func runGuidedSetup(azureYamlPath string, prompter *guide.Prompter) error {
+	projectDir := filepath.Dir(azureYamlPath)
+
+	azureYaml, err := service.ParseAzureYaml(projectDir)
+	if err != nil {
+		return fmt.Errorf("failed to parse azure.yaml: %w", err)
+	}
+
+	// Step 1: What was detected
+	output.Section("🔍", "Step 1 of 4: Detected services")
+	if !service.HasServices(azureYaml) {
+		return showNoServicesMessage()
+	}
+	services, err := filterServices(azureYaml, projectDir)
+	if err != nil {
+		return err
+	}
+	runtimes, err := detectServiceRuntimes(services, projectDir, runtimeModeAzd)
+	if err != nil {
+		return err
+	}
+	sort.Slice(runtimes, func(i, j int) bool { return runtimes[i].Name < runtimes[j].Name })
+	for _, rt := range runtimes {
+		output.Step("📦", "%s", rt.Name)
+		output.Label("Language", rt.Language)
+		if rt.Framework != "" && rt.Framework != rt.Language {
+			output.Label("Framework", rt.Framework)
+		}
+		output.Label("Command", strings.TrimSpace(rt.Command+" "+strings.Join(rt.Args, " ")))
+		if rt.Port > 0 {
+			output.Label("Port", fmt.Sprintf("%d", rt.Port))
+		}
+	}
+
+	// Step 2: Tools
+	output.Section("🧰", "Step 2 of 4: Required tools")
+	if err := guideTools(azureYamlPath, projectDir, prompter); err != nil {
+		return err
+	}
+
+	// Step 3: Environment variables
+	output.Section("🔑", "Step 3 of 4: Environment variables")
+	if err := guideEnvVars(projectDir, azureYaml, prompter); err != nil {
+		return err
+	}
+
+	// Step 4: Start
+	output.Section("🚀", "Step 4 of 4: Start the app")
+	if !prompter.Confirm("Install dependencies and start the app now?", true) {
+		output.Info("💡 Run 'azd app run' when you're ready")
+		return nil
+	}
+
+	if err := cmdOrchestrator.Run("run"); err != nil {
+		return fmt.Errorf("failed to execute command dependencies: %w", err)
+	}
+	return runServicesFromAzureYaml(azureYamlPath, runRuntime)
+}
+
+// guideTools checks the reqs in azure.yaml, offering to generate them when none are defined,
+// and shows install commands for missing tools. Returns an error if tools are missing and
+// the user chooses not to continue.
+func guideTools(azureYamlPath, projectDir string, prompter *guide.Prompter) error {
+	reqs, err := loadReqs(azureYamlPath)
+	if err != nil {
+		return err
+	}
+	if len(reqs) == 0 {
+		output.Info("No reqs are defined in azure.yaml")
+		if !prompter.Confirm("Detect the tools this app needs and add them to azure.yaml?", true) {
+			return fmt.Errorf("no reqs defined in azure.yaml - run 'azd app reqs --generate' to add them")
+		}
+		if err := runGenerate(GenerateConfig{WorkingDir: projectDir}); err != nil {
+			return err
+		}
+		if reqs, err = loadReqs(azureYamlPath); err != nil {
+			return err
+		}
+	}
+
+	missing := 0
+	for _, req := range reqs {
+		result := checkPrerequisiteWithResult(req)
+		if result.Satisfied {
+			continue
+		}
+		missing++
+		// Tools that are installed but not running need to be started, not installed
+		if command := guide.InstallCommand(req.Name); command != "" && !result.CheckedRun {
+			output.Item("  Install: %s", command)
+		}
+	}
+	if missing == 0 {
+		output.Success("All required tools are installed")
+		return nil
+	}
+
+	output.Newline()
+	if !prompter.Confirm(fmt.Sprintf("%d tool(s) need attention. Continue anyway?", missing), false) {
+		return fmt.Errorf("%d required tool(s) missing - install them and run 'azd app run' again", missing)
+	}
+	return nil
+}
+
+// guideEnvVars asks for values of environment variables the app expects but that are not set,
+// and saves them to .env in the project, which every run loads.
+func guideEnvVars(projectDir string, azureYaml *service.AzureYaml, prompter *guide.Prompter) error {
+	envPath := filepath.Join(projectDir, ".env")
+
+	vars, err := guide.MissingEnvVars(projectDir, azureYaml)
+	if err != nil {
+		return err
+	}
+	if len(vars) == 0 {
+		output.Success("All expected environment variables are set")
+		return nil
+	}
+
+	output.Info("These variables have no value yet. Press Enter to accept the default or skip.")
+	values := make(map[string]string)
+	for _, v := range vars {
+		value := prompter.Ask(fmt.Sprintf("  %s (from %s)", v.Name, v.Source), v.Default)
+		if value == "" {
+			output.ItemWarning("%s left unset", v.Name)
+			continue
+		}
+		values[v.Name] = value
+	}
+
+	if err := guide.AppendEnvFile(envPath, values); err != nil {
+		return err
+	}
+	if len(values) > 0 {
+		output.Success("Saved %d value(s) to %s", len(values), envPath)
+		output.Item("Every 'azd app run' of this project loads them")
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jongio/azd-app/cli/src/internal/helptopics"
+	"github.com/jongio/azd-app/cli/src/internal/output"
+
+	"github.com/spf13/cobra"
+)
+
+var helpTopicsSearch string
+
+// NewHelpCommand creates the help command, which shows the help of commands like the default
+// help command of cobra, and the extended help topics compiled into the binary.
+func NewHelpCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "help [command]",
+		Short: "Help about any command, and help topics",
+		Long: `Shows the help of any command, e.g. 'help run'.
+
+Use 'help topics' to read the concepts, configuration reference, and troubleshooting guides
+that are compiled into azd app, so they can be read without network access.`,
+		ValidArgsFunction: func(c *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			var completions []string
+			cmd, _, e := c.Root().Find(args)
+			if e != nil {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			if cmd == nil {
+				cmd = c.Root()
+			}
+			for _, sub := range cmd.Commands() {
+				if sub.IsAvailableCommand() || sub == c {
+					if strings.HasPrefix(sub.Name(), toComplete) {
+						completions = append(completions, fmt.Sprintf("%s\t%s", sub.Name(), sub.Short))
+					}
+				}
+			}
+			return completions, cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(c *cobra.Command, args []string) error {
+			cmd, _, e := c.Root().Find(args)
+			if cmd == nil || e != nil {
+				return fmt.Errorf("unknown help topic %q; list topics with 'azd app help topics'", strings.Join(args, " "))
+			}
+			cmd.InitDefaultHelpFlag()
+			return cmd.Help()
+		},
+	}
+
+	topics := &cobra.Command{
+		Use:   "topics [name]",
+		Short: "List, read, and search the help topics",
+		Long: `Lists the help topics by category, shows the topic named, or, with --search, the topics
+that contain every word searched for, best first, with the lines that match.`,
+		Args:         cobra.MaximumNArgs(1),
+		SilenceUsage: true,
+		ValidArgsFunction: func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) > 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			var names []string
+			for _, topic := range helptopics.All() {
+				if strings.HasPrefix(topic.Name, toComplete) {
+					names = append(names, topic.Name+"\t"+topic.Title)
+				}
+			}
+			return names, cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: runHelpTopics,
+	}
+	topics.Flags().StringVarP(&helpTopicsSearch, "search", "s", "", "Show the topics that contain these words")
+
+	cmd.AddCommand(topics)
+	return cmd
+}
+
+// runHelpTopics executes the help topics command.
+func runHelpTopics(cmd *cobra.Command, args []string) error {
+	switch {
+	case helpTopicsSearch != "":
+		if len(args) > 0 {
+			return fmt.Errorf("--search can't be used with a topic name")
+		}
+		return printTopicSearch(helpTopicsSearch)
+	case len(args) == 1:
+		topic, err := helptopics.Get(args[0])
+		if err != nil {
+			if similar := helptopics.Similar(args[0]); len(similar) > 0 {
+				return fmt.Errorf("%w; did you mean %s?", err, strings.Join(similar, ", "))
+			}
+			return fmt.Errorf("%w; search with 'azd app help topics --search %s'", err, args[0])
+		}
+		if output.IsJSON() {
+			return output.PrintJSON(topic)
+		}
+		printTopic(topic)
+		return nil
+	default:
+		topics := helptopics.All()
+		if output.IsJSON() {
+			return output.PrintJSON(topics)
+		}
+		printTopicList(topics)
+		return nil
+	}
+}
+
+// printTopicList prints the topics by category.
+func printTopicList(topics []helptopics.Topic) {
+	for _, category := range helptopics.Categories {
+		table := output.NewTable("TOPIC", "SUMMARY")
+		for _, topic := range topics {
+			if topic.Category == category {
+				table.AddRow(topic.Name, topic.Summary)
+			}
+		}
+		output.Section("📖", strings.ToUpper(category[:1])+category[1:])
+		table.Print()
+	}
+	output.Newline()
+	output.Info("Read a topic with 'azd app help topics <name>', or search with --search <words>")
+}
+
+// printTopic prints the Markdown of a topic, with its headings highlighted.
+func printTopic(topic helptopics.Topic) {
+	w := output.Writer()
+	_, _ = fmt.Fprintf(w, "%s%s%s\n\n", output.Bold, topic.Title, output.Reset)
+	for _, line := range strings.Split(topic.Body, "\n") {
+		if heading, ok := strings.CutPrefix(line, "## "); ok {
+			_, _ = fmt.Fprintf(w, "%s%s%s%s\n", output.Bold, output.Cyan, heading, output.Reset)
+			continue
+		}
+		_, _ = fmt.Fprintln(w, line)
+	}
+}
+
+// printTopicSearch prints the topics that match query, with the lines that match.
+func printTopicSearch(query string) error {
+	matches := helptopics.Search(query)
+	if output.IsJSON() {
+		if matches == nil {
+			matches = []helptopics.Match{}
+		}
+		return output.PrintJSON(matches)
+	}
+	if len(matches) == 0 {
+		output.Warning("No help topic contains %q; list topics with 'azd app help topics'", query)
+		return nil
+	}
+	for _, match := range matches {
+		output.Section("📖", fmt.Sprintf("%s - %s", match.Name, match.Title))
+		output.Item("%s", match.Summary)
+		for _, snippet := range match.Snippets {
+			output.Item("%s", output.Muted("%s", snippet))
+		}
+	}
+	output.Newline()
+	output.Info("Read a topic with 'azd app help topics <name>'")
+	return nil
+}
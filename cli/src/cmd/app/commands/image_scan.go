@@ -0,0 +1,145 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/jongio/azd-app/cli/src/internal/audit"
+	"github.com/jongio/azd-app/cli/src/internal/container"
+	"github.com/jongio/azd-app/cli/src/internal/imagebuild"
+	"github.com/jongio/azd-app/cli/src/internal/output"
+	"github.com/jongio/azd-app/cli/src/internal/statedir"
+
+	"github.com/spf13/cobra"
+)
+
+// maxFailingFindings is how many findings at or above the threshold are listed per image.
+const maxFailingFindings = 10
+
+// imageScanFlags are the flags of commands that scan the images they build.
+type imageScanFlags struct {
+	enabled  bool
+	severity string
+	scanner  string
+}
+
+// register adds the scan flags to cmd.
+func (f *imageScanFlags) register(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&f.enabled, "scan", false, "Scan each image for vulnerabilities with Trivy or Grype once it is built")
+	cmd.Flags().StringVar(&f.severity, "scan-severity", "high", "With --scan, fail on vulnerabilities of this severity or above: low, medium, high, or critical")
+	cmd.Flags().StringVar(&f.scanner, "scanner", "", "With --scan, the scanner to run: trivy or grype (default: the one installed, trivy first)")
+}
+
+// imageScanner scans the images built by a command, one at a time, since scanners lock their
+// vulnerability database.
+type imageScanner struct {
+	mu        sync.Mutex
+	name      string
+	path      string
+	threshold string
+	engine    container.Engine
+	outDir    string
+}
+
+// newImageScanner returns the scanner of f for images built on engine, or nil without --scan.
+func (f *imageScanFlags) newImageScanner(workspace string, engine container.Engine) (*imageScanner, error) {
+	if !f.enabled {
+		if f.scanner != "" {
+			return nil, fmt.Errorf("--scanner can only be used with --scan")
+		}
+		return nil, nil
+	}
+	threshold, err := audit.ParseSeverity(f.severity)
+	if err != nil {
+		return nil, fmt.Errorf("--scan-severity: %w", err)
+	}
+	name, path, err := audit.FindScanner(f.scanner)
+	if err != nil {
+		return nil, err
+	}
+	outDir, err := statedir.DataDir(workspace, "audit")
+	if err != nil {
+		return nil, err
+	}
+	return &imageScanner{name: name, path: path, threshold: threshold, engine: engine, outDir: outDir}, nil
+}
+
+// scan scans the image of a successful build. The error reports an image that couldn't be
+// scanned or has vulnerabilities at or above the threshold.
+func (s *imageScanner) scan(ctx context.Context, built imagebuild.Result) (*audit.ImageResult, error) {
+	if !built.Loaded && !built.Pushed {
+		return nil, fmt.Errorf("the multi-arch image of %s is only in the build cache and can't be scanned; scan it with 'azd app images push --scan'", built.Service)
+	}
+	if !output.IsJSON() {
+		output.Step("🛡️", "Scanning %s with %s...", built.Image, s.name)
+	}
+	s.mu.Lock()
+	result, err := audit.ScanImage(ctx, s.name, s.path, s.engine.Host, built.Service, built.Image, s.outDir)
+	s.mu.Unlock()
+	if err != nil {
+		return nil, fmt.Errorf("scan failed: %w", err)
+	}
+	result.Threshold = s.threshold
+	if failing := result.Failing(); len(failing) > 0 {
+		return result, fmt.Errorf("%d vulnerabilities of severity %s or above in %s", len(failing), strings.ToLower(s.threshold), built.Image)
+	}
+	return result, nil
+}
+
+// completedScans returns the results of the scans that ran.
+func completedScans(scans []*audit.ImageResult) []*audit.ImageResult {
+	var completed []*audit.ImageResult
+	for _, scan := range scans {
+		if scan != nil {
+			completed = append(completed, scan)
+		}
+	}
+	return completed
+}
+
+// printImageScans prints the vulnerabilities found in each image, and those at or above the
+// threshold.
+func printImageScans(results []*audit.ImageResult) {
+	output.Newline()
+	table := output.NewTable("SERVICE", "CRITICAL", "HIGH", "MEDIUM", "LOW", "RESULT")
+	for _, result := range results {
+		if result == nil {
+			continue
+		}
+		verdict := "passed"
+		if !result.Passed() {
+			verdict = "failed"
+		}
+		table.AddRow(result.Service,
+			fmt.Sprint(result.Counts[audit.SeverityCritical]),
+			fmt.Sprint(result.Counts[audit.SeverityHigh]),
+			fmt.Sprint(result.Counts[audit.SeverityMedium]),
+			fmt.Sprint(result.Counts[audit.SeverityLow]),
+			verdict)
+	}
+	table.Print()
+
+	for _, result := range results {
+		if result == nil || result.Passed() {
+			continue
+		}
+		failing := result.Failing()
+		output.Section("🛡️", fmt.Sprintf("%s: %d vulnerabilities of severity %s or above", result.Service, len(failing), strings.ToLower(result.Threshold)))
+		for i, f := range failing {
+			if i == maxFailingFindings {
+				output.Item("... and %d more", len(failing)-i)
+				break
+			}
+			fixed := "no fix"
+			if f.FixedVersion != "" {
+				fixed = "fixed in " + f.FixedVersion
+			}
+			output.ItemError("%s %s: %s %s (%s)", f.Severity, f.ID, f.Package, f.Version, fixed)
+		}
+		if result.Report != "" {
+			output.Item("Report: %s", result.Report)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/jongio/azd-app/cli/src/internal/audit"
+	"github.com/jongio/azd-app/cli/src/internal/azureyaml"
+	"github.com/jongio/azd-app/cli/src/internal/container"
+	"github.com/jongio/azd-app/cli/src/internal/detector"
+	"github.com/jongio/azd-app/cli/src/internal/imagebuild"
+	"github.com/jongio/azd-app/cli/src/internal/output"
+	"github.com/jongio/azd-app/cli/src/internal/service"
+	"github.com/jongio/azd-app/cli/src/internal/statedir"
+	"github.com/jongio/azd-app/cli/src/internal/workerpool"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	imagesEnvironment string
+	imagesPlatforms   []string
+	imagesTag         string
+	imagesScan        imageScanFlags
+)
+
+// NewImagesCommand creates the images command.
+func NewImagesCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "images",
+		Short: "Push the container images of services to the registry of the azd environment",
+	}
+
+	push := &cobra.Command{
+		Use:   "push [service...]",
+		Short: "Build the images of services and push them to the registry of the azd environment",
+		Long: `Builds the container image of every service with a Dockerfile, or of the services named, and
+pushes it to the container registry that 'azd provision' created for the azd environment,
+read from AZURE_CONTAINER_REGISTRY_ENDPOINT in .azure/<environment>/.env. The engine signs in
+to the registry with 'az acr login'.
+
+Images are pushed to the repository azd deploys to, <project>/<service>-<environment>, tagged
+with the short SHA of the git commit, with a -dirty suffix when the working tree has changes,
+or with --tag. Each image is recorded as SERVICE_<NAME>_IMAGE_NAME in the azd environment, so
+'azd provision' and 'azd deploy' use the image that was pushed.
+
+Images are built like 'azd app build', for the platforms of azure.yaml or --platform. With
+--scan, each image is scanned for vulnerabilities once it is pushed, and an image with
+vulnerabilities of --scan-severity or above isn't recorded, so 'azd deploy' doesn't use it.`,
+		RunE: runImagesPush,
+	}
+	push.Flags().StringVarP(&imagesEnvironment, "environment", "e", "", "azd environment whose registry images are pushed to (default: AZURE_ENV_NAME, or the environment selected with 'azd env select')")
+	push.Flags().StringSliceVar(&imagesPlatforms, "platform", nil, "Platforms to build for, e.g. linux/amd64,linux/arm64 (default: from azure.yaml)")
+	push.Flags().StringVar(&imagesTag, "tag", "", "Tag of the images (default: the short SHA of the git commit)")
+	imagesScan.register(push)
+
+	cmd.AddCommand(push)
+	return cmd
+}
+
+// imagesPushOutput is the JSON output of the images push command.
+type imagesPushOutput struct {
+	Environment string               `json:"environment"`
+	Registry    string               `json:"registry"`
+	Tag         string               `json:"tag"`
+	Results     []imagebuild.Result  `json:"results"`
+	Scans       []*audit.ImageResult `json:"scans,omitempty"`
+	Summary     batchSummary         `json:"summary"`
+}
+
+// runImagesPush executes the images push command.
+func runImagesPush(cmd *cobra.Command, args []string) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+	azureYamlPath, err := detector.FindAzureYaml(cwd)
+	if err != nil {
+		return fmt.Errorf("failed to find azure.yaml: %w", err)
+	}
+	if azureYamlPath == "" {
+		return fmt.Errorf("azure.yaml not found in %s or parent directories", cwd)
+	}
+	workspace := filepath.Dir(azureYamlPath)
+
+	environment := service.AzdEnvironmentName(workspace, imagesEnvironment)
+	if environment == "" {
+		return fmt.Errorf("no azd environment; create one with 'azd env new' or pass --environment")
+	}
+	values, err := service.LoadAzdEnvironment(workspace, environment)
+	if err != nil {
+		return err
+	}
+	registry, err := imagebuild.RegistryEndpoint(values)
+	if err != nil {
+		return fmt.Errorf("azd environment %s: %w", environment, err)
+	}
+
+	ctx := commandContext()
+	tag := imagesTag
+	if tag == "" {
+		if tag, err = imagebuild.GitTag(ctx, workspace); err != nil {
+			return err
+		}
+	}
+
+	doc, err := azureyaml.Load(azureYamlPath)
+	if err != nil {
+		return err
+	}
+	targets, cache, err := imagebuild.LoadTargets(workspace, imagesPlatforms)
+	if err != nil {
+		return err
+	}
+	if targets, err = selectBuildTargets(targets, args); err != nil {
+		return err
+	}
+	if len(targets) == 0 {
+		return fmt.Errorf("no service in azure.yaml has a Dockerfile, or a language that buildpacks build")
+	}
+	for i := range targets {
+		targets[i].Image = fmt.Sprintf("%s/%s:%s", registry, imagebuild.Repository(doc.Project.Name, targets[i].Service, environment), tag)
+		targets[i].Push = true
+	}
+
+	// Dockerfiles and buildpacks run commands of the workspace during the build
+	if err := requireWorkspaceTrust(workspace); err != nil {
+		return err
+	}
+	engine, err := container.Current()
+	if err != nil {
+		return err
+	}
+	if err := engine.Check(ctx); err != nil {
+		return err
+	}
+	if err := imagebuild.EnsureBuilder(ctx, engine); err != nil {
+		return err
+	}
+	if err := imagebuild.Login(ctx, registry); err != nil {
+		return err
+	}
+	scanner, err := imagesScan.newImageScanner(workspace, engine)
+	if err != nil {
+		return err
+	}
+	logDir, err := statedir.DataDir(workspace, "build")
+	if err != nil {
+		return fmt.Errorf("failed to create log directory: %w", err)
+	}
+
+	results := make([]imagebuild.Result, len(targets))
+	progress := buildProgress()
+	scans := make([]*audit.ImageResult, len(targets))
+	tasks := make([]workerpool.Task, len(targets))
+	names := make([]string, len(targets))
+	for i, target := range targets {
+		names[i] = target.Service
+		tasks[i] = workerpool.Task{Name: target.Service, Run: func(ctx context.Context) error {
+			if !output.IsJSON() {
+				output.Step("📦", "Pushing %s to %s...", target.Service, target.Image)
+			}
+			results[i] = imagebuild.Build(ctx, engine, target, cache, filepath.Join(logDir, target.Service+".log"), progress(target.Service))
+			if results[i].Error != "" {
+				return errors.New(results[i].Error)
+			}
+			if scanner != nil {
+				scan, err := scanner.scan(ctx, results[i])
+				scans[i] = scan
+				if err != nil {
+					return err
+				}
+			}
+			return imagebuild.RecordImage(ctx, workspace, environment, target.Service, target.Image)
+		}}
+	}
+	err = workerpool.Run(ctx, tasks, workerpool.Options{Workers: 1})
+
+	summary := newBatchSummary(names, workerpool.Errors(err))
+	if output.IsJSON() {
+		out := imagesPushOutput{Environment: environment, Registry: registry, Tag: tag, Results: results, Scans: completedScans(scans), Summary: summary}
+		if err := output.PrintJSON(out); err != nil {
+			return err
+		}
+	} else {
+		printBuildResults(results)
+		if scanner != nil {
+			printImageScans(scans)
+		}
+		summary.print()
+	}
+	if err != nil {
+		return fmt.Errorf("push failed for %d of %d services: %w", summary.Failed, summary.Total, err)
+	}
+	if !output.IsJSON() {
+		output.Success("Recorded the images in azd environment %s; 'azd deploy' uses them", environment)
+	}
+	return nil
+}
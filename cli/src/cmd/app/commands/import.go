@@ -0,0 +1,206 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jongio/azd-app/cli/src/internal/detector"
+	"github.com/jongio/azd-app/cli/src/internal/output"
+	"github.com/jongio/azd-app/cli/src/internal/security"
+	"github.com/jongio/azd-app/cli/src/internal/service"
+	"github.com/jongio/azd-app/cli/src/internal/yamlutil"
+
+	"github.com/spf13/cobra"
+)
+
+var importWrite bool
+
+// NewImportCommand creates the import command.
+func NewImportCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "import [compose-file]",
+		Short: "Import Docker Compose services into azure.yaml",
+		Long: `Maps the services of a Docker Compose file to azure.yaml services and resources.
+Without an argument, compose.yaml, compose.yml, docker-compose.yaml, or docker-compose.yml
+in the current directory is used.
+
+Services built from source become container app services: the build context becomes the
+project, dockerfile and build args become docker settings, the first published port becomes
+config.port, environment becomes env, and depends_on becomes uses. Services running a
+postgres, redis, or mongo image become resources. Other prebuilt images are skipped.
+
+Without --write the mapped services and their start order are only shown. With --write they
+are added to azure.yaml, creating it if needed; existing services and resources are kept.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: runImport,
+	}
+
+	cmd.Flags().BoolVar(&importWrite, "write", false, "Add the imported services and resources to azure.yaml")
+
+	return cmd
+}
+
+// importResult is the JSON output of the import command.
+type importResult struct {
+	ComposeFile    string                      `json:"composeFile"`
+	AzureYaml      string                      `json:"azureYaml"`
+	Services       map[string]service.Service  `json:"services"`
+	Resources      map[string]service.Resource `json:"resources"`
+	Skipped        map[string]string           `json:"skipped"`
+	StartOrder     [][]string                  `json:"startOrder"`
+	Written        bool                        `json:"written"`
+	AddedServices  int                         `json:"addedServices"`
+	AddedResources int                         `json:"addedResources"`
+}
+
+// runImport executes the import command.
+func runImport(cmd *cobra.Command, args []string) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+
+	composePath := ""
+	if len(args) > 0 {
+		composePath, err = filepath.Abs(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid compose file path: %w", err)
+		}
+	} else if composePath = detector.FindComposeFile(cwd); composePath == "" {
+		return fmt.Errorf("no compose.yaml or docker-compose.yml found in %s", cwd)
+	}
+
+	azureYamlPath := filepath.Join(cwd, "azure.yaml")
+	if existing, err := detector.FindAzureYaml(cwd); err == nil && existing != "" {
+		azureYamlPath = existing
+	}
+
+	project, err := detector.ParseComposeFile(composePath)
+	if err != nil {
+		return err
+	}
+	imported, err := service.ImportCompose(project, filepath.Dir(azureYamlPath))
+	if err != nil {
+		return err
+	}
+
+	result := importResult{
+		ComposeFile: composePath,
+		AzureYaml:   azureYamlPath,
+		Services:    imported.Services,
+		Resources:   imported.Resources,
+		Skipped:     imported.Skipped,
+		StartOrder:  service.TopologicalSort(imported.Graph),
+	}
+
+	if importWrite {
+		result.AddedServices, result.AddedResources, err = writeComposeImport(azureYamlPath, imported)
+		if err != nil {
+			return err
+		}
+		result.Written = true
+	}
+
+	if output.IsJSON() {
+		return output.PrintJSON(result)
+	}
+	return printImportResult(cwd, result)
+}
+
+// writeComposeImport adds the imported services and resources to azure.yaml, creating it if it
+// does not exist, and returns how many of each were added. Existing entries are not changed.
+func writeComposeImport(azureYamlPath string, imported *service.ComposeImport) (int, int, error) {
+	if err := security.ValidatePath(azureYamlPath); err != nil {
+		return 0, 0, fmt.Errorf("invalid path: %w", err)
+	}
+
+	content := fmt.Sprintf("name: %s\n", filepath.Base(filepath.Dir(azureYamlPath)))
+	// #nosec G304 -- Path validated by security.ValidatePath
+	data, err := os.ReadFile(azureYamlPath)
+	if err == nil {
+		content = string(data)
+	} else if !os.IsNotExist(err) {
+		return 0, 0, fmt.Errorf("failed to read azure.yaml: %w", err)
+	}
+
+	content, addedServices, err := yamlutil.AppendToMapSection(content, "services", mapEntries(imported.Services))
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to add services to azure.yaml: %w", err)
+	}
+	content, addedResources, err := yamlutil.AppendToMapSection(content, "resources", mapEntries(imported.Resources))
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to add resources to azure.yaml: %w", err)
+	}
+
+	if addedServices+addedResources > 0 {
+		// #nosec G306 -- azure.yaml is a config file, 0644 is appropriate for team access
+		if err := os.WriteFile(azureYamlPath, []byte(content), 0644); err != nil {
+			return 0, 0, fmt.Errorf("failed to write azure.yaml: %w", err)
+		}
+	}
+	return addedServices, addedResources, nil
+}
+
+// printImportResult prints the imported services, resources, skipped services, and start order.
+func printImportResult(cwd string, result importResult) error {
+	output.Section("🐳", fmt.Sprintf("Imported %s", relPath(cwd, result.ComposeFile)))
+	for _, name := range sortedKeys(result.Services) {
+		svc := result.Services[name]
+		details := []string{svc.Project}
+		if port, ok := svc.Config["port"]; ok {
+			details = append(details, fmt.Sprintf("port %v", port))
+		}
+		if len(svc.Uses) > 0 {
+			details = append(details, "uses "+strings.Join(svc.Uses, ", "))
+		}
+		output.ItemSuccess("%s (%s)", name, strings.Join(details, ", "))
+	}
+	for _, name := range sortedKeys(result.Resources) {
+		output.ItemSuccess("%s (resource %s)", name, result.Resources[name].Type)
+	}
+	for _, name := range sortedKeys(result.Skipped) {
+		output.ItemWarning("%s skipped: %s", name, result.Skipped[name])
+	}
+
+	if len(result.StartOrder) > 0 {
+		output.Newline()
+		output.Step("🔗", "Start order")
+		for i, level := range result.StartOrder {
+			output.Item("%d. %s", i+1, strings.Join(level, ", "))
+		}
+	}
+
+	output.Newline()
+	if result.Written {
+		if result.AddedServices+result.AddedResources == 0 {
+			output.Info("azure.yaml already defines all imported services and resources")
+		} else {
+			output.Success("Added %d service(s) and %d resource(s) to %s", result.AddedServices, result.AddedResources, relPath(cwd, result.AzureYaml))
+		}
+		return nil
+	}
+
+	preview, _, err := yamlutil.AppendToMapSection("", "services", mapEntries(result.Services))
+	if err != nil {
+		return err
+	}
+	preview, _, err = yamlutil.AppendToMapSection(preview, "resources", mapEntries(result.Resources))
+	if err != nil {
+		return err
+	}
+	fmt.Print(preview)
+	output.Newline()
+	output.Info("💡 Run 'azd app import --write' to add these to azure.yaml")
+	return nil
+}
+
+// mapEntries returns the entries of m sorted by key.
+func mapEntries[V any](m map[string]V) []yamlutil.MapEntry {
+	entries := make([]yamlutil.MapEntry, 0, len(m))
+	for _, key := range sortedKeys(m) {
+		entries = append(entries, yamlutil.MapEntry{Key: key, Value: m[key]})
+	}
+	return entries
+}
@@ -0,0 +1,61 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jongio/azd-app/cli/src/internal/service"
+)
+
+func TestWriteComposeImport(t *testing.T) {
+	imported := &service.ComposeImport{
+		Services: map[string]service.Service{
+			"api": {Host: "containerapp", Project: "./api"},
+			"web": {Host: "containerapp", Project: "./web", Uses: []string{"api"}},
+		},
+		Resources: map[string]service.Resource{"db": {Type: "db.postgres"}},
+	}
+
+	t.Run("creates azure.yaml", func(t *testing.T) {
+		azureYamlPath := filepath.Join(t.TempDir(), "azure.yaml")
+		services, resources, err := writeComposeImport(azureYamlPath, imported)
+		if err != nil {
+			t.Fatalf("writeComposeImport failed: %v", err)
+		}
+		if services != 2 || resources != 1 {
+			t.Errorf("added %d services and %d resources, want 2 and 1", services, resources)
+		}
+		parsed, err := service.ParseAzureYaml(filepath.Dir(azureYamlPath))
+		if err != nil {
+			t.Fatalf("written azure.yaml is invalid: %v", err)
+		}
+		if len(parsed.Services) != 2 || len(parsed.Services["web"].Uses) != 1 || parsed.Resources["db"].Type != "db.postgres" {
+			t.Errorf("unexpected azure.yaml: %+v", parsed)
+		}
+	})
+
+	t.Run("keeps existing services", func(t *testing.T) {
+		azureYamlPath := filepath.Join(t.TempDir(), "azure.yaml")
+		existing := "name: app\n# keep me\nservices:\n  api:\n    host: appservice\n    project: ./src/api\n"
+		if err := os.WriteFile(azureYamlPath, []byte(existing), 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		services, _, err := writeComposeImport(azureYamlPath, imported)
+		if err != nil {
+			t.Fatalf("writeComposeImport failed: %v", err)
+		}
+		if services != 1 {
+			t.Errorf("added %d services, want only web", services)
+		}
+		data, err := os.ReadFile(azureYamlPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(string(data), existing) {
+			t.Errorf("existing content was changed:\n%s", data)
+		}
+	})
+}
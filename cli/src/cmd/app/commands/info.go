@@ -13,6 +13,7 @@ import (
 
 	"github.com/jongio/azd-app/cli/src/internal/output"
 	"github.com/jongio/azd-app/cli/src/internal/registry"
+	"github.com/jongio/azd-app/cli/src/internal/service"
 	"github.com/jongio/azd-app/cli/src/internal/serviceinfo"
 
 	"github.com/spf13/cobra"
@@ -66,6 +67,9 @@ func validateAndCleanServices(reg *registry.ServiceRegistry) error {
 			if !pidExists {
 				// Port is listening but PID changed - update health but keep running
 				health = "unknown"
+			} else if svc.Health == service.HealthDegraded {
+				// Listening says nothing about the health endpoint that run probes
+				health = svc.Health
 			}
 
 			// Update the service status
@@ -419,9 +423,9 @@ func formatHealth(health string) string {
 	switch health {
 	case "healthy":
 		return colorGreen + health + colorReset
-	case "unhealthy":
+	case "unhealthy", "crashed":
 		return colorRed + health + colorReset
-	case "unknown":
+	case "unknown", "starting", "degraded":
 		return colorYellow + health + colorReset
 	default:
 		return health
@@ -470,7 +474,7 @@ func getStatusIcon(status, health string) string {
 	if status == "starting" {
 		return colorYellow + "○" + colorReset
 	}
-	if status == "error" || health == "unhealthy" {
+	if status == "error" || health == "unhealthy" || health == "crashed" {
 		return colorRed + "✗" + colorReset
 	}
 	if status == "stopped" {
@@ -0,0 +1,275 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/jongio/azd-app/cli/src/internal/azureyaml"
+	"github.com/jongio/azd-app/cli/src/internal/detector"
+	"github.com/jongio/azd-app/cli/src/internal/guide"
+	"github.com/jongio/azd-app/cli/src/internal/output"
+	"github.com/jongio/azd-app/cli/src/internal/security"
+	"github.com/jongio/azd-app/cli/src/internal/service"
+
+	"github.com/spf13/cobra"
+)
+
+// azureYamlSchemaComment points editors at the azd schema for azure.yaml.
+const azureYamlSchemaComment = "# yaml-language-server: $schema=https://raw.githubusercontent.com/Azure/azure-dev/main/schemas/v1.0/azure.yaml.json\n\n"
+
+var (
+	initDryRun    bool
+	initYes       bool
+	initLanguages map[string]string
+	initHosts     map[string]string
+)
+
+// NewInitCommand creates the init command.
+func NewInitCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "init",
+		Short: "Create azure.yaml from the projects detected in the workspace",
+		Long: `Scans the current directory with every project detector and writes a new azure.yaml
+with a service per detected project: its language, its directory relative to azure.yaml as
+project, and a suggested host.
+
+Projects whose language or host is ambiguous, such as a frontend that can be hosted on
+Static Web Apps or in a container, are asked about. Without a terminal, with --output json,
+or with --yes the suggestions are used, unless --language or --host choose for a service.
+
+A directory with the files of several languages, such as package.json, requirements.txt, and
+a .csproj, is reported as one composite project rather than a service per language. Its
+suggested language is the one its Dockerfile builds from, else one other than Node.js when
+package.json has no script that runs an app; the report lists the files of each language and
+the --language choice for each other one.
+
+An existing azure.yaml is never overwritten; use 'azd app import' to add services to it.`,
+		Args: cobra.NoArgs,
+		RunE: runInit,
+	}
+
+	cmd.Flags().BoolVar(&initDryRun, "dry-run", false, "Show the azure.yaml that would be written without writing it")
+	cmd.Flags().BoolVar(&initYes, "yes", false, "Use the suggested language and host for every service without asking")
+	cmd.Flags().StringToStringVar(&initLanguages, "language", nil, "Language of a detected service, as service=language, e.g. ml=python (repeatable)")
+	cmd.Flags().StringToStringVar(&initHosts, "host", nil, "Host of a detected service, as service=host, e.g. web=containerapp (repeatable)")
+
+	return cmd
+}
+
+// initResult is the JSON output of the init command.
+type initResult struct {
+	AzureYaml string                     `json:"azureYaml"`
+	Detected  []service.DetectedService  `json:"detected"`
+	Services  map[string]service.Service `json:"services"`
+	Written   bool                       `json:"written"`
+}
+
+// runInit executes the init command.
+func runInit(cmd *cobra.Command, args []string) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+	if existing, err := detector.FindAzureYaml(cwd); err == nil && existing != "" {
+		return fmt.Errorf("azure.yaml already exists at %s - edit it, or add services with 'azd app import'", existing)
+	}
+
+	detected, err := service.DetectServices(cwd)
+	if err != nil {
+		return fmt.Errorf("failed to detect projects: %w", err)
+	}
+	if len(detected) == 0 {
+		return fmt.Errorf("no projects detected in %s", cwd)
+	}
+
+	if err := validateInitChoices(detected, initLanguages, initHosts); err != nil {
+		return err
+	}
+
+	interactive := guide.IsInteractive() && !output.IsJSON() && !initYes
+	if interactive {
+		output.Section("🔍", fmt.Sprintf("Detected %s project(s)", output.Count(len(detected))))
+	}
+	services := chooseServices(detected, guide.NewPrompter(os.Stdin, os.Stderr), interactive, initLanguages, initHosts)
+
+	content, err := initAzureYaml(filepath.Base(cwd), services)
+	if err != nil {
+		return err
+	}
+
+	result := initResult{
+		AzureYaml: filepath.Join(cwd, azureyaml.FileName),
+		Detected:  detected,
+		Services:  services,
+	}
+	if !initDryRun {
+		if err := security.ValidatePath(result.AzureYaml); err != nil {
+			return fmt.Errorf("invalid path: %w", err)
+		}
+		// #nosec G306 -- azure.yaml is a config file, 0644 is appropriate for team access
+		if err := os.WriteFile(result.AzureYaml, content, 0644); err != nil {
+			return fmt.Errorf("failed to write azure.yaml: %w", err)
+		}
+		result.Written = true
+	}
+
+	if output.IsJSON() {
+		return output.PrintJSON(result)
+	}
+
+	if !interactive {
+		output.Section("🔍", fmt.Sprintf("Detected %s project(s)", output.Count(len(detected))))
+	}
+	for _, name := range sortedKeys(services) {
+		svc := services[name]
+		output.ItemSuccess("%s: %s %s", name, svc.Project, output.Muted("(%s, %s)", svc.Language, svc.Host))
+	}
+	if !interactive {
+		printCompositeProjects(detected, services)
+	}
+	output.Newline()
+	if !result.Written {
+		fmt.Print(string(content))
+		output.Newline()
+		output.Info("💡 Run 'azd app init' without --dry-run to write azure.yaml")
+		return nil
+	}
+	output.Success("Created %s with %d service(s)", azureyaml.FileName, len(services))
+	output.Item("Run 'azd app reqs --generate' to add requirements, then 'azd app run'.")
+	return nil
+}
+
+// chooseServices returns the azure.yaml service of each detected project, with the language and
+// host chosen for it in languages and hosts. When interactive, the others of ambiguous projects
+// are asked for; otherwise the suggestions are used.
+func chooseServices(detected []service.DetectedService, prompter *guide.Prompter, interactive bool, languages, hosts map[string]string) map[string]service.Service {
+	services := make(map[string]service.Service, len(detected))
+	for _, d := range detected {
+		language, languageChosen := languages[d.Name]
+		host, hostChosen := hosts[d.Name]
+		if !languageChosen {
+			language = d.Languages[0]
+		}
+		if !hostChosen {
+			host = d.Hosts[0]
+		}
+		askLanguage := interactive && !languageChosen && len(d.Languages) > 1
+		askHost := interactive && !hostChosen && len(d.Hosts) > 1
+		if askLanguage || askHost {
+			output.Step("❓", "%s (%s)", d.Name, d.Project)
+		}
+		if askLanguage {
+			for _, archetype := range d.Archetypes {
+				output.Item("%s: %s", archetype.Language, strings.Join(archetype.Files, ", "))
+			}
+			if d.Reason != "" {
+				output.Item("%s", output.Muted("Suggested %s: %s", d.Languages[0], d.Reason))
+			}
+			language = chooseOption(prompter, "   Language", d.Languages)
+		}
+		if askHost {
+			host = chooseOption(prompter, "   Host", d.Hosts)
+		}
+		services[d.Name] = d.Entry(language, host)
+	}
+	return services
+}
+
+// validateInitChoices returns an error for a language or host chosen for a service that wasn't
+// detected, or that isn't one of the candidates of the service.
+func validateInitChoices(detected []service.DetectedService, languages, hosts map[string]string) error {
+	byName := make(map[string]service.DetectedService, len(detected))
+	names := make([]string, 0, len(detected))
+	for _, d := range detected {
+		byName[d.Name] = d
+		names = append(names, d.Name)
+	}
+	check := func(flag string, choices map[string]string, candidates func(service.DetectedService) []string) error {
+		for _, name := range sortedKeys(choices) {
+			d, ok := byName[name]
+			if !ok {
+				return fmt.Errorf("--%s %s=%s: no service %s was detected; detected: %s", flag, name, choices[name], name, strings.Join(names, ", "))
+			}
+			if !slices.Contains(candidates(d), choices[name]) {
+				return fmt.Errorf("--%s %s=%s: %s can be %s", flag, name, choices[name], name, strings.Join(candidates(d), ", "))
+			}
+		}
+		return nil
+	}
+	if err := check("language", languages, func(d service.DetectedService) []string { return d.Languages }); err != nil {
+		return err
+	}
+	return check("host", hosts, func(d service.DetectedService) []string { return d.Hosts })
+}
+
+// printCompositeProjects reports the detected projects whose directory holds several languages,
+// with the files of each, the language used and why, and how to choose another.
+func printCompositeProjects(detected []service.DetectedService, services map[string]service.Service) {
+	var composite []service.DetectedService
+	for _, d := range detected {
+		if d.Composite() {
+			composite = append(composite, d)
+		}
+	}
+	if len(composite) == 0 {
+		return
+	}
+
+	output.Section("🧩", fmt.Sprintf("Composite projects: %s", output.Count(len(composite))))
+	for _, d := range composite {
+		used := services[d.Name].Language
+		output.ItemWarning("%s (%s) holds %d languages, so it is one service", d.Name, d.Project, len(d.Languages))
+		var others []string
+		for _, archetype := range d.Archetypes {
+			detail := strings.Join(archetype.Files, ", ")
+			if archetype.Framework != "" {
+				detail += ", " + archetype.Framework
+			}
+			output.Item("%s: %s", archetype.Language, output.Muted("%s", detail))
+			if archetype.Language != used {
+				others = append(others, fmt.Sprintf("--language %s=%s", d.Name, archetype.Language))
+			}
+		}
+		if used == d.Languages[0] {
+			output.Item("Using %s: %s", used, d.Reason)
+		} else {
+			output.Item("Using %s, as chosen", used)
+		}
+		output.Item("Choose another with %s, or move the files of each language to a folder of its own", strings.Join(others, " or "))
+	}
+}
+
+// chooseOption asks for one of options until a valid one is given. The first option is the
+// default, and is returned without asking when it is the only one.
+func chooseOption(prompter *guide.Prompter, question string, options []string) string {
+	if len(options) == 1 {
+		return options[0]
+	}
+	for {
+		answer := strings.ToLower(prompter.Ask(fmt.Sprintf("%s (%s)", question, strings.Join(options, ", ")), options[0]))
+		for _, option := range options {
+			if answer == option {
+				return option
+			}
+		}
+		output.Warning("Choose one of: %s", strings.Join(options, ", "))
+	}
+}
+
+// initAzureYaml returns the content of a new azure.yaml named name with the given services.
+func initAzureYaml(name string, services map[string]service.Service) ([]byte, error) {
+	doc, err := azureyaml.Parse(nil)
+	if err != nil {
+		return nil, err
+	}
+	doc.Project.Name = strings.ToLower(name)
+	doc.Project.Services = services
+	data, err := doc.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(azureYamlSchemaComment), data...), nil
+}
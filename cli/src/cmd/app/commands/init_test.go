@@ -0,0 +1,94 @@
+package commands
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/jongio/azd-app/cli/src/internal/azureyaml"
+	"github.com/jongio/azd-app/cli/src/internal/guide"
+	"github.com/jongio/azd-app/cli/src/internal/service"
+)
+
+func TestChooseServices(t *testing.T) {
+	detected := []service.DetectedService{
+		{Name: "api", Project: "./api", Languages: []string{"python"}, Hosts: []string{"containerapp"}},
+		{Name: "web", Project: "./web", Languages: []string{"ts"}, Hosts: []string{"staticwebapp", "containerapp"}, Dist: "dist"},
+		{Name: "ml", Project: "./ml", Languages: []string{"js", "python"}, Hosts: []string{"containerapp"}},
+	}
+
+	tests := []struct {
+		name        string
+		input       string
+		interactive bool
+		languages   map[string]string
+		hosts       map[string]string
+		wantWeb     string
+		wantML      string
+	}{
+		{name: "suggestions without a terminal", input: "containerapp\npython\n", wantWeb: "staticwebapp", wantML: "js"},
+		{name: "defaults on empty answers", input: "\n\n", interactive: true, wantWeb: "staticwebapp", wantML: "js"},
+		{name: "answers", input: "containerapp\npython\n", interactive: true, wantWeb: "containerapp", wantML: "python"},
+		{name: "invalid answer is asked again", input: "vm\nCONTAINERAPP\npython\n", interactive: true, wantWeb: "containerapp", wantML: "python"},
+		{name: "choices without a terminal", languages: map[string]string{"ml": "python"}, hosts: map[string]string{"web": "containerapp"}, wantWeb: "containerapp", wantML: "python"},
+		{name: "choices are not asked", input: "containerapp\n", interactive: true, languages: map[string]string{"ml": "python"}, wantWeb: "containerapp", wantML: "python"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prompter := guide.NewPrompter(strings.NewReader(tt.input), io.Discard)
+			services := chooseServices(detected, prompter, tt.interactive, tt.languages, tt.hosts)
+
+			if len(services) != 3 || services["api"].Host != "containerapp" || services["api"].Language != "python" {
+				t.Errorf("unexpected services: %+v", services)
+			}
+			if services["web"].Host != tt.wantWeb {
+				t.Errorf("web host = %s, want %s", services["web"].Host, tt.wantWeb)
+			}
+			if services["ml"].Language != tt.wantML {
+				t.Errorf("ml language = %s, want %s", services["ml"].Language, tt.wantML)
+			}
+		})
+	}
+}
+
+func TestValidateInitChoices(t *testing.T) {
+	detected := []service.DetectedService{
+		{Name: "ml", Project: "./ml", Languages: []string{"js", "python"}, Hosts: []string{"containerapp"}},
+	}
+	if err := validateInitChoices(detected, map[string]string{"ml": "python"}, map[string]string{"ml": "containerapp"}); err != nil {
+		t.Errorf("valid choices: %v", err)
+	}
+	if err := validateInitChoices(detected, map[string]string{"ml": "dotnet"}, nil); err == nil || !strings.Contains(err.Error(), "js, python") {
+		t.Errorf("language that wasn't detected: error = %v, want the candidates", err)
+	}
+	if err := validateInitChoices(detected, nil, map[string]string{"api": "containerapp"}); err == nil || !strings.Contains(err.Error(), "detected: ml") {
+		t.Errorf("service that wasn't detected: error = %v, want the detected services", err)
+	}
+}
+
+func TestInitAzureYaml(t *testing.T) {
+	services := map[string]service.Service{
+		"web": {Host: "staticwebapp", Language: "ts", Project: "./web", Dist: "dist"},
+		"api": {Host: "containerapp", Language: "python", Project: "./api"},
+	}
+
+	content, err := initAzureYaml("Shop", services)
+	if err != nil {
+		t.Fatalf("initAzureYaml failed: %v", err)
+	}
+	if !strings.HasPrefix(string(content), "# yaml-language-server: $schema=") {
+		t.Errorf("azure.yaml should reference the schema:\n%s", content)
+	}
+
+	doc, err := azureyaml.Parse(content)
+	if err != nil {
+		t.Fatalf("generated azure.yaml does not parse: %v\n%s", err, content)
+	}
+	if issues := doc.Validate(); len(issues) > 0 {
+		t.Errorf("generated azure.yaml has schema issues: %v", issues)
+	}
+	if doc.Project.Name != "shop" || len(doc.Project.Services) != 2 || doc.Project.Services["web"].Dist != "dist" {
+		t.Errorf("unexpected project: %+v", doc.Project)
+	}
+}
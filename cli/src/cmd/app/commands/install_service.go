@@ -0,0 +1,197 @@
+package commands
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/jongio/azd-app/cli/src/internal/autostart"
+	"github.com/jongio/azd-app/cli/src/internal/output"
+	"github.com/jongio/azd-app/cli/src/internal/trust"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	installServiceFilter  string
+	installServiceEnvFile string
+)
+
+// NewInstallServiceCommand creates the install-service command.
+func NewInstallServiceCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "install-service",
+		Short: "Start this workspace's services automatically when you log in",
+		Long: `Registers 'azd app run' for the current workspace with the service manager of your
+platform, so the dev stack starts at login and is restarted if it fails: a launch agent on
+macOS, a systemd user unit on Linux, and a Task Scheduler task on Windows. The stack is
+also started right away. Output goes to autostart.log in the workspace state directory
+(see 'azd app cache list'). Running it again replaces the registration.`,
+		Args: cobra.NoArgs,
+		RunE: runInstallService,
+	}
+
+	cmd.Flags().StringVarP(&installServiceFilter, "service", "s", "", "Run specific service(s) only (comma-separated)")
+	_ = cmd.RegisterFlagCompletionFunc("service", completeServiceNames)
+	cmd.Flags().StringVar(&installServiceEnvFile, "env-file", "", "Load environment variables from .env file")
+
+	return cmd
+}
+
+// NewUninstallServiceCommand creates the uninstall-service command.
+func NewUninstallServiceCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "uninstall-service",
+		Short: "Stop starting this workspace's services at login",
+		Long:  `Stops the services started by install-service for the current workspace and removes the registration.`,
+		Args:  cobra.NoArgs,
+		RunE:  runUninstallService,
+	}
+}
+
+// NewServiceStatusCommand creates the service-status command.
+func NewServiceStatusCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "service-status",
+		Short: "Show whether this workspace's services start at login",
+		Args:  cobra.NoArgs,
+		RunE:  runServiceStatus,
+	}
+}
+
+// runInstallService executes the install-service command.
+func runInstallService(cmd *cobra.Command, args []string) error {
+	workspace, err := autostartWorkspace()
+	if err != nil {
+		return err
+	}
+
+	// The service runs without a terminal to ask on, so the decision must be recorded
+	if err := requireWorkspaceTrust(workspace); err != nil {
+		return err
+	}
+	if err := trust.Set(workspace, true); err != nil {
+		return err
+	}
+
+	runArgs := []string{"run"}
+	if installServiceFilter != "" {
+		runArgs = append(runArgs, "--service", installServiceFilter)
+	}
+	if installServiceEnvFile != "" {
+		// The service does not run in the current directory, so the path must be absolute
+		envFile, err := filepath.Abs(installServiceEnvFile)
+		if err != nil {
+			return fmt.Errorf("invalid --env-file: %w", err)
+		}
+		runArgs = append(runArgs, "--env-file", envFile)
+	}
+
+	svc, err := autostart.NewService(workspace, runArgs)
+	if err != nil {
+		return err
+	}
+	status, err := autostart.Install(svc)
+	if err != nil {
+		return fmt.Errorf("failed to install service: %w", err)
+	}
+
+	if output.IsJSON() {
+		return output.PrintJSON(status)
+	}
+
+	output.Success("Installed %s", status.Name)
+	output.Label("Workspace", workspace)
+	output.Label("Manager", status.Manager)
+	output.Label("Registration", status.Path)
+	output.Label("Logs", svc.LogFile)
+	output.Newline()
+	output.Info("💡 Services start when you log in. Remove with 'azd app uninstall-service'")
+	return nil
+}
+
+// runUninstallService executes the uninstall-service command.
+func runUninstallService(cmd *cobra.Command, args []string) error {
+	workspace, err := autostartWorkspace()
+	if err != nil {
+		return err
+	}
+	name, err := autostart.ServiceName(workspace)
+	if err != nil {
+		return err
+	}
+
+	status, err := autostart.GetStatus(name)
+	if err != nil {
+		return err
+	}
+	if status.Installed {
+		if err := autostart.Uninstall(name); err != nil {
+			return fmt.Errorf("failed to uninstall service: %w", err)
+		}
+	}
+
+	if output.IsJSON() {
+		return output.PrintJSON(map[string]interface{}{
+			"name":        name,
+			"uninstalled": status.Installed,
+		})
+	}
+
+	if !status.Installed {
+		output.Info("No service is installed for %s", workspace)
+		return nil
+	}
+	output.Success("Uninstalled %s", name)
+	return nil
+}
+
+// runServiceStatus executes the service-status command.
+func runServiceStatus(cmd *cobra.Command, args []string) error {
+	workspace, err := autostartWorkspace()
+	if err != nil {
+		return err
+	}
+	name, err := autostart.ServiceName(workspace)
+	if err != nil {
+		return err
+	}
+	status, err := autostart.GetStatus(name)
+	if err != nil {
+		return err
+	}
+	if status.Installed {
+		status.LogFile, _ = autostart.LogFile(workspace)
+	}
+
+	if output.IsJSON() {
+		return output.PrintJSON(status)
+	}
+
+	if !status.Installed {
+		output.Info("No service is installed for %s", workspace)
+		output.Item("Install one with 'azd app install-service'")
+		return nil
+	}
+
+	state := "stopped"
+	if status.Running {
+		state = "running"
+	}
+	output.Section("🔁", "Start at login")
+	output.Label("Service", status.Name)
+	output.Label("Workspace", workspace)
+	output.Label("Manager", status.Manager)
+	output.Label("Registration", status.Path)
+	output.Label("State", state)
+	output.Label("Logs", status.LogFile)
+	return nil
+}
+
+// autostartWorkspace returns the workspace whose services start at login: the directory of azure.yaml.
+func autostartWorkspace() (string, error) {
+	azureYamlPath, err := findAzureYaml()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Dir(azureYamlPath), nil
+}
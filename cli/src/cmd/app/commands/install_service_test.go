@@ -0,0 +1,47 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/jongio/azd-app/cli/src/internal/statedir"
+)
+
+func TestServiceCommandsWithoutInstallation(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("uses the systemd user unit directory")
+	}
+	t.Setenv(statedir.RootEnvVar, t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	workspace := t.TempDir()
+	if err := os.WriteFile(filepath.Join(workspace, "azure.yaml"), []byte("name: demo\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+	if err := os.Chdir(workspace); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := runServiceStatus(NewServiceStatusCommand(), nil); err != nil {
+		t.Errorf("service-status failed: %v", err)
+	}
+	if err := runUninstallService(NewUninstallServiceCommand(), nil); err != nil {
+		t.Errorf("uninstall-service without an installed service failed: %v", err)
+	}
+}
+
+func TestInstallServiceRequiresAzureYaml(t *testing.T) {
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := runInstallService(NewInstallServiceCommand(), nil); err == nil {
+		t.Error("expected install-service to fail without azure.yaml")
+	}
+}
@@ -0,0 +1,132 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"time"
+
+	"github.com/jongio/azd-app/cli/src/internal/loadtest"
+	"github.com/jongio/azd-app/cli/src/internal/output"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	loadtestRPS      int
+	loadtestDuration time.Duration
+	loadtestTimeout  time.Duration
+	loadtestScenario string
+	loadtestPath     string
+	loadtestURL      string
+	loadtestExport   string
+)
+
+// NewLoadtestCommand creates the loadtest command.
+func NewLoadtestCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "loadtest <service>",
+		Short: "Generate HTTP load against a running service",
+		Long: `Sends HTTP requests to a running service at a fixed rate and reports
+throughput, status codes, and latency percentiles.
+
+By default a GET request is sent to --path. Use --scenario to send a weighted mix
+of requests defined in a YAML file. Press Ctrl+C to stop early and print results.`,
+		Args: cobra.ExactArgs(1),
+		RunE: runLoadtest,
+	}
+
+	cmd.Flags().IntVar(&loadtestRPS, "rps", loadtest.DefaultRPS, "Requests per second")
+	cmd.Flags().DurationVarP(&loadtestDuration, "duration", "d", loadtest.DefaultDuration, "How long to generate load")
+	cmd.Flags().DurationVar(&loadtestTimeout, "timeout", loadtest.DefaultTimeout, "Timeout for each request")
+	cmd.Flags().StringVar(&loadtestScenario, "scenario", "", "Path to a YAML scenario file")
+	cmd.Flags().StringVar(&loadtestPath, "path", "/", "Request path when no scenario is given")
+	cmd.Flags().StringVar(&loadtestURL, "url", "", "Base URL to test instead of the registered service URL")
+	cmd.Flags().StringVar(&loadtestExport, "export", "", "Metrics endpoint URL to POST the JSON report to")
+
+	return cmd
+}
+
+// runLoadtest executes the loadtest command.
+func runLoadtest(cmd *cobra.Command, args []string) error {
+	serviceName := args[0]
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+
+	baseURL, err := resolveServiceURL(cwd, serviceName, loadtestURL)
+	if err != nil {
+		return err
+	}
+
+	scenario := &loadtest.Scenario{Requests: []loadtest.Request{{Path: loadtestPath}}}
+	if loadtestScenario != "" {
+		scenario, err = loadtest.LoadScenario(loadtestScenario)
+		if err != nil {
+			return err
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(commandContext(), os.Interrupt)
+	defer stop()
+
+	if !output.IsJSON() {
+		output.Section("📈", fmt.Sprintf("Load testing %s (%s)", serviceName, baseURL))
+		output.Item("%d req/s for %s", loadtestRPS, loadtestDuration)
+	}
+
+	report, err := loadtest.Run(ctx, loadtest.Config{
+		BaseURL:  baseURL,
+		RPS:      loadtestRPS,
+		Duration: loadtestDuration,
+		Timeout:  loadtestTimeout,
+		Scenario: scenario,
+	})
+	if err != nil {
+		return fmt.Errorf("load test failed: %w", err)
+	}
+	report.Service = serviceName
+
+	if output.IsJSON() {
+		if err := output.PrintJSON(report); err != nil {
+			return err
+		}
+	} else {
+		printLoadtestReport(report)
+	}
+
+	// The report is shown first, so a failed export doesn't lose it
+	if loadtestExport != "" {
+		if err := loadtest.Export(ctx, loadtestExport, report); err != nil {
+			return err
+		}
+		if !output.IsJSON() {
+			output.Success("Report exported to %s", loadtestExport)
+		}
+	}
+	return nil
+}
+
+// printLoadtestReport prints a human-readable load test summary.
+func printLoadtestReport(report *loadtest.Report) {
+	output.Newline()
+	output.Label("Requests", fmt.Sprintf("%d (%.1f req/s)", report.Requests, report.ActualRPS))
+	output.Label("Errors", fmt.Sprintf("%d", report.Errors))
+
+	codes := make([]string, 0, len(report.StatusCodes))
+	for code := range report.StatusCodes {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	for _, code := range codes {
+		output.Item("%s: %d", code, report.StatusCodes[code])
+	}
+
+	l := report.Latency
+	output.Label("Latency", fmt.Sprintf("p50 %.1fms  p90 %.1fms  p95 %.1fms  p99 %.1fms", l.P50, l.P90, l.P95, l.P99))
+	output.Item("min %.1fms  mean %.1fms  max %.1fms", l.Min, l.Mean, l.Max)
+	output.Newline()
+}
@@ -0,0 +1,23 @@
+package commands
+
+import (
+	"testing"
+)
+
+func TestNewLoadtestCommand(t *testing.T) {
+	cmd := NewLoadtestCommand()
+
+	if cmd.Use != "loadtest <service>" {
+		t.Errorf("Use = %q, want %q", cmd.Use, "loadtest <service>")
+	}
+
+	for _, flag := range []string{"rps", "duration", "timeout", "scenario", "path", "url", "export"} {
+		if cmd.Flags().Lookup(flag) == nil {
+			t.Errorf("Expected flag %q to exist", flag)
+		}
+	}
+
+	if err := cmd.Args(cmd, []string{}); err == nil {
+		t.Error("Expected error when no service is given")
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"regexp"
+	"sort"
 	"strings"
 	"syscall"
 	"time"
@@ -25,6 +27,7 @@ var (
 	logsLevel      string
 	logsFormat     string
 	logsOutput     string
+	logsGrep       string
 )
 
 // NewLogsCommand creates the logs command.
@@ -32,12 +35,17 @@ func NewLogsCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "logs [service-name]",
 		Short: "View logs from running services",
-		Long:  `Display output logs from running services for debugging and monitoring`,
-		RunE:  runLogs,
+		Long: `Display output logs from running services for debugging and monitoring.
+
+Service output is saved to log files in the workspace state, so logs can be read from a
+second terminal while 'azd app run' is active, or after it exits. Without a running session,
+the logs of the most recent one are shown.`,
+		RunE: runLogs,
 	}
 
 	cmd.Flags().BoolVarP(&logsFollow, "follow", "f", false, "Follow log output (tail -f behavior)")
 	cmd.Flags().StringVarP(&logsService, "service", "s", "", "Filter by service name(s) (comma-separated)")
+	_ = cmd.RegisterFlagCompletionFunc("service", completeServiceNames)
 	cmd.Flags().IntVarP(&logsTail, "tail", "n", 100, "Number of lines to show from the end")
 	cmd.Flags().StringVar(&logsSince, "since", "", "Show logs since duration (e.g., 5m, 1h)")
 	cmd.Flags().BoolVar(&logsTimestamps, "timestamps", true, "Show timestamps with each log entry")
@@ -45,6 +53,7 @@ func NewLogsCommand() *cobra.Command {
 	cmd.Flags().StringVar(&logsLevel, "level", "all", "Filter by log level (info, warn, error, debug, all)")
 	cmd.Flags().StringVar(&logsFormat, "format", "text", "Output format (text, json)")
 	cmd.Flags().StringVar(&logsOutput, "output", "", "Write logs to file instead of stdout")
+	cmd.Flags().StringVar(&logsGrep, "grep", "", "Show only entries whose message matches a regular expression")
 
 	return cmd
 }
@@ -69,13 +78,46 @@ func runLogs(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	// Get log manager
-	logManager := service.GetLogManager(cwd)
+	// Parse log level filter
+	levelFilter := parseLogLevel(logsLevel)
+
+	// Parse message filter
+	var grepFilter *regexp.Regexp
+	if logsGrep != "" {
+		grepFilter, err = regexp.Compile(logsGrep)
+		if err != nil {
+			return fmt.Errorf("invalid --grep pattern: %w", err)
+		}
+	}
+
+	// Parse since duration
+	var sinceTime time.Time
+	if logsSince != "" {
+		duration, err := time.ParseDuration(logsSince)
+		if err != nil {
+			return fmt.Errorf("invalid --since duration: %w", err)
+		}
+		sinceTime = time.Now().Add(-duration)
+	}
 
-	// Check if any services are running
+	// Services started by this process log to in-memory buffers; otherwise read the log
+	// files of the running session, or of the last one, from the workspace state.
+	logManager := service.GetLogManager(cwd)
 	serviceNames := logManager.GetServiceNames()
+	var sessionDirs []string
+	if len(serviceNames) == 0 {
+		sessionDirs, err = service.LogSessionDirs(cwd)
+		if err != nil {
+			return fmt.Errorf("failed to find service logs: %w", err)
+		}
+		for name := range service.LogFiles(sessionDirs) {
+			serviceNames = append(serviceNames, name)
+		}
+	}
+	sort.Strings(serviceNames)
+
 	if len(serviceNames) == 0 {
-		output.Info("No services are currently running")
+		output.Info("No service logs found")
 		output.Item("Run 'azd app run' to start services")
 		return nil
 	}
@@ -96,19 +138,6 @@ func runLogs(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	// Parse log level filter
-	levelFilter := parseLogLevel(logsLevel)
-
-	// Parse since duration
-	var sinceTime time.Time
-	if logsSince != "" {
-		duration, err := time.ParseDuration(logsSince)
-		if err != nil {
-			return fmt.Errorf("invalid --since duration: %w", err)
-		}
-		sinceTime = time.Now().Add(-duration)
-	}
-
 	// Setup output writer
 	output := os.Stdout
 	if logsOutput != "" {
@@ -127,7 +156,12 @@ func runLogs(cmd *cobra.Command, args []string) error {
 
 	// Get initial logs
 	var logs []service.LogEntry
-	if len(serviceFilter) == 0 {
+	if sessionDirs != nil {
+		logs, err = service.ReadLogFiles(sessionDirs, serviceFilter, logsTail, sinceTime)
+		if err != nil {
+			return err
+		}
+	} else if len(serviceFilter) == 0 {
 		// Get logs from all services
 		if logsSince != "" {
 			logs = logManager.GetAllLogsSince(sinceTime)
@@ -152,26 +186,38 @@ func runLogs(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	// Filter by level
+	// Filter by level and message
 	logs = filterLogsByLevel(logs, levelFilter)
+	logs = filterLogsByMessage(logs, grepFilter)
 
 	// Display initial logs
-	if logsFormat == "json" {
-		displayLogsJSON(logs, output)
-	} else {
-		displayLogsText(logs, output, logsTimestamps, logsNoColor)
+	display := func(entries []service.LogEntry) {
+		if logsFormat == "json" {
+			displayLogsJSON(entries, output)
+		} else {
+			displayLogsText(entries, output, logsTimestamps, logsNoColor)
+		}
 	}
+	display(logs)
 
-	// Follow mode - subscribe to live logs
-	if logsFollow {
-		return followLogs(logManager, serviceFilter, levelFilter, output)
+	if !logsFollow {
+		return nil
 	}
 
-	return nil
+	// Follow mode - stream new entries until interrupted
+	follow := func(entry service.LogEntry) {
+		display(filterLogsByMessage(filterLogsByLevel([]service.LogEntry{entry}, levelFilter), grepFilter))
+	}
+	if sessionDirs != nil {
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		return service.FollowLogFiles(ctx, sessionDirs, serviceFilter, follow)
+	}
+	return followLogs(logManager, serviceFilter, follow)
 }
 
-// followLogs subscribes to live log streams and displays them.
-func followLogs(logManager *service.LogManager, serviceFilter []string, levelFilter service.LogLevel, output *os.File) error {
+// followLogs subscribes to live log streams and passes entries to display.
+func followLogs(logManager *service.LogManager, serviceFilter []string, display func(service.LogEntry)) error {
 	// Create subscriptions
 	subscriptions := make(map[string]chan service.LogEntry)
 
@@ -212,17 +258,7 @@ func followLogs(logManager *service.LogManager, serviceFilter []string, levelFil
 	for {
 		select {
 		case entry := <-mergedChan:
-			// Filter by level
-			if levelFilter != -1 && entry.Level != levelFilter {
-				continue
-			}
-
-			// Display log entry
-			if logsFormat == "json" {
-				displayLogsJSON([]service.LogEntry{entry}, output)
-			} else {
-				displayLogsText([]service.LogEntry{entry}, output, logsTimestamps, logsNoColor)
-			}
+			display(entry)
 
 		case <-sigChan:
 			// Cleanup subscriptions
@@ -320,3 +356,18 @@ func filterLogsByLevel(logs []service.LogEntry, level service.LogLevel) []servic
 	}
 	return filtered
 }
+
+// filterLogsByMessage filters logs to those whose message matches pattern; nil keeps all.
+func filterLogsByMessage(logs []service.LogEntry, pattern *regexp.Regexp) []service.LogEntry {
+	if pattern == nil {
+		return logs
+	}
+
+	filtered := make([]service.LogEntry, 0)
+	for _, entry := range logs {
+		if pattern.MatchString(entry.Message) {
+			filtered = append(filtered, entry)
+		}
+	}
+	return filtered
+}
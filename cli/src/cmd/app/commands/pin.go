@@ -0,0 +1,135 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/jongio/azd-app/cli/src/internal/detector"
+	"github.com/jongio/azd-app/cli/src/internal/output"
+	"github.com/jongio/azd-app/cli/src/internal/pinning"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	pinWrite  bool
+	pinStrict bool
+)
+
+// NewPinCommand creates the pin command.
+func NewPinCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "pin",
+		Short: "Report and pin floating dependency versions",
+		Long: `Reports dependencies with floating or unpinned versions (latest, ^, ~, >=, *)
+and projects without a lockfile across all Node.js, Python, and .NET projects.
+
+With --write, each reported dependency is pinned to the version currently
+installed (node_modules, the project's virtual environment, or the last NuGet restore).
+Run 'azd app deps' first so resolved versions are available.`,
+		Args: cobra.NoArgs,
+		RunE: runPin,
+	}
+
+	cmd.Flags().BoolVar(&pinWrite, "write", false, "Pin reported dependencies to their currently resolved versions")
+	cmd.Flags().BoolVar(&pinStrict, "strict", false, "Exit with an error when unpinned dependencies remain")
+
+	return cmd
+}
+
+// runPin executes the pin command.
+func runPin(cmd *cobra.Command, args []string) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+
+	searchRoot := cwd
+	if azureYamlPath, err := detector.FindAzureYaml(cwd); err == nil && azureYamlPath != "" {
+		searchRoot = filepath.Dir(azureYamlPath)
+	}
+
+	issues, err := pinning.Scan(searchRoot)
+	if err != nil {
+		return err
+	}
+
+	var pinned []pinning.Issue
+	if pinWrite {
+		pinned, err = pinning.Pin(issues)
+		if err != nil {
+			return err
+		}
+		issues, err = pinning.Scan(searchRoot)
+		if err != nil {
+			return err
+		}
+	}
+
+	if output.IsJSON() {
+		if issues == nil {
+			issues = []pinning.Issue{}
+		}
+		if err := output.PrintJSON(map[string]interface{}{
+			"issues": issues,
+			"pinned": pinned,
+		}); err != nil {
+			return err
+		}
+	} else {
+		printPinResult(searchRoot, issues, pinned)
+	}
+
+	if pinStrict && len(issues) > 0 {
+		return fmt.Errorf("%d unpinned dependency issue(s) found", len(issues))
+	}
+	return nil
+}
+
+// printPinResult prints pinned dependencies and remaining issues grouped by file.
+func printPinResult(root string, issues, pinned []pinning.Issue) {
+	if len(pinned) > 0 {
+		output.Section("📌", fmt.Sprintf("Pinned %d dependency version(s)", len(pinned)))
+		for _, issue := range pinned {
+			output.ItemSuccess("%s: %s %s → %s", relPath(root, issue.File), issue.Package, issue.Spec, issue.Resolved)
+		}
+		output.Newline()
+	}
+
+	if len(issues) == 0 {
+		output.Success("All dependency versions are pinned")
+		return
+	}
+
+	output.Section("🔍", fmt.Sprintf("Found %d unpinned dependency issue(s)", len(issues)))
+	currentFile := ""
+	for _, issue := range issues {
+		if issue.File != currentFile {
+			currentFile = issue.File
+			output.Step("📄", "%s", relPath(root, issue.File))
+		}
+		switch {
+		case issue.Package == "":
+			output.ItemWarning("%s", issue.Reason)
+		case issue.Resolved != "":
+			output.ItemWarning("%s %q (%s, resolved %s)", issue.Package, issue.Spec, issue.Reason, issue.Resolved)
+		default:
+			output.ItemWarning("%s %q (%s)", issue.Package, issue.Spec, issue.Reason)
+		}
+	}
+
+	if !pinWrite {
+		output.Newline()
+		output.Info("💡 Run 'azd app pin --write' to pin dependencies to their installed versions")
+	}
+}
+
+// relPath returns path relative to root for display, or path unchanged if that fails.
+func relPath(root, path string) string {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return path
+	}
+	return rel
+}
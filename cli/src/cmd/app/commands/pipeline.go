@@ -0,0 +1,219 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jongio/azd-app/cli/src/internal/output"
+	"github.com/jongio/azd-app/cli/src/internal/pipeline"
+	"github.com/jongio/azd-app/cli/src/internal/workerpool"
+
+	"github.com/spf13/cobra"
+)
+
+var pipelineDryRun bool
+
+// NewPipelineCommand creates the pipeline command.
+func NewPipelineCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "pipeline [name]",
+		Short: "Run a pipeline of stages defined in .azdapp.yaml",
+		Long: `Runs a named pipeline from the pipelines section of .azdapp.yaml in the workspace: stages of
+commands, each started once the stages it depends on have succeeded, such as generating code,
+building libraries, testing, and running the services. Stages that don't depend on each other
+run at the same time. Without a name, the pipelines are listed.
+
+Commands are split into words like aliases and run without a shell. Commands starting with
+'azd app' run this azd app. A stage that fails skips the stages after it.`,
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completePipelineNames,
+		RunE:              runPipeline,
+		SilenceUsage:      true,
+	}
+
+	cmd.Flags().BoolVar(&pipelineDryRun, "dry-run", false, "Show the stages in the order they would run, without running them")
+
+	return cmd
+}
+
+// completePipelineNames completes the names of the pipelines of the workspace.
+func completePipelineNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	workspace, err := currentWorkspace()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	pipelines, err := pipeline.Load(workspace)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	var names []string
+	for name, p := range pipelines {
+		if strings.HasPrefix(name, toComplete) {
+			names = append(names, name+"\t"+p.Description)
+		}
+	}
+	sort.Strings(names)
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+// pipelinePlan is the JSON output of pipeline --dry-run.
+type pipelinePlan struct {
+	Pipeline *pipeline.Pipeline `json:"pipeline"`
+	Levels   [][]string         `json:"levels"` // Stages that run at the same time, in order
+}
+
+// runPipeline executes the pipeline command.
+func runPipeline(cmd *cobra.Command, args []string) error {
+	workspace, err := currentWorkspace()
+	if err != nil {
+		return err
+	}
+	pipelines, err := pipeline.Load(workspace)
+	if err != nil {
+		return err
+	}
+	names := make([]string, 0, len(pipelines))
+	for name := range pipelines {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if len(args) == 0 {
+		return listPipelines(pipelines, names)
+	}
+	p, ok := pipelines[args[0]]
+	if !ok {
+		if len(names) == 0 {
+			return fmt.Errorf("unknown pipeline %q: no pipelines are defined in %s", args[0], filepath.Join(workspace, ".azdapp.yaml"))
+		}
+		return fmt.Errorf("unknown pipeline %q, defined: %s", args[0], strings.Join(names, ", "))
+	}
+	levels, err := p.Levels()
+	if err != nil {
+		return err
+	}
+
+	if pipelineDryRun {
+		if output.IsJSON() {
+			return output.PrintJSON(pipelinePlan{Pipeline: p, Levels: levels})
+		}
+		printPipelinePlan(p, levels)
+		return nil
+	}
+
+	// Stages run commands the workspace defines on the host, also with --sandbox
+	if err := requireWorkspaceTrust(workspace); err != nil {
+		return err
+	}
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to find the azd app executable: %w", err)
+	}
+
+	output.Section("🔧", fmt.Sprintf("Running pipeline %s", p.Name))
+	err = pipeline.Run(commandContext(), p, pipeline.Options{
+		ProjectDir: workspace,
+		Executable: executable,
+		Progress: func(stage, command string) {
+			output.Item("%s%s%s $ %s", output.Cyan, output.PadRight(stage, 15), output.Reset, command)
+		},
+	})
+	var batchErr *workerpool.Error
+	if errors.As(err, &batchErr) {
+		printPipelineSummary(levels, batchErr)
+		return fmt.Errorf("pipeline %s failed: %v", p.Name, err)
+	}
+	if err != nil {
+		return err
+	}
+	output.Success("Pipeline %s finished: %d stage(s)", p.Name, len(p.Stages))
+	return nil
+}
+
+// listPipelines prints the pipelines of the workspace, named in names in order.
+func listPipelines(pipelines map[string]*pipeline.Pipeline, names []string) error {
+	if output.IsJSON() {
+		list := make([]*pipeline.Pipeline, len(names))
+		for i, name := range names {
+			list[i] = pipelines[name]
+		}
+		return output.PrintJSON(list)
+	}
+	if len(names) == 0 {
+		output.Info("No pipelines are defined")
+		output.Item("Add them to the pipelines section of %s", ".azdapp.yaml")
+		return nil
+	}
+	for _, name := range names {
+		p := pipelines[name]
+		levels, _ := p.Levels()
+		stages := make([]string, len(levels))
+		for i, level := range levels {
+			stages[i] = strings.Join(level, ", ")
+		}
+		output.Item("%s%s%s %s", output.Cyan, output.PadRight(name, 15), output.Reset, p.Description)
+		output.Item("%s%s", strings.Repeat(" ", 16), output.Dim+strings.Join(stages, " → ")+output.Reset)
+	}
+	return nil
+}
+
+// printPipelinePlan prints the stages of p in the order they would run.
+func printPipelinePlan(p *pipeline.Pipeline, levels [][]string) {
+	output.Section("🔧", fmt.Sprintf("Pipeline %s", p.Name))
+	for i, level := range levels {
+		for _, name := range level {
+			stage := p.Stages[name]
+			details := []string{fmt.Sprintf("step %d", i+1)}
+			if stage.Dir != "" {
+				details = append(details, "in "+stage.Dir)
+			}
+			if stage.Parallel > 1 {
+				details = append(details, fmt.Sprintf("%d commands at once", stage.Parallel))
+			}
+			if len(stage.DependsOn) > 0 {
+				details = append(details, "after "+strings.Join(stage.DependsOn, ", "))
+			}
+			output.Item("%s%s%s (%s)", output.Cyan, name, output.Reset, strings.Join(details, ", "))
+			for _, command := range stage.Commands {
+				output.Item("   $ %s", command)
+			}
+		}
+	}
+}
+
+// printPipelineSummary prints whether each stage succeeded, failed, or was skipped.
+func printPipelineSummary(levels [][]string, batchErr *workerpool.Error) {
+	failed := make(map[string]error, len(batchErr.Failed))
+	for _, failure := range batchErr.Failed {
+		failed[failure.Name] = failure.Err
+	}
+	skipped := make(map[string]bool, len(batchErr.Skipped))
+	for _, name := range batchErr.Skipped {
+		skipped[name] = true
+	}
+
+	output.Section("📋", "Summary")
+	table := output.NewTable()
+	table.Indent, table.Gap = "   ", 1
+	for _, level := range levels {
+		for _, name := range level {
+			switch {
+			case failed[name] != nil:
+				table.AddRow(output.Red+"✗"+output.Reset, name, failed[name].Error())
+			case skipped[name]:
+				table.AddRow(output.Dim+"-"+output.Reset, name, "skipped")
+			default:
+				table.AddRow(output.Green+"✓"+output.Reset, name, "ok")
+			}
+		}
+	}
+	table.Print()
+	output.Newline()
+}
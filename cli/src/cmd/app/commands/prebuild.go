@@ -0,0 +1,215 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/jongio/azd-app/cli/src/internal/detector"
+	"github.com/jongio/azd-app/cli/src/internal/devenv"
+	"github.com/jongio/azd-app/cli/src/internal/output"
+	"github.com/jongio/azd-app/cli/src/internal/security"
+
+	"github.com/spf13/cobra"
+)
+
+var prebuildWrite bool
+
+// NewPrebuildCommand creates the prebuild command.
+func NewPrebuildCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "prebuild",
+		Short: "Generate devcontainer scripts that install dependencies for Codespaces prebuilds",
+		Long: `Generates the scripts a dev container runs when it is created, from the projects detected in
+the workspace: .devcontainer/azd-app/on-create.sh installs the dependencies of every project
+and pulls the base images of Dockerfiles, and .devcontainer/azd-app/post-create.sh checks the
+requirements of azure.yaml. GitHub Codespaces prebuilds run the onCreateCommand ahead of
+time, so codespaces open with everything installed.
+
+Without --write the steps are only shown. With --write the scripts are written, replacing
+earlier ones, and .devcontainer/devcontainer.json is created to run them if the workspace
+has no dev container configuration. An existing devcontainer.json is not changed; the
+commands to add to it are shown instead.`,
+		Args: cobra.NoArgs,
+		RunE: runPrebuild,
+	}
+
+	cmd.Flags().BoolVar(&prebuildWrite, "write", false, "Write the scripts, and devcontainer.json if there is none")
+
+	return cmd
+}
+
+// prebuildResult is the JSON output of the prebuild command.
+type prebuildResult struct {
+	Workspace    string          `json:"workspace"`
+	Prebuild     devenv.Prebuild `json:"prebuild"`
+	Written      []string        `json:"written"`      // Files written, relative to the workspace
+	Devcontainer string          `json:"devcontainer"` // devcontainer.json, relative to the workspace; empty when there is none
+	Configured   bool            `json:"configured"`   // devcontainer.json runs the scripts
+}
+
+// runPrebuild executes the prebuild command.
+func runPrebuild(cmd *cobra.Command, args []string) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+	workspace := cwd
+	if azureYamlPath, err := detector.FindAzureYaml(cwd); err == nil && azureYamlPath != "" {
+		workspace = filepath.Dir(azureYamlPath)
+	}
+
+	result := prebuildResult{Workspace: workspace, Prebuild: prebuildPlan(workspace), Written: []string{}}
+	if prebuildWrite {
+		if err := writePrebuild(&result); err != nil {
+			return err
+		}
+	} else {
+		result.Devcontainer, result.Configured, err = findDevcontainer(workspace)
+		if err != nil {
+			return err
+		}
+	}
+
+	if output.IsJSON() {
+		return output.PrintJSON(result)
+	}
+	printPrebuildResult(result)
+	return nil
+}
+
+// prebuildPlan returns the dependency installs of the projects detected in workspace, and pulls
+// of the base images of its Dockerfiles.
+func prebuildPlan(workspace string) devenv.Prebuild {
+	plan := devenv.Prebuild{Install: []devenv.PrebuildStep{}, Warm: []devenv.PrebuildStep{}}
+	for _, c := range workspaceCommands(workspace) {
+		if c.Step == "deps" {
+			plan.Install = append(plan.Install, devenv.PrebuildStep{Name: c.Name, Dir: filepath.ToSlash(relPath(workspace, c.Dir)), Command: c.Command})
+		}
+	}
+
+	scan, err := detector.ScanWorkspace(workspace)
+	if err != nil {
+		return plan
+	}
+	var images []string
+	for _, dockerfile := range scan.Dockerfiles {
+		image := dockerfile.BaseImage
+		// Stages, scratch, and images named by build arguments can't be pulled ahead of time
+		if image == "" || image == "scratch" || strings.Contains(image, "$") || slices.Contains(dockerfile.Stages, image) {
+			continue
+		}
+		if !slices.Contains(images, image) {
+			images = append(images, image)
+		}
+	}
+	sort.Strings(images)
+	for _, image := range images {
+		plan.Warm = append(plan.Warm, devenv.PrebuildStep{Name: image, Dir: ".", Command: "docker pull " + image})
+	}
+	return plan
+}
+
+// writePrebuild writes the prebuild scripts of result, and a devcontainer.json that runs them
+// if the workspace has none.
+func writePrebuild(result *prebuildResult) error {
+	files := map[string]string{
+		devenv.OnCreateScript:   result.Prebuild.OnCreate(),
+		devenv.PostCreateScript: result.Prebuild.PostCreate(),
+	}
+	for _, name := range []string{devenv.OnCreateScript, devenv.PostCreateScript} {
+		if err := writeWorkspaceFile(result.Workspace, name, []byte(files[name]), 0755); err != nil {
+			return err
+		}
+		result.Written = append(result.Written, name)
+	}
+
+	devcontainer, configured, err := findDevcontainer(result.Workspace)
+	if err != nil {
+		return err
+	}
+	if devcontainer == "" {
+		data, err := result.Prebuild.Devcontainer(filepath.Base(result.Workspace))
+		if err != nil {
+			return err
+		}
+		if err := writeWorkspaceFile(result.Workspace, devenv.DevcontainerFile, data, 0644); err != nil {
+			return err
+		}
+		result.Written = append(result.Written, devenv.DevcontainerFile)
+		devcontainer, configured = devenv.DevcontainerFile, true
+	}
+	result.Devcontainer, result.Configured = devcontainer, configured
+	return nil
+}
+
+// writeWorkspaceFile writes data to name, relative to workspace, creating its directory.
+func writeWorkspaceFile(workspace, name string, data []byte, perm os.FileMode) error {
+	path := filepath.Join(workspace, filepath.FromSlash(name))
+	if err := security.ValidatePath(path); err != nil {
+		return fmt.Errorf("invalid path: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(name), err)
+	}
+	// #nosec G306 -- Scripts must be executable and, like devcontainer.json, are shared through the repository
+	if err := os.WriteFile(path, data, perm); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return nil
+}
+
+// findDevcontainer returns the devcontainer.json of workspace, relative to it, or "" when it
+// has none, and whether it runs the prebuild scripts.
+func findDevcontainer(workspace string) (string, bool, error) {
+	for _, name := range []string{devenv.DevcontainerFile, ".devcontainer.json"} {
+		path := filepath.Join(workspace, filepath.FromSlash(name))
+		if err := security.ValidatePath(path); err != nil {
+			return "", false, fmt.Errorf("invalid path: %w", err)
+		}
+		// #nosec G304 -- Path validated by security.ValidatePath
+		data, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return "", false, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		content := string(data)
+		return name, strings.Contains(content, devenv.OnCreateScript) && strings.Contains(content, devenv.PostCreateScript), nil
+	}
+	return "", false, nil
+}
+
+// printPrebuildResult prints the prebuild steps and the files written, or how to write them.
+func printPrebuildResult(result prebuildResult) {
+	output.Section("📦", fmt.Sprintf("Prebuild: %s", result.Workspace))
+	if len(result.Prebuild.Install) == 0 && len(result.Prebuild.Warm) == 0 {
+		output.Item("No projects or Dockerfiles were detected.")
+	}
+	for _, s := range result.Prebuild.Install {
+		output.Item("%s: %s %s", s.Name, s.Command, output.Muted("(in %s)", s.Dir))
+	}
+	for _, s := range result.Prebuild.Warm {
+		output.Item("%s %s", s.Command, output.Muted("(cache)"))
+	}
+	output.Newline()
+
+	if !prebuildWrite {
+		output.Info("💡 Run 'azd app prebuild --write' to write the scripts")
+		return
+	}
+	for _, name := range result.Written {
+		output.ItemSuccess("Wrote %s", name)
+	}
+	if !result.Configured {
+		output.ItemWarning("%s doesn't run the scripts yet; add:", result.Devcontainer)
+		output.Item(`"onCreateCommand": "%s",`, devenv.LifecycleCommand(devenv.OnCreateScript))
+		output.Item(`"postCreateCommand": "%s"`, devenv.LifecycleCommand(devenv.PostCreateScript))
+	}
+	output.Newline()
+	output.Info("💡 Enable prebuilds for the repository in its Codespaces settings to run on-create.sh ahead of time")
+}
@@ -0,0 +1,79 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jongio/azd-app/cli/src/internal/devenv"
+)
+
+func TestPrebuildPlan(t *testing.T) {
+	workspace := t.TempDir()
+	files := map[string]string{
+		"web/package.json":  `{"name": "web", "scripts": {"dev": "vite"}}`,
+		"web/Dockerfile":    "FROM node:20-alpine AS build\nRUN npm ci\nFROM nginx:alpine\n",
+		"api/Dockerfile":    "ARG BASE=python:3.12\nFROM ${BASE}\n",
+		"worker/Dockerfile": "FROM golang:1.23 AS build\nFROM scratch\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(workspace, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	plan := prebuildPlan(workspace)
+	if len(plan.Install) != 1 || plan.Install[0].Dir != "web" || plan.Install[0].Command != "npm install" {
+		t.Errorf("Install = %+v, want npm install in web", plan.Install)
+	}
+	var pulls []string
+	for _, step := range plan.Warm {
+		pulls = append(pulls, step.Command)
+	}
+	if want := "docker pull nginx:alpine,docker pull python:3.12"; strings.Join(pulls, ",") != want {
+		t.Errorf("Warm = %v, want %s", pulls, want)
+	}
+}
+
+func TestWritePrebuild(t *testing.T) {
+	workspace := t.TempDir()
+	result := prebuildResult{Workspace: workspace, Prebuild: devenv.Prebuild{
+		Install: []devenv.PrebuildStep{{Name: "web", Dir: "web", Command: "npm install"}},
+	}}
+
+	if err := writePrebuild(&result); err != nil {
+		t.Fatalf("writePrebuild() error = %v", err)
+	}
+	if !result.Configured || result.Devcontainer != devenv.DevcontainerFile || len(result.Written) != 3 {
+		t.Errorf("result = %+v, want both scripts and a devcontainer.json that runs them", result)
+	}
+	info, err := os.Stat(filepath.Join(workspace, filepath.FromSlash(devenv.OnCreateScript)))
+	if err != nil || info.Mode().Perm()&0100 == 0 {
+		t.Errorf("on-create.sh is not executable: %v", err)
+	}
+
+	// An existing devcontainer.json is left as it is
+	devcontainer := filepath.Join(workspace, filepath.FromSlash(devenv.DevcontainerFile))
+	existing := "{\n  // Team settings\n  \"image\": \"mcr.microsoft.com/devcontainers/go:1\"\n}\n"
+	if err := os.WriteFile(devcontainer, []byte(existing), 0600); err != nil {
+		t.Fatal(err)
+	}
+	result = prebuildResult{Workspace: workspace}
+	if err := writePrebuild(&result); err != nil {
+		t.Fatalf("writePrebuild() error = %v", err)
+	}
+	if result.Configured || len(result.Written) != 2 {
+		t.Errorf("result = %+v, want the scripts written and devcontainer.json reported as not running them", result)
+	}
+	if data, _ := os.ReadFile(devcontainer); string(data) != existing {
+		t.Errorf("devcontainer.json was changed:\n%s", data)
+	}
+	if data, _ := os.ReadFile(filepath.Join(workspace, filepath.FromSlash(devenv.PostCreateScript))); !strings.Contains(string(data), "azd app reqs") {
+		t.Errorf("post-create.sh does not check the requirements:\n%s", data)
+	}
+}
@@ -0,0 +1,250 @@
+package commands
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/jongio/azd-app/cli/src/internal/browser"
+	"github.com/jongio/azd-app/cli/src/internal/detector"
+	"github.com/jongio/azd-app/cli/src/internal/diagnostics"
+	"github.com/jongio/azd-app/cli/src/internal/output"
+	"github.com/jongio/azd-app/cli/src/internal/security"
+
+	"github.com/spf13/cobra"
+)
+
+// issueRepoURL is the GitHub repository that receives bug reports.
+const issueRepoURL = "https://github.com/jongio/azd-app"
+
+// maxIssueURLLength keeps the pre-filled URL below the length GitHub accepts.
+const maxIssueURLLength = 8000
+
+// bundleReviewWarning asks users to check a diagnostics bundle before they attach it to a public issue.
+const bundleReviewWarning = "Review the bundle before attaching it: secret-looking values are redacted, but it includes azure.yaml and the end of recent service logs."
+
+// issueReportTools lists the tools whose versions are included in issue reports.
+var issueReportTools = []string{"azd", "node", "python", "dotnet", "docker", "git"}
+
+var (
+	reportIssueTitle    string
+	reportIssueBundle   string
+	reportIssueNoBundle bool
+	reportIssuePrint    bool
+)
+
+// IssueReport contains the environment details included in a pre-filled issue.
+type IssueReport struct {
+	Title         string            `json:"title"`
+	Version       string            `json:"version"`
+	BuildTime     string            `json:"buildTime"`
+	OS            string            `json:"os"`
+	Arch          string            `json:"arch"`
+	GoVersion     string            `json:"goVersion"`
+	AzureYamlPath string            `json:"azureYamlPath,omitempty"`
+	Tools         map[string]string `json:"tools"`
+	BundlePath    string            `json:"bundlePath,omitempty"`
+	URL           string            `json:"url"`
+}
+
+// NewReportIssueCommand creates the report-issue command.
+func NewReportIssueCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "report-issue",
+		Short: "Open a pre-filled GitHub issue with environment details",
+		Long: `Collects extension version, platform, and tool versions and opens the
+GitHub new-issue page with those details pre-filled in the issue body.
+
+A diagnostics bundle with these details, azure.yaml, the service registry, and the end of
+recent service logs is generated for you to attach to the issue. Use --bundle to reference
+a bundle you already have instead, or --no-bundle to skip it.`,
+		RunE: runReportIssue,
+	}
+
+	cmd.Flags().StringVar(&reportIssueTitle, "title", "", "Issue title")
+	cmd.Flags().StringVar(&reportIssueBundle, "bundle", "", "Path to an existing diagnostics bundle to reference instead of generating one")
+	cmd.Flags().BoolVar(&reportIssueNoBundle, "no-bundle", false, "Do not generate a diagnostics bundle")
+	cmd.Flags().BoolVar(&reportIssuePrint, "print", false, "Print the issue URL instead of opening a browser")
+
+	return cmd
+}
+
+// runReportIssue executes the report-issue command.
+func runReportIssue(cmd *cobra.Command, args []string) error {
+	if reportIssueBundle != "" && reportIssueNoBundle {
+		return fmt.Errorf("--bundle and --no-bundle cannot be used together")
+	}
+	if reportIssueBundle != "" {
+		if err := security.ValidatePath(reportIssueBundle); err != nil {
+			return fmt.Errorf("invalid bundle path: %w", err)
+		}
+		if _, err := os.Stat(reportIssueBundle); err != nil {
+			return fmt.Errorf("diagnostics bundle not found: %w", err)
+		}
+		if abs, err := filepath.Abs(reportIssueBundle); err == nil {
+			reportIssueBundle = abs
+		}
+	}
+
+	report := collectIssueReport(reportIssueTitle, reportIssueBundle)
+	if reportIssueBundle == "" && !reportIssueNoBundle {
+		path, err := writeIssueBundle(report)
+		if err != nil {
+			output.Warning("Could not generate a diagnostics bundle: %v", err)
+		}
+		report.BundlePath = path
+	}
+	report.URL = buildIssueURL(report)
+
+	if output.IsJSON() {
+		return output.PrintJSON(report)
+	}
+
+	if reportIssuePrint {
+		fmt.Println(report.URL)
+		if report.BundlePath != "" {
+			// Standard output only has the URL, so it can be piped
+			fmt.Fprintf(output.ErrWriter(), "Attach the diagnostics bundle to the issue: %s\n", report.BundlePath)
+			fmt.Fprintln(output.ErrWriter(), bundleReviewWarning)
+		}
+		return nil
+	}
+
+	output.Info("Opening GitHub issue page...")
+	opened := true
+	if err := browser.Open(report.URL); err != nil {
+		output.Warning("Could not open browser: %v", err)
+		output.Item("Open this URL manually:")
+		output.Item("%s", report.URL)
+		opened = false
+	}
+
+	if report.BundlePath != "" {
+		output.Item("Attach the diagnostics bundle to the issue: %s", report.BundlePath)
+		output.ItemWarning("%s", bundleReviewWarning)
+	}
+	if opened {
+		output.Success("Issue page opened")
+	}
+	return nil
+}
+
+// writeIssueBundle generates a diagnostics bundle for report in the workspace state of the
+// project, or of the current directory outside a project, and returns its path.
+func writeIssueBundle(report *IssueReport) (string, error) {
+	projectDir := ""
+	if report.AzureYamlPath != "" {
+		projectDir = filepath.Dir(report.AzureYamlPath)
+	} else if cwd, err := os.Getwd(); err == nil {
+		projectDir = cwd
+	}
+	path, err := diagnostics.DefaultPath(projectDir, time.Now())
+	if err != nil {
+		return "", err
+	}
+	if _, err := diagnostics.Write(path, diagnostics.Options{ProjectDir: projectDir, Report: report}); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// collectIssueReport gathers environment details for an issue report.
+func collectIssueReport(title, bundlePath string) *IssueReport {
+	report := &IssueReport{
+		Title:      title,
+		Version:    Version,
+		BuildTime:  BuildTime,
+		OS:         runtime.GOOS,
+		Arch:       runtime.GOARCH,
+		GoVersion:  runtime.Version(),
+		Tools:      make(map[string]string),
+		BundlePath: bundlePath,
+	}
+
+	if cwd, err := os.Getwd(); err == nil {
+		if azureYamlPath, err := detector.FindAzureYaml(cwd); err == nil {
+			report.AzureYamlPath = azureYamlPath
+		}
+	}
+
+	for _, tool := range issueReportTools {
+		version, err := getToolVersion(tool)
+		if err != nil {
+			report.Tools[tool] = "not installed"
+			continue
+		}
+		report.Tools[tool] = version
+	}
+
+	return report
+}
+
+// buildIssueBody renders the markdown body of an issue report.
+func buildIssueBody(report *IssueReport) string {
+	var b strings.Builder
+
+	b.WriteString("## Description\n\n")
+	b.WriteString("<!-- What happened? What did you expect to happen? -->\n\n")
+	b.WriteString("## Steps to reproduce\n\n1. \n\n")
+
+	b.WriteString("## Environment\n\n")
+	b.WriteString(fmt.Sprintf("- azd app version: %s (built %s)\n", report.Version, report.BuildTime))
+	b.WriteString(fmt.Sprintf("- OS/Arch: %s/%s\n", report.OS, report.Arch))
+	b.WriteString(fmt.Sprintf("- Go runtime: %s\n", report.GoVersion))
+	if report.AzureYamlPath != "" {
+		b.WriteString("- azure.yaml: found\n")
+	} else {
+		b.WriteString("- azure.yaml: not found\n")
+	}
+
+	b.WriteString("\n## Tool versions\n\n")
+	for _, tool := range issueReportTools {
+		if version, ok := report.Tools[tool]; ok {
+			b.WriteString(fmt.Sprintf("- %s: %s\n", tool, version))
+		}
+	}
+
+	if report.BundlePath != "" {
+		b.WriteString("\n## Diagnostics bundle\n\n")
+		b.WriteString(fmt.Sprintf("Generated bundle: `%s` (attach it to this issue)\n", filepath.Base(report.BundlePath)))
+	}
+
+	return b.String()
+}
+
+// buildIssueURL builds the GitHub new-issue URL with a pre-filled title and body.
+// The body is truncated if the resulting URL would exceed GitHub's length limit.
+func buildIssueURL(report *IssueReport) string {
+	body := buildIssueBody(report)
+
+	build := func(body string) string {
+		params := url.Values{}
+		if report.Title != "" {
+			params.Set("title", report.Title)
+		}
+		params.Set("body", body)
+		return issueRepoURL + "/issues/new?" + params.Encode()
+	}
+
+	issueURL := build(body)
+	const truncationNote = "\n\n_(truncated)_"
+	for len(issueURL) > maxIssueURLLength && len(body) > 0 {
+		cut := len(body) - (len(issueURL) - maxIssueURLLength) - len(truncationNote)
+		if cut < 0 {
+			cut = 0
+		}
+		// Cut at the start of a rune, so multi-byte characters aren't split
+		for cut > 0 && !utf8.RuneStart(body[cut]) {
+			cut--
+		}
+		body = body[:cut]
+		issueURL = build(body + truncationNote)
+	}
+
+	return issueURL
+}
@@ -0,0 +1,119 @@
+package commands
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestNewReportIssueCommand(t *testing.T) {
+	cmd := NewReportIssueCommand()
+
+	if cmd.Use != "report-issue" {
+		t.Errorf("Use = %q, want %q", cmd.Use, "report-issue")
+	}
+
+	for _, flag := range []string{"title", "bundle", "no-bundle", "print"} {
+		if cmd.Flags().Lookup(flag) == nil {
+			t.Errorf("Expected flag %q to exist", flag)
+		}
+	}
+}
+
+func TestBuildIssueBody(t *testing.T) {
+	report := &IssueReport{
+		Version:    "1.2.3",
+		BuildTime:  "2025-01-01",
+		OS:         "linux",
+		Arch:       "amd64",
+		GoVersion:  "go1.25",
+		Tools:      map[string]string{"node": "22.3.0", "docker": "not installed"},
+		BundlePath: "/tmp/diag/azd-app-diagnostics.zip",
+	}
+
+	body := buildIssueBody(report)
+
+	expected := []string{
+		"azd app version: 1.2.3",
+		"OS/Arch: linux/amd64",
+		"- node: 22.3.0",
+		"- docker: not installed",
+		"azure.yaml: not found",
+		"azd-app-diagnostics.zip",
+	}
+	for _, want := range expected {
+		if !strings.Contains(body, want) {
+			t.Errorf("buildIssueBody() missing %q\nbody:\n%s", want, body)
+		}
+	}
+
+	// Full local paths should not leak into the issue body
+	if strings.Contains(body, "/tmp/diag") {
+		t.Errorf("buildIssueBody() should only include the bundle file name, got:\n%s", body)
+	}
+}
+
+func TestBuildIssueURL(t *testing.T) {
+	report := &IssueReport{
+		Title:   "Crash on run",
+		Version: "dev",
+		Tools:   map[string]string{},
+	}
+
+	issueURL := buildIssueURL(report)
+
+	if !strings.HasPrefix(issueURL, issueRepoURL+"/issues/new?") {
+		t.Fatalf("buildIssueURL() = %q, want prefix %q", issueURL, issueRepoURL+"/issues/new?")
+	}
+
+	parsed, err := url.Parse(issueURL)
+	if err != nil {
+		t.Fatalf("buildIssueURL() produced invalid URL: %v", err)
+	}
+	if got := parsed.Query().Get("title"); got != "Crash on run" {
+		t.Errorf("title = %q, want %q", got, "Crash on run")
+	}
+	if !strings.Contains(parsed.Query().Get("body"), "azd app version: dev") {
+		t.Errorf("body missing version, got %q", parsed.Query().Get("body"))
+	}
+}
+
+func TestBuildIssueURLTruncatesLongBodies(t *testing.T) {
+	tools := make(map[string]string)
+	for _, tool := range issueReportTools {
+		tools[tool] = strings.Repeat("x", 3000)
+	}
+	report := &IssueReport{Version: "dev", Tools: tools}
+
+	issueURL := buildIssueURL(report)
+
+	if len(issueURL) > maxIssueURLLength {
+		t.Errorf("buildIssueURL() length = %d, want <= %d", len(issueURL), maxIssueURLLength)
+	}
+	parsed, err := url.Parse(issueURL)
+	if err != nil {
+		t.Fatalf("buildIssueURL() produced invalid URL: %v", err)
+	}
+	if !strings.Contains(parsed.Query().Get("body"), "(truncated)") {
+		t.Error("expected truncated body to include truncation note")
+	}
+}
+
+func TestBuildIssueURLTruncatesOnRuneBoundary(t *testing.T) {
+	tools := make(map[string]string)
+	for _, tool := range issueReportTools {
+		tools[tool] = strings.Repeat("é", 250)
+	}
+	// Titles of different lengths move where the body is cut
+	for n := range 4 {
+		report := &IssueReport{Title: strings.Repeat("t", n), Version: "dev", Tools: tools}
+		parsed, err := url.Parse(buildIssueURL(report))
+		if err != nil {
+			t.Fatalf("buildIssueURL() produced invalid URL: %v", err)
+		}
+		if body := parsed.Query().Get("body"); !utf8.ValidString(body) {
+			t.Errorf("title of %d bytes: truncated body is not valid UTF-8: %q", n, body[len(body)-40:])
+		}
+	}
+}
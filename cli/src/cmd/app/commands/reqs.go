@@ -1,39 +1,23 @@
 package commands
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
 	"regexp"
 	"strings"
 
+	"github.com/jongio/azd-app/cli/src/internal/azureyaml"
 	"github.com/jongio/azd-app/cli/src/internal/cache"
+	"github.com/jongio/azd-app/cli/src/internal/container"
 	"github.com/jongio/azd-app/cli/src/internal/output"
 
 	"github.com/spf13/cobra"
 )
 
-// Prerequisite represents a prerequisite from azure.yaml.
-type Prerequisite struct {
-	Name       string `yaml:"name"`
-	MinVersion string `yaml:"minVersion"`
-	// Custom tool configuration (optional)
-	Command       string   `yaml:"command,omitempty"`       // Override command to execute
-	Args          []string `yaml:"args,omitempty"`          // Override arguments
-	VersionPrefix string   `yaml:"versionPrefix,omitempty"` // Override version prefix to strip
-	VersionField  int      `yaml:"versionField,omitempty"`  // Override which field contains version
-	// Runtime check configuration (optional)
-	CheckRunning         bool     `yaml:"checkRunning,omitempty"`         // Whether to check if the tool is running
-	RunningCheckCommand  string   `yaml:"runningCheckCommand,omitempty"`  // Command to check if tool is running
-	RunningCheckArgs     []string `yaml:"runningCheckArgs,omitempty"`     // Arguments for running check command
-	RunningCheckExpected string   `yaml:"runningCheckExpected,omitempty"` // Expected substring in output (optional)
-	RunningCheckExitCode *int     `yaml:"runningCheckExitCode,omitempty"` // Expected exit code (default: 0)
-}
-
-// AzureYaml represents the structure of azure.yaml.
-type AzureYaml struct {
-	Reqs []Prerequisite `yaml:"reqs"`
-}
+// Prerequisite is a tool listed in the reqs section of azure.yaml.
+type Prerequisite = azureyaml.Requirement
 
 // ReqResult represents the result of checking a requirement.
 type ReqResult struct {
@@ -105,11 +89,24 @@ var toolRegistry = map[string]ToolConfig{
 		Command: "aspire",
 		Args:    []string{"--version"},
 	},
+	"func": {
+		Command: "func",
+		Args:    []string{"--version"},
+	},
+	"swa": {
+		Command: "swa",
+		Args:    []string{"--version"},
+	},
 	"docker": {
 		Command:      "docker",
 		Args:         []string{"--version"},
 		VersionField: 2, // "Docker version 28.5.1, build ..." -> take field 2
 	},
+	"podman": {
+		Command:      "podman",
+		Args:         []string{"--version"},
+		VersionField: 2, // "podman version 5.2.2" -> take field 2
+	},
 	"git": {
 		Command:      "git",
 		Args:         []string{"--version"},
@@ -152,7 +149,8 @@ are installed and meet the minimum version reqs.
 With --generate, it scans your project to detect dependencies and automatically
 generates the reqs section in azure.yaml based on what's installed on your machine.
 
-The command caches results in .azure/cache/ to improve performance on subsequent runs.
+The command caches results in the workspace's cache directory (see 'azd app cache')
+to improve performance on subsequent runs.
 Use --no-cache to force a fresh check and bypass cached results.`,
 		PreRunE: func(cmd *cobra.Command, args []string) error {
 			// Try to get the output flag from parent or self
@@ -202,6 +200,29 @@ Use --no-cache to force a fresh check and bypass cached results.`,
 	return cmd
 }
 
+// loadReqs reads the reqs section of azure.yaml.
+func loadReqs(azureYamlPath string) ([]Prerequisite, error) {
+	doc, err := azureyaml.Load(azureYamlPath)
+	if err != nil {
+		return nil, err
+	}
+	return doc.Project.Reqs, nil
+}
+
+// printSchemaIssues lists the places where azure.yaml does not match the azd schema.
+func printSchemaIssues(issues []azureyaml.Issue) {
+	for _, issue := range issues {
+		if issue.Severity == azureyaml.SeverityError {
+			output.ItemError("azure.yaml %s", issue)
+		} else {
+			output.ItemWarning("azure.yaml %s", issue)
+		}
+	}
+	if len(issues) > 0 {
+		output.Newline()
+	}
+}
+
 func runReqs() error {
 	// Use orchestrator to execute reqs check with caching support
 	return executeReqs()
@@ -250,6 +271,32 @@ func checkPrerequisiteWithResult(prereq Prerequisite) ReqResult {
 	// Check if the tool is running (if configured)
 	if prereq.CheckRunning {
 		result.CheckedRun = true
+		// Container engines are checked where they run, which may be a remote host
+		if isContainerEngine(prereq) {
+			engine, err := checkContainerEngine(prereq.Name)
+			result.Running = err == nil
+			if err != nil {
+				result.Message = err.Error()
+				if !output.IsJSON() {
+					output.Item("- %s✗%s NOT RUNNING: %v", output.Red, output.Reset, err)
+				}
+				return result
+			}
+			result.Satisfied = true
+			result.Message = "Running"
+			where := engine.String()
+			if info, err := engine.Inspect(context.Background()); err == nil {
+				where += ", " + info.Distribution
+				if info.Rootless {
+					where += ", rootless"
+				}
+				result.Message = "Running on " + info.Distribution
+			}
+			if !output.IsJSON() {
+				output.Item("- %s✓%s RUNNING (%s)", output.Green, output.Reset, where)
+			}
+			return result
+		}
 		isRunning := checkIsRunning(prereq)
 		result.Running = isRunning
 		if !isRunning {
@@ -396,6 +443,22 @@ func compareVersions(installed, required string) bool {
 	return true // All parts equal or installed version is longer
 }
 
+// isContainerEngine reports whether prereq is a container engine without a custom running check.
+func isContainerEngine(prereq Prerequisite) bool {
+	return prereq.RunningCheckCommand == "" && (prereq.Name == container.EngineDocker || prereq.Name == container.EnginePodman)
+}
+
+// checkContainerEngine returns the engine named name, on the host the environment configures,
+// and an error if it can't be reached there.
+func checkContainerEngine(name string) (container.Engine, error) {
+	engine, err := container.Current()
+	if err != nil {
+		return engine, err
+	}
+	engine.Name = name
+	return engine, engine.Check(context.Background())
+}
+
 func checkIsRunning(prereq Prerequisite) bool {
 	// If no custom running check is configured, use defaults based on tool ID
 	command := prereq.RunningCheckCommand
@@ -407,14 +470,12 @@ func checkIsRunning(prereq Prerequisite) bool {
 
 	// Default checks for known tools
 	if command == "" {
-		switch prereq.Name {
-		case "docker":
-			command = "docker"
-			args = []string{"ps"}
-		default:
-			// No default running check for this tool
-			return true
+		if isContainerEngine(prereq) {
+			_, err := checkContainerEngine(prereq.Name)
+			return err == nil
 		}
+		// No default running check for this tool
+		return true
 	}
 
 	// #nosec G204 -- Command and args come from azure.yaml running check configuration or default Docker check
@@ -7,6 +7,8 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/jongio/azd-app/cli/src/internal/azureyaml"
+
 	"gopkg.in/yaml.v3"
 )
 
@@ -543,7 +545,7 @@ reqs:
 		t.Fatal(err)
 	}
 
-	var azureYaml AzureYaml
+	var azureYaml azureyaml.Project
 	if err := yaml.Unmarshal(data, &azureYaml); err != nil {
 		t.Fatal(err)
 	}
@@ -773,7 +775,7 @@ reqs:
 		t.Fatal(err)
 	}
 
-	var azureYaml AzureYaml
+	var azureYaml azureyaml.Project
 	if err := yaml.Unmarshal(data, &azureYaml); err != nil {
 		t.Fatal(err)
 	}
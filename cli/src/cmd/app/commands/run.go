@@ -1,18 +1,31 @@
 package commands
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
 	"syscall"
+	"time"
 
+	"github.com/jongio/azd-app/cli/src/internal/chaos"
+	"github.com/jongio/azd-app/cli/src/internal/daemon"
 	"github.com/jongio/azd-app/cli/src/internal/dashboard"
 	"github.com/jongio/azd-app/cli/src/internal/detector"
-	"github.com/jongio/azd-app/cli/src/internal/executor"
+	"github.com/jongio/azd-app/cli/src/internal/devenv"
+	"github.com/jongio/azd-app/cli/src/internal/guide"
 	"github.com/jongio/azd-app/cli/src/internal/output"
+	"github.com/jongio/azd-app/cli/src/internal/runhistory"
 	"github.com/jongio/azd-app/cli/src/internal/service"
+	"github.com/jongio/azd-app/cli/src/internal/smoke"
+	"github.com/jongio/azd-app/cli/src/internal/soak"
+	"github.com/jongio/azd-app/cli/src/internal/statedir"
+	"github.com/jongio/azd-app/cli/src/internal/workerpool"
 
 	"github.com/spf13/cobra"
 )
@@ -24,10 +37,38 @@ const (
 
 var (
 	runServiceFilter string
-	runEnvFile       string
+	runEnvFiles      []string
+	runEnvironment   string
 	runVerbose       bool
 	runDryRun        bool
 	runRuntime       string
+	runSmoke         bool
+	runGuided        bool
+	runChaos         bool
+	runChaosInterval time.Duration
+	runChaosPause    time.Duration
+	runChaosActions  []string
+	runChaosExclude  []string
+	runWatch         bool
+	runOnly          []string
+	runExcept        []string
+	runNoDeps        bool
+	runDetach        bool
+	runDashboard     bool
+	runTUI           bool
+	runSoak          time.Duration
+	runSoakInterval  time.Duration
+
+	runInstall            string
+	runInstallConcurrency int
+
+	runNoForward      bool
+	runPortVisibility string
+
+	runNoEmulators bool
+
+	runLaunchProfiles []string
+	runStrict         bool
 )
 
 // NewRunCommand creates the run command.
@@ -43,10 +84,37 @@ func NewRunCommand() *cobra.Command {
 
 	// Add flags for service orchestration
 	cmd.Flags().StringVarP(&runServiceFilter, "service", "s", "", "Run specific service(s) only (comma-separated)")
-	cmd.Flags().StringVar(&runEnvFile, "env-file", "", "Load environment variables from .env file")
+	_ = cmd.RegisterFlagCompletionFunc("service", completeServiceNames)
+	cmd.Flags().StringSliceVar(&runOnly, "only", nil, "Run only these services and the services they depend on (comma-separated)")
+	_ = cmd.RegisterFlagCompletionFunc("only", completeServiceNames)
+	cmd.Flags().StringSliceVar(&runExcept, "except", nil, "Don't run these services (comma-separated)")
+	_ = cmd.RegisterFlagCompletionFunc("except", completeServiceNames)
+	cmd.Flags().BoolVar(&runNoDeps, "no-deps", false, "With --only, don't add the services the selected services depend on")
+	cmd.Flags().StringVar(&runInstall, "install", "", "When to install dependencies before starting: 'always', 'missing' (projects without node_modules, .venv, or obj), or 'never' (default: run.install in .azdapp.yaml, or always)")
+	cmd.Flags().IntVar(&runInstallConcurrency, "install-concurrency", defaultInstallConcurrency, "Projects installed at once with --install missing")
+	cmd.Flags().StringArrayVar(&runEnvFiles, "env-file", nil, "Load environment variables from .env file, over those of the .env files of services (repeatable)")
+	cmd.Flags().StringVarP(&runEnvironment, "environment", "e", "", "azd environment whose values services get (default: AZURE_ENV_NAME, or the environment selected with 'azd env select')")
 	cmd.Flags().BoolVarP(&runVerbose, "verbose", "v", false, "Enable verbose logging")
 	cmd.Flags().BoolVar(&runDryRun, "dry-run", false, "Show what would be run without starting services")
-	cmd.Flags().StringVar(&runRuntime, "runtime", runtimeModeAzd, "Runtime mode: 'azd' (azd dashboard) or 'aspire' (native Aspire with dotnet run)")
+	cmd.Flags().BoolVarP(&runDetach, "detach", "d", false, "Start services in the background and return once they are ready; see 'azd app status' and 'azd app stop'")
+	cmd.Flags().BoolVar(&runDashboard, "dashboard", false, "Serve a local web dashboard with the status, health, and logs of services, and buttons to restart them")
+	cmd.Flags().BoolVar(&runTUI, "tui", false, "Show a pane with the output of each service in a terminal UI, with keys to restart, stop, scroll, and filter")
+	cmd.Flags().DurationVar(&runSoak, "soak", 0, "Keep services up for this long, e.g. 2h, sampling their health, memory, and errors, then stop them and report how they held up")
+	cmd.Flags().DurationVar(&runSoakInterval, "soak-interval", defaultSoakInterval, "Time between samples with --soak")
+	cmd.Flags().StringVar(&runRuntime, "runtime", runtimeModeAzd, "Runtime mode: 'azd' (start each service) or 'aspire' (run the AppHost, which starts its resources, and attach to its dashboard)")
+	cmd.Flags().BoolVar(&runGuided, "guided", false, "Walk through detected services, missing tools, and environment variables before starting")
+	cmd.Flags().BoolVar(&runSmoke, "smoke", false, "Load frontend services in a headless browser after startup and report console errors")
+	cmd.Flags().BoolVar(&runChaos, "chaos", false, "Randomly kill, pause, or restart services during the session")
+	cmd.Flags().DurationVar(&runChaosInterval, "chaos-interval", chaos.DefaultInterval, "Time between chaos actions")
+	cmd.Flags().DurationVar(&runChaosPause, "chaos-pause", chaos.DefaultPauseDuration, "How long a paused service stays suspended")
+	cmd.Flags().StringSliceVar(&runChaosActions, "chaos-actions", []string{"kill", "pause", "restart"}, "Chaos actions to choose from (kill, pause, restart)")
+	cmd.Flags().StringSliceVar(&runChaosExclude, "chaos-exclude", nil, "Services that chaos actions never touch")
+	cmd.Flags().BoolVar(&runWatch, "watch", false, "Reload services when their source changes, using native watchers where available")
+	cmd.Flags().BoolVar(&runNoForward, "no-forward", false, "In a codespace, keep localhost service URLs and don't set the visibility of forwarded ports")
+	cmd.Flags().StringVar(&runPortVisibility, "port-visibility", devenv.VisibilityPrivate, "Visibility of forwarded codespace ports: 'private', 'org', or 'public'")
+	cmd.Flags().BoolVar(&runNoEmulators, "no-emulators", false, "Don't start local emulators of the resources in azure.yaml; services get the values of the azd environment instead")
+	cmd.Flags().BoolVar(&runStrict, "strict", false, "Fail instead of warning when the installed node, package manager, Python, or .NET SDK doesn't satisfy the versions the projects pin")
+	cmd.Flags().StringArrayVar(&runLaunchProfiles, "launch-profile", nil, "Run .NET services with this profile of their launchSettings.json: 'profile' for every service that has it, or 'service=profile' (repeatable)")
 
 	return cmd
 }
@@ -56,10 +124,41 @@ func runWithServices(_ *cobra.Command, _ []string) error {
 	if err := validateRuntimeMode(runRuntime); err != nil {
 		return err
 	}
+	if runWatch && runChaos {
+		return fmt.Errorf("--watch cannot be combined with --chaos")
+	}
+	if err := devenv.ValidateVisibility(runPortVisibility); err != nil {
+		return err
+	}
+	if runDetach && (runGuided || runRuntime == runtimeModeAspire) {
+		return fmt.Errorf("--detach cannot be combined with --guided or --runtime aspire")
+	}
+	if runSoak < 0 || runSoakInterval <= 0 {
+		return fmt.Errorf("--soak and --soak-interval must be positive durations")
+	}
+	if runTUI && (runDetach || output.IsJSON()) {
+		return fmt.Errorf("--tui cannot be combined with --detach or --output json")
+	}
+
+	// Start this command again in the background; the session itself runs in the foreground
+	if runDetach && !runDryRun && !daemon.IsChild() {
+		return runDetached()
+	}
+
+	// Offer the guided walkthrough on the first run of a template, unless nobody would see it
+	if azureYamlPath, err := findAzureYaml(); err == nil && !runDetach {
+		prompter := guide.NewPrompter(os.Stdin, os.Stderr)
+		guided, err := shouldRunGuided(azureYamlPath, prompter)
+		if err != nil {
+			return err
+		}
+		if guided {
+			return runGuidedSetup(azureYamlPath, prompter)
+		}
+	}
 
-	// Execute dependencies first (reqs -> deps -> run)
-	if err := cmdOrchestrator.Run("run"); err != nil {
-		return fmt.Errorf("failed to execute command dependencies: %w", err)
+	if err := runPrerequisites(); err != nil {
+		return err
 	}
 
 	azureYamlPath, err := findAzureYaml()
@@ -103,6 +202,9 @@ func runServicesFromAzureYaml(azureYamlPath string, runtimeMode string) error {
 
 	// Aspire mode: run AppHost directly
 	if runtimeMode == runtimeModeAspire {
+		if sandboxMode {
+			return fmt.Errorf("--sandbox is not supported with --runtime aspire")
+		}
 		return runAspireMode(azureYamlDir)
 	}
 
@@ -129,23 +231,59 @@ func runAzdMode(azureYamlPath, azureYamlDir string) error {
 	}
 
 	// Filter and detect services
-	services := filterServices(azureYaml)
+	services, err := filterServices(azureYaml, azureYamlDir)
+	if err != nil {
+		return err
+	}
 	if len(services) == 0 {
 		return fmt.Errorf("no services match filter: %s", runServiceFilter)
 	}
+	showAddedDependencies(services)
+	services = deferToAppHost(services, azureYamlDir)
+	services, err = service.ApplyLaunchProfiles(services, azureYamlDir, runLaunchProfiles)
+	if err != nil {
+		return err
+	}
 
 	runtimes, err := detectServiceRuntimes(services, azureYamlDir, runtimeModeAzd)
 	if err != nil {
 		return err
 	}
+	if err := checkToolchain(runtimes, azureYamlDir, localToolchain()); err != nil {
+		return err
+	}
+	if err := prepareRuntimes(azureYaml, azureYamlDir, runtimes); err != nil {
+		return err
+	}
 
 	// Dry-run mode: show what would be executed
 	if runDryRun {
-		return showDryRun(runtimes)
+		return showDryRun(runtimes, emulatedResources(azureYaml, services))
+	}
+
+	// Remove old logs and caches before this session starts writing its own
+	collectGarbage(azureYamlDir)
+
+	// Emulated resources start before the services that use them and stop after them
+	stopEmulators, err := startEmulators(azureYaml, azureYamlDir, services, runtimes)
+	if err != nil {
+		return err
+	}
+	defer stopEmulators()
+
+	// A background session is over when this process exits
+	if daemon.IsChild() {
+		defer daemon.Clear(cwd)
 	}
 
 	// Execute and monitor services
-	return executeAndMonitorServices(runtimes, cwd)
+	session := &runSession{
+		azureYamlPath: azureYamlPath,
+		azureYamlDir:  azureYamlDir,
+		cwd:           cwd,
+		services:      services,
+	}
+	return executeAndMonitorServices(runtimes, session)
 }
 
 // showNoServicesMessage displays a message when no services are defined.
@@ -156,13 +294,47 @@ func showNoServicesMessage() error {
 	return nil
 }
 
-// filterServices applies service filtering based on --service flag.
-func filterServices(azureYaml *service.AzureYaml) map[string]service.Service {
-	if runServiceFilter == "" {
-		return azureYaml.Services
+// filterServices selects the services to run with --service, or with --only and --except.
+// --only adds the services that the selected services depend on, unless --no-deps is set.
+func filterServices(azureYaml *service.AzureYaml, azureYamlDir string) (map[string]service.Service, error) {
+	if runServiceFilter != "" {
+		if len(runOnly) > 0 || len(runExcept) > 0 {
+			return nil, fmt.Errorf("--service cannot be combined with --only or --except")
+		}
+		return service.FilterServices(azureYaml, strings.Split(runServiceFilter, ",")), nil
 	}
-	filterList := strings.Split(runServiceFilter, ",")
-	return service.FilterServices(azureYaml, filterList)
+	if len(runOnly) == 0 && len(runExcept) == 0 {
+		return azureYaml.Services, nil
+	}
+
+	var graph *service.DependencyGraph
+	if len(runOnly) > 0 && !runNoDeps {
+		g, _, err := service.BuildServiceGraph(azureYaml, azureYamlDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build service dependency graph: %w", err)
+		}
+		graph = g
+	}
+	return service.SelectServices(azureYaml, graph, runOnly, runExcept)
+}
+
+// showAddedDependencies lists the services that run because services selected with --only
+// depend on them.
+func showAddedDependencies(services map[string]service.Service) {
+	if len(runOnly) == 0 || runNoDeps {
+		return
+	}
+	var added []string
+	for name := range services {
+		if !slices.Contains(runOnly, name) {
+			added = append(added, name)
+		}
+	}
+	if len(added) == 0 {
+		return
+	}
+	sort.Strings(added)
+	output.Info("Also running dependencies: %s (use --no-deps to skip)", strings.Join(added, ", "))
 }
 
 // detectServiceRuntimes detects runtime information for all services.
@@ -176,17 +348,84 @@ func detectServiceRuntimes(services map[string]service.Service, azureYamlDir, ru
 			return nil, fmt.Errorf("failed to detect runtime for service %s: %w", name, err)
 		}
 		usedPorts[runtime.Port] = true
+		if runWatch {
+			service.EnableNativeWatch(runtime)
+		}
 		runtimes = append(runtimes, runtime)
 	}
 
+	if err := sandboxRuntimes(runtimes, azureYamlDir); err != nil {
+		return nil, err
+	}
 	return runtimes, nil
 }
 
+// prepareRuntimes makes runtimes start after the services they depend on, as found by the
+// dependency graph of azureYaml, sets their retry policies, readiness, health probes, and
+// runners from azureYaml, loads the values of the azd environment, their secrets, and the variables of their
+// .env files, and gives them the URLs of their forwarded ports in a codespace.
+func prepareRuntimes(azureYaml *service.AzureYaml, azureYamlDir string, runtimes []*service.ServiceRuntime) error {
+	graph, _, err := service.BuildServiceGraph(azureYaml, azureYamlDir)
+	if err != nil {
+		return fmt.Errorf("failed to build service dependency graph: %w", err)
+	}
+	service.ApplyDependencies(runtimes, graph)
+	if err := service.ApplyRetryPolicies(runtimes, azureYaml); err != nil {
+		return fmt.Errorf("invalid retry policy in azure.yaml: %w", err)
+	}
+	if err := service.ApplyReadiness(runtimes, azureYaml); err != nil {
+		return fmt.Errorf("invalid ready settings in azure.yaml: %w", err)
+	}
+	if err := service.ApplyHealthSettings(runtimes, azureYaml); err != nil {
+		return fmt.Errorf("invalid health settings in azure.yaml: %w", err)
+	}
+	if err := service.ApplyRestartPolicies(runtimes, azureYaml); err != nil {
+		return fmt.Errorf("invalid restart policy in azure.yaml: %w", err)
+	}
+	if err := service.ApplyRunners(runtimes, azureYaml); err != nil {
+		return fmt.Errorf("invalid runner in azure.yaml: %w", err)
+	}
+	if err := service.ApplyEnvContracts(runtimes, azureYaml, azureYamlDir); err != nil {
+		return fmt.Errorf("invalid environment contract in azure.yaml: %w", err)
+	}
+	if err := service.ApplyOutputBindings(runtimes, azureYaml); err != nil {
+		return fmt.Errorf("invalid outputs or inputs in azure.yaml: %w", err)
+	}
+	environment := service.AzdEnvironmentName(azureYamlDir, runEnvironment)
+	if err := applyAzdEnvironment(runtimes, azureYaml, azureYamlDir, environment); err != nil {
+		return err
+	}
+	if err := service.ApplySecrets(runtimes, azureYamlDir); err != nil {
+		return fmt.Errorf("failed to load secrets: %w", err)
+	}
+	if err := service.ApplyDotEnvFiles(runtimes, azureYamlDir, environment); err != nil {
+		return fmt.Errorf("failed to load .env files: %w", err)
+	}
+	applyForwardedURLs(runtimes)
+	return nil
+}
+
 // executeAndMonitorServices starts services and monitors them until interrupted.
-func executeAndMonitorServices(runtimes []*service.ServiceRuntime, cwd string) error {
+func executeAndMonitorServices(runtimes []*service.ServiceRuntime, session *runSession) error {
 	// Create logger
 	logger := service.NewServiceLogger(runVerbose)
 	logger.LogStartup(len(runtimes))
+	session.logger = logger
+
+	// With --output json, stdout carries service output and lifecycle events as JSON lines
+	records := service.NewRecordWriter(nil)
+	if output.IsJSON() {
+		records = service.NewRecordWriter(os.Stdout)
+		service.GetLogManager(session.cwd).SetSink(records.WriteEntry)
+	}
+	// Lifecycle events are kept in the run history of the workspace, for 'azd app stats'
+	if history, err := runhistory.Create(session.cwd); err != nil {
+		output.Warning("Failed to record run history: %v", err)
+	} else {
+		defer history.Close()
+		records.SetEventLog(history)
+	}
+	logger.SetRecords(records)
 
 	// Load environment variables
 	envVars, err := loadEnvironmentVariables()
@@ -195,8 +434,15 @@ func executeAndMonitorServices(runtimes []*service.ServiceRuntime, cwd string) e
 	}
 
 	// Orchestrate services
-	result, err := service.OrchestrateServices(runtimes, envVars, logger)
+	result, err := service.OrchestrateServices(commandContext(), runtimes, envVars, logger)
 	if err != nil {
+		if !output.IsJSON() {
+			names := make([]string, len(runtimes))
+			for i, rt := range runtimes {
+				names[i] = rt.Name
+			}
+			newBatchSummary(names, workerpool.Errors(err)).print()
+		}
 		return fmt.Errorf("service orchestration failed: %w", err)
 	}
 
@@ -207,34 +453,186 @@ func executeAndMonitorServices(runtimes []*service.ServiceRuntime, cwd string) e
 	}
 
 	logger.LogReady()
+	for name, process := range result.Processes {
+		logger.Records().Write(service.Record{Service: name, Event: service.EventReady, PID: process.Process.Pid})
+	}
+	forwardPorts(result.Processes)
+	markDetachedReady(session.cwd, result.Processes)
+
+	if runSmoke {
+		runSmokeChecks(result.Processes, session.cwd)
+	}
 
 	// Start dashboard and wait for shutdown
-	return monitorServicesUntilShutdown(result, cwd)
+	session.result = result
+	return monitorServicesUntilShutdown(session)
 }
 
-// loadEnvironmentVariables loads environment variables from --env-file if specified.
-func loadEnvironmentVariables() (map[string]string, error) {
-	if runEnvFile == "" {
-		return make(map[string]string), nil
+// runSmokeChecks loads each frontend service in a headless browser and prints a summary.
+// Failures are reported as warnings and never stop the running services.
+func runSmokeChecks(processes map[string]*service.ServiceProcess, cwd string) {
+	output.Section("🧪", "Smoke checking frontends")
+
+	chromePath, err := smoke.FindChrome()
+	if err != nil {
+		output.Warning("Skipping smoke checks: %v", err)
+		return
+	}
+
+	urls := make(map[string]string)
+	for name, process := range processes {
+		if smoke.IsFrontend(process.Runtime.Framework) {
+			urls[name] = fmt.Sprintf("http://localhost:%d", process.Port)
+		}
+	}
+
+	if len(urls) == 0 {
+		output.Info("No frontend services to check")
+		return
+	}
+
+	outDir, err := statedir.DataDir(cwd, "smoke")
+	if err != nil {
+		output.Warning("Skipping smoke checks: %v", err)
+		return
+	}
+	for _, result := range smoke.CheckAll(commandContext(), chromePath, urls, outDir) {
+		name := result.Service
+		switch {
+		case result.Error != "":
+			output.ItemError("%s: %s", name, result.Error)
+		case result.Passed():
+			output.ItemSuccess("%s: page rendered without console errors", name)
+		default:
+			if result.Blank {
+				output.ItemWarning("%s: page rendered blank", name)
+			}
+			for _, consoleErr := range result.ConsoleErrors {
+				output.ItemWarning("%s: console error: %s", name, consoleErr)
+			}
+		}
+		if result.Screenshot != "" {
+			output.Item("Screenshot: %s", result.Screenshot)
+		}
+	}
+	output.Newline()
+}
+
+// applyAzdEnvironment gives runtimes the values of the azd environment of the project in
+// azureYamlDir. A project without an environment runs without its values, unless one was asked
+// for with --environment.
+func applyAzdEnvironment(runtimes []*service.ServiceRuntime, azureYaml *service.AzureYaml, azureYamlDir, environment string) error {
+	var values map[string]string
+	if environment != "" {
+		var err error
+		values, err = service.LoadAzdEnvironment(azureYamlDir, environment)
+		if err != nil && (runEnvironment != "" || !errors.Is(err, service.ErrNoAzdEnvironment)) {
+			return fmt.Errorf("failed to load the azd environment: %w", err)
+		}
 	}
+	warnings, err := service.ApplyAzdEnvironment(runtimes, azureYaml, values)
+	if err != nil {
+		return fmt.Errorf("invalid azdEnv in azure.yaml: %w", err)
+	}
+	for _, warning := range warnings {
+		output.Warning("%s", warning)
+	}
+	return nil
+}
 
-	envVars, err := service.LoadDotEnv(runEnvFile)
+// loadEnvironmentVariables loads environment variables from the --env-file files, later files
+// overriding earlier ones.
+func loadEnvironmentVariables() (map[string]string, error) {
+	envVars, err := service.LoadDotEnvFiles(runEnvFiles)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load env file: %w", err)
 	}
 	return envVars, nil
 }
 
-// monitorServicesUntilShutdown starts the dashboard and waits for shutdown signal.
-func monitorServicesUntilShutdown(result *service.OrchestrationResult, cwd string) error {
-	dashboardServer := startDashboard(cwd)
+// monitorServicesUntilShutdown starts the dashboard when asked and waits for shutdown signal.
+func monitorServicesUntilShutdown(session *runSession) error {
+	var dashboardServer *dashboard.Server
+	if runDashboard {
+		dashboardServer = startDashboard(session.cwd)
+		if dashboardServer != nil {
+			dashboardServer.SetRestartHandler(session.restartService)
+		}
+	}
+
+	if runChaos {
+		stop, err := startChaos(session)
+		if err != nil {
+			output.Warning("Chaos disabled: %v", err)
+		}
+		session.stopChaos = stop
+	}
+	if runWatch {
+		session.stopWatch = startWatch(session)
+	}
+	session.stopHealth = startHealth(session)
+
+	// A service that exhausts its restarts ends the session, like --deadline
+	ctx, cancel := context.WithCancelCause(commandContext())
+	defer cancel(nil)
+	session.fail = cancel
+	session.stopSupervisor = startSupervisor(session, session.fail)
+	var stopSoak func() *soak.Report
+	if runSoak > 0 {
+		stopSoak = startSoak(session, runSoak, runSoakInterval, session.fail)
+	}
+	var stopLeaks func()
+	if settings, ok := leakDetection(session.azureYamlDir); ok {
+		stopLeaks = startLeakDetection(session, settings)
+	}
 
 	output.Info("💡 Press Ctrl+C to stop all services")
 	output.Newline()
 
-	waitForShutdownSignal()
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, append([]os.Signal{os.Interrupt, syscall.SIGTERM}, controlSignals...)...)
+	var stopTUI func()
+	if runTUI {
+		stopTUI = startTUI(session, sigChan)
+	}
+	waitForShutdown(ctx, sigChan, session)
+	signal.Stop(sigChan)
+	if stopTUI != nil {
+		stopTUI()
+	}
+	var soakReport *soak.Report
+	if stopSoak != nil {
+		soakReport = stopSoak()
+	}
+	if stopLeaks != nil {
+		stopLeaks()
+	}
 
-	return shutdownServices(result, dashboardServer)
+	if session.stopSupervisor != nil {
+		session.stopSupervisor()
+	}
+
+	if session.stopChaos != nil {
+		session.stopChaos()
+	}
+	if session.stopWatch != nil {
+		session.stopWatch()
+	}
+	if session.stopHealth != nil {
+		session.stopHealth()
+	}
+
+	if err := shutdownServices(session.result, dashboardServer, session.logger.Records()); err != nil {
+		return err
+	}
+	if soakReport != nil {
+		printSoakReport(soakReport, session.cwd)
+	}
+	// Services were stopped because --deadline passed or a service exhausted its restarts
+	if err := context.Cause(ctx); !errors.Is(err, errSoakFinished) {
+		return err
+	}
+	return nil
 }
 
 // startDashboard starts the azd dashboard server.
@@ -252,15 +650,9 @@ func startDashboard(cwd string) *dashboard.Server {
 	return dashboardServer
 }
 
-// waitForShutdownSignal blocks until SIGINT or SIGTERM is received.
-func waitForShutdownSignal() {
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
-	<-sigChan
-}
-
-// shutdownServices stops all services and the dashboard.
-func shutdownServices(result *service.OrchestrationResult, dashboardServer *dashboard.Server) error {
+// shutdownServices stops all services and the dashboard, and writes an exited record for each
+// service to records.
+func shutdownServices(result *service.OrchestrationResult, dashboardServer *dashboard.Server, records *service.RecordWriter) error {
 	output.Newline()
 	output.Newline()
 	output.Warning("🛑 Shutting down services...")
@@ -271,47 +663,34 @@ func shutdownServices(result *service.OrchestrationResult, dashboardServer *dash
 		}
 	}
 
+	// Services that exited on their own are recorded before stopping the others marks them
+	for _, process := range result.Processes {
+		select {
+		case <-process.Exited():
+			records.WriteExit(process)
+		default:
+		}
+	}
 	service.StopAllServices(result.Processes)
+	for _, process := range result.Processes {
+		records.WriteExit(process)
+	}
 	output.Success("All services stopped")
 	output.Newline()
 
 	return nil
 }
 
-// runAspireMode runs Aspire AppHost directly using dotnet run.
-func runAspireMode(rootDir string) error {
-	// Find Aspire AppHost project
-	aspireProject, err := detector.FindAppHost(rootDir)
-	if err != nil {
-		return fmt.Errorf("failed to search for Aspire AppHost: %w", err)
-	}
+// showDryRun displays what would be executed without starting services, and the resources
+// that would run in emulators.
+func showDryRun(runtimes []*service.ServiceRuntime, emulated []string) error {
+	output.Section("🔍", "Dry-run mode: Showing execution plan")
 
-	if aspireProject == nil {
-		return fmt.Errorf("no Aspire AppHost found - --runtime aspire requires an AppHost.cs or Program.cs file in a .csproj project")
+	if len(emulated) > 0 {
+		output.Newline()
+		output.Info("Emulated resources: %s", strings.Join(emulated, ", "))
 	}
 
-	output.Info("🚀 Running Aspire in native mode")
-	output.Item("Directory: %s", aspireProject.Dir)
-	output.Item("Project: %s", aspireProject.ProjectFile)
-	output.Newline()
-	output.Info("💡 Aspire dashboard will start automatically")
-	output.Info("💡 All azd environment variables are available to your app")
-	output.Newline()
-
-	// Use executor to run dotnet with proper environment inheritance
-	args := []string{"run", "--project", aspireProject.ProjectFile}
-
-	output.Info("💡 Press Ctrl+C to stop")
-	output.Newline()
-
-	// Run dotnet and let it handle everything (inherits all azd env vars)
-	return executor.StartCommand("dotnet", args, aspireProject.Dir)
-}
-
-// showDryRun displays what would be executed without starting services.
-func showDryRun(runtimes []*service.ServiceRuntime) error {
-	output.Section("🔍", "Dry-run mode: Showing execution plan")
-
 	for _, runtime := range runtimes {
 		output.Newline()
 		output.Info("%s", runtime.Name)
@@ -320,6 +699,9 @@ func showDryRun(runtimes []*service.ServiceRuntime) error {
 		output.Label("Port", fmt.Sprintf("%d", runtime.Port))
 		output.Label("Directory", runtime.WorkingDir)
 		output.Label("Command", fmt.Sprintf("%s %v", runtime.Command, runtime.Args))
+		if len(runtime.DependsOn) > 0 {
+			output.Label("Starts after", strings.Join(runtime.DependsOn, ", "))
+		}
 	}
 
 	return nil
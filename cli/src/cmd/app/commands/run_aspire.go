@@ -0,0 +1,258 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/jongio/azd-app/cli/src/internal/aspire"
+	"github.com/jongio/azd-app/cli/src/internal/detector"
+	"github.com/jongio/azd-app/cli/src/internal/executor"
+	"github.com/jongio/azd-app/cli/src/internal/output"
+	"github.com/jongio/azd-app/cli/src/internal/service"
+)
+
+const (
+	// aspireStatusInterval is the time between probes of the dashboard and OTLP endpoints.
+	aspireStatusInterval = 2 * time.Second
+	// aspireStopTimeout is how long the AppHost gets to stop its resources before it is killed.
+	aspireStopTimeout = 15 * time.Second
+)
+
+// runAspireMode runs the Aspire AppHost with dotnet run and lets it start its resources,
+// instead of starting each service of azure.yaml. Once the AppHost is up, run attaches to its
+// dashboard and OTLP endpoints, reports their status until the AppHost exits or Ctrl+C, and
+// then stops the AppHost, which stops its resources.
+func runAspireMode(rootDir string) error {
+	appHost, err := detector.FindAppHost(rootDir)
+	if err != nil {
+		return fmt.Errorf("failed to search for Aspire AppHost: %w", err)
+	}
+	if appHost == nil {
+		return fmt.Errorf("no Aspire AppHost found - --runtime aspire requires an AppHost.cs or Program.cs file in a .csproj project")
+	}
+
+	profile, err := appHostLaunchProfile()
+	if err != nil {
+		return err
+	}
+	endpoints, err := aspire.LaunchEndpoints(appHost.Dir, profile)
+	if err != nil {
+		if profile != "" {
+			return err
+		}
+		output.Warning("%v", err)
+	}
+	args := []string{"run", "--project", appHost.ProjectFile}
+	if profile != "" {
+		args = append(args, "--launch-profile", profile)
+	}
+
+	output.Info("🚀 Running Aspire in native mode")
+	output.Item("Directory: %s", appHost.Dir)
+	output.Item("Project: %s", appHost.ProjectFile)
+	// The resources come from the source, because publishing the manifest would build the AppHost twice
+	if model, err := aspire.ParseSource(*appHost); err == nil {
+		var names []string
+		for _, r := range model.Resources {
+			if r.Runnable() {
+				names = append(names, r.Name)
+			}
+		}
+		if len(names) > 0 {
+			output.Item("Resources the AppHost starts: %s", strings.Join(names, ", "))
+		}
+	}
+	output.Newline()
+	output.Info("💡 All azd environment variables are available to your app")
+	output.Newline()
+
+	if runDryRun {
+		output.Label("Command", "dotnet "+strings.Join(args, " "))
+		return nil
+	}
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigChan)
+
+	dashboardURLs := make(chan string, 1)
+	cmd, err := executor.StartCommandWithOutputHandler("dotnet", args, appHost.Dir, func(line string) error {
+		if url, ok := aspire.DashboardURL(line); ok {
+			select {
+			case dashboardURLs <- url:
+			default:
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	output.Success("Started the AppHost (PID: %d)", cmd.Process.Pid)
+	output.Info("💡 Press Ctrl+C to stop the AppHost and its resources")
+	output.Newline()
+
+	exited := make(chan error, 1)
+	go func() { exited <- cmd.Wait() }()
+	return watchAspireSession(commandContext(), sigChan, cmd, endpoints, dashboardURLs, exited)
+}
+
+// appHostLaunchProfile returns the launch profile the AppHost runs with in aspire mode: the
+// last bare profile of --launch-profile, or "" for its default one. The AppHost starts the
+// other projects, so profiles can't be selected for services.
+func appHostLaunchProfile() (string, error) {
+	profile := ""
+	for _, spec := range runLaunchProfiles {
+		if strings.Contains(spec, "=") {
+			return "", fmt.Errorf("--launch-profile %s: with --runtime aspire, only the profile of the AppHost can be selected", spec)
+		}
+		profile = strings.TrimSpace(spec)
+	}
+	return profile, nil
+}
+
+// watchAspireSession reports the status of the dashboard and OTLP endpoints of a running
+// AppHost until it exits, or until a signal or the end of ctx, such as --deadline, stops it.
+// The dashboard URL from the output of the AppHost, which holds its login token, replaces the
+// one of its launch profile.
+func watchAspireSession(ctx context.Context, sigChan <-chan os.Signal, cmd *exec.Cmd, endpoints aspire.Endpoints, dashboardURLs <-chan string, exited <-chan error) error {
+	ticker := time.NewTicker(aspireStatusInterval)
+	defer ticker.Stop()
+
+	attached := false
+	var last aspire.Status
+	for {
+		select {
+		case err := <-exited:
+			if err != nil {
+				return fmt.Errorf("the AppHost exited: %w", err)
+			}
+			output.Info("The AppHost exited")
+			return nil
+
+		case url := <-dashboardURLs:
+			endpoints.Dashboard = url
+
+		case <-ticker.C:
+			status := aspire.Probe(ctx, endpoints)
+			if !attached {
+				if status.Dashboard {
+					attached = true
+					printAspireEndpoints(endpoints, status)
+				}
+				last = status
+				continue
+			}
+			if last.Dashboard != status.Dashboard {
+				if status.Dashboard {
+					output.Success("Aspire dashboard is responding again")
+				} else {
+					output.Warning("Aspire dashboard stopped responding at %s", endpoints.Dashboard)
+				}
+			}
+			if endpoints.OTLP != "" && last.OTLP != status.OTLP {
+				if status.OTLP {
+					output.Success("OTLP endpoint is accepting telemetry again")
+				} else {
+					output.Warning("OTLP endpoint stopped accepting connections at %s", endpoints.OTLP)
+				}
+			}
+			last = status
+
+		case sig := <-sigChan:
+			output.Newline()
+			output.Warning("🛑 Stopping the AppHost...")
+			// A Ctrl+C in the terminal has already reached the AppHost, which runs in the same process group
+			return stopAppHost(cmd, exited, sig != os.Interrupt)
+
+		case <-ctx.Done():
+			output.Newline()
+			output.Warning("🛑 Stopping the AppHost...")
+			if err := stopAppHost(cmd, exited, true); err != nil {
+				return err
+			}
+			return context.Cause(ctx)
+		}
+	}
+}
+
+// printAspireEndpoints prints where the dashboard of a running AppHost is and whether its OTLP
+// endpoint accepts telemetry.
+func printAspireEndpoints(endpoints aspire.Endpoints, status aspire.Status) {
+	output.Newline()
+	output.Info("📊 Aspire dashboard: %s", output.URL(endpoints.Dashboard))
+	if endpoints.OTLP != "" {
+		state := "accepting telemetry"
+		if !status.OTLP {
+			state = "not accepting connections yet"
+		}
+		output.Item("OTLP endpoint: %s %s", endpoints.OTLP, output.Muted("(%s)", state))
+	}
+	output.Newline()
+}
+
+// stopAppHost waits for a running AppHost to stop its resources and exit, after interrupting it
+// when asked. It is killed when it doesn't exit in time, and right away on Windows, where it
+// can't be interrupted.
+func stopAppHost(cmd *exec.Cmd, exited <-chan error, interrupt bool) error {
+	if interrupt {
+		if runtime.GOOS == "windows" {
+			_ = cmd.Process.Kill()
+		} else {
+			_ = cmd.Process.Signal(os.Interrupt)
+		}
+	}
+	select {
+	case <-exited:
+	case <-time.After(aspireStopTimeout):
+		if err := cmd.Process.Kill(); err != nil && !errors.Is(err, os.ErrProcessDone) {
+			return fmt.Errorf("failed to stop the AppHost: %w", err)
+		}
+		<-exited
+	}
+	output.Success("AppHost stopped")
+	return nil
+}
+
+// deferToAppHost drops the services the AppHost of the workspace starts from services when the
+// AppHost runs as one of them, so its resources aren't started twice. When the AppHost isn't
+// among them, it suggests --runtime aspire for the services that are its resources.
+func deferToAppHost(services map[string]service.Service, azureYamlDir string) map[string]service.Service {
+	appHost, err := detector.FindAppHost(azureYamlDir)
+	if err != nil || appHost == nil {
+		return services
+	}
+	model, err := aspire.ParseSource(*appHost)
+	if err != nil {
+		return services
+	}
+
+	orchestration := service.OrchestratedByAppHost(services, azureYamlDir, model)
+	if len(orchestration.Orchestrated) == 0 {
+		return services
+	}
+	if len(orchestration.AppHost) == 0 {
+		output.Info("💡 %s run as resources of %s; use 'azd app run --runtime aspire' to let the AppHost start them",
+			strings.Join(orchestration.Orchestrated, ", "), filepath.Base(appHost.ProjectFile))
+		return services
+	}
+
+	kept := make(map[string]service.Service, len(services))
+	for name, svc := range services {
+		kept[name] = svc
+	}
+	for _, name := range orchestration.Orchestrated {
+		delete(kept, name)
+	}
+	output.Info("Not starting %s: %s starts them as resources of the AppHost", strings.Join(orchestration.Orchestrated, ", "), strings.Join(orchestration.AppHost, ", "))
+	return kept
+}
@@ -0,0 +1,71 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jongio/azd-app/cli/src/internal/container"
+	"github.com/jongio/azd-app/cli/src/internal/emulator"
+	"github.com/jongio/azd-app/cli/src/internal/output"
+	"github.com/jongio/azd-app/cli/src/internal/service"
+)
+
+// emulatedResources returns the resources of azureYaml that services use and that run in local
+// emulators, unless --no-emulators is set.
+func emulatedResources(azureYaml *service.AzureYaml, services map[string]service.Service) []string {
+	if runNoEmulators {
+		return nil
+	}
+	return emulator.Select(azureYaml, services)
+}
+
+// startEmulators starts the emulators of the resources services use and gives the runtimes
+// that use them their connection strings. The returned function stops the emulators.
+func startEmulators(azureYaml *service.AzureYaml, azureYamlDir string, services map[string]service.Service, runtimes []*service.ServiceRuntime) (func(), error) {
+	names := emulatedResources(azureYaml, services)
+	if len(names) == 0 {
+		return func() {}, nil
+	}
+
+	ctx := commandContext()
+	engine, err := container.Current()
+	if err != nil {
+		return nil, err
+	}
+	if err := engine.Check(ctx); err != nil {
+		return nil, fmt.Errorf("emulators of %s need a container engine: %w (use --no-emulators to use the azd environment instead)", strings.Join(names, ", "), err)
+	}
+	manager, err := emulator.NewManager(engine, azureYamlDir)
+	if err != nil {
+		return nil, err
+	}
+
+	output.Section("🧪", "Starting emulators")
+	instances, err := manager.Start(ctx, azureYaml, names)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start emulators: %w", err)
+	}
+	env := make(map[string]map[string]string, len(instances))
+	for _, inst := range instances {
+		env[inst.Name] = inst.Env
+		ports := make([]string, 0, len(inst.Ports))
+		for _, port := range inst.Ports {
+			ports = append(ports, fmt.Sprintf("%d", port))
+		}
+		sort.Strings(ports)
+		reused := ""
+		if inst.Reused {
+			reused = output.Muted(" (already running)")
+		}
+		output.ItemSuccess("%s (%s) on %s:%s%s", inst.Name, inst.Type, inst.Host, strings.Join(ports, ","), reused)
+	}
+	service.ApplyEmulatorEnv(runtimes, azureYaml, env)
+
+	return func() {
+		if err := manager.Stop(context.Background()); err != nil {
+			output.Warning("Failed to stop emulators: %v", err)
+		}
+	}, nil
+}
@@ -0,0 +1,54 @@
+package commands
+
+import (
+	"github.com/jongio/azd-app/cli/src/internal/devenv"
+	"github.com/jongio/azd-app/cli/src/internal/output"
+	"github.com/jongio/azd-app/cli/src/internal/service"
+)
+
+// forwardingEnvironment returns the remote development environment whose port forwarding run
+// uses, or nil when it runs on a local machine or forwarding is disabled with --no-forward.
+func forwardingEnvironment() *devenv.Environment {
+	if runNoForward {
+		return nil
+	}
+	return devenv.Detect()
+}
+
+// applyForwardedURLs shows the URLs of forwarded ports when run in a codespace, where browsers
+// reach services at them. Services only reach each other at them when the ports are public;
+// private and org ports ask for a GitHub sign-in, which a service calling another can't do.
+func applyForwardedURLs(runtimes []*service.ServiceRuntime) {
+	env := forwardingEnvironment()
+	if env == nil || env.Kind != devenv.KindCodespaces {
+		return
+	}
+	for _, rt := range runtimes {
+		if rt.Port > 0 {
+			rt.ForwardedURL = env.ForwardedURL(rt.Port)
+			rt.ForwardedOpen = runPortVisibility == devenv.VisibilityPublic
+		}
+	}
+}
+
+// forwardPorts declares the ports of the started services as forwarded in a codespace. A
+// failure is a warning, since the codespace forwards ports it sees in use by itself.
+func forwardPorts(processes map[string]*service.ServiceProcess) {
+	env := forwardingEnvironment()
+	if env == nil || env.Kind != devenv.KindCodespaces {
+		return
+	}
+	var ports []int
+	for _, process := range processes {
+		if process.Port > 0 {
+			ports = append(ports, process.Port)
+		}
+	}
+	if err := env.ForwardPorts(commandContext(), ports, runPortVisibility); err != nil {
+		output.Warning("Could not set the visibility of forwarded ports: %v", err)
+		return
+	}
+	if len(ports) > 0 {
+		output.Info("Forwarded %d port(s) of codespace %s with %s visibility", len(ports), env.Name, runPortVisibility)
+	}
+}
@@ -0,0 +1,57 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/jongio/azd-app/cli/src/internal/devenv"
+	"github.com/jongio/azd-app/cli/src/internal/service"
+)
+
+func TestApplyForwardedURLs(t *testing.T) {
+	tests := []struct {
+		name      string
+		codespace bool
+		noForward bool
+		public    bool
+		want      string
+		wantOpen  bool
+	}{
+		{name: "local machine", want: ""},
+		{name: "codespace", codespace: true, want: "https://shiny-space-x7-5000.app.github.dev"},
+		{name: "codespace with public ports", codespace: true, public: true, want: "https://shiny-space-x7-5000.app.github.dev", wantOpen: true},
+		{name: "codespace with --no-forward", codespace: true, noForward: true, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("REMOTE_CONTAINERS", "")
+			t.Setenv("DEVCONTAINER", "")
+			t.Setenv("GITHUB_CODESPACES_PORT_FORWARDING_DOMAIN", "")
+			t.Setenv("CODESPACES", "")
+			t.Setenv("CODESPACE_NAME", "")
+			if tt.codespace {
+				t.Setenv("CODESPACES", "true")
+				t.Setenv("CODESPACE_NAME", "shiny-space-x7")
+			}
+			runNoForward = tt.noForward
+			runPortVisibility = devenv.VisibilityPrivate
+			if tt.public {
+				runPortVisibility = devenv.VisibilityPublic
+			}
+			t.Cleanup(func() {
+				runNoForward = false
+				runPortVisibility = devenv.VisibilityPrivate
+			})
+
+			api := &service.ServiceRuntime{Name: "api", Port: 5000}
+			worker := &service.ServiceRuntime{Name: "worker"}
+			applyForwardedURLs([]*service.ServiceRuntime{api, worker})
+			if api.ForwardedURL != tt.want || worker.ForwardedURL != "" {
+				t.Errorf("forwarded URLs = %q and %q, want %q and none for the worker", api.ForwardedURL, worker.ForwardedURL, tt.want)
+			}
+			if api.ForwardedOpen != tt.wantOpen {
+				t.Errorf("ForwardedOpen = %v, want %v", api.ForwardedOpen, tt.wantOpen)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package commands
+
+import (
+	"maps"
+
+	"github.com/jongio/azd-app/cli/src/internal/dashboard"
+	"github.com/jongio/azd-app/cli/src/internal/service"
+)
+
+// startHealth probes the services of session while they run, reporting when their health
+// changes, and returns a function that stops probing.
+func startHealth(session *runSession) func() {
+	session.mu.Lock()
+	processes := maps.Clone(session.result.Processes)
+	session.mu.Unlock()
+
+	manager := service.GetHealthManager(session.cwd)
+	manager.Start(processes, func(status service.HealthStatus, previous string) {
+		if message := healthChangeMessage(status, previous); message != "" {
+			session.logger.LogService(status.Service, message)
+		}
+		_ = dashboard.GetServer(session.cwd).BroadcastServiceUpdate(session.cwd)
+	})
+	return manager.Stop
+}
+
+// restartHealth starts probing again after a reload.
+func (s *runSession) restartHealth() {
+	s.stopHealth = startHealth(s)
+}
+
+// healthChangeMessage describes a change of the health of a service, or returns "" for the
+// first successful probe, since services are reported as they start.
+func healthChangeMessage(status service.HealthStatus, previous string) string {
+	switch status.State {
+	case service.HealthHealthy:
+		if previous == service.HealthStarting {
+			return ""
+		}
+		return "✓ Healthy again"
+	case service.HealthDegraded:
+		return "⚠ Degraded: " + status.Error
+	case service.HealthCrashed:
+		return "✗ Crashed: " + status.Error
+	default:
+		return ""
+	}
+}
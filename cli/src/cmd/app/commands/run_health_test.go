@@ -0,0 +1,30 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/jongio/azd-app/cli/src/internal/service"
+)
+
+func TestHealthChangeMessage(t *testing.T) {
+	tests := []struct {
+		name     string
+		state    string
+		previous string
+		want     string
+	}{
+		{name: "first healthy probe", state: service.HealthHealthy, previous: service.HealthStarting, want: ""},
+		{name: "recovered", state: service.HealthHealthy, previous: service.HealthDegraded, want: "✓ Healthy again"},
+		{name: "degraded", state: service.HealthDegraded, previous: service.HealthHealthy, want: "⚠ Degraded: /health returned HTTP 503"},
+		{name: "crashed", state: service.HealthCrashed, previous: service.HealthHealthy, want: "✗ Crashed: /health returned HTTP 503"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := service.HealthStatus{Service: "api", State: tt.state, Error: "/health returned HTTP 503"}
+			if got := healthChangeMessage(status, tt.previous); got != tt.want {
+				t.Errorf("healthChangeMessage() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,197 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jongio/azd-app/cli/src/internal/detector"
+	"github.com/jongio/azd-app/cli/src/internal/installer"
+	"github.com/jongio/azd-app/cli/src/internal/output"
+	"github.com/jongio/azd-app/cli/src/internal/security"
+	"github.com/jongio/azd-app/cli/src/internal/types"
+	"github.com/jongio/azd-app/cli/src/internal/workerpool"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Modes of the dependency installation that run does before starting services.
+const (
+	installAlways  = "always"  // Install the dependencies of every project, like deps
+	installMissing = "missing" // Install only projects without node_modules, .venv, or obj
+	installNever   = "never"   // Don't install dependencies
+)
+
+// defaultInstallConcurrency is how many projects --install missing installs at once.
+const defaultInstallConcurrency = 4
+
+// runConfig is the run section of .azdapp.yaml.
+type runConfig struct {
+	Install       string     `yaml:"install"`
+	LeakDetection leakConfig `yaml:"leakDetection"`
+}
+
+// resolveInstallMode returns the install mode set with --install, or else with run.install in
+// the .azdapp.yaml of projectDir, or else always.
+func resolveInstallMode(projectDir string) (string, error) {
+	mode, source := runInstall, "--install"
+	if mode == "" && projectDir != "" {
+		config, err := loadRunConfig(projectDir)
+		if err != nil {
+			return "", err
+		}
+		mode, source = config.Install, "run.install in "+detector.ConfigFileName
+	}
+	switch mode {
+	case "":
+		return installAlways, nil
+	case installAlways, installMissing, installNever:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("invalid %s value: %s (must be '%s', '%s', or '%s')", source, mode, installAlways, installMissing, installNever)
+	}
+}
+
+// loadRunConfig reads the run section of .azdapp.yaml in projectDir. A missing file yields
+// the defaults.
+func loadRunConfig(projectDir string) (runConfig, error) {
+	var config struct {
+		Run runConfig `yaml:"run"`
+	}
+	path := filepath.Join(projectDir, detector.ConfigFileName)
+	if err := security.ValidatePath(path); err != nil {
+		return config.Run, nil
+	}
+	// #nosec G304 -- Path validated by security.ValidatePath
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return config.Run, nil
+	}
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return config.Run, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return config.Run, nil
+}
+
+// runPrerequisites checks reqs and installs dependencies as the install mode says, before
+// services start.
+func runPrerequisites() error {
+	azureYamlPath, projectDir := "", ""
+	if path, err := findAzureYaml(); err == nil {
+		azureYamlPath, projectDir = path, filepath.Dir(path)
+	}
+	mode, err := resolveInstallMode(projectDir)
+	if err != nil {
+		return err
+	}
+
+	// Sandboxed dependencies live in the container, where node_modules and .venv can't be seen
+	if mode == installAlways || (mode == installMissing && sandboxMode) {
+		// Execute dependencies first (reqs -> deps -> run)
+		if err := cmdOrchestrator.Run("run"); err != nil {
+			return fmt.Errorf("failed to execute command dependencies: %w", err)
+		}
+		return nil
+	}
+
+	if err := cmdOrchestrator.Run("reqs"); err != nil {
+		return fmt.Errorf("failed to execute command dependencies: %w", err)
+	}
+	if mode == installMissing && azureYamlPath != "" {
+		if err := installMissingDependencies(azureYamlPath, runInstallConcurrency); err != nil {
+			return err
+		}
+	}
+	if !output.IsJSON() {
+		output.Section("🚀", "Starting services...")
+	}
+	return nil
+}
+
+// installMissingDependencies installs the dependencies of the projects of the workspace of
+// azureYamlPath that have none yet, up to workers projects at once. .NET projects are restored
+// one after another, since projects share the obj folders of the projects they reference.
+func installMissingDependencies(azureYamlPath string, workers int) error {
+	root := filepath.Dir(azureYamlPath)
+	scan, err := detector.ScanWorkspace(root)
+	if err != nil {
+		scan = &detector.WorkspaceScan{}
+	}
+	policies, err := newInstallPolicies(azureYamlPath)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := withTimeout(commandContext(), depsTimeout, "dependency installation")
+	defer cancel()
+
+	var names []string
+	var tasks []workerpool.Task
+	add := func(name, dir string, install func(ctx context.Context) error) {
+		names = append(names, name)
+		tasks = append(tasks, workerpool.Task{Name: name, Run: func(context.Context) error {
+			return policies.install(ctx, dir, install)
+		}})
+	}
+
+	nodeDirs := make(map[string]bool, len(scan.NodeProjects))
+	for _, project := range scan.NodeProjects {
+		nodeDirs[project.Dir] = true
+	}
+	for _, project := range scan.NodeProjects {
+		// Workspace members are installed by the install at the workspace root
+		if project.WorkspaceRoot != "" && nodeDirs[project.WorkspaceRoot] {
+			continue
+		}
+		if !installer.NodeDependenciesInstalled(project) {
+			add(project.Dir, project.Dir, func(ctx context.Context) error {
+				return installer.InstallNodeDependencies(ctx, project)
+			})
+		}
+	}
+	for _, project := range scan.PythonProjects {
+		if !installer.PythonEnvironmentExists(project) {
+			add(project.Dir, project.Dir, func(ctx context.Context) error {
+				return installer.SetupPythonVirtualEnv(ctx, project)
+			})
+		}
+	}
+	var dotnetProjects []types.DotnetProject
+	for _, project := range scan.DotnetProjects {
+		if !strings.EqualFold(filepath.Ext(project.Path), ".sln") && !installer.DotnetProjectRestored(project) {
+			dotnetProjects = append(dotnetProjects, project)
+		}
+	}
+	if len(dotnetProjects) > 0 {
+		add(".NET projects", root, func(ctx context.Context) error {
+			var errs []error
+			for _, project := range dotnetProjects {
+				if err := installer.RestoreDotnetProject(ctx, project); err != nil {
+					errs = append(errs, fmt.Errorf("%s: %w", project.Path, err))
+				}
+			}
+			return errors.Join(errs...)
+		})
+	}
+
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	output.Newline()
+	output.Section("📦", "Installing missing dependencies")
+	for _, name := range names {
+		output.Item("%s", name)
+	}
+	err = workerpool.Run(ctx, tasks, workerpool.Options{Workers: max(workers, 1)})
+	summary := newBatchSummary(names, workerpool.Errors(err))
+	if summary.Failed > 0 {
+		summary.print()
+		return fmt.Errorf("failed to install dependencies: %w", summary.Err())
+	}
+	output.Success("Installed dependencies of %d project(s)", len(tasks))
+	return nil
+}
@@ -0,0 +1,73 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveInstallMode(t *testing.T) {
+	tests := []struct {
+		name    string
+		flag    string
+		config  string
+		want    string
+		wantErr bool
+	}{
+		{name: "default", want: installAlways},
+		{name: "flag", flag: "never", want: installNever},
+		{name: "config", config: "run:\n  install: missing\n", want: installMissing},
+		{name: "flag wins over config", flag: "always", config: "run:\n  install: never\n", want: installAlways},
+		{name: "config without run section", config: "aliases:\n  api: backend\n", want: installAlways},
+		{name: "invalid flag", flag: "sometimes", wantErr: true},
+		{name: "invalid config", config: "run:\n  install: sometimes\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if tt.config != "" {
+				if err := os.WriteFile(filepath.Join(dir, ".azdapp.yaml"), []byte(tt.config), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+			runInstall = tt.flag
+			t.Cleanup(func() { runInstall = "" })
+
+			got, err := resolveInstallMode(dir)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolveInstallMode() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("resolveInstallMode() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInstallMissingDependencies_SkipsInstalledProjects(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"azure.yaml":                  "name: test\n",
+		"web/package.json":            `{"name": "web"}`,
+		"web/node_modules/.keep":      "",
+		"api/requirements.txt":        "flask\n",
+		"api/.venv/pyvenv.cfg":        "",
+		"svc/svc.csproj":              "<Project Sdk=\"Microsoft.NET.Sdk\"></Project>",
+		"svc/obj/project.assets.json": "{}",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Every project is installed, so no package manager runs
+	if err := installMissingDependencies(filepath.Join(root, "azure.yaml"), 2); err != nil {
+		t.Errorf("installMissingDependencies() error = %v", err)
+	}
+}
@@ -0,0 +1,168 @@
+package commands
+
+import (
+	"fmt"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/jongio/azd-app/cli/src/internal/detector"
+	"github.com/jongio/azd-app/cli/src/internal/gc"
+	"github.com/jongio/azd-app/cli/src/internal/notify"
+	"github.com/jongio/azd-app/cli/src/internal/output"
+	"github.com/jongio/azd-app/cli/src/internal/procstat"
+	"github.com/jongio/azd-app/cli/src/internal/soak"
+	"github.com/jongio/azd-app/cli/src/internal/statedir"
+)
+
+// defaultLeakInterval is how often the memory of services is sampled for leaks.
+const defaultLeakInterval = 30 * time.Second
+
+// leakConfig is the run.leakDetection section of .azdapp.yaml. Leak detection runs in --watch
+// and --soak sessions unless it is disabled.
+type leakConfig struct {
+	Enabled       *bool  `yaml:"enabled"`
+	Interval      string `yaml:"interval"`      // Time between samples, e.g. 30s
+	Samples       int    `yaml:"samples"`       // Samples memory must grow across
+	GrowthPerHour string `yaml:"growthPerHour"` // Trend that counts as a leak, e.g. 100MB
+	MinGrowth     string `yaml:"minGrowth"`     // Memory a service must gain over the samples, e.g. 50MB
+	Notify        *bool  `yaml:"notify"`        // Show a desktop notification for each leak
+}
+
+// leakSettings are the resolved settings of leak detection.
+type leakSettings struct {
+	interval   time.Duration
+	thresholds soak.LeakThresholds
+	notify     bool
+}
+
+// resolveLeakSettings returns the leak detection settings of config, with defaults for the
+// values it leaves out, and whether leak detection is enabled.
+func resolveLeakSettings(config leakConfig) (leakSettings, bool, error) {
+	settings := leakSettings{interval: defaultLeakInterval, thresholds: soak.DefaultLeakThresholds, notify: true}
+	if config.Enabled != nil && !*config.Enabled {
+		return settings, false, nil
+	}
+	if config.Interval != "" {
+		d, err := time.ParseDuration(config.Interval)
+		if err != nil || d <= 0 {
+			return settings, false, fmt.Errorf("invalid run.leakDetection.interval in %s: %q", detector.ConfigFileName, config.Interval)
+		}
+		settings.interval = d
+	}
+	if config.Samples != 0 {
+		if config.Samples < 2 {
+			return settings, false, fmt.Errorf("invalid run.leakDetection.samples in %s: %d (must be at least 2)", detector.ConfigFileName, config.Samples)
+		}
+		settings.thresholds.Samples = config.Samples
+	}
+	for _, size := range []struct {
+		key   string
+		value string
+		into  *uint64
+	}{
+		{"growthPerHour", config.GrowthPerHour, &settings.thresholds.GrowthPerHour},
+		{"minGrowth", config.MinGrowth, &settings.thresholds.MinGrowth},
+	} {
+		if size.value == "" {
+			continue
+		}
+		n, err := gc.ParseSize(size.value)
+		if err != nil {
+			return settings, false, fmt.Errorf("invalid run.leakDetection.%s in %s: %w", size.key, detector.ConfigFileName, err)
+		}
+		*size.into = uint64(n)
+	}
+	if config.Notify != nil {
+		settings.notify = *config.Notify
+	}
+	return settings, true, nil
+}
+
+// startLeakDetection samples the memory of the services of session every interval of
+// settings and warns about each service whose memory grows steadily, with a desktop
+// notification when settings ask for one. The returned function stops sampling.
+func startLeakDetection(session *runSession, settings leakSettings) func() {
+	leaks := soak.NewLeakDetector(settings.thresholds)
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(settings.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case now := <-ticker.C:
+				for _, leak := range sampleLeaks(session, leaks, now) {
+					message := describeLeak(leak)
+					session.logger.LogWarning(leak.Service, message)
+					if settings.notify {
+						_ = notify.Send("azd app: "+leak.Service+" may be leaking memory", message)
+					}
+				}
+			}
+		}
+	}()
+	return func() {
+		close(stop)
+		wg.Wait()
+	}
+}
+
+// sampleLeaks adds the memory of each running service of session to detector at now and
+// returns the leaks it reports.
+func sampleLeaks(session *runSession, detector *soak.LeakDetector, now time.Time) []*soak.Leak {
+	session.mu.Lock()
+	processes := maps.Clone(session.result.Processes)
+	session.mu.Unlock()
+
+	var leaks []*soak.Leak
+	for name, process := range processes {
+		// The process of a containerized service is the docker CLI, not the service
+		if process.Process == nil || process.Runtime.Sandbox != nil || process.Runtime.Container != nil {
+			continue
+		}
+		select {
+		case <-process.Exited():
+			continue
+		default:
+		}
+		rss, err := procstat.RSS(process.Process.Pid)
+		if err != nil {
+			continue
+		}
+		if leak := detector.Add(name, soak.Sample{Time: now, PID: process.Process.Pid, RSS: rss}); leak != nil {
+			leaks = append(leaks, leak)
+		}
+	}
+	return leaks
+}
+
+// describeLeak describes a leak: how much memory grew, over what time, at what rate.
+func describeLeak(leak *soak.Leak) string {
+	return fmt.Sprintf("Memory grew steadily by %s over %s to %s (%s/h); it may be leaking",
+		statedir.FormatSize(int64(leak.Growth)), leak.Over.Round(time.Second), statedir.FormatSize(int64(leak.RSS)), formatGrowth(leak.GrowthPerHour))
+}
+
+// leakDetection returns the leak detection settings of the workspace in projectDir, and
+// whether leak detection runs in this session: a --watch or --soak session where it isn't
+// disabled.
+func leakDetection(projectDir string) (leakSettings, bool) {
+	if !runWatch && runSoak <= 0 {
+		return leakSettings{}, false
+	}
+	config, err := loadRunConfig(projectDir)
+	if err != nil {
+		output.Warning("Leak detection disabled: %v", err)
+		return leakSettings{}, false
+	}
+	settings, enabled, err := resolveLeakSettings(config.LeakDetection)
+	if err != nil {
+		output.Warning("Leak detection disabled: %v", err)
+		return leakSettings{}, false
+	}
+	return settings, enabled
+}
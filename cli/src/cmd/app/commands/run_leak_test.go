@@ -0,0 +1,68 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jongio/azd-app/cli/src/internal/soak"
+)
+
+func TestResolveLeakSettings(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      string
+		want        leakSettings
+		wantEnabled bool
+		wantErr     bool
+	}{
+		{name: "default", want: leakSettings{interval: defaultLeakInterval, thresholds: soak.DefaultLeakThresholds, notify: true}, wantEnabled: true},
+		{
+			name:        "tuned",
+			config:      "run:\n  leakDetection:\n    interval: 1m\n    samples: 5\n    growthPerHour: 1GB\n    minGrowth: 10MB\n    notify: false\n",
+			want:        leakSettings{interval: time.Minute, thresholds: soak.LeakThresholds{GrowthPerHour: 1 << 30, MinGrowth: 10 << 20, Samples: 5}},
+			wantEnabled: true,
+		},
+		{name: "disabled", config: "run:\n  leakDetection:\n    enabled: false\n"},
+		{name: "invalid interval", config: "run:\n  leakDetection:\n    interval: often\n", wantErr: true},
+		{name: "too few samples", config: "run:\n  leakDetection:\n    samples: 1\n", wantErr: true},
+		{name: "invalid size", config: "run:\n  leakDetection:\n    growthPerHour: lots\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if tt.config != "" {
+				if err := os.WriteFile(filepath.Join(dir, ".azdapp.yaml"), []byte(tt.config), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+			config, err := loadRunConfig(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, enabled, err := resolveLeakSettings(config.LeakDetection)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolveLeakSettings() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if enabled != tt.wantEnabled {
+				t.Errorf("resolveLeakSettings() enabled = %v, want %v", enabled, tt.wantEnabled)
+			}
+			if enabled && got != tt.want {
+				t.Errorf("resolveLeakSettings() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDescribeLeak(t *testing.T) {
+	leak := &soak.Leak{Service: "api", RSS: 900 << 20, Growth: 300 << 20, GrowthPerHour: 1 << 30, Over: 17*time.Minute + 400*time.Millisecond}
+	want := "Memory grew steadily by 300.0 MB over 17m0s to 900.0 MB (+1.0 GB/h); it may be leaking"
+	if got := describeLeak(leak); got != want {
+		t.Errorf("describeLeak() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,149 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jongio/azd-app/cli/src/internal/output"
+	"github.com/jongio/azd-app/cli/src/internal/registry"
+	"github.com/jongio/azd-app/cli/src/internal/service"
+)
+
+// restartResetAfter is how long a restarted service has to run before its restarts are
+// counted from zero again, so that rare crashes of a long-running service don't add up.
+const restartResetAfter = time.Minute
+
+// replacedPollInterval is how often a supervisor checks whether a service that azd app stopped,
+// e.g. to restart it on a change, has been replaced.
+const replacedPollInterval = 100 * time.Millisecond
+
+// startSupervisor restarts the services of session that exit, as their restart policies say,
+// until the returned stop function is called. A service that exits more often than its policy
+// allows is left stopped and fail is called with why, so that the session ends with an error.
+func startSupervisor(session *runSession, fail func(error)) func() {
+	var names []string
+	session.mu.Lock()
+	for name, process := range session.result.Processes {
+		if process.Runtime.Restart.Enabled() {
+			names = append(names, name)
+		}
+	}
+	session.mu.Unlock()
+	if len(names) == 0 {
+		return func() {}
+	}
+	sort.Strings(names)
+	output.Info("♻️ Restarting on exit: %s", strings.Join(names, ", "))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	for _, name := range names {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			session.supervise(ctx, name, fail)
+		}()
+	}
+
+	return func() {
+		cancel()
+		wg.Wait()
+	}
+}
+
+// restartSupervisor supervises the services again after a reload.
+func (s *runSession) restartSupervisor() {
+	s.stopSupervisor = startSupervisor(s, s.fail)
+}
+
+// supervise restarts the named service whenever it exits, as its restart policy says, until
+// ctx is done, the policy leaves it stopped, or it is no longer in the process map.
+func (s *runSession) supervise(ctx context.Context, name string, fail func(error)) {
+	restarts := 0
+	for {
+		s.mu.Lock()
+		process, exists := s.result.Processes[name]
+		s.mu.Unlock()
+		if !exists {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-process.Exited():
+		}
+		s.logger.Records().WriteExit(process)
+
+		// Stopped on purpose, e.g. to restart it on a change: supervise its replacement
+		if process.Stopping() {
+			if !s.waitForReplacement(ctx, name, process) {
+				return
+			}
+			continue
+		}
+
+		policy := process.Runtime.Restart
+		code := process.ExitCode()
+		if time.Since(process.StartTime) >= restartResetAfter {
+			restarts = 0
+		}
+		if !policy.ShouldRestart(code) {
+			s.logger.LogService(name, fmt.Sprintf("Exited with code %d", code))
+			_ = registry.GetRegistry(s.cwd).UpdateStatus(name, "stopped", "unknown")
+			return
+		}
+		if policy.Exhausted(restarts) {
+			s.logger.LogService(name, fmt.Sprintf("✗ Exited with code %d, not restarting after %d restart(s)", code, restarts))
+			_ = registry.GetRegistry(s.cwd).UpdateStatus(name, "error", service.HealthCrashed)
+			fail(fmt.Errorf("service %s exited with code %d after %d restart(s), the most its restart policy allows", name, code, restarts))
+			return
+		}
+
+		restarts++
+		delay := policy.Delay(restarts)
+		s.logger.LogService(name, fmt.Sprintf("✗ Exited with code %d, restarting in %s (restart %d)", code, delay.Round(time.Millisecond), restarts))
+		_ = registry.GetRegistry(s.cwd).UpdateStatus(name, "starting", service.HealthCrashed)
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		if err := s.restartService(name); err != nil {
+			s.logger.LogService(name, "✗ "+err.Error())
+			fail(err)
+			return
+		}
+	}
+}
+
+// waitForReplacement waits until the named service, whose process was stopped on purpose,
+// is replaced by a new process, and reports whether it was; it is not when it was removed or
+// ctx is done.
+func (s *runSession) waitForReplacement(ctx context.Context, name string, stopped *service.ServiceProcess) bool {
+	ticker := time.NewTicker(replacedPollInterval)
+	defer ticker.Stop()
+	for {
+		s.mu.Lock()
+		process, exists := s.result.Processes[name]
+		s.mu.Unlock()
+		if !exists {
+			return false
+		}
+		if process != stopped {
+			return true
+		}
+		select {
+		case <-ctx.Done():
+			return false
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package commands
+
+import (
+	"context"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jongio/azd-app/cli/src/internal/retry"
+	"github.com/jongio/azd-app/cli/src/internal/service"
+)
+
+func TestSupervise(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping process test in short mode")
+	}
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+
+	tests := []struct {
+		name     string
+		mode     string
+		script   string
+		wantFail string // Substring of the error the session ends with; empty when it doesn't
+	}{
+		{name: "on-failure exhausts its restarts", mode: service.RestartOnFailure, script: "exit 2", wantFail: "exited with code 2 after 2 restart(s)"},
+		{name: "on-failure leaves a clean exit", mode: service.RestartOnFailure, script: "exit 0"},
+		{name: "never", mode: service.RestartNever, script: "exit 1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			rt := &service.ServiceRuntime{
+				Name:       "worker",
+				WorkingDir: dir,
+				Command:    "sh",
+				Args:       []string{"-c", tt.script},
+				Language:   "shell",
+				Restart:    service.RestartPolicy{Mode: tt.mode, Retry: retry.Policy{MaxAttempts: 3, BaseDelay: time.Millisecond, Backoff: 1}},
+			}
+			process, err := service.StartService(context.Background(), rt, nil, dir)
+			if err != nil {
+				t.Fatalf("StartService() error = %v", err)
+			}
+			t.Cleanup(func() { _ = service.GetLogManager(dir).RemoveBuffer(rt.Name) })
+
+			session := &runSession{
+				cwd:    dir,
+				logger: service.NewServiceLogger(false),
+				result: &service.OrchestrationResult{Processes: map[string]*service.ServiceProcess{"worker": process}},
+			}
+			failed := make(chan error, 1)
+			stop := startSupervisor(session, func(err error) { failed <- err })
+			defer stop()
+
+			select {
+			case err := <-failed:
+				if tt.wantFail == "" || !strings.Contains(err.Error(), tt.wantFail) {
+					t.Errorf("session failed with %v, want %q", err, tt.wantFail)
+				}
+			case <-time.After(500 * time.Millisecond):
+				if tt.wantFail != "" {
+					t.Fatalf("session did not fail, want %q", tt.wantFail)
+				}
+				session.mu.Lock()
+				current := session.result.Processes["worker"]
+				session.mu.Unlock()
+				if current != process {
+					t.Error("service was restarted, want it left stopped")
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,201 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/jongio/azd-app/cli/src/internal/output"
+	"github.com/jongio/azd-app/cli/src/internal/service"
+	"github.com/jongio/azd-app/cli/src/internal/statedir"
+)
+
+// runSignalHandler performs the actions that control signals request from a running session.
+type runSignalHandler interface {
+	reload() error
+	dumpDiagnostics() (string, error)
+}
+
+// runSession is the state of an `azd app run` session that control signals act on.
+type runSession struct {
+	azureYamlPath  string
+	azureYamlDir   string
+	cwd            string
+	services       map[string]service.Service
+	logger         *service.ServiceLogger
+	result         *service.OrchestrationResult
+	stopChaos      func()
+	stopWatch      func()
+	stopHealth     func()
+	stopSupervisor func()
+	fail           func(error) // Ends the session with an error, e.g. when a service exhausts its restarts
+
+	mu sync.Mutex
+}
+
+// waitForShutdown blocks until SIGINT or SIGTERM is received and returns that signal, or
+// until ctx is done, e.g. when --deadline passes, and returns nil.
+// Until then SIGHUP reloads azure.yaml and SIGUSR1 writes a diagnostics dump; failures of
+// either are reported and the session keeps running.
+func waitForShutdown(ctx context.Context, sigChan <-chan os.Signal, handler runSignalHandler) os.Signal {
+	for {
+		var sig os.Signal
+		select {
+		case <-ctx.Done():
+			return nil
+		case received, ok := <-sigChan:
+			if !ok {
+				return nil
+			}
+			sig = received
+		}
+
+		switch {
+		case isReloadSignal(sig):
+			output.Newline()
+			output.Info("🔄 Reloading azure.yaml (%s)", sig)
+			if err := handler.reload(); err != nil {
+				output.Warning("Reload failed: %v", err)
+			}
+		case isDumpSignal(sig):
+			path, err := handler.dumpDiagnostics()
+			if err != nil {
+				output.Warning("Failed to write diagnostics: %v", err)
+				continue
+			}
+			output.Info("🩺 Diagnostics written to %s", path)
+		default:
+			return sig
+		}
+	}
+}
+
+// reload parses azure.yaml again and applies the differences to the running services:
+// removed services are stopped, added services are started, and changed services are restarted.
+// Services that fail to start are reported and left stopped.
+func (s *runSession) reload() error {
+	azureYaml, err := service.ParseAzureYaml(s.azureYamlPath)
+	if err != nil {
+		return fmt.Errorf("failed to parse azure.yaml: %w", err)
+	}
+	services, err := filterServices(azureYaml, s.azureYamlDir)
+	if err != nil {
+		return err
+	}
+
+	changes := service.DiffServices(s.services, services)
+	if changes.IsEmpty() {
+		output.Info("No service changes in azure.yaml")
+		return nil
+	}
+
+	// Stopped services are no longer supervised, and added services must be
+	if s.stopSupervisor != nil {
+		s.stopSupervisor()
+		s.stopSupervisor = nil
+		defer s.restartSupervisor()
+	}
+	// Stopped services would be reported as crashed, so probing is paused while they are replaced
+	if s.stopHealth != nil {
+		s.stopHealth()
+		s.stopHealth = nil
+		defer s.restartHealth()
+	}
+	// Chaos holds on to the process map, so it is paused while services are replaced
+	if s.stopChaos != nil {
+		s.stopChaos()
+		s.stopChaos = nil
+		defer s.restartChaos()
+	}
+	// Watching restarts services in the process map too, and must pick up the new services
+	if s.stopWatch != nil {
+		s.stopWatch()
+		s.stopWatch = nil
+		defer s.restartWatch()
+	}
+
+	s.mu.Lock()
+	toStop := make(map[string]*service.ServiceProcess)
+	for _, name := range append(append([]string{}, changes.Removed...), changes.Changed...) {
+		if process, ok := s.result.Processes[name]; ok {
+			toStop[name] = process
+			delete(s.result.Processes, name)
+		}
+	}
+	s.mu.Unlock()
+	service.StopAllServices(toStop)
+	for _, name := range changes.Removed {
+		output.ItemSuccess("Stopped %s (removed from azure.yaml)", name)
+	}
+	s.services = services
+
+	toStart := make(map[string]service.Service)
+	for _, name := range append(append([]string{}, changes.Added...), changes.Changed...) {
+		toStart[name] = services[name]
+	}
+	if len(toStart) == 0 {
+		return nil
+	}
+
+	runtimes, err := detectServiceRuntimes(toStart, s.azureYamlDir, runtimeModeAzd)
+	if err != nil {
+		return err
+	}
+	if err := prepareRuntimes(azureYaml, s.azureYamlDir, runtimes); err != nil {
+		return err
+	}
+	envVars, err := loadEnvironmentVariables()
+	if err != nil {
+		return err
+	}
+	// On failure the orchestrator has already stopped the services it started
+	result, err := service.OrchestrateServices(commandContext(), runtimes, envVars, s.logger)
+	if err != nil {
+		return fmt.Errorf("service orchestration failed: %w", err)
+	}
+	s.mu.Lock()
+	for name, process := range result.Processes {
+		s.result.Processes[name] = process
+	}
+	s.mu.Unlock()
+
+	output.Success("Reloaded: %d added, %d removed, %d restarted", len(changes.Added), len(changes.Removed), len(changes.Changed))
+	return nil
+}
+
+// restartChaos starts chaos again after a reload.
+func (s *runSession) restartChaos() {
+	stop, err := startChaos(s)
+	if err != nil {
+		output.Warning("Chaos disabled: %v", err)
+		return
+	}
+	s.stopChaos = stop
+}
+
+// dumpDiagnostics writes the goroutine stacks and service state of the session to a file in
+// the session directory and returns its path.
+func (s *runSession) dumpDiagnostics() (string, error) {
+	sessionDir, err := statedir.SessionDir(s.cwd)
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(sessionDir, fmt.Sprintf("diagnostics-%s.txt", time.Now().Format("20060102-150405")))
+
+	// #nosec G304 -- Path is built from the session directory
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return "", fmt.Errorf("failed to create diagnostics file: %w", err)
+	}
+	defer file.Close()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := service.WriteDiagnostics(file, s.result.Processes); err != nil {
+		return "", err
+	}
+	return path, nil
+}
@@ -0,0 +1,156 @@
+//go:build !windows
+
+package commands
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/jongio/azd-app/cli/src/internal/service"
+	"github.com/jongio/azd-app/cli/src/internal/statedir"
+)
+
+// fakeSignalHandler records the actions requested by control signals.
+type fakeSignalHandler struct {
+	reloads int
+	dumps   int
+	err     error
+}
+
+func (h *fakeSignalHandler) reload() error {
+	h.reloads++
+	return h.err
+}
+
+func (h *fakeSignalHandler) dumpDiagnostics() (string, error) {
+	h.dumps++
+	return "diagnostics.txt", h.err
+}
+
+func TestWaitForShutdown(t *testing.T) {
+	tests := []struct {
+		name        string
+		signals     []os.Signal
+		handlerErr  error
+		wantSignal  os.Signal
+		wantReloads int
+		wantDumps   int
+	}{
+		{
+			name:       "interrupt stops immediately",
+			signals:    []os.Signal{os.Interrupt},
+			wantSignal: os.Interrupt,
+		},
+		{
+			name:        "control signals keep the session running",
+			signals:     []os.Signal{syscall.SIGHUP, syscall.SIGUSR1, syscall.SIGHUP, syscall.SIGTERM},
+			wantSignal:  syscall.SIGTERM,
+			wantReloads: 2,
+			wantDumps:   1,
+		},
+		{
+			name:        "failed actions do not stop the session",
+			signals:     []os.Signal{syscall.SIGHUP, syscall.SIGUSR1, syscall.SIGTERM},
+			handlerErr:  errors.New("boom"),
+			wantSignal:  syscall.SIGTERM,
+			wantReloads: 1,
+			wantDumps:   1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sigChan := make(chan os.Signal, len(tt.signals))
+			for _, sig := range tt.signals {
+				sigChan <- sig
+			}
+			handler := &fakeSignalHandler{err: tt.handlerErr}
+
+			got := waitForShutdown(context.Background(), sigChan, handler)
+
+			if got != tt.wantSignal {
+				t.Errorf("waitForShutdown() = %v, want %v", got, tt.wantSignal)
+			}
+			if handler.reloads != tt.wantReloads || handler.dumps != tt.wantDumps {
+				t.Errorf("reloads = %d, dumps = %d; want %d and %d", handler.reloads, handler.dumps, tt.wantReloads, tt.wantDumps)
+			}
+		})
+	}
+}
+
+func TestWaitForShutdownDeadline(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if got := waitForShutdown(ctx, make(chan os.Signal), &fakeSignalHandler{}); got != nil {
+		t.Errorf("waitForShutdown() = %v, want nil when the context is done", got)
+	}
+}
+
+func TestRunSessionReload(t *testing.T) {
+	dir := t.TempDir()
+	azureYamlPath := filepath.Join(dir, "azure.yaml")
+	writeAzureYaml := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(azureYamlPath, []byte(content), 0600); err != nil {
+			t.Fatalf("failed to write azure.yaml: %v", err)
+		}
+	}
+	writeAzureYaml("name: demo\nservices:\n  api:\n    host: containerapp\n    project: ./api\n  web:\n    host: containerapp\n    project: ./web\n")
+
+	azureYaml, err := service.ParseAzureYaml(azureYamlPath)
+	if err != nil {
+		t.Fatalf("ParseAzureYaml failed: %v", err)
+	}
+	session := &runSession{
+		azureYamlPath: azureYamlPath,
+		azureYamlDir:  dir,
+		cwd:           dir,
+		services:      azureYaml.Services,
+		result:        &service.OrchestrationResult{Processes: map[string]*service.ServiceProcess{}},
+	}
+
+	if err := session.reload(); err != nil {
+		t.Fatalf("reload without changes failed: %v", err)
+	}
+	if len(session.services) != 2 {
+		t.Errorf("services = %d after reload without changes, want 2", len(session.services))
+	}
+
+	writeAzureYaml("name: demo\nservices:\n  api:\n    host: containerapp\n    project: ./api\n")
+	if err := session.reload(); err != nil {
+		t.Fatalf("reload with a removed service failed: %v", err)
+	}
+	if _, exists := session.services["web"]; exists || len(session.services) != 1 {
+		t.Errorf("services after removing web = %v, want only api", session.services)
+	}
+
+	writeAzureYaml("services: [")
+	if err := session.reload(); err == nil {
+		t.Error("expected reload of an invalid azure.yaml to fail")
+	}
+	if len(session.services) != 1 {
+		t.Errorf("a failed reload changed the services: %v", session.services)
+	}
+}
+
+func TestRunSessionDumpDiagnostics(t *testing.T) {
+	t.Setenv(statedir.RootEnvVar, t.TempDir())
+	session := &runSession{
+		cwd:    t.TempDir(),
+		result: &service.OrchestrationResult{Processes: map[string]*service.ServiceProcess{}},
+	}
+
+	path, err := session.dumpDiagnostics()
+	if err != nil {
+		t.Fatalf("dumpDiagnostics failed: %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil || info.Size() == 0 {
+		t.Errorf("diagnostics file %s was not written: %v", path, err)
+	}
+}
@@ -0,0 +1,21 @@
+//go:build !windows
+
+package commands
+
+import (
+	"os"
+	"syscall"
+)
+
+// controlSignals are the signals a run session handles besides SIGINT and SIGTERM.
+var controlSignals = []os.Signal{syscall.SIGHUP, syscall.SIGUSR1}
+
+// isReloadSignal reports whether sig asks the session to reload azure.yaml.
+func isReloadSignal(sig os.Signal) bool {
+	return sig == syscall.SIGHUP
+}
+
+// isDumpSignal reports whether sig asks the session to write a diagnostics dump.
+func isDumpSignal(sig os.Signal) bool {
+	return sig == syscall.SIGUSR1
+}
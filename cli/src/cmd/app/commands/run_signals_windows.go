@@ -0,0 +1,19 @@
+//go:build windows
+
+package commands
+
+import "os"
+
+// controlSignals are the signals a run session handles besides SIGINT and SIGTERM.
+// Windows has no SIGHUP or SIGUSR1 to send to a process, so there are none.
+var controlSignals []os.Signal
+
+// isReloadSignal reports whether sig asks the session to reload azure.yaml.
+func isReloadSignal(os.Signal) bool {
+	return false
+}
+
+// isDumpSignal reports whether sig asks the session to write a diagnostics dump.
+func isDumpSignal(os.Signal) bool {
+	return false
+}
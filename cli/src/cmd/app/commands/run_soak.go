@@ -0,0 +1,179 @@
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jongio/azd-app/cli/src/internal/output"
+	"github.com/jongio/azd-app/cli/src/internal/procstat"
+	"github.com/jongio/azd-app/cli/src/internal/service"
+	"github.com/jongio/azd-app/cli/src/internal/soak"
+	"github.com/jongio/azd-app/cli/src/internal/statedir"
+)
+
+// defaultSoakInterval is how often services are sampled during --soak.
+const defaultSoakInterval = time.Minute
+
+// errSoakFinished ends a session once --soak has passed; the session then succeeds.
+var errSoakFinished = errors.New("soak finished")
+
+// startSoak samples the services of session every interval, printing a line about them each
+// time, and ends the session through end with errSoakFinished once duration has passed. The
+// returned function stops sampling and returns the report.
+func startSoak(session *runSession, duration, interval time.Duration, end func(error)) func() *soak.Report {
+	start := time.Now()
+	recorder := soak.NewRecorder(start, interval)
+	output.Info("🧪 Soaking for %s, sampling every %s", duration, interval)
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		deadline := time.NewTimer(duration)
+		defer deadline.Stop()
+		last := start
+		for {
+			select {
+			case <-stop:
+				return
+			case <-deadline.C:
+				sampleSoak(session, recorder, last)
+				end(errSoakFinished)
+				return
+			case now := <-ticker.C:
+				samples := sampleSoak(session, recorder, last)
+				last = now
+				session.logger.LogInfo(fmt.Sprintf("Soak %s of %s: %s", now.Sub(start).Round(time.Second), duration, describeSoakSamples(samples)))
+			}
+		}
+	}()
+
+	return func() *soak.Report {
+		close(stop)
+		wg.Wait()
+		return recorder.Report(time.Now())
+	}
+}
+
+// sampleSoak records a sample of each service of session, counting the error lines logged
+// since last, and returns the samples by service.
+func sampleSoak(session *runSession, recorder *soak.Recorder, last time.Time) map[string]soak.Sample {
+	session.mu.Lock()
+	processes := maps.Clone(session.result.Processes)
+	session.mu.Unlock()
+
+	now := time.Now()
+	health := service.GetHealthManager(session.cwd)
+	samples := make(map[string]soak.Sample, len(processes))
+	for name, process := range processes {
+		sample := soak.Sample{Time: now, State: soak.StateRunning}
+		if process.Process != nil {
+			sample.PID = process.Process.Pid
+		}
+		select {
+		case <-process.Exited():
+			sample.State = soak.StateExited
+			if process.Stopping() {
+				sample.State = soak.StateStopped
+			}
+		default:
+			if status, ok := health.Status(name); ok {
+				sample.State = status.State
+			}
+			// The process of a containerized service is the docker CLI, not the service
+			if process.Runtime.Sandbox == nil && process.Runtime.Container == nil {
+				if rss, err := procstat.RSS(sample.PID); err == nil {
+					sample.RSS = rss
+				}
+			}
+		}
+		if buffer, ok := service.GetLogManager(session.cwd).GetBuffer(name); ok {
+			for _, entry := range buffer.GetSince(last) {
+				if entry.Level == service.LogLevelError {
+					sample.Errors++
+				}
+			}
+		}
+		recorder.Add(name, sample)
+		samples[name] = sample
+	}
+	return samples
+}
+
+// describeSoakSamples describes samples in a line: the state and memory of each service.
+func describeSoakSamples(samples map[string]soak.Sample) string {
+	parts := make([]string, 0, len(samples))
+	for _, name := range slices.Sorted(maps.Keys(samples)) {
+		sample := samples[name]
+		part := name + " " + sample.State
+		if sample.RSS > 0 {
+			part += " " + statedir.FormatSize(int64(sample.RSS))
+		}
+		if sample.Errors > 0 {
+			part += fmt.Sprintf(" %d error(s)", sample.Errors)
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, " · ")
+}
+
+// printSoakReport writes report to the soak directory of the workspace and prints a summary.
+func printSoakReport(report *soak.Report, cwd string) {
+	path, err := writeSoakReport(report, cwd)
+
+	output.Section("🧪", fmt.Sprintf("Soak report (%s)", report.End.Sub(report.Start).Round(time.Second)))
+	table := output.NewTable("Service", "Restarts", "Memory", "Growth/h", "Errors/h", "Healthy")
+	table.Indent = "   "
+	for _, svc := range report.Services {
+		memory, growth := "-", "-"
+		if svc.MemoryEnd > 0 {
+			memory = fmt.Sprintf("%s → %s (peak %s)", statedir.FormatSize(int64(svc.MemoryStart)), statedir.FormatSize(int64(svc.MemoryEnd)), statedir.FormatSize(int64(svc.MemoryPeak)))
+			growth = formatGrowth(svc.MemoryGrowthPerHour)
+		}
+		table.AddRow(svc.Service, fmt.Sprint(svc.Restarts), memory, growth, fmt.Sprintf("%.1f", svc.ErrorsPerHour), fmt.Sprintf("%.0f%%", svc.HealthyPercent))
+	}
+	table.Print()
+	output.Newline()
+	if err != nil {
+		output.Warning("Failed to save the soak report: %v", err)
+		return
+	}
+	output.Info("Report with every sample: %s", path)
+}
+
+// formatGrowth formats a memory trend in bytes per hour, with its sign.
+func formatGrowth(bytesPerHour float64) string {
+	if bytesPerHour < 0 {
+		return "-" + statedir.FormatSize(int64(-bytesPerHour))
+	}
+	return "+" + statedir.FormatSize(int64(bytesPerHour))
+}
+
+// writeSoakReport writes report as JSON to the soak directory of the workspace and returns
+// its path.
+func writeSoakReport(report *soak.Report, cwd string) (string, error) {
+	dir, err := statedir.DataDir(cwd, "soak")
+	if err != nil {
+		return "", err
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, "soak-"+report.Start.Format("20060102-150405")+".json")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return path, nil
+}
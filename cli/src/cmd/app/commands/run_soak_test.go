@@ -0,0 +1,27 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/jongio/azd-app/cli/src/internal/soak"
+)
+
+func TestDescribeSoakSamples(t *testing.T) {
+	samples := map[string]soak.Sample{
+		"web": {State: soak.StateExited},
+		"api": {State: "healthy", RSS: 150 << 20, Errors: 2},
+	}
+	want := "api healthy 150.0 MB 2 error(s) · web exited"
+	if got := describeSoakSamples(samples); got != want {
+		t.Errorf("describeSoakSamples() = %q, want %q", got, want)
+	}
+}
+
+func TestFormatGrowth(t *testing.T) {
+	if got := formatGrowth(3 << 20); got != "+3.0 MB" {
+		t.Errorf("formatGrowth(3 MB) = %q", got)
+	}
+	if got := formatGrowth(-512 << 10); got != "-512.0 KB" {
+		t.Errorf("formatGrowth(-512 KB) = %q", got)
+	}
+}
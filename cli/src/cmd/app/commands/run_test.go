@@ -3,10 +3,12 @@ package commands
 import (
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"testing"
 
 	"github.com/jongio/azd-app/cli/src/internal/detector"
+	"github.com/jongio/azd-app/cli/src/internal/service"
 	"github.com/spf13/cobra"
 )
 
@@ -206,3 +208,52 @@ public class Program {
 		t.Errorf("Expected project file %q, got %q", csprojPath, aspireProject.ProjectFile)
 	}
 }
+
+func TestFilterServicesFlags(t *testing.T) {
+	projectDir := t.TempDir()
+	azureYaml := &service.AzureYaml{
+		Services: map[string]service.Service{
+			"web":    {Uses: []string{"api"}},
+			"api":    {},
+			"worker": {},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    []string
+		wantErr bool
+	}{
+		{name: "no filter", want: []string{"api", "web", "worker"}},
+		{name: "service filter", args: []string{"-s", "web"}, want: []string{"web"}},
+		{name: "only adds dependencies", args: []string{"--only", "web"}, want: []string{"api", "web"}},
+		{name: "only without dependencies", args: []string{"--only", "web", "--no-deps"}, want: []string{"web"}},
+		{name: "except", args: []string{"--except", "worker,api"}, want: []string{"web"}},
+		{name: "service with only", args: []string{"-s", "web", "--only", "api"}, wantErr: true},
+		{name: "unknown service", args: []string{"--except", "frontend"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runServiceFilter, runOnly, runExcept, runNoDeps = "", nil, nil, false
+			t.Cleanup(func() { runServiceFilter, runOnly, runExcept, runNoDeps = "", nil, nil, false })
+			if err := NewRunCommand().ParseFlags(tt.args); err != nil {
+				t.Fatalf("ParseFlags() error = %v", err)
+			}
+
+			services, err := filterServices(azureYaml, projectDir)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("filterServices() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var got []string
+			for name := range services {
+				got = append(got, name)
+			}
+			sort.Strings(got)
+			if !tt.wantErr && strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("filterServices() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
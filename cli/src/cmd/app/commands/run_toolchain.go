@@ -0,0 +1,87 @@
+package commands
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/jongio/azd-app/cli/src/internal/detector"
+	"github.com/jongio/azd-app/cli/src/internal/doctor"
+	"github.com/jongio/azd-app/cli/src/internal/output"
+	"github.com/jongio/azd-app/cli/src/internal/service"
+	"github.com/jongio/azd-app/cli/src/internal/types"
+)
+
+// toolchainMismatch is a pinned tool that the installed one doesn't satisfy, and the services
+// whose projects pin it.
+type toolchainMismatch struct {
+	check    doctor.Check
+	services []string
+}
+
+// checkToolchain checks the installed node, package managers, Python, and .NET SDK against
+// the versions the projects of runtimes pin, as doctor does. Mismatches are warnings, or fail
+// with --strict. Sandboxed services run on the tools of their images, so they aren't checked.
+func checkToolchain(runtimes []*service.ServiceRuntime, azureYamlDir string, toolchain doctor.Toolchain) error {
+	if sandboxMode {
+		return nil
+	}
+	toolchain = doctor.Cached(toolchain)
+
+	var mismatches []*toolchainMismatch
+	byRequirement := make(map[doctor.Requirement]*toolchainMismatch)
+	for _, runtime := range runtimes {
+		for _, check := range doctor.CheckPins(azureYamlDir, runtimePins(runtime, azureYamlDir), toolchain) {
+			if !check.Failed() {
+				continue
+			}
+			m, ok := byRequirement[check.Requirement]
+			if !ok {
+				m = &toolchainMismatch{check: check}
+				byRequirement[check.Requirement] = m
+				mismatches = append(mismatches, m)
+			}
+			m.services = append(m.services, runtime.Name)
+		}
+	}
+	if len(mismatches) == 0 {
+		return nil
+	}
+
+	lines := make([]string, 0, len(mismatches))
+	for _, m := range mismatches {
+		slices.Sort(m.services)
+		lines = append(lines, fmt.Sprintf("%s: %s, pinned by %s", strings.Join(m.services, ", "), m.check.Message, m.check.Source))
+	}
+	if runStrict {
+		return fmt.Errorf("the installed tools don't satisfy the versions the projects pin (see 'azd app doctor'):\n  - %s", strings.Join(lines, "\n  - "))
+	}
+	for i, m := range mismatches {
+		output.Warning("%s", lines[i])
+		if m.check.Fix != "" {
+			output.Item("%s", output.Muted("→ %s", m.check.Fix))
+		}
+	}
+	output.Info("💡 Use --strict to stop instead of running with these tools")
+	return nil
+}
+
+// runtimePins returns the versions of the tools the project of runtime pins: node and its
+// package manager for Node.js, Python, or the .NET SDK.
+func runtimePins(runtime *service.ServiceRuntime, azureYamlDir string) []types.VersionPin {
+	switch runtime.Language {
+	case "JavaScript", "TypeScript":
+		var pins []types.VersionPin
+		for _, pin := range detector.NodeVersionPins(runtime.WorkingDir, azureYamlDir) {
+			if pin.Tool == doctor.ToolNode || pin.Tool == runtime.PackageManager {
+				pins = append(pins, pin)
+			}
+		}
+		return pins
+	case "Python":
+		return detector.PythonVersionPins(runtime.WorkingDir, azureYamlDir)
+	case ".NET":
+		return detector.DotnetVersionPins(runtime.WorkingDir, azureYamlDir)
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jongio/azd-app/cli/src/internal/output"
+	"github.com/jongio/azd-app/cli/src/internal/registry"
+	"github.com/jongio/azd-app/cli/src/internal/scorecard"
+	"github.com/jongio/azd-app/cli/src/internal/service"
+	"github.com/jongio/azd-app/cli/src/internal/tui"
+)
+
+// startTUI shows the services of session in the terminal UI until the returned stop function
+// is called. Quitting the UI sends an interrupt to sigChan, which ends the session like Ctrl+C.
+func startTUI(session *runSession, sigChan chan<- os.Signal) func() {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	ctl := tuiController{session: session, health: &tuiHealth{}}
+	// Rating the workspace scans it, so it starts now rather than when the panel is opened
+	go ctl.health.rate(session.azureYamlDir, session.azureYamlPath)
+	go func() {
+		defer close(done)
+		if err := tui.Run(ctx, os.Stdin, os.Stdout, ctl); err != nil {
+			if ctx.Err() == nil {
+				output.Warning("Terminal UI unavailable: %v", err)
+			}
+			return
+		}
+		select {
+		case sigChan <- os.Interrupt:
+		default:
+		}
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// tuiController gives the terminal UI the services of a run session.
+type tuiController struct {
+	session *runSession
+	health  *tuiHealth
+}
+
+// Services returns the services of the session by name.
+func (c tuiController) Services() []tui.Service {
+	c.session.mu.Lock()
+	defer c.session.mu.Unlock()
+
+	services := make([]tui.Service, 0, len(c.session.result.Processes))
+	for name, process := range c.session.result.Processes {
+		svc := tui.Service{Name: name, State: tui.StateRunning}
+		select {
+		case <-process.Exited():
+			if process.Stopping() {
+				svc.State = tui.StateStopped
+			} else {
+				svc.State = fmt.Sprintf("%s (code %d)", tui.StateExited, process.ExitCode())
+			}
+		default:
+			if process.Port > 0 {
+				svc.URL = fmt.Sprintf("http://localhost:%d", process.Port)
+			}
+		}
+		services = append(services, svc)
+	}
+	sort.Slice(services, func(i, j int) bool { return services[i].Name < services[j].Name })
+	return services
+}
+
+// Logs returns the recent output of the named service.
+func (c tuiController) Logs(name string) []service.LogEntry {
+	buffer, ok := service.GetLogManager(c.session.cwd).GetBuffer(name)
+	if !ok {
+		return nil
+	}
+	return buffer.GetRecent(maxTUILines)
+}
+
+// Restart restarts the named service, or starts it again when it was stopped.
+func (c tuiController) Restart(name string) error {
+	return c.session.restartService(name)
+}
+
+// Stop stops the named service and keeps it in the session, so it can be started again.
+func (c tuiController) Stop(name string) error {
+	return c.session.stopService(name)
+}
+
+// Health returns the health of the workspace, or nil while it is being rated.
+func (c tuiController) Health() *tui.Health {
+	return c.health.get()
+}
+
+// tuiHealth is the health of the workspace of a session, rated once.
+type tuiHealth struct {
+	mu     sync.Mutex
+	health *tui.Health
+}
+
+// rate rates the workspace at projectDir, whose azure.yaml is at azureYamlPath.
+func (h *tuiHealth) rate(projectDir, azureYamlPath string) {
+	report := scorecard.Check(projectDir, azureYamlPath, time.Now())
+	health := &tui.Health{Score: report.Score, Grade: report.Grade}
+	for _, c := range report.Categories {
+		health.Categories = append(health.Categories, tui.HealthCategory{Name: c.Name, Score: c.Score, Checked: c.Checked})
+	}
+	for _, f := range report.Recommendations {
+		health.Recommendations = append(health.Recommendations, tui.Recommendation{Severity: f.Severity, Message: f.Message, Fix: f.Fix})
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.health = health
+}
+
+// get returns the health, or nil before rate is done.
+func (h *tuiHealth) get() *tui.Health {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.health
+}
+
+// maxTUILines is how many recent lines of each service the terminal UI scrolls back through.
+const maxTUILines = 1000
+
+// stopService stops the named service without removing it from the session. A supervisor
+// doesn't restart it, since it was stopped on purpose.
+func (s *runSession) stopService(name string) error {
+	s.mu.Lock()
+	process, exists := s.result.Processes[name]
+	s.mu.Unlock()
+	if !exists {
+		return fmt.Errorf("service %s is not running", name)
+	}
+	select {
+	case <-process.Exited():
+		return fmt.Errorf("service %s is not running", name)
+	default:
+	}
+	if err := service.StopService(process); err != nil {
+		return fmt.Errorf("failed to stop %s: %w", name, err)
+	}
+	_ = registry.GetRegistry(s.cwd).UpdateStatus(name, "stopped", "unknown")
+	return nil
+}
@@ -0,0 +1,121 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jongio/azd-app/cli/src/internal/output"
+	"github.com/jongio/azd-app/cli/src/internal/registry"
+	"github.com/jongio/azd-app/cli/src/internal/service"
+	"github.com/jongio/azd-app/cli/src/internal/watch"
+)
+
+// startWatch restarts services without a native watcher whenever files in their source tree
+// change, until the returned stop function is called. The stop function waits for any
+// in-progress restart to finish before returning.
+func startWatch(session *runSession) func() {
+	dirs := make(map[string]string)
+	var native []string
+	session.mu.Lock()
+	for name, process := range session.result.Processes {
+		if service.HasNativeWatch(&process.Runtime) {
+			native = append(native, name)
+			continue
+		}
+		dirs[name] = process.Runtime.WorkingDir
+	}
+	session.mu.Unlock()
+
+	sort.Strings(native)
+	output.Info("👀 Watching for changes")
+	if len(native) > 0 {
+		output.Item("Reloaded by their own tooling: %s", strings.Join(native, ", "))
+	}
+	if len(dirs) == 0 {
+		return func() {}
+	}
+	names := make([]string, 0, len(dirs))
+	for name := range dirs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	output.Item("Restarted on change: %s", strings.Join(names, ", "))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		watch.Run(ctx, dirs, watch.DefaultInterval, func(name string, files []string) {
+			output.Info("🔁 %s: %s changed, restarting", name, describeChanges(dirs[name], files))
+			if err := session.restartService(name); err != nil {
+				output.Warning("%v", err)
+			}
+		})
+	}()
+
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// restartWatch starts watching again after a reload.
+func (s *runSession) restartWatch() {
+	s.stopWatch = startWatch(s)
+}
+
+// restartService stops a service and starts it again with the same runtime and environment.
+func (s *runSession) restartService(name string) error {
+	s.mu.Lock()
+	process, exists := s.result.Processes[name]
+	s.mu.Unlock()
+	if !exists {
+		return fmt.Errorf("service %s is not running", name)
+	}
+
+	reg := registry.GetRegistry(s.cwd)
+	restarted, err := service.RestartService(commandContext(), process, s.cwd)
+	if err != nil {
+		s.mu.Lock()
+		delete(s.result.Processes, name)
+		s.mu.Unlock()
+		_ = reg.UpdateStatus(name, "error", "unknown")
+		return fmt.Errorf("failed to restart %s: %w", name, err)
+	}
+	restarted.Ready = true
+	records := s.logger.Records()
+	started := service.Record{Service: name, Event: service.EventStarted, PID: restarted.Process.Pid}
+	if restarted.Port > 0 {
+		started.URL = fmt.Sprintf("http://localhost:%d", restarted.Port)
+	}
+	records.Write(started)
+	records.Write(service.Record{Service: name, Event: service.EventReady, PID: restarted.Process.Pid})
+
+	s.mu.Lock()
+	s.result.Processes[name] = restarted
+	s.mu.Unlock()
+
+	if entry, exists := reg.GetService(name); exists {
+		entry.PID = restarted.Process.Pid
+		entry.StartTime = time.Now()
+		_ = reg.Register(entry)
+	}
+	_ = reg.UpdateStatus(name, "running", "healthy")
+	return nil
+}
+
+// describeChanges names the changed file, relative to the service directory, or counts the
+// changed files when there are several.
+func describeChanges(dir string, files []string) string {
+	if len(files) != 1 {
+		return fmt.Sprintf("%d files", len(files))
+	}
+	if rel, err := filepath.Rel(dir, files[0]); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return files[0]
+}
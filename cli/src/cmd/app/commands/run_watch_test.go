@@ -0,0 +1,26 @@
+package commands
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestDescribeChanges(t *testing.T) {
+	dir := filepath.Join("src", "api")
+	tests := []struct {
+		name  string
+		files []string
+		want  string
+	}{
+		{name: "single file", files: []string{filepath.Join(dir, "app", "main.py")}, want: "app/main.py"},
+		{name: "several files", files: []string{filepath.Join(dir, "a.py"), filepath.Join(dir, "b.py")}, want: "2 files"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := describeChanges(dir, tt.files); got != tt.want {
+				t.Errorf("describeChanges() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/jongio/azd-app/cli/src/internal/output"
+	"github.com/jongio/azd-app/cli/src/internal/sandbox"
+	"github.com/jongio/azd-app/cli/src/internal/service"
+	"github.com/jongio/azd-app/cli/src/internal/verify"
+)
+
+// sandboxMode and sandboxImage are set by the global --sandbox and --sandbox-image flags to run
+// workspace commands in containers instead of on the host.
+var (
+	sandboxMode  bool
+	sandboxImage string
+)
+
+// SetSandbox sets whether install, build, and run commands run in containers (--sandbox), and
+// the image they run in when not chosen per command (--sandbox-image).
+func SetSandbox(enabled bool, image string) {
+	sandboxMode = enabled
+	sandboxImage = image
+}
+
+// sandboxArgs returns the global flags that pass sandbox mode on to a child azd app process.
+func sandboxArgs() []string {
+	if !sandboxMode {
+		return nil
+	}
+	args := []string{"--sandbox"}
+	if sandboxImage != "" {
+		args = append(args, "--sandbox-image", sandboxImage)
+	}
+	return args
+}
+
+// executeSandboxReqs replaces the reqs check in sandbox mode: tools run from images, so only
+// Docker is needed on the host, and custom checks from azure.yaml are not run.
+func executeSandboxReqs() error {
+	if err := sandbox.Available(); err != nil {
+		return err
+	}
+	if output.IsJSON() {
+		return output.PrintJSON(map[string]interface{}{
+			"satisfied": true,
+			"sandbox":   true,
+		})
+	}
+	output.ItemSuccess("docker: available (--sandbox runs tools from container images)")
+	output.Newline()
+	return nil
+}
+
+// executeSandboxDeps installs the dependencies of every project in workspace in sandbox
+// containers. Installed packages stay in the sandbox volume, not in the workspace.
+func executeSandboxDeps(workspace string) error {
+	sb, err := sandbox.New(workspace, sandboxImage)
+	if err != nil {
+		return err
+	}
+
+	var results []map[string]interface{}
+	allSuccess := true
+	for _, c := range workspaceCommands(workspace) {
+		if c.Step != "deps" {
+			continue
+		}
+		result := map[string]interface{}{"dir": c.Dir, "command": c.Command, "success": true}
+		if !output.IsJSON() {
+			output.Step("📦", "%s: %s", c.Name, c.Command)
+		}
+		if err := runSandboxed(sb, sandbox.Command{Dir: c.Dir, Script: c.Command}); err != nil {
+			if !output.IsJSON() {
+				output.ItemWarning("Failed to install for %s: %v", c.Dir, err)
+			}
+			result["success"] = false
+			result["error"] = err.Error()
+			allSuccess = false
+		}
+		results = append(results, result)
+	}
+
+	if output.IsJSON() {
+		return output.PrintJSON(map[string]interface{}{
+			"success":  allSuccess,
+			"sandbox":  sb.Volume,
+			"projects": results,
+		})
+	}
+	if len(results) == 0 {
+		output.Info("No projects detected - skipping dependency installation")
+		return nil
+	}
+	output.Newline()
+	if !allSuccess {
+		return fmt.Errorf("dependency installation failed in the sandbox")
+	}
+	output.Success("Dependencies installed in sandbox volume %s", sb.Volume)
+	return nil
+}
+
+// runSandboxed runs c in a container of sb, streaming its output unless JSON output is on.
+func runSandboxed(sb *sandbox.Sandbox, c sandbox.Command) error {
+	cmd, err := sb.Exec(commandContext(), c)
+	if err != nil {
+		return err
+	}
+	if !output.IsJSON() {
+		cmd.Stdout = output.Writer()
+		cmd.Stderr = os.Stderr
+	}
+	return cmd.Run()
+}
+
+// sandboxRuntimes makes runtimes start their service in sandbox containers when --sandbox is set.
+func sandboxRuntimes(runtimes []*service.ServiceRuntime, azureYamlDir string) error {
+	if !sandboxMode {
+		return nil
+	}
+	sb, err := sandbox.New(azureYamlDir, sandboxImage)
+	if err != nil {
+		return err
+	}
+	for _, rt := range runtimes {
+		rt.Sandbox = sb
+	}
+	return nil
+}
+
+// sandboxVerifyCommand returns the container engine command that runs a verify build command in sb.
+func sandboxVerifyCommand(sb *sandbox.Sandbox, c verify.Command) (verify.Command, error) {
+	command := sandbox.Command{Dir: c.Dir, Script: sandbox.Script(c.Name, c.Args)}
+	if err := sb.Seed(command); err != nil {
+		return verify.Command{}, err
+	}
+	args, env, err := sb.DockerArgs(command)
+	if err != nil {
+		return verify.Command{}, err
+	}
+	return verify.Command{Dir: c.Dir, Name: sb.Engine.Name, Args: sb.Engine.Args(args...), Env: env}, nil
+}
@@ -0,0 +1,119 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/jongio/azd-app/cli/src/internal/detector"
+	"github.com/jongio/azd-app/cli/src/internal/output"
+	"github.com/jongio/azd-app/cli/src/internal/scorecard"
+
+	"github.com/spf13/cobra"
+)
+
+var scorecardMinScore int
+
+// NewScorecardCommand creates the scorecard command.
+func NewScorecardCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "scorecard",
+		Short: "Rate the health of the workspace and recommend what to fix first",
+		Long: `Combines the signals of other checks into one health score from 0 to 100, with a grade
+and recommendations ordered by how much they matter:
+
+  validation    azure.yaml against the azd schema
+  lint          azure.yaml against the workspace: projects it misses or points at wrongly,
+                resources of the Aspire AppHost without a service, projects that use Aspire
+                but that no AppHost references, invalid uses, missing reqs
+  reliability   services that failed to start, crashed, or started slowly in the last 7 days
+  dependencies  floating dependency versions and missing lockfiles
+  audit         Lighthouse categories that scored below 90 in the last audit of each service
+
+Each category starts at 100; an error takes 30 points off, a warning 10, and information 2.
+The score is the mean of the categories, weighted 3 for validation and lint, 2 for
+reliability, and 1 for dependencies and audit. Categories without data, such as audit before
+any 'azd app audit web', don't count. Nothing is built or run.
+
+Use --min-score to fail when the score is below a quality bar, e.g. in CI.`,
+		Args: cobra.NoArgs,
+		RunE: runScorecard,
+	}
+
+	cmd.Flags().IntVar(&scorecardMinScore, "min-score", 0, "Minimum score (0-100) the workspace must reach")
+
+	return cmd
+}
+
+// runScorecard executes the scorecard command.
+func runScorecard(cmd *cobra.Command, args []string) error {
+	if scorecardMinScore < 0 || scorecardMinScore > 100 {
+		return fmt.Errorf("--min-score must be between 0 and 100")
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+	workspace := cwd
+	azureYamlPath, err := detector.FindAzureYaml(cwd)
+	if err != nil {
+		return fmt.Errorf("error searching for azure.yaml: %w", err)
+	}
+	if azureYamlPath != "" {
+		workspace = filepath.Dir(azureYamlPath)
+	}
+
+	report := scorecard.Check(workspace, azureYamlPath, time.Now())
+	if output.IsJSON() {
+		if err := output.PrintJSON(report); err != nil {
+			return err
+		}
+	} else {
+		printScorecard(report)
+	}
+	if report.Score < scorecardMinScore {
+		return fmt.Errorf("workspace scored %d, below %d", report.Score, scorecardMinScore)
+	}
+	return nil
+}
+
+// printScorecard prints the score, the score of each category, and the recommendations.
+func printScorecard(report scorecard.Report) {
+	output.Section("🩺", fmt.Sprintf("Workspace health: %s", output.Emphasize("%d/100 (%s)", report.Score, report.Grade)))
+
+	table := output.NewTable("CATEGORY", "SCORE", "FINDINGS")
+	for _, c := range report.Categories {
+		if !c.Checked {
+			table.AddRow(c.Name, "-", output.Muted("not checked: %s", c.Reason))
+			continue
+		}
+		table.AddRow(c.Name, strconv.Itoa(c.Score), strconv.Itoa(c.Findings))
+	}
+	table.Print()
+
+	if len(report.Recommendations) == 0 {
+		output.Newline()
+		output.Success("Nothing to recommend")
+		return
+	}
+	output.Section("💡", "Recommendations")
+	for i, f := range report.Recommendations {
+		line := fmt.Sprintf("%d. %s", i+1, f.Message)
+		if f.Fix != "" {
+			line += " " + output.Muted("→ %s", f.Fix)
+		}
+		switch f.Severity {
+		case scorecard.SeverityError:
+			output.ItemError("%s", line)
+		case scorecard.SeverityWarning:
+			output.ItemWarning("%s", line)
+		default:
+			output.Item("%s", line)
+		}
+		for _, detail := range f.Details {
+			output.Item("     %s", output.Muted("%s", detail))
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package commands
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jongio/azd-app/cli/src/internal/detector"
+	"github.com/jongio/azd-app/cli/src/internal/output"
+	"github.com/jongio/azd-app/cli/src/internal/secretstore"
+
+	"github.com/spf13/cobra"
+)
+
+var secretService string
+
+// NewSecretCommand creates the secret command.
+func NewSecretCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "secret",
+		Short: "Manage secrets that services get as environment variables",
+		Long: `Keeps secrets of the workspace encrypted on this machine, so services get them without the
+secrets being written to .env files. 'azd app run' gives every service the secrets set without
+--service, and each service those set for it, which win.
+
+Secrets are stored in the workspace state directory (see 'azd app cache'), encrypted with a
+key that is generated on first use and only readable by you.`,
+	}
+
+	cmd.PersistentFlags().StringVar(&secretService, "service", "", "Service the secret is for (default: every service)")
+
+	cmd.AddCommand(
+		&cobra.Command{
+			Use:   "set <name> [value]",
+			Short: "Set a secret, reading the value from stdin when it isn't given",
+			Long: `Sets a secret, replacing its value. Without a value, the value is read from the first line of
+stdin, which keeps it out of the shell history:
+
+  azd app secret set STRIPE_KEY < stripe.key`,
+			Args: cobra.RangeArgs(1, 2),
+			RunE: runSecretSet,
+		},
+		&cobra.Command{
+			Use:   "get <name>",
+			Short: "Print the value of a secret",
+			Args:  cobra.ExactArgs(1),
+			RunE:  runSecretGet,
+		},
+		&cobra.Command{
+			Use:   "list",
+			Short: "List the secrets without their values",
+			Args:  cobra.NoArgs,
+			RunE:  runSecretList,
+		},
+		&cobra.Command{
+			Use:   "delete <name>",
+			Short: "Delete a secret",
+			Args:  cobra.ExactArgs(1),
+			RunE:  runSecretDelete,
+		},
+	)
+
+	return cmd
+}
+
+// openSecretStore opens the secret store of the workspace of the nearest azure.yaml, or of the
+// current directory when there is none.
+func openSecretStore() (*secretstore.Store, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current directory: %w", err)
+	}
+	workspace := cwd
+	if azureYamlPath, err := detector.FindAzureYaml(cwd); err == nil && azureYamlPath != "" {
+		workspace = filepath.Dir(azureYamlPath)
+	}
+	return secretstore.Open(workspace)
+}
+
+// runSecretSet executes the secret set command.
+func runSecretSet(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	if err := secretstore.ValidateName(name); err != nil {
+		return err
+	}
+	var value string
+	if len(args) == 2 {
+		value = args[1]
+	} else {
+		line, err := bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+		if err != nil && err != io.EOF {
+			return fmt.Errorf("failed to read the value from stdin: %w", err)
+		}
+		value = strings.TrimRight(line, "\r\n")
+	}
+
+	store, err := openSecretStore()
+	if err != nil {
+		return err
+	}
+	if err := store.Set(secretService, name, value); err != nil {
+		return err
+	}
+	if output.IsJSON() {
+		return output.PrintJSON(secretstore.Secret{Name: name, Service: secretService})
+	}
+	output.Success("Set %s for %s", name, describeSecretScope(secretService))
+	return nil
+}
+
+// runSecretGet executes the secret get command.
+func runSecretGet(cmd *cobra.Command, args []string) error {
+	store, err := openSecretStore()
+	if err != nil {
+		return err
+	}
+	secret, ok, err := store.Get(secretService, args[0])
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("no secret %s for %s", args[0], describeSecretScope(secretService))
+	}
+	if output.IsJSON() {
+		return output.PrintJSON(secret)
+	}
+	// The value is the result, so it's printed even when quiet
+	_, _ = fmt.Fprintln(os.Stdout, secret.Value)
+	return nil
+}
+
+// runSecretList executes the secret list command.
+func runSecretList(cmd *cobra.Command, args []string) error {
+	store, err := openSecretStore()
+	if err != nil {
+		return err
+	}
+	secrets, err := store.List()
+	if err != nil {
+		return err
+	}
+	if secretService != "" {
+		var filtered []secretstore.Secret
+		for _, secret := range secrets {
+			if secret.Service == secretService {
+				filtered = append(filtered, secret)
+			}
+		}
+		secrets = filtered
+	}
+
+	if output.IsJSON() {
+		if secrets == nil {
+			secrets = []secretstore.Secret{}
+		}
+		return output.PrintJSON(secrets)
+	}
+	if len(secrets) == 0 {
+		output.Info("No secrets; add one with 'azd app secret set <name>'")
+		return nil
+	}
+	table := output.NewTable("NAME", "SERVICE", "UPDATED")
+	for _, secret := range secrets {
+		service := secret.Service
+		if service == "" {
+			service = "(all)"
+		}
+		table.AddRow(secret.Name, service, secret.UpdatedAt.Local().Format("2006-01-02 15:04"))
+	}
+	table.Print()
+	return nil
+}
+
+// runSecretDelete executes the secret delete command.
+func runSecretDelete(cmd *cobra.Command, args []string) error {
+	store, err := openSecretStore()
+	if err != nil {
+		return err
+	}
+	deleted, err := store.Delete(secretService, args[0])
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return fmt.Errorf("no secret %s for %s", args[0], describeSecretScope(secretService))
+	}
+	if !output.IsJSON() {
+		output.Success("Deleted %s for %s", args[0], describeSecretScope(secretService))
+	}
+	return nil
+}
+
+// describeSecretScope names the services a secret is for.
+func describeSecretScope(service string) string {
+	if service == "" {
+		return "every service"
+	}
+	return "service " + service
+}
@@ -0,0 +1,134 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jongio/azd-app/cli/src/internal/output"
+	"github.com/jongio/azd-app/cli/src/internal/runhistory"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	statsSince string
+	statsTop   int
+)
+
+// NewStatsCommand creates the stats command.
+func NewStatsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "stats",
+		Short: "Show how your services behaved across past runs",
+		Long: `Summarizes the run history of the workspace: which services crash most, which start
+slowest, and which are restarted most, on a crash or a change, to show where your inner loop
+loses time.
+
+Each 'azd app run' records when its services start, become ready, exit, or fail to start, in
+the workspace state directory (see 'azd app cache'). The history stays on this machine: stats
+reads it locally, and nothing is sent anywhere. The last 200 sessions are kept.`,
+		Args: cobra.NoArgs,
+		RunE: runStats,
+	}
+
+	cmd.Flags().StringVar(&statsSince, "since", "", "Only count sessions started within this duration, e.g. 24h or 7d")
+	cmd.Flags().IntVar(&statsTop, "top", 5, "Services listed in each ranking")
+
+	return cmd
+}
+
+// runStats executes the stats command.
+func runStats(cmd *cobra.Command, args []string) error {
+	if statsTop < 1 {
+		return fmt.Errorf("--top must be at least 1")
+	}
+	var since time.Time
+	if statsSince != "" {
+		duration, err := parseSinceDuration(statsSince)
+		if err != nil {
+			return err
+		}
+		since = time.Now().Add(-duration)
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+	sessions, err := runhistory.Load(cwd, since)
+	if err != nil {
+		return err
+	}
+	report := runhistory.Summarize(sessions)
+
+	if output.IsJSON() {
+		return output.PrintJSON(report)
+	}
+	if report.Sessions == 0 {
+		output.Info("No run history for this workspace")
+		output.Item("History is recorded each time 'azd app run' starts services")
+		return nil
+	}
+	printStats(report)
+	return nil
+}
+
+// parseSinceDuration parses a duration of time.ParseDuration, or a number of days such as 7d.
+func parseSinceDuration(value string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 1 {
+			return 0, fmt.Errorf("invalid --since duration %q: want e.g. 24h or 7d", value)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		return 0, fmt.Errorf("invalid --since duration %q: want e.g. 24h or 7d", value)
+	}
+	return duration, nil
+}
+
+// printStats prints the rankings of report.
+func printStats(report runhistory.Report) {
+	output.Section("📊", fmt.Sprintf("Usage across %d session(s) since %s", report.Sessions, report.From.Local().Format("2006-01-02 15:04")))
+
+	output.Section("💥", "Crashes")
+	if crashed := report.MostCrashes(statsTop); len(crashed) > 0 {
+		table := output.NewTable("SERVICE", "CRASHES", "FAILED STARTS", "LAST CRASH")
+		for _, s := range crashed {
+			last := "-"
+			if s.LastCrash != nil {
+				last = s.LastCrash.Local().Format("2006-01-02 15:04")
+			}
+			table.AddRow(s.Service, strconv.Itoa(s.Crashes), strconv.Itoa(s.Failures), last)
+		}
+		table.Print()
+	} else {
+		output.ItemSuccess("No service crashed or failed to start")
+	}
+
+	if slowest := report.SlowestStartups(statsTop); len(slowest) > 0 {
+		output.Section("🐢", "Slowest startups")
+		table := output.NewTable("SERVICE", "MEAN", "SLOWEST")
+		for _, s := range slowest {
+			table.AddRow(s.Service, s.AvgStartup.Round(100*time.Millisecond).String(), s.MaxStartup.Round(100*time.Millisecond).String())
+		}
+		table.Print()
+	}
+
+	if restarted := report.MostRestarted(statsTop); len(restarted) > 0 {
+		output.Section("♻️", "Most restarted")
+		table := output.NewTable("SERVICE", "RESTARTS", "SESSIONS")
+		for _, s := range restarted {
+			table.AddRow(s.Service, strconv.Itoa(s.Restarts), strconv.Itoa(s.Sessions))
+		}
+		table.Print()
+	}
+
+	output.Newline()
+	output.Info("Read from the run history on this machine; nothing is sent anywhere")
+}
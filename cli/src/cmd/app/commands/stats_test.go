@@ -0,0 +1,26 @@
+package commands
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSinceDuration(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{value: "7d", want: 7 * 24 * time.Hour},
+		{value: "36h", want: 36 * time.Hour},
+		{value: "0d", wantErr: true},
+		{value: "-1h", wantErr: true},
+		{value: "week", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseSinceDuration(tt.value)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseSinceDuration(%q) = %s, %v, want %s, error %v", tt.value, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
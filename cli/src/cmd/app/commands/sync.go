@@ -0,0 +1,120 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/jongio/azd-app/cli/src/internal/azureyaml"
+	"github.com/jongio/azd-app/cli/src/internal/detector"
+	"github.com/jongio/azd-app/cli/src/internal/output"
+	"github.com/jongio/azd-app/cli/src/internal/service"
+
+	"github.com/spf13/cobra"
+)
+
+var syncWrite bool
+
+// NewSyncCommand creates the sync command.
+func NewSyncCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sync",
+		Short: "Reconcile azure.yaml services with the projects in the workspace",
+		Long: `Compares the services in azure.yaml with the projects detected next to it and reports:
+
+  - services whose project path no longer exists
+  - detected projects that no service points at
+  - services whose language is not the detected one
+
+With --write, azure.yaml is updated in place: services with a missing project are removed
+(and dropped from uses), new projects are added with their detected language and a suggested
+host, and mismatched languages are set to the detected one. Comments and formatting of the
+rest of the file are preserved.`,
+		Args: cobra.NoArgs,
+		RunE: runSync,
+	}
+
+	cmd.Flags().BoolVar(&syncWrite, "write", false, "Apply the changes to azure.yaml")
+
+	return cmd
+}
+
+// syncResult is the JSON output of the sync command.
+type syncResult struct {
+	AzureYaml string               `json:"azureYaml"`
+	Changes   []service.SyncChange `json:"changes"`
+	Written   bool                 `json:"written"`
+}
+
+// runSync executes the sync command.
+func runSync(cmd *cobra.Command, args []string) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+	azureYamlPath, err := detector.FindAzureYaml(cwd)
+	if err != nil {
+		return fmt.Errorf("error searching for azure.yaml: %w", err)
+	}
+	if azureYamlPath == "" {
+		return fmt.Errorf("azure.yaml not found - run 'azd app init' to create it")
+	}
+
+	doc, err := azureyaml.Load(azureYamlPath)
+	if err != nil {
+		return err
+	}
+	azureYamlDir := filepath.Dir(azureYamlPath)
+	detected, err := service.DetectServices(azureYamlDir)
+	if err != nil {
+		return fmt.Errorf("failed to detect projects: %w", err)
+	}
+
+	result := syncResult{
+		AzureYaml: azureYamlPath,
+		Changes:   service.DiffProjects(doc.Project, azureYamlDir, detected),
+	}
+	if result.Changes == nil {
+		result.Changes = []service.SyncChange{}
+	}
+	if syncWrite && len(result.Changes) > 0 {
+		service.ApplySync(doc.Project, result.Changes)
+		if err := doc.Save(); err != nil {
+			return err
+		}
+		result.Written = true
+	}
+
+	if output.IsJSON() {
+		return output.PrintJSON(result)
+	}
+	printSyncResult(cwd, result)
+	return nil
+}
+
+// printSyncResult prints the changes and whether they were applied.
+func printSyncResult(cwd string, result syncResult) {
+	output.Section("🔄", fmt.Sprintf("Syncing %s with the workspace", relPath(cwd, result.AzureYaml)))
+	if len(result.Changes) == 0 {
+		output.Success("azure.yaml matches the detected projects")
+		return
+	}
+
+	for _, change := range result.Changes {
+		switch change.Kind {
+		case service.SyncMissingProject:
+			output.ItemError("%s: project %s no longer exists %s", change.Service, change.Project, output.Muted("(remove service)"))
+		case service.SyncNewProject:
+			output.ItemWarning("%s: %s is not in azure.yaml %s", change.Service, change.Project, output.Muted("(add as %s, %s)", change.Detected, change.Host))
+		case service.SyncLanguageMismatch:
+			output.ItemWarning("%s: language is %s, detected %s %s", change.Service, change.Language, change.Detected, output.Muted("(set to %s)", change.Detected))
+		}
+	}
+
+	output.Newline()
+	if result.Written {
+		output.Success("Updated %s with %d change(s)", relPath(cwd, result.AzureYaml), len(result.Changes))
+		return
+	}
+	output.Info("💡 Run 'azd app sync --write' to apply these changes")
+}
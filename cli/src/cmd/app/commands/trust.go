@@ -0,0 +1,329 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jongio/azd-app/cli/src/internal/detector"
+	"github.com/jongio/azd-app/cli/src/internal/guide"
+	"github.com/jongio/azd-app/cli/src/internal/output"
+	"github.com/jongio/azd-app/cli/src/internal/provenance"
+	"github.com/jongio/azd-app/cli/src/internal/service"
+	"github.com/jongio/azd-app/cli/src/internal/trust"
+
+	"github.com/spf13/cobra"
+)
+
+// trustWorkspace is set by the global --trust flag to run without checking workspace trust.
+var trustWorkspace bool
+
+var (
+	trustRevoke bool
+	trustList   bool
+)
+
+// SetTrustWorkspace sets whether commands run without checking workspace trust (--trust).
+func SetTrustWorkspace(trusted bool) {
+	trustWorkspace = trusted
+}
+
+// NewTrustCommand creates the trust command.
+func NewTrustCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "trust",
+		Short: "Trust this workspace to run the commands it defines",
+		Long: `Shows the commands azd app would run for the current workspace and records that you
+trust them: custom reqs checks from azure.yaml, dependency installs (which run package
+scripts), and service start commands.
+
+Before running any of these in a workspace for the first time, reqs, deps, and run ask
+whether you trust it and remember the answer. Use this command to trust a workspace
+ahead of time, --revoke to stop trusting it, and --list to see all decisions. Pass the
+global --trust flag to skip the check in automation.`,
+		Args: cobra.NoArgs,
+		RunE: runTrust,
+	}
+
+	cmd.Flags().BoolVar(&trustRevoke, "revoke", false, "Stop trusting this workspace")
+	cmd.Flags().BoolVar(&trustList, "list", false, "List the trust decisions of all workspaces")
+
+	return cmd
+}
+
+// workspaceCommand is a command that azd app runs on behalf of a workspace.
+type workspaceCommand struct {
+	Step    string `json:"step"` // "reqs", "deps", or "run"
+	Name    string `json:"name"` // Requirement, project, or service the command belongs to
+	Dir     string `json:"dir"`
+	Command string `json:"command"`
+}
+
+// runTrust executes the trust command.
+func runTrust(cmd *cobra.Command, args []string) error {
+	if trustList {
+		decisions, err := trust.List()
+		if err != nil {
+			return err
+		}
+		return printTrustDecisions(decisions)
+	}
+
+	workspace, err := currentWorkspace()
+	if err != nil {
+		return err
+	}
+
+	if trustRevoke {
+		if err := trust.Set(workspace, false); err != nil {
+			return err
+		}
+		if output.IsJSON() {
+			return output.PrintJSON(map[string]interface{}{"path": workspace, "trusted": false})
+		}
+		output.Success("Workspace %s is no longer trusted", workspace)
+		return nil
+	}
+
+	commands := workspaceCommands(workspace)
+	origin := provenance.Check(cmd.Context(), workspace)
+	if err := trust.Set(workspace, true); err != nil {
+		return err
+	}
+	if output.IsJSON() {
+		return output.PrintJSON(map[string]interface{}{"path": workspace, "trusted": true, "commands": commands, "provenance": origin})
+	}
+	output.Prompt(func() {
+		printProvenance(origin)
+		printWorkspaceCommands(workspace, commands)
+	})
+	output.Success("Workspace %s is trusted", workspace)
+	return nil
+}
+
+// executeTrust is the core logic that runs before reqs, deps, and run: it makes sure the
+// workspace is trusted before any of its commands run. With --sandbox, those commands only run
+// in containers that cannot reach the host, so they run without trust. Other commands that run
+// workspace commands on the host check trust with requireWorkspaceTrust, sandbox or not.
+func executeTrust() error {
+	if sandboxMode {
+		return nil
+	}
+	workspace, err := currentWorkspace()
+	if err != nil {
+		return err
+	}
+	return requireWorkspaceTrust(workspace)
+}
+
+// requireWorkspaceTrust returns an error unless workspace is trusted, asking the user when
+// there is no decision yet and a terminal to ask on.
+func requireWorkspaceTrust(workspace string) error {
+	interactive := guide.IsInteractive() && !output.IsJSON()
+	return checkWorkspaceTrust(workspace, guide.NewPrompter(os.Stdin, os.Stderr), interactive)
+}
+
+// checkWorkspaceTrust implements requireWorkspaceTrust. Only a recorded decision counts: state
+// azd app keeps for a workspace, e.g. from a sandboxed run, does not make it trusted.
+func checkWorkspaceTrust(workspace string, prompter *guide.Prompter, interactive bool) error {
+	if trustWorkspace {
+		return nil
+	}
+
+	decision, decided, err := trust.Get(workspace)
+	if err != nil {
+		return fmt.Errorf("failed to read workspace trust: %w", err)
+	}
+	if decided {
+		if decision.Trusted {
+			return nil
+		}
+		return fmt.Errorf("workspace %s is not trusted - run 'azd app trust' to trust it, or pass --trust", workspace)
+	}
+
+	if !interactive {
+		return fmt.Errorf("workspace %s has not been trusted yet - run 'azd app trust' to review and trust it, or pass --trust", workspace)
+	}
+
+	// What the user is asked to approve is shown even with --quiet
+	output.Prompt(func() {
+		printProvenance(provenance.Check(context.Background(), workspace))
+		printWorkspaceCommands(workspace, workspaceCommands(workspace))
+	})
+	// Only an explicit answer is remembered; no answer declines this time only
+	answer := strings.ToLower(prompter.Ask("Do you trust the authors of this workspace and want to run these commands? (y/N)", ""))
+	switch answer {
+	case "y", "yes":
+		if err := trust.Set(workspace, true); err != nil {
+			return err
+		}
+		output.Newline()
+		return nil
+	case "n", "no":
+		if err := trust.Set(workspace, false); err != nil {
+			return err
+		}
+	}
+	return fmt.Errorf("workspace %s is not trusted, nothing was run - run 'azd app trust' if you change your mind", workspace)
+}
+
+// currentWorkspace returns the directory of azure.yaml, or the current directory without one.
+func currentWorkspace() (string, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("failed to get current directory: %w", err)
+	}
+	if azureYamlPath, err := detector.FindAzureYaml(cwd); err == nil && azureYamlPath != "" {
+		return filepath.Dir(azureYamlPath), nil
+	}
+	return cwd, nil
+}
+
+// workspaceCommands lists the commands that reqs, deps, and run would execute for workspace,
+// without running anything. Commands that cannot be determined are left out.
+func workspaceCommands(workspace string) []workspaceCommand {
+	var commands []workspaceCommand
+	azureYamlPath := filepath.Join(workspace, "azure.yaml")
+
+	// Custom reqs checks; built-in tools run fixed version commands
+	if reqs, err := loadReqs(azureYamlPath); err == nil {
+		for _, req := range reqs {
+			if req.Command != "" {
+				commands = append(commands, workspaceCommand{Step: "reqs", Name: req.Name, Dir: workspace, Command: joinCommand(req.Command, req.Args)})
+			}
+			if req.RunningCheckCommand != "" {
+				commands = append(commands, workspaceCommand{Step: "reqs", Name: req.Name, Dir: workspace, Command: joinCommand(req.RunningCheckCommand, req.RunningCheckArgs)})
+			}
+		}
+	}
+
+	if scan, err := detector.ScanWorkspace(workspace); err == nil {
+		nodeDirs := make(map[string]bool, len(scan.NodeProjects))
+		for _, project := range scan.NodeProjects {
+			nodeDirs[project.Dir] = true
+		}
+		for _, project := range scan.NodeProjects {
+			if project.WorkspaceRoot != "" && nodeDirs[project.WorkspaceRoot] {
+				continue
+			}
+			commands = append(commands, workspaceCommand{Step: "deps", Name: filepath.Base(project.Dir), Dir: project.Dir, Command: project.PackageManager + " install"})
+		}
+		for _, project := range scan.PythonProjects {
+			commands = append(commands, workspaceCommand{Step: "deps", Name: filepath.Base(project.Dir), Dir: project.Dir, Command: pythonInstallCommand(project.PackageManager)})
+		}
+		dotnetProjects := scan.DotnetProjects
+		if scoped, err := findSolutionDotnetProjects(scan.Root); err == nil {
+			dotnetProjects = scoped
+		}
+		for _, project := range dotnetProjects {
+			commands = append(commands, workspaceCommand{Step: "deps", Name: filepath.Base(project.Path), Dir: filepath.Dir(project.Path), Command: "dotnet restore " + filepath.Base(project.Path)})
+		}
+	}
+
+	if azureYaml, err := service.ParseAzureYaml(workspace); err == nil {
+		names := make([]string, 0, len(azureYaml.Services))
+		for name := range azureYaml.Services {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			rt, err := service.PreviewServiceRuntime(name, azureYaml.Services[name], workspace, runtimeModeAzd)
+			if err != nil {
+				continue
+			}
+			commands = append(commands, workspaceCommand{Step: "run", Name: name, Dir: rt.WorkingDir, Command: joinCommand(rt.Command, rt.Args)})
+		}
+	}
+
+	return commands
+}
+
+// pythonInstallCommand returns the main command deps runs for a Python package manager.
+func pythonInstallCommand(packageManager string) string {
+	switch packageManager {
+	case "uv":
+		return "uv sync"
+	case "poetry":
+		return "poetry install --no-root"
+	case "pipenv":
+		return "pipenv install --dev"
+	default:
+		return "python -m venv .venv && .venv/bin/pip install -r requirements.txt"
+	}
+}
+
+// joinCommand formats a command and its arguments for display.
+func joinCommand(command string, args []string) string {
+	return strings.TrimSpace(command + " " + strings.Join(args, " "))
+}
+
+// printWorkspaceCommands shows the commands a workspace would run, grouped by step.
+func printWorkspaceCommands(workspace string, commands []workspaceCommand) {
+	output.Section("🛡️", fmt.Sprintf("Workspace trust: %s", workspace))
+	output.Item("azd app runs commands defined by the files in this workspace. Only continue if you")
+	output.Item("trust its authors; dependency installs can also run package scripts.")
+	output.Newline()
+
+	if len(commands) == 0 {
+		output.Item("No commands were detected.")
+		output.Newline()
+		return
+	}
+	step := ""
+	for _, c := range commands {
+		if c.Step != step {
+			step = c.Step
+			output.Step("▶", "%s", step)
+		}
+		output.Item("%s: %s %s", c.Name, c.Command, output.Muted("(in %s)", relPath(workspace, c.Dir)))
+	}
+	output.Newline()
+}
+
+// printProvenance shows whether the source of the workspace is signed, and warns prominently
+// when it is not, since its commands are then only as trustworthy as wherever it came from.
+func printProvenance(origin provenance.Result) {
+	if origin.Verified {
+		source := "commit " + origin.Commit
+		if origin.SignedTag != "" {
+			source = "tag " + origin.SignedTag
+		}
+		if origin.Signer != "" || origin.SignedTag != "" {
+			output.Success("Signed source: %s %s", source, output.Muted("(signed by %s)", origin.Signer))
+		} else {
+			output.Success("Gallery source: %s %s", source, output.Muted("(listed for %s)", origin.Gallery))
+		}
+		output.Newline()
+		return
+	}
+	output.Warning("⚠️  THE SOURCE OF THIS WORKSPACE COULD NOT BE VERIFIED")
+	for _, problem := range origin.Problems {
+		output.ItemWarning("%s", problem)
+	}
+	output.Item("Only trust it if you know where it came from, or review it with --sandbox.")
+	output.Newline()
+}
+
+// printTrustDecisions prints the trust decisions of all workspaces.
+func printTrustDecisions(decisions []trust.Decision) error {
+	if output.IsJSON() {
+		return output.PrintJSON(map[string]interface{}{"workspaces": decisions})
+	}
+	if len(decisions) == 0 {
+		output.Info("No workspace trust decisions recorded")
+		return nil
+	}
+	output.Section("🛡️", "Workspace trust")
+	for _, decision := range decisions {
+		date := decision.DecidedAt.Format("2006-01-02")
+		if decision.Trusted {
+			output.ItemSuccess("%s %s", decision.Path, output.Muted("(trusted %s)", date))
+		} else {
+			output.ItemError("%s %s", decision.Path, output.Muted("(not trusted %s)", date))
+		}
+	}
+	return nil
+}
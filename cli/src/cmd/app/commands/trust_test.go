@@ -0,0 +1,183 @@
+package commands
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jongio/azd-app/cli/src/internal/guide"
+	"github.com/jongio/azd-app/cli/src/internal/statedir"
+	"github.com/jongio/azd-app/cli/src/internal/trust"
+)
+
+func TestCheckWorkspaceTrust(t *testing.T) {
+	tests := []struct {
+		name        string
+		flag        bool
+		sandbox     bool
+		decision    *bool
+		history     bool
+		interactive bool
+		answer      string
+		wantErr     bool
+		wantTrusted *bool // Recorded decision afterwards; nil for none
+	}{
+		{name: "--trust skips the check", flag: true},
+		{name: "--sandbox doesn't skip the check", sandbox: true, wantErr: true},
+		{name: "--sandbox doesn't trust untrusted workspace", sandbox: true, decision: boolPtr(false), wantErr: true, wantTrusted: boolPtr(false)},
+		{name: "trusted workspace", decision: boolPtr(true), wantTrusted: boolPtr(true)},
+		{name: "untrusted workspace", decision: boolPtr(false), interactive: true, answer: "y\n", wantErr: true, wantTrusted: boolPtr(false)},
+		{name: "workspace state does not imply trust", history: true, wantErr: true},
+		{name: "new workspace without terminal", wantErr: true},
+		{name: "user trusts new workspace", interactive: true, answer: "y\n", wantTrusted: boolPtr(true)},
+		{name: "user declines new workspace", interactive: true, answer: "n\n", wantErr: true, wantTrusted: boolPtr(false)},
+		{name: "no answer is not remembered", interactive: true, answer: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(statedir.RootEnvVar, t.TempDir())
+			workspace := t.TempDir()
+			if err := os.WriteFile(filepath.Join(workspace, "azure.yaml"), []byte("name: test\n"), 0600); err != nil {
+				t.Fatal(err)
+			}
+			if tt.decision != nil {
+				if err := trust.Set(workspace, *tt.decision); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if tt.history {
+				if _, err := statedir.CacheDir(workspace); err != nil {
+					t.Fatal(err)
+				}
+			}
+			SetTrustWorkspace(tt.flag)
+			defer SetTrustWorkspace(false)
+			SetSandbox(tt.sandbox, "")
+			defer SetSandbox(false, "")
+
+			prompter := guide.NewPrompter(strings.NewReader(tt.answer), io.Discard)
+			err := checkWorkspaceTrust(workspace, prompter, tt.interactive)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("checkWorkspaceTrust() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			decision, decided, err := trust.Get(workspace)
+			if err != nil {
+				t.Fatal(err)
+			}
+			switch {
+			case tt.wantTrusted == nil && decided:
+				t.Errorf("unexpected decision recorded: %+v", decision)
+			case tt.wantTrusted != nil && (!decided || decision.Trusted != *tt.wantTrusted):
+				t.Errorf("decision = %+v (recorded %v), want trusted=%v", decision, decided, *tt.wantTrusted)
+			}
+		})
+	}
+}
+
+func TestWorkspaceCommands(t *testing.T) {
+	workspace := t.TempDir()
+	files := map[string]string{
+		"azure.yaml": `name: test
+reqs:
+  - name: docker
+    minVersion: "20.0.0"
+    checkRunning: true
+    runningCheckCommand: docker
+    runningCheckArgs: [info]
+  - name: node
+    minVersion: "18.0.0"
+services:
+  web:
+    host: containerapp
+    language: js
+    project: ./web
+`,
+		"web/package.json":   `{"name": "web", "scripts": {"dev": "vite"}}`,
+		"web/pnpm-lock.yaml": "",
+	}
+	for name, content := range files {
+		path := filepath.Join(workspace, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	commands := workspaceCommands(workspace)
+	got := make(map[string]string)
+	for _, c := range commands {
+		got[c.Step+" "+c.Name] = c.Command
+	}
+	if got["reqs docker"] != "docker info" {
+		t.Errorf("reqs docker = %q, want %q", got["reqs docker"], "docker info")
+	}
+	if _, ok := got["reqs node"]; ok {
+		t.Error("built-in reqs checks should not be listed")
+	}
+	if got["deps web"] != "pnpm install" {
+		t.Errorf("deps web = %q, want %q", got["deps web"], "pnpm install")
+	}
+	if _, ok := got["run web"]; !ok {
+		t.Errorf("run command of web missing: %v", got)
+	}
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+func TestExecuteTrustSandbox(t *testing.T) {
+	t.Setenv(statedir.RootEnvVar, t.TempDir())
+	workspace := t.TempDir()
+	if err := os.WriteFile(filepath.Join(workspace, "azure.yaml"), []byte("name: test\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := trust.Set(workspace, false); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(workspace)
+	SetSandbox(true, "")
+	defer SetSandbox(false, "")
+
+	// reqs, deps, and run only run workspace commands in sandbox containers
+	if err := executeTrust(); err != nil {
+		t.Errorf("executeTrust() with --sandbox error = %v", err)
+	}
+}
+
+func TestPipelineSandboxRequiresTrust(t *testing.T) {
+	t.Setenv(statedir.RootEnvVar, t.TempDir())
+	workspace := t.TempDir()
+	files := map[string]string{
+		"azure.yaml": "name: test\n",
+		".azdapp.yaml": `pipelines:
+  dev:
+    stages:
+      hello:
+        commands: [touch ran]
+`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(workspace, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Chdir(workspace)
+	SetSandbox(true, "")
+	defer SetSandbox(false, "")
+
+	// Stages run on the host, so --sandbox doesn't stand in for trust
+	err := runPipeline(NewPipelineCommand(), []string{"dev"})
+	if err == nil || !strings.Contains(err.Error(), "trust") {
+		t.Errorf("runPipeline() error = %v, want the workspace not trusted", err)
+	}
+	if _, err := os.Stat(filepath.Join(workspace, "ran")); err == nil {
+		t.Error("pipeline stage ran in an untrusted workspace")
+	}
+}
@@ -0,0 +1,444 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jongio/azd-app/cli/src/internal/container"
+	"github.com/jongio/azd-app/cli/src/internal/detector"
+	"github.com/jongio/azd-app/cli/src/internal/output"
+	"github.com/jongio/azd-app/cli/src/internal/registry"
+	"github.com/jongio/azd-app/cli/src/internal/sandbox"
+	"github.com/jongio/azd-app/cli/src/internal/service"
+	"github.com/jongio/azd-app/cli/src/internal/smoke"
+	"github.com/jongio/azd-app/cli/src/internal/statedir"
+	"github.com/jongio/azd-app/cli/src/internal/verify"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	verifyClean          bool
+	verifyKeep           bool
+	verifyTimeout        time.Duration
+	verifyInstallTimeout time.Duration
+	verifyBuildTimeout   time.Duration
+)
+
+// NewVerifyCommand creates the verify command.
+func NewVerifyCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "verify",
+		Short: "Check that the app installs, builds, and runs from scratch",
+		Long: `Installs dependencies, builds projects, starts all services, and smoke checks
+frontends, then reports whether each step succeeded. Services that declare a budget
+in azure.yaml are measured against it: the size of the image built from their
+Dockerfile (budget.imageSize) and the time they take to become ready (budget.startup).
+
+With --clean, the workspace is first cloned into a temporary directory (the committed
+state of the git repository, or a copy without installed dependencies and build output)
+and package manager caches point at empty directories. This shows whether the template
+works for a brand-new user. Step logs are written to the workspace state directory
+(see 'azd app cache').`,
+		Args: cobra.NoArgs,
+		RunE: runVerify,
+	}
+
+	cmd.Flags().BoolVar(&verifyClean, "clean", false, "Run in a fresh clone without reusing installed dependencies or caches")
+	cmd.Flags().BoolVar(&verifyKeep, "keep", false, "Keep the temporary clone after verification")
+	cmd.Flags().DurationVar(&verifyTimeout, "timeout", verify.DefaultStartTimeout, "How long to wait for services to become reachable")
+	cmd.Flags().DurationVar(&verifyInstallTimeout, "install-timeout", 0, "Maximum time for installing dependencies (default: no limit)")
+	cmd.Flags().DurationVar(&verifyBuildTimeout, "build-timeout", 0, "Maximum time for building projects (default: no limit)")
+
+	return cmd
+}
+
+// runVerify executes the verify command.
+func runVerify(cmd *cobra.Command, args []string) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+
+	azureYamlPath, err := detector.FindAzureYaml(cwd)
+	if err != nil {
+		return fmt.Errorf("failed to find azure.yaml: %w", err)
+	}
+	if azureYamlPath == "" {
+		return fmt.Errorf("azure.yaml not found in %s or parent directories", cwd)
+	}
+	projectDir := filepath.Dir(azureYamlPath)
+
+	// Trust is checked here once; the clone and the steps below run with --trust. With --sandbox,
+	// every step runs in sandbox containers, as deps and run do, so trust isn't needed
+	if !sandboxMode {
+		if err := requireWorkspaceTrust(projectDir); err != nil {
+			return err
+		}
+	} else {
+		if err := sandbox.Available(); err != nil {
+			return err
+		}
+	}
+
+	self, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate azd app executable: %w", err)
+	}
+
+	logDir, err := statedir.DataDir(projectDir, "verify")
+	if err != nil {
+		return fmt.Errorf("failed to create log directory: %w", err)
+	}
+
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	report := &verify.Report{Source: projectDir, Workspace: projectDir, Clean: verifyClean}
+	var env []string
+
+	if verifyClean {
+		tempDir, err := os.MkdirTemp("", "azd-app-verify-")
+		if err != nil {
+			return fmt.Errorf("failed to create temporary directory: %w", err)
+		}
+		if verifyKeep {
+			defer output.Info("Kept clean workspace at %s", tempDir)
+		} else {
+			defer func() { _ = os.RemoveAll(tempDir) }()
+		}
+
+		step := runVerifyStep(report, "clone", func() (string, error) {
+			workspace, err := verify.CloneWorkspace(ctx, projectDir, filepath.Join(tempDir, "workspace"))
+			if err != nil {
+				return "", err
+			}
+			report.Workspace = workspace
+			return "", nil
+		})
+		if step.Status == verify.StatusFailed {
+			return finishVerify(report)
+		}
+		if sandboxMode && !verifyKeep {
+			// The sandbox volume of the clone is as disposable as the clone
+			if sb, err := sandbox.New(report.Workspace, sandboxImage); err == nil {
+				defer func() { _ = sb.Remove() }()
+			}
+		}
+		env = append(os.Environ(), verify.CacheEnv(filepath.Join(tempDir, "cache"))...)
+	}
+
+	step := runVerifyStep(report, "install", func() (string, error) {
+		logPath := filepath.Join(logDir, "install.log")
+		args := append([]string{"deps", "--trust"}, sandboxArgs()...)
+		installCtx, cancel := withTimeout(ctx, verifyInstallTimeout, "install")
+		defer cancel()
+		return logPath, contextError(installCtx, runLogged(installCtx, verify.Command{Dir: report.Workspace, Name: self, Args: args}, env, logPath))
+	})
+	if step.Status == verify.StatusFailed {
+		return finishVerify(report)
+	}
+
+	step = runVerifyStep(report, "build", func() (string, error) {
+		commands, err := verify.BuildCommands(report.Workspace)
+		if err != nil {
+			return "", err
+		}
+		if len(commands) == 0 {
+			return "", verifySkipped("no build scripts or .NET projects")
+		}
+		var sb *sandbox.Sandbox
+		if sandboxMode {
+			if sb, err = sandbox.New(report.Workspace, sandboxImage); err != nil {
+				return "", err
+			}
+		}
+		logPath := filepath.Join(logDir, "build.log")
+		buildCtx, cancel := withTimeout(ctx, verifyBuildTimeout, "build")
+		defer cancel()
+		for _, c := range commands {
+			run := c
+			if sb != nil {
+				if run, err = sandboxVerifyCommand(sb, c); err != nil {
+					return logPath, err
+				}
+			}
+			if err := runLogged(buildCtx, run, env, logPath); err != nil {
+				return logPath, fmt.Errorf("%s in %s: %w", c, c.Dir, contextError(buildCtx, err))
+			}
+		}
+		return logPath, nil
+	})
+	if step.Status == verify.StatusFailed {
+		return finishVerify(report)
+	}
+
+	runVerifyServices(ctx, report, self, env, logDir)
+	return finishVerify(report)
+}
+
+// verifySkipped is returned by a step that does not apply, with the reason it was skipped.
+type verifySkipped string
+
+func (s verifySkipped) Error() string { return string(s) }
+
+// runVerifyStep runs fn as a named step, records its outcome in the report, and prints it.
+// fn returns the path of the step's log file, if any.
+func runVerifyStep(report *verify.Report, name string, fn func() (string, error)) verify.Step {
+	if !output.IsJSON() {
+		output.Step("⏳", "%s...", name)
+	}
+
+	start := time.Now()
+	detail, err := fn()
+	step := verify.Step{Name: name, Status: verify.StatusPassed, Duration: time.Since(start), Log: detail}
+	var skipped verifySkipped
+	switch {
+	case errors.As(err, &skipped):
+		step.Status = verify.StatusSkipped
+		step.Detail = string(skipped)
+	case err != nil:
+		step.Status = verify.StatusFailed
+		step.Error = err.Error()
+	}
+	report.Steps = append(report.Steps, step)
+
+	if !output.IsJSON() {
+		printVerifyStep(step)
+	}
+	return step
+}
+
+// runVerifyServices starts all services with 'azd app run', waits for them to become
+// reachable, and smoke checks frontends. Services are stopped afterwards.
+func runVerifyServices(ctx context.Context, report *verify.Report, self string, env []string, logDir string) {
+	azureYaml, err := service.ParseAzureYaml(report.Workspace)
+	if err != nil {
+		report.Steps = append(report.Steps, verify.Step{Name: "run", Status: verify.StatusFailed, Error: err.Error()})
+		return
+	}
+	names := make([]string, 0, len(azureYaml.Services))
+	for name := range azureYaml.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	logPath := filepath.Join(logDir, "run.log")
+	// #nosec G304 -- Log path is inside the workspace state directory
+	logFile, err := os.Create(logPath)
+	if err != nil {
+		report.Steps = append(report.Steps, verify.Step{Name: "run", Status: verify.StatusFailed, Error: err.Error()})
+		return
+	}
+	defer logFile.Close()
+
+	// #nosec G204 -- Runs this executable with a fixed subcommand
+	runCmd := exec.Command(self, append([]string{"run", "--trust"}, sandboxArgs()...)...)
+	runCmd.Dir = report.Workspace
+	runCmd.Env = env
+	runCmd.Stdout = logFile
+	runCmd.Stderr = logFile
+
+	var urls map[string]string
+	var entries map[string]*registry.ServiceRegistryEntry
+	exited := make(chan struct{})
+	runVerifyStep(report, "run", func() (string, error) {
+		if err := runCmd.Start(); err != nil {
+			close(exited)
+			return logPath, fmt.Errorf("failed to start services: %w", err)
+		}
+		go func() {
+			_ = runCmd.Wait()
+			close(exited)
+		}()
+
+		var err error
+		entries, err = verify.WaitForServices(ctx, report.Workspace, names, verifyTimeout, exited)
+		if err != nil {
+			return logPath, contextError(ctx, err)
+		}
+		urls = make(map[string]string)
+		for _, name := range names {
+			if smoke.IsFrontend(entries[name].Framework) && entries[name].URL != "" {
+				urls[name] = entries[name].URL
+			}
+		}
+		return logPath, nil
+	})
+	defer stopVerifyServices(runCmd, exited)
+
+	if urls == nil {
+		return
+	}
+
+	runVerifyStep(report, "smoke", func() (string, error) {
+		if len(urls) == 0 {
+			return "", verifySkipped("no frontend services")
+		}
+		chromePath, err := smoke.FindChrome()
+		if err != nil {
+			return "", verifySkipped(err.Error())
+		}
+		outDir := filepath.Join(logDir, "smoke")
+		for _, result := range smoke.CheckAll(ctx, chromePath, urls, outDir) {
+			switch {
+			case result.Error != "":
+				return outDir, fmt.Errorf("%s: %s", result.Service, result.Error)
+			case result.Blank:
+				return outDir, fmt.Errorf("%s: page rendered blank", result.Service)
+			case len(result.ConsoleErrors) > 0:
+				return outDir, fmt.Errorf("%s: console error: %s", result.Service, result.ConsoleErrors[0])
+			}
+		}
+		return outDir, nil
+	})
+
+	runVerifyStep(report, "budget", func() (string, error) {
+		return "", checkVerifyBudgets(ctx, report, entries)
+	})
+}
+
+// checkVerifyBudgets measures the services that declare a budget in azure.yaml and records the
+// results in the report. Returns an error naming the budgets that were exceeded or couldn't be
+// measured.
+func checkVerifyBudgets(ctx context.Context, report *verify.Report, entries map[string]*registry.ServiceRegistryEntry) error {
+	budgets, err := verify.LoadBudgets(report.Workspace)
+	if err != nil {
+		return err
+	}
+	if len(budgets) == 0 {
+		return verifySkipped("no service budgets in azure.yaml")
+	}
+
+	var engine container.Engine
+	for _, budget := range budgets {
+		if budget.Startup > 0 {
+			report.Budgets = append(report.Budgets, verify.CheckStartup(budget, entries[budget.Service]))
+		}
+		if budget.ImageSize > 0 {
+			if engine.Name == "" {
+				if engine, err = container.Current(); err != nil {
+					return err
+				}
+			}
+			report.Budgets = append(report.Budgets, verify.CheckImageSize(ctx, engine, budget))
+		}
+	}
+
+	var failed []string
+	for _, result := range report.Budgets {
+		if !output.IsJSON() {
+			if result.Failed() {
+				output.ItemError("%s", result)
+			} else {
+				output.Item("%s", result)
+			}
+		}
+		if result.Failed() {
+			failed = append(failed, result.String())
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("%s", strings.Join(failed, "; "))
+	}
+	return nil
+}
+
+// stopVerifyServices stops the 'azd app run' process started by verify and waits for it to exit.
+// The process is interrupted so it can stop its services, and killed if it has not exited in time.
+func stopVerifyServices(runCmd *exec.Cmd, exited <-chan struct{}) {
+	select {
+	case <-exited:
+		return
+	default:
+	}
+
+	if runtime.GOOS == "windows" {
+		_ = runCmd.Process.Kill()
+	} else {
+		_ = runCmd.Process.Signal(os.Interrupt)
+	}
+	select {
+	case <-exited:
+	case <-time.After(30 * time.Second):
+		_ = runCmd.Process.Kill()
+		<-exited
+	}
+}
+
+// runLogged runs a command, appending its combined output to logPath.
+func runLogged(ctx context.Context, c verify.Command, env []string, logPath string) error {
+	// #nosec G304 -- Log path is inside the workspace state directory
+	logFile, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	defer logFile.Close()
+	_, _ = fmt.Fprintf(logFile, "$ %s\n", c)
+
+	// #nosec G204 -- Commands are this executable or detected package managers
+	cmd := exec.CommandContext(ctx, c.Name, c.Args...)
+	cmd.Dir = c.Dir
+	cmd.Env = append(slices.Clip(env), c.Env...)
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	return cmd.Run()
+}
+
+// finishVerify prints the report and returns an error if any step failed.
+func finishVerify(report *verify.Report) error {
+	if output.IsJSON() {
+		if err := output.PrintJSON(report); err != nil {
+			return err
+		}
+	} else {
+		output.Newline()
+		if report.Passed() {
+			output.Success("Verification passed")
+		} else {
+			output.Error("Verification failed")
+		}
+	}
+
+	if !report.Passed() {
+		return fmt.Errorf("verification failed")
+	}
+	return nil
+}
+
+// printVerifyStep prints the outcome of a verification step.
+func printVerifyStep(step verify.Step) {
+	duration := step.Duration.Round(100 * time.Millisecond)
+	switch step.Status {
+	case verify.StatusPassed:
+		output.ItemSuccess("%s passed (%s)", step.Name, duration)
+	case verify.StatusSkipped:
+		output.Item("%s skipped: %s", step.Name, step.Detail)
+	default:
+		output.ItemError("%s failed: %s", step.Name, step.Error)
+		if step.Log != "" {
+			output.Item("See %s", step.Log)
+		}
+	}
+}
+
+// sortedKeys returns the keys of a map in sorted order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -13,15 +13,16 @@ var BuildTime = "unknown"
 
 // NewVersionCommand creates the version command.
 func NewVersionCommand() *cobra.Command {
-	return &cobra.Command{
+	return withYAMLOutput(&cobra.Command{
 		Use:   "version",
 		Short: "Show version information",
 		Long:  `Display the version of the azd app extension.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			output.Header("azd app extension")
-			output.Label("Version", Version)
-			output.Label("Built", BuildTime)
-			return nil
+			return output.Print(map[string]string{"version": Version, "buildTime": BuildTime}, func() {
+				output.Header("azd app extension")
+				output.Label("Version", Version)
+				output.Label("Built", BuildTime)
+			})
 		},
-	}
+	})
 }
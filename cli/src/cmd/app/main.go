@@ -3,14 +3,27 @@ package main
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"time"
 
 	"github.com/jongio/azd-app/cli/src/cmd/app/commands"
+	"github.com/jongio/azd-app/cli/src/internal/detector"
 	"github.com/jongio/azd-app/cli/src/internal/output"
 
 	"github.com/spf13/cobra"
 )
 
-var outputFormat string
+var (
+	outputFormat   string
+	quiet          bool
+	noIgnore       bool
+	trustWorkspace bool
+	sandboxMode    bool
+	sandboxImage   string
+	workspace      string
+	appHost        string
+	deadline       time.Duration
+)
 
 func main() {
 	rootCmd := &cobra.Command{
@@ -18,13 +31,65 @@ func main() {
 		Short: "App - Automate your development environment setup",
 		Long:  `App is an Azure Developer CLI extension that automatically detects and sets up your development environment across multiple languages and frameworks.`,
 		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
-			// Set global output format from the flag
-			return output.SetFormat(outputFormat)
+			// Set global output format from the flags first, so everything below prints to the
+			// right stream
+			if err := output.SetFormat(outputFormat); err != nil {
+				return err
+			}
+			if err := commands.CheckOutputFormat(cmd); err != nil {
+				return err
+			}
+			output.SetQuiet(quiet)
+
+			// Honor .gitignore and .azdignore during detection unless disabled
+			detector.SetRespectIgnoreFiles(!noIgnore)
+
+			// Use the selected Aspire AppHost when the workspace has several. The path is relative to
+			// the directory the command was started in, so it is resolved before --workspace
+			if appHost != "" {
+				path, err := filepath.Abs(appHost)
+				if err != nil {
+					return fmt.Errorf("invalid --apphost: %w", err)
+				}
+				detector.SetAppHostPath(path)
+			}
+
+			// Skip the workspace trust prompt, e.g. in automation
+			commands.SetTrustWorkspace(trustWorkspace)
+
+			// Run install, build, and run commands in containers, e.g. to review untrusted templates
+			commands.SetSandbox(sandboxMode || sandboxImage != "", sandboxImage)
+
+			// Run in the selected azure.yaml workspace of a monorepo, as if started there
+			if workspace != "" {
+				if err := enterWorkspace(workspace); err != nil {
+					return err
+				}
+			}
+
+			// Retry requests to remote services as azure.yaml configures
+			if err := commands.ConfigureNetworkRetry(); err != nil {
+				return err
+			}
+
+			// Bound the whole invocation, e.g. so CI jobs never hang
+			if deadline > 0 {
+				cmd.SetContext(commands.SetDeadline(cmd.Context(), deadline))
+			}
+			return nil
 		},
 	}
 
 	// Add global flags
-	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", "default", "Output format (default, json)")
+	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", "table", "Output format (table, json, yaml)")
+	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "Print only warnings, errors, and machine-readable output")
+	rootCmd.PersistentFlags().BoolVar(&noIgnore, "no-ignore", false, "Don't skip paths listed in .gitignore or .azdignore during project detection")
+	rootCmd.PersistentFlags().BoolVar(&trustWorkspace, "trust", false, "Run commands defined by the workspace without asking whether it is trusted")
+	rootCmd.PersistentFlags().BoolVar(&sandboxMode, "sandbox", false, "Run install, build, and run commands in disposable Docker containers with the workspace mounted read-only")
+	rootCmd.PersistentFlags().StringVar(&sandboxImage, "sandbox-image", "", "Image for all sandboxed commands (implies --sandbox; default: chosen per language)")
+	rootCmd.PersistentFlags().StringVar(&appHost, "apphost", "", "Aspire AppHost to use when the workspace has several: its project directory or project file")
+	rootCmd.PersistentFlags().DurationVar(&deadline, "deadline", 0, "Maximum time for the whole command, e.g. 30m; services and processes it started are stopped when exceeded")
+	rootCmd.PersistentFlags().StringVarP(&workspace, "workspace", "w", "", "azure.yaml workspace to use: a directory, or a workspace name in the repository (default: nearest azure.yaml)")
 
 	// Register all commands
 	rootCmd.AddCommand(
@@ -32,13 +97,68 @@ func main() {
 		commands.NewRunCommand(),
 		commands.NewDepsCommand(),
 		commands.NewLogsCommand(),
+		commands.NewStatusCommand(),
+		commands.NewStopCommand(),
 		commands.NewInfoCommand(),
 		commands.NewVersionCommand(),
+		commands.NewReportIssueCommand(),
+		commands.NewAuditCommand(),
+		commands.NewLoadtestCommand(),
+		commands.NewDataCommand(),
+		commands.NewEnvCommand(),
+		commands.NewPinCommand(),
+		commands.NewVerifyCommand(),
+		commands.NewConsoleCommand(),
+		commands.NewCacheCommand(),
+		commands.NewImportCommand(),
+		commands.NewGraphCommand(),
+		commands.NewInitCommand(),
+		commands.NewSyncCommand(),
+		commands.NewInstallServiceCommand(),
+		commands.NewUninstallServiceCommand(),
+		commands.NewServiceStatusCommand(),
+		commands.NewTrustCommand(),
+		commands.NewPrebuildCommand(),
+		commands.NewBuildCommand(),
+		commands.NewImagesCommand(),
+		commands.NewSecretCommand(),
+		commands.NewPipelineCommand(),
+		commands.NewStatsCommand(),
+		commands.NewAspireCommand(),
+		commands.NewScorecardCommand(),
+		commands.NewDoctorCommand(),
+		commands.NewDetectCommand(),
 		commands.NewListenCommand(), // Required for azd extension framework
 	)
 
+	rootCmd.SetHelpCommand(commands.NewHelpCommand())
+
+	// Expand user-defined aliases, e.g. "up" = "run --service web,api --smoke"
+	args, err := commands.ApplyAliases(rootCmd, os.Args[1:])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		args = os.Args[1:]
+	}
+	rootCmd.SetArgs(args)
+
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+		os.Exit(commands.ExitCode(err))
+	}
+}
+
+// enterWorkspace changes to the directory of the azure.yaml workspace selected by --workspace.
+func enterWorkspace(workspace string) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+	azureYamlPath, err := detector.ResolveWorkspace(cwd, workspace)
+	if err != nil {
+		return err
+	}
+	if err := os.Chdir(filepath.Dir(azureYamlPath)); err != nil {
+		return fmt.Errorf("failed to enter workspace %s: %w", workspace, err)
 	}
+	return nil
 }
@@ -0,0 +1,275 @@
+// Package alias loads user-defined command shortcuts and expands them into full commands.
+package alias
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/jongio/azd-app/cli/src/internal/security"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigEnvVar overrides the location of the user config file.
+const ConfigEnvVar = "AZD_APP_CONFIG"
+
+// projectConfigFileName is the project-level config file, shared with detector settings.
+const projectConfigFileName = ".azdapp.yaml"
+
+// maxDepth limits how many aliases can expand into each other.
+const maxDepth = 10
+
+// projectDeniedFlags are the global flags that aliases of a project can't set, with their
+// shorthands: a cloned workspace could otherwise skip the trust prompt, detection rules, or
+// sandbox of whoever runs its alias, or point it at another workspace or AppHost.
+var projectDeniedFlags = []struct{ name, shorthand string }{
+	{"--trust", ""},
+	{"--no-ignore", ""},
+	{"--sandbox", ""},
+	{"--sandbox-image", ""},
+	{"--workspace", "w"},
+	{"--apphost", ""},
+}
+
+// namePattern restricts alias names to simple command-like words.
+var namePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]*$`)
+
+// config is the part of a config file that defines aliases.
+type config struct {
+	Aliases map[string]string `yaml:"aliases"`
+}
+
+// UserConfigPath returns the path of the user config file:
+// $AZD_APP_CONFIG if set, otherwise azd-app/config.yaml in the user config directory.
+func UserConfigPath() (string, error) {
+	if path := os.Getenv(ConfigEnvVar); path != "" {
+		return path, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate user config directory: %w", err)
+	}
+	return filepath.Join(dir, "azd-app", "config.yaml"), nil
+}
+
+// Load returns the aliases defined in the user config file and in .azdapp.yaml in projectDir.
+// User aliases take precedence over project aliases of the same name, and project aliases
+// can't set the flags of projectDeniedFlags, themselves or through the aliases they expand
+// into. Missing files are ignored; an empty projectDir skips the project config.
+func Load(projectDir string) (map[string]string, error) {
+	userPath, err := UserConfigPath()
+	if err != nil {
+		return nil, err
+	}
+	aliases, err := loadAliases(userPath)
+	if err != nil {
+		return nil, err
+	}
+	if projectDir == "" {
+		return aliases, nil
+	}
+
+	projectPath := filepath.Join(projectDir, projectConfigFileName)
+	project, err := loadAliases(projectPath)
+	if err != nil {
+		return nil, err
+	}
+	var added []string
+	for name, command := range project {
+		if _, ok := aliases[name]; !ok {
+			aliases[name] = command
+			added = append(added, name)
+		}
+	}
+	// A project alias may also refer to a user alias that sets the flags
+	sort.Strings(added)
+	for _, name := range added {
+		expanded, err := Expand([]string{name}, aliases, nil, func(string) bool { return false })
+		if err != nil {
+			// Expand reports the alias when it is used
+			continue
+		}
+		if flag := deniedFlag(expanded); flag != "" {
+			return nil, fmt.Errorf("alias %q in %s sets %s, which only the user config or the command line can set", name, projectPath, flag)
+		}
+	}
+	return aliases, nil
+}
+
+// loadAliases returns the aliases of the config file at path.
+func loadAliases(path string) (map[string]string, error) {
+	cfg, err := readConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	aliases := make(map[string]string, len(cfg.Aliases))
+	for name, command := range cfg.Aliases {
+		if !namePattern.MatchString(name) {
+			return nil, fmt.Errorf("invalid alias name %q in %s", name, path)
+		}
+		aliases[name] = command
+	}
+	return aliases, nil
+}
+
+// deniedFlag returns the first flag of projectDeniedFlags in words, or "".
+// A shorthand is found anywhere in a group of shorthands, such as -qw, -wdir, or -w=dir.
+func deniedFlag(words []string) string {
+	for _, word := range words {
+		shorthands := ""
+		if len(word) > 1 && word[0] == '-' && word[1] != '-' {
+			shorthands, _, _ = strings.Cut(word[1:], "=")
+		}
+		for _, flag := range projectDeniedFlags {
+			if word == flag.name || strings.HasPrefix(word, flag.name+"=") {
+				return flag.name
+			}
+			if flag.shorthand != "" && strings.Contains(shorthands, flag.shorthand) {
+				return "-" + flag.shorthand
+			}
+		}
+	}
+	return ""
+}
+
+// Expand replaces the command name in args with its alias definition, repeatedly, so aliases
+// may refer to other aliases. Leading flags (e.g. --output json) are kept in place;
+// flagsWithValue lists the leading flags that consume the following argument.
+// Names for which isBuiltin returns true are never expanded, so aliases cannot shadow commands.
+func Expand(args []string, aliases map[string]string, flagsWithValue map[string]bool, isBuiltin func(string) bool) ([]string, error) {
+	index := commandIndex(args, flagsWithValue)
+	if index < 0 {
+		return args, nil
+	}
+	// While completing the command name itself, leave it for the shell to complete
+	if isCompletion(args) && index == len(args)-1 {
+		return args, nil
+	}
+
+	expanded := args
+	seen := make(map[string]bool)
+	for depth := 0; ; depth++ {
+		name := expanded[index]
+		definition, ok := aliases[name]
+		if !ok || isBuiltin(name) {
+			return expanded, nil
+		}
+		if seen[name] || depth >= maxDepth {
+			return nil, fmt.Errorf("alias %q expands into itself", name)
+		}
+		seen[name] = true
+
+		words, err := Split(definition)
+		if err != nil {
+			return nil, fmt.Errorf("invalid alias %q: %w", name, err)
+		}
+		if len(words) == 0 {
+			return nil, fmt.Errorf("alias %q is empty", name)
+		}
+
+		next := make([]string, 0, len(expanded)+len(words))
+		next = append(next, expanded[:index]...)
+		next = append(next, words...)
+		next = append(next, expanded[index+1:]...)
+		expanded = next
+	}
+}
+
+// Split splits a command line into words. Words are separated by whitespace; single or
+// double quotes group words, and a backslash escapes the next character outside single quotes.
+func Split(s string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	inWord := false
+	var quote rune
+
+	runes := []rune(s)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else if r == '\\' && quote == '"' && i+1 < len(runes) {
+				i++
+				word.WriteRune(runes[i])
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case r == '\\' && i+1 < len(runes):
+			i++
+			word.WriteRune(runes[i])
+			inWord = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated quote in %q", s)
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
+
+// commandIndex returns the index of the first argument that names a command, skipping
+// leading flags and the values of flagsWithValue, or -1 if there is none.
+// Shell completion requests (__complete) are skipped so aliases complete like their commands.
+func commandIndex(args []string, flagsWithValue map[string]bool) int {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--":
+			return -1
+		case i == 0 && isCompletion(args):
+			continue
+		case strings.HasPrefix(arg, "-"):
+			if flagsWithValue[arg] {
+				i++
+			}
+		default:
+			return i
+		}
+	}
+	return -1
+}
+
+// isCompletion reports whether args are a shell completion request from cobra.
+func isCompletion(args []string) bool {
+	return len(args) > 0 && (args[0] == "__complete" || args[0] == "__completeNoDesc")
+}
+
+// readConfig reads the aliases section of a config file. A missing file yields an empty config.
+func readConfig(path string) (*config, error) {
+	cfg := &config{}
+	if err := security.ValidatePath(path); err != nil {
+		return nil, fmt.Errorf("invalid config path: %w", err)
+	}
+	// #nosec G304 -- Path validated by security.ValidatePath
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return cfg, nil
+}
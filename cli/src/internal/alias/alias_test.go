@@ -0,0 +1,164 @@
+package alias
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{"run --service web,api", []string{"run", "--service", "web,api"}},
+		{"  run   --smoke ", []string{"run", "--smoke"}},
+		{`logs --service "my api"`, []string{"logs", "--service", "my api"}},
+		{`run --env-file 'dev env/.env'`, []string{"run", "--env-file", "dev env/.env"}},
+		{`echo "say \"hi\""`, []string{"echo", `say "hi"`}},
+		{`a\ b c`, []string{"a b", "c"}},
+		{`run ""`, []string{"run", ""}},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		got, err := Split(tt.input)
+		if err != nil {
+			t.Errorf("Split(%q) error: %v", tt.input, err)
+			continue
+		}
+		if strings.Join(got, "|") != strings.Join(tt.want, "|") || len(got) != len(tt.want) {
+			t.Errorf("Split(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+
+	if _, err := Split(`run "unterminated`); err == nil {
+		t.Error("expected error for unterminated quote")
+	}
+}
+
+func TestExpand(t *testing.T) {
+	aliases := map[string]string{
+		"up":    "run --service web,api --smoke",
+		"dev":   "up --verbose",
+		"run":   "version",
+		"loop":  "loop2",
+		"loop2": "loop",
+	}
+	builtins := map[string]bool{"run": true, "version": true}
+	isBuiltin := func(name string) bool { return builtins[name] }
+	flagsWithValue := map[string]bool{"-o": true, "--output": true}
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    []string
+		wantErr bool
+	}{
+		{"alias", []string{"up"}, []string{"run", "--service", "web,api", "--smoke"}, false},
+		{"extra args appended", []string{"up", "--dry-run"}, []string{"run", "--service", "web,api", "--smoke", "--dry-run"}, false},
+		{"nested alias", []string{"dev"}, []string{"run", "--service", "web,api", "--smoke", "--verbose"}, false},
+		{"leading global flag", []string{"-o", "json", "up"}, []string{"-o", "json", "run", "--service", "web,api", "--smoke"}, false},
+		{"builtin not shadowed", []string{"run"}, []string{"run"}, false},
+		{"not an alias", []string{"deps"}, []string{"deps"}, false},
+		{"no command", []string{"--help"}, []string{"--help"}, false},
+		{"completion of arguments", []string{"__complete", "up", ""}, []string{"__complete", "run", "--service", "web,api", "--smoke", ""}, false},
+		{"completion of command name", []string{"__complete", "up"}, []string{"__complete", "up"}, false},
+		{"cycle", []string{"loop"}, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Expand(tt.args, aliases, flagsWithValue, isBuiltin)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Expand error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("Expand(%q) = %q, want %q", tt.args, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	userConfig := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(userConfig, []byte("aliases:\n  up: run --smoke\n  l: logs --follow\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	projectDir := filepath.Join(dir, "project")
+	if err := os.MkdirAll(projectDir, 0750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(projectDir, ".azdapp.yaml"), []byte("detector:\n  ignore: [samples]\naliases:\n  up: run --service web\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(ConfigEnvVar, userConfig)
+
+	aliases, err := Load(projectDir)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if aliases["up"] != "run --smoke" {
+		t.Errorf("user alias should take precedence over project alias, got %q", aliases["up"])
+	}
+	if aliases["l"] != "logs --follow" {
+		t.Errorf("expected user alias, got %q", aliases["l"])
+	}
+
+	// Project aliases can't skip the trust prompt, ignore rules, or sandbox, or change the target
+	for _, definition := range []string{
+		"run --trust",
+		"'deps' --no-ignore=true",
+		"run --sandbox=false",
+		"deps --sandbox-image evil",
+		"run --workspace ../other",
+		"run -w=../other",
+		"-qw ../other run",
+		"run --apphost ../AppHost",
+	} {
+		if err := os.WriteFile(filepath.Join(projectDir, ".azdapp.yaml"), []byte("aliases:\n  start: "+definition+"\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Load(projectDir); err == nil {
+			t.Errorf("expected error for project alias %q", definition)
+		}
+	}
+	// Other flags, and flags that only start like denied ones, are allowed
+	if err := os.WriteFile(filepath.Join(projectDir, ".azdapp.yaml"), []byte("aliases:\n  start: run -q --service web --watch\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(projectDir); err != nil {
+		t.Errorf("Load() error = %v, want the project alias allowed", err)
+	}
+	// Users may set them in their own aliases
+	if err := os.WriteFile(userConfig, []byte("aliases:\n  start: run --trust\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if aliases, err := Load(""); err != nil || aliases["start"] != "run --trust" {
+		t.Errorf("Load() = %v, %v; want the user alias", aliases, err)
+	}
+	// but project aliases can't set them through a user alias
+	if err := os.WriteFile(filepath.Join(projectDir, ".azdapp.yaml"), []byte("aliases:\n  dev: start --service web\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(projectDir); err == nil || !strings.Contains(err.Error(), "--trust") {
+		t.Errorf("Load() error = %v, want the project alias rejected for --trust", err)
+	}
+
+	// Missing files yield no aliases
+	t.Setenv(ConfigEnvVar, filepath.Join(dir, "missing.yaml"))
+	aliases, err = Load("")
+	if err != nil || len(aliases) != 0 {
+		t.Errorf("Load with missing config = %v, %v; want empty", aliases, err)
+	}
+
+	// Invalid names are rejected
+	if err := os.WriteFile(userConfig, []byte("aliases:\n  \"bad name\": run\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(ConfigEnvVar, userConfig)
+	if _, err := Load(""); err == nil {
+		t.Error("expected error for invalid alias name")
+	}
+}
@@ -0,0 +1,147 @@
+package aspire
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"time"
+
+	"github.com/jongio/azd-app/cli/src/internal/detector"
+	"github.com/jongio/azd-app/cli/src/internal/httpclient"
+)
+
+// dashboardLoginPattern matches the line an AppHost logs once its dashboard is up, e.g.
+// "Login to the dashboard at https://localhost:17001/login?t=0123abcd".
+var dashboardLoginPattern = regexp.MustCompile(`(?i)login to the dashboard at (https?://\S+)`)
+
+// Environment variables of a launch profile that set the endpoints of the dashboard; Aspire 9
+// renamed the DOTNET_ ones.
+var (
+	otlpEndpointVars            = []string{"ASPIRE_DASHBOARD_OTLP_ENDPOINT_URL", "DOTNET_DASHBOARD_OTLP_ENDPOINT_URL"}
+	resourceServiceEndpointVars = []string{"ASPIRE_RESOURCE_SERVICE_ENDPOINT_URL", "DOTNET_RESOURCE_SERVICE_ENDPOINT_URL"}
+)
+
+// Endpoints are where a running AppHost serves its dashboard and receives telemetry.
+type Endpoints struct {
+	Dashboard       string `json:"dashboard,omitempty"`       // URL of the dashboard, with its login token once logged
+	OTLP            string `json:"otlp,omitempty"`            // URL the dashboard receives OTLP telemetry on
+	ResourceService string `json:"resourceService,omitempty"` // URL of the resource service the dashboard reads resources from
+}
+
+// LaunchEndpoints returns the endpoints of the AppHost in appHostDir from its launch profile
+// named profile, or else from the one dotnet run uses by default: the first profile of
+// Properties/launchSettings.json that runs the project. The dashboard is at the first URL of
+// the profile; the endpoints are empty without one.
+func LaunchEndpoints(appHostDir, profile string) (Endpoints, error) {
+	profiles, err := detector.ParseLaunchSettings(appHostDir)
+	if err != nil {
+		return Endpoints{}, err
+	}
+	launch := detector.DefaultLaunchProfile(profiles)
+	if profile != "" {
+		if launch, err = detector.FindLaunchProfile(profiles, profile); err != nil {
+			return Endpoints{}, err
+		}
+	}
+	var endpoints Endpoints
+	if launch == nil {
+		return endpoints, nil
+	}
+	if len(launch.ApplicationURLs) > 0 {
+		endpoints.Dashboard = launch.ApplicationURLs[0]
+	}
+	endpoints.OTLP = firstVar(launch.Environment, otlpEndpointVars)
+	endpoints.ResourceService = firstVar(launch.Environment, resourceServiceEndpointVars)
+	return endpoints, nil
+}
+
+// firstVar returns the value of the first of names that vars sets.
+func firstVar(vars map[string]string, names []string) string {
+	for _, name := range names {
+		if value := vars[name]; value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// DashboardURL returns the login URL of the dashboard from a line of the output of a running
+// AppHost, or false when the line isn't the one that logs it.
+func DashboardURL(line string) (string, bool) {
+	m := dashboardLoginPattern.FindStringSubmatch(line)
+	if m == nil {
+		return "", false
+	}
+	return m[1], true
+}
+
+// Status is whether the endpoints of a running AppHost respond.
+type Status struct {
+	Dashboard bool `json:"dashboard"`
+	OTLP      bool `json:"otlp"`
+}
+
+// probeTimeout bounds each probe of an endpoint.
+const probeTimeout = 2 * time.Second
+
+// probeClient is shared by all probes so that their connections are reused rather than
+// left open by a transport per probe.
+var probeClient = func() *http.Client {
+	client := httpclient.NewLocal(0)
+	client.Transport = &http.Transport{
+		// #nosec G402 -- The dashboard of a local AppHost serves the ASP.NET Core development certificate
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+	}
+	return client
+}()
+
+// Probe reports whether the dashboard answers HTTP requests and the OTLP endpoint accepts
+// connections. Endpoints that aren't known are reported as not responding.
+func Probe(ctx context.Context, endpoints Endpoints) Status {
+	return Status{
+		Dashboard: endpoints.Dashboard != "" && probeHTTP(ctx, endpoints.Dashboard),
+		OTLP:      endpoints.OTLP != "" && probeTCP(ctx, endpoints.OTLP),
+	}
+}
+
+// probeHTTP reports whether rawURL answers with any HTTP response.
+func probeHTTP(ctx context.Context, rawURL string) bool {
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return false
+	}
+	resp, err := probeClient.Do(req)
+	if err != nil {
+		return false
+	}
+	_ = resp.Body.Close()
+	return true
+}
+
+// probeTCP reports whether the host and port of rawURL accept connections.
+func probeTCP(ctx context.Context, rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	host := u.Host
+	if u.Port() == "" {
+		port := "80"
+		if u.Scheme == "https" {
+			port = "443"
+		}
+		host = net.JoinHostPort(u.Hostname(), port)
+	}
+	dialer := net.Dialer{Timeout: probeTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", host)
+	if err != nil {
+		return false
+	}
+	_ = conn.Close()
+	return true
+}
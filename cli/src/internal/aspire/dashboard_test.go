@@ -0,0 +1,81 @@
+package aspire
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLaunchEndpoints(t *testing.T) {
+	dir := t.TempDir()
+	if endpoints, err := LaunchEndpoints(dir, ""); err != nil || endpoints != (Endpoints{}) {
+		t.Errorf("LaunchEndpoints() without launch settings = %+v, %v", endpoints, err)
+	}
+
+	settings := `{
+  "profiles": {
+    "docker": {"commandName": "Docker"},
+    "https": {
+      "commandName": "Project",
+      "applicationUrl": "https://localhost:17001;http://localhost:15001",
+      "environmentVariables": {
+        "DOTNET_DASHBOARD_OTLP_ENDPOINT_URL": "https://localhost:21001",
+        "ASPIRE_RESOURCE_SERVICE_ENDPOINT_URL": "https://localhost:22001"
+      }
+    },
+    "http": {"commandName": "Project", "applicationUrl": "http://localhost:15001"}
+  }
+}`
+	if err := os.MkdirAll(filepath.Join(dir, "Properties"), 0750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "Properties", "launchSettings.json"), []byte(settings), 0600); err != nil {
+		t.Fatal(err)
+	}
+	endpoints, err := LaunchEndpoints(dir, "")
+	if err != nil {
+		t.Fatalf("LaunchEndpoints() error = %v", err)
+	}
+	want := Endpoints{Dashboard: "https://localhost:17001", OTLP: "https://localhost:21001", ResourceService: "https://localhost:22001"}
+	if endpoints != want {
+		t.Errorf("LaunchEndpoints() = %+v, want %+v", endpoints, want)
+	}
+
+	if endpoints, err := LaunchEndpoints(dir, "http"); err != nil || endpoints != (Endpoints{Dashboard: "http://localhost:15001"}) {
+		t.Errorf("LaunchEndpoints(http) = %+v, %v", endpoints, err)
+	}
+	if _, err := LaunchEndpoints(dir, "docker"); err == nil {
+		t.Error("LaunchEndpoints(docker) succeeded with a profile dotnet run can't use")
+	}
+}
+
+func TestDashboardURL(t *testing.T) {
+	line := "info: Aspire.Hosting.DistributedApplication[0]\n      Login to the dashboard at https://localhost:17001/login?t=0123abcd"
+	if url, ok := DashboardURL(line); !ok || url != "https://localhost:17001/login?t=0123abcd" {
+		t.Errorf("DashboardURL() = %q, %v", url, ok)
+	}
+	if _, ok := DashboardURL("Now listening on: https://localhost:17001"); ok {
+		t.Error("DashboardURL() matched a line without the login URL")
+	}
+}
+
+func TestProbe(t *testing.T) {
+	dashboard := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/login", http.StatusFound)
+	}))
+	defer dashboard.Close()
+	otlp := httptest.NewServer(http.NotFoundHandler())
+	otlpURL := otlp.URL
+	otlp.Close()
+
+	status := Probe(context.Background(), Endpoints{Dashboard: dashboard.URL, OTLP: otlpURL})
+	if !status.Dashboard || status.OTLP {
+		t.Errorf("Probe() = %+v, want the dashboard up and OTLP down", status)
+	}
+	if status := Probe(context.Background(), Endpoints{}); status.Dashboard || status.OTLP {
+		t.Errorf("Probe() without endpoints = %+v", status)
+	}
+}
@@ -0,0 +1,68 @@
+package aspire
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/jongio/azd-app/cli/src/internal/statedir"
+	"github.com/jongio/azd-app/cli/src/internal/types"
+)
+
+// manifestFile is the name of the manifest in the cache directory of the workspace.
+const manifestFile = "aspire-manifest.json"
+
+// LoadOptions controls how Load extracts the model.
+type LoadOptions struct {
+	FromSource bool // Parse the AppHost source without running it
+}
+
+// Load returns the model of the AppHost of the workspace at projectDir: from the manifest the
+// AppHost publishes, written to the cache directory of the workspace, or from its source with
+// opts.FromSource, or when the manifest can't be published, e.g. without the .NET SDK, with a
+// warning saying why.
+func Load(ctx context.Context, projectDir string, appHost types.AspireProject, opts LoadOptions) (*Model, error) {
+	if opts.FromSource {
+		return ParseSource(appHost)
+	}
+
+	model, err := loadManifest(ctx, projectDir, appHost)
+	if err == nil {
+		return model, nil
+	}
+	model, sourceErr := ParseSource(appHost)
+	if sourceErr != nil {
+		return nil, fmt.Errorf("%w; reading the AppHost source also failed: %v", err, sourceErr)
+	}
+	model.Warnings = append(model.Warnings, fmt.Sprintf("%v; read the model from the AppHost source instead, which misses resources it doesn't state literally", err))
+	return model, nil
+}
+
+// loadManifest publishes the manifest of appHost and returns its model.
+func loadManifest(ctx context.Context, projectDir string, appHost types.AspireProject) (*Model, error) {
+	if _, err := exec.LookPath("dotnet"); err != nil {
+		return nil, fmt.Errorf("the .NET SDK is not installed, so the AppHost can't publish its manifest")
+	}
+	dir, err := statedir.CacheDir(projectDir)
+	if err != nil {
+		return nil, err
+	}
+	path := filepath.Join(dir, manifestFile)
+	_ = os.Remove(path) // So a manifest of an earlier run isn't mistaken for this one
+	if err := GenerateManifest(ctx, appHost, path); err != nil {
+		return nil, err
+	}
+	// #nosec G304 -- Path is in the cache directory of the workspace
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the Aspire manifest: %w", err)
+	}
+	model, err := ParseManifest(data, dir)
+	if err != nil {
+		return nil, err
+	}
+	model.AppHost = appHost.ProjectFile
+	return model, nil
+}
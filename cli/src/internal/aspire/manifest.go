@@ -0,0 +1,207 @@
+package aspire
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/jongio/azd-app/cli/src/internal/types"
+)
+
+// expressionPattern captures the resource an expression of the manifest refers to, e.g. api in
+// {api.bindings.http.url} or db in {db.connectionString}.
+var expressionPattern = regexp.MustCompile(`\{([\w-]+)\.(?:bindings|connectionString|inputs|value|outputs|secretOutputs)\b`)
+
+// manifest is the manifest an AppHost publishes with --publisher manifest.
+type manifest struct {
+	Resources map[string]manifestResource `json:"resources"`
+}
+
+type manifestResource struct {
+	Type             string                     `json:"type"`
+	Path             string                     `json:"path"`    // Project file, or the Dockerfile of dockerfile.v0
+	Context          string                     `json:"context"` // Of dockerfile.v0
+	Image            string                     `json:"image"`
+	Build            *manifestBuild             `json:"build"` // Of container.v1
+	Entrypoint       string                     `json:"entrypoint"`
+	Command          string                     `json:"command"`
+	WorkingDirectory string                     `json:"workingDirectory"`
+	Args             []string                   `json:"args"`
+	Env              map[string]string          `json:"env"`
+	Bindings         map[string]manifestBinding `json:"bindings"`
+	ConnectionString string                     `json:"connectionString"`
+	Value            string                     `json:"value"`
+	Parent           string                     `json:"parent"`
+	Inputs           map[string]struct {
+		Secret bool `json:"secret"`
+	} `json:"inputs"`
+}
+
+type manifestBuild struct {
+	Context    string `json:"context"`
+	Dockerfile string `json:"dockerfile"`
+}
+
+type manifestBinding struct {
+	Scheme     string `json:"scheme"`
+	Protocol   string `json:"protocol"`
+	Transport  string `json:"transport"`
+	Port       int    `json:"port"`
+	TargetPort int    `json:"targetPort"`
+	External   bool   `json:"external"`
+}
+
+// ParseManifest returns the model of an Aspire manifest. Paths of the manifest are relative to
+// baseDir, the directory it was written to.
+func ParseManifest(data []byte, baseDir string) (*Model, error) {
+	var doc manifest
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid Aspire manifest: %w", err)
+	}
+	if doc.Resources == nil {
+		return nil, fmt.Errorf("invalid Aspire manifest: no resources")
+	}
+
+	resolve := func(path string) string {
+		if path == "" || filepath.IsAbs(path) {
+			return path
+		}
+		return filepath.Clean(filepath.Join(baseDir, filepath.FromSlash(path)))
+	}
+
+	model := &Model{Source: SourceManifest, Resources: []Resource{}}
+	for name, res := range doc.Resources {
+		r := Resource{
+			Name:             name,
+			Kind:             manifestKind(res.Type),
+			Type:             res.Type,
+			Parent:           res.Parent,
+			Image:            res.Image,
+			Command:          res.Command,
+			Args:             res.Args,
+			WorkingDir:       resolve(res.WorkingDirectory),
+			Env:              res.Env,
+			ConnectionString: res.ConnectionString,
+		}
+		switch {
+		case r.Kind == KindProject:
+			r.Project = resolve(res.Path)
+		case res.Type == "dockerfile.v0":
+			r.Build = &Build{Context: resolve(res.Context), Dockerfile: resolve(res.Path)}
+		case res.Build != nil:
+			r.Build = &Build{Context: resolve(res.Build.Context), Dockerfile: resolve(res.Build.Dockerfile)}
+		}
+		if r.Command == "" {
+			r.Command = res.Entrypoint
+		}
+		if r.ConnectionString == "" && r.Kind == KindValue {
+			r.ConnectionString = res.Value
+		}
+		for _, input := range res.Inputs {
+			r.Secret = r.Secret || input.Secret
+		}
+
+		bindingNames := make([]string, 0, len(res.Bindings))
+		for bindingName := range res.Bindings {
+			bindingNames = append(bindingNames, bindingName)
+		}
+		sort.Strings(bindingNames)
+		for _, bindingName := range bindingNames {
+			b := res.Bindings[bindingName]
+			r.Bindings = append(r.Bindings, Binding{
+				Name:       bindingName,
+				Scheme:     b.Scheme,
+				Protocol:   b.Protocol,
+				Transport:  b.Transport,
+				Port:       b.Port,
+				TargetPort: b.TargetPort,
+				External:   b.External,
+			})
+		}
+
+		expressions := append([]string{res.ConnectionString, res.Value}, res.Args...)
+		for _, value := range res.Env {
+			expressions = append(expressions, value)
+		}
+		for _, expression := range expressions {
+			for _, m := range expressionPattern.FindAllStringSubmatch(expression, -1) {
+				r.References = append(r.References, m[1])
+			}
+		}
+		if r.Parent != "" {
+			r.References = append(r.References, r.Parent)
+		}
+		model.Resources = append(model.Resources, r)
+	}
+	model.finish()
+	return model, nil
+}
+
+// manifestKind returns the kind of a resource type of the manifest.
+func manifestKind(resourceType string) string {
+	kind, _, _ := strings.Cut(resourceType, ".")
+	switch {
+	case kind == "project":
+		return KindProject
+	case kind == "container" || kind == "dockerfile":
+		return KindContainer
+	case kind == "executable":
+		return KindExecutable
+	case kind == "parameter":
+		return KindParameter
+	case kind == "value":
+		return KindValue
+	case kind == "azure":
+		return KindAzure
+	default:
+		return KindOther
+	}
+}
+
+// runDotnet runs dotnet with args in dir and returns its combined output; tests replace it.
+var runDotnet = func(ctx context.Context, dir string, args ...string) ([]byte, error) {
+	// #nosec G204 -- dotnet with fixed arguments and the path of the AppHost project
+	cmd := exec.CommandContext(ctx, "dotnet", args...)
+	cmd.Dir = dir
+	return cmd.CombinedOutput()
+}
+
+// GenerateManifest runs the AppHost with the manifest publisher, which writes the manifest to
+// path instead of starting the app. The AppHost is built first, so this runs code of the
+// workspace.
+func GenerateManifest(ctx context.Context, appHost types.AspireProject, path string) error {
+	args := []string{"run", "--project", appHost.ProjectFile, "--", "--publisher", "manifest", "--output-path", path}
+	if out, err := runDotnet(ctx, appHost.Dir, args...); err != nil {
+		return fmt.Errorf("failed to publish the manifest of %s: %w: %s", filepath.Base(appHost.ProjectFile), err, dotnetError(string(out)))
+	}
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("%s didn't publish a manifest; is it an Aspire AppHost?", filepath.Base(appHost.ProjectFile))
+	}
+	return nil
+}
+
+// dotnetError returns the errors in the output of a failed dotnet run, such as build errors,
+// or its last line when it has none.
+func dotnetError(out string) string {
+	var errs []string
+	for _, line := range strings.Split(out, "\n") {
+		if line = strings.TrimSpace(line); strings.Contains(line, ": error ") && !slices.Contains(errs, line) {
+			errs = append(errs, line)
+		}
+	}
+	if len(errs) > 3 {
+		errs = append(errs[:3], fmt.Sprintf("and %d more errors", len(errs)-3))
+	}
+	if len(errs) == 0 {
+		lines := strings.Split(strings.TrimSpace(out), "\n")
+		return strings.TrimSpace(lines[len(lines)-1])
+	}
+	return strings.Join(errs, "\n")
+}
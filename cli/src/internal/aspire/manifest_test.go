@@ -0,0 +1,129 @@
+package aspire
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/jongio/azd-app/cli/src/internal/types"
+)
+
+const testManifest = `{
+  "$schema": "https://json.schemastore.org/aspire-8.0.json",
+  "resources": {
+    "cache": {
+      "type": "container.v0",
+      "connectionString": "{cache.bindings.tcp.host}:{cache.bindings.tcp.port}",
+      "image": "docker.io/library/redis:7.4",
+      "bindings": {"tcp": {"scheme": "tcp", "protocol": "tcp", "transport": "tcp", "targetPort": 6379}}
+    },
+    "pg-password": {
+      "type": "parameter.v0",
+      "value": "{pg-password.inputs.value}",
+      "inputs": {"value": {"type": "string", "secret": true}}
+    },
+    "pg": {
+      "type": "container.v0",
+      "connectionString": "Host={pg.bindings.tcp.host};Password={pg-password.value}",
+      "image": "docker.io/library/postgres:17"
+    },
+    "db": {"type": "value.v0", "connectionString": "{pg.connectionString};Database=db"},
+    "api": {
+      "type": "project.v0",
+      "path": "../Api/Api.csproj",
+      "env": {"ConnectionStrings__cache": "{cache.connectionString}", "ConnectionStrings__db": "{db.connectionString}"},
+      "bindings": {
+        "https": {"scheme": "https", "protocol": "tcp", "transport": "http"},
+        "http": {"scheme": "http", "protocol": "tcp", "transport": "http", "external": true}
+      }
+    },
+    "web": {
+      "type": "container.v1",
+      "build": {"context": "../web", "dockerfile": "../web/Dockerfile"},
+      "env": {"API_URL": "{api.bindings.http.url}"}
+    },
+    "storage": {"type": "azure.bicep.v0", "path": "storage.module.bicep"}
+  }
+}`
+
+func TestParseManifest(t *testing.T) {
+	base := filepath.Join(t.TempDir(), "AppHost")
+	model, err := ParseManifest([]byte(testManifest), base)
+	if err != nil {
+		t.Fatalf("ParseManifest() error = %v", err)
+	}
+
+	var names []string
+	for _, r := range model.Resources {
+		names = append(names, r.Name+":"+r.Kind)
+	}
+	want := []string{"api:project", "cache:container", "db:value", "pg:container", "pg-password:parameter", "storage:azure", "web:container"}
+	if !slices.Equal(names, want) {
+		t.Errorf("resources = %v, want %v", names, want)
+	}
+
+	api := model.Resource("api")
+	if api.Project != filepath.Join(filepath.Dir(base), "Api", "Api.csproj") {
+		t.Errorf("api project = %q", api.Project)
+	}
+	if len(api.Bindings) != 2 || api.Bindings[0].Name != "http" || !api.Bindings[0].External {
+		t.Errorf("api bindings = %+v, want http (external) then https", api.Bindings)
+	}
+	if !slices.Equal(api.References, []string{"cache", "db"}) {
+		t.Errorf("api references = %v", api.References)
+	}
+	if cache := model.Resource("cache"); cache.References != nil || cache.Bindings[0].TargetPort != 6379 {
+		t.Errorf("cache = %+v, want no reference to itself", cache)
+	}
+	if refs := model.Resource("pg").References; !slices.Equal(refs, []string{"pg-password"}) {
+		t.Errorf("pg references = %v", refs)
+	}
+	if !model.Resource("pg-password").Secret {
+		t.Error("pg-password should be secret")
+	}
+	if web := model.Resource("web"); web.Build == nil || web.Build.Context != filepath.Join(filepath.Dir(base), "web") {
+		t.Errorf("web build = %+v", web.Build)
+	}
+	if got := model.Dependents("db"); !slices.Equal(got, []string{"api"}) {
+		t.Errorf("Dependents(db) = %v", got)
+	}
+	if got := len(model.OfKind(KindContainer)); got != 3 {
+		t.Errorf("OfKind(container) = %d resources, want 3", got)
+	}
+
+	if _, err := ParseManifest([]byte(`{"name": "x"}`), base); err == nil {
+		t.Error("expected error for a manifest without resources")
+	}
+}
+
+func TestGenerateManifest(t *testing.T) {
+	orig := runDotnet
+	defer func() { runDotnet = orig }()
+
+	dir := t.TempDir()
+	appHost := types.AspireProject{Dir: dir, ProjectFile: filepath.Join(dir, "AppHost.csproj")}
+	path := filepath.Join(t.TempDir(), "manifest.json")
+
+	var got []string
+	runDotnet = func(_ context.Context, _ string, args ...string) ([]byte, error) {
+		got = args
+		return nil, os.WriteFile(path, []byte(testManifest), 0600)
+	}
+	if err := GenerateManifest(context.Background(), appHost, path); err != nil {
+		t.Fatalf("GenerateManifest() error = %v", err)
+	}
+	if want := "run --project " + appHost.ProjectFile + " -- --publisher manifest --output-path " + path; strings.Join(got, " ") != want {
+		t.Errorf("dotnet args = %v, want %s", got, want)
+	}
+
+	runDotnet = func(context.Context, string, ...string) ([]byte, error) {
+		return []byte("Build started\nProgram.cs(3,5): error CS1002: ; expected\n\nThe build failed.\n"), errors.New("exit status 1")
+	}
+	if err := GenerateManifest(context.Background(), appHost, path); err == nil || !strings.Contains(err.Error(), "CS1002") {
+		t.Errorf("GenerateManifest() error = %v, want the build error", err)
+	}
+}
@@ -0,0 +1,129 @@
+// Package aspire extracts the resource model of an Aspire AppHost: the projects, containers,
+// executables, parameters, and values it defines, their endpoints, and the references between
+// them. The model comes from the manifest the AppHost publishes, or, when the AppHost can't be
+// run, from its source.
+package aspire
+
+import (
+	"slices"
+	"sort"
+)
+
+// Kinds of resources.
+const (
+	KindProject    = "project"
+	KindContainer  = "container"
+	KindExecutable = "executable"
+	KindParameter  = "parameter"
+	KindValue      = "value" // A connection string or value, e.g. a database of a server
+	KindAzure      = "azure" // An Azure resource that azd provisions
+	KindOther      = "other"
+)
+
+// Sources of a model.
+const (
+	SourceManifest = "manifest" // Published by the AppHost
+	SourceCode     = "source"   // Parsed from the AppHost source
+)
+
+// Model is the resource model of an AppHost.
+type Model struct {
+	AppHost   string     `json:"appHost"` // Project file of the AppHost
+	Source    string     `json:"source"`  // SourceManifest or SourceCode
+	Resources []Resource `json:"resources"`
+	Warnings  []string   `json:"warnings,omitempty"`
+}
+
+// Resource is a resource of an AppHost.
+type Resource struct {
+	Name             string            `json:"name"`
+	Kind             string            `json:"kind"`
+	Type             string            `json:"type"`                 // Type of the manifest, e.g. project.v0, or the Add method of the source, e.g. AddRedis
+	Parent           string            `json:"parent,omitempty"`     // Resource it was added to, e.g. the server of a database
+	Project          string            `json:"project,omitempty"`    // Absolute path of the project file of a project
+	Image            string            `json:"image,omitempty"`      // Image of a container, with its tag
+	Build            *Build            `json:"build,omitempty"`      // Of a container built from a Dockerfile
+	Command          string            `json:"command,omitempty"`    // Of an executable
+	Args             []string          `json:"args,omitempty"`       // Of an executable or container
+	WorkingDir       string            `json:"workingDir,omitempty"` // Absolute path, of an executable
+	Env              map[string]string `json:"env,omitempty"`        // Values may hold expressions, e.g. {api.bindings.http.url}
+	Bindings         []Binding         `json:"bindings,omitempty"`   // Endpoints, by name
+	ConnectionString string            `json:"connectionString,omitempty"`
+	Secret           bool              `json:"secret,omitempty"`     // Of a parameter
+	References       []string          `json:"references,omitempty"` // Resources it refers to, sorted
+}
+
+// Build is how the image of a container is built.
+type Build struct {
+	Context    string `json:"context"`              // Absolute path
+	Dockerfile string `json:"dockerfile,omitempty"` // Absolute path
+}
+
+// Binding is an endpoint of a resource.
+type Binding struct {
+	Name       string `json:"name"`
+	Scheme     string `json:"scheme"`
+	Protocol   string `json:"protocol,omitempty"`
+	Transport  string `json:"transport,omitempty"`
+	Port       int    `json:"port,omitempty"`       // Port the endpoint is reached on
+	TargetPort int    `json:"targetPort,omitempty"` // Port the resource listens on
+	External   bool   `json:"external,omitempty"`   // Reachable from outside the app
+}
+
+// Resource returns the resource named name, or nil.
+func (m *Model) Resource(name string) *Resource {
+	for i := range m.Resources {
+		if m.Resources[i].Name == name {
+			return &m.Resources[i]
+		}
+	}
+	return nil
+}
+
+// OfKind returns the resources of kind, by name.
+func (m *Model) OfKind(kind string) []Resource {
+	var resources []Resource
+	for _, r := range m.Resources {
+		if r.Kind == kind {
+			resources = append(resources, r)
+		}
+	}
+	return resources
+}
+
+// Dependents returns the names of the resources that refer to name, sorted.
+func (m *Model) Dependents(name string) []string {
+	var names []string
+	for _, r := range m.Resources {
+		if slices.Contains(r.References, name) {
+			names = append(names, r.Name)
+		}
+	}
+	return names
+}
+
+// Runnable reports whether the resource runs as a process or container, rather than being
+// configuration or provisioned in Azure.
+func (r Resource) Runnable() bool {
+	return r.Kind == KindProject || r.Kind == KindContainer || r.Kind == KindExecutable
+}
+
+// finish sorts the resources by name and drops references to resources the model doesn't have,
+// such as a resource that refers to itself through an expression.
+func (m *Model) finish() {
+	sort.Slice(m.Resources, func(i, j int) bool { return m.Resources[i].Name < m.Resources[j].Name })
+	for i := range m.Resources {
+		r := &m.Resources[i]
+		refs := r.References[:0]
+		for _, ref := range r.References {
+			if ref != r.Name && m.Resource(ref) != nil && !slices.Contains(refs, ref) {
+				refs = append(refs, ref)
+			}
+		}
+		sort.Strings(refs)
+		r.References = refs
+		if len(r.References) == 0 {
+			r.References = nil
+		}
+	}
+}
@@ -0,0 +1,518 @@
+package aspire
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jongio/azd-app/cli/src/internal/security"
+	"github.com/jongio/azd-app/cli/src/internal/types"
+)
+
+var (
+	// commentPattern matches the comments of C# source, but not the // of a URL such as
+	// http://localhost, and keeps the character before a line comment in $1.
+	commentPattern = regexp.MustCompile(`(?s:/\*.*?\*/)|(?m:(^|[^:])//.*$)`)
+	// assignPattern captures the variable a statement assigns, e.g. api in var api = builder...
+	assignPattern = regexp.MustCompile(`^\s*(?:var|[A-Za-z_][\w<>.]*)\s+(\w+)\s*=`)
+	// receiverPattern captures the variable a statement calls a method of, e.g. builder or api.
+	receiverPattern = regexp.MustCompile(`^\s*(?:(?:var|[A-Za-z_][\w<>.]*)\s+\w+\s*=\s*)?(\w+)\s*\.\s*\w+\s*[<(]`)
+	// builderPattern captures the variable of the builder of the AppHost.
+	builderPattern = regexp.MustCompile(`(\w+)\s*=\s*DistributedApplication\s*\.\s*CreateBuilder\b`)
+	// callPattern captures the method and type argument of a call in a chain, e.g. AddProject
+	// and Projects.Api in .AddProject<Projects.Api>(.
+	callPattern = regexp.MustCompile(`\.\s*(\w+)\s*(?:<([^>]*)>)?\s*\(`)
+	// projectReferencePattern captures the project references of the AppHost project file.
+	projectReferencePattern = regexp.MustCompile(`<ProjectReference\s+Include="([^"]+)"`)
+)
+
+// executableMethods are the Add methods of resources that run as a process of the machine.
+var executableMethods = map[string]bool{
+	"AddExecutable": true, "AddNpmApp": true, "AddNodeApp": true, "AddPythonApp": true,
+	"AddUvicornApp": true, "AddViteApp": true, "AddJavaScriptApp": true,
+}
+
+// ParseSource returns the model of the AppHost from its source: the resources added to the
+// builder in its .cs files and the calls that configure them. It recognizes what the AppHost
+// states literally, so values that are computed, or resources added in loops or helpers, are
+// missed; the manifest of GenerateManifest has them all.
+func ParseSource(appHost types.AspireProject) (*Model, error) {
+	paths, err := filepath.Glob(filepath.Join(appHost.Dir, "*.cs"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	p := &sourceParser{
+		dir:      appHost.Dir,
+		builder:  "builder",
+		projects: projectReferences(appHost.ProjectFile),
+		vars:     make(map[string]*Resource),
+		byName:   make(map[string]*Resource),
+	}
+	found := false
+	for _, path := range paths {
+		if err := security.ValidatePath(path); err != nil {
+			continue
+		}
+		// #nosec G304 -- Path validated by security.ValidatePath
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", filepath.Base(path), err)
+		}
+		if !strings.Contains(string(data), "DistributedApplication") {
+			continue
+		}
+		found = true
+		p.parse(string(data))
+	}
+	if !found {
+		return nil, fmt.Errorf("no source file in %s creates a DistributedApplication", appHost.Dir)
+	}
+
+	model := &Model{AppHost: appHost.ProjectFile, Source: SourceCode, Resources: []Resource{}}
+	for _, r := range p.order {
+		if r.Kind == KindProject && len(r.Bindings) == 0 {
+			// Projects get the endpoints of their launch profile, which are http and https
+			r.Bindings = []Binding{{Name: "http", Scheme: "http"}, {Name: "https", Scheme: "https"}}
+		}
+		if r.Kind == KindProject && r.external {
+			for i := range r.Bindings {
+				r.Bindings[i].External = r.Bindings[i].External || strings.HasPrefix(r.Bindings[i].Scheme, "http")
+			}
+		}
+		model.Resources = append(model.Resources, r.Resource)
+	}
+	model.finish()
+	return model, nil
+}
+
+// sourceParser collects the resources of the source files of an AppHost.
+type sourceParser struct {
+	dir      string
+	builder  string            // Variable of the builder
+	projects map[string]string // Project file by the name of its type in the Projects namespace
+	vars     map[string]*Resource
+	byName   map[string]*Resource
+	order    []*sourceResource
+}
+
+// sourceResource is a resource as the source configures it.
+type sourceResource struct {
+	Resource
+	external bool // WithExternalHttpEndpoints
+}
+
+// parse adds the resources of the statements of source.
+func (p *sourceParser) parse(source string) {
+	source = commentPattern.ReplaceAllString(source, "$1")
+	if m := builderPattern.FindStringSubmatch(source); m != nil {
+		p.builder = m[1]
+	}
+	for _, statement := range splitStatements(source) {
+		receiver := receiverPattern.FindStringSubmatch(statement)
+		if receiver == nil {
+			continue
+		}
+		var current *Resource
+		if receiver[1] != p.builder {
+			if current = p.vars[receiver[1]]; current == nil {
+				continue
+			}
+		}
+		depths := parenDepths(statement)
+		for _, call := range callPattern.FindAllStringSubmatchIndex(statement, -1) {
+			method := statement[call[2]:call[3]]
+			typeArg := ""
+			if call[4] >= 0 {
+				typeArg = statement[call[4]:call[5]]
+			}
+			args := callArgs(statement, call[1])
+			if depths[call[0]] > 0 {
+				// A call in the arguments of the chain: a resource added there, such as the
+				// parameter of a password, is one the current resource refers to
+				if strings.HasPrefix(method, "Add") && p.calledOnBuilder(statement[:call[0]]) {
+					if r := p.add(method, typeArg, args, nil); r != nil && current != nil {
+						current.References = append(current.References, r.Name)
+					}
+				}
+				continue
+			}
+			if strings.HasPrefix(method, "Add") {
+				if r := p.add(method, typeArg, args, current); r != nil {
+					current = r
+				}
+			} else if current != nil {
+				p.configure(current, method, args)
+			}
+		}
+		if m := assignPattern.FindStringSubmatch(statement); m != nil && current != nil {
+			p.vars[m[1]] = current
+		}
+	}
+}
+
+// calledOnBuilder reports whether the call after prefix is a method of the builder.
+func (p *sourceParser) calledOnBuilder(prefix string) bool {
+	prefix = strings.TrimSpace(prefix)
+	rest, ok := strings.CutSuffix(prefix, p.builder)
+	return ok && (rest == "" || !isIdentifier(rest[len(rest)-1:]))
+}
+
+// add adds the resource of an Add call to parent, or to the builder when parent is nil, and
+// returns it; nil for a call that adds no resource.
+func (p *sourceParser) add(method, typeArg string, args []callArg, parent *Resource) *Resource {
+	name, ok := stringArg(args, 0, "name")
+	if !ok {
+		return nil
+	}
+	r := &sourceResource{Resource: Resource{Name: name, Type: method, Kind: KindContainer}}
+	switch {
+	case parent != nil:
+		r.Kind = KindValue
+		r.Parent = parent.Name
+		r.References = []string{parent.Name}
+	case method == "AddProject":
+		r.Kind = KindProject
+		if path, ok := stringArg(args, 1, "projectPath"); ok {
+			r.Project = p.resolve(path)
+		} else {
+			r.Project = p.projects[strings.TrimPrefix(typeArg, "Projects.")]
+		}
+	case method == "AddContainer":
+		if image, ok := stringArg(args, 1, "image"); ok {
+			r.Image = image
+			if tag, ok := stringArg(args, 2, "tag"); ok {
+				r.Image += ":" + tag
+			}
+		}
+	case method == "AddDockerfile":
+		contextPath, _ := stringArg(args, 1, "contextPath")
+		dockerfile, ok := stringArg(args, 2, "dockerfilePath")
+		if !ok {
+			dockerfile = "Dockerfile"
+		}
+		r.Build = &Build{Context: p.resolve(contextPath), Dockerfile: filepath.Join(p.resolve(contextPath), dockerfile)}
+	case method == "AddParameter":
+		r.Kind = KindParameter
+		secret, ok := argValue(args, 1, "secret")
+		r.Secret = ok && secret == "true"
+	case method == "AddConnectionString":
+		r.Kind = KindValue
+	case executableMethods[method]:
+		r.Kind = KindExecutable
+		p.executable(r, method, args)
+	case strings.HasPrefix(method, "AddAzure"):
+		r.Kind = KindAzure
+	}
+
+	if existing := p.byName[name]; existing != nil {
+		return existing
+	}
+	p.order = append(p.order, r)
+	p.byName[name] = &r.Resource
+	return &r.Resource
+}
+
+// executable sets the command of a resource of an Add method of executableMethods.
+func (p *sourceParser) executable(r *sourceResource, method string, args []callArg) {
+	switch method {
+	case "AddExecutable":
+		r.Command, _ = stringArg(args, 1, "command")
+		dir, _ := stringArg(args, 2, "workingDirectory")
+		r.WorkingDir = p.resolve(dir)
+		for _, arg := range args[min(3, len(args)):] {
+			if value, ok := unquote(arg.value); ok {
+				r.Args = append(r.Args, value)
+			}
+		}
+	case "AddNpmApp":
+		dir, _ := stringArg(args, 1, "workingDirectory")
+		script, ok := stringArg(args, 2, "scriptName")
+		if !ok {
+			script = "start"
+		}
+		r.Command, r.Args, r.WorkingDir = "npm", []string{"run", script}, p.resolve(dir)
+	case "AddNodeApp":
+		script, _ := stringArg(args, 1, "scriptPath")
+		dir, ok := stringArg(args, 2, "workingDirectory")
+		if !ok {
+			dir = "."
+		}
+		r.Command, r.Args, r.WorkingDir = "node", []string{script}, p.resolve(dir)
+	case "AddPythonApp":
+		dir, _ := stringArg(args, 1, "appDirectory")
+		script, _ := stringArg(args, 2, "scriptPath")
+		r.Command, r.Args, r.WorkingDir = "python", []string{script}, p.resolve(dir)
+	default:
+		dir, _ := stringArg(args, 1, "workingDirectory")
+		r.WorkingDir = p.resolve(dir)
+	}
+}
+
+// configure applies a call of the chain of r.
+func (p *sourceParser) configure(r *Resource, method string, args []callArg) {
+	switch method {
+	case "WithReference", "WaitFor", "WaitForCompletion":
+		if len(args) > 0 {
+			variable, _, _ := strings.Cut(args[0].value, ".")
+			if target := p.vars[strings.TrimSpace(variable)]; target != nil {
+				r.References = append(r.References, target.Name)
+			}
+		}
+	case "WithHttpEndpoint", "WithHttpsEndpoint", "WithEndpoint":
+		r.Bindings = append(r.Bindings, endpointBinding(method, args))
+	case "WithExternalHttpEndpoints":
+		for i := range r.Bindings {
+			if strings.HasPrefix(r.Bindings[i].Scheme, "http") {
+				r.Bindings[i].External = true
+			}
+		}
+		for _, sr := range p.order {
+			if &sr.Resource == r {
+				sr.external = true
+			}
+		}
+	case "WithEnvironment":
+		key, ok := stringArg(args, 0, "name")
+		if !ok || len(args) < 2 {
+			return
+		}
+		if r.Env == nil {
+			r.Env = make(map[string]string)
+		}
+		value, ok := unquote(args[1].value)
+		if !ok {
+			value = args[1].value
+			variable, _, _ := strings.Cut(value, ".")
+			if target := p.vars[strings.TrimSpace(variable)]; target != nil {
+				r.References = append(r.References, target.Name)
+			}
+		}
+		r.Env[key] = value
+	case "WithArgs":
+		for _, arg := range args {
+			if value, ok := unquote(arg.value); ok {
+				r.Args = append(r.Args, value)
+			}
+		}
+	}
+}
+
+// endpointBinding returns the binding of a WithHttpEndpoint, WithHttpsEndpoint, or
+// WithEndpoint call, whose parameters are port, targetPort, then scheme for WithEndpoint, and
+// name; the name defaults to the scheme.
+func endpointBinding(method string, args []callArg) Binding {
+	b := Binding{Scheme: "http", Transport: "http"}
+	nameIndex := 2
+	switch method {
+	case "WithHttpsEndpoint":
+		b.Scheme = "https"
+	case "WithEndpoint":
+		b.Scheme, b.Transport = "tcp", "tcp"
+		if scheme, ok := stringArg(args, 2, "scheme"); ok {
+			b.Scheme = scheme
+		}
+		nameIndex = 3
+	}
+	if port, ok := argValue(args, 0, "port"); ok {
+		b.Port, _ = strconv.Atoi(port)
+	}
+	if port, ok := argValue(args, 1, "targetPort"); ok {
+		b.TargetPort, _ = strconv.Atoi(port)
+	}
+	b.Name = b.Scheme
+	if name, ok := stringArg(args, nameIndex, "name"); ok {
+		b.Name = name
+	}
+	if external, ok := argValue(args, -1, "isExternal"); ok {
+		b.External = external == "true"
+	}
+	return b
+}
+
+// resolve returns path, relative to the AppHost directory, as an absolute path.
+func (p *sourceParser) resolve(path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Clean(filepath.Join(p.dir, filepath.FromSlash(strings.ReplaceAll(path, `\`, "/"))))
+}
+
+// projectReferences returns the projects the AppHost project file references, by the name of
+// their type in the Projects namespace that Aspire generates: the file name, with dots and
+// dashes replaced by underscores.
+func projectReferences(projectFile string) map[string]string {
+	refs := make(map[string]string)
+	if err := security.ValidatePath(projectFile); err != nil {
+		return refs
+	}
+	// #nosec G304 -- Path validated by security.ValidatePath
+	data, err := os.ReadFile(projectFile)
+	if err != nil {
+		return refs
+	}
+	dir := filepath.Dir(projectFile)
+	for _, m := range projectReferencePattern.FindAllStringSubmatch(string(data), -1) {
+		path := filepath.Clean(filepath.Join(dir, filepath.FromSlash(strings.ReplaceAll(m[1], `\`, "/"))))
+		name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		refs[strings.NewReplacer(".", "_", "-", "_").Replace(name)] = path
+	}
+	return refs
+}
+
+// callArg is an argument of a call, with its name when it is passed by name.
+type callArg struct {
+	name, value string
+}
+
+// callArgs returns the arguments of the call whose argument list starts at open, just after
+// its opening parenthesis.
+func callArgs(s string, open int) []callArg {
+	depth, inString := 1, false
+	end := len(s)
+	for i := open; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '"' && (i == 0 || s[i-1] != '\\'):
+			inString = !inString
+		case inString:
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+			if depth == 0 {
+				end = i
+				i = len(s)
+			}
+		}
+	}
+
+	var args []callArg
+	for _, part := range splitTopLevel(s[open:end], ',') {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		arg := callArg{value: part}
+		if name, value, ok := strings.Cut(part, ":"); ok && isIdentifier(strings.TrimSpace(name)) {
+			arg = callArg{name: strings.TrimSpace(name), value: strings.TrimSpace(value)}
+		}
+		args = append(args, arg)
+	}
+	return args
+}
+
+// argValue returns the argument passed by name, else the one at index when it is positional.
+func argValue(args []callArg, index int, name string) (string, bool) {
+	for _, arg := range args {
+		if arg.name == name {
+			return arg.value, true
+		}
+	}
+	if index >= 0 && index < len(args) && args[index].name == "" {
+		return args[index].value, true
+	}
+	return "", false
+}
+
+// stringArg returns the argument of argValue when it is a string literal.
+func stringArg(args []callArg, index int, name string) (string, bool) {
+	value, ok := argValue(args, index, name)
+	if !ok {
+		return "", false
+	}
+	return unquote(value)
+}
+
+// unquote returns the value of a C# string literal, regular or verbatim.
+func unquote(s string) (string, bool) {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, `@"`) && strings.HasSuffix(s, `"`) && len(s) >= 3 {
+		return strings.ReplaceAll(s[2:len(s)-1], `""`, `"`), true
+	}
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		if value, err := strconv.Unquote(s); err == nil {
+			return value, true
+		}
+		return s[1 : len(s)-1], true
+	}
+	return "", false
+}
+
+// splitStatements splits source into statements: at each semicolon and brace outside string
+// literals and parentheses, so the bodies of lambdas passed to calls stay in their statement.
+func splitStatements(source string) []string {
+	var statements []string
+	depths := parenDepths(source)
+	inString, start := false, 0
+	for i := 0; i < len(source); i++ {
+		switch c := source[i]; {
+		case c == '"' && (i == 0 || source[i-1] != '\\'):
+			inString = !inString
+		case inString || depths[i] > 0:
+		case c == ';' || c == '{' || c == '}':
+			statements = append(statements, source[start:i])
+			start = i + 1
+		}
+	}
+	return append(statements, source[start:])
+}
+
+// parenDepths returns the depth of parentheses at each byte of s, outside string literals.
+func parenDepths(s string) []int {
+	depths := make([]int, len(s)+1)
+	depth, inString := 0, false
+	for i := 0; i < len(s); i++ {
+		depths[i] = depth
+		switch c := s[i]; {
+		case c == '"' && (i == 0 || s[i-1] != '\\'):
+			inString = !inString
+		case inString:
+		case c == '(':
+			depth++
+		case c == ')':
+			depth = max(depth-1, 0)
+		}
+	}
+	depths[len(s)] = depth
+	return depths
+}
+
+// splitTopLevel splits s at each sep outside string literals, parentheses, and braces.
+func splitTopLevel(s string, sep byte) []string {
+	var parts []string
+	depth, inString, start := 0, false, 0
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '"' && (i == 0 || s[i-1] != '\\'):
+			inString = !inString
+		case inString:
+		case c == '(' || c == '{' || c == '[':
+			depth++
+		case c == ')' || c == '}' || c == ']':
+			depth--
+		case c == sep && depth <= 0:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// isIdentifier reports whether s is a C# identifier, as the name of a named argument.
+func isIdentifier(s string) bool {
+	if s == "" || s[0] >= '0' && s[0] <= '9' {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c != '_' && (c < '0' || c > '9') && (c < 'A' || c > 'Z') && (c < 'a' || c > 'z') {
+			return false
+		}
+	}
+	return true
+}
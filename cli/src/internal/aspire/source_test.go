@@ -0,0 +1,117 @@
+package aspire
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/jongio/azd-app/cli/src/internal/types"
+)
+
+const testAppHost = `using Aspire.Hosting;
+
+var app = DistributedApplication.CreateBuilder(args);
+
+// var old = app.AddRedis("old");
+var password = app.AddParameter("pg-password", secret: true);
+var db = app.AddPostgres("pg", password: app.AddParameter("pg-user"))
+    .WithDataVolume()
+    .AddDatabase("db");
+var cache = app.AddRedis("cache");
+
+var api = app.AddProject<Projects.Shop_Api>("api")
+    .WithReference(db)
+    .WaitFor(db)
+    .WithEnvironment("FEATURE_FLAGS", "beta;dark-mode")
+    .WithExternalHttpEndpoints();
+api.WithReference(cache);
+
+app.AddNpmApp("web", "../web", "dev")
+    .WithReference(api)
+    .WithHttpEndpoint(port: 3000, env: "PORT");
+
+app.AddContainer("mail", "maildev/maildev", "2.1.0")
+    .WithHttpEndpoint(targetPort: 1080, name: "ui");
+
+app.AddAzureStorage("storage");
+
+app.Build().Run();
+`
+
+func TestParseSource(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "AppHost")
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		t.Fatal(err)
+	}
+	csproj := `<Project Sdk="Microsoft.NET.Sdk"><ItemGroup><ProjectReference Include="..\Shop.Api\Shop.Api.csproj" /></ItemGroup></Project>`
+	if err := os.WriteFile(filepath.Join(dir, "AppHost.csproj"), []byte(csproj), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "Program.cs"), []byte(testAppHost), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	model, err := ParseSource(types.AspireProject{Dir: dir, ProjectFile: filepath.Join(dir, "AppHost.csproj")})
+	if err != nil {
+		t.Fatalf("ParseSource() error = %v", err)
+	}
+
+	var names []string
+	for _, r := range model.Resources {
+		names = append(names, r.Name+":"+r.Kind)
+	}
+	want := []string{"api:project", "cache:container", "db:value", "mail:container", "pg:container", "pg-password:parameter", "pg-user:parameter", "storage:azure", "web:executable"}
+	if !slices.Equal(names, want) {
+		t.Errorf("resources = %v, want %v", names, want)
+	}
+
+	api := model.Resource("api")
+	if api.Project != filepath.Join(root, "Shop.Api", "Shop.Api.csproj") {
+		t.Errorf("api project = %q", api.Project)
+	}
+	if !slices.Equal(api.References, []string{"cache", "db"}) {
+		t.Errorf("api references = %v", api.References)
+	}
+	if api.Env["FEATURE_FLAGS"] != "beta;dark-mode" {
+		t.Errorf("api env = %v", api.Env)
+	}
+	if len(api.Bindings) != 2 || !api.Bindings[0].External || !api.Bindings[1].External {
+		t.Errorf("api bindings = %+v, want external http and https", api.Bindings)
+	}
+
+	if db := model.Resource("db"); db.Parent != "pg" {
+		t.Errorf("db parent = %q, want pg", db.Parent)
+	}
+	if refs := model.Resource("pg").References; !slices.Equal(refs, []string{"pg-user"}) {
+		t.Errorf("pg references = %v, want the parameter added in its arguments", refs)
+	}
+	if !model.Resource("pg-password").Secret || model.Resource("pg-user").Secret {
+		t.Error("only pg-password should be secret")
+	}
+	web := model.Resource("web")
+	if web.Command != "npm" || !slices.Equal(web.Args, []string{"run", "dev"}) || web.WorkingDir != filepath.Join(root, "web") {
+		t.Errorf("web = %+v", web)
+	}
+	if len(web.Bindings) != 1 || web.Bindings[0].Port != 3000 || web.Bindings[0].Name != "http" {
+		t.Errorf("web bindings = %+v", web.Bindings)
+	}
+	mail := model.Resource("mail")
+	if mail.Image != "maildev/maildev:2.1.0" || len(mail.Bindings) != 1 || mail.Bindings[0].Name != "ui" || mail.Bindings[0].TargetPort != 1080 {
+		t.Errorf("mail = %+v", mail)
+	}
+	if model.Resource("old") != nil {
+		t.Error("commented-out resources should be skipped")
+	}
+}
+
+func TestParseSourceWithoutAppHost(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "Program.cs"), []byte(`Console.WriteLine("hi");`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseSource(types.AspireProject{Dir: dir, ProjectFile: filepath.Join(dir, "App.csproj")}); err == nil {
+		t.Error("expected error for a project that isn't an AppHost")
+	}
+}
@@ -0,0 +1,186 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jongio/azd-app/cli/src/internal/security"
+)
+
+// DefaultTimeout is the maximum time a single Lighthouse run may take.
+const DefaultTimeout = 3 * time.Minute
+
+// DefaultCategories lists the Lighthouse categories audited when none are specified.
+var DefaultCategories = []string{"performance", "accessibility", "best-practices", "seo"}
+
+// servicePattern restricts service names to safe file names, since reports are named after the
+// service.
+var servicePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// Score is the result for a single Lighthouse category.
+type Score struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+	Score int    `json:"score"` // 0-100
+}
+
+// Result contains the outcome of a web audit for a single service.
+type Result struct {
+	Service  string  `json:"service"`
+	URL      string  `json:"url"`
+	Scores   []Score `json:"scores"`
+	Report   string  `json:"report,omitempty"`
+	MinScore int     `json:"minScore"`
+}
+
+// Failing returns the categories that scored below the minimum score.
+func (r Result) Failing() []Score {
+	var failing []Score
+	for _, s := range r.Scores {
+		if s.Score < r.MinScore {
+			failing = append(failing, s)
+		}
+	}
+	return failing
+}
+
+// Passed reports whether every audited category met the minimum score.
+func (r Result) Passed() bool {
+	return len(r.Failing()) == 0
+}
+
+// lighthouseReport is the subset of the Lighthouse JSON report that we read.
+type lighthouseReport struct {
+	Categories map[string]struct {
+		ID    string   `json:"id"`
+		Title string   `json:"title"`
+		Score *float64 `json:"score"`
+	} `json:"categories"`
+	RuntimeError *struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"runtimeError"`
+}
+
+// FindLighthouse returns the command used to run Lighthouse.
+// A lighthouse binary on PATH is preferred; otherwise npx is used to fetch it on demand.
+func FindLighthouse() (string, []string, error) {
+	if path, err := exec.LookPath("lighthouse"); err == nil {
+		return path, nil, nil
+	}
+	if path, err := exec.LookPath("npx"); err == nil {
+		return path, []string{"--yes", "lighthouse"}, nil
+	}
+	return "", nil, fmt.Errorf("lighthouse not found (install with 'npm install -g lighthouse' or install Node.js for npx)")
+}
+
+// Run audits a URL with Lighthouse and writes the full JSON report into outDir.
+// chromePath is passed to Lighthouse through CHROME_PATH when set.
+func Run(ctx context.Context, chromePath, serviceName, url, outDir string, categories []string) (*Result, error) {
+	if !servicePattern.MatchString(serviceName) || strings.Contains(serviceName, "..") {
+		return nil, fmt.Errorf("invalid service name '%s'", serviceName)
+	}
+	if err := security.ValidatePath(outDir); err != nil {
+		return nil, fmt.Errorf("invalid output directory: %w", err)
+	}
+	if err := os.MkdirAll(outDir, 0750); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+	if len(categories) == 0 {
+		categories = DefaultCategories
+	}
+
+	name, prefix, err := FindLighthouse()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, DefaultTimeout)
+	defer cancel()
+
+	args := make([]string, 0, len(prefix)+6)
+	args = append(args, prefix...)
+	args = append(args,
+		url,
+		"--output=json",
+		"--output-path=stdout",
+		"--quiet",
+		"--only-categories="+strings.Join(categories, ","),
+		"--chrome-flags=--headless=new --no-first-run --no-default-browser-check",
+	)
+
+	// #nosec G204 -- name comes from FindLighthouse and url is a local service URL
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Env = os.Environ()
+	if chromePath != "" {
+		cmd.Env = append(cmd.Env, "CHROME_PATH="+chromePath)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("lighthouse timed out auditing %s", url)
+		}
+		return nil, fmt.Errorf("lighthouse failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	scores, err := ParseReport(stdout.Bytes())
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{
+		Service: serviceName,
+		URL:     url,
+		Scores:  scores,
+	}
+
+	reportPath := filepath.Join(outDir, serviceName+".json")
+	if err := os.WriteFile(reportPath, stdout.Bytes(), 0600); err == nil {
+		result.Report = reportPath
+	}
+
+	return result, nil
+}
+
+// ParseReport extracts category scores from a Lighthouse JSON report.
+// Scores are returned as integers from 0 to 100, sorted by category ID.
+func ParseReport(data []byte) ([]Score, error) {
+	var report lighthouseReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("failed to parse lighthouse report: %w", err)
+	}
+	if report.RuntimeError != nil && report.RuntimeError.Code != "" && report.RuntimeError.Code != "NO_ERROR" {
+		return nil, fmt.Errorf("lighthouse could not load the page: %s", report.RuntimeError.Message)
+	}
+	if len(report.Categories) == 0 {
+		return nil, fmt.Errorf("lighthouse report contains no category scores")
+	}
+
+	scores := make([]Score, 0, len(report.Categories))
+	for id, category := range report.Categories {
+		score := 0
+		if category.Score != nil {
+			score = int(*category.Score*100 + 0.5)
+		}
+		scores = append(scores, Score{
+			ID:    id,
+			Title: category.Title,
+			Score: score,
+		})
+	}
+	sort.Slice(scores, func(i, j int) bool { return scores[i].ID < scores[j].ID })
+
+	return scores, nil
+}
@@ -0,0 +1,100 @@
+package audit
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseReport(t *testing.T) {
+	data := []byte(`{
+		"categories": {
+			"performance": {"id": "performance", "title": "Performance", "score": 0.914},
+			"accessibility": {"id": "accessibility", "title": "Accessibility", "score": 1},
+			"seo": {"id": "seo", "title": "SEO", "score": null}
+		}
+	}`)
+
+	scores, err := ParseReport(data)
+	if err != nil {
+		t.Fatalf("ParseReport() error = %v", err)
+	}
+
+	expected := []Score{
+		{ID: "accessibility", Title: "Accessibility", Score: 100},
+		{ID: "performance", Title: "Performance", Score: 91},
+		{ID: "seo", Title: "SEO", Score: 0},
+	}
+	if len(scores) != len(expected) {
+		t.Fatalf("ParseReport() returned %d scores, want %d", len(scores), len(expected))
+	}
+	for i, want := range expected {
+		if scores[i] != want {
+			t.Errorf("scores[%d] = %+v, want %+v", i, scores[i], want)
+		}
+	}
+}
+
+func TestParseReportErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"invalid json", `not json`},
+		{"no categories", `{"categories": {}}`},
+		{"runtime error", `{"runtimeError": {"code": "FAILED_DOCUMENT_REQUEST", "message": "connection refused"}, "categories": {"seo": {"score": 0}}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseReport([]byte(tt.data)); err == nil {
+				t.Error("ParseReport() expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestResultPassed(t *testing.T) {
+	result := Result{
+		Scores: []Score{
+			{ID: "accessibility", Score: 95},
+			{ID: "performance", Score: 72},
+		},
+	}
+
+	tests := []struct {
+		minScore    int
+		wantPassed  bool
+		wantFailing int
+	}{
+		{0, true, 0},
+		{70, true, 0},
+		{80, false, 1},
+		{100, false, 2},
+	}
+
+	for _, tt := range tests {
+		result.MinScore = tt.minScore
+		if got := result.Passed(); got != tt.wantPassed {
+			t.Errorf("MinScore %d: Passed() = %v, want %v", tt.minScore, got, tt.wantPassed)
+		}
+		if got := len(result.Failing()); got != tt.wantFailing {
+			t.Errorf("MinScore %d: len(Failing()) = %d, want %d", tt.minScore, got, tt.wantFailing)
+		}
+	}
+}
+
+func TestRunInvalidServiceName(t *testing.T) {
+	outDir := filepath.Join(t.TempDir(), "audit")
+	for _, name := range []string{"../../x", "..", "a/b", `a\b`, ".hidden", ""} {
+		_, err := Run(context.Background(), "", name, "http://localhost:3000", outDir, nil)
+		if err == nil || !strings.Contains(err.Error(), "invalid service name") {
+			t.Errorf("Run(%q) error = %v, want invalid service name", name, err)
+		}
+	}
+	if _, err := os.Stat(outDir); !os.IsNotExist(err) {
+		t.Errorf("Run created %s for an invalid service name", outDir)
+	}
+}
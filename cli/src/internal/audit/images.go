@@ -0,0 +1,293 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jongio/azd-app/cli/src/internal/security"
+)
+
+// ScanTimeout is the maximum time a single image scan may take, including the download of the
+// vulnerability database on first use.
+const ScanTimeout = 10 * time.Minute
+
+// Image scanners.
+const (
+	ScannerTrivy = "trivy"
+	ScannerGrype = "grype"
+)
+
+// Severities of vulnerabilities, from least to most severe.
+const (
+	SeverityUnknown  = "UNKNOWN"
+	SeverityLow      = "LOW"
+	SeverityMedium   = "MEDIUM"
+	SeverityHigh     = "HIGH"
+	SeverityCritical = "CRITICAL"
+)
+
+// Severities lists the severities from least to most severe.
+var Severities = []string{SeverityUnknown, SeverityLow, SeverityMedium, SeverityHigh, SeverityCritical}
+
+// ParseSeverity returns the severity named by s, in any case.
+func ParseSeverity(s string) (string, error) {
+	severity := strings.ToUpper(strings.TrimSpace(s))
+	if severityRank(severity) < 0 {
+		return "", fmt.Errorf("invalid severity %q: want low, medium, high, or critical", s)
+	}
+	return severity, nil
+}
+
+// severityRank returns the position of severity in Severities, or -1 if it is none of them.
+func severityRank(severity string) int {
+	for i, s := range Severities {
+		if s == severity {
+			return i
+		}
+	}
+	return -1
+}
+
+// Finding is a vulnerability of a package of an image.
+type Finding struct {
+	ID           string `json:"id"` // e.g. CVE-2024-3094
+	Package      string `json:"package"`
+	Version      string `json:"version"`
+	FixedVersion string `json:"fixedVersion,omitempty"`
+	Severity     string `json:"severity"`
+	Title        string `json:"title,omitempty"`
+}
+
+// ImageResult contains the outcome of the vulnerability scan of the image of a service.
+type ImageResult struct {
+	Service   string         `json:"service"`
+	Image     string         `json:"image"`
+	Scanner   string         `json:"scanner"`
+	Findings  []Finding      `json:"findings"`
+	Counts    map[string]int `json:"counts"` // Findings by severity
+	Report    string         `json:"report,omitempty"`
+	Threshold string         `json:"threshold"` // Least severity that fails the scan
+}
+
+// Failing returns the findings at or above the threshold, most severe first.
+func (r ImageResult) Failing() []Finding {
+	threshold := severityRank(r.Threshold)
+	var failing []Finding
+	for _, f := range r.Findings {
+		if threshold >= 0 && severityRank(f.Severity) >= threshold {
+			failing = append(failing, f)
+		}
+	}
+	return failing
+}
+
+// Passed reports whether the image has no finding at or above the threshold.
+func (r ImageResult) Passed() bool {
+	return len(r.Failing()) == 0
+}
+
+// FindScanner returns the name and path of the image scanner to run: name when set, otherwise
+// Trivy when installed, otherwise Grype.
+func FindScanner(name string) (string, string, error) {
+	switch name {
+	case ScannerTrivy, ScannerGrype:
+		path, err := exec.LookPath(name)
+		if err != nil {
+			return "", "", fmt.Errorf("%s not found on PATH", name)
+		}
+		return name, path, nil
+	case "":
+	default:
+		return "", "", fmt.Errorf("invalid scanner %q: want %s or %s", name, ScannerTrivy, ScannerGrype)
+	}
+	for _, scanner := range []string{ScannerTrivy, ScannerGrype} {
+		if path, err := exec.LookPath(scanner); err == nil {
+			return scanner, path, nil
+		}
+	}
+	return "", "", fmt.Errorf("no image scanner found; install Trivy (https://trivy.dev) or Grype (https://github.com/anchore/grype)")
+}
+
+// ScanImage scans image with scanner, found with FindScanner, and writes the JSON report of the
+// scanner into outDir. Images are read from the image store of Docker at dockerHost, or the
+// default one when it is empty, and otherwise pulled from their registry.
+func ScanImage(ctx context.Context, scanner, scannerPath, dockerHost, serviceName, image, outDir string) (*ImageResult, error) {
+	if err := security.ValidatePath(outDir); err != nil {
+		return nil, fmt.Errorf("invalid output directory: %w", err)
+	}
+	if err := os.MkdirAll(outDir, 0750); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, ScanTimeout)
+	defer cancel()
+
+	var args []string
+	parse := ParseTrivyReport
+	if scanner == ScannerGrype {
+		args = []string{image, "--output", "json", "--quiet"}
+		parse = ParseGrypeReport
+	} else {
+		args = []string{"image", "--format", "json", "--quiet", "--scanners", "vuln", image}
+	}
+
+	// #nosec G204 -- scannerPath comes from FindScanner and image is passed as one argument
+	cmd := exec.CommandContext(ctx, scannerPath, args...)
+	cmd.Env = os.Environ()
+	if dockerHost != "" {
+		cmd.Env = append(cmd.Env, "DOCKER_HOST="+dockerHost)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("%s timed out scanning %s", scanner, image)
+		}
+		return nil, fmt.Errorf("%s failed: %w: %s", scanner, err, strings.TrimSpace(stderr.String()))
+	}
+
+	findings, err := parse(stdout.Bytes())
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ImageResult{
+		Service:  serviceName,
+		Image:    image,
+		Scanner:  scanner,
+		Findings: findings,
+		Counts:   CountBySeverity(findings),
+	}
+
+	reportPath := filepath.Join(outDir, serviceName+"-image.json")
+	if err := os.WriteFile(reportPath, stdout.Bytes(), 0600); err == nil {
+		result.Report = reportPath
+	}
+
+	return result, nil
+}
+
+// CountBySeverity returns the number of findings of each severity.
+func CountBySeverity(findings []Finding) map[string]int {
+	counts := make(map[string]int, len(Severities))
+	for _, s := range Severities {
+		counts[s] = 0
+	}
+	for _, f := range findings {
+		counts[f.Severity]++
+	}
+	return counts
+}
+
+// trivyReport is the subset of the Trivy JSON report that we read.
+type trivyReport struct {
+	Results []struct {
+		Vulnerabilities []struct {
+			VulnerabilityID  string `json:"VulnerabilityID"`
+			PkgName          string `json:"PkgName"`
+			InstalledVersion string `json:"InstalledVersion"`
+			FixedVersion     string `json:"FixedVersion"`
+			Severity         string `json:"Severity"`
+			Title            string `json:"Title"`
+		} `json:"Vulnerabilities"`
+	} `json:"Results"`
+}
+
+// ParseTrivyReport extracts the findings of a Trivy JSON report, sorted by SortFindings.
+func ParseTrivyReport(data []byte) ([]Finding, error) {
+	var report trivyReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("failed to parse trivy report: %w", err)
+	}
+	findings := []Finding{}
+	for _, result := range report.Results {
+		for _, v := range result.Vulnerabilities {
+			findings = append(findings, Finding{
+				ID:           v.VulnerabilityID,
+				Package:      v.PkgName,
+				Version:      v.InstalledVersion,
+				FixedVersion: v.FixedVersion,
+				Severity:     normalizeSeverity(v.Severity),
+				Title:        v.Title,
+			})
+		}
+	}
+	SortFindings(findings)
+	return findings, nil
+}
+
+// grypeReport is the subset of the Grype JSON report that we read.
+type grypeReport struct {
+	Matches []struct {
+		Vulnerability struct {
+			ID          string `json:"id"`
+			Severity    string `json:"severity"`
+			Description string `json:"description"`
+			Fix         struct {
+				Versions []string `json:"versions"`
+			} `json:"fix"`
+		} `json:"vulnerability"`
+		Artifact struct {
+			Name    string `json:"name"`
+			Version string `json:"version"`
+		} `json:"artifact"`
+	} `json:"matches"`
+}
+
+// ParseGrypeReport extracts the findings of a Grype JSON report, sorted by SortFindings.
+func ParseGrypeReport(data []byte) ([]Finding, error) {
+	var report grypeReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("failed to parse grype report: %w", err)
+	}
+	findings := []Finding{}
+	for _, m := range report.Matches {
+		findings = append(findings, Finding{
+			ID:           m.Vulnerability.ID,
+			Package:      m.Artifact.Name,
+			Version:      m.Artifact.Version,
+			FixedVersion: strings.Join(m.Vulnerability.Fix.Versions, ", "),
+			Severity:     normalizeSeverity(m.Vulnerability.Severity),
+			Title:        m.Vulnerability.Description,
+		})
+	}
+	SortFindings(findings)
+	return findings, nil
+}
+
+// normalizeSeverity maps the severity of a scanner to one of Severities. Grype calls the
+// least severe vulnerabilities negligible.
+func normalizeSeverity(severity string) string {
+	severity = strings.ToUpper(severity)
+	if severity == "NEGLIGIBLE" {
+		return SeverityLow
+	}
+	if severityRank(severity) < 0 {
+		return SeverityUnknown
+	}
+	return severity
+}
+
+// SortFindings sorts findings by severity, most severe first, then by ID and package.
+func SortFindings(findings []Finding) {
+	sort.SliceStable(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		if ra, rb := severityRank(a.Severity), severityRank(b.Severity); ra != rb {
+			return ra > rb
+		}
+		if a.ID != b.ID {
+			return a.ID < b.ID
+		}
+		return a.Package < b.Package
+	})
+}
@@ -0,0 +1,98 @@
+package audit
+
+import (
+	"testing"
+)
+
+func TestParseTrivyReport(t *testing.T) {
+	data := []byte(`{
+		"Results": [
+			{"Target": "alpine 3.19", "Vulnerabilities": [
+				{"VulnerabilityID": "CVE-2024-0002", "PkgName": "openssl", "InstalledVersion": "3.1.4", "FixedVersion": "3.1.5", "Severity": "MEDIUM", "Title": "openssl: crash"},
+				{"VulnerabilityID": "CVE-2024-0001", "PkgName": "busybox", "InstalledVersion": "1.36.1", "Severity": "CRITICAL"}
+			]},
+			{"Target": "app/package-lock.json"}
+		]
+	}`)
+
+	findings, err := ParseTrivyReport(data)
+	if err != nil {
+		t.Fatalf("ParseTrivyReport() error = %v", err)
+	}
+	expected := []Finding{
+		{ID: "CVE-2024-0001", Package: "busybox", Version: "1.36.1", Severity: SeverityCritical},
+		{ID: "CVE-2024-0002", Package: "openssl", Version: "3.1.4", FixedVersion: "3.1.5", Severity: SeverityMedium, Title: "openssl: crash"},
+	}
+	if len(findings) != len(expected) {
+		t.Fatalf("ParseTrivyReport() returned %d findings, want %d", len(findings), len(expected))
+	}
+	for i, want := range expected {
+		if findings[i] != want {
+			t.Errorf("findings[%d] = %+v, want %+v", i, findings[i], want)
+		}
+	}
+}
+
+func TestParseGrypeReport(t *testing.T) {
+	data := []byte(`{
+		"matches": [
+			{"vulnerability": {"id": "GHSA-1", "severity": "Negligible", "fix": {"versions": []}}, "artifact": {"name": "zlib", "version": "1.3"}},
+			{"vulnerability": {"id": "CVE-2024-0003", "severity": "High", "fix": {"versions": ["2.0.1"]}}, "artifact": {"name": "express", "version": "2.0.0"}}
+		]
+	}`)
+
+	findings, err := ParseGrypeReport(data)
+	if err != nil {
+		t.Fatalf("ParseGrypeReport() error = %v", err)
+	}
+	if len(findings) != 2 || findings[0].ID != "CVE-2024-0003" || findings[0].Severity != SeverityHigh || findings[0].FixedVersion != "2.0.1" {
+		t.Fatalf("ParseGrypeReport() = %+v", findings)
+	}
+	if findings[1].Severity != SeverityLow {
+		t.Errorf("negligible severity = %s, want %s", findings[1].Severity, SeverityLow)
+	}
+
+	if _, err := ParseGrypeReport([]byte("not json")); err == nil {
+		t.Error("expected error for invalid report")
+	}
+}
+
+func TestImageResultFailing(t *testing.T) {
+	findings := []Finding{
+		{ID: "a", Severity: SeverityCritical},
+		{ID: "b", Severity: SeverityHigh},
+		{ID: "c", Severity: SeverityMedium},
+		{ID: "d", Severity: SeverityUnknown},
+	}
+	tests := []struct {
+		threshold string
+		want      int
+	}{
+		{threshold: SeverityCritical, want: 1},
+		{threshold: SeverityHigh, want: 2},
+		{threshold: SeverityLow, want: 3},
+	}
+	for _, tt := range tests {
+		result := ImageResult{Findings: findings, Threshold: tt.threshold}
+		if got := len(result.Failing()); got != tt.want {
+			t.Errorf("Failing() at %s = %d findings, want %d", tt.threshold, got, tt.want)
+		}
+		if result.Passed() != (tt.want == 0) {
+			t.Errorf("Passed() at %s = %v", tt.threshold, result.Passed())
+		}
+	}
+
+	counts := CountBySeverity(findings)
+	if counts[SeverityCritical] != 1 || counts[SeverityLow] != 0 || counts[SeverityUnknown] != 1 {
+		t.Errorf("CountBySeverity() = %v", counts)
+	}
+}
+
+func TestParseSeverity(t *testing.T) {
+	if got, err := ParseSeverity(" High "); err != nil || got != SeverityHigh {
+		t.Errorf("ParseSeverity(High) = %q, %v", got, err)
+	}
+	if _, err := ParseSeverity("severe"); err == nil {
+		t.Error("expected error for an unknown severity")
+	}
+}
@@ -0,0 +1,169 @@
+// Package autostart registers a workspace's dev stack to start at login, using the service
+// manager of the platform: launchd on macOS, systemd user units on Linux, and Task Scheduler
+// on Windows.
+package autostart
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+
+	"github.com/jongio/azd-app/cli/src/internal/statedir"
+)
+
+// Service managers.
+const (
+	ManagerLaunchd  = "launchd"
+	ManagerSystemd  = "systemd"
+	ManagerSchtasks = "schtasks"
+)
+
+// namePrefix starts the name of every autostart service, so they are easy to find.
+const namePrefix = "azd-app-"
+
+// logFileName is the file in the workspace state directory that receives the service output.
+const logFileName = "autostart.log"
+
+// Service is a command that runs in a workspace whenever the user logs in.
+type Service struct {
+	Name      string            // Unique name derived from the workspace, e.g. "azd-app-shop-1a2b3c"
+	Workspace string            // Directory the command runs in
+	Command   string            // Absolute path of the executable
+	Args      []string          // Arguments of the executable
+	LogFile   string            // File that receives stdout and stderr
+	Env       map[string]string // Environment variables set for the command
+}
+
+// Status describes whether a workspace's autostart service is installed and running.
+type Status struct {
+	Name      string `json:"name"`
+	Manager   string `json:"manager"`
+	Installed bool   `json:"installed"`
+	Running   bool   `json:"running"`
+	Path      string `json:"path,omitempty"`    // Unit, plist, or script file of the service
+	LogFile   string `json:"logFile,omitempty"` // File that receives the service output
+}
+
+// manager installs services with one platform's service manager.
+type manager interface {
+	name() string
+	install(svc Service) error
+	uninstall(name string) error
+	status(name string) (Status, error)
+}
+
+// runCommand runs a service manager command and returns its combined output.
+var runCommand = func(name string, args ...string) ([]byte, error) {
+	// #nosec G204 -- name is a fixed service manager executable
+	return exec.Command(name, args...).CombinedOutput()
+}
+
+// ServiceName returns the autostart service name of a workspace.
+func ServiceName(workspace string) (string, error) {
+	key, err := statedir.WorkspaceKey(workspace)
+	if err != nil {
+		return "", err
+	}
+	return namePrefix + key, nil
+}
+
+// LogFile returns the file that receives the output of a workspace's autostart service.
+func LogFile(workspace string) (string, error) {
+	wsDir, err := statedir.WorkspaceDir(workspace)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(wsDir, logFileName), nil
+}
+
+// NewService returns a service that runs `azd app` with args in workspace. Its output goes to
+// autostart.log in the workspace state directory, and it keeps the current PATH so that the
+// tools the stack needs are found outside a login shell.
+func NewService(workspace string, args []string) (Service, error) {
+	workspace, err := filepath.Abs(workspace)
+	if err != nil {
+		return Service{}, err
+	}
+	name, err := ServiceName(workspace)
+	if err != nil {
+		return Service{}, err
+	}
+	azd, err := exec.LookPath("azd")
+	if err != nil {
+		return Service{}, fmt.Errorf("azd was not found on PATH: %w", err)
+	}
+	if azd, err = filepath.Abs(azd); err != nil {
+		return Service{}, err
+	}
+	logFile, err := LogFile(workspace)
+	if err != nil {
+		return Service{}, err
+	}
+
+	return Service{
+		Name:      name,
+		Workspace: workspace,
+		Command:   azd,
+		Args:      append([]string{"app"}, args...),
+		LogFile:   logFile,
+		Env:       map[string]string{"PATH": os.Getenv("PATH")},
+	}, nil
+}
+
+// Install registers svc to start at login and starts it now.
+// Installing a service that is already installed replaces it.
+func Install(svc Service) (Status, error) {
+	m, err := currentManager()
+	if err != nil {
+		return Status{}, err
+	}
+	if err := os.MkdirAll(filepath.Dir(svc.LogFile), 0750); err != nil {
+		return Status{}, fmt.Errorf("failed to create log directory: %w", err)
+	}
+	if err := m.install(svc); err != nil {
+		return Status{}, err
+	}
+	status, err := m.status(svc.Name)
+	status.LogFile = svc.LogFile
+	return status, err
+}
+
+// Uninstall stops the service called name and removes it from the service manager.
+// Uninstalling a service that is not installed is not an error.
+func Uninstall(name string) error {
+	m, err := currentManager()
+	if err != nil {
+		return err
+	}
+	return m.uninstall(name)
+}
+
+// GetStatus reports whether the service called name is installed and running.
+func GetStatus(name string) (Status, error) {
+	m, err := currentManager()
+	if err != nil {
+		return Status{}, err
+	}
+	return m.status(name)
+}
+
+// currentManager returns the service manager of the running platform.
+func currentManager() (manager, error) {
+	return managerFor(runtime.GOOS)
+}
+
+// managerFor returns the service manager used on goos.
+func managerFor(goos string) (manager, error) {
+	switch goos {
+	case "darwin":
+		return launchdManager{}, nil
+	case "linux":
+		return systemdManager{}, nil
+	case "windows":
+		return schtasksManager{}, nil
+	default:
+		return nil, fmt.Errorf("starting at login is not supported on %s", goos)
+	}
+}
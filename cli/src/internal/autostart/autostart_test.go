@@ -0,0 +1,215 @@
+package autostart
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jongio/azd-app/cli/src/internal/statedir"
+)
+
+// fakeCommands replaces runCommand for the duration of a test and records every call.
+// respond returns the output and error of a call.
+func fakeCommands(t *testing.T, respond func(call string) (string, error)) *[]string {
+	t.Helper()
+	var calls []string
+	original := runCommand
+	runCommand = func(name string, args ...string) ([]byte, error) {
+		call := strings.Join(append([]string{name}, args...), " ")
+		calls = append(calls, call)
+		out, err := respond(call)
+		return []byte(out), err
+	}
+	t.Cleanup(func() { runCommand = original })
+	return &calls
+}
+
+func testService(t *testing.T) Service {
+	t.Helper()
+	workspace := filepath.Join(t.TempDir(), "shop 50%")
+	return Service{
+		Name:      "azd-app-shop-123456",
+		Workspace: workspace,
+		Command:   "/usr/local/bin/azd",
+		Args:      []string{"app", "run", "--service", "web,api"},
+		LogFile:   filepath.Join(t.TempDir(), "state", logFileName),
+		Env:       map[string]string{"PATH": "/usr/local/bin:/usr/bin"},
+	}
+}
+
+func TestServiceName(t *testing.T) {
+	t.Setenv(statedir.RootEnvVar, t.TempDir())
+	workspace := filepath.Join(t.TempDir(), "my shop")
+
+	name, err := ServiceName(workspace)
+	if err != nil {
+		t.Fatalf("ServiceName failed: %v", err)
+	}
+	key, _ := statedir.WorkspaceKey(workspace)
+	if name != namePrefix+key {
+		t.Errorf("ServiceName() = %q, want %q", name, namePrefix+key)
+	}
+}
+
+func TestManagerFor(t *testing.T) {
+	tests := []struct {
+		goos    string
+		want    string
+		wantErr bool
+	}{
+		{"darwin", ManagerLaunchd, false},
+		{"linux", ManagerSystemd, false},
+		{"windows", ManagerSchtasks, false},
+		{"plan9", "", true},
+	}
+	for _, tt := range tests {
+		m, err := managerFor(tt.goos)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("managerFor(%s) error = %v, wantErr %v", tt.goos, err, tt.wantErr)
+			continue
+		}
+		if err == nil && m.name() != tt.want {
+			t.Errorf("managerFor(%s) = %s, want %s", tt.goos, m.name(), tt.want)
+		}
+	}
+}
+
+func TestSystemdUnit(t *testing.T) {
+	svc := testService(t)
+	unit := systemdUnit(svc)
+
+	for _, want := range []string{
+		`WorkingDirectory="` + strings.ReplaceAll(svc.Workspace, "%", "%%") + `"`,
+		`ExecStart="/usr/local/bin/azd" "app" "run" "--service" "web,api"`,
+		`Environment="PATH=/usr/local/bin:/usr/bin"`,
+		"StandardOutput=append:" + svc.LogFile,
+		"Restart=on-failure",
+		"WantedBy=default.target",
+	} {
+		if !strings.Contains(unit, want) {
+			t.Errorf("unit is missing %q:\n%s", want, unit)
+		}
+	}
+}
+
+func TestSystemdInstallAndUninstall(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	active := false
+	calls := fakeCommands(t, func(call string) (string, error) {
+		if strings.Contains(call, "is-active") && active {
+			return "active\n", nil
+		}
+		return "", nil
+	})
+	svc := testService(t)
+	m := systemdManager{}
+
+	if err := m.install(svc); err != nil {
+		t.Fatalf("install failed: %v", err)
+	}
+	active = true
+	status, err := m.status(svc.Name)
+	if err != nil {
+		t.Fatalf("status failed: %v", err)
+	}
+	if !status.Installed || !status.Running || filepath.Base(status.Path) != svc.Name+".service" {
+		t.Errorf("status after install = %+v, want installed and running", status)
+	}
+	wantCalls := []string{
+		"systemctl --user daemon-reload",
+		"systemctl --user enable " + svc.Name + ".service",
+		"systemctl --user restart " + svc.Name + ".service",
+	}
+	for i, want := range wantCalls {
+		if i >= len(*calls) || (*calls)[i] != want {
+			t.Fatalf("calls = %v, want them to start with %v", *calls, wantCalls)
+		}
+	}
+
+	if err := m.uninstall(svc.Name); err != nil {
+		t.Fatalf("uninstall failed: %v", err)
+	}
+	if _, err := os.Stat(status.Path); !os.IsNotExist(err) {
+		t.Error("unit file was not removed")
+	}
+	status, _ = m.status(svc.Name)
+	if status.Installed {
+		t.Error("service is still installed after uninstall")
+	}
+	if err := m.uninstall(svc.Name); err != nil {
+		t.Errorf("uninstalling a missing service failed: %v", err)
+	}
+}
+
+func TestLaunchdPlist(t *testing.T) {
+	svc := testService(t)
+	svc.Args = append(svc.Args, "--env-file", "a&b.env")
+	plist := launchdPlist(svc)
+
+	for _, want := range []string{
+		"<key>Label</key>\n\t<string>" + svc.Name + "</string>",
+		"<string>/usr/local/bin/azd</string>",
+		"<string>a&amp;b.env</string>",
+		"<key>PATH</key>\n\t\t<string>/usr/local/bin:/usr/bin</string>",
+		"<key>RunAtLoad</key>\n\t<true/>",
+		"<key>SuccessfulExit</key>\n\t\t<false/>",
+	} {
+		if !strings.Contains(plist, want) {
+			t.Errorf("plist is missing %q:\n%s", want, plist)
+		}
+	}
+}
+
+func TestLaunchdInstall(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	calls := fakeCommands(t, func(call string) (string, error) {
+		if strings.HasPrefix(call, "launchctl print") {
+			return "state = running\n", nil
+		}
+		return "", nil
+	})
+	svc := testService(t)
+	m := launchdManager{}
+
+	if err := m.install(svc); err != nil {
+		t.Fatalf("install failed: %v", err)
+	}
+	status, err := m.status(svc.Name)
+	if err != nil || !status.Installed || !status.Running {
+		t.Errorf("status after install = %+v, %v; want installed and running", status, err)
+	}
+	if !strings.Contains(strings.Join(*calls, "\n"), "launchctl bootstrap "+launchdDomain()+" "+status.Path) {
+		t.Errorf("agent was not bootstrapped: %v", *calls)
+	}
+}
+
+func TestSchtasksScript(t *testing.T) {
+	svc := testService(t)
+	script := schtasksScript(svc)
+
+	for _, want := range []string{
+		`set "PATH=/usr/local/bin:/usr/bin"`,
+		`cd /d "` + svc.Workspace + `"`,
+		`"/usr/local/bin/azd" "app" "run" "--service" "web,api" >> "` + svc.LogFile + `" 2>&1`,
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("script is missing %q:\n%s", want, script)
+		}
+	}
+}
+
+func TestSchtasksStatus(t *testing.T) {
+	fakeCommands(t, func(call string) (string, error) {
+		return "TaskName:      \\azd-app\\azd-app-shop-123456\nStatus:        Running\n", nil
+	})
+
+	status, err := schtasksManager{}.status("azd-app-shop-123456")
+	if err != nil {
+		t.Fatalf("status failed: %v", err)
+	}
+	if !status.Installed || !status.Running {
+		t.Errorf("status = %+v, want installed and running", status)
+	}
+}
@@ -0,0 +1,142 @@
+package autostart
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// launchdManager installs launch agents, which start when the user logs in.
+type launchdManager struct{}
+
+func (launchdManager) name() string {
+	return ManagerLaunchd
+}
+
+func (m launchdManager) install(svc Service) error {
+	path, err := launchAgentPath(svc.Name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return fmt.Errorf("failed to create LaunchAgents directory: %w", err)
+	}
+
+	// An agent that is already loaded must be unloaded before it can be replaced
+	_, _ = runCommand("launchctl", "bootout", launchdTarget(svc.Name))
+	if err := os.WriteFile(path, []byte(launchdPlist(svc)), 0600); err != nil {
+		return fmt.Errorf("failed to write launch agent: %w", err)
+	}
+	if out, err := runCommand("launchctl", "bootstrap", launchdDomain(), path); err != nil {
+		return fmt.Errorf("launchctl bootstrap failed: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+func (m launchdManager) uninstall(name string) error {
+	path, err := launchAgentPath(name)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil
+	}
+
+	// The agent may not be loaded
+	_, _ = runCommand("launchctl", "bootout", launchdTarget(name))
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("failed to remove launch agent: %w", err)
+	}
+	return nil
+}
+
+func (m launchdManager) status(name string) (Status, error) {
+	status := Status{Name: name, Manager: m.name()}
+	path, err := launchAgentPath(name)
+	if err != nil {
+		return status, err
+	}
+	if _, err := os.Stat(path); err != nil {
+		return status, nil
+	}
+	status.Installed = true
+	status.Path = path
+
+	out, err := runCommand("launchctl", "print", launchdTarget(name))
+	status.Running = err == nil && strings.Contains(string(out), "state = running")
+	return status, nil
+}
+
+// launchAgentPath returns the path of the launch agent plist for a service.
+func launchAgentPath(name string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate home directory: %w", err)
+	}
+	return filepath.Join(home, "Library", "LaunchAgents", name+".plist"), nil
+}
+
+// launchdDomain returns the launchd domain of the user's GUI session.
+func launchdDomain() string {
+	return "gui/" + strconv.Itoa(os.Getuid())
+}
+
+// launchdTarget returns the launchd service target of a service.
+func launchdTarget(name string) string {
+	return launchdDomain() + "/" + name
+}
+
+// launchdPlist returns the launch agent property list of a service. The agent starts at
+// login and is restarted when it exits with an error.
+func launchdPlist(svc Service) string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+`)
+	writePlistString(&b, "Label", svc.Name)
+	b.WriteString("\t<key>ProgramArguments</key>\n\t<array>\n")
+	for _, arg := range append([]string{svc.Command}, svc.Args...) {
+		fmt.Fprintf(&b, "\t\t<string>%s</string>\n", xmlEscape(arg))
+	}
+	b.WriteString("\t</array>\n")
+	writePlistString(&b, "WorkingDirectory", svc.Workspace)
+
+	if len(svc.Env) > 0 {
+		keys := make([]string, 0, len(svc.Env))
+		for key := range svc.Env {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		b.WriteString("\t<key>EnvironmentVariables</key>\n\t<dict>\n")
+		for _, key := range keys {
+			fmt.Fprintf(&b, "\t\t<key>%s</key>\n\t\t<string>%s</string>\n", xmlEscape(key), xmlEscape(svc.Env[key]))
+		}
+		b.WriteString("\t</dict>\n")
+	}
+
+	writePlistString(&b, "StandardOutPath", svc.LogFile)
+	writePlistString(&b, "StandardErrorPath", svc.LogFile)
+	b.WriteString("\t<key>RunAtLoad</key>\n\t<true/>\n")
+	b.WriteString("\t<key>KeepAlive</key>\n\t<dict>\n\t\t<key>SuccessfulExit</key>\n\t\t<false/>\n\t</dict>\n")
+	b.WriteString("</dict>\n</plist>\n")
+	return b.String()
+}
+
+// writePlistString writes a string entry of a property list dictionary.
+func writePlistString(b *strings.Builder, key, value string) {
+	fmt.Fprintf(b, "\t<key>%s</key>\n\t<string>%s</string>\n", key, xmlEscape(value))
+}
+
+// xmlEscape escapes a value for XML character data.
+func xmlEscape(value string) string {
+	var buf bytes.Buffer
+	_ = xml.EscapeText(&buf, []byte(value))
+	return buf.String()
+}
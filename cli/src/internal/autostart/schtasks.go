@@ -0,0 +1,99 @@
+package autostart
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// taskFolder is the Task Scheduler folder that holds autostart tasks.
+const taskFolder = `azd-app\`
+
+// scriptFileName is the script in the workspace state directory that the task runs.
+// Task Scheduler actions can't set a working directory or redirect output, so a script does.
+const scriptFileName = "autostart.cmd"
+
+// schtasksManager installs Task Scheduler tasks that run when the user logs on.
+type schtasksManager struct{}
+
+func (schtasksManager) name() string {
+	return ManagerSchtasks
+}
+
+func (m schtasksManager) install(svc Service) error {
+	path := filepath.Join(filepath.Dir(svc.LogFile), scriptFileName)
+	if err := os.WriteFile(path, []byte(schtasksScript(svc)), 0600); err != nil {
+		return fmt.Errorf("failed to write autostart script: %w", err)
+	}
+
+	task := taskFolder + svc.Name
+	if out, err := runCommand("schtasks", "/Create", "/TN", task, "/TR", `"`+path+`"`, "/SC", "ONLOGON", "/RL", "LIMITED", "/F"); err != nil {
+		return fmt.Errorf("schtasks /Create failed: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	// Replace a running instance so the new script takes effect
+	_, _ = runCommand("schtasks", "/End", "/TN", task)
+	if out, err := runCommand("schtasks", "/Run", "/TN", task); err != nil {
+		return fmt.Errorf("schtasks /Run failed: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+func (m schtasksManager) uninstall(name string) error {
+	task := taskFolder + name
+	if _, err := runCommand("schtasks", "/Query", "/TN", task); err != nil {
+		return nil
+	}
+
+	// The task may not be running
+	_, _ = runCommand("schtasks", "/End", "/TN", task)
+	if out, err := runCommand("schtasks", "/Delete", "/TN", task, "/F"); err != nil {
+		return fmt.Errorf("schtasks /Delete failed: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+func (m schtasksManager) status(name string) (Status, error) {
+	status := Status{Name: name, Manager: m.name()}
+	out, err := runCommand("schtasks", "/Query", "/TN", taskFolder+name, "/FO", "LIST")
+	if err != nil {
+		return status, nil
+	}
+	status.Installed = true
+	status.Path = taskFolder + name
+
+	for _, line := range strings.Split(string(out), "\n") {
+		if key, value, ok := strings.Cut(line, ":"); ok && strings.TrimSpace(key) == "Status" {
+			status.Running = strings.EqualFold(strings.TrimSpace(value), "Running")
+		}
+	}
+	return status, nil
+}
+
+// schtasksScript returns the batch script that runs a service in its workspace.
+func schtasksScript(svc Service) string {
+	var b strings.Builder
+	b.WriteString("@echo off\r\n")
+	keys := make([]string, 0, len(svc.Env))
+	for key := range svc.Env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(&b, "set \"%s=%s\"\r\n", key, batchEscape(svc.Env[key]))
+	}
+	fmt.Fprintf(&b, "cd /d \"%s\"\r\n", svc.Workspace)
+
+	words := []string{`"` + svc.Command + `"`}
+	for _, arg := range svc.Args {
+		words = append(words, `"`+batchEscape(arg)+`"`)
+	}
+	fmt.Fprintf(&b, "%s >> \"%s\" 2>&1\r\n", strings.Join(words, " "), svc.LogFile)
+	return b.String()
+}
+
+// batchEscape escapes % so a value is not expanded as a variable in a batch script.
+func batchEscape(value string) string {
+	return strings.ReplaceAll(value, "%", "%%")
+}
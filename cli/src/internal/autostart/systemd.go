@@ -0,0 +1,140 @@
+package autostart
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// systemdManager installs systemd user units, which start when the user's session starts.
+type systemdManager struct{}
+
+func (systemdManager) name() string {
+	return ManagerSystemd
+}
+
+func (m systemdManager) install(svc Service) error {
+	path, err := systemdUnitPath(svc.Name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return fmt.Errorf("failed to create systemd user directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(systemdUnit(svc)), 0600); err != nil {
+		return fmt.Errorf("failed to write systemd unit: %w", err)
+	}
+
+	unit := svc.Name + ".service"
+	if err := systemctl("daemon-reload"); err != nil {
+		return err
+	}
+	// restart applies a changed unit when the service was already running
+	if err := systemctl("enable", unit); err != nil {
+		return err
+	}
+	if err := systemctl("restart", unit); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (m systemdManager) uninstall(name string) error {
+	path, err := systemdUnitPath(name)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil
+	}
+
+	// The unit may already be stopped or disabled
+	_ = systemctl("disable", "--now", name+".service")
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("failed to remove systemd unit: %w", err)
+	}
+	return systemctl("daemon-reload")
+}
+
+func (m systemdManager) status(name string) (Status, error) {
+	status := Status{Name: name, Manager: m.name()}
+	path, err := systemdUnitPath(name)
+	if err != nil {
+		return status, err
+	}
+	if _, err := os.Stat(path); err != nil {
+		return status, nil
+	}
+	status.Installed = true
+	status.Path = path
+
+	// is-active exits with an error for inactive units, so only the output matters
+	out, _ := runCommand("systemctl", "--user", "is-active", name+".service")
+	status.Running = strings.TrimSpace(string(out)) == "active"
+	return status, nil
+}
+
+// systemdUnitPath returns the path of the user unit file for a service.
+func systemdUnitPath(name string) (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate user config directory: %w", err)
+	}
+	return filepath.Join(configDir, "systemd", "user", name+".service"), nil
+}
+
+// systemdUnit returns the unit file of a service. Failed runs are restarted after a delay.
+func systemdUnit(svc Service) string {
+	var b strings.Builder
+	b.WriteString("[Unit]\n")
+	fmt.Fprintf(&b, "Description=azd app for %s\n", systemdEscape(svc.Workspace))
+	b.WriteString("\n[Service]\n")
+	b.WriteString("Type=simple\n")
+	fmt.Fprintf(&b, "WorkingDirectory=%s\n", systemdQuote(svc.Workspace))
+
+	words := []string{systemdQuote(svc.Command)}
+	for _, arg := range svc.Args {
+		words = append(words, systemdQuote(arg))
+	}
+	fmt.Fprintf(&b, "ExecStart=%s\n", strings.Join(words, " "))
+
+	keys := make([]string, 0, len(svc.Env))
+	for key := range svc.Env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(&b, "Environment=%s\n", systemdQuote(key+"="+svc.Env[key]))
+	}
+
+	fmt.Fprintf(&b, "StandardOutput=append:%s\n", systemdEscape(svc.LogFile))
+	fmt.Fprintf(&b, "StandardError=append:%s\n", systemdEscape(svc.LogFile))
+	b.WriteString("Restart=on-failure\n")
+	b.WriteString("RestartSec=10\n")
+	b.WriteString("\n[Install]\n")
+	b.WriteString("WantedBy=default.target\n")
+	return b.String()
+}
+
+// systemdQuote quotes a value for a systemd unit file, escaping specifiers and quotes.
+func systemdQuote(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `"`, `\"`)
+	return `"` + systemdEscape(value) + `"`
+}
+
+// systemdEscape escapes % so it is not expanded as a unit specifier.
+func systemdEscape(value string) string {
+	return strings.ReplaceAll(value, "%", "%%")
+}
+
+// systemctl runs a systemctl --user command.
+func systemctl(args ...string) error {
+	out, err := runCommand("systemctl", append([]string{"--user"}, args...)...)
+	if err != nil {
+		return fmt.Errorf("systemctl --user %s failed: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
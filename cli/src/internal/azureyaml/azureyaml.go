@@ -0,0 +1,226 @@
+// Package azureyaml reads, validates, and writes azure.yaml, the project file of the Azure
+// Developer CLI, including the sections azd app adds to it (reqs and metadata.detector).
+//
+// Project is a typed model of the file. Keys the model does not cover are kept in the Extra
+// fields, so nothing is lost when a project is written back. A Document keeps the parsed YAML
+// tree next to its Project: Marshal merges the model into that tree, so comments, key order,
+// and quoting survive for everything that did not change.
+package azureyaml
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/jongio/azd-app/cli/src/internal/security"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileName is the name of the project file.
+const FileName = "azure.yaml"
+
+// Document is a parsed azure.yaml.
+type Document struct {
+	Path    string   // Optional: file the document was loaded from, and is saved to
+	Project *Project // Typed model; change it and call Marshal or Save to write the changes
+	root    *yaml.Node
+}
+
+// Load reads and parses the azure.yaml at path. The paths of its services must lead inside the
+// directory of path (see Project.ValidatePaths).
+func Load(path string) (*Document, error) {
+	if err := security.ValidatePath(path); err != nil {
+		return nil, fmt.Errorf("invalid azure.yaml path: %w", err)
+	}
+	// #nosec G304 -- Path validated by security.ValidatePath
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read azure.yaml: %w", err)
+	}
+	doc, err := Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	if err := doc.Project.ValidatePaths(filepath.Dir(path)); err != nil {
+		return nil, err
+	}
+	doc.Path = path
+	return doc, nil
+}
+
+// Parse parses azure.yaml content. Syntax errors and values of the wrong type are errors; use
+// Validate to check the content against the azd schema.
+func Parse(data []byte) (*Document, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse azure.yaml: %w", err)
+	}
+
+	project := &Project{}
+	if root.Kind == yaml.DocumentNode && len(root.Content) > 0 {
+		if err := root.Content[0].Decode(project); err != nil {
+			return nil, fmt.Errorf("failed to parse azure.yaml: %w", err)
+		}
+	}
+	return &Document{Project: project, root: &root}, nil
+}
+
+// Validate checks the document against the azd schema.
+func (d *Document) Validate() []Issue {
+	if d.root == nil || d.root.Kind != yaml.DocumentNode || len(d.root.Content) == 0 {
+		return []Issue{{Severity: SeverityError, Message: "azure.yaml is empty"}}
+	}
+	return validate(d.root.Content[0])
+}
+
+// Marshal returns the document with the changes made to Project. Unchanged keys keep their
+// comments, order, and style.
+func (d *Document) Marshal() ([]byte, error) {
+	var updated yaml.Node
+	if err := updated.Encode(d.Project); err != nil {
+		return nil, fmt.Errorf("failed to encode azure.yaml: %w", err)
+	}
+
+	if d.root == nil || d.root.Kind != yaml.DocumentNode || len(d.root.Content) == 0 {
+		d.root = &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{&updated}}
+	} else {
+		merge(d.root.Content[0], &updated)
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(d.root); err != nil {
+		return nil, fmt.Errorf("failed to encode azure.yaml: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encode azure.yaml: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Save writes the document to Path.
+func (d *Document) Save() error {
+	if d.Path == "" {
+		return fmt.Errorf("azure.yaml document has no path")
+	}
+	data, err := d.Marshal()
+	if err != nil {
+		return err
+	}
+	if err := security.ValidatePath(d.Path); err != nil {
+		return fmt.Errorf("invalid azure.yaml path: %w", err)
+	}
+	// #nosec G306 -- azure.yaml is a config file, 0644 is appropriate for team access
+	if err := os.WriteFile(d.Path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write azure.yaml: %w", err)
+	}
+	return nil
+}
+
+// merge updates dst, a node of the original document, to the content of src, the same node
+// encoded from the model. Nodes whose content did not change are left alone.
+func merge(dst, src *yaml.Node) {
+	if dst.Kind == yaml.AliasNode && dst.Alias != nil && equalNodes(dst.Alias, src) {
+		return
+	}
+	if dst.Kind != src.Kind {
+		replaceNode(dst, src)
+		return
+	}
+
+	switch dst.Kind {
+	case yaml.ScalarNode:
+		if dst.Value != src.Value || dst.ShortTag() != src.ShortTag() {
+			dst.Value = src.Value
+			dst.Tag = src.Tag
+			dst.Style = src.Style
+		}
+
+	case yaml.SequenceNode:
+		for i, item := range src.Content {
+			if i < len(dst.Content) {
+				merge(dst.Content[i], item)
+			} else {
+				dst.Content = append(dst.Content, item)
+			}
+		}
+		if len(dst.Content) > len(src.Content) {
+			dst.Content = dst.Content[:len(src.Content)]
+		}
+
+	case yaml.MappingNode:
+		values := make(map[string]*yaml.Node, len(src.Content)/2)
+		for i := 0; i+1 < len(src.Content); i += 2 {
+			values[src.Content[i].Value] = src.Content[i+1]
+		}
+		kept := make(map[string]bool, len(values))
+		content := make([]*yaml.Node, 0, len(src.Content))
+		for i := 0; i+1 < len(dst.Content); i += 2 {
+			key, value := dst.Content[i], dst.Content[i+1]
+			if updated, ok := values[key.Value]; ok {
+				merge(value, updated)
+			} else if !isEmptyNode(value) {
+				// The model omits empty values, so only keys that had content were removed
+				continue
+			}
+			content = append(content, key, value)
+			kept[key.Value] = true
+		}
+		for i := 0; i+1 < len(src.Content); i += 2 {
+			if !kept[src.Content[i].Value] {
+				content = append(content, src.Content[i], src.Content[i+1])
+			}
+		}
+		dst.Content = content
+	}
+}
+
+// replaceNode replaces the content of dst with src, keeping the comments of dst.
+func replaceNode(dst, src *yaml.Node) {
+	head, line, foot := dst.HeadComment, dst.LineComment, dst.FootComment
+	*dst = *src
+	dst.HeadComment, dst.LineComment, dst.FootComment = head, line, foot
+}
+
+// isEmptyNode reports whether n holds a value that the model encodes by omitting the key: null,
+// false, zero, an empty string, or an empty collection.
+func isEmptyNode(n *yaml.Node) bool {
+	switch n.Kind {
+	case yaml.ScalarNode:
+		switch n.ShortTag() {
+		case "!!null":
+			return true
+		case "!!bool":
+			return n.Value == "false"
+		case "!!int", "!!float":
+			return n.Value == "0"
+		case "!!str":
+			return n.Value == ""
+		}
+	case yaml.SequenceNode, yaml.MappingNode:
+		return len(n.Content) == 0
+	}
+	return false
+}
+
+// equalNodes reports whether two nodes have the same content, ignoring style and comments.
+func equalNodes(a, b *yaml.Node) bool {
+	if a.Kind == yaml.AliasNode && a.Alias != nil {
+		return equalNodes(a.Alias, b)
+	}
+	if a.Kind != b.Kind || len(a.Content) != len(b.Content) {
+		return false
+	}
+	if a.Kind == yaml.ScalarNode {
+		return a.Value == b.Value && a.ShortTag() == b.ShortTag()
+	}
+	for i := range a.Content {
+		if !equalNodes(a.Content[i], b.Content[i]) {
+			return false
+		}
+	}
+	return true
+}
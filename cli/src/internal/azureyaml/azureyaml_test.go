@@ -0,0 +1,369 @@
+package azureyaml
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const sample = `# yaml-language-server: $schema=https://raw.githubusercontent.com/Azure/azure-dev/main/schemas/v1.0/azure.yaml.json
+name: shop # the app
+metadata:
+  template: shop@0.1.0
+  detector:
+    ignore:
+      - legacy/**
+infra:
+  provider: terraform
+hooks:
+  preprovision:
+    shell: sh
+    run: ./scripts/check.sh
+  postdeploy:
+    - run: echo one
+    - run: echo two
+      continueOnError: true
+# Services in start order
+services:
+  api:
+    project: ./api
+    host: containerapp
+    language: python
+    config:
+      port: "8000"
+    hooks:
+      prebuild:
+        windows:
+          run: build.ps1
+        posix:
+          run: ./build.sh
+    k8s:
+      namespace: shop
+  web:
+    project: ./web
+    host: staticwebapp
+    language: js
+    uses:
+      - api
+    requiredEnv:
+      - API_KEY
+      - name: DATABASE_URL
+        source: azd env
+resources:
+  db:
+    type: db.postgres
+    version: "16"
+reqs:
+  - name: node
+    minVersion: "20"
+state:
+  remote:
+    backend: AzureBlobStorage
+`
+
+func TestParse(t *testing.T) {
+	doc, err := Parse([]byte(sample))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	p := doc.Project
+
+	if p.Name != "shop" || p.Metadata.Template != "shop@0.1.0" || p.Infra.Provider != "terraform" {
+		t.Errorf("unexpected project fields: %+v", p)
+	}
+	if got := p.Metadata.Detector.Ignore; len(got) != 1 || got[0] != "legacy/**" {
+		t.Errorf("detector ignore = %v", got)
+	}
+	if hooks := p.Hooks["preprovision"]; len(hooks) != 1 || hooks[0].Run != "./scripts/check.sh" || hooks[0].Shell != "sh" {
+		t.Errorf("preprovision hooks = %+v", hooks)
+	}
+	if hooks := p.Hooks["postdeploy"]; len(hooks) != 2 || !hooks[1].ContinueOnError {
+		t.Errorf("postdeploy hooks = %+v", hooks)
+	}
+
+	api := p.Services["api"]
+	if api.Host != "containerapp" || api.Project != "./api" || api.Config["port"] != "8000" {
+		t.Errorf("api service = %+v", api)
+	}
+	if hook := api.Hooks["prebuild"]; len(hook) != 1 || hook[0].Windows.Run != "build.ps1" || hook[0].Posix.Run != "./build.sh" {
+		t.Errorf("api prebuild hooks = %+v", hook)
+	}
+	if _, ok := api.Extra["k8s"]; !ok {
+		t.Errorf("unmodeled service key k8s should be kept in Extra: %+v", api.Extra)
+	}
+	if web := p.Services["web"]; len(web.Uses) != 1 || web.Uses[0] != "api" {
+		t.Errorf("web uses = %v", web.Uses)
+	}
+	if got := p.Services["web"].RequiredEnv; len(got) != 2 || got[0] != (RequiredEnvVar{Name: "API_KEY"}) ||
+		got[1] != (RequiredEnvVar{Name: "DATABASE_URL", Source: "azd env"}) {
+		t.Errorf("web requiredEnv = %+v", got)
+	}
+	if db := p.Resources["db"]; db.Type != "db.postgres" || db.Extra["version"] != "16" {
+		t.Errorf("db resource = %+v", db)
+	}
+	if len(p.Reqs) != 1 || p.Reqs[0].Name != "node" || p.Reqs[0].MinVersion != "20" {
+		t.Errorf("reqs = %+v", p.Reqs)
+	}
+	if _, ok := p.Extra["state"]; !ok {
+		t.Errorf("unmodeled key state should be kept in Extra: %+v", p.Extra)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "syntax", content: "name: [unclosed"},
+		{name: "wrong type", content: "name: test\nservices: [api]\n"},
+		{name: "invalid hook", content: "name: test\nhooks:\n  preprovision: ./run.sh\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse([]byte(tt.content)); err == nil {
+				t.Error("Parse() should fail")
+			}
+		})
+	}
+}
+
+func TestMarshalRoundTrip(t *testing.T) {
+	doc, err := Parse([]byte(sample))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	data, err := doc.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if string(data) != sample {
+		t.Errorf("unchanged document was not preserved:\n%s", data)
+	}
+}
+
+func TestMarshalChanges(t *testing.T) {
+	doc, err := Parse([]byte(sample))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	p := doc.Project
+
+	api := p.Services["api"]
+	api.Config["port"] = "9000"
+	p.Services["api"] = api
+	web := p.Services["web"]
+	web.Uses = nil
+	p.Services["web"] = web
+	p.Services["worker"] = Service{Host: "containerapp", Project: "./worker"}
+	p.Infra = nil
+
+	data, err := doc.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	content := string(data)
+
+	for _, want := range []string{
+		"name: shop # the app\n",
+		"# Services in start order\nservices:\n",
+		"      port: \"9000\"\n",
+		"  worker:\n    host: containerapp\n    project: ./worker\n",
+		"    k8s:\n      namespace: shop\n",
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("document should contain %q:\n%s", want, content)
+		}
+	}
+	for _, removed := range []string{"infra:", "uses:"} {
+		if strings.Contains(content, removed) {
+			t.Errorf("document should no longer contain %q:\n%s", removed, content)
+		}
+	}
+	if strings.Index(content, "  api:") > strings.Index(content, "  web:") || strings.Index(content, "  web:") > strings.Index(content, "  worker:") {
+		t.Errorf("existing services should keep their order, new ones are appended:\n%s", content)
+	}
+
+	again, err := Parse(data)
+	if err != nil {
+		t.Fatalf("changed document does not parse: %v", err)
+	}
+	if len(again.Project.Services) != 3 {
+		t.Errorf("services after round trip = %d, want 3", len(again.Project.Services))
+	}
+}
+
+func TestLoadAndSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	if err := os.WriteFile(path, []byte("# my app\nname: demo\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	doc, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	doc.Project.Reqs = append(doc.Project.Reqs, Requirement{Name: "docker", MinVersion: "24.0.0"})
+	if err := doc.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "# my app\nname: demo\nreqs:\n  - name: docker\n    minVersion: 24.0.0\n"
+	if string(data) != want {
+		t.Errorf("saved document = %q, want %q", data, want)
+	}
+}
+
+func TestMarshalEmptyDocument(t *testing.T) {
+	doc, err := Parse(nil)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	doc.Project.Name = "new"
+	data, err := doc.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if string(data) != "name: new\n" {
+		t.Errorf("Marshal() = %q", data)
+	}
+}
+
+// TestCorpus parses azure.yaml files modeled on public azd templates. Each must parse into the
+// typed model, pass the schema, and be written back byte for byte when nothing changed.
+func TestCorpus(t *testing.T) {
+	tests := []struct {
+		file  string
+		check func(t *testing.T, p *Project)
+	}{
+		{file: "todo-nodejs-mongo.yaml", check: func(t *testing.T, p *Project) {
+			web := p.Services["web"]
+			if p.Metadata == nil || p.Metadata.Template != "todo-nodejs-mongo@0.0.1-beta" {
+				t.Errorf("metadata = %+v, want the template", p.Metadata)
+			}
+			if web.Dist != "dist" || web.Host != "appservice" {
+				t.Errorf("web = %+v, want dist and host", web)
+			}
+			if hooks := web.Hooks["prepackage"]; len(hooks) != 1 || hooks[0].Windows == nil || hooks[0].Posix == nil || hooks[0].Posix.Shell != "sh" {
+				t.Errorf("web prepackage hooks = %+v, want windows and posix variants", hooks)
+			}
+		}},
+		{file: "todo-python-mongo-aca.yaml", check: func(t *testing.T, p *Project) {
+			api := p.Services["api"]
+			if api.Docker == nil || api.Docker.Platform != "linux/amd64" || len(api.Docker.BuildArgs) != 1 {
+				t.Errorf("api docker = %+v, want platform and build args", api.Docker)
+			}
+			if web := p.Services["web"]; web.Docker == nil || !web.Docker.RemoteBuild {
+				t.Errorf("web docker = %+v, want a remote build", web.Docker)
+			}
+			if p.RequiredVersions == nil || p.RequiredVersions.Azd != ">= 1.10.0" {
+				t.Errorf("requiredVersions = %+v", p.RequiredVersions)
+			}
+			if hooks := p.Hooks["postprovision"]; len(hooks) != 1 || hooks[0].Windows == nil || !hooks[0].Windows.Interactive {
+				t.Errorf("postprovision hooks = %+v, want an interactive windows variant", hooks)
+			}
+			if p.Pipeline == nil || p.Pipeline.Provider != "github" || len(p.Pipeline.Secrets) != 1 {
+				t.Errorf("pipeline = %+v", p.Pipeline)
+			}
+			if _, ok := p.Extra["workflows"]; !ok {
+				t.Error("workflows should be kept in Extra")
+			}
+		}},
+		{file: "aspire-starter.yaml", check: func(t *testing.T, p *Project) {
+			if app := p.Services["app"]; app.Language != "dotnet" || !strings.HasSuffix(app.Project, ".AppHost.csproj") {
+				t.Errorf("app = %+v, want the AppHost project", app)
+			}
+		}},
+		{file: "functions-terraform.yaml", check: func(t *testing.T, p *Project) {
+			if p.Infra == nil || p.Infra.Provider != "terraform" || p.Infra.Path != "./infra/terraform" || p.Infra.Module != "main" {
+				t.Errorf("infra = %+v", p.Infra)
+			}
+			hooks := p.Hooks["preprovision"]
+			if len(hooks) != 2 || hooks[1].Secrets["ARM_CLIENT_SECRET"] != "AZURE_CLIENT_SECRET" {
+				t.Errorf("preprovision hooks = %+v, want two hooks with secrets on the second", hooks)
+			}
+			if _, ok := p.Extra["state"]; !ok {
+				t.Error("state should be kept in Extra")
+			}
+		}},
+		{file: "ai-chat-resources.yaml", check: func(t *testing.T, p *Project) {
+			chat := p.Services["chat"]
+			if len(chat.Env) != 2 || chat.Env[1].Secret != "${AZURE_SEARCH_KEY}" || len(chat.Uses) != 2 {
+				t.Errorf("chat = %+v, want env with a secret and two uses", chat)
+			}
+			if _, ok := p.Services["frontend"].Extra["k8s"]; !ok {
+				t.Error("frontend k8s should be kept in Extra")
+			}
+			if search := p.Resources["search"]; search.Type != "ai.search" || !search.Existing {
+				t.Errorf("search = %+v, want an existing ai.search", search)
+			}
+			if _, ok := p.Resources["llm"].Extra["model"]; !ok {
+				t.Error("llm model should be kept in Extra")
+			}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			data, err := os.ReadFile(filepath.Join("testdata", "corpus", tt.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			doc, err := Parse(data)
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+			for _, issue := range doc.Validate() {
+				if issue.Severity == SeverityError {
+					t.Errorf("schema error: %s", issue)
+				}
+			}
+			tt.check(t, doc.Project)
+
+			out, err := doc.Marshal()
+			if err != nil {
+				t.Fatalf("Marshal failed: %v", err)
+			}
+			if string(out) != string(data) {
+				t.Errorf("unchanged document was not preserved:\n%s", out)
+			}
+		})
+	}
+}
+
+func TestLoadRejectsPathsOutsideWorkspace(t *testing.T) {
+	tests := []struct {
+		name    string
+		service string
+		wantErr string
+	}{
+		{name: "inside", service: "project: ./api\n    docker:\n      context: ..\n      path: ./Dockerfile"},
+		{name: "project", service: "project: ../api", wantErr: "api: project ../api is outside the workspace"},
+		{name: "docker context", service: "project: ./api\n    docker:\n      context: ../..", wantErr: "api: docker.context ../.. is outside the workspace"},
+		{name: "dockerfile", service: "project: ./api\n    docker:\n      path: ../../Dockerfile", wantErr: "api: docker.path ../../Dockerfile is outside the workspace"},
+		{name: "absolute dockerfile", service: "project: ./api\n    docker:\n      path: /srv/Dockerfile", wantErr: "api: docker.path /srv/Dockerfile is an absolute path"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), FileName)
+			if err := os.WriteFile(path, []byte("name: demo\nservices:\n  api:\n    host: containerapp\n    "+tt.service+"\n"), 0600); err != nil {
+				t.Fatal(err)
+			}
+			_, err := Load(path)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Load() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Load() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,259 @@
+package azureyaml
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Project is the typed model of azure.yaml.
+type Project struct {
+	Name             string                 `yaml:"name"`
+	ResourceGroup    string                 `yaml:"resourceGroup,omitempty"`
+	RequiredVersions *RequiredVersions      `yaml:"requiredVersions,omitempty"`
+	Metadata         *Metadata              `yaml:"metadata,omitempty"`
+	Infra            *Infra                 `yaml:"infra,omitempty"`
+	Hooks            map[string]Hooks       `yaml:"hooks,omitempty"`
+	Services         map[string]Service     `yaml:"services,omitempty"`
+	Resources        map[string]Resource    `yaml:"resources,omitempty"`
+	Pipeline         *Pipeline              `yaml:"pipeline,omitempty"`
+	Reqs             []Requirement          `yaml:"reqs,omitempty"`  // azd app: tools the project needs
+	Retry            *RetrySettings         `yaml:"retry,omitempty"` // azd app: retry policies of every service
+	Build            *BuildSettings         `yaml:"build,omitempty"` // azd app: container image builds of every service
+	Extra            map[string]interface{} `yaml:",inline"`         // Keys not modeled above, e.g. state or workflows
+}
+
+// RequiredVersions constrains the versions of the tools that may use the project.
+type RequiredVersions struct {
+	Azd string `yaml:"azd,omitempty"` // Version range, e.g. ">= 1.10.0"
+}
+
+// Metadata describes where the project came from.
+type Metadata struct {
+	Template string                 `yaml:"template,omitempty"` // Template the project was created from, e.g. "todo-nodejs-mongo@0.0.1-beta"
+	Detector *DetectorSettings      `yaml:"detector,omitempty"` // azd app: project detection settings
+	Extra    map[string]interface{} `yaml:",inline"`
+}
+
+// DetectorSettings configures azd app project detection.
+type DetectorSettings struct {
+	Ignore  []string `yaml:"ignore,omitempty"`  // gitignore-style globs excluded from detection
+	AppHost string   `yaml:"apphost,omitempty"` // Aspire AppHost to use when there are several
+}
+
+// Infra configures the infrastructure as code of the project.
+type Infra struct {
+	Provider string                 `yaml:"provider,omitempty"` // "bicep" or "terraform"
+	Path     string                 `yaml:"path,omitempty"`     // Defaults to infra
+	Module   string                 `yaml:"module,omitempty"`   // Defaults to main
+	Extra    map[string]interface{} `yaml:",inline"`
+}
+
+// Service represents a service definition in azure.yaml.
+type Service struct {
+	Host         string                    `yaml:"host"`
+	Language     string                    `yaml:"language,omitempty"`
+	Project      string                    `yaml:"project,omitempty"`
+	Entrypoint   string                    `yaml:"entrypoint,omitempty"` // Entry point file for Python/Node projects
+	Module       string                    `yaml:"module,omitempty"`
+	Dist         string                    `yaml:"dist,omitempty"`
+	ResourceName string                    `yaml:"resourceName,omitempty"`
+	Image        string                    `yaml:"image,omitempty"`
+	Docker       *DockerConfig             `yaml:"docker,omitempty"`
+	Config       map[string]interface{}    `yaml:"config,omitempty"`
+	Env          []EnvVar                  `yaml:"env,omitempty"`
+	Uses         []string                  `yaml:"uses,omitempty"`
+	DependsOn    []string                  `yaml:"dependsOn,omitempty"`   // azd app: services to start first, without the connection that uses sets up
+	Retry        *RetrySettings            `yaml:"retry,omitempty"`       // azd app: retry policies of this service, over those of the project
+	Ready        *ReadySettings            `yaml:"ready,omitempty"`       // azd app: when the service counts as started
+	Health       *HealthSettings           `yaml:"health,omitempty"`      // azd app: how the running service is probed
+	Restart      string                    `yaml:"restart,omitempty"`     // azd app: "never" (default), "on-failure" or "always"
+	Runner       string                    `yaml:"runner,omitempty"`      // azd app: "native" (default), or "docker" to build the Dockerfile and run the service in a container
+	RequiredEnv  []RequiredEnvVar          `yaml:"requiredEnv,omitempty"` // azd app: variables that must be set before the service starts
+	EnvContract  string                    `yaml:"envContract,omitempty"` // azd app: file of the project listing required variables, e.g. .env.example
+	Outputs      map[string]OutputSettings `yaml:"outputs,omitempty"`     // azd app: values the service prints once started, by name
+	Inputs       map[string]string         `yaml:"inputs,omitempty"`      // azd app: variables set to outputs of other services, e.g. API_KEY: auth.apiKey
+	AzdEnv       map[string]string         `yaml:"azdEnv,omitempty"`      // azd app: variables set to values of the azd environment, e.g. DATABASE_URL: AZURE_POSTGRES_URL
+	Budget       *BudgetSettings           `yaml:"budget,omitempty"`      // azd app: resources the service may take, enforced by verify
+	Hooks        map[string]Hooks          `yaml:"hooks,omitempty"`
+	Extra        map[string]interface{}    `yaml:",inline"` // Keys not modeled above, e.g. k8s or apiVersion
+}
+
+// DockerConfig represents Docker build configuration.
+type DockerConfig struct {
+	Path        string   `yaml:"path,omitempty"`
+	Context     string   `yaml:"context,omitempty"`
+	Platform    string   `yaml:"platform,omitempty"`
+	Registry    string   `yaml:"registry,omitempty"`
+	Image       string   `yaml:"image,omitempty"`
+	Tag         string   `yaml:"tag,omitempty"`
+	BuildArgs   []string `yaml:"buildArgs,omitempty"`
+	RemoteBuild bool     `yaml:"remoteBuild,omitempty"`
+	Platforms   []string `yaml:"platforms,omitempty"` // azd app: platforms of a multi-arch build, e.g. [linux/amd64, linux/arm64]
+	Builder     string   `yaml:"builder,omitempty"`   // azd app: buildpacks builder of a service without a Dockerfile, over the one of its language
+}
+
+// BuildSettings configures how 'azd app build' builds the container images of services.
+type BuildSettings struct {
+	Platforms   []string `yaml:"platforms,omitempty"`   // Platforms of services without docker.platforms, e.g. [linux/amd64, linux/arm64]
+	Cache       string   `yaml:"cache,omitempty"`       // Build cache shared by builds: "local" (default), "gha", "registry:<repository>", or "none"
+	Concurrency int      `yaml:"concurrency,omitempty"` // Images built at once (default 4)
+}
+
+// EnvVar represents an environment variable.
+type EnvVar struct {
+	Name   string `yaml:"name"`
+	Value  string `yaml:"value,omitempty"`
+	Secret string `yaml:"secret,omitempty"`
+}
+
+// RequiredEnvVar is a variable a service needs to start. azure.yaml lists it by name, or as a
+// mapping that also says where the value is expected to come from.
+type RequiredEnvVar struct {
+	Name   string `yaml:"name"`
+	Source string `yaml:"source,omitempty"` // Where the value comes from, e.g. "azd env" or "Key Vault", shown when it is missing
+}
+
+// UnmarshalYAML accepts a variable name or a mapping.
+func (v *RequiredEnvVar) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*v = RequiredEnvVar{Name: node.Value}
+		return nil
+	}
+	type plain RequiredEnvVar
+	return node.Decode((*plain)(v))
+}
+
+// MarshalYAML writes a variable without a source as its name.
+func (v RequiredEnvVar) MarshalYAML() (interface{}, error) {
+	if v.Source == "" {
+		return v.Name, nil
+	}
+	type plain RequiredEnvVar
+	return plain(v), nil
+}
+
+// RetrySettings configures how azd app retries operations that may fail transiently.
+type RetrySettings struct {
+	HealthCheck *RetryPolicy `yaml:"healthCheck,omitempty"` // Probes of a starting service
+	Install     *RetryPolicy `yaml:"install,omitempty"`     // Dependency installs
+	Restart     *RetryPolicy `yaml:"restart,omitempty"`     // Restarts of a service that exited
+	Network     *RetryPolicy `yaml:"network,omitempty"`     // Requests to remote services; project-wide only
+}
+
+// RetryPolicy overrides the settings of a retry policy that it sets.
+type RetryPolicy struct {
+	MaxAttempts *int     `yaml:"maxAttempts,omitempty"` // Attempts in total, including the first
+	Delay       string   `yaml:"delay,omitempty"`       // Delay before the first retry, e.g. 500ms
+	Backoff     *float64 `yaml:"backoff,omitempty"`     // Factor the delay grows by per retry
+	MaxDelay    string   `yaml:"maxDelay,omitempty"`    // Longest delay before a retry
+	Jitter      *float64 `yaml:"jitter,omitempty"`      // Random fraction of each delay, from 0 to 1
+}
+
+// ReadySettings configures when azd app considers a started service ready.
+type ReadySettings struct {
+	LogPattern string `yaml:"logPattern,omitempty"` // Regular expression a log line matches once the service is ready
+	Timeout    string `yaml:"timeout,omitempty"`    // Longest wait for a matching line, e.g. 90s
+}
+
+// BudgetSettings limits the resources of a service, which 'azd app verify' measures.
+type BudgetSettings struct {
+	ImageSize string `yaml:"imageSize,omitempty"` // Largest container image built from its Dockerfile, e.g. 300MB
+	Startup   string `yaml:"startup,omitempty"`   // Longest time from starting the service until it is ready, e.g. 15s
+}
+
+// OutputSettings configures how azd app captures a value a service prints to stdout.
+type OutputSettings struct {
+	Pattern string `yaml:"pattern,omitempty"` // Regular expression with one group, the value, e.g. 'API key: (\S+)'
+	Type    string `yaml:"type,omitempty"`    // "string" (default), "url", or "int"
+	Timeout string `yaml:"timeout,omitempty"` // Longest wait for a matching line, e.g. 30s
+}
+
+// HealthSettings configures how azd app probes a running service.
+type HealthSettings struct {
+	Type     string `yaml:"type,omitempty"`     // "http" (default) or "port"
+	Path     string `yaml:"path,omitempty"`     // Path of the health endpoint, e.g. /health
+	Interval string `yaml:"interval,omitempty"` // Time between probes, e.g. 10s
+	Timeout  string `yaml:"timeout,omitempty"`  // Longest wait for one probe, e.g. 2s
+}
+
+// Resource represents a resource definition in azure.yaml.
+type Resource struct {
+	Type     string                 `yaml:"type"`
+	Uses     []string               `yaml:"uses,omitempty"`
+	Existing bool                   `yaml:"existing,omitempty"`
+	Extra    map[string]interface{} `yaml:",inline"` // Settings of the resource type, e.g. port or models
+}
+
+// Pipeline configures the CI/CD pipeline created by azd pipeline config.
+type Pipeline struct {
+	Provider  string   `yaml:"provider,omitempty"` // "github" or "azdo"
+	Variables []string `yaml:"variables,omitempty"`
+	Secrets   []string `yaml:"secrets,omitempty"`
+}
+
+// Hook is a command that azd runs before or after a lifecycle event.
+type Hook struct {
+	Run             string            `yaml:"run,omitempty"`   // Inline script or path to a script file
+	Shell           string            `yaml:"shell,omitempty"` // "sh" or "pwsh"
+	ContinueOnError bool              `yaml:"continueOnError,omitempty"`
+	Interactive     bool              `yaml:"interactive,omitempty"`
+	Secrets         map[string]string `yaml:"secrets,omitempty"`
+	Windows         *Hook             `yaml:"windows,omitempty"` // Replaces the hook on Windows
+	Posix           *Hook             `yaml:"posix,omitempty"`   // Replaces the hook on Linux and macOS
+}
+
+// Hooks are the hooks of one lifecycle event. azure.yaml lists a single hook as a mapping and
+// several hooks as a sequence; both forms are kept when the file is written back.
+type Hooks []Hook
+
+// UnmarshalYAML accepts a single hook or a sequence of hooks.
+func (h *Hooks) UnmarshalYAML(node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.MappingNode:
+		var hook Hook
+		if err := node.Decode(&hook); err != nil {
+			return err
+		}
+		*h = Hooks{hook}
+		return nil
+	case yaml.SequenceNode:
+		var hooks []Hook
+		if err := node.Decode(&hooks); err != nil {
+			return err
+		}
+		*h = hooks
+		return nil
+	case yaml.ScalarNode:
+		if node.Tag == "!!null" {
+			*h = nil
+			return nil
+		}
+	}
+	return fmt.Errorf("line %d: hook must be a mapping or a sequence of mappings", node.Line)
+}
+
+// MarshalYAML writes a single hook as a mapping.
+func (h Hooks) MarshalYAML() (interface{}, error) {
+	if len(h) == 1 {
+		return h[0], nil
+	}
+	return []Hook(h), nil
+}
+
+// Requirement is a tool listed in the reqs section, which azd app checks before running.
+type Requirement struct {
+	Name       string `yaml:"name"`
+	MinVersion string `yaml:"minVersion"`
+	// Custom tool configuration (optional)
+	Command       string   `yaml:"command,omitempty"`       // Override command to execute
+	Args          []string `yaml:"args,omitempty"`          // Override arguments
+	VersionPrefix string   `yaml:"versionPrefix,omitempty"` // Override version prefix to strip
+	VersionField  int      `yaml:"versionField,omitempty"`  // Override which field contains version
+	// Runtime check configuration (optional)
+	CheckRunning         bool     `yaml:"checkRunning,omitempty"`         // Whether to check if the tool is running
+	RunningCheckCommand  string   `yaml:"runningCheckCommand,omitempty"`  // Command to check if tool is running
+	RunningCheckArgs     []string `yaml:"runningCheckArgs,omitempty"`     // Arguments for running check command
+	RunningCheckExpected string   `yaml:"runningCheckExpected,omitempty"` // Expected substring in output (optional)
+	RunningCheckExitCode *int     `yaml:"runningCheckExitCode,omitempty"` // Expected exit code (default: 0)
+}
@@ -0,0 +1,71 @@
+package azureyaml
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ValidatePaths returns an error listing every service whose project, docker.context, or
+// docker.path is absolute or leads outside root, the directory of azure.yaml. Like the
+// detectors, services never reach outside the workspace; symlinks are followed, so a link can't
+// lead out either. The docker paths are relative to the project, as in azd.
+func (p *Project) ValidatePaths(root string) error {
+	resolvedRoot := root
+	if resolved, err := filepath.EvalSymlinks(root); err == nil {
+		resolvedRoot = resolved
+	}
+
+	var problems []string
+	check := func(name, field, value, base string) bool {
+		if value == "" {
+			return true
+		}
+		if isAbsolute(value) {
+			problems = append(problems, fmt.Sprintf("%s: %s %s is an absolute path", name, field, value))
+			return false
+		}
+		if reason := outside(resolvedRoot, filepath.Join(base, value)); reason != "" {
+			problems = append(problems, fmt.Sprintf("%s: %s %s %s", name, field, value, reason))
+			return false
+		}
+		return true
+	}
+	for name, svc := range p.Services {
+		if !check(name, "project", svc.Project, resolvedRoot) || svc.Docker == nil {
+			continue
+		}
+		projectDir := filepath.Join(resolvedRoot, svc.Project)
+		check(name, "docker.context", svc.Docker.Context, projectDir)
+		check(name, "docker.path", svc.Docker.Path, projectDir)
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+	sort.Strings(problems)
+	return fmt.Errorf("service paths must be relative paths inside the workspace %s:\n  - %s", root, strings.Join(problems, "\n  - "))
+}
+
+// isAbsolute reports whether path is absolute on any platform, so that an azure.yaml written on
+// another one is checked the same.
+func isAbsolute(path string) bool {
+	return filepath.IsAbs(path) || filepath.VolumeName(path) != "" || strings.HasPrefix(filepath.ToSlash(path), "/")
+}
+
+// outside returns why path isn't inside root, following a symlink at path, or "" when it is.
+func outside(root, path string) string {
+	reason := "is outside the workspace"
+	if _, err := os.Lstat(path); err == nil {
+		if resolved, err := filepath.EvalSymlinks(path); err == nil && resolved != path {
+			path = resolved
+			reason = "links outside the workspace"
+		}
+	}
+	if rel, err := filepath.Rel(root, path); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return reason
+	}
+	return ""
+}
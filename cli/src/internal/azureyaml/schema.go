@@ -0,0 +1,428 @@
+package azureyaml
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Severity of a schema issue.
+const (
+	SeverityError   = "error"   // azd rejects the file
+	SeverityWarning = "warning" // azd ignores the value, or only azd app understands it
+)
+
+// Issue is a place where azure.yaml does not match the azd schema.
+type Issue struct {
+	Path     string `json:"path"` // Location in the document, e.g. "services.api.host"
+	Line     int    `json:"line,omitempty"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+// String formats the issue for display.
+func (i Issue) String() string {
+	location := i.Path
+	if location == "" {
+		location = FileName
+	}
+	if i.Line > 0 {
+		location = fmt.Sprintf("%s (line %d)", location, i.Line)
+	}
+	return location + ": " + i.Message
+}
+
+// HasErrors reports whether any issue is an error.
+func HasErrors(issues []Issue) bool {
+	for _, issue := range issues {
+		if issue.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// kind is the expected type of a value.
+type kind int
+
+const (
+	kindAny kind = iota
+	kindString
+	kindBool
+	kindObject // Mapping with known properties
+	kindMap    // Mapping from names to values of one type
+	kindList   // Sequence of values of one type
+	kindHooks  // A hook, or a sequence of hooks
+)
+
+// rule describes the values allowed at one place of the document, following the azd v1.0
+// schema (https://raw.githubusercontent.com/Azure/azure-dev/main/schemas/v1.0/azure.yaml.json).
+type rule struct {
+	kind       kind
+	properties map[string]*rule // kindObject: known keys
+	extensions map[string]*rule // kindObject: keys azd app adds, which azd ignores
+	open       bool             // kindObject: any other key is allowed
+	required   []string         // kindObject
+	items      *rule            // kindMap values and kindList items
+	enum       []string         // kindString: allowed values
+	soft       bool             // kindString: values outside enum are only warnings
+	minLength  int              // kindString
+	maxLength  int              // kindString
+}
+
+var (
+	anyRule    = &rule{kind: kindAny}
+	stringRule = &rule{kind: kindString}
+	boolRule   = &rule{kind: kindBool}
+	stringList = &rule{kind: kindList, items: stringRule}
+)
+
+// projectHookNames and serviceHookNames are the lifecycle events hooks can be registered for.
+var (
+	projectHookNames = []string{
+		"preprovision", "postprovision", "preinfracreate", "postinfracreate", "preinfradelete", "postinfradelete",
+		"predeploy", "postdeploy", "prerestore", "postrestore", "prepackage", "postpackage",
+		"predown", "postdown", "preup", "postup",
+	}
+	serviceHookNames = []string{
+		"prerestore", "postrestore", "prebuild", "postbuild", "prepackage", "postpackage", "predeploy", "postdeploy",
+	}
+)
+
+// hookRule is a single hook; windows and posix replace it on their platforms.
+var hookRule = func() *rule {
+	r := &rule{kind: kindObject, properties: map[string]*rule{
+		"run":             stringRule,
+		"shell":           {kind: kindString, enum: []string{"sh", "pwsh"}},
+		"continueOnError": boolRule,
+		"interactive":     boolRule,
+		"secrets":         {kind: kindMap, items: stringRule},
+	}}
+	platform := &rule{kind: kindObject, properties: r.properties, required: []string{"run"}}
+	r.properties = mergeProperties(r.properties, map[string]*rule{"windows": platform, "posix": platform})
+	return r
+}()
+
+// schema is the rule for the whole document.
+var schema = &rule{
+	kind:     kindObject,
+	required: []string{"name"},
+	properties: map[string]*rule{
+		"name":          {kind: kindString, minLength: 2},
+		"resourceGroup": {kind: kindString, minLength: 3, maxLength: 64},
+		"requiredVersions": {kind: kindObject, properties: map[string]*rule{
+			"azd": stringRule,
+		}},
+		"metadata": {kind: kindObject, properties: map[string]*rule{
+			"template": stringRule,
+		}, extensions: map[string]*rule{"detector": {kind: kindObject, properties: map[string]*rule{
+			"ignore":  stringList,
+			"apphost": stringRule,
+		}}}},
+		"infra": {kind: kindObject, properties: map[string]*rule{
+			"provider": {kind: kindString, enum: []string{"bicep", "terraform"}},
+			"path":     stringRule,
+			"module":   stringRule,
+		}},
+		"hooks":     hooksRule(projectHookNames),
+		"services":  {kind: kindMap, items: serviceRule},
+		"resources": {kind: kindMap, items: resourceRule},
+		"pipeline": {kind: kindObject, properties: map[string]*rule{
+			"provider":  {kind: kindString, enum: []string{"github", "azdo"}},
+			"variables": stringList,
+			"secrets":   stringList,
+		}},
+		"state":     {kind: kindObject, open: true},
+		"workflows": {kind: kindObject, open: true},
+		"platform":  {kind: kindObject, open: true},
+		"cloud":     {kind: kindObject, open: true},
+	},
+	extensions: map[string]*rule{
+		"reqs":  {kind: kindList, items: requirementRule},
+		"retry": retryRule("healthCheck", "install", "restart", "network"),
+		"build": {kind: kindObject, properties: map[string]*rule{
+			"platforms":   stringList,
+			"cache":       stringRule,
+			"concurrency": stringRule,
+		}},
+	},
+}
+
+// retryRule is the azd app retry section with a policy for each of the given operations.
+// Numbers are checked as scalars; their ranges are checked when the policies are resolved.
+func retryRule(operations ...string) *rule {
+	policy := &rule{kind: kindObject, properties: map[string]*rule{
+		"maxAttempts": stringRule,
+		"delay":       stringRule,
+		"backoff":     stringRule,
+		"maxDelay":    stringRule,
+		"jitter":      stringRule,
+	}}
+	properties := make(map[string]*rule, len(operations))
+	for _, operation := range operations {
+		properties[operation] = policy
+	}
+	return &rule{kind: kindObject, properties: properties}
+}
+
+// requirementRule is a tool in the azd app reqs section.
+var requirementRule = &rule{
+	kind:     kindObject,
+	required: []string{"name"},
+	properties: map[string]*rule{
+		"name":                 stringRule,
+		"minVersion":           stringRule,
+		"command":              stringRule,
+		"args":                 stringList,
+		"versionPrefix":        stringRule,
+		"versionField":         anyRule,
+		"checkRunning":         boolRule,
+		"runningCheckCommand":  stringRule,
+		"runningCheckArgs":     stringList,
+		"runningCheckExpected": stringRule,
+		"runningCheckExitCode": anyRule,
+	},
+}
+
+// serviceRule is a service. azd app also understands languages azd does not deploy, such as go.
+var serviceRule = &rule{
+	kind:     kindObject,
+	required: []string{"host"},
+	properties: map[string]*rule{
+		"host": {kind: kindString, enum: []string{
+			"appservice", "containerapp", "function", "springapp", "staticwebapp", "aks", "ai.endpoint", "azure.ai.agent",
+		}},
+		"language": {kind: kindString, soft: true, enum: []string{
+			"dotnet", "csharp", "fsharp", "py", "python", "js", "ts", "java", "docker", "custom",
+		}},
+		"project":      stringRule,
+		"module":       stringRule,
+		"dist":         stringRule,
+		"resourceName": stringRule,
+		"image":        stringRule,
+		"apiVersion":   stringRule,
+		"docker": {kind: kindObject, properties: map[string]*rule{
+			"path":        stringRule,
+			"context":     stringRule,
+			"platform":    stringRule,
+			"registry":    stringRule,
+			"image":       stringRule,
+			"tag":         stringRule,
+			"buildArgs":   stringList,
+			"remoteBuild": boolRule,
+		}, extensions: map[string]*rule{
+			"platforms": stringList,
+			"builder":   stringRule,
+		}},
+		"k8s":    {kind: kindObject, open: true},
+		"config": {kind: kindObject, open: true},
+		"env":    anyRule,
+		"uses":   stringList,
+		"hooks":  hooksRule(serviceHookNames),
+	},
+	extensions: map[string]*rule{
+		"entrypoint": stringRule,
+		"dependsOn":  stringList,
+		"retry":      retryRule("healthCheck", "install", "restart"),
+		"ready": {kind: kindObject, required: []string{"logPattern"}, properties: map[string]*rule{
+			"logPattern": stringRule,
+			"timeout":    stringRule,
+		}},
+		"health": {kind: kindObject, properties: map[string]*rule{
+			"type":     {kind: kindString, enum: []string{"http", "port"}},
+			"path":     stringRule,
+			"interval": stringRule,
+			"timeout":  stringRule,
+		}},
+		"restart":     {kind: kindString, enum: []string{"never", "on-failure", "always"}},
+		"runner":      {kind: kindString, enum: []string{"native", "docker"}},
+		"requiredEnv": {kind: kindList, items: anyRule}, // Names, or mappings with name and source
+		"envContract": stringRule,
+		"outputs": {kind: kindMap, items: &rule{kind: kindObject, required: []string{"pattern"}, properties: map[string]*rule{
+			"pattern": stringRule,
+			"type":    {kind: kindString, enum: []string{"string", "url", "int"}},
+			"timeout": stringRule,
+		}}},
+		"inputs": {kind: kindMap, items: stringRule},
+		"azdEnv": {kind: kindMap, items: stringRule},
+		"budget": {kind: kindObject, properties: map[string]*rule{
+			"imageSize": stringRule,
+			"startup":   stringRule,
+		}},
+	},
+}
+
+// resourceRule is a resource; its type decides which other settings apply.
+var resourceRule = &rule{
+	kind:     kindObject,
+	required: []string{"type"},
+	open:     true,
+	properties: map[string]*rule{
+		"type": {kind: kindString, enum: []string{
+			"host.appservice", "host.containerapp", "ai.openai.model", "ai.project", "ai.search",
+			"db.cosmos", "db.mongo", "db.mysql", "db.postgres", "db.redis",
+			"keyvault", "messaging.eventhubs", "messaging.servicebus", "storage",
+		}},
+		"uses":     stringList,
+		"existing": boolRule,
+	},
+}
+
+// hooksRule allows hooks for the given lifecycle events.
+func hooksRule(names []string) *rule {
+	properties := make(map[string]*rule, len(names))
+	for _, name := range names {
+		properties[name] = &rule{kind: kindHooks}
+	}
+	return &rule{kind: kindObject, properties: properties}
+}
+
+// mergeProperties returns the union of two property sets.
+func mergeProperties(a, b map[string]*rule) map[string]*rule {
+	merged := make(map[string]*rule, len(a)+len(b))
+	for name, r := range a {
+		merged[name] = r
+	}
+	for name, r := range b {
+		merged[name] = r
+	}
+	return merged
+}
+
+// validate checks the root node of a document against the schema.
+func validate(root *yaml.Node) []Issue {
+	var issues []Issue
+	check(root, schema, "", &issues)
+	return issues
+}
+
+// check validates node against r and appends the issues found. path is the location of node.
+func check(node *yaml.Node, r *rule, path string, issues *[]Issue) {
+	if node.Kind == yaml.AliasNode && node.Alias != nil {
+		node = node.Alias
+	}
+	report := func(severity, format string, args ...interface{}) {
+		*issues = append(*issues, Issue{Path: path, Line: node.Line, Severity: severity, Message: fmt.Sprintf(format, args...)})
+	}
+	// An empty value is the same as leaving the key out
+	if node.Kind == yaml.ScalarNode && node.ShortTag() == "!!null" {
+		return
+	}
+
+	switch r.kind {
+	case kindAny:
+		return
+
+	case kindString:
+		if node.Kind != yaml.ScalarNode {
+			report(SeverityError, "must be a string")
+			return
+		}
+		if r.minLength > 0 && len(node.Value) < r.minLength {
+			report(SeverityError, "must be at least %d characters long", r.minLength)
+		}
+		if r.maxLength > 0 && len(node.Value) > r.maxLength {
+			report(SeverityError, "must be at most %d characters long", r.maxLength)
+		}
+		if len(r.enum) > 0 && !contains(r.enum, node.Value) {
+			severity := SeverityError
+			if r.soft {
+				severity = SeverityWarning
+			}
+			report(severity, "%q is not one of %s", node.Value, strings.Join(r.enum, ", "))
+		}
+
+	case kindBool:
+		if node.Kind != yaml.ScalarNode || node.ShortTag() != "!!bool" {
+			report(SeverityError, "must be true or false")
+		}
+
+	case kindList:
+		if node.Kind != yaml.SequenceNode {
+			report(SeverityError, "must be a list")
+			return
+		}
+		for i, item := range node.Content {
+			check(item, r.items, fmt.Sprintf("%s[%d]", path, i), issues)
+		}
+
+	case kindMap:
+		if node.Kind != yaml.MappingNode {
+			report(SeverityError, "must be a mapping")
+			return
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			check(node.Content[i+1], r.items, join(path, node.Content[i].Value), issues)
+		}
+
+	case kindHooks:
+		switch node.Kind {
+		case yaml.MappingNode:
+			check(node, hookRule, path, issues)
+		case yaml.SequenceNode:
+			for i, item := range node.Content {
+				check(item, hookRule, fmt.Sprintf("%s[%d]", path, i), issues)
+			}
+		default:
+			report(SeverityError, "must be a hook or a list of hooks")
+		}
+
+	case kindObject:
+		if node.Kind != yaml.MappingNode {
+			report(SeverityError, "must be a mapping")
+			return
+		}
+		present := make(map[string]bool, len(node.Content)/2)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			present[key.Value] = true
+			if child, ok := r.properties[key.Value]; ok {
+				check(value, child, join(path, key.Value), issues)
+			} else if child, ok := r.extensions[key.Value]; ok {
+				check(value, child, join(path, key.Value), issues)
+			} else if !r.open && key.Value != "<<" {
+				*issues = append(*issues, Issue{
+					Path:     join(path, key.Value),
+					Line:     key.Line,
+					Severity: SeverityWarning,
+					Message:  fmt.Sprintf("unknown key (expected one of %s)", strings.Join(sortedNames(r.properties), ", ")),
+				})
+			}
+		}
+		for _, name := range r.required {
+			if !present[name] {
+				report(SeverityError, "missing required key %q", name)
+			}
+		}
+	}
+}
+
+// join appends a key to a document path.
+func join(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// contains reports whether values contains value.
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// sortedNames returns the keys of properties in sorted order.
+func sortedNames(properties map[string]*rule) []string {
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,144 @@
+package azureyaml
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []Issue // Path and severity of the expected issues, in order
+	}{
+		{
+			name:    "valid sample",
+			content: sample,
+		},
+		{
+			name:    "missing name",
+			content: "services:\n  api:\n    host: containerapp\n",
+			want:    []Issue{{Path: "", Severity: SeverityError}},
+		},
+		{
+			name:    "invalid host and missing host",
+			content: "name: app\nservices:\n  api:\n    host: vm\n  web:\n    project: ./web\n",
+			want:    []Issue{{Path: "services.api.host", Severity: SeverityError}, {Path: "services.web", Severity: SeverityError}},
+		},
+		{
+			name:    "language only azd app understands",
+			content: "name: app\nservices:\n  api:\n    host: containerapp\n    language: go\n",
+			want:    []Issue{{Path: "services.api.language", Severity: SeverityWarning}},
+		},
+		{
+			name:    "unknown keys",
+			content: "name: app\nservics: {}\nservices:\n  api:\n    host: containerapp\n    ports: [80]\n",
+			want:    []Issue{{Path: "servics", Severity: SeverityWarning}, {Path: "services.api.ports", Severity: SeverityWarning}},
+		},
+		{
+			name:    "wrong types",
+			content: "name: app\ninfra: bicep\nservices:\n  api:\n    host: containerapp\n    uses: api\n    docker:\n      remoteBuild: yes please\n",
+			want: []Issue{
+				{Path: "infra", Severity: SeverityError},
+				{Path: "services.api.uses", Severity: SeverityError},
+				{Path: "services.api.docker.remoteBuild", Severity: SeverityError},
+			},
+		},
+		{
+			name:    "hooks",
+			content: "name: app\nhooks:\n  preprovision:\n    run: x\n    shell: bash\n  prebuild:\n    run: y\n  postdeploy:\n    - windows:\n        shell: pwsh\n",
+			want: []Issue{
+				{Path: "hooks.preprovision.shell", Severity: SeverityError},
+				{Path: "hooks.prebuild", Severity: SeverityWarning},
+				{Path: "hooks.postdeploy[0].windows", Severity: SeverityError},
+			},
+		},
+		{
+			name:    "infra provider, resource type, and name length",
+			content: "name: a\ninfra:\n  provider: pulumi\nresources:\n  cache:\n    type: db.memcached\n",
+			want: []Issue{
+				{Path: "name", Severity: SeverityError},
+				{Path: "infra.provider", Severity: SeverityError},
+				{Path: "resources.cache.type", Severity: SeverityError},
+			},
+		},
+		{
+			name:    "retry",
+			content: "name: app\nretry:\n  network:\n    maxAttempts: 5\n    delay: 1s\n  probe: {}\nservices:\n  api:\n    host: containerapp\n    retry:\n      network:\n        maxAttempts: 2\n      install:\n        delay: [1s]\n",
+			want: []Issue{
+				{Path: "retry.probe", Severity: SeverityWarning},
+				{Path: "services.api.retry.network", Severity: SeverityWarning},
+				{Path: "services.api.retry.install.delay", Severity: SeverityError},
+			},
+		},
+		{
+			name:    "ready",
+			content: "name: app\nservices:\n  web:\n    host: staticwebapp\n    ready:\n      logPattern: Compiled\n      timeout: 90s\n  api:\n    host: containerapp\n    ready:\n      timeout: 30s\n",
+			want:    []Issue{{Path: "services.api.ready", Severity: SeverityError}},
+		},
+		{
+			name:    "health",
+			content: "name: app\nservices:\n  api:\n    host: containerapp\n    health:\n      type: grpc\n      path: /health\n      interval: 5s\n",
+			want:    []Issue{{Path: "services.api.health.type", Severity: SeverityError}},
+		},
+		{
+			name:    "restart",
+			content: "name: app\nservices:\n  api:\n    host: containerapp\n    restart: sometimes\n    retry:\n      restart:\n        maxAttempts: 3\n",
+			want:    []Issue{{Path: "services.api.restart", Severity: SeverityError}},
+		},
+		{
+			name:    "required env",
+			content: "name: app\nservices:\n  web:\n    host: staticwebapp\n    envContract: .env.example\n    requiredEnv:\n      - API_KEY\n      - name: DATABASE_URL\n        source: azd env\n  api:\n    host: containerapp\n    requiredEnv: API_KEY\n",
+			want:    []Issue{{Path: "services.api.requiredEnv", Severity: SeverityError}},
+		},
+		{
+			name:    "outputs and inputs",
+			content: "name: app\nservices:\n  auth:\n    host: containerapp\n    outputs:\n      apiKey:\n        pattern: 'API key: (\\S+)'\n      url:\n        pattern: 'on (\\S+)'\n        type: uri\n      port:\n        type: int\n  api:\n    host: containerapp\n    inputs:\n      AUTH_KEY: auth.apiKey\n",
+			want: []Issue{
+				{Path: "services.auth.outputs.url.type", Severity: SeverityError},
+				{Path: "services.auth.outputs.port", Severity: SeverityError},
+			},
+		},
+		{
+			name:    "reqs",
+			content: "name: app\nreqs:\n  - minVersion: \"20\"\n",
+			want:    []Issue{{Path: "reqs[0]", Severity: SeverityError}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Values of the wrong type fail Parse, so validate the YAML tree directly
+			var root yaml.Node
+			if err := yaml.Unmarshal([]byte(tt.content), &root); err != nil {
+				t.Fatalf("invalid test YAML: %v", err)
+			}
+			issues := validate(root.Content[0])
+			if len(issues) != len(tt.want) {
+				t.Fatalf("Validate() = %v, want %d issues", issues, len(tt.want))
+			}
+			for i, want := range tt.want {
+				if issues[i].Path != want.Path || issues[i].Severity != want.Severity {
+					t.Errorf("issue %d = %+v, want path %q severity %s", i, issues[i], want.Path, want.Severity)
+				}
+				if issues[i].Line == 0 {
+					t.Errorf("issue %d has no line: %+v", i, issues[i])
+				}
+			}
+			if HasErrors(issues) != HasErrors(tt.want) {
+				t.Errorf("HasErrors() = %v", HasErrors(issues))
+			}
+		})
+	}
+}
+
+func TestValidateEmptyDocument(t *testing.T) {
+	doc, err := Parse([]byte(""))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if issues := doc.Validate(); !HasErrors(issues) {
+		t.Errorf("Validate() = %v, want an error", issues)
+	}
+}
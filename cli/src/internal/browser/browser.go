@@ -0,0 +1,44 @@
+package browser
+
+import (
+	"fmt"
+	"net/url"
+	"os/exec"
+	"runtime"
+)
+
+// Open opens the given URL in the user's default web browser.
+// Only http and https URLs are accepted to avoid launching arbitrary handlers.
+func Open(rawURL string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid URL: %w", err)
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return fmt.Errorf("unsupported URL scheme: %s", parsed.Scheme)
+	}
+
+	name, args := command(runtime.GOOS, parsed.String())
+
+	// #nosec G204 -- Command is a fixed platform opener and the URL scheme is validated above
+	cmd := exec.Command(name, args...)
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to open browser: %w", err)
+	}
+
+	// Release the opener process; we don't care about its exit status
+	go func() { _ = cmd.Wait() }()
+	return nil
+}
+
+// command returns the platform-specific command used to open a URL.
+func command(goos string, target string) (string, []string) {
+	switch goos {
+	case "windows":
+		return "rundll32", []string{"url.dll,FileProtocolHandler", target}
+	case "darwin":
+		return "open", []string{target}
+	default:
+		return "xdg-open", []string{target}
+	}
+}
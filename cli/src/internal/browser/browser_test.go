@@ -0,0 +1,45 @@
+package browser
+
+import (
+	"testing"
+)
+
+func TestCommand(t *testing.T) {
+	tests := []struct {
+		goos     string
+		wantName string
+	}{
+		{"windows", "rundll32"},
+		{"darwin", "open"},
+		{"linux", "xdg-open"},
+		{"freebsd", "xdg-open"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.goos, func(t *testing.T) {
+			name, args := command(tt.goos, "https://example.com")
+			if name != tt.wantName {
+				t.Errorf("command(%q) name = %q, want %q", tt.goos, name, tt.wantName)
+			}
+			if len(args) == 0 || args[len(args)-1] != "https://example.com" {
+				t.Errorf("command(%q) args = %v, want URL as last argument", tt.goos, args)
+			}
+		})
+	}
+}
+
+func TestOpenRejectsUnsupportedSchemes(t *testing.T) {
+	tests := []string{
+		"file:///etc/passwd",
+		"javascript:alert(1)",
+		"ftp://example.com",
+	}
+
+	for _, rawURL := range tests {
+		t.Run(rawURL, func(t *testing.T) {
+			if err := Open(rawURL); err == nil {
+				t.Errorf("Open(%q) expected error, got nil", rawURL)
+			}
+		})
+	}
+}
@@ -8,6 +8,8 @@ import (
 	"os"
 	"path/filepath"
 	"time"
+
+	"github.com/jongio/azd-app/cli/src/internal/statedir"
 )
 
 // ReqsCache represents cached reqs check results.
@@ -35,31 +37,31 @@ type CacheManager struct {
 	cacheDir string
 }
 
-// NewCacheManager creates a new cache manager.
+// NewCacheManager creates a new cache manager for the workspace containing the current directory.
+// The cache lives in the workspace's state directory, outside the project.
 func NewCacheManager() (*CacheManager, error) {
-	// Find .azure directory in current or parent directories
 	cwd, err := os.Getwd()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get current directory: %w", err)
 	}
 
-	azureDir := findAzureDir(cwd)
-	if azureDir == "" {
-		// Create .azure directory in current directory if not found
-		azureDir = filepath.Join(cwd, ".azure")
-		if err := os.MkdirAll(azureDir, 0750); err != nil {
-			return nil, fmt.Errorf("failed to create .azure directory: %w", err)
-		}
-	}
-
-	cacheDir := filepath.Join(azureDir, "cache")
-	if err := os.MkdirAll(cacheDir, 0750); err != nil {
-		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	cacheDir, err := statedir.CacheDir(WorkspaceRoot(cwd))
+	if err != nil {
+		return nil, err
 	}
 
 	return &CacheManager{cacheDir: cacheDir}, nil
 }
 
+// WorkspaceRoot returns the workspace containing startDir: the nearest directory with a
+// .azure folder, or startDir itself when there is none.
+func WorkspaceRoot(startDir string) string {
+	if azureDir := findAzureDir(startDir); azureDir != "" {
+		return filepath.Dir(azureDir)
+	}
+	return startDir
+}
+
 // findAzureDir searches for .azure directory in current and parent directories.
 // It stops at filesystem boundaries and does not search in user home directory.
 func findAzureDir(startDir string) string {
@@ -155,7 +157,7 @@ func (cm *CacheManager) SaveResults(azureYamlPath string, results []CachedReqRes
 	}
 
 	cacheFile := filepath.Join(cm.cacheDir, "reqs_cache.json")
-	if err := os.WriteFile(cacheFile, data, 0600); err != nil {
+	if err := statedir.WriteFile(cacheFile, data); err != nil {
 		return fmt.Errorf("failed to write cache file: %w", err)
 	}
 
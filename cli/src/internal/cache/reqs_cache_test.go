@@ -6,78 +6,70 @@ import (
 	"path/filepath"
 	"testing"
 	"time"
+
+	"github.com/jongio/azd-app/cli/src/internal/statedir"
 )
 
 func TestNewCacheManager(t *testing.T) {
-	// Create a temporary directory
-	tempDir := t.TempDir()
-
-	// Change to temp directory
-	originalDir, err := os.Getwd()
-	if err != nil {
-		t.Fatalf("failed to get working directory: %v", err)
-	}
-	defer os.Chdir(originalDir)
-
-	if err := os.Chdir(tempDir); err != nil {
-		t.Fatalf("failed to change directory: %v", err)
-	}
-
-	// Test creating cache manager in new directory
-	cm, err := NewCacheManager()
-	if err != nil {
-		t.Fatalf("NewCacheManager() failed: %v", err)
-	}
-
-	if cm == nil {
-		t.Fatal("NewCacheManager() returned nil")
-	}
-
-	// Verify .azure/cache directory was created
-	azureDir := filepath.Join(tempDir, ".azure")
-	if _, err := os.Stat(azureDir); os.IsNotExist(err) {
-		t.Errorf(".azure directory was not created")
-	}
-
-	cacheDir := filepath.Join(azureDir, "cache")
-	if _, err := os.Stat(cacheDir); os.IsNotExist(err) {
-		t.Errorf(".azure/cache directory was not created")
-	}
-}
-
-func TestNewCacheManagerExistingAzureDir(t *testing.T) {
-	// Create a temporary directory with .azure
-	tempDir := t.TempDir()
-	azureDir := filepath.Join(tempDir, ".azure")
-	if err := os.MkdirAll(azureDir, 0750); err != nil {
-		t.Fatalf("failed to create .azure directory: %v", err)
-	}
+	stateRoot := t.TempDir()
+	t.Setenv(statedir.RootEnvVar, stateRoot)
 
-	// Change to temp directory
-	originalDir, err := os.Getwd()
-	if err != nil {
-		t.Fatalf("failed to get working directory: %v", err)
+	tests := []struct {
+		name     string
+		azureDir bool
+		startDir string
+	}{
+		{name: "no .azure directory", startDir: "."},
+		{name: "existing .azure directory", azureDir: true, startDir: "."},
+		{name: "subdirectory of workspace", azureDir: true, startDir: filepath.Join("src", "api")},
 	}
-	defer os.Chdir(originalDir)
 
-	if err := os.Chdir(tempDir); err != nil {
-		t.Fatalf("failed to change directory: %v", err)
-	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Resolve symlinks so the path matches os.Getwd (e.g. /var on macOS)
+			tempDir, err := filepath.EvalSymlinks(t.TempDir())
+			if err != nil {
+				t.Fatalf("failed to resolve temp directory: %v", err)
+			}
+			if tt.azureDir {
+				if err := os.MkdirAll(filepath.Join(tempDir, ".azure"), 0750); err != nil {
+					t.Fatalf("failed to create .azure directory: %v", err)
+				}
+			}
+			startDir := filepath.Join(tempDir, tt.startDir)
+			if err := os.MkdirAll(startDir, 0750); err != nil {
+				t.Fatalf("failed to create start directory: %v", err)
+			}
 
-	// Test creating cache manager with existing .azure directory
-	cm, err := NewCacheManager()
-	if err != nil {
-		t.Fatalf("NewCacheManager() failed: %v", err)
-	}
+			originalDir, err := os.Getwd()
+			if err != nil {
+				t.Fatalf("failed to get working directory: %v", err)
+			}
+			defer os.Chdir(originalDir)
+			if err := os.Chdir(startDir); err != nil {
+				t.Fatalf("failed to change directory: %v", err)
+			}
 
-	if cm == nil {
-		t.Fatal("NewCacheManager() returned nil")
-	}
+			cm, err := NewCacheManager()
+			if err != nil {
+				t.Fatalf("NewCacheManager() failed: %v", err)
+			}
 
-	// Verify cache directory was created inside existing .azure
-	cacheDir := filepath.Join(azureDir, "cache")
-	if _, err := os.Stat(cacheDir); os.IsNotExist(err) {
-		t.Errorf(".azure/cache directory was not created")
+			// The cache belongs to the workspace and lives outside the project
+			wsDir, err := statedir.WorkspaceDir(tempDir)
+			if err != nil {
+				t.Fatalf("WorkspaceDir failed: %v", err)
+			}
+			if cm.cacheDir != filepath.Join(wsDir, "cache") {
+				t.Errorf("cacheDir = %s, want %s", cm.cacheDir, filepath.Join(wsDir, "cache"))
+			}
+			if _, err := os.Stat(cm.cacheDir); err != nil {
+				t.Errorf("cache directory was not created: %v", err)
+			}
+			if _, err := os.Stat(filepath.Join(tempDir, ".azure", "cache")); !os.IsNotExist(err) {
+				t.Error("cache should not be created under .azure")
+			}
+		})
 	}
 }
 
@@ -0,0 +1,150 @@
+package chaos
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Action is a disruption applied to a service.
+type Action string
+
+const (
+	// ActionKill terminates the service process and leaves it stopped.
+	ActionKill Action = "kill"
+	// ActionPause suspends the service process and resumes it after the pause duration.
+	ActionPause Action = "pause"
+	// ActionRestart stops the service and starts it again.
+	ActionRestart Action = "restart"
+)
+
+const (
+	// DefaultInterval is the default time between disruptions.
+	DefaultInterval = 30 * time.Second
+	// DefaultPauseDuration is the default time a paused service stays suspended.
+	DefaultPauseDuration = 10 * time.Second
+)
+
+// AllActions lists every supported action.
+var AllActions = []Action{ActionKill, ActionPause, ActionRestart}
+
+// Target is a set of running services that chaos actions can be applied to.
+type Target interface {
+	// Services returns the names of services that are currently running.
+	Services() []string
+	Kill(name string) error
+	Pause(name string, d time.Duration) error
+	Restart(name string) error
+}
+
+// Config controls a chaos session.
+type Config struct {
+	Interval      time.Duration
+	PauseDuration time.Duration
+	Actions       []Action
+	Exclude       []string
+}
+
+// Event describes a single disruption.
+type Event struct {
+	Time    time.Time `json:"time"`
+	Service string    `json:"service"`
+	Action  Action    `json:"action"`
+	Error   string    `json:"error,omitempty"`
+}
+
+// ParseActions converts action names into Actions, rejecting unknown names.
+func ParseActions(names []string) ([]Action, error) {
+	if len(names) == 0 {
+		return AllActions, nil
+	}
+
+	actions := make([]Action, 0, len(names))
+	for _, name := range names {
+		action := Action(strings.ToLower(strings.TrimSpace(name)))
+		switch action {
+		case ActionKill, ActionPause, ActionRestart:
+			actions = append(actions, action)
+		default:
+			return nil, fmt.Errorf("unknown chaos action '%s' (valid: kill, pause, restart)", name)
+		}
+	}
+	return actions, nil
+}
+
+// Candidates returns the services eligible for disruption, sorted by name.
+func Candidates(services []string, exclude []string) []string {
+	excluded := make(map[string]bool, len(exclude))
+	for _, name := range exclude {
+		excluded[strings.TrimSpace(name)] = true
+	}
+
+	var candidates []string
+	for _, name := range services {
+		if !excluded[name] {
+			candidates = append(candidates, name)
+		}
+	}
+	sort.Strings(candidates)
+	return candidates
+}
+
+// Run disrupts a random eligible service every interval until ctx is cancelled.
+// onEvent is called after each action is applied.
+func Run(ctx context.Context, target Target, cfg Config, onEvent func(Event)) {
+	if cfg.Interval <= 0 {
+		cfg.Interval = DefaultInterval
+	}
+	if cfg.PauseDuration <= 0 {
+		cfg.PauseDuration = DefaultPauseDuration
+	}
+	if len(cfg.Actions) == 0 {
+		cfg.Actions = AllActions
+	}
+
+	// #nosec G404 -- Random service selection doesn't need a cryptographic RNG
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+
+	ticker := time.NewTicker(cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			candidates := Candidates(target.Services(), cfg.Exclude)
+			if len(candidates) == 0 {
+				continue
+			}
+			event := Event{
+				Time:    time.Now(),
+				Service: candidates[rng.Intn(len(candidates))],
+				Action:  cfg.Actions[rng.Intn(len(cfg.Actions))],
+			}
+			if err := apply(target, event, cfg.PauseDuration); err != nil {
+				event.Error = err.Error()
+			}
+			if onEvent != nil {
+				onEvent(event)
+			}
+		}
+	}
+}
+
+// apply performs the event's action against the target.
+func apply(target Target, event Event, pauseDuration time.Duration) error {
+	switch event.Action {
+	case ActionKill:
+		return target.Kill(event.Service)
+	case ActionPause:
+		return target.Pause(event.Service, pauseDuration)
+	case ActionRestart:
+		return target.Restart(event.Service)
+	default:
+		return fmt.Errorf("unknown chaos action '%s'", event.Action)
+	}
+}
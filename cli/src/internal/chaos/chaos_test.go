@@ -0,0 +1,121 @@
+package chaos
+
+import (
+	"context"
+	"os/exec"
+	"reflect"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeTarget records the actions applied to it.
+type fakeTarget struct {
+	mu       sync.Mutex
+	services []string
+	applied  map[string][]Action
+}
+
+func (f *fakeTarget) Services() []string { return f.services }
+
+func (f *fakeTarget) record(name string, action Action) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.applied[name] = append(f.applied[name], action)
+	return nil
+}
+
+func (f *fakeTarget) Kill(name string) error                   { return f.record(name, ActionKill) }
+func (f *fakeTarget) Pause(name string, _ time.Duration) error { return f.record(name, ActionPause) }
+func (f *fakeTarget) Restart(name string) error                { return f.record(name, ActionRestart) }
+
+func TestParseActions(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   []string
+		want    []Action
+		wantErr bool
+	}{
+		{"default", nil, AllActions, false},
+		{"subset", []string{"kill", " Pause "}, []Action{ActionKill, ActionPause}, false},
+		{"unknown", []string{"explode"}, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseActions(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseActions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseActions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCandidates(t *testing.T) {
+	got := Candidates([]string{"web", "db", "api"}, []string{"db"})
+	want := []string{"api", "web"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Candidates() = %v, want %v", got, want)
+	}
+
+	if got := Candidates([]string{"db"}, []string{"db"}); len(got) != 0 {
+		t.Errorf("Candidates() = %v, want none", got)
+	}
+}
+
+func TestRunRespectsExclusions(t *testing.T) {
+	target := &fakeTarget{
+		services: []string{"web", "api", "db"},
+		applied:  make(map[string][]Action),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	var events []Event
+	Run(ctx, target, Config{
+		Interval: 10 * time.Millisecond,
+		Actions:  []Action{ActionRestart},
+		Exclude:  []string{"db"},
+	}, func(e Event) {
+		events = append(events, e)
+	})
+
+	if len(events) == 0 {
+		t.Fatal("Run() applied no actions")
+	}
+	if len(target.applied["db"]) != 0 {
+		t.Errorf("excluded service was disrupted: %v", target.applied["db"])
+	}
+	for _, e := range events {
+		if e.Action != ActionRestart {
+			t.Errorf("unexpected action %q", e.Action)
+		}
+	}
+}
+
+func TestSuspendResume(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("pausing processes is not supported on Windows")
+	}
+
+	cmd := exec.Command("sleep", "5")
+	if err := cmd.Start(); err != nil {
+		t.Skipf("sleep not available: %v", err)
+	}
+	defer func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	}()
+
+	if err := Suspend(cmd.Process); err != nil {
+		t.Fatalf("Suspend() error = %v", err)
+	}
+	if err := Resume(cmd.Process); err != nil {
+		t.Fatalf("Resume() error = %v", err)
+	}
+}
@@ -0,0 +1,18 @@
+//go:build !windows
+
+package chaos
+
+import (
+	"os"
+	"syscall"
+)
+
+// Suspend stops a process until Resume is called.
+func Suspend(process *os.Process) error {
+	return process.Signal(syscall.SIGSTOP)
+}
+
+// Resume continues a process stopped by Suspend.
+func Resume(process *os.Process) error {
+	return process.Signal(syscall.SIGCONT)
+}
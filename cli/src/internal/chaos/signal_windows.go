@@ -0,0 +1,20 @@
+//go:build windows
+
+package chaos
+
+import (
+	"fmt"
+	"os"
+)
+
+// Suspend stops a process until Resume is called.
+// Suspending processes is not supported on Windows.
+func Suspend(process *os.Process) error {
+	return fmt.Errorf("pausing processes is not supported on Windows")
+}
+
+// Resume continues a process stopped by Suspend.
+// Suspending processes is not supported on Windows.
+func Resume(process *os.Process) error {
+	return fmt.Errorf("pausing processes is not supported on Windows")
+}
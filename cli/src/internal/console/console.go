@@ -0,0 +1,163 @@
+// Package console provides the line editing, history, and completion used by the
+// interactive console.
+package console
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jongio/azd-app/cli/src/internal/security"
+)
+
+// DefaultHistorySize is the number of history entries kept on disk.
+const DefaultHistorySize = 500
+
+// History holds previously entered console lines.
+type History struct {
+	path    string
+	max     int
+	entries []string
+}
+
+// LoadHistory reads history from path, keeping at most max entries.
+// A missing file yields an empty history, and an empty path one that is never saved.
+func LoadHistory(path string, max int) (*History, error) {
+	history := &History{path: path, max: max}
+	if path == "" {
+		return history, nil
+	}
+	if err := security.ValidatePath(path); err != nil {
+		return nil, fmt.Errorf("invalid history path: %w", err)
+	}
+
+	// #nosec G304 -- Path validated by security.ValidatePath
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return history, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		history.Add(line)
+	}
+	return history, nil
+}
+
+// Add appends a line to the history. Blank lines and repeats of the previous line are skipped.
+func (h *History) Add(line string) {
+	line = strings.TrimSpace(line)
+	if line == "" || (len(h.entries) > 0 && h.entries[len(h.entries)-1] == line) {
+		return
+	}
+	h.entries = append(h.entries, line)
+	if h.max > 0 && len(h.entries) > h.max {
+		h.entries = h.entries[len(h.entries)-h.max:]
+	}
+}
+
+// Entries returns the history, oldest first.
+func (h *History) Entries() []string {
+	return h.entries
+}
+
+// Save writes the history to disk. Histories without a path are not saved.
+func (h *History) Save() error {
+	if h.path == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(h.path), 0750); err != nil {
+		return fmt.Errorf("failed to create history directory: %w", err)
+	}
+	data := strings.Join(h.entries, "\n") + "\n"
+	if err := os.WriteFile(h.path, []byte(data), 0600); err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	return nil
+}
+
+// Expand replaces a history reference with the line it refers to: "!!" is the previous
+// line, "!n" is entry n as numbered by the history command, and "!text" is the most recent
+// line starting with text. Other lines are returned unchanged.
+func (h *History) Expand(line string) (string, error) {
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, "!") || line == "!" {
+		return line, nil
+	}
+	ref := line[1:]
+
+	if ref == "!" {
+		if len(h.entries) == 0 {
+			return "", fmt.Errorf("history is empty")
+		}
+		return h.entries[len(h.entries)-1], nil
+	}
+	if n, err := strconv.Atoi(ref); err == nil {
+		if n < 1 || n > len(h.entries) {
+			return "", fmt.Errorf("no history entry %d", n)
+		}
+		return h.entries[n-1], nil
+	}
+	for i := len(h.entries) - 1; i >= 0; i-- {
+		if strings.HasPrefix(h.entries[i], ref) {
+			return h.entries[i], nil
+		}
+	}
+	return "", fmt.Errorf("no history entry starts with %q", ref)
+}
+
+// Completer returns the candidates for the word being typed, given the complete words
+// before it. Candidates need not be filtered by prefix.
+type Completer func(words []string, prefix string) []string
+
+// Complete completes the last word of line. A single match is completed in full and
+// followed by a space; several matches extend the word to their common prefix and are
+// returned so they can be listed.
+func Complete(line string, completer Completer) (string, []string) {
+	if completer == nil {
+		return line, nil
+	}
+
+	words := strings.Fields(line)
+	prefix := ""
+	if len(words) > 0 && !strings.HasSuffix(line, " ") {
+		prefix = words[len(words)-1]
+		words = words[:len(words)-1]
+	}
+
+	var matches []string
+	seen := make(map[string]bool)
+	for _, candidate := range completer(words, prefix) {
+		if strings.HasPrefix(candidate, prefix) && !seen[candidate] {
+			matches = append(matches, candidate)
+			seen[candidate] = true
+		}
+	}
+	sort.Strings(matches)
+
+	base := strings.TrimSuffix(line, prefix)
+	switch len(matches) {
+	case 0:
+		return line, nil
+	case 1:
+		return base + matches[0] + " ", nil
+	default:
+		return base + commonPrefix(matches), matches
+	}
+}
+
+// commonPrefix returns the longest prefix shared by all values.
+func commonPrefix(values []string) string {
+	prefix := values[0]
+	for _, value := range values[1:] {
+		for !strings.HasPrefix(value, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	return prefix
+}
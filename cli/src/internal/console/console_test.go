@@ -0,0 +1,127 @@
+package console
+
+import (
+	"bytes"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".azure", "console_history")
+	history, err := LoadHistory(path, 3)
+	if err != nil {
+		t.Fatalf("LoadHistory failed: %v", err)
+	}
+
+	for _, line := range []string{"start api", "start api", "  ", "logs api", "status", "env set A=1"} {
+		history.Add(line)
+	}
+	want := []string{"logs api", "status", "env set A=1"}
+	if strings.Join(history.Entries(), "|") != strings.Join(want, "|") {
+		t.Errorf("Entries() = %q, want %q", history.Entries(), want)
+	}
+
+	if err := history.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	reloaded, err := LoadHistory(path, 3)
+	if err != nil {
+		t.Fatalf("LoadHistory after save failed: %v", err)
+	}
+	if strings.Join(reloaded.Entries(), "|") != strings.Join(want, "|") {
+		t.Errorf("reloaded Entries() = %q, want %q", reloaded.Entries(), want)
+	}
+}
+
+func TestHistoryExpand(t *testing.T) {
+	history := &History{}
+	for _, line := range []string{"start api", "logs api -f", "status"} {
+		history.Add(line)
+	}
+
+	tests := []struct {
+		line    string
+		want    string
+		wantErr bool
+	}{
+		{"!!", "status", false},
+		{"!1", "start api", false},
+		{"!logs", "logs api -f", false},
+		{"status", "status", false},
+		{"!", "!", false},
+		{"!9", "", true},
+		{"!restart", "", true},
+	}
+	for _, tt := range tests {
+		got, err := history.Expand(tt.line)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Expand(%q) error = %v, wantErr %v", tt.line, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Expand(%q) = %q, want %q", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestComplete(t *testing.T) {
+	completer := func(words []string, prefix string) []string {
+		if len(words) == 0 {
+			return []string{"start", "status", "stop", "logs"}
+		}
+		return []string{"api", "web", "worker"}
+	}
+
+	tests := []struct {
+		line           string
+		want           string
+		wantCandidates []string
+	}{
+		{"lo", "logs ", nil},
+		{"sta", "sta", []string{"start", "status"}},
+		{"s", "st", []string{"start", "status", "stop"}},
+		{"start w", "start w", []string{"web", "worker"}},
+		{"start wo", "start worker ", nil},
+		{"start ", "start ", []string{"api", "web", "worker"}},
+		{"xyz", "xyz", nil},
+	}
+	for _, tt := range tests {
+		got, candidates := Complete(tt.line, completer)
+		if got != tt.want {
+			t.Errorf("Complete(%q) = %q, want %q", tt.line, got, tt.want)
+		}
+		if strings.Join(candidates, " ") != strings.Join(tt.wantCandidates, " ") {
+			t.Errorf("Complete(%q) candidates = %q, want %q", tt.line, candidates, tt.wantCandidates)
+		}
+	}
+}
+
+func TestReaderPlain(t *testing.T) {
+	var out bytes.Buffer
+	reader := NewReader(strings.NewReader("start api\r\nstatus\nexit"), &out, nil, nil)
+	if reader.Interactive() {
+		t.Fatal("reader over a string should not be interactive")
+	}
+
+	var lines []string
+	for {
+		line, err := reader.ReadLine("> ")
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("ReadLine failed: %v", err)
+		}
+		lines = append(lines, line)
+	}
+
+	want := []string{"start api", "status", "exit"}
+	if strings.Join(lines, "|") != strings.Join(want, "|") {
+		t.Errorf("lines = %q, want %q", lines, want)
+	}
+	if out.Len() != 0 {
+		t.Errorf("non-interactive reader should not print prompts, got %q", out.String())
+	}
+}
@@ -0,0 +1,198 @@
+package console
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// ErrInterrupted is returned by ReadLine when the user presses Ctrl+C.
+var ErrInterrupted = errors.New("interrupted")
+
+// Key codes handled by the line editor.
+const (
+	keyCtrlC     = 3
+	keyCtrlD     = 4
+	keyBackspace = 8
+	keyTab       = 9
+	keyCtrlL     = 12
+	keyCtrlU     = 21
+	keyEscape    = 27
+	keyDelete    = 127
+)
+
+// Reader reads console lines. On a terminal that supports it, lines are edited in raw
+// mode with history recall (Up/Down) and Tab completion; otherwise lines are read as-is,
+// which is how scripts piped to the console are read.
+type Reader struct {
+	in          *bufio.Reader
+	out         io.Writer
+	fd          uintptr
+	interactive bool
+	history     *History
+	completer   Completer
+}
+
+// NewReader creates a reader for in that echoes prompts and edits to out.
+func NewReader(in io.Reader, out io.Writer, history *History, completer Completer) *Reader {
+	r := &Reader{
+		in:        bufio.NewReader(in),
+		out:       out,
+		history:   history,
+		completer: completer,
+	}
+	if file, ok := in.(*os.File); ok {
+		if info, err := file.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 {
+			r.fd = file.Fd()
+			r.interactive = true
+		}
+	}
+	return r
+}
+
+// MakeRaw puts the terminal fd into raw mode, so keys are read one at a time and Ctrl+C is
+// read rather than sent as a signal, and returns a function that restores the previous mode.
+// It fails on platforms without raw mode.
+func MakeRaw(fd uintptr) (func(), error) {
+	return makeRaw(fd)
+}
+
+// Interactive reports whether lines are read from a terminal.
+func (r *Reader) Interactive() bool {
+	return r.interactive
+}
+
+// ReadLine shows prompt and reads one line. It returns io.EOF when input ends and
+// ErrInterrupted when the line is abandoned with Ctrl+C.
+func (r *Reader) ReadLine(prompt string) (string, error) {
+	if !r.interactive {
+		return r.readPlain()
+	}
+
+	restore, err := makeRaw(r.fd)
+	if err != nil {
+		// Terminals without raw mode still get a prompt, just without editing
+		fmt.Fprint(r.out, prompt)
+		return r.readPlain()
+	}
+	defer restore()
+
+	return r.edit(prompt)
+}
+
+// readPlain reads a line without editing.
+func (r *Reader) readPlain() (string, error) {
+	line, err := r.in.ReadString('\n')
+	if err == io.EOF && line != "" {
+		err = nil
+	}
+	return strings.TrimRight(line, "\r\n"), err
+}
+
+// edit reads a line in raw mode.
+func (r *Reader) edit(prompt string) (string, error) {
+	var entries []string
+	if r.history != nil {
+		entries = r.history.Entries()
+	}
+	line := []rune{}
+	pos := len(entries) // position in history; len(entries) is the line being typed
+	draft := ""
+
+	redraw := func() {
+		fmt.Fprintf(r.out, "\r\033[K%s%s", prompt, string(line))
+	}
+	recall := func(to int) {
+		if to < 0 || to > len(entries) || to == pos {
+			return
+		}
+		if pos == len(entries) {
+			draft = string(line)
+		}
+		pos = to
+		if pos == len(entries) {
+			line = []rune(draft)
+		} else {
+			line = []rune(entries[pos])
+		}
+		redraw()
+	}
+
+	fmt.Fprint(r.out, prompt)
+	for {
+		key, _, err := r.in.ReadRune()
+		if err != nil {
+			fmt.Fprint(r.out, "\r\n")
+			return "", err
+		}
+
+		switch key {
+		case '\r', '\n':
+			fmt.Fprint(r.out, "\r\n")
+			return string(line), nil
+		case keyCtrlC:
+			fmt.Fprint(r.out, "^C\r\n")
+			return "", ErrInterrupted
+		case keyCtrlD:
+			if len(line) == 0 {
+				fmt.Fprint(r.out, "\r\n")
+				return "", io.EOF
+			}
+		case keyBackspace, keyDelete:
+			if len(line) > 0 {
+				line = line[:len(line)-1]
+				redraw()
+			}
+		case keyCtrlU:
+			line = line[:0]
+			redraw()
+		case keyCtrlL:
+			fmt.Fprint(r.out, "\033[H\033[2J")
+			redraw()
+		case keyTab:
+			completed, candidates := Complete(string(line), r.completer)
+			line = []rune(completed)
+			if len(candidates) > 1 {
+				fmt.Fprintf(r.out, "\r\n%s\r\n", strings.Join(candidates, "  "))
+			}
+			redraw()
+		case keyEscape:
+			switch r.readEscape() {
+			case 'A':
+				recall(pos - 1)
+			case 'B':
+				recall(pos + 1)
+			}
+		default:
+			if key >= ' ' {
+				line = append(line, key)
+				fmt.Fprint(r.out, string(key))
+			}
+		}
+	}
+}
+
+// readEscape reads the rest of an escape sequence and returns its final byte for
+// cursor keys ("\033[A" or "\033OA"), or 0 for other sequences.
+func (r *Reader) readEscape() rune {
+	next, _, err := r.in.ReadRune()
+	if err != nil || (next != '[' && next != 'O') {
+		return 0
+	}
+	for {
+		key, _, err := r.in.ReadRune()
+		if err != nil {
+			return 0
+		}
+		// Parameters and intermediates precede the final byte, e.g. "\033[3~"
+		if key >= '@' && key <= '~' {
+			if key == 'A' || key == 'B' || key == 'C' || key == 'D' {
+				return key
+			}
+			return 0
+		}
+	}
+}
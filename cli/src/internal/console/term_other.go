@@ -0,0 +1,10 @@
+//go:build !linux && !darwin
+
+package console
+
+import "errors"
+
+// makeRaw is not supported on this platform, so lines are read without editing.
+func makeRaw(fd uintptr) (func(), error) {
+	return nil, errors.New("raw terminal mode is not supported on this platform")
+}
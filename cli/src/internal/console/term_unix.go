@@ -0,0 +1,41 @@
+//go:build linux || darwin
+
+package console
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// makeRaw puts the terminal into raw mode so keys can be read one at a time, and returns
+// a function that restores the previous mode. Output processing is left on so "\n" still
+// starts a new line.
+func makeRaw(fd uintptr) (func(), error) {
+	var previous syscall.Termios
+	if err := ioctlTermios(fd, ioctlGetTermios, &previous); err != nil {
+		return nil, err
+	}
+
+	raw := previous
+	raw.Iflag &^= syscall.ICRNL | syscall.IXON
+	raw.Lflag &^= syscall.ECHO | syscall.ICANON | syscall.ISIG | syscall.IEXTEN
+	raw.Cc[syscall.VMIN] = 1
+	raw.Cc[syscall.VTIME] = 0
+	if err := ioctlTermios(fd, ioctlSetTermios, &raw); err != nil {
+		return nil, err
+	}
+
+	return func() {
+		_ = ioctlTermios(fd, ioctlSetTermios, &previous)
+	}, nil
+}
+
+// ioctlTermios gets or sets the terminal attributes of fd.
+func ioctlTermios(fd, request uintptr, termios *syscall.Termios) error {
+	// #nosec G103 -- The ioctl interface requires a pointer to the termios struct
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, request, uintptr(unsafe.Pointer(termios)))
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package container
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Distributions that provide a container engine. Colima and Rancher Desktop run the Docker
+// engine in a VM behind a socket of their own; Podman is daemonless and usually rootless.
+const (
+	DistributionDockerDesktop  = "Docker Desktop"
+	DistributionDockerEngine   = "Docker Engine"
+	DistributionColima         = "Colima"
+	DistributionRancherDesktop = "Rancher Desktop"
+	DistributionPodman         = "Podman"
+)
+
+// Info describes a reachable engine.
+type Info struct {
+	Distribution string // One of the Distribution constants
+	Rootless     bool   // Containers run as an unprivileged user, so ports below 1024 usually can't be published
+}
+
+// engineOutput runs the engine CLI and returns its standard output; tests replace it.
+var engineOutput = func(ctx context.Context, e Engine, args ...string) ([]byte, error) {
+	return e.Command(ctx, args...).Output()
+}
+
+// unprivilegedPortStart returns the first port an unprivileged process may bind on this machine.
+// Variable for testing.
+var unprivilegedPortStart = func() int {
+	data, err := os.ReadFile("/proc/sys/net/ipv4/ip_unprivileged_port_start")
+	if err != nil {
+		return 1024
+	}
+	port, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return 1024
+	}
+	return port
+}
+
+// inspected caches Inspect by engine, since it runs the CLI twice.
+var inspected sync.Map
+
+// Inspect returns the distribution of the engine and whether it runs rootless. The Docker CLI
+// talks to Docker Desktop, Colima, Rancher Desktop, and Podman alike, so the distribution is told
+// from the docker context and the socket it points at.
+func (e Engine) Inspect(ctx context.Context) (Info, error) {
+	if cached, ok := inspected.Load(e); ok {
+		return cached.(Info), nil
+	}
+
+	var info Info
+	if e.Name == EnginePodman {
+		out, err := engineOutput(ctx, e, "info", "--format", "{{.Host.Security.Rootless}}")
+		if err != nil {
+			return Info{}, fmt.Errorf("failed to inspect %s: %w", e, err)
+		}
+		info = Info{Distribution: DistributionPodman, Rootless: strings.TrimSpace(string(out)) == "true"}
+	} else {
+		out, err := engineOutput(ctx, e, "info", "--format", "{{.OperatingSystem}}|{{range .SecurityOptions}}{{.}};{{end}}")
+		if err != nil {
+			return Info{}, fmt.Errorf("failed to inspect %s: %w", e, err)
+		}
+		system, security, _ := strings.Cut(strings.TrimSpace(string(out)), "|")
+
+		contextName, host := "", e.Host
+		if host == "" {
+			// The current context names the endpoint when DOCKER_HOST is not set
+			if out, err := engineOutput(ctx, e, "context", "inspect", "--format", "{{.Name}}|{{.Endpoints.docker.Host}}"); err == nil {
+				contextName, host, _ = strings.Cut(strings.TrimSpace(string(out)), "|")
+			}
+		}
+		info.Distribution = distribution(contextName, host, system)
+		info.Rootless = strings.Contains(security, "name=rootless") ||
+			(info.Distribution == DistributionPodman && strings.Contains(host, "/run/user/"))
+	}
+
+	inspected.Store(e, info)
+	return info, nil
+}
+
+// distribution tells the distribution of a Docker engine from the name of its docker context,
+// its endpoint, and the operating system it reports.
+func distribution(contextName, host, system string) string {
+	host = strings.ToLower(host)
+	switch {
+	case contextName == "colima" || strings.HasPrefix(contextName, "colima-") || strings.Contains(host, "/.colima/"):
+		return DistributionColima
+	case contextName == "rancher-desktop" || strings.Contains(host, "/.rd/") || strings.Contains(host, "rancher-desktop") ||
+		strings.Contains(system, "Rancher Desktop"):
+		return DistributionRancherDesktop
+	case strings.Contains(host, "podman"):
+		return DistributionPodman
+	case contextName == "desktop-linux" || strings.Contains(host, "/.docker/desktop/") || strings.Contains(host, "/.docker/run/") ||
+		strings.Contains(host, "docker_engine") || strings.Contains(system, "Docker Desktop"):
+		return DistributionDockerDesktop
+	default:
+		return DistributionDockerEngine
+	}
+}
+
+// CheckPorts returns an error naming the ports that a rootless engine on this machine can't
+// publish, because they are below the first port unprivileged processes may bind. Engines in a
+// VM (Docker Desktop, Colima, Rancher Desktop, Podman machines) and remote engines publish
+// ports elsewhere and are not affected.
+func (e Engine) CheckPorts(ctx context.Context, ports []int) error {
+	if runtime.GOOS != "linux" || e.IsRemote() || len(ports) == 0 {
+		return nil
+	}
+	info, err := e.Inspect(ctx)
+	if err != nil || !info.Rootless {
+		return nil // Check reports engines that can't be reached
+	}
+
+	start := unprivilegedPortStart()
+	var blocked []string
+	sorted := append([]int(nil), ports...)
+	sort.Ints(sorted)
+	for _, port := range sorted {
+		if port < start {
+			blocked = append(blocked, strconv.Itoa(port))
+		}
+	}
+	if len(blocked) == 0 {
+		return nil
+	}
+	return fmt.Errorf("rootless %s can't publish port %s (ports below %d are privileged): use a higher port, or allow it with 'sysctl net.ipv4.ip_unprivileged_port_start=%s'",
+		e.Name, strings.Join(blocked, ", "), start, blocked[0])
+}
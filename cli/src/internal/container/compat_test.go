@@ -0,0 +1,118 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeEngineOutput replaces engineOutput with one that answers the given subcommands, keyed
+// by their first argument, and clears the Inspect cache.
+func fakeEngineOutput(t *testing.T, answers map[string]string) {
+	t.Helper()
+	original := engineOutput
+	engineOutput = func(_ context.Context, _ Engine, args ...string) ([]byte, error) {
+		if answer, ok := answers[args[0]]; ok {
+			return []byte(answer), nil
+		}
+		return nil, errors.New("unknown command")
+	}
+	inspected = sync.Map{}
+	t.Cleanup(func() {
+		engineOutput = original
+		inspected = sync.Map{}
+	})
+}
+
+func TestInspect(t *testing.T) {
+	tests := []struct {
+		name    string
+		engine  Engine
+		answers map[string]string
+		want    Info
+	}{
+		{
+			name:    "colima context",
+			engine:  Engine{Name: EngineDocker},
+			answers: map[string]string{"info": "Ubuntu 24.04 LTS|name=seccomp,profile=builtin;", "context": "colima|unix:///Users/me/.colima/default/docker.sock"},
+			want:    Info{Distribution: DistributionColima},
+		},
+		{
+			name:    "rancher desktop socket",
+			engine:  Engine{Name: EngineDocker, Host: "unix:///Users/me/.rd/docker.sock"},
+			answers: map[string]string{"info": "Alpine Linux v3.20|"},
+			want:    Info{Distribution: DistributionRancherDesktop},
+		},
+		{
+			name:    "docker desktop",
+			engine:  Engine{Name: EngineDocker},
+			answers: map[string]string{"info": "Docker Desktop|name=seccomp,profile=unconfined;", "context": "desktop-linux|unix:///Users/me/.docker/run/docker.sock"},
+			want:    Info{Distribution: DistributionDockerDesktop},
+		},
+		{
+			name:    "rootless docker engine",
+			engine:  Engine{Name: EngineDocker},
+			answers: map[string]string{"info": "Ubuntu 22.04|name=seccomp,profile=builtin;name=rootless;", "context": "rootless|unix:///run/user/1000/docker.sock"},
+			want:    Info{Distribution: DistributionDockerEngine, Rootless: true},
+		},
+		{
+			name:    "docker CLI on a rootless podman socket",
+			engine:  Engine{Name: EngineDocker, Host: "unix:///run/user/1000/podman/podman.sock"},
+			answers: map[string]string{"info": "fedora|"},
+			want:    Info{Distribution: DistributionPodman, Rootless: true},
+		},
+		{
+			name:    "rootless podman",
+			engine:  Engine{Name: EnginePodman},
+			answers: map[string]string{"info": "true\n"},
+			want:    Info{Distribution: DistributionPodman, Rootless: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeEngineOutput(t, tt.answers)
+			got, err := tt.engine.Inspect(context.Background())
+			if err != nil {
+				t.Fatalf("Inspect() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Inspect() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckPorts(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("rootless port limits only apply on Linux")
+	}
+	original := unprivilegedPortStart
+	unprivilegedPortStart = func() int { return 1024 }
+	t.Cleanup(func() { unprivilegedPortStart = original })
+
+	rootless := Engine{Name: EnginePodman}
+	fakeEngineOutput(t, map[string]string{"info": "true"})
+	if err := rootless.CheckPorts(context.Background(), []int{3000, 8080}); err != nil {
+		t.Errorf("CheckPorts(3000, 8080) error = %v", err)
+	}
+	err := rootless.CheckPorts(context.Background(), []int{3000, 443, 80})
+	if err == nil || !strings.Contains(err.Error(), "port 80, 443") {
+		t.Errorf("CheckPorts(3000, 443, 80) error = %v, want it to name ports 80 and 443", err)
+	}
+
+	rootful := Engine{Name: EnginePodman}
+	fakeEngineOutput(t, map[string]string{"info": "false"})
+	if err := rootful.CheckPorts(context.Background(), []int{80}); err != nil {
+		t.Errorf("CheckPorts(80) of rootful engine error = %v", err)
+	}
+
+	remote := Engine{Name: EnginePodman, Host: "ssh://me@build-box"}
+	fakeEngineOutput(t, map[string]string{"info": "true"})
+	if err := remote.CheckPorts(context.Background(), []int{80}); err != nil {
+		t.Errorf("CheckPorts(80) of remote engine error = %v", err)
+	}
+}
@@ -0,0 +1,165 @@
+// Package container resolves the container engine that the container-based features of azd app,
+// such as sandbox mode and data snapshots, run on: Docker or Podman, on this machine or on a
+// remote host.
+package container
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Container engines, named by their CLI.
+const (
+	EngineDocker = "docker"
+	EnginePodman = "podman"
+)
+
+// Environment variables that choose the engine. Without them, the docker CLI is used if it is
+// installed, and podman otherwise, each with the host its own environment variable sets.
+const (
+	EngineEnv = "AZD_APP_CONTAINER_ENGINE" // "docker" or "podman"
+	HostEnv   = "AZD_APP_CONTAINER_HOST"   // Engine endpoint, e.g. ssh://me@build-box or tcp://10.0.0.5:2375
+)
+
+// lookPath finds a CLI on the PATH; tests replace it.
+var lookPath = exec.LookPath
+
+// Engine is a container engine and where it runs.
+type Engine struct {
+	Name string // EngineDocker or EnginePodman, which is also the CLI that is run
+	Host string // Optional: endpoint of the engine, e.g. unix:///run/podman/podman.sock or ssh://me@build-box
+
+	// explicit is set when Host comes from HostEnv, so it is passed to the CLI, which reads the
+	// other variables itself
+	explicit bool
+}
+
+// Current returns the engine configured by the environment.
+func Current() (Engine, error) {
+	name := os.Getenv(EngineEnv)
+	switch name {
+	case EngineDocker, EnginePodman:
+	case "":
+		name = EngineDocker
+		if _, err := lookPath(EngineDocker); err != nil {
+			if _, err := lookPath(EnginePodman); err == nil {
+				name = EnginePodman
+			}
+		}
+	default:
+		return Engine{}, fmt.Errorf("invalid %s: %s (must be '%s' or '%s')", EngineEnv, name, EngineDocker, EnginePodman)
+	}
+
+	engine := Engine{Name: name}
+	if host := os.Getenv(HostEnv); host != "" {
+		engine.Host, engine.explicit = host, true
+	} else if name == EngineDocker {
+		engine.Host = os.Getenv("DOCKER_HOST")
+	} else {
+		engine.Host = os.Getenv("CONTAINER_HOST")
+	}
+	if engine.Host != "" {
+		if _, err := url.Parse(engine.Host); err != nil {
+			return Engine{}, fmt.Errorf("invalid container engine host %s: %w", engine.Host, err)
+		}
+	}
+	return engine, nil
+}
+
+// Args returns args prefixed by the global flag that points the CLI at the configured host.
+func (e Engine) Args(args ...string) []string {
+	if !e.explicit {
+		return args
+	}
+	flag := "--host"
+	if e.Name == EnginePodman {
+		flag = "--url"
+	}
+	return append([]string{flag, e.Host}, args...)
+}
+
+// Command returns the command that runs the engine CLI with args.
+func (e Engine) Command(ctx context.Context, args ...string) *exec.Cmd {
+	// #nosec G204 -- Runs the docker or podman CLI with arguments built by azd app
+	return exec.CommandContext(ctx, e.Name, e.Args(args...)...)
+}
+
+// IsRemote reports whether the engine runs on another machine, so that it can't mount files
+// of this machine and publishes ports on that machine.
+func (e Engine) IsRemote() bool {
+	u, err := url.Parse(e.Host)
+	if err != nil || e.Host == "" {
+		return false
+	}
+	switch u.Scheme {
+	case "tcp", "http", "https":
+		host := u.Hostname()
+		if host == "localhost" {
+			return false
+		}
+		ip := net.ParseIP(host)
+		return ip == nil || !ip.IsLoopback()
+	case "ssh":
+		return true
+	default:
+		return false // unix and npipe sockets
+	}
+}
+
+// RemoteHost returns the name of the machine a remote engine runs on, e.g. build-box for
+// ssh://me@build-box:2222, or "" for a local engine.
+func (e Engine) RemoteHost() string {
+	if !e.IsRemote() {
+		return ""
+	}
+	u, _ := url.Parse(e.Host)
+	return u.Hostname()
+}
+
+// PublishAddress returns the address that containers publish their ports on. Ports of a
+// remote engine reached over TCP must be published on all interfaces of its machine to be
+// forwarded; ports of other engines stay on the loopback interface of theirs.
+func (e Engine) PublishAddress() string {
+	if e.IsRemote() && !strings.HasPrefix(e.Host, "ssh://") {
+		return "0.0.0.0"
+	}
+	return "127.0.0.1"
+}
+
+// Check returns an error if the engine can't be reached, naming the host of a remote one.
+func (e Engine) Check(ctx context.Context) error {
+	out, err := e.Command(ctx, "info").CombinedOutput()
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, exec.ErrNotFound) {
+		// Rancher Desktop with the containerd runtime only provides nerdctl
+		if _, nerdctlErr := lookPath("nerdctl"); nerdctlErr == nil {
+			return fmt.Errorf("%s is not installed; nerdctl is not supported, so switch Rancher Desktop to the dockerd (moby) runtime", e.Name)
+		}
+		return fmt.Errorf("%s is not installed", e.Name)
+	}
+	where := "this machine"
+	if e.Host != "" {
+		where = e.Host
+	}
+	detail := strings.TrimSpace(string(out))
+	if lines := strings.Split(detail, "\n"); len(lines) > 3 {
+		detail = strings.Join(lines[len(lines)-3:], "\n")
+	}
+	return fmt.Errorf("cannot reach the %s engine on %s: %w: %s", e.Name, where, err, detail)
+}
+
+// String describes the engine, e.g. "podman on ssh://me@build-box".
+func (e Engine) String() string {
+	if e.Host == "" {
+		return e.Name
+	}
+	return e.Name + " on " + e.Host
+}
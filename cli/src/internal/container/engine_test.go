@@ -0,0 +1,134 @@
+package container
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCurrent(t *testing.T) {
+	tests := []struct {
+		name      string
+		env       map[string]string
+		installed []string // CLIs on the PATH
+		want      Engine
+		wantArgs  []string // Args("ps")
+		wantErr   string
+	}{
+		{
+			name:      "docker by default",
+			installed: []string{"docker", "podman"},
+			want:      Engine{Name: EngineDocker},
+			wantArgs:  []string{"ps"},
+		},
+		{
+			name:      "podman when docker is not installed",
+			installed: []string{"podman"},
+			want:      Engine{Name: EnginePodman},
+			wantArgs:  []string{"ps"},
+		},
+		{
+			name:     "DOCKER_HOST is read by the docker CLI itself",
+			env:      map[string]string{"DOCKER_HOST": "ssh://me@build-box"},
+			want:     Engine{Name: EngineDocker, Host: "ssh://me@build-box"},
+			wantArgs: []string{"ps"},
+		},
+		{
+			name:     "podman socket",
+			env:      map[string]string{EngineEnv: "podman", "CONTAINER_HOST": "unix:///run/user/1000/podman/podman.sock", "DOCKER_HOST": "tcp://other:2375"},
+			want:     Engine{Name: EnginePodman, Host: "unix:///run/user/1000/podman/podman.sock"},
+			wantArgs: []string{"ps"},
+		},
+		{
+			name:     "explicit host is passed to podman",
+			env:      map[string]string{EngineEnv: "podman", HostEnv: "ssh://me@build-box/run/podman/podman.sock"},
+			want:     Engine{Name: EnginePodman, Host: "ssh://me@build-box/run/podman/podman.sock", explicit: true},
+			wantArgs: []string{"--url", "ssh://me@build-box/run/podman/podman.sock", "ps"},
+		},
+		{
+			name:     "explicit host is passed to docker",
+			env:      map[string]string{EngineEnv: "docker", HostEnv: "tcp://10.0.0.5:2375"},
+			want:     Engine{Name: EngineDocker, Host: "tcp://10.0.0.5:2375", explicit: true},
+			wantArgs: []string{"--host", "tcp://10.0.0.5:2375", "ps"},
+		},
+		{
+			name:    "unknown engine",
+			env:     map[string]string{EngineEnv: "containerd"},
+			wantErr: EngineEnv,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, name := range []string{EngineEnv, HostEnv, "DOCKER_HOST", "CONTAINER_HOST"} {
+				t.Setenv(name, tt.env[name])
+			}
+			installed := tt.installed
+			if installed == nil {
+				installed = []string{"docker"}
+			}
+			original := lookPath
+			lookPath = func(file string) (string, error) {
+				for _, name := range installed {
+					if name == file {
+						return "/usr/bin/" + file, nil
+					}
+				}
+				return "", errors.New("not found")
+			}
+			t.Cleanup(func() { lookPath = original })
+
+			engine, err := Current()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Current() error = %v, want it to mention %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Current() error = %v", err)
+			}
+			if engine != tt.want {
+				t.Errorf("Current() = %+v, want %+v", engine, tt.want)
+			}
+			if got := engine.Args("ps"); !reflect.DeepEqual(got, tt.wantArgs) {
+				t.Errorf("Args(ps) = %v, want %v", got, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestEngineRemote(t *testing.T) {
+	tests := []struct {
+		host        string
+		wantRemote  bool
+		wantHost    string
+		wantPublish string
+	}{
+		{host: "", wantPublish: "127.0.0.1"},
+		{host: "unix:///var/run/docker.sock", wantPublish: "127.0.0.1"},
+		{host: "npipe:////./pipe/docker_engine", wantPublish: "127.0.0.1"},
+		{host: "tcp://127.0.0.1:2375", wantPublish: "127.0.0.1"},
+		{host: "tcp://localhost:2375", wantPublish: "127.0.0.1"},
+		{host: "tcp://10.0.0.5:2376", wantRemote: true, wantHost: "10.0.0.5", wantPublish: "0.0.0.0"},
+		{host: "ssh://me@build-box:2222", wantRemote: true, wantHost: "build-box", wantPublish: "127.0.0.1"},
+	}
+
+	for _, tt := range tests {
+		engine := Engine{Name: EngineDocker, Host: tt.host}
+		if got := engine.IsRemote(); got != tt.wantRemote {
+			t.Errorf("IsRemote(%q) = %v, want %v", tt.host, got, tt.wantRemote)
+		}
+		if got := engine.RemoteHost(); got != tt.wantHost {
+			t.Errorf("RemoteHost(%q) = %q, want %q", tt.host, got, tt.wantHost)
+		}
+		if got := engine.PublishAddress(); got != tt.wantPublish {
+			t.Errorf("PublishAddress(%q) = %q, want %q", tt.host, got, tt.wantPublish)
+		}
+	}
+
+	if got := (Engine{Name: EnginePodman, Host: "ssh://me@build-box"}).String(); got != "podman on ssh://me@build-box" {
+		t.Errorf("String() = %q", got)
+	}
+}
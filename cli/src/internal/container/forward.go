@@ -0,0 +1,180 @@
+package container
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os/exec"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// forwardDialTimeout is the longest wait for a connection to a port of a remote engine.
+const forwardDialTimeout = 10 * time.Second
+
+// Forwarder forwards ports of this machine to the same ports on the machine of a remote engine,
+// so services in its containers are reached at localhost as if they ran here.
+type Forwarder struct {
+	listeners []net.Listener
+	tunnel    *exec.Cmd // ssh process, for engines reached over SSH
+	wg        sync.WaitGroup
+	closeOnce sync.Once
+
+	mu     sync.Mutex
+	conns  map[net.Conn]struct{} // Open connections, on both sides
+	closed bool
+}
+
+// Forward forwards ports to the machine of the engine until Close is called. A local engine
+// publishes its ports on this machine, so nothing is forwarded. Ports of an engine reached
+// over SSH are tunneled by ssh; others are proxied to the host of the engine.
+func (e Engine) Forward(ports []int) (*Forwarder, error) {
+	f := &Forwarder{}
+	if !e.IsRemote() || len(ports) == 0 {
+		return f, nil
+	}
+	u, err := url.Parse(e.Host)
+	if err != nil {
+		return nil, fmt.Errorf("invalid container engine host %s: %w", e.Host, err)
+	}
+	if u.Scheme == "ssh" {
+		return f, f.startTunnel(u, ports)
+	}
+	for _, port := range ports {
+		if err := f.proxy("127.0.0.1", u.Hostname(), port); err != nil {
+			_ = f.Close()
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+// Close stops forwarding and waits for open connections to end.
+func (f *Forwarder) Close() error {
+	if f == nil {
+		return nil
+	}
+	f.closeOnce.Do(func() {
+		for _, l := range f.listeners {
+			_ = l.Close()
+		}
+		f.mu.Lock()
+		f.closed = true
+		for conn := range f.conns {
+			_ = conn.Close()
+		}
+		f.mu.Unlock()
+		if f.tunnel != nil && f.tunnel.Process != nil {
+			_ = f.tunnel.Process.Kill()
+			_ = f.tunnel.Wait()
+		}
+	})
+	f.wg.Wait()
+	return nil
+}
+
+// startTunnel forwards ports to the loopback interface of the SSH host u with one ssh process.
+func (f *Forwarder) startTunnel(u *url.URL, ports []int) error {
+	args := []string{"-N", "-o", "ExitOnForwardFailure=yes", "-o", "BatchMode=yes"}
+	if port := u.Port(); port != "" {
+		args = append(args, "-p", port)
+	}
+	for _, port := range ports {
+		args = append(args, "-L", fmt.Sprintf("127.0.0.1:%d:127.0.0.1:%d", port, port))
+	}
+	target := u.Hostname()
+	if u.User != nil {
+		target = u.User.Username() + "@" + target
+	}
+	args = append(args, target)
+
+	// #nosec G204 -- Runs ssh with the host of the configured engine and numeric ports
+	f.tunnel = exec.Command("ssh", args...)
+	if err := f.tunnel.Start(); err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return fmt.Errorf("ssh is required to forward ports of the container engine on %s", u.Hostname())
+		}
+		return fmt.Errorf("failed to forward ports to %s: %w", u.Hostname(), err)
+	}
+	return nil
+}
+
+// proxy accepts connections on port of localHost and relays each to the same port of remoteHost.
+func (f *Forwarder) proxy(localHost, remoteHost string, port int) error {
+	listener, err := net.Listen("tcp", net.JoinHostPort(localHost, strconv.Itoa(port)))
+	if err != nil {
+		return fmt.Errorf("failed to forward port %d: %w", port, err)
+	}
+	f.listeners = append(f.listeners, listener)
+	remote := net.JoinHostPort(remoteHost, strconv.Itoa(port))
+
+	f.wg.Add(1)
+	go func() {
+		defer f.wg.Done()
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return // Closed
+			}
+			f.wg.Add(1)
+			go func() {
+				defer f.wg.Done()
+				f.relay(conn, remote)
+			}()
+		}
+	}()
+	return nil
+}
+
+// relay copies data between conn and a new connection to remote until either side closes.
+func (f *Forwarder) relay(conn net.Conn, remote string) {
+	if !f.track(conn) {
+		return
+	}
+	defer f.untrack(conn)
+	upstream, err := net.DialTimeout("tcp", remote, forwardDialTimeout)
+	if err != nil {
+		return
+	}
+	if !f.track(upstream) {
+		return
+	}
+	defer f.untrack(upstream)
+
+	done := make(chan struct{}, 2)
+	go func() {
+		_, _ = io.Copy(upstream, conn)
+		done <- struct{}{}
+	}()
+	go func() {
+		_, _ = io.Copy(conn, upstream)
+		done <- struct{}{}
+	}()
+	<-done
+}
+
+// track records conn so that Close closes it, or closes it and returns false if Close was called.
+func (f *Forwarder) track(conn net.Conn) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		_ = conn.Close()
+		return false
+	}
+	if f.conns == nil {
+		f.conns = make(map[net.Conn]struct{})
+	}
+	f.conns[conn] = struct{}{}
+	return true
+}
+
+// untrack closes conn and forgets it.
+func (f *Forwarder) untrack(conn net.Conn) {
+	f.mu.Lock()
+	delete(f.conns, conn)
+	f.mu.Unlock()
+	_ = conn.Close()
+}
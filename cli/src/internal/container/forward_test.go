@@ -0,0 +1,74 @@
+package container
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"runtime"
+	"strconv"
+	"testing"
+)
+
+func TestForwardLocalEngine(t *testing.T) {
+	f, err := Engine{Name: EngineDocker}.Forward([]int{8080})
+	if err != nil {
+		t.Fatalf("Forward() error = %v", err)
+	}
+	if len(f.listeners) != 0 || f.tunnel != nil {
+		t.Error("Forward() forwards the ports of a local engine")
+	}
+	if err := f.Close(); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+}
+
+func TestForwarderProxy(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("needs a second loopback address")
+	}
+
+	// The "remote" service listens on 127.0.0.2, and is forwarded to the same port on 127.0.0.1
+	remote, err := net.Listen("tcp", "127.0.0.2:0")
+	if err != nil {
+		t.Skipf("127.0.0.2 unavailable: %v", err)
+	}
+	defer remote.Close()
+	port := remote.Addr().(*net.TCPAddr).Port
+	go func() {
+		for {
+			conn, err := remote.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				line, _ := bufio.NewReader(conn).ReadString('\n')
+				fmt.Fprintf(conn, "echo: %s", line)
+			}()
+		}
+	}()
+
+	f := &Forwarder{}
+	if err := f.proxy("127.0.0.1", "127.0.0.2", port); err != nil {
+		t.Skipf("port %d is in use locally: %v", port, err)
+	}
+
+	conn, err := net.Dial("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(port)))
+	if err != nil {
+		t.Fatalf("failed to connect to the forwarded port: %v", err)
+	}
+	fmt.Fprintln(conn, "hello")
+	reply, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil || reply != "echo: hello\n" {
+		t.Errorf("reply = %q, %v; want the remote service's echo", reply, err)
+	}
+
+	// Close ends open connections too
+	if err := f.Close(); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+	_ = conn.Close()
+	if _, err := net.Dial("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(port))); err == nil {
+		t.Error("the port is still forwarded after Close()")
+	}
+}
@@ -0,0 +1,245 @@
+// Package daemon runs 'azd app run' in the background: it starts the session detached from the
+// terminal, records its PID and the PIDs and ports of its services in a state file of the
+// workspace, and stops it again.
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/jongio/azd-app/cli/src/internal/statedir"
+)
+
+// ChildEnv is set in the environment of the detached session, so that it runs in the
+// foreground of its own process instead of detaching again.
+const ChildEnv = "AZD_APP_DAEMON"
+
+// dataKind names the workspace data directory of the state and log files (see statedir.DataDir).
+const dataKind = "daemon"
+
+// File names in the data directory.
+const (
+	stateFileName = "state.json"
+	logFileName   = "run.log"
+)
+
+// pollInterval is how often Wait and Stop check the session.
+const pollInterval = 250 * time.Millisecond
+
+// startSlack is how much later than StartedAt the process of a session may have started, since
+// StartedAt is recorded once the process runs, and start times are read at coarse precision.
+const startSlack = 5 * time.Second
+
+// State describes the background session of a workspace.
+type State struct {
+	PID       int       `json:"pid"`       // Process of the session
+	StartedAt time.Time `json:"startedAt"` // When the session was started
+	Args      []string  `json:"args"`      // Arguments of the session, e.g. ["run", "--service", "api"]
+	LogFile   string    `json:"logFile"`   // File that receives the output of the session
+	Ready     bool      `json:"ready"`     // All services started and passed their readiness checks
+	Services  []Service `json:"services,omitempty"`
+}
+
+// Service is a service of the background session.
+type Service struct {
+	Name string `json:"name"`
+	PID  int    `json:"pid"`
+	Port int    `json:"port,omitempty"`
+	URL  string `json:"url,omitempty"`
+}
+
+// IsChild reports whether this process is a detached session.
+func IsChild() bool {
+	return os.Getenv(ChildEnv) == "1"
+}
+
+// statePath returns the state file of projectDir without creating anything.
+func statePath(projectDir string) (string, error) {
+	dir, err := statedir.DataPath(projectDir, dataKind)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, stateFileName), nil
+}
+
+// Load returns the state of the background session of projectDir, or nil when there is none.
+// The state of a session whose process has exited is removed, and nil is returned, so Stop never
+// signals a process that reused its PID.
+func Load(projectDir string) (*State, error) {
+	path, err := statePath(projectDir)
+	if err != nil {
+		return nil, err
+	}
+	// #nosec G304 -- State file in the workspace state directory
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read background session state: %w", err)
+	}
+	var state State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if !state.alive() {
+		_ = os.Remove(path)
+		return nil, nil
+	}
+	return &state, nil
+}
+
+// alive reports whether the session still runs: its PID is alive and, where the start time of
+// the process can be read, it started by StartedAt, so that a process that reused the PID after
+// the session exited isn't taken for it.
+func (s *State) alive() bool {
+	if !statedir.ProcessAlive(s.PID) {
+		return false
+	}
+	started, ok := processStartTime(s.PID)
+	return !ok || !started.After(s.StartedAt.Add(startSlack))
+}
+
+// save writes state as the state of the background session of projectDir.
+func save(projectDir string, state *State) error {
+	dir, err := statedir.DataDir(projectDir, dataKind)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return statedir.WriteFile(filepath.Join(dir, stateFileName), data)
+}
+
+// Start starts 'azd app' with args in projectDir, detached from the terminal, and records it
+// as the background session. Its output goes to run.log in the workspace state. It returns an
+// error when a background session of the workspace is already running.
+func Start(projectDir string, args []string) (*State, error) {
+	if existing, err := Load(projectDir); err != nil {
+		return nil, err
+	} else if existing != nil {
+		return nil, fmt.Errorf("a background session is already running (PID %d) - stop it with 'azd app stop'", existing.PID)
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find the azd app executable: %w", err)
+	}
+	dir, err := statedir.DataDir(projectDir, dataKind)
+	if err != nil {
+		return nil, err
+	}
+	logFile := filepath.Join(dir, logFileName)
+	// #nosec G304 -- Log file in the workspace state directory
+	log, err := os.OpenFile(logFile, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", logFile, err)
+	}
+	defer log.Close()
+
+	// #nosec G204 -- Runs this executable again with the arguments it was given
+	cmd := exec.Command(executable, args...)
+	cmd.Dir = projectDir
+	cmd.Env = append(os.Environ(), ChildEnv+"=1")
+	cmd.Stdout = log
+	cmd.Stderr = log
+	detach(cmd)
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start background session: %w", err)
+	}
+
+	state := &State{PID: cmd.Process.Pid, StartedAt: time.Now(), Args: args, LogFile: logFile}
+	if err := save(projectDir, state); err != nil {
+		_ = cmd.Process.Kill()
+		return nil, err
+	}
+	// Reap the session if it exits while this process waits for it, so that it doesn't linger
+	// as a zombie that still looks alive; the session outlives this process otherwise
+	go func() { _ = cmd.Wait() }()
+	return state, nil
+}
+
+// MarkReady records the services of the background session once they are ready. It does
+// nothing unless this process is the session the state describes.
+func MarkReady(projectDir string, services []Service) error {
+	state, err := Load(projectDir)
+	if err != nil || state == nil || state.PID != os.Getpid() {
+		return err
+	}
+	state.Ready = true
+	state.Services = services
+	return save(projectDir, state)
+}
+
+// Clear removes the state of the background session when this process is that session, as it
+// exits.
+func Clear(projectDir string) {
+	state, err := Load(projectDir)
+	if err != nil || state == nil || state.PID != os.Getpid() {
+		return
+	}
+	if path, err := statePath(projectDir); err == nil {
+		_ = os.Remove(path)
+	}
+}
+
+// Wait waits until the background session started as pid has started its services, and
+// returns its state. It returns an error when the session exits first or ctx is done.
+func Wait(ctx context.Context, projectDir string, pid int) (*State, error) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		state, err := Load(projectDir)
+		if err != nil {
+			return nil, err
+		}
+		if state == nil || state.PID != pid {
+			return nil, errors.New("the background session exited before its services were ready")
+		}
+		if state.Ready {
+			return state, nil
+		}
+		select {
+		case <-ctx.Done():
+			return state, context.Cause(ctx)
+		case <-ticker.C:
+		}
+	}
+}
+
+// Stop stops the background session of projectDir and returns the state it had, or nil when
+// none is running. The session is asked to shut down, which stops its services; a session
+// still running after timeout is killed together with the services it recorded.
+func Stop(projectDir string, timeout time.Duration) (*State, error) {
+	state, err := Load(projectDir)
+	if err != nil || state == nil {
+		return nil, err
+	}
+
+	if err := terminate(state.PID); err != nil {
+		return state, fmt.Errorf("failed to stop background session (PID %d): %w", state.PID, err)
+	}
+	deadline := time.Now().Add(timeout)
+	for statedir.ProcessAlive(state.PID) && time.Now().Before(deadline) {
+		time.Sleep(pollInterval)
+	}
+	if statedir.ProcessAlive(state.PID) {
+		kill(state.PID)
+		for _, svc := range state.Services {
+			kill(svc.PID)
+		}
+	}
+
+	if path, err := statePath(projectDir); err == nil {
+		_ = os.Remove(path)
+	}
+	return state, nil
+}
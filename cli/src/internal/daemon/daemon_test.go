@@ -0,0 +1,134 @@
+package daemon
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/jongio/azd-app/cli/src/internal/statedir"
+)
+
+// exitedPID returns the PID of a process that has exited.
+func exitedPID(t *testing.T) int {
+	t.Helper()
+	executable, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command(executable, "-test.run=^$")
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("failed to run test binary: %v", err)
+	}
+	return cmd.Process.Pid
+}
+
+func TestLoad(t *testing.T) {
+	t.Setenv(statedir.RootEnvVar, t.TempDir())
+	projectDir := t.TempDir()
+
+	state, err := Load(projectDir)
+	if err != nil || state != nil {
+		t.Fatalf("Load() without a session = %v, %v, want nil, nil", state, err)
+	}
+
+	if err := save(projectDir, &State{PID: os.Getpid(), StartedAt: time.Now(), Args: []string{"run"}}); err != nil {
+		t.Fatal(err)
+	}
+	state, err = Load(projectDir)
+	if err != nil || state == nil || state.PID != os.Getpid() {
+		t.Fatalf("Load() = %+v, %v, want the session of this process", state, err)
+	}
+
+	// The state of a session that has exited is removed
+	if err := save(projectDir, &State{PID: exitedPID(t)}); err != nil {
+		t.Fatal(err)
+	}
+	if state, err := Load(projectDir); err != nil || state != nil {
+		t.Fatalf("Load() of an exited session = %+v, %v, want nil, nil", state, err)
+	}
+	path, _ := statePath(projectDir)
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("state file of an exited session was not removed: %v", err)
+	}
+
+	// A live process that started after the session, having reused its PID, isn't the session
+	if _, ok := processStartTime(os.Getpid()); !ok {
+		t.Skip("process start times can't be read on this platform")
+	}
+	if err := save(projectDir, &State{PID: os.Getpid(), StartedAt: time.Now().Add(-24 * time.Hour)}); err != nil {
+		t.Fatal(err)
+	}
+	if state, err := Load(projectDir); err != nil || state != nil {
+		t.Fatalf("Load() of a session whose PID was reused = %+v, %v, want nil, nil", state, err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("state file of a session whose PID was reused was not removed: %v", err)
+	}
+}
+
+func TestProcessStartTime(t *testing.T) {
+	started, ok := processStartTime(os.Getpid())
+	if !ok {
+		t.Skip("process start times can't be read on this platform")
+	}
+	if started.After(time.Now()) || time.Since(started) > time.Hour {
+		t.Errorf("processStartTime() = %v, want shortly before now", started)
+	}
+	if _, ok := processStartTime(exitedPID(t)); ok {
+		t.Error("processStartTime() of an exited process succeeded")
+	}
+}
+
+func TestMarkReadyAndClear(t *testing.T) {
+	t.Setenv(statedir.RootEnvVar, t.TempDir())
+	projectDir := t.TempDir()
+	if err := save(projectDir, &State{PID: os.Getpid(), StartedAt: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+
+	services := []Service{{Name: "api", PID: 42, Port: 8080, URL: "http://localhost:8080"}}
+	if err := MarkReady(projectDir, services); err != nil {
+		t.Fatalf("MarkReady() error = %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	state, err := Wait(ctx, projectDir, os.Getpid())
+	if err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+	if !state.Ready || len(state.Services) != 1 || state.Services[0].Port != 8080 {
+		t.Errorf("Wait() = %+v, want the ready session with service api", state)
+	}
+
+	Clear(projectDir)
+	if state, _ := Load(projectDir); state != nil {
+		t.Errorf("Load() after Clear() = %+v, want nil", state)
+	}
+	if _, err := Wait(ctx, projectDir, os.Getpid()); err == nil {
+		t.Error("Wait() after Clear() succeeded, want an error")
+	}
+}
+
+func TestMarkReadyOfAnotherSession(t *testing.T) {
+	t.Setenv(statedir.RootEnvVar, t.TempDir())
+	projectDir := t.TempDir()
+
+	// Record a live process other than this one, the parent of the test, as the session
+	if err := save(projectDir, &State{PID: os.Getppid(), StartedAt: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := MarkReady(projectDir, []Service{{Name: "api"}}); err != nil {
+		t.Fatalf("MarkReady() error = %v", err)
+	}
+	Clear(projectDir)
+	state, err := Load(projectDir)
+	if err != nil || state == nil {
+		t.Fatalf("Load() = %v, %v, want the other session to remain", state, err)
+	}
+	if state.Ready {
+		t.Error("MarkReady() marked the session of another process as ready")
+	}
+}
@@ -0,0 +1,35 @@
+//go:build windows
+
+package daemon
+
+import (
+	"os/exec"
+	"strconv"
+	"syscall"
+)
+
+// Process creation flags that detach the session from the console that started it.
+const (
+	createNewProcessGroup = 0x00000200
+	detachedProcess       = 0x00000008
+)
+
+// detach starts cmd without a console, so that it keeps running when the terminal that
+// started it is closed.
+func detach(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{CreationFlags: createNewProcessGroup | detachedProcess}
+}
+
+// terminate ends the session with pid and the services it started. Windows can't send a Ctrl+C
+// to a process without a console, so the process tree is killed.
+func terminate(pid int) error {
+	// #nosec G204 -- the PID is an integer from the state file
+	return exec.Command("taskkill", "/T", "/F", "/PID", strconv.Itoa(pid)).Run()
+}
+
+// kill ends pid and the processes it spawned.
+func kill(pid int) {
+	if pid > 0 {
+		_ = terminate(pid)
+	}
+}
@@ -0,0 +1,17 @@
+package daemon
+
+import (
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// processStartTime returns when the process with pid started, from its kinfo_proc.
+func processStartTime(pid int) (time.Time, bool) {
+	info, err := unix.SysctlKinfoProc("kern.proc.pid", pid)
+	if err != nil || info.Proc.P_pid != int32(pid) {
+		return time.Time{}, false
+	}
+	started := info.Proc.P_starttime
+	return time.Unix(started.Sec, int64(started.Usec)*int64(time.Microsecond)), true
+}
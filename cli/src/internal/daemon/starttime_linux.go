@@ -0,0 +1,61 @@
+package daemon
+
+import (
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// clockTicks is the unit of process start times in /proc, USER_HZ, which is 100 on every
+// architecture Linux supports.
+const clockTicks = 100
+
+// processStartTime returns when the process with pid started, from the ticks since boot in
+// /proc/<pid>/stat and the boot time in /proc/stat.
+func processStartTime(pid int) (time.Time, bool) {
+	data, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/stat")
+	if err != nil {
+		return time.Time{}, false
+	}
+	// The command name in parentheses may hold spaces, so fields are counted after it; the
+	// start time is field 22, the 20th after the name
+	end := strings.LastIndexByte(string(data), ')')
+	if end < 0 {
+		return time.Time{}, false
+	}
+	fields := strings.Fields(string(data[end+1:]))
+	if len(fields) < 20 {
+		return time.Time{}, false
+	}
+	ticks, err := strconv.ParseInt(fields[19], 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	boot, ok := bootTime()
+	if !ok {
+		return time.Time{}, false
+	}
+	return boot.Add(time.Duration(ticks) * time.Second / clockTicks), true
+}
+
+// bootTime returns when the system booted, from the btime line of /proc/stat.
+func bootTime() (time.Time, bool) {
+	file, err := os.Open("/proc/stat")
+	if err != nil {
+		return time.Time{}, false
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if value, ok := strings.CutPrefix(scanner.Text(), "btime "); ok {
+			seconds, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+			if err != nil {
+				return time.Time{}, false
+			}
+			return time.Unix(seconds, 0), true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,10 @@
+//go:build !linux && !darwin && !windows
+
+package daemon
+
+import "time"
+
+// processStartTime can't read process start times on this platform.
+func processStartTime(int) (time.Time, bool) {
+	return time.Time{}, false
+}
@@ -0,0 +1,25 @@
+package daemon
+
+import (
+	"syscall"
+	"time"
+)
+
+// processQueryLimitedInformation is the access right that GetProcessTimes needs, which is
+// granted for processes of other users too.
+const processQueryLimitedInformation = 0x1000
+
+// processStartTime returns when the process with pid started, from its creation time.
+func processStartTime(pid int) (time.Time, bool) {
+	// #nosec G115 -- PIDs are positive and fit in 32 bits on Windows
+	handle, err := syscall.OpenProcess(processQueryLimitedInformation, false, uint32(pid))
+	if err != nil {
+		return time.Time{}, false
+	}
+	defer func() { _ = syscall.CloseHandle(handle) }()
+	var creation, exit, kernel, user syscall.Filetime
+	if err := syscall.GetProcessTimes(handle, &creation, &exit, &kernel, &user); err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(0, creation.Nanoseconds()), true
+}
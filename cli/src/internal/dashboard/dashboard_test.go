@@ -2,10 +2,12 @@ package dashboard
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/jongio/azd-app/cli/src/internal/registry"
@@ -528,3 +530,171 @@ func TestBroadcastUpdate_NilServices(t *testing.T) {
 	// Should not panic with nil services
 	srv.BroadcastUpdate(nil)
 }
+
+func TestHandleGetHealth(t *testing.T) {
+	srv := GetServer(t.TempDir())
+
+	w := httptest.NewRecorder()
+	srv.handleGetHealth(w, httptest.NewRequest("GET", "/api/health", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET status = %d, want 200", w.Code)
+	}
+	var statuses []map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &statuses); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(statuses) != 0 {
+		t.Errorf("statuses = %v, want none before services are probed", statuses)
+	}
+}
+
+func TestHandleVirtualServices(t *testing.T) {
+	tempDir := t.TempDir()
+	azureYamlContent := `name: test-project
+services:
+  web:
+    language: js
+    project: ./
+`
+	if err := os.WriteFile(filepath.Join(tempDir, "azure.yaml"), []byte(azureYamlContent), 0600); err != nil {
+		t.Fatalf("Failed to create azure.yaml: %v", err)
+	}
+	srv := GetServer(tempDir)
+
+	// Register
+	body := strings.NewReader(`{"name": "api", "url": "http://localhost:1", "language": "go"}`)
+	w := httptest.NewRecorder()
+	srv.handleVirtualServices(w, httptest.NewRequest("POST", "/api/services/virtual", body))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("POST status = %d, want 201: %s", w.Code, w.Body.String())
+	}
+
+	// Invalid registrations are rejected
+	w = httptest.NewRecorder()
+	srv.handleVirtualServices(w, httptest.NewRequest("POST", "/api/services/virtual", strings.NewReader(`{"name": "api"}`)))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("POST without URL status = %d, want 400", w.Code)
+	}
+
+	// Virtual services are listed with the services from azure.yaml
+	w = httptest.NewRecorder()
+	srv.handleGetServices(w, httptest.NewRequest("GET", "/api/services", nil))
+	var services []map[string]interface{}
+	if err := json.NewDecoder(w.Body).Decode(&services); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	found := false
+	for _, svc := range services {
+		if svc["name"] == "api" {
+			found = svc["virtual"] == true
+		}
+	}
+	if len(services) != 2 || !found {
+		t.Errorf("services = %v, want web and the virtual api", services)
+	}
+
+	// Unregister
+	w = httptest.NewRecorder()
+	srv.handleVirtualServices(w, httptest.NewRequest("DELETE", "/api/services/virtual?name=api", nil))
+	if w.Code != http.StatusNoContent {
+		t.Errorf("DELETE status = %d, want 204", w.Code)
+	}
+	w = httptest.NewRecorder()
+	srv.handleVirtualServices(w, httptest.NewRequest("DELETE", "/api/services/virtual?name=web", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("DELETE of a service from azure.yaml status = %d, want 404", w.Code)
+	}
+}
+
+func TestHandleRestartService(t *testing.T) {
+	srv := GetServer(t.TempDir())
+	srv.port = 43210
+	restart := func(target string) (*httptest.ResponseRecorder, *http.Request) {
+		req := httptest.NewRequest("POST", "/api/services/restart?name="+target, nil)
+		req.RemoteAddr = "127.0.0.1:50000"
+		req.Host = "localhost:43210"
+		return httptest.NewRecorder(), req
+	}
+
+	// Without a handler, services can't be restarted
+	w, req := restart("api")
+	srv.handleRestartService(w, req)
+	if w.Code != http.StatusNotImplemented {
+		t.Errorf("POST without handler status = %d, want 501", w.Code)
+	}
+
+	var restarted []string
+	srv.SetRestartHandler(func(name string) error {
+		if name != "api" {
+			return errors.New("service " + name + " is not running")
+		}
+		restarted = append(restarted, name)
+		return nil
+	})
+
+	w, req = restart("api")
+	srv.handleRestartService(w, req)
+	if w.Code != http.StatusNoContent || len(restarted) != 1 {
+		t.Fatalf("POST status = %d, restarted = %v, want 204 and api restarted: %s", w.Code, restarted, w.Body.String())
+	}
+
+	w, req = restart("web")
+	srv.handleRestartService(w, req)
+	if w.Code != http.StatusConflict || !strings.Contains(w.Body.String(), "not running") {
+		t.Errorf("POST of a stopped service = %d %q, want 409 with the reason", w.Code, w.Body.String())
+	}
+
+	w, req = restart("")
+	srv.handleRestartService(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("POST without name status = %d, want 400", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	srv.handleRestartService(w, httptest.NewRequest("GET", "/api/services/restart?name=api", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET status = %d, want 405", w.Code)
+	}
+
+	// Other hosts on the network can't restart services
+	w, req = restart("api")
+	req.RemoteAddr = "192.0.2.1:50000"
+	srv.handleRestartService(w, req)
+	if w.Code != http.StatusForbidden || len(restarted) != 1 {
+		t.Errorf("POST from another host status = %d, want 403 without a restart", w.Code)
+	}
+
+	// Pages of other sites can't restart services
+	w, req = restart("api")
+	req.Header.Set("Origin", "https://attacker.test")
+	srv.handleRestartService(w, req)
+	if w.Code != http.StatusForbidden || len(restarted) != 1 {
+		t.Errorf("cross-origin POST status = %d, want 403 without a restart", w.Code)
+	}
+	w, req = restart("api")
+	req.Header.Set("Origin", "http://"+req.Host)
+	srv.handleRestartService(w, req)
+	if w.Code != http.StatusNoContent {
+		t.Errorf("same-origin POST status = %d, want 204", w.Code)
+	}
+	for _, host := range []string{"127.0.0.1:43210", "[::1]:43210"} {
+		w, req = restart("api")
+		req.Host = host
+		req.Header.Set("Origin", "http://"+host)
+		srv.handleRestartService(w, req)
+		if w.Code != http.StatusNoContent {
+			t.Errorf("POST to %s status = %d, want 204", host, w.Code)
+		}
+	}
+
+	// Pages of sites whose name was rebound to this machine can't restart services
+	for _, host := range []string{"rebind.attacker.test:43210", "localhost:8080", "localhost"} {
+		w, req = restart("api")
+		req.Host = host
+		req.Header.Set("Origin", "http://"+host)
+		srv.handleRestartService(w, req)
+		if w.Code != http.StatusForbidden {
+			t.Errorf("POST to %s status = %d, want 403", host, w.Code)
+		}
+	}
+}
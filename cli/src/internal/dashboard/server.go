@@ -8,10 +8,13 @@ import (
 	"io/fs"
 	"log"
 	"math/big"
+	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -52,6 +55,8 @@ type Server struct {
 	clients    map[*clientConn]bool
 	clientsMu  sync.RWMutex
 	stopChan   chan struct{}
+	restart    func(name string) error // Restarts a service of the session; nil when it can't
+	restartMu  sync.RWMutex
 }
 
 // GetServer returns the dashboard server instance for the specified project.
@@ -112,6 +117,9 @@ func (s *Server) setupRoutes() {
 	// API endpoints (these take precedence over the file server)
 	s.mux.HandleFunc("/api/project", s.handleGetProject)
 	s.mux.HandleFunc("/api/services", s.handleGetServices)
+	s.mux.HandleFunc("/api/services/virtual", s.handleVirtualServices)
+	s.mux.HandleFunc("/api/services/restart", s.handleRestartService)
+	s.mux.HandleFunc("/api/health", s.handleGetHealth)
 	s.mux.HandleFunc("/api/logs", s.handleGetLogs)
 	s.mux.HandleFunc("/api/logs/stream", s.handleLogStream)
 	s.mux.HandleFunc("/api/ws", s.handleWebSocket)
@@ -125,6 +133,9 @@ func (s *Server) setupRoutes() {
 
 // handleGetServices returns services for the current project.
 func (s *Server) handleGetServices(w http.ResponseWriter, r *http.Request) {
+	// Services started outside azd app are not health checked by it otherwise
+	service.CheckVirtualServices(s.projectDir)
+
 	// Use shared serviceinfo package to get merged service data
 	services, err := serviceinfo.GetServiceInfo(s.projectDir)
 	if err != nil {
@@ -139,6 +150,148 @@ func (s *Server) handleGetServices(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// handleGetHealth returns the health of the services probed while they run, as of their last
+// probe.
+func (s *Server) handleGetHealth(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(service.GetHealthManager(s.projectDir).Statuses()); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// handleVirtualServices lists (GET), registers (POST), or unregisters (DELETE ?name=) services
+// that run outside azd app.
+func (s *Server) handleVirtualServices(w http.ResponseWriter, r *http.Request) {
+	var response interface{}
+	status := http.StatusOK
+
+	switch r.Method {
+	case http.MethodGet:
+		service.CheckVirtualServices(s.projectDir)
+		services := service.VirtualServices(s.projectDir)
+		if services == nil {
+			services = []*registry.ServiceRegistryEntry{}
+		}
+		response = services
+	case http.MethodPost:
+		var vs service.VirtualService
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&vs); err != nil {
+			http.Error(w, fmt.Sprintf("Invalid service: %v", err), http.StatusBadRequest)
+			return
+		}
+		entry, err := service.RegisterVirtualService(s.projectDir, vs)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.notifyServicesChanged()
+		response = entry
+		status = http.StatusCreated
+	case http.MethodDelete:
+		if err := service.UnregisterVirtualService(s.projectDir, r.URL.Query().Get("name")); err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		s.notifyServicesChanged()
+		w.WriteHeader(http.StatusNoContent)
+		return
+	default:
+		w.Header().Set("Allow", "GET, POST, DELETE")
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Printf("Warning: Failed to write response: %v", err)
+	}
+}
+
+// SetRestartHandler sets the function that restarts a service of the session when the
+// dashboard asks for it.
+func (s *Server) SetRestartHandler(restart func(name string) error) {
+	s.restartMu.Lock()
+	defer s.restartMu.Unlock()
+	s.restart = restart
+}
+
+// handleRestartService restarts the service named by ?name= (POST). Only clients on this
+// machine and pages served by the dashboard itself may ask, so neither other hosts on the
+// network nor other sites open in the browser can restart services.
+func (s *Server) handleRestartService(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST")
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !fromLoopback(r) || !s.localHost(r) || !sameOrigin(r) {
+		http.Error(w, "Services can only be restarted from this machine and the dashboard", http.StatusForbidden)
+		return
+	}
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		http.Error(w, "Missing service name", http.StatusBadRequest)
+		return
+	}
+
+	s.restartMu.RLock()
+	restart := s.restart
+	s.restartMu.RUnlock()
+	if restart == nil {
+		http.Error(w, "Services can't be restarted from this dashboard", http.StatusNotImplemented)
+		return
+	}
+	if err := restart(name); err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	s.notifyServicesChanged()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// fromLoopback reports whether r was sent from this machine.
+func fromLoopback(r *http.Request) bool {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return false
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// localHost reports whether r was sent to the dashboard by a loopback name, so that a page of
+// another site can't reach it through a DNS name it rebinds to 127.0.0.1.
+func (s *Server) localHost(r *http.Request) bool {
+	host, port, err := net.SplitHostPort(r.Host)
+	if err != nil || port != strconv.Itoa(s.port) {
+		return false
+	}
+	switch host {
+	case "localhost", "127.0.0.1", "::1":
+		return true
+	}
+	return false
+}
+
+// sameOrigin reports whether r has no Origin header, as from curl, or comes from a page of the
+// host it was sent to.
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && u.Host == r.Host
+}
+
+// notifyServicesChanged sends the current services to connected clients.
+func (s *Server) notifyServicesChanged() {
+	if err := s.BroadcastServiceUpdate(s.projectDir); err != nil {
+		log.Printf("Warning: Failed to broadcast service update: %v", err)
+	}
+}
+
 // handleGetProject returns project metadata from azure.yaml.
 func (s *Server) handleGetProject(w http.ResponseWriter, r *http.Request) {
 	azureYaml, err := service.ParseAzureYaml(s.projectDir)
@@ -0,0 +1,200 @@
+package detector
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	"github.com/jongio/azd-app/cli/src/internal/azureyaml"
+	"github.com/jongio/azd-app/cli/src/internal/types"
+)
+
+// sampleDirs are folders of samples and demos; AppHosts in them are less likely to be the one
+// of the workspace.
+var sampleDirs = map[string]bool{
+	"samples":    true,
+	"sample":     true,
+	"examples":   true,
+	"example":    true,
+	"demos":      true,
+	"demo":       true,
+	"playground": true,
+}
+
+// appHostPath is the AppHost selected with --apphost, which overrides detection.
+var appHostPath atomic.Value
+
+// SetAppHostPath selects the AppHost every detector uses: the directory of its project, or
+// its project file. An empty path restores detection.
+func SetAppHostPath(path string) {
+	appHostPath.Store(path)
+}
+
+// AppHostPath returns the AppHost selected with SetAppHostPath, or "".
+func AppHostPath() string {
+	path, _ := appHostPath.Load().(string)
+	return path
+}
+
+// AmbiguousAppHostError is returned when a workspace has several AppHosts and none is more
+// likely than the others.
+type AmbiguousAppHostError struct {
+	Root       string
+	Candidates []types.AspireProject // Most likely first
+}
+
+func (e *AmbiguousAppHostError) Error() string {
+	names := make([]string, 0, len(e.Candidates))
+	for _, c := range e.Candidates {
+		name := c.ProjectFile
+		if rel, err := filepath.Rel(e.Root, name); err == nil {
+			name = filepath.ToSlash(rel)
+		}
+		names = append(names, name)
+	}
+	return fmt.Sprintf("found %d Aspire AppHosts: %s; choose one with --apphost, or with detector.apphost in %s",
+		len(e.Candidates), strings.Join(names, ", "), ConfigFileName)
+}
+
+// appHostCandidate is a project that may be the AppHost of a workspace, with what makes it
+// more or less likely to be.
+type appHostCandidate struct {
+	project    types.AspireProject
+	isAppHost  bool // Uses the Aspire AppHost SDK, rather than only having a Program.cs
+	incidental bool // In a folder of samples, tools, or tests
+	referenced bool // Referenced by a solution of the workspace
+	depth      int
+}
+
+// FindAppHosts returns every Aspire AppHost under rootDir, the most likely first: AppHost
+// projects are preferred to projects that only have a Program.cs, projects outside folders of
+// samples, tools, and tests to those in them, projects a solution of the workspace references
+// to the others, and then shallower projects. The AppHost selected with --apphost or
+// detector.apphost is not taken into account.
+// Only searches within rootDir and does not traverse outside it.
+func FindAppHosts(rootDir string) ([]types.AspireProject, error) {
+	rootDir, err := filepath.Abs(rootDir)
+	if err != nil {
+		return nil, err
+	}
+	m := newAppHostMatcher()
+	err = walkWorkspace(rootDir, m)
+	candidates := m.ranked(rootDir)
+	projects := make([]types.AspireProject, 0, len(candidates))
+	for _, c := range candidates {
+		projects = append(projects, c.project)
+	}
+	return projects, err
+}
+
+// selectedAppHost returns the AppHost selected for rootDir with --apphost or detector.apphost,
+// and whether one is.
+func selectedAppHost(rootDir string) (*types.AspireProject, bool, error) {
+	path, source := appHostSetting(rootDir)
+	if path == "" {
+		return nil, false, nil
+	}
+	project, err := ResolveAppHost(path)
+	if err != nil {
+		return nil, true, fmt.Errorf("invalid AppHost in %s: %w", source, err)
+	}
+	return project, true, nil
+}
+
+// likeliestAppHost returns the most likely of the candidates m found under rootDir, or nil when
+// there are none. It fails when several AppHost projects are equally likely; the most likely
+// one is still returned then.
+func likeliestAppHost(rootDir string, m *appHostMatcher) (*types.AspireProject, error) {
+	candidates := m.ranked(rootDir)
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+	best := candidates[0]
+	var tied []types.AspireProject
+	for _, c := range candidates {
+		if c.isAppHost && c.incidental == best.incidental && c.referenced == best.referenced {
+			tied = append(tied, c.project)
+		}
+	}
+	if len(tied) > 1 {
+		return &best.project, &AmbiguousAppHostError{Root: rootDir, Candidates: tied}
+	}
+	return &best.project, nil
+}
+
+// appHostSetting returns the AppHost selected for rootDir, and where it was selected: with
+// --apphost, or with detector.apphost in .azdapp.yaml, or else in the azure.yaml metadata.
+// Paths in files are relative to rootDir.
+func appHostSetting(rootDir string) (string, string) {
+	if path := AppHostPath(); path != "" {
+		return path, "--apphost"
+	}
+
+	var appConfig struct {
+		Detector Config `yaml:"detector"`
+	}
+	if readYamlFile(filepath.Join(rootDir, ConfigFileName), &appConfig) && appConfig.Detector.AppHost != "" {
+		return filepath.Join(rootDir, filepath.FromSlash(appConfig.Detector.AppHost)), "detector.apphost in " + ConfigFileName
+	}
+	if doc, err := azureyaml.Load(filepath.Join(rootDir, azureyaml.FileName)); err == nil {
+		if metadata := doc.Project.Metadata; metadata != nil && metadata.Detector != nil && metadata.Detector.AppHost != "" {
+			return filepath.Join(rootDir, filepath.FromSlash(metadata.Detector.AppHost)), "metadata.detector.apphost in " + azureyaml.FileName
+		}
+	}
+	return "", ""
+}
+
+// ResolveAppHost returns the AppHost at path: the project file of an AppHost, or the directory
+// of its project. Of several project files in the directory, the one using the Aspire AppHost
+// SDK is used.
+func ResolveAppHost(path string) (*types.AspireProject, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("%s doesn't exist", path)
+	}
+	if !info.IsDir() {
+		if !IsDotnetProjectFile(path) {
+			return nil, fmt.Errorf("%s is not a .NET project file", path)
+		}
+		return &types.AspireProject{Dir: filepath.Dir(path), ProjectFile: path}, nil
+	}
+
+	files, _ := filepath.Glob(filepath.Join(path, "*.csproj"))
+	if len(files) == 0 {
+		return nil, fmt.Errorf("%s has no .csproj project", path)
+	}
+	sort.Strings(files)
+	return &types.AspireProject{Dir: path, ProjectFile: appHostProjectFile(files)}, nil
+}
+
+// appHostProjectFile returns the project file of files that uses the Aspire AppHost SDK, or
+// the first one.
+func appHostProjectFile(files []string) string {
+	for _, file := range files {
+		if project, err := ParseDotnetProject(file); err == nil && project.IsAppHost {
+			return file
+		}
+	}
+	return files[0]
+}
+
+// inSampleDir reports whether dir, below rootDir, is in a folder of samples or demos.
+func inSampleDir(rootDir, dir string) bool {
+	rel, err := filepath.Rel(rootDir, dir)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return false
+	}
+	for _, segment := range strings.Split(filepath.ToSlash(rel), "/") {
+		if sampleDirs[strings.ToLower(segment)] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,134 @@
+package detector
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+const testAppHostProject = `<Project Sdk="Microsoft.NET.Sdk">
+  <Sdk Name="Aspire.AppHost.Sdk" Version="9.1.0" />
+  <PropertyGroup><OutputType>Exe</OutputType></PropertyGroup>
+</Project>`
+
+// writeAppHost writes an AppHost project named after its folder in dir.
+func writeAppHost(t *testing.T, dir string) string {
+	t.Helper()
+	project := filepath.Join(dir, filepath.Base(dir)+".csproj")
+	writeFileContent(t, project, testAppHostProject)
+	writeFileContent(t, filepath.Join(dir, "Program.cs"), "var builder = DistributedApplication.CreateBuilder(args);")
+	return project
+}
+
+func TestFindAppHostPrefersWorkspaceAppHost(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(t *testing.T, root string) string
+	}{
+		{
+			name: "outside samples",
+			setup: func(t *testing.T, root string) string {
+				writeAppHost(t, filepath.Join(root, "samples", "Demo.AppHost"))
+				return writeAppHost(t, filepath.Join(root, "src", "Shop", "Shop.AppHost"))
+			},
+		},
+		{
+			name: "outside tests",
+			setup: func(t *testing.T, root string) string {
+				writeAppHost(t, filepath.Join(root, "tests", "Fixture.AppHost"))
+				return writeAppHost(t, filepath.Join(root, "src", "Shop.AppHost"))
+			},
+		},
+		{
+			name: "referenced by a solution",
+			setup: func(t *testing.T, root string) string {
+				writeAppHost(t, filepath.Join(root, "Spike.AppHost"))
+				project := writeAppHost(t, filepath.Join(root, "src", "Shop.AppHost"))
+				writeFileContent(t, filepath.Join(root, "Shop.slnx"), `<Solution><Project Path="src/Shop.AppHost/Shop.AppHost.csproj" /></Solution>`)
+				return project
+			},
+		},
+		{
+			name: "an AppHost project over a Program.cs",
+			setup: func(t *testing.T, root string) string {
+				writeFileContent(t, filepath.Join(root, "Api", "Api.csproj"), `<Project Sdk="Microsoft.NET.Sdk.Web"></Project>`)
+				writeFileContent(t, filepath.Join(root, "Api", "Program.cs"), "")
+				return writeAppHost(t, filepath.Join(root, "src", "Shop.AppHost"))
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			want := tt.setup(t, root)
+
+			project, err := FindAppHost(root)
+			if err != nil {
+				t.Fatalf("FindAppHost() error = %v", err)
+			}
+			if project == nil || project.ProjectFile != want {
+				t.Errorf("FindAppHost() = %+v, want %s", project, want)
+			}
+
+			all, err := FindAppHosts(root)
+			if err != nil || len(all) == 0 || all[0].ProjectFile != want {
+				t.Errorf("FindAppHosts() = %+v, %v, want %s first", all, err, want)
+			}
+		})
+	}
+}
+
+func TestFindAppHostAmbiguous(t *testing.T) {
+	root := t.TempDir()
+	first := writeAppHost(t, filepath.Join(root, "Orders.AppHost"))
+	second := writeAppHost(t, filepath.Join(root, "Shop.AppHost"))
+
+	project, err := FindAppHost(root)
+	var ambiguous *AmbiguousAppHostError
+	if !errors.As(err, &ambiguous) {
+		t.Fatalf("FindAppHost() = %+v, %v, want an AmbiguousAppHostError", project, err)
+	}
+	if len(ambiguous.Candidates) != 2 || ambiguous.Candidates[0].ProjectFile != first || ambiguous.Candidates[1].ProjectFile != second {
+		t.Errorf("candidates = %+v", ambiguous.Candidates)
+	}
+
+	scan, err := ScanWorkspace(root)
+	if err != nil || scan.AppHost == nil || scan.AppHost.ProjectFile != first {
+		t.Errorf("ScanWorkspace() AppHost = %+v, %v, want %s", scan.AppHost, err, first)
+	}
+}
+
+func TestFindAppHostSelected(t *testing.T) {
+	root := t.TempDir()
+	writeAppHost(t, filepath.Join(root, "Orders.AppHost"))
+	shop := writeAppHost(t, filepath.Join(root, "Shop.AppHost"))
+
+	t.Run("config", func(t *testing.T) {
+		writeFileContent(t, filepath.Join(root, ConfigFileName), "detector:\n  apphost: Shop.AppHost\n")
+		t.Cleanup(func() { writeFileContent(t, filepath.Join(root, ConfigFileName), "") })
+
+		project, err := FindAppHost(root)
+		if err != nil || project == nil || project.ProjectFile != shop {
+			t.Errorf("FindAppHost() = %+v, %v, want %s", project, err, shop)
+		}
+	})
+
+	t.Run("flag", func(t *testing.T) {
+		SetAppHostPath(shop)
+		t.Cleanup(func() { SetAppHostPath("") })
+
+		project, err := FindAppHost(root)
+		if err != nil || project == nil || project.Dir != filepath.Dir(shop) {
+			t.Errorf("FindAppHost() = %+v, %v, want %s", project, err, shop)
+		}
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		SetAppHostPath(filepath.Join(root, "Gone.AppHost"))
+		t.Cleanup(func() { SetAppHostPath("") })
+
+		if _, err := FindAppHost(root); err == nil {
+			t.Error("FindAppHost() succeeded with a missing --apphost")
+		}
+	})
+}
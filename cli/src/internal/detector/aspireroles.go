@@ -0,0 +1,103 @@
+package detector
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/jongio/azd-app/cli/src/internal/types"
+)
+
+// Roles of .NET projects in an Aspire solution.
+const (
+	AspireRoleAppHost         = "apphost"
+	AspireRoleServiceDefaults = "service-defaults"
+	AspireRoleManaged         = "managed"    // An AppHost references it, so the AppHost runs it
+	AspireRoleOrphan          = "orphan"     // Uses the service defaults or Aspire client integrations, but no AppHost references it
+	AspireRoleStandalone      = "standalone" // Doesn't use Aspire, and no AppHost references it
+)
+
+// AspireProjectRole is the role of a .NET project in an Aspire solution.
+type AspireProjectRole struct {
+	Path            string   `json:"path"`
+	Role            string   `json:"role"`
+	AppHosts        []string `json:"appHosts,omitempty"`        // AppHosts that reference it
+	ServiceDefaults string   `json:"serviceDefaults,omitempty"` // ServiceDefaults project it references
+	Integrations    []string `json:"integrations,omitempty"`    // Aspire client integrations it references, e.g. Aspire.Npgsql
+}
+
+// AspireRoles returns the role of each AppHost, ServiceDefaults, and runnable project of
+// projects, in their order. A runnable project is managed when an AppHost of projects
+// references it, and otherwise an orphan when it references the ServiceDefaults project or an
+// Aspire client integration, which only an AppHost configures, or else standalone. Solutions,
+// test projects, and other libraries are left out.
+func AspireRoles(projects []types.DotnetProject) []AspireProjectRole {
+	serviceDefaults := make(map[string]bool)
+	appHosts := make(map[string][]string)
+	for _, p := range projects {
+		if p.IsServiceDefaults {
+			serviceDefaults[filepath.Clean(p.Path)] = true
+		}
+		if p.IsAppHost {
+			for _, ref := range p.ProjectReferences {
+				ref = filepath.Clean(ref)
+				appHosts[ref] = append(appHosts[ref], p.Path)
+			}
+		}
+	}
+
+	var roles []AspireProjectRole
+	for _, p := range projects {
+		if !IsDotnetProjectFile(p.Path) || p.IsTestProject {
+			continue
+		}
+		role := AspireProjectRole{Path: p.Path}
+		switch {
+		case p.IsAppHost:
+			role.Role = AspireRoleAppHost
+		case p.IsServiceDefaults:
+			role.Role = AspireRoleServiceDefaults
+		case p.IsRunnable():
+			role.AppHosts = appHosts[filepath.Clean(p.Path)]
+			role.ServiceDefaults = referencedServiceDefaults(p, serviceDefaults)
+			role.Integrations = aspireIntegrations(p.Packages)
+			switch {
+			case len(role.AppHosts) > 0:
+				role.Role = AspireRoleManaged
+			case role.ServiceDefaults != "" || len(role.Integrations) > 0:
+				role.Role = AspireRoleOrphan
+			default:
+				role.Role = AspireRoleStandalone
+			}
+		default:
+			continue
+		}
+		roles = append(roles, role)
+	}
+	return roles
+}
+
+// referencedServiceDefaults returns the ServiceDefaults project p references: one of
+// serviceDefaults, or one named like Shop.ServiceDefaults when it wasn't detected.
+func referencedServiceDefaults(p types.DotnetProject, serviceDefaults map[string]bool) string {
+	for _, ref := range p.ProjectReferences {
+		name := strings.TrimSuffix(filepath.Base(ref), filepath.Ext(ref))
+		if serviceDefaults[filepath.Clean(ref)] || strings.HasSuffix(strings.ToLower(name), "servicedefaults") {
+			return ref
+		}
+	}
+	return ""
+}
+
+// aspireIntegrations returns the Aspire client integrations of packages, such as
+// Aspire.Npgsql or Aspire.StackExchange.Redis; hosting packages, which only an AppHost
+// references, are left out.
+func aspireIntegrations(packages []string) []string {
+	var integrations []string
+	for _, pkg := range packages {
+		lower := strings.ToLower(pkg)
+		if strings.HasPrefix(lower, "aspire.") && !strings.HasPrefix(lower, "aspire.hosting") {
+			integrations = append(integrations, pkg)
+		}
+	}
+	return integrations
+}
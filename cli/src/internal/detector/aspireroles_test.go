@@ -0,0 +1,71 @@
+package detector
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestAspireRoles(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"Shop.AppHost/Shop.AppHost.csproj": `<Project Sdk="Microsoft.NET.Sdk">
+  <Sdk Name="Aspire.AppHost.Sdk" Version="9.1.0" />
+  <ItemGroup>
+    <PackageReference Include="Aspire.Hosting.Redis" Version="9.1.0" />
+    <ProjectReference Include="..\Shop.Api\Shop.Api.csproj" />
+    <ProjectReference Include="..\Shop.Shared\Shop.Shared.csproj" IsAspireProjectResource="false" />
+  </ItemGroup>
+</Project>`,
+		"Shop.Api/Shop.Api.csproj": `<Project Sdk="Microsoft.NET.Sdk.Web">
+  <ItemGroup><ProjectReference Include="..\Shop.ServiceDefaults\Shop.ServiceDefaults.csproj" /></ItemGroup>
+</Project>`,
+		"Shop.Worker/Shop.Worker.csproj": `<Project Sdk="Microsoft.NET.Sdk.Worker">
+  <ItemGroup><ProjectReference Include="../Shop.ServiceDefaults/Shop.ServiceDefaults.csproj" /></ItemGroup>
+</Project>`,
+		"Shop.Jobs/Shop.Jobs.csproj": `<Project Sdk="Microsoft.NET.Sdk.Worker">
+  <ItemGroup><PackageReference Include="Aspire.Azure.Storage.Queues" Version="9.1.0" /></ItemGroup>
+</Project>`,
+		"Shop.ServiceDefaults/Shop.ServiceDefaults.csproj": `<Project Sdk="Microsoft.NET.Sdk">
+  <PropertyGroup><IsAspireSharedProject>true</IsAspireSharedProject></PropertyGroup>
+</Project>`,
+		"Shop.Shared/Shop.Shared.csproj": `<Project Sdk="Microsoft.NET.Sdk"></Project>`,
+		"Admin/Admin.csproj":             `<Project Sdk="Microsoft.NET.Sdk.Web"></Project>`,
+		"Shop.Tests/Shop.Tests.csproj":   `<Project Sdk="Microsoft.NET.Sdk"><ItemGroup><PackageReference Include="Aspire.Hosting.Testing" /></ItemGroup></Project>`,
+	}
+	for name, content := range files {
+		writeFileContent(t, filepath.Join(root, filepath.FromSlash(name)), content)
+	}
+
+	projects, err := FindDotnetProjects(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	roles := make(map[string]AspireProjectRole)
+	for _, role := range AspireRoles(projects) {
+		rel, _ := filepath.Rel(root, filepath.Dir(role.Path))
+		roles[filepath.ToSlash(rel)] = role
+	}
+
+	path := func(name string) string {
+		return filepath.Join(root, name, name+".csproj")
+	}
+	want := map[string]AspireProjectRole{
+		"Shop.AppHost":         {Path: path("Shop.AppHost"), Role: AspireRoleAppHost},
+		"Shop.ServiceDefaults": {Path: path("Shop.ServiceDefaults"), Role: AspireRoleServiceDefaults},
+		"Shop.Api":             {Path: path("Shop.Api"), Role: AspireRoleManaged, AppHosts: []string{path("Shop.AppHost")}, ServiceDefaults: path("Shop.ServiceDefaults")},
+		"Shop.Worker":          {Path: path("Shop.Worker"), Role: AspireRoleOrphan, ServiceDefaults: path("Shop.ServiceDefaults")},
+		"Shop.Jobs":            {Path: path("Shop.Jobs"), Role: AspireRoleOrphan, Integrations: []string{"Aspire.Azure.Storage.Queues"}},
+		"Admin":                {Path: path("Admin"), Role: AspireRoleStandalone},
+	}
+	if !reflect.DeepEqual(roles, want) {
+		for name, role := range roles {
+			if !reflect.DeepEqual(role, want[name]) {
+				t.Errorf("%s = %+v, want %+v", name, role, want[name])
+			}
+		}
+		if len(roles) != len(want) {
+			t.Errorf("got %d roles, want %d", len(roles), len(want))
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package detector
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Workspace is a directory with an azure.yaml. Its projects are the ones below Dir, except
+// those in nested workspaces, which own their projects.
+type Workspace struct {
+	Name      string   `json:"name"` // Name in azure.yaml, or the directory name
+	Dir       string   `json:"dir"`
+	AzureYaml string   `json:"azureYaml"`
+	Nested    []string `json:"nested,omitempty"` // Directories of the workspaces below Dir
+}
+
+// Contains reports whether path belongs to the workspace: it is in Dir and not in a nested workspace.
+func (w Workspace) Contains(path string) bool {
+	if !isWithin(w.Dir, path) {
+		return false
+	}
+	for _, nested := range w.Nested {
+		if isWithin(nested, path) {
+			return false
+		}
+	}
+	return true
+}
+
+// azureYamlMatcher collects azure.yaml files.
+type azureYamlMatcher struct {
+	paths []string
+}
+
+func (m *azureYamlMatcher) matchFile(path string, name string) {
+	if name == "azure.yaml" {
+		m.paths = append(m.paths, path)
+	}
+}
+
+// FindAzureYamls returns the absolute path of every azure.yaml in rootDir and below, sorted.
+func FindAzureYamls(rootDir string) ([]string, error) {
+	rootDir, err := filepath.Abs(rootDir)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &azureYamlMatcher{}
+	if err := walkTree(rootDir, false, m); err != nil {
+		return nil, err
+	}
+	sort.Strings(m.paths)
+	return m.paths, nil
+}
+
+// FindWorkspaces returns a workspace for every azure.yaml in rootDir and below, sorted by directory.
+func FindWorkspaces(rootDir string) ([]Workspace, error) {
+	paths, err := FindAzureYamls(rootDir)
+	if err != nil {
+		return nil, err
+	}
+
+	workspaces := make([]Workspace, 0, len(paths))
+	for _, path := range paths {
+		dir := filepath.Dir(path)
+		ws := Workspace{Name: filepath.Base(dir), Dir: dir, AzureYaml: path}
+		var doc struct {
+			Name string `yaml:"name"`
+		}
+		if readYamlFile(path, &doc) && doc.Name != "" {
+			ws.Name = doc.Name
+		}
+		workspaces = append(workspaces, ws)
+	}
+
+	for i := range workspaces {
+		for _, other := range workspaces {
+			if other.Dir != workspaces[i].Dir && isWithin(workspaces[i].Dir, other.Dir) {
+				workspaces[i].Nested = append(workspaces[i].Nested, other.Dir)
+			}
+		}
+	}
+	return workspaces, nil
+}
+
+// ResolveWorkspace returns the azure.yaml of the workspace selected by workspace. workspace is
+// a directory or azure.yaml path, relative to startDir unless absolute, or the name or
+// directory name of a workspace in the repository containing startDir.
+func ResolveWorkspace(startDir string, workspace string) (string, error) {
+	path := workspace
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(startDir, path)
+	}
+	if info, err := os.Stat(path); err == nil {
+		if info.IsDir() {
+			path = filepath.Join(path, "azure.yaml")
+		}
+		if _, err := os.Stat(path); err != nil {
+			return "", fmt.Errorf("no azure.yaml in workspace %s", workspace)
+		}
+		return filepath.Abs(path)
+	}
+
+	root := repositoryRoot(startDir)
+	workspaces, err := FindWorkspaces(root)
+	if err != nil {
+		return "", fmt.Errorf("failed to find workspaces: %w", err)
+	}
+	var matches, names []string
+	for _, ws := range workspaces {
+		if ws.Name == workspace || filepath.Base(ws.Dir) == workspace {
+			matches = append(matches, ws.AzureYaml)
+		}
+		names = append(names, ws.Name)
+	}
+
+	switch len(matches) {
+	case 1:
+		return matches[0], nil
+	case 0:
+		if len(names) == 0 {
+			return "", fmt.Errorf("workspace %q not found: no azure.yaml under %s", workspace, root)
+		}
+		return "", fmt.Errorf("workspace %q not found, available workspaces: %s", workspace, strings.Join(names, ", "))
+	default:
+		return "", fmt.Errorf("workspace %q is ambiguous, pass its directory instead: %s", workspace, strings.Join(matches, ", "))
+	}
+}
+
+// repositoryRoot returns the nearest directory from dir up that contains .git, or dir if none does.
+func repositoryRoot(dir string) string {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return dir
+	}
+	for current := absDir; ; {
+		if _, err := os.Stat(filepath.Join(current, ".git")); err == nil {
+			return current
+		}
+		parent := filepath.Dir(current)
+		if parent == current {
+			return absDir
+		}
+		current = parent
+	}
+}
+
+// hasAzureYaml reports whether dir contains an azure.yaml.
+func hasAzureYaml(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, "azure.yaml"))
+	return err == nil
+}
+
+// isWithin reports whether path is dir or below it.
+func isWithin(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,132 @@
+package detector
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeMonorepo creates a repository with a root workspace and two nested app workspaces.
+func writeMonorepo(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	if err := os.Mkdir(filepath.Join(root, ".git"), 0750); err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, filepath.Join(root, "azure.yaml"))
+	writeTestFile(t, filepath.Join(root, "tools", "package.json"))
+	writeTestFile(t, filepath.Join(root, "apps", "shop", "azure.yaml"))
+	writeTestFile(t, filepath.Join(root, "apps", "shop", "web", "package.json"))
+	writeTestFile(t, filepath.Join(root, "apps", "blog", "api", "requirements.txt"))
+	if err := os.WriteFile(filepath.Join(root, "apps", "blog", "azure.yaml"), []byte("name: contoso-blog\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return root
+}
+
+func TestFindWorkspaces(t *testing.T) {
+	root := writeMonorepo(t)
+	shop, blog := filepath.Join(root, "apps", "shop"), filepath.Join(root, "apps", "blog")
+
+	paths, err := FindAzureYamls(root)
+	if err != nil {
+		t.Fatalf("FindAzureYamls() error = %v", err)
+	}
+	wantPaths := []string{filepath.Join(root, "apps", "blog", "azure.yaml"), filepath.Join(root, "apps", "shop", "azure.yaml"), filepath.Join(root, "azure.yaml")}
+	if !reflect.DeepEqual(paths, wantPaths) {
+		t.Errorf("FindAzureYamls() = %v, want %v", paths, wantPaths)
+	}
+
+	workspaces, err := FindWorkspaces(root)
+	if err != nil {
+		t.Fatalf("FindWorkspaces() error = %v", err)
+	}
+	want := []Workspace{
+		{Name: "contoso-blog", Dir: blog, AzureYaml: filepath.Join(blog, "azure.yaml")},
+		{Name: "shop", Dir: shop, AzureYaml: filepath.Join(shop, "azure.yaml")},
+		{Name: filepath.Base(root), Dir: root, AzureYaml: filepath.Join(root, "azure.yaml"), Nested: []string{blog, shop}},
+	}
+	if !reflect.DeepEqual(workspaces, want) {
+		t.Errorf("FindWorkspaces() =\n%+v\nwant\n%+v", workspaces, want)
+	}
+
+	rootWorkspace := workspaces[2]
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{filepath.Join(root, "tools"), true},
+		{filepath.Join(shop, "web"), false},
+		{filepath.Join(root, "apps"), true},
+		{filepath.Dir(root), false},
+	}
+	for _, tt := range tests {
+		if got := rootWorkspace.Contains(tt.path); got != tt.want {
+			t.Errorf("Contains(%s) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestScanWorkspaceStopsAtNestedWorkspaces(t *testing.T) {
+	root := writeMonorepo(t)
+
+	scan, err := ScanWorkspace(root)
+	if err != nil {
+		t.Fatalf("ScanWorkspace() error = %v", err)
+	}
+	if len(scan.NodeProjects) != 1 || scan.NodeProjects[0].Dir != filepath.Join(root, "tools") {
+		t.Errorf("NodeProjects = %+v, want only tools", scan.NodeProjects)
+	}
+	if len(scan.PythonProjects) != 0 {
+		t.Errorf("PythonProjects = %+v, want none from the nested blog workspace", scan.PythonProjects)
+	}
+
+	// A nested workspace scanned directly finds its own projects
+	scan, err = ScanWorkspace(filepath.Join(root, "apps", "shop"))
+	if err != nil {
+		t.Fatalf("ScanWorkspace() error = %v", err)
+	}
+	if len(scan.NodeProjects) != 1 {
+		t.Errorf("NodeProjects = %+v, want the shop web project", scan.NodeProjects)
+	}
+}
+
+func TestResolveWorkspace(t *testing.T) {
+	root := writeMonorepo(t)
+	shopYaml := filepath.Join(root, "apps", "shop", "azure.yaml")
+	blogYaml := filepath.Join(root, "apps", "blog", "azure.yaml")
+	writeTestFile(t, filepath.Join(root, "legacy", "shop", "azure.yaml"))
+
+	tests := []struct {
+		name      string
+		startDir  string
+		workspace string
+		want      string
+		wantErr   string
+	}{
+		{name: "relative directory", startDir: root, workspace: "apps/shop", want: shopYaml},
+		{name: "azure.yaml path", startDir: root, workspace: shopYaml, want: shopYaml},
+		{name: "name from azure.yaml", startDir: filepath.Join(root, "tools"), workspace: "contoso-blog", want: blogYaml},
+		{name: "directory name", startDir: root, workspace: "blog", want: blogYaml},
+		{name: "directory without azure.yaml", startDir: root, workspace: "tools", wantErr: "no azure.yaml"},
+		{name: "unknown", startDir: root, workspace: "missing", wantErr: "available workspaces"},
+		{name: "ambiguous", startDir: root, workspace: "shop", wantErr: "ambiguous"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ResolveWorkspace(tt.startDir, tt.workspace)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ResolveWorkspace() error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("ResolveWorkspace() = %q, %v; want %q", got, err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,185 @@
+package detector
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jongio/azd-app/cli/src/internal/types"
+
+	"gopkg.in/yaml.v3"
+)
+
+// composeFileNames lists Docker Compose file names in the order Compose itself looks for them.
+var composeFileNames = []string{"compose.yaml", "compose.yml", "docker-compose.yaml", "docker-compose.yml"}
+
+// composeFile is the part of a Compose file that is imported. Fields with several accepted
+// forms, such as a list or a map of environment variables, are decoded from nodes.
+type composeFile struct {
+	Services map[string]struct {
+		Image       string      `yaml:"image"`
+		Build       yaml.Node   `yaml:"build"`
+		Ports       []yaml.Node `yaml:"ports"`
+		Expose      []yaml.Node `yaml:"expose"`
+		Environment yaml.Node   `yaml:"environment"`
+		DependsOn   yaml.Node   `yaml:"depends_on"`
+	} `yaml:"services"`
+}
+
+// FindComposeFile returns the path of the Compose file in dir, or "" if there is none.
+func FindComposeFile(dir string) string {
+	for _, name := range composeFileNames {
+		if fileExistsIn(dir, name) {
+			return filepath.Join(dir, name)
+		}
+	}
+	return ""
+}
+
+// ParseComposeFile reads the services of a Docker Compose file: their image, build context,
+// Dockerfile and build arguments, ports, environment, and dependencies. Both the short and
+// long syntax of build, ports, environment, and depends_on are accepted. Variables in
+// published ports resolve to their default, e.g. "${WEB_PORT:-8080}:80" publishes 8080.
+func ParseComposeFile(path string) (*types.ComposeProject, error) {
+	data, err := readProjectFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file composeFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filepath.Base(path), err)
+	}
+
+	project := &types.ComposeProject{Path: path}
+	for name, raw := range file.Services {
+		svc := types.ComposeService{
+			Name:        name,
+			Image:       raw.Image,
+			Environment: composeStringMap(&raw.Environment),
+			DependsOn:   composeNames(&raw.DependsOn),
+		}
+
+		switch raw.Build.Kind {
+		case yaml.ScalarNode:
+			svc.BuildContext = raw.Build.Value
+		case yaml.MappingNode:
+			var build struct {
+				Context    string    `yaml:"context"`
+				Dockerfile string    `yaml:"dockerfile"`
+				Args       yaml.Node `yaml:"args"`
+			}
+			if err := raw.Build.Decode(&build); err != nil {
+				return nil, fmt.Errorf("invalid build of service %s: %w", name, err)
+			}
+			svc.BuildContext = firstNonEmpty(build.Context, ".")
+			svc.Dockerfile = build.Dockerfile
+			for key, value := range composeStringMap(&build.Args) {
+				// An argument without a value is taken from the environment at build time
+				if value == "" {
+					svc.BuildArgs = append(svc.BuildArgs, key)
+				} else {
+					svc.BuildArgs = append(svc.BuildArgs, key+"="+value)
+				}
+			}
+			sort.Strings(svc.BuildArgs)
+		}
+
+		for i := range raw.Ports {
+			if port, ok := composePort(&raw.Ports[i]); ok {
+				svc.Ports = append(svc.Ports, port)
+			}
+		}
+		for i := range raw.Expose {
+			if port, ok := composePort(&raw.Expose[i]); ok {
+				port.Host = 0 // Exposed ports are only reachable from other services
+				svc.Ports = append(svc.Ports, port)
+			}
+		}
+
+		project.Services = append(project.Services, svc)
+	}
+
+	sort.Slice(project.Services, func(i, j int) bool {
+		return project.Services[i].Name < project.Services[j].Name
+	})
+	return project, nil
+}
+
+// composeStringMap decodes a map or a list of KEY=value entries. Keys without a value map to "".
+func composeStringMap(node *yaml.Node) map[string]string {
+	values := make(map[string]string)
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			value := node.Content[i+1]
+			if value.Tag == "!!null" {
+				values[node.Content[i].Value] = ""
+			} else {
+				values[node.Content[i].Value] = value.Value
+			}
+		}
+	case yaml.SequenceNode:
+		for _, item := range node.Content {
+			key, value, _ := strings.Cut(item.Value, "=")
+			values[key] = value
+		}
+	}
+	return values
+}
+
+// composeNames decodes a list of names, or the keys of a map of names.
+func composeNames(node *yaml.Node) []string {
+	var names []string
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i < len(node.Content); i += 2 {
+			names = append(names, node.Content[i].Value)
+		}
+	case yaml.SequenceNode:
+		for _, item := range node.Content {
+			names = append(names, item.Value)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// composePort decodes a port such as 80, "8080:80", "127.0.0.1:8080:80/tcp", a range such as
+// "3000-3005:3000-3005" (its first port), or the long syntax with target and published.
+func composePort(node *yaml.Node) (types.ComposePort, bool) {
+	var port types.ComposePort
+
+	if node.Kind == yaml.MappingNode {
+		var long struct {
+			Target    string `yaml:"target"`
+			Published string `yaml:"published"`
+		}
+		if err := node.Decode(&long); err != nil {
+			return port, false
+		}
+		port.Container = composePortNumber(long.Target)
+		port.Host = composePortNumber(long.Published)
+		return port, port.Container > 0
+	}
+
+	value, _, _ := strings.Cut(expandDockerVars(node.Value, nil), "/")
+	parts := strings.Split(value, ":")
+	port.Container = composePortNumber(parts[len(parts)-1])
+	if len(parts) >= 2 {
+		port.Host = composePortNumber(parts[len(parts)-2])
+	}
+	return port, port.Container > 0
+}
+
+// composePortNumber returns the first port of a port or port range, or 0 if it is not valid.
+func composePortNumber(value string) int {
+	start, _, _ := strings.Cut(strings.TrimSpace(value), "-")
+	port, err := strconv.Atoi(start)
+	if err != nil || port < 1 || port > 65535 {
+		return 0
+	}
+	return port
+}
@@ -0,0 +1,88 @@
+package detector
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/jongio/azd-app/cli/src/internal/types"
+)
+
+func TestParseComposeFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "docker-compose.yml")
+	writeFileContent(t, path, `services:
+  api:
+    build:
+      context: ./api
+      dockerfile: Dockerfile.dev
+      args:
+        VERSION: "1.2"
+        TOKEN:
+    ports:
+      - "${API_PORT:-8080}:80"
+      - target: 9090
+        published: "9091"
+    environment:
+      - LOG_LEVEL=debug
+      - DEBUG
+    depends_on:
+      db:
+        condition: service_healthy
+  web:
+    build: ./web
+    expose: ["3000"]
+    environment:
+      API_URL: http://api:80
+    depends_on: [api]
+  db:
+    image: postgres:16
+    ports: ["127.0.0.1:5432:5432/tcp"]
+`)
+
+	project, err := ParseComposeFile(path)
+	if err != nil {
+		t.Fatalf("ParseComposeFile failed: %v", err)
+	}
+
+	want := []types.ComposeService{
+		{
+			Name:         "api",
+			BuildContext: "./api",
+			Dockerfile:   "Dockerfile.dev",
+			BuildArgs:    []string{"TOKEN", "VERSION=1.2"},
+			Ports:        []types.ComposePort{{Host: 8080, Container: 80}, {Host: 9091, Container: 9090}},
+			Environment:  map[string]string{"LOG_LEVEL": "debug", "DEBUG": ""},
+			DependsOn:    []string{"db"},
+		},
+		{
+			Name:        "db",
+			Image:       "postgres:16",
+			Ports:       []types.ComposePort{{Host: 5432, Container: 5432}},
+			Environment: map[string]string{},
+		},
+		{
+			Name:         "web",
+			BuildContext: "./web",
+			Ports:        []types.ComposePort{{Container: 3000}},
+			Environment:  map[string]string{"API_URL": "http://api:80"},
+			DependsOn:    []string{"api"},
+		},
+	}
+	if !reflect.DeepEqual(project.Services, want) {
+		t.Errorf("Services =\n%+v\nwant\n%+v", project.Services, want)
+	}
+}
+
+func TestFindComposeFile(t *testing.T) {
+	dir := t.TempDir()
+	if got := FindComposeFile(dir); got != "" {
+		t.Errorf("FindComposeFile() = %q, want none", got)
+	}
+
+	writeFileContent(t, filepath.Join(dir, "docker-compose.yml"), "services: {}\n")
+	writeFileContent(t, filepath.Join(dir, "compose.yaml"), "services: {}\n")
+	if got := FindComposeFile(dir); got != filepath.Join(dir, "compose.yaml") {
+		t.Errorf("FindComposeFile() = %q, want compose.yaml", got)
+	}
+}
@@ -0,0 +1,79 @@
+package detector
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/jongio/azd-app/cli/src/internal/azureyaml"
+	"github.com/jongio/azd-app/cli/src/internal/security"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFileName is the optional project-level configuration file for azd app.
+const ConfigFileName = ".azdapp.yaml"
+
+// Config holds detector settings.
+// It is read from the `detector` key of .azdapp.yaml or of the azure.yaml `metadata` section.
+type Config struct {
+	// Ignore lists gitignore-style globs, relative to the project root, excluded from detection.
+	Ignore []string `yaml:"ignore,omitempty"`
+	// AppHost is the Aspire AppHost to use when the workspace has several: the directory of its
+	// project, or its project file, relative to the project root.
+	AppHost string `yaml:"apphost,omitempty"`
+}
+
+// LoadIgnoreGlobs returns the ignore globs configured for rootDir.
+// Globs from azure.yaml metadata and .azdapp.yaml are combined; missing or invalid files are ignored.
+func LoadIgnoreGlobs(rootDir string) []string {
+	var globs []string
+	for _, glob := range loadIgnoreGlobs(rootDir) {
+		globs = append(globs, glob.pattern)
+	}
+	return globs
+}
+
+// ignoreGlob is a configured ignore glob and the file that configures it.
+type ignoreGlob struct {
+	pattern string
+	source  string
+}
+
+// loadIgnoreGlobs returns the ignore globs configured for rootDir, with their files.
+func loadIgnoreGlobs(rootDir string) []ignoreGlob {
+	var globs []ignoreGlob
+
+	azureYamlPath := filepath.Join(rootDir, azureyaml.FileName)
+	if doc, err := azureyaml.Load(azureYamlPath); err == nil {
+		if metadata := doc.Project.Metadata; metadata != nil && metadata.Detector != nil {
+			for _, pattern := range metadata.Detector.Ignore {
+				globs = append(globs, ignoreGlob{pattern: pattern, source: azureYamlPath})
+			}
+		}
+	}
+
+	var appConfig struct {
+		Detector Config `yaml:"detector"`
+	}
+	configPath := filepath.Join(rootDir, ConfigFileName)
+	if readYamlFile(configPath, &appConfig) {
+		for _, pattern := range appConfig.Detector.Ignore {
+			globs = append(globs, ignoreGlob{pattern: pattern, source: configPath})
+		}
+	}
+
+	return globs
+}
+
+// readYamlFile unmarshals a YAML file into out and reports whether it succeeded.
+func readYamlFile(path string, out interface{}) bool {
+	if err := security.ValidatePath(path); err != nil {
+		return false
+	}
+	// #nosec G304 -- Path validated by security.ValidatePath
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	return yaml.Unmarshal(data, out) == nil
+}
@@ -0,0 +1,116 @@
+package detector
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadIgnoreGlobs(t *testing.T) {
+	tests := []struct {
+		name      string
+		azureYaml string
+		appConfig string
+		want      []string
+	}{
+		{
+			name: "no config",
+			want: nil,
+		},
+		{
+			name: "azure.yaml metadata",
+			azureYaml: `name: test
+metadata:
+  detector:
+    ignore: ["samples/**", "tools/**"]
+`,
+			want: []string{"samples/**", "tools/**"},
+		},
+		{
+			name: ".azdapp.yaml",
+			appConfig: `detector:
+  ignore:
+    - demo/
+`,
+			want: []string{"demo/"},
+		},
+		{
+			name: "both combined",
+			azureYaml: `metadata:
+  detector:
+    ignore: [samples/**]
+`,
+			appConfig: `detector:
+  ignore: [tools/**]
+`,
+			want: []string{"samples/**", "tools/**"},
+		},
+		{
+			name:      "invalid yaml ignored",
+			azureYaml: "metadata: [",
+			want:      nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			if tt.azureYaml != "" {
+				if err := os.WriteFile(filepath.Join(tmpDir, "azure.yaml"), []byte(tt.azureYaml), 0600); err != nil {
+					t.Fatalf("failed to write azure.yaml: %v", err)
+				}
+			}
+			if tt.appConfig != "" {
+				if err := os.WriteFile(filepath.Join(tmpDir, ConfigFileName), []byte(tt.appConfig), 0600); err != nil {
+					t.Fatalf("failed to write %s: %v", ConfigFileName, err)
+				}
+			}
+
+			if got := LoadIgnoreGlobs(tmpDir); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("LoadIgnoreGlobs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindHonorsConfiguredIgnoreGlobs(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	writeTestFile(t, filepath.Join(tmpDir, "src", "web", "package.json"))
+	writeTestFile(t, filepath.Join(tmpDir, "samples", "demo", "package.json"))
+	writeTestFile(t, filepath.Join(tmpDir, "tools", "scripts", "requirements.txt"))
+	writeTestFile(t, filepath.Join(tmpDir, "src", "api", "requirements.txt"))
+
+	azureYaml := `name: test
+metadata:
+  detector:
+    ignore: ["samples/**"]
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, "azure.yaml"), []byte(azureYaml), 0600); err != nil {
+		t.Fatalf("failed to write azure.yaml: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, ConfigFileName), []byte("detector:\n  ignore: [tools/]\n"), 0600); err != nil {
+		t.Fatalf("failed to write %s: %v", ConfigFileName, err)
+	}
+
+	// Configured globs apply even when ignore files are disabled
+	SetRespectIgnoreFiles(false)
+	defer SetRespectIgnoreFiles(true)
+
+	node, err := FindNodeProjects(tmpDir)
+	if err != nil {
+		t.Fatalf("FindNodeProjects() error = %v", err)
+	}
+	if len(node) != 1 || node[0].Dir != filepath.Join(tmpDir, "src", "web") {
+		t.Errorf("FindNodeProjects() = %+v, want only src/web", node)
+	}
+
+	python, err := FindPythonProjects(tmpDir)
+	if err != nil {
+		t.Fatalf("FindPythonProjects() error = %v", err)
+	}
+	if len(python) != 1 || python[0].Dir != filepath.Join(tmpDir, "src", "api") {
+		t.Errorf("FindPythonProjects() = %+v, want only src/api", python)
+	}
+}
@@ -20,67 +20,18 @@ const (
 // FindPythonProjects searches for Python projects and detects their package manager.
 // Only searches within rootDir and does not traverse outside it.
 func FindPythonProjects(rootDir string) ([]types.PythonProject, error) {
-	var pythonProjects []types.PythonProject
-	seen := make(map[string]bool)
-
-	// Clean the root directory path
 	rootDir, err := filepath.Abs(rootDir)
 	if err != nil {
-		return pythonProjects, err
+		return nil, err
 	}
 
-	err = filepath.Walk(rootDir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return nil // Skip errors
-		}
-
-		// Ensure we don't traverse outside rootDir
-		absPath, err := filepath.Abs(path)
-		if err != nil {
-			return nil
-		}
-		relPath, err := filepath.Rel(rootDir, absPath)
-		if err != nil || strings.HasPrefix(relPath, "..") {
-			return filepath.SkipDir
-		}
-
-		// Skip common directories
-		if info.IsDir() {
-			name := info.Name()
-			if name == skipDirNodeModules || name == skipDirBin || name == skipDirObj || name == skipDirGit ||
-				name == "venv" || name == ".venv" || name == "__pycache__" || name == ".uv" {
-				return filepath.SkipDir
-			}
-		}
-
-		if !info.IsDir() {
-			dir := filepath.Dir(path)
-
-			// Skip if we've already found this directory
-			if seen[dir] {
-				return nil
-			}
-
-			// Look for Python project indicators
-			if info.Name() == "requirements.txt" || info.Name() == "pyproject.toml" ||
-				info.Name() == "poetry.lock" || info.Name() == "uv.lock" {
-				packageManager := DetectPythonPackageManager(dir)
-				pythonProjects = append(pythonProjects, types.PythonProject{
-					Dir:            dir,
-					PackageManager: packageManager,
-				})
-				seen[dir] = true
-			}
-		}
-
-		return nil
-	})
-
-	return pythonProjects, err
+	m := newPythonMatcher(rootDir)
+	err = walkWorkspace(rootDir, m)
+	return m.projects, err
 }
 
 // DetectPythonPackageManager determines which package manager to use.
-// Priority order: uv > poetry > pip.
+// Priority order: uv > poetry > pipenv > pip.
 func DetectPythonPackageManager(projectDir string) string {
 	// Check for uv (uv.lock)
 	if _, err := os.Stat(filepath.Join(projectDir, "uv.lock")); err == nil {
@@ -108,6 +59,14 @@ func DetectPythonPackageManager(projectDir string) string {
 		}
 	}
 
+	// Check for pipenv (Pipfile or Pipfile.lock)
+	if _, err := os.Stat(filepath.Join(projectDir, "Pipfile")); err == nil {
+		return "pipenv"
+	}
+	if _, err := os.Stat(filepath.Join(projectDir, "Pipfile.lock")); err == nil {
+		return "pipenv"
+	}
+
 	// Default to pip
 	return "pip"
 }
@@ -115,57 +74,14 @@ func DetectPythonPackageManager(projectDir string) string {
 // FindNodeProjects searches for package.json files.
 // Only searches within rootDir and does not traverse outside it.
 func FindNodeProjects(rootDir string) ([]types.NodeProject, error) {
-	var nodeProjects []types.NodeProject
-	seen := make(map[string]bool)
-
-	// Clean the root directory path
 	rootDir, err := filepath.Abs(rootDir)
 	if err != nil {
-		return nodeProjects, err
+		return nil, err
 	}
 
-	err = filepath.Walk(rootDir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return nil
-		}
-
-		// Ensure we don't traverse outside rootDir
-		absPath, err := filepath.Abs(path)
-		if err != nil {
-			return nil
-		}
-		relPath, err := filepath.Rel(rootDir, absPath)
-		if err != nil || strings.HasPrefix(relPath, "..") {
-			return filepath.SkipDir
-		}
-
-		if info.IsDir() {
-			name := info.Name()
-			// Skip common directories
-			if name == skipDirNodeModules || name == skipDirGit || name == skipDirBin || name == skipDirObj {
-				return filepath.SkipDir
-			}
-		}
-
-		if !info.IsDir() && info.Name() == "package.json" {
-			dir := filepath.Dir(path)
-
-			if seen[dir] {
-				return nil
-			}
-
-			packageManager := DetectNodePackageManagerWithBoundary(dir, rootDir)
-			nodeProjects = append(nodeProjects, types.NodeProject{
-				Dir:            dir,
-				PackageManager: packageManager,
-			})
-			seen[dir] = true
-		}
-
-		return nil
-	})
-
-	return nodeProjects, err
+	m := newNodeMatcher(rootDir)
+	err = walkWorkspace(rootDir, m)
+	return m.projects, err
 }
 
 // DetectNodePackageManager determines whether to use pnpm, yarn, or npm.
@@ -200,123 +116,49 @@ func DetectNodePackageManagerWithBoundary(projectDir string, boundaryDir string)
 	return "npm"
 }
 
-// FindDotnetProjects searches for .csproj and .sln files.
+// FindDotnetProjects searches for .csproj, .fsproj, .vbproj, .sln, and .slnx files.
 // Only searches within rootDir and does not traverse outside it.
-func FindDotnetProjects(rootDir string) ([]types.DotnetProject, error) {
-	var dotnetProjects []types.DotnetProject
-	seen := make(map[string]bool)
-
-	// Clean the root directory path
+// When solutions are given, only those solutions and the projects they reference are returned,
+// so stray project files in large repositories are left out.
+func FindDotnetProjects(rootDir string, solutions ...string) ([]types.DotnetProject, error) {
 	rootDir, err := filepath.Abs(rootDir)
 	if err != nil {
-		return dotnetProjects, err
+		return nil, err
 	}
 
-	err = filepath.Walk(rootDir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return nil
-		}
-
-		// Ensure we don't traverse outside rootDir
-		absPath, err := filepath.Abs(path)
-		if err != nil {
-			return nil
-		}
-		relPath, err := filepath.Rel(rootDir, absPath)
-		if err != nil || strings.HasPrefix(relPath, "..") {
-			return filepath.SkipDir
-		}
-
-		if info.IsDir() {
-			name := info.Name()
-			if name == skipDirNodeModules || name == skipDirGit || name == skipDirBin || name == skipDirObj {
-				return filepath.SkipDir
-			}
-		}
-
-		if !info.IsDir() {
-			ext := filepath.Ext(info.Name())
-			if ext == ".csproj" || ext == ".sln" {
-				// For .csproj, use the directory; for .sln, use the file itself
-				if ext == ".sln" {
-					if !seen[path] {
-						dotnetProjects = append(dotnetProjects, types.DotnetProject{
-							Path: path,
-						})
-						seen[path] = true
-					}
-				} else {
-					dir := filepath.Dir(path)
-					if !seen[dir] {
-						dotnetProjects = append(dotnetProjects, types.DotnetProject{
-							Path: path,
-						})
-						seen[dir] = true
-					}
-				}
-			}
-		}
-
-		return nil
-	})
-
-	return dotnetProjects, err
+	m := newDotnetMatcher(rootDir)
+	if err := walkWorkspace(rootDir, m); err != nil {
+		return m.projects, err
+	}
+	if len(solutions) == 0 {
+		return m.projects, nil
+	}
+	return scopeToSolutions(m.projects, solutions)
 }
 
-// FindAppHost searches for AppHost.cs recursively.
+// FindAppHost returns the Aspire AppHost of rootDir: the one selected with --apphost or with
+// detector.apphost in .azdapp.yaml or the azure.yaml metadata, or else the most likely of those
+// FindAppHosts finds. It returns an *AmbiguousAppHostError when several AppHost projects are
+// equally likely, and nil when there is none.
 // Only searches within rootDir and does not traverse outside it.
 func FindAppHost(rootDir string) (*types.AspireProject, error) {
-	var aspireProject *types.AspireProject
-
-	// Clean the root directory path
 	rootDir, err := filepath.Abs(rootDir)
 	if err != nil {
 		return nil, err
 	}
+	if project, ok, err := selectedAppHost(rootDir); ok {
+		return project, err
+	}
 
-	err = filepath.Walk(rootDir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return nil // Skip errors
-		}
-
-		// Ensure we don't traverse outside rootDir
-		absPath, err := filepath.Abs(path)
-		if err != nil {
-			return nil
-		}
-		relPath, err := filepath.Rel(rootDir, absPath)
-		if err != nil || strings.HasPrefix(relPath, "..") {
-			return filepath.SkipDir
-		}
-
-		// Skip common directories
-		if info.IsDir() {
-			name := info.Name()
-			if name == skipDirNodeModules || name == skipDirBin || name == skipDirObj || name == skipDirGit {
-				return filepath.SkipDir
-			}
-		}
-
-		if !info.IsDir() && (info.Name() == "AppHost.cs" || info.Name() == "Program.cs") {
-			// Check if it's in a project directory (has .csproj)
-			dir := filepath.Dir(path)
-			matches, err := filepath.Glob(filepath.Join(dir, "*.csproj"))
-			if err != nil {
-				return nil // Skip on error
-			}
-			if len(matches) > 0 {
-				aspireProject = &types.AspireProject{
-					Dir:         dir,
-					ProjectFile: matches[0],
-				}
-				return filepath.SkipAll // Found it, stop walking
-			}
-		}
-
-		return nil
-	})
-
-	return aspireProject, err
+	m := newAppHostMatcher()
+	if err := walkWorkspace(rootDir, m); err != nil {
+		return nil, err
+	}
+	project, err := likeliestAppHost(rootDir, m)
+	if err != nil {
+		return nil, err
+	}
+	return project, nil
 }
 
 // HasPackageJson checks if package.json exists in a directory.
@@ -379,9 +221,9 @@ func HasDockerComposeScript(dir string) bool {
 		return false
 	}
 
-	// Look for any script containing "docker compose up" or "docker-compose up"
+	// Look for any script that runs "compose up"
 	for _, scriptCmd := range pkg.Scripts {
-		if strings.Contains(scriptCmd, "docker compose up") || strings.Contains(scriptCmd, "docker-compose up") {
+		if isComposeUpScript(scriptCmd) {
 			return true
 		}
 	}
@@ -389,6 +231,19 @@ func HasDockerComposeScript(dir string) bool {
 	return false
 }
 
+// composeUpCommands are the commands that start a Compose project with Docker or Podman.
+var composeUpCommands = []string{"docker compose up", "docker-compose up", "podman compose up", "podman-compose up"}
+
+// isComposeUpScript reports whether a package.json script starts a Compose project.
+func isComposeUpScript(scriptCmd string) bool {
+	for _, command := range composeUpCommands {
+		if strings.Contains(scriptCmd, command) {
+			return true
+		}
+	}
+	return false
+}
+
 // FindDockerComposeScript finds the script name containing docker compose.
 func FindDockerComposeScript(dir string) string {
 	packageJsonPath := filepath.Join(dir, "package.json")
@@ -410,9 +265,9 @@ func FindDockerComposeScript(dir string) string {
 		return ""
 	}
 
-	// Find the script with docker compose
+	// Find the script that runs compose up
 	for scriptName, scriptCmd := range pkg.Scripts {
-		if strings.Contains(scriptCmd, "docker compose up") || strings.Contains(scriptCmd, "docker-compose up") {
+		if isComposeUpScript(scriptCmd) {
 			return scriptName
 		}
 	}
@@ -40,6 +40,21 @@ func TestDetectPythonPackageManager(t *testing.T) {
 			},
 			expected: "uv",
 		},
+		{
+			name: "Pipfile",
+			files: map[string]string{
+				"Pipfile": "[packages]\nflask = \"*\"",
+			},
+			expected: "pipenv",
+		},
+		{
+			name: "Pipfile.lock with requirements.txt",
+			files: map[string]string{
+				"Pipfile.lock":     "{}",
+				"requirements.txt": "flask",
+			},
+			expected: "pipenv",
+		},
 		{
 			name: "requirements.txt only",
 			files: map[string]string{
@@ -278,6 +293,16 @@ func TestHasDockerComposeScript(t *testing.T) {
 			content:  `{"scripts": {"dev": "docker-compose up -d"}}`,
 			expected: true,
 		},
+		{
+			name:     "has podman compose up",
+			content:  `{"scripts": {"start": "podman compose up --build"}}`,
+			expected: true,
+		},
+		{
+			name:     "has podman-compose up",
+			content:  `{"scripts": {"dev": "podman-compose up"}}`,
+			expected: true,
+		},
 		{
 			name:     "no docker compose",
 			content:  `{"scripts": {"start": "node index.js"}}`,
@@ -0,0 +1,284 @@
+package detector
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/jongio/azd-app/cli/src/internal/types"
+)
+
+// dockerfileNames lists the container build file names recognized in a service directory, in priority order.
+var dockerfileNames = []string{"Dockerfile", "Containerfile"}
+
+// maxExposedPortRange limits how many ports an EXPOSE range such as 8000-8010 expands to.
+const maxExposedPortRange = 100
+
+// FindDockerfiles searches for Dockerfiles and Containerfiles, reporting at most one per directory.
+// Only searches within rootDir and does not traverse outside it.
+func FindDockerfiles(rootDir string) ([]types.Dockerfile, error) {
+	rootDir, err := filepath.Abs(rootDir)
+	if err != nil {
+		return nil, err
+	}
+
+	m := newDockerfileMatcher()
+	if err := walkWorkspace(rootDir, m); err != nil {
+		return nil, err
+	}
+	return m.dockerfiles, nil
+}
+
+// DetectDockerfile returns the parsed Dockerfile or Containerfile in dir, or nil if there is none
+// or it cannot be read. A Dockerfile takes precedence over a Containerfile.
+func DetectDockerfile(dir string) *types.Dockerfile {
+	for _, name := range dockerfileNames {
+		path := filepath.Join(dir, name)
+		if info, err := os.Stat(path); err != nil || info.IsDir() {
+			continue
+		}
+		if dockerfile, err := ParseDockerfile(path); err == nil {
+			return dockerfile
+		}
+	}
+	return nil
+}
+
+// ParseDockerfile reads the Dockerfile at path and extracts its base image, build stages,
+// exposed ports, and build arguments. The base image and ports are those of the final stage;
+// a stage built FROM an earlier stage inherits that stage's image and ports. ARG defaults and
+// ENV values are substituted into FROM and EXPOSE, e.g. `FROM node:${NODE_VERSION}`.
+func ParseDockerfile(path string) (*types.Dockerfile, error) {
+	data, err := readProjectFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	type stage struct {
+		name  string
+		image string
+		ports []int
+	}
+
+	dockerfile := &types.Dockerfile{Path: path}
+	vars := make(map[string]string)
+	declared := make(map[string]bool)
+	var stages []stage
+
+	for _, instruction := range dockerfileInstructions(data) {
+		keyword, args := splitInstruction(instruction)
+		switch keyword {
+		case "FROM":
+			fields := withoutFlags(strings.Fields(args))
+			if len(fields) == 0 {
+				continue
+			}
+			current := stage{image: expandDockerVars(fields[0], vars)}
+			if len(fields) >= 3 && strings.EqualFold(fields[1], "AS") {
+				current.name = fields[2]
+				dockerfile.Stages = append(dockerfile.Stages, current.name)
+			}
+			// A stage built from an earlier stage starts with that stage's image and ports
+			for i := len(stages) - 1; i >= 0; i-- {
+				if stages[i].name != "" && strings.EqualFold(stages[i].name, current.image) {
+					current.image = stages[i].image
+					current.ports = append([]int(nil), stages[i].ports...)
+					break
+				}
+			}
+			stages = append(stages, current)
+		case "EXPOSE":
+			if len(stages) == 0 {
+				continue
+			}
+			last := &stages[len(stages)-1]
+			for _, field := range strings.Fields(args) {
+				last.ports = appendPorts(last.ports, expandDockerVars(field, vars))
+			}
+		case "ARG":
+			for _, field := range strings.Fields(args) {
+				name, value, hasDefault := strings.Cut(field, "=")
+				value = strings.Trim(value, `"'`)
+				if _, ok := vars[name]; !ok && hasDefault {
+					vars[name] = value
+				}
+				if declared[name] {
+					continue
+				}
+				declared[name] = true
+				if hasDefault {
+					dockerfile.BuildArgs = append(dockerfile.BuildArgs, name+"="+value)
+				} else {
+					dockerfile.BuildArgs = append(dockerfile.BuildArgs, name)
+				}
+			}
+		case "ENV":
+			for name, value := range parseEnvInstruction(args) {
+				vars[name] = value
+			}
+		}
+	}
+
+	if len(stages) > 0 {
+		final := stages[len(stages)-1]
+		dockerfile.BaseImage = final.image
+		dockerfile.ExposedPorts = final.ports
+	}
+	return dockerfile, nil
+}
+
+// dockerfileInstructions splits Dockerfile content into instructions, joining continuation
+// lines and dropping comments. The escape character is taken from an `# escape=` parser
+// directive, defaulting to a backslash.
+func dockerfileInstructions(data []byte) []string {
+	escape := `\`
+	var instructions []string
+	var current strings.Builder
+	directives := true
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "#") {
+			// Parser directives are only recognized before the first instruction
+			if directives {
+				if key, value, ok := strings.Cut(strings.TrimSpace(line[1:]), "="); ok && strings.EqualFold(strings.TrimSpace(key), "escape") {
+					escape = strings.TrimSpace(value)
+				}
+			}
+			continue
+		}
+		if line == "" {
+			continue
+		}
+		directives = false
+
+		if escape != "" && strings.HasSuffix(line, escape) {
+			current.WriteString(strings.TrimSuffix(line, escape))
+			current.WriteString(" ")
+			continue
+		}
+		current.WriteString(line)
+		instructions = append(instructions, current.String())
+		current.Reset()
+	}
+	if current.Len() > 0 {
+		instructions = append(instructions, current.String())
+	}
+	return instructions
+}
+
+// splitInstruction returns the upper-cased keyword of an instruction and its arguments.
+func splitInstruction(instruction string) (string, string) {
+	keyword, args, _ := strings.Cut(instruction, " ")
+	return strings.ToUpper(keyword), strings.TrimSpace(args)
+}
+
+// withoutFlags drops leading flags such as --platform=linux/amd64 from instruction arguments.
+func withoutFlags(fields []string) []string {
+	for len(fields) > 0 && strings.HasPrefix(fields[0], "--") {
+		fields = fields[1:]
+	}
+	return fields
+}
+
+// parseEnvInstruction parses `ENV KEY=value ...` and the legacy `ENV KEY value` form.
+func parseEnvInstruction(args string) map[string]string {
+	env := make(map[string]string)
+	fields := strings.Fields(args)
+	if len(fields) == 0 {
+		return env
+	}
+	if !strings.Contains(fields[0], "=") {
+		env[fields[0]] = strings.Trim(strings.TrimSpace(strings.TrimPrefix(args, fields[0])), `"'`)
+		return env
+	}
+	for _, field := range fields {
+		if name, value, ok := strings.Cut(field, "="); ok {
+			env[name] = strings.Trim(value, `"'`)
+		}
+	}
+	return env
+}
+
+// expandDockerVars substitutes $VAR, ${VAR}, and ${VAR:-default} using vars.
+// Unknown variables expand to their default, or to an empty string.
+func expandDockerVars(value string, vars map[string]string) string {
+	return os.Expand(value, func(name string) string {
+		name, fallback, hasFallback := strings.Cut(name, ":-")
+		if v, ok := vars[name]; ok && (v != "" || !hasFallback) {
+			return v
+		}
+		return fallback
+	})
+}
+
+// appendPorts adds the ports of an EXPOSE value such as 8080, 8080/tcp, or 8000-8010/udp to ports.
+func appendPorts(ports []int, value string) []int {
+	value, _, _ = strings.Cut(value, "/")
+	start, end, isRange := strings.Cut(value, "-")
+	first, err := strconv.Atoi(start)
+	if err != nil || first < 1 || first > 65535 {
+		return ports
+	}
+	last := first
+	if isRange {
+		if n, err := strconv.Atoi(end); err == nil && n >= first && n <= 65535 && n-first < maxExposedPortRange {
+			last = n
+		}
+	}
+	for port := first; port <= last; port++ {
+		if !containsPort(ports, port) {
+			ports = append(ports, port)
+		}
+	}
+	return ports
+}
+
+func containsPort(ports []int, port int) bool {
+	for _, p := range ports {
+		if p == port {
+			return true
+		}
+	}
+	return false
+}
+
+// dockerfileMatcher collects one Dockerfile or Containerfile per directory.
+type dockerfileMatcher struct {
+	dockerfiles []types.Dockerfile
+	seen        map[string]bool
+}
+
+func newDockerfileMatcher() *dockerfileMatcher {
+	return &dockerfileMatcher{seen: make(map[string]bool)}
+}
+
+func (m *dockerfileMatcher) matchFile(path string, name string) {
+	if !IsDockerfileName(name) {
+		return
+	}
+
+	dir := filepath.Dir(path)
+	if m.seen[dir] {
+		return
+	}
+	m.seen[dir] = true
+	if dockerfile := DetectDockerfile(dir); dockerfile != nil {
+		m.dockerfiles = append(m.dockerfiles, *dockerfile)
+	}
+}
+
+// IsDockerfileName reports whether name is a recognized container build file name.
+func IsDockerfileName(name string) bool {
+	for _, candidate := range dockerfileNames {
+		if name == candidate {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,141 @@
+package detector
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseDockerfile(t *testing.T) {
+	tests := []struct {
+		name       string
+		content    string
+		wantImage  string
+		wantStages []string
+		wantPorts  []int
+		wantArgs   []string
+	}{
+		{
+			name: "single stage",
+			content: `FROM python:3.12-slim
+WORKDIR /app
+EXPOSE 8000
+CMD ["uvicorn", "main:app"]`,
+			wantImage: "python:3.12-slim",
+			wantPorts: []int{8000},
+		},
+		{
+			name: "multi-stage build with args",
+			content: `# syntax=docker/dockerfile:1
+ARG NODE_VERSION=20
+FROM --platform=$BUILDPLATFORM node:${NODE_VERSION}-alpine AS build
+ARG API_URL
+EXPOSE 9999
+RUN npm ci && \
+    npm run build
+
+FROM nginx:1.27 as runtime
+EXPOSE 80/tcp 443
+`,
+			wantImage:  "nginx:1.27",
+			wantStages: []string{"build", "runtime"},
+			wantPorts:  []int{80, 443},
+			wantArgs:   []string{"NODE_VERSION=20", "API_URL"},
+		},
+		{
+			name: "final stage built from an earlier stage",
+			content: `ARG TAG=8.0
+FROM mcr.microsoft.com/dotnet/aspnet:$TAG AS base
+EXPOSE 8080
+FROM base AS final
+ENV PORT=5000
+EXPOSE ${PORT}`,
+			wantImage:  "mcr.microsoft.com/dotnet/aspnet:8.0",
+			wantStages: []string{"base", "final"},
+			wantPorts:  []int{8080, 5000},
+			wantArgs:   []string{"TAG=8.0"},
+		},
+		{
+			name:      "escape directive and port range",
+			content:   "# escape=`\nfrom mcr.microsoft.com/windows/servercore:ltsc2022\nexpose 7000-7002/udp `\n  7000\n",
+			wantImage: "mcr.microsoft.com/windows/servercore:ltsc2022",
+			wantPorts: []int{7000, 7001, 7002},
+		},
+		{
+			name:      "variable with default",
+			content:   "FROM golang:${GO_VERSION:-1.25}\nEXPOSE $PORT\n",
+			wantImage: "golang:1.25",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "Dockerfile")
+			writeFileContent(t, path, tt.content)
+
+			dockerfile, err := ParseDockerfile(path)
+			if err != nil {
+				t.Fatalf("ParseDockerfile failed: %v", err)
+			}
+			if dockerfile.BaseImage != tt.wantImage {
+				t.Errorf("BaseImage = %q, want %q", dockerfile.BaseImage, tt.wantImage)
+			}
+			if !reflect.DeepEqual(dockerfile.Stages, tt.wantStages) {
+				t.Errorf("Stages = %v, want %v", dockerfile.Stages, tt.wantStages)
+			}
+			if !reflect.DeepEqual(dockerfile.ExposedPorts, tt.wantPorts) {
+				t.Errorf("ExposedPorts = %v, want %v", dockerfile.ExposedPorts, tt.wantPorts)
+			}
+			if !reflect.DeepEqual(dockerfile.BuildArgs, tt.wantArgs) {
+				t.Errorf("BuildArgs = %v, want %v", dockerfile.BuildArgs, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestFindDockerfiles(t *testing.T) {
+	dir := t.TempDir()
+	writeFileContent(t, filepath.Join(dir, "api", "Dockerfile"), "FROM node:20\nEXPOSE 3000\n")
+	writeFileContent(t, filepath.Join(dir, "api", "Containerfile"), "FROM node:18\n")
+	writeFileContent(t, filepath.Join(dir, "worker", "Containerfile"), "FROM python:3.12\n")
+	writeFileContent(t, filepath.Join(dir, "node_modules", "pkg", "Dockerfile"), "FROM scratch\n")
+
+	dockerfiles, err := FindDockerfiles(dir)
+	if err != nil {
+		t.Fatalf("FindDockerfiles failed: %v", err)
+	}
+
+	got := make(map[string]string)
+	for _, dockerfile := range dockerfiles {
+		rel, _ := filepath.Rel(dir, dockerfile.Path)
+		got[filepath.ToSlash(rel)] = dockerfile.BaseImage
+	}
+	want := map[string]string{"api/Dockerfile": "node:20", "worker/Containerfile": "python:3.12"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FindDockerfiles() = %v, want %v", got, want)
+	}
+}
+
+func TestScanWorkspaceAttachesDockerfile(t *testing.T) {
+	dir := t.TempDir()
+	writeFileContent(t, filepath.Join(dir, "web", "package.json"), `{"name": "web"}`)
+	writeFileContent(t, filepath.Join(dir, "web", "Dockerfile"), "FROM node:20\nEXPOSE 3000\n")
+	writeFileContent(t, filepath.Join(dir, "api", "requirements.txt"), "fastapi\n")
+
+	scan, err := ScanWorkspace(dir)
+	if err != nil {
+		t.Fatalf("ScanWorkspace failed: %v", err)
+	}
+	if len(scan.Dockerfiles) != 1 {
+		t.Fatalf("Dockerfiles = %d, want 1", len(scan.Dockerfiles))
+	}
+	if len(scan.NodeProjects) != 1 || scan.NodeProjects[0].Dockerfile == nil {
+		t.Fatalf("node project should have its Dockerfile attached: %+v", scan.NodeProjects)
+	}
+	if ports := scan.NodeProjects[0].Dockerfile.ExposedPorts; !reflect.DeepEqual(ports, []int{3000}) {
+		t.Errorf("ExposedPorts = %v, want [3000]", ports)
+	}
+	if len(scan.PythonProjects) != 1 || scan.PythonProjects[0].Dockerfile != nil {
+		t.Errorf("python project without a Dockerfile should have none attached: %+v", scan.PythonProjects)
+	}
+}
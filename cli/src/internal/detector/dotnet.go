@@ -0,0 +1,288 @@
+package detector
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/jongio/azd-app/cli/src/internal/types"
+)
+
+// slnProjectPattern matches project entries in a .sln file, e.g.
+// Project("{FAE04EC0-...}") = "Api", "src\Api\Api.csproj", "{GUID}"
+var slnProjectPattern = regexp.MustCompile(`(?m)^Project\("\{[^}]*\}"\)\s*=\s*"[^"]*",\s*"([^"]+)"`)
+
+// sdkDefaultOutputTypes are the output types of SDKs that build applications by default.
+// Projects using other SDKs, including plain Microsoft.NET.Sdk, default to "Library".
+var sdkDefaultOutputTypes = map[string]string{
+	"Microsoft.NET.Sdk.Web":               "Exe",
+	"Microsoft.NET.Sdk.Worker":            "Exe",
+	"Microsoft.NET.Sdk.BlazorWebAssembly": "Exe",
+}
+
+// projectFileXML is the subset of an MSBuild project file used for detection.
+type projectFileXML struct {
+	Sdk  string `xml:"Sdk,attr"`
+	Sdks []struct {
+		Name string `xml:"Name,attr"`
+	} `xml:"Sdk"`
+	Imports []struct {
+		Sdk string `xml:"Sdk,attr"`
+	} `xml:"Import"`
+	PropertyGroups []struct {
+		TargetFramework  string `xml:"TargetFramework"`
+		TargetFrameworks string `xml:"TargetFrameworks"`
+		OutputType       string `xml:"OutputType"`
+		AssemblyName     string `xml:"AssemblyName"`
+		IsAspireHost     string `xml:"IsAspireHost"`
+		IsAspireShared   string `xml:"IsAspireSharedProject"`
+		IsTestProject    string `xml:"IsTestProject"`
+		UserSecretsID    string `xml:"UserSecretsId"`
+	} `xml:"PropertyGroup"`
+	ItemGroups []struct {
+		PackageReferences []struct {
+			Include string `xml:"Include,attr"`
+		} `xml:"PackageReference"`
+		ProjectReferences []struct {
+			Include                 string `xml:"Include,attr"`
+			IsAspireProjectResource string `xml:"IsAspireProjectResource,attr"`
+		} `xml:"ProjectReference"`
+	} `xml:"ItemGroup"`
+}
+
+// ParseDotnetProject reads the target frameworks, SDK, output type, assembly name, user secrets
+// ID, and package references of a project file. Properties in conditional property groups are read like unconditional ones;
+// the first value found wins.
+func ParseDotnetProject(path string) (types.DotnetProject, error) {
+	project := types.DotnetProject{Path: path}
+
+	data, err := readProjectFile(path)
+	if err != nil {
+		return project, fmt.Errorf("failed to read project %s: %w", path, err)
+	}
+	var doc projectFileXML
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return project, fmt.Errorf("failed to parse project %s: %w", path, err)
+	}
+
+	// SDKs can be set on <Project Sdk="A;B/1.0">, as <Sdk Name="..."/>, or on <Import Sdk="..."/>
+	sdks := strings.Split(doc.Sdk, ";")
+	for _, sdk := range doc.Sdks {
+		sdks = append(sdks, sdk.Name)
+	}
+	for _, imp := range doc.Imports {
+		sdks = append(sdks, imp.Sdk)
+	}
+	for _, sdk := range sdks {
+		name, _, _ := strings.Cut(strings.TrimSpace(sdk), "/")
+		switch {
+		case name == "":
+		case strings.HasPrefix(name, "Aspire.AppHost.Sdk"):
+			project.IsAppHost = true
+		case project.Sdk == "" || project.Sdk == "Microsoft.NET.Sdk":
+			// Prefer the most specific SDK, e.g. Microsoft.NET.Sdk.Web over Microsoft.NET.Sdk
+			project.Sdk = name
+		}
+	}
+
+	var frameworks string
+	for _, group := range doc.PropertyGroups {
+		frameworks = firstNonEmpty(frameworks, strings.TrimSpace(group.TargetFrameworks), strings.TrimSpace(group.TargetFramework))
+		project.OutputType = firstNonEmpty(project.OutputType, strings.TrimSpace(group.OutputType))
+		project.AssemblyName = firstNonEmpty(project.AssemblyName, strings.TrimSpace(group.AssemblyName))
+		project.UserSecretsID = firstNonEmpty(project.UserSecretsID, strings.TrimSpace(group.UserSecretsID))
+		if strings.EqualFold(strings.TrimSpace(group.IsAspireHost), "true") {
+			project.IsAppHost = true
+		}
+		if strings.EqualFold(strings.TrimSpace(group.IsTestProject), "true") {
+			project.IsTestProject = true
+		}
+		if strings.EqualFold(strings.TrimSpace(group.IsAspireShared), "true") {
+			project.IsServiceDefaults = true
+		}
+	}
+	for _, framework := range strings.Split(frameworks, ";") {
+		if framework = strings.TrimSpace(framework); framework != "" {
+			project.TargetFrameworks = append(project.TargetFrameworks, framework)
+		}
+	}
+	for _, group := range doc.ItemGroups {
+		for _, ref := range group.PackageReferences {
+			if ref.Include != "" {
+				project.Packages = append(project.Packages, ref.Include)
+			}
+			if strings.EqualFold(ref.Include, "Microsoft.NET.Test.Sdk") {
+				project.IsTestProject = true
+			}
+		}
+		for _, ref := range group.ProjectReferences {
+			// An AppHost can reference projects that aren't resources, such as shared libraries
+			if ref.Include == "" || (project.IsAppHost && strings.EqualFold(strings.TrimSpace(ref.IsAspireProjectResource), "false")) {
+				continue
+			}
+			// Projects written on Windows use backslash separators
+			project.ProjectReferences = append(project.ProjectReferences, filepath.Join(filepath.Dir(path), filepath.FromSlash(strings.ReplaceAll(ref.Include, `\`, "/"))))
+		}
+	}
+
+	if isDotnetTestProject(path, project.Packages) {
+		project.IsTestProject = true
+	}
+	if strings.HasSuffix(strings.ToLower(strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))), "servicedefaults") {
+		project.IsServiceDefaults = true
+	}
+
+	if project.OutputType == "" {
+		project.OutputType = "Library"
+		if project.IsAppHost {
+			project.OutputType = "Exe"
+		} else if outputType, ok := sdkDefaultOutputTypes[project.Sdk]; ok {
+			project.OutputType = outputType
+		}
+	}
+	if project.AssemblyName == "" {
+		project.AssemblyName = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	// Invalid launch settings leave the project without profiles, as dotnet run fails on them
+	project.LaunchProfiles, _ = ParseLaunchSettings(filepath.Dir(path))
+	return project, nil
+}
+
+// projectFileExts are the extensions of C#, F#, and Visual Basic project files.
+var projectFileExts = map[string]bool{
+	".csproj": true,
+	".fsproj": true,
+	".vbproj": true,
+}
+
+// IsDotnetProjectFile reports whether a path is a .csproj, .fsproj, or .vbproj project file.
+func IsDotnetProjectFile(path string) bool {
+	return projectFileExts[strings.ToLower(filepath.Ext(path))]
+}
+
+// FindDotnetProjectFiles returns the .csproj, .fsproj, and .vbproj files directly in dir,
+// sorted by name.
+func FindDotnetProjectFiles(dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var files []string
+	for _, entry := range entries {
+		if !entry.IsDir() && IsDotnetProjectFile(entry.Name()) {
+			files = append(files, filepath.Join(dir, entry.Name()))
+		}
+	}
+	return files
+}
+
+// IsDotnetSolution reports whether a path is a .sln or .slnx solution file.
+func IsDotnetSolution(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return ext == ".sln" || ext == ".slnx"
+}
+
+// FindDotnetSolutions searches for .sln and .slnx files.
+// Only searches within rootDir and does not traverse outside it.
+func FindDotnetSolutions(rootDir string) ([]string, error) {
+	projects, err := FindDotnetProjects(rootDir)
+	var solutions []string
+	for _, project := range projects {
+		if IsDotnetSolution(project.Path) {
+			solutions = append(solutions, project.Path)
+		}
+	}
+	return solutions, err
+}
+
+// ParseSolutionProjects returns the absolute paths of the project files referenced by a
+// .sln or .slnx solution. Solution folders and other project types are ignored.
+func ParseSolutionProjects(solutionPath string) ([]string, error) {
+	data, err := readProjectFile(solutionPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read solution %s: %w", solutionPath, err)
+	}
+
+	var refs []string
+	if strings.EqualFold(filepath.Ext(solutionPath), ".slnx") {
+		refs, err = parseSlnxProjects(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse solution %s: %w", solutionPath, err)
+		}
+	} else {
+		for _, match := range slnProjectPattern.FindAllStringSubmatch(string(data), -1) {
+			refs = append(refs, match[1])
+		}
+	}
+
+	dir := filepath.Dir(solutionPath)
+	var projects []string
+	for _, ref := range refs {
+		// Solutions written on Windows use backslash separators
+		ref = filepath.FromSlash(strings.ReplaceAll(ref, `\`, "/"))
+		if !IsDotnetProjectFile(ref) {
+			continue
+		}
+		projects = append(projects, filepath.Join(dir, ref))
+	}
+	return projects, nil
+}
+
+// parseSlnxProjects returns the Path attribute of every <Project> element in a .slnx
+// file, including projects nested in <Folder> elements.
+func parseSlnxProjects(data []byte) ([]string, error) {
+	var refs []string
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return refs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		element, ok := token.(xml.StartElement)
+		if !ok || element.Name.Local != "Project" {
+			continue
+		}
+		for _, attr := range element.Attr {
+			if attr.Name.Local == "Path" && attr.Value != "" {
+				refs = append(refs, attr.Value)
+			}
+		}
+	}
+}
+
+// scopeToSolutions keeps the given solutions and the projects they reference,
+// dropping solutions and projects that are not part of them.
+func scopeToSolutions(projects []types.DotnetProject, solutions []string) ([]types.DotnetProject, error) {
+	referenced := make(map[string]bool)
+	for _, solution := range solutions {
+		solution, err := filepath.Abs(solution)
+		if err != nil {
+			return nil, err
+		}
+		referenced[solution] = true
+
+		paths, err := ParseSolutionProjects(solution)
+		if err != nil {
+			return nil, err
+		}
+		for _, path := range paths {
+			referenced[path] = true
+		}
+	}
+
+	var scoped []types.DotnetProject
+	for _, project := range projects {
+		if referenced[project.Path] {
+			scoped = append(scoped, project)
+		}
+	}
+	return scoped, nil
+}
@@ -0,0 +1,382 @@
+package detector
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+const testSln = `
+Microsoft Visual Studio Solution File, Format Version 12.00
+# Visual Studio Version 17
+Project("{FAE04EC0-301F-11D3-BF4B-00C04F79EFBC}") = "Api", "src\Api\Api.csproj", "{11111111-1111-1111-1111-111111111111}"
+EndProject
+Project("{2150E333-8FDC-42A3-9474-1A3956D46DE8}") = "src", "src", "{22222222-2222-2222-2222-222222222222}"
+EndProject
+Project("{9A19103F-16F7-4668-BE54-9A1E7A4F7556}") = "Web", "src/Web/Web.csproj", "{33333333-3333-3333-3333-333333333333}"
+EndProject
+Global
+EndGlobal
+`
+
+const testSlnx = `<Solution>
+  <Folder Name="/src/">
+    <Project Path="src/Api/Api.csproj" />
+  </Folder>
+  <Project Path="tests/Api.Tests/Api.Tests.csproj" />
+  <Project Path="docs/docs.esproj" />
+</Solution>
+`
+
+func TestParseSolutionProjects(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		content  string
+		expected []string
+	}{
+		{
+			name:     "sln with solution folder",
+			file:     "app.sln",
+			content:  testSln,
+			expected: []string{"src/Api/Api.csproj", "src/Web/Web.csproj"},
+		},
+		{
+			name:     "slnx with nested folder",
+			file:     "app.slnx",
+			content:  testSlnx,
+			expected: []string{"src/Api/Api.csproj", "tests/Api.Tests/Api.Tests.csproj"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, tt.file)
+			writeFileContent(t, path, tt.content)
+
+			projects, err := ParseSolutionProjects(path)
+			if err != nil {
+				t.Fatalf("ParseSolutionProjects failed: %v", err)
+			}
+			if len(projects) != len(tt.expected) {
+				t.Fatalf("got %v, want %v", projects, tt.expected)
+			}
+			for i, want := range tt.expected {
+				if projects[i] != filepath.Join(dir, filepath.FromSlash(want)) {
+					t.Errorf("projects[%d] = %s, want %s", i, projects[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseSolutionProjectsInvalidSlnx(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.slnx")
+	writeFileContent(t, path, "<Solution><Project Path=")
+
+	if _, err := ParseSolutionProjects(path); err == nil {
+		t.Error("expected error for malformed .slnx")
+	}
+}
+
+func TestFindDotnetSolutionsAndScopedProjects(t *testing.T) {
+	dir := t.TempDir()
+	writeFileContent(t, filepath.Join(dir, "app.slnx"), testSlnx)
+	writeFileContent(t, filepath.Join(dir, "src", "Api", "Api.csproj"), "<Project />")
+	writeFileContent(t, filepath.Join(dir, "tests", "Api.Tests", "Api.Tests.csproj"), "<Project />")
+	writeFileContent(t, filepath.Join(dir, "samples", "Stray", "Stray.csproj"), "<Project />")
+
+	solutions, err := FindDotnetSolutions(dir)
+	if err != nil {
+		t.Fatalf("FindDotnetSolutions failed: %v", err)
+	}
+	if len(solutions) != 1 || filepath.Base(solutions[0]) != "app.slnx" {
+		t.Fatalf("solutions = %v, want [app.slnx]", solutions)
+	}
+
+	all, err := FindDotnetProjects(dir)
+	if err != nil {
+		t.Fatalf("FindDotnetProjects failed: %v", err)
+	}
+	if len(all) != 4 {
+		t.Errorf("expected 4 projects without scoping, got %d", len(all))
+	}
+
+	scoped, err := FindDotnetProjects(dir, solutions...)
+	if err != nil {
+		t.Fatalf("FindDotnetProjects with solution failed: %v", err)
+	}
+	var got []string
+	for _, project := range scoped {
+		rel, _ := filepath.Rel(dir, project.Path)
+		got = append(got, filepath.ToSlash(rel))
+	}
+	sort.Strings(got)
+	want := []string{"app.slnx", "src/Api/Api.csproj", "tests/Api.Tests/Api.Tests.csproj"}
+	if len(got) != len(want) {
+		t.Fatalf("scoped projects = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("scoped projects = %v, want %v", got, want)
+			break
+		}
+	}
+}
+
+func TestIsDotnetSolution(t *testing.T) {
+	tests := map[string]bool{
+		"app.sln":     true,
+		"app.slnx":    true,
+		"App.SLN":     true,
+		"api.csproj":  false,
+		"solution.md": false,
+	}
+	for path, want := range tests {
+		if got := IsDotnetSolution(path); got != want {
+			t.Errorf("IsDotnetSolution(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
+
+func TestParseDotnetProject(t *testing.T) {
+	tests := []struct {
+		name           string
+		file           string
+		content        string
+		wantSdk        string
+		wantFrameworks []string
+		wantOutputType string
+		wantAssembly   string
+		wantAppHost    bool
+		wantTest       bool
+		wantRunnable   bool
+		wantSecretsID  string
+	}{
+		{
+			name:           "web api",
+			file:           "Api.csproj",
+			content:        `<Project Sdk="Microsoft.NET.Sdk.Web"><PropertyGroup><TargetFramework>net8.0</TargetFramework></PropertyGroup></Project>`,
+			wantSdk:        "Microsoft.NET.Sdk.Web",
+			wantFrameworks: []string{"net8.0"},
+			wantOutputType: "Exe",
+			wantAssembly:   "Api",
+			wantRunnable:   true,
+		},
+		{
+			name: "web api with user secrets",
+			file: "Api.csproj",
+			content: `<Project Sdk="Microsoft.NET.Sdk.Web">
+  <PropertyGroup>
+    <TargetFramework>net8.0</TargetFramework>
+    <UserSecretsId>aspnet-Api-1f0c</UserSecretsId>
+  </PropertyGroup>
+</Project>`,
+			wantSdk:        "Microsoft.NET.Sdk.Web",
+			wantFrameworks: []string{"net8.0"},
+			wantOutputType: "Exe",
+			wantAssembly:   "Api",
+			wantRunnable:   true,
+			wantSecretsID:  "aspnet-Api-1f0c",
+		},
+		{
+			name: "multi-targeted class library",
+			file: "Shared.csproj",
+			content: `<Project Sdk="Microsoft.NET.Sdk">
+  <PropertyGroup>
+    <TargetFrameworks>net8.0; net9.0</TargetFrameworks>
+    <AssemblyName>Contoso.Shared</AssemblyName>
+  </PropertyGroup>
+</Project>`,
+			wantSdk:        "Microsoft.NET.Sdk",
+			wantFrameworks: []string{"net8.0", "net9.0"},
+			wantOutputType: "Library",
+			wantAssembly:   "Contoso.Shared",
+		},
+		{
+			name:           "worker",
+			file:           "Worker.csproj",
+			content:        `<Project Sdk="Microsoft.NET.Sdk.Worker"><PropertyGroup><TargetFramework>net9.0</TargetFramework></PropertyGroup></Project>`,
+			wantSdk:        "Microsoft.NET.Sdk.Worker",
+			wantFrameworks: []string{"net9.0"},
+			wantOutputType: "Exe",
+			wantAssembly:   "Worker",
+			wantRunnable:   true,
+		},
+		{
+			name:           "aspire apphost sdk",
+			file:           "AppHost.csproj",
+			content:        `<Project Sdk="Aspire.AppHost.Sdk/9.2.0"><PropertyGroup><TargetFramework>net9.0</TargetFramework></PropertyGroup></Project>`,
+			wantFrameworks: []string{"net9.0"},
+			wantOutputType: "Exe",
+			wantAssembly:   "AppHost",
+			wantAppHost:    true,
+			wantRunnable:   true,
+		},
+		{
+			name: "aspire apphost sdk element",
+			file: "AppHost.csproj",
+			content: `<Project Sdk="Microsoft.NET.Sdk">
+  <Sdk Name="Aspire.AppHost.Sdk" Version="9.0.0" />
+  <PropertyGroup>
+    <OutputType>Exe</OutputType>
+    <IsAspireHost>true</IsAspireHost>
+  </PropertyGroup>
+</Project>`,
+			wantSdk:        "Microsoft.NET.Sdk",
+			wantOutputType: "Exe",
+			wantAssembly:   "AppHost",
+			wantAppHost:    true,
+			wantRunnable:   true,
+		},
+		{
+			name: "test project",
+			file: "Api.Tests.csproj",
+			content: `<Project Sdk="Microsoft.NET.Sdk">
+  <ItemGroup>
+    <PackageReference Include="Microsoft.NET.Test.Sdk" Version="17.10.0" />
+  </ItemGroup>
+</Project>`,
+			wantSdk:        "Microsoft.NET.Sdk",
+			wantOutputType: "Library",
+			wantAssembly:   "Api.Tests",
+			wantTest:       true,
+		},
+		{
+			name:           "test project named like one",
+			file:           "Api.IntegrationTests.csproj",
+			content:        `<Project Sdk="Microsoft.NET.Sdk"><PropertyGroup><OutputType>Exe</OutputType></PropertyGroup></Project>`,
+			wantSdk:        "Microsoft.NET.Sdk",
+			wantOutputType: "Exe",
+			wantAssembly:   "Api.IntegrationTests",
+			wantTest:       true,
+		},
+		{
+			name: "test project referencing a test framework",
+			file: "Checks.csproj",
+			content: `<Project Sdk="Microsoft.NET.Sdk">
+  <PropertyGroup><OutputType>Exe</OutputType></PropertyGroup>
+  <ItemGroup>
+    <PackageReference Include="xunit.v3" Version="1.0.0" />
+  </ItemGroup>
+</Project>`,
+			wantSdk:        "Microsoft.NET.Sdk",
+			wantOutputType: "Exe",
+			wantAssembly:   "Checks",
+			wantTest:       true,
+		},
+		{
+			name:           "console app",
+			file:           "Tool.csproj",
+			content:        `<Project Sdk="Microsoft.NET.Sdk"><PropertyGroup><OutputType>Exe</OutputType></PropertyGroup></Project>`,
+			wantSdk:        "Microsoft.NET.Sdk",
+			wantOutputType: "Exe",
+			wantAssembly:   "Tool",
+			wantRunnable:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			writeFileContent(t, path, tt.content)
+
+			project, err := ParseDotnetProject(path)
+			if err != nil {
+				t.Fatalf("ParseDotnetProject failed: %v", err)
+			}
+			if project.Sdk != tt.wantSdk {
+				t.Errorf("Sdk = %q, want %q", project.Sdk, tt.wantSdk)
+			}
+			if strings.Join(project.TargetFrameworks, ";") != strings.Join(tt.wantFrameworks, ";") {
+				t.Errorf("TargetFrameworks = %v, want %v", project.TargetFrameworks, tt.wantFrameworks)
+			}
+			if project.OutputType != tt.wantOutputType {
+				t.Errorf("OutputType = %q, want %q", project.OutputType, tt.wantOutputType)
+			}
+			if project.AssemblyName != tt.wantAssembly {
+				t.Errorf("AssemblyName = %q, want %q", project.AssemblyName, tt.wantAssembly)
+			}
+			if project.IsAppHost != tt.wantAppHost || project.IsTestProject != tt.wantTest {
+				t.Errorf("IsAppHost = %v, IsTestProject = %v, want %v, %v", project.IsAppHost, project.IsTestProject, tt.wantAppHost, tt.wantTest)
+			}
+			if project.UserSecretsID != tt.wantSecretsID {
+				t.Errorf("UserSecretsID = %q, want %q", project.UserSecretsID, tt.wantSecretsID)
+			}
+			if project.IsRunnable() != tt.wantRunnable {
+				t.Errorf("IsRunnable() = %v, want %v", project.IsRunnable(), tt.wantRunnable)
+			}
+		})
+	}
+}
+
+func TestParseDotnetProjectInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "Broken.csproj")
+	writeFileContent(t, path, "<Project")
+
+	project, err := ParseDotnetProject(path)
+	if err == nil {
+		t.Error("expected error for malformed project")
+	}
+	if project.Path != path {
+		t.Errorf("Path = %q, want %q", project.Path, path)
+	}
+}
+
+func TestFindDotnetProjectsMixedLanguages(t *testing.T) {
+	dir := t.TempDir()
+	writeFileContent(t, filepath.Join(dir, "src", "Api", "Api.csproj"), `<Project Sdk="Microsoft.NET.Sdk.Web" />`)
+	writeFileContent(t, filepath.Join(dir, "src", "Pricing", "Pricing.fsproj"),
+		`<Project Sdk="Microsoft.NET.Sdk"><PropertyGroup><TargetFramework>net8.0</TargetFramework></PropertyGroup></Project>`)
+	writeFileContent(t, filepath.Join(dir, "src", "Legacy", "Legacy.vbproj"),
+		`<Project Sdk="Microsoft.NET.Sdk"><PropertyGroup><OutputType>Exe</OutputType></PropertyGroup></Project>`)
+	writeFileContent(t, filepath.Join(dir, "app.sln"), `
+Project("{F2A71F9B-5D33-465A-A702-920D77279786}") = "Pricing", "src\Pricing\Pricing.fsproj", "{11111111-1111-1111-1111-111111111111}"
+EndProject
+Project("{F184B08F-C81C-45F6-A57F-5ABD9991F28F}") = "Legacy", "src\Legacy\Legacy.vbproj", "{22222222-2222-2222-2222-222222222222}"
+EndProject
+`)
+
+	all, err := FindDotnetProjects(dir)
+	if err != nil {
+		t.Fatalf("FindDotnetProjects failed: %v", err)
+	}
+	byName := make(map[string]string)
+	for _, project := range all {
+		byName[filepath.Base(project.Path)] = project.OutputType
+	}
+	want := map[string]string{"Api.csproj": "Exe", "Pricing.fsproj": "Library", "Legacy.vbproj": "Exe", "app.sln": ""}
+	if len(byName) != len(want) {
+		t.Fatalf("projects = %v, want %v", byName, want)
+	}
+	for name, outputType := range want {
+		if got, ok := byName[name]; !ok || got != outputType {
+			t.Errorf("%s output type = %q (found %v), want %q", name, got, ok, outputType)
+		}
+	}
+
+	scoped, err := FindDotnetProjects(dir, filepath.Join(dir, "app.sln"))
+	if err != nil {
+		t.Fatalf("FindDotnetProjects with solution failed: %v", err)
+	}
+	if len(scoped) != 3 {
+		t.Errorf("expected solution and its F# and VB projects, got %v", scoped)
+	}
+}
+
+func TestIsDotnetProjectFile(t *testing.T) {
+	tests := map[string]bool{
+		"Api.csproj":    true,
+		"Lib.fsproj":    true,
+		"Legacy.VBPROJ": true,
+		"app.sln":       false,
+		"docs.esproj":   false,
+	}
+	for path, want := range tests {
+		if got := IsDotnetProjectFile(path); got != want {
+			t.Errorf("IsDotnetProjectFile(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
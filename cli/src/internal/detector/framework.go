@@ -0,0 +1,85 @@
+package detector
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jongio/azd-app/cli/src/internal/security"
+)
+
+// nodeFrameworkRule identifies a Node.js framework by config file or package dependency.
+type nodeFrameworkRule struct {
+	framework    string
+	configFiles  []string
+	dependencies []string
+}
+
+// nodeFrameworkRules are checked in order; meta-frameworks come before the
+// libraries they build on (e.g. Next.js before React, SvelteKit before Vite).
+var nodeFrameworkRules = []nodeFrameworkRule{
+	{"Next.js", []string{"next.config.js", "next.config.mjs", "next.config.ts"}, []string{"next"}},
+	{"Nuxt", []string{"nuxt.config.js", "nuxt.config.ts"}, []string{"nuxt"}},
+	{"Remix", []string{"remix.config.js", "remix.config.mjs"}, []string{"@remix-run/dev", "@remix-run/react"}},
+	{"Astro", []string{"astro.config.mjs", "astro.config.js", "astro.config.ts"}, []string{"astro"}},
+	{"SvelteKit", []string{"svelte.config.js"}, []string{"@sveltejs/kit"}},
+	{"Angular", []string{"angular.json"}, []string{"@angular/core"}},
+	{"NestJS", []string{"nest-cli.json"}, []string{"@nestjs/core"}},
+	{"Vite", []string{"vite.config.js", "vite.config.mjs", "vite.config.ts"}, []string{"vite"}},
+	{"React", nil, []string{"react"}},
+	{"Vue", nil, []string{"vue"}},
+	{"Express", nil, []string{"express"}},
+}
+
+// DetectNodeFramework identifies the framework of a Node.js project from its
+// config files and package.json dependencies. Returns "Node.js" when no framework matches.
+func DetectNodeFramework(projectDir string) string {
+	deps := readPackageDependencies(projectDir)
+
+	for _, rule := range nodeFrameworkRules {
+		for _, name := range rule.configFiles {
+			if _, err := os.Stat(filepath.Join(projectDir, name)); err == nil {
+				return rule.framework
+			}
+		}
+		for _, dep := range rule.dependencies {
+			if deps[dep] {
+				return rule.framework
+			}
+		}
+	}
+
+	return "Node.js"
+}
+
+// readPackageDependencies returns the names of all dependencies and devDependencies in package.json.
+func readPackageDependencies(projectDir string) map[string]bool {
+	deps := make(map[string]bool)
+
+	packageJSONPath := filepath.Join(projectDir, "package.json")
+	if err := security.ValidatePath(packageJSONPath); err != nil {
+		return deps
+	}
+	// #nosec G304 -- Path validated by security.ValidatePath
+	data, err := os.ReadFile(packageJSONPath)
+	if err != nil {
+		return deps
+	}
+
+	var pkg struct {
+		Dependencies    map[string]string `json:"dependencies"`
+		DevDependencies map[string]string `json:"devDependencies"`
+	}
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return deps
+	}
+
+	for name := range pkg.Dependencies {
+		deps[strings.TrimSpace(name)] = true
+	}
+	for name := range pkg.DevDependencies {
+		deps[strings.TrimSpace(name)] = true
+	}
+	return deps
+}
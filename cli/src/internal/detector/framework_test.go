@@ -0,0 +1,65 @@
+package detector
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDetectNodeFramework(t *testing.T) {
+	tests := []struct {
+		name        string
+		packageJSON string
+		configFile  string
+		want        string
+	}{
+		{"next from dependency", `{"dependencies": {"next": "14.0.0", "react": "18.0.0"}}`, "", "Next.js"},
+		{"next from config", `{}`, "next.config.mjs", "Next.js"},
+		{"vite with react", `{"dependencies": {"react": "18.0.0"}, "devDependencies": {"vite": "5.0.0"}}`, "", "Vite"},
+		{"vite from config", `{}`, "vite.config.ts", "Vite"},
+		{"remix", `{"dependencies": {"@remix-run/react": "2.0.0"}, "devDependencies": {"vite": "5.0.0"}}`, "vite.config.ts", "Remix"},
+		{"astro", `{"dependencies": {"astro": "4.0.0"}}`, "", "Astro"},
+		{"sveltekit before vite", `{"devDependencies": {"@sveltejs/kit": "2.0.0", "vite": "5.0.0"}}`, "vite.config.js", "SvelteKit"},
+		{"angular", `{"dependencies": {"@angular/core": "17.0.0"}}`, "", "Angular"},
+		{"nestjs", `{"dependencies": {"@nestjs/core": "10.0.0", "express": "4.0.0"}}`, "", "NestJS"},
+		{"express", `{"dependencies": {"express": "4.0.0"}}`, "", "Express"},
+		{"plain react", `{"dependencies": {"react": "18.0.0"}}`, "", "React"},
+		{"script mentioning express is not a dependency", `{"scripts": {"start": "node express.js"}}`, "", "Node.js"},
+		{"invalid package.json", `{`, "", "Node.js"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(tmpDir, "package.json"), []byte(tt.packageJSON), 0600); err != nil {
+				t.Fatalf("failed to write package.json: %v", err)
+			}
+			if tt.configFile != "" {
+				writeTestFile(t, filepath.Join(tmpDir, tt.configFile))
+			}
+
+			if got := DetectNodeFramework(tmpDir); got != tt.want {
+				t.Errorf("DetectNodeFramework() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindNodeProjectsSetsFramework(t *testing.T) {
+	tmpDir := t.TempDir()
+	webDir := filepath.Join(tmpDir, "web")
+	if err := os.MkdirAll(webDir, 0750); err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(webDir, "package.json"), []byte(`{"dependencies": {"next": "14.0.0"}}`), 0600); err != nil {
+		t.Fatalf("failed to write package.json: %v", err)
+	}
+
+	projects, err := FindNodeProjects(tmpDir)
+	if err != nil {
+		t.Fatalf("FindNodeProjects() error = %v", err)
+	}
+	if len(projects) != 1 || projects[0].Framework != "Next.js" {
+		t.Errorf("FindNodeProjects() = %+v, want one Next.js project", projects)
+	}
+}
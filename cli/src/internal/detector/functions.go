@@ -0,0 +1,172 @@
+package detector
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/jongio/azd-app/cli/src/internal/types"
+)
+
+// Azure Functions worker runtimes, as used by FUNCTIONS_WORKER_RUNTIME.
+const (
+	FunctionsRuntimeDotnet         = "dotnet"
+	FunctionsRuntimeDotnetIsolated = "dotnet-isolated"
+	FunctionsRuntimeNode           = "node"
+	FunctionsRuntimePython         = "python"
+)
+
+// pythonFunctionAppPattern matches the v2 programming model app, e.g. `app = func.FunctionApp()`.
+var pythonFunctionAppPattern = regexp.MustCompile(`\b(?:func\.)?(?:Async)?FunctionApp\(`)
+
+// FindFunctionProjects searches for Azure Functions apps.
+// Only searches within rootDir and does not traverse outside it.
+func FindFunctionProjects(rootDir string) ([]types.FunctionProject, error) {
+	rootDir, err := filepath.Abs(rootDir)
+	if err != nil {
+		return nil, err
+	}
+
+	m := newFunctionMatcher()
+	if err := walkWorkspace(rootDir, m); err != nil {
+		return nil, err
+	}
+	return m.projects, nil
+}
+
+// DetectFunctionProject reports whether dir is the root of an Azure Functions app and, if so,
+// its worker runtime. An app is recognized by a host.json with a version, a function.json in
+// one of its function folders, or the Functions SDK: Microsoft.Azure.Functions.Worker
+// (isolated) or Microsoft.NET.Sdk.Functions (in-process) for .NET, @azure/functions for
+// Node.js, and a FunctionApp() with decorated functions for Python.
+// FUNCTIONS_WORKER_RUNTIME in local.settings.json takes precedence over the detected runtime.
+// Returns nil when dir is not a Functions app.
+func DetectFunctionProject(dir string) *types.FunctionProject {
+	project := &types.FunctionProject{Dir: dir}
+
+	runtime := ""
+	if projectFile, dotnetRuntime := dotnetFunctionsRuntime(dir); dotnetRuntime != "" {
+		project.ProjectFile = projectFile
+		runtime = dotnetRuntime
+	} else if readPackageDependencies(dir)["@azure/functions"] {
+		runtime = FunctionsRuntimeNode
+	} else if isPythonFunctionApp(dir) {
+		runtime = FunctionsRuntimePython
+	}
+
+	hasHost := isFunctionsHostFile(filepath.Join(dir, "host.json"))
+	if runtime == "" && (hasHost || hasFunctionJSON(dir)) {
+		// Apps without an SDK reference, e.g. the v1 programming model, are identified by their files
+		switch {
+		case len(FindDotnetProjectFiles(dir)) > 0:
+			runtime = FunctionsRuntimeDotnet
+		case fileExistsIn(dir, "package.json"):
+			runtime = FunctionsRuntimeNode
+		case fileExistsIn(dir, "requirements.txt") || fileExistsIn(dir, "pyproject.toml") || fileExistsIn(dir, "function_app.py"):
+			runtime = FunctionsRuntimePython
+		}
+	} else if runtime == "" {
+		return nil
+	}
+
+	project.Runtime = firstNonEmpty(functionsWorkerRuntime(dir), runtime)
+	project.IsIsolated = project.Runtime == FunctionsRuntimeDotnetIsolated
+	return project
+}
+
+// dotnetFunctionsRuntime returns the first project file in dir that references the Functions
+// SDK, and whether it uses the isolated worker or in-process model.
+func dotnetFunctionsRuntime(dir string) (string, string) {
+	for _, path := range FindDotnetProjectFiles(dir) {
+		project, err := ParseDotnetProject(path)
+		if err != nil {
+			continue
+		}
+		for _, pkg := range project.Packages {
+			switch {
+			case strings.EqualFold(pkg, "Microsoft.Azure.Functions.Worker"):
+				return path, FunctionsRuntimeDotnetIsolated
+			case strings.EqualFold(pkg, "Microsoft.NET.Sdk.Functions"):
+				return path, FunctionsRuntimeDotnet
+			}
+		}
+	}
+	return "", ""
+}
+
+// isPythonFunctionApp reports whether function_app.py defines a v2 programming model app.
+func isPythonFunctionApp(dir string) bool {
+	data, err := readProjectFile(filepath.Join(dir, "function_app.py"))
+	return err == nil && pythonFunctionAppPattern.Match(data)
+}
+
+// isFunctionsHostFile reports whether path is a Functions host.json, which always has a version.
+func isFunctionsHostFile(path string) bool {
+	data, err := readProjectFile(path)
+	if err != nil {
+		return false
+	}
+	var host struct {
+		Version string `json:"version"`
+	}
+	return json.Unmarshal(data, &host) == nil && host.Version != ""
+}
+
+// hasFunctionJSON reports whether a direct subdirectory of dir defines a function.
+func hasFunctionJSON(dir string) bool {
+	matches, err := filepath.Glob(filepath.Join(dir, "*", "function.json"))
+	return err == nil && len(matches) > 0
+}
+
+// functionsWorkerRuntime returns FUNCTIONS_WORKER_RUNTIME from local.settings.json, if set.
+func functionsWorkerRuntime(dir string) string {
+	data, err := readProjectFile(filepath.Join(dir, "local.settings.json"))
+	if err != nil {
+		return ""
+	}
+	var settings struct {
+		Values map[string]string `json:"Values"`
+	}
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return ""
+	}
+	return strings.ToLower(strings.TrimSpace(settings.Values["FUNCTIONS_WORKER_RUNTIME"]))
+}
+
+// fileExistsIn reports whether name exists in dir.
+func fileExistsIn(dir string, name string) bool {
+	_, err := os.Stat(filepath.Join(dir, name))
+	return err == nil
+}
+
+// functionMatcher collects Azure Functions app directories.
+type functionMatcher struct {
+	projects []types.FunctionProject
+	seen     map[string]bool
+}
+
+func newFunctionMatcher() *functionMatcher {
+	return &functionMatcher{seen: make(map[string]bool)}
+}
+
+func (m *functionMatcher) matchFile(path string, name string) {
+	dir := filepath.Dir(path)
+	switch {
+	case name == "host.json", name == "function_app.py", name == "package.json", IsDotnetProjectFile(name):
+	case name == "function.json":
+		// Functions live in subfolders of the app
+		dir = filepath.Dir(dir)
+	default:
+		return
+	}
+
+	if m.seen[dir] {
+		return
+	}
+	m.seen[dir] = true
+	if project := DetectFunctionProject(dir); project != nil {
+		m.projects = append(m.projects, *project)
+	}
+}
@@ -0,0 +1,152 @@
+package detector
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+const testHostJSON = `{"version": "2.0", "logging": {}}`
+
+func TestDetectFunctionProject(t *testing.T) {
+	tests := []struct {
+		name         string
+		files        map[string]string
+		wantRuntime  string
+		wantIsolated bool
+		wantProject  string
+		wantNil      bool
+	}{
+		{
+			name: "dotnet isolated worker",
+			files: map[string]string{
+				"host.json": testHostJSON,
+				"Api.csproj": `<Project Sdk="Microsoft.NET.Sdk">
+  <ItemGroup>
+    <PackageReference Include="Microsoft.Azure.Functions.Worker" Version="1.23.0" />
+    <PackageReference Include="Microsoft.Azure.Functions.Worker.Sdk" Version="1.18.0" />
+  </ItemGroup>
+</Project>`,
+			},
+			wantRuntime:  FunctionsRuntimeDotnetIsolated,
+			wantIsolated: true,
+			wantProject:  "Api.csproj",
+		},
+		{
+			name: "dotnet in-process",
+			files: map[string]string{
+				"Legacy.csproj": `<Project Sdk="Microsoft.NET.Sdk"><ItemGroup><PackageReference Include="Microsoft.NET.Sdk.Functions" Version="4.4.0" /></ItemGroup></Project>`,
+			},
+			wantRuntime: FunctionsRuntimeDotnet,
+			wantProject: "Legacy.csproj",
+		},
+		{
+			name: "node v4 programming model",
+			files: map[string]string{
+				"host.json":    testHostJSON,
+				"package.json": `{"dependencies": {"@azure/functions": "^4.0.0"}}`,
+			},
+			wantRuntime: FunctionsRuntimeNode,
+		},
+		{
+			name: "python v2 decorators",
+			files: map[string]string{
+				"function_app.py":  "import azure.functions as func\napp = func.FunctionApp()\n\n@app.route(route=\"hello\")\ndef hello(req): ...\n",
+				"requirements.txt": "azure-functions\n",
+			},
+			wantRuntime: FunctionsRuntimePython,
+		},
+		{
+			name: "python v1 function.json",
+			files: map[string]string{
+				"host.json":                 testHostJSON,
+				"requirements.txt":          "azure-functions\n",
+				"HttpTrigger/__init__.py":   "def main(req): ...\n",
+				"HttpTrigger/function.json": `{"bindings": []}`,
+			},
+			wantRuntime: FunctionsRuntimePython,
+		},
+		{
+			name: "worker runtime from local.settings.json",
+			files: map[string]string{
+				"host.json":           testHostJSON,
+				"local.settings.json": `{"IsEncrypted": false, "Values": {"FUNCTIONS_WORKER_RUNTIME": "PowerShell"}}`,
+				"profile.ps1":         "",
+			},
+			wantRuntime: "powershell",
+		},
+		{
+			name: "host.json without version",
+			files: map[string]string{
+				"host.json":    `{"name": "not functions"}`,
+				"package.json": `{"dependencies": {"express": "^4.0.0"}}`,
+			},
+			wantNil: true,
+		},
+		{
+			name: "plain node project",
+			files: map[string]string{
+				"package.json": `{"dependencies": {"express": "^4.0.0"}}`,
+			},
+			wantNil: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				writeFileContent(t, filepath.Join(dir, filepath.FromSlash(name)), content)
+			}
+
+			project := DetectFunctionProject(dir)
+			if tt.wantNil {
+				if project != nil {
+					t.Errorf("expected no function project, got %+v", project)
+				}
+				return
+			}
+			if project == nil {
+				t.Fatal("expected a function project")
+			}
+			if project.Runtime != tt.wantRuntime {
+				t.Errorf("Runtime = %q, want %q", project.Runtime, tt.wantRuntime)
+			}
+			if project.IsIsolated != tt.wantIsolated {
+				t.Errorf("IsIsolated = %v, want %v", project.IsIsolated, tt.wantIsolated)
+			}
+			if filepath.Base(project.ProjectFile) != filepath.Base(tt.wantProject) || (tt.wantProject == "") != (project.ProjectFile == "") {
+				t.Errorf("ProjectFile = %q, want %q", project.ProjectFile, tt.wantProject)
+			}
+		})
+	}
+}
+
+func TestFindFunctionProjects(t *testing.T) {
+	dir := t.TempDir()
+	writeFileContent(t, filepath.Join(dir, "api", "host.json"), testHostJSON)
+	writeFileContent(t, filepath.Join(dir, "api", "package.json"), `{"dependencies": {"@azure/functions": "^4.0.0"}}`)
+	writeFileContent(t, filepath.Join(dir, "jobs", "host.json"), testHostJSON)
+	writeFileContent(t, filepath.Join(dir, "jobs", "Cleanup", "function.json"), `{"bindings": []}`)
+	writeFileContent(t, filepath.Join(dir, "jobs", "requirements.txt"), "azure-functions\n")
+	writeFileContent(t, filepath.Join(dir, "web", "package.json"), `{"dependencies": {"react": "^18.0.0"}}`)
+
+	projects, err := FindFunctionProjects(dir)
+	if err != nil {
+		t.Fatalf("FindFunctionProjects failed: %v", err)
+	}
+
+	got := make(map[string]string)
+	for _, project := range projects {
+		rel, _ := filepath.Rel(dir, project.Dir)
+		got[filepath.ToSlash(rel)] = project.Runtime
+	}
+	want := map[string]string{"api": FunctionsRuntimeNode, "jobs": FunctionsRuntimePython}
+	if len(got) != len(want) {
+		t.Fatalf("projects = %v, want %v", got, want)
+	}
+	for dir, runtime := range want {
+		if got[dir] != runtime {
+			t.Errorf("runtime of %s = %q, want %q", dir, got[dir], runtime)
+		}
+	}
+}
@@ -0,0 +1,234 @@
+package detector
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync/atomic"
+
+	"github.com/jongio/azd-app/cli/src/internal/security"
+)
+
+// ignoreFileNames lists the files whose patterns exclude paths from detection.
+var ignoreFileNames = []string{".gitignore", ".azdignore"}
+
+// respectIgnoreFiles controls whether detectors honor .gitignore and .azdignore.
+var respectIgnoreFiles atomic.Bool
+
+func init() {
+	respectIgnoreFiles.Store(true)
+}
+
+// SetRespectIgnoreFiles enables or disables .gitignore and .azdignore handling for all detectors.
+func SetRespectIgnoreFiles(enabled bool) {
+	respectIgnoreFiles.Store(enabled)
+}
+
+// RespectIgnoreFiles reports whether detectors honor .gitignore and .azdignore.
+func RespectIgnoreFiles() bool {
+	return respectIgnoreFiles.Load()
+}
+
+// ignoreRule is a single parsed pattern from an ignore file.
+type ignoreRule struct {
+	base    string // directory containing the ignore file
+	pattern string // the line the rule was parsed from
+	source  string // the file the rule is from
+	regex   *regexp.Regexp
+	negate  bool
+	dirOnly bool
+	hasPath bool // pattern contains a slash and matches against the path relative to base
+}
+
+// ignoreRules holds the ignore rules loaded for each directory visited during a walk.
+type ignoreRules struct {
+	rootDir   string
+	loadFiles bool
+	byDir     map[string][]ignoreRule
+}
+
+// newIgnoreRules creates the rule set for a walk of rootDir.
+// Globs configured for rootDir always apply; ignore files are read only when loadFiles is set.
+func newIgnoreRules(rootDir string, loadFiles bool) *ignoreRules {
+	r := &ignoreRules{rootDir: rootDir, loadFiles: loadFiles, byDir: make(map[string][]ignoreRule)}
+	for _, glob := range loadIgnoreGlobs(rootDir) {
+		if rule, ok := parseIgnoreLine(rootDir, glob.pattern); ok {
+			rule.source = glob.source
+			r.byDir[rootDir] = append(r.byDir[rootDir], rule)
+		}
+	}
+	return r
+}
+
+// load reads the ignore files in dir, if any.
+func (r *ignoreRules) load(dir string) {
+	if !r.loadFiles {
+		return
+	}
+	for _, name := range ignoreFileNames {
+		r.byDir[dir] = append(r.byDir[dir], parseIgnoreFile(dir, filepath.Join(dir, name))...)
+	}
+}
+
+// ignored reports whether path is excluded by the ignore files of its ancestors.
+// Rules in deeper directories override those closer to the root, and later rules
+// within a file override earlier ones.
+func (r *ignoreRules) ignored(path string, isDir bool) bool {
+	_, ignored := r.match(path, isDir)
+	return ignored
+}
+
+// match returns the rule that decides whether path is ignored, and whether it is.
+func (r *ignoreRules) match(path string, isDir bool) (ignoreRule, bool) {
+	if len(r.byDir) == 0 {
+		return ignoreRule{}, false
+	}
+
+	// Collect ancestor directories from rootDir down to the parent of path
+	var dirs []string
+	for dir := filepath.Dir(path); ; dir = filepath.Dir(dir) {
+		dirs = append(dirs, dir)
+		if dir == r.rootDir || dir == filepath.Dir(dir) {
+			break
+		}
+	}
+
+	var decided ignoreRule
+	ignored := false
+	for i := len(dirs) - 1; i >= 0; i-- {
+		for _, rule := range r.byDir[dirs[i]] {
+			if rule.matches(path, isDir) {
+				decided, ignored = rule, !rule.negate
+			}
+		}
+	}
+	return decided, ignored
+}
+
+// matches reports whether the rule applies to path.
+func (rule ignoreRule) matches(path string, isDir bool) bool {
+	if rule.dirOnly && !isDir {
+		return false
+	}
+
+	rel, err := filepath.Rel(rule.base, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return false
+	}
+	rel = filepath.ToSlash(rel)
+
+	if rule.hasPath {
+		return rule.regex.MatchString(rel)
+	}
+	return rule.regex.MatchString(filepath.Base(path))
+}
+
+// parseIgnoreFile reads gitignore-style patterns from path.
+// Missing or unreadable files yield no rules.
+func parseIgnoreFile(base, path string) []ignoreRule {
+	if err := security.ValidatePath(path); err != nil {
+		return nil
+	}
+	// #nosec G304 -- Path validated by security.ValidatePath
+	file, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	var rules []ignoreRule
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if rule, ok := parseIgnoreLine(base, scanner.Text()); ok {
+			rule.source = path
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// parseIgnoreLine parses a single gitignore-style pattern.
+func parseIgnoreLine(base, line string) (ignoreRule, bool) {
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return ignoreRule{}, false
+	}
+
+	rule := ignoreRule{base: base, pattern: line}
+	if strings.HasPrefix(line, "!") {
+		rule.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\`) {
+		// Escaped leading ! or #
+		line = line[1:]
+	}
+
+	if strings.HasSuffix(line, "/") {
+		rule.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+
+	// A slash at the start or in the middle anchors the pattern to base
+	if strings.Contains(line, "/") {
+		rule.hasPath = true
+		line = strings.TrimPrefix(line, "/")
+	}
+	if line == "" {
+		return ignoreRule{}, false
+	}
+
+	regex, err := regexp.Compile("^" + globToRegexp(line) + "$")
+	if err != nil {
+		return ignoreRule{}, false
+	}
+	rule.regex = regex
+	return rule, true
+}
+
+// globToRegexp converts a gitignore glob into a regular expression.
+// Supports *, ?, [...] character classes, and ** across directories.
+func globToRegexp(glob string) string {
+	var sb strings.Builder
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch c {
+		case '*':
+			if i+1 < len(glob) && glob[i+1] == '*' {
+				i++
+				if i+1 < len(glob) && glob[i+1] == '/' {
+					// "**/" matches zero or more directories
+					i++
+					sb.WriteString("(?:.*/)?")
+				} else {
+					sb.WriteString(".*")
+				}
+			} else {
+				sb.WriteString("[^/]*")
+			}
+		case '?':
+			sb.WriteString("[^/]")
+		case '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				sb.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			sb.WriteString("[" + class + "]")
+			i += end + 1
+		case '\\':
+			if i+1 < len(glob) {
+				i++
+				sb.WriteString(regexp.QuoteMeta(string(glob[i])))
+			}
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,97 @@
+package detector
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseIgnoreLine(t *testing.T) {
+	base := filepath.Join(string(filepath.Separator), "repo")
+
+	tests := []struct {
+		name    string
+		line    string
+		path    string
+		isDir   bool
+		want    bool
+		wantErr bool // line should not produce a rule
+	}{
+		{name: "comment", line: "# dist", wantErr: true},
+		{name: "blank", line: "   ", wantErr: true},
+		{name: "name matches at any depth", line: "dist", path: "web/dist", isDir: true, want: true},
+		{name: "dir only skips files", line: "dist/", path: "web/dist", isDir: false, want: false},
+		{name: "dir only matches dirs", line: "dist/", path: "web/dist", isDir: true, want: true},
+		{name: "anchored matches root", line: "/build", path: "build", isDir: true, want: true},
+		{name: "anchored ignores nested", line: "/build", path: "src/build", isDir: true, want: false},
+		{name: "wildcard", line: "*.log", path: "logs/app.log", want: true},
+		{name: "path pattern", line: "samples/*", path: "samples/demo", isDir: true, want: true},
+		{name: "double star prefix", line: "**/generated", path: "a/b/generated", isDir: true, want: true},
+		{name: "double star suffix", line: "tools/**", path: "tools/x/package.json", want: true},
+		{name: "character class", line: "out[0-9]", path: "out1", isDir: true, want: true},
+		{name: "question mark", line: "tmp?", path: "tmpa", isDir: true, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, ok := parseIgnoreLine(base, tt.line)
+			if ok == tt.wantErr {
+				t.Fatalf("parseIgnoreLine(%q) ok = %v, want %v", tt.line, ok, !tt.wantErr)
+			}
+			if !ok {
+				return
+			}
+			path := filepath.Join(base, filepath.FromSlash(tt.path))
+			if got := rule.matches(path, tt.isDir); got != tt.want {
+				t.Errorf("rule %q matches(%q) = %v, want %v", tt.line, tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWalkRespectsIgnoreFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	writeTestFile(t, filepath.Join(tmpDir, "web", "package.json"))
+	writeTestFile(t, filepath.Join(tmpDir, "web", "dist", "package.json"))
+	writeTestFile(t, filepath.Join(tmpDir, "samples", "demo", "package.json"))
+	writeTestFile(t, filepath.Join(tmpDir, "samples", "keep", "package.json"))
+	writeTestFile(t, filepath.Join(tmpDir, "api", "requirements.txt"))
+	writeTestFile(t, filepath.Join(tmpDir, "api", "build", "pyproject.toml"))
+
+	if err := os.WriteFile(filepath.Join(tmpDir, ".gitignore"), []byte("dist/\nsamples/*\n!samples/keep\n"), 0600); err != nil {
+		t.Fatalf("failed to write .gitignore: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "api", ".azdignore"), []byte("build\n"), 0600); err != nil {
+		t.Fatalf("failed to write .azdignore: %v", err)
+	}
+
+	node, err := FindNodeProjects(tmpDir)
+	if err != nil {
+		t.Fatalf("FindNodeProjects() error = %v", err)
+	}
+	if len(node) != 2 {
+		t.Errorf("FindNodeProjects() found %d projects, want 2: %+v", len(node), node)
+	}
+
+	python, err := FindPythonProjects(tmpDir)
+	if err != nil {
+		t.Fatalf("FindPythonProjects() error = %v", err)
+	}
+	if len(python) != 1 {
+		t.Errorf("FindPythonProjects() found %d projects, want 1: %+v", len(python), python)
+	}
+
+	// Disabling ignore handling finds everything
+	SetRespectIgnoreFiles(false)
+	defer SetRespectIgnoreFiles(true)
+
+	node, _ = FindNodeProjects(tmpDir)
+	if len(node) != 4 {
+		t.Errorf("FindNodeProjects() with ignore disabled found %d projects, want 4", len(node))
+	}
+	python, _ = FindPythonProjects(tmpDir)
+	if len(python) != 2 {
+		t.Errorf("FindPythonProjects() with ignore disabled found %d projects, want 2", len(python))
+	}
+}
@@ -0,0 +1,249 @@
+package detector
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/jongio/azd-app/cli/src/internal/types"
+)
+
+// Infrastructure providers, named as in the infra.provider setting of azure.yaml.
+const (
+	InfraProviderBicep     = "bicep"
+	InfraProviderTerraform = "terraform"
+)
+
+// DefaultInfraDir and DefaultInfraModule are where azd looks for infrastructure when azure.yaml
+// does not configure infra.path and infra.module.
+const (
+	DefaultInfraDir    = "infra"
+	DefaultInfraModule = "main"
+)
+
+var (
+	bicepParamPattern     = regexp.MustCompile(`^param\s+([A-Za-z_]\w*)\s+([^=]+?)\s*(=.*)?$`)
+	bicepOutputPattern    = regexp.MustCompile(`^output\s+([A-Za-z_]\w*)\s`)
+	terraformBlockPattern = regexp.MustCompile(`^(variable|output)\s+"([^"]+)"`)
+	terraformAttrPattern  = regexp.MustCompile(`^([a-z_]+)\s*=\s*(.*)$`)
+)
+
+// FindInfra detects the infrastructure in the default infra/ directory of rootDir.
+// Returns nil if there is none.
+func FindInfra(rootDir string) (*types.InfraProject, error) {
+	return DetectInfra(filepath.Join(rootDir, DefaultInfraDir), DefaultInfraModule)
+}
+
+// DetectInfra detects the infrastructure provider of dir and parses the parameters and outputs
+// the infrastructure declares. dir uses Bicep when it contains <module>.bicep, and Terraform
+// when it contains *.tf files, which form the root module. Bicep takes precedence when both
+// are present. Returns nil if dir contains neither.
+func DetectInfra(dir, module string) (*types.InfraProject, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+
+	if fileExistsIn(dir, module+".bicep") {
+		return parseBicepInfra(dir, module, names)
+	}
+	for _, name := range names {
+		if strings.HasSuffix(name, ".tf") {
+			return parseTerraformInfra(dir, names)
+		}
+	}
+	return nil, nil
+}
+
+// parseBicepInfra reads the parameters and outputs of the Bicep module <module>.bicep in dir.
+func parseBicepInfra(dir, module string, names []string) (*types.InfraProject, error) {
+	infra := &types.InfraProject{
+		Provider: InfraProviderBicep,
+		Dir:      dir,
+		Module:   filepath.Join(dir, module+".bicep"),
+	}
+	for _, name := range names {
+		if strings.HasSuffix(name, ".bicepparam") || name == module+".parameters.json" {
+			infra.ParameterFiles = append(infra.ParameterFiles, filepath.Join(dir, name))
+		}
+	}
+
+	data, err := readProjectFile(infra.Module)
+	if err != nil {
+		return nil, err
+	}
+
+	secure := false
+	for _, line := range infraLines(string(data)) {
+		// Declarations and their decorators start at the beginning of a line
+		if line == "" || line[0] == ' ' || line[0] == '\t' {
+			continue
+		}
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "@") {
+			if strings.HasPrefix(line, "@secure()") {
+				secure = true
+			}
+			continue
+		}
+
+		if match := bicepParamPattern.FindStringSubmatch(line); match != nil {
+			paramType := strings.TrimSpace(match[2])
+			infra.Parameters = append(infra.Parameters, types.InfraParameter{
+				Name:     match[1],
+				Type:     strings.TrimSuffix(paramType, "?"),
+				Required: match[3] == "" && !strings.HasSuffix(paramType, "?"),
+				Secure:   secure,
+			})
+		} else if match := bicepOutputPattern.FindStringSubmatch(line); match != nil {
+			infra.Outputs = append(infra.Outputs, match[1])
+		}
+		secure = false
+	}
+	return infra, nil
+}
+
+// parseTerraformInfra reads the variables and outputs of the Terraform root module in dir.
+func parseTerraformInfra(dir string, names []string) (*types.InfraProject, error) {
+	infra := &types.InfraProject{
+		Provider: InfraProviderTerraform,
+		Dir:      dir,
+		Module:   dir,
+	}
+
+	for _, name := range names {
+		if strings.HasSuffix(name, ".tfvars") || strings.HasSuffix(name, ".tfvars.json") {
+			infra.ParameterFiles = append(infra.ParameterFiles, filepath.Join(dir, name))
+		}
+		if !strings.HasSuffix(name, ".tf") {
+			continue
+		}
+
+		data, err := readProjectFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+
+		depth := 0
+		var variable *types.InfraParameter
+		for _, line := range infraLines(string(data)) {
+			line = strings.TrimSpace(line)
+			if depth == 0 {
+				if match := terraformBlockPattern.FindStringSubmatch(line); match != nil {
+					if match[1] == "output" {
+						infra.Outputs = append(infra.Outputs, match[2])
+					} else {
+						infra.Parameters = append(infra.Parameters, types.InfraParameter{Name: match[2], Required: true})
+						variable = &infra.Parameters[len(infra.Parameters)-1]
+					}
+				}
+			} else if depth == 1 && variable != nil {
+				if match := terraformAttrPattern.FindStringSubmatch(line); match != nil {
+					switch match[1] {
+					case "type":
+						variable.Type = match[2]
+					case "default":
+						variable.Required = false
+					case "sensitive":
+						variable.Secure = match[2] == "true"
+					}
+				}
+			}
+
+			depth += braceDelta(line)
+			if depth <= 0 {
+				depth = 0
+				variable = nil
+			}
+		}
+	}
+	return infra, nil
+}
+
+// infraLines splits Bicep or Terraform source into lines without comments. Line comments start
+// with // or, in Terraform, #; block comments are enclosed in /* and */. Comment markers inside
+// quoted strings are kept, so URLs in default values survive.
+func infraLines(source string) []string {
+	var lines []string
+	var current strings.Builder
+	inBlock := false
+	var quote byte
+
+	for i := 0; i < len(source); i++ {
+		c := source[i]
+		switch {
+		case c == '\n':
+			lines = append(lines, strings.TrimRight(current.String(), " \t\r"))
+			current.Reset()
+			quote = 0
+		case inBlock:
+			if c == '*' && i+1 < len(source) && source[i+1] == '/' {
+				inBlock = false
+				i++
+			}
+		case quote != 0:
+			current.WriteByte(c)
+			if c == '\\' && i+1 < len(source) {
+				i++
+				current.WriteByte(source[i])
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+			current.WriteByte(c)
+		case c == '/' && i+1 < len(source) && source[i+1] == '*':
+			inBlock = true
+			i++
+		case c == '#' || (c == '/' && i+1 < len(source) && source[i+1] == '/'):
+			// Skip to the end of the line
+			for i+1 < len(source) && source[i+1] != '\n' {
+				i++
+			}
+		default:
+			current.WriteByte(c)
+		}
+	}
+	if current.Len() > 0 {
+		lines = append(lines, strings.TrimRight(current.String(), " \t\r"))
+	}
+	return lines
+}
+
+// braceDelta returns the number of opening minus closing braces in a line, ignoring quoted text.
+func braceDelta(line string) int {
+	delta := 0
+	var quote rune
+	for _, c := range line {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '{':
+			delta++
+		case c == '}':
+			delta--
+		}
+	}
+	return delta
+}
@@ -0,0 +1,176 @@
+package detector
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/jongio/azd-app/cli/src/internal/types"
+)
+
+func TestFindInfraBicep(t *testing.T) {
+	dir := t.TempDir()
+	writeFileContent(t, filepath.Join(dir, "infra", "main.bicep"), `targetScope = 'subscription'
+
+// Name of the environment
+@minLength(1)
+@description('Name of the environment, e.g. dev')
+param environmentName string
+
+param location string = 'eastus' // default region
+param tags object = {
+  'azd-env-name': environmentName
+}
+@secure()
+param dbPassword string
+param sku 'Basic' | 'Standard' = 'Basic'
+param principalId string?
+/* param commented string */
+param url string = 'https://example.com/#fragment'
+
+module app 'app.bicep' = {
+  name: 'app'
+  params: {
+    location: location
+  }
+}
+
+output AZURE_LOCATION string = location
+output SERVICE_API_URI string = app.outputs.uri
+`)
+	writeFileContent(t, filepath.Join(dir, "infra", "app.bicep"), "param uri string\noutput uri string = uri\n")
+	writeFileContent(t, filepath.Join(dir, "infra", "main.parameters.json"), "{}")
+	writeFileContent(t, filepath.Join(dir, "infra", "main.bicepparam"), "using './main.bicep'\n")
+	writeFileContent(t, filepath.Join(dir, "infra", "main.tf"), `variable "ignored" {}`)
+
+	infra, err := FindInfra(dir)
+	if err != nil {
+		t.Fatalf("FindInfra failed: %v", err)
+	}
+	if infra == nil || infra.Provider != InfraProviderBicep {
+		t.Fatalf("expected bicep infrastructure, got %+v", infra)
+	}
+	if infra.Module != filepath.Join(dir, "infra", "main.bicep") {
+		t.Errorf("Module = %q", infra.Module)
+	}
+
+	wantFiles := []string{filepath.Join(dir, "infra", "main.bicepparam"), filepath.Join(dir, "infra", "main.parameters.json")}
+	if !reflect.DeepEqual(infra.ParameterFiles, wantFiles) {
+		t.Errorf("ParameterFiles = %v, want %v", infra.ParameterFiles, wantFiles)
+	}
+
+	wantParams := []types.InfraParameter{
+		{Name: "environmentName", Type: "string", Required: true},
+		{Name: "location", Type: "string"},
+		{Name: "tags", Type: "object"},
+		{Name: "dbPassword", Type: "string", Required: true, Secure: true},
+		{Name: "sku", Type: "'Basic' | 'Standard'"},
+		{Name: "principalId", Type: "string"},
+		{Name: "url", Type: "string"},
+	}
+	if !reflect.DeepEqual(infra.Parameters, wantParams) {
+		t.Errorf("Parameters =\n%+v\nwant\n%+v", infra.Parameters, wantParams)
+	}
+	if want := []string{"AZURE_LOCATION", "SERVICE_API_URI"}; !reflect.DeepEqual(infra.Outputs, want) {
+		t.Errorf("Outputs = %v, want %v", infra.Outputs, want)
+	}
+}
+
+func TestFindInfraTerraform(t *testing.T) {
+	dir := t.TempDir()
+	writeFileContent(t, filepath.Join(dir, "infra", "variables.tf"), `# Inputs
+variable "location" {
+  description = "Azure region"
+  type        = string
+  default     = "eastus"
+}
+
+variable "environment_name" {
+  type = string
+  validation {
+    condition     = length(var.environment_name) > 0
+    error_message = "Required { value"
+  }
+}
+
+variable "admin_password" {
+  type      = string
+  sensitive = true
+}
+
+variable "tags" {
+  type = map(string)
+  default = {
+    owner = "team"
+  }
+}
+
+variable "untyped" {}
+`)
+	writeFileContent(t, filepath.Join(dir, "infra", "outputs.tf"), `output "AZURE_LOCATION" {
+  value = var.location
+}
+/*
+output "disabled" {
+  value = 1
+}
+*/
+output "API_URL" { value = azurerm_container_app.api.latest_revision_fqdn }
+`)
+	writeFileContent(t, filepath.Join(dir, "infra", "main.tfvars.json"), "{}")
+	writeFileContent(t, filepath.Join(dir, "infra", "modules", "app", "main.tf"), `variable "child" {}`)
+
+	infra, err := FindInfra(dir)
+	if err != nil {
+		t.Fatalf("FindInfra failed: %v", err)
+	}
+	if infra == nil || infra.Provider != InfraProviderTerraform {
+		t.Fatalf("expected terraform infrastructure, got %+v", infra)
+	}
+	if infra.Module != filepath.Join(dir, "infra") {
+		t.Errorf("Module = %q", infra.Module)
+	}
+	if want := []string{filepath.Join(dir, "infra", "main.tfvars.json")}; !reflect.DeepEqual(infra.ParameterFiles, want) {
+		t.Errorf("ParameterFiles = %v, want %v", infra.ParameterFiles, want)
+	}
+
+	wantParams := []types.InfraParameter{
+		{Name: "location", Type: "string"},
+		{Name: "environment_name", Type: "string", Required: true},
+		{Name: "admin_password", Type: "string", Required: true, Secure: true},
+		{Name: "tags", Type: "map(string)"},
+		{Name: "untyped", Required: true},
+	}
+	if !reflect.DeepEqual(infra.Parameters, wantParams) {
+		t.Errorf("Parameters =\n%+v\nwant\n%+v", infra.Parameters, wantParams)
+	}
+	if want := []string{"AZURE_LOCATION", "API_URL"}; !reflect.DeepEqual(infra.Outputs, want) {
+		t.Errorf("Outputs = %v, want %v", infra.Outputs, want)
+	}
+}
+
+func TestFindInfraNone(t *testing.T) {
+	dir := t.TempDir()
+	if infra, err := FindInfra(dir); err != nil || infra != nil {
+		t.Errorf("FindInfra() = %+v, %v; want nil without infra directory", infra, err)
+	}
+
+	writeFileContent(t, filepath.Join(dir, "infra", "README.md"), "# Infra")
+	if infra, err := FindInfra(dir); err != nil || infra != nil {
+		t.Errorf("FindInfra() = %+v, %v; want nil for infra directory without modules", infra, err)
+	}
+}
+
+func TestDetectInfraCustomModule(t *testing.T) {
+	dir := t.TempDir()
+	writeFileContent(t, filepath.Join(dir, "deploy", "resources.bicep"), "param name string\n")
+	writeFileContent(t, filepath.Join(dir, "deploy", "resources.parameters.json"), "{}")
+
+	infra, err := DetectInfra(filepath.Join(dir, "deploy"), "resources")
+	if err != nil {
+		t.Fatalf("DetectInfra failed: %v", err)
+	}
+	if infra == nil || len(infra.Parameters) != 1 || len(infra.ParameterFiles) != 1 {
+		t.Errorf("DetectInfra() = %+v, want resources.bicep with its parameters file", infra)
+	}
+}
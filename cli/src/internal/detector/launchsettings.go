@@ -0,0 +1,115 @@
+package detector
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jongio/azd-app/cli/src/internal/security"
+	"github.com/jongio/azd-app/cli/src/internal/types"
+)
+
+// launchProfileCommand is the commandName of the launch profiles dotnet run can use.
+const launchProfileCommand = "Project"
+
+// launchProfileJSON is a profile of launchSettings.json.
+type launchProfileJSON struct {
+	CommandName          string            `json:"commandName"`
+	ApplicationURL       string            `json:"applicationUrl"`
+	EnvironmentVariables map[string]string `json:"environmentVariables"`
+}
+
+// ParseLaunchSettings returns the launch profiles of Properties/launchSettings.json in the
+// project directory projectDir, in the order of the file, or nil when it has none.
+func ParseLaunchSettings(projectDir string) ([]types.LaunchProfile, error) {
+	path := filepath.Join(projectDir, "Properties", "launchSettings.json")
+	if err := security.ValidatePath(path); err != nil {
+		return nil, err
+	}
+	// #nosec G304 -- Path validated by security.ValidatePath
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read launch settings: %w", err)
+	}
+	profiles, err := parseLaunchProfiles(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", path, err)
+	}
+	return profiles, nil
+}
+
+// parseLaunchProfiles returns the profiles of launch settings in the order of the file.
+func parseLaunchProfiles(data []byte) ([]types.LaunchProfile, error) {
+	// launchSettings.json can start with a byte order mark, which encoding/json rejects
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	var doc struct {
+		Profiles json.RawMessage `json:"profiles"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Profiles) == 0 || string(doc.Profiles) == "null" {
+		return nil, nil
+	}
+
+	// Profiles are read one by one, because a map loses their order
+	dec := json.NewDecoder(bytes.NewReader(doc.Profiles))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return nil, fmt.Errorf("profiles is not an object")
+	}
+	var profiles []types.LaunchProfile
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		var p launchProfileJSON
+		if err := dec.Decode(&p); err != nil {
+			return nil, err
+		}
+		profile := types.LaunchProfile{Name: fmt.Sprint(tok), CommandName: p.CommandName, Environment: p.EnvironmentVariables}
+		for _, u := range strings.Split(p.ApplicationURL, ";") {
+			if u = strings.TrimSpace(u); u != "" {
+				profile.ApplicationURLs = append(profile.ApplicationURLs, u)
+			}
+		}
+		profiles = append(profiles, profile)
+	}
+	return profiles, nil
+}
+
+// DefaultLaunchProfile returns the profile dotnet run uses without --launch-profile: the first
+// one that runs the project. It returns nil when there is none.
+func DefaultLaunchProfile(profiles []types.LaunchProfile) *types.LaunchProfile {
+	for i := range profiles {
+		if profiles[i].CommandName == launchProfileCommand {
+			return &profiles[i]
+		}
+	}
+	return nil
+}
+
+// FindLaunchProfile returns the profile of profiles named name, which dotnet run can use, or
+// an error listing the ones it can.
+func FindLaunchProfile(profiles []types.LaunchProfile, name string) (*types.LaunchProfile, error) {
+	var names []string
+	for i := range profiles {
+		if profiles[i].CommandName != launchProfileCommand {
+			continue
+		}
+		if profiles[i].Name == name {
+			return &profiles[i], nil
+		}
+		names = append(names, profiles[i].Name)
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no launch profile %q: the project has no launch profiles", name)
+	}
+	return nil, fmt.Errorf("no launch profile %q (profiles: %s)", name, strings.Join(names, ", "))
+}
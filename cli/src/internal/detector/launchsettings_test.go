@@ -0,0 +1,67 @@
+package detector
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testLaunchSettings = "\xef\xbb\xbf" + `{
+  "profiles": {
+    "IIS Express": {"commandName": "IISExpress", "applicationUrl": "http://localhost:8080"},
+    "https": {
+      "commandName": "Project",
+      "applicationUrl": "https://localhost:7001;http://localhost:5001",
+      "environmentVariables": {"ASPNETCORE_ENVIRONMENT": "Development"}
+    },
+    "http": {"commandName": "Project", "applicationUrl": "http://localhost:5002"},
+    "worker": {"commandName": "Project"}
+  }
+}`
+
+func TestParseLaunchSettings(t *testing.T) {
+	dir := t.TempDir()
+	if profiles, err := ParseLaunchSettings(dir); err != nil || profiles != nil {
+		t.Errorf("ParseLaunchSettings() without launch settings = %+v, %v", profiles, err)
+	}
+
+	writeFileContent(t, filepath.Join(dir, "Properties", "launchSettings.json"), testLaunchSettings)
+	profiles, err := ParseLaunchSettings(dir)
+	if err != nil {
+		t.Fatalf("ParseLaunchSettings() error = %v", err)
+	}
+	var names []string
+	for _, p := range profiles {
+		names = append(names, p.Name)
+	}
+	if got := strings.Join(names, ","); got != "IIS Express,https,http,worker" {
+		t.Fatalf("profiles = %s, want them in the order of the file", got)
+	}
+
+	https := profiles[1]
+	if len(https.ApplicationURLs) != 2 || https.Environment["ASPNETCORE_ENVIRONMENT"] != "Development" {
+		t.Errorf("https = %+v", https)
+	}
+	if port := https.Port(); port != 5001 {
+		t.Errorf("https.Port() = %d, want the port of its http URL", port)
+	}
+	if port := profiles[3].Port(); port != 0 {
+		t.Errorf("worker.Port() = %d, want 0 without URLs", port)
+	}
+
+	if p := DefaultLaunchProfile(profiles); p == nil || p.Name != "https" {
+		t.Errorf("DefaultLaunchProfile() = %+v, want https", p)
+	}
+	if p, err := FindLaunchProfile(profiles, "http"); err != nil || p.Port() != 5002 {
+		t.Errorf("FindLaunchProfile(http) = %+v, %v", p, err)
+	}
+	_, err = FindLaunchProfile(profiles, "IIS Express")
+	if err == nil || !strings.Contains(err.Error(), "https, http, worker") {
+		t.Errorf("FindLaunchProfile(IIS Express) error = %v, want the profiles dotnet run can use", err)
+	}
+
+	writeFileContent(t, filepath.Join(dir, "Properties", "launchSettings.json"), `{"profiles": [`)
+	if _, err := ParseLaunchSettings(dir); err == nil {
+		t.Error("ParseLaunchSettings() succeeded with invalid JSON")
+	}
+}
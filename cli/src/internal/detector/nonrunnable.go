@@ -0,0 +1,136 @@
+package detector
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/jongio/azd-app/cli/src/internal/security"
+)
+
+// toolDirs are folders of repository tooling, such as build and release scripts; projects in
+// them are never services.
+var toolDirs = map[string]bool{
+	"scripts": true,
+	"eng":     true,
+	"tools":   true,
+}
+
+// testDirs are folders of tests; projects in them, such as the fixtures of a test suite, are
+// never services.
+var testDirs = map[string]bool{
+	"test":      true,
+	"tests":     true,
+	"__tests__": true,
+	"e2e":       true,
+	"spec":      true,
+	"specs":     true,
+}
+
+// dotnetTestNamePattern matches the names of .NET test projects, e.g. Api.Tests, Api.UnitTests,
+// or Api.Specs.
+var dotnetTestNamePattern = regexp.MustCompile(`(?i)[._-](?:unit|integration|functional|acceptance|e2e|end2end)?(?:tests?|specs?)$`)
+
+// dotnetTestPackages are the NuGet packages of test frameworks, lowercase.
+var dotnetTestPackages = map[string]bool{
+	"xunit":                true,
+	"xunit.v3":             true,
+	"nunit":                true,
+	"mstest":               true,
+	"mstest.testframework": true,
+	"tunit":                true,
+}
+
+// nodeTestScriptPattern matches the package.json scripts of a package that only holds tests:
+// tests, and the linting and formatting that come with them.
+var nodeTestScriptPattern = regexp.MustCompile(`^(?:pre|post)?(test|e2e|cypress|playwright|lint|format|typecheck|coverage)(?:[:._-].*)?$`)
+
+// pythonTestFilePattern matches the files pytest collects, and its configuration.
+var pythonTestFilePattern = regexp.MustCompile(`^(?:test_.*|.*_test|conftest|__init__)\.py$`)
+
+// InToolOrTestDir reports whether dir, below rootDir, is in a folder of repository tooling,
+// such as scripts/ or eng/, or of tests, such as tests/ or __tests__/. Projects there are
+// tools, tests, or test fixtures rather than services.
+func InToolOrTestDir(rootDir, dir string) bool {
+	rel, err := filepath.Rel(rootDir, dir)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return false
+	}
+	for _, segment := range strings.Split(filepath.ToSlash(rel), "/") {
+		segment = strings.ToLower(segment)
+		if toolDirs[segment] || testDirs[segment] {
+			return true
+		}
+	}
+	return false
+}
+
+// isDotnetTestProject reports whether the project file at path, which references packages,
+// is named like a test project or references a test framework.
+func isDotnetTestProject(path string, packages []string) bool {
+	if dotnetTestNamePattern.MatchString(strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))) {
+		return true
+	}
+	for _, pkg := range packages {
+		if dotnetTestPackages[strings.ToLower(pkg)] {
+			return true
+		}
+	}
+	return false
+}
+
+// IsNodeTestProject reports whether the package in dir only holds tests: it has no main module,
+// and its scripts, at least one of which runs tests, are all test, lint, or format scripts.
+func IsNodeTestProject(dir string) bool {
+	path := filepath.Join(dir, "package.json")
+	if err := security.ValidatePath(path); err != nil {
+		return false
+	}
+	// #nosec G304 -- Path validated by security.ValidatePath
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	var pkg struct {
+		Main    string            `json:"main"`
+		Scripts map[string]string `json:"scripts"`
+	}
+	if json.Unmarshal(data, &pkg) != nil || pkg.Main != "" || len(pkg.Scripts) == 0 {
+		return false
+	}
+	tests := false
+	for name := range pkg.Scripts {
+		m := nodeTestScriptPattern.FindStringSubmatch(name)
+		if m == nil {
+			return false
+		}
+		switch m[1] {
+		case "test", "e2e", "cypress", "playwright":
+			tests = true
+		}
+	}
+	return tests
+}
+
+// IsPythonTestProject reports whether dir only holds tests that pytest runs: its Python files
+// are all test modules, conftest.py, or __init__.py, and there is at least one test module.
+func IsPythonTestProject(dir string) bool {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return false
+	}
+	tests := false
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || filepath.Ext(name) != ".py" {
+			continue
+		}
+		if !pythonTestFilePattern.MatchString(name) {
+			return false
+		}
+		tests = tests || (name != "conftest.py" && name != "__init__.py")
+	}
+	return tests
+}
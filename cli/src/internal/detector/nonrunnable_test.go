@@ -0,0 +1,69 @@
+package detector
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestInToolOrTestDir(t *testing.T) {
+	root := filepath.Join(string(filepath.Separator), "repo")
+	tests := map[string]bool{
+		".":                    false,
+		"api":                  false,
+		"scripts":              true,
+		"eng/release":          true,
+		"Tools/codegen":        true,
+		"tests/fixtures/web":   true,
+		"web/__tests__/app":    true,
+		"services/testing-api": false,
+		"../scripts":           false,
+	}
+	for rel, want := range tests {
+		if got := InToolOrTestDir(root, filepath.Join(root, filepath.FromSlash(rel))); got != want {
+			t.Errorf("InToolOrTestDir(%s) = %v, want %v", rel, got, want)
+		}
+	}
+}
+
+func TestIsNodeTestProject(t *testing.T) {
+	tests := []struct {
+		name, packageJSON string
+		want              bool
+	}{
+		{"only tests", `{"scripts": {"test": "playwright test", "test:ui": "playwright test --ui", "lint": "eslint ."}}`, true},
+		{"e2e", `{"scripts": {"e2e": "cypress run"}}`, true},
+		{"app with tests", `{"scripts": {"start": "node server.js", "test": "jest"}}`, false},
+		{"main module", `{"main": "index.js", "scripts": {"test": "jest"}}`, false},
+		{"lint only", `{"scripts": {"lint": "eslint ."}}`, false},
+		{"no scripts", `{}`, false},
+	}
+	for _, tt := range tests {
+		dir := t.TempDir()
+		writeFileContent(t, filepath.Join(dir, "package.json"), tt.packageJSON)
+		if got := IsNodeTestProject(dir); got != tt.want {
+			t.Errorf("%s: IsNodeTestProject() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestIsPythonTestProject(t *testing.T) {
+	tests := []struct {
+		name  string
+		files []string
+		want  bool
+	}{
+		{"pytest only", []string{"requirements.txt", "conftest.py", "test_api.py", "orders_test.py", "__init__.py"}, true},
+		{"app with tests", []string{"requirements.txt", "main.py", "test_main.py"}, false},
+		{"conftest only", []string{"requirements.txt", "conftest.py"}, false},
+		{"no Python files", []string{"pyproject.toml"}, false},
+	}
+	for _, tt := range tests {
+		dir := t.TempDir()
+		for _, name := range tt.files {
+			writeTestFile(t, filepath.Join(dir, name))
+		}
+		if got := IsPythonTestProject(dir); got != tt.want {
+			t.Errorf("%s: IsPythonTestProject() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package detector
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+
+	"github.com/jongio/azd-app/cli/src/internal/types"
+)
+
+// Files that pin the versions of tools, besides the project files themselves.
+const (
+	nvmrcFile         = ".nvmrc"
+	nodeVersionFile   = ".node-version"
+	pythonVersionFile = ".python-version"
+	globalJSONFile    = "global.json"
+	toolVersionsFile  = ".tool-versions" // asdf and mise
+)
+
+// toolVersionsNames maps the plugins of .tool-versions to the tools they install.
+var toolVersionsNames = map[string]string{
+	"nodejs":      "node",
+	"node":        "node",
+	"pnpm":        "pnpm",
+	"yarn":        "yarn",
+	"python":      "python",
+	"dotnet":      "dotnet",
+	"dotnet-core": "dotnet",
+}
+
+// nodePinnedTools are the engines of package.json that pin a tool.
+var nodePinnedTools = []string{"node", "npm", "pnpm", "yarn"}
+
+// NodeVersionPins returns the versions of node and its package managers the Node.js project in
+// dir pins: the engines of its package.json, then the nearest .nvmrc or .node-version, then
+// the nearest .tool-versions. Version files are searched from dir up to boundaryDir, as nvm and
+// asdf do, but never above it; with an empty boundaryDir only dir is searched.
+func NodeVersionPins(dir, boundaryDir string) []types.VersionPin {
+	var pins []types.VersionPin
+	path := filepath.Join(dir, "package.json")
+	var pkg struct {
+		Engines map[string]string `json:"engines"`
+	}
+	if data, err := readProjectFile(path); err == nil && json.Unmarshal(data, &pkg) == nil {
+		for _, tool := range nodePinnedTools {
+			if constraint := strings.TrimSpace(pkg.Engines[tool]); constraint != "" {
+				pins = append(pins, types.VersionPin{Tool: tool, Constraint: constraint, Source: path})
+			}
+		}
+	}
+
+	if path := findUp(dir, boundaryDir, nvmrcFile, nodeVersionFile); path != "" {
+		if version := firstLine(path); version != "" {
+			pins = append(pins, types.VersionPin{Tool: "node", Constraint: version, Source: path})
+		}
+	}
+	return append(pins, toolVersionsPins(dir, boundaryDir, "node", "pnpm", "yarn")...)
+}
+
+// PythonVersionPins returns the versions of Python the Python project in dir pins: the
+// requires-python of pyproject.toml, or its Poetry or Pipfile equivalent, then the nearest
+// .python-version, then the nearest .tool-versions, searched as NodeVersionPins does.
+func PythonVersionPins(dir, boundaryDir string) []types.VersionPin {
+	var pins []types.VersionPin
+	path := filepath.Join(dir, "pyproject.toml")
+	if tables := readTomlFile(path); tables != nil {
+		if constraint := firstNonEmpty(tables["project"]["requires-python"], tables["tool.poetry.dependencies"]["python"]); constraint != "" {
+			pins = append(pins, types.VersionPin{Tool: "python", Constraint: constraint, Source: path})
+		}
+	}
+	if len(pins) == 0 {
+		path := filepath.Join(dir, "Pipfile")
+		if tables := readTomlFile(path); tables != nil {
+			if constraint := firstNonEmpty(tables["requires"]["python_full_version"], tables["requires"]["python_version"]); constraint != "" {
+				pins = append(pins, types.VersionPin{Tool: "python", Constraint: constraint, Source: path})
+			}
+		}
+	}
+
+	if path := findUp(dir, boundaryDir, pythonVersionFile); path != "" {
+		if version := firstLine(path); version != "" {
+			pins = append(pins, types.VersionPin{Tool: "python", Constraint: version, Source: path})
+		}
+	}
+	return append(pins, toolVersionsPins(dir, boundaryDir, "python")...)
+}
+
+// DotnetVersionPins returns the versions of the .NET SDK the .NET project in dir pins: the sdk
+// of the nearest global.json that has one, then the nearest .tool-versions, searched as
+// NodeVersionPins does.
+func DotnetVersionPins(dir, boundaryDir string) []types.VersionPin {
+	var pins []types.VersionPin
+	for _, path := range findAllUp(dir, boundaryDir, globalJSONFile) {
+		var doc struct {
+			SDK struct {
+				Version     string `json:"version"`
+				RollForward string `json:"rollForward"`
+			} `json:"sdk"`
+		}
+		data, err := readProjectFile(path)
+		if err != nil {
+			continue
+		}
+		// global.json can start with a byte order mark, which encoding/json rejects
+		if json.Unmarshal(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")), &doc) != nil || doc.SDK.Version == "" {
+			continue
+		}
+		// dotnet uses the nearest global.json with an SDK version
+		pins = append(pins, types.VersionPin{Tool: "dotnet", Constraint: doc.SDK.Version, RollForward: doc.SDK.RollForward, Source: path})
+		break
+	}
+	return append(pins, toolVersionsPins(dir, boundaryDir, "dotnet")...)
+}
+
+// toolVersionsPins returns the versions of tools the nearest .tool-versions pins. A line lists
+// a plugin and its versions, the first of which is used.
+func toolVersionsPins(dir, boundaryDir string, tools ...string) []types.VersionPin {
+	path := findUp(dir, boundaryDir, toolVersionsFile)
+	if path == "" {
+		return nil
+	}
+	data, err := readProjectFile(path)
+	if err != nil {
+		return nil
+	}
+	var pins []types.VersionPin
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		tool := toolVersionsNames[fields[0]]
+		for _, want := range tools {
+			if tool == want {
+				pins = append(pins, types.VersionPin{Tool: tool, Constraint: fields[1], Source: path})
+			}
+		}
+	}
+	return pins
+}
+
+// findUp returns the first file named one of names in dir or its parents up to boundaryDir,
+// or "" when there is none.
+func findUp(dir, boundaryDir string, names ...string) string {
+	for _, d := range dirsUp(dir, boundaryDir) {
+		for _, name := range names {
+			if fileExistsIn(d, name) {
+				return filepath.Join(d, name)
+			}
+		}
+	}
+	return ""
+}
+
+// findAllUp returns every file named name in dir and its parents up to boundaryDir, nearest
+// first.
+func findAllUp(dir, boundaryDir, name string) []string {
+	var paths []string
+	for _, d := range dirsUp(dir, boundaryDir) {
+		if fileExistsIn(d, name) {
+			paths = append(paths, filepath.Join(d, name))
+		}
+	}
+	return paths
+}
+
+// dirsUp returns dir and its parents up to boundaryDir, or only dir when boundaryDir is empty
+// or doesn't contain it.
+func dirsUp(dir, boundaryDir string) []string {
+	dir = filepath.Clean(dir)
+	dirs := []string{dir}
+	if boundaryDir == "" {
+		return dirs
+	}
+	boundaryDir = filepath.Clean(boundaryDir)
+	if rel, err := filepath.Rel(boundaryDir, dir); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return dirs
+	}
+	for dir != boundaryDir {
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+		dirs = append(dirs, dir)
+	}
+	return dirs
+}
+
+// firstLine returns the first line of the file at path, trimmed, or "" when it can't be read.
+func firstLine(path string) string {
+	data, err := readProjectFile(path)
+	if err != nil {
+		return ""
+	}
+	line, _, _ := strings.Cut(string(data), "\n")
+	return strings.TrimSpace(line)
+}
@@ -0,0 +1,67 @@
+package detector
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/jongio/azd-app/cli/src/internal/types"
+)
+
+func TestNodeVersionPins(t *testing.T) {
+	root := t.TempDir()
+	web := filepath.Join(root, "apps", "web")
+	writeFileContent(t, filepath.Join(web, "package.json"), `{"name": "web", "engines": {"node": ">=20", "pnpm": "^9.1.0", "vscode": "^1.90.0"}}`)
+	writeFileContent(t, filepath.Join(root, ".nvmrc"), "20.11.1\n")
+	writeFileContent(t, filepath.Join(root, ".tool-versions"), "nodejs 20.11.1 18.19.0 # fallback\npython 3.12.4\npnpm 9.4.0\n")
+	// Above the boundary, so never read
+	writeFileContent(t, filepath.Join(filepath.Dir(root), ".node-version"), "16\n")
+
+	want := []types.VersionPin{
+		{Tool: "node", Constraint: ">=20", Source: filepath.Join(web, "package.json")},
+		{Tool: "pnpm", Constraint: "^9.1.0", Source: filepath.Join(web, "package.json")},
+		{Tool: "node", Constraint: "20.11.1", Source: filepath.Join(root, ".nvmrc")},
+		{Tool: "node", Constraint: "20.11.1", Source: filepath.Join(root, ".tool-versions")},
+		{Tool: "pnpm", Constraint: "9.4.0", Source: filepath.Join(root, ".tool-versions")},
+	}
+	if got := NodeVersionPins(web, root); !reflect.DeepEqual(got, want) {
+		t.Errorf("NodeVersionPins() = %+v, want %+v", got, want)
+	}
+
+	// Without a boundary only the project directory is searched
+	if got := NodeVersionPins(web, ""); len(got) != 2 {
+		t.Errorf("NodeVersionPins() without a boundary = %+v, want the engines only", got)
+	}
+}
+
+func TestPythonVersionPins(t *testing.T) {
+	root := t.TempDir()
+	writeFileContent(t, filepath.Join(root, "api", "pyproject.toml"), "[tool.poetry.dependencies]\npython = \"^3.11\"\n")
+	writeFileContent(t, filepath.Join(root, "api", ".python-version"), "3.12.4\n")
+	writeFileContent(t, filepath.Join(root, "worker", "Pipfile"), "[requires]\npython_version = \"3.11\"\n")
+
+	api := PythonVersionPins(filepath.Join(root, "api"), root)
+	if len(api) != 2 || api[0].Constraint != "^3.11" || api[1].Constraint != "3.12.4" {
+		t.Errorf("PythonVersionPins(api) = %+v, want Poetry then .python-version", api)
+	}
+	worker := PythonVersionPins(filepath.Join(root, "worker"), root)
+	if len(worker) != 1 || worker[0].Constraint != "3.11" {
+		t.Errorf("PythonVersionPins(worker) = %+v, want the Pipfile", worker)
+	}
+}
+
+func TestDotnetVersionPins(t *testing.T) {
+	root := t.TempDir()
+	api := filepath.Join(root, "src", "Api")
+	// A global.json without an SDK version is skipped for the next one up
+	writeFileContent(t, filepath.Join(root, "src", "global.json"), `{"msbuild-sdks": {"Microsoft.Build.NoTargets": "3.7.0"}}`)
+	writeFileContent(t, filepath.Join(root, "global.json"), "\xef\xbb\xbf"+`{"sdk": {"version": "8.0.100", "rollForward": "latestFeature"}}`)
+
+	want := []types.VersionPin{{Tool: "dotnet", Constraint: "8.0.100", RollForward: "latestFeature", Source: filepath.Join(root, "global.json")}}
+	if got := DotnetVersionPins(api, root); !reflect.DeepEqual(got, want) {
+		t.Errorf("DotnetVersionPins() = %+v, want %+v", got, want)
+	}
+	if got := DotnetVersionPins(api, api); len(got) != 0 {
+		t.Errorf("DotnetVersionPins() = %+v, want none inside the boundary", got)
+	}
+}
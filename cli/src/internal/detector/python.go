@@ -0,0 +1,270 @@
+package detector
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/jongio/azd-app/cli/src/internal/security"
+)
+
+// pythonProjectFiles are the file names that mark a directory as a Python project.
+var pythonProjectFiles = map[string]bool{
+	"requirements.txt": true,
+	"pyproject.toml":   true,
+	"poetry.lock":      true,
+	"uv.lock":          true,
+	"Pipfile":          true,
+	"Pipfile.lock":     true,
+	"setup.py":         true,
+}
+
+// setupKeywordPattern matches simple string keyword arguments in setup.py, e.g. name="app".
+var setupKeywordPattern = regexp.MustCompile(`\b(name|python_requires)\s*=\s*["']([^"']+)["']`)
+
+// IsPythonProjectFile reports whether a file name marks a directory as a Python project.
+func IsPythonProjectFile(name string) bool {
+	return pythonProjectFiles[name]
+}
+
+// PythonProjectMetadata returns the project name and Python version constraint of a
+// Python project, read from pyproject.toml (PEP 621 or Poetry), Pipfile, setup.py,
+// or .python-version, in that order. Missing values are returned as empty strings.
+func PythonProjectMetadata(projectDir string) (name string, pythonVersion string) {
+	if tables := readTomlFile(filepath.Join(projectDir, "pyproject.toml")); tables != nil {
+		name = firstNonEmpty(tables["project"]["name"], tables["tool.poetry"]["name"])
+		pythonVersion = firstNonEmpty(tables["project"]["requires-python"], tables["tool.poetry.dependencies"]["python"])
+	}
+
+	if pythonVersion == "" {
+		if tables := readTomlFile(filepath.Join(projectDir, "Pipfile")); tables != nil {
+			pythonVersion = firstNonEmpty(tables["requires"]["python_full_version"], tables["requires"]["python_version"])
+		}
+	}
+
+	if name == "" || pythonVersion == "" {
+		if data, err := readProjectFile(filepath.Join(projectDir, "setup.py")); err == nil {
+			for _, match := range setupKeywordPattern.FindAllStringSubmatch(string(data), -1) {
+				switch {
+				case match[1] == "name" && name == "":
+					name = match[2]
+				case match[1] == "python_requires" && pythonVersion == "":
+					pythonVersion = match[2]
+				}
+			}
+		}
+	}
+
+	if pythonVersion == "" {
+		if data, err := readProjectFile(filepath.Join(projectDir, ".python-version")); err == nil {
+			pythonVersion = strings.TrimSpace(strings.SplitN(string(data), "\n", 2)[0])
+		}
+	}
+
+	return name, pythonVersion
+}
+
+// readTomlFile reads the string values of a TOML file, keyed by table name and key.
+// Only single-line `key = "value"` pairs are supported, which covers the metadata fields
+// needed for detection without a full TOML parser. Returns nil if the file cannot be read.
+func readTomlFile(path string) map[string]map[string]string {
+	data, err := readProjectFile(path)
+	if err != nil {
+		return nil
+	}
+
+	tables := map[string]map[string]string{"": {}}
+	table := ""
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			table = strings.Trim(line, "[] ")
+			if tables[table] == nil {
+				tables[table] = make(map[string]string)
+			}
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		key = strings.Trim(strings.TrimSpace(key), `"'`)
+		value = strings.TrimSpace(value)
+		if len(value) < 2 || (value[0] != '"' && value[0] != '\'') {
+			continue
+		}
+		quote := value[0]
+		end := strings.IndexByte(value[1:], quote)
+		if end < 0 {
+			continue
+		}
+		tables[table][key] = value[1 : end+1]
+	}
+	return tables
+}
+
+// readProjectFile reads a file after validating its path.
+func readProjectFile(path string) ([]byte, error) {
+	if err := security.ValidatePath(path); err != nil {
+		return nil, err
+	}
+	// #nosec G304 -- Path validated by security.ValidatePath
+	return os.ReadFile(path)
+}
+
+// firstNonEmpty returns the first non-empty string.
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// pythonFrameworkImports maps top-level import names to frameworks, in detection priority order.
+var pythonFrameworkImports = []struct {
+	module    string
+	framework string
+}{
+	{"django", "Django"},
+	{"fastapi", "FastAPI"},
+	{"flask", "Flask"},
+	{"streamlit", "Streamlit"},
+	{"gradio", "Gradio"},
+}
+
+// pythonEntryCandidates are the files checked for imports and entrypoints, in order of preference.
+var pythonEntryCandidates = []string{
+	"main.py", "app.py", "streamlit_app.py", "server.py", "api.py",
+	"src/main.py", "src/app.py", "app/main.py", "app/app.py",
+}
+
+var (
+	pythonImportPattern = regexp.MustCompile(`(?m)^\s*(?:from|import)\s+([A-Za-z_][A-Za-z0-9_]*)`)
+	pythonAppPattern    = regexp.MustCompile(`(?m)^([A-Za-z_][A-Za-z0-9_]*)\s*(?::[^=]+)?=\s*(?:fastapi\.|flask\.)?(FastAPI|Flask)\(`)
+)
+
+// PythonEntrypoint describes how a Python web app is started.
+type PythonEntrypoint struct {
+	File   string // Path relative to the project, e.g. "main.py", "src/app.py", or "manage.py"
+	Module string // Import path of File or of the ASGI/WSGI module, e.g. "src.app" or "mysite.asgi"
+	App    string // Application object in Module, e.g. "app" or "application"; empty for scripts
+}
+
+// Target returns the "module:app" string used by uvicorn, gunicorn, and FLASK_APP.
+func (e *PythonEntrypoint) Target() string {
+	if e.App == "" {
+		return e.Module
+	}
+	return e.Module + ":" + e.App
+}
+
+// DetectPythonFramework identifies the web framework of a Python project from manage.py,
+// imports in common entry files, and declared dependencies.
+// Returns "Python" when no framework matches.
+func DetectPythonFramework(projectDir string) string {
+	if _, err := os.Stat(filepath.Join(projectDir, "manage.py")); err == nil {
+		return "Django"
+	}
+
+	imports := make(map[string]bool)
+	for _, candidate := range pythonEntryCandidates {
+		data, err := readProjectFile(filepath.Join(projectDir, filepath.FromSlash(candidate)))
+		if err != nil {
+			continue
+		}
+		for _, match := range pythonImportPattern.FindAllStringSubmatch(string(data), -1) {
+			imports[strings.ToLower(match[1])] = true
+		}
+	}
+	for _, rule := range pythonFrameworkImports {
+		if imports[rule.module] {
+			return rule.framework
+		}
+	}
+
+	deps := readPythonDependencyNames(projectDir)
+	for _, rule := range pythonFrameworkImports {
+		if deps[rule.module] {
+			return rule.framework
+		}
+	}
+	return "Python"
+}
+
+// DetectPythonEntrypoint finds the entry file and application object for a framework.
+// Django projects use manage.py, or their asgi.py/wsgi.py module when manage.py is missing.
+// FastAPI and Flask apps are located by their `app = FastAPI()` / `app = Flask(...)` assignment.
+// Returns nil when no entrypoint is found.
+func DetectPythonEntrypoint(projectDir string, framework string) *PythonEntrypoint {
+	if framework == "Django" {
+		if _, err := os.Stat(filepath.Join(projectDir, "manage.py")); err == nil {
+			return &PythonEntrypoint{File: "manage.py", Module: "manage"}
+		}
+		for _, name := range []string{"asgi.py", "wsgi.py"} {
+			matches, _ := filepath.Glob(filepath.Join(projectDir, "*", name))
+			if len(matches) > 0 {
+				rel, err := filepath.Rel(projectDir, matches[0])
+				if err == nil {
+					return &PythonEntrypoint{File: filepath.ToSlash(rel), Module: pythonModuleName(rel), App: "application"}
+				}
+			}
+		}
+		return nil
+	}
+
+	var firstExisting *PythonEntrypoint
+	for _, candidate := range pythonEntryCandidates {
+		data, err := readProjectFile(filepath.Join(projectDir, filepath.FromSlash(candidate)))
+		if err != nil {
+			continue
+		}
+		entry := &PythonEntrypoint{File: candidate, Module: pythonModuleName(candidate)}
+
+		if framework != "FastAPI" && framework != "Flask" {
+			return entry
+		}
+		if match := pythonAppPattern.FindStringSubmatch(string(data)); match != nil && match[2] == framework {
+			entry.App = match[1]
+			return entry
+		}
+		if firstExisting == nil {
+			entry.App = "app"
+			firstExisting = entry
+		}
+	}
+	return firstExisting
+}
+
+// pythonModuleName converts a relative .py path to its import path, e.g. "src/app.py" → "src.app".
+func pythonModuleName(relPath string) string {
+	return strings.ReplaceAll(strings.TrimSuffix(filepath.ToSlash(relPath), ".py"), "/", ".")
+}
+
+// readPythonDependencyNames returns the lowercase names of packages declared in
+// requirements.txt, pyproject.toml, or Pipfile. Names are matched loosely by line prefix.
+func readPythonDependencyNames(projectDir string) map[string]bool {
+	deps := make(map[string]bool)
+	namePattern := regexp.MustCompile(`^\s*["']?([A-Za-z0-9][A-Za-z0-9._-]*)`)
+	for _, name := range []string{"requirements.txt", "pyproject.toml", "Pipfile"} {
+		data, err := readProjectFile(filepath.Join(projectDir, name))
+		if err != nil {
+			continue
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			if match := namePattern.FindStringSubmatch(line); match != nil {
+				deps[strings.ToLower(match[1])] = true
+			}
+		}
+	}
+	return deps
+}
@@ -0,0 +1,215 @@
+package detector
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestPythonProjectMetadata(t *testing.T) {
+	tests := []struct {
+		name        string
+		files       map[string]string
+		wantName    string
+		wantVersion string
+	}{
+		{
+			name: "PEP 621 pyproject",
+			files: map[string]string{
+				"pyproject.toml": "[project]\nname = \"api\"\nrequires-python = \">=3.11\"\ndependencies = [\n  \"fastapi\",\n]\n",
+			},
+			wantName:    "api",
+			wantVersion: ">=3.11",
+		},
+		{
+			name: "Poetry pyproject",
+			files: map[string]string{
+				"pyproject.toml": "[tool.poetry]\nname = 'worker'\n\n[tool.poetry.dependencies]\npython = \"^3.10\"\ncelery = \"^5.3\"\n",
+			},
+			wantName:    "worker",
+			wantVersion: "^3.10",
+		},
+		{
+			name: "Pipfile",
+			files: map[string]string{
+				"Pipfile": "[packages]\nflask = \"*\"\n\n[requires]\npython_version = \"3.12\"\n",
+			},
+			wantVersion: "3.12",
+		},
+		{
+			name: "setup.py",
+			files: map[string]string{
+				"setup.py": "from setuptools import setup\nsetup(\n    name=\"legacy-app\",\n    python_requires='>=3.8',\n)\n",
+			},
+			wantName:    "legacy-app",
+			wantVersion: ">=3.8",
+		},
+		{
+			name: ".python-version fallback",
+			files: map[string]string{
+				"requirements.txt": "flask\n",
+				".python-version":  "3.12.1\n",
+			},
+			wantVersion: "3.12.1",
+		},
+		{
+			name: "requirements.txt only",
+			files: map[string]string{
+				"requirements.txt": "flask\n",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				writeFileContent(t, filepath.Join(dir, name), content)
+			}
+
+			name, version := PythonProjectMetadata(dir)
+			if name != tt.wantName {
+				t.Errorf("name = %q, want %q", name, tt.wantName)
+			}
+			if version != tt.wantVersion {
+				t.Errorf("pythonVersion = %q, want %q", version, tt.wantVersion)
+			}
+		})
+	}
+}
+
+func TestFindPythonProjectsAllIndicators(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	writeFileContent(t, filepath.Join(tmpDir, "pipenv-app", "Pipfile"), "[requires]\npython_version = \"3.11\"\n")
+	writeFileContent(t, filepath.Join(tmpDir, "setup-app", "setup.py"), "setup(name='setup-app')\n")
+	writeFileContent(t, filepath.Join(tmpDir, "uv-app", "pyproject.toml"), "[project]\nname = \"uv-app\"\n")
+	writeTestFile(t, filepath.Join(tmpDir, "uv-app", "uv.lock"))
+
+	projects, err := FindPythonProjects(tmpDir)
+	if err != nil {
+		t.Fatalf("FindPythonProjects failed: %v", err)
+	}
+
+	byDir := make(map[string]string)
+	names := make(map[string]string)
+	for _, p := range projects {
+		base := filepath.Base(p.Dir)
+		byDir[base] = p.PackageManager
+		names[base] = p.Name
+	}
+
+	want := map[string]string{"pipenv-app": "pipenv", "setup-app": "pip", "uv-app": "uv"}
+	for dir, manager := range want {
+		if byDir[dir] != manager {
+			t.Errorf("%s: PackageManager = %q, want %q", dir, byDir[dir], manager)
+		}
+	}
+	if names["uv-app"] != "uv-app" || names["setup-app"] != "setup-app" {
+		t.Errorf("unexpected project names: %v", names)
+	}
+}
+
+func TestDetectPythonFramework(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  string
+	}{
+		{"manage.py", map[string]string{"manage.py": "", "requirements.txt": "django"}, "Django"},
+		{"fastapi import", map[string]string{"main.py": "import uvicorn\nfrom fastapi import FastAPI\n"}, "FastAPI"},
+		{"flask import in src", map[string]string{"src/app.py": "from flask import Flask\n"}, "Flask"},
+		{"streamlit import", map[string]string{"streamlit_app.py": "import streamlit as st\n"}, "Streamlit"},
+		{"dependency only", map[string]string{"requirements.txt": "FastAPI==0.110.0\nuvicorn\n", "server.py": "import os\n"}, "FastAPI"},
+		{"pyproject dependency", map[string]string{"pyproject.toml": "[project]\ndependencies = [\n  \"flask>=3\",\n]\n"}, "Flask"},
+		{"plain script", map[string]string{"main.py": "print('hi')\n", "requirements.txt": "requests\n"}, "Python"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				writeFileContent(t, filepath.Join(dir, name), content)
+			}
+			if got := DetectPythonFramework(dir); got != tt.want {
+				t.Errorf("DetectPythonFramework() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectPythonEntrypoint(t *testing.T) {
+	tests := []struct {
+		name       string
+		framework  string
+		files      map[string]string
+		wantFile   string
+		wantTarget string
+	}{
+		{
+			name:       "FastAPI default app",
+			framework:  "FastAPI",
+			files:      map[string]string{"main.py": "from fastapi import FastAPI\napp = FastAPI()\n"},
+			wantFile:   "main.py",
+			wantTarget: "main:app",
+		},
+		{
+			name:       "FastAPI custom variable in app package",
+			framework:  "FastAPI",
+			files:      map[string]string{"app/main.py": "import fastapi\nserver: fastapi.FastAPI = fastapi.FastAPI(title='x')\n"},
+			wantFile:   "app/main.py",
+			wantTarget: "app.main:server",
+		},
+		{
+			name:      "Flask prefers file that creates the app",
+			framework: "Flask",
+			files: map[string]string{
+				"main.py": "import sys\n",
+				"app.py":  "from flask import Flask\napplication = Flask(__name__)\n",
+			},
+			wantFile:   "app.py",
+			wantTarget: "app:application",
+		},
+		{
+			name:       "Django manage.py",
+			framework:  "Django",
+			files:      map[string]string{"manage.py": "", "mysite/wsgi.py": ""},
+			wantFile:   "manage.py",
+			wantTarget: "manage",
+		},
+		{
+			name:       "Django wsgi module",
+			framework:  "Django",
+			files:      map[string]string{"mysite/wsgi.py": "application = get_wsgi_application()\n"},
+			wantFile:   "mysite/wsgi.py",
+			wantTarget: "mysite.wsgi:application",
+		},
+		{
+			name:       "Streamlit script",
+			framework:  "Streamlit",
+			files:      map[string]string{"streamlit_app.py": "import streamlit as st\n"},
+			wantFile:   "streamlit_app.py",
+			wantTarget: "streamlit_app",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				writeFileContent(t, filepath.Join(dir, filepath.FromSlash(name)), content)
+			}
+
+			ep := DetectPythonEntrypoint(dir, tt.framework)
+			if ep == nil {
+				t.Fatal("expected an entrypoint, got nil")
+			}
+			if ep.File != tt.wantFile || ep.Target() != tt.wantTarget {
+				t.Errorf("got file %q target %q, want %q %q", ep.File, ep.Target(), tt.wantFile, tt.wantTarget)
+			}
+		})
+	}
+
+	if ep := DetectPythonEntrypoint(t.TempDir(), "FastAPI"); ep != nil {
+		t.Errorf("expected nil entrypoint for empty project, got %+v", ep)
+	}
+}
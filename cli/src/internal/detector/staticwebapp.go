@@ -0,0 +1,347 @@
+package detector
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/jongio/azd-app/cli/src/internal/types"
+)
+
+// HostStaticWebApp is the azure.yaml host for Azure Static Web Apps.
+const HostStaticWebApp = "staticwebapp"
+
+// Files that mark a folder as a Static Web Apps project.
+const (
+	swaConfigFile    = "staticwebapp.config.json"
+	swaCLIConfigFile = "swa-cli.config.json"
+)
+
+// swaConfigDirs are folders of a frontend where staticwebapp.config.json is commonly kept
+// so that the build copies it into the output.
+var swaConfigDirs = []string{"public", "static", "src"}
+
+// serverDependencies mark a Node.js project as a server or Functions app rather than a static frontend.
+var serverDependencies = []string{"express", "fastify", "koa", "@nestjs/core", "@hapi/hapi", "restify", "@azure/functions"}
+
+var (
+	// viteOutDirPattern matches build.outDir in vite.config, e.g. `outDir: 'build'`.
+	viteOutDirPattern = regexp.MustCompile(`outDir\s*:\s*['"]([^'"]+)['"]`)
+	// vueOutputDirPattern matches outputDir in vue.config.js.
+	vueOutputDirPattern = regexp.MustCompile(`outputDir\s*:\s*['"]([^'"]+)['"]`)
+	// nextExportPattern matches `output: 'export'` in next.config, which builds a static site.
+	nextExportPattern = regexp.MustCompile(`output\s*:\s*['"]export['"]`)
+	// astroServerPattern matches Astro configs that render on a server.
+	astroServerPattern = regexp.MustCompile(`output\s*:\s*['"](?:server|hybrid)['"]`)
+)
+
+// FindStaticWebApps searches for frontends that can be hosted on Azure Static Web Apps.
+// Only searches within rootDir and does not traverse outside it.
+func FindStaticWebApps(rootDir string) ([]types.StaticWebApp, error) {
+	rootDir, err := filepath.Abs(rootDir)
+	if err != nil {
+		return nil, err
+	}
+
+	m := newStaticWebAppMatcher()
+	if err := walkWorkspace(rootDir, m); err != nil {
+		return nil, err
+	}
+	return m.result(), nil
+}
+
+// DetectStaticWebApp reports whether dir holds a Static Web Apps candidate. Apps are recognized,
+// in order, by a swa-cli.config.json, by a staticwebapp.config.json in dir or its public, static,
+// or src folder, or by a package.json of a frontend-only project whose framework builds to a
+// known output folder (Vite, Create React App, Vue CLI, Angular, Astro, Gatsby, SvelteKit with
+// adapter-static, or Next.js with `output: 'export'`) and that has a build script.
+// Returns nil when dir is not a candidate.
+func DetectStaticWebApp(dir string) *types.StaticWebApp {
+	app := &types.StaticWebApp{Dir: dir, AppLocation: ".", Host: HostStaticWebApp}
+
+	if detectFromSWACLIConfig(app) {
+		appDir := filepath.Join(dir, app.AppLocation)
+		if fileExistsIn(appDir, "package.json") {
+			app.Framework = DetectNodeFramework(appDir)
+			app.BuildCommand = firstNonEmpty(app.BuildCommand, nodeBuildCommand(appDir))
+		}
+	} else if source := findSWAConfig(dir); source != "" {
+		app.Source = source
+		if fileExistsIn(dir, "package.json") {
+			app.Framework = DetectNodeFramework(dir)
+			app.OutputLocation, _ = staticOutputLocation(dir, app.Framework)
+			app.BuildCommand = nodeBuildCommand(dir)
+		} else if fileExistsIn(filepath.Dir(dir), "package.json") {
+			return nil // The public or build output folder of a frontend, not an app of its own
+		} else {
+			app.OutputLocation = "." // Plain static site served as-is
+		}
+	} else {
+		if !fileExistsIn(dir, "package.json") || hasServerDependency(dir) {
+			return nil
+		}
+		app.BuildCommand = nodeBuildCommand(dir)
+		if app.BuildCommand == "" {
+			return nil
+		}
+		app.Framework = DetectNodeFramework(dir)
+		output, ok := staticOutputLocation(dir, app.Framework)
+		if !ok {
+			return nil
+		}
+		app.Source = "package.json"
+		app.OutputLocation = output
+	}
+
+	if app.APILocation == "" && DetectFunctionProject(filepath.Join(dir, "api")) != nil {
+		app.APILocation = "api"
+	}
+	return app
+}
+
+// detectFromSWACLIConfig fills app from the first configuration in swa-cli.config.json, if any.
+func detectFromSWACLIConfig(app *types.StaticWebApp) bool {
+	data, err := readProjectFile(filepath.Join(app.Dir, swaCLIConfigFile))
+	if err != nil {
+		return false
+	}
+	var cfg struct {
+		Configurations map[string]struct {
+			AppLocation     string `json:"appLocation"`
+			OutputLocation  string `json:"outputLocation"`
+			APILocation     string `json:"apiLocation"`
+			AppBuildCommand string `json:"appBuildCommand"`
+			AppDevserverURL string `json:"appDevserverUrl"`
+		} `json:"configurations"`
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil || len(cfg.Configurations) == 0 {
+		return false
+	}
+
+	names := make([]string, 0, len(cfg.Configurations))
+	for name := range cfg.Configurations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	c := cfg.Configurations[names[0]]
+
+	app.Source = swaCLIConfigFile
+	app.AppLocation = cleanRelative(c.AppLocation)
+	app.OutputLocation = cleanRelative(c.OutputLocation)
+	if c.APILocation != "" {
+		app.APILocation = cleanRelative(c.APILocation)
+	}
+	app.BuildCommand = c.AppBuildCommand
+	app.DevServerURL = c.AppDevserverURL
+	return true
+}
+
+// findSWAConfig returns the path of staticwebapp.config.json relative to dir, or "" if there is none.
+func findSWAConfig(dir string) string {
+	for _, sub := range append([]string{"."}, swaConfigDirs...) {
+		if fileExistsIn(filepath.Join(dir, sub), swaConfigFile) {
+			return filepath.ToSlash(filepath.Join(sub, swaConfigFile))
+		}
+	}
+	return ""
+}
+
+// staticOutputLocation returns the folder a frontend builds to, relative to dir, and whether
+// the framework produces a static site at all.
+func staticOutputLocation(dir string, framework string) (string, bool) {
+	deps := readPackageDependencies(dir)
+
+	switch framework {
+	case "Vite":
+		if match := viteOutDirPattern.FindSubmatch(readFirstFile(dir, "vite.config.ts", "vite.config.js", "vite.config.mjs")); match != nil {
+			return cleanRelative(string(match[1])), true
+		}
+		return "dist", true
+	case "Angular":
+		return angularOutputPath(dir), true
+	case "Astro":
+		if deps["@astrojs/node"] || astroServerPattern.Match(readFirstFile(dir, "astro.config.mjs", "astro.config.js", "astro.config.ts")) {
+			return "", false
+		}
+		return "dist", true
+	case "SvelteKit":
+		return "build", deps["@sveltejs/adapter-static"]
+	case "Next.js":
+		return "out", nextExportPattern.Match(readFirstFile(dir, "next.config.js", "next.config.mjs", "next.config.ts"))
+	case "React":
+		switch {
+		case deps["gatsby"]:
+			return "public", true
+		case deps["react-scripts"]:
+			return "build", true
+		}
+	case "Vue":
+		if deps["@vue/cli-service"] {
+			if match := vueOutputDirPattern.FindSubmatch(readFirstFile(dir, "vue.config.js", "vue.config.mjs")); match != nil {
+				return cleanRelative(string(match[1])), true
+			}
+			return "dist", true
+		}
+	}
+	return "", false
+}
+
+// angularOutputPath returns the browser output folder of the first application in angular.json.
+// Projects built with the application builder emit the browser bundle into a browser subfolder.
+func angularOutputPath(dir string) string {
+	data, err := readProjectFile(filepath.Join(dir, "angular.json"))
+	if err != nil {
+		return "dist"
+	}
+	var cfg struct {
+		Projects map[string]struct {
+			ProjectType string `json:"projectType"`
+			Architect   map[string]struct {
+				Builder string `json:"builder"`
+				Options struct {
+					OutputPath json.RawMessage `json:"outputPath"`
+				} `json:"options"`
+			} `json:"architect"`
+		} `json:"projects"`
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return "dist"
+	}
+
+	names := make([]string, 0, len(cfg.Projects))
+	for name, project := range cfg.Projects {
+		if project.ProjectType == "" || project.ProjectType == "application" {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return "dist"
+	}
+	sort.Strings(names)
+	name := names[0]
+	build := cfg.Projects[name].Architect["build"]
+
+	base, browser := filepath.Join("dist", name), "browser"
+	var path string
+	var pathObject struct {
+		Base    string  `json:"base"`
+		Browser *string `json:"browser"`
+	}
+	if json.Unmarshal(build.Options.OutputPath, &path) == nil && path != "" {
+		base = path
+	} else if json.Unmarshal(build.Options.OutputPath, &pathObject) == nil && pathObject.Base != "" {
+		base = pathObject.Base
+		if pathObject.Browser != nil {
+			browser = *pathObject.Browser
+		}
+	}
+
+	if strings.HasSuffix(build.Builder, ":application") && browser != "" {
+		return cleanRelative(filepath.Join(base, browser))
+	}
+	return cleanRelative(base)
+}
+
+// nodeBuildCommand returns the command that runs the build script of package.json in dir, or "".
+func nodeBuildCommand(dir string) string {
+	data, err := readProjectFile(filepath.Join(dir, "package.json"))
+	if err != nil {
+		return ""
+	}
+	var pkg struct {
+		Scripts map[string]string `json:"scripts"`
+	}
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return ""
+	}
+	if _, ok := pkg.Scripts["build"]; !ok {
+		return ""
+	}
+	return DetectNodePackageManager(dir) + " run build"
+}
+
+// hasServerDependency reports whether package.json in dir depends on a server framework.
+func hasServerDependency(dir string) bool {
+	deps := readPackageDependencies(dir)
+	for _, dep := range serverDependencies {
+		if deps[dep] {
+			return true
+		}
+	}
+	return false
+}
+
+// readFirstFile returns the content of the first of names that can be read in dir.
+func readFirstFile(dir string, names ...string) []byte {
+	for _, name := range names {
+		if data, err := readProjectFile(filepath.Join(dir, name)); err == nil {
+			return data
+		}
+	}
+	return nil
+}
+
+// cleanRelative normalizes a relative location to forward slashes, using "." for the folder itself.
+func cleanRelative(path string) string {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return "."
+	}
+	return filepath.ToSlash(filepath.Clean(path))
+}
+
+// staticWebAppMatcher collects Static Web Apps candidate directories.
+type staticWebAppMatcher struct {
+	apps []types.StaticWebApp
+	seen map[string]bool
+}
+
+func newStaticWebAppMatcher() *staticWebAppMatcher {
+	return &staticWebAppMatcher{seen: make(map[string]bool)}
+}
+
+func (m *staticWebAppMatcher) matchFile(path string, name string) {
+	dir := filepath.Dir(path)
+	switch name {
+	case "package.json", swaCLIConfigFile:
+		m.check(dir)
+	case swaConfigFile:
+		// The config may sit in a folder that the build copies into the output
+		for _, sub := range swaConfigDirs {
+			if filepath.Base(dir) == sub {
+				m.check(filepath.Dir(dir))
+			}
+		}
+		m.check(dir)
+	}
+}
+
+// result returns the apps found. An app configured by swa-cli.config.json in a parent folder
+// is reported only once.
+func (m *staticWebAppMatcher) result() []types.StaticWebApp {
+	claimed := make(map[string]bool)
+	for _, app := range m.apps {
+		if app.AppLocation != "." {
+			claimed[filepath.Join(app.Dir, app.AppLocation)] = true
+		}
+	}
+	var apps []types.StaticWebApp
+	for _, app := range m.apps {
+		if !claimed[app.Dir] {
+			apps = append(apps, app)
+		}
+	}
+	return apps
+}
+
+func (m *staticWebAppMatcher) check(dir string) {
+	if m.seen[dir] {
+		return
+	}
+	m.seen[dir] = true
+	if app := DetectStaticWebApp(dir); app != nil {
+		m.apps = append(m.apps, *app)
+	}
+}
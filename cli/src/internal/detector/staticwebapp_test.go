@@ -0,0 +1,181 @@
+package detector
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestDetectStaticWebApp(t *testing.T) {
+	tests := []struct {
+		name       string
+		files      map[string]string
+		wantNil    bool
+		wantSource string
+		wantOutput string
+		wantApp    string
+		wantAPI    string
+		wantBuild  string
+		wantDevURL string
+	}{
+		{
+			name: "swa-cli config",
+			files: map[string]string{
+				"swa-cli.config.json":   `{"configurations": {"web": {"appLocation": "frontend", "outputLocation": "dist", "apiLocation": "api", "appDevserverUrl": "http://localhost:5173"}}}`,
+				"frontend/package.json": `{"scripts": {"build": "vite build"}, "devDependencies": {"vite": "^5.0.0"}}`,
+			},
+			wantSource: "swa-cli.config.json",
+			wantApp:    "frontend",
+			wantOutput: "dist",
+			wantAPI:    "api",
+			wantBuild:  "npm run build",
+			wantDevURL: "http://localhost:5173",
+		},
+		{
+			name: "staticwebapp.config.json in public folder",
+			files: map[string]string{
+				"public/staticwebapp.config.json": `{"navigationFallback": {"rewrite": "/index.html"}}`,
+				"package.json":                    `{"scripts": {"build": "react-scripts build"}, "dependencies": {"react": "^18.0.0", "react-scripts": "5.0.1"}}`,
+			},
+			wantSource: "public/staticwebapp.config.json",
+			wantApp:    ".",
+			wantOutput: "build",
+			wantBuild:  "npm run build",
+		},
+		{
+			name: "plain static site",
+			files: map[string]string{
+				"staticwebapp.config.json": `{}`,
+				"index.html":               "<html></html>",
+			},
+			wantSource: "staticwebapp.config.json",
+			wantApp:    ".",
+			wantOutput: ".",
+		},
+		{
+			name: "vite with custom outDir and functions api",
+			files: map[string]string{
+				"package.json":     `{"scripts": {"build": "vite build"}, "dependencies": {"vue": "^3.0.0"}, "devDependencies": {"vite": "^5.0.0"}}`,
+				"vite.config.ts":   "export default defineConfig({ build: { outDir: 'public-dist' } })",
+				"api/host.json":    `{"version": "2.0"}`,
+				"api/package.json": `{"dependencies": {"@azure/functions": "^4.0.0"}}`,
+				"pnpm-lock.yaml":   "",
+			},
+			wantSource: "package.json",
+			wantApp:    ".",
+			wantOutput: "public-dist",
+			wantAPI:    "api",
+			wantBuild:  "pnpm run build",
+		},
+		{
+			name: "angular application builder",
+			files: map[string]string{
+				"package.json": `{"scripts": {"build": "ng build"}, "dependencies": {"@angular/core": "^17.0.0"}}`,
+				"angular.json": `{"projects": {"shop": {"projectType": "application", "architect": {"build": {"builder": "@angular-devkit/build-angular:application", "options": {"outputPath": "dist/shop"}}}}}}`,
+			},
+			wantSource: "package.json",
+			wantApp:    ".",
+			wantOutput: "dist/shop/browser",
+			wantBuild:  "npm run build",
+		},
+		{
+			name: "next.js static export",
+			files: map[string]string{
+				"package.json":   `{"scripts": {"build": "next build"}, "dependencies": {"next": "^14.0.0", "react": "^18.0.0"}}`,
+				"next.config.js": "module.exports = { output: 'export' }",
+			},
+			wantSource: "package.json",
+			wantApp:    ".",
+			wantOutput: "out",
+			wantBuild:  "npm run build",
+		},
+		{
+			name: "next.js server rendering",
+			files: map[string]string{
+				"package.json": `{"scripts": {"build": "next build"}, "dependencies": {"next": "^14.0.0"}}`,
+			},
+			wantNil: true,
+		},
+		{
+			name: "react app with express server",
+			files: map[string]string{
+				"package.json": `{"scripts": {"build": "react-scripts build"}, "dependencies": {"react": "^18.0.0", "react-scripts": "5.0.1", "express": "^4.0.0"}}`,
+			},
+			wantNil: true,
+		},
+		{
+			name: "frontend without build script",
+			files: map[string]string{
+				"package.json": `{"devDependencies": {"vite": "^5.0.0"}}`,
+			},
+			wantNil: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				writeFileContent(t, filepath.Join(dir, filepath.FromSlash(name)), content)
+			}
+
+			app := DetectStaticWebApp(dir)
+			if tt.wantNil {
+				if app != nil {
+					t.Errorf("expected no static web app, got %+v", app)
+				}
+				return
+			}
+			if app == nil {
+				t.Fatal("expected a static web app")
+			}
+			if app.Host != HostStaticWebApp {
+				t.Errorf("Host = %q, want %q", app.Host, HostStaticWebApp)
+			}
+			checks := []struct{ field, got, want string }{
+				{"Source", app.Source, tt.wantSource},
+				{"AppLocation", app.AppLocation, tt.wantApp},
+				{"OutputLocation", app.OutputLocation, tt.wantOutput},
+				{"APILocation", app.APILocation, tt.wantAPI},
+				{"BuildCommand", app.BuildCommand, tt.wantBuild},
+				{"DevServerURL", app.DevServerURL, tt.wantDevURL},
+			}
+			for _, c := range checks {
+				if c.got != c.want {
+					t.Errorf("%s = %q, want %q", c.field, c.got, c.want)
+				}
+			}
+		})
+	}
+}
+
+func TestFindStaticWebApps(t *testing.T) {
+	dir := t.TempDir()
+	// Configured by swa-cli.config.json at the root; web/ must not be reported twice
+	writeFileContent(t, filepath.Join(dir, "swa-cli.config.json"), `{"configurations": {"app": {"appLocation": "web", "outputLocation": "dist"}}}`)
+	writeFileContent(t, filepath.Join(dir, "web", "package.json"), `{"scripts": {"build": "vite build"}, "devDependencies": {"vite": "^5.0.0"}}`)
+	writeFileContent(t, filepath.Join(dir, "web", "public", "staticwebapp.config.json"), `{}`)
+	// A second frontend recognized from package.json only
+	writeFileContent(t, filepath.Join(dir, "admin", "package.json"), `{"scripts": {"build": "react-scripts build"}, "dependencies": {"react": "^18.0.0", "react-scripts": "5.0.1"}}`)
+	// A backend that is not a candidate
+	writeFileContent(t, filepath.Join(dir, "server", "package.json"), `{"scripts": {"build": "tsc"}, "dependencies": {"express": "^4.0.0"}}`)
+
+	apps, err := FindStaticWebApps(dir)
+	if err != nil {
+		t.Fatalf("FindStaticWebApps failed: %v", err)
+	}
+
+	got := make(map[string]string)
+	for _, app := range apps {
+		rel, _ := filepath.Rel(dir, app.Dir)
+		got[filepath.ToSlash(rel)] = app.Source
+	}
+	want := map[string]string{".": "swa-cli.config.json", "admin": "package.json"}
+	if len(got) != len(want) {
+		t.Fatalf("apps = %v, want %v", got, want)
+	}
+	for dir, source := range want {
+		if got[dir] != source {
+			t.Errorf("source of %s = %q, want %q", dir, got[dir], source)
+		}
+	}
+}
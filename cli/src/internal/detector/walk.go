@@ -0,0 +1,422 @@
+package detector
+
+import (
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jongio/azd-app/cli/src/internal/types"
+)
+
+// skipDirs lists directory names that are never descended into by any detector.
+// These are dependency, build output, virtual environment, and VCS folders.
+var skipDirs = map[string]bool{
+	skipDirNodeModules: true,
+	skipDirGit:         true,
+	skipDirBin:         true,
+	skipDirObj:         true,
+	"venv":             true,
+	".venv":            true,
+	"__pycache__":      true,
+	".uv":              true,
+}
+
+// fileMatcher receives every file visited during a workspace walk.
+// Each detector implements a matcher so that a single traversal can feed all of them.
+type fileMatcher interface {
+	matchFile(path string, name string)
+}
+
+// Reasons a directory is skipped by the workspace walk.
+const (
+	// SkipBuiltIn is a dependency, build output, virtual environment, or VCS folder.
+	SkipBuiltIn = "built-in"
+	// SkipIgnored is a path excluded by .gitignore, .azdignore, or a configured ignore glob.
+	SkipIgnored = "ignored"
+	// SkipNestedWorkspace is a directory with its own azure.yaml, which owns its projects.
+	SkipNestedWorkspace = "nested-workspace"
+)
+
+// SkippedDir is a directory the workspace walk didn't descend into, and why.
+type SkippedDir struct {
+	Path   string `json:"path"`
+	Reason string `json:"reason"`
+	Rule   string `json:"rule,omitempty"`   // Ignore pattern that excludes it, or the azure.yaml of a nested workspace
+	Source string `json:"source,omitempty"` // File the ignore pattern is from
+}
+
+// skipMatcher is implemented by matchers that want to know which directories the walk skips.
+type skipMatcher interface {
+	skippedDir(dir SkippedDir)
+}
+
+// finiteMatcher is implemented by matchers that stop needing input once satisfied.
+// The walk ends early when every matcher is finite and done.
+type finiteMatcher interface {
+	done() bool
+}
+
+// WorkspaceScan contains the results of a single-pass scan of a workspace.
+type WorkspaceScan struct {
+	Root           string
+	NodeProjects   []types.NodeProject
+	PythonProjects []types.PythonProject
+	DotnetProjects []types.DotnetProject
+	Dockerfiles    []types.Dockerfile
+	Functions      []types.FunctionProject
+	StaticWebApps  []types.StaticWebApp
+	AppHost        *types.AspireProject // As FindAppHost selects it
+	Skipped        []SkippedDir         // Directories not descended into, in walk order
+}
+
+// ScanWorkspace walks rootDir once and runs every project detector against the visited files.
+// Only searches within rootDir and does not traverse outside it.
+func ScanWorkspace(rootDir string) (*WorkspaceScan, error) {
+	rootDir, err := filepath.Abs(rootDir)
+	if err != nil {
+		return nil, err
+	}
+
+	node := newNodeMatcher(rootDir)
+	python := newPythonMatcher(rootDir)
+	dotnet := newDotnetMatcher(rootDir)
+	dockerfiles := newDockerfileMatcher()
+	functions := newFunctionMatcher()
+	staticApps := newStaticWebAppMatcher()
+	appHost := newAppHostMatcher()
+	skipped := &skippedDirs{}
+
+	err = walkWorkspace(rootDir, node, python, dotnet, dockerfiles, functions, staticApps, appHost, skipped)
+	selected, ok, _ := selectedAppHost(rootDir)
+	if !ok {
+		// Several equally likely AppHosts don't fail the scan; the most likely one is used
+		selected, _ = likeliestAppHost(rootDir, appHost)
+	}
+
+	return &WorkspaceScan{
+		Root:           rootDir,
+		NodeProjects:   node.projects,
+		PythonProjects: python.projects,
+		DotnetProjects: dotnet.projects,
+		Dockerfiles:    dockerfiles.dockerfiles,
+		Functions:      functions.projects,
+		StaticWebApps:  staticApps.result(),
+		AppHost:        selected,
+		Skipped:        skipped.dirs,
+	}, err
+}
+
+// walkWorkspace traverses rootDir once, skipping ignored directories, and
+// passes every file to each matcher. rootDir must be absolute.
+// Paths matched by configured ignore globs are always skipped; paths excluded by
+// .gitignore or .azdignore are skipped unless disabled with SetRespectIgnoreFiles.
+// Directories below rootDir with their own azure.yaml are nested workspaces, which own
+// their projects, and are skipped too.
+func walkWorkspace(rootDir string, matchers ...fileMatcher) error {
+	return walkTree(rootDir, true, matchers...)
+}
+
+// walkTree is walkWorkspace, descending into nested workspaces unless stopAtWorkspaces is set.
+func walkTree(rootDir string, stopAtWorkspaces bool, matchers ...fileMatcher) error {
+	ignores := newIgnoreRules(rootDir, RespectIgnoreFiles())
+
+	return filepath.WalkDir(rootDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil // Skip errors
+		}
+
+		// Ensure we don't traverse outside rootDir
+		relPath, err := filepath.Rel(rootDir, path)
+		if err != nil || strings.HasPrefix(relPath, "..") {
+			return filepath.SkipDir
+		}
+
+		if d.IsDir() {
+			if path == rootDir {
+				ignores.load(path)
+				return nil
+			}
+			if skipDirs[d.Name()] {
+				return skipDir(matchers, SkippedDir{Path: path, Reason: SkipBuiltIn})
+			}
+			if rule, ignored := ignores.match(path, true); ignored {
+				return skipDir(matchers, SkippedDir{Path: path, Reason: SkipIgnored, Rule: rule.pattern, Source: rule.source})
+			}
+			if stopAtWorkspaces && hasAzureYaml(path) {
+				return skipDir(matchers, SkippedDir{Path: path, Reason: SkipNestedWorkspace, Rule: filepath.Join(path, "azure.yaml")})
+			}
+			ignores.load(path)
+			return nil
+		}
+
+		if ignores.ignored(path, false) {
+			return nil
+		}
+
+		for _, m := range matchers {
+			m.matchFile(path, d.Name())
+		}
+		if allDone(matchers) {
+			return filepath.SkipAll
+		}
+		return nil
+	})
+}
+
+// skipDir tells the matchers that want to know that dir is skipped, and skips it.
+func skipDir(matchers []fileMatcher, dir SkippedDir) error {
+	for _, m := range matchers {
+		if s, ok := m.(skipMatcher); ok {
+			s.skippedDir(dir)
+		}
+	}
+	return filepath.SkipDir
+}
+
+// skippedDirs collects the directories the walk skips.
+type skippedDirs struct {
+	dirs []SkippedDir
+}
+
+func (m *skippedDirs) matchFile(string, string) {}
+
+func (m *skippedDirs) skippedDir(dir SkippedDir) {
+	m.dirs = append(m.dirs, dir)
+}
+
+// WalkFiles calls fn for every file below rootDir that detectors would visit, honoring the
+// same skip directories, ignore files, and ignore globs. Nested workspaces are skipped.
+func WalkFiles(rootDir string, fn func(path string)) error {
+	rootDir, err := filepath.Abs(rootDir)
+	if err != nil {
+		return err
+	}
+	return walkWorkspace(rootDir, funcMatcher(fn))
+}
+
+// funcMatcher passes every visited file to a function.
+type funcMatcher func(path string)
+
+func (m funcMatcher) matchFile(path string, _ string) {
+	m(path)
+}
+
+// allDone reports whether every matcher has finished and the walk can stop.
+func allDone(matchers []fileMatcher) bool {
+	for _, m := range matchers {
+		f, ok := m.(finiteMatcher)
+		if !ok || !f.done() {
+			return false
+		}
+	}
+	return true
+}
+
+// nodeMatcher collects directories containing package.json.
+// Inside a pnpm, yarn, or npm workspace only the root and its declared members are collected.
+type nodeMatcher struct {
+	rootDir    string
+	projects   []types.NodeProject
+	seen       map[string]bool
+	workspaces *workspaceResolver
+}
+
+func newNodeMatcher(rootDir string) *nodeMatcher {
+	return &nodeMatcher{rootDir: rootDir, seen: make(map[string]bool), workspaces: newWorkspaceResolver(rootDir)}
+}
+
+func (m *nodeMatcher) matchFile(path string, name string) {
+	if name != "package.json" {
+		return
+	}
+
+	dir := filepath.Dir(path)
+	if m.seen[dir] {
+		return
+	}
+
+	m.seen[dir] = true
+
+	project := types.NodeProject{
+		Dir:            dir,
+		PackageManager: DetectNodePackageManagerWithBoundary(dir, m.rootDir),
+		Framework:      DetectNodeFramework(dir),
+		Dockerfile:     DetectDockerfile(dir),
+		IsTestProject:  IsNodeTestProject(dir),
+		VersionPins:    NodeVersionPins(dir, m.rootDir),
+	}
+
+	if ws := m.workspaces.workspaceAt(dir); ws != nil {
+		project.IsWorkspaceRoot = true
+		project.PackageManager = ws.manager
+	}
+	if owner := m.workspaces.owner(dir); owner != nil {
+		if !owner.isMember(dir) {
+			return // Internal package that is not a declared workspace member
+		}
+		// Members share the root lockfile and package manager
+		project.WorkspaceRoot = owner.dir
+		project.PackageManager = owner.manager
+	}
+
+	m.projects = append(m.projects, project)
+}
+
+// pythonMatcher collects directories containing Python project indicators.
+type pythonMatcher struct {
+	rootDir  string
+	projects []types.PythonProject
+	seen     map[string]bool
+}
+
+func newPythonMatcher(rootDir string) *pythonMatcher {
+	return &pythonMatcher{rootDir: rootDir, seen: make(map[string]bool)}
+}
+
+func (m *pythonMatcher) matchFile(path string, name string) {
+	if !IsPythonProjectFile(name) {
+		return
+	}
+
+	dir := filepath.Dir(path)
+	if m.seen[dir] {
+		return
+	}
+
+	projectName, pythonVersion := PythonProjectMetadata(dir)
+	project := types.PythonProject{
+		Dir:            dir,
+		PackageManager: DetectPythonPackageManager(dir),
+		Name:           projectName,
+		PythonVersion:  pythonVersion,
+		Framework:      DetectPythonFramework(dir),
+		Dockerfile:     DetectDockerfile(dir),
+		IsTestProject:  IsPythonTestProject(dir),
+		VersionPins:    PythonVersionPins(dir, m.rootDir),
+	}
+	if ep := DetectPythonEntrypoint(dir, project.Framework); ep != nil {
+		project.EntryFile = ep.File
+		if ep.App != "" {
+			project.AppTarget = ep.Target()
+		}
+	}
+	m.projects = append(m.projects, project)
+	m.seen[dir] = true
+}
+
+// dotnetMatcher collects .csproj/.fsproj/.vbproj directories and .sln/.slnx files.
+type dotnetMatcher struct {
+	rootDir  string
+	projects []types.DotnetProject
+	seen     map[string]bool
+}
+
+func newDotnetMatcher(rootDir string) *dotnetMatcher {
+	return &dotnetMatcher{rootDir: rootDir, seen: make(map[string]bool)}
+}
+
+func (m *dotnetMatcher) matchFile(path string, name string) {
+	switch filepath.Ext(name) {
+	case ".sln", ".slnx":
+		// For solutions, track the file itself
+		if !m.seen[path] {
+			m.projects = append(m.projects, types.DotnetProject{Path: path})
+			m.seen[path] = true
+		}
+	case ".csproj", ".fsproj", ".vbproj":
+		// For project files, track one project per directory
+		dir := filepath.Dir(path)
+		if !m.seen[dir] {
+			// Unreadable project files are still reported, without metadata
+			project, _ := ParseDotnetProject(path)
+			project.Dockerfile = DetectDockerfile(dir)
+			project.VersionPins = DotnetVersionPins(dir, m.rootDir)
+			m.projects = append(m.projects, project)
+			m.seen[dir] = true
+		}
+	}
+}
+
+// appHostMatcher collects the directories where an AppHost.cs or Program.cs sits next to a
+// .csproj, which may be Aspire AppHosts, and the solutions that may reference them.
+type appHostMatcher struct {
+	candidates []appHostCandidate
+	solutions  []string
+	seen       map[string]bool
+}
+
+func newAppHostMatcher() *appHostMatcher {
+	return &appHostMatcher{seen: make(map[string]bool)}
+}
+
+func (m *appHostMatcher) matchFile(path string, name string) {
+	if IsDotnetSolution(name) {
+		m.solutions = append(m.solutions, path)
+		return
+	}
+	if name != "AppHost.cs" && name != "Program.cs" {
+		return
+	}
+
+	// Check if it's in a project directory (has .csproj)
+	dir := filepath.Dir(path)
+	if m.seen[dir] {
+		return
+	}
+	matches, err := filepath.Glob(filepath.Join(dir, "*.csproj"))
+	if err != nil || len(matches) == 0 {
+		return
+	}
+	m.seen[dir] = true
+
+	m.candidates = append(m.candidates, appHostCandidate{project: types.AspireProject{
+		Dir:         dir,
+		ProjectFile: appHostProjectFile(matches),
+	}})
+}
+
+// ranked returns the candidates, most likely first. When some are AppHost projects, the
+// others are left out.
+func (m *appHostMatcher) ranked(rootDir string) []appHostCandidate {
+	referenced := make(map[string]bool)
+	for _, solution := range m.solutions {
+		paths, _ := ParseSolutionProjects(solution)
+		for _, path := range paths {
+			referenced[filepath.Clean(path)] = true
+		}
+	}
+
+	var candidates, appHosts []appHostCandidate
+	for _, c := range m.candidates {
+		project, err := ParseDotnetProject(c.project.ProjectFile)
+		c.isAppHost = err == nil && project.IsAppHost
+		c.incidental = inSampleDir(rootDir, c.project.Dir) || InToolOrTestDir(rootDir, c.project.Dir)
+		c.referenced = referenced[filepath.Clean(c.project.ProjectFile)]
+		if rel, err := filepath.Rel(rootDir, c.project.Dir); err == nil && rel != "." {
+			c.depth = len(strings.Split(filepath.ToSlash(rel), "/"))
+		}
+		candidates = append(candidates, c)
+		if c.isAppHost {
+			appHosts = append(appHosts, c)
+		}
+	}
+	if len(appHosts) > 0 {
+		candidates = appHosts
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		switch {
+		case a.incidental != b.incidental:
+			return !a.incidental
+		case a.referenced != b.referenced:
+			return a.referenced
+		case a.depth != b.depth:
+			return a.depth < b.depth
+		default:
+			return a.project.ProjectFile < b.project.ProjectFile
+		}
+	})
+	return candidates
+}
@@ -0,0 +1,153 @@
+package detector
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeTestFile(t *testing.T, path string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(""), 0600); err != nil {
+		t.Fatalf("failed to write %s: %v", path, err)
+	}
+}
+
+func TestScanWorkspace(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	writeTestFile(t, filepath.Join(tmpDir, "web", "package.json"))
+	writeTestFile(t, filepath.Join(tmpDir, "web", "pnpm-lock.yaml"))
+	writeTestFile(t, filepath.Join(tmpDir, "api", "requirements.txt"))
+	writeTestFile(t, filepath.Join(tmpDir, "api", "pyproject.toml"))
+	writeTestFile(t, filepath.Join(tmpDir, "App.sln"))
+	writeTestFile(t, filepath.Join(tmpDir, "AppHost", "AppHost.csproj"))
+	writeTestFile(t, filepath.Join(tmpDir, "AppHost", "AppHost.cs"))
+
+	// Files inside shared skip directories must be ignored by every matcher
+	writeTestFile(t, filepath.Join(tmpDir, "web", "node_modules", "dep", "package.json"))
+	writeTestFile(t, filepath.Join(tmpDir, "api", ".venv", "lib", "pyproject.toml"))
+	writeTestFile(t, filepath.Join(tmpDir, ".venv", "tool", "package.json"))
+	writeTestFile(t, filepath.Join(tmpDir, "AppHost", "bin", "Debug", "Other.csproj"))
+
+	scan, err := ScanWorkspace(tmpDir)
+	if err != nil {
+		t.Fatalf("ScanWorkspace() error = %v", err)
+	}
+
+	if len(scan.NodeProjects) != 1 || scan.NodeProjects[0].PackageManager != "pnpm" {
+		t.Errorf("NodeProjects = %+v, want one pnpm project", scan.NodeProjects)
+	}
+	if len(scan.PythonProjects) != 1 || scan.PythonProjects[0].Dir != filepath.Join(tmpDir, "api") {
+		t.Errorf("PythonProjects = %+v, want one project in api", scan.PythonProjects)
+	}
+	if len(scan.DotnetProjects) != 2 {
+		t.Errorf("DotnetProjects = %+v, want 2 (sln and csproj)", scan.DotnetProjects)
+	}
+	if scan.AppHost == nil || scan.AppHost.Dir != filepath.Join(tmpDir, "AppHost") {
+		t.Errorf("AppHost = %+v, want project in AppHost", scan.AppHost)
+	}
+}
+
+func TestScanWorkspaceMatchesFindFunctions(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	writeTestFile(t, filepath.Join(tmpDir, "frontend", "package.json"))
+	writeTestFile(t, filepath.Join(tmpDir, "frontend", "yarn.lock"))
+	writeTestFile(t, filepath.Join(tmpDir, "backend", "uv.lock"))
+	writeTestFile(t, filepath.Join(tmpDir, "svc", "Svc.csproj"))
+	writeTestFile(t, filepath.Join(tmpDir, "svc", "Program.cs"))
+	writeFileContent(t, filepath.Join(tmpDir, "fn", "host.json"), `{"version": "2.0"}`)
+	writeTestFile(t, filepath.Join(tmpDir, "fn", "requirements.txt"))
+	writeFileContent(t, filepath.Join(tmpDir, "site", "staticwebapp.config.json"), "{}")
+
+	scan, err := ScanWorkspace(tmpDir)
+	if err != nil {
+		t.Fatalf("ScanWorkspace() error = %v", err)
+	}
+
+	functions, _ := FindFunctionProjects(tmpDir)
+	staticApps, _ := FindStaticWebApps(tmpDir)
+	if len(functions) != 1 || !reflect.DeepEqual(functions, scan.Functions) {
+		t.Errorf("FindFunctionProjects() = %+v, ScanWorkspace = %+v", functions, scan.Functions)
+	}
+	if len(staticApps) == 0 || !reflect.DeepEqual(staticApps, scan.StaticWebApps) {
+		t.Errorf("FindStaticWebApps() = %+v, ScanWorkspace = %+v", staticApps, scan.StaticWebApps)
+	}
+
+	node, _ := FindNodeProjects(tmpDir)
+	python, _ := FindPythonProjects(tmpDir)
+	dotnet, _ := FindDotnetProjects(tmpDir)
+	appHost, _ := FindAppHost(tmpDir)
+
+	if len(node) != len(scan.NodeProjects) || !reflect.DeepEqual(node[0], scan.NodeProjects[0]) {
+		t.Errorf("FindNodeProjects() = %+v, ScanWorkspace = %+v", node, scan.NodeProjects)
+	}
+	if len(python) != len(scan.PythonProjects) || !reflect.DeepEqual(python[0], scan.PythonProjects[0]) {
+		t.Errorf("FindPythonProjects() = %+v, ScanWorkspace = %+v", python, scan.PythonProjects)
+	}
+	if !reflect.DeepEqual(dotnet, scan.DotnetProjects) {
+		t.Errorf("FindDotnetProjects() = %+v, ScanWorkspace = %+v", dotnet, scan.DotnetProjects)
+	}
+	if appHost == nil || scan.AppHost == nil || *appHost != *scan.AppHost {
+		t.Errorf("FindAppHost() = %+v, ScanWorkspace = %+v", appHost, scan.AppHost)
+	}
+}
+
+func TestWalkFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	writeTestFile(t, filepath.Join(tmpDir, "main.py"))
+	writeTestFile(t, filepath.Join(tmpDir, "pkg", "util.py"))
+	writeTestFile(t, filepath.Join(tmpDir, "node_modules", "dep", "index.js"))
+	writeTestFile(t, filepath.Join(tmpDir, "dist", "out.js"))
+	writeTestFile(t, filepath.Join(tmpDir, "nested", "azure.yaml"))
+	writeTestFile(t, filepath.Join(tmpDir, "nested", "app.py"))
+	if err := os.WriteFile(filepath.Join(tmpDir, ".gitignore"), []byte("dist/\n"), 0600); err != nil {
+		t.Fatalf("failed to write .gitignore: %v", err)
+	}
+
+	var got []string
+	if err := WalkFiles(tmpDir, func(path string) {
+		rel, _ := filepath.Rel(tmpDir, path)
+		got = append(got, filepath.ToSlash(rel))
+	}); err != nil {
+		t.Fatalf("WalkFiles() error = %v", err)
+	}
+
+	want := []string{".gitignore", "main.py", "pkg/util.py"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("WalkFiles() visited %v, want %v", got, want)
+	}
+}
+
+func TestScanWorkspaceSkipped(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	writeTestFile(t, filepath.Join(tmpDir, "web", "package.json"))
+	writeTestFile(t, filepath.Join(tmpDir, "web", "node_modules", "dep", "package.json"))
+	writeTestFile(t, filepath.Join(tmpDir, "web", "dist", "package.json"))
+	writeTestFile(t, filepath.Join(tmpDir, "legacy", "requirements.txt"))
+	writeTestFile(t, filepath.Join(tmpDir, "nested", "azure.yaml"))
+	writeFileContent(t, filepath.Join(tmpDir, "web", ".gitignore"), "# build output\ndist/\n")
+	writeFileContent(t, filepath.Join(tmpDir, ConfigFileName), "detector:\n  ignore:\n    - legacy\n")
+
+	scan, err := ScanWorkspace(tmpDir)
+	if err != nil {
+		t.Fatalf("ScanWorkspace() error = %v", err)
+	}
+
+	want := []SkippedDir{
+		{Path: filepath.Join(tmpDir, "legacy"), Reason: SkipIgnored, Rule: "legacy", Source: filepath.Join(tmpDir, ConfigFileName)},
+		{Path: filepath.Join(tmpDir, "nested"), Reason: SkipNestedWorkspace, Rule: filepath.Join(tmpDir, "nested", "azure.yaml")},
+		{Path: filepath.Join(tmpDir, "web", "dist"), Reason: SkipIgnored, Rule: "dist/", Source: filepath.Join(tmpDir, "web", ".gitignore")},
+		{Path: filepath.Join(tmpDir, "web", "node_modules"), Reason: SkipBuiltIn},
+	}
+	if !reflect.DeepEqual(scan.Skipped, want) {
+		t.Errorf("Skipped = %+v, want %+v", scan.Skipped, want)
+	}
+}
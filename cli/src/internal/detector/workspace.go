@@ -0,0 +1,149 @@
+package detector
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/jongio/azd-app/cli/src/internal/security"
+)
+
+// nodeWorkspace describes a Node.js workspace root and its member globs.
+type nodeWorkspace struct {
+	dir     string
+	manager string
+	include []*regexp.Regexp
+	exclude []*regexp.Regexp
+}
+
+// ReadWorkspaceGlobs returns the workspace member globs declared in dir by
+// pnpm-workspace.yaml or the package.json "workspaces" field.
+// The boolean result reports whether dir is a workspace root.
+func ReadWorkspaceGlobs(dir string) ([]string, bool) {
+	var pnpmWorkspace struct {
+		Packages []string `yaml:"packages"`
+	}
+	if readYamlFile(filepath.Join(dir, "pnpm-workspace.yaml"), &pnpmWorkspace) {
+		return pnpmWorkspace.Packages, true
+	}
+
+	packageJSONPath := filepath.Join(dir, "package.json")
+	if err := security.ValidatePath(packageJSONPath); err != nil {
+		return nil, false
+	}
+	// #nosec G304 -- Path validated by security.ValidatePath
+	data, err := os.ReadFile(packageJSONPath)
+	if err != nil {
+		return nil, false
+	}
+
+	var pkg struct {
+		Workspaces json.RawMessage `json:"workspaces"`
+	}
+	if err := json.Unmarshal(data, &pkg); err != nil || len(pkg.Workspaces) == 0 {
+		return nil, false
+	}
+
+	// npm and yarn accept an array, yarn classic also accepts {"packages": [...]}
+	var globs []string
+	if err := json.Unmarshal(pkg.Workspaces, &globs); err == nil {
+		return globs, true
+	}
+	var object struct {
+		Packages []string `json:"packages"`
+	}
+	if err := json.Unmarshal(pkg.Workspaces, &object); err == nil {
+		return object.Packages, true
+	}
+	return nil, false
+}
+
+// newNodeWorkspace compiles the member globs of the workspace rooted at dir.
+func newNodeWorkspace(dir string, globs []string) *nodeWorkspace {
+	ws := &nodeWorkspace{dir: dir, manager: DetectNodePackageManager(dir)}
+	if _, err := os.Stat(filepath.Join(dir, "pnpm-workspace.yaml")); err == nil {
+		ws.manager = "pnpm"
+	}
+	for _, glob := range globs {
+		glob = strings.TrimSpace(glob)
+		negate := strings.HasPrefix(glob, "!")
+		glob = strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(glob, "!"), "./"), "/")
+		if glob == "" {
+			continue
+		}
+		regex, err := regexp.Compile("^" + globToRegexp(glob) + "$")
+		if err != nil {
+			continue
+		}
+		if negate {
+			ws.exclude = append(ws.exclude, regex)
+		} else {
+			ws.include = append(ws.include, regex)
+		}
+	}
+	return ws
+}
+
+// isMember reports whether dir matches the workspace member globs.
+func (ws *nodeWorkspace) isMember(dir string) bool {
+	rel, err := filepath.Rel(ws.dir, dir)
+	if err != nil || strings.HasPrefix(rel, "..") || rel == "." {
+		return false
+	}
+	rel = filepath.ToSlash(rel)
+
+	for _, regex := range ws.exclude {
+		if regex.MatchString(rel) {
+			return false
+		}
+	}
+	for _, regex := range ws.include {
+		if regex.MatchString(rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// workspaceResolver finds the workspace that owns a directory, caching lookups per directory.
+type workspaceResolver struct {
+	rootDir string
+	cache   map[string]*nodeWorkspace
+	checked map[string]bool
+}
+
+func newWorkspaceResolver(rootDir string) *workspaceResolver {
+	return &workspaceResolver{
+		rootDir: rootDir,
+		cache:   make(map[string]*nodeWorkspace),
+		checked: make(map[string]bool),
+	}
+}
+
+// workspaceAt returns the workspace rooted at dir, or nil if dir is not a workspace root.
+func (r *workspaceResolver) workspaceAt(dir string) *nodeWorkspace {
+	if !r.checked[dir] {
+		r.checked[dir] = true
+		if globs, ok := ReadWorkspaceGlobs(dir); ok {
+			r.cache[dir] = newNodeWorkspace(dir, globs)
+		}
+	}
+	return r.cache[dir]
+}
+
+// owner returns the nearest workspace root above dir, stopping at rootDir.
+func (r *workspaceResolver) owner(dir string) *nodeWorkspace {
+	if dir == r.rootDir {
+		return nil
+	}
+	for parent := filepath.Dir(dir); ; parent = filepath.Dir(parent) {
+		if ws := r.workspaceAt(parent); ws != nil {
+			return ws
+		}
+		if parent == r.rootDir || parent == filepath.Dir(parent) {
+			return nil
+		}
+	}
+}
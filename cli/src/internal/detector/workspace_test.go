@@ -0,0 +1,161 @@
+package detector
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeFileContent(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("failed to write %s: %v", path, err)
+	}
+}
+
+func TestReadWorkspaceGlobs(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		want     []string
+		wantRoot bool
+	}{
+		{
+			name:     "pnpm workspace",
+			files:    map[string]string{"pnpm-workspace.yaml": "packages:\n  - 'apps/*'\n  - '!apps/legacy'\n"},
+			want:     []string{"apps/*", "!apps/legacy"},
+			wantRoot: true,
+		},
+		{
+			name:     "npm workspaces array",
+			files:    map[string]string{"package.json": `{"workspaces": ["packages/*"]}`},
+			want:     []string{"packages/*"},
+			wantRoot: true,
+		},
+		{
+			name:     "yarn workspaces object",
+			files:    map[string]string{"package.json": `{"workspaces": {"packages": ["apps/*"], "nohoist": ["**/x"]}}`},
+			want:     []string{"apps/*"},
+			wantRoot: true,
+		},
+		{
+			name:     "plain package",
+			files:    map[string]string{"package.json": `{"name": "app"}`},
+			wantRoot: false,
+		},
+		{
+			name:     "no files",
+			wantRoot: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				writeFileContent(t, filepath.Join(dir, name), content)
+			}
+
+			got, isRoot := ReadWorkspaceGlobs(dir)
+			if isRoot != tt.wantRoot {
+				t.Errorf("isRoot = %v, want %v", isRoot, tt.wantRoot)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("globs = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindNodeProjectsWorkspace(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	writeFileContent(t, filepath.Join(tmpDir, "package.json"), `{"workspaces": ["apps/*", "!apps/legacy"]}`)
+	writeTestFile(t, filepath.Join(tmpDir, "yarn.lock"))
+	writeFileContent(t, filepath.Join(tmpDir, "apps", "web", "package.json"), `{"dependencies": {"next": "14.0.0"}}`)
+	writeFileContent(t, filepath.Join(tmpDir, "apps", "api", "package.json"), `{"dependencies": {"express": "4.0.0"}}`)
+	writeFileContent(t, filepath.Join(tmpDir, "apps", "legacy", "package.json"), `{}`)
+	writeFileContent(t, filepath.Join(tmpDir, "tools", "scripts", "package.json"), `{}`)
+
+	projects, err := FindNodeProjects(tmpDir)
+	if err != nil {
+		t.Fatalf("FindNodeProjects failed: %v", err)
+	}
+
+	byDir := make(map[string]bool)
+	for _, p := range projects {
+		rel, _ := filepath.Rel(tmpDir, p.Dir)
+		byDir[filepath.ToSlash(rel)] = true
+
+		switch filepath.ToSlash(rel) {
+		case ".":
+			if !p.IsWorkspaceRoot || p.WorkspaceRoot != "" {
+				t.Errorf("root: IsWorkspaceRoot=%v WorkspaceRoot=%q", p.IsWorkspaceRoot, p.WorkspaceRoot)
+			}
+		case "apps/web", "apps/api":
+			if p.WorkspaceRoot != tmpDir {
+				t.Errorf("%s: WorkspaceRoot = %q, want %q", rel, p.WorkspaceRoot, tmpDir)
+			}
+			if p.PackageManager != "yarn" {
+				t.Errorf("%s: PackageManager = %q, want yarn", rel, p.PackageManager)
+			}
+		}
+	}
+
+	for _, want := range []string{".", "apps/web", "apps/api"} {
+		if !byDir[want] {
+			t.Errorf("expected %s to be detected, got %v", want, byDir)
+		}
+	}
+	for _, unwanted := range []string{"apps/legacy", "tools/scripts"} {
+		if byDir[unwanted] {
+			t.Errorf("expected %s to be excluded as a non-member", unwanted)
+		}
+	}
+}
+
+func TestFindNodeProjectsPnpmWorkspace(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	writeFileContent(t, filepath.Join(tmpDir, "pnpm-workspace.yaml"), "packages:\n  - 'packages/**'\n")
+	writeFileContent(t, filepath.Join(tmpDir, "package.json"), `{}`)
+	writeFileContent(t, filepath.Join(tmpDir, "packages", "ui", "package.json"), `{}`)
+	writeFileContent(t, filepath.Join(tmpDir, "packages", "shared", "core", "package.json"), `{}`)
+
+	projects, err := FindNodeProjects(tmpDir)
+	if err != nil {
+		t.Fatalf("FindNodeProjects failed: %v", err)
+	}
+	if len(projects) != 3 {
+		t.Fatalf("expected 3 projects, got %d: %+v", len(projects), projects)
+	}
+	for _, p := range projects {
+		if p.PackageManager != "pnpm" {
+			t.Errorf("%s: PackageManager = %q, want pnpm", p.Dir, p.PackageManager)
+		}
+	}
+}
+
+func TestFindNodeProjectsWithoutWorkspace(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	writeFileContent(t, filepath.Join(tmpDir, "frontend", "package.json"), `{}`)
+	writeFileContent(t, filepath.Join(tmpDir, "backend", "package.json"), `{}`)
+
+	projects, err := FindNodeProjects(tmpDir)
+	if err != nil {
+		t.Fatalf("FindNodeProjects failed: %v", err)
+	}
+	if len(projects) != 2 {
+		t.Fatalf("expected 2 projects, got %d", len(projects))
+	}
+	for _, p := range projects {
+		if p.IsWorkspaceRoot || p.WorkspaceRoot != "" {
+			t.Errorf("%s: unexpected workspace info %+v", p.Dir, p)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+// Package devenv detects remote development environments, such as GitHub Codespaces and dev
+// containers, where the ports of services are reached through port forwarding.
+package devenv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+)
+
+// Kinds of remote development environments.
+const (
+	KindCodespaces   = "codespaces"   // GitHub Codespaces, which forwards ports to URLs of its own
+	KindDevContainer = "devcontainer" // A dev container, which forwards ports to the same ports on the host
+)
+
+// Visibilities of forwarded codespace ports.
+const (
+	VisibilityPrivate = "private" // Only the owner of the codespace, signed in to GitHub
+	VisibilityOrg     = "org"     // Members of the organization that owns the codespace
+	VisibilityPublic  = "public"  // Anyone with the URL
+)
+
+// defaultForwardingDomain is the domain of forwarded codespace ports when the codespace doesn't
+// set GITHUB_CODESPACES_PORT_FORWARDING_DOMAIN.
+const defaultForwardingDomain = "app.github.dev"
+
+// Environment is a remote development environment.
+type Environment struct {
+	Kind   string
+	Name   string // Name of the codespace
+	Domain string // Domain of the URLs of forwarded codespace ports, e.g. app.github.dev
+}
+
+// runCommand runs a command and returns its combined output.
+var runCommand = func(ctx context.Context, name string, args ...string) ([]byte, error) {
+	// #nosec G204 -- name is the fixed gh executable and args are port numbers
+	return exec.CommandContext(ctx, name, args...).CombinedOutput()
+}
+
+// Detect returns the remote development environment the process runs in, or nil when it runs
+// on a local machine.
+func Detect() *Environment {
+	if os.Getenv("CODESPACES") == "true" && os.Getenv("CODESPACE_NAME") != "" {
+		domain := os.Getenv("GITHUB_CODESPACES_PORT_FORWARDING_DOMAIN")
+		if domain == "" {
+			domain = defaultForwardingDomain
+		}
+		return &Environment{Kind: KindCodespaces, Name: os.Getenv("CODESPACE_NAME"), Domain: domain}
+	}
+	if os.Getenv("REMOTE_CONTAINERS") == "true" || os.Getenv("DEVCONTAINER") == "true" {
+		return &Environment{Kind: KindDevContainer}
+	}
+	return nil
+}
+
+// ValidateVisibility checks that visibility is a visibility of forwarded codespace ports.
+func ValidateVisibility(visibility string) error {
+	switch visibility {
+	case VisibilityPrivate, VisibilityOrg, VisibilityPublic:
+		return nil
+	default:
+		return fmt.Errorf("invalid port visibility: %s (must be '%s', '%s', or '%s')", visibility, VisibilityPrivate, VisibilityOrg, VisibilityPublic)
+	}
+}
+
+// ForwardedURL returns the URL that port is forwarded to. A dev container forwards it to the
+// same port on the host, so the URL is the local one.
+func (e *Environment) ForwardedURL(port int) string {
+	if e.Kind == KindCodespaces {
+		return fmt.Sprintf("https://%s-%d.%s", e.Name, port, e.Domain)
+	}
+	return fmt.Sprintf("http://localhost:%d", port)
+}
+
+// ForwardPorts declares ports as forwarded with the given visibility. Codespace ports are
+// declared with the GitHub CLI; a dev container forwards the ports its services listen on by
+// itself, so there is nothing to declare.
+func (e *Environment) ForwardPorts(ctx context.Context, ports []int, visibility string) error {
+	if e.Kind != KindCodespaces || len(ports) == 0 {
+		return nil
+	}
+	if err := ValidateVisibility(visibility); err != nil {
+		return err
+	}
+
+	sorted := append([]int(nil), ports...)
+	sort.Ints(sorted)
+	args := []string{"codespace", "ports", "visibility"}
+	for _, port := range sorted {
+		args = append(args, fmt.Sprintf("%d:%s", port, visibility))
+	}
+	args = append(args, "--codespace", e.Name)
+	out, err := runCommand(ctx, "gh", args...)
+	if errors.Is(err, exec.ErrNotFound) {
+		return fmt.Errorf("the GitHub CLI (gh) is required to forward codespace ports")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to forward ports: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package devenv
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want *Environment
+	}{
+		{name: "local machine"},
+		{
+			name: "codespace",
+			env:  map[string]string{"CODESPACES": "true", "CODESPACE_NAME": "shiny-space-x7", "GITHUB_CODESPACES_PORT_FORWARDING_DOMAIN": "preview.app.github.dev"},
+			want: &Environment{Kind: KindCodespaces, Name: "shiny-space-x7", Domain: "preview.app.github.dev"},
+		},
+		{
+			name: "codespace without forwarding domain",
+			env:  map[string]string{"CODESPACES": "true", "CODESPACE_NAME": "shiny-space-x7"},
+			want: &Environment{Kind: KindCodespaces, Name: "shiny-space-x7", Domain: "app.github.dev"},
+		},
+		{name: "vs code dev container", env: map[string]string{"REMOTE_CONTAINERS": "true"}, want: &Environment{Kind: KindDevContainer}},
+		{name: "dev container", env: map[string]string{"DEVCONTAINER": "true"}, want: &Environment{Kind: KindDevContainer}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, name := range []string{"CODESPACES", "CODESPACE_NAME", "GITHUB_CODESPACES_PORT_FORWARDING_DOMAIN", "REMOTE_CONTAINERS", "DEVCONTAINER"} {
+				t.Setenv(name, tt.env[name])
+			}
+			if got := Detect(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Detect() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestForwardedURL(t *testing.T) {
+	codespace := &Environment{Kind: KindCodespaces, Name: "shiny-space-x7", Domain: "app.github.dev"}
+	if got, want := codespace.ForwardedURL(5173), "https://shiny-space-x7-5173.app.github.dev"; got != want {
+		t.Errorf("ForwardedURL() in a codespace = %s, want %s", got, want)
+	}
+	container := &Environment{Kind: KindDevContainer}
+	if got, want := container.ForwardedURL(5173), "http://localhost:5173"; got != want {
+		t.Errorf("ForwardedURL() in a dev container = %s, want %s", got, want)
+	}
+}
+
+func TestForwardPorts(t *testing.T) {
+	var calls [][]string
+	var fail error
+	original := runCommand
+	t.Cleanup(func() { runCommand = original })
+	runCommand = func(_ context.Context, name string, args ...string) ([]byte, error) {
+		calls = append(calls, append([]string{name}, args...))
+		return []byte("HTTP 403: forbidden\n"), fail
+	}
+
+	codespace := &Environment{Kind: KindCodespaces, Name: "shiny-space-x7", Domain: "app.github.dev"}
+	if err := codespace.ForwardPorts(context.Background(), []int{5000, 3000}, VisibilityPublic); err != nil {
+		t.Fatalf("ForwardPorts() error = %v", err)
+	}
+	want := [][]string{{"gh", "codespace", "ports", "visibility", "3000:public", "5000:public", "--codespace", "shiny-space-x7"}}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("commands = %v, want %v", calls, want)
+	}
+
+	fail = errors.New("exit status 1")
+	if err := codespace.ForwardPorts(context.Background(), []int{3000}, VisibilityPrivate); err == nil || !strings.Contains(err.Error(), "HTTP 403") {
+		t.Errorf("ForwardPorts() error = %v, want the output of gh", err)
+	}
+	if err := codespace.ForwardPorts(context.Background(), []int{3000}, "everyone"); err == nil {
+		t.Error("ForwardPorts() accepted an invalid visibility")
+	}
+
+	calls = nil
+	container := &Environment{Kind: KindDevContainer}
+	if err := container.ForwardPorts(context.Background(), []int{3000}, VisibilityPrivate); err != nil || len(calls) != 0 {
+		t.Errorf("ForwardPorts() in a dev container = %v with commands %v, want nothing to do", err, calls)
+	}
+}
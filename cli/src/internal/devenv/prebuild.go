@@ -0,0 +1,117 @@
+package devenv
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Paths of the prebuild artifacts, relative to the workspace root.
+const (
+	DevcontainerFile = ".devcontainer/devcontainer.json"
+	OnCreateScript   = ".devcontainer/azd-app/on-create.sh"
+	PostCreateScript = ".devcontainer/azd-app/post-create.sh"
+)
+
+// defaultDevcontainerImage is the image of a generated devcontainer.json. It has Node.js,
+// Python, .NET, and Docker, so the detected projects can be installed without features.
+const defaultDevcontainerImage = "mcr.microsoft.com/devcontainers/universal:2"
+
+// PrebuildStep is a command a prebuild script runs in a directory of the workspace.
+type PrebuildStep struct {
+	Name    string `json:"name"`    // Project or image the step prepares
+	Dir     string `json:"dir"`     // Relative to the workspace root, with forward slashes
+	Command string `json:"command"` // Shell command
+}
+
+// Prebuild is what a codespace prebuild does before anyone opens the codespace.
+type Prebuild struct {
+	Install []PrebuildStep `json:"install"` // Dependency installs of the detected projects
+	Warm    []PrebuildStep `json:"warm"`    // Cache warming, such as pulling the base images of Dockerfiles
+}
+
+// OnCreate returns the script the devcontainer runs as onCreateCommand, which codespace
+// prebuilds run ahead of time. A failing step is reported without failing the script, so
+// that a project that can't be installed doesn't keep the codespace from being created.
+func (p Prebuild) OnCreate() string {
+	var b strings.Builder
+	b.WriteString(scriptHeader("Runs when the codespace, or its prebuild, is created: installs dependencies\n# and warms caches, so that `azd app run` starts right away."))
+	b.WriteString(`failed=0
+step() {
+	echo "==> $1: $3"
+	if ! (cd "$2" && bash -c "$3"); then
+		echo "warning: $1 failed" >&2
+		failed=1
+	fi
+}
+
+`)
+	for _, s := range p.Install {
+		fmt.Fprintf(&b, "step %s %s %s\n", shellQuote(s.Name), shellQuote(s.Dir), shellQuote(s.Command))
+	}
+	if len(p.Warm) > 0 {
+		b.WriteString("\nif command -v docker >/dev/null 2>&1; then\n")
+		for _, s := range p.Warm {
+			fmt.Fprintf(&b, "\tstep %s %s %s\n", shellQuote(s.Name), shellQuote(s.Dir), shellQuote(s.Command))
+		}
+		b.WriteString("fi\n")
+	}
+	b.WriteString(`
+if [ "$failed" -ne 0 ]; then
+	echo "Some steps failed; 'azd app run' installs what is missing." >&2
+fi
+exit 0
+`)
+	return b.String()
+}
+
+// PostCreate returns the script the devcontainer runs as postCreateCommand, once the codespace
+// is created for its user. Installs are done by then, so it only checks the requirements.
+func (p Prebuild) PostCreate() string {
+	return scriptHeader("Runs once the codespace is created for its user, after the prebuild.") + `if azd app version >/dev/null 2>&1; then
+	azd app reqs || echo "warning: requirements are missing, see above" >&2
+else
+	echo "Install the azd app extension to run the services: azd extension install jongio.azd.app"
+fi
+exit 0
+`
+}
+
+// Devcontainer returns a devcontainer.json named name that runs the prebuild scripts.
+func (p Prebuild) Devcontainer(name string) ([]byte, error) {
+	config := map[string]interface{}{
+		"name":  name,
+		"image": defaultDevcontainerImage,
+		"features": map[string]interface{}{
+			"ghcr.io/azure/azure-dev/azd:latest": map[string]interface{}{},
+		},
+		"onCreateCommand":   LifecycleCommand(OnCreateScript),
+		"postCreateCommand": LifecycleCommand(PostCreateScript),
+	}
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode devcontainer.json: %w", err)
+	}
+	return append(data, '\n'), nil
+}
+
+// LifecycleCommand returns the devcontainer.json lifecycle command that runs script, which
+// devcontainers run in the workspace root.
+func LifecycleCommand(script string) string {
+	return "bash " + script
+}
+
+// scriptHeader returns the start of a generated script, which runs in the workspace root.
+func scriptHeader(description string) string {
+	return `#!/usr/bin/env bash
+# Generated by 'azd app prebuild'. ` + description + `
+set -u
+cd "$(dirname "$0")/../.."
+
+`
+}
+
+// shellQuote quotes s as a single shell word.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,92 @@
+package devenv
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPrebuildOnCreate(t *testing.T) {
+	prebuild := Prebuild{
+		Install: []PrebuildStep{
+			{Name: "web", Dir: "src/web", Command: "npm install"},
+			{Name: "api", Dir: "src/api", Command: "python -m venv .venv && .venv/bin/pip install -r requirements.txt"},
+		},
+		Warm: []PrebuildStep{{Name: "node:20", Dir: ".", Command: "docker pull node:20"}},
+	}
+
+	script := prebuild.OnCreate()
+	for _, want := range []string{
+		"#!/usr/bin/env bash",
+		"step 'web' 'src/web' 'npm install'\n",
+		"step 'api' 'src/api' 'python -m venv .venv && .venv/bin/pip install -r requirements.txt'\n",
+		"if command -v docker >/dev/null 2>&1; then\n\tstep 'node:20' '.' 'docker pull node:20'\nfi\n",
+		"exit 0\n",
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("OnCreate() is missing %q:\n%s", want, script)
+		}
+	}
+	if strings.Contains((Prebuild{}).OnCreate(), "command -v docker") {
+		t.Error("OnCreate() checks for docker without images to pull")
+	}
+}
+
+func TestPrebuildScriptsRun(t *testing.T) {
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash unavailable")
+	}
+
+	// The scripts run from the workspace root, two levels above them
+	workspace := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(workspace, "web"), 0750); err != nil {
+		t.Fatal(err)
+	}
+	prebuild := Prebuild{Install: []PrebuildStep{
+		{Name: "web", Dir: "web", Command: `echo "it's installed" > installed.txt`},
+		{Name: "broken", Dir: "web", Command: "exit 7"},
+	}}
+	script := filepath.Join(workspace, filepath.FromSlash(OnCreateScript))
+	if err := os.MkdirAll(filepath.Dir(script), 0750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(script, []byte(prebuild.OnCreate()), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := exec.Command(bash, script).CombinedOutput()
+	if err != nil {
+		t.Fatalf("on-create.sh failed: %v\n%s", err, out)
+	}
+	if !strings.Contains(string(out), "warning: broken failed") {
+		t.Errorf("on-create.sh output does not report the failed step:\n%s", out)
+	}
+	data, err := os.ReadFile(filepath.Join(workspace, "web", "installed.txt"))
+	if err != nil || strings.TrimSpace(string(data)) != "it's installed" {
+		t.Errorf("install step did not run in its directory: %q, %v", data, err)
+	}
+}
+
+func TestPrebuildDevcontainer(t *testing.T) {
+	data, err := (Prebuild{}).Devcontainer("demo")
+	if err != nil {
+		t.Fatalf("Devcontainer() error = %v", err)
+	}
+	var config map[string]interface{}
+	if err := json.Unmarshal(data, &config); err != nil {
+		t.Fatalf("Devcontainer() is not JSON: %v\n%s", err, data)
+	}
+	if config["name"] != "demo" {
+		t.Errorf("name = %v, want demo", config["name"])
+	}
+	if config["onCreateCommand"] != "bash .devcontainer/azd-app/on-create.sh" {
+		t.Errorf("onCreateCommand = %v", config["onCreateCommand"])
+	}
+	if config["postCreateCommand"] != "bash .devcontainer/azd-app/post-create.sh" {
+		t.Errorf("postCreateCommand = %v", config["postCreateCommand"])
+	}
+}
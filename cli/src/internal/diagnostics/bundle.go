@@ -0,0 +1,167 @@
+// Package diagnostics writes diagnostics bundles: zip archives with the environment report,
+// configuration, service registry, and recent service logs of a workspace, for attaching to
+// bug reports. Values that look like secrets are redacted from every file.
+package diagnostics
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jongio/azd-app/cli/src/internal/registry"
+	"github.com/jongio/azd-app/cli/src/internal/security"
+	"github.com/jongio/azd-app/cli/src/internal/statedir"
+)
+
+// dataKind names the workspace data directory that holds generated bundles (see statedir.DataDir).
+const dataKind = "diagnostics"
+
+// DefaultLogLines is the number of lines kept from the end of each service log.
+const DefaultLogLines = 500
+
+// Options configures a bundle.
+type Options struct {
+	ProjectDir string      // Workspace whose configuration, registry, and logs are included
+	Report     interface{} // Written as report.json
+	LogLines   int         // Lines kept from the end of each log; zero uses DefaultLogLines
+}
+
+// DefaultPath returns the path of a new bundle in the workspace state of projectDir.
+func DefaultPath(projectDir string, now time.Time) (string, error) {
+	dir, err := statedir.DataDir(projectDir, dataKind)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "azd-app-diagnostics-"+now.Format("20060102-150405")+".zip"), nil
+}
+
+// Write creates the bundle at path and returns the names of the files it contains.
+// Files that do not exist, such as the registry of a workspace that never ran, are skipped.
+func Write(path string, opts Options) ([]string, error) {
+	if opts.LogLines == 0 {
+		opts.LogLines = DefaultLogLines
+	}
+	if err := security.ValidatePath(path); err != nil {
+		return nil, fmt.Errorf("invalid bundle path: %w", err)
+	}
+
+	// #nosec G304 -- Path validated by security.ValidatePath
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create bundle: %w", err)
+	}
+	archive := zip.NewWriter(file)
+
+	var names []string
+	add := func(name string, data []byte) error {
+		w, err := archive.Create(name)
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
+		names = append(names, name)
+		return nil
+	}
+
+	err = writeEntries(opts, add)
+	if closeErr := archive.Close(); err == nil {
+		err = closeErr
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(path)
+		return nil, fmt.Errorf("failed to write bundle: %w", err)
+	}
+	return names, nil
+}
+
+// writeEntries adds the report, configuration, registry, and logs of a bundle, redacted.
+func writeEntries(opts Options, add func(name string, data []byte) error) error {
+	report, err := json.MarshalIndent(opts.Report, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := add("report.json", redactText(report)); err != nil {
+		return err
+	}
+
+	if opts.ProjectDir == "" {
+		return nil
+	}
+	type file struct {
+		name, path string
+		redact     func([]byte) []byte
+	}
+	files := []file{{"azure.yaml", filepath.Join(opts.ProjectDir, "azure.yaml"), redactYAML}}
+	if path, err := registry.Path(opts.ProjectDir); err == nil {
+		files = append(files, file{"registry/services.json", path, redactText})
+	}
+	for _, f := range files {
+		// #nosec G304 -- Fixed files of the workspace and its state directory
+		data, err := os.ReadFile(f.path)
+		if err != nil {
+			continue
+		}
+		if err := add(f.name, f.redact(data)); err != nil {
+			return err
+		}
+	}
+
+	ws, err := statedir.Get(opts.ProjectDir)
+	if err != nil {
+		return nil
+	}
+	for _, session := range ws.ListSessions() {
+		logs, _ := filepath.Glob(filepath.Join(session.Dir, "logs", "*.log"))
+		sort.Strings(logs)
+		for _, path := range logs {
+			data, err := tail(path, opts.LogLines)
+			if err != nil {
+				continue
+			}
+			name := "logs/" + strconv.Itoa(session.PID) + "/" + filepath.Base(path)
+			if err := add(name, redactText(data)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// tail returns the last n lines of the file at path.
+func tail(path string, n int) ([]byte, error) {
+	// #nosec G304 -- Log files in the workspace state directory
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	lines := make([]string, 0, n)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if len(lines) == n {
+			lines = lines[1:]
+		}
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(lines) == 0 {
+		return nil, nil
+	}
+	return []byte(strings.Join(lines, "\n") + "\n"), nil
+}
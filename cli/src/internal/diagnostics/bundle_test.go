@@ -0,0 +1,145 @@
+package diagnostics
+
+import (
+	"archive/zip"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jongio/azd-app/cli/src/internal/registry"
+	"github.com/jongio/azd-app/cli/src/internal/statedir"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("failed to write %s: %v", path, err)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	t.Setenv(statedir.RootEnvVar, t.TempDir())
+	projectDir := t.TempDir()
+	writeFile(t, filepath.Join(projectDir, "azure.yaml"), "name: app\n")
+	registryPath, err := registry.Path(projectDir)
+	if err != nil {
+		t.Fatalf("registry.Path failed: %v", err)
+	}
+	writeFile(t, registryPath, `{"api": {"name": "api"}}`)
+	sessionDir, err := statedir.SessionDir(projectDir)
+	if err != nil {
+		t.Fatalf("SessionDir failed: %v", err)
+	}
+	writeFile(t, filepath.Join(sessionDir, "logs", "api.log"), "one\ntwo\nthree\n")
+
+	path, err := DefaultPath(projectDir, time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("DefaultPath failed: %v", err)
+	}
+	if filepath.Base(path) != "azd-app-diagnostics-20260102-030405.zip" {
+		t.Errorf("DefaultPath() = %s", path)
+	}
+
+	names, err := Write(path, Options{ProjectDir: projectDir, Report: map[string]string{"version": "1.2.3"}, LogLines: 2})
+	if err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	logName := "logs/" + strconv.Itoa(os.Getpid()) + "/api.log"
+	want := map[string]string{
+		"report.json":            `"version": "1.2.3"`,
+		"azure.yaml":             "name: app",
+		"registry/services.json": `"api"`,
+		logName:                  "two\nthree\n",
+	}
+	if len(names) != len(want) {
+		t.Errorf("Write() names = %v, want %d files", names, len(want))
+	}
+
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		t.Fatalf("failed to open bundle: %v", err)
+	}
+	defer archive.Close()
+	for _, file := range archive.File {
+		content, ok := want[file.Name]
+		if !ok {
+			t.Errorf("unexpected file %s in bundle", file.Name)
+			continue
+		}
+		r, err := file.Open()
+		if err != nil {
+			t.Fatalf("failed to open %s: %v", file.Name, err)
+		}
+		data, _ := io.ReadAll(r)
+		_ = r.Close()
+		if !strings.Contains(string(data), content) {
+			t.Errorf("%s = %q, want it to contain %q", file.Name, data, content)
+		}
+		if file.Name == logName && strings.Contains(string(data), "one") {
+			t.Errorf("%s should only keep the last lines, got %q", file.Name, data)
+		}
+	}
+}
+
+func TestWriteWithoutProject(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bundle.zip")
+	names, err := Write(path, Options{Report: struct{}{}})
+	if err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if len(names) != 1 || names[0] != "report.json" {
+		t.Errorf("Write() names = %v, want only report.json", names)
+	}
+}
+
+func TestRedactText(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"API_KEY=abc123", "API_KEY=[redacted]"},
+		{`{"password": "hunter2", "port": 8080}`, `{"password": "[redacted]", "port": 8080}`},
+		{"Server=db;User Id=sa;Password=p@ss;", "Server=db;User Id=sa;Password=[redacted];"},
+		{"GET /api?access_token=xyz&page=2", "GET /api?access_token=[redacted]&page=2"},
+		{"Authorization: Bearer eyJhbGciOi.abc", "Authorization: Bearer [redacted]"},
+		{"listening on http://localhost:3000", "listening on http://localhost:3000"},
+	}
+	for _, tt := range tests {
+		if got := string(redactText([]byte(tt.in))); got != tt.want {
+			t.Errorf("redactText(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestRedactYAML(t *testing.T) {
+	in := `name: app
+services:
+  api:
+    project: ./api
+    env:
+      - name: DB_PASSWORD
+        value: two words
+      - name: LOG_LEVEL
+        value: debug
+    config:
+      clientSecret: s3cret
+`
+	got := string(redactYAML([]byte(in)))
+	for _, secret := range []string{"two words", "s3cret"} {
+		if strings.Contains(got, secret) {
+			t.Errorf("redactYAML() kept %q:\n%s", secret, got)
+		}
+	}
+	for _, kept := range []string{"name: app", "project: ./api", "name: DB_PASSWORD", "value: debug"} {
+		if !strings.Contains(got, kept) {
+			t.Errorf("redactYAML() lost %q:\n%s", kept, got)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package diagnostics
+
+import (
+	"bytes"
+	"regexp"
+
+	"github.com/jongio/azd-app/cli/src/internal/envhistory"
+
+	"gopkg.in/yaml.v3"
+)
+
+// redacted replaces the values of secrets in the files of a bundle.
+const redacted = "[redacted]"
+
+// assignmentPattern matches assignments such as KEY=value, key: value, and "key": "value",
+// including those in connection strings and query strings.
+var assignmentPattern = regexp.MustCompile(`"?([A-Za-z_][A-Za-z0-9_.-]*)"?[ \t]*[:=][ \t]*("[^"\n]*"|'[^'\n]*'|[^\s"',;&]+)`)
+
+// bearerPattern matches bearer tokens, e.g. in a logged Authorization header.
+var bearerPattern = regexp.MustCompile(`(?i)\b(bearer[ \t]+)[A-Za-z0-9._~+/=-]+`)
+
+// redactText replaces the values assigned to secret-looking keys (see envhistory.IsSecretKey)
+// and bearer tokens in data, such as a log.
+func redactText(data []byte) []byte {
+	var out bytes.Buffer
+	last := 0
+	for _, m := range assignmentPattern.FindAllSubmatchIndex(data, -1) {
+		if !envhistory.IsSecretKey(string(data[m[2]:m[3]])) {
+			continue
+		}
+		value := data[m[4]:m[5]]
+		out.Write(data[last:m[4]])
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') {
+			out.WriteByte(value[0])
+			out.WriteString(redacted)
+			out.WriteByte(value[len(value)-1])
+		} else {
+			out.WriteString(redacted)
+		}
+		last = m[5]
+	}
+	out.Write(data[last:])
+	return bearerPattern.ReplaceAll(out.Bytes(), []byte("${1}"+redacted))
+}
+
+// redactYAML redacts the values of secret-looking keys in a YAML document, such as azure.yaml,
+// including the value of an env entry whose name looks secret. Text that isn't valid YAML is
+// redacted as text.
+func redactYAML(data []byte) []byte {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return redactText(data)
+	}
+	redactNode(&doc)
+	var out bytes.Buffer
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return redactText(data)
+	}
+	_ = encoder.Close()
+	return redactText(out.Bytes())
+}
+
+// redactNode redacts the secret scalars of n and its children.
+func redactNode(n *yaml.Node) {
+	if n.Kind == yaml.MappingNode {
+		secretName := false
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if key, value := n.Content[i], n.Content[i+1]; key.Value == "name" && value.Kind == yaml.ScalarNode {
+				secretName = envhistory.IsSecretKey(value.Value)
+			}
+		}
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key, value := n.Content[i], n.Content[i+1]
+			if value.Kind == yaml.ScalarNode && (envhistory.IsSecretKey(key.Value) || secretName && key.Value == "value") {
+				value.Value = redacted
+				value.Style = 0
+			}
+		}
+	}
+	for _, child := range n.Content {
+		redactNode(child)
+	}
+}
@@ -0,0 +1,372 @@
+// Package doctor checks that the tools the projects of a workspace need are installed, in the
+// versions the projects pin: node and its package manager from the engines of package.json,
+// .nvmrc, or .tool-versions, the .NET SDK from global.json or the target frameworks, Python
+// from requires-python or .python-version, a running container engine for Dockerfiles, Azure
+// Functions Core Tools, and azd.
+package doctor
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jongio/azd-app/cli/src/internal/detector"
+	"github.com/jongio/azd-app/cli/src/internal/guide"
+	"github.com/jongio/azd-app/cli/src/internal/types"
+)
+
+// Statuses of checks.
+const (
+	StatusOK         = "ok"
+	StatusMissing    = "missing"     // The tool isn't installed
+	StatusMismatch   = "mismatch"    // No installed version satisfies the constraint
+	StatusNotRunning = "not-running" // The tool is installed, but its daemon can't be reached
+	StatusUnchecked  = "unchecked"   // The constraint or the installed version can't be parsed
+)
+
+// Tools the checks know.
+const (
+	ToolAzd    = "azd"
+	ToolNode   = "node"
+	ToolDotnet = "dotnet"
+	ToolPython = "python"
+	ToolFunc   = "func"
+	ToolDocker = "docker" // A container engine: docker, or podman when it's the one configured
+)
+
+// Requirement is a tool the workspace needs.
+type Requirement struct {
+	Tool        string `json:"tool"`
+	Constraint  string `json:"constraint,omitempty"`  // Version constraint, e.g. ">=18"; "" for any version
+	RollForward string `json:"rollForward,omitempty"` // .NET SDK roll-forward policy of global.json
+	Source      string `json:"source,omitempty"`      // File that requires the tool or pins its version, relative to the workspace
+}
+
+// Check is the outcome of checking a requirement.
+type Check struct {
+	Requirement
+	Status  string `json:"status"`
+	Version string `json:"version,omitempty"` // Installed version, or the engine that runs
+	Message string `json:"message,omitempty"`
+	Fix     string `json:"fix,omitempty"` // How to install or update the tool
+}
+
+// Failed reports whether the check fails the workspace.
+func (c Check) Failed() bool {
+	return c.Status != StatusOK && c.Status != StatusUnchecked
+}
+
+// ReportSchemaVersion is the version of Report, raised when a change would break its readers.
+const ReportSchemaVersion = 1
+
+// Report is the outcome of every check of a workspace.
+type Report struct {
+	SchemaVersion int     `json:"schemaVersion"`
+	Checks        []Check `json:"checks"`
+	Failed        int     `json:"failed"`
+}
+
+// Toolchain finds the installed tools; tests replace it.
+type Toolchain struct {
+	// Version returns the version of tool, and whether it is installed
+	Version func(tool string) (string, bool)
+	// DotnetSDKs returns the versions of the installed .NET SDKs
+	DotnetSDKs func() []string
+	// ContainerEngine returns the configured container engine, and an error when it isn't
+	// installed or can't be reached
+	ContainerEngine func() (string, error)
+}
+
+// Run checks the tools the workspace at rootDir needs.
+func Run(rootDir string, toolchain Toolchain) (Report, error) {
+	scan, err := detector.ScanWorkspace(rootDir)
+	if err != nil {
+		return Report{}, fmt.Errorf("failed to scan workspace: %w", err)
+	}
+	report := Report{SchemaVersion: ReportSchemaVersion, Checks: []Check{}}
+	toolchain = Cached(toolchain)
+	for _, req := range Requirements(scan) {
+		check := checkRequirement(req, toolchain)
+		if check.Failed() {
+			report.Failed++
+		}
+		report.Checks = append(report.Checks, check)
+	}
+	return report, nil
+}
+
+// Requirements returns the tools the projects of scan need, with each version their files pin
+// (see detector.NodeVersionPins and its siblings), in the order of the tools: azd, then the
+// tools of each language, then the container engine. A tool several projects pin alike is
+// required once.
+func Requirements(scan *detector.WorkspaceScan) []Requirement {
+	reqs := []Requirement{{Tool: ToolAzd}}
+	seen := map[Requirement]bool{reqs[0]: true}
+	add := func(req Requirement) {
+		if !seen[req] {
+			seen[req] = true
+			reqs = append(reqs, req)
+		}
+	}
+
+	for _, p := range scan.NodeProjects {
+		source := relativeTo(scan.Root, filepath.Join(p.Dir, "package.json"))
+		for _, tool := range []string{ToolNode, p.PackageManager} {
+			for _, req := range pinnedRequirements(scan.Root, tool, source, p.VersionPins) {
+				add(req)
+			}
+		}
+	}
+	for _, req := range dotnetRequirements(scan) {
+		add(req)
+	}
+	for _, p := range scan.PythonProjects {
+		for _, req := range pinnedRequirements(scan.Root, ToolPython, relativeTo(scan.Root, p.Dir), p.VersionPins) {
+			add(req)
+		}
+	}
+	if len(scan.Functions) > 0 {
+		add(Requirement{Tool: ToolFunc, Source: relativeTo(scan.Root, filepath.Join(scan.Functions[0].Dir, "host.json"))})
+	}
+	if len(scan.Dockerfiles) > 0 {
+		add(Requirement{Tool: ToolDocker, Source: relativeTo(scan.Root, scan.Dockerfiles[0].Path)})
+	}
+	return reqs
+}
+
+// pinnedRequirements returns a requirement of tool for each of pins that pins it, or else one
+// for any version of it, required by source.
+func pinnedRequirements(rootDir, tool, source string, pins []types.VersionPin) []Requirement {
+	if tool == "" {
+		return nil
+	}
+	var reqs []Requirement
+	for _, pin := range pins {
+		if pin.Tool == tool {
+			reqs = append(reqs, pinRequirement(rootDir, pin))
+		}
+	}
+	if len(reqs) == 0 {
+		reqs = append(reqs, Requirement{Tool: tool, Source: source})
+	}
+	return reqs
+}
+
+// pinRequirement returns the requirement of pin, with its source relative to rootDir.
+func pinRequirement(rootDir string, pin types.VersionPin) Requirement {
+	return Requirement{Tool: pin.Tool, Constraint: pin.Constraint, RollForward: pin.RollForward, Source: relativeTo(rootDir, pin.Source)}
+}
+
+// dotnetRequirements returns the .NET SDK the projects need: the versions their files pin, or
+// else at least the major version of the newest framework the others target.
+func dotnetRequirements(scan *detector.WorkspaceScan) []Requirement {
+	var reqs []Requirement
+	newest, newestProject := 0, ""
+	for _, p := range scan.DotnetProjects {
+		if len(p.VersionPins) > 0 {
+			for _, pin := range p.VersionPins {
+				reqs = append(reqs, pinRequirement(scan.Root, pin))
+			}
+			continue
+		}
+		if major := newestTargetFramework(p); major > newest {
+			newest, newestProject = major, p.Path
+		}
+	}
+	if newest > 0 {
+		reqs = append(reqs, Requirement{Tool: ToolDotnet, Constraint: fmt.Sprintf(">=%d.0", newest), Source: relativeTo(scan.Root, newestProject)})
+	} else if len(reqs) == 0 && (len(scan.DotnetProjects) > 0 || hasDotnetFunctions(scan.Functions)) {
+		source := scan.Root
+		if len(scan.DotnetProjects) > 0 {
+			source = scan.DotnetProjects[0].Path
+		}
+		reqs = append(reqs, Requirement{Tool: ToolDotnet, Source: relativeTo(scan.Root, source)})
+	}
+	return reqs
+}
+
+// hasDotnetFunctions reports whether any of functions runs on .NET.
+func hasDotnetFunctions(functions []types.FunctionProject) bool {
+	for _, f := range functions {
+		if strings.HasPrefix(f.Runtime, "dotnet") {
+			return true
+		}
+	}
+	return false
+}
+
+// newestTargetFramework returns the major version of the newest .NET framework p targets,
+// such as 9 for net9.0, or 0 when it targets none.
+func newestTargetFramework(p types.DotnetProject) int {
+	newest := 0
+	for _, tfm := range p.TargetFrameworks {
+		// net5.0 and later; netcoreapp3.1 and net48 aren't built by a current SDK alone
+		major, _, ok := strings.Cut(strings.TrimPrefix(tfm, "net"), ".")
+		if !ok || !strings.HasPrefix(tfm, "net") || strings.HasPrefix(tfm, "netcoreapp") || strings.HasPrefix(tfm, "netstandard") {
+			continue
+		}
+		if n, err := strconv.Atoi(major); err == nil && n > newest {
+			newest = n
+		}
+	}
+	return newest
+}
+
+// CheckPins checks the tools installed against pins, whose sources are shown relative to
+// rootDir.
+func CheckPins(rootDir string, pins []types.VersionPin, toolchain Toolchain) []Check {
+	checks := make([]Check, 0, len(pins))
+	for _, pin := range pins {
+		checks = append(checks, checkRequirement(pinRequirement(rootDir, pin), toolchain))
+	}
+	return checks
+}
+
+// Cached returns toolchain, asking for the version of each tool, the .NET SDKs, and the
+// container engine only once.
+func Cached(toolchain Toolchain) Toolchain {
+	type version struct {
+		version   string
+		installed bool
+	}
+	versions := make(map[string]version)
+	var sdks []string
+	sdksListed := false
+	return Toolchain{
+		Version: func(tool string) (string, bool) {
+			v, ok := versions[tool]
+			if !ok {
+				v.version, v.installed = toolchain.Version(tool)
+				versions[tool] = v
+			}
+			return v.version, v.installed
+		},
+		DotnetSDKs: func() []string {
+			if !sdksListed {
+				sdks, sdksListed = toolchain.DotnetSDKs(), true
+			}
+			return sdks
+		},
+		ContainerEngine: toolchain.ContainerEngine,
+	}
+}
+
+// checkRequirement checks req against the installed tools.
+func checkRequirement(req Requirement, toolchain Toolchain) Check {
+	check := Check{Requirement: req, Status: StatusOK}
+	switch req.Tool {
+	case ToolDocker:
+		engine, err := toolchain.ContainerEngine()
+		check.Version = engine
+		if err != nil {
+			check.Status, check.Message = StatusNotRunning, err.Error()
+			if strings.Contains(err.Error(), "not installed") {
+				check.Status = StatusMissing
+				check.Fix = guide.InstallCommand(ToolDocker)
+			} else {
+				check.Fix = fmt.Sprintf("start %s, e.g. Docker Desktop or the docker service", engine)
+			}
+		}
+		return check
+	case ToolDotnet:
+		return checkDotnet(check, toolchain.DotnetSDKs())
+	}
+
+	installed, ok := toolchain.Version(req.Tool)
+	if !ok {
+		check.Status, check.Message = StatusMissing, req.Tool+" is not installed"
+		check.Fix = guide.InstallCommand(req.Tool)
+		return check
+	}
+	check.Version = installed
+	if req.Constraint == "" {
+		return check
+	}
+	satisfied, err := Satisfies(installed, req.Constraint)
+	if err != nil {
+		check.Status, check.Message = StatusUnchecked, err.Error()
+		return check
+	}
+	if !satisfied {
+		check.Status = StatusMismatch
+		check.Message = fmt.Sprintf("%s %s doesn't satisfy %s", req.Tool, installed, req.Constraint)
+		check.Fix = updateHint(req)
+	}
+	return check
+}
+
+// checkDotnet checks that one of the installed .NET SDKs satisfies the requirement of check.
+// The version of the check is the SDK that does, or else the newest one.
+func checkDotnet(check Check, sdks []string) Check {
+	if len(sdks) == 0 {
+		check.Status, check.Message = StatusMissing, "no .NET SDK is installed"
+		check.Fix = guide.InstallCommand(ToolDotnet)
+		return check
+	}
+	sort.Slice(sdks, func(i, j int) bool {
+		a, _ := parseVersion(sdks[i])
+		b, _ := parseVersion(sdks[j])
+		return compare(a, b, 3) > 0
+	})
+	check.Version = sdks[0]
+	if check.Constraint == "" {
+		return check
+	}
+
+	var lastErr error
+	for _, sdk := range sdks {
+		var satisfied bool
+		var err error
+		if check.RollForward != "" || strings.HasSuffix(check.Source, "global.json") {
+			satisfied, err = SDKSatisfies(sdk, check.Constraint, check.RollForward)
+		} else {
+			satisfied, err = Satisfies(sdk, check.Constraint)
+		}
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if satisfied {
+			check.Version = sdk
+			return check
+		}
+	}
+	if lastErr != nil {
+		check.Status, check.Message = StatusUnchecked, lastErr.Error()
+		return check
+	}
+	check.Status = StatusMismatch
+	check.Message = fmt.Sprintf("no installed .NET SDK (%s) satisfies %s", strings.Join(sdks, ", "), check.Constraint)
+	if check.RollForward != "" {
+		check.Message += " with rollForward " + check.RollForward
+	}
+	check.Fix = updateHint(check.Requirement)
+	return check
+}
+
+// updateHint returns how to get a version of the tool of req that satisfies its constraint.
+func updateHint(req Requirement) string {
+	switch req.Tool {
+	case ToolNode:
+		return fmt.Sprintf("install node %s, e.g. with nvm or fnm, or change %s", req.Constraint, req.Source)
+	case ToolDotnet:
+		return fmt.Sprintf("install .NET SDK %s from https://dot.net/download, or change %s", req.Constraint, req.Source)
+	case ToolPython:
+		return fmt.Sprintf("install Python %s, e.g. with pyenv or 'uv python install', or change %s", req.Constraint, req.Source)
+	case "npm":
+		return fmt.Sprintf("npm install -g npm@'%s'", req.Constraint)
+	case "pnpm", "yarn":
+		return fmt.Sprintf("corepack enable, or npm install -g %s@'%s'", req.Tool, req.Constraint)
+	}
+	return fmt.Sprintf("install %s %s", req.Tool, req.Constraint)
+}
+
+// relativeTo returns path relative to dir, with forward slashes, or path when it isn't below dir.
+func relativeTo(dir, path string) string {
+	if rel, err := filepath.Rel(dir, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(rel)
+	}
+	return path
+}
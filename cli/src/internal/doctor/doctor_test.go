@@ -0,0 +1,136 @@
+package doctor
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/jongio/azd-app/cli/src/internal/types"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// fakeToolchain has the tools of versions installed, the .NET SDKs sdks, and a container
+// engine that fails with engineErr.
+func fakeToolchain(versions map[string]string, sdks []string, engineErr error) Toolchain {
+	return Toolchain{
+		Version: func(tool string) (string, bool) {
+			v, ok := versions[tool]
+			return v, ok
+		},
+		DotnetSDKs:      func() []string { return sdks },
+		ContainerEngine: func() (string, error) { return "docker", engineErr },
+	}
+}
+
+func TestRun(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "web", "package.json"), `{"name": "web", "engines": {"node": ">=20", "npm": ">=10"}, "scripts": {"dev": "vite"}}`)
+	writeFile(t, filepath.Join(root, "global.json"), "\xef\xbb\xbf"+`{"sdk": {"version": "8.0.100", "rollForward": "latestFeature"}}`)
+	writeFile(t, filepath.Join(root, "api", "Api.csproj"), `<Project Sdk="Microsoft.NET.Sdk.Web"><PropertyGroup><TargetFramework>net8.0</TargetFramework></PropertyGroup></Project>`)
+	writeFile(t, filepath.Join(root, "worker", "pyproject.toml"), "[project]\nname = \"worker\"\nrequires-python = \">=3.11\"\n")
+	writeFile(t, filepath.Join(root, "worker", "Dockerfile"), "FROM python:3.12-slim\n")
+
+	report, err := Run(root, fakeToolchain(map[string]string{"azd": "1.11.0", "node": "18.19.0", "npm": "10.2.3"}, []string{"6.0.400", "8.0.303"}, errors.New("cannot reach the docker engine")))
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	statuses := make(map[string]Check)
+	for _, c := range report.Checks {
+		statuses[c.Tool] = c
+	}
+	want := map[string]string{
+		ToolAzd:    StatusOK,
+		ToolNode:   StatusMismatch,
+		"npm":      StatusOK,
+		ToolDotnet: StatusOK,
+		ToolPython: StatusMissing,
+		ToolDocker: StatusNotRunning,
+	}
+	for tool, status := range want {
+		if got := statuses[tool]; got.Status != status {
+			t.Errorf("%s = %+v, want %s", tool, got, status)
+		}
+	}
+	if len(report.Checks) != len(want) || report.Failed != 3 {
+		t.Errorf("report = %+v, want %d checks with 3 failed", report, len(want))
+	}
+	if dotnet := statuses[ToolDotnet]; dotnet.Version != "8.0.303" || dotnet.Source != "global.json" {
+		t.Errorf("dotnet = %+v, want 8.0.303 for global.json", dotnet)
+	}
+	if node := statuses[ToolNode]; node.Source != "web/package.json" || node.Fix == "" {
+		t.Errorf("node = %+v, want a fix for web/package.json", node)
+	}
+}
+
+func TestRunTargetFramework(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "Api", "Api.csproj"), `<Project Sdk="Microsoft.NET.Sdk.Web"><PropertyGroup><TargetFrameworks>net8.0;net9.0</TargetFrameworks></PropertyGroup></Project>`)
+
+	report, err := Run(root, fakeToolchain(map[string]string{"azd": "1.11.0"}, []string{"8.0.303"}, nil))
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if len(report.Checks) != 2 {
+		t.Fatalf("checks = %+v, want azd and dotnet", report.Checks)
+	}
+	dotnet := report.Checks[1]
+	if dotnet.Constraint != ">=9.0" || dotnet.Status != StatusMismatch || dotnet.Source != "Api/Api.csproj" {
+		t.Errorf("dotnet = %+v, want a mismatch with >=9.0 from Api/Api.csproj", dotnet)
+	}
+}
+
+func TestRunUnchecked(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "package.json"), `{"name": "web", "engines": {"node": "lts/*"}}`)
+
+	report, err := Run(root, fakeToolchain(map[string]string{"azd": "1.11.0", "node": "20.0.0", "npm": "10.0.0"}, nil, nil))
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if report.Failed != 0 || report.Checks[1].Status != StatusUnchecked {
+		t.Errorf("report = %+v, want node unchecked without failures", report)
+	}
+}
+
+func TestCheckPins(t *testing.T) {
+	root := t.TempDir()
+	pins := []types.VersionPin{
+		{Tool: ToolNode, Constraint: "20.11.1", Source: filepath.Join(root, ".nvmrc")},
+		{Tool: ToolNode, Constraint: ">=18", Source: filepath.Join(root, "web", "package.json")},
+		{Tool: ToolPython, Constraint: "3.12", Source: filepath.Join(root, ".tool-versions")},
+	}
+	calls := 0
+	toolchain := fakeToolchain(map[string]string{"node": "20.11.1"}, nil, nil)
+	version := toolchain.Version
+	toolchain.Version = func(tool string) (string, bool) {
+		calls++
+		return version(tool)
+	}
+
+	checks := CheckPins(root, pins, Cached(toolchain))
+	var statuses []string
+	for _, c := range checks {
+		statuses = append(statuses, c.Status)
+	}
+	if want := []string{StatusOK, StatusOK, StatusMissing}; !reflect.DeepEqual(statuses, want) {
+		t.Errorf("statuses = %v, want %v", statuses, want)
+	}
+	if checks[0].Source != ".nvmrc" || checks[1].Source != "web/package.json" {
+		t.Errorf("sources = %q, %q, want them relative to the root", checks[0].Source, checks[1].Source)
+	}
+	if calls != 2 {
+		t.Errorf("Version called %d times, want once per tool", calls)
+	}
+}
@@ -0,0 +1,216 @@
+package doctor
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// version is a parsed version. Parts holds the numbers given, so "18" has one part and
+// matches every 18.x.y release.
+type version []int
+
+// parseVersion parses a version such as "v20.11.1", "3.12", or "9.0.100-preview.1". Only the
+// major, minor, and patch numbers count; pre-release and build suffixes are dropped.
+func parseVersion(s string) (version, bool) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "v")
+	if i := strings.IndexAny(s, "-+"); i >= 0 {
+		s = s[:i]
+	}
+	if s == "" {
+		return nil, false
+	}
+	var v version
+	for _, part := range strings.Split(s, ".") {
+		if len(v) == 3 {
+			break
+		}
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return nil, false
+		}
+		v = append(v, n)
+	}
+	return v, true
+}
+
+// compare compares the first n parts of a and b, where missing parts are 0.
+func compare(a, b version, n int) int {
+	for i := 0; i < n; i++ {
+		var x, y int
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// hasPrefix reports whether v starts with the parts of prefix, as 18.2.1 starts with 18.
+func hasPrefix(v, prefix version) bool {
+	return compare(v, prefix, len(prefix)) == 0
+}
+
+// Satisfies reports whether the version installed satisfies constraint, which is written as
+// npm engines, PEP 440 requires-python, or .python-version write them: ">=18", "^20.11",
+// "~3.11", ">=3.10,<3.13", "~=3.11", "==3.12.*", "18 || 20", "1.2 - 2.3", or a bare version,
+// which matches every release it is a prefix of. An empty constraint, "*", and "x" match any
+// version. It fails when version or constraint can't be parsed.
+func Satisfies(installed, constraint string) (bool, error) {
+	v, ok := parseVersion(installed)
+	if !ok {
+		return false, fmt.Errorf("invalid version %q", installed)
+	}
+	for _, alternative := range strings.Split(constraint, "||") {
+		ok, err := satisfiesAll(v, alternative)
+		if err != nil {
+			return false, fmt.Errorf("invalid version constraint %q: %w", constraint, err)
+		}
+		if ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// satisfiesAll reports whether v satisfies every comparison of constraint.
+func satisfiesAll(v version, constraint string) (bool, error) {
+	if lo, hi, ok := strings.Cut(constraint, " - "); ok {
+		constraint = ">=" + strings.TrimSpace(lo) + " <=" + strings.TrimSpace(hi)
+	}
+	for _, term := range constraintTerms(constraint) {
+		ok, err := satisfiesTerm(v, term)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// constraintTerms splits constraint into its comparisons, which are separated by commas or
+// spaces, joining operators written apart from their version, as in ">= 18".
+func constraintTerms(constraint string) []string {
+	var terms []string
+	pending := ""
+	for _, field := range strings.Fields(strings.ReplaceAll(constraint, ",", " ")) {
+		if strings.Trim(field, "<>=!~^") == "" {
+			pending += field
+			continue
+		}
+		terms = append(terms, pending+field)
+		pending = ""
+	}
+	if pending != "" {
+		terms = append(terms, pending)
+	}
+	return terms
+}
+
+// satisfiesTerm reports whether v satisfies a single comparison, such as ">=18" or "^3.11".
+func satisfiesTerm(v version, term string) (bool, error) {
+	op := term[:len(term)-len(strings.TrimLeft(term, "<>=!~^"))]
+	bound := strings.TrimSpace(term[len(op):])
+	// Wildcards end the version: 3.12.* and 18.x are 3.12 and 18
+	for _, wildcard := range []string{".*", ".x", ".X"} {
+		for strings.HasSuffix(bound, wildcard) {
+			bound = strings.TrimSuffix(bound, wildcard)
+		}
+	}
+	if bound == "*" || bound == "x" || bound == "X" || bound == "" {
+		if op == "" || op == "=" || op == "==" || op == ">=" {
+			return true, nil
+		}
+		return false, fmt.Errorf("no version in %q", term)
+	}
+	b, ok := parseVersion(bound)
+	if !ok {
+		return false, fmt.Errorf("invalid version in %q", term)
+	}
+
+	switch op {
+	case "", "=", "==", "===":
+		return hasPrefix(v, b), nil
+	case "!=":
+		return !hasPrefix(v, b), nil
+	case ">=":
+		return compare(v, b, 3) >= 0, nil
+	case "<":
+		return compare(v, b, 3) < 0, nil
+	case ">":
+		// >1.2 excludes every 1.2.x release
+		return compare(v, b, len(b)) > 0, nil
+	case "<=":
+		// <=1.2 includes every 1.2.x release
+		return compare(v, b, len(b)) <= 0, nil
+	case "~":
+		// ~1.2.3 and ~1.2 allow patch releases, ~1 minor releases
+		return compare(v, b, 3) >= 0 && hasPrefix(v, b[:min(len(b), 2)]), nil
+	case "~=":
+		// ~=3.11 allows 3.x from 3.11, ~=3.11.2 allows 3.11.x from 3.11.2
+		if len(b) < 2 {
+			return false, fmt.Errorf("%q needs at least two version numbers", term)
+		}
+		return compare(v, b, 3) >= 0 && hasPrefix(v, b[:len(b)-1]), nil
+	case "^":
+		// ^ allows releases that don't change the first non-zero number
+		keep := len(b)
+		for i, n := range b {
+			if n != 0 {
+				keep = i + 1
+				break
+			}
+		}
+		return compare(v, b, 3) >= 0 && hasPrefix(v, b[:keep]), nil
+	}
+	return false, fmt.Errorf("unknown operator %q", op)
+}
+
+// Roll-forward policies of the sdk section of global.json.
+const (
+	rollForwardPatch   = "patch"
+	rollForwardFeature = "feature"
+	rollForwardMinor   = "minor"
+	rollForwardMajor   = "major"
+	rollForwardDisable = "disable"
+)
+
+// SDKSatisfies reports whether the .NET SDK installed can build a project whose global.json
+// pins the SDK version pinned, rolling forward as the policy rollForward allows. SDK versions
+// are major.minor.feature-band and patch, as in 8.0.1xx; without a policy, later patches of
+// the feature band are allowed, as the default latestPatch policy does.
+func SDKSatisfies(installed, pinned, rollForward string) (bool, error) {
+	v, ok := parseVersion(installed)
+	if !ok || len(v) < 3 {
+		return false, fmt.Errorf("invalid .NET SDK version %q", installed)
+	}
+	p, ok := parseVersion(pinned)
+	if !ok || len(p) < 3 {
+		return false, fmt.Errorf("invalid .NET SDK version %q in global.json", pinned)
+	}
+	if compare(v, p, 3) < 0 {
+		return false, nil
+	}
+
+	policy := strings.ToLower(strings.TrimPrefix(strings.TrimPrefix(rollForward, "latest"), "Latest"))
+	switch policy {
+	case "", rollForwardPatch:
+		return hasPrefix(v, p[:2]) && v[2]/100 == p[2]/100, nil
+	case rollForwardFeature:
+		return hasPrefix(v, p[:2]), nil
+	case rollForwardMinor:
+		return hasPrefix(v, p[:1]), nil
+	case rollForwardMajor:
+		return true, nil
+	case rollForwardDisable:
+		return compare(v, p, 3) == 0, nil
+	}
+	return false, fmt.Errorf("unknown rollForward %q in global.json", rollForward)
+}
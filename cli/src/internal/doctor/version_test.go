@@ -0,0 +1,89 @@
+package doctor
+
+import "testing"
+
+func TestSatisfies(t *testing.T) {
+	tests := []struct {
+		version    string
+		constraint string
+		want       bool
+	}{
+		{"20.11.1", "", true},
+		{"20.11.1", "*", true},
+		{"v20.11.1", ">=18", true},
+		{"16.20.0", ">=18", false},
+		{"18.0.0", ">= 18.0.0", true},
+		{"20.11.1", "^20.10", true},
+		{"21.0.0", "^20.10", false},
+		{"0.3.5", "^0.3.1", true},
+		{"0.4.0", "^0.3.1", false},
+		{"1.2.9", "~1.2.3", true},
+		{"1.3.0", "~1.2.3", false},
+		{"20.1.0", "18 || 20", true},
+		{"19.1.0", "18 || 20", false},
+		{"20.1.0", "18.x || 20.x", true},
+		{"2.3.9", "1.2 - 2.3", true},
+		{"2.4.0", "1.2 - 2.3", false},
+		{"1.2.0", ">1.2", false},
+		{"1.3.0", ">1.2", true},
+		{"3.12.4", "3.12", true},
+		{"3.13.0", "3.12", false},
+		{"3.12.4", ">=3.10,<3.13", true},
+		{"3.13.0", ">=3.10,<3.13", false},
+		{"3.13.1", "~=3.11", true},
+		{"4.0.0", "~=3.11", false},
+		{"3.11.9", "~=3.11.2", true},
+		{"3.12.0", "~=3.11.2", false},
+		{"3.12.4", "==3.12.*", true},
+		{"3.12.4", "!=3.12.*", false},
+		{"9.0.100-preview.1", ">=9.0", true},
+	}
+	for _, tt := range tests {
+		got, err := Satisfies(tt.version, tt.constraint)
+		if err != nil {
+			t.Errorf("Satisfies(%q, %q) error = %v", tt.version, tt.constraint, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Satisfies(%q, %q) = %v, want %v", tt.version, tt.constraint, got, tt.want)
+		}
+	}
+
+	for _, tt := range [][2]string{{"unknown", ">=18"}, {"20.0.0", "lts/*"}, {"20.0.0", ">=x.y"}} {
+		if _, err := Satisfies(tt[0], tt[1]); err == nil {
+			t.Errorf("Satisfies(%q, %q) succeeded", tt[0], tt[1])
+		}
+	}
+}
+
+func TestSDKSatisfies(t *testing.T) {
+	tests := []struct {
+		installed   string
+		rollForward string
+		want        bool
+	}{
+		{"8.0.105", "", true},
+		{"8.0.200", "", false},
+		{"8.0.099", "latestPatch", false},
+		{"8.0.200", "latestFeature", true},
+		{"8.1.100", "feature", false},
+		{"8.1.100", "minor", true},
+		{"9.0.100", "minor", false},
+		{"9.0.100", "latestMajor", true},
+		{"8.0.101", "disable", false},
+		{"8.0.100", "disable", true},
+	}
+	for _, tt := range tests {
+		got, err := SDKSatisfies(tt.installed, "8.0.100", tt.rollForward)
+		if err != nil {
+			t.Errorf("SDKSatisfies(%s, %s) error = %v", tt.installed, tt.rollForward, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("SDKSatisfies(%s, %s) = %v, want %v", tt.installed, tt.rollForward, got, tt.want)
+		}
+	}
+	if _, err := SDKSatisfies("8.0.100", "8.0.100", "sometimes"); err == nil {
+		t.Error("SDKSatisfies() succeeded with an unknown rollForward")
+	}
+}
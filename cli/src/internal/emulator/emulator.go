@@ -0,0 +1,385 @@
+// Package emulator runs local emulators of the Azure resources that azure.yaml declares, such as
+// Azurite for storage or a Postgres container for db.postgres, and gives the services that use
+// them their connection strings.
+//
+// Emulators run as containers of the engine of the container package, named after the
+// workspace so that sessions of other workspaces don't share them. Their ports are published
+// on the loopback interface, at ports the engine chooses unless the emulator needs fixed ones.
+// Data of emulators that keep it, such as databases, is kept in volumes across sessions.
+package emulator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jongio/azd-app/cli/src/internal/azureyaml"
+	"github.com/jongio/azd-app/cli/src/internal/container"
+	"github.com/jongio/azd-app/cli/src/internal/statedir"
+	"github.com/jongio/azd-app/cli/src/internal/workerpool"
+)
+
+// StartTimeout is the longest wait for an emulator to accept connections. Emulators with a
+// database, such as Service Bus, take a minute or more on first start.
+const StartTimeout = 3 * time.Minute
+
+// readyInterval is the time between checks of whether an emulator is ready.
+const readyInterval = time.Second
+
+// workspaceLabel labels the containers of emulators with the workspace they run for.
+const workspaceLabel = "azd-app.emulator.workspace"
+
+// Instance is a running emulator of a resource.
+type Instance struct {
+	Name      string            `json:"name"` // Resource name in azure.yaml
+	Type      string            `json:"type"`
+	Image     string            `json:"image"`
+	Container string            `json:"container"`
+	Host      string            `json:"host"`  // Host that services reach the emulator at
+	Ports     map[int]int       `json:"ports"` // Host port of each port of the container
+	Env       map[string]string `json:"-"`     // Variables of the services that use the resource
+	Reused    bool              `json:"reused,omitempty"`
+
+	companion string // Container of the companion, if any
+}
+
+// Manager starts and stops the emulators of a workspace.
+type Manager struct {
+	engine container.Engine
+	prefix string // Prefix of the names of containers, volumes, and the network
+
+	mu        sync.Mutex
+	instances []*Instance
+	network   bool // Whether the network was created
+	forwarder *container.Forwarder
+}
+
+// NewManager returns the manager of the emulators of workspace, run on engine.
+func NewManager(engine container.Engine, workspace string) (*Manager, error) {
+	key, err := statedir.WorkspaceKey(workspace)
+	if err != nil {
+		return nil, err
+	}
+	return &Manager{engine: engine, prefix: "azd-app-" + strings.ToLower(key)}, nil
+}
+
+// Select returns the names of the resources of project that services use and that can run in an
+// emulator, sorted. Resources marked existing are left to Azure.
+func Select(project *azureyaml.Project, services map[string]azureyaml.Service) []string {
+	used := make(map[string]bool)
+	for _, svc := range services {
+		for _, name := range svc.Uses {
+			if r, ok := project.Resources[name]; ok && !r.Existing && Supported(r.Type) {
+				used[name] = true
+			}
+		}
+	}
+	names := make([]string, 0, len(used))
+	for name := range used {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Start starts the emulators of the named resources of project at once and waits until each
+// accepts connections. An emulator that is already running for the workspace, e.g. for a
+// session in the background, is reused. On error, the emulators this call started are stopped.
+func (m *Manager) Start(ctx context.Context, project *azureyaml.Project, names []string) ([]*Instance, error) {
+	for _, name := range names {
+		if emulators[project.Resources[name].Type].Companion != nil {
+			if err := m.createNetwork(ctx); err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+
+	instances := make([]*Instance, len(names))
+	tasks := make([]workerpool.Task, len(names))
+	for i, name := range names {
+		i, name := i, name
+		tasks[i] = workerpool.Task{Name: name, Run: func(ctx context.Context) error {
+			inst, err := m.start(ctx, name, project.Resources[name])
+			if inst != nil {
+				m.mu.Lock()
+				m.instances = append(m.instances, inst)
+				m.mu.Unlock()
+			}
+			instances[i] = inst
+			return err
+		}}
+	}
+	if err := workerpool.Run(ctx, tasks, workerpool.Options{Workers: len(tasks)}); err != nil {
+		_ = m.Stop(context.Background())
+		return nil, err
+	}
+
+	// Ports of a remote engine are published on its machine; forward them to this one
+	var ports []int
+	for _, inst := range instances {
+		for _, port := range inst.Ports {
+			ports = append(ports, port)
+		}
+	}
+	forwarder, err := m.engine.Forward(ports)
+	if err != nil {
+		_ = m.Stop(context.Background())
+		return nil, fmt.Errorf("failed to forward the ports of emulators: %w", err)
+	}
+	m.forwarder = forwarder
+	return instances, nil
+}
+
+// createNetwork creates the network that emulators reach their companions on, if it doesn't
+// exist yet.
+func (m *Manager) createNetwork(ctx context.Context) error {
+	if err := m.engine.Command(ctx, "network", "inspect", m.prefix).Run(); err == nil {
+		return nil
+	}
+	if out, err := m.engine.Command(ctx, "network", "create", m.prefix).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to create network %s: %w: %s", m.prefix, err, strings.TrimSpace(string(out)))
+	}
+	m.network = true
+	return nil
+}
+
+// Stop stops the emulators Start started, keeping their volumes, and removes the network.
+// Reused emulators keep running for the session that started them.
+func (m *Manager) Stop(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var errs []error
+	if err := m.forwarder.Close(); err != nil {
+		errs = append(errs, err)
+	}
+	m.forwarder = nil
+	for _, inst := range m.instances {
+		if inst.Reused {
+			continue
+		}
+		for _, name := range []string{inst.Container, inst.companion} {
+			if name == "" {
+				continue
+			}
+			if out, err := m.engine.Command(ctx, "rm", "--force", name).CombinedOutput(); err != nil {
+				errs = append(errs, fmt.Errorf("failed to stop %s: %w: %s", name, err, strings.TrimSpace(string(out))))
+			}
+		}
+	}
+	m.instances = nil
+	if m.network {
+		// The network stays while reused emulators are attached to it
+		_ = m.engine.Command(ctx, "network", "rm", m.prefix).Run()
+		m.network = false
+	}
+	return errors.Join(errs...)
+}
+
+// start starts the emulator of the resource name, or reuses its running container, and waits
+// until it is ready. The instance is returned with an error once its container exists, so
+// that it is stopped.
+func (m *Manager) start(ctx context.Context, name string, r azureyaml.Resource) (*Instance, error) {
+	spec := emulators[r.Type]
+	inst := &Instance{
+		Name:      name,
+		Type:      r.Type,
+		Image:     spec.Image,
+		Container: m.containerName(name),
+		Host:      "127.0.0.1",
+	}
+
+	if running, _ := isRunning(ctx, m.engine, inst.Container); running {
+		inst.Reused = true
+	} else {
+		// Remove a container a session that crashed left behind
+		_ = m.engine.Command(ctx, "rm", "--force", inst.Container).Run()
+		if spec.Companion != nil {
+			inst.companion = inst.Container + "-" + spec.Companion.Type
+			_ = m.engine.Command(ctx, "rm", "--force", inst.companion).Run()
+			if err := m.run(ctx, m.createArgs(spec.Companion, inst.companion, true, nil), nil, inst.companion); err != nil {
+				return inst, err
+			}
+		}
+		files := map[string][]byte(nil)
+		if spec.files != nil {
+			var err error
+			if files, err = spec.files(name, r); err != nil {
+				return inst, err
+			}
+		}
+		env := map[string]string(nil)
+		if inst.companion != "" {
+			env = map[string]string{"SQL_SERVER": inst.companion}
+		}
+		if err := m.run(ctx, m.createArgs(spec, inst.Container, spec.Companion != nil, env), files, inst.Container); err != nil {
+			return inst, err
+		}
+	}
+
+	ports, err := m.publishedPorts(ctx, inst.Container, spec.Ports)
+	if err != nil {
+		return inst, err
+	}
+	inst.Ports = ports
+	if err := waitReady(ctx, m.engine, spec, inst); err != nil {
+		return inst, err
+	}
+	inst.Env = spec.vars(inst)
+	return inst, nil
+}
+
+// containerName returns the name of the container of the resource name.
+func (m *Manager) containerName(resource string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(resource) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '-' || r == '_' || r == '.' {
+			b.WriteRune(r)
+		} else {
+			b.WriteRune('-')
+		}
+	}
+	return m.prefix + "-" + b.String()
+}
+
+// createArgs returns the arguments of the create command of the engine for the container of
+// spec named containerName, on the network of the workspace with network, with env added to
+// the variables of spec.
+func (m *Manager) createArgs(spec *Emulator, containerName string, network bool, env map[string]string) []string {
+	args := []string{"create", "--name", containerName, "--label", workspaceLabel + "=" + m.prefix}
+	if network {
+		args = append(args, "--network", m.prefix)
+	}
+	for _, port := range spec.Ports {
+		hostPort := ""
+		if spec.FixedPorts {
+			hostPort = strconv.Itoa(port)
+		}
+		args = append(args, "--publish", fmt.Sprintf("%s:%s:%d", m.engine.PublishAddress(), hostPort, port))
+	}
+	if spec.Data != "" {
+		args = append(args, "--mount", fmt.Sprintf("type=volume,source=%s-data,target=%s", containerName, spec.Data))
+	}
+
+	vars := make(map[string]string, len(spec.Env)+len(env))
+	for k, v := range spec.Env {
+		vars[k] = v
+	}
+	for k, v := range env {
+		vars[k] = v
+	}
+	names := make([]string, 0, len(vars))
+	for k := range vars {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	for _, k := range names {
+		args = append(args, "--env", k+"="+vars[k])
+	}
+
+	args = append(args, spec.Image)
+	return append(args, spec.Command...)
+}
+
+// run creates a container with args, copies files into it, and starts it. Files are copied
+// instead of mounted so that a remote engine, which can't mount files of this machine, gets
+// them too.
+func (m *Manager) run(ctx context.Context, args []string, files map[string][]byte, containerName string) error {
+	if out, err := m.engine.Command(ctx, args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to create %s: %w: %s", containerName, err, strings.TrimSpace(string(out)))
+	}
+	if len(files) > 0 {
+		dir, err := os.MkdirTemp("", "azd-app-emulator-*")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(dir)
+		for target, data := range files {
+			source := filepath.Join(dir, filepath.Base(target))
+			if err := os.WriteFile(source, data, 0600); err != nil {
+				return err
+			}
+			if out, err := m.engine.Command(ctx, "cp", source, containerName+":"+target).CombinedOutput(); err != nil {
+				return fmt.Errorf("failed to copy %s into %s: %w: %s", target, containerName, err, strings.TrimSpace(string(out)))
+			}
+		}
+	}
+	if out, err := m.engine.Command(ctx, "start", containerName).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to start %s: %w: %s", containerName, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// isRunning reports whether the container named name is running.
+func isRunning(ctx context.Context, engine container.Engine, name string) (bool, error) {
+	out, err := engine.Command(ctx, "inspect", "--format", "{{.State.Running}}", name).Output()
+	if err != nil {
+		return false, err
+	}
+	return strings.TrimSpace(string(out)) == "true", nil
+}
+
+// publishedPorts returns the host port that each of ports of the container is published on.
+func (m *Manager) publishedPorts(ctx context.Context, name string, ports []int) (map[int]int, error) {
+	published := make(map[int]int, len(ports))
+	for _, port := range ports {
+		out, err := m.engine.Command(ctx, "port", name, fmt.Sprintf("%d/tcp", port)).Output()
+		if err != nil {
+			return nil, fmt.Errorf("failed to find the published port %d of %s: %w", port, name, err)
+		}
+		hostPort, err := parsePortOutput(string(out))
+		if err != nil {
+			return nil, fmt.Errorf("failed to find the published port %d of %s: %w", port, name, err)
+		}
+		published[port] = hostPort
+	}
+	return published, nil
+}
+
+// parsePortOutput returns the host port of the output of the port command of an engine, e.g.
+// 127.0.0.1:49153, which lists a line per address.
+func parsePortOutput(out string) (int, error) {
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		line = strings.TrimSpace(line)
+		i := strings.LastIndex(line, ":")
+		if i < 0 {
+			continue
+		}
+		if port, err := strconv.Atoi(line[i+1:]); err == nil && port > 0 {
+			return port, nil
+		}
+	}
+	return 0, fmt.Errorf("unexpected output %q", strings.TrimSpace(out))
+}
+
+// waitReady waits until the emulator of inst is ready, its container stops, or StartTimeout
+// passes.
+func waitReady(ctx context.Context, engine container.Engine, spec *Emulator, inst *Instance) error {
+	ctx, cancel := context.WithTimeout(ctx, StartTimeout)
+	defer cancel()
+	for {
+		err := spec.ready(ctx, engine, inst)
+		if err == nil {
+			return nil
+		}
+		if running, _ := isRunning(ctx, engine, inst.Container); !running && ctx.Err() == nil {
+			return fmt.Errorf("emulator of %s stopped; see '%s logs %s'", inst.Name, engine.Name, inst.Container)
+		}
+		select {
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return fmt.Errorf("emulator of %s was not ready within %s: %w", inst.Name, StartTimeout, err)
+			}
+			return ctx.Err()
+		case <-time.After(readyInterval):
+		}
+	}
+}
@@ -0,0 +1,289 @@
+package emulator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jongio/azd-app/cli/src/internal/azureyaml"
+	"github.com/jongio/azd-app/cli/src/internal/container"
+	"github.com/jongio/azd-app/cli/src/internal/httpclient"
+)
+
+// Well-known credentials of the emulators. Emulators publish their ports on the loopback
+// interface only, so the values don't need to be secret.
+const (
+	azuriteAccount    = "devstoreaccount1"
+	azuriteKey        = "Eby8vdM02xNOcqFlqUwJPLlmEtlCDXJ1OUzFT50uSRZ6IFsuFq2UVErCz4I6tq/K1SZFPTOtr/KBHBeksoGMGw=="
+	cosmosKey         = "C2y6yDjf5/R+ob0N8A7Cgv30VRDJIWEHLM+4QDU5DE2nQ9nDuVTqobD4b8mGGyPMbIZnqyMsEcaGQy67XIw/Jw=="
+	serviceBusKey     = "SAS_KEY_VALUE"
+	serviceBusSQLPass = "AzdApp-Local-1" // Meets the password policy of SQL Server
+	postgresUser      = "postgres"
+	postgresPassword  = "azdapp-local"
+)
+
+// serviceBusConfigPath is where the Service Bus emulator reads its queues and topics.
+const serviceBusConfigPath = "/ServiceBus_Emulator/ConfigFiles/Config.json"
+
+// Emulator describes the container that emulates a type of resource.
+type Emulator struct {
+	Type       string            // Resource type in azure.yaml, e.g. db.postgres
+	Image      string            // Image of the container
+	Ports      []int             // Ports of the container that services connect to
+	FixedPorts bool              // Publish Ports on the same ports of the host, for emulators that advertise their address
+	Env        map[string]string // Variables of the container
+	Command    []string          // Optional: replaces the command of the image
+	Data       string            // Optional: directory of the container kept in a volume across sessions
+	Companion  *Emulator         // Optional: container started first on the same network, e.g. a database the emulator needs
+
+	// files returns the files copied into the container before it starts, by path
+	files func(name string, r azureyaml.Resource) (map[string][]byte, error)
+	// ready returns nil once the emulator accepts connections
+	ready func(ctx context.Context, engine container.Engine, inst *Instance) error
+	// vars returns the variables that services using the emulator get
+	vars func(inst *Instance) map[string]string
+}
+
+// emulators are the emulators of the resource types azd app can run locally.
+var emulators = map[string]*Emulator{
+	"storage": {
+		Type:    "storage",
+		Image:   "mcr.microsoft.com/azure-storage/azurite",
+		Ports:   []int{10000, 10001, 10002},
+		Command: []string{"azurite", "--location", "/data", "--blobHost", "0.0.0.0", "--queueHost", "0.0.0.0", "--tableHost", "0.0.0.0", "--skipApiVersionCheck", "--loose"},
+		Data:    "/data",
+		ready:   readyLog("Azurite Table service is successfully listening"),
+		vars:    storageVars,
+	},
+	"db.cosmos": {
+		Type:       "db.cosmos",
+		Image:      "mcr.microsoft.com/cosmosdb/linux/azure-cosmos-emulator:vnext-preview",
+		Ports:      []int{8081},
+		FixedPorts: true,
+		Env:        map[string]string{"PROTOCOL": "http"},
+		ready:      readyHTTP(8081),
+		vars:       cosmosVars,
+	},
+	"messaging.servicebus": {
+		Type:  "messaging.servicebus",
+		Image: "mcr.microsoft.com/azure-messaging/servicebus-emulator:latest",
+		Ports: []int{5672},
+		Env:   map[string]string{"ACCEPT_EULA": "Y", "MSSQL_SA_PASSWORD": serviceBusSQLPass},
+		Companion: &Emulator{
+			Type:  "sql",
+			Image: "mcr.microsoft.com/azure-sql-edge:latest",
+			Env:   map[string]string{"ACCEPT_EULA": "Y", "MSSQL_SA_PASSWORD": serviceBusSQLPass},
+		},
+		files: serviceBusFiles,
+		ready: readyLog("Emulator Service is Successfully Up!"),
+		vars:  serviceBusVars,
+	},
+	"db.redis": {
+		Type:  "db.redis",
+		Image: "redis:7-alpine",
+		Ports: []int{6379},
+		Data:  "/data",
+		ready: readyExec("redis-cli", "ping"),
+		vars:  redisVars,
+	},
+	"db.postgres": {
+		Type:  "db.postgres",
+		Image: "postgres:16-alpine",
+		Ports: []int{5432},
+		Env:   map[string]string{"POSTGRES_USER": postgresUser, "POSTGRES_PASSWORD": postgresPassword},
+		Data:  "/var/lib/postgresql/data",
+		// The server that initializes the database only listens on a socket, so TCP means ready
+		ready: readyExec("pg_isready", "--host", "127.0.0.1", "--username", postgresUser),
+		vars:  postgresVars,
+	},
+}
+
+// Supported reports whether resources of resourceType can run in an emulator.
+func Supported(resourceType string) bool {
+	_, ok := emulators[resourceType]
+	return ok
+}
+
+// Types returns the resource types that can run in an emulator, sorted.
+func Types() []string {
+	types := make([]string, 0, len(emulators))
+	for t := range emulators {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// endpoint returns the host:port that services reach port of the container at.
+func (inst *Instance) endpoint(port int) string {
+	return fmt.Sprintf("%s:%d", inst.Host, inst.Ports[port])
+}
+
+func storageVars(inst *Instance) map[string]string {
+	blob := fmt.Sprintf("http://%s/%s", inst.endpoint(10000), azuriteAccount)
+	queue := fmt.Sprintf("http://%s/%s", inst.endpoint(10001), azuriteAccount)
+	table := fmt.Sprintf("http://%s/%s", inst.endpoint(10002), azuriteAccount)
+	return map[string]string{
+		"AZURE_STORAGE_ACCOUNT_NAME":   azuriteAccount,
+		"AZURE_STORAGE_BLOB_ENDPOINT":  blob,
+		"AZURE_STORAGE_QUEUE_ENDPOINT": queue,
+		"AZURE_STORAGE_TABLE_ENDPOINT": table,
+		"AZURE_STORAGE_CONNECTION_STRING": fmt.Sprintf(
+			"DefaultEndpointsProtocol=http;AccountName=%s;AccountKey=%s;BlobEndpoint=%s;QueueEndpoint=%s;TableEndpoint=%s;",
+			azuriteAccount, azuriteKey, blob, queue, table),
+	}
+}
+
+func cosmosVars(inst *Instance) map[string]string {
+	endpoint := fmt.Sprintf("http://%s/", inst.endpoint(8081))
+	return map[string]string{
+		"AZURE_COSMOS_ENDPOINT":          endpoint,
+		"AZURE_COSMOS_CONNECTION_STRING": fmt.Sprintf("AccountEndpoint=%s;AccountKey=%s;", endpoint, cosmosKey),
+	}
+}
+
+func serviceBusVars(inst *Instance) map[string]string {
+	return map[string]string{
+		"AZURE_SERVICE_BUS_HOST": inst.endpoint(5672),
+		"AZURE_SERVICE_BUS_CONNECTION_STRING": fmt.Sprintf(
+			"Endpoint=sb://%s;SharedAccessKeyName=RootManageSharedAccessKey;SharedAccessKey=%s;UseDevelopmentEmulator=true;",
+			inst.endpoint(5672), serviceBusKey),
+	}
+}
+
+func redisVars(inst *Instance) map[string]string {
+	return map[string]string{
+		"REDIS_HOST":     inst.Host,
+		"REDIS_PORT":     strconv.Itoa(inst.Ports[6379]),
+		"REDIS_ENDPOINT": inst.endpoint(6379),
+		"REDIS_PASSWORD": "",
+		"REDIS_URL":      "redis://" + inst.endpoint(6379),
+	}
+}
+
+func postgresVars(inst *Instance) map[string]string {
+	return map[string]string{
+		"POSTGRES_HOST":     inst.Host,
+		"POSTGRES_PORT":     strconv.Itoa(inst.Ports[5432]),
+		"POSTGRES_USERNAME": postgresUser,
+		"POSTGRES_PASSWORD": postgresPassword,
+		"POSTGRES_DATABASE": postgresUser,
+		"POSTGRES_URL":      fmt.Sprintf("postgres://%s:%s@%s/%s", postgresUser, postgresPassword, inst.endpoint(5432), postgresUser),
+	}
+}
+
+// serviceBusFiles returns the configuration of the Service Bus emulator, with the queues and
+// topics of the resource.
+func serviceBusFiles(name string, r azureyaml.Resource) (map[string][]byte, error) {
+	queues, err := stringList(r.Extra["queues"])
+	if err != nil {
+		return nil, fmt.Errorf("invalid queues of resource %s: %w", name, err)
+	}
+	topics, err := stringList(r.Extra["topics"])
+	if err != nil {
+		return nil, fmt.Errorf("invalid topics of resource %s: %w", name, err)
+	}
+
+	namespace := map[string]interface{}{"Name": "sbemulatorns", "Queues": []interface{}{}, "Topics": []interface{}{}}
+	for _, queue := range queues {
+		namespace["Queues"] = append(namespace["Queues"].([]interface{}), map[string]interface{}{
+			"Name": queue,
+			"Properties": map[string]interface{}{
+				"DeadLetteringOnMessageExpiration":    false,
+				"DefaultMessageTimeToLive":            "PT1H",
+				"DuplicateDetectionHistoryTimeWindow": "PT20S",
+				"ForwardDeadLetteredMessagesTo":       "",
+				"ForwardTo":                           "",
+				"LockDuration":                        "PT1M",
+				"MaxDeliveryCount":                    10,
+				"RequiresDuplicateDetection":          false,
+				"RequiresSession":                     false,
+			},
+		})
+	}
+	for _, topic := range topics {
+		namespace["Topics"] = append(namespace["Topics"].([]interface{}), map[string]interface{}{
+			"Name": topic,
+			"Properties": map[string]interface{}{
+				"DefaultMessageTimeToLive":            "PT1H",
+				"DuplicateDetectionHistoryTimeWindow": "PT20S",
+				"RequiresDuplicateDetection":          false,
+			},
+			"Subscriptions": []interface{}{},
+		})
+	}
+	config := map[string]interface{}{
+		"UserConfig": map[string]interface{}{
+			"Namespaces": []interface{}{namespace},
+			"Logging":    map[string]interface{}{"Type": "Console"},
+		},
+	}
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return map[string][]byte{serviceBusConfigPath: data}, nil
+}
+
+// stringList returns the strings of a list setting of a resource.
+func stringList(value interface{}) ([]string, error) {
+	if value == nil {
+		return nil, nil
+	}
+	items, ok := value.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("must be a list of names")
+	}
+	list := make([]string, 0, len(items))
+	for _, item := range items {
+		s, ok := item.(string)
+		if !ok || s == "" {
+			return nil, fmt.Errorf("must be a list of names")
+		}
+		list = append(list, s)
+	}
+	return list, nil
+}
+
+// readyLog is ready once the logs of the container contain line.
+func readyLog(line string) func(context.Context, container.Engine, *Instance) error {
+	return func(ctx context.Context, engine container.Engine, inst *Instance) error {
+		out, err := engine.Command(ctx, "logs", inst.Container).CombinedOutput()
+		if err != nil {
+			return err
+		}
+		if !strings.Contains(string(out), line) {
+			return fmt.Errorf("not ready")
+		}
+		return nil
+	}
+}
+
+// readyExec is ready once command succeeds in the container.
+func readyExec(command ...string) func(context.Context, container.Engine, *Instance) error {
+	return func(ctx context.Context, engine container.Engine, inst *Instance) error {
+		return engine.Command(ctx, append([]string{"exec", inst.Container}, command...)...).Run()
+	}
+}
+
+// readyHTTP is ready once port answers HTTP requests, with any status.
+func readyHTTP(port int) func(context.Context, container.Engine, *Instance) error {
+	return func(ctx context.Context, _ container.Engine, inst *Instance) error {
+		ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://"+inst.endpoint(port)+"/", nil)
+		if err != nil {
+			return err
+		}
+		resp, err := httpclient.NewLocal(0).Do(req)
+		if err != nil {
+			return err
+		}
+		return resp.Body.Close()
+	}
+}
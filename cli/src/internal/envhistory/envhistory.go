@@ -0,0 +1,296 @@
+package envhistory
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jongio/azd-app/cli/src/internal/security"
+	"github.com/jongio/azd-app/cli/src/internal/statedir"
+)
+
+// MaxRuns is the number of changed environments kept per service.
+const MaxRuns = 100
+
+// hashLength is the number of hex characters kept from each value hash.
+const hashLength = 12
+
+// keyFileName is the file in the state root holding the key values are hashed with.
+const keyFileName = "envhistory.key"
+
+// dataKind names the workspace data directory that holds the history.
+const dataKind = "envhistory"
+
+// servicePattern restricts service names to safe file names.
+var servicePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// secretMarkers are key fragments that mark a variable as secret.
+var secretMarkers = []string{"SECRET", "PASSWORD", "PASSWD", "TOKEN", "KEY", "CONNECTION_STRING", "CONNECTIONSTRING", "CREDENTIAL", "PRIVATE"}
+
+// Change kinds.
+const (
+	Added   = "added"
+	Changed = "changed"
+	Removed = "removed"
+)
+
+// Run is the environment of a service at the start of one run.
+// Values are stored as truncated HMAC-SHA-256 hashes, never in plain text.
+type Run struct {
+	Time   time.Time         `json:"time"`
+	Hashes map[string]string `json:"hashes"`
+}
+
+// History is the stored environment history of a service.
+type History struct {
+	Service string `json:"service"`
+	Runs    []Run  `json:"runs"`
+}
+
+// Change describes a key that appeared, changed, or disappeared between two runs.
+type Change struct {
+	Key     string `json:"key"`
+	Kind    string `json:"kind"`
+	OldHash string `json:"oldHash,omitempty"`
+	NewHash string `json:"newHash,omitempty"`
+	Secret  bool   `json:"secret,omitempty"`
+}
+
+// Entry is a run whose environment differed from the run before it.
+type Entry struct {
+	Time    time.Time `json:"time"`
+	Changes []Change  `json:"changes"`
+}
+
+// Dir returns the directory where environment history is stored: in the workspace state,
+// outside the project, so it never ends up in the repository.
+func Dir(projectDir string) (string, error) {
+	return statedir.DataDir(projectDir, dataKind)
+}
+
+// Hash returns the truncated hash stored for a value. Values are hashed with a random key
+// kept on this machine, so short values and secrets can't be guessed from a history file.
+func Hash(key []byte, value string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))[:hashLength]
+}
+
+// HashKey returns the key of this machine that values are hashed with, creating it on first use.
+func HashKey() ([]byte, error) {
+	root, err := statedir.Root()
+	if err != nil {
+		return nil, err
+	}
+	path := filepath.Join(root, keyFileName)
+	if err := security.ValidatePath(path); err != nil {
+		return nil, fmt.Errorf("invalid environment history key path: %w", err)
+	}
+
+	// #nosec G304 -- Path validated by security.ValidatePath
+	if key, err := os.ReadFile(path); err == nil && len(key) > 0 {
+		return key, nil
+	}
+	if err := os.MkdirAll(root, 0750); err != nil {
+		return nil, fmt.Errorf("failed to create state directory: %w", err)
+	}
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("failed to generate environment history key: %w", err)
+	}
+	// #nosec G304 -- Path validated by security.ValidatePath
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if errors.Is(err, os.ErrExist) {
+		// Another process created it first
+		// #nosec G304 -- Path validated by security.ValidatePath
+		return os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create environment history key: %w", err)
+	}
+	_, err = file.Write(key)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to write environment history key: %w", err)
+	}
+	return key, nil
+}
+
+// IsSecretKey reports whether a variable name looks like it holds a secret.
+func IsSecretKey(key string) bool {
+	upper := strings.ToUpper(key)
+	for _, marker := range secretMarkers {
+		if strings.Contains(upper, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// Record hashes the resolved environment of a service and appends it to the
+// history when it differs from the previous run. Returns the changes recorded.
+func Record(projectDir, serviceName string, env map[string]string) ([]Change, error) {
+	history, err := Load(projectDir, serviceName)
+	if err != nil {
+		return nil, err
+	}
+	hashKey, err := HashKey()
+	if err != nil {
+		return nil, err
+	}
+
+	hashes := make(map[string]string, len(env))
+	for key, value := range env {
+		hashes[key] = Hash(hashKey, value)
+	}
+
+	var previous map[string]string
+	if n := len(history.Runs); n > 0 {
+		previous = history.Runs[n-1].Hashes
+	}
+	changes := Diff(previous, hashes)
+	if len(history.Runs) > 0 && len(changes) == 0 {
+		return nil, nil
+	}
+
+	history.Runs = append(history.Runs, Run{Time: time.Now().UTC(), Hashes: hashes})
+	if len(history.Runs) > MaxRuns {
+		history.Runs = history.Runs[len(history.Runs)-MaxRuns:]
+	}
+
+	if err := save(projectDir, history); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// Load reads the environment history of a service.
+// Returns an empty history when nothing has been recorded yet.
+func Load(projectDir, serviceName string) (*History, error) {
+	path, err := historyPath(projectDir, serviceName)
+	if err != nil {
+		return nil, err
+	}
+
+	// #nosec G304 -- Path validated by security.ValidatePath
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &History{Service: serviceName}, nil
+		}
+		return nil, fmt.Errorf("failed to read environment history: %w", err)
+	}
+
+	var history History
+	if err := json.Unmarshal(data, &history); err != nil {
+		return nil, fmt.Errorf("failed to parse environment history: %w", err)
+	}
+	return &history, nil
+}
+
+// Entries returns each recorded run with the changes since the run before it, oldest first.
+// When key is non-empty only changes to that key are included.
+func (h *History) Entries(key string) []Entry {
+	var entries []Entry
+	var previous map[string]string
+	for _, run := range h.Runs {
+		changes := Diff(previous, run.Hashes)
+		previous = run.Hashes
+
+		if key != "" {
+			filtered := changes[:0]
+			for _, change := range changes {
+				if change.Key == key {
+					filtered = append(filtered, change)
+				}
+			}
+			changes = filtered
+		}
+		if len(changes) == 0 {
+			continue
+		}
+		entries = append(entries, Entry{Time: run.Time, Changes: changes})
+	}
+	return entries
+}
+
+// Diff compares two sets of hashed values and returns the changes sorted by key.
+// Hashes of secret keys are omitted from the result.
+func Diff(previous, current map[string]string) []Change {
+	var changes []Change
+	for key, hash := range current {
+		old, existed := previous[key]
+		switch {
+		case !existed:
+			changes = append(changes, Change{Key: key, Kind: Added, NewHash: hash})
+		case old != hash:
+			changes = append(changes, Change{Key: key, Kind: Changed, OldHash: old, NewHash: hash})
+		}
+	}
+	for key, old := range previous {
+		if _, exists := current[key]; !exists {
+			changes = append(changes, Change{Key: key, Kind: Removed, OldHash: old})
+		}
+	}
+
+	for i := range changes {
+		if IsSecretKey(changes[i].Key) {
+			changes[i].Secret = true
+			changes[i].OldHash = ""
+			changes[i].NewHash = ""
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Key < changes[j].Key
+	})
+	return changes
+}
+
+// historyPath returns the validated history file path for a service.
+func historyPath(projectDir, serviceName string) (string, error) {
+	if !servicePattern.MatchString(serviceName) || strings.Contains(serviceName, "..") {
+		return "", fmt.Errorf("invalid service name '%s'", serviceName)
+	}
+	dir, err := Dir(projectDir)
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, serviceName+".json")
+	if err := security.ValidatePath(path); err != nil {
+		return "", fmt.Errorf("invalid environment history path: %w", err)
+	}
+	return path, nil
+}
+
+// save writes the environment history of a service.
+func save(projectDir string, history *History) error {
+	path, err := historyPath(projectDir, history.Service)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return fmt.Errorf("failed to create environment history directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(history, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode environment history: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write environment history: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package envhistory
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jongio/azd-app/cli/src/internal/statedir"
+)
+
+func TestRecordAndEntries(t *testing.T) {
+	t.Setenv(statedir.RootEnvVar, t.TempDir())
+	dir := t.TempDir()
+
+	runs := []map[string]string{
+		{"PORT": "3000", "API_URL": "http://localhost:5000", "DB_PASSWORD": "one"},
+		{"PORT": "3000", "API_URL": "http://localhost:5000", "DB_PASSWORD": "one"}, // unchanged, not recorded
+		{"PORT": "3001", "DB_PASSWORD": "two", "NEW_FLAG": "true"},
+	}
+	for _, env := range runs {
+		if _, err := Record(dir, "web", env); err != nil {
+			t.Fatalf("Record failed: %v", err)
+		}
+	}
+
+	history, err := Load(dir, "web")
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(history.Runs) != 2 {
+		t.Fatalf("expected 2 recorded runs, got %d", len(history.Runs))
+	}
+
+	entries := history.Entries("")
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+	if len(entries[0].Changes) != 3 {
+		t.Errorf("expected first run to add 3 keys, got %+v", entries[0].Changes)
+	}
+
+	key, err := HashKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]Change)
+	for _, c := range entries[1].Changes {
+		got[c.Key] = c
+	}
+	if got["PORT"].Kind != Changed || got["PORT"].OldHash != Hash(key, "3000") || got["PORT"].NewHash != Hash(key, "3001") {
+		t.Errorf("unexpected PORT change: %+v", got["PORT"])
+	}
+	if got["API_URL"].Kind != Removed {
+		t.Errorf("expected API_URL removed, got %+v", got["API_URL"])
+	}
+	if got["NEW_FLAG"].Kind != Added {
+		t.Errorf("expected NEW_FLAG added, got %+v", got["NEW_FLAG"])
+	}
+	if c := got["DB_PASSWORD"]; c.Kind != Changed || !c.Secret || c.OldHash != "" || c.NewHash != "" {
+		t.Errorf("expected redacted DB_PASSWORD change, got %+v", c)
+	}
+
+	if filtered := history.Entries("PORT"); len(filtered) != 2 || len(filtered[1].Changes) != 1 {
+		t.Errorf("expected PORT filter to return one change per entry, got %+v", filtered)
+	}
+}
+
+func TestRecordNeverStoresValues(t *testing.T) {
+	t.Setenv(statedir.RootEnvVar, t.TempDir())
+	dir := t.TempDir()
+
+	if _, err := Record(dir, "api", map[string]string{"API_TOKEN": "super-secret-value", "MODE": "plain-value"}); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+
+	path, err := historyPath(dir, "api")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read history: %v", err)
+	}
+	for _, value := range []string{"super-secret-value", "plain-value"} {
+		if strings.Contains(string(data), value) {
+			t.Errorf("history file contains plain text value %q", value)
+		}
+		// An unkeyed hash could be brute-forced from the file
+		sum := sha256.Sum256([]byte(value))
+		if strings.Contains(string(data), hex.EncodeToString(sum[:])[:hashLength]) {
+			t.Errorf("history file contains the unkeyed hash of %q", value)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".azure")); !os.IsNotExist(err) {
+		t.Errorf("history was written to the project directory")
+	}
+}
+
+func TestRecordKeepsMaxRuns(t *testing.T) {
+	t.Setenv(statedir.RootEnvVar, t.TempDir())
+	dir := t.TempDir()
+
+	for i := 0; i < MaxRuns+5; i++ {
+		if _, err := Record(dir, "web", map[string]string{"N": strings.Repeat("x", i)}); err != nil {
+			t.Fatalf("Record failed: %v", err)
+		}
+	}
+
+	history, err := Load(dir, "web")
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(history.Runs) != MaxRuns {
+		t.Errorf("expected %d runs, got %d", MaxRuns, len(history.Runs))
+	}
+}
+
+func TestIsSecretKey(t *testing.T) {
+	tests := map[string]bool{
+		"DB_PASSWORD":               true,
+		"API_TOKEN":                 true,
+		"STORAGE_CONNECTION_STRING": true,
+		"client_secret":             true,
+		"OPENAI_API_KEY":            true,
+		"PORT":                      false,
+		"SERVICE_URL_API":           false,
+		"NODE_ENV":                  false,
+	}
+	for key, want := range tests {
+		if got := IsSecretKey(key); got != want {
+			t.Errorf("IsSecretKey(%q) = %v, want %v", key, got, want)
+		}
+	}
+}
+
+func TestLoadInvalidServiceName(t *testing.T) {
+	t.Setenv(statedir.RootEnvVar, t.TempDir())
+	for _, name := range []string{"", "../web", "a/b", ".hidden"} {
+		if _, err := Load(t.TempDir(), name); err == nil {
+			t.Errorf("expected error for service name %q", name)
+		}
+	}
+}
+
+func TestHashKeyIsStable(t *testing.T) {
+	t.Setenv(statedir.RootEnvVar, t.TempDir())
+
+	first, err := HashKey()
+	if err != nil {
+		t.Fatalf("HashKey failed: %v", err)
+	}
+	second, err := HashKey()
+	if err != nil {
+		t.Fatalf("HashKey failed: %v", err)
+	}
+	if len(first) != 32 || string(first) != string(second) {
+		t.Errorf("HashKey() = %x then %x, want the same 32-byte key", first, second)
+	}
+}
@@ -29,7 +29,7 @@ func RunWithContext(ctx context.Context, name string, args []string, dir string)
 		cmd.Stderr = io.Discard
 		cmd.Stdin = nil
 	} else {
-		cmd.Stdout = os.Stdout
+		cmd.Stdout = output.Writer()
 		cmd.Stderr = os.Stderr
 		cmd.Stdin = os.Stdin
 	}
@@ -49,7 +49,15 @@ func RunWithTimeout(name string, args []string, dir string, timeout time.Duratio
 
 // RunCommand executes a command safely with default timeout.
 func RunCommand(name string, args []string, dir string) error {
-	return RunWithTimeout(name, args, dir, DefaultTimeout)
+	return RunCommandContext(context.Background(), name, args, dir)
+}
+
+// RunCommandContext executes a command safely with default timeout, stopping it early when ctx is done.
+func RunCommandContext(ctx context.Context, name string, args []string, dir string) error {
+	ctx, cancel := context.WithTimeout(ctx, DefaultTimeout)
+	defer cancel()
+
+	return RunWithContext(ctx, name, args, dir)
 }
 
 // StartCommand starts a long-running command in the background and returns immediately.
@@ -59,7 +67,7 @@ func RunCommand(name string, args []string, dir string) error {
 func StartCommand(name string, args []string, dir string) error {
 	cmd := exec.Command(name, args...)
 	cmd.Dir = dir
-	cmd.Stdout = os.Stdout
+	cmd.Stdout = output.Writer()
 	cmd.Stderr = os.Stderr
 	cmd.Stdin = os.Stdin
 	cmd.Env = os.Environ() // Inherit all environment variables from parent process
@@ -144,7 +152,7 @@ func StartCommandWithOutputMonitoring(name string, args []string, dir string, ha
 	cmd.Env = os.Environ() // Inherit all environment variables from parent process
 
 	// Wrap stdout and stderr with line handlers
-	cmd.Stdout = &lineWriter{output: os.Stdout, handler: handler}
+	cmd.Stdout = &lineWriter{output: output.Writer(), handler: handler}
 	cmd.Stderr = &lineWriter{output: os.Stderr, handler: handler}
 
 	// Start the command
@@ -165,3 +173,21 @@ func StartCommandWithOutputMonitoring(name string, args []string, dir string, ha
 
 	return nil
 }
+
+// StartCommandWithOutputHandler starts a command whose output is displayed to the user, and
+// calls handler for each line of its stdout and stderr. Unlike StartCommandWithOutputMonitoring
+// it returns once the command starts; the caller waits for it.
+// The command inherits all environment variables including azd context.
+func StartCommandWithOutputHandler(name string, args []string, dir string, handler OutputLineHandler) (*exec.Cmd, error) {
+	cmd := exec.Command(name, args...)
+	cmd.Dir = dir
+	cmd.Stdin = os.Stdin
+	cmd.Env = os.Environ() // Inherit all environment variables from parent process
+	cmd.Stdout = &lineWriter{output: output.Writer(), handler: handler}
+	cmd.Stderr = &lineWriter{output: os.Stderr, handler: handler}
+
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start command: %w", err)
+	}
+	return cmd, nil
+}
@@ -0,0 +1,400 @@
+// Package gc removes old logs, caches, and snapshots according to age and size policies.
+package gc
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jongio/azd-app/cli/src/internal/alias"
+	"github.com/jongio/azd-app/cli/src/internal/security"
+	"github.com/jongio/azd-app/cli/src/internal/snapshot"
+	"github.com/jongio/azd-app/cli/src/internal/statedir"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Categories of state that are collected.
+const (
+	CategoryLogs      = "logs"
+	CategoryCaches    = "caches"
+	CategorySnapshots = "snapshots"
+)
+
+// stampFileName records when automatic collection last ran, in the state root.
+const stampFileName = "gc.json"
+
+// Policy limits how much of one category of state is kept. Zero values mean no limit.
+type Policy struct {
+	MaxAge  time.Duration
+	MaxSize int64
+}
+
+// Policies configures garbage collection.
+type Policies struct {
+	Auto      bool          // Collect automatically when services are started
+	Interval  time.Duration // Minimum time between automatic collections
+	Logs      Policy        // Logs of sessions that have exited
+	Caches    Policy        // Workspace caches, such as reqs results
+	Snapshots Policy        // Database snapshots of the current project
+}
+
+// DefaultPolicies returns the policies used when the user config does not set them.
+// Snapshots hold user data and are only collected when a policy is configured.
+func DefaultPolicies() Policies {
+	return Policies{
+		Auto:     true,
+		Interval: 24 * time.Hour,
+		Logs:     Policy{MaxAge: 7 * 24 * time.Hour, MaxSize: 500 << 20},
+		Caches:   Policy{MaxAge: 30 * 24 * time.Hour, MaxSize: 1 << 30},
+	}
+}
+
+// CategoryReport describes what was collected in one category.
+type CategoryReport struct {
+	Name      string   `json:"name"`
+	Removed   []string `json:"removed"`
+	Freed     int64    `json:"freed"`
+	Kept      int      `json:"kept"`
+	KeptSize  int64    `json:"keptSize"`
+	Errors    []string `json:"errors,omitempty"`
+	Unlimited bool     `json:"unlimited,omitempty"` // No policy applies to the category
+}
+
+// Report describes the result of a collection.
+type Report struct {
+	DryRun     bool             `json:"dryRun"`
+	Categories []CategoryReport `json:"categories"`
+	Freed      int64            `json:"freed"`
+}
+
+// config is the part of the user config file that sets collection policies.
+type config struct {
+	GC struct {
+		Auto      *bool         `yaml:"auto"`
+		Interval  string        `yaml:"interval"`
+		Logs      *policyConfig `yaml:"logs"`
+		Caches    *policyConfig `yaml:"caches"`
+		Snapshots *policyConfig `yaml:"snapshots"`
+	} `yaml:"gc"`
+}
+
+// policyConfig is a policy as written in the config file, e.g. maxAge: 7d, maxSize: 500MB.
+type policyConfig struct {
+	MaxAge  string `yaml:"maxAge"`
+	MaxSize string `yaml:"maxSize"`
+}
+
+// item is one removable piece of state.
+type item struct {
+	name     string
+	size     int64
+	time     time.Time
+	orphaned bool // Removed regardless of policy
+	remove   func() error
+}
+
+// LoadPolicies returns the default policies overridden by the gc section of the user config file.
+func LoadPolicies() (Policies, error) {
+	policies := DefaultPolicies()
+
+	path, err := alias.UserConfigPath()
+	if err != nil {
+		return policies, err
+	}
+	if err := security.ValidatePath(path); err != nil {
+		return policies, fmt.Errorf("invalid config path: %w", err)
+	}
+	// #nosec G304 -- Path validated by security.ValidatePath
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return policies, nil
+	}
+	if err != nil {
+		return policies, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var cfg config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return policies, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	if cfg.GC.Auto != nil {
+		policies.Auto = *cfg.GC.Auto
+	}
+	if cfg.GC.Interval != "" {
+		if policies.Interval, err = ParseAge(cfg.GC.Interval); err != nil {
+			return policies, fmt.Errorf("invalid gc.interval in %s: %w", path, err)
+		}
+	}
+	for _, p := range []struct {
+		name   string
+		config *policyConfig
+		target *Policy
+	}{
+		{CategoryLogs, cfg.GC.Logs, &policies.Logs},
+		{CategoryCaches, cfg.GC.Caches, &policies.Caches},
+		{CategorySnapshots, cfg.GC.Snapshots, &policies.Snapshots},
+	} {
+		if p.config == nil {
+			continue
+		}
+		if p.config.MaxAge != "" {
+			if p.target.MaxAge, err = ParseAge(p.config.MaxAge); err != nil {
+				return policies, fmt.Errorf("invalid gc.%s.maxAge in %s: %w", p.name, path, err)
+			}
+		}
+		if p.config.MaxSize != "" {
+			if p.target.MaxSize, err = ParseSize(p.config.MaxSize); err != nil {
+				return policies, fmt.Errorf("invalid gc.%s.maxSize in %s: %w", p.name, path, err)
+			}
+		}
+	}
+	return policies, nil
+}
+
+// Run collects state that falls outside the policies: exited session logs of every workspace,
+// caches of every workspace, and snapshots of projectDir. Workspaces whose directory no longer
+// exists lose their caches and logs, but keep their records in data/. With dryRun, nothing is
+// removed but the report is the same.
+func Run(projectDir string, policies Policies, dryRun bool) (*Report, error) {
+	workspaces, err := statedir.List()
+	if err != nil {
+		return nil, err
+	}
+
+	var logs, caches []item
+	for _, ws := range workspaces {
+		if ws.Sessions == 0 && ws.Path != "" && !exists(ws.Path) {
+			// The workspace is gone, e.g. renamed or on an unmounted drive, so its caches and logs
+			// can't be used again. Its records, such as snapshots and secrets, are kept for when it
+			// returns, and only removed by cache clean
+			if size := ws.CleanableSize(); size > 0 {
+				caches = append(caches, item{
+					name:     ws.Path,
+					size:     size,
+					orphaned: true,
+					remove: func() error {
+						_, err := ws.Clean()
+						return err
+					},
+				})
+			}
+			continue
+		}
+
+		for _, session := range ws.ListSessions() {
+			if session.Running {
+				continue
+			}
+			dir := session.Dir
+			logs = append(logs, item{
+				name:   fmt.Sprintf("%s (session %d)", displayName(ws), session.PID),
+				size:   statedir.DirSize(dir),
+				time:   statedir.LastModified(dir),
+				remove: func() error { return os.RemoveAll(dir) },
+			})
+		}
+
+		cacheDir := ws.CacheDir()
+		if exists(cacheDir) && ws.Sessions == 0 {
+			caches = append(caches, item{
+				name:   displayName(ws),
+				size:   statedir.DirSize(cacheDir),
+				time:   ws.LastUsed,
+				remove: func() error { return os.RemoveAll(cacheDir) },
+			})
+		}
+	}
+
+	var snapshots []item
+	if projectDir != "" {
+		manifests, err := snapshot.List(projectDir)
+		if err != nil {
+			return nil, err
+		}
+		snapshotsDir, err := snapshot.Dir(projectDir)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range manifests {
+			name := m.Name
+			snapshots = append(snapshots, item{
+				name:   name,
+				size:   statedir.DirSize(filepath.Join(snapshotsDir, name)),
+				time:   m.Created,
+				remove: func() error { return snapshot.Delete(projectDir, name) },
+			})
+		}
+	}
+
+	report := &Report{DryRun: dryRun}
+	for _, category := range []struct {
+		name   string
+		items  []item
+		policy Policy
+	}{
+		{CategoryLogs, logs, policies.Logs},
+		{CategoryCaches, caches, policies.Caches},
+		{CategorySnapshots, snapshots, policies.Snapshots},
+	} {
+		result := collect(category.name, category.items, category.policy, dryRun)
+		report.Freed += result.Freed
+		report.Categories = append(report.Categories, result)
+	}
+	return report, nil
+}
+
+// Auto runs a collection with the user's policies when automatic collection is enabled
+// and the configured interval has passed since the last one. It returns nil when skipped.
+func Auto(projectDir string) (*Report, error) {
+	policies, err := LoadPolicies()
+	if err != nil || !policies.Auto {
+		return nil, err
+	}
+
+	root, err := statedir.Root()
+	if err != nil {
+		return nil, err
+	}
+	stampPath := filepath.Join(root, stampFileName)
+	if last := lastRun(stampPath); !last.IsZero() && time.Since(last) < policies.Interval {
+		return nil, nil
+	}
+
+	report, err := Run(projectDir, policies, false)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(map[string]time.Time{"lastRun": time.Now()})
+	if err != nil {
+		return report, fmt.Errorf("failed to marshal gc stamp: %w", err)
+	}
+	if err := os.MkdirAll(root, 0750); err != nil {
+		return report, fmt.Errorf("failed to create state directory: %w", err)
+	}
+	if err := statedir.WriteFile(stampPath, data); err != nil {
+		return report, fmt.Errorf("failed to write gc stamp: %w", err)
+	}
+	return report, nil
+}
+
+// collect removes orphaned items and items older than the policy's maximum age, then the
+// oldest remaining items until their total size is within the policy's maximum size.
+func collect(name string, items []item, policy Policy, dryRun bool) CategoryReport {
+	result := CategoryReport{Name: name, Removed: []string{}}
+	if policy.MaxAge == 0 && policy.MaxSize == 0 {
+		result.Unlimited = true
+	}
+
+	// Newest first, so the size limit keeps the most recent items
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].time.After(items[j].time)
+	})
+
+	var total int64
+	for _, it := range items {
+		expired := it.orphaned ||
+			(policy.MaxAge > 0 && time.Since(it.time) > policy.MaxAge) ||
+			(policy.MaxSize > 0 && total+it.size > policy.MaxSize)
+		if !expired {
+			total += it.size
+			result.Kept++
+			continue
+		}
+		if !dryRun {
+			if err := it.remove(); err != nil {
+				result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", it.name, err))
+				total += it.size
+				result.Kept++
+				continue
+			}
+		}
+		result.Removed = append(result.Removed, it.name)
+		result.Freed += it.size
+	}
+	result.KeptSize = total
+	return result
+}
+
+// ParseAge parses a duration such as "36h" or "7d".
+func ParseAge(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.ParseFloat(days, 64)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid number of days %q", s)
+		}
+		return time.Duration(n * float64(24*time.Hour)), nil
+	}
+	if s == "0" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("negative duration %q", s)
+	}
+	return d, nil
+}
+
+// ParseSize parses a size such as "500MB", "1.5GB", or a plain byte count. Units are powers of 1024.
+func ParseSize(s string) (int64, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	multiplier := int64(1)
+	for _, unit := range []struct {
+		suffix string
+		size   int64
+	}{
+		{"TB", 1 << 40}, {"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}, {"B", 1},
+	} {
+		if number, ok := strings.CutSuffix(s, unit.suffix); ok {
+			s, multiplier = strings.TrimSpace(number), unit.size
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	return int64(n * float64(multiplier)), nil
+}
+
+// lastRun returns when automatic collection last ran, or zero if it never has.
+func lastRun(stampPath string) time.Time {
+	if err := security.ValidatePath(stampPath); err != nil {
+		return time.Time{}
+	}
+	// #nosec G304 -- Path validated by security.ValidatePath
+	data, err := os.ReadFile(stampPath)
+	if err != nil {
+		return time.Time{}
+	}
+	var stamp map[string]time.Time
+	if json.Unmarshal(data, &stamp) != nil {
+		return time.Time{}
+	}
+	return stamp["lastRun"]
+}
+
+// displayName names a workspace by its path, or by its state directory if the path is unknown.
+func displayName(ws statedir.Workspace) string {
+	if ws.Path != "" {
+		return ws.Path
+	}
+	return filepath.Base(ws.Dir)
+}
+
+// exists reports whether path exists.
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
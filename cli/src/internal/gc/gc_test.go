@@ -0,0 +1,316 @@
+package gc
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jongio/azd-app/cli/src/internal/alias"
+	"github.com/jongio/azd-app/cli/src/internal/statedir"
+)
+
+func TestParseAge(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"7d", 7 * 24 * time.Hour, false},
+		{"1.5d", 36 * time.Hour, false},
+		{"36h", 36 * time.Hour, false},
+		{"90m", 90 * time.Minute, false},
+		{"0", 0, false},
+		{"-1h", 0, true},
+		{"week", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseAge(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseAge(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseAge(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    int64
+		wantErr bool
+	}{
+		{"500MB", 500 << 20, false},
+		{"1.5 GB", 3 << 29, false},
+		{"2kb", 2048, false},
+		{"100", 100, false},
+		{"0", 0, false},
+		{"lots", 0, true},
+		{"-1MB", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseSize(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseSize(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseSize(%q) = %d, want %d", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestLoadPolicies(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	t.Setenv(alias.ConfigEnvVar, configPath)
+
+	policies, err := LoadPolicies()
+	if err != nil {
+		t.Fatalf("LoadPolicies without config failed: %v", err)
+	}
+	if policies != DefaultPolicies() {
+		t.Errorf("policies without config = %+v, want defaults", policies)
+	}
+
+	writeTestFile(t, configPath, `gc:
+  auto: false
+  interval: 12h
+  logs:
+    maxAge: 2d
+  snapshots:
+    maxSize: 5GB
+`)
+	policies, err = LoadPolicies()
+	if err != nil {
+		t.Fatalf("LoadPolicies failed: %v", err)
+	}
+	if policies.Auto || policies.Interval != 12*time.Hour {
+		t.Errorf("auto = %v, interval = %v; want false, 12h", policies.Auto, policies.Interval)
+	}
+	if policies.Logs.MaxAge != 48*time.Hour || policies.Logs.MaxSize != DefaultPolicies().Logs.MaxSize {
+		t.Errorf("logs policy = %+v, want 48h and the default size", policies.Logs)
+	}
+	if policies.Snapshots.MaxSize != 5<<30 || policies.Snapshots.MaxAge != 0 {
+		t.Errorf("snapshots policy = %+v, want 5GB and no age limit", policies.Snapshots)
+	}
+
+	writeTestFile(t, configPath, "gc:\n  caches:\n    maxSize: huge\n")
+	if _, err := LoadPolicies(); err == nil || !strings.Contains(err.Error(), "gc.caches.maxSize") {
+		t.Errorf("expected an error naming gc.caches.maxSize, got %v", err)
+	}
+}
+
+func TestCollect(t *testing.T) {
+	now := time.Now()
+	newItem := func(name string, size int64, age time.Duration, removed map[string]bool) item {
+		return item{name: name, size: size, time: now.Add(-age), remove: func() error {
+			removed[name] = true
+			return nil
+		}}
+	}
+
+	tests := []struct {
+		name        string
+		policy      Policy
+		wantRemoved []string
+	}{
+		{"no policy", Policy{}, nil},
+		{"max age", Policy{MaxAge: 48 * time.Hour}, []string{"old"}},
+		{"max size keeps newest", Policy{MaxSize: 250}, []string{"old"}},
+		{"age and size", Policy{MaxAge: 48 * time.Hour, MaxSize: 150}, []string{"middle", "old"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			removed := make(map[string]bool)
+			items := []item{
+				newItem("old", 100, 72*time.Hour, removed),
+				newItem("new", 100, time.Hour, removed),
+				newItem("middle", 100, 24*time.Hour, removed),
+			}
+
+			result := collect("test", items, tt.policy, false)
+
+			if len(result.Removed) != len(tt.wantRemoved) {
+				t.Fatalf("removed %v, want %v", result.Removed, tt.wantRemoved)
+			}
+			for _, name := range tt.wantRemoved {
+				if !removed[name] {
+					t.Errorf("%s was not removed", name)
+				}
+			}
+			if result.Freed != int64(100*len(tt.wantRemoved)) || result.Kept != 3-len(tt.wantRemoved) {
+				t.Errorf("freed %d and kept %d, want %d and %d", result.Freed, result.Kept, 100*len(tt.wantRemoved), 3-len(tt.wantRemoved))
+			}
+		})
+	}
+
+	t.Run("dry run", func(t *testing.T) {
+		removed := make(map[string]bool)
+		result := collect("test", []item{newItem("old", 100, 72*time.Hour, removed)}, Policy{MaxAge: time.Hour}, true)
+		if len(result.Removed) != 1 || result.Freed != 100 || removed["old"] {
+			t.Errorf("dry run = %+v, removed = %v; want it reported but not removed", result, removed)
+		}
+	})
+}
+
+func TestRun(t *testing.T) {
+	t.Setenv(statedir.RootEnvVar, t.TempDir())
+	old := time.Now().Add(-30 * 24 * time.Hour)
+
+	// A workspace with an old finished session and a recent cache
+	project := t.TempDir()
+	cacheDir, err := statedir.CacheDir(project)
+	if err != nil {
+		t.Fatalf("CacheDir failed: %v", err)
+	}
+	writeTestFile(t, filepath.Join(cacheDir, "reqs_cache.json"), "{}")
+	wsDir := filepath.Dir(cacheDir)
+	session := filepath.Join(wsDir, "sessions", strconv.Itoa(exitedPID(t)))
+	writeTestFile(t, filepath.Join(session, "logs", "api.log"), "old logs")
+	ageTree(t, session, old)
+
+	// A workspace whose directory was deleted
+	gone := filepath.Join(t.TempDir(), "gone")
+	if err := os.Mkdir(gone, 0750); err != nil {
+		t.Fatal(err)
+	}
+	goneCache, err := statedir.CacheDir(gone)
+	if err != nil {
+		t.Fatalf("CacheDir failed: %v", err)
+	}
+	if err := os.Remove(gone); err != nil {
+		t.Fatal(err)
+	}
+
+	// A deleted workspace with records, which are kept
+	moved := filepath.Join(t.TempDir(), "moved")
+	if err := os.Mkdir(moved, 0750); err != nil {
+		t.Fatal(err)
+	}
+	movedCache, err := statedir.CacheDir(moved)
+	if err != nil {
+		t.Fatalf("CacheDir failed: %v", err)
+	}
+	writeTestFile(t, filepath.Join(movedCache, "reqs_cache.json"), "{}")
+	movedData, err := statedir.DataDir(moved, "snapshots")
+	if err != nil {
+		t.Fatalf("DataDir failed: %v", err)
+	}
+	writeTestFile(t, filepath.Join(movedData, "db.snapshot"), "rows")
+	if err := os.Remove(moved); err != nil {
+		t.Fatal(err)
+	}
+
+	policies := DefaultPolicies()
+
+	report, err := Run(project, policies, true)
+	if err != nil {
+		t.Fatalf("dry run failed: %v", err)
+	}
+	if _, err := os.Stat(session); err != nil {
+		t.Error("dry run removed a session")
+	}
+	if report.Freed == 0 {
+		t.Error("dry run reported nothing to free")
+	}
+
+	report, err = Run(project, policies, false)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if _, err := os.Stat(session); !os.IsNotExist(err) {
+		t.Error("old session logs were not removed")
+	}
+	if _, err := os.Stat(filepath.Dir(goneCache)); !os.IsNotExist(err) {
+		t.Error("state of a deleted workspace was not removed")
+	}
+	if _, err := os.Stat(movedCache); !os.IsNotExist(err) {
+		t.Error("cache of a deleted workspace was not removed")
+	}
+	if _, err := os.Stat(filepath.Join(movedData, "db.snapshot")); err != nil {
+		t.Errorf("records of a deleted workspace were removed: %v", err)
+	}
+	if _, err := os.Stat(cacheDir); err != nil {
+		t.Errorf("recent cache was removed: %v", err)
+	}
+
+	counts := make(map[string]int)
+	for _, category := range report.Categories {
+		counts[category.Name] = len(category.Removed)
+	}
+	if counts[CategoryLogs] != 1 || counts[CategoryCaches] != 2 || counts[CategorySnapshots] != 0 {
+		t.Errorf("removed per category = %v, want 1 log, 2 caches, 0 snapshots", counts)
+	}
+
+	// Once its caches are gone, a deleted workspace with records has nothing left to collect
+	report, err = Run(project, policies, true)
+	if err != nil {
+		t.Fatalf("second run failed: %v", err)
+	}
+	for _, category := range report.Categories {
+		if category.Name == CategoryCaches && len(category.Removed) != 0 {
+			t.Errorf("second run removed caches %v, want none", category.Removed)
+		}
+	}
+}
+
+func TestAutoInterval(t *testing.T) {
+	root := t.TempDir()
+	t.Setenv(statedir.RootEnvVar, root)
+	t.Setenv(alias.ConfigEnvVar, filepath.Join(t.TempDir(), "config.yaml"))
+	project := t.TempDir()
+
+	report, err := Auto(project)
+	if err != nil || report == nil {
+		t.Fatalf("first Auto = %v, %v; want a report", report, err)
+	}
+	if _, err := os.Stat(filepath.Join(root, stampFileName)); err != nil {
+		t.Errorf("stamp was not written: %v", err)
+	}
+
+	report, err = Auto(project)
+	if err != nil || report != nil {
+		t.Errorf("second Auto = %v, %v; want it skipped within the interval", report, err)
+	}
+}
+
+// exitedPID returns a PID that is not in use by any running process.
+func exitedPID(t *testing.T) int {
+	t.Helper()
+	for pid := 999999; pid > 900000; pid-- {
+		if !statedir.ProcessAlive(pid) {
+			return pid
+		}
+	}
+	t.Skip("no unused PID found")
+	return 0
+}
+
+// ageTree sets the modification time of dir and everything under it.
+func ageTree(t *testing.T, dir string, when time.Time) {
+	t.Helper()
+	err := filepath.Walk(dir, func(path string, _ os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		return os.Chtimes(path, when, when)
+	})
+	if err != nil {
+		t.Fatalf("failed to age %s: %v", dir, err)
+	}
+}
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("failed to write %s: %v", path, err)
+	}
+}
@@ -0,0 +1,367 @@
+// Package guide supports the interactive first-run walkthrough: detecting a fresh
+// template, suggesting install commands for missing tools, and collecting values for
+// environment variables the app expects.
+package guide
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/jongio/azd-app/cli/src/internal/registry"
+	"github.com/jongio/azd-app/cli/src/internal/security"
+	"github.com/jongio/azd-app/cli/src/internal/service"
+	"github.com/jongio/azd-app/cli/src/internal/statedir"
+)
+
+// EnvTemplateFiles are the files templates use to document the environment variables they need.
+var EnvTemplateFiles = []string{".env.example", ".env.sample", ".env.template"}
+
+// envReferencePattern matches ${VAR} and $VAR references in azure.yaml env values.
+var envReferencePattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}|\$([A-Za-z_][A-Za-z0-9_]*)`)
+
+// installCommands maps tool names to install commands by operating system.
+// The "" entry applies to every operating system.
+var installCommands = map[string]map[string]string{
+	"node": {
+		"windows": "winget install OpenJS.NodeJS.LTS",
+		"darwin":  "brew install node",
+		"linux":   "curl -fsSL https://fnm.vercel.app/install | bash && fnm install --lts",
+	},
+	"pnpm":   {"": "npm install -g pnpm"},
+	"yarn":   {"": "npm install -g yarn"},
+	"poetry": {"": "pipx install poetry"},
+	"pipenv": {"": "pipx install pipenv"},
+	"python": {
+		"windows": "winget install Python.Python.3.12",
+		"darwin":  "brew install python",
+		"linux":   "sudo apt-get install -y python3 python3-venv python3-pip",
+	},
+	"uv": {
+		"windows": "winget install astral-sh.uv",
+		"":        "curl -LsSf https://astral.sh/uv/install.sh | sh",
+	},
+	"dotnet": {
+		"windows": "winget install Microsoft.DotNet.SDK.9",
+		"darwin":  "brew install --cask dotnet-sdk",
+		"linux":   "curl -fsSL https://dot.net/v1/dotnet-install.sh | bash",
+	},
+	"aspire": {"": "dotnet tool install -g Aspire.Cli"},
+	"func":   {"": "npm install -g azure-functions-core-tools@4"},
+	"swa":    {"": "npm install -g @azure/static-web-apps-cli"},
+	"docker": {
+		"windows": "winget install Docker.DockerDesktop",
+		"darwin":  "brew install --cask docker",
+		"linux":   "curl -fsSL https://get.docker.com | sh",
+	},
+	"git": {
+		"windows": "winget install Git.Git",
+		"darwin":  "brew install git",
+		"linux":   "sudo apt-get install -y git",
+	},
+	"go": {
+		"windows": "winget install GoLang.Go",
+		"darwin":  "brew install go",
+		"linux":   "sudo apt-get install -y golang",
+	},
+	"azd": {
+		"windows": "winget install Microsoft.Azd",
+		"darwin":  "brew tap azure/azd && brew install azd",
+		"linux":   "curl -fsSL https://aka.ms/install-azd.sh | bash",
+	},
+	"az": {
+		"windows": "winget install Microsoft.AzureCLI",
+		"darwin":  "brew install azure-cli",
+		"linux":   "curl -sL https://aka.ms/InstallAzureCLIDeb | sudo bash",
+	},
+}
+
+// toolAliases maps tools that ship with another tool to the tool that installs them.
+var toolAliases = map[string]string{
+	"npm":        "node",
+	"nodejs":     "node",
+	"pip":        "python",
+	"azure-cli":  "az",
+	"python3":    "python",
+	"dotnet-sdk": "dotnet",
+}
+
+// MissingEnvVar is an environment variable the app expects but that has no value.
+type MissingEnvVar struct {
+	Name    string `json:"name"`
+	Source  string `json:"source"`            // File that references the variable, relative to the project
+	Default string `json:"default,omitempty"` // Example value from the template file, if any
+}
+
+// IsFirstRun reports whether the app has never been run or installed in projectDir,
+// based on the workspace's service registry and cache directory.
+func IsFirstRun(projectDir string) bool {
+	var paths []string
+	if path, err := registry.Path(projectDir); err == nil {
+		paths = append(paths, path)
+	}
+	if wsDir, err := statedir.WorkspaceDir(projectDir); err == nil {
+		paths = append(paths, filepath.Join(wsDir, "cache"))
+	}
+	for _, path := range paths {
+		if _, err := os.Stat(path); err == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// IsInteractive reports whether stdin is a terminal a user can answer prompts on.
+func IsInteractive() bool {
+	info, err := os.Stdin.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// InstallCommand returns a command that installs a tool on the current operating system,
+// or an empty string if none is known.
+func InstallCommand(tool string) string {
+	return installCommandFor(tool, runtime.GOOS)
+}
+
+// installCommandFor returns the install command for a tool on goos.
+func installCommandFor(tool, goos string) string {
+	tool = strings.ToLower(tool)
+	if alias, ok := toolAliases[tool]; ok {
+		tool = alias
+	}
+	commands := installCommands[tool]
+	if command, ok := commands[goos]; ok {
+		return command
+	}
+	return commands[""]
+}
+
+// MissingEnvVars returns the environment variables the app expects that have no value in
+// the OS environment or in the project's .env file. Variables are collected from
+// .env.example, .env.sample, and .env.template files in the project and service directories,
+// and from ${VAR} references in azure.yaml service env values.
+func MissingEnvVars(projectDir string, azureYaml *service.AzureYaml) ([]MissingEnvVar, error) {
+	known, err := service.LoadEnvFileIfExists(projectDir, ".env")
+	if err != nil {
+		return nil, err
+	}
+	isSet := func(name string) bool {
+		if _, ok := known[name]; ok {
+			return true
+		}
+		_, ok := os.LookupEnv(name)
+		return ok
+	}
+
+	var missing []MissingEnvVar
+	seen := make(map[string]bool)
+	add := func(name, source, def string) {
+		if seen[name] || isSet(name) {
+			return
+		}
+		seen[name] = true
+		missing = append(missing, MissingEnvVar{Name: name, Source: source, Default: def})
+	}
+
+	dirs := []string{projectDir}
+	names := make([]string, 0, len(azureYaml.Services))
+	for name := range azureYaml.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if project := azureYaml.Services[name].Project; project != "" {
+			dirs = append(dirs, filepath.Join(projectDir, project))
+		}
+	}
+
+	for _, dir := range dirs {
+		serviceEnv, err := service.LoadEnvFileIfExists(dir, ".env")
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range EnvTemplateFiles {
+			path := filepath.Join(dir, file)
+			template, err := readEnvTemplate(path)
+			if err != nil {
+				continue
+			}
+			source, _ := filepath.Rel(projectDir, path)
+			for _, entry := range template {
+				if _, ok := serviceEnv[entry.Name]; !ok {
+					add(entry.Name, filepath.ToSlash(source), entry.Default)
+				}
+			}
+		}
+	}
+
+	// Service env values can only reference variables, since their own values come from azure.yaml
+	defined := make(map[string]bool)
+	for _, name := range names {
+		for _, envVar := range azureYaml.Services[name].Env {
+			defined[envVar.Name] = true
+		}
+	}
+	for _, name := range names {
+		for _, envVar := range azureYaml.Services[name].Env {
+			for _, match := range envReferencePattern.FindAllStringSubmatch(envVar.Value+" "+envVar.Secret, -1) {
+				ref := firstNonEmpty(match[1], match[2])
+				if !defined[ref] {
+					add(ref, "azure.yaml", "")
+				}
+			}
+		}
+	}
+
+	return missing, nil
+}
+
+// AppendEnvFile appends variables to the .env file at path, creating it if needed.
+// Values containing whitespace or # are quoted.
+func AppendEnvFile(path string, values map[string]string) error {
+	if len(values) == 0 {
+		return nil
+	}
+	if err := security.ValidatePath(path); err != nil {
+		return fmt.Errorf("invalid .env file path: %w", err)
+	}
+
+	var b strings.Builder
+	// Start on a fresh line if the existing file does not end with one
+	// #nosec G304 -- Path validated by security.ValidatePath
+	if data, err := os.ReadFile(path); err == nil && len(data) > 0 && data[len(data)-1] != '\n' {
+		b.WriteString("\n")
+	}
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		value := values[name]
+		if strings.ContainsAny(value, " \t#") {
+			value = `"` + value + `"`
+		}
+		fmt.Fprintf(&b, "%s=%s\n", name, value)
+	}
+
+	// #nosec G304 -- Path validated by security.ValidatePath
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open .env file: %w", err)
+	}
+	if _, err := file.WriteString(b.String()); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to write .env file: %w", err)
+	}
+	return file.Close()
+}
+
+// Prompter asks the user questions on a terminal.
+type Prompter struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+// NewPrompter creates a Prompter that reads answers from in and writes questions to out.
+func NewPrompter(in io.Reader, out io.Writer) *Prompter {
+	return &Prompter{in: bufio.NewReader(in), out: out}
+}
+
+// Confirm asks a yes/no question. An empty answer returns defaultYes.
+func (p *Prompter) Confirm(question string, defaultYes bool) bool {
+	hint := "y/N"
+	if defaultYes {
+		hint = "Y/n"
+	}
+	answer := strings.ToLower(p.Ask(fmt.Sprintf("%s (%s)", question, hint), ""))
+	if answer == "" {
+		return defaultYes
+	}
+	return answer == "y" || answer == "yes"
+}
+
+// Ask asks for a value. An empty answer, or no more input, returns def.
+func (p *Prompter) Ask(question, def string) string {
+	if def != "" {
+		fmt.Fprintf(p.out, "%s [%s]: ", question, def)
+	} else {
+		fmt.Fprintf(p.out, "%s: ", question)
+	}
+	line, err := p.in.ReadString('\n')
+	if err != nil && line == "" {
+		fmt.Fprintln(p.out)
+		return def
+	}
+	if answer := strings.TrimSpace(line); answer != "" {
+		return answer
+	}
+	return def
+}
+
+// envTemplateEntry is a variable listed in an env template file.
+type envTemplateEntry struct {
+	Name    string
+	Default string
+}
+
+// readEnvTemplate reads the variables of an env template file in file order.
+// Placeholder values such as "<your-key>" or "changeme" are not used as defaults.
+func readEnvTemplate(path string) ([]envTemplateEntry, error) {
+	if err := security.ValidatePath(path); err != nil {
+		return nil, err
+	}
+	// #nosec G304 -- Path validated by security.ValidatePath
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []envTemplateEntry
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		name, value, ok := strings.Cut(line, "=")
+		name = strings.TrimSpace(strings.TrimPrefix(name, "export "))
+		if !ok || name == "" {
+			continue
+		}
+		value = strings.Trim(strings.TrimSpace(value), `"'`)
+		if isPlaceholder(value) {
+			value = ""
+		}
+		entries = append(entries, envTemplateEntry{Name: name, Default: value})
+	}
+	return entries, nil
+}
+
+// isPlaceholder reports whether an example value is a placeholder rather than a usable default.
+func isPlaceholder(value string) bool {
+	lower := strings.ToLower(value)
+	return strings.HasPrefix(lower, "<") ||
+		strings.Contains(lower, "your") ||
+		strings.Contains(lower, "changeme") ||
+		strings.Contains(lower, "replace") ||
+		strings.Contains(lower, "xxx")
+}
+
+// firstNonEmpty returns the first non-empty string.
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
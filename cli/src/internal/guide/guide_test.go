@@ -0,0 +1,156 @@
+package guide
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jongio/azd-app/cli/src/internal/registry"
+	"github.com/jongio/azd-app/cli/src/internal/service"
+	"github.com/jongio/azd-app/cli/src/internal/statedir"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("failed to write %s: %v", path, err)
+	}
+}
+
+func TestIsFirstRun(t *testing.T) {
+	t.Setenv(statedir.RootEnvVar, t.TempDir())
+
+	dir := t.TempDir()
+	if !IsFirstRun(dir) {
+		t.Error("expected first run in empty project")
+	}
+
+	cached := t.TempDir()
+	if _, err := statedir.CacheDir(cached); err != nil {
+		t.Fatalf("CacheDir failed: %v", err)
+	}
+	if IsFirstRun(cached) {
+		t.Error("expected not first run once requirements have been cached")
+	}
+
+	registryPath, err := registry.Path(dir)
+	if err != nil {
+		t.Fatalf("registry.Path failed: %v", err)
+	}
+	writeFile(t, registryPath, "{}")
+	if IsFirstRun(dir) {
+		t.Error("expected not first run once services have been registered")
+	}
+}
+
+func TestInstallCommandFor(t *testing.T) {
+	tests := []struct {
+		tool string
+		goos string
+		want string
+	}{
+		{"node", "windows", "winget install OpenJS.NodeJS.LTS"},
+		{"npm", "darwin", "brew install node"},
+		{"pnpm", "linux", "npm install -g pnpm"},
+		{"uv", "windows", "winget install astral-sh.uv"},
+		{"uv", "darwin", "curl -LsSf https://astral.sh/uv/install.sh | sh"},
+		{"Docker", "darwin", "brew install --cask docker"},
+		{"unknown-tool", "linux", ""},
+	}
+	for _, tt := range tests {
+		if got := installCommandFor(tt.tool, tt.goos); got != tt.want {
+			t.Errorf("installCommandFor(%q, %q) = %q, want %q", tt.tool, tt.goos, got, tt.want)
+		}
+	}
+}
+
+func TestMissingEnvVars(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, ".env.example"), "# Settings\nAPI_KEY=<your-api-key>\nLOG_LEVEL=info\nALREADY_SET=x\n")
+	writeFile(t, filepath.Join(dir, ".env"), "ALREADY_SET=y\n")
+	writeFile(t, filepath.Join(dir, "api", ".env.sample"), "export DB_URL=\"postgres://localhost/app\"\nAPI_KEY=\n")
+	t.Setenv("GUIDE_TEST_FROM_OS", "1")
+
+	azureYaml := &service.AzureYaml{Services: map[string]service.Service{
+		"api": {
+			Project: "./api",
+			Env: []service.EnvVar{
+				{Name: "CONNECTION", Value: "${DB_URL}"},
+				{Name: "OTHER", Value: "$CONNECTION-${GUIDE_TEST_FROM_OS}-${STORAGE_NAME}"},
+			},
+		},
+	}}
+
+	vars, err := MissingEnvVars(dir, azureYaml)
+	if err != nil {
+		t.Fatalf("MissingEnvVars failed: %v", err)
+	}
+
+	want := []MissingEnvVar{
+		{Name: "API_KEY", Source: ".env.example"},
+		{Name: "LOG_LEVEL", Source: ".env.example", Default: "info"},
+		{Name: "DB_URL", Source: "api/.env.sample", Default: "postgres://localhost/app"},
+		{Name: "STORAGE_NAME", Source: "azure.yaml"},
+	}
+	if len(vars) != len(want) {
+		t.Fatalf("got %+v, want %+v", vars, want)
+	}
+	for i := range want {
+		if vars[i] != want[i] {
+			t.Errorf("vars[%d] = %+v, want %+v", i, vars[i], want[i])
+		}
+	}
+}
+
+func TestAppendEnvFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	writeFile(t, path, "EXISTING=1")
+
+	if err := AppendEnvFile(path, map[string]string{"B": "two words", "A": "plain"}); err != nil {
+		t.Fatalf("AppendEnvFile failed: %v", err)
+	}
+
+	data, _ := os.ReadFile(path)
+	want := "EXISTING=1\nA=plain\nB=\"two words\"\n"
+	if string(data) != want {
+		t.Errorf(".env = %q, want %q", data, want)
+	}
+
+	env, err := service.LoadDotEnv(path)
+	if err != nil {
+		t.Fatalf("LoadDotEnv failed: %v", err)
+	}
+	if env["B"] != "two words" {
+		t.Errorf("B = %q, want %q", env["B"], "two words")
+	}
+}
+
+func TestPrompter(t *testing.T) {
+	var out bytes.Buffer
+	p := NewPrompter(strings.NewReader("\nno\nvalue\n\n"), &out)
+
+	if !p.Confirm("Start?", true) {
+		t.Error("empty answer should use default yes")
+	}
+	if p.Confirm("Continue?", true) {
+		t.Error("'no' should decline")
+	}
+	if got := p.Ask("Name", "default"); got != "value" {
+		t.Errorf("Ask = %q, want %q", got, "value")
+	}
+	if got := p.Ask("Port", "8080"); got != "8080" {
+		t.Errorf("Ask = %q, want default %q", got, "8080")
+	}
+	// Input is exhausted; defaults are used
+	if got := p.Ask("Region", "eastus"); got != "eastus" {
+		t.Errorf("Ask after EOF = %q, want %q", got, "eastus")
+	}
+	if !strings.Contains(out.String(), "Start? (Y/n): ") || !strings.Contains(out.String(), "Port [8080]: ") {
+		t.Errorf("unexpected prompts: %q", out.String())
+	}
+}
@@ -0,0 +1,194 @@
+// Package helptopics holds the extended help of azd app, compiled into the binary so it can be
+// read and searched without network access.
+package helptopics
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Categories of topics, in the order they are listed.
+const (
+	CategoryConcepts        = "concepts"
+	CategoryConfiguration   = "configuration"
+	CategoryTroubleshooting = "troubleshooting"
+)
+
+// Categories lists the categories in the order they are listed.
+var Categories = []string{CategoryConcepts, CategoryConfiguration, CategoryTroubleshooting}
+
+// maxSnippets is how many matching lines Search returns per topic.
+const maxSnippets = 3
+
+//go:embed topics
+var files embed.FS
+
+// Topic is a help topic: a Markdown file of topics/<category>/<name>.md, whose first line is
+// its "# Title" and whose first paragraph is its summary.
+type Topic struct {
+	Name     string `json:"name"`
+	Category string `json:"category"`
+	Title    string `json:"title"`
+	Summary  string `json:"summary"`
+	Body     string `json:"body,omitempty"` // Markdown after the title
+}
+
+// Match is a topic found by Search.
+type Match struct {
+	Topic
+	Score    int      `json:"score"`
+	Snippets []string `json:"snippets,omitempty"` // Lines of the body that contain a search term
+}
+
+// All returns every topic, by category and then name, without their body.
+func All() []Topic {
+	topics := load()
+	for i := range topics {
+		topics[i].Body = ""
+	}
+	return topics
+}
+
+// Get returns the topic called name, with its body.
+func Get(name string) (Topic, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for _, topic := range load() {
+		if topic.Name == name {
+			return topic, nil
+		}
+	}
+	return Topic{}, fmt.Errorf("no help topic %q", name)
+}
+
+// Similar returns the names of the topics that contain name, or that name contains, for
+// suggestions when Get finds none.
+func Similar(name string) []string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	var similar []string
+	for _, topic := range load() {
+		if name != "" && (strings.Contains(topic.Name, name) || strings.Contains(name, topic.Name)) {
+			similar = append(similar, topic.Name)
+		}
+	}
+	return similar
+}
+
+// Search returns the topics that contain every word of query, in any case, best first. A word
+// in the name or title counts more than one in the summary, which counts more than one in the
+// body.
+func Search(query string) []Match {
+	terms := strings.Fields(strings.ToLower(query))
+	if len(terms) == 0 {
+		return nil
+	}
+	var matches []Match
+	for _, topic := range load() {
+		match := Match{Topic: topic}
+		heading := strings.ToLower(topic.Name + " " + topic.Title)
+		summary := strings.ToLower(topic.Summary)
+		body := strings.ToLower(topic.Body)
+		for _, term := range terms {
+			score := 10*strings.Count(heading, term) + 3*strings.Count(summary, term) + strings.Count(body, term)
+			if score == 0 {
+				match.Score = 0
+				break
+			}
+			match.Score += score
+		}
+		if match.Score == 0 {
+			continue
+		}
+		match.Snippets = snippets(topic.Body, terms)
+		match.Body = ""
+		matches = append(matches, match)
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].Score > matches[j].Score })
+	return matches
+}
+
+// snippets returns up to maxSnippets lines of body after its summary that contain the most
+// distinct terms, in the order of the body, trimmed of Markdown list and heading markers.
+func snippets(body string, terms []string) []string {
+	_, rest, _ := strings.Cut(body, "\n\n")
+	type line struct {
+		index, hits int
+		text        string
+	}
+	var lines []line
+	for i, text := range strings.Split(rest, "\n") {
+		lower := strings.ToLower(text)
+		hits := 0
+		for _, term := range terms {
+			if strings.Contains(lower, term) {
+				hits++
+			}
+		}
+		if hits > 0 {
+			lines = append(lines, line{index: i, hits: hits, text: strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(text), "#-*|> "))})
+		}
+	}
+	sort.SliceStable(lines, func(i, j int) bool { return lines[i].hits > lines[j].hits })
+	if len(lines) > maxSnippets {
+		lines = lines[:maxSnippets]
+	}
+	sort.Slice(lines, func(i, j int) bool { return lines[i].index < lines[j].index })
+	texts := make([]string, len(lines))
+	for i, l := range lines {
+		texts[i] = l.text
+	}
+	return texts
+}
+
+// load parses the embedded topics, sorted by category and then name.
+func load() []Topic {
+	var topics []Topic
+	_ = fs.WalkDir(files, "topics", func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || path.Ext(p) != ".md" {
+			return err
+		}
+		data, err := files.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		topic := parse(string(data))
+		topic.Name = strings.TrimSuffix(path.Base(p), ".md")
+		topic.Category = path.Base(path.Dir(p))
+		topics = append(topics, topic)
+		return nil
+	})
+	sort.Slice(topics, func(i, j int) bool {
+		ci, cj := categoryRank(topics[i].Category), categoryRank(topics[j].Category)
+		if ci != cj {
+			return ci < cj
+		}
+		return topics[i].Name < topics[j].Name
+	})
+	return topics
+}
+
+// parse returns the title, summary, and body of the Markdown of a topic.
+func parse(markdown string) Topic {
+	markdown = strings.ReplaceAll(markdown, "\r\n", "\n")
+	title, body, _ := strings.Cut(markdown, "\n")
+	body = strings.TrimSpace(body)
+	summary, _, _ := strings.Cut(body, "\n\n")
+	return Topic{
+		Title:   strings.TrimSpace(strings.TrimPrefix(title, "#")),
+		Summary: strings.Join(strings.Fields(summary), " "),
+		Body:    body,
+	}
+}
+
+// categoryRank returns the position of category in Categories, and other categories after them.
+func categoryRank(category string) int {
+	for i, c := range Categories {
+		if c == category {
+			return i
+		}
+	}
+	return len(Categories)
+}
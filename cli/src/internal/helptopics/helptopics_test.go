@@ -0,0 +1,88 @@
+package helptopics
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestTopics(t *testing.T) {
+	topics := load()
+	if len(topics) == 0 {
+		t.Fatal("no topics embedded")
+	}
+	names := make(map[string]bool)
+	for _, topic := range topics {
+		if names[topic.Name] {
+			t.Errorf("topic %s is defined twice", topic.Name)
+		}
+		names[topic.Name] = true
+		if !slices.Contains(Categories, topic.Category) {
+			t.Errorf("topic %s has unknown category %s", topic.Name, topic.Category)
+		}
+		if topic.Title == "" || topic.Summary == "" {
+			t.Errorf("topic %s needs a '# Title' line and a summary paragraph", topic.Name)
+		}
+	}
+
+	// Every topic a "See also" line names exists
+	for _, topic := range topics {
+		for _, line := range strings.Split(topic.Body, "\n") {
+			refs, ok := strings.CutPrefix(line, "See also: ")
+			if !ok {
+				continue
+			}
+			for _, ref := range strings.Split(refs, ",") {
+				if ref = strings.TrimSpace(ref); !names[ref] {
+					t.Errorf("topic %s refers to unknown topic %q", topic.Name, ref)
+				}
+			}
+		}
+	}
+}
+
+func TestGet(t *testing.T) {
+	topic, err := Get(" Port-In-Use ")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if topic.Category != CategoryTroubleshooting || topic.Title != "Port in use" || !strings.Contains(topic.Body, "## A port set in azure.yaml") {
+		t.Errorf("Get() = %+v", topic)
+	}
+	if _, err := Get("nope"); err == nil {
+		t.Error("expected error for an unknown topic")
+	}
+	if got := Similar("port"); !slices.Equal(got, []string{"port-in-use"}) {
+		t.Errorf("Similar(port) = %v", got)
+	}
+	for _, topic := range All() {
+		if topic.Body != "" {
+			t.Errorf("All() includes the body of %s", topic.Name)
+		}
+	}
+}
+
+func TestSearch(t *testing.T) {
+	matches := Search("Port in use")
+	if len(matches) == 0 || matches[0].Name != "port-in-use" {
+		t.Fatalf("Search() = %+v, want port-in-use first", matches)
+	}
+	if len(matches[0].Snippets) == 0 || len(matches[0].Snippets) > maxSnippets {
+		t.Errorf("snippets = %q", matches[0].Snippets)
+	}
+
+	// Every word must be found
+	if matches := Search("port zebra"); len(matches) != 0 {
+		t.Errorf("Search() = %+v, want no matches", matches)
+	}
+	if matches := Search("  "); matches != nil {
+		t.Errorf("Search() of nothing = %+v", matches)
+	}
+}
+
+func TestParse(t *testing.T) {
+	topic := parse("# Title\r\n\r\nFirst line\r\nof the summary.\r\n\r\n## Section\r\nText\r\n")
+	if topic.Title != "Title" || topic.Summary != "First line of the summary." || !strings.HasPrefix(topic.Body, "First line") {
+		t.Errorf("parse() = %+v", topic)
+	}
+}
@@ -0,0 +1,144 @@
+package httpclient
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jongio/azd-app/cli/src/internal/security"
+	"github.com/jongio/azd-app/cli/src/internal/statedir"
+)
+
+// cachedHeaders are the response headers kept with a cached body.
+var cachedHeaders = []string{"Content-Type", "Cache-Control", "ETag", "Last-Modified"}
+
+// diskCache stores GET responses as one JSON file per URL. Files are written atomically, so
+// concurrent processes can share the cache.
+type diskCache struct {
+	dir string
+}
+
+// cacheEntry is a cached response.
+type cacheEntry struct {
+	URL          string        `json:"url"`
+	Header       http.Header   `json:"header"`
+	Body         []byte        `json:"body"`
+	ETag         string        `json:"etag,omitempty"`
+	LastModified string        `json:"lastModified,omitempty"`
+	MaxAge       time.Duration `json:"maxAge"`
+	Validated    time.Time     `json:"validated"` // Last time the server returned or confirmed the body
+}
+
+// newCacheEntry returns the cache entry for a 200 response to a GET of url, or nil when the
+// response must not be stored or could never be reused: no-store, or neither a lifetime nor a
+// validator.
+func newCacheEntry(url string, resp *Response, now time.Time) *cacheEntry {
+	maxAge, store := cacheControl(resp.Header)
+	if !store {
+		return nil
+	}
+	entry := &cacheEntry{
+		URL:          url,
+		Header:       make(http.Header),
+		Body:         resp.Body,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		MaxAge:       maxAge,
+		Validated:    now,
+	}
+	if entry.MaxAge == 0 && entry.ETag == "" && entry.LastModified == "" {
+		return nil
+	}
+	for _, name := range cachedHeaders {
+		if value := resp.Header.Get(name); value != "" {
+			entry.Header.Set(name, value)
+		}
+	}
+	return entry
+}
+
+// fresh reports whether the entry can be used without asking the server.
+func (e *cacheEntry) fresh(now time.Time) bool {
+	return now.Before(e.Validated.Add(e.MaxAge))
+}
+
+// refresh records that the server confirmed the entry with a 304 response carrying header.
+func (e *cacheEntry) refresh(header http.Header, now time.Time) {
+	if header.Get("Cache-Control") != "" {
+		e.MaxAge, _ = cacheControl(header)
+		e.Header.Set("Cache-Control", header.Get("Cache-Control"))
+	}
+	if etag := header.Get("ETag"); etag != "" {
+		e.ETag = etag
+		e.Header.Set("ETag", etag)
+	}
+	e.Validated = now
+}
+
+// response returns the entry as a response.
+func (e *cacheEntry) response() *Response {
+	return &Response{StatusCode: http.StatusOK, Header: e.Header.Clone(), Body: e.Body, FromCache: true}
+}
+
+// cacheControl returns the lifetime set by the Cache-Control header, and false for no-store.
+func cacheControl(header http.Header) (time.Duration, bool) {
+	var maxAge time.Duration
+	for _, directive := range strings.Split(strings.ToLower(header.Get("Cache-Control")), ",") {
+		directive = strings.TrimSpace(directive)
+		switch {
+		case directive == "no-store":
+			return 0, false
+		case directive == "no-cache":
+			return 0, true
+		case strings.HasPrefix(directive, "max-age="):
+			if seconds, err := strconv.Atoi(strings.TrimPrefix(directive, "max-age=")); err == nil && seconds > 0 {
+				maxAge = time.Duration(seconds) * time.Second
+			}
+		}
+	}
+	return maxAge, true
+}
+
+// path returns the cache file of url.
+func (c *diskCache) path(url string) string {
+	return filepath.Join(c.dir, fmt.Sprintf("%x.json", sha256.Sum256([]byte(url))))
+}
+
+// get returns the entry for url, or nil when there is none or it cannot be read.
+func (c *diskCache) get(url string) *cacheEntry {
+	path := c.path(url)
+	if err := security.ValidatePath(path); err != nil {
+		return nil
+	}
+	// #nosec G304 -- Path validated by security.ValidatePath
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil || entry.URL != url {
+		return nil
+	}
+	if entry.Header == nil {
+		entry.Header = make(http.Header)
+	}
+	return &entry
+}
+
+// put stores entry. The cache is best effort, so failures are ignored.
+func (c *diskCache) put(entry *cacheEntry) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(c.dir, 0750); err != nil {
+		return
+	}
+	_ = statedir.WriteFile(c.path(entry.URL), data)
+}
@@ -0,0 +1,298 @@
+// Package httpclient is the shared client for requests to remote services, such as package
+// registries and template galleries.
+//
+// Requests to the same host are spaced by a minimum interval, so batch scans do not hammer a
+// registry. Failed requests and 429, 502, 503, and 504 responses are retried with exponential
+// backoff and jitter, honoring Retry-After. Successful GET responses are cached on disk: they
+// are reused while fresh according to Cache-Control max-age, and revalidated with their ETag or
+// Last-Modified date once stale.
+//
+// Requests to services running on this machine, such as health probes and load tests, use
+// NewLocal instead: they must see every response as it comes, without retries or caching.
+package httpclient
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/jongio/azd-app/cli/src/internal/retry"
+	"github.com/jongio/azd-app/cli/src/internal/statedir"
+)
+
+// Defaults used for zero Options fields. Retries use retry.DefaultNetwork.
+const (
+	DefaultTimeout     = 30 * time.Second
+	DefaultMinInterval = 200 * time.Millisecond
+	DefaultUserAgent   = "azd-app"
+)
+
+// retryStatus lists the response statuses that are retried.
+var retryStatus = map[int]bool{
+	http.StatusTooManyRequests:    true,
+	http.StatusBadGateway:         true,
+	http.StatusServiceUnavailable: true,
+	http.StatusGatewayTimeout:     true,
+}
+
+// Options configures a Client. Zero values use the defaults.
+type Options struct {
+	CacheDir    string            // Optional: directory for cached GET responses; empty disables caching
+	Timeout     time.Duration     // Timeout of each attempt
+	Retry       retry.Policy      // Retries of failed requests; Retry-After values over its MaxDelay are not waited for
+	MinInterval time.Duration     // Minimum time between requests to the same host; negative disables the limit
+	UserAgent   string            // User-Agent header of every request
+	Transport   http.RoundTripper // Optional: transport of the underlying http.Client
+}
+
+// Client sends rate-limited, retried, and cached HTTP requests. It is safe for concurrent use.
+type Client struct {
+	opts   Options
+	http   *http.Client
+	cache  *diskCache
+	mu     sync.Mutex
+	nextAt map[string]time.Time // Earliest time of the next request to each host
+}
+
+// Response is a response whose body has been read.
+type Response struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+	FromCache  bool // The body was served from the cache, while fresh or after a 304 Not Modified
+}
+
+var (
+	defaultClient     *Client
+	defaultClientOnce sync.Once
+	defaultRetry      retry.Policy
+)
+
+// SetDefaultRetry sets the retry policy of the client returned by Default. It has no effect
+// once Default has been called.
+func SetDefaultRetry(policy retry.Policy) {
+	defaultRetry = policy
+}
+
+// Default returns the client shared by the whole process. Its cache is in the http folder of
+// the state root; without a state root, responses are not cached.
+func Default() *Client {
+	defaultClientOnce.Do(func() {
+		opts := Options{Retry: defaultRetry}
+		if dir, err := statedir.HTTPCacheDir(); err == nil {
+			opts.CacheDir = dir
+		}
+		defaultClient = New(opts)
+	})
+	return defaultClient
+}
+
+// New creates a client.
+func New(opts Options) *Client {
+	if opts.Timeout == 0 {
+		opts.Timeout = DefaultTimeout
+	}
+	if opts.Retry == (retry.Policy{}) {
+		opts.Retry = retry.DefaultNetwork
+	}
+	if opts.MinInterval == 0 {
+		opts.MinInterval = DefaultMinInterval
+	}
+	if opts.UserAgent == "" {
+		opts.UserAgent = DefaultUserAgent
+	}
+
+	c := &Client{
+		opts:   opts,
+		http:   &http.Client{Timeout: opts.Timeout, Transport: opts.Transport},
+		nextAt: make(map[string]time.Time),
+	}
+	if opts.CacheDir != "" {
+		c.cache = &diskCache{dir: opts.CacheDir}
+	}
+	return c
+}
+
+// NewLocal returns a client for requests to services running on this machine. It doesn't
+// retry, space, or cache requests, and returns redirects as they are instead of following them.
+func NewLocal(timeout time.Duration) *http.Client {
+	return &http.Client{
+		Timeout: timeout,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// Get fetches url.
+func (c *Client) Get(ctx context.Context, url string) (*Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL %s: %w", url, err)
+	}
+	return c.Do(req)
+}
+
+// Do sends req. Only idempotent requests are retried, and only GET requests are cached.
+// Responses with any status are returned without an error; check StatusCode.
+func (c *Client) Do(req *http.Request) (*Response, error) {
+	req.Header.Set("User-Agent", c.opts.UserAgent)
+
+	var cached *cacheEntry
+	if c.cache != nil && req.Method == http.MethodGet {
+		cached = c.cache.get(req.URL.String())
+		if cached != nil && cached.fresh(time.Now()) {
+			return cached.response(), nil
+		}
+		if cached != nil {
+			if cached.ETag != "" {
+				req.Header.Set("If-None-Match", cached.ETag)
+			}
+			if cached.LastModified != "" {
+				req.Header.Set("If-Modified-Since", cached.LastModified)
+			}
+		}
+	}
+
+	resp, err := c.send(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if cached != nil && resp.StatusCode == http.StatusNotModified {
+		cached.refresh(resp.Header, time.Now())
+		c.cache.put(cached)
+		return cached.response(), nil
+	}
+	if c.cache != nil && req.Method == http.MethodGet && resp.StatusCode == http.StatusOK {
+		if entry := newCacheEntry(req.URL.String(), resp, time.Now()); entry != nil {
+			c.cache.put(entry)
+		}
+	}
+	return resp, nil
+}
+
+// send sends req, waiting for the host's rate limit before each attempt and retrying failures.
+func (c *Client) send(req *http.Request) (*Response, error) {
+	ctx := req.Context()
+	retries := c.opts.Retry.MaxAttempts - 1
+	if !idempotent(req.Method) {
+		retries = 0
+	}
+
+	for attempt := 0; ; attempt++ {
+		if err := c.wait(ctx, req.URL.Host); err != nil {
+			return nil, err
+		}
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, fmt.Errorf("failed to rewind request body: %w", err)
+			}
+			req.Body = body
+		}
+
+		resp, err := c.attempt(req)
+		if attempt >= retries || ctx.Err() != nil || (err == nil && !retryStatus[resp.StatusCode]) {
+			if err != nil {
+				return nil, err
+			}
+			return resp, nil
+		}
+
+		delay := c.opts.Retry.Delay(attempt + 1)
+		if err == nil {
+			if after, ok := retryAfter(resp.Header, time.Now()); ok {
+				if maxDelay := c.opts.Retry.MaxDelay; maxDelay > 0 && after > maxDelay {
+					return resp, nil // The server asked for a longer pause than is worth waiting for
+				}
+				delay = after
+			}
+		}
+		// Other requests to the host wait out the delay too
+		c.delayHost(req.URL.Host, delay)
+	}
+}
+
+// attempt sends req once and reads the response.
+func (c *Client) attempt(req *http.Request) (*Response, error) {
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request to %s failed: %w", req.URL.Host, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response from %s: %w", req.URL.Host, err)
+	}
+	return &Response{StatusCode: resp.StatusCode, Header: resp.Header, Body: body}, nil
+}
+
+// wait blocks until a request to host is allowed, and reserves the next slot.
+func (c *Client) wait(ctx context.Context, host string) error {
+	c.mu.Lock()
+	now := time.Now()
+	at := c.nextAt[host]
+	if at.Before(now) {
+		at = now
+	}
+	if c.opts.MinInterval > 0 {
+		c.nextAt[host] = at.Add(c.opts.MinInterval)
+	}
+	c.mu.Unlock()
+
+	return sleep(ctx, time.Until(at))
+}
+
+// delayHost makes the next request to host wait at least d.
+func (c *Client) delayHost(host string, d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if at := time.Now().Add(d); at.After(c.nextAt[host]) {
+		c.nextAt[host] = at
+	}
+}
+
+// retryAfter parses the Retry-After header, in seconds or as an HTTP date.
+func retryAfter(header http.Header, now time.Time) (time.Duration, bool) {
+	value := header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(at.Sub(now), 0), true
+	}
+	return 0, false
+}
+
+// idempotent reports whether requests with method can safely be sent again.
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// sleep waits for d, or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,217 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jongio/azd-app/cli/src/internal/retry"
+)
+
+// newTestClient returns a client with short delays and no rate limit.
+func newTestClient(cacheDir string) *Client {
+	return New(Options{CacheDir: cacheDir, Retry: retry.Policy{MaxAttempts: 4, BaseDelay: time.Millisecond, Backoff: 2, MaxDelay: 50 * time.Millisecond}, MinInterval: -1})
+}
+
+func TestRetry(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		failures   int    // Requests answered with failStatus before succeeding
+		retryAfter string // Retry-After header of the failures
+		wantCalls  int32
+		wantStatus int
+	}{
+		{name: "succeeds after retries", method: http.MethodGet, failures: 2, wantCalls: 3, wantStatus: http.StatusOK},
+		{name: "gives up after max retries", method: http.MethodGet, failures: 10, wantCalls: 4, wantStatus: http.StatusServiceUnavailable},
+		{name: "honors short Retry-After", method: http.MethodGet, failures: 1, retryAfter: "0", wantCalls: 2, wantStatus: http.StatusOK},
+		{name: "does not wait for long Retry-After", method: http.MethodGet, failures: 1, retryAfter: "3600", wantCalls: 1, wantStatus: http.StatusServiceUnavailable},
+		{name: "does not retry POST", method: http.MethodPost, failures: 1, wantCalls: 1, wantStatus: http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if int(calls.Add(1)) <= tt.failures {
+					if tt.retryAfter != "" {
+						w.Header().Set("Retry-After", tt.retryAfter)
+					}
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				_, _ = w.Write([]byte("ok"))
+			}))
+			defer server.Close()
+
+			req, err := http.NewRequestWithContext(context.Background(), tt.method, server.URL, strings.NewReader("body"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := newTestClient("").Do(req)
+			if err != nil {
+				t.Fatalf("Do failed: %v", err)
+			}
+			if resp.StatusCode != tt.wantStatus || calls.Load() != tt.wantCalls {
+				t.Errorf("status %d after %d calls, want %d after %d", resp.StatusCode, calls.Load(), tt.wantStatus, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestRetryNetworkError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	url := server.URL
+	server.Close()
+
+	if _, err := newTestClient("").Get(context.Background(), url); err == nil {
+		t.Error("Get of a closed server should fail")
+	}
+}
+
+func TestCache(t *testing.T) {
+	tests := []struct {
+		name         string
+		header       map[string]string
+		wantCalls    int32 // Server calls for two Gets
+		wantCached   bool  // Second Get is served from the cache
+		wantValidate bool  // Second Get is a conditional request
+	}{
+		{name: "fresh", header: map[string]string{"Cache-Control": "max-age=60", "ETag": `"v1"`}, wantCalls: 1, wantCached: true},
+		{name: "revalidated with ETag", header: map[string]string{"ETag": `"v1"`}, wantCalls: 2, wantCached: true, wantValidate: true},
+		{name: "revalidated with Last-Modified", header: map[string]string{"Last-Modified": "Mon, 02 Jan 2006 15:04:05 GMT"}, wantCalls: 2, wantCached: true, wantValidate: true},
+		{name: "no-store", header: map[string]string{"Cache-Control": "no-store", "ETag": `"v1"`}, wantCalls: 2},
+		{name: "no validator", header: map[string]string{}, wantCalls: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			var conditional atomic.Bool
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls.Add(1)
+				for name, value := range tt.header {
+					w.Header().Set(name, value)
+				}
+				if r.Header.Get("If-None-Match") != "" || r.Header.Get("If-Modified-Since") != "" {
+					conditional.Store(true)
+					w.WriteHeader(http.StatusNotModified)
+					return
+				}
+				_, _ = w.Write([]byte(`{"version":"1.0.0"}`))
+			}))
+			defer server.Close()
+
+			client := newTestClient(t.TempDir())
+			first, err := client.Get(context.Background(), server.URL+"/pkg")
+			if err != nil {
+				t.Fatalf("first Get failed: %v", err)
+			}
+			if first.FromCache {
+				t.Error("first response should not come from the cache")
+			}
+			second, err := client.Get(context.Background(), server.URL+"/pkg")
+			if err != nil {
+				t.Fatalf("second Get failed: %v", err)
+			}
+
+			if calls.Load() != tt.wantCalls || second.FromCache != tt.wantCached || conditional.Load() != tt.wantValidate {
+				t.Errorf("calls = %d, from cache = %v, conditional = %v; want %d, %v, %v",
+					calls.Load(), second.FromCache, conditional.Load(), tt.wantCalls, tt.wantCached, tt.wantValidate)
+			}
+			if second.StatusCode != http.StatusOK || string(second.Body) != `{"version":"1.0.0"}` {
+				t.Errorf("second response = %d %q", second.StatusCode, second.Body)
+			}
+		})
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	client := New(Options{MinInterval: 30 * time.Millisecond})
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if _, err := client.Get(context.Background(), server.URL); err != nil {
+			t.Fatalf("Get failed: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 60*time.Millisecond {
+		t.Errorf("3 requests took %v, want at least 60ms with a 30ms interval", elapsed)
+	}
+}
+
+func TestRateLimitCanceled(t *testing.T) {
+	client := New(Options{MinInterval: time.Hour})
+	client.nextAt["example.com"] = time.Now().Add(time.Hour)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := client.Get(ctx, "https://example.com/"); err == nil {
+		t.Error("Get should fail when the context is canceled while waiting")
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 2, 15, 4, 0, 0, time.UTC)
+	tests := []struct {
+		value  string
+		want   time.Duration
+		wantOK bool
+	}{
+		{value: "", wantOK: false},
+		{value: "120", want: 2 * time.Minute, wantOK: true},
+		{value: "Tue, 02 Jan 2024 15:04:30 GMT", want: 30 * time.Second, wantOK: true},
+		{value: "Tue, 02 Jan 2024 15:00:00 GMT", want: 0, wantOK: true},
+		{value: "soon", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		header := http.Header{}
+		if tt.value != "" {
+			header.Set("Retry-After", tt.value)
+		}
+		got, ok := retryAfter(header, now)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("retryAfter(%q) = %v, %v; want %v, %v", tt.value, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestNewLocal(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if r.URL.Path == "/" {
+			http.Redirect(w, r, "/login", http.StatusFound)
+			return
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := NewLocal(time.Second)
+	// Redirects are returned as they are
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusFound {
+		t.Errorf("status = %d, want the redirect", resp.StatusCode)
+	}
+	// Failures are not retried
+	resp, err = client.Get(server.URL + "/login")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	_ = resp.Body.Close()
+	if got := calls.Load(); got != 2 {
+		t.Errorf("server got %d requests, want 2", got)
+	}
+}
@@ -0,0 +1,164 @@
+package imagebuild
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/jongio/azd-app/cli/src/internal/container"
+)
+
+// Builders of Cloud Native Buildpacks that build the images of services without a Dockerfile,
+// as azd does: Oryx, the build system of Azure App Service, for the languages it supports, and
+// Paketo for the others.
+const (
+	OryxBuilder   = "mcr.microsoft.com/oryx/builder:debian-bullseye-20240424.1"
+	PaketoBuilder = "paketobuildpacks/builder-jammy-base"
+)
+
+// PackInstallURL is where to install the pack CLI, which builds images with buildpacks.
+const PackInstallURL = "https://buildpacks.io/docs/for-platform-operators/how-to/integrate-ci/pack/"
+
+// Languages of services that buildpacks can build.
+const (
+	LanguageNode   = "node"
+	LanguagePython = "python"
+	LanguagePHP    = "php"
+	LanguageDotnet = "dotnet"
+	LanguageJava   = "java"
+	LanguageGo     = "go"
+)
+
+// languageBuilders maps each language to the builder of its images.
+var languageBuilders = map[string]string{
+	LanguageNode:   OryxBuilder,
+	LanguagePython: OryxBuilder,
+	LanguagePHP:    OryxBuilder,
+	LanguageDotnet: PaketoBuilder,
+	LanguageJava:   PaketoBuilder,
+	LanguageGo:     PaketoBuilder,
+}
+
+// digestPattern matches the digest pack prints once the image is built, e.g.
+// "*** Images (sha256:4f2b...):".
+var digestPattern = regexp.MustCompile(`Images \((sha256:[0-9a-f]{64})\)`)
+
+// BuilderFor returns the buildpacks builder of a service in dir without a Dockerfile, and its
+// language: the language declared in azure.yaml, else the one of the files in dir. It returns
+// "" for a service whose language no builder supports.
+func BuilderFor(dir, declared string) (builder, language string) {
+	language = normalizeLanguage(declared)
+	if declared == "" {
+		language = detectLanguage(dir)
+	}
+	return languageBuilders[language], language
+}
+
+// normalizeLanguage returns the language of a language name of azure.yaml, or "".
+func normalizeLanguage(name string) string {
+	switch strings.ToLower(name) {
+	case "js", "ts", "javascript", "typescript", "node", "nodejs":
+		return LanguageNode
+	case "py", "python":
+		return LanguagePython
+	case "php":
+		return LanguagePHP
+	case "dotnet", "csharp", "fsharp":
+		return LanguageDotnet
+	case "java":
+		return LanguageJava
+	case "go", "golang":
+		return LanguageGo
+	default:
+		return ""
+	}
+}
+
+// detectLanguage returns the language of the project files in dir, or "".
+func detectLanguage(dir string) string {
+	has := func(patterns ...string) bool {
+		for _, pattern := range patterns {
+			if matches, _ := filepath.Glob(filepath.Join(dir, pattern)); len(matches) > 0 {
+				return true
+			}
+		}
+		return false
+	}
+	switch {
+	case has("package.json"):
+		return LanguageNode
+	case has("requirements.txt", "pyproject.toml", "Pipfile", "setup.py"):
+		return LanguagePython
+	case has("*.csproj", "*.fsproj", "*.vbproj"):
+		return LanguageDotnet
+	case has("pom.xml", "build.gradle", "build.gradle.kts"):
+		return LanguageJava
+	case has("go.mod"):
+		return LanguageGo
+	case has("composer.json"):
+		return LanguagePHP
+	default:
+		return ""
+	}
+}
+
+// PackArgs returns the arguments of the pack build of target. Build arguments become build-time
+// variables of the buildpacks, e.g. BP_JVM_VERSION=21.
+func PackArgs(target Target) []string {
+	args := []string{"build", target.Image, "--builder", target.Builder, "--path", target.Context, "--trust-builder"}
+	if len(target.Platforms) == 1 {
+		args = append(args, "--platform", target.Platforms[0])
+	}
+	for _, arg := range target.BuildArgs {
+		args = append(args, "--env", arg)
+	}
+	if target.Push {
+		args = append(args, "--publish")
+	}
+	return args
+}
+
+// buildWithPack builds target with the pack CLI, on the engine, into result. Buildpacks build
+// for one platform, and keep their layer cache in volumes of the engine rather than in the
+// cache of azure.yaml.
+func buildWithPack(ctx context.Context, engine container.Engine, target Target, out io.Writer, logPath string, result *Result) {
+	if target.MultiArch() {
+		result.Error = fmt.Sprintf("buildpacks build for one platform, not %s; add a Dockerfile to build a multi-arch image", strings.Join(target.Platforms, ", "))
+		return
+	}
+	pack, err := exec.LookPath("pack")
+	if err != nil {
+		result.Error = fmt.Sprintf("%s has no Dockerfile and is built with buildpacks, which need the pack CLI; install it from %s", target.Service, PackInstallURL)
+		return
+	}
+
+	var digest string
+	lines := &lineWriter{emit: func(line string) {
+		if m := digestPattern.FindStringSubmatch(line); m != nil {
+			digest = m[1]
+		}
+	}}
+	// #nosec G204 -- pack with a fixed subcommand; values come from azure.yaml and are single arguments
+	cmd := exec.CommandContext(ctx, pack, PackArgs(target)...)
+	cmd.Env = os.Environ()
+	if engine.Host != "" {
+		cmd.Env = append(cmd.Env, "DOCKER_HOST="+engine.Host)
+	}
+	w := io.MultiWriter(out, lines)
+	cmd.Stdout = w
+	cmd.Stderr = w
+	err = cmd.Run()
+	lines.Flush()
+	if err != nil {
+		result.Error = fmt.Sprintf("build failed: %v (see %s)", err, logPath)
+		return
+	}
+	result.Pushed = target.Push
+	result.Loaded = !target.Push
+	result.Digest = digest
+}
@@ -0,0 +1,99 @@
+package imagebuild
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jongio/azd-app/cli/src/internal/statedir"
+)
+
+func TestBuilderFor(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "node", "package.json"), "{}")
+	writeFile(t, filepath.Join(dir, "dotnet", "Api.csproj"), "<Project />")
+	writeFile(t, filepath.Join(dir, "go", "go.mod"), "module api\n")
+	writeFile(t, filepath.Join(dir, "rust", "Cargo.toml"), "[package]\n")
+
+	tests := []struct {
+		dir, declared     string
+		builder, language string
+	}{
+		{dir: "node", builder: OryxBuilder, language: LanguageNode},
+		{dir: "dotnet", builder: PaketoBuilder, language: LanguageDotnet},
+		{dir: "go", builder: PaketoBuilder, language: LanguageGo},
+		{dir: "rust"},
+		{dir: "rust", declared: "py", builder: OryxBuilder, language: LanguagePython},
+		{dir: "node", declared: "docker"},
+	}
+	for _, tt := range tests {
+		builder, language := BuilderFor(filepath.Join(dir, tt.dir), tt.declared)
+		if builder != tt.builder || language != tt.language {
+			t.Errorf("BuilderFor(%s, %q) = %q, %q, want %q, %q", tt.dir, tt.declared, builder, language, tt.builder, tt.language)
+		}
+	}
+}
+
+func TestLoadTargetsBuildpacks(t *testing.T) {
+	t.Setenv(statedir.RootEnvVar, t.TempDir())
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "azure.yaml"), `name: shop
+services:
+  web:
+    host: containerapp
+    project: ./web
+  jobs:
+    host: containerapp
+    project: ./jobs
+    docker:
+      builder: paketobuildpacks/builder-jammy-full
+  site:
+    host: staticwebapp
+    project: ./site
+`)
+	writeFile(t, filepath.Join(dir, "web", "package.json"), "{}")
+	writeFile(t, filepath.Join(dir, "jobs", "requirements.txt"), "")
+	writeFile(t, filepath.Join(dir, "site", "package.json"), "{}")
+
+	targets, _, err := LoadTargets(dir, nil)
+	if err != nil {
+		t.Fatalf("LoadTargets failed: %v", err)
+	}
+	if len(targets) != 2 {
+		t.Fatalf("targets = %+v, want jobs and web, not the static web app", targets)
+	}
+	jobs, web := targets[0], targets[1]
+	if jobs.Builder != "paketobuildpacks/builder-jammy-full" || jobs.Language != LanguagePython {
+		t.Errorf("jobs target = %+v, want the builder of azure.yaml", jobs)
+	}
+	if web.Builder != OryxBuilder || web.Dockerfile != "" || web.Context != filepath.Join(dir, "web") {
+		t.Errorf("web target = %+v, want the Oryx builder", web)
+	}
+}
+
+func TestPackArgs(t *testing.T) {
+	target := Target{Service: "api", Image: "shop-api:1", Builder: PaketoBuilder, Context: "/w/api", Platforms: []string{"linux/arm64"}, BuildArgs: []string{"BP_GO_VERSION=1.22"}}
+	args := strings.Join(PackArgs(target), " ")
+	if args != "build shop-api:1 --builder paketobuildpacks/builder-jammy-base --path /w/api --trust-builder --platform linux/arm64 --env BP_GO_VERSION=1.22" {
+		t.Errorf("args = %s", args)
+	}
+
+	target.Push = true
+	if args := PackArgs(target); args[len(args)-1] != "--publish" {
+		t.Errorf("push args = %v, want --publish", args)
+	}
+}
+
+func TestWorkflowBuildpacks(t *testing.T) {
+	targets := []Target{{Service: "web", Image: "shop-web", Builder: OryxBuilder, Context: filepath.Join("/w", "web")}}
+	workflow := Workflow("/w", targets, Cache{Type: CacheNone})
+	for _, want := range []string{
+		"uses: buildpacks/github-actions/setup-pack@",
+		"pack 'build' 'shop-web' '--builder' '" + OryxBuilder + "' '--path' 'web'",
+		"${{ steps.build-web.outputs.digest }}",
+	} {
+		if !strings.Contains(workflow, want) {
+			t.Errorf("workflow is missing %q:\n%s", want, workflow)
+		}
+	}
+}
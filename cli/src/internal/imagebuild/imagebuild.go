@@ -0,0 +1,351 @@
+// Package imagebuild builds the container images of services with docker buildx, for one
+// platform or several, with a build cache shared by builds of the same service.
+package imagebuild
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jongio/azd-app/cli/src/internal/azureyaml"
+	"github.com/jongio/azd-app/cli/src/internal/container"
+	"github.com/jongio/azd-app/cli/src/internal/detector"
+	"github.com/jongio/azd-app/cli/src/internal/security"
+	"github.com/jongio/azd-app/cli/src/internal/statedir"
+)
+
+// DefaultConcurrency is how many images are built at once unless build.concurrency or
+// --concurrency say otherwise. Builds share the builder, which runs the steps of each build in
+// parallel too.
+const DefaultConcurrency = 4
+
+// BuilderName is the buildx builder azd app creates for its builds. Unlike the default builder
+// of Docker, it can build for several platforms at once and export build caches.
+const BuilderName = "azd-app"
+
+// Cache types of the build settings in azure.yaml.
+const (
+	CacheLocal    = "local"    // A directory of the workspace state, for builds on this machine
+	CacheGHA      = "gha"      // The GitHub Actions cache, for builds in workflows
+	CacheRegistry = "registry" // A repository of a container registry, written as registry:<repository>
+	CacheNone     = "none"
+)
+
+// platformPattern matches a platform such as linux/amd64 or linux/arm64/v8.
+var platformPattern = regexp.MustCompile(`^[a-z0-9]+/[a-z0-9_]+(/[a-z0-9]+)?$`)
+
+// Target is the image of a service to build.
+type Target struct {
+	Service    string   `json:"service"`
+	Image      string   `json:"image"`                // Image name, e.g. shop-api, or the reference of a registry to push to
+	Dockerfile string   `json:"dockerfile,omitempty"` // Absolute path; empty for a target built with buildpacks
+	Builder    string   `json:"builder,omitempty"`    // Buildpacks builder of a service without a Dockerfile
+	Language   string   `json:"language,omitempty"`   // Language the builder was chosen for
+	Context    string   `json:"context"`              // Absolute path of the build context
+	Platforms  []string `json:"platforms"`            // Empty for the platform of the engine
+	BuildArgs  []string `json:"buildArgs,omitempty"`
+	Push       bool     `json:"push,omitempty"` // Push Image, a reference of a registry, instead of keeping it here
+}
+
+// MultiArch reports whether the target builds for more than one platform.
+func (t Target) MultiArch() bool {
+	return len(t.Platforms) > 1
+}
+
+// Cache is where builds store and find their layer cache.
+type Cache struct {
+	Type       string // One of the Cache constants
+	Dir        string // CacheLocal: directory holding a cache per service
+	Repository string // CacheRegistry: repository holding a cache tag per service
+}
+
+// Args returns the --cache-from and --cache-to flags of a build of service.
+func (c Cache) Args(service string) []string {
+	var from, to string
+	switch c.Type {
+	case CacheLocal:
+		dir := filepath.Join(c.Dir, service)
+		from, to = "type=local,src="+dir, "type=local,dest="+dir+",mode=max"
+	case CacheGHA:
+		from, to = "type=gha,scope="+service, "type=gha,scope="+service+",mode=max"
+	case CacheRegistry:
+		ref := c.Repository + ":" + service + "-cache"
+		from, to = "type=registry,ref="+ref, "type=registry,ref="+ref+",mode=max"
+	default:
+		return nil
+	}
+	return []string{"--cache-from", from, "--cache-to", to}
+}
+
+// Result is the outcome of the build of a target.
+type Result struct {
+	Service   string        `json:"service"`
+	Image     string        `json:"image"`
+	Platforms []string      `json:"platforms"`
+	Digest    string        `json:"digest,omitempty"` // Digest of the image, or of the index of a multi-arch image
+	Loaded    bool          `json:"loaded"`           // The image is in the image store of the engine
+	Pushed    bool          `json:"pushed,omitempty"` // The image is in its registry
+	Duration  time.Duration `json:"duration"`
+	Error     string        `json:"error,omitempty"`
+}
+
+// LoadTargets returns the images to build for the services of the azure.yaml in workspace,
+// sorted by service, and the build cache of azure.yaml. Services with a Dockerfile are built
+// from it; container services without one are built with buildpacks when their language has a
+// builder. platforms, when set, replace the platforms of azure.yaml.
+func LoadTargets(workspace string, platforms []string) ([]Target, Cache, error) {
+	doc, err := azureyaml.Load(filepath.Join(workspace, azureyaml.FileName))
+	if err != nil {
+		return nil, Cache{}, err
+	}
+
+	var settings azureyaml.BuildSettings
+	if doc.Project.Build != nil {
+		settings = *doc.Project.Build
+	}
+	cache, err := ParseCache(settings.Cache, workspace)
+	if err != nil {
+		return nil, Cache{}, err
+	}
+	if err := validatePlatforms(platforms); err != nil {
+		return nil, Cache{}, err
+	}
+
+	project := imageName(doc.Project.Name)
+	var targets []Target
+	for name, svc := range doc.Project.Services {
+		target := Target{Service: name, Image: project + "-" + imageName(name), Platforms: platforms}
+		var docker azureyaml.DockerConfig
+		if svc.Docker != nil {
+			docker = *svc.Docker
+		}
+		projectDir := filepath.Join(workspace, svc.Project)
+		target.Context = projectDir
+		if docker.Context != "" {
+			target.Context = filepath.Join(projectDir, docker.Context)
+		}
+		if docker.Path != "" {
+			target.Dockerfile = filepath.Join(projectDir, docker.Path)
+		} else if dockerfile := detector.DetectDockerfile(projectDir); dockerfile != nil {
+			target.Dockerfile = dockerfile.Path
+		} else if svc.Host == "containerapp" || svc.Host == "aks" {
+			target.Builder, target.Language = BuilderFor(projectDir, svc.Language)
+			if docker.Builder != "" {
+				target.Builder = docker.Builder
+			}
+			if target.Builder == "" {
+				continue
+			}
+		} else {
+			continue
+		}
+		if len(target.Platforms) == 0 {
+			switch {
+			case len(docker.Platforms) > 0:
+				target.Platforms = docker.Platforms
+			case len(settings.Platforms) > 0:
+				target.Platforms = settings.Platforms
+			case docker.Platform != "":
+				target.Platforms = []string{docker.Platform}
+			}
+		}
+		if err := validatePlatforms(target.Platforms); err != nil {
+			return nil, Cache{}, fmt.Errorf("service %s: %w", name, err)
+		}
+		target.BuildArgs = docker.BuildArgs
+		targets = append(targets, target)
+	}
+	sort.Slice(targets, func(i, j int) bool { return targets[i].Service < targets[j].Service })
+	return targets, cache, nil
+}
+
+// ParseCache parses the cache setting of azure.yaml. The local cache is kept in the state
+// directory of workspace, so the cache management of 'azd app cache' covers it.
+func ParseCache(value, workspace string) (Cache, error) {
+	switch {
+	case value == "" || value == CacheLocal:
+		dir, err := statedir.CacheDir(workspace)
+		if err != nil {
+			return Cache{}, fmt.Errorf("failed to create build cache directory: %w", err)
+		}
+		return Cache{Type: CacheLocal, Dir: filepath.Join(dir, "buildx")}, nil
+	case value == CacheGHA || value == CacheNone:
+		return Cache{Type: value}, nil
+	case strings.HasPrefix(value, CacheRegistry+":"):
+		repository := strings.TrimPrefix(value, CacheRegistry+":")
+		if repository == "" || strings.ContainsAny(repository, " ,=@") {
+			return Cache{}, fmt.Errorf("invalid build cache %q: want registry:<repository>, e.g. registry:myregistry.azurecr.io/cache", value)
+		}
+		return Cache{Type: CacheRegistry, Repository: repository}, nil
+	default:
+		return Cache{}, fmt.Errorf("invalid build cache %q: must be local, gha, registry:<repository>, or none", value)
+	}
+}
+
+// EnsureBuilder creates the buildx builder of azd app unless it exists. It runs in a container,
+// so that it can build for several platforms and export caches.
+func EnsureBuilder(ctx context.Context, engine container.Engine) error {
+	if engine.Name != container.EngineDocker {
+		return fmt.Errorf("container image builds use docker buildx, which %s doesn't have; set %s=docker", engine.Name, container.EngineEnv)
+	}
+	if err := engine.Command(ctx, "buildx", "inspect", BuilderName).Run(); err == nil {
+		return nil
+	}
+	out, err := engine.Command(ctx, "buildx", "create", "--name", BuilderName, "--driver", "docker-container", "--bootstrap").CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to create buildx builder %s: %w: %s", BuilderName, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// Build builds target, writing the output of buildx, or of pack for a target built with
+// buildpacks, to logPath, and each of its lines to progress when set. An image for one platform
+// is loaded into the image store of the engine; a multi-arch image stays in the builder, since
+// the image store can't hold it. An image to push is pushed to its registry instead.
+func Build(ctx context.Context, engine container.Engine, target Target, cache Cache, logPath string, progress func(line string)) Result {
+	result := Result{Service: target.Service, Image: target.Image, Platforms: target.Platforms}
+	start := time.Now()
+	defer func() { result.Duration = time.Since(start) }()
+
+	// #nosec G304 -- Log path is inside the workspace state directory
+	logFile, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		result.Error = fmt.Sprintf("failed to open log file: %v", err)
+		return result
+	}
+	defer logFile.Close()
+
+	var out io.Writer = logFile
+	if progress != nil {
+		lines := &lineWriter{emit: progress}
+		defer lines.Flush()
+		out = io.MultiWriter(logFile, lines)
+	}
+	if target.Builder != "" {
+		_, _ = fmt.Fprintf(logFile, "$ pack %s\n", strings.Join(PackArgs(target), " "))
+		buildWithPack(ctx, engine, target, out, logPath, &result)
+		return result
+	}
+
+	metadata, err := os.CreateTemp("", "azd-app-build-*.json")
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	_ = metadata.Close()
+	defer func() { _ = os.Remove(metadata.Name()) }()
+
+	args := BuildArgs(target, cache, metadata.Name())
+	_, _ = fmt.Fprintf(logFile, "$ %s %s\n", engine.Name, strings.Join(args, " "))
+	cmd := engine.Command(ctx, args...)
+	cmd.Stdout = out
+	cmd.Stderr = out
+	if err := cmd.Run(); err != nil {
+		result.Error = fmt.Sprintf("build failed: %v (see %s)", err, logPath)
+		return result
+	}
+
+	result.Pushed = target.Push
+	result.Loaded = !target.Push && !target.MultiArch()
+	result.Digest = readDigest(metadata.Name())
+	return result
+}
+
+// BuildArgs returns the arguments of the buildx build of target, which writes its metadata,
+// including the digest of the image, to metadataPath.
+func BuildArgs(target Target, cache Cache, metadataPath string) []string {
+	args := []string{"buildx", "build", "--builder", BuilderName, "--progress", "plain", "--file", target.Dockerfile, "--tag", target.Image}
+	if len(target.Platforms) > 0 {
+		args = append(args, "--platform", strings.Join(target.Platforms, ","))
+	}
+	for _, arg := range target.BuildArgs {
+		args = append(args, "--build-arg", arg)
+	}
+	args = append(args, cache.Args(target.Service)...)
+	if target.Push {
+		args = append(args, "--output", "type=image,push=true")
+	} else if target.MultiArch() {
+		args = append(args, "--output", "type=image,push=false")
+	} else {
+		args = append(args, "--load")
+	}
+	return append(args, "--metadata-file", metadataPath, target.Context)
+}
+
+// lineWriter calls emit with each complete line written to it, without its line ending.
+type lineWriter struct {
+	emit func(line string)
+	buf  []byte
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			return len(p), nil
+		}
+		w.emit(strings.TrimRight(string(w.buf[:i]), "\r"))
+		w.buf = w.buf[i+1:]
+	}
+}
+
+// Flush emits the last line when it has no line ending.
+func (w *lineWriter) Flush() {
+	if len(w.buf) > 0 {
+		w.emit(string(w.buf))
+		w.buf = nil
+	}
+}
+
+// readDigest returns the image digest from the metadata file of a build, or "" if it has none.
+func readDigest(path string) string {
+	if err := security.ValidatePath(path); err != nil {
+		return ""
+	}
+	// #nosec G304 -- Path validated by security.ValidatePath; written by buildx
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	var metadata struct {
+		Digest string `json:"containerimage.digest"`
+	}
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		return ""
+	}
+	return metadata.Digest
+}
+
+// validatePlatforms returns an error for a platform that isn't of the form os/arch[/variant].
+func validatePlatforms(platforms []string) error {
+	for _, platform := range platforms {
+		if !platformPattern.MatchString(platform) {
+			return fmt.Errorf("invalid platform %q: want os/arch, e.g. linux/arm64", platform)
+		}
+	}
+	return nil
+}
+
+// imageName returns name as a valid image name component: lowercase, with characters other
+// than letters, digits, '.', '_' and '-' replaced by '-'.
+func imageName(name string) string {
+	name = strings.ToLower(name)
+	var b strings.Builder
+	for _, r := range name {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '.' || r == '_' || r == '-' {
+			b.WriteRune(r)
+		} else {
+			b.WriteRune('-')
+		}
+	}
+	return strings.Trim(b.String(), "-._")
+}
@@ -0,0 +1,155 @@
+package imagebuild
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/jongio/azd-app/cli/src/internal/statedir"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("failed to write %s: %v", path, err)
+	}
+}
+
+func TestLoadTargets(t *testing.T) {
+	t.Setenv(statedir.RootEnvVar, t.TempDir())
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "azure.yaml"), `name: Shop
+build:
+  platforms: [linux/amd64]
+services:
+  api:
+    host: containerapp
+    project: ./api
+    docker:
+      platforms: [linux/amd64, linux/arm64]
+      buildArgs: [VERSION=1]
+  web:
+    host: containerapp
+    project: ./web
+    docker:
+      path: ../docker/web.Dockerfile
+      context: ..
+  worker:
+    host: containerapp
+    project: ./worker
+`)
+	writeFile(t, filepath.Join(dir, "api", "Dockerfile"), "FROM python:3.12\n")
+
+	targets, cache, err := LoadTargets(dir, nil)
+	if err != nil {
+		t.Fatalf("LoadTargets failed: %v", err)
+	}
+	if cache.Type != CacheLocal || cache.Dir == "" {
+		t.Errorf("cache = %+v, want a local cache", cache)
+	}
+	if len(targets) != 2 {
+		t.Fatalf("targets = %+v, want api and web", targets)
+	}
+
+	api, web := targets[0], targets[1]
+	if api.Image != "shop-api" || !api.MultiArch() || !slices.Equal(api.BuildArgs, []string{"VERSION=1"}) {
+		t.Errorf("api target = %+v", api)
+	}
+	if web.Dockerfile != filepath.Join(dir, "docker", "web.Dockerfile") || web.Context != dir {
+		t.Errorf("web Dockerfile = %s, context = %s", web.Dockerfile, web.Context)
+	}
+	if !slices.Equal(web.Platforms, []string{"linux/amd64"}) {
+		t.Errorf("web platforms = %v, want those of build.platforms", web.Platforms)
+	}
+
+	// --platform replaces the platforms of azure.yaml
+	targets, _, err = LoadTargets(dir, []string{"linux/arm64"})
+	if err != nil {
+		t.Fatalf("LoadTargets failed: %v", err)
+	}
+	for _, target := range targets {
+		if !slices.Equal(target.Platforms, []string{"linux/arm64"}) {
+			t.Errorf("%s platforms = %v, want linux/arm64", target.Service, target.Platforms)
+		}
+	}
+
+	if _, _, err := LoadTargets(dir, []string{"arm64"}); err == nil {
+		t.Error("expected error for a platform without an OS")
+	}
+}
+
+func TestParseCache(t *testing.T) {
+	t.Setenv(statedir.RootEnvVar, t.TempDir())
+	tests := []struct {
+		value   string
+		want    []string
+		wantErr bool
+	}{
+		{value: "gha", want: []string{"--cache-from", "type=gha,scope=api", "--cache-to", "type=gha,scope=api,mode=max"}},
+		{value: "registry:contoso.azurecr.io/cache", want: []string{
+			"--cache-from", "type=registry,ref=contoso.azurecr.io/cache:api-cache",
+			"--cache-to", "type=registry,ref=contoso.azurecr.io/cache:api-cache,mode=max",
+		}},
+		{value: "none"},
+		{value: "registry:", wantErr: true},
+		{value: "s3", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			cache, err := ParseCache(tt.value, t.TempDir())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseCache(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if got := cache.Args("api"); !tt.wantErr && !slices.Equal(got, tt.want) {
+				t.Errorf("Args() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildArgs(t *testing.T) {
+	target := Target{Service: "api", Image: "shop-api", Dockerfile: "/w/api/Dockerfile", Context: "/w/api"}
+	args := strings.Join(BuildArgs(target, Cache{Type: CacheNone}, "/tmp/meta.json"), " ")
+	if args != "buildx build --builder azd-app --progress plain --file /w/api/Dockerfile --tag shop-api --load --metadata-file /tmp/meta.json /w/api" {
+		t.Errorf("single-platform args = %s", args)
+	}
+
+	target.Platforms = []string{"linux/amd64", "linux/arm64"}
+	args = strings.Join(BuildArgs(target, Cache{Type: CacheGHA}, "/tmp/meta.json"), " ")
+	for _, want := range []string{"--platform linux/amd64,linux/arm64", "--cache-from type=gha,scope=api", "--output type=image,push=false"} {
+		if !strings.Contains(args, want) {
+			t.Errorf("multi-arch args = %s, want %s", args, want)
+		}
+	}
+	if strings.Contains(args, "--load") {
+		t.Errorf("multi-arch args = %s, want no --load", args)
+	}
+}
+
+func TestWorkflow(t *testing.T) {
+	targets := []Target{{
+		Service:    "api",
+		Image:      "shop-api",
+		Dockerfile: filepath.Join("/w", "src", "api", "Dockerfile"),
+		Context:    filepath.Join("/w", "src", "api"),
+		Platforms:  []string{"linux/amd64", "linux/arm64"},
+	}}
+	workflow := Workflow("/w", targets, Cache{Type: CacheLocal, Dir: "/cache"})
+	for _, want := range []string{
+		"uses: docker/setup-qemu-action@v3",
+		`context: "src/api"`,
+		`file: "src/api/Dockerfile"`,
+		`platforms: "linux/amd64,linux/arm64"`,
+		`cache-from: "type=gha,scope=api"`,
+		"${{ steps.build-api.outputs.digest }}",
+	} {
+		if !strings.Contains(workflow, want) {
+			t.Errorf("workflow is missing %q:\n%s", want, workflow)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package imagebuild
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/jongio/azd-app/cli/src/internal/statedir"
+)
+
+// ManifestVersion is the version of the manifest format, raised when fields change meaning.
+const ManifestVersion = 1
+
+// ManifestFile is the name of the manifest in the build directory of the workspace state.
+const ManifestFile = "manifest.json"
+
+// Manifest lists the images of a build, for tools that deploy or scan them.
+type Manifest struct {
+	Version int             `json:"version"`
+	Tag     string          `json:"tag"` // Tag of every image, e.g. the short SHA of the git commit
+	Built   time.Time       `json:"built"`
+	Images  []ManifestImage `json:"images"`
+}
+
+// ManifestImage is an image that was built.
+type ManifestImage struct {
+	Service   string   `json:"service"`
+	Image     string   `json:"image"` // Reference, including the tag
+	Digest    string   `json:"digest,omitempty"`
+	Platforms []string `json:"platforms"`
+	Loaded    bool     `json:"loaded"`
+	Pushed    bool     `json:"pushed,omitempty"`
+	Reference string   `json:"reference,omitempty"` // Of a pushed image, pinned to its digest
+}
+
+// NewManifest returns the manifest of the images of results that were built, tagged with tag.
+func NewManifest(tag string, results []Result) Manifest {
+	manifest := Manifest{Version: ManifestVersion, Tag: tag, Built: time.Now().UTC(), Images: []ManifestImage{}}
+	for _, result := range results {
+		if result.Service == "" || result.Error != "" {
+			continue
+		}
+		image := ManifestImage{
+			Service:   result.Service,
+			Image:     result.Image,
+			Digest:    result.Digest,
+			Platforms: result.Platforms,
+			Loaded:    result.Loaded,
+			Pushed:    result.Pushed,
+		}
+		if result.Pushed {
+			image.Reference = PinnedReference(result.Image, result.Digest)
+		}
+		manifest.Images = append(manifest.Images, image)
+	}
+	return manifest
+}
+
+// WriteManifest writes manifest to the build directory of the state of workspace, and to the
+// extra paths given, and returns the path in the state directory.
+func WriteManifest(workspace string, manifest Manifest, paths ...string) (string, error) {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	data = append(data, '\n')
+
+	dir, err := statedir.DataDir(workspace, "build")
+	if err != nil {
+		return "", err
+	}
+	statePath := filepath.Join(dir, ManifestFile)
+	for _, path := range append([]string{statePath}, paths...) {
+		if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+			return "", fmt.Errorf("failed to write build manifest: %w", err)
+		}
+		if err := statedir.WriteFile(path, data); err != nil {
+			return "", fmt.Errorf("failed to write build manifest %s: %w", path, err)
+		}
+	}
+	return statePath, nil
+}
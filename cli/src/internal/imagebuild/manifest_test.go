@@ -0,0 +1,65 @@
+package imagebuild
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/jongio/azd-app/cli/src/internal/statedir"
+)
+
+func TestWriteManifest(t *testing.T) {
+	t.Setenv(statedir.RootEnvVar, t.TempDir())
+	workspace := t.TempDir()
+	extra := filepath.Join(t.TempDir(), "out", "images.json")
+
+	results := []Result{
+		{Service: "api", Image: "shop-api:3f2a9c1d4b5e", Platforms: []string{"linux/amd64"}, Digest: "sha256:abc", Loaded: true},
+		{Service: "web", Image: "shop-web:3f2a9c1d4b5e", Error: "build failed"},
+		{}, // Not built, because the build was stopped
+	}
+	path, err := WriteManifest(workspace, NewManifest("3f2a9c1d4b5e", results), extra)
+	if err != nil {
+		t.Fatalf("WriteManifest() error = %v", err)
+	}
+
+	for _, p := range []string{path, extra} {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			t.Fatalf("manifest %s: %v", p, err)
+		}
+		var manifest Manifest
+		if err := json.Unmarshal(data, &manifest); err != nil {
+			t.Fatalf("manifest %s: %v", p, err)
+		}
+		if manifest.Version != ManifestVersion || manifest.Tag != "3f2a9c1d4b5e" || len(manifest.Images) != 1 {
+			t.Fatalf("manifest %s = %+v", p, manifest)
+		}
+		if image := manifest.Images[0]; image.Service != "api" || image.Digest != "sha256:abc" || !image.Loaded || image.Reference != "" {
+			t.Errorf("manifest image = %+v", image)
+		}
+	}
+}
+
+func TestNewManifestPushed(t *testing.T) {
+	results := []Result{{Service: "api", Image: "contoso.azurecr.io/shop/api-dev:abc", Digest: "sha256:123", Pushed: true}}
+	manifest := NewManifest("abc", results)
+	if got := manifest.Images[0].Reference; got != "contoso.azurecr.io/shop/api-dev:abc@sha256:123" {
+		t.Errorf("Reference = %q, want the image pinned to its digest", got)
+	}
+}
+
+func TestLineWriter(t *testing.T) {
+	var lines []string
+	w := &lineWriter{emit: func(line string) { lines = append(lines, line) }}
+	_, _ = w.Write([]byte("#1 [internal] load build definition\r\n#2 [1/3] FROM"))
+	_, _ = w.Write([]byte(" node:20\n#3 DONE"))
+	w.Flush()
+
+	want := []string{"#1 [internal] load build definition", "#2 [1/3] FROM node:20", "#3 DONE"}
+	if !slices.Equal(lines, want) {
+		t.Errorf("lines = %q, want %q", lines, want)
+	}
+}
@@ -0,0 +1,113 @@
+package imagebuild
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// RegistryEnvVar is the output of azd provisioning that holds the endpoint of the container
+// registry of the environment, e.g. contoso.azurecr.io.
+const RegistryEnvVar = "AZURE_CONTAINER_REGISTRY_ENDPOINT"
+
+// RegistryEndpoint returns the container registry of the values of an azd environment.
+func RegistryEndpoint(values map[string]string) (string, error) {
+	if strings.TrimSpace(values[RegistryEnvVar]) == "" {
+		return "", fmt.Errorf("the azd environment has no %s; provision a container registry with 'azd provision'", RegistryEnvVar)
+	}
+	endpoint, err := NormalizeRegistry(values[RegistryEnvVar])
+	if err != nil {
+		return "", fmt.Errorf("invalid %s: %w", RegistryEnvVar, err)
+	}
+	return endpoint, nil
+}
+
+// NormalizeRegistry returns the host of a container registry endpoint, without a scheme or a
+// trailing slash, e.g. contoso.azurecr.io for https://contoso.azurecr.io/.
+func NormalizeRegistry(endpoint string) (string, error) {
+	host := strings.TrimSpace(endpoint)
+	host = strings.TrimPrefix(strings.TrimPrefix(host, "https://"), "http://")
+	host = strings.TrimSuffix(host, "/")
+	if host == "" || strings.ContainsAny(host, " /@") {
+		return "", fmt.Errorf("%q is not a container registry host, e.g. contoso.azurecr.io", endpoint)
+	}
+	return host, nil
+}
+
+// IsAzureRegistry reports whether endpoint is an Azure container registry, which Login signs
+// in to; other registries need 'docker login'.
+func IsAzureRegistry(endpoint string) bool {
+	host, _, _ := strings.Cut(strings.ToLower(endpoint), ":")
+	return strings.HasSuffix(host, ".azurecr.io")
+}
+
+// Repository returns the repository azd deploys the image of service to for environment of
+// project: <project>/<service>-<environment>, or <project>/<service> without an environment.
+func Repository(project, service, environment string) string {
+	if environment == "" {
+		return imageName(project) + "/" + imageName(service)
+	}
+	return imageName(project) + "/" + imageName(service) + "-" + imageName(environment)
+}
+
+// PinnedReference returns image pinned to digest, e.g. contoso.azurecr.io/shop/api-dev:abc@sha256:...,
+// which deploys the exact image pushed even when its tag is pushed again; image without a digest.
+func PinnedReference(image, digest string) string {
+	if digest == "" {
+		return image
+	}
+	return image + "@" + digest
+}
+
+// ImageNameEnvVar returns the variable of the azd environment that holds the image of service,
+// which azd sets on deploy and infrastructure templates pass to the container app.
+func ImageNameEnvVar(service string) string {
+	return "SERVICE_" + strings.ToUpper(strings.ReplaceAll(service, "-", "_")) + "_IMAGE_NAME"
+}
+
+// gitOutput runs git in dir and returns its output; tests replace it.
+var gitOutput = func(ctx context.Context, dir string, args ...string) (string, error) {
+	// #nosec G204 -- git with fixed subcommands
+	out, err := exec.CommandContext(ctx, "git", append([]string{"-C", dir}, args...)...).Output()
+	return string(out), err
+}
+
+// GitTag returns the tag of images built from the checkout in dir: the short SHA of its commit,
+// with a -dirty suffix when the working tree has changes, so the tag doesn't claim a commit the
+// image wasn't built from.
+func GitTag(ctx context.Context, dir string) (string, error) {
+	sha, err := gitOutput(ctx, dir, "rev-parse", "--short=12", "HEAD")
+	if err != nil {
+		return "", fmt.Errorf("failed to find the git commit of %s, pass --tag: %w", dir, err)
+	}
+	tag := strings.TrimSpace(sha)
+	if status, err := gitOutput(ctx, dir, "status", "--porcelain"); err == nil && strings.TrimSpace(status) != "" {
+		tag += "-dirty"
+	}
+	return tag, nil
+}
+
+// Login signs the engine in to the Azure container registry at endpoint with the Azure CLI, as
+// the account signed in with 'az login'.
+func Login(ctx context.Context, endpoint string) error {
+	name, _, _ := strings.Cut(endpoint, ".")
+	// #nosec G204 -- Registry name comes from the azd environment and is passed as one argument
+	out, err := exec.CommandContext(ctx, "az", "acr", "login", "--name", name).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to sign in to %s with 'az acr login': %w: %s", endpoint, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// RecordImage sets the image of service in the azd environment of the project in workspace,
+// so 'azd provision' and 'azd deploy' use the image that was pushed.
+func RecordImage(ctx context.Context, workspace, environment, service, image string) error {
+	// #nosec G204 -- azd with a fixed subcommand; values are passed as single arguments
+	cmd := exec.CommandContext(ctx, "azd", "env", "set", ImageNameEnvVar(service), image, "--environment", environment)
+	cmd.Dir = workspace
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to set %s in azd environment %s: %w: %s", ImageNameEnvVar(service), environment, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package imagebuild
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestRegistryEndpoint(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{value: "contoso.azurecr.io", want: "contoso.azurecr.io"},
+		{value: "https://contoso.azurecr.io/", want: "contoso.azurecr.io"},
+		{value: "", wantErr: true},
+		{value: "contoso.azurecr.io/repo", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := RegistryEndpoint(map[string]string{RegistryEnvVar: tt.value})
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("RegistryEndpoint(%q) = %q, %v, want %q", tt.value, got, err, tt.want)
+		}
+	}
+}
+
+func TestRepositoryAndImageNameEnvVar(t *testing.T) {
+	if got := Repository("Todo App", "web-api", "dev"); got != "todo-app/web-api-dev" {
+		t.Errorf("Repository() = %q", got)
+	}
+	if got := Repository("Todo App", "web-api", ""); got != "todo-app/web-api" {
+		t.Errorf("Repository() without an environment = %q", got)
+	}
+	if got := ImageNameEnvVar("web-api"); got != "SERVICE_WEB_API_IMAGE_NAME" {
+		t.Errorf("ImageNameEnvVar() = %q", got)
+	}
+}
+
+func TestGitTag(t *testing.T) {
+	orig := gitOutput
+	defer func() { gitOutput = orig }()
+
+	status := ""
+	gitOutput = func(_ context.Context, _ string, args ...string) (string, error) {
+		if args[0] == "status" {
+			return status, nil
+		}
+		return "3f2a9c1d4b5e\n", nil
+	}
+	if tag, err := GitTag(context.Background(), "."); err != nil || tag != "3f2a9c1d4b5e" {
+		t.Errorf("GitTag() = %q, %v", tag, err)
+	}
+	status = " M main.go\n"
+	if tag, _ := GitTag(context.Background(), "."); tag != "3f2a9c1d4b5e-dirty" {
+		t.Errorf("GitTag() of a modified checkout = %q, want a -dirty suffix", tag)
+	}
+
+	gitOutput = func(context.Context, string, ...string) (string, error) {
+		return "", errors.New("not a git repository")
+	}
+	if _, err := GitTag(context.Background(), "."); err == nil {
+		t.Error("expected error outside a git checkout")
+	}
+}
+
+func TestBuildArgsPush(t *testing.T) {
+	target := Target{Service: "api", Image: "contoso.azurecr.io/shop/api-dev:abc", Dockerfile: "/w/Dockerfile", Context: "/w", Push: true}
+	args := BuildArgs(target, Cache{Type: CacheNone}, "/tmp/m.json")
+	if !slices.Contains(args, "type=image,push=true") || slices.Contains(args, "--load") {
+		t.Errorf("BuildArgs() = %v, want a pushed image that isn't loaded", args)
+	}
+}
+
+func TestNormalizeRegistry(t *testing.T) {
+	for _, endpoint := range []string{"contoso.azurecr.io", " https://contoso.azurecr.io/ ", "http://contoso.azurecr.io"} {
+		if got, err := NormalizeRegistry(endpoint); err != nil || got != "contoso.azurecr.io" {
+			t.Errorf("NormalizeRegistry(%q) = %q, %v", endpoint, got, err)
+		}
+	}
+	for _, endpoint := range []string{"", "contoso.azurecr.io/shop", "user@ghcr.io"} {
+		if _, err := NormalizeRegistry(endpoint); err == nil {
+			t.Errorf("NormalizeRegistry(%q) succeeded, want an error", endpoint)
+		}
+	}
+
+	if !IsAzureRegistry("contoso.azurecr.io") || IsAzureRegistry("ghcr.io") || IsAzureRegistry("localhost:5000") {
+		t.Error("IsAzureRegistry() should only match *.azurecr.io")
+	}
+}
+
+func TestPinnedReference(t *testing.T) {
+	if got := PinnedReference("contoso.azurecr.io/shop/api-dev:abc", "sha256:123"); got != "contoso.azurecr.io/shop/api-dev:abc@sha256:123" {
+		t.Errorf("PinnedReference() = %q", got)
+	}
+	if got := PinnedReference("shop-api:abc", ""); got != "shop-api:abc" {
+		t.Errorf("PinnedReference() without a digest = %q", got)
+	}
+}
@@ -0,0 +1,123 @@
+package imagebuild
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// WorkflowFile is the path of the generated GitHub Actions workflow, relative to the workspace.
+const WorkflowFile = ".github/workflows/azd-app-build.yml"
+
+// Workflow returns a GitHub Actions workflow that builds targets as 'azd app build' does, with
+// QEMU for platforms other than that of the runner, and pack for targets built with buildpacks. A local cache can't be shared by runners, so
+// the workflow uses the GitHub Actions cache instead.
+func Workflow(workspace string, targets []Target, cache Cache) string {
+	if cache.Type == CacheLocal {
+		cache = Cache{Type: CacheGHA}
+	}
+
+	var b strings.Builder
+	b.WriteString(`# Generated by 'azd app build --workflow'; regenerate it after changing the services or
+# the build settings of azure.yaml.
+name: azd-app-build
+
+on:
+  push:
+    branches: [main]
+  pull_request:
+
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+      - uses: docker/setup-qemu-action@v3
+      - uses: docker/setup-buildx-action@v3
+`)
+	for _, t := range targets {
+		if t.Builder != "" {
+			b.WriteString("      - uses: buildpacks/github-actions/setup-pack@v5.7.0\n")
+			break
+		}
+	}
+	for _, t := range targets {
+		id := "build-" + imageName(t.Service)
+		if t.Builder != "" {
+			writePackStep(&b, workspace, id, t)
+			continue
+		}
+		fmt.Fprintf(&b, "      - name: %s\n", yamlQuote("Build "+t.Service))
+		fmt.Fprintf(&b, "        id: %s\n", id)
+		b.WriteString("        uses: docker/build-push-action@v6\n")
+		b.WriteString("        with:\n")
+		fmt.Fprintf(&b, "          context: %s\n", yamlQuote(workflowPath(workspace, t.Context)))
+		fmt.Fprintf(&b, "          file: %s\n", yamlQuote(workflowPath(workspace, t.Dockerfile)))
+		if len(t.Platforms) > 0 {
+			fmt.Fprintf(&b, "          platforms: %s\n", yamlQuote(strings.Join(t.Platforms, ",")))
+		}
+		fmt.Fprintf(&b, "          tags: %s\n", yamlQuote(t.Image))
+		b.WriteString("          push: false\n")
+		if len(t.BuildArgs) > 0 {
+			b.WriteString("          build-args: |\n")
+			for _, arg := range t.BuildArgs {
+				fmt.Fprintf(&b, "            %s\n", arg)
+			}
+		}
+		if args := cache.Args(t.Service); len(args) == 4 {
+			fmt.Fprintf(&b, "          cache-from: %s\n", yamlQuote(args[1]))
+			fmt.Fprintf(&b, "          cache-to: %s\n", yamlQuote(args[3]))
+		}
+	}
+
+	b.WriteString("      - name: Summarize digests\n")
+	b.WriteString("        run: |\n")
+	b.WriteString("          {\n")
+	b.WriteString("            echo '| Service | Platforms | Digest |'\n")
+	b.WriteString("            echo '| --- | --- | --- |'\n")
+	for _, t := range targets {
+		platforms := strings.Join(t.Platforms, ", ")
+		if platforms == "" {
+			platforms = "linux/amd64"
+		}
+		fmt.Fprintf(&b, "            echo '| %s | %s | ${{ steps.build-%s.outputs.digest }} |'\n", t.Service, platforms, imageName(t.Service))
+	}
+	b.WriteString("          } >> \"$GITHUB_STEP_SUMMARY\"\n")
+	return b.String()
+}
+
+// writePackStep writes the step that builds t with buildpacks, whose digest output is the ID of
+// the image, as pack doesn't report the digest of an image it doesn't push.
+func writePackStep(b *strings.Builder, workspace, id string, t Target) {
+	t.Context = workflowPath(workspace, t.Context)
+	args := PackArgs(t)
+	for i, arg := range args {
+		args[i] = shellQuote(arg)
+	}
+	fmt.Fprintf(b, "      - name: %s\n", yamlQuote("Build "+t.Service))
+	fmt.Fprintf(b, "        id: %s\n", id)
+	b.WriteString("        run: |\n")
+	fmt.Fprintf(b, "          pack %s\n", strings.Join(args, " "))
+	fmt.Fprintf(b, "          echo \"digest=$(docker image inspect --format '{{.Id}}' %s)\" >> \"$GITHUB_OUTPUT\"\n", shellQuote(t.Image))
+}
+
+// shellQuote quotes s as a single-quoted argument of sh.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// workflowPath returns path relative to the workspace with forward slashes, as the workflow
+// runs in the root of the checkout.
+func workflowPath(workspace, path string) string {
+	rel, err := filepath.Rel(workspace, path)
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// yamlQuote quotes s as a YAML double-quoted scalar.
+func yamlQuote(s string) string {
+	return strconv.Quote(s)
+}
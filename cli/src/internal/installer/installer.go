@@ -1,12 +1,14 @@
 package installer
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"strings"
 
 	"github.com/jongio/azd-app/cli/src/internal/executor"
 	"github.com/jongio/azd-app/cli/src/internal/output"
@@ -15,7 +17,7 @@ import (
 )
 
 // InstallNodeDependencies installs dependencies using the detected package manager.
-func InstallNodeDependencies(project types.NodeProject) error {
+func InstallNodeDependencies(ctx context.Context, project types.NodeProject) error {
 	// Validate inputs
 	if err := security.ValidatePath(project.Dir); err != nil {
 		return fmt.Errorf("invalid project directory: %w", err)
@@ -25,7 +27,7 @@ func InstallNodeDependencies(project types.NodeProject) error {
 		return fmt.Errorf("invalid package manager: %w", err)
 	}
 
-	if err := executor.RunCommand(project.PackageManager, []string{"install"}, project.Dir); err != nil {
+	if err := executor.RunCommandContext(ctx, project.PackageManager, []string{"install"}, project.Dir); err != nil {
 		return fmt.Errorf("failed to run %s install: %w", project.PackageManager, err)
 	}
 
@@ -36,7 +38,7 @@ func InstallNodeDependencies(project types.NodeProject) error {
 }
 
 // RestoreDotnetProject runs dotnet restore on a project.
-func RestoreDotnetProject(project types.DotnetProject) error {
+func RestoreDotnetProject(ctx context.Context, project types.DotnetProject) error {
 	// Validate path
 	if err := security.ValidatePath(project.Path); err != nil {
 		return fmt.Errorf("invalid project path: %w", err)
@@ -47,7 +49,7 @@ func RestoreDotnetProject(project types.DotnetProject) error {
 	}
 
 	dir := filepath.Dir(project.Path)
-	if err := executor.RunCommand("dotnet", []string{"restore", project.Path}, dir); err != nil {
+	if err := executor.RunCommandContext(ctx, "dotnet", []string{"restore", project.Path}, dir); err != nil {
 		return fmt.Errorf("failed to restore: %w", err)
 	}
 
@@ -58,27 +60,29 @@ func RestoreDotnetProject(project types.DotnetProject) error {
 }
 
 // SetupPythonVirtualEnv creates a virtual environment and installs dependencies.
-func SetupPythonVirtualEnv(project types.PythonProject) error {
+func SetupPythonVirtualEnv(ctx context.Context, project types.PythonProject) error {
 	switch project.PackageManager {
 	case "uv":
-		return setupWithUv(project.Dir)
+		return setupWithUv(ctx, project.Dir)
 	case "poetry":
-		return setupWithPoetry(project.Dir)
+		return setupWithPoetry(ctx, project.Dir)
+	case "pipenv":
+		return setupWithPipenv(ctx, project.Dir)
 	case "pip":
-		return setupWithPip(project.Dir)
+		return setupWithPip(ctx, project.Dir)
 	default:
 		return fmt.Errorf("unknown package manager: %s", project.PackageManager)
 	}
 }
 
 // setupWithUv sets up a Python project using uv.
-func setupWithUv(projectDir string) error {
+func setupWithUv(ctx context.Context, projectDir string) error {
 	// Check if uv is installed
 	if _, err := exec.LookPath("uv"); err != nil {
 		if !output.IsJSON() {
 			output.ItemWarning("uv not found, falling back to pip")
 		}
-		return setupWithPip(projectDir)
+		return setupWithPip(ctx, projectDir)
 	}
 
 	// uv automatically manages virtual environments
@@ -87,14 +91,14 @@ func setupWithUv(projectDir string) error {
 		output.Item("Syncing with uv...")
 	}
 
-	cmd := exec.Command("uv", "sync")
+	cmd := exec.CommandContext(ctx, "uv", "sync")
 	cmd.Dir = projectDir
 
 	if output.IsJSON() {
 		cmd.Stdout = io.Discard
 		cmd.Stderr = io.Discard
 	} else {
-		cmd.Stdout = os.Stdout
+		cmd.Stdout = output.Writer()
 		cmd.Stderr = os.Stderr
 	}
 
@@ -104,14 +108,14 @@ func setupWithUv(projectDir string) error {
 			if !output.IsJSON() {
 				output.Item("Installing with uv pip...")
 			}
-			installCmd := exec.Command("uv", "pip", "install", "-r", "requirements.txt")
+			installCmd := exec.CommandContext(ctx, "uv", "pip", "install", "-r", "requirements.txt")
 			installCmd.Dir = projectDir
 
 			if output.IsJSON() {
 				installCmd.Stdout = io.Discard
 				installCmd.Stderr = io.Discard
 			} else {
-				installCmd.Stdout = os.Stdout
+				installCmd.Stdout = output.Writer()
 				installCmd.Stderr = os.Stderr
 			}
 
@@ -130,17 +134,17 @@ func setupWithUv(projectDir string) error {
 }
 
 // setupWithPoetry sets up a Python project using poetry.
-func setupWithPoetry(projectDir string) error {
+func setupWithPoetry(ctx context.Context, projectDir string) error {
 	// Check if poetry is installed
 	if _, err := exec.LookPath("poetry"); err != nil {
 		if !output.IsJSON() {
 			output.ItemWarning("poetry not found, falling back to pip")
 		}
-		return setupWithPip(projectDir)
+		return setupWithPip(ctx, projectDir)
 	}
 
 	// Check if virtual environment exists
-	checkCmd := exec.Command("poetry", "env", "info", "--path")
+	checkCmd := exec.CommandContext(ctx, "poetry", "env", "info", "--path")
 	checkCmd.Dir = projectDir
 	cmdOutput, err := checkCmd.CombinedOutput()
 
@@ -156,14 +160,14 @@ func setupWithPoetry(projectDir string) error {
 	}
 
 	// Install dependencies (use --no-root to avoid installing the package itself)
-	cmd := exec.Command("poetry", "install", "--no-root")
+	cmd := exec.CommandContext(ctx, "poetry", "install", "--no-root")
 	cmd.Dir = projectDir
 
 	if output.IsJSON() {
 		cmd.Stdout = io.Discard
 		cmd.Stderr = io.Discard
 	} else {
-		cmd.Stdout = os.Stdout
+		cmd.Stdout = output.Writer()
 		cmd.Stderr = os.Stderr
 	}
 
@@ -177,8 +181,45 @@ func setupWithPoetry(projectDir string) error {
 	return nil
 }
 
+// setupWithPipenv sets up a Python project using pipenv.
+func setupWithPipenv(ctx context.Context, projectDir string) error {
+	// Check if pipenv is installed
+	if _, err := exec.LookPath("pipenv"); err != nil {
+		if !output.IsJSON() {
+			output.ItemWarning("pipenv not found, falling back to pip")
+		}
+		return setupWithPip(ctx, projectDir)
+	}
+
+	if !output.IsJSON() {
+		output.Item("Installing dependencies with pipenv...")
+	}
+
+	// Keep the virtual environment in the project like the other package managers
+	cmd := exec.CommandContext(ctx, "pipenv", "install", "--dev")
+	cmd.Dir = projectDir
+	cmd.Env = append(os.Environ(), "PIPENV_VENV_IN_PROJECT=1")
+
+	if output.IsJSON() {
+		cmd.Stdout = io.Discard
+		cmd.Stderr = io.Discard
+	} else {
+		cmd.Stdout = output.Writer()
+		cmd.Stderr = os.Stderr
+	}
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to install with pipenv: %v", err)
+	}
+
+	if !output.IsJSON() {
+		output.ItemSuccess("Dependencies installed (pipenv)")
+	}
+	return nil
+}
+
 // setupWithPip sets up a Python project using pip and venv.
-func setupWithPip(projectDir string) error {
+func setupWithPip(ctx context.Context, projectDir string) error {
 	venvPath := filepath.Join(projectDir, ".venv")
 
 	// Check if venv already exists
@@ -194,7 +235,7 @@ func setupWithPip(projectDir string) error {
 	}
 
 	// Create virtual environment
-	cmd := exec.Command("python", "-m", "venv", ".venv")
+	cmd := exec.CommandContext(ctx, "python", "-m", "venv", ".venv")
 	cmd.Dir = projectDir
 	cmdOutput, err := cmd.CombinedOutput()
 	if err != nil {
@@ -221,7 +262,7 @@ func setupWithPip(projectDir string) error {
 		}
 
 		// Use safe executor for pip install
-		if err := executor.RunCommand(pipPath, []string{"install", "-r", "requirements.txt"}, projectDir); err != nil {
+		if err := executor.RunCommandContext(ctx, pipPath, []string{"install", "-r", "requirements.txt"}, projectDir); err != nil {
 			return fmt.Errorf("failed to install requirements: %w", err)
 		}
 
@@ -232,3 +273,30 @@ func setupWithPip(projectDir string) error {
 
 	return nil
 }
+
+// NodeDependenciesInstalled reports whether the project has a node_modules folder.
+func NodeDependenciesInstalled(project types.NodeProject) bool {
+	return dirExists(filepath.Join(project.Dir, "node_modules"))
+}
+
+// PythonEnvironmentExists reports whether the project has a .venv virtual environment,
+// which every package manager creates in the project.
+func PythonEnvironmentExists(project types.PythonProject) bool {
+	return dirExists(filepath.Join(project.Dir, ".venv"))
+}
+
+// DotnetProjectRestored reports whether the project has been restored, leaving
+// obj/project.assets.json next to it. Solutions are never considered restored.
+func DotnetProjectRestored(project types.DotnetProject) bool {
+	if strings.EqualFold(filepath.Ext(project.Path), ".sln") {
+		return false
+	}
+	info, err := os.Stat(filepath.Join(filepath.Dir(project.Path), "obj", "project.assets.json"))
+	return err == nil && !info.IsDir()
+}
+
+// dirExists reports whether path is a directory.
+func dirExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
@@ -4,6 +4,7 @@
 package installer
 
 import (
+	"context"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -92,7 +93,7 @@ func TestInstallNodeDependenciesIntegration(t *testing.T) {
 			tempDir := t.TempDir()
 			tt.setupFunc(t, tempDir)
 
-			err := InstallNodeDependencies(types.NodeProject{
+			err := InstallNodeDependencies(context.Background(), types.NodeProject{
 				Dir:            tempDir,
 				PackageManager: tt.packageManager,
 			})
@@ -129,7 +130,7 @@ func TestRestoreDotnetProjectIntegration(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	err := RestoreDotnetProject(types.DotnetProject{
+	err := RestoreDotnetProject(context.Background(), types.DotnetProject{
 		Path: csprojPath,
 	})
 	if err != nil {
@@ -218,7 +219,7 @@ requests = "^2.31.0"
 			tempDir := t.TempDir()
 			tt.setupFunc(t, tempDir)
 
-			err := SetupPythonVirtualEnv(types.PythonProject{
+			err := SetupPythonVirtualEnv(context.Background(), types.PythonProject{
 				Dir:            tempDir,
 				PackageManager: tt.packageManager,
 			})
@@ -1,6 +1,7 @@
 package installer
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"testing"
@@ -69,7 +70,7 @@ func TestInstallNodeDependencies(t *testing.T) {
 				t.Skip("Skipping actual package manager execution in unit tests")
 			}
 
-			err := InstallNodeDependencies(tt.project)
+			err := InstallNodeDependencies(context.Background(), tt.project)
 			if tt.expectError && err == nil {
 				t.Error("expected error but got nil")
 			}
@@ -201,7 +202,7 @@ func TestSetupPythonVirtualEnv(t *testing.T) {
 			if tt.skipRealInstall {
 				// For unknown package manager, we want to test the error path
 				if tt.project.PackageManager == "unknown" {
-					err := SetupPythonVirtualEnv(tt.project)
+					err := SetupPythonVirtualEnv(context.Background(), tt.project)
 					if err == nil {
 						t.Error("expected error for unknown package manager")
 					}
@@ -211,7 +212,7 @@ func TestSetupPythonVirtualEnv(t *testing.T) {
 				t.Skip("Skipping actual Python environment setup in unit tests")
 			}
 
-			err = SetupPythonVirtualEnv(tt.project)
+			err = SetupPythonVirtualEnv(context.Background(), tt.project)
 			if tt.expectError && err == nil {
 				t.Error("expected error but got nil")
 			}
@@ -293,7 +294,7 @@ func TestInstallNodeDependencies_InvalidPath(t *testing.T) {
 		PackageManager: "npm",
 	}
 
-	err := InstallNodeDependencies(project)
+	err := InstallNodeDependencies(context.Background(), project)
 	if err == nil {
 		t.Error("expected error for invalid path")
 	}
@@ -307,7 +308,7 @@ func TestInstallNodeDependencies_InvalidPackageManager(t *testing.T) {
 		PackageManager: "invalid-pm; rm -rf /",
 	}
 
-	err := InstallNodeDependencies(project)
+	err := InstallNodeDependencies(context.Background(), project)
 	if err == nil {
 		t.Error("expected error for invalid package manager")
 	}
@@ -318,7 +319,7 @@ func TestRestoreDotnetProject_InvalidPath(t *testing.T) {
 		Path: "../../../invalid/path.csproj",
 	}
 
-	err := RestoreDotnetProject(project)
+	err := RestoreDotnetProject(context.Background(), project)
 	if err == nil {
 		t.Error("expected error for invalid path")
 	}
@@ -332,7 +333,7 @@ func TestSetupPythonVirtualEnv_UnknownPackageManager(t *testing.T) {
 		PackageManager: "unknown-manager",
 	}
 
-	err := SetupPythonVirtualEnv(project)
+	err := SetupPythonVirtualEnv(context.Background(), project)
 	if err == nil {
 		t.Error("expected error for unknown package manager")
 	}
@@ -352,7 +353,7 @@ func TestSetupWithPip_ExistingVenv(t *testing.T) {
 	}
 
 	// Should return nil when venv exists
-	err := setupWithPip(tmpDir)
+	err := setupWithPip(context.Background(), tmpDir)
 	if err != nil {
 		t.Errorf("setupWithPip() with existing venv should not error: %v", err)
 	}
@@ -367,7 +368,7 @@ func TestSetupWithPip_NoRequirementsTxt(t *testing.T) {
 
 	// Try to create venv without requirements.txt
 	// This will succeed if python is available
-	err := setupWithPip(tmpDir)
+	err := setupWithPip(context.Background(), tmpDir)
 
 	// We don't assert success/failure as it depends on python availability
 	// Just verify it doesn't panic
@@ -383,7 +384,7 @@ func TestSetupWithPoetry_EnvExists(t *testing.T) {
 
 	// This tests the path where poetry env info succeeds
 	// In practice, this requires poetry to be installed
-	err := setupWithPoetry(tmpDir)
+	err := setupWithPoetry(context.Background(), tmpDir)
 
 	// We expect this to either succeed or fallback to pip
 	// Just verify it doesn't panic
@@ -404,9 +405,49 @@ func TestSetupWithUv_NoUvInstalled(t *testing.T) {
 	}
 
 	// This will fallback to pip if uv is not installed
-	err := setupWithUv(tmpDir)
+	err := setupWithUv(context.Background(), tmpDir)
 
 	// We don't assert success/failure as it depends on tool availability
 	// Just verify it doesn't panic
 	t.Logf("setupWithUv result: %v", err)
 }
+
+func TestInstalledChecks(t *testing.T) {
+	dir := t.TempDir()
+	mkdir := func(path string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Join(dir, path), 0750); err != nil {
+			t.Fatalf("failed to create %s: %v", path, err)
+		}
+	}
+	mkdir("web/node_modules")
+	mkdir("web-new")
+	mkdir("api/.venv")
+	mkdir("worker")
+	mkdir("Api/obj")
+	if err := os.WriteFile(filepath.Join(dir, "Api", "obj", "project.assets.json"), []byte("{}"), 0600); err != nil {
+		t.Fatalf("failed to write project.assets.json: %v", err)
+	}
+
+	if !NodeDependenciesInstalled(types.NodeProject{Dir: filepath.Join(dir, "web")}) {
+		t.Error("NodeDependenciesInstalled() = false for a project with node_modules")
+	}
+	if NodeDependenciesInstalled(types.NodeProject{Dir: filepath.Join(dir, "web-new")}) {
+		t.Error("NodeDependenciesInstalled() = true for a project without node_modules")
+	}
+	if !PythonEnvironmentExists(types.PythonProject{Dir: filepath.Join(dir, "api")}) {
+		t.Error("PythonEnvironmentExists() = false for a project with .venv")
+	}
+	if PythonEnvironmentExists(types.PythonProject{Dir: filepath.Join(dir, "worker")}) {
+		t.Error("PythonEnvironmentExists() = true for a project without .venv")
+	}
+	if !DotnetProjectRestored(types.DotnetProject{Path: filepath.Join(dir, "Api", "Api.csproj")}) {
+		t.Error("DotnetProjectRestored() = false for a restored project")
+	}
+	if DotnetProjectRestored(types.DotnetProject{Path: filepath.Join(dir, "worker", "Worker.csproj")}) {
+		t.Error("DotnetProjectRestored() = true for a project without obj")
+	}
+	if DotnetProjectRestored(types.DotnetProject{Path: filepath.Join(dir, "Api", "App.sln")}) {
+		t.Error("DotnetProjectRestored() = true for a solution")
+	}
+}
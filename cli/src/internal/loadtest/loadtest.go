@@ -0,0 +1,358 @@
+package loadtest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jongio/azd-app/cli/src/internal/httpclient"
+	"github.com/jongio/azd-app/cli/src/internal/security"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// DefaultRPS is the default request rate.
+	DefaultRPS = 10
+	// DefaultDuration is the default test duration.
+	DefaultDuration = 30 * time.Second
+	// DefaultTimeout is the default per-request timeout.
+	DefaultTimeout = 10 * time.Second
+	// MaxRPS is the highest supported request rate.
+	MaxRPS = 10000
+	// maxInFlight caps concurrent requests so a slow service can't exhaust local resources.
+	maxInFlight = 1000
+)
+
+// Request describes a single request in a scenario.
+type Request struct {
+	Name    string            `yaml:"name,omitempty" json:"name,omitempty"`
+	Method  string            `yaml:"method,omitempty" json:"method,omitempty"`
+	Path    string            `yaml:"path" json:"path"`
+	Headers map[string]string `yaml:"headers,omitempty" json:"headers,omitempty"`
+	Body    string            `yaml:"body,omitempty" json:"body,omitempty"`
+	Weight  int               `yaml:"weight,omitempty" json:"weight,omitempty"`
+}
+
+// Scenario is a weighted set of requests sent during a load test.
+type Scenario struct {
+	Requests []Request `yaml:"requests"`
+}
+
+// Config controls a load test run.
+type Config struct {
+	BaseURL  string
+	RPS      int
+	Duration time.Duration
+	Timeout  time.Duration
+	Scenario *Scenario
+}
+
+// Latency summarizes request latencies in milliseconds.
+type Latency struct {
+	Min  float64 `json:"min"`
+	Mean float64 `json:"mean"`
+	P50  float64 `json:"p50"`
+	P90  float64 `json:"p90"`
+	P95  float64 `json:"p95"`
+	P99  float64 `json:"p99"`
+	Max  float64 `json:"max"`
+}
+
+// Report contains the results of a load test.
+type Report struct {
+	Service     string         `json:"service,omitempty"`
+	Target      string         `json:"target"`
+	StartTime   time.Time      `json:"startTime"`
+	Duration    float64        `json:"durationSeconds"`
+	TargetRPS   int            `json:"targetRps"`
+	ActualRPS   float64        `json:"actualRps"`
+	Requests    int            `json:"requests"`
+	Errors      int            `json:"errors"`
+	StatusCodes map[string]int `json:"statusCodes"`
+	Latency     Latency        `json:"latencyMs"`
+}
+
+// sample is the outcome of a single request.
+type sample struct {
+	latency time.Duration
+	status  int
+	err     error
+}
+
+// LoadScenario reads a scenario file in YAML (or JSON) format.
+func LoadScenario(path string) (*Scenario, error) {
+	if err := security.ValidatePath(path); err != nil {
+		return nil, fmt.Errorf("invalid scenario path: %w", err)
+	}
+	// #nosec G304 -- Path validated by security.ValidatePath
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scenario file: %w", err)
+	}
+
+	var scenario Scenario
+	if err := yaml.Unmarshal(data, &scenario); err != nil {
+		return nil, fmt.Errorf("failed to parse scenario file: %w", err)
+	}
+	if err := scenario.Validate(); err != nil {
+		return nil, err
+	}
+	return &scenario, nil
+}
+
+// Validate checks that the scenario has at least one request and normalizes defaults.
+func (s *Scenario) Validate() error {
+	if len(s.Requests) == 0 {
+		return fmt.Errorf("scenario must define at least one request")
+	}
+	for i := range s.Requests {
+		req := &s.Requests[i]
+		if req.Method == "" {
+			req.Method = http.MethodGet
+		}
+		req.Method = strings.ToUpper(req.Method)
+		if req.Weight < 0 {
+			return fmt.Errorf("request %d: weight must not be negative", i+1)
+		}
+		if req.Weight == 0 {
+			req.Weight = 1
+		}
+		if !strings.HasPrefix(req.Path, "/") {
+			req.Path = "/" + req.Path
+		}
+	}
+	return nil
+}
+
+// pick chooses a request at random according to the request weights.
+func (s *Scenario) pick(rng *rand.Rand) Request {
+	total := 0
+	for _, req := range s.Requests {
+		total += req.Weight
+	}
+	// #nosec G404 -- Weighted request selection doesn't need a cryptographic RNG
+	n := rng.Intn(total)
+	for _, req := range s.Requests {
+		if n < req.Weight {
+			return req
+		}
+		n -= req.Weight
+	}
+	return s.Requests[len(s.Requests)-1]
+}
+
+// Run sends requests to cfg.BaseURL at the configured rate until the duration elapses
+// or ctx is cancelled, then returns a summary report. Cancelling ctx also stops requests in flight.
+func Run(ctx context.Context, cfg Config) (*Report, error) {
+	if cfg.RPS <= 0 || cfg.RPS > MaxRPS {
+		return nil, fmt.Errorf("rps must be between 1 and %d", MaxRPS)
+	}
+	if cfg.Duration <= 0 {
+		return nil, fmt.Errorf("duration must be greater than 0")
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+	if cfg.Scenario == nil {
+		cfg.Scenario = &Scenario{Requests: []Request{{Path: "/"}}}
+	}
+	if err := cfg.Scenario.Validate(); err != nil {
+		return nil, err
+	}
+	baseURL := strings.TrimRight(cfg.BaseURL, "/")
+
+	client := httpclient.NewLocal(cfg.Timeout)
+	// Load follows redirects, as browsers do, so that each request measures the page it reaches
+	client.CheckRedirect = nil
+	// #nosec G404 -- Weighted request selection doesn't need a cryptographic RNG
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+
+	// Requests sent before the duration ends may finish after it, unless ctx is done
+	sending, cancel := context.WithTimeout(ctx, cfg.Duration)
+	defer cancel()
+
+	var (
+		mu      sync.Mutex
+		samples []sample
+		wg      sync.WaitGroup
+	)
+	inFlight := make(chan struct{}, maxInFlight)
+
+	start := time.Now()
+	ticker := time.NewTicker(time.Second / time.Duration(cfg.RPS))
+	defer ticker.Stop()
+
+loop:
+	for {
+		select {
+		case <-sending.Done():
+			break loop
+		case <-ticker.C:
+			select {
+			case inFlight <- struct{}{}:
+			default:
+				// Too many requests outstanding; record as dropped
+				mu.Lock()
+				samples = append(samples, sample{err: fmt.Errorf("dropped: too many requests in flight")})
+				mu.Unlock()
+				continue
+			}
+
+			req := cfg.Scenario.pick(rng)
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer func() { <-inFlight }()
+				s := send(ctx, client, baseURL, req)
+				mu.Lock()
+				samples = append(samples, s)
+				mu.Unlock()
+			}()
+		}
+	}
+
+	wg.Wait()
+	elapsed := time.Since(start)
+
+	report := summarize(samples, elapsed)
+	report.Target = baseURL
+	report.StartTime = start
+	report.TargetRPS = cfg.RPS
+	return report, nil
+}
+
+// send issues a single request and measures its latency.
+func send(ctx context.Context, client *http.Client, baseURL string, req Request) sample {
+	var body io.Reader
+	if req.Body != "" {
+		body = strings.NewReader(req.Body)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, req.Method, baseURL+req.Path, body)
+	if err != nil {
+		return sample{err: err}
+	}
+	for k, v := range req.Headers {
+		httpReq.Header.Set(k, v)
+	}
+
+	start := time.Now()
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return sample{latency: time.Since(start), err: err}
+	}
+	// Drain the body so the latency includes the full response
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+
+	return sample{latency: time.Since(start), status: resp.StatusCode}
+}
+
+// summarize builds a report from request samples.
+// Transport errors and 5xx responses count as errors; latency covers completed responses.
+func summarize(samples []sample, elapsed time.Duration) *Report {
+	report := &Report{
+		Duration:    elapsed.Seconds(),
+		Requests:    len(samples),
+		StatusCodes: make(map[string]int),
+	}
+
+	latencies := make([]time.Duration, 0, len(samples))
+	for _, s := range samples {
+		if s.err != nil {
+			report.Errors++
+			report.StatusCodes["error"]++
+			continue
+		}
+		if s.status >= 500 {
+			report.Errors++
+		}
+		report.StatusCodes[fmt.Sprintf("%d", s.status)]++
+		latencies = append(latencies, s.latency)
+	}
+
+	if elapsed > 0 {
+		report.ActualRPS = float64(len(samples)) / elapsed.Seconds()
+	}
+	report.Latency = summarizeLatency(latencies)
+	return report
+}
+
+// summarizeLatency computes latency statistics in milliseconds.
+func summarizeLatency(latencies []time.Duration) Latency {
+	if len(latencies) == 0 {
+		return Latency{}
+	}
+
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+
+	var total time.Duration
+	for _, l := range latencies {
+		total += l
+	}
+
+	return Latency{
+		Min:  ms(latencies[0]),
+		Mean: ms(total / time.Duration(len(latencies))),
+		P50:  ms(Percentile(latencies, 50)),
+		P90:  ms(Percentile(latencies, 90)),
+		P95:  ms(Percentile(latencies, 95)),
+		P99:  ms(Percentile(latencies, 99)),
+		Max:  ms(latencies[len(latencies)-1]),
+	}
+}
+
+// Percentile returns the p-th percentile of sorted latencies using the nearest-rank method.
+func Percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(p/100*float64(len(sorted))+0.999999) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	if rank >= len(sorted) {
+		rank = len(sorted) - 1
+	}
+	return sorted[rank]
+}
+
+// ms converts a duration to fractional milliseconds.
+func ms(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+// Export posts the report as JSON to a metrics endpoint.
+func Export(ctx context.Context, endpoint string, report *Report) error {
+	data, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("failed to encode report: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("invalid export endpoint: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	// The endpoint is a remote collector, so the request goes through the shared client
+	resp, err := httpclient.Default().Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to export report: %w", err)
+	}
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("metrics endpoint returned status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,217 @@
+package loadtest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPercentile(t *testing.T) {
+	var sorted []time.Duration
+	for i := 1; i <= 100; i++ {
+		sorted = append(sorted, time.Duration(i)*time.Millisecond)
+	}
+
+	tests := []struct {
+		p    float64
+		want time.Duration
+	}{
+		{50, 50 * time.Millisecond},
+		{90, 90 * time.Millisecond},
+		{99, 99 * time.Millisecond},
+		{100, 100 * time.Millisecond},
+		{0, 1 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		if got := Percentile(sorted, tt.p); got != tt.want {
+			t.Errorf("Percentile(%v) = %v, want %v", tt.p, got, tt.want)
+		}
+	}
+
+	if got := Percentile(nil, 50); got != 0 {
+		t.Errorf("Percentile(nil) = %v, want 0", got)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	samples := []sample{
+		{latency: 10 * time.Millisecond, status: 200},
+		{latency: 20 * time.Millisecond, status: 200},
+		{latency: 30 * time.Millisecond, status: 503},
+		{err: errors.New("connection refused")},
+	}
+
+	report := summarize(samples, 2*time.Second)
+
+	if report.Requests != 4 {
+		t.Errorf("Requests = %d, want 4", report.Requests)
+	}
+	if report.Errors != 2 {
+		t.Errorf("Errors = %d, want 2", report.Errors)
+	}
+	if report.StatusCodes["200"] != 2 || report.StatusCodes["503"] != 1 || report.StatusCodes["error"] != 1 {
+		t.Errorf("StatusCodes = %v", report.StatusCodes)
+	}
+	if report.ActualRPS != 2 {
+		t.Errorf("ActualRPS = %v, want 2", report.ActualRPS)
+	}
+	if report.Latency.Min != 10 || report.Latency.Max != 30 || report.Latency.Mean != 20 || report.Latency.P50 != 20 {
+		t.Errorf("Latency = %+v", report.Latency)
+	}
+}
+
+func TestLoadScenario(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "scenario.yaml")
+	content := `requests:
+  - name: list
+    path: api/items
+    weight: 3
+  - name: create
+    method: post
+    path: /api/items
+    headers:
+      Content-Type: application/json
+    body: '{"name": "x"}'
+`
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("failed to write scenario: %v", err)
+	}
+
+	scenario, err := LoadScenario(path)
+	if err != nil {
+		t.Fatalf("LoadScenario() error = %v", err)
+	}
+
+	if len(scenario.Requests) != 2 {
+		t.Fatalf("len(Requests) = %d, want 2", len(scenario.Requests))
+	}
+	list := scenario.Requests[0]
+	if list.Method != http.MethodGet || list.Path != "/api/items" || list.Weight != 3 {
+		t.Errorf("Requests[0] = %+v", list)
+	}
+	create := scenario.Requests[1]
+	if create.Method != http.MethodPost || create.Weight != 1 || create.Headers["Content-Type"] != "application/json" {
+		t.Errorf("Requests[1] = %+v", create)
+	}
+}
+
+func TestScenarioValidate(t *testing.T) {
+	if err := (&Scenario{}).Validate(); err == nil {
+		t.Error("Validate() expected error for empty scenario")
+	}
+	if err := (&Scenario{Requests: []Request{{Path: "/", Weight: -1}}}).Validate(); err == nil {
+		t.Error("Validate() expected error for negative weight")
+	}
+}
+
+func TestRun(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	report, err := Run(context.Background(), Config{
+		BaseURL:  server.URL,
+		RPS:      50,
+		Duration: 300 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if report.Requests == 0 || int(hits.Load()) != report.Requests {
+		t.Errorf("Requests = %d, server hits = %d", report.Requests, hits.Load())
+	}
+	if report.Errors != 0 {
+		t.Errorf("Errors = %d, want 0", report.Errors)
+	}
+	if report.TargetRPS != 50 || report.Target != server.URL {
+		t.Errorf("report = %+v", report)
+	}
+}
+
+func TestRunCancelStopsRequestsInFlight(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	report, err := Run(ctx, Config{
+		BaseURL:  server.URL,
+		RPS:      10,
+		Duration: time.Minute,
+		Timeout:  10 * time.Second,
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Run() took %s after ctx was done, want requests in flight stopped", elapsed)
+	}
+	if report.Requests == 0 || report.Errors != report.Requests {
+		t.Errorf("Requests = %d, Errors = %d, want every request stopped", report.Requests, report.Errors)
+	}
+}
+
+func TestRunRejectsInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+	}{
+		{"zero rps", Config{BaseURL: "http://localhost", RPS: 0, Duration: time.Second}},
+		{"rps too high", Config{BaseURL: "http://localhost", RPS: MaxRPS + 1, Duration: time.Second}},
+		{"zero duration", Config{BaseURL: "http://localhost", RPS: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Run(context.Background(), tt.cfg); err == nil {
+				t.Error("Run() expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestExport(t *testing.T) {
+	var received Report
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_ = json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	report := &Report{Service: "api", Requests: 42}
+	if err := Export(context.Background(), server.URL, report); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	if received.Service != "api" || received.Requests != 42 {
+		t.Errorf("received = %+v", received)
+	}
+}
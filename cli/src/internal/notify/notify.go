@@ -0,0 +1,56 @@
+// Package notify shows desktop notifications, for alerts a developer should see while the
+// terminal of a long session is out of sight.
+package notify
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// Send shows a desktop notification with title and message. It fails where the platform has
+// no notification command, such as a Linux machine without notify-send.
+func Send(title, message string) error {
+	name, args := command(runtime.GOOS, title, message)
+	if _, err := exec.LookPath(name); err != nil {
+		return fmt.Errorf("desktop notifications are unavailable: %w", err)
+	}
+
+	// #nosec G204 -- Command is a fixed platform notifier; title and message are passed as arguments or quoted
+	cmd := exec.Command(name, args...)
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to show notification: %w", err)
+	}
+
+	// Release the notifier process; we don't care about its exit status
+	go func() { _ = cmd.Wait() }()
+	return nil
+}
+
+// command returns the platform-specific command that shows a notification.
+func command(goos, title, message string) (string, []string) {
+	switch goos {
+	case "windows":
+		script := "Add-Type -AssemblyName System.Windows.Forms; " +
+			"$n = New-Object System.Windows.Forms.NotifyIcon; " +
+			"$n.Icon = [System.Drawing.SystemIcons]::Warning; $n.Visible = $true; " +
+			"$n.ShowBalloonTip(10000, " + powershellQuote(title) + ", " + powershellQuote(message) + ", 'Warning'); " +
+			"Start-Sleep -Seconds 10; $n.Dispose()"
+		return "powershell", []string{"-NoProfile", "-NonInteractive", "-Command", script}
+	case "darwin":
+		return "osascript", []string{"-e", "display notification " + appleScriptQuote(message) + " with title " + appleScriptQuote(title)}
+	default:
+		return "notify-send", []string{"--app-name=azd app", title, message}
+	}
+}
+
+// powershellQuote quotes s as a single-quoted PowerShell string.
+func powershellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// appleScriptQuote quotes s as an AppleScript string.
+func appleScriptQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
@@ -0,0 +1,30 @@
+package notify
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCommand(t *testing.T) {
+	tests := []struct {
+		goos     string
+		wantName string
+		wantArg  string
+	}{
+		{"linux", "notify-send", "it's \"leaking\""},
+		{"darwin", "osascript", `display notification "it's \"leaking\"" with title "api"`},
+		{"windows", "powershell", `'api', 'it''s "leaking"'`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.goos, func(t *testing.T) {
+			name, args := command(tt.goos, "api", `it's "leaking"`)
+			if name != tt.wantName {
+				t.Errorf("command(%q) name = %q, want %q", tt.goos, name, tt.wantName)
+			}
+			if last := args[len(args)-1]; !strings.Contains(last, tt.wantArg) {
+				t.Errorf("command(%q) last argument = %q, want it to contain %q", tt.goos, last, tt.wantArg)
+			}
+		})
+	}
+}
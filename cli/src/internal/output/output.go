@@ -3,22 +3,28 @@ package output
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"strings"
+
+	"gopkg.in/yaml.v3"
 )
 
 // Format represents the output format.
 type Format string
 
 const (
-	// FormatDefault is the default human-readable format.
+	// FormatDefault is the default human-readable format, with tables.
 	FormatDefault Format = "default"
 	// FormatJSON is JSON format.
 	FormatJSON Format = "json"
+	// FormatYAML is YAML format, with the fields and names of the JSON format.
+	FormatYAML Format = "yaml"
 )
 
-// ANSI color codes for consistent styling
-const (
+// ANSI color codes for consistent styling. They are empty when colors are disabled, so
+// output built from them needs no checks of its own; see SetColor.
+var (
 	Reset = "\033[0m"
 	Bold  = "\033[1m"
 	Dim   = "\033[2m"
@@ -46,15 +52,27 @@ const (
 // Global output format setting
 var globalFormat Format = FormatDefault
 
+// quiet suppresses human-readable output other than warnings and errors.
+var quiet bool
+
+// prompting sends human-readable output where warnings go; see Prompt.
+var prompting bool
+
+// redirect receives all human-readable output, warnings and errors included, when set; see
+// Redirect.
+var redirect io.Writer
+
 // SetFormat sets the global output format.
 func SetFormat(format string) error {
 	switch format {
-	case "default", "":
+	case "table", "default", "":
 		globalFormat = FormatDefault
 	case "json":
 		globalFormat = FormatJSON
+	case "yaml":
+		globalFormat = FormatYAML
 	default:
-		return fmt.Errorf("invalid output format: %s (valid options: default, json)", format)
+		return fmt.Errorf("invalid output format: %s (valid options: table, json, yaml)", format)
 	}
 	return nil
 }
@@ -69,6 +87,62 @@ func IsJSON() bool {
 	return globalFormat == FormatJSON
 }
 
+// IsStructured returns true if the output format is machine-readable: JSON or YAML.
+func IsStructured() bool {
+	return globalFormat == FormatJSON || globalFormat == FormatYAML
+}
+
+// SetQuiet suppresses human-readable output other than warnings and errors.
+func SetQuiet(enabled bool) {
+	quiet = enabled
+}
+
+// IsQuiet reports whether human-readable output other than warnings and errors is suppressed.
+func IsQuiet() bool {
+	return quiet
+}
+
+// Writer returns where human-readable output goes. Stdout carries only machine-readable
+// output in JSON and YAML mode, so human output goes to stderr then, and nowhere when quiet.
+func Writer() io.Writer {
+	switch {
+	case redirect != nil:
+		return redirect
+	case prompting:
+		return ErrWriter()
+	case quiet:
+		return io.Discard
+	case IsStructured():
+		return os.Stderr
+	default:
+		return os.Stdout
+	}
+}
+
+// ErrWriter returns where warnings and errors go: stderr in every mode, so they never mix with
+// output piped from stdout.
+func ErrWriter() io.Writer {
+	if redirect != nil {
+		return redirect
+	}
+	return os.Stderr
+}
+
+// Redirect sends all human-readable output, warnings and errors included, to w until the
+// returned function is called, e.g. while a full-screen view owns the terminal.
+func Redirect(w io.Writer) (restore func()) {
+	redirect = w
+	return func() { redirect = nil }
+}
+
+// Prompt runs fn with its human-readable output sent where warnings go, so that what the user
+// is asked to decide on is shown even when quiet, and never mixed into JSON on stdout.
+func Prompt(fn func()) {
+	prompting = true
+	defer func() { prompting = false }()
+	fn()
+}
+
 // PrintJSON prints data as JSON to stdout.
 func PrintJSON(data interface{}) error {
 	encoder := json.NewEncoder(os.Stdout)
@@ -76,6 +150,44 @@ func PrintJSON(data interface{}) error {
 	return encoder.Encode(data)
 }
 
+// PrintYAML prints data as YAML to stdout, with the fields, names, and order of its JSON, so
+// both formats share one schema.
+func PrintYAML(data interface{}) error {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	// JSON is YAML in flow style, which only needs its styles reset to print as block YAML
+	var doc yaml.Node
+	if err := yaml.Unmarshal(encoded, &doc); err != nil {
+		return err
+	}
+	resetStyle(&doc)
+	encoder := yaml.NewEncoder(os.Stdout)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// resetStyle clears the flow and quoting styles of node and its children; the encoder still
+// quotes strings that would otherwise read as another type.
+func resetStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		resetStyle(child)
+	}
+}
+
+// PrintStructured prints data as JSON or YAML, as the output format selects.
+func PrintStructured(data interface{}) error {
+	if globalFormat == FormatYAML {
+		return PrintYAML(data)
+	}
+	return PrintJSON(data)
+}
+
 // PrintDefault prints data in default format using a custom formatter function.
 func PrintDefault(formatter func()) {
 	if globalFormat == FormatDefault {
@@ -85,10 +197,10 @@ func PrintDefault(formatter func()) {
 
 // Print outputs data in the configured format.
 // For default format, uses the formatter function.
-// For JSON format, marshals the data object.
+// For JSON and YAML format, marshals the data object.
 func Print(data interface{}, formatter func()) error {
-	if globalFormat == FormatJSON {
-		return PrintJSON(data)
+	if IsStructured() {
+		return PrintStructured(data)
 	}
 	formatter()
 	return nil
@@ -98,82 +210,82 @@ func Print(data interface{}, formatter func()) error {
 
 // Header prints a bold header with a divider
 func Header(text string) {
-	fmt.Printf("\n%s%s%s\n", Bold, text, Reset)
-	fmt.Println(strings.Repeat("=", len(text)))
+	fmt.Fprintf(Writer(), "\n%s%s%s\n", Bold, text, Reset)
+	fmt.Fprintln(Writer(), strings.Repeat("=", Width(text)))
 }
 
 // Section prints a section header
 func Section(icon, text string) {
-	fmt.Printf("\n%s%s %s%s\n", Cyan, icon, text, Reset)
+	fmt.Fprintf(Writer(), "\n%s%s %s%s\n", Cyan, icon, text, Reset)
 }
 
 // Success prints a success message with green checkmark
 func Success(format string, args ...interface{}) {
 	msg := fmt.Sprintf(format, args...)
-	fmt.Printf("%s✓%s %s\n", BrightGreen, Reset, msg)
+	fmt.Fprintf(Writer(), "%s✓%s %s\n", BrightGreen, Reset, msg)
 }
 
 // Error prints an error message with red X
 func Error(format string, args ...interface{}) {
 	msg := fmt.Sprintf(format, args...)
-	fmt.Printf("%s✗%s %s\n", BrightRed, Reset, msg)
+	fmt.Fprintf(ErrWriter(), "%s✗%s %s\n", BrightRed, Reset, msg)
 }
 
 // Warning prints a warning message with yellow triangle
 func Warning(format string, args ...interface{}) {
 	msg := fmt.Sprintf(format, args...)
-	fmt.Printf("%s⚠%s  %s\n", BrightYellow, Reset, msg)
+	fmt.Fprintf(ErrWriter(), "%s⚠%s  %s\n", BrightYellow, Reset, msg)
 }
 
 // Info prints an info message with blue info icon
 func Info(format string, args ...interface{}) {
 	msg := fmt.Sprintf(format, args...)
-	fmt.Printf("%sℹ%s  %s\n", BrightBlue, Reset, msg)
+	fmt.Fprintf(Writer(), "%sℹ%s  %s\n", BrightBlue, Reset, msg)
 }
 
 // Step prints a step message with an icon
 func Step(icon, format string, args ...interface{}) {
 	msg := fmt.Sprintf(format, args...)
-	fmt.Printf("%s%s%s %s\n", Cyan, icon, Reset, msg)
+	fmt.Fprintf(Writer(), "%s%s%s %s\n", Cyan, icon, Reset, msg)
 }
 
 // Item prints an indented item
 func Item(format string, args ...interface{}) {
 	msg := fmt.Sprintf(format, args...)
-	fmt.Printf("   %s\n", msg)
+	fmt.Fprintf(Writer(), "   %s\n", msg)
 }
 
 // ItemSuccess prints an indented success item
 func ItemSuccess(format string, args ...interface{}) {
 	msg := fmt.Sprintf(format, args...)
-	fmt.Printf("   %s✓%s %s\n", Green, Reset, msg)
+	fmt.Fprintf(Writer(), "   %s✓%s %s\n", Green, Reset, msg)
 }
 
 // ItemError prints an indented error item
 func ItemError(format string, args ...interface{}) {
 	msg := fmt.Sprintf(format, args...)
-	fmt.Printf("   %s✗%s %s\n", Red, Reset, msg)
+	fmt.Fprintf(ErrWriter(), "   %s✗%s %s\n", Red, Reset, msg)
 }
 
 // ItemWarning prints an indented warning item
 func ItemWarning(format string, args ...interface{}) {
 	msg := fmt.Sprintf(format, args...)
-	fmt.Printf("   %s⚠%s  %s\n", Yellow, Reset, msg)
+	fmt.Fprintf(ErrWriter(), "   %s⚠%s  %s\n", Yellow, Reset, msg)
 }
 
 // Divider prints a horizontal divider
 func Divider() {
-	fmt.Printf("\n%s%s%s\n", Dim, strings.Repeat("-", 75), Reset)
+	fmt.Fprintf(Writer(), "\n%s%s%s\n", Dim, strings.Repeat("-", 75), Reset)
 }
 
 // Newline prints a blank line
 func Newline() {
-	fmt.Println()
+	fmt.Fprintln(Writer())
 }
 
 // Label prints a label and value pair
 func Label(label, value string) {
-	fmt.Printf("   %s%-12s%s %s\n", Dim, label+":", Reset, value)
+	fmt.Fprintf(Writer(), "   %s%s%s %s\n", Dim, PadRight(label+":", 12), Reset, value)
 }
 
 // Highlight prints highlighted text
@@ -25,6 +25,16 @@ func TestSetFormat(t *testing.T) {
 			format:  "json",
 			wantErr: false,
 		},
+		{
+			name:    "table format",
+			format:  "table",
+			wantErr: false,
+		},
+		{
+			name:    "yaml format",
+			format:  "yaml",
+			wantErr: false,
+		},
 		{
 			name:    "empty format (defaults to default)",
 			format:  "",
@@ -239,6 +249,38 @@ func TestPrintJSONMode(t *testing.T) {
 	}
 }
 
+func TestPrintYAMLMode(t *testing.T) {
+	SetFormat("yaml")
+	defer SetFormat("default") // Reset
+
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	data := struct {
+		SchemaVersion int               `json:"schemaVersion"`
+		Name          string            `json:"name"`
+		Version       string            `json:"version"`
+		Ports         []int             `json:"ports"`
+		Labels        map[string]string `json:"labels,omitempty"`
+	}{SchemaVersion: 1, Name: "web", Version: "1.0", Ports: []int{3000, 3001}}
+	err := Print(data, func() { t.Error("Print() formatter called in YAML mode") })
+
+	w.Close()
+	os.Stdout = oldStdout
+	if err != nil {
+		t.Fatalf("Print() error = %v, want nil", err)
+	}
+
+	var buf bytes.Buffer
+	io.Copy(&buf, r)
+	// The JSON names and order, in block style, with strings that look like numbers quoted
+	want := "schemaVersion: 1\nname: web\nversion: \"1.0\"\nports:\n  - 3000\n  - 3001\n"
+	if got := buf.String(); got != want {
+		t.Errorf("Print() in YAML mode = %q, want %q", got, want)
+	}
+}
+
 func TestOutputFunctions(t *testing.T) {
 	// Test that output functions don't panic
 	// We can't easily test the actual output without capturing stdout
@@ -358,16 +400,16 @@ func TestSuccessWithArgs(t *testing.T) {
 }
 
 func TestErrorWithArgs(t *testing.T) {
-	// Capture stdout
-	oldStdout := os.Stdout
+	// Capture stderr
+	oldStderr := os.Stderr
 	r, w, _ := os.Pipe()
-	os.Stdout = w
+	os.Stderr = w
 
 	Error("Error %s %d", "message", 456)
 
-	// Restore stdout
+	// Restore stderr
 	w.Close()
-	os.Stdout = oldStdout
+	os.Stderr = oldStderr
 
 	// Read captured output
 	var buf bytes.Buffer
@@ -378,3 +420,132 @@ func TestErrorWithArgs(t *testing.T) {
 		t.Errorf("Error() output = %q, want to contain 'Error message 456'", output)
 	}
 }
+
+// captureStreams runs fn and returns what it wrote to stdout and stderr.
+func captureStreams(t *testing.T, fn func()) (string, string) {
+	t.Helper()
+	dir := t.TempDir()
+	stdout, err := os.Create(dir + "/stdout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stderr, err := os.Create(dir + "/stderr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldStdout, oldStderr := os.Stdout, os.Stderr
+	os.Stdout, os.Stderr = stdout, stderr
+	defer func() { os.Stdout, os.Stderr = oldStdout, oldStderr }()
+
+	fn()
+	_ = stdout.Close()
+	_ = stderr.Close()
+	out, _ := os.ReadFile(stdout.Name())
+	errOut, _ := os.ReadFile(stderr.Name())
+	return string(out), string(errOut)
+}
+
+// TestOutputStreams covers the contract pipelines rely on: in JSON mode stdout carries only
+// JSON, warnings and errors go to stderr in every mode, and --quiet leaves only those.
+func TestOutputStreams(t *testing.T) {
+	t.Cleanup(func() {
+		_ = SetFormat("default")
+		SetQuiet(false)
+	})
+
+	human := func() {
+		Header("header")
+		Section("📦", "section")
+		Step("🔧", "step")
+		Info("info")
+		Item("item")
+		ItemSuccess("item success")
+		Label("label", "value")
+		Success("success")
+		table := NewTable()
+		table.MaxWidth = -1
+		table.AddRow("table", "row")
+		table.Print()
+	}
+	diagnostics := func() {
+		Warning("warning")
+		Error("error")
+		ItemWarning("item warning")
+		ItemError("item error")
+	}
+
+	tests := []struct {
+		name             string
+		format           string
+		quiet            bool
+		humanStream      string // "stdout", "stderr", or "" when suppressed
+		diagnosticsTo    string
+		jsonOnlyOnStdout bool
+	}{
+		{name: "default", format: "default", humanStream: "stdout", diagnosticsTo: "stderr"},
+		{name: "table", format: "table", humanStream: "stdout", diagnosticsTo: "stderr"},
+		{name: "json", format: "json", humanStream: "stderr", diagnosticsTo: "stderr", jsonOnlyOnStdout: true},
+		{name: "quiet", format: "default", quiet: true, diagnosticsTo: "stderr"},
+		{name: "json and quiet", format: "json", quiet: true, diagnosticsTo: "stderr", jsonOnlyOnStdout: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := SetFormat(tt.format); err != nil {
+				t.Fatal(err)
+			}
+			SetQuiet(tt.quiet)
+
+			stdout, stderr := captureStreams(t, func() {
+				human()
+				diagnostics()
+				Prompt(func() { Item("prompt") })
+				if IsJSON() {
+					_ = PrintJSON(map[string]string{"status": "ok"})
+				}
+			})
+			streams := map[string]string{"stdout": stdout, "stderr": stderr}
+
+			for _, text := range []string{"header", "section", "step", "info", "item success", "label", "table  row"} {
+				for name, content := range streams {
+					if want := name == tt.humanStream; strings.Contains(content, text) != want {
+						t.Errorf("%q on %s = %v, want %v", text, name, !want, want)
+					}
+				}
+			}
+			for _, text := range []string{"warning", "error", "item warning", "item error", "prompt"} {
+				for name, content := range streams {
+					if want := name == tt.diagnosticsTo; strings.Contains(content, text) != want {
+						t.Errorf("%q on %s = %v, want %v", text, name, !want, want)
+					}
+				}
+			}
+			if tt.jsonOnlyOnStdout {
+				var decoded map[string]string
+				if err := json.Unmarshal([]byte(stdout), &decoded); err != nil || decoded["status"] != "ok" {
+					t.Errorf("stdout is not only JSON: %q (%v)", stdout, err)
+				}
+			}
+		})
+	}
+}
+
+func TestRedirect(t *testing.T) {
+	var redirected bytes.Buffer
+	stdout, stderr := captureStreams(t, func() {
+		restore := Redirect(&redirected)
+		Info("info")
+		Warning("warning")
+		Error("error")
+		restore()
+		Info("after")
+	})
+	for _, text := range []string{"info", "warning", "error"} {
+		if !strings.Contains(redirected.String(), text) {
+			t.Errorf("%q was not redirected: %q", text, redirected.String())
+		}
+	}
+	if !strings.Contains(stdout, "after") || strings.Contains(stdout, "info") || stderr != "" {
+		t.Errorf("stdout = %q, stderr = %q, want only output after restoring", stdout, stderr)
+	}
+}
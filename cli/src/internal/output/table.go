@@ -0,0 +1,258 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Ellipsis ends text that was truncated to fit.
+const Ellipsis = "…"
+
+// minColumnWidth is the narrowest a table column is truncated to.
+const minColumnWidth = 8
+
+// Table lays out rows of text in aligned columns. Widths are measured in terminal columns, so
+// wide East Asian characters and colored cells line up, and columns are truncated with an
+// ellipsis when the table does not fit the terminal.
+type Table struct {
+	Indent   string // Printed before each row
+	Gap      int    // Spaces between columns; 0 uses 2
+	MaxWidth int    // Columns the table may use; 0 uses TerminalWidth, negative does not limit it
+
+	headers []string
+	rows    [][]string
+}
+
+// NewTable creates a table with the given column headers. Without headers, no header row is
+// printed and the first row sets the number of columns.
+func NewTable(headers ...string) *Table {
+	return &Table{headers: headers}
+}
+
+// AddRow adds a row. Missing cells are empty and extra cells are dropped.
+func (t *Table) AddRow(cells ...string) {
+	t.rows = append(t.rows, cells)
+}
+
+// SortBy sorts the rows by the text of a column, ignoring case and colors. Rows that compare
+// equal keep their order.
+func (t *Table) SortBy(column int) {
+	key := func(row []string) string {
+		if column < len(row) {
+			return strings.ToLower(stripEscapes(row[column]))
+		}
+		return ""
+	}
+	sort.SliceStable(t.rows, func(i, j int) bool { return key(t.rows[i]) < key(t.rows[j]) })
+}
+
+// Print writes the table where human-readable output goes; see Writer.
+func (t *Table) Print() {
+	t.Render(Writer())
+}
+
+// Render writes the table to w.
+func (t *Table) Render(w io.Writer) {
+	columns := len(t.headers)
+	if columns == 0 && len(t.rows) > 0 {
+		columns = len(t.rows[0])
+	}
+	if columns == 0 {
+		return
+	}
+
+	rows := t.rows
+	if len(t.headers) > 0 {
+		rows = append([][]string{t.headers}, rows...)
+	}
+	widths := make([]int, columns)
+	for _, row := range rows {
+		for i := 0; i < columns && i < len(row); i++ {
+			widths[i] = max(widths[i], Width(row[i]))
+		}
+	}
+	t.fit(widths)
+
+	gap := strings.Repeat(" ", t.gap())
+	for _, row := range rows {
+		var line strings.Builder
+		line.WriteString(t.Indent)
+		for i := 0; i < columns; i++ {
+			cell := ""
+			if i < len(row) {
+				cell = Truncate(row[i], widths[i])
+			}
+			if i == columns-1 {
+				line.WriteString(cell)
+				break
+			}
+			line.WriteString(PadRight(cell, widths[i]))
+			line.WriteString(gap)
+		}
+		fmt.Fprintln(w, strings.TrimRight(line.String(), " "))
+	}
+}
+
+// gap returns the number of spaces between columns.
+func (t *Table) gap() int {
+	if t.Gap > 0 {
+		return t.Gap
+	}
+	return 2
+}
+
+// fit narrows the widest columns, one column at a time, until the table fits its maximum
+// width or no column can be narrowed further.
+func (t *Table) fit(widths []int) {
+	limit := t.MaxWidth
+	if limit == 0 {
+		limit = TerminalWidth()
+	}
+	if limit <= 0 {
+		return
+	}
+
+	total := Width(t.Indent) + t.gap()*(len(widths)-1)
+	for _, w := range widths {
+		total += w
+	}
+	for total > limit {
+		widest := 0
+		for i, w := range widths {
+			if w > widths[widest] {
+				widest = i
+			}
+		}
+		if widths[widest] <= minColumnWidth {
+			return
+		}
+		widths[widest]--
+		total--
+	}
+}
+
+// Width returns the number of terminal columns s takes up: wide East Asian characters and
+// emoji take two, combining marks and ANSI escape codes none.
+func Width(s string) int {
+	width := 0
+	for i := 0; i < len(s); {
+		if n := escapeLength(s[i:]); n > 0 {
+			i += n
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		width += runeWidth(r)
+		i += size
+	}
+	return width
+}
+
+// Truncate shortens s to at most width columns, replacing the end with an ellipsis. Escape
+// codes are kept, and colors are reset after a cut.
+func Truncate(s string, width int) string {
+	if Width(s) <= width {
+		return s
+	}
+	if width <= 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	used, colored := 0, false
+	for i := 0; i < len(s); {
+		if n := escapeLength(s[i:]); n > 0 {
+			b.WriteString(s[i : i+n])
+			colored = true
+			i += n
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		rw := runeWidth(r)
+		if used+rw > width-Width(Ellipsis) {
+			break
+		}
+		used += rw
+		b.WriteRune(r)
+		i += size
+	}
+	b.WriteString(Ellipsis)
+	if colored {
+		b.WriteString(Reset)
+	}
+	return b.String()
+}
+
+// PadRight pads s with spaces to width columns.
+func PadRight(s string, width int) string {
+	if pad := width - Width(s); pad > 0 {
+		return s + strings.Repeat(" ", pad)
+	}
+	return s
+}
+
+// stripEscapes removes ANSI escape codes from s.
+func stripEscapes(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		if n := escapeLength(s[i:]); n > 0 {
+			i += n
+			continue
+		}
+		b.WriteByte(s[i])
+		i++
+	}
+	return b.String()
+}
+
+// escapeLength returns the length of the ANSI escape sequence s starts with, or 0.
+func escapeLength(s string) int {
+	if len(s) < 2 || s[0] != '\033' || s[1] != '[' {
+		return 0
+	}
+	for i := 2; i < len(s); i++ {
+		if c := s[i]; c >= 0x40 && c <= 0x7e {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// wideRanges are the ranges of characters that take two terminal columns: East Asian wide and
+// fullwidth characters, and emoji.
+var wideRanges = [][2]rune{
+	{0x1100, 0x115F},   // Hangul Jamo
+	{0x2E80, 0x303E},   // CJK radicals and punctuation
+	{0x3041, 0x33FF},   // Hiragana, Katakana, CJK compatibility
+	{0x3400, 0x4DBF},   // CJK extension A
+	{0x4E00, 0x9FFF},   // CJK unified ideographs
+	{0xA000, 0xA4CF},   // Yi
+	{0xAC00, 0xD7A3},   // Hangul syllables
+	{0xF900, 0xFAFF},   // CJK compatibility ideographs
+	{0xFE30, 0xFE4F},   // CJK compatibility forms
+	{0xFF00, 0xFF60},   // Fullwidth forms
+	{0xFFE0, 0xFFE6},   // Fullwidth signs
+	{0x1F300, 0x1F64F}, // Pictographs and emoticons
+	{0x1F680, 0x1F6FF}, // Transport and map symbols
+	{0x1F900, 0x1F9FF}, // Supplemental symbols and pictographs
+	{0x20000, 0x3FFFD}, // CJK extensions B and later
+}
+
+// runeWidth returns the number of terminal columns r takes up.
+func runeWidth(r rune) int {
+	switch {
+	case r == 0 || r == 0x200D || (r >= 0xFE00 && r <= 0xFE0F):
+		return 0 // NUL, zero width joiner, variation selectors
+	case unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf, unicode.Cc):
+		return 0
+	}
+	for _, wide := range wideRanges {
+		if r >= wide[0] && r <= wide[1] {
+			return 2
+		}
+	}
+	return 1
+}
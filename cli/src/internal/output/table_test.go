@@ -0,0 +1,107 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWidth(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		want int
+	}{
+		{name: "ascii", s: "api", want: 3},
+		{name: "cjk", s: "服务", want: 4},
+		{name: "hangul", s: "서비스", want: 6},
+		{name: "combining mark", s: "café", want: 4},
+		{name: "emoji", s: "🚀 go", want: 5},
+		{name: "escape codes", s: Cyan + "web" + Reset, want: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Width(tt.s); got != tt.want {
+				t.Errorf("Width(%q) = %d, want %d", tt.s, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		name  string
+		s     string
+		width int
+		want  string
+	}{
+		{name: "fits", s: "api", width: 5, want: "api"},
+		{name: "ascii", s: "notifications", width: 6, want: "notif…"},
+		{name: "cjk keeps whole characters", s: "订单服务", width: 6, want: "订单…"},
+		{name: "colored", s: "\033[36mnotifications\033[0m", width: 4, want: "\033[36mnot…" + Reset},
+		{name: "no room", s: "api", width: 0, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Truncate(tt.s, tt.width)
+			if got != tt.want {
+				t.Errorf("Truncate(%q, %d) = %q, want %q", tt.s, tt.width, got, tt.want)
+			}
+			if Width(got) > tt.width && tt.width > 0 {
+				t.Errorf("Truncate(%q, %d) is %d columns wide", tt.s, tt.width, Width(got))
+			}
+		})
+	}
+}
+
+func TestTableRender(t *testing.T) {
+	table := NewTable("NAME", "STATE")
+	table.Indent, table.MaxWidth = "  ", -1
+	table.AddRow("web", "running")
+	table.AddRow("订单服务", "stopped")
+	table.AddRow("API", "starting")
+	table.SortBy(0)
+
+	var buf bytes.Buffer
+	table.Render(&buf)
+	want := "" +
+		"  NAME      STATE\n" +
+		"  API       starting\n" +
+		"  web       running\n" +
+		"  订单服务  stopped\n"
+	if buf.String() != want {
+		t.Errorf("Render() =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+func TestTableFitsMaxWidth(t *testing.T) {
+	table := NewTable()
+	table.MaxWidth = 30
+	table.AddRow("notifications-service", "https://notifications.example.com/health")
+
+	var buf bytes.Buffer
+	table.Render(&buf)
+	want := "notifications…  https://notif…\n"
+	if buf.String() != want {
+		t.Errorf("Render() = %q, want %q", buf.String(), want)
+	}
+	if got := Width(buf.String()) - 1; got > table.MaxWidth {
+		t.Errorf("rendered row is %d columns wide, want at most %d", got, table.MaxWidth)
+	}
+}
+
+func TestSetColor(t *testing.T) {
+	t.Cleanup(func() { SetColor(true) })
+
+	SetColor(false)
+	if ColorEnabled() || Reset != "" || Cyan != "" {
+		t.Errorf("SetColor(false) left colors enabled: %q %q", Reset, Cyan)
+	}
+	if got := URL("http://localhost"); got != "http://localhost" {
+		t.Errorf("URL() without colors = %q", got)
+	}
+
+	SetColor(true)
+	if !ColorEnabled() || Reset != "\033[0m" || Cyan != "\033[36m" {
+		t.Errorf("SetColor(true) did not restore colors: %q %q", Reset, Cyan)
+	}
+}
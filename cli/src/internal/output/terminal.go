@@ -0,0 +1,60 @@
+package output
+
+import (
+	"os"
+	"strconv"
+)
+
+// colorCodes are the color variables with their codes, so SetColor can clear and restore them.
+var colorCodes = map[*string]string{
+	&Reset: Reset, &Bold: Bold, &Dim: Dim,
+	&Black: Black, &Red: Red, &Green: Green, &Yellow: Yellow, &Blue: Blue,
+	&Magenta: Magenta, &Cyan: Cyan, &White: White, &Gray: Gray,
+	&BrightRed: BrightRed, &BrightGreen: BrightGreen, &BrightYellow: BrightYellow,
+	&BrightBlue: BrightBlue, &BrightMagenta: BrightMagenta, &BrightCyan: BrightCyan,
+}
+
+// colorEnabled reports whether output is colored.
+var colorEnabled = true
+
+func init() {
+	// https://no-color.org: any non-empty NO_COLOR disables colors
+	if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		SetColor(false)
+	}
+}
+
+// SetColor enables or disables colored output.
+func SetColor(enabled bool) {
+	colorEnabled = enabled
+	for variable, code := range colorCodes {
+		if enabled {
+			*variable = code
+		} else {
+			*variable = ""
+		}
+	}
+}
+
+// ColorEnabled reports whether output is colored. Code that prints its own escape codes
+// should check it.
+func ColorEnabled() bool {
+	return colorEnabled
+}
+
+// TerminalWidth returns the width of the terminal stdout writes to, in columns: the COLUMNS
+// environment variable when it is set, and otherwise the size of the terminal. It returns 0
+// when the width is unknown, e.g. when output is redirected to a file.
+func TerminalWidth() int {
+	if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && columns > 0 {
+		return columns
+	}
+	width, _ := terminalSize(os.Stdout.Fd())
+	return width
+}
+
+// TerminalSize returns the columns and rows of the terminal fd refers to, or zeros when it is
+// not one or the size is not detected on this platform.
+func TerminalSize(fd uintptr) (width, height int) {
+	return terminalSize(fd)
+}
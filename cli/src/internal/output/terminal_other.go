@@ -0,0 +1,8 @@
+//go:build !linux && !darwin
+
+package output
+
+// terminalSize is not detected on this platform; set COLUMNS to limit the width of tables.
+func terminalSize(uintptr) (int, int) {
+	return 0, 0
+}
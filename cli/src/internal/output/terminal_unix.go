@@ -0,0 +1,22 @@
+//go:build linux || darwin
+
+package output
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// terminalSize returns the columns and rows of the terminal fd refers to, or zeros when it is
+// not one.
+func terminalSize(fd uintptr) (int, int) {
+	var size struct {
+		Rows, Cols, XPixel, YPixel uint16
+	}
+	// #nosec G103 -- The ioctl interface requires a pointer to the winsize struct
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, syscall.TIOCGWINSZ, uintptr(unsafe.Pointer(&size)))
+	if errno != 0 {
+		return 0, 0
+	}
+	return int(size.Cols), int(size.Rows)
+}
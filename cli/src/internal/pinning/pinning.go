@@ -0,0 +1,501 @@
+package pinning
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/jongio/azd-app/cli/src/internal/detector"
+	"github.com/jongio/azd-app/cli/src/internal/security"
+	"github.com/jongio/azd-app/cli/src/internal/types"
+)
+
+// Ecosystems.
+const (
+	Node   = "node"
+	Python = "python"
+	Dotnet = "dotnet"
+)
+
+// Issue reasons.
+const (
+	ReasonFloatingTag     = "floating tag"
+	ReasonRange           = "version range"
+	ReasonUnpinned        = "no version"
+	ReasonMissingLockfile = "missing lockfile"
+)
+
+// Issue is a dependency whose version is not reproducible.
+type Issue struct {
+	Ecosystem string `json:"ecosystem"`
+	File      string `json:"file"`
+	Package   string `json:"package,omitempty"`
+	Section   string `json:"section,omitempty"` // Dependency section of package.json, e.g. devDependencies
+	Spec      string `json:"spec,omitempty"`
+	Reason    string `json:"reason"`
+	Resolved  string `json:"resolved,omitempty"`
+}
+
+// Pinnable reports whether the issue can be fixed by pinning to the resolved version.
+func (i Issue) Pinnable() bool {
+	return i.Package != "" && i.Resolved != ""
+}
+
+var (
+	exactNodeVersion   = regexp.MustCompile(`^v?\d+\.\d+\.\d+(-[0-9A-Za-z.-]+)?(\+[0-9A-Za-z.-]+)?$`)
+	requirementPattern = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9._-]*)(\[[^\]]*\])?\s*([^;]*?)\s*(;.*)?$`)
+	packageRefPattern  = regexp.MustCompile(`<PackageReference\s[^>]*>`)
+	includeAttrPattern = regexp.MustCompile(`\bInclude="([^"]+)"`)
+	versionAttrPattern = regexp.MustCompile(`\bVersion="([^"]*)"`)
+	pythonNameNormal   = regexp.MustCompile(`[-_.]+`)
+)
+
+// Scan reports unpinned or floating dependency versions and missing lockfiles
+// for every Node.js, Python, and .NET project under rootDir.
+func Scan(rootDir string) ([]Issue, error) {
+	scan, err := detector.ScanWorkspace(rootDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan projects: %w", err)
+	}
+
+	var issues []Issue
+	for _, project := range scan.NodeProjects {
+		issues = append(issues, scanNodeProject(project)...)
+	}
+	for _, project := range scan.PythonProjects {
+		issues = append(issues, scanPythonProject(project)...)
+	}
+	for _, project := range scan.DotnetProjects {
+		issues = append(issues, scanDotnetProject(project)...)
+	}
+	return issues, nil
+}
+
+// Pin rewrites each pinnable issue's manifest to its resolved version.
+// Returns the issues that were pinned.
+func Pin(issues []Issue) ([]Issue, error) {
+	byFile := make(map[string][]Issue)
+	var files []string
+	for _, issue := range issues {
+		if !issue.Pinnable() {
+			continue
+		}
+		if _, ok := byFile[issue.File]; !ok {
+			files = append(files, issue.File)
+		}
+		byFile[issue.File] = append(byFile[issue.File], issue)
+	}
+	sort.Strings(files)
+
+	var pinned []Issue
+	for _, file := range files {
+		data, err := readFile(file)
+		if err != nil {
+			return pinned, err
+		}
+
+		content := string(data)
+		var filePinned []Issue
+		for _, issue := range byFile[file] {
+			updated := pinInContent(issue, content)
+			if updated != content {
+				content = updated
+				filePinned = append(filePinned, issue)
+			}
+		}
+		if len(filePinned) == 0 {
+			continue
+		}
+
+		info, err := os.Stat(file)
+		if err != nil {
+			return pinned, fmt.Errorf("failed to stat %s: %w", file, err)
+		}
+		if err := os.WriteFile(file, []byte(content), info.Mode().Perm()); err != nil {
+			return pinned, fmt.Errorf("failed to write %s: %w", file, err)
+		}
+		pinned = append(pinned, filePinned...)
+	}
+	return pinned, nil
+}
+
+// ClassifyNodeSpec returns the issue reason for a package.json version spec, or "" if it is exact.
+// Local, workspace, git, and URL references are not registry versions and are never reported.
+func ClassifyNodeSpec(spec string) string {
+	spec = strings.TrimSpace(spec)
+	for _, prefix := range []string{"workspace:", "file:", "link:", "portal:", "git", "http:", "https:", "npm:", "github:"} {
+		if strings.HasPrefix(spec, prefix) {
+			return ""
+		}
+	}
+	switch {
+	case spec == "" || spec == "*" || spec == "x" || spec == "latest" || spec == "next":
+		return ReasonFloatingTag
+	case exactNodeVersion.MatchString(spec):
+		return ""
+	case strings.ContainsAny(spec, "^~<>|*xX ") || strings.Contains(spec, ".x"):
+		return ReasonRange
+	case strings.ContainsAny(spec, "0123456789"):
+		// Partial versions such as "1" or "1.2" match any later minor or patch release
+		return ReasonRange
+	default:
+		return ReasonFloatingTag
+	}
+}
+
+// ClassifyRequirement parses a requirements.txt line and returns the package name,
+// its version specifier, and the issue reason ("" when pinned with == or ===).
+// Returns an empty name for comments, options, and URL or path requirements.
+func ClassifyRequirement(line string) (name, spec, reason string) {
+	if i := strings.Index(line, " #"); i >= 0 {
+		line = line[:i]
+	}
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "-") || strings.Contains(line, "://") || strings.Contains(line, " @ ") {
+		return "", "", ""
+	}
+
+	match := requirementPattern.FindStringSubmatch(line)
+	if match == nil {
+		return "", "", ""
+	}
+	name, spec = match[1], strings.TrimSpace(match[3])
+
+	switch {
+	case spec == "":
+		return name, spec, ReasonUnpinned
+	case (strings.HasPrefix(spec, "==") || strings.HasPrefix(spec, "===")) && !strings.Contains(spec, ",") && !strings.Contains(spec, "*"):
+		return name, spec, ""
+	default:
+		return name, spec, ReasonRange
+	}
+}
+
+// ClassifyNuGetVersion returns the issue reason for a PackageReference version, or "" if it is exact.
+func ClassifyNuGetVersion(version string) string {
+	version = strings.TrimSpace(version)
+	switch {
+	case version == "":
+		return ReasonUnpinned
+	case strings.Contains(version, "*"):
+		return ReasonFloatingTag
+	case strings.HasPrefix(version, "[") && strings.HasSuffix(version, "]") && !strings.Contains(version, ","):
+		return "" // [1.2.3] is an exact match
+	case strings.ContainsAny(version, "[](),"):
+		return ReasonRange
+	default:
+		// A bare version is a minimum in NuGet, but restore resolves the lowest match, so it is reproducible
+		return ""
+	}
+}
+
+// scanNodeProject checks package.json dependencies and the presence of a lockfile.
+func scanNodeProject(project types.NodeProject) []Issue {
+	file := filepath.Join(project.Dir, "package.json")
+	data, err := readFile(file)
+	if err != nil {
+		return nil
+	}
+
+	var pkg struct {
+		Dependencies         map[string]string `json:"dependencies"`
+		DevDependencies      map[string]string `json:"devDependencies"`
+		OptionalDependencies map[string]string `json:"optionalDependencies"`
+	}
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return nil
+	}
+
+	// Workspace members install into and lock at the workspace root
+	installDir := project.Dir
+	if project.WorkspaceRoot != "" {
+		installDir = project.WorkspaceRoot
+	}
+
+	var issues []Issue
+	if project.WorkspaceRoot == "" && !hasNodeLockfile(project.Dir) {
+		issues = append(issues, Issue{Ecosystem: Node, File: file, Reason: ReasonMissingLockfile})
+	}
+
+	sections := []struct {
+		name string
+		deps map[string]string
+	}{
+		{"dependencies", pkg.Dependencies},
+		{"devDependencies", pkg.DevDependencies},
+		{"optionalDependencies", pkg.OptionalDependencies},
+	}
+	for _, section := range sections {
+		for _, name := range sortedKeys(section.deps) {
+			spec := section.deps[name]
+			reason := ClassifyNodeSpec(spec)
+			if reason == "" {
+				continue
+			}
+			issues = append(issues, Issue{
+				Ecosystem: Node,
+				File:      file,
+				Package:   name,
+				Section:   section.name,
+				Spec:      spec,
+				Reason:    reason,
+				Resolved:  resolveNodeVersion(project.Dir, installDir, name),
+			})
+		}
+	}
+	return issues
+}
+
+// scanPythonProject checks requirements.txt pins and the lockfile of uv and Poetry projects.
+func scanPythonProject(project types.PythonProject) []Issue {
+	var issues []Issue
+
+	switch project.PackageManager {
+	case "uv":
+		if _, err := os.Stat(filepath.Join(project.Dir, "uv.lock")); os.IsNotExist(err) {
+			issues = append(issues, Issue{Ecosystem: Python, File: filepath.Join(project.Dir, "pyproject.toml"), Reason: ReasonMissingLockfile})
+		}
+	case "poetry":
+		if _, err := os.Stat(filepath.Join(project.Dir, "poetry.lock")); os.IsNotExist(err) {
+			issues = append(issues, Issue{Ecosystem: Python, File: filepath.Join(project.Dir, "pyproject.toml"), Reason: ReasonMissingLockfile})
+		}
+	}
+
+	file := filepath.Join(project.Dir, "requirements.txt")
+	data, err := readFile(file)
+	if err != nil {
+		return issues
+	}
+
+	resolved := installedPythonPackages(project.Dir)
+	for _, line := range strings.Split(string(data), "\n") {
+		name, spec, reason := ClassifyRequirement(line)
+		if name == "" || reason == "" {
+			continue
+		}
+		issues = append(issues, Issue{
+			Ecosystem: Python,
+			File:      file,
+			Package:   name,
+			Spec:      spec,
+			Reason:    reason,
+			Resolved:  resolved[normalizePythonName(name)],
+		})
+	}
+	return issues
+}
+
+// scanDotnetProject checks PackageReference versions of a .csproj, .fsproj, or .vbproj file.
+func scanDotnetProject(project types.DotnetProject) []Issue {
+	if !detector.IsDotnetProjectFile(project.Path) {
+		return nil
+	}
+	data, err := readFile(project.Path)
+	if err != nil {
+		return nil
+	}
+
+	resolved := restoredNuGetPackages(filepath.Dir(project.Path))
+
+	var issues []Issue
+	for _, element := range packageRefPattern.FindAllString(string(data), -1) {
+		include := includeAttrPattern.FindStringSubmatch(element)
+		if include == nil {
+			continue
+		}
+		version := versionAttrPattern.FindStringSubmatch(element)
+		if version == nil {
+			continue // Version is set in a child element or by central package management
+		}
+
+		reason := ClassifyNuGetVersion(version[1])
+		if reason == "" {
+			continue
+		}
+		issues = append(issues, Issue{
+			Ecosystem: Dotnet,
+			File:      project.Path,
+			Package:   include[1],
+			Spec:      version[1],
+			Reason:    reason,
+			Resolved:  resolved[strings.ToLower(include[1])],
+		})
+	}
+	return issues
+}
+
+// pinInContent replaces an issue's version spec with its resolved version in a manifest.
+func pinInContent(issue Issue, content string) string {
+	switch issue.Ecosystem {
+	case Node:
+		// Only the section the issue was found in, as a package may be in several
+		start, end := jsonObjectSpan(content, issue.Section)
+		if start < 0 {
+			return content
+		}
+		pattern := regexp.MustCompile(`("` + regexp.QuoteMeta(issue.Package) + `"\s*:\s*)"` + regexp.QuoteMeta(issue.Spec) + `"`)
+		return content[:start] + pattern.ReplaceAllString(content[start:end], `${1}"`+issue.Resolved+`"`) + content[end:]
+
+	case Python:
+		lines := strings.Split(content, "\n")
+		for i, line := range lines {
+			name, spec, _ := ClassifyRequirement(line)
+			if name != issue.Package || spec != issue.Spec {
+				continue
+			}
+			// Keep the indentation and inline comment of the line
+			requirement, comment := line, ""
+			if j := strings.Index(line, " #"); j >= 0 {
+				requirement, comment = line[:j], line[j:]
+			}
+			trimmed := strings.TrimSpace(requirement)
+			indent := requirement[:strings.Index(requirement, trimmed)]
+			match := requirementPattern.FindStringSubmatch(trimmed)
+			lines[i] = indent + match[1] + match[2] + "==" + issue.Resolved + match[4] + requirement[len(indent)+len(trimmed):] + comment
+		}
+		return strings.Join(lines, "\n")
+
+	case Dotnet:
+		return packageRefPattern.ReplaceAllStringFunc(content, func(element string) string {
+			include := includeAttrPattern.FindStringSubmatch(element)
+			if include == nil || include[1] != issue.Package {
+				return element
+			}
+			return strings.Replace(element, `Version="`+issue.Spec+`"`, `Version="`+issue.Resolved+`"`, 1)
+		})
+	}
+	return content
+}
+
+// jsonObjectSpan returns the start and end offsets in content of the members of the object held
+// by the first key named key, or -1 if there is none.
+func jsonObjectSpan(content, key string) (start, end int) {
+	loc := regexp.MustCompile(`"` + regexp.QuoteMeta(key) + `"\s*:\s*\{`).FindStringIndex(content)
+	if loc == nil {
+		return -1, -1
+	}
+	depth, inString := 0, false
+	for i := loc[1] - 1; i < len(content); i++ {
+		switch c := content[i]; {
+		case inString && c == '\\':
+			i++
+		case c == '"':
+			inString = !inString
+		case inString:
+		case c == '{':
+			depth++
+		case c == '}':
+			depth--
+			if depth == 0 {
+				return loc[1], i
+			}
+		}
+	}
+	return -1, -1
+}
+
+// hasNodeLockfile reports whether dir contains an npm, pnpm, or yarn lockfile.
+func hasNodeLockfile(dir string) bool {
+	for _, name := range []string{"package-lock.json", "npm-shrinkwrap.json", "pnpm-lock.yaml", "yarn.lock", "bun.lockb"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveNodeVersion returns the installed version of a package from node_modules,
+// checking the project first and then the workspace root.
+func resolveNodeVersion(projectDir, installDir, name string) string {
+	for _, dir := range []string{projectDir, installDir} {
+		data, err := readFile(filepath.Join(dir, "node_modules", filepath.FromSlash(name), "package.json"))
+		if err != nil {
+			continue
+		}
+		var pkg struct {
+			Version string `json:"version"`
+		}
+		if json.Unmarshal(data, &pkg) == nil && pkg.Version != "" {
+			return pkg.Version
+		}
+	}
+	return ""
+}
+
+// installedPythonPackages returns the versions installed in the project's virtual environment,
+// keyed by normalized package name.
+func installedPythonPackages(projectDir string) map[string]string {
+	packages := make(map[string]string)
+	for _, venv := range []string{".venv", "venv"} {
+		patterns := []string{
+			filepath.Join(projectDir, venv, "lib", "python*", "site-packages", "*.dist-info"),
+			filepath.Join(projectDir, venv, "Lib", "site-packages", "*.dist-info"),
+		}
+		for _, pattern := range patterns {
+			matches, _ := filepath.Glob(pattern)
+			for _, match := range matches {
+				base := strings.TrimSuffix(filepath.Base(match), ".dist-info")
+				i := strings.LastIndex(base, "-")
+				if i <= 0 {
+					continue
+				}
+				packages[normalizePythonName(base[:i])] = base[i+1:]
+			}
+		}
+	}
+	return packages
+}
+
+// restoredNuGetPackages returns the versions resolved by the last restore, keyed by lowercase package ID.
+func restoredNuGetPackages(projectDir string) map[string]string {
+	packages := make(map[string]string)
+
+	data, err := readFile(filepath.Join(projectDir, "obj", "project.assets.json"))
+	if err != nil {
+		return packages
+	}
+	var assets struct {
+		Libraries map[string]json.RawMessage `json:"libraries"`
+	}
+	if err := json.Unmarshal(data, &assets); err != nil {
+		return packages
+	}
+	for key := range assets.Libraries {
+		if name, version, ok := strings.Cut(key, "/"); ok {
+			packages[strings.ToLower(name)] = version
+		}
+	}
+	return packages
+}
+
+// normalizePythonName normalizes a distribution name as described in PEP 503.
+func normalizePythonName(name string) string {
+	return pythonNameNormal.ReplaceAllString(strings.ToLower(name), "-")
+}
+
+// readFile reads a file after validating its path.
+func readFile(path string) ([]byte, error) {
+	if err := security.ValidatePath(path); err != nil {
+		return nil, fmt.Errorf("invalid path %s: %w", path, err)
+	}
+	// #nosec G304 -- Path validated by security.ValidatePath
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return data, nil
+}
+
+// sortedKeys returns the keys of a map in sorted order.
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,216 @@
+package pinning
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("failed to write %s: %v", path, err)
+	}
+}
+
+func TestClassifyNodeSpec(t *testing.T) {
+	tests := map[string]string{
+		"1.2.3":          "",
+		"v1.2.3":         "",
+		"1.2.3-beta.1":   "",
+		"^1.2.3":         ReasonRange,
+		"~1.2.3":         ReasonRange,
+		">=1.0.0":        ReasonRange,
+		"1.x":            ReasonRange,
+		"1.2":            ReasonRange,
+		"1.0.0 - 2.0.0":  ReasonRange,
+		"latest":         ReasonFloatingTag,
+		"*":              ReasonFloatingTag,
+		"":               ReasonFloatingTag,
+		"beta":           ReasonFloatingTag,
+		"workspace:*":    "",
+		"file:../shared": "",
+		"github:org/lib": "",
+	}
+	for spec, want := range tests {
+		if got := ClassifyNodeSpec(spec); got != want {
+			t.Errorf("ClassifyNodeSpec(%q) = %q, want %q", spec, got, want)
+		}
+	}
+}
+
+func TestClassifyRequirement(t *testing.T) {
+	tests := []struct {
+		line       string
+		wantName   string
+		wantSpec   string
+		wantReason string
+	}{
+		{"flask==3.0.0", "flask", "==3.0.0", ""},
+		{"flask", "flask", "", ReasonUnpinned},
+		{"fastapi[all]>=0.100", "fastapi", ">=0.100", ReasonRange},
+		{"requests~=2.31 ; python_version > '3.8'", "requests", "~=2.31", ReasonRange},
+		{"django==4.*", "django", "==4.*", ReasonRange},
+		{"uvicorn  # server", "uvicorn", "", ReasonUnpinned},
+		{"# comment", "", "", ""},
+		{"-r base.txt", "", "", ""},
+		{"pkg @ https://example.com/pkg.whl", "", "", ""},
+		{"", "", "", ""},
+	}
+	for _, tt := range tests {
+		name, spec, reason := ClassifyRequirement(tt.line)
+		if name != tt.wantName || spec != tt.wantSpec || reason != tt.wantReason {
+			t.Errorf("ClassifyRequirement(%q) = (%q, %q, %q), want (%q, %q, %q)",
+				tt.line, name, spec, reason, tt.wantName, tt.wantSpec, tt.wantReason)
+		}
+	}
+}
+
+func TestClassifyNuGetVersion(t *testing.T) {
+	tests := map[string]string{
+		"8.0.0":     "",
+		"[8.0.0]":   "",
+		"8.*":       ReasonFloatingTag,
+		"[8.0,9.0)": ReasonRange,
+		"(,9.0]":    ReasonRange,
+		"":          ReasonUnpinned,
+	}
+	for version, want := range tests {
+		if got := ClassifyNuGetVersion(version); got != want {
+			t.Errorf("ClassifyNuGetVersion(%q) = %q, want %q", version, got, want)
+		}
+	}
+}
+
+func TestScanAndPinNode(t *testing.T) {
+	dir := t.TempDir()
+	pkgPath := filepath.Join(dir, "web", "package.json")
+	writeFile(t, pkgPath, `{
+  "dependencies": {
+    "express": "^4.18.0",
+    "lodash": "4.17.21"
+  },
+  "devDependencies": {
+    "vite": "latest"
+  },
+  "peerDependencies": {
+    "express": "^4.18.0"
+  }
+}
+`)
+	writeFile(t, filepath.Join(dir, "web", "node_modules", "express", "package.json"), `{"version": "4.19.2"}`)
+
+	issues, err := Scan(dir)
+	if err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+
+	got := make(map[string]Issue)
+	for _, issue := range issues {
+		got[issue.Package] = issue
+	}
+	if _, ok := got[""]; !ok {
+		t.Error("expected missing lockfile issue")
+	}
+	if got["express"].Reason != ReasonRange || got["express"].Resolved != "4.19.2" {
+		t.Errorf("unexpected express issue: %+v", got["express"])
+	}
+	if got["vite"].Reason != ReasonFloatingTag || got["vite"].Pinnable() {
+		t.Errorf("unexpected vite issue: %+v", got["vite"])
+	}
+	if _, ok := got["lodash"]; ok {
+		t.Error("exact version should not be reported")
+	}
+
+	pinned, err := Pin(issues)
+	if err != nil {
+		t.Fatalf("Pin failed: %v", err)
+	}
+	if len(pinned) != 1 || pinned[0].Package != "express" {
+		t.Fatalf("expected only express to be pinned, got %+v", pinned)
+	}
+
+	data, _ := os.ReadFile(pkgPath)
+	if !strings.Contains(string(data), `"express": "4.19.2"`) || !strings.Contains(string(data), `"vite": "latest"`) {
+		t.Errorf("unexpected package.json after pin:\n%s", data)
+	}
+	// Only the section the issue was found in is pinned
+	if !strings.Contains(string(data), `"peerDependencies": {
+    "express": "^4.18.0"`) {
+		t.Errorf("peerDependencies should keep its range after pin:\n%s", data)
+	}
+}
+
+func TestScanAndPinPython(t *testing.T) {
+	dir := t.TempDir()
+	reqPath := filepath.Join(dir, "api", "requirements.txt")
+	writeFile(t, reqPath, "fastapi>=0.100  # web framework\n  uvicorn[standard] ; python_version >= \"3.8\"\nrequests==2.31.0\n")
+	writeFile(t, filepath.Join(dir, "api", ".venv", "lib", "python3.12", "site-packages", "fastapi-0.110.0.dist-info", "METADATA"), "")
+	writeFile(t, filepath.Join(dir, "api", ".venv", "lib", "python3.12", "site-packages", "uvicorn-0.29.0.dist-info", "METADATA"), "")
+
+	issues, err := Scan(dir)
+	if err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	if len(issues) != 2 {
+		t.Fatalf("expected 2 issues, got %+v", issues)
+	}
+
+	if _, err := Pin(issues); err != nil {
+		t.Fatalf("Pin failed: %v", err)
+	}
+
+	data, _ := os.ReadFile(reqPath)
+	want := "fastapi==0.110.0  # web framework\n  uvicorn[standard]==0.29.0; python_version >= \"3.8\"\nrequests==2.31.0\n"
+	if string(data) != want {
+		t.Errorf("requirements.txt = %q, want %q", data, want)
+	}
+}
+
+func TestScanAndPinDotnet(t *testing.T) {
+	dir := t.TempDir()
+	csproj := filepath.Join(dir, "api", "api.csproj")
+	writeFile(t, csproj, `<Project Sdk="Microsoft.NET.Sdk.Web">
+  <ItemGroup>
+    <PackageReference Include="Serilog" Version="3.*" />
+    <PackageReference Include="Dapper" Version="2.1.35" />
+  </ItemGroup>
+</Project>
+`)
+	writeFile(t, filepath.Join(dir, "api", "obj", "project.assets.json"), `{"libraries": {"Serilog/3.1.1": {}, "Dapper/2.1.35": {}}}`)
+
+	issues, err := Scan(dir)
+	if err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	if len(issues) != 1 || issues[0].Package != "Serilog" || issues[0].Resolved != "3.1.1" {
+		t.Fatalf("unexpected issues: %+v", issues)
+	}
+
+	if _, err := Pin(issues); err != nil {
+		t.Fatalf("Pin failed: %v", err)
+	}
+	data, _ := os.ReadFile(csproj)
+	if !strings.Contains(string(data), `Include="Serilog" Version="3.1.1"`) {
+		t.Errorf("unexpected csproj after pin:\n%s", data)
+	}
+}
+
+func TestScanWorkspaceMemberUsesRootLockfile(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "package.json"), `{"workspaces": ["apps/*"]}`)
+	writeFile(t, filepath.Join(dir, "package-lock.json"), `{}`)
+	writeFile(t, filepath.Join(dir, "apps", "web", "package.json"), `{"dependencies": {"react": "18.2.0"}}`)
+
+	issues, err := Scan(dir)
+	if err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	if len(issues) != 0 {
+		t.Errorf("expected no issues, got %+v", issues)
+	}
+}
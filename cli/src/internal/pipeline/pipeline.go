@@ -0,0 +1,262 @@
+// Package pipeline runs named pipelines defined in .azdapp.yaml: stages of commands that start
+// once the stages they depend on have finished, such as generating code, building libraries,
+// testing, and running the services.
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/jongio/azd-app/cli/src/internal/alias"
+	"github.com/jongio/azd-app/cli/src/internal/executor"
+	"github.com/jongio/azd-app/cli/src/internal/security"
+	"github.com/jongio/azd-app/cli/src/internal/workerpool"
+
+	"gopkg.in/yaml.v3"
+)
+
+// configFileName is the project config file that defines pipelines, shared with other settings.
+const configFileName = ".azdapp.yaml"
+
+// namePattern restricts the names of pipelines and stages to simple words.
+var namePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]*$`)
+
+// Pipeline is a named flow of stages.
+type Pipeline struct {
+	Name        string            `yaml:"-" json:"name"`
+	Description string            `yaml:"description,omitempty" json:"description,omitempty"`
+	Stages      map[string]*Stage `yaml:"stages" json:"stages"`
+}
+
+// Stage is a step of a pipeline: commands run in the directory of the stage once the stages it
+// depends on have succeeded.
+type Stage struct {
+	Commands  []string `yaml:"commands" json:"commands"`
+	Dir       string   `yaml:"dir,omitempty" json:"dir,omitempty"`             // Relative to the project; default: the project
+	DependsOn []string `yaml:"dependsOn,omitempty" json:"dependsOn,omitempty"` // Stages that finish first
+	Parallel  int      `yaml:"parallel,omitempty" json:"parallel,omitempty"`   // Commands run at once; default 1, one after another
+}
+
+// Load returns the pipelines defined in the .azdapp.yaml of projectDir, by name. A missing
+// file defines none. Pipelines are checked: every stage has commands and depends on stages of
+// its pipeline, without cycles.
+func Load(projectDir string) (map[string]*Pipeline, error) {
+	path := filepath.Join(projectDir, configFileName)
+	if err := security.ValidatePath(path); err != nil {
+		return nil, fmt.Errorf("invalid config path: %w", err)
+	}
+	// #nosec G304 -- Path validated by security.ValidatePath
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return map[string]*Pipeline{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	var config struct {
+		Pipelines map[string]*Pipeline `yaml:"pipelines"`
+	}
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	pipelines := make(map[string]*Pipeline, len(config.Pipelines))
+	for name, p := range config.Pipelines {
+		if p == nil {
+			p = &Pipeline{}
+		}
+		p.Name = name
+		if err := p.validate(); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		pipelines[name] = p
+	}
+	return pipelines, nil
+}
+
+// validate checks the names, commands, and dependencies of the stages of p.
+func (p *Pipeline) validate() error {
+	path := "pipelines." + p.Name
+	if !namePattern.MatchString(p.Name) {
+		return fmt.Errorf("%s: invalid pipeline name %q", path, p.Name)
+	}
+	if len(p.Stages) == 0 {
+		return fmt.Errorf("%s.stages: a pipeline needs at least one stage", path)
+	}
+	for _, name := range p.stageNames() {
+		stage := p.Stages[name]
+		stagePath := path + ".stages." + name
+		if !namePattern.MatchString(name) {
+			return fmt.Errorf("%s: invalid stage name %q", stagePath, name)
+		}
+		if stage == nil || len(stage.Commands) == 0 {
+			return fmt.Errorf("%s.commands: a stage needs at least one command", stagePath)
+		}
+		for i, command := range stage.Commands {
+			if words, err := alias.Split(command); err != nil || len(words) == 0 {
+				return fmt.Errorf("%s.commands[%d]: invalid command %q", stagePath, i, command)
+			}
+		}
+		if stage.Parallel < 0 {
+			return fmt.Errorf("%s.parallel: must be 1 or more", stagePath)
+		}
+		for _, dep := range stage.DependsOn {
+			if _, ok := p.Stages[dep]; !ok {
+				return fmt.Errorf("%s.dependsOn: unknown stage %q", stagePath, dep)
+			}
+		}
+	}
+	_, err := p.Levels()
+	return err
+}
+
+// stageNames returns the names of the stages of p in order.
+func (p *Pipeline) stageNames() []string {
+	names := make([]string, 0, len(p.Stages))
+	for name := range p.Stages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Levels groups the stages of p into levels that run one after another, each stage in a later
+// level than the stages it depends on. Levels are sorted by name. It returns an error when
+// stages depend on each other in a cycle.
+func (p *Pipeline) Levels() ([][]string, error) {
+	levels := make(map[string]int, len(p.Stages))
+	var stack []string
+	var levelOf func(name string) (int, error)
+	levelOf = func(name string) (int, error) {
+		if level, ok := levels[name]; ok {
+			return level, nil
+		}
+		if i := slices.Index(stack, name); i >= 0 {
+			return 0, fmt.Errorf("pipelines.%s: stages depend on each other: %s", p.Name, strings.Join(append(stack[i:], name), " → "))
+		}
+		stack = append(stack, name)
+		level := 0
+		for _, dep := range p.Stages[name].DependsOn {
+			depLevel, err := levelOf(dep)
+			if err != nil {
+				return 0, err
+			}
+			level = max(level, depLevel+1)
+		}
+		stack = stack[:len(stack)-1]
+		levels[name] = level
+		return level, nil
+	}
+
+	var grouped [][]string
+	for _, name := range p.stageNames() {
+		level, err := levelOf(name)
+		if err != nil {
+			return nil, err
+		}
+		for len(grouped) <= level {
+			grouped = append(grouped, nil)
+		}
+		grouped[level] = append(grouped[level], name)
+	}
+	return grouped, nil
+}
+
+// Options configures a pipeline run.
+type Options struct {
+	ProjectDir string // Directory stage directories are relative to
+	Executable string // Runs commands that start with "azd app", instead of azd; optional
+	Progress   func(stage, command string)
+	Run        func(ctx context.Context, name string, args []string, dir string) error // Optional: runs a command, for tests
+}
+
+// Run runs the stages of p, the stages of each level at once after the levels they depend on.
+// The commands of a stage run as many at once as its parallel setting allows; one after another,
+// the first to fail ends the stage. Once a stage fails, stages of later levels are skipped. The
+// error is a *workerpool.Error with the stages that failed and were skipped.
+func Run(ctx context.Context, p *Pipeline, opts Options) error {
+	levels, err := p.Levels()
+	if err != nil {
+		return err
+	}
+	run := opts.Run
+	if run == nil {
+		run = executor.RunWithContext
+	}
+
+	total := len(p.Stages)
+	var failed []*workerpool.TaskError
+	var skipped []string
+	for _, level := range levels {
+		if len(failed) > 0 || ctx.Err() != nil {
+			skipped = append(skipped, level...)
+			continue
+		}
+		tasks := make([]workerpool.Task, len(level))
+		for i, name := range level {
+			stage := p.Stages[name]
+			tasks[i] = workerpool.Task{Name: name, Run: func(ctx context.Context) error {
+				return runStage(ctx, name, stage, opts, run)
+			}}
+		}
+		err := workerpool.Run(ctx, tasks, workerpool.Options{Workers: len(tasks)})
+		failed = append(failed, workerpool.Errors(err)...)
+		var batchErr *workerpool.Error
+		if errors.As(err, &batchErr) {
+			skipped = append(skipped, batchErr.Skipped...)
+		}
+	}
+	if len(failed) == 0 && len(skipped) == 0 {
+		return nil
+	}
+	cause := errors.New("an earlier stage failed")
+	if ctx.Err() != nil {
+		cause = context.Cause(ctx)
+	}
+	return &workerpool.Error{Total: total, Succeeded: total - len(failed) - len(skipped), Failed: failed, Skipped: skipped, Cause: cause}
+}
+
+// runStage runs the commands of stage.
+func runStage(ctx context.Context, name string, stage *Stage, opts Options, run func(context.Context, string, []string, string) error) error {
+	dir := opts.ProjectDir
+	if stage.Dir != "" {
+		dir = filepath.Join(opts.ProjectDir, stage.Dir)
+	}
+	if err := security.ValidatePath(dir); err != nil {
+		return fmt.Errorf("invalid stage directory: %w", err)
+	}
+
+	tasks := make([]workerpool.Task, len(stage.Commands))
+	for i, command := range stage.Commands {
+		tasks[i] = workerpool.Task{Name: command, Run: func(ctx context.Context) error {
+			words, err := alias.Split(command)
+			if err != nil {
+				return err
+			}
+			if len(words) >= 2 && words[0] == "azd" && words[1] == "app" && opts.Executable != "" {
+				words = append([]string{opts.Executable}, words[2:]...)
+			}
+			if opts.Progress != nil {
+				opts.Progress(name, command)
+			}
+			return run(ctx, words[0], words[1:], dir)
+		}}
+	}
+	workers := max(stage.Parallel, 1)
+	err := workerpool.Run(ctx, tasks, workerpool.Options{Workers: workers, StopOnError: workers == 1})
+	if failures := workerpool.Errors(err); len(failures) > 0 {
+		msgs := make([]string, len(failures))
+		for i, failure := range failures {
+			msgs[i] = failure.Error()
+		}
+		return errors.New(strings.Join(msgs, "; "))
+	}
+	return err
+}
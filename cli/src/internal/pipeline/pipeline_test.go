@@ -0,0 +1,165 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/jongio/azd-app/cli/src/internal/workerpool"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, configFileName), []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestLoad(t *testing.T) {
+	dir := writeConfig(t, `
+aliases:
+  t: test
+pipelines:
+  dev:
+    description: Generate, build, test, and run
+    stages:
+      proto:
+        commands: [buf generate]
+      libs:
+        dependsOn: [proto]
+        parallel: 2
+        commands: [npm run build --workspace a, npm run build --workspace b]
+      lint:
+        dependsOn: [proto]
+        commands: [npm run lint]
+      test:
+        dependsOn: [libs, lint]
+        commands: [npm test]
+`)
+	pipelines, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	dev, ok := pipelines["dev"]
+	if !ok || dev.Name != "dev" || len(dev.Stages) != 4 {
+		t.Fatalf("pipelines = %+v, want dev with 4 stages", pipelines)
+	}
+	levels, err := dev.Levels()
+	if err != nil {
+		t.Fatalf("Levels() error = %v", err)
+	}
+	want := [][]string{{"proto"}, {"libs", "lint"}, {"test"}}
+	if !reflect.DeepEqual(levels, want) {
+		t.Errorf("Levels() = %v, want %v", levels, want)
+	}
+
+	if pipelines, err := Load(t.TempDir()); err != nil || len(pipelines) != 0 {
+		t.Errorf("Load() without config = %v, %v, want none", pipelines, err)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		wantErr string
+	}{
+		{name: "no stages", config: "pipelines:\n  dev: {}\n", wantErr: "pipelines.dev.stages: a pipeline needs at least one stage"},
+		{name: "no commands", config: "pipelines:\n  dev:\n    stages:\n      build: {}\n", wantErr: "pipelines.dev.stages.build.commands"},
+		{name: "invalid command", config: "pipelines:\n  dev:\n    stages:\n      build:\n        commands: ['go \"build']\n", wantErr: "pipelines.dev.stages.build.commands[0]: invalid command"},
+		{name: "unknown stage", config: "pipelines:\n  dev:\n    stages:\n      build:\n        commands: [make]\n        dependsOn: [gen]\n", wantErr: `unknown stage "gen"`},
+		{name: "negative parallel", config: "pipelines:\n  dev:\n    stages:\n      build:\n        commands: [make]\n        parallel: -1\n", wantErr: "pipelines.dev.stages.build.parallel"},
+		{name: "invalid name", config: "pipelines:\n  dev:\n    stages:\n      'my stage':\n        commands: [make]\n", wantErr: `invalid stage name "my stage"`},
+		{name: "cycle", config: "pipelines:\n  dev:\n    stages:\n      a:\n        commands: [make]\n        dependsOn: [b]\n      b:\n        commands: [make]\n        dependsOn: [a]\n", wantErr: "stages depend on each other: a → b → a"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(writeConfig(t, tt.config))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Load() error = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// recorder records the commands a pipeline runs, failing those in fail.
+type recorder struct {
+	mu   sync.Mutex
+	runs []string
+	fail map[string]bool
+}
+
+func (r *recorder) run(_ context.Context, name string, args []string, dir string) error {
+	command := strings.Join(append([]string{name}, args...), " ")
+	r.mu.Lock()
+	r.runs = append(r.runs, command+" @"+filepath.Base(dir))
+	r.mu.Unlock()
+	if r.fail[command] {
+		return errors.New("exit status 1")
+	}
+	return nil
+}
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "web"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	p := &Pipeline{Name: "dev", Stages: map[string]*Stage{
+		"gen":   {Commands: []string{"gen one", "gen two"}},
+		"build": {Commands: []string{"build web"}, Dir: "web", DependsOn: []string{"gen"}},
+		"run":   {Commands: []string{"azd app run --trust"}, DependsOn: []string{"build"}},
+	}}
+
+	rec := &recorder{}
+	var progress []string
+	err := Run(context.Background(), p, Options{
+		ProjectDir: dir,
+		Executable: "/bin/azdapp",
+		Progress:   func(stage, command string) { progress = append(progress, stage) },
+		Run:        rec.run,
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	want := []string{"gen one @" + filepath.Base(dir), "gen two @" + filepath.Base(dir), "build web @web", "/bin/azdapp run --trust @" + filepath.Base(dir)}
+	if !reflect.DeepEqual(rec.runs, want) {
+		t.Errorf("runs = %v, want %v", rec.runs, want)
+	}
+	if !reflect.DeepEqual(progress, []string{"gen", "gen", "build", "run"}) {
+		t.Errorf("progress = %v", progress)
+	}
+}
+
+func TestRunFailure(t *testing.T) {
+	p := &Pipeline{Name: "dev", Stages: map[string]*Stage{
+		"gen":  {Commands: []string{"gen one", "gen two"}},
+		"lint": {Commands: []string{"lint"}},
+		"test": {Commands: []string{"test"}, DependsOn: []string{"gen", "lint"}},
+	}}
+
+	rec := &recorder{fail: map[string]bool{"gen one": true}}
+	err := Run(context.Background(), p, Options{ProjectDir: t.TempDir(), Run: rec.run})
+	var batchErr *workerpool.Error
+	if !errors.As(err, &batchErr) {
+		t.Fatalf("Run() error = %v, want a *workerpool.Error", err)
+	}
+	if batchErr.Total != 3 || batchErr.Succeeded != 1 || len(batchErr.Failed) != 1 || batchErr.Failed[0].Name != "gen" {
+		t.Errorf("error = %+v, want gen failed and lint succeeded", batchErr)
+	}
+	if !reflect.DeepEqual(batchErr.Skipped, []string{"test"}) {
+		t.Errorf("Skipped = %v, want [test]", batchErr.Skipped)
+	}
+	for _, run := range rec.runs {
+		if strings.HasPrefix(run, "gen two") || strings.HasPrefix(run, "test") {
+			t.Errorf("ran %q after gen one failed", run)
+		}
+	}
+}
@@ -15,8 +15,16 @@ import (
 	"time"
 
 	"github.com/jongio/azd-app/cli/src/internal/executor"
+	"github.com/jongio/azd-app/cli/src/internal/statedir"
 )
 
+// dataKind names the workspace data directory that holds port assignments (see statedir.DataDir).
+const dataKind = "ports"
+
+// fileName is the file of port assignments, in that directory and, before they moved there,
+// in the .azure directory of the project.
+const fileName = "ports.json"
+
 // PortAssignment represents a port assignment for a service.
 type PortAssignment struct {
 	ServiceName string    `json:"serviceName"`
@@ -44,6 +52,8 @@ var (
 )
 
 // GetPortManager returns the port manager instance for the given project directory.
+// Assignments are kept in the workspace state, outside the project, and shared by every
+// session of the workspace on purpose, so that services keep their ports across sessions.
 func GetPortManager(projectDir string) *PortManager {
 	if projectDir == "" {
 		cwd, err := os.Getwd()
@@ -67,12 +77,8 @@ func GetPortManager(projectDir string) *PortManager {
 		return mgr
 	}
 
-	portsDir := filepath.Join(absPath, ".azure")
-	portsFile := filepath.Join(portsDir, "ports.json")
-
 	manager := &PortManager{
 		assignments: make(map[string]*PortAssignment),
-		filePath:    portsFile,
 	}
 	manager.portRange.start = 3000
 	manager.portRange.end = 65535 // Allow full dynamic port range
@@ -81,12 +87,22 @@ func GetPortManager(projectDir string) *PortManager {
 	manager.portChecker = manager.defaultIsPortAvailable
 
 	// Ensure directory exists
-	if err := os.MkdirAll(portsDir, 0750); err != nil {
+	if portsDir, err := statedir.DataDir(absPath, dataKind); err != nil {
 		fmt.Fprintf(os.Stderr, "Warning: Failed to create ports directory: %v\n", err)
+	} else {
+		manager.filePath = filepath.Join(portsDir, fileName)
 	}
 
-	// Load existing assignments
-	if err := manager.load(); err != nil && !os.IsNotExist(err) {
+	// Load existing assignments, or those kept in the project by earlier versions, so that
+	// services keep their ports
+	err = manager.load()
+	if os.IsNotExist(err) {
+		// #nosec G304 -- Fixed file name in the project directory
+		if data, legacyErr := os.ReadFile(filepath.Join(absPath, ".azure", fileName)); legacyErr == nil {
+			err = json.Unmarshal(data, &manager.assignments)
+		}
+	}
+	if err != nil && !os.IsNotExist(err) {
 		fmt.Fprintf(os.Stderr, "Warning: Failed to load port assignments: %v\n", err)
 	}
 
@@ -363,9 +379,9 @@ func (pm *PortManager) promptAndKillProcessOnPort(serviceName string, port int)
 	pid, err := pm.getProcessOnPort(port)
 	if err != nil {
 		// If we can't get PID, fall back to generic message
-		fmt.Printf("Port %d for service '%s' is in use. Stop existing process? (y/N): ", port, serviceName)
+		fmt.Fprintf(os.Stderr, "Port %d for service '%s' is in use. Stop existing process? (y/N): ", port, serviceName)
 	} else {
-		fmt.Printf("Port %d for service '%s' is in use by process %d. Stop existing process? (y/N): ", port, serviceName, pid)
+		fmt.Fprintf(os.Stderr, "Port %d for service '%s' is in use by process %d. Stop existing process? (y/N): ", port, serviceName, pid)
 	}
 
 	// Read user input
@@ -424,6 +440,10 @@ func (pm *PortManager) killProcessOnPort(port int) error {
 
 // load reads port assignments from disk.
 func (pm *PortManager) load() error {
+	if pm.filePath == "" {
+		return os.ErrNotExist
+	}
+	// #nosec G304 -- Path is in the workspace state directory
 	data, err := os.ReadFile(pm.filePath)
 	if err != nil {
 		return err
@@ -438,6 +458,10 @@ func (pm *PortManager) save() error {
 	if err != nil {
 		return fmt.Errorf("failed to marshal port assignments: %w", err)
 	}
+	if pm.filePath == "" {
+		return fmt.Errorf("no ports file: the workspace state directory could not be created")
+	}
 
-	return os.WriteFile(pm.filePath, data, 0600)
+	// Replaced atomically, since other sessions of the workspace read it
+	return statedir.WriteFile(pm.filePath, data)
 }
@@ -6,8 +6,22 @@ import (
 	"sync"
 	"testing"
 	"time"
+
+	"github.com/jongio/azd-app/cli/src/internal/statedir"
 )
 
+// TestMain keeps the port assignments written by the tests out of the user's state directory.
+func TestMain(m *testing.M) {
+	root, err := os.MkdirTemp("", "portmanager-state-")
+	if err != nil {
+		panic(err)
+	}
+	_ = os.Setenv(statedir.RootEnvVar, root)
+	code := m.Run()
+	_ = os.RemoveAll(root)
+	os.Exit(code)
+}
+
 // mockPortChecker returns a port checker that simulates port availability without network binding.
 // This avoids Windows Firewall prompts during testing.
 func mockPortChecker(unavailablePorts map[int]bool) func(int) bool {
@@ -333,11 +347,18 @@ func TestPortAssignmentFile(t *testing.T) {
 	// Assign a port
 	pm.AssignPort("test-service", 9886, false, false)
 
-	// Verify file was created
-	portsFile := filepath.Join(tempDir, ".azure", "ports.json")
+	// Verify file was created in the workspace state, not in the project
+	portsDir, err := statedir.DataDir(tempDir, dataKind)
+	if err != nil {
+		t.Fatalf("DataDir() error = %v", err)
+	}
+	portsFile := filepath.Join(portsDir, fileName)
 	if _, err := os.Stat(portsFile); os.IsNotExist(err) {
 		t.Error("Expected ports.json file to be created")
 	}
+	if _, err := os.Stat(filepath.Join(tempDir, ".azure")); !os.IsNotExist(err) {
+		t.Error("Expected no .azure directory in the project")
+	}
 
 	// Verify file permissions
 	info, err := os.Stat(portsFile)
@@ -555,13 +576,13 @@ func TestSaveAndLoad(t *testing.T) {
 
 func TestLoadCorruptedFile(t *testing.T) {
 	tempDir := t.TempDir()
-	portsDir := filepath.Join(tempDir, ".azure")
-	portsFile := filepath.Join(portsDir, "ports.json")
-
-	// Create directory and corrupt file
-	if err := os.MkdirAll(portsDir, 0750); err != nil {
+	portsDir, err := statedir.DataDir(tempDir, dataKind)
+	if err != nil {
 		t.Fatalf("Failed to create directory: %v", err)
 	}
+	portsFile := filepath.Join(portsDir, fileName)
+
+	// Create corrupt file
 
 	if err := os.WriteFile(portsFile, []byte("invalid json"), 0600); err != nil {
 		t.Fatalf("Failed to write corrupt file: %v", err)
@@ -585,6 +606,25 @@ func TestLoadCorruptedFile(t *testing.T) {
 	}
 }
 
+func TestLoadLegacyFile(t *testing.T) {
+	tempDir := t.TempDir()
+	legacyDir := filepath.Join(tempDir, ".azure")
+	if err := os.MkdirAll(legacyDir, 0750); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	legacy := `{"api": {"serviceName": "api", "port": 9903}}`
+	if err := os.WriteFile(filepath.Join(legacyDir, fileName), []byte(legacy), 0600); err != nil {
+		t.Fatalf("Failed to write legacy file: %v", err)
+	}
+
+	pm := setupTestManager(tempDir, nil)
+
+	port, exists := pm.GetAssignment("api")
+	if !exists || port != 9903 {
+		t.Errorf("Expected legacy assignment 9903, got %d (exists: %v)", port, exists)
+	}
+}
+
 func TestAssignPort_ExplicitMode(t *testing.T) {
 	tempDir := t.TempDir()
 	pm := setupTestManager(tempDir, nil)
@@ -0,0 +1,15 @@
+// Package procstat reads the resource usage of services: the memory of a service together
+// with the processes it spawned, such as the dev server behind npm run dev.
+package procstat
+
+import "errors"
+
+// ErrUnsupported is returned where memory usage can't be read on this platform.
+var ErrUnsupported = errors.New("reading memory usage is not supported on this platform")
+
+// RSS returns the resident memory, in bytes, of the processes in the process group pgid. A
+// service is started as the leader of its own group, so its PID is the group of the service
+// and the processes it spawned.
+func RSS(pgid int) (uint64, error) {
+	return groupRSS(pgid)
+}
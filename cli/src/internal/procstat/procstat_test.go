@@ -0,0 +1,33 @@
+//go:build linux || darwin
+
+package procstat
+
+import (
+	"os/exec"
+	"syscall"
+	"testing"
+)
+
+func TestRSS(t *testing.T) {
+	// A process group of its own, like a service
+	cmd := exec.Command("sleep", "5")
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	if err := cmd.Start(); err != nil {
+		t.Skipf("can't start sleep: %v", err)
+	}
+	defer func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	}()
+
+	rss, err := RSS(cmd.Process.Pid)
+	if err != nil || rss == 0 {
+		t.Errorf("RSS() = %d, %v, want the memory of sleep", rss, err)
+	}
+
+	_ = cmd.Process.Kill()
+	_ = cmd.Wait()
+	if _, err := RSS(cmd.Process.Pid); err == nil {
+		t.Error("RSS() of an exited group succeeded")
+	}
+}
@@ -0,0 +1,38 @@
+package procstat
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// groupRSS sums the resident memory of the processes of group pgid, as ps reports it in
+// kilobytes.
+func groupRSS(pgid int) (uint64, error) {
+	out, err := exec.Command("ps", "-A", "-o", "pgid=,rss=").Output()
+	if err != nil {
+		return 0, fmt.Errorf("failed to run ps: %w", err)
+	}
+	var total uint64
+	found := false
+	for _, line := range strings.Split(string(out), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		if group, err := strconv.Atoi(fields[0]); err != nil || group != pgid {
+			continue
+		}
+		kb, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		found = true
+		total += kb * 1024
+	}
+	if !found {
+		return 0, fmt.Errorf("no process in group %d", pgid)
+	}
+	return total, nil
+}
@@ -0,0 +1,60 @@
+package procstat
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// groupRSS sums the resident memory of the processes of group pgid from /proc.
+func groupRSS(pgid int) (uint64, error) {
+	paths, err := filepath.Glob("/proc/[0-9]*/stat")
+	if err != nil {
+		return 0, err
+	}
+	var total uint64
+	found := false
+	for _, path := range paths {
+		// #nosec G304 -- Paths of /proc entries
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue // The process exited
+		}
+		group, pages, err := parseStat(string(data))
+		if err != nil || group != pgid {
+			continue
+		}
+		found = true
+		total += pages * uint64(os.Getpagesize())
+	}
+	if !found {
+		return 0, fmt.Errorf("no process in group %d", pgid)
+	}
+	return total, nil
+}
+
+// parseStat returns the process group and the resident pages from the contents of a
+// /proc/<pid>/stat file. The command name, in parentheses, may contain spaces and
+// parentheses, so fields are counted after its last ")".
+func parseStat(stat string) (int, uint64, error) {
+	end := strings.LastIndexByte(stat, ')')
+	if end < 0 {
+		return 0, 0, fmt.Errorf("malformed stat %q", stat)
+	}
+	// Fields from the third on: state, ppid, pgrp, ..., rss is the 24th
+	fields := strings.Fields(stat[end+1:])
+	if len(fields) < 22 {
+		return 0, 0, fmt.Errorf("malformed stat %q", stat)
+	}
+	group, err := strconv.Atoi(fields[2])
+	if err != nil {
+		return 0, 0, fmt.Errorf("malformed process group %q", fields[2])
+	}
+	pages, err := strconv.ParseUint(fields[21], 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("malformed rss %q", fields[21])
+	}
+	return group, pages, nil
+}
@@ -0,0 +1,14 @@
+package procstat
+
+import "testing"
+
+func TestParseStat(t *testing.T) {
+	stat := "4242 (node (dev) server) S 4200 4242 4242 0 -1 4194560 100 0 0 0 10 5 0 0 20 0 11 0 1000 1234567 2500 18446744073709551615"
+	group, pages, err := parseStat(stat)
+	if err != nil || group != 4242 || pages != 2500 {
+		t.Errorf("parseStat() = %d, %d, %v, want 4242, 2500", group, pages, err)
+	}
+	if _, _, err := parseStat("4242 (node) S 1"); err == nil {
+		t.Error("parseStat() of a short stat succeeded")
+	}
+}
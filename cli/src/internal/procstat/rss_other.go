@@ -0,0 +1,8 @@
+//go:build !linux && !darwin
+
+package procstat
+
+// groupRSS is not supported on this platform.
+func groupRSS(int) (uint64, error) {
+	return 0, ErrUnsupported
+}
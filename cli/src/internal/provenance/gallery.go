@@ -0,0 +1,147 @@
+package provenance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/jongio/azd-app/cli/src/internal/alias"
+	"github.com/jongio/azd-app/cli/src/internal/httpclient"
+	"github.com/jongio/azd-app/cli/src/internal/security"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Gallery is a template gallery index: the template sources it lists and the commits of each
+// that it vouches for. Commit hashes are checksums of the whole tree and history, so a checkout
+// at a listed commit has exactly the content the gallery reviewed.
+type Gallery struct {
+	Templates []GalleryTemplate `json:"templates"`
+}
+
+// GalleryTemplate is a template listed in a gallery index.
+type GalleryTemplate struct {
+	Source  string   `json:"source"`  // Repository URL, e.g. https://github.com/Azure-Samples/todo-nodejs-mongo
+	Commits []string `json:"commits"` // Full hashes of the commits the gallery lists
+}
+
+// galleryConfig is the part of the user config file that sets the gallery index.
+type galleryConfig struct {
+	Gallery struct {
+		Index string `yaml:"index"` // URL or file path of the index
+	} `yaml:"gallery"`
+}
+
+// readGallery returns the gallery index set in the user config file, or nil when none is set.
+// Variable for testing.
+var readGallery = func(ctx context.Context) (*Gallery, error) {
+	location, err := galleryIndex()
+	if err != nil || location == "" {
+		return nil, err
+	}
+	return loadGallery(ctx, location)
+}
+
+// galleryIndex returns the gallery.index setting of the user config file.
+func galleryIndex() (string, error) {
+	path, err := alias.UserConfigPath()
+	if err != nil {
+		return "", err
+	}
+	if err := security.ValidatePath(path); err != nil {
+		return "", fmt.Errorf("invalid config path: %w", err)
+	}
+	// #nosec G304 -- Path validated by security.ValidatePath
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	var cfg galleryConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return "", fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return strings.TrimSpace(cfg.Gallery.Index), nil
+}
+
+// loadGallery reads a gallery index from an https URL or a file. Other URLs are refused, since
+// an index fetched over plain http could be swapped on the way to vouch for any commit.
+func loadGallery(ctx context.Context, location string) (*Gallery, error) {
+	var data []byte
+	switch {
+	case strings.HasPrefix(location, "https://"):
+		resp, err := httpclient.Default().Get(ctx, location)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("%s returned %d", location, resp.StatusCode)
+		}
+		data = resp.Body
+	case strings.Contains(location, "://"):
+		return nil, fmt.Errorf("gallery index %s must be an https URL or a file path", location)
+	default:
+		if err := security.ValidatePath(location); err != nil {
+			return nil, fmt.Errorf("invalid gallery index path: %w", err)
+		}
+		// #nosec G304 -- Path validated by security.ValidatePath
+		content, err := os.ReadFile(location)
+		if err != nil {
+			return nil, err
+		}
+		data = content
+	}
+
+	var gallery Gallery
+	if err := json.Unmarshal(data, &gallery); err != nil {
+		return nil, fmt.Errorf("failed to parse gallery index %s: %w", location, err)
+	}
+	return &gallery, nil
+}
+
+// Find returns the template whose source is the repository at remote, or nil.
+func (g *Gallery) Find(remote string) *GalleryTemplate {
+	want := normalizeSource(remote)
+	if want == "" {
+		return nil
+	}
+	for i := range g.Templates {
+		if normalizeSource(g.Templates[i].Source) == want {
+			return &g.Templates[i]
+		}
+	}
+	return nil
+}
+
+// Lists reports whether the gallery lists commit for the template.
+func (t *GalleryTemplate) Lists(commit string) bool {
+	for _, listed := range t.Commits {
+		if commit != "" && strings.EqualFold(listed, commit) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeSource reduces the forms of a repository URL (https, ssh, scp-like, with or without
+// .git) to host/path, so a clone's remote can be compared with a gallery source.
+func normalizeSource(source string) string {
+	source = strings.ToLower(strings.TrimSpace(source))
+	for _, scheme := range []string{"https://", "http://", "ssh://", "git://"} {
+		source = strings.TrimPrefix(source, scheme)
+	}
+	if at := strings.Index(source, "@"); at >= 0 && at < strings.IndexAny(source+"/", ":/") {
+		source = source[at+1:]
+	}
+	// scp-like git@host:owner/repo
+	if colon := strings.Index(source, ":"); colon >= 0 && !strings.Contains(source[:colon], "/") {
+		source = source[:colon] + "/" + strings.TrimLeft(source[colon+1:], "/")
+	}
+	source = strings.TrimSuffix(strings.TrimSuffix(source, "/"), ".git")
+	return strings.TrimSuffix(source, "/")
+}
@@ -0,0 +1,176 @@
+// Package provenance establishes where the code of a workspace came from, by checking the
+// signatures of the git commit and tags it is checked out at, and the commits a template
+// gallery lists for the repository it was cloned from.
+//
+// Signatures are checked with git, so they are only trusted if the signer's key is in the
+// user's GnuPG keyring or gpg.ssh.allowedSignersFile. A workspace is verified when its commit,
+// or a tag pointing at it, has a good signature or is listed in the gallery index, and the
+// working tree has no local changes or untracked files.
+package provenance
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Result describes the provenance of a workspace.
+type Result struct {
+	Verified   bool     `json:"verified"`
+	Repository bool     `json:"repository"`          // The workspace is a git checkout
+	Commit     string   `json:"commit,omitempty"`    // Checked-out commit
+	Signer     string   `json:"signer,omitempty"`    // Signer of the commit or tag with a good signature
+	SignedTag  string   `json:"signedTag,omitempty"` // Tag at the commit with a good signature
+	Remote     string   `json:"remote,omitempty"`    // URL of the origin remote
+	Gallery    string   `json:"gallery,omitempty"`   // Gallery source that lists the commit
+	Modified   bool     `json:"modified,omitempty"`  // The working tree has uncommitted changes
+	Problems   []string `json:"problems,omitempty"`  // Why provenance could not be established
+}
+
+// runGit runs git in dir and returns its standard output. Variable for testing.
+var runGit = func(ctx context.Context, dir string, args ...string) (string, error) {
+	// #nosec G204 -- git with fixed subcommands; the directory comes from the local filesystem
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", dir}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return string(out), fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return string(out), nil
+}
+
+// verifyTag checks the signature of tag and returns what git reports about it, which goes to
+// standard error. With --raw, GnuPG reports its status lines. Variable for testing.
+var verifyTag = func(ctx context.Context, dir, tag string) (string, error) {
+	// #nosec G204 -- git with a fixed subcommand; the tag name comes from git itself
+	cmd := exec.CommandContext(ctx, "git", "-C", dir, "verify-tag", "--raw", tag)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	return stderr.String(), err
+}
+
+// signatureStates explains the signature codes of git log --format=%G?.
+var signatureStates = map[string]string{
+	"B": "has a bad signature",
+	"U": "is signed with a key of unknown validity",
+	"X": "has a good signature that has expired",
+	"Y": "is signed with an expired key",
+	"R": "is signed with a revoked key",
+	"E": "is signed, but the signature cannot be checked (missing key?)",
+	"N": "is not signed",
+}
+
+// Check establishes the provenance of the workspace in dir.
+func Check(ctx context.Context, dir string) Result {
+	var result Result
+	if _, err := runGit(ctx, dir, "rev-parse", "--is-inside-work-tree"); err != nil {
+		result.Problems = append(result.Problems, "the workspace is not a git checkout, so its source cannot be verified")
+		return result
+	}
+	result.Repository = true
+
+	out, err := runGit(ctx, dir, "log", "-1", "--format=%H%x00%G?%x00%GS", "HEAD")
+	if err != nil {
+		result.Problems = append(result.Problems, "the workspace has no commits")
+		return result
+	}
+	fields := strings.Split(strings.TrimSpace(out), "\x00")
+	for len(fields) < 3 {
+		fields = append(fields, "")
+	}
+	result.Commit = fields[0]
+	commitSigned := fields[1] == "G"
+	if commitSigned {
+		result.Signer = fields[2]
+	}
+
+	if tags, err := runGit(ctx, dir, "tag", "--points-at", "HEAD"); err == nil {
+		for _, tag := range strings.Fields(tags) {
+			// verify-tag fails for lightweight and unsigned tags as well as bad signatures
+			if report, err := verifyTag(ctx, dir, tag); err == nil {
+				result.SignedTag = tag
+				if result.Signer == "" {
+					result.Signer = tagSigner(report)
+				}
+				break
+			}
+		}
+	}
+
+	if remote, err := runGit(ctx, dir, "remote", "get-url", "origin"); err == nil {
+		result.Remote = strings.TrimSpace(remote)
+	}
+	checkGallery(ctx, &result)
+
+	if !commitSigned && result.SignedTag == "" && result.Gallery == "" {
+		state := signatureStates[fields[1]]
+		if state == "" {
+			state = "has a signature git cannot check"
+		}
+		result.Problems = append(result.Problems, fmt.Sprintf("commit %s %s and no signed tag points at it", short(result.Commit), state))
+	}
+
+	// Untracked files count too: they can hold commands just as well. Ignored files don't.
+	if status, err := runGit(ctx, dir, "status", "--porcelain"); err == nil && strings.TrimSpace(status) != "" {
+		result.Modified = true
+		result.Problems = append(result.Problems, "the working tree has changes that are not covered by a signature")
+	}
+
+	result.Verified = len(result.Problems) == 0
+	return result
+}
+
+// checkGallery looks up the origin remote in the gallery index of the user config. A commit
+// the gallery lists for the repository verifies the source; any other commit of a listed
+// repository is a problem, since it is not what the gallery reviewed.
+func checkGallery(ctx context.Context, result *Result) {
+	gallery, err := readGallery(ctx)
+	if err != nil {
+		result.Problems = append(result.Problems, fmt.Sprintf("the gallery index could not be read: %v", err))
+		return
+	}
+	if gallery == nil {
+		return
+	}
+	template := gallery.Find(result.Remote)
+	if template == nil {
+		return
+	}
+	if template.Lists(result.Commit) {
+		result.Gallery = template.Source
+		return
+	}
+	result.Problems = append(result.Problems, fmt.Sprintf("commit %s is not a version of %s listed in the gallery index", short(result.Commit), template.Source))
+}
+
+// tagSigner returns the signer in the report of a good tag signature: the GOODSIG status line
+// of GnuPG, or the principal of an SSH signature. The tagger name is not used, since anyone
+// can set it.
+func tagSigner(report string) string {
+	for _, line := range strings.Split(report, "\n") {
+		line = strings.TrimSpace(line)
+		if rest, ok := strings.CutPrefix(line, "[GNUPG:] GOODSIG "); ok {
+			// The key ID comes first
+			if _, signer, ok := strings.Cut(rest, " "); ok {
+				return strings.TrimSpace(signer)
+			}
+		}
+		if rest, ok := strings.CutPrefix(line, `Good "git" signature for `); ok {
+			signer, _, _ := strings.Cut(rest, " with ")
+			return strings.TrimSpace(signer)
+		}
+	}
+	return ""
+}
+
+// short abbreviates a commit hash for display.
+func short(commit string) string {
+	if len(commit) > 12 {
+		return commit[:12]
+	}
+	return commit
+}
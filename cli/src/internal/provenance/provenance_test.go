@@ -0,0 +1,194 @@
+package provenance
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jongio/azd-app/cli/src/internal/alias"
+)
+
+func TestCheck(t *testing.T) {
+	const commit = "0123456789abcdef0123456789abcdef01234567"
+	tests := []struct {
+		name         string
+		outputs      map[string]string // Output of each git subcommand; missing ones fail
+		gallery      *Gallery
+		wantVerified bool
+		wantSigner   string
+		wantTag      string
+		wantProblem  string
+	}{
+		{
+			name:         "signed commit",
+			outputs:      map[string]string{"rev-parse": "true", "log": commit + "\x00G\x00Jane <jane@example.com>", "tag": "", "status": ""},
+			wantVerified: true,
+			wantSigner:   "Jane <jane@example.com>",
+		},
+		{
+			name:         "signed tag on unsigned commit",
+			outputs:      map[string]string{"rev-parse": "true", "log": commit + "\x00N\x00", "tag": "v1.0.0", "verify-tag": "[GNUPG:] NEWSIG\n[GNUPG:] GOODSIG 1234ABCD Release Bot <bot@example.com>\n", "status": ""},
+			wantVerified: true,
+			wantTag:      "v1.0.0",
+			wantSigner:   "Release Bot <bot@example.com>",
+		},
+		{
+			name:         "ssh signed tag",
+			outputs:      map[string]string{"rev-parse": "true", "log": commit + "\x00N\x00", "tag": "v1.0.0", "verify-tag": `Good "git" signature for bot@example.com with ED25519 key SHA256:abc` + "\n", "status": ""},
+			wantVerified: true,
+			wantTag:      "v1.0.0",
+			wantSigner:   "bot@example.com",
+		},
+		{
+			name:         "commit listed in gallery",
+			outputs:      map[string]string{"rev-parse": "true", "log": commit + "\x00N\x00", "tag": "", "remote": "git@github.com:Azure-Samples/todo.git\n", "status": ""},
+			gallery:      &Gallery{Templates: []GalleryTemplate{{Source: "https://github.com/azure-samples/todo", Commits: []string{commit}}}},
+			wantVerified: true,
+		},
+		{
+			name:        "commit not listed in gallery",
+			outputs:     map[string]string{"rev-parse": "true", "log": commit + "\x00G\x00Jane", "tag": "", "remote": "https://github.com/Azure-Samples/todo", "status": ""},
+			gallery:     &Gallery{Templates: []GalleryTemplate{{Source: "https://github.com/Azure-Samples/todo", Commits: []string{"fedcba9876543210fedcba9876543210fedcba98"}}}},
+			wantSigner:  "Jane",
+			wantProblem: "not a version of https://github.com/Azure-Samples/todo listed in the gallery index",
+		},
+		{
+			name:        "untracked files",
+			outputs:     map[string]string{"rev-parse": "true", "log": commit + "\x00G\x00Jane", "tag": "", "status": "?? setup.sh\n"},
+			wantSigner:  "Jane",
+			wantProblem: "changes that are not covered",
+		},
+		{
+			name:        "unsigned commit",
+			outputs:     map[string]string{"rev-parse": "true", "log": commit + "\x00N\x00", "tag": "", "status": ""},
+			wantProblem: "commit 0123456789ab is not signed",
+		},
+		{
+			name:        "missing key",
+			outputs:     map[string]string{"rev-parse": "true", "log": commit + "\x00E\x00", "tag": "", "status": ""},
+			wantProblem: "cannot be checked",
+		},
+		{
+			name:        "local changes",
+			outputs:     map[string]string{"rev-parse": "true", "log": commit + "\x00G\x00Jane", "tag": "", "status": " M main.py\n"},
+			wantSigner:  "Jane",
+			wantProblem: "changes that are not covered",
+		},
+		{
+			name:        "not a repository",
+			outputs:     map[string]string{},
+			wantProblem: "not a git checkout",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			originalGit, originalVerify, originalGallery := runGit, verifyTag, readGallery
+			defer func() { runGit, verifyTag, readGallery = originalGit, originalVerify, originalGallery }()
+			runGit = func(_ context.Context, _ string, args ...string) (string, error) {
+				if args[0] == "status" && len(args) > 2 {
+					t.Errorf("status must not leave out untracked files: %v", args)
+				}
+				out, ok := tt.outputs[args[0]]
+				if !ok {
+					return "", errors.New("failed")
+				}
+				return out, nil
+			}
+			verifyTag = func(_ context.Context, _, _ string) (string, error) {
+				out, ok := tt.outputs["verify-tag"]
+				if !ok {
+					return "error: no signature found", errors.New("failed")
+				}
+				return out, nil
+			}
+			readGallery = func(context.Context) (*Gallery, error) {
+				return tt.gallery, nil
+			}
+
+			result := Check(context.Background(), t.TempDir())
+			if result.Verified != tt.wantVerified {
+				t.Errorf("Verified = %v, want %v (problems: %v)", result.Verified, tt.wantVerified, result.Problems)
+			}
+			if result.Signer != tt.wantSigner || result.SignedTag != tt.wantTag {
+				t.Errorf("Signer, SignedTag = %q, %q, want %q, %q", result.Signer, result.SignedTag, tt.wantSigner, tt.wantTag)
+			}
+			if tt.wantProblem != "" && !strings.Contains(strings.Join(result.Problems, "\n"), tt.wantProblem) {
+				t.Errorf("Problems = %v, want one containing %q", result.Problems, tt.wantProblem)
+			}
+		})
+	}
+}
+
+func TestNormalizeSource(t *testing.T) {
+	for _, source := range []string{
+		"https://github.com/Azure-Samples/todo",
+		"https://github.com/Azure-Samples/todo.git",
+		"https://github.com/azure-samples/todo/",
+		"git@github.com:Azure-Samples/todo.git",
+		"ssh://git@github.com/Azure-Samples/todo",
+	} {
+		if got := normalizeSource(source); got != "github.com/azure-samples/todo" {
+			t.Errorf("normalizeSource(%q) = %q", source, got)
+		}
+	}
+}
+
+func TestLoadGalleryFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gallery.json")
+	index := `{"templates": [{"source": "https://github.com/Azure-Samples/todo", "commits": ["ABC123"]}]}`
+	if err := os.WriteFile(path, []byte(index), 0600); err != nil {
+		t.Fatal(err)
+	}
+	config := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(config, []byte("gallery:\n  index: "+path+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(alias.ConfigEnvVar, config)
+
+	gallery, err := readGallery(context.Background())
+	if err != nil {
+		t.Fatalf("readGallery() error = %v", err)
+	}
+	template := gallery.Find("git@github.com:azure-samples/todo.git")
+	if template == nil || !template.Lists("abc123") || template.Lists("def456") {
+		t.Errorf("Find() = %+v, want the listed template with commit abc123", template)
+	}
+}
+
+func TestLoadGalleryInsecureURL(t *testing.T) {
+	for _, location := range []string{"http://gallery.contoso.com/templates.json", "ftp://gallery.contoso.com/templates.json"} {
+		if _, err := loadGallery(context.Background(), location); err == nil || !strings.Contains(err.Error(), "https") {
+			t.Errorf("loadGallery(%q) error = %v, want it refused", location, err)
+		}
+	}
+}
+
+func TestCheckUnsignedRepository(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	t.Setenv(alias.ConfigEnvVar, filepath.Join(t.TempDir(), "config.yaml"))
+	dir := t.TempDir()
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=test", "-c", "user.email=test@example.com", "-c", "commit.gpgsign=false"}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+	}
+	git("init", "--quiet")
+	if err := os.WriteFile(filepath.Join(dir, "azure.yaml"), []byte("name: test\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	git("add", "azure.yaml")
+	git("commit", "--quiet", "-m", "initial")
+
+	result := Check(context.Background(), dir)
+	if !result.Repository || result.Verified || len(result.Commit) != 40 {
+		t.Errorf("unexpected result for unsigned commit: %+v", result)
+	}
+}
@@ -9,8 +9,16 @@ import (
 	"sync"
 	"syscall"
 	"time"
+
+	"github.com/jongio/azd-app/cli/src/internal/statedir"
 )
 
+// dataKind names the workspace data directory that holds the registry (see statedir.DataDir).
+const dataKind = "registry"
+
+// fileName is the registry file in that directory.
+const fileName = "services.json"
+
 // ServiceRegistryEntry represents a running service in the registry.
 type ServiceRegistryEntry struct {
 	Name        string    `json:"name"`
@@ -22,10 +30,13 @@ type ServiceRegistryEntry struct {
 	Language    string    `json:"language"`
 	Framework   string    `json:"framework"`
 	Status      string    `json:"status"` // "starting", "ready", "stopping", "stopped", "error"
-	Health      string    `json:"health"` // "healthy", "unhealthy", "unknown"
+	Health      string    `json:"health"` // "healthy", "unhealthy", "unknown", or a state of service.HealthManager
 	StartTime   time.Time `json:"startTime"`
+	ReadyTime   time.Time `json:"readyTime,omitempty"` // When it last went from starting to running
 	LastChecked time.Time `json:"lastChecked"`
 	Error       string    `json:"error,omitempty"`
+	HealthURL   string    `json:"healthUrl,omitempty"`
+	Virtual     bool      `json:"virtual,omitempty"` // Started outside azd app; see service.RegisterVirtualService
 }
 
 // ServiceRegistry manages the registry of running services for a project.
@@ -42,6 +53,10 @@ var (
 
 // GetRegistry returns the service registry instance for the given project directory.
 // If projectDir is empty, uses current working directory.
+//
+// The registry is kept in the workspace state, outside the project. It is shared by every
+// session of the workspace on purpose, so that commands such as info and logs see the services
+// that another session started.
 func GetRegistry(projectDir string) *ServiceRegistry {
 	if projectDir == "" {
 		cwd, err := os.Getwd()
@@ -65,17 +80,15 @@ func GetRegistry(projectDir string) *ServiceRegistry {
 		return reg
 	}
 
-	registryDir := filepath.Join(absPath, ".azure")
-	registryFile := filepath.Join(registryDir, "services.json")
-
 	registry := &ServiceRegistry{
 		services: make(map[string]*ServiceRegistryEntry),
-		filePath: registryFile,
 	}
 
 	// Ensure directory exists
-	if err := os.MkdirAll(registryDir, 0750); err != nil {
+	if registryDir, err := statedir.DataDir(absPath, dataKind); err != nil {
 		fmt.Fprintf(os.Stderr, "Warning: Failed to create registry directory: %v\n", err)
+	} else {
+		registry.filePath = filepath.Join(registryDir, fileName)
 	}
 
 	// Load existing registry
@@ -124,9 +137,13 @@ func (r *ServiceRegistry) UpdateStatus(serviceName, status, health string) error
 	defer r.mu.Unlock()
 
 	if svc, exists := r.services[serviceName]; exists {
+		now := time.Now()
+		if svc.Status == "starting" && status == "running" {
+			svc.ReadyTime = now
+		}
 		svc.Status = status
 		svc.Health = health
-		svc.LastChecked = time.Now()
+		svc.LastChecked = now
 		return r.save()
 	}
 	return fmt.Errorf("service not found: %s", serviceName)
@@ -153,14 +170,28 @@ func (r *ServiceRegistry) ListAll() []*ServiceRegistryEntry {
 	return result
 }
 
-// save persists the registry to disk.
+// Path returns the registry file of projectDir without creating anything, for reading the
+// registry that another process writes.
+func Path(projectDir string) (string, error) {
+	dir, err := statedir.DataPath(projectDir, dataKind)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, fileName), nil
+}
+
+// save persists the registry to disk. The file is replaced atomically, since other sessions
+// of the workspace read it.
 func (r *ServiceRegistry) save() error {
+	if r.filePath == "" {
+		return fmt.Errorf("no registry file: the workspace state directory could not be created")
+	}
 	data, err := json.MarshalIndent(r.services, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal registry: %w", err)
 	}
 
-	if err := os.WriteFile(r.filePath, data, 0600); err != nil {
+	if err := statedir.WriteFile(r.filePath, data); err != nil {
 		return fmt.Errorf("failed to write registry file: %w", err)
 	}
 
@@ -169,6 +200,10 @@ func (r *ServiceRegistry) save() error {
 
 // load reads the registry from disk.
 func (r *ServiceRegistry) load() error {
+	if r.filePath == "" {
+		return os.ErrNotExist
+	}
+	// #nosec G304 -- Path is in the workspace state directory
 	data, err := os.ReadFile(r.filePath)
 	if err != nil {
 		return err
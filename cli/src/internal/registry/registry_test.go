@@ -6,8 +6,22 @@ import (
 	"path/filepath"
 	"testing"
 	"time"
+
+	"github.com/jongio/azd-app/cli/src/internal/statedir"
 )
 
+// TestMain keeps the registries written by the tests out of the user's state directory.
+func TestMain(m *testing.M) {
+	root, err := os.MkdirTemp("", "registry-state-")
+	if err != nil {
+		panic(err)
+	}
+	_ = os.Setenv(statedir.RootEnvVar, root)
+	code := m.Run()
+	_ = os.RemoveAll(root)
+	os.Exit(code)
+}
+
 func TestGetRegistry(t *testing.T) {
 	tempDir := t.TempDir()
 
@@ -17,10 +31,16 @@ func TestGetRegistry(t *testing.T) {
 		t.Fatal("GetRegistry() returned nil")
 	}
 
-	// Verify .azure directory was created
-	azureDir := filepath.Join(tempDir, ".azure")
-	if _, err := os.Stat(azureDir); os.IsNotExist(err) {
-		t.Errorf(".azure directory was not created")
+	// Verify the registry directory was created in the workspace state, not in the project
+	registryFile, err := Path(tempDir)
+	if err != nil {
+		t.Fatalf("Path() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Dir(registryFile)); os.IsNotExist(err) {
+		t.Errorf("registry directory was not created")
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, ".azure")); !os.IsNotExist(err) {
+		t.Errorf("GetRegistry() created .azure in the project")
 	}
 
 	// Get the same registry again - should return cached instance
@@ -147,6 +167,31 @@ func TestUpdateStatus(t *testing.T) {
 	if svc.LastChecked.IsZero() {
 		t.Errorf("UpdateStatus() did not update LastChecked")
 	}
+	if !svc.ReadyTime.IsZero() {
+		t.Errorf("UpdateStatus() set ReadyTime for status %q", svc.Status)
+	}
+}
+
+func TestUpdateStatusRecordsReadyTime(t *testing.T) {
+	registry := GetRegistry(t.TempDir())
+	registry.Register(&ServiceRegistryEntry{Name: "api", Status: "starting", StartTime: time.Now()})
+
+	if err := registry.UpdateStatus("api", "running", "healthy"); err != nil {
+		t.Fatalf("UpdateStatus() error = %v", err)
+	}
+	svc, _ := registry.GetService("api")
+	ready := svc.ReadyTime
+	if ready.IsZero() || ready.Before(svc.StartTime) {
+		t.Fatalf("ReadyTime = %v, want the time the service became running", ready)
+	}
+
+	// Later updates of a running service keep the time it became ready
+	if err := registry.UpdateStatus("api", "running", "unhealthy"); err != nil {
+		t.Fatalf("UpdateStatus() error = %v", err)
+	}
+	if svc, _ := registry.GetService("api"); !svc.ReadyTime.Equal(ready) {
+		t.Errorf("ReadyTime = %v after a health update, want %v", svc.ReadyTime, ready)
+	}
 }
 
 func TestUpdateStatusNonexistent(t *testing.T) {
@@ -294,7 +339,10 @@ func TestClear(t *testing.T) {
 
 func TestRegistryPersistence(t *testing.T) {
 	tempDir := t.TempDir()
-	registryFile := filepath.Join(tempDir, ".azure", "services.json")
+	registryFile, err := Path(tempDir)
+	if err != nil {
+		t.Fatalf("Path() error = %v", err)
+	}
 
 	registry := GetRegistry(tempDir)
 
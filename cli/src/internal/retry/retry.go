@@ -0,0 +1,87 @@
+// Package retry runs operations that may fail transiently, such as health probes, installs,
+// and network requests, again after a delay that grows with each attempt.
+package retry
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"time"
+)
+
+// Policy says how often and how quickly an operation is retried.
+type Policy struct {
+	MaxAttempts int           // Attempts in total, including the first; 1 disables retries
+	BaseDelay   time.Duration // Delay before the first retry
+	Backoff     float64       // Factor the delay grows by for each further retry; 1 keeps it constant
+	MaxDelay    time.Duration // Longest delay before a retry; 0 does not limit it
+	Jitter      float64       // Fraction of each delay that is random, from 0 to 1, so clients retrying together spread out
+}
+
+// Default policies of azd app, used unless azure.yaml configures others.
+var (
+	// DefaultHealthCheck probes a starting service every 2 seconds for up to a minute.
+	DefaultHealthCheck = Policy{MaxAttempts: 30, BaseDelay: 2 * time.Second, Backoff: 1}
+	// DefaultInstall runs dependency installs once; package managers retry downloads themselves.
+	DefaultInstall = Policy{MaxAttempts: 1, BaseDelay: 5 * time.Second, Backoff: 2, MaxDelay: time.Minute, Jitter: 0.2}
+	// DefaultRestart restarts a service that exited up to 4 times, waiting about 1, 2, 4, and 8 seconds.
+	DefaultRestart = Policy{MaxAttempts: 5, BaseDelay: time.Second, Backoff: 2, MaxDelay: 30 * time.Second, Jitter: 0.2}
+	// DefaultNetwork retries requests to remote services 3 times with exponential backoff.
+	DefaultNetwork = Policy{MaxAttempts: 4, BaseDelay: 500 * time.Millisecond, Backoff: 2, MaxDelay: 30 * time.Second, Jitter: 0.5}
+)
+
+// Validate reports settings that are out of range.
+func (p Policy) Validate() error {
+	switch {
+	case p.MaxAttempts < 1:
+		return fmt.Errorf("maxAttempts must be at least 1, got %d", p.MaxAttempts)
+	case p.BaseDelay < 0:
+		return fmt.Errorf("delay must not be negative, got %s", p.BaseDelay)
+	case p.Backoff < 1:
+		return fmt.Errorf("backoff must be at least 1, got %g", p.Backoff)
+	case p.MaxDelay < 0:
+		return fmt.Errorf("maxDelay must not be negative, got %s", p.MaxDelay)
+	case p.Jitter < 0 || p.Jitter > 1:
+		return fmt.Errorf("jitter must be between 0 and 1, got %g", p.Jitter)
+	}
+	return nil
+}
+
+// Delay returns the delay before retry n, counted from 1: the base delay grown by the backoff
+// factor for each earlier retry, capped at the maximum, with the jitter fraction randomized.
+func (p Policy) Delay(n int) time.Duration {
+	delay := float64(p.BaseDelay)
+	for i := 1; i < n; i++ {
+		delay *= p.Backoff
+		if p.MaxDelay > 0 && delay >= float64(p.MaxDelay) {
+			break
+		}
+	}
+	if p.MaxDelay > 0 && delay > float64(p.MaxDelay) {
+		delay = float64(p.MaxDelay)
+	}
+	if jitter := delay * p.Jitter; jitter >= 1 {
+		delay = delay - jitter + rand.Float64()*jitter // #nosec G404 -- jitter does not need a secure source
+	}
+	return time.Duration(delay)
+}
+
+// Do runs fn until it succeeds, it has run policy.MaxAttempts times, or ctx is done, and
+// returns the error of the last attempt. When ctx ends while waiting for a retry, the error
+// says why as well. attempt counts from 1.
+func Do(ctx context.Context, policy Policy, fn func(ctx context.Context, attempt int) error) error {
+	for attempt := 1; ; attempt++ {
+		err := fn(ctx, attempt)
+		if err == nil || attempt >= policy.MaxAttempts || ctx.Err() != nil {
+			return err
+		}
+
+		timer := time.NewTimer(policy.Delay(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("%w after %d attempts: %w", context.Cause(ctx), attempt, err)
+		case <-timer.C:
+		}
+	}
+}
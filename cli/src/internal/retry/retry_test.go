@@ -0,0 +1,122 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPolicyDelay(t *testing.T) {
+	policy := Policy{MaxAttempts: 10, BaseDelay: 100 * time.Millisecond, Backoff: 2, MaxDelay: time.Second}
+	tests := []struct {
+		retry int
+		want  time.Duration
+	}{
+		{retry: 1, want: 100 * time.Millisecond},
+		{retry: 2, want: 200 * time.Millisecond},
+		{retry: 4, want: 800 * time.Millisecond},
+		{retry: 5, want: time.Second},
+		{retry: 100, want: time.Second},
+	}
+	for _, tt := range tests {
+		if got := policy.Delay(tt.retry); got != tt.want {
+			t.Errorf("Delay(%d) = %s, want %s", tt.retry, got, tt.want)
+		}
+	}
+
+	constant := Policy{MaxAttempts: 3, BaseDelay: 2 * time.Second, Backoff: 1}
+	if got := constant.Delay(7); got != 2*time.Second {
+		t.Errorf("Delay(7) with backoff 1 = %s, want 2s", got)
+	}
+
+	jittered := Policy{MaxAttempts: 3, BaseDelay: time.Second, Backoff: 1, Jitter: 0.5}
+	for i := 0; i < 20; i++ {
+		if got := jittered.Delay(1); got < 500*time.Millisecond || got > time.Second {
+			t.Fatalf("Delay(1) with jitter 0.5 = %s, want between 500ms and 1s", got)
+		}
+	}
+}
+
+func TestPolicyValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  Policy
+		wantErr string
+	}{
+		{name: "defaults", policy: DefaultNetwork},
+		{name: "no attempts", policy: Policy{Backoff: 1}, wantErr: "maxAttempts"},
+		{name: "shrinking backoff", policy: Policy{MaxAttempts: 2, Backoff: 0.5}, wantErr: "backoff"},
+		{name: "negative delay", policy: Policy{MaxAttempts: 2, Backoff: 1, BaseDelay: -time.Second}, wantErr: "delay"},
+		{name: "jitter above 1", policy: Policy{MaxAttempts: 2, Backoff: 1, Jitter: 1.5}, wantErr: "jitter"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Validate()
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("Validate() = %v, want nil", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("Validate() = %v, want an error about %s", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDo(t *testing.T) {
+	policy := Policy{MaxAttempts: 3, BaseDelay: time.Millisecond, Backoff: 1}
+	errBoom := errors.New("boom")
+
+	t.Run("succeeds after retries", func(t *testing.T) {
+		attempts := 0
+		err := Do(context.Background(), policy, func(ctx context.Context, attempt int) error {
+			attempts = attempt
+			if attempt < 3 {
+				return errBoom
+			}
+			return nil
+		})
+		if err != nil || attempts != 3 {
+			t.Errorf("Do() = %v after %d attempts, want nil after 3", err, attempts)
+		}
+	})
+
+	t.Run("returns the last error", func(t *testing.T) {
+		attempts := 0
+		err := Do(context.Background(), policy, func(ctx context.Context, attempt int) error {
+			attempts++
+			return errBoom
+		})
+		if !errors.Is(err, errBoom) || attempts != 3 {
+			t.Errorf("Do() = %v after %d attempts, want boom after 3", err, attempts)
+		}
+	})
+
+	t.Run("stops when the context ends", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		slow := Policy{MaxAttempts: 5, BaseDelay: time.Minute, Backoff: 1}
+		attempts := 0
+		err := Do(ctx, slow, func(ctx context.Context, attempt int) error {
+			attempts++
+			time.AfterFunc(10*time.Millisecond, cancel)
+			return errBoom
+		})
+		if !errors.Is(err, context.Canceled) || !errors.Is(err, errBoom) || attempts != 1 {
+			t.Errorf("Do() = %v after %d attempts, want it to wrap context.Canceled and the last error after 1", err, attempts)
+		}
+	})
+
+	t.Run("does not retry once the context ended", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		attempts := 0
+		err := Do(ctx, policy, func(ctx context.Context, attempt int) error {
+			attempts++
+			cancel()
+			return errBoom
+		})
+		if err != errBoom || attempts != 1 {
+			t.Errorf("Do() = %v after %d attempts, want boom after 1", err, attempts)
+		}
+	})
+}
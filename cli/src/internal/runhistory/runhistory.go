@@ -0,0 +1,148 @@
+// Package runhistory keeps the lifecycle events of the services of each 'azd app run' session
+// in the workspace state, and summarizes them into usage statistics. The history stays on this
+// machine: it is never sent anywhere.
+package runhistory
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jongio/azd-app/cli/src/internal/statedir"
+)
+
+// MaxSessions is the number of sessions whose events are kept; older sessions are removed when
+// a session starts.
+const MaxSessions = 200
+
+// dataKind names the workspace data directory that holds the history.
+const dataKind = "runhistory"
+
+// sessionTimeFormat names session files by their start, so they sort by it.
+const sessionTimeFormat = "20060102T150405.000Z"
+
+// Lifecycle events, as written by the record writer of run.
+const (
+	eventStarted = "started"
+	eventReady   = "ready"
+	eventExited  = "exited"
+	eventFailed  = "failed"
+)
+
+// Event is a lifecycle event of a service in a session.
+type Event struct {
+	Service  string    `json:"service"`
+	Time     time.Time `json:"ts"`
+	Event    string    `json:"event"`
+	ExitCode *int      `json:"exitCode,omitempty"`
+	Crashed  bool      `json:"crashed,omitempty"`
+}
+
+// Session is the events of one 'azd app run', in the order they happened.
+type Session struct {
+	Started time.Time `json:"started"`
+	Events  []Event   `json:"events"`
+}
+
+// Create starts the history of a session of the project in projectDir and returns the file
+// its events are appended to, as JSON lines. The oldest sessions are removed so that no more
+// than MaxSessions are kept.
+func Create(projectDir string) (*os.File, error) {
+	dir, err := statedir.DataDir(projectDir, dataKind)
+	if err != nil {
+		return nil, err
+	}
+	files, err := sessionFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	for len(files) >= MaxSessions {
+		_ = os.Remove(filepath.Join(dir, files[0]))
+		files = files[1:]
+	}
+
+	name := fmt.Sprintf("%s-%d.jsonl", time.Now().UTC().Format(sessionTimeFormat), os.Getpid())
+	// #nosec G304 -- Path is in the workspace state directory, named by time and PID
+	file, err := os.OpenFile(filepath.Join(dir, name), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create run history: %w", err)
+	}
+	return file, nil
+}
+
+// Load reads the sessions of the project in projectDir that started at or after since, oldest
+// first. Lines that can't be parsed, e.g. the last line of a session that was killed while
+// writing it, are skipped.
+func Load(projectDir string, since time.Time) ([]Session, error) {
+	dir, err := statedir.DataPath(projectDir, dataKind)
+	if err != nil {
+		return nil, err
+	}
+	files, err := sessionFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var sessions []Session
+	for _, name := range files {
+		stamp, _, _ := strings.Cut(name, "-")
+		started, err := time.Parse(sessionTimeFormat, stamp)
+		if err != nil || started.Before(since) {
+			continue
+		}
+		events, err := readEvents(filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, Session{Started: started, Events: events})
+	}
+	return sessions, nil
+}
+
+// sessionFiles returns the names of the session files in dir, oldest first.
+func sessionFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read run history: %w", err)
+	}
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".jsonl") {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// readEvents reads the events of a session file.
+func readEvents(path string) ([]Event, error) {
+	// #nosec G304 -- Path is a session file of the workspace state directory
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read run history: %w", err)
+	}
+	defer file.Close()
+
+	var events []Event
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var event Event
+		if json.Unmarshal(scanner.Bytes(), &event) != nil || event.Service == "" || event.Event == "" {
+			continue
+		}
+		events = append(events, event)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read run history %s: %w", filepath.Base(path), err)
+	}
+	return events, nil
+}
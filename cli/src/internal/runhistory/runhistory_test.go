@@ -0,0 +1,136 @@
+package runhistory
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jongio/azd-app/cli/src/internal/statedir"
+)
+
+func writeSession(t *testing.T, projectDir string, events ...Event) {
+	t.Helper()
+	file, err := Create(projectDir)
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	defer file.Close()
+	encoder := json.NewEncoder(file)
+	for _, event := range events {
+		if err := encoder.Encode(event); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestCreateAndLoad(t *testing.T) {
+	t.Setenv(statedir.RootEnvVar, t.TempDir())
+	projectDir := t.TempDir()
+
+	sessions, err := Load(projectDir, time.Time{})
+	if err != nil || len(sessions) != 0 {
+		t.Fatalf("Load() = %v, %v, want no sessions before the first run", sessions, err)
+	}
+
+	now := time.Now()
+	writeSession(t, projectDir,
+		Event{Service: "api", Time: now, Event: eventStarted},
+		Event{Service: "api", Time: now.Add(time.Second), Event: eventReady},
+	)
+	dir, err := statedir.DataPath(projectDir, dataKind)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, _ := sessionFiles(dir)
+	f, err := os.OpenFile(filepath.Join(dir, files[0]), os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = f.WriteString(`{"service":"api","ev`) // Cut short by a killed session
+	_ = f.Close()
+
+	sessions, err = Load(projectDir, time.Time{})
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(sessions) != 1 || len(sessions[0].Events) != 2 {
+		t.Fatalf("sessions = %+v, want one session with two events", sessions)
+	}
+	if sessions[0].Started.IsZero() {
+		t.Error("session start is not parsed from its file name")
+	}
+
+	if sessions, _ := Load(projectDir, now.Add(time.Hour)); len(sessions) != 0 {
+		t.Errorf("Load(since) = %+v, want no sessions started after since", sessions)
+	}
+}
+
+func TestCreatePrunes(t *testing.T) {
+	t.Setenv(statedir.RootEnvVar, t.TempDir())
+	projectDir := t.TempDir()
+	dir, err := statedir.DataDir(projectDir, dataKind)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < MaxSessions; i++ {
+		name := start.Add(time.Duration(i)*time.Minute).Format(sessionTimeFormat) + "-1.jsonl"
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	writeSession(t, projectDir)
+	files, _ := sessionFiles(dir)
+	if len(files) != MaxSessions {
+		t.Fatalf("%d sessions kept, want %d", len(files), MaxSessions)
+	}
+	if oldest := start.Format(sessionTimeFormat) + "-1.jsonl"; files[0] == oldest {
+		t.Error("the oldest session was kept")
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	t0 := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	code := 1
+	sessions := []Session{
+		{Started: t0, Events: []Event{
+			{Service: "api", Time: t0, Event: eventStarted},
+			{Service: "web", Time: t0, Event: eventStarted},
+			{Service: "api", Time: t0.Add(2 * time.Second), Event: eventReady},
+			{Service: "web", Time: t0.Add(2 * time.Second), Event: eventReady},
+			{Service: "api", Time: t0.Add(time.Minute), Event: eventExited, ExitCode: &code, Crashed: true},
+			{Service: "api", Time: t0.Add(time.Minute + time.Second), Event: eventStarted},
+			{Service: "api", Time: t0.Add(time.Minute + 5*time.Second), Event: eventReady},
+			{Service: "api", Time: t0.Add(time.Hour), Event: eventExited},
+			{Service: "web", Time: t0.Add(time.Hour), Event: eventExited},
+		}},
+		{Started: t0.Add(24 * time.Hour), Events: []Event{
+			{Service: "worker", Time: t0.Add(24 * time.Hour), Event: eventFailed},
+		}},
+	}
+
+	report := Summarize(sessions)
+	if report.Sessions != 2 || !report.From.Equal(t0) || len(report.Services) != 3 {
+		t.Fatalf("report = %+v", report)
+	}
+	api := report.Services[0]
+	if api.Service != "api" || api.Sessions != 1 || api.Starts != 2 || api.Restarts != 1 || api.Crashes != 1 {
+		t.Errorf("api = %+v", api)
+	}
+	if api.AvgStartup != 3*time.Second || api.MaxStartup != 4*time.Second {
+		t.Errorf("api startup = %s mean, %s max, want 3s and 4s", api.AvgStartup, api.MaxStartup)
+	}
+
+	if crashed := report.MostCrashes(5); len(crashed) != 2 || crashed[0].Service != "api" || crashed[1].Service != "worker" {
+		t.Errorf("MostCrashes = %+v, want api then worker", crashed)
+	}
+	if slowest := report.SlowestStartups(1); len(slowest) != 1 || slowest[0].Service != "api" {
+		t.Errorf("SlowestStartups(1) = %+v, want api", slowest)
+	}
+	if restarted := report.MostRestarted(5); len(restarted) != 1 || restarted[0].Service != "api" {
+		t.Errorf("MostRestarted = %+v, want api", restarted)
+	}
+}
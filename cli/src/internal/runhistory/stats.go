@@ -0,0 +1,113 @@
+package runhistory
+
+import (
+	"sort"
+	"time"
+)
+
+// ServiceStats is the usage of a service across sessions.
+type ServiceStats struct {
+	Service    string        `json:"service"`
+	Sessions   int           `json:"sessions"`            // Sessions that started the service
+	Starts     int           `json:"starts"`              // Processes started, including restarts
+	Restarts   int           `json:"restarts"`            // Starts after the first of a session, on a crash or a change
+	Crashes    int           `json:"crashes"`             // Exits that azd app didn't ask for
+	Failures   int           `json:"failures"`            // Starts that failed
+	AvgStartup time.Duration `json:"avgStartup"`          // Mean time from start until ready
+	MaxStartup time.Duration `json:"maxStartup"`          // Longest time from start until ready
+	LastCrash  *time.Time    `json:"lastCrash,omitempty"` // Time of the last crash
+	startups   int           // Startups measured for AvgStartup
+}
+
+// Report is the usage of the services of a project across sessions.
+type Report struct {
+	Sessions int            `json:"sessions"`
+	From     *time.Time     `json:"from,omitempty"` // Start of the first session
+	Services []ServiceStats `json:"services"`       // By service
+}
+
+// Summarize returns the usage of the services in sessions. The startup of a service is the time
+// from its start until it was ready, which run reports once every service is ready.
+func Summarize(sessions []Session) Report {
+	report := Report{Sessions: len(sessions), Services: []ServiceStats{}}
+	if len(sessions) > 0 {
+		from := sessions[0].Started
+		report.From = &from
+	}
+
+	byService := make(map[string]*ServiceStats)
+	for _, session := range sessions {
+		started := make(map[string]time.Time) // Start of each service not ready yet
+		starts := make(map[string]int)
+		for _, event := range session.Events {
+			stats, ok := byService[event.Service]
+			if !ok {
+				stats = &ServiceStats{Service: event.Service}
+				byService[event.Service] = stats
+			}
+			switch event.Event {
+			case eventStarted:
+				stats.Starts++
+				if starts[event.Service] == 0 {
+					stats.Sessions++
+				} else {
+					stats.Restarts++
+				}
+				starts[event.Service]++
+				started[event.Service] = event.Time
+			case eventReady:
+				if start, ok := started[event.Service]; ok {
+					delete(started, event.Service)
+					startup := event.Time.Sub(start)
+					stats.AvgStartup = (stats.AvgStartup*time.Duration(stats.startups) + startup) / time.Duration(stats.startups+1)
+					stats.startups++
+					stats.MaxStartup = max(stats.MaxStartup, startup)
+				}
+			case eventExited:
+				if event.Crashed {
+					stats.Crashes++
+					at := event.Time
+					stats.LastCrash = &at
+				}
+			case eventFailed:
+				stats.Failures++
+			}
+		}
+	}
+
+	for _, stats := range byService {
+		report.Services = append(report.Services, *stats)
+	}
+	sort.Slice(report.Services, func(i, j int) bool { return report.Services[i].Service < report.Services[j].Service })
+	return report
+}
+
+// MostCrashes returns up to n services that crashed, most crashes first.
+func (r Report) MostCrashes(n int) []ServiceStats {
+	return top(r.Services, n, func(s ServiceStats) int64 { return int64(s.Crashes + s.Failures) })
+}
+
+// SlowestStartups returns up to n services, slowest mean startup first.
+func (r Report) SlowestStartups(n int) []ServiceStats {
+	return top(r.Services, n, func(s ServiceStats) int64 { return int64(s.AvgStartup) })
+}
+
+// MostRestarted returns up to n services that were restarted, most restarts first.
+func (r Report) MostRestarted(n int) []ServiceStats {
+	return top(r.Services, n, func(s ServiceStats) int64 { return int64(s.Restarts) })
+}
+
+// top returns up to n of services whose key is above zero, highest key first, then by name.
+func top(services []ServiceStats, n int, key func(ServiceStats) int64) []ServiceStats {
+	var ranked []ServiceStats
+	for _, s := range services {
+		if key(s) > 0 {
+			ranked = append(ranked, s)
+		}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool { return key(ranked[i]) > key(ranked[j]) })
+	if len(ranked) > n {
+		ranked = ranked[:n]
+	}
+	return ranked
+}
@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"runtime"
 
+	"github.com/jongio/azd-app/cli/src/internal/detector"
 	"github.com/jongio/azd-app/cli/src/internal/executor"
 	"github.com/jongio/azd-app/cli/src/internal/output"
 	"github.com/jongio/azd-app/cli/src/internal/security"
@@ -236,12 +237,12 @@ func RunDotnet(project types.DotnetProject) error {
 	output.Item("Project: %s", project.Path)
 	output.Newline()
 
-	// For .sln files, we need to run from the directory
+	// For .sln/.slnx files, we need to run from the directory
 	// For .csproj files, we can pass the path directly
 	args := []string{"run"}
 	dir := ""
 
-	if filepath.Ext(project.Path) == ".sln" {
+	if detector.IsDotnetSolution(project.Path) {
 		dir = filepath.Dir(project.Path)
 	} else {
 		args = append(args, "--project", project.Path)
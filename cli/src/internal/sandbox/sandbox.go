@@ -0,0 +1,351 @@
+// Package sandbox runs workspace commands in disposable Docker containers, so installing,
+// building, and running an untrusted template never executes its code on the host.
+//
+// The workspace is mounted read-only at /src. On first use it is copied into a Docker volume
+// mounted at /workspace, where commands run and may write: installed dependencies and build
+// output stay in the volume and are shared by later commands of the same workspace, while the
+// host checkout is never modified. Containers are removed when their command exits. Only the
+// environment variables of a command are passed, never the host environment.
+//
+// Containers run on the engine the container package resolves. A remote engine can't mount
+// the workspace, so it is copied into the volume before the first command instead.
+package sandbox
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/jongio/azd-app/cli/src/internal/container"
+	"github.com/jongio/azd-app/cli/src/internal/statedir"
+)
+
+// namePrefix starts the name of every sandbox volume and container.
+const namePrefix = "azd-app-sandbox-"
+
+// Mount points inside the container.
+const (
+	sourceDir = "/src"
+	workDir   = "/workspace"
+)
+
+// seededMarker is created in the volume once the workspace has been copied into it.
+const seededMarker = ".azd-app-sandbox"
+
+// images maps command names to the image they run in by default.
+var images = map[string]string{
+	"node":    "node:lts",
+	"npm":     "node:lts",
+	"npx":     "node:lts",
+	"pnpm":    "node:lts",
+	"yarn":    "node:lts",
+	"python":  "python:3.12",
+	"python3": "python:3.12",
+	"pip":     "python:3.12",
+	"uv":      "python:3.12",
+	"poetry":  "python:3.12",
+	"pipenv":  "python:3.12",
+	"dotnet":  "mcr.microsoft.com/dotnet/sdk:8.0",
+}
+
+// defaultEnv is set in every container unless a command sets it: servers listen on all
+// interfaces so published ports reach them, and Python package managers create their virtual
+// environment in the project, where it is found by later commands.
+var defaultEnv = map[string]string{
+	"HOST":                          "0.0.0.0",
+	"PIPENV_VENV_IN_PROJECT":        "1",
+	"POETRY_VIRTUALENVS_IN_PROJECT": "true",
+}
+
+// setup maps command names to shell commands that make them available in their image.
+var setup = map[string]string{
+	"pnpm":   "corepack enable",
+	"yarn":   "corepack enable",
+	"uv":     "pip install --quiet --disable-pip-version-check uv",
+	"poetry": "pip install --quiet --disable-pip-version-check poetry",
+	"pipenv": "pip install --quiet --disable-pip-version-check pipenv",
+}
+
+// runCommand runs a command of the container engine and returns its combined output.
+var runCommand = func(engine container.Engine, args ...string) ([]byte, error) {
+	return engine.Command(context.Background(), args...).CombinedOutput()
+}
+
+// Sandbox runs the commands of one workspace in containers.
+type Sandbox struct {
+	Workspace string           // Host directory mounted read-only into every container
+	Volume    string           // Docker volume holding the writable copy of the workspace
+	Image     string           // Optional: image for every command, instead of one chosen per command
+	Engine    container.Engine // Engine the containers run on
+
+	seedMu sync.Mutex
+	seeded bool // The workspace was copied into the volume of a remote engine
+}
+
+// Command is a command to run in the sandbox.
+type Command struct {
+	Label  string            // Optional: names the container, e.g. the service name
+	Dir    string            // Host directory to run in, within the workspace
+	Script string            // Shell command line, e.g. "npm install"
+	Env    map[string]string // Environment variables of the command
+	Ports  []int             // Ports to publish on 127.0.0.1 of the host, or forwarded to it from a remote engine
+}
+
+// New returns the sandbox of workspace. image overrides the image chosen for each command.
+func New(workspace, image string) (*Sandbox, error) {
+	absPath, err := filepath.Abs(workspace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve workspace path: %w", err)
+	}
+	key, err := statedir.WorkspaceKey(absPath)
+	if err != nil {
+		return nil, err
+	}
+	engine, err := container.Current()
+	if err != nil {
+		return nil, err
+	}
+	return &Sandbox{Workspace: absPath, Volume: namePrefix + strings.ToLower(key), Image: image, Engine: engine}, nil
+}
+
+// Available returns an error if the container engine cannot be reached.
+func Available() error {
+	engine, err := container.Current()
+	if err != nil {
+		return err
+	}
+	if out, err := runCommand(engine, "info"); err != nil {
+		return fmt.Errorf("%s is required for --sandbox: %w: %s", engine, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// Exec returns the engine command that runs c in a new container.
+func (s *Sandbox) Exec(ctx context.Context, c Command) (*exec.Cmd, error) {
+	if err := s.Engine.CheckPorts(ctx, c.Ports); err != nil {
+		return nil, err
+	}
+	if err := s.Seed(c); err != nil {
+		return nil, err
+	}
+	args, env, err := s.DockerArgs(c)
+	if err != nil {
+		return nil, err
+	}
+	cmd := s.Engine.Command(ctx, args...)
+	cmd.Env = append(os.Environ(), env...)
+	return cmd, nil
+}
+
+// Seed copies the workspace into the volume of a remote engine, in a container of the image
+// of c, unless an earlier command did. Containers of local engines copy it themselves.
+func (s *Sandbox) Seed(c Command) error {
+	if !s.Engine.IsRemote() {
+		return nil
+	}
+	s.seedMu.Lock()
+	defer s.seedMu.Unlock()
+	if s.seeded {
+		return nil
+	}
+	image, err := s.image(c.Script)
+	if err != nil {
+		return err
+	}
+
+	volume := fmt.Sprintf("type=volume,source=%s,target=%s", s.Volume, workDir)
+	if _, err := runCommand(s.Engine, "run", "--rm", "--mount", volume, image, "test", "-e", workDir+"/"+seededMarker); err == nil {
+		s.seeded = true
+		return nil
+	}
+
+	// The marker is copied last, so that an interrupted copy is started over
+	helper := s.Volume + "-seed"
+	_, _ = runCommand(s.Engine, "rm", "--force", helper)
+	if out, err := runCommand(s.Engine, "create", "--name", helper, "--mount", volume, image, "true"); err != nil {
+		return fmt.Errorf("failed to copy the workspace to %s: %w: %s", s.Engine, err, strings.TrimSpace(string(out)))
+	}
+	defer func() { _, _ = runCommand(s.Engine, "rm", "--force", helper) }()
+	if out, err := runCommand(s.Engine, "cp", s.Workspace+string(filepath.Separator)+".", helper+":"+workDir); err != nil {
+		return fmt.Errorf("failed to copy the workspace to %s: %w: %s", s.Engine, err, strings.TrimSpace(string(out)))
+	}
+	marker, err := os.CreateTemp("", "azd-app-sandbox-*")
+	if err != nil {
+		return fmt.Errorf("failed to create sandbox marker: %w", err)
+	}
+	_ = marker.Close()
+	defer os.Remove(marker.Name())
+	if out, err := runCommand(s.Engine, "cp", marker.Name(), helper+":"+workDir+"/"+seededMarker); err != nil {
+		return fmt.Errorf("failed to copy the workspace to %s: %w: %s", s.Engine, err, strings.TrimSpace(string(out)))
+	}
+	s.seeded = true
+	return nil
+}
+
+// DockerArgs returns the arguments of the run command of the engine that runs c, and the
+// variables of c as NAME=value, which must be set in the environment of the engine command.
+// The arguments only name the variables, so that their values, which may be secrets, aren't
+// visible in the process list.
+func (s *Sandbox) DockerArgs(c Command) (args, env []string, err error) {
+	dir, err := s.containerPath(c.Dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	program := firstWord(c.Script)
+	image, err := s.image(c.Script)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	args = []string{"run", "--rm", "--init", "--security-opt", "no-new-privileges"}
+	// A remote engine can't mount the workspace; Seed copied it into the volume
+	if !s.Engine.IsRemote() {
+		args = append(args, "--mount", fmt.Sprintf("type=bind,source=%s,target=%s,readonly", s.Workspace, sourceDir))
+	}
+	args = append(args,
+		"--mount", fmt.Sprintf("type=volume,source=%s,target=%s", s.Volume, workDir),
+		"--workdir", dir,
+	)
+	if c.Label != "" {
+		args = append(args, "--name", s.ContainerName(c.Label))
+	}
+	for _, port := range c.Ports {
+		args = append(args, "--publish", fmt.Sprintf("%s:%d:%d", s.Engine.PublishAddress(), port, port))
+	}
+	vars := make(map[string]string, len(defaultEnv)+len(c.Env))
+	for name, value := range defaultEnv {
+		vars[name] = value
+	}
+	for name, value := range c.Env {
+		vars[name] = s.translate(value)
+	}
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		args = append(args, "--env", name)
+		env = append(env, name+"="+vars[name])
+	}
+
+	script := []string{
+		// Copy the workspace into the volume on first use
+		fmt.Sprintf("[ -e %[1]s/%[2]s ] || { cp -R %[3]s/. %[1]s/ && touch %[1]s/%[2]s; }", workDir, seededMarker, sourceDir),
+		"cd " + Quote(dir),
+		// Commands of Python projects use the virtual environment deps created
+		`if [ -d .venv/bin ]; then PATH="$PWD/.venv/bin:$PATH"; fi`,
+	}
+	if step := setup[filepath.Base(program)]; step != "" {
+		script = append(script, step)
+	}
+	script = append(script, s.translate(c.Script))
+
+	return append(args, image, "sh", "-c", strings.Join(script, " && ")), env, nil
+}
+
+// image returns the image that script runs in: the image of the sandbox, or else the image of
+// its program.
+func (s *Sandbox) image(script string) (string, error) {
+	if s.Image != "" {
+		return s.Image, nil
+	}
+	program := firstWord(script)
+	if image := images[filepath.Base(program)]; image != "" {
+		return image, nil
+	}
+	return "", fmt.Errorf("no sandbox image known for %q - pass --sandbox-image", program)
+}
+
+// ContainerName returns the name of the container that runs the command labeled label.
+func (s *Sandbox) ContainerName(label string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(label) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '-' || r == '_' || r == '.' {
+			b.WriteRune(r)
+		} else {
+			b.WriteRune('-')
+		}
+	}
+	return s.Volume + "-" + b.String()
+}
+
+// RemoveContainer stops and removes the container of the command labeled label, if it exists.
+func (s *Sandbox) RemoveContainer(label string) error {
+	if out, err := runCommand(s.Engine, "rm", "--force", s.ContainerName(label)); err != nil {
+		return fmt.Errorf("failed to remove container: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// Remove deletes the volume with the copy of the workspace, discarding installed dependencies
+// and build output. The next command copies the workspace again.
+func (s *Sandbox) Remove() error {
+	if out, err := runCommand(s.Engine, "volume", "rm", "--force", s.Volume); err != nil {
+		return fmt.Errorf("failed to remove sandbox volume: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	s.seedMu.Lock()
+	s.seeded = false
+	s.seedMu.Unlock()
+	return nil
+}
+
+// containerPath maps a host directory within the workspace to its path in the container.
+func (s *Sandbox) containerPath(dir string) (string, error) {
+	if dir == "" {
+		return workDir, nil
+	}
+	rel, err := filepath.Rel(s.Workspace, dir)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s is outside the sandboxed workspace %s", dir, s.Workspace)
+	}
+	if rel == "." {
+		return workDir, nil
+	}
+	return workDir + "/" + filepath.ToSlash(rel), nil
+}
+
+// translate replaces host paths within the workspace by their path in the container, e.g. the
+// virtual environment python of a service.
+func (s *Sandbox) translate(value string) string {
+	return strings.ReplaceAll(value, s.Workspace, workDir)
+}
+
+// Quote quotes a word for a POSIX shell when it contains special characters.
+func Quote(word string) string {
+	if word != "" && strings.IndexFunc(word, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_./:=@%+,", r))
+	}) < 0 {
+		return word
+	}
+	return "'" + strings.ReplaceAll(word, "'", `'\''`) + "'"
+}
+
+// Script formats a command and its arguments as a shell command line.
+func Script(name string, args []string) string {
+	words := make([]string, 0, len(args)+1)
+	words = append(words, Quote(name))
+	for _, arg := range args {
+		words = append(words, Quote(arg))
+	}
+	return strings.Join(words, " ")
+}
+
+// firstWord returns the program a shell command line starts with.
+func firstWord(script string) string {
+	fields := strings.Fields(script)
+	if len(fields) == 0 {
+		return ""
+	}
+	word := strings.Trim(fields[0], `'"`)
+	// Virtual environment executables run in the image of their language
+	if strings.Contains(filepath.ToSlash(word), "/.venv/") || strings.HasPrefix(word, ".venv/") {
+		return "python"
+	}
+	return word
+}
@@ -0,0 +1,154 @@
+package sandbox
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestDockerArgs(t *testing.T) {
+	workspace := t.TempDir()
+	s, err := New(workspace, "")
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if !strings.HasPrefix(s.Volume, namePrefix) {
+		t.Fatalf("volume %q should start with %q", s.Volume, namePrefix)
+	}
+
+	tests := []struct {
+		name      string
+		image     string
+		command   Command
+		wantArgs  []string // Consecutive arguments that must appear
+		wantImage string
+		wantShell []string // Substrings of the shell script, in order
+		wantErr   string
+	}{
+		{
+			name:      "node install in a project directory",
+			command:   Command{Dir: filepath.Join(workspace, "web"), Script: "pnpm install"},
+			wantArgs:  []string{"--workdir", "/workspace/web"},
+			wantImage: "node:lts",
+			wantShell: []string{"cp -R /src/. /workspace/", "cd /workspace/web", "corepack enable", "pnpm install"},
+		},
+		{
+			name: "service with port, env, and host paths",
+			command: Command{
+				Label:  "My API",
+				Dir:    filepath.Join(workspace, "api"),
+				Script: Script(filepath.Join(workspace, "api", ".venv", "bin", "python"), []string{"main.py"}),
+				Env:    map[string]string{"DATA_DIR": filepath.Join(workspace, "data"), "HOST": "127.0.0.1"},
+				Ports:  []int{8000},
+			},
+			wantArgs:  []string{"--publish", "127.0.0.1:8000:8000"},
+			wantImage: "python:3.12",
+			wantShell: []string{"cd /workspace/api", "/workspace/api/.venv/bin/python main.py"},
+		},
+		{
+			name:      "image override",
+			image:     "golang:1.25",
+			command:   Command{Script: "go run ."},
+			wantArgs:  []string{"--workdir", "/workspace"},
+			wantImage: "golang:1.25",
+			wantShell: []string{"cd /workspace", "go run ."},
+		},
+		{
+			name:    "unknown command",
+			command: Command{Script: "make build"},
+			wantErr: "--sandbox-image",
+		},
+		{
+			name:    "directory outside the workspace",
+			command: Command{Dir: filepath.Dir(workspace), Script: "npm install"},
+			wantErr: "outside the sandboxed workspace",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s.Image = tt.image
+			args, env, err := s.DockerArgs(tt.command)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("DockerArgs() error = %v, want it to mention %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("DockerArgs() failed: %v", err)
+			}
+
+			joined := strings.Join(args, "\x00")
+			if !strings.Contains(joined, strings.Join(tt.wantArgs, "\x00")) {
+				t.Errorf("args %q should contain %q", args, tt.wantArgs)
+			}
+			if !strings.Contains(joined, "type=bind,source="+s.Workspace+",target=/src,readonly") {
+				t.Errorf("args %q should mount the workspace read-only", args)
+			}
+			if len(args) < 4 || args[len(args)-4] != tt.wantImage || args[len(args)-3] != "sh" {
+				t.Fatalf("args %q should end with image %q and a shell script", args, tt.wantImage)
+			}
+
+			script := args[len(args)-1]
+			rest := script
+			for _, want := range tt.wantShell {
+				i := strings.Index(rest, want)
+				if i < 0 {
+					t.Fatalf("script %q should contain %q after the previous steps", script, want)
+				}
+				rest = rest[i+len(want):]
+			}
+			if strings.Contains(script, s.Workspace) {
+				t.Errorf("script %q should not refer to the host workspace", script)
+			}
+			for i, arg := range args {
+				if arg == "--env" && strings.Contains(args[i+1], "=") {
+					t.Errorf("args %q should name variables without their values", args)
+				}
+			}
+			if !slices.Contains(env, "HOST=0.0.0.0") && tt.command.Env["HOST"] == "" {
+				t.Errorf("env %q should have the default variables", env)
+			}
+			for _, arg := range env {
+				if strings.HasPrefix(arg, "DATA_DIR=") && arg != "DATA_DIR=/workspace/data" {
+					t.Errorf("env %q should use the container path", arg)
+				}
+				if strings.HasPrefix(arg, "HOST=") && tt.command.Env["HOST"] != "" && arg != "HOST="+tt.command.Env["HOST"] {
+					t.Errorf("env %q should keep the command's value", arg)
+				}
+			}
+		})
+	}
+}
+
+func TestContainerName(t *testing.T) {
+	s := &Sandbox{Volume: "azd-app-sandbox-app-1234"}
+	if got, want := s.ContainerName("My API/v2"), "azd-app-sandbox-app-1234-my-api-v2"; got != want {
+		t.Errorf("ContainerName() = %q, want %q", got, want)
+	}
+}
+
+func TestScript(t *testing.T) {
+	tests := []struct {
+		name string
+		cmd  string
+		args []string
+		want string
+	}{
+		{name: "plain words", cmd: "npm", args: []string{"run", "dev"}, want: "npm run dev"},
+		{name: "spaces", cmd: "python", args: []string{"my app.py"}, want: "python 'my app.py'"},
+		{name: "single quote", cmd: "echo", args: []string{"it's"}, want: `echo 'it'\''s'`},
+		{name: "shell characters", cmd: "echo", args: []string{"$HOME;rm"}, want: "echo '$HOME;rm'"},
+		{name: "empty argument", cmd: "node", args: []string{""}, want: "node ''"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Script(tt.cmd, tt.args); got != tt.want {
+				t.Errorf("Script() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
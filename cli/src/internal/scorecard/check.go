@@ -0,0 +1,286 @@
+package scorecard
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jongio/azd-app/cli/src/internal/aspire"
+	"github.com/jongio/azd-app/cli/src/internal/audit"
+	"github.com/jongio/azd-app/cli/src/internal/azureyaml"
+	"github.com/jongio/azd-app/cli/src/internal/detector"
+	"github.com/jongio/azd-app/cli/src/internal/pinning"
+	"github.com/jongio/azd-app/cli/src/internal/runhistory"
+	"github.com/jongio/azd-app/cli/src/internal/service"
+	"github.com/jongio/azd-app/cli/src/internal/statedir"
+)
+
+// HistoryWindow is how far back runs count toward reliability.
+const HistoryWindow = 7 * 24 * time.Hour
+
+// slowStartup is how long a service may take to become ready before it is reported.
+const slowStartup = time.Minute
+
+// Audit scores below which a Lighthouse category is a warning, or an error.
+const (
+	auditWarningScore = 90
+	auditErrorScore   = 50
+)
+
+// maxDetails is how many instances a finding lists.
+const maxDetails = 10
+
+// Check rates the workspace at projectDir, whose azure.yaml is at azureYamlPath, or "" when it
+// has none. The checks only read the workspace and the state azd app keeps for it; nothing is
+// built or run.
+func Check(projectDir, azureYamlPath string, now time.Time) Report {
+	var findings []Finding
+	unchecked := make(map[string]string)
+	add := func(f []Finding, category, reason string) {
+		findings = append(findings, f...)
+		if reason != "" {
+			unchecked[category] = reason
+		}
+	}
+
+	f, reason := checkAzureYaml(projectDir, azureYamlPath)
+	add(f, CategoryLint, reason)
+	f, reason = checkDependencies(projectDir)
+	add(f, CategoryDependencies, reason)
+	f, reason = checkReliability(projectDir, now)
+	add(f, CategoryReliability, reason)
+	f, reason = checkAudits(projectDir)
+	add(f, CategoryAudit, reason)
+	return Rate(findings, unchecked)
+}
+
+// checkAzureYaml validates azure.yaml against the schema and lints it against the workspace:
+// projects it misses or points at wrongly, resources of the AppHost without a service, invalid
+// dependencies, and missing reqs. It returns why lint was skipped, if it was.
+func checkAzureYaml(projectDir, azureYamlPath string) ([]Finding, string) {
+	if azureYamlPath == "" {
+		return []Finding{{Category: CategoryValidation, Severity: SeverityError, Message: "The workspace has no azure.yaml", Fix: "azd app init"}}, "no azure.yaml"
+	}
+	doc, err := azureyaml.Load(azureYamlPath)
+	if err != nil {
+		return []Finding{{Category: CategoryValidation, Severity: SeverityError, Message: err.Error(), Fix: "Fix the syntax of azure.yaml"}}, "azure.yaml doesn't parse"
+	}
+
+	var findings []Finding
+	var schemaErrors, schemaWarnings []string
+	for _, issue := range doc.Validate() {
+		if issue.Severity == azureyaml.SeverityError {
+			schemaErrors = append(schemaErrors, issue.String())
+		} else {
+			schemaWarnings = append(schemaWarnings, issue.String())
+		}
+	}
+	if len(schemaErrors) > 0 {
+		findings = append(findings, finding(CategoryValidation, SeverityError, fmt.Sprintf("azure.yaml has %d schema error(s)", len(schemaErrors)), "azd app reqs", schemaErrors))
+	}
+	if len(schemaWarnings) > 0 {
+		findings = append(findings, finding(CategoryValidation, SeverityWarning, fmt.Sprintf("azure.yaml has %d schema warning(s)", len(schemaWarnings)), "azd app reqs", schemaWarnings))
+	}
+
+	project := doc.Project
+	if len(project.Services) == 0 {
+		findings = append(findings, Finding{Category: CategoryLint, Severity: SeverityWarning, Message: "azure.yaml defines no services", Fix: "azd app init"})
+	}
+	if len(project.Reqs) == 0 {
+		findings = append(findings, Finding{Category: CategoryLint, Severity: SeverityInfo, Message: "azure.yaml declares no reqs, so missing tools aren't reported before a run", Fix: "azd app reqs --generate"})
+	}
+	if _, _, err := service.BuildServiceGraph(project, projectDir); err != nil {
+		findings = append(findings, Finding{Category: CategoryLint, Severity: SeverityError, Message: fmt.Sprintf("Invalid service dependencies: %v", err), Fix: "Fix uses in azure.yaml"})
+	}
+
+	if detected, err := service.DetectServices(projectDir); err == nil {
+		findings = append(findings, syncFindings(service.DiffProjects(project, projectDir, detected))...)
+	}
+	appHost, err := detector.FindAppHost(projectDir)
+	var ambiguous *detector.AmbiguousAppHostError
+	if errors.As(err, &ambiguous) {
+		details := make([]string, 0, len(ambiguous.Candidates))
+		for _, c := range ambiguous.Candidates {
+			details = append(details, relativeTo(projectDir, c.ProjectFile))
+		}
+		findings = append(findings, finding(CategoryLint, SeverityWarning, fmt.Sprintf("The workspace has %d Aspire AppHosts and none is selected", len(details)), "Set detector.apphost in "+detector.ConfigFileName, details))
+	}
+	if err == nil && appHost != nil {
+		if model, err := aspire.ParseSource(*appHost); err == nil {
+			findings = append(findings, aspireFindings(service.DiffAspire(project, projectDir, model))...)
+		}
+	}
+	if projects, err := detector.FindDotnetProjects(projectDir); err == nil {
+		findings = append(findings, orphanFindings(projectDir, detector.AspireRoles(projects))...)
+	}
+	return findings, ""
+}
+
+// orphanFindings reports projects that use Aspire but that no AppHost references, so nothing
+// configures their service discovery, telemetry, and connection strings.
+func orphanFindings(projectDir string, roles []detector.AspireProjectRole) []Finding {
+	var orphans []string
+	for _, role := range roles {
+		if role.Role == detector.AspireRoleOrphan {
+			orphans = append(orphans, relativeTo(projectDir, role.Path))
+		}
+	}
+	if len(orphans) == 0 {
+		return nil
+	}
+	return []Finding{finding(CategoryLint, SeverityWarning, fmt.Sprintf("%d project(s) use Aspire, but no AppHost references them", len(orphans)), "azd app aspire projects", orphans)}
+}
+
+// syncFindings groups the differences between azure.yaml and the projects of the workspace.
+func syncFindings(changes []service.SyncChange) []Finding {
+	var missing, mismatched, added []string
+	for _, change := range changes {
+		switch change.Kind {
+		case service.SyncMissingProject:
+			missing = append(missing, fmt.Sprintf("%s: %s", change.Service, change.Project))
+		case service.SyncLanguageMismatch:
+			mismatched = append(mismatched, fmt.Sprintf("%s: %s, detected %s", change.Service, change.Language, change.Detected))
+		case service.SyncNewProject:
+			added = append(added, change.Project)
+		}
+	}
+
+	var findings []Finding
+	if len(missing) > 0 {
+		findings = append(findings, finding(CategoryLint, SeverityError, fmt.Sprintf("%d service(s) point at projects that don't exist", len(missing)), "azd app sync --write", missing))
+	}
+	if len(mismatched) > 0 {
+		findings = append(findings, finding(CategoryLint, SeverityWarning, fmt.Sprintf("%d service(s) declare a language their project isn't", len(mismatched)), "azd app sync --write", mismatched))
+	}
+	if len(added) > 0 {
+		findings = append(findings, finding(CategoryLint, SeverityWarning, fmt.Sprintf("%d project(s) aren't services in azure.yaml", len(added)), "azd app sync --write", added))
+	}
+	return findings
+}
+
+// aspireFindings groups the differences between azure.yaml and the resources of the AppHost.
+// Services the AppHost doesn't define are fine, so they aren't findings.
+func aspireFindings(changes []service.AspireChange) []Finding {
+	var details []string
+	for _, change := range changes {
+		switch change.Kind {
+		case service.AspireNewService:
+			details = append(details, fmt.Sprintf("%s: not in azure.yaml", change.Resource))
+		case service.AspireMismatch:
+			details = append(details, fmt.Sprintf("%s: %s differ", change.Service, strings.Join(change.Fields, ", ")))
+		}
+	}
+	if len(details) == 0 {
+		return nil
+	}
+	return []Finding{finding(CategoryLint, SeverityWarning, fmt.Sprintf("azure.yaml and the Aspire AppHost disagree on %d service(s)", len(details)), "azd app aspire sync --write", details)}
+}
+
+// checkDependencies reports floating dependency versions and missing lockfiles.
+func checkDependencies(projectDir string) ([]Finding, string) {
+	issues, err := pinning.Scan(projectDir)
+	if err != nil {
+		return nil, fmt.Sprintf("dependencies couldn't be scanned: %v", err)
+	}
+	if len(issues) == 0 {
+		return nil, ""
+	}
+	details := make([]string, 0, len(issues))
+	for _, issue := range issues {
+		file := relativeTo(projectDir, issue.File)
+		detail := file + ": " + issue.Reason
+		if issue.Package != "" {
+			detail = fmt.Sprintf("%s: %s %s (%s)", file, issue.Package, issue.Spec, issue.Reason)
+		}
+		details = append(details, detail)
+	}
+	return []Finding{finding(CategoryDependencies, SeverityWarning, fmt.Sprintf("%d dependency version(s) aren't reproducible", len(issues)), "azd app pin --write", details)}, ""
+}
+
+// checkReliability reports services that crashed, failed to start, or started slowly in the runs
+// of the last HistoryWindow.
+func checkReliability(projectDir string, now time.Time) ([]Finding, string) {
+	sessions, err := runhistory.Load(projectDir, now.Add(-HistoryWindow))
+	if err != nil {
+		return nil, fmt.Sprintf("run history couldn't be read: %v", err)
+	}
+	if len(sessions) == 0 {
+		return nil, "no runs in the last 7 days"
+	}
+
+	report := runhistory.Summarize(sessions)
+	var findings []Finding
+	for _, s := range report.Services {
+		logs := "azd app logs -s " + s.Service + " --level error"
+		if s.Failures > 0 {
+			findings = append(findings, Finding{Category: CategoryReliability, Severity: SeverityError, Message: fmt.Sprintf("%s failed to start %d time(s) in %d run(s)", s.Service, s.Failures, report.Sessions), Fix: logs})
+		}
+		if s.Crashes > 0 {
+			findings = append(findings, Finding{Category: CategoryReliability, Severity: SeverityWarning, Message: fmt.Sprintf("%s crashed %d time(s) in %d run(s)", s.Service, s.Crashes, report.Sessions), Fix: logs})
+		}
+		if s.AvgStartup > slowStartup {
+			findings = append(findings, Finding{Category: CategoryReliability, Severity: SeverityInfo, Message: fmt.Sprintf("%s takes %s on average to become ready", s.Service, s.AvgStartup.Round(time.Second)), Fix: "azd app stats"})
+		}
+	}
+	return findings, ""
+}
+
+// checkAudits reports Lighthouse categories that scored low in the last audit of each service.
+func checkAudits(projectDir string) ([]Finding, string) {
+	dir, err := statedir.DataPath(projectDir, "audit")
+	if err != nil {
+		return nil, err.Error()
+	}
+	reports, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	if len(reports) == 0 {
+		return nil, "no Lighthouse audits; run azd app audit web"
+	}
+	sort.Strings(reports)
+
+	var findings []Finding
+	for _, path := range reports {
+		// #nosec G304 -- Path is in the data directory of the workspace
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		scores, err := audit.ParseReport(data)
+		if err != nil {
+			continue
+		}
+		name := strings.TrimSuffix(filepath.Base(path), ".json")
+		for _, score := range scores {
+			severity := ""
+			switch {
+			case score.Score < auditErrorScore:
+				severity = SeverityError
+			case score.Score < auditWarningScore:
+				severity = SeverityWarning
+			default:
+				continue
+			}
+			findings = append(findings, Finding{Category: CategoryAudit, Severity: severity, Message: fmt.Sprintf("%s scores %d for %s", name, score.Score, score.Title), Fix: "azd app audit web " + name})
+		}
+	}
+	return findings, ""
+}
+
+// relativeTo returns path relative to dir when it is below dir.
+func relativeTo(dir, path string) string {
+	if rel, err := filepath.Rel(dir, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(rel)
+	}
+	return path
+}
+
+// finding returns a finding with up to maxDetails details.
+func finding(category, severity, message, fix string, details []string) Finding {
+	if len(details) > maxDetails {
+		details = append(details[:maxDetails:maxDetails], fmt.Sprintf("and %d more", len(details)-maxDetails))
+	}
+	return Finding{Category: category, Severity: severity, Message: message, Fix: fix, Details: details}
+}
@@ -0,0 +1,137 @@
+// Package scorecard rates the health of a workspace: it combines the signals of other checks,
+// such as azure.yaml validation, lint of azure.yaml against the workspace, floating dependency
+// versions, crashes of past runs, and Lighthouse audits, into a score from 0 to 100 with
+// recommendations ordered by how much they matter.
+package scorecard
+
+import (
+	"sort"
+)
+
+// Categories of findings.
+const (
+	CategoryValidation   = "validation"   // azure.yaml against the azd schema
+	CategoryLint         = "lint"         // azure.yaml against the workspace
+	CategoryDependencies = "dependencies" // Reproducible dependency versions
+	CategoryReliability  = "reliability"  // Crashes and failed starts of past runs
+	CategoryAudit        = "audit"        // Lighthouse scores of the last audits
+)
+
+// Severities of findings.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+	SeverityInfo    = "info"
+)
+
+// categoryWeights is how much each category counts toward the score, in the order categories
+// are shown.
+var categoryWeights = []struct {
+	name   string
+	weight int
+}{
+	{CategoryValidation, 3},
+	{CategoryLint, 3},
+	{CategoryReliability, 2},
+	{CategoryDependencies, 1},
+	{CategoryAudit, 1},
+}
+
+// severityPenalty is how many points a finding takes off the score of its category.
+var severityPenalty = map[string]int{SeverityError: 30, SeverityWarning: 10, SeverityInfo: 2}
+
+// severityOrder orders findings by severity.
+var severityOrder = map[string]int{SeverityError: 0, SeverityWarning: 1, SeverityInfo: 2}
+
+// Finding is a problem a check found, and how to fix it.
+type Finding struct {
+	Category string   `json:"category"`
+	Severity string   `json:"severity"`
+	Message  string   `json:"message"`
+	Fix      string   `json:"fix,omitempty"`     // Command or change that fixes it
+	Details  []string `json:"details,omitempty"` // Each instance, e.g. each service
+}
+
+// Category is the score of a category.
+type Category struct {
+	Name     string `json:"name"`
+	Score    int    `json:"score"`
+	Checked  bool   `json:"checked"`
+	Reason   string `json:"reason,omitempty"` // Why it wasn't checked, e.g. no audits were run
+	Findings int    `json:"findings"`
+}
+
+// Report is the health of a workspace.
+type Report struct {
+	Score           int        `json:"score"` // Weighted mean of the checked categories, 0 to 100
+	Grade           string     `json:"grade"` // A to F
+	Categories      []Category `json:"categories"`
+	Recommendations []Finding  `json:"recommendations"` // Findings, most important first
+}
+
+// Rate scores findings. Categories in unchecked, with the reason they weren't checked, don't
+// count toward the score. A category starts at 100 and each finding takes points off it: 30
+// for an error, 10 for a warning, and 2 for information.
+func Rate(findings []Finding, unchecked map[string]string) Report {
+	report := Report{Recommendations: append([]Finding{}, findings...)}
+
+	scores := make(map[string]int, len(categoryWeights))
+	counts := make(map[string]int, len(categoryWeights))
+	for _, c := range categoryWeights {
+		scores[c.name] = 100
+	}
+	for _, f := range findings {
+		scores[f.Category] = max(scores[f.Category]-severityPenalty[f.Severity], 0)
+		counts[f.Category]++
+	}
+
+	total, weights := 0, 0
+	for _, c := range categoryWeights {
+		category := Category{Name: c.name, Score: scores[c.name], Findings: counts[c.name], Checked: true}
+		if reason, ok := unchecked[c.name]; ok {
+			category = Category{Name: c.name, Reason: reason}
+		} else {
+			total += category.Score * c.weight
+			weights += c.weight
+		}
+		report.Categories = append(report.Categories, category)
+	}
+	report.Score = 100
+	if weights > 0 {
+		report.Score = (total + weights/2) / weights
+	}
+	report.Grade = Grade(report.Score)
+
+	weight := make(map[string]int, len(categoryWeights))
+	for _, c := range categoryWeights {
+		weight[c.name] = c.weight
+	}
+	sort.SliceStable(report.Recommendations, func(i, j int) bool {
+		a, b := report.Recommendations[i], report.Recommendations[j]
+		if a.Severity != b.Severity {
+			return severityOrder[a.Severity] < severityOrder[b.Severity]
+		}
+		if weight[a.Category] != weight[b.Category] {
+			return weight[a.Category] > weight[b.Category]
+		}
+		return a.Message < b.Message
+	})
+	return report
+}
+
+// Grade returns the letter grade of a score: A from 90, B from 80, C from 70, D from 60, and F
+// below.
+func Grade(score int) string {
+	switch {
+	case score >= 90:
+		return "A"
+	case score >= 80:
+		return "B"
+	case score >= 70:
+		return "C"
+	case score >= 60:
+		return "D"
+	default:
+		return "F"
+	}
+}
@@ -0,0 +1,157 @@
+package scorecard
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jongio/azd-app/cli/src/internal/statedir"
+)
+
+func TestRate(t *testing.T) {
+	findings := []Finding{
+		{Category: CategoryDependencies, Severity: SeverityWarning, Message: "floating versions"},
+		{Category: CategoryLint, Severity: SeverityInfo, Message: "no reqs"},
+		{Category: CategoryLint, Severity: SeverityWarning, Message: "new project"},
+		{Category: CategoryValidation, Severity: SeverityWarning, Message: "schema warning"},
+		{Category: CategoryReliability, Severity: SeverityError, Message: "api failed to start"},
+	}
+	report := Rate(findings, map[string]string{CategoryAudit: "no audits"})
+
+	// validation 90*3 + lint 88*3 + reliability 70*2 + dependencies 90*1, over 9
+	if report.Score != 85 || report.Grade != "B" {
+		t.Errorf("score = %d (%s), want 85 (B)", report.Score, report.Grade)
+	}
+
+	want := map[string]Category{
+		CategoryValidation:   {Name: CategoryValidation, Score: 90, Checked: true, Findings: 1},
+		CategoryLint:         {Name: CategoryLint, Score: 88, Checked: true, Findings: 2},
+		CategoryReliability:  {Name: CategoryReliability, Score: 70, Checked: true, Findings: 1},
+		CategoryDependencies: {Name: CategoryDependencies, Score: 90, Checked: true, Findings: 1},
+		CategoryAudit:        {Name: CategoryAudit, Reason: "no audits"},
+	}
+	if len(report.Categories) != len(want) {
+		t.Fatalf("got %d categories, want %d", len(report.Categories), len(want))
+	}
+	for _, c := range report.Categories {
+		if c != want[c.Name] {
+			t.Errorf("category %s = %+v, want %+v", c.Name, c, want[c.Name])
+		}
+	}
+
+	// Errors first, then warnings by the weight of their category, then information
+	var order []string
+	for _, f := range report.Recommendations {
+		order = append(order, f.Message)
+	}
+	wantOrder := "api failed to start, new project, schema warning, floating versions, no reqs"
+	if got := strings.Join(order, ", "); got != wantOrder {
+		t.Errorf("recommendations = %s, want %s", got, wantOrder)
+	}
+}
+
+func TestRateFloorsAtZero(t *testing.T) {
+	var findings []Finding
+	for range 5 {
+		findings = append(findings, Finding{Category: CategoryValidation, Severity: SeverityError, Message: "error"})
+	}
+	report := Rate(findings, map[string]string{CategoryLint: "x", CategoryReliability: "x", CategoryDependencies: "x", CategoryAudit: "x"})
+	if report.Score != 0 || report.Grade != "F" {
+		t.Errorf("score = %d (%s), want 0 (F)", report.Score, report.Grade)
+	}
+}
+
+func TestRateNothingChecked(t *testing.T) {
+	report := Rate(nil, map[string]string{CategoryValidation: "x", CategoryLint: "x", CategoryReliability: "x", CategoryDependencies: "x", CategoryAudit: "x"})
+	if report.Score != 100 || len(report.Recommendations) != 0 {
+		t.Errorf("got score %d with %d recommendations, want 100 with none", report.Score, len(report.Recommendations))
+	}
+}
+
+func TestGrade(t *testing.T) {
+	tests := map[int]string{100: "A", 90: "A", 89: "B", 80: "B", 75: "C", 60: "D", 59: "F", 0: "F"}
+	for score, want := range tests {
+		if got := Grade(score); got != want {
+			t.Errorf("Grade(%d) = %s, want %s", score, got, want)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	t.Setenv(statedir.RootEnvVar, t.TempDir())
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "azure.yaml"), `name: shop
+services:
+  api:
+    project: ./api
+    language: python
+    host: containerapp
+`)
+	writeFile(t, filepath.Join(dir, "web", "package.json"), `{"name": "web", "dependencies": {"express": "^4.0.0"}}`)
+
+	auditDir, err := statedir.DataPath(dir, "audit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(auditDir, "web.json"), `{"categories": {
+  "performance": {"id": "performance", "title": "Performance", "score": 0.42},
+  "accessibility": {"id": "accessibility", "title": "Accessibility", "score": 0.95}
+}}`)
+
+	report := Check(dir, filepath.Join(dir, "azure.yaml"), time.Now())
+
+	messages := make(map[string]Finding)
+	for _, f := range report.Recommendations {
+		messages[f.Message] = f
+	}
+	for _, want := range []string{
+		"1 service(s) point at projects that don't exist",
+		"1 project(s) aren't services in azure.yaml",
+		"web scores 42 for Performance",
+	} {
+		if _, ok := messages[want]; !ok {
+			t.Errorf("missing recommendation %q in %+v", want, report.Recommendations)
+		}
+	}
+	if f := messages["1 service(s) point at projects that don't exist"]; f.Fix != "azd app sync --write" || len(f.Details) != 1 {
+		t.Errorf("missing project finding = %+v", f)
+	}
+	if report.Recommendations[0].Severity != SeverityError {
+		t.Errorf("first recommendation = %+v, want an error", report.Recommendations[0])
+	}
+
+	for _, c := range report.Categories {
+		if c.Name == CategoryReliability && c.Checked {
+			t.Errorf("reliability was checked without run history")
+		}
+		if c.Name == CategoryAudit && (!c.Checked || c.Score != 70) {
+			t.Errorf("audit = %+v, want checked with score 70", c)
+		}
+	}
+}
+
+func TestCheckWithoutAzureYaml(t *testing.T) {
+	t.Setenv(statedir.RootEnvVar, t.TempDir())
+	report := Check(t.TempDir(), "", time.Now())
+
+	if len(report.Recommendations) == 0 || report.Recommendations[0].Fix != "azd app init" {
+		t.Fatalf("recommendations = %+v, want azd app init first", report.Recommendations)
+	}
+	for _, c := range report.Categories {
+		if c.Name == CategoryLint && c.Checked {
+			t.Errorf("lint was checked without azure.yaml")
+		}
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,305 @@
+// Package secretstore keeps secrets of a workspace encrypted on this machine, so that services
+// get them without the secrets being written to .env files.
+//
+// Secrets of a workspace are kept in secrets.json in its data directory (see statedir.DataDir),
+// encrypted with AES-256-GCM. The key is generated on first use and kept in secrets.key in the
+// state root, readable only by the user, so a copied secrets file can't be read elsewhere.
+package secretstore
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/jongio/azd-app/cli/src/internal/security"
+	"github.com/jongio/azd-app/cli/src/internal/statedir"
+)
+
+const (
+	keyFileName    = "secrets.key"
+	secretsKind    = "secrets"
+	secretsFile    = "secrets.json"
+	keySize        = 32
+	storeVersion   = 1
+	allServicesKey = "" // Service of secrets every service gets
+)
+
+// namePattern matches the names of secrets, which are the variables services get them as.
+var namePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Secret is a secret of the store.
+type Secret struct {
+	Name      string    `json:"name"`
+	Service   string    `json:"service,omitempty"` // Service that gets it; empty for every service
+	Value     string    `json:"value,omitempty"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// envelope is the encrypted secrets file.
+type envelope struct {
+	Version int    `json:"version"`
+	Nonce   []byte `json:"nonce"`
+	Data    []byte `json:"data"` // Secrets as JSON, sealed with AES-GCM
+}
+
+// Store is the secret store of one workspace.
+type Store struct {
+	workspace string
+	path      string
+}
+
+// Open returns the secret store of workspace.
+func Open(workspace string) (*Store, error) {
+	dir, err := statedir.DataPath(workspace, secretsKind)
+	if err != nil {
+		return nil, err
+	}
+	return &Store{workspace: workspace, path: filepath.Join(dir, secretsFile)}, nil
+}
+
+// ValidateName returns an error unless name can be the name of a secret.
+func ValidateName(name string) error {
+	if !namePattern.MatchString(name) {
+		return fmt.Errorf("invalid secret name %q: use letters, digits, and underscores, e.g. DATABASE_PASSWORD", name)
+	}
+	return nil
+}
+
+// Set stores a secret for service, or for every service when service is empty, replacing
+// the value it had.
+func (s *Store) Set(service, name, value string) error {
+	if err := ValidateName(name); err != nil {
+		return err
+	}
+	secrets, err := s.load()
+	if err != nil {
+		return err
+	}
+	i := find(secrets, service, name)
+	secret := Secret{Name: name, Service: service, Value: value, UpdatedAt: time.Now().UTC()}
+	if i < 0 {
+		secrets = append(secrets, secret)
+	} else {
+		secrets[i] = secret
+	}
+	return s.save(secrets)
+}
+
+// Get returns the secret of service named name, or of every service when service is empty,
+// and false if there is none.
+func (s *Store) Get(service, name string) (Secret, bool, error) {
+	secrets, err := s.load()
+	if err != nil {
+		return Secret{}, false, err
+	}
+	if i := find(secrets, service, name); i >= 0 {
+		return secrets[i], true, nil
+	}
+	return Secret{}, false, nil
+}
+
+// Delete removes a secret and reports whether it existed.
+func (s *Store) Delete(service, name string) (bool, error) {
+	secrets, err := s.load()
+	if err != nil {
+		return false, err
+	}
+	i := find(secrets, service, name)
+	if i < 0 {
+		return false, nil
+	}
+	return true, s.save(append(secrets[:i], secrets[i+1:]...))
+}
+
+// List returns the secrets, without their values, sorted by service and name.
+func (s *Store) List() ([]Secret, error) {
+	secrets, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	for i := range secrets {
+		secrets[i].Value = ""
+	}
+	sort.Slice(secrets, func(i, j int) bool {
+		if secrets[i].Service != secrets[j].Service {
+			return secrets[i].Service < secrets[j].Service
+		}
+		return secrets[i].Name < secrets[j].Name
+	})
+	return secrets, nil
+}
+
+// Env returns the variables service gets from the store: the secrets of every service,
+// overridden by its own.
+func (s *Store) Env(service string) (map[string]string, error) {
+	secrets, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	env := make(map[string]string)
+	for _, scope := range []string{allServicesKey, service} {
+		for _, secret := range secrets {
+			if secret.Service == scope {
+				env[secret.Name] = secret.Value
+			}
+		}
+	}
+	return env, nil
+}
+
+// find returns the index of the secret of service named name, or -1.
+func find(secrets []Secret, service, name string) int {
+	for i, secret := range secrets {
+		if secret.Service == service && secret.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// load decrypts the secrets of the store. A store that was never written has none.
+func (s *Store) load() ([]Secret, error) {
+	if err := security.ValidatePath(s.path); err != nil {
+		return nil, err
+	}
+	// #nosec G304 -- Path validated by security.ValidatePath
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read secrets: %w", err)
+	}
+
+	var env envelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", s.path, err)
+	}
+	if env.Version != storeVersion {
+		return nil, fmt.Errorf("unsupported secret store version %d in %s", env.Version, s.path)
+	}
+	gcm, err := cipherForKey(false)
+	if err != nil {
+		return nil, err
+	}
+	plain, err := gcm.Open(nil, env.Nonce, env.Data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt %s: it was written with another key", s.path)
+	}
+	var secrets []Secret
+	if err := json.Unmarshal(plain, &secrets); err != nil {
+		return nil, fmt.Errorf("failed to parse secrets: %w", err)
+	}
+	return secrets, nil
+}
+
+// save encrypts the secrets and replaces the file of the store.
+func (s *Store) save(secrets []Secret) error {
+	gcm, err := cipherForKey(true)
+	if err != nil {
+		return err
+	}
+	plain, err := json.Marshal(secrets)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	data, err := json.MarshalIndent(envelope{Version: storeVersion, Nonce: nonce, Data: gcm.Seal(nil, nonce, plain, nil)}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if _, err := statedir.DataDir(s.workspace, secretsKind); err != nil {
+		return err
+	}
+	if err := statedir.WriteFile(s.path, data); err != nil {
+		return fmt.Errorf("failed to write secrets: %w", err)
+	}
+	return nil
+}
+
+// cipherForKey returns the AES-GCM cipher of the key in the state root. With create, a key is
+// generated when there is none.
+func cipherForKey(create bool) (cipher.AEAD, error) {
+	root, err := statedir.Root()
+	if err != nil {
+		return nil, err
+	}
+	path := filepath.Join(root, keyFileName)
+	if err := security.ValidatePath(path); err != nil {
+		return nil, err
+	}
+	// #nosec G304 -- Path validated by security.ValidatePath
+	key, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist) && create:
+		if key, err = createKey(root, path); err != nil {
+			return nil, err
+		}
+	case errors.Is(err, os.ErrNotExist):
+		return nil, fmt.Errorf("secret store key %s is missing; set the secrets again", path)
+	case err != nil:
+		return nil, fmt.Errorf("failed to read secret store key: %w", err)
+	}
+	if len(key) != keySize {
+		return nil, fmt.Errorf("invalid secret store key %s", path)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// createKey generates the key at path, or returns the one another process created first, so
+// that concurrent sessions never encrypt with different keys. The key is written to a temporary
+// file and linked into place, so no process reads a key that is only partly written.
+func createKey(root, path string) ([]byte, error) {
+	key := make([]byte, keySize)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return nil, fmt.Errorf("failed to generate secret store key: %w", err)
+	}
+	if err := os.MkdirAll(root, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", root, err)
+	}
+	tmp, err := os.CreateTemp(root, "."+keyFileName+".*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create secret store key: %w", err)
+	}
+	tmpName := tmp.Name()
+	defer os.Remove(tmpName)
+	_, err = tmp.Write(key)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to write secret store key: %w", err)
+	}
+
+	// Unlike a rename, a link fails if another process created the key first
+	err = os.Link(tmpName, path)
+	if errors.Is(err, os.ErrExist) {
+		// #nosec G304 -- Path validated by security.ValidatePath
+		if key, err = os.ReadFile(path); err != nil {
+			return nil, fmt.Errorf("failed to read secret store key: %w", err)
+		}
+		return key, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create secret store key: %w", err)
+	}
+	return key, nil
+}
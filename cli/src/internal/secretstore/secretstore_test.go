@@ -0,0 +1,119 @@
+package secretstore
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/jongio/azd-app/cli/src/internal/statedir"
+)
+
+func TestStore(t *testing.T) {
+	root := t.TempDir()
+	t.Setenv(statedir.RootEnvVar, root)
+	store, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+
+	if secrets, err := store.List(); err != nil || len(secrets) != 0 {
+		t.Fatalf("List() of a new store = %v, %v, want none", secrets, err)
+	}
+	if err := store.Set("", "DB_PASSWORD", "shared-pw"); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if err := store.Set("api", "DB_PASSWORD", "api-pw"); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if err := store.Set("api", "STRIPE_KEY", "sk_test"); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if err := store.Set("", "bad-name", "x"); err == nil {
+		t.Error("expected error for an invalid name")
+	}
+
+	// Values are not stored in plain text
+	data, err := os.ReadFile(store.path)
+	if err != nil {
+		t.Fatalf("failed to read store: %v", err)
+	}
+	if strings.Contains(string(data), "sk_test") || strings.Contains(string(data), "STRIPE_KEY") {
+		t.Errorf("store holds secrets in plain text: %s", data)
+	}
+
+	env, err := store.Env("api")
+	if err != nil {
+		t.Fatalf("Env failed: %v", err)
+	}
+	if env["DB_PASSWORD"] != "api-pw" || env["STRIPE_KEY"] != "sk_test" {
+		t.Errorf("Env(api) = %v, want the secrets of api over shared ones", env)
+	}
+	if env, _ := store.Env("web"); len(env) != 1 || env["DB_PASSWORD"] != "shared-pw" {
+		t.Errorf("Env(web) = %v, want only shared secrets", env)
+	}
+
+	secret, ok, err := store.Get("", "DB_PASSWORD")
+	if err != nil || !ok || secret.Value != "shared-pw" {
+		t.Errorf("Get() = %+v, %v, %v", secret, ok, err)
+	}
+	secrets, err := store.List()
+	if err != nil || len(secrets) != 3 || secrets[0].Service != "" || secrets[0].Value != "" {
+		t.Errorf("List() = %+v, %v, want three secrets without values, shared first", secrets, err)
+	}
+
+	if deleted, err := store.Delete("api", "STRIPE_KEY"); err != nil || !deleted {
+		t.Errorf("Delete() = %v, %v", deleted, err)
+	}
+	if deleted, _ := store.Delete("api", "STRIPE_KEY"); deleted {
+		t.Error("Delete() of a missing secret reported it deleted")
+	}
+
+	// Without its key the store can't be read
+	if err := os.Remove(filepath.Join(root, keyFileName)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Env("api"); err == nil {
+		t.Error("expected error when the key is missing")
+	}
+}
+
+func TestConcurrentKeyCreation(t *testing.T) {
+	root := t.TempDir()
+	t.Setenv(statedir.RootEnvVar, root)
+	workspace := t.TempDir()
+
+	// Sessions that set their first secrets at the same time must agree on the key
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for i := 0; i < cap(errs); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			store, err := Open(workspace)
+			if err == nil {
+				err = store.Set("", "TOKEN", "value")
+			}
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("Set failed: %v", err)
+		}
+	}
+
+	store, err := Open(workspace)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if secret, ok, err := store.Get("", "TOKEN"); err != nil || !ok || secret.Value != "value" {
+		t.Errorf("Get() = %+v, %v, %v, want the secret readable with the key on disk", secret, ok, err)
+	}
+	if leftovers, _ := filepath.Glob(filepath.Join(root, "."+keyFileName+".*")); len(leftovers) > 0 {
+		t.Errorf("temporary key files left behind: %v", leftovers)
+	}
+}
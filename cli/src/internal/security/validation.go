@@ -51,6 +51,7 @@ func ValidatePackageManager(pm string) error {
 		"yarn":   true,
 		"pip":    true,
 		"poetry": true,
+		"pipenv": true,
 		"uv":     true,
 		"dotnet": true,
 	}
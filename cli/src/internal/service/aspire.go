@@ -0,0 +1,325 @@
+package service
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/jongio/azd-app/cli/src/internal/aspire"
+)
+
+// Kinds of differences between azure.yaml and the resources of an Aspire AppHost.
+const (
+	AspireNewService   = "new-service"    // A resource has no service
+	AspireMismatch     = "mismatch"       // A service doesn't match its resource
+	AspireNotInAppHost = "not-in-apphost" // A service has no resource
+)
+
+// aspireKindOrder orders changes by kind.
+var aspireKindOrder = map[string]int{AspireMismatch: 0, AspireNewService: 1, AspireNotInAppHost: 2}
+
+// AspireChange is a difference between azure.yaml and the resources of an AppHost, and how it
+// is fixed: a resource without a service is added as one, and the fields of a mismatched
+// service are set from its resource. Services the AppHost doesn't have are only reported.
+type AspireChange struct {
+	Kind     string   `json:"kind"`
+	Service  string   `json:"service"`
+	Resource string   `json:"resource,omitempty"`
+	Project  string   `json:"project,omitempty"`  // Project of the resource, relative to azure.yaml
+	Language string   `json:"language,omitempty"` // Language of the resource
+	Image    string   `json:"image,omitempty"`    // Image of a prebuilt container
+	Fields   []string `json:"fields,omitempty"`   // Fields of a mismatched service that differ, e.g. project or uses
+	Entry    Service  `json:"-"`                  // Service generated from the resource
+}
+
+// AspireServices maps the resources of an AppHost that run as services to azure.yaml services
+// for a project whose azure.yaml is in azureYamlDir, by service name. Projects become dotnet
+// container app services; containers built from a Dockerfile become docker services with their
+// build context as project; prebuilt containers become services of their image; and Node.js and
+// Python executables become services of their working directory. References to other resources
+// that became services are uses. Parameters, connection strings, Azure resources, and containers
+// without an image, such as integrations parsed from the source, are not services.
+func AspireServices(model *aspire.Model, azureYamlDir string) map[string]Service {
+	services := make(map[string]Service)
+	names := make(map[string]string) // Resource to service name
+	for _, r := range model.Resources {
+		if svc, ok := aspireService(r, azureYamlDir); ok {
+			name := serviceName(r.Name)
+			services[name] = svc
+			names[r.Name] = name
+		}
+	}
+
+	for _, r := range model.Resources {
+		name, ok := names[r.Name]
+		if !ok {
+			continue
+		}
+		svc := services[name]
+		for _, ref := range r.References {
+			if dep, ok := names[ref]; ok && !slices.Contains(svc.Uses, dep) {
+				svc.Uses = append(svc.Uses, dep)
+			}
+		}
+		sort.Strings(svc.Uses)
+		services[name] = svc
+	}
+	return services
+}
+
+// aspireService maps a resource to an azure.yaml service, or reports false when the resource
+// doesn't run as a service.
+func aspireService(r aspire.Resource, azureYamlDir string) (Service, bool) {
+	switch r.Kind {
+	case aspire.KindProject:
+		if r.Project == "" {
+			return Service{}, false
+		}
+		return Service{Host: hostContainerApp, Language: "dotnet", Project: aspireProjectPath(azureYamlDir, filepath.Dir(r.Project))}, true
+	case aspire.KindContainer:
+		if r.Build != nil && r.Build.Context != "" {
+			svc := Service{Host: hostContainerApp, Language: "docker", Project: aspireProjectPath(azureYamlDir, r.Build.Context)}
+			// The docker path is relative to the project, like the Dockerfile is to the build context
+			if r.Build.Dockerfile != "" {
+				if rel, err := filepath.Rel(r.Build.Context, r.Build.Dockerfile); err == nil && path.Clean(filepath.ToSlash(rel)) != "Dockerfile" {
+					svc.Docker = &DockerConfig{Path: relativeProjectPath(rel)}
+				}
+			}
+			return svc, true
+		}
+		if r.Image != "" {
+			return Service{Host: hostContainerApp, Image: r.Image}, true
+		}
+	case aspire.KindExecutable:
+		if r.WorkingDir == "" {
+			return Service{}, false
+		}
+		if language := executableLanguage(r.Command, r.WorkingDir); language != "" {
+			return Service{Host: hostContainerApp, Language: language, Project: aspireProjectPath(azureYamlDir, r.WorkingDir)}, true
+		}
+	}
+	return Service{}, false
+}
+
+// executableLanguage returns the language of an executable from its command, e.g. js for npm
+// or python for uvicorn, or "" when the command isn't one of a language azure.yaml builds.
+func executableLanguage(command, dir string) string {
+	name := strings.ToLower(strings.TrimSuffix(filepath.Base(command), filepath.Ext(command)))
+	switch name {
+	case "npm", "npx", "node", "pnpm", "yarn", "bun":
+		return nodeLanguage(dir)
+	case "python", "python3", "py", "uv", "uvicorn", "gunicorn", "flask", "streamlit", "poetry":
+		return "python"
+	}
+	return ""
+}
+
+// aspireProjectPath returns dir relative to azureYamlDir, the way azure.yaml writes it, or dir
+// when it isn't under azureYamlDir.
+func aspireProjectPath(azureYamlDir, dir string) string {
+	rel, err := filepath.Rel(azureYamlDir, dir)
+	if err != nil {
+		return dir
+	}
+	return relativeProjectPath(rel)
+}
+
+// DiffAspire compares the services of azureYaml, whose file is in azureYamlDir, with the
+// services AspireServices generates from the resources of model. A service matches a resource
+// by name, or else by project directory. The service of the AppHost itself, which azd deploys
+// the resources from, is not a difference.
+func DiffAspire(azureYaml *AzureYaml, azureYamlDir string, model *aspire.Model) []AspireChange {
+	var changes []AspireChange
+	generated := AspireServices(model, azureYamlDir)
+	resources := make(map[string]string, len(model.Resources)) // Service to resource name
+	for _, r := range model.Resources {
+		resources[serviceName(r.Name)] = r.Name
+	}
+
+	declared := make(map[string]string, len(azureYaml.Services)) // Project directory to service
+	for name, svc := range azureYaml.Services {
+		if svc.Project != "" {
+			declared[aspireServiceDir(azureYamlDir, svc.Project)] = name
+		}
+	}
+
+	names := make([]string, 0, len(generated))
+	for name := range generated {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	taken := make(map[string]bool, len(azureYaml.Services))
+	for name := range azureYaml.Services {
+		taken[name] = true
+	}
+	matched := make(map[string]bool, len(generated))
+	for _, name := range names {
+		want := generated[name]
+		change := AspireChange{Service: name, Resource: resources[name], Project: want.Project, Language: want.Language, Image: want.Image, Entry: want}
+
+		existingName := name
+		existing, ok := azureYaml.Services[name]
+		if !ok && want.Project != "" {
+			existingName, ok = declared[aspireServiceDir(azureYamlDir, want.Project)]
+			existing = azureYaml.Services[existingName]
+		}
+		if !ok || matched[existingName] {
+			change.Kind = AspireNewService
+			change.Service = uniqueServiceName(name, taken)
+			taken[change.Service] = true
+			changes = append(changes, change)
+			continue
+		}
+		matched[existingName] = true
+		if fields := aspireMismatches(existing, want, azureYamlDir); len(fields) > 0 {
+			change.Kind = AspireMismatch
+			change.Service = existingName
+			change.Fields = fields
+			changes = append(changes, change)
+		}
+	}
+
+	appHostDir := filepath.Clean(filepath.Dir(model.AppHost))
+	for name, svc := range azureYaml.Services {
+		if matched[name] || (model.AppHost != "" && svc.Project != "" && aspireServiceDir(azureYamlDir, svc.Project) == appHostDir) {
+			continue
+		}
+		changes = append(changes, AspireChange{Kind: AspireNotInAppHost, Service: name, Project: svc.Project, Language: svc.Language, Image: svc.Image})
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Kind != changes[j].Kind {
+			return aspireKindOrder[changes[i].Kind] < aspireKindOrder[changes[j].Kind]
+		}
+		return changes[i].Service < changes[j].Service
+	})
+	return changes
+}
+
+// aspireMismatches returns the fields of existing that don't match want: project, language,
+// image, docker, or uses. A service may use more than its resource refers to, such as azure.yaml
+// resources, so only missing uses are a difference.
+func aspireMismatches(existing, want Service, azureYamlDir string) []string {
+	var fields []string
+	if want.Project != "" && (existing.Project == "" || aspireServiceDir(azureYamlDir, existing.Project) != aspireServiceDir(azureYamlDir, want.Project)) {
+		fields = append(fields, "project")
+	}
+	if want.Language != "" && (existing.Language == "" || !languageDetected(existing.Language, []string{want.Language})) {
+		fields = append(fields, "language")
+	}
+	if want.Image != "" && existing.Image != want.Image {
+		fields = append(fields, "image")
+	}
+	if want.Docker != nil && (existing.Docker == nil || path.Clean(existing.Docker.Path) != path.Clean(want.Docker.Path)) {
+		fields = append(fields, "docker")
+	}
+	for _, dep := range want.Uses {
+		if !slices.Contains(existing.Uses, dep) {
+			fields = append(fields, "uses")
+			break
+		}
+	}
+	return fields
+}
+
+// aspireServiceDir returns the directory of the project of a service, which may be a project
+// file, e.g. ./src/Api/Api.csproj.
+func aspireServiceDir(azureYamlDir, project string) string {
+	dir := project
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(azureYamlDir, filepath.FromSlash(dir))
+	}
+	dir = filepath.Clean(dir)
+	switch filepath.Ext(dir) {
+	case ".csproj", ".fsproj", ".vbproj":
+		return filepath.Dir(dir)
+	}
+	if info, err := os.Stat(dir); err == nil && !info.IsDir() {
+		dir = filepath.Dir(dir)
+	}
+	return dir
+}
+
+// ApplyAspire applies changes to azureYaml. New services are added as generated, and the
+// differing fields of mismatched services are set from their resource, keeping the rest of the
+// service, such as its env and hooks. Uses are merged rather than replaced.
+func ApplyAspire(azureYaml *AzureYaml, changes []AspireChange) {
+	for _, change := range changes {
+		switch change.Kind {
+		case AspireNewService:
+			if azureYaml.Services == nil {
+				azureYaml.Services = make(map[string]Service)
+			}
+			azureYaml.Services[change.Service] = change.Entry
+		case AspireMismatch:
+			svc := azureYaml.Services[change.Service]
+			for _, field := range change.Fields {
+				switch field {
+				case "project":
+					svc.Project = change.Entry.Project
+				case "language":
+					svc.Language = change.Entry.Language
+				case "image":
+					svc.Image = change.Entry.Image
+				case "docker":
+					if svc.Docker == nil {
+						svc.Docker = &DockerConfig{}
+					}
+					svc.Docker.Path = change.Entry.Docker.Path
+				case "uses":
+					for _, dep := range change.Entry.Uses {
+						if !slices.Contains(svc.Uses, dep) {
+							svc.Uses = append(svc.Uses, dep)
+						}
+					}
+				}
+			}
+			azureYaml.Services[change.Service] = svc
+		}
+	}
+}
+
+// AspireOrchestration is how the services of azure.yaml relate to a running AppHost.
+type AspireOrchestration struct {
+	AppHost      []string // Services that run the AppHost itself, sorted
+	Orchestrated []string // Services that run a resource of the AppHost, which starts them, sorted
+}
+
+// OrchestratedByAppHost returns which of services, from an azure.yaml in azureYamlDir, run the
+// AppHost of model and which run one of its resources. A service runs a resource when it has
+// its name, or the project directory of its service from AspireServices.
+func OrchestratedByAppHost(services map[string]Service, azureYamlDir string, model *aspire.Model) AspireOrchestration {
+	var result AspireOrchestration
+	generated := AspireServices(model, azureYamlDir)
+	dirs := make(map[string]bool, len(generated))
+	for _, svc := range generated {
+		if svc.Project != "" {
+			dirs[aspireServiceDir(azureYamlDir, svc.Project)] = true
+		}
+	}
+	appHostDir := filepath.Clean(filepath.Dir(model.AppHost))
+
+	for name, svc := range services {
+		dir := ""
+		if svc.Project != "" {
+			dir = aspireServiceDir(azureYamlDir, svc.Project)
+		}
+		switch {
+		case model.AppHost != "" && dir == appHostDir:
+			result.AppHost = append(result.AppHost, name)
+		case dirs[dir]:
+			result.Orchestrated = append(result.Orchestrated, name)
+		default:
+			if _, ok := generated[name]; ok {
+				result.Orchestrated = append(result.Orchestrated, name)
+			}
+		}
+	}
+	sort.Strings(result.AppHost)
+	sort.Strings(result.Orchestrated)
+	return result
+}
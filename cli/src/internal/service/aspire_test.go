@@ -0,0 +1,108 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jongio/azd-app/cli/src/internal/aspire"
+)
+
+// testAspireModel returns the model of an AppHost in root/AppHost with an api project, a web
+// container built from source, a redis cache, a Node.js frontend, and a database.
+func testAspireModel(root string) *aspire.Model {
+	return &aspire.Model{
+		AppHost: filepath.Join(root, "AppHost", "AppHost.csproj"),
+		Source:  aspire.SourceManifest,
+		Resources: []aspire.Resource{
+			{Name: "api", Kind: aspire.KindProject, Project: filepath.Join(root, "src", "Api", "Api.csproj"), References: []string{"cache", "db"}},
+			{Name: "cache", Kind: aspire.KindContainer, Image: "docker.io/library/redis:7.4"},
+			{Name: "db", Kind: aspire.KindValue, Parent: "pg"},
+			{Name: "frontend", Kind: aspire.KindExecutable, Command: "npm", WorkingDir: filepath.Join(root, "frontend"), References: []string{"api"}},
+			{Name: "pg", Kind: aspire.KindContainer, Type: "AddPostgres"},
+			{Name: "web", Kind: aspire.KindContainer, Build: &aspire.Build{Context: filepath.Join(root, "web"), Dockerfile: filepath.Join(root, "web", "Dockerfile.prod")}, References: []string{"api"}},
+		},
+	}
+}
+
+func TestAspireServices(t *testing.T) {
+	root := t.TempDir()
+	got := AspireServices(testAspireModel(root), root)
+	want := map[string]Service{
+		"api":      {Host: "containerapp", Language: "dotnet", Project: "./src/Api", Uses: []string{"cache"}},
+		"cache":    {Host: "containerapp", Image: "docker.io/library/redis:7.4"},
+		"frontend": {Host: "containerapp", Language: "js", Project: "./frontend", Uses: []string{"api"}},
+		"web":      {Host: "containerapp", Language: "docker", Project: "./web", Docker: &DockerConfig{Path: "./Dockerfile.prod"}, Uses: []string{"api"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("AspireServices() =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestDiffAspire(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "src", "Api"), 0750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "src", "Api", "Api.csproj"), []byte("<Project />"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	azureYaml := &AzureYaml{Services: map[string]Service{
+		"app":      {Host: "containerapp", Language: "dotnet", Project: "./AppHost/AppHost.csproj"},
+		"backend":  {Host: "containerapp", Language: "csharp", Project: "./src/Api/Api.csproj", Env: []EnvVar{{Name: "LOG_LEVEL", Value: "debug"}}},
+		"frontend": {Host: "containerapp", Language: "ts", Project: "./frontend", Uses: []string{"api", "storage"}},
+		"web":      {Host: "containerapp", Language: "docker", Project: "./site"},
+		"legacy":   {Host: "containerapp", Language: "python", Project: "./legacy"},
+	}}
+
+	changes := DiffAspire(azureYaml, root, testAspireModel(root))
+	var got []string
+	for _, change := range changes {
+		got = append(got, change.Kind+":"+change.Service+":"+change.Resource+":"+strings.Join(change.Fields, ","))
+	}
+	want := []string{
+		"mismatch:backend:api:uses",
+		"mismatch:web:web:project,docker,uses",
+		"new-service:cache:cache:",
+		"not-in-apphost:legacy::",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DiffAspire() =\n%v\nwant\n%v", got, want)
+	}
+
+	ApplyAspire(azureYaml, changes)
+	if backend := azureYaml.Services["backend"]; !reflect.DeepEqual(backend.Uses, []string{"cache"}) || len(backend.Env) != 1 || backend.Project != "./src/Api/Api.csproj" {
+		t.Errorf("backend = %+v, want uses added and the rest kept", backend)
+	}
+	if web := azureYaml.Services["web"]; web.Project != "./web" || web.Docker == nil || web.Docker.Path != "./Dockerfile.prod" {
+		t.Errorf("web = %+v, want the project and Dockerfile of the resource", web)
+	}
+	if cache, ok := azureYaml.Services["cache"]; !ok || cache.Image != "docker.io/library/redis:7.4" {
+		t.Errorf("cache = %+v, want a service of the image", cache)
+	}
+	if _, ok := azureYaml.Services["legacy"]; !ok {
+		t.Error("legacy should be kept")
+	}
+	if changes := DiffAspire(azureYaml, root, testAspireModel(root)); len(changes) != 1 || changes[0].Kind != AspireNotInAppHost {
+		t.Errorf("DiffAspire() after ApplyAspire = %+v, want only legacy", changes)
+	}
+}
+
+func TestOrchestratedByAppHost(t *testing.T) {
+	root := t.TempDir()
+	services := map[string]Service{
+		"app":      {Host: "containerapp", Language: "dotnet", Project: "./AppHost"},
+		"backend":  {Host: "containerapp", Language: "dotnet", Project: "./src/Api/Api.csproj"},
+		"cache":    {Host: "containerapp", Image: "redis:7"},
+		"frontend": {Host: "containerapp", Language: "js", Project: "./frontend"},
+		"worker":   {Host: "containerapp", Language: "python", Project: "./worker"},
+	}
+	got := OrchestratedByAppHost(services, root, testAspireModel(root))
+	want := AspireOrchestration{AppHost: []string{"app"}, Orchestrated: []string{"backend", "cache", "frontend"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("OrchestratedByAppHost() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// ErrNoAzdEnvironment is returned by LoadAzdEnvironment for an environment that doesn't exist.
+var ErrNoAzdEnvironment = errors.New("azd environment not found")
+
+// AzdEnvironmentName returns the azd environment of the project in projectDir: name when set,
+// else AZURE_ENV_NAME, else the defaultEnvironment of .azure/config.json, which 'azd env
+// select' sets. It returns "" when the project has none.
+func AzdEnvironmentName(projectDir, name string) string {
+	if name != "" {
+		return name
+	}
+	if name := os.Getenv("AZURE_ENV_NAME"); name != "" {
+		return name
+	}
+	// #nosec G304 -- Path is the azd configuration of the project
+	data, err := os.ReadFile(filepath.Join(projectDir, ".azure", "config.json"))
+	if err != nil {
+		return ""
+	}
+	var config struct {
+		DefaultEnvironment string `json:"defaultEnvironment"`
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return ""
+	}
+	return config.DefaultEnvironment
+}
+
+// LoadAzdEnvironment loads the values of the azd environment name of the project in
+// projectDir from .azure/<name>/.env: the outputs of provisioning, such as endpoints,
+// connection strings, and resource names.
+func LoadAzdEnvironment(projectDir, name string) (map[string]string, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) || name == "." || name == ".." {
+		return nil, fmt.Errorf("invalid azd environment name %q", name)
+	}
+	path := filepath.Join(projectDir, ".azure", name, ".env")
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("%w: %s (create it with 'azd env new %s' or 'azd provision')", ErrNoAzdEnvironment, name, name)
+	}
+	return LoadDotEnv(path)
+}
+
+// ApplyAzdEnvironment sets the values of the azd environment each runtime gets: all of values,
+// and the variables its service maps to keys of values with azdEnv in azure.yaml. It returns a
+// warning for each mapped key missing from values, which provisioning hasn't set yet.
+func ApplyAzdEnvironment(runtimes []*ServiceRuntime, azureYaml *AzureYaml, values map[string]string) ([]string, error) {
+	var warnings []string
+	for _, rt := range runtimes {
+		env := maps.Clone(values)
+		if env == nil {
+			env = make(map[string]string)
+		}
+		if azureYaml != nil {
+			svc := azureYaml.Services[rt.Name]
+			for _, name := range slices.Sorted(maps.Keys(svc.AzdEnv)) {
+				key := svc.AzdEnv[name]
+				if !envNamePattern.MatchString(name) {
+					return nil, fmt.Errorf("services.%s.azdEnv: invalid variable name %q", rt.Name, name)
+				}
+				value, ok := values[key]
+				if !ok {
+					warnings = append(warnings, fmt.Sprintf("%s: %s is not set, since the azd environment has no %s", rt.Name, name, key))
+					continue
+				}
+				env[name] = value
+			}
+		}
+		rt.AzdEnv = env
+	}
+	return warnings, nil
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestAzdEnvironmentName(t *testing.T) {
+	projectDir := t.TempDir()
+	t.Setenv("AZURE_ENV_NAME", "")
+	if got := AzdEnvironmentName(projectDir, ""); got != "" {
+		t.Errorf("AzdEnvironmentName() without an environment = %q, want none", got)
+	}
+
+	if err := os.MkdirAll(filepath.Join(projectDir, ".azure"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(projectDir, ".azure", "config.json"), []byte(`{"version":1,"defaultEnvironment":"dev"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if got := AzdEnvironmentName(projectDir, ""); got != "dev" {
+		t.Errorf("AzdEnvironmentName() = %q, want the default environment dev", got)
+	}
+	t.Setenv("AZURE_ENV_NAME", "test")
+	if got := AzdEnvironmentName(projectDir, ""); got != "test" {
+		t.Errorf("AzdEnvironmentName() = %q, want test from AZURE_ENV_NAME", got)
+	}
+	if got := AzdEnvironmentName(projectDir, "prod"); got != "prod" {
+		t.Errorf("AzdEnvironmentName() = %q, want the name asked for", got)
+	}
+}
+
+func TestLoadAzdEnvironment(t *testing.T) {
+	projectDir := t.TempDir()
+	envDir := filepath.Join(projectDir, ".azure", "dev")
+	if err := os.MkdirAll(envDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	content := "AZURE_ENV_NAME=\"dev\"\nAZURE_POSTGRES_URL=\"postgres://db.example.com/app\"\n"
+	if err := os.WriteFile(filepath.Join(envDir, ".env"), []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	values, err := LoadAzdEnvironment(projectDir, "dev")
+	if err != nil {
+		t.Fatalf("LoadAzdEnvironment() error = %v", err)
+	}
+	if values["AZURE_POSTGRES_URL"] != "postgres://db.example.com/app" {
+		t.Errorf("values = %v", values)
+	}
+	if _, err := LoadAzdEnvironment(projectDir, "prod"); !errors.Is(err, ErrNoAzdEnvironment) {
+		t.Errorf("LoadAzdEnvironment() of a missing environment error = %v, want ErrNoAzdEnvironment", err)
+	}
+	if _, err := LoadAzdEnvironment(projectDir, "../dev"); err == nil {
+		t.Error("LoadAzdEnvironment() accepted a path as environment name")
+	}
+}
+
+func TestApplyAzdEnvironment(t *testing.T) {
+	values := map[string]string{"AZURE_POSTGRES_URL": "postgres://db", "AZURE_STORAGE_ENDPOINT": "https://st.blob"}
+	azureYaml := &AzureYaml{Services: map[string]Service{
+		"api": {AzdEnv: map[string]string{"DATABASE_URL": "AZURE_POSTGRES_URL", "REDIS_URL": "AZURE_REDIS_URL"}},
+	}}
+	api := &ServiceRuntime{Name: "api"}
+	web := &ServiceRuntime{Name: "web"}
+
+	warnings, err := ApplyAzdEnvironment([]*ServiceRuntime{api, web}, azureYaml, values)
+	if err != nil {
+		t.Fatalf("ApplyAzdEnvironment() error = %v", err)
+	}
+	want := map[string]string{"AZURE_POSTGRES_URL": "postgres://db", "AZURE_STORAGE_ENDPOINT": "https://st.blob", "DATABASE_URL": "postgres://db"}
+	if !reflect.DeepEqual(api.AzdEnv, want) {
+		t.Errorf("api AzdEnv = %v, want %v", api.AzdEnv, want)
+	}
+	if !reflect.DeepEqual(web.AzdEnv, values) {
+		t.Errorf("web AzdEnv = %v, want the values of the environment", web.AzdEnv)
+	}
+	if len(warnings) != 1 || warnings[0] != "api: REDIS_URL is not set, since the azd environment has no AZURE_REDIS_URL" {
+		t.Errorf("warnings = %q", warnings)
+	}
+
+	azureYaml.Services["api"] = Service{AzdEnv: map[string]string{"DATABASE-URL": "AZURE_POSTGRES_URL"}}
+	if _, err := ApplyAzdEnvironment([]*ServiceRuntime{api}, azureYaml, values); err == nil {
+		t.Error("ApplyAzdEnvironment() accepted an invalid variable name")
+	}
+}
@@ -0,0 +1,173 @@
+//go:build compat
+
+package service
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// compatManifestEnv names the environment variable that replaces the default list of templates.
+const compatManifestEnv = "AZD_APP_COMPAT_TEMPLATES"
+
+// compatTemplate is a gallery template that service detection is checked against.
+type compatTemplate struct {
+	Repo     string                   `yaml:"repo"` // GitHub owner/name, or the URL of a mirror
+	Ref      string                   `yaml:"ref,omitempty"`
+	Services map[string]compatService `yaml:"services,omitempty"` // Expected services; read from azure.yaml when empty
+	Ignore   []string                 `yaml:"ignore,omitempty"`
+}
+
+// compatService is a service detection must find.
+type compatService struct {
+	Project  string `yaml:"project"`  // Relative to the repository root
+	Language string `yaml:"language"` // azure.yaml language; py, ts, and csharp match python, js, and dotnet
+}
+
+// TestTemplateCompat clones real templates and checks that DetectServices finds the services
+// each declares, to catch regressions against real-world layouts before a release. It needs git
+// and network access, so it only builds with the compat tag.
+func TestTemplateCompat(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	manifest := filepath.Join("testdata", "compat", "templates.yaml")
+	if path := os.Getenv(compatManifestEnv); path != "" {
+		manifest = path
+	}
+	data, err := os.ReadFile(manifest)
+	if err != nil {
+		t.Fatalf("failed to read template list: %v", err)
+	}
+	var list struct {
+		Templates []compatTemplate `yaml:"templates"`
+	}
+	if err := yaml.Unmarshal(data, &list); err != nil {
+		t.Fatalf("failed to parse %s: %v", manifest, err)
+	}
+
+	for _, tmpl := range list.Templates {
+		t.Run(strings.ReplaceAll(tmpl.Repo, "/", "_"), func(t *testing.T) {
+			t.Parallel()
+			dir := cloneTemplate(t, tmpl)
+
+			want := tmpl.Services
+			if len(want) == 0 {
+				want = declaredServices(t, dir)
+			}
+			for _, name := range tmpl.Ignore {
+				delete(want, name)
+			}
+
+			detected, err := DetectServices(dir)
+			if err != nil {
+				t.Fatalf("DetectServices failed: %v", err)
+			}
+			byProject := make(map[string]DetectedService, len(detected))
+			for _, d := range detected {
+				byProject[normalizeProject(d.Project)] = d
+			}
+
+			for name, svc := range want {
+				d, ok := byProject[normalizeProject(svc.Project)]
+				if !ok {
+					t.Errorf("service %s (%s) was not detected; detected %v", name, svc.Project, detectedProjects(detected))
+					continue
+				}
+				if !hasLanguage(d.Languages, svc.Language) {
+					t.Errorf("service %s (%s) detected as %v, want %s", name, svc.Project, d.Languages, svc.Language)
+				}
+			}
+		})
+	}
+}
+
+// cloneTemplate makes a shallow clone of the template and returns its directory.
+func cloneTemplate(t *testing.T, tmpl compatTemplate) string {
+	t.Helper()
+	dir := t.TempDir()
+	args := []string{"clone", "--quiet", "--depth", "1"}
+	if tmpl.Ref != "" {
+		args = append(args, "--branch", tmpl.Ref)
+	}
+	url := tmpl.Repo
+	if !strings.Contains(url, "://") {
+		url = "https://github.com/" + url + ".git"
+	}
+	args = append(args, url, dir)
+	// #nosec G204 -- the repository comes from the template list of the test
+	if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+		t.Fatalf("failed to clone %s: %v\n%s", tmpl.Repo, err, out)
+	}
+	return dir
+}
+
+// declaredServices returns the services in the template's azure.yaml that have a project.
+func declaredServices(t *testing.T, dir string) map[string]compatService {
+	t.Helper()
+	azureYaml, err := ParseAzureYaml(dir)
+	if err != nil {
+		t.Fatalf("failed to read azure.yaml: %v", err)
+	}
+	services := make(map[string]compatService)
+	for name, svc := range azureYaml.Services {
+		if svc.Project == "" {
+			continue
+		}
+		project := svc.Project
+		// Some projects name a file, such as the .csproj of an Aspire AppHost
+		if info, err := os.Stat(project); err == nil && !info.IsDir() {
+			project = filepath.Dir(project)
+		}
+		rel, err := filepath.Rel(dir, project)
+		if err != nil {
+			t.Fatal(err)
+		}
+		services[name] = compatService{Project: rel, Language: svc.Language}
+	}
+	return services
+}
+
+// normalizeProject returns a project path relative to the repository root in a comparable form.
+func normalizeProject(project string) string {
+	return filepath.ToSlash(filepath.Clean(strings.TrimPrefix(filepath.FromSlash(project), "."+string(filepath.Separator))))
+}
+
+// hasLanguage reports whether the detected candidates include the language azure.yaml declares.
+// An empty language matches anything.
+func hasLanguage(candidates []string, language string) bool {
+	if language == "" {
+		return true
+	}
+	family := func(l string) string {
+		switch strings.ToLower(l) {
+		case "py", "python":
+			return "python"
+		case "js", "ts", "javascript", "typescript":
+			return "js"
+		case "csharp", "fsharp", "dotnet":
+			return "dotnet"
+		}
+		return strings.ToLower(l)
+	}
+	for _, candidate := range candidates {
+		if family(candidate) == family(language) {
+			return true
+		}
+	}
+	return false
+}
+
+// detectedProjects lists the projects of the detected services for failure messages.
+func detectedProjects(detected []DetectedService) []string {
+	projects := make([]string, 0, len(detected))
+	for _, d := range detected {
+		projects = append(projects, d.Project)
+	}
+	return projects
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jongio/azd-app/cli/src/internal/types"
+)
+
+// composeResourceTypes maps images of well-known backing services to azure.yaml resource types.
+var composeResourceTypes = map[string]string{
+	"postgres": "db.postgres",
+	"redis":    "db.redis",
+	"mongo":    "db.mongo",
+}
+
+// ComposeImport is a Docker Compose file mapped to azure.yaml services and resources.
+type ComposeImport struct {
+	Services  map[string]Service
+	Resources map[string]Resource
+	Skipped   map[string]string // Compose services that were not imported, with the reason
+	Graph     *DependencyGraph  // Dependencies of the imported services and resources
+}
+
+// ImportCompose maps the services of a Compose file to azure.yaml entries for a project whose
+// azure.yaml is in azureYamlDir. Services built from source become container app services with
+// their build context as project, Dockerfile and build arguments as docker settings, first
+// published port as config.port, environment as env, and depends_on as uses. Services that run a
+// postgres, redis, or mongo image become resources. Other prebuilt images are skipped, because
+// there is no source to run locally.
+func ImportCompose(project *types.ComposeProject, azureYamlDir string) (*ComposeImport, error) {
+	result := &ComposeImport{
+		Services:  make(map[string]Service),
+		Resources: make(map[string]Resource),
+		Skipped:   make(map[string]string),
+	}
+	composeDir := filepath.Dir(project.Path)
+
+	for _, cs := range project.Services {
+		switch {
+		case cs.BuildContext != "":
+			svc, err := composeService(cs, composeDir, azureYamlDir)
+			if err != nil {
+				return nil, err
+			}
+			result.Services[cs.Name] = svc
+		case cs.Image != "":
+			if resourceType := composeResourceTypes[imageName(cs.Image)]; resourceType != "" {
+				result.Resources[cs.Name] = Resource{Type: resourceType}
+			} else {
+				result.Skipped[cs.Name] = fmt.Sprintf("runs the prebuilt image %s", cs.Image)
+			}
+		default:
+			result.Skipped[cs.Name] = "has neither build nor image"
+		}
+	}
+
+	// Dependencies on skipped services are dropped
+	for name, svc := range result.Services {
+		var uses []string
+		for _, dep := range svc.Uses {
+			if _, ok := result.Skipped[dep]; !ok {
+				uses = append(uses, dep)
+			}
+		}
+		svc.Uses = uses
+		result.Services[name] = svc
+	}
+
+	graph, err := BuildDependencyGraph(result.Services, result.Resources)
+	if err != nil {
+		return nil, fmt.Errorf("invalid depends_on in %s: %w", filepath.Base(project.Path), err)
+	}
+	result.Graph = graph
+	return result, nil
+}
+
+// composeService maps a Compose service with a build section to an azure.yaml service.
+func composeService(cs types.ComposeService, composeDir, azureYamlDir string) (Service, error) {
+	buildDir := filepath.Join(composeDir, filepath.FromSlash(cs.BuildContext))
+	rel, err := filepath.Rel(azureYamlDir, buildDir)
+	if err != nil {
+		return Service{}, fmt.Errorf("build context of %s is not under %s: %w", cs.Name, azureYamlDir, err)
+	}
+
+	svc := Service{
+		Host:    "containerapp",
+		Project: relativeProjectPath(rel),
+		Uses:    cs.DependsOn,
+	}
+
+	// The docker path is relative to the project, like the Compose dockerfile is to the build context
+	docker := &DockerConfig{BuildArgs: cs.BuildArgs}
+	if dockerfile := path.Clean(filepath.ToSlash(cs.Dockerfile)); cs.Dockerfile != "" && dockerfile != "Dockerfile" {
+		docker.Path = relativeProjectPath(dockerfile)
+	}
+	if docker.Path != "" || len(docker.BuildArgs) > 0 {
+		svc.Docker = docker
+	}
+
+	if port := composeServicePort(cs.Ports); port > 0 {
+		svc.Config = map[string]interface{}{"port": port}
+	}
+
+	names := make([]string, 0, len(cs.Environment))
+	for name := range cs.Environment {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		svc.Env = append(svc.Env, EnvVar{Name: name, Value: cs.Environment[name]})
+	}
+	return svc, nil
+}
+
+// composeServicePort returns the first published host port, or else the first container port.
+func composeServicePort(ports []types.ComposePort) int {
+	for _, port := range ports {
+		if port.Host > 0 {
+			return port.Host
+		}
+	}
+	if len(ports) > 0 {
+		return ports[0].Container
+	}
+	return 0
+}
+
+// relativeProjectPath formats a relative path the way azure.yaml writes it, e.g. "./src/api".
+func relativeProjectPath(rel string) string {
+	rel = filepath.ToSlash(filepath.Clean(rel))
+	if rel == "." || strings.HasPrefix(rel, "../") {
+		return rel
+	}
+	return "./" + rel
+}
+
+// imageName returns the repository name of an image without registry, namespace, tag, or digest,
+// e.g. "postgres" for "docker.io/library/postgres:16-alpine".
+func imageName(image string) string {
+	image, _, _ = strings.Cut(image, "@")
+	name := path.Base(image)
+	name, _, _ = strings.Cut(name, ":")
+	return strings.ToLower(name)
+}
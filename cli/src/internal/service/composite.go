@@ -0,0 +1,117 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jongio/azd-app/cli/src/internal/types"
+)
+
+// Archetype is one kind of project found in the directory of a composite project: a language,
+// and the files it was recognized by.
+type Archetype struct {
+	Language  string   `json:"language"`
+	Framework string   `json:"framework,omitempty"`
+	Files     []string `json:"files"` // Relative to the project directory
+}
+
+// archetypePatterns are the files each language is recognized by.
+var archetypePatterns = map[string][]string{
+	"dotnet": {"*.csproj", "*.fsproj", "*.vbproj"},
+	"python": {"requirements.txt", "pyproject.toml", "Pipfile", "setup.py"},
+	"js":     {"package.json"},
+	"ts":     {"package.json", "tsconfig.json"},
+	"docker": {"Dockerfile", "Containerfile"},
+}
+
+// nodeStartScripts are the package.json scripts that run an app rather than tooling.
+var nodeStartScripts = []string{"start", "dev", "serve"}
+
+// Reasons a language of a composite project is suggested.
+const (
+	reasonDockerfile = "its Dockerfile builds from a %s image"
+	reasonTooling    = "package.json has no start, dev, or serve script, so it only holds tooling"
+	reasonToolingAnd = reasonTooling + ", and nothing tells the other languages apart"
+	reasonFirst      = "nothing tells its languages apart"
+)
+
+// archetypes returns the archetype of each language of the project in dir.
+func archetypes(dir string, languages []string, frameworks map[string]string) []Archetype {
+	result := make([]Archetype, 0, len(languages))
+	for _, language := range languages {
+		archetype := Archetype{Language: language, Framework: frameworks[language], Files: []string{}}
+		for _, pattern := range archetypePatterns[language] {
+			matches, _ := filepath.Glob(filepath.Join(dir, pattern))
+			sort.Strings(matches)
+			for _, match := range matches {
+				archetype.Files = append(archetype.Files, filepath.Base(match))
+			}
+		}
+		result = append(result, archetype)
+	}
+	return result
+}
+
+// rankLanguages returns the languages of the project in dir, suggested first, and for a project
+// of several languages, why: the language its Dockerfile builds from, else one other than
+// Node.js when package.json only holds tooling, such as a CSS build.
+func rankLanguages(dir string, languages []string, dockerfile *types.Dockerfile) ([]string, string) {
+	if len(languages) < 2 {
+		return languages, ""
+	}
+	if dockerfile != nil {
+		image := strings.ToLower(dockerfile.BaseImage)
+		for i, language := range languages {
+			if hint := baseImageHints[language]; hint != "" && strings.Contains(image, hint) {
+				return moveToFront(languages, i), fmt.Sprintf(reasonDockerfile, hint)
+			}
+		}
+	}
+	if nodeToolingOnly(dir) {
+		var others []int
+		for i, language := range languages {
+			if language != "js" && language != "ts" {
+				others = append(others, i)
+			}
+		}
+		switch {
+		case len(others) == 1:
+			return moveToFront(languages, others[0]), reasonTooling
+		case len(others) > 1:
+			return moveToFront(languages, others[0]), reasonToolingAnd
+		}
+	}
+	return languages, reasonFirst
+}
+
+// moveToFront returns languages with the one at i first.
+func moveToFront(languages []string, i int) []string {
+	return append([]string{languages[i]}, append(append([]string{}, languages[:i]...), languages[i+1:]...)...)
+}
+
+// nodeToolingOnly reports whether the package.json in dir has no script that runs an app and no
+// main module, as when it only builds assets for a project of another language.
+func nodeToolingOnly(dir string) bool {
+	// #nosec G304 -- package.json of a detected project directory
+	data, err := os.ReadFile(filepath.Join(dir, "package.json"))
+	if err != nil {
+		return false
+	}
+	var pkg struct {
+		Main    string            `json:"main"`
+		Scripts map[string]string `json:"scripts"`
+	}
+	if json.Unmarshal(data, &pkg) != nil || pkg.Main != "" {
+		return false
+	}
+	for _, script := range nodeStartScripts {
+		if pkg.Scripts[script] != "" {
+			return false
+		}
+	}
+	return true
+}
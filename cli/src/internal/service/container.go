@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jongio/azd-app/cli/src/internal/container"
+)
+
+// Runners of services, set with runner in azure.yaml.
+const (
+	RunnerNative = "native" // The command of the framework of the service, on this machine
+	RunnerDocker = "docker" // A container of the image built from the Dockerfile of the service
+)
+
+// localHostPattern matches the addresses of this machine in variables, which a container
+// reaches at the host alias of its engine instead.
+var localHostPattern = regexp.MustCompile(`\b(localhost|127\.0\.0\.1)\b`)
+
+// ContainerRun runs a service as a container of the image built from its Dockerfile.
+type ContainerRun struct {
+	Engine        container.Engine
+	Image         string // Tag of the image built for the service, e.g. azd-app-shop-api
+	Name          string // Name of the container
+	Dockerfile    string
+	Context       string   // Build context
+	BuildArgs     []string // Build arguments as NAME=value
+	ContainerPort int      // Port the service listens on in the container
+}
+
+// ApplyRunners makes the runtimes of services with runner: docker in azure.yaml run as
+// containers built from their Dockerfile, on the configured container engine. Such services
+// don't run in the sandbox, since they run in a container already.
+func ApplyRunners(runtimes []*ServiceRuntime, azureYaml *AzureYaml) error {
+	if azureYaml == nil {
+		return nil
+	}
+	var engine *container.Engine
+	for _, rt := range runtimes {
+		svc, ok := azureYaml.Services[rt.Name]
+		if !ok {
+			continue
+		}
+		switch svc.Runner {
+		case "", RunnerNative:
+			continue
+		case RunnerDocker:
+		default:
+			return fmt.Errorf("services.%s.runner: must be '%s' or '%s', not %q", rt.Name, RunnerNative, RunnerDocker, svc.Runner)
+		}
+		if rt.Dockerfile == nil {
+			return fmt.Errorf("service %s has runner: %s but no Dockerfile; add one to %s or set docker.path", rt.Name, RunnerDocker, rt.WorkingDir)
+		}
+		if engine == nil {
+			e, err := container.Current()
+			if err != nil {
+				return err
+			}
+			engine = &e
+		}
+
+		run := &ContainerRun{
+			Engine:        *engine,
+			Image:         containerImageName(azureYaml.Name, rt.Name),
+			Dockerfile:    rt.Dockerfile.Path,
+			Context:       rt.WorkingDir,
+			ContainerPort: rt.Port,
+		}
+		run.Name = run.Image
+		if len(rt.Dockerfile.ExposedPorts) > 0 {
+			run.ContainerPort = rt.Dockerfile.ExposedPorts[0]
+		}
+		if svc.Docker != nil {
+			if svc.Docker.Context != "" {
+				run.Context = filepath.Join(rt.WorkingDir, svc.Docker.Context)
+			}
+			run.BuildArgs = svc.Docker.BuildArgs
+		}
+		rt.Container = run
+		rt.Sandbox = nil
+		rt.Command = engine.Name
+		rt.Args = []string{"run", run.Image}
+	}
+	return nil
+}
+
+// containerImageName returns the image name of service of project: azd-app-<project>-<service>,
+// lowercase, with characters images can't have replaced by '-'.
+func containerImageName(project, service string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower("azd-app-" + project + "-" + service) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '.' || r == '_' || r == '-' {
+			b.WriteRune(r)
+		} else {
+			b.WriteRune('-')
+		}
+	}
+	return strings.Trim(b.String(), "-._")
+}
+
+// build builds the image of the service, so that each start runs its current source.
+func (c *ContainerRun) build(ctx context.Context) error {
+	args := []string{"build", "--file", c.Dockerfile, "--tag", c.Image}
+	for _, arg := range c.BuildArgs {
+		args = append(args, "--build-arg", arg)
+	}
+	out, err := c.Engine.Command(ctx, append(args, c.Context)...).CombinedOutput()
+	if err != nil {
+		lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+		if len(lines) > 20 {
+			lines = lines[len(lines)-20:]
+		}
+		return fmt.Errorf("%s build failed: %w\n%s", c.Engine.Name, err, strings.Join(lines, "\n"))
+	}
+	return nil
+}
+
+// runArgs returns the arguments of the run command of the engine that runs the container in
+// the foreground, with ContainerPort published on hostPort and env as its variables, and those
+// variables as NAME=value for the environment of the engine command: the arguments only name
+// them, so that secrets aren't visible in the process list. Addresses of this machine in env
+// are replaced by the host alias of the engine, and PORT is set to the port the service listens
+// on in the container.
+func (c *ContainerRun) runArgs(hostPort int, env map[string]string) (args, vars []string) {
+	args = []string{"run", "--rm", "--init", "--name", c.Name}
+	hostAlias := "host.containers.internal" // Podman defines it
+	if c.Engine.Name == container.EngineDocker {
+		hostAlias = "host.docker.internal"
+		// Docker Desktop defines it; Docker on Linux needs it added
+		args = append(args, "--add-host", hostAlias+":host-gateway")
+	}
+	if hostPort > 0 && c.ContainerPort > 0 {
+		args = append(args, "--publish", fmt.Sprintf("%s:%d:%d", c.Engine.PublishAddress(), hostPort, c.ContainerPort))
+	}
+
+	values := make(map[string]string, len(env)+1)
+	for k, v := range env {
+		values[k] = localHostPattern.ReplaceAllString(v, hostAlias)
+	}
+	if c.ContainerPort > 0 {
+		values["PORT"] = strconv.Itoa(c.ContainerPort)
+	}
+	names := make([]string, 0, len(values))
+	for k := range values {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	for _, k := range names {
+		args = append(args, "--env", k)
+		vars = append(vars, k+"="+values[k])
+	}
+	return append(args, c.Image), vars
+}
+
+// remove stops and removes the container, which may outlive the engine CLI that started it.
+func (c *ContainerRun) remove() {
+	_ = c.Engine.Command(context.Background(), "rm", "--force", c.Name).Run()
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/jongio/azd-app/cli/src/internal/container"
+	"github.com/jongio/azd-app/cli/src/internal/types"
+)
+
+func TestApplyRunners(t *testing.T) {
+	t.Setenv(container.EngineEnv, container.EngineDocker)
+	azureYaml := &AzureYaml{
+		Name: "Shop",
+		Services: map[string]Service{
+			"api": {Runner: RunnerDocker, Docker: &DockerConfig{Context: "..", BuildArgs: []string{"VERSION=1"}}},
+			"web": {Runner: RunnerNative},
+		},
+	}
+	api := &ServiceRuntime{Name: "api", WorkingDir: "/w/api", Port: 5000, Dockerfile: &types.Dockerfile{Path: "/w/api/Dockerfile", ExposedPorts: []int{8080}}}
+	web := &ServiceRuntime{Name: "web", WorkingDir: "/w/web", Port: 3000, Command: "npm"}
+	if err := ApplyRunners([]*ServiceRuntime{api, web}, azureYaml); err != nil {
+		t.Fatalf("ApplyRunners() error = %v", err)
+	}
+
+	if web.Container != nil || web.Command != "npm" {
+		t.Errorf("native service web was changed: %+v", web)
+	}
+	c := api.Container
+	if c == nil {
+		t.Fatal("api has no container")
+	}
+	if c.Image != "azd-app-shop-api" || c.Context != "/w" || c.ContainerPort != 8080 || !slices.Equal(c.BuildArgs, []string{"VERSION=1"}) {
+		t.Errorf("api container = %+v", c)
+	}
+}
+
+func TestApplyRunnersErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		runner  string
+		wantErr string
+	}{
+		{name: "invalid", runner: "vm", wantErr: "must be"},
+		{name: "no Dockerfile", runner: RunnerDocker, wantErr: "no Dockerfile"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			azureYaml := &AzureYaml{Services: map[string]Service{"api": {Runner: tt.runner}}}
+			err := ApplyRunners([]*ServiceRuntime{{Name: "api"}}, azureYaml)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ApplyRunners() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestContainerRunArgs(t *testing.T) {
+	c := &ContainerRun{Engine: container.Engine{Name: container.EngineDocker}, Image: "azd-app-shop-api", Name: "azd-app-shop-api", ContainerPort: 8080}
+	args, vars := c.runArgs(5000, map[string]string{
+		"DATABASE_URL": "postgres://localhost:5432/shop",
+		"CACHE":        "127.0.0.1:6379",
+		"PORT":         "5000",
+		"NAME":         "localhostname",
+	})
+
+	want := []string{
+		"run", "--rm", "--init", "--name", "azd-app-shop-api",
+		"--add-host", "host.docker.internal:host-gateway",
+		"--publish", "127.0.0.1:5000:8080",
+		"--env", "CACHE",
+		"--env", "DATABASE_URL",
+		"--env", "NAME",
+		"--env", "PORT",
+		"azd-app-shop-api",
+	}
+	if !slices.Equal(args, want) {
+		t.Errorf("runArgs() =\n%v\nwant\n%v", args, want)
+	}
+	// Values are only in the environment of the engine command, not in its arguments
+	wantVars := []string{
+		"CACHE=host.docker.internal:6379",
+		"DATABASE_URL=postgres://host.docker.internal:5432/shop",
+		"NAME=localhostname",
+		"PORT=8080",
+	}
+	if !slices.Equal(vars, wantVars) {
+		t.Errorf("runArgs() vars =\n%v\nwant\n%v", vars, wantVars)
+	}
+
+	c.Engine.Name = container.EnginePodman
+	args, vars = c.runArgs(5000, map[string]string{"API": "http://localhost:7000"})
+	if slices.Contains(args, "--add-host") || !slices.Contains(vars, "API=http://host.containers.internal:7000") {
+		t.Errorf("podman runArgs() = %v, %v", args, vars)
+	}
+}
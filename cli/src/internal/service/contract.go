@@ -0,0 +1,164 @@
+package service
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/jongio/azd-app/cli/src/internal/security"
+	"github.com/jongio/azd-app/cli/src/internal/workerpool"
+)
+
+// envNamePattern matches the names of environment variables a contract may require.
+var envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// EnvRequirement is a variable a service needs to start, and where its value is expected to
+// come from.
+type EnvRequirement struct {
+	Name   string
+	Source string // e.g. "azd env", or "listed in src/api/.env.example"
+}
+
+// MissingEnvError is the error of a service whose resolved environment lacks variables its
+// contract requires.
+type MissingEnvError struct {
+	Missing []EnvRequirement
+}
+
+func (e *MissingEnvError) Error() string {
+	names := make([]string, len(e.Missing))
+	for i, req := range e.Missing {
+		names[i] = fmt.Sprintf("%s (%s)", req.Name, req.Source)
+	}
+	return "missing " + strings.Join(names, ", ")
+}
+
+// ApplyEnvContracts sets the variables each runtime requires from the requiredEnv list and the
+// envContract file of its service in azure.yaml. Contract files are read relative to the
+// project of the service, in projectDir.
+func ApplyEnvContracts(runtimes []*ServiceRuntime, azureYaml *AzureYaml, projectDir string) error {
+	if azureYaml == nil {
+		return nil
+	}
+	for _, rt := range runtimes {
+		svc, ok := azureYaml.Services[rt.Name]
+		if !ok {
+			continue
+		}
+		path := "services." + rt.Name
+		var reqs []EnvRequirement
+		seen := make(map[string]bool)
+		add := func(name, source string) {
+			if !seen[name] {
+				seen[name] = true
+				reqs = append(reqs, EnvRequirement{Name: name, Source: source})
+			}
+		}
+
+		for i, v := range svc.RequiredEnv {
+			if !envNamePattern.MatchString(v.Name) {
+				return fmt.Errorf("%s.requiredEnv[%d]: invalid variable name %q", path, i, v.Name)
+			}
+			source := v.Source
+			if source == "" {
+				source = "requiredEnv in azure.yaml"
+			}
+			add(v.Name, "expected from "+source)
+		}
+
+		if svc.EnvContract != "" {
+			serviceDir := GetServiceProjectDir(svc, projectDir)
+			if !filepath.IsAbs(serviceDir) {
+				serviceDir = filepath.Join(projectDir, serviceDir)
+			}
+			file := filepath.Join(serviceDir, svc.EnvContract)
+			names, err := readEnvContract(file)
+			if err != nil {
+				return fmt.Errorf("%s.envContract: %w", path, err)
+			}
+			display := file
+			if rel, err := filepath.Rel(projectDir, file); err == nil {
+				display = filepath.ToSlash(rel)
+			}
+			for _, name := range names {
+				add(name, "listed in "+display)
+			}
+		}
+		rt.RequiredEnv = reqs
+	}
+	return nil
+}
+
+// readEnvContract returns the names of the variables a contract file lists. It takes the form
+// of a .env.example file: a variable per line, with or without an example value, and
+// comments starting with #.
+func readEnvContract(path string) ([]string, error) {
+	if err := security.ValidatePath(path); err != nil {
+		return nil, fmt.Errorf("invalid contract file path: %w", err)
+	}
+	// #nosec G304 -- Path validated by security.ValidatePath
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open contract file: %w", err)
+	}
+	defer file.Close()
+
+	var names []string
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		name, _, _ := strings.Cut(strings.TrimPrefix(text, "export "), "=")
+		name = strings.TrimSpace(name)
+		if !envNamePattern.MatchString(name) {
+			return nil, fmt.Errorf("%s:%d: invalid variable name %q", path, line, name)
+		}
+		names = append(names, name)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading contract file: %w", err)
+	}
+	return names, nil
+}
+
+// missingEnv returns the variables rt requires that are empty or unset in env, the resolved
+// environment of the service, and in the environment of this process, which services inherit.
+// Variables set to outputs of other services are set once those services print them.
+func missingEnv(rt *ServiceRuntime, env map[string]string) []EnvRequirement {
+	var missing []EnvRequirement
+	for _, req := range rt.RequiredEnv {
+		if slices.ContainsFunc(rt.Inputs, func(input OutputBinding) bool { return input.Env == req.Name }) {
+			continue
+		}
+		if env[req.Name] == "" && os.Getenv(req.Name) == "" {
+			missing = append(missing, req)
+		}
+	}
+	return missing
+}
+
+// verifyEnvContracts returns an error when the resolved environment of any runtime, from
+// baseEnv and the variables of the runtime, lacks variables it requires. The error is a
+// *workerpool.Error with a *MissingEnvError for each such runtime; the other runtimes fail as
+// not started.
+func verifyEnvContracts(runtimes []*ServiceRuntime, baseEnv map[string]string) error {
+	var failed, notStarted []*workerpool.TaskError
+	for _, rt := range runtimes {
+		if missing := missingEnv(rt, serviceEnvironment(baseEnv, rt)); len(missing) > 0 {
+			failed = append(failed, &workerpool.TaskError{Name: rt.Name, Err: &MissingEnvError{Missing: missing}})
+		} else {
+			notStarted = append(notStarted, &workerpool.TaskError{Name: rt.Name, Err: fmt.Errorf("not started: another service is missing required environment variables")})
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+	err := &workerpool.Error{Total: len(runtimes), Failed: append(failed, notStarted...)}
+	return fmt.Errorf("%d of %d services are missing required environment variables: %w", len(failed), len(runtimes), err)
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jongio/azd-app/cli/src/internal/workerpool"
+)
+
+func TestApplyEnvContracts(t *testing.T) {
+	projectDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(projectDir, "api"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	contract := "# Connection to the database\nDATABASE_URL=postgres://localhost/app\nexport REDIS_URL=\n\nAPI_KEY\n"
+	if err := os.WriteFile(filepath.Join(projectDir, "api", ".env.example"), []byte(contract), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	rt := &ServiceRuntime{Name: "api"}
+	azureYaml := &AzureYaml{Services: map[string]Service{"api": {
+		Project:     "./api",
+		EnvContract: ".env.example",
+		RequiredEnv: []RequiredEnvVar{{Name: "API_KEY", Source: "Key Vault"}, {Name: "LOG_LEVEL"}},
+	}}}
+	if err := ApplyEnvContracts([]*ServiceRuntime{rt}, azureYaml, projectDir); err != nil {
+		t.Fatalf("ApplyEnvContracts() error = %v", err)
+	}
+
+	want := []EnvRequirement{
+		{Name: "API_KEY", Source: "expected from Key Vault"},
+		{Name: "LOG_LEVEL", Source: "expected from requiredEnv in azure.yaml"},
+		{Name: "DATABASE_URL", Source: "listed in api/.env.example"},
+		{Name: "REDIS_URL", Source: "listed in api/.env.example"},
+	}
+	if len(rt.RequiredEnv) != len(want) {
+		t.Fatalf("RequiredEnv = %+v, want %+v", rt.RequiredEnv, want)
+	}
+	for i := range want {
+		if rt.RequiredEnv[i] != want[i] {
+			t.Errorf("RequiredEnv[%d] = %+v, want %+v", i, rt.RequiredEnv[i], want[i])
+		}
+	}
+}
+
+func TestApplyEnvContractsErrors(t *testing.T) {
+	projectDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(projectDir, "bad.env"), []byte("API-KEY=1\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		svc     Service
+		wantErr string
+	}{
+		{name: "invalid name", svc: Service{RequiredEnv: []RequiredEnvVar{{Name: "API KEY"}}}, wantErr: "services.api.requiredEnv[0]"},
+		{name: "missing file", svc: Service{EnvContract: "missing.env"}, wantErr: "services.api.envContract"},
+		{name: "invalid name in file", svc: Service{EnvContract: "bad.env"}, wantErr: `bad.env:1: invalid variable name "API-KEY"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			azureYaml := &AzureYaml{Services: map[string]Service{"api": tt.svc}}
+			err := ApplyEnvContracts([]*ServiceRuntime{{Name: "api"}}, azureYaml, projectDir)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ApplyEnvContracts() error = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVerifyEnvContracts(t *testing.T) {
+	t.Setenv("AZD_APP_TEST_FROM_OS", "1")
+	api := &ServiceRuntime{Name: "api", Env: map[string]string{"FROM_RUNTIME": "1"}, RequiredEnv: []EnvRequirement{
+		{Name: "FROM_ENV_FILE", Source: "expected from .env"},
+		{Name: "FROM_RUNTIME", Source: "expected from requiredEnv in azure.yaml"},
+		{Name: "AZD_APP_TEST_FROM_OS", Source: "expected from requiredEnv in azure.yaml"},
+	}}
+	web := &ServiceRuntime{Name: "web", RequiredEnv: []EnvRequirement{
+		{Name: "API_KEY", Source: "expected from Key Vault"},
+		{Name: "EMPTY", Source: "listed in web/.env.example"},
+	}}
+	worker := &ServiceRuntime{Name: "worker"}
+
+	if err := verifyEnvContracts([]*ServiceRuntime{api, worker}, map[string]string{"FROM_ENV_FILE": "x"}); err != nil {
+		t.Fatalf("verifyEnvContracts() of satisfied contracts error = %v", err)
+	}
+
+	err := verifyEnvContracts([]*ServiceRuntime{api, web, worker}, map[string]string{"FROM_ENV_FILE": "x", "EMPTY": ""})
+	if err == nil {
+		t.Fatal("verifyEnvContracts() succeeded, want an error for web")
+	}
+	if !strings.HasPrefix(err.Error(), "1 of 3 services are missing") {
+		t.Errorf("error = %q", err)
+	}
+	failed := workerpool.Errors(err)
+	if len(failed) != 3 || failed[0].Name != "web" {
+		t.Fatalf("failed = %v, want web first, then the services not started", failed)
+	}
+	var missing *MissingEnvError
+	if !errors.As(failed[0].Err, &missing) || len(missing.Missing) != 2 {
+		t.Fatalf("web error = %v, want API_KEY and EMPTY missing", failed[0].Err)
+	}
+	if got := failed[0].Err.Error(); got != "missing API_KEY (expected from Key Vault), EMPTY (listed in web/.env.example)" {
+		t.Errorf("web error = %q", got)
+	}
+}
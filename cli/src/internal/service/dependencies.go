@@ -0,0 +1,310 @@
+package service
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/jongio/azd-app/cli/src/internal/detector"
+	"github.com/jongio/azd-app/cli/src/internal/security"
+)
+
+// Where a dependency was found.
+const (
+	DependencySourceAzureYaml = "azure.yaml" // uses, dependsOn, or inputs of a service, or uses of a resource
+	DependencySourceAppHost   = "apphost"    // WithReference or WaitFor in the Aspire AppHost
+	DependencySourceEnv       = "env"        // An environment variable refers to the other service
+)
+
+// Dependency is an edge of the service graph: From starts after To.
+type Dependency struct {
+	From    string `json:"from"`
+	To      string `json:"to"`
+	Source  string `json:"source"`            // Where the dependency was found, a DependencySource constant
+	Detail  string `json:"detail,omitempty"`  // What declared it, e.g. the environment variable
+	Skipped string `json:"skipped,omitempty"` // Why it is not part of the graph
+}
+
+// serviceVarSuffixes are the suffixes of the SERVICE_<NAME>_* variables azd sets for a service.
+var serviceVarSuffixes = []string{"URL", "ENDPOINT", "ENDPOINTS", "NAME", "IMAGE_NAME"}
+
+var (
+	// urlHostPattern captures the host of a URL, e.g. api in http://user@api:8080/path.
+	urlHostPattern = regexp.MustCompile(`[A-Za-z][A-Za-z0-9+.-]*://(?:[^@/\s]*@)?([A-Za-z0-9.-]+)`)
+	// connectionHostPattern captures the host of a connection string, e.g. db in Host=db;Port=5432.
+	connectionHostPattern = regexp.MustCompile(`(?i)(?:^|;)\s*(?:host|server|data source|endpoint|address|addr)\s*=\s*(?:tcp:)?([A-Za-z0-9.-]+)`)
+	// hostPortPattern matches a plain host:port value, e.g. cache:6379.
+	hostPortPattern = regexp.MustCompile(`^([A-Za-z][A-Za-z0-9.-]*):\d+$`)
+
+	// appHostCommentPattern matches whole-line comments of the AppHost.
+	appHostCommentPattern = regexp.MustCompile(`(?m)^\s*//.*$`)
+	// appHostVarPattern captures the variable and name of a resource added to the AppHost,
+	// e.g. api and "api" in var api = builder.AddProject<Projects.Api>("api").
+	appHostVarPattern = regexp.MustCompile(`^\s*(?:var|[A-Za-z_][\w<>.]*)\s+(\w+)\s*=\s*builder\s*\.\s*Add\w*(?:<[^>]*>)?\s*\(\s*"([^"]+)"`)
+	// appHostAddPattern captures the name of a resource added to the AppHost.
+	appHostAddPattern = regexp.MustCompile(`builder\s*\.\s*Add\w*(?:<[^>]*>)?\s*\(\s*"([^"]+)"`)
+	// appHostRefPattern captures a reference from one AppHost resource to the variable of another.
+	appHostRefPattern = regexp.MustCompile(`\.\s*(WithReference|WaitFor|WaitForCompletion)\s*\(\s*(\w+)\s*\)`)
+)
+
+// DiscoverDependencies returns the dependencies between the services and resources of
+// azureYaml, from uses and dependsOn in azure.yaml, references in the Aspire AppHost under
+// projectDir, and environment variables in azure.yaml and the .env file of each service that
+// refer to another service by its SERVICE_<NAME>_* variables, URL host, or connection string
+// host. Dependencies on names that azure.yaml does not define are only reported for uses and
+// dependsOn. Each edge is reported once, from the first source above that declares it.
+func DiscoverDependencies(azureYaml *AzureYaml, projectDir string) []Dependency {
+	known := make(map[string]bool, len(azureYaml.Services)+len(azureYaml.Resources))
+	for name := range azureYaml.Services {
+		known[name] = true
+	}
+	for name := range azureYaml.Resources {
+		known[name] = true
+	}
+
+	var deps []Dependency
+	seen := make(map[[2]string]bool)
+	add := func(dep Dependency) {
+		key := [2]string{dep.From, dep.To}
+		if dep.From == dep.To || seen[key] {
+			return
+		}
+		seen[key] = true
+		deps = append(deps, dep)
+	}
+
+	for _, name := range sortedNames(azureYaml.Services) {
+		svc := azureYaml.Services[name]
+		for _, to := range svc.Uses {
+			add(Dependency{From: name, To: to, Source: DependencySourceAzureYaml, Detail: "uses"})
+		}
+		for _, to := range svc.DependsOn {
+			add(Dependency{From: name, To: to, Source: DependencySourceAzureYaml, Detail: "dependsOn"})
+		}
+		for _, env := range slices.Sorted(maps.Keys(svc.Inputs)) {
+			if to, _, ok := strings.Cut(svc.Inputs[env], "."); ok {
+				add(Dependency{From: name, To: to, Source: DependencySourceAzureYaml, Detail: "inputs"})
+			}
+		}
+	}
+	for _, name := range sortedNames(azureYaml.Resources) {
+		for _, to := range azureYaml.Resources[name].Uses {
+			add(Dependency{From: name, To: to, Source: DependencySourceAzureYaml, Detail: "uses"})
+		}
+	}
+
+	for _, dep := range appHostDependencies(projectDir) {
+		if known[dep.From] && known[dep.To] {
+			add(dep)
+		}
+	}
+
+	for _, name := range sortedNames(azureYaml.Services) {
+		for _, dep := range envDependencies(name, azureYaml.Services[name], projectDir, known) {
+			add(dep)
+		}
+	}
+	return deps
+}
+
+// BuildServiceGraph builds the dependency graph of the services and resources of azureYaml
+// from the dependencies found by DiscoverDependencies, which are returned as well. Declared
+// dependencies that form a cycle or name an unknown service are errors; discovered ones that
+// would form a cycle are left out of the graph and marked as skipped.
+func BuildServiceGraph(azureYaml *AzureYaml, projectDir string) (*DependencyGraph, []Dependency, error) {
+	deps := DiscoverDependencies(azureYaml, projectDir)
+
+	edges := make(map[string][]string)
+	for i, dep := range deps {
+		if dep.Source != DependencySourceAzureYaml && reaches(edges, dep.To, dep.From) {
+			deps[i].Skipped = "would create a dependency cycle"
+			continue
+		}
+		edges[dep.From] = append(edges[dep.From], dep.To)
+	}
+
+	services := make(map[string]Service, len(azureYaml.Services))
+	for name, svc := range azureYaml.Services {
+		svc.Uses = edges[name]
+		services[name] = svc
+	}
+	resources := make(map[string]Resource, len(azureYaml.Resources))
+	for name, res := range azureYaml.Resources {
+		res.Uses = edges[name]
+		resources[name] = res
+	}
+
+	graph, err := BuildDependencyGraph(services, resources)
+	if err != nil {
+		return nil, nil, err
+	}
+	return graph, deps, nil
+}
+
+// reaches reports whether to can be reached from from by following edges.
+func reaches(edges map[string][]string, from, to string) bool {
+	visited := make(map[string]bool)
+	var visit func(string) bool
+	visit = func(name string) bool {
+		if name == to {
+			return true
+		}
+		if visited[name] {
+			return false
+		}
+		visited[name] = true
+		for _, next := range edges[name] {
+			if visit(next) {
+				return true
+			}
+		}
+		return false
+	}
+	return visit(from)
+}
+
+// appHostDependencies returns the references between the resources of the Aspire AppHost
+// under projectDir, if there is one.
+func appHostDependencies(projectDir string) []Dependency {
+	appHost, err := detector.FindAppHost(projectDir)
+	if err != nil || appHost == nil {
+		return nil
+	}
+	paths, _ := filepath.Glob(filepath.Join(appHost.Dir, "*.cs"))
+	sort.Strings(paths)
+
+	var deps []Dependency
+	for _, path := range paths {
+		if err := security.ValidatePath(path); err != nil {
+			continue
+		}
+		// #nosec G304 -- Path validated by security.ValidatePath
+		data, err := os.ReadFile(path)
+		if err != nil || !strings.Contains(string(data), "DistributedApplication") {
+			continue
+		}
+		deps = append(deps, parseAppHostReferences(string(data))...)
+	}
+	return deps
+}
+
+// parseAppHostReferences returns the WithReference, WaitFor, and WaitForCompletion references
+// between the resources added to an AppHost, identified by their resource names.
+func parseAppHostReferences(source string) []Dependency {
+	source = appHostCommentPattern.ReplaceAllString(source, "")
+
+	var deps []Dependency
+	vars := make(map[string]string)
+	for _, statement := range strings.Split(source, ";") {
+		from := ""
+		if m := appHostVarPattern.FindStringSubmatch(statement); m != nil {
+			vars[m[1]], from = m[2], m[2]
+		} else if m := appHostAddPattern.FindStringSubmatch(statement); m != nil {
+			from = m[1]
+		}
+		if from == "" {
+			continue
+		}
+		for _, ref := range appHostRefPattern.FindAllStringSubmatch(statement, -1) {
+			if to, ok := vars[ref[2]]; ok {
+				deps = append(deps, Dependency{From: from, To: to, Source: DependencySourceAppHost, Detail: ref[1] + "(" + ref[2] + ")"})
+			}
+		}
+	}
+	return deps
+}
+
+// envDependencies returns the dependencies of a service on the known services and resources
+// its environment variables refer to, from azure.yaml and the .env file of its project.
+func envDependencies(name string, svc Service, projectDir string, known map[string]bool) []Dependency {
+	type envVar struct{ name, value, detail string }
+	var vars []envVar
+	for _, env := range svc.Env {
+		vars = append(vars, envVar{env.Name, env.Value, env.Name})
+	}
+	serviceDir := GetServiceProjectDir(svc, projectDir)
+	if !filepath.IsAbs(serviceDir) {
+		serviceDir = filepath.Join(projectDir, serviceDir)
+	}
+	if dotEnv, err := LoadDotEnv(filepath.Join(serviceDir, ".env")); err == nil {
+		for _, key := range sortedNames(dotEnv) {
+			vars = append(vars, envVar{key, dotEnv[key], key + " (.env)"})
+		}
+	}
+
+	var deps []Dependency
+	for _, v := range vars {
+		for _, target := range envReferences(v.name, v.value, known) {
+			if target != name {
+				deps = append(deps, Dependency{From: name, To: target, Source: DependencySourceEnv, Detail: v.detail})
+			}
+		}
+	}
+	return deps
+}
+
+// envReferences returns the known names, sorted, that an environment variable refers to.
+func envReferences(name, value string, known map[string]bool) []string {
+	found := make(map[string]bool)
+	for target := range known {
+		prefix := "SERVICE_" + strings.ToUpper(strings.ReplaceAll(target, "-", "_")) + "_"
+		for _, suffix := range serviceVarSuffixes {
+			if containsWord(name, prefix+suffix) || containsWord(value, prefix+suffix) {
+				found[target] = true
+			}
+		}
+	}
+
+	var hosts []string
+	for _, m := range urlHostPattern.FindAllStringSubmatch(value, -1) {
+		hosts = append(hosts, m[1])
+	}
+	for _, m := range connectionHostPattern.FindAllStringSubmatch(value, -1) {
+		hosts = append(hosts, m[1])
+	}
+	if m := hostPortPattern.FindStringSubmatch(strings.TrimSpace(value)); m != nil {
+		hosts = append(hosts, m[1])
+	}
+	for _, host := range hosts {
+		for target := range known {
+			if strings.EqualFold(host, target) {
+				found[target] = true
+			}
+		}
+	}
+	return sortedNames(found)
+}
+
+// containsWord reports whether s contains word, not as part of a longer identifier.
+func containsWord(s, word string) bool {
+	for offset := 0; ; {
+		i := strings.Index(s[offset:], word)
+		if i < 0 {
+			return false
+		}
+		start, end := offset+i, offset+i+len(word)
+		if (start == 0 || !isIdentChar(s[start-1])) && (end == len(s) || !isIdentChar(s[end])) {
+			return true
+		}
+		offset = start + 1
+	}
+}
+
+// isIdentChar reports whether c can be part of an environment variable name.
+func isIdentChar(c byte) bool {
+	return c == '_' || c >= '0' && c <= '9' || c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z'
+}
+
+// sortedNames returns the keys of m, sorted.
+func sortedNames[V any](m map[string]V) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const testAppHost = `var builder = DistributedApplication.CreateBuilder(args);
+
+var api = builder.AddProject<Projects.Api>("api");
+// builder.AddProject<Projects.Old>("old").WithReference(api);
+builder.AddProject<Projects.Worker>("worker")
+    .WithReference(api)
+    .WaitFor(api);
+var admin = builder.AddProject<Projects.Admin>("admin").WithReference(api);
+
+builder.Build().Run();
+`
+
+func TestBuildServiceGraph(t *testing.T) {
+	dir := t.TempDir()
+	for path, content := range map[string]string{
+		"api/.env":               "REDIS=cache:6379\nWORKER=${SERVICE_WORKER_URL}\n",
+		"apphost/AppHost.csproj": "<Project Sdk=\"Aspire.AppHost.Sdk/9.0.0\"></Project>",
+		"apphost/Program.cs":     testAppHost,
+	} {
+		path = filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	azureYaml := &AzureYaml{
+		Services: map[string]Service{
+			"api":    {Project: "./api", Uses: []string{"db"}},
+			"web":    {Project: "./web", Env: []EnvVar{{Name: "API_URL", Value: "http://api:8080"}}},
+			"worker": {Project: "./worker", DependsOn: []string{"db"}},
+		},
+		Resources: map[string]Resource{
+			"db":    {Type: "db.postgres"},
+			"cache": {Type: "db.redis"},
+		},
+	}
+
+	graph, deps, err := BuildServiceGraph(azureYaml, dir)
+	if err != nil {
+		t.Fatalf("BuildServiceGraph() error = %v", err)
+	}
+
+	want := []Dependency{
+		{From: "api", To: "db", Source: DependencySourceAzureYaml, Detail: "uses"},
+		{From: "worker", To: "db", Source: DependencySourceAzureYaml, Detail: "dependsOn"},
+		{From: "worker", To: "api", Source: DependencySourceAppHost, Detail: "WithReference(api)"},
+		{From: "api", To: "cache", Source: DependencySourceEnv, Detail: "REDIS (.env)"},
+		{From: "api", To: "worker", Source: DependencySourceEnv, Detail: "WORKER (.env)", Skipped: "would create a dependency cycle"},
+		{From: "web", To: "api", Source: DependencySourceEnv, Detail: "API_URL"},
+	}
+	if !reflect.DeepEqual(deps, want) {
+		t.Errorf("dependencies =\n%+v\nwant\n%+v", deps, want)
+	}
+
+	wantOrder := [][]string{{"api"}, {"web", "worker"}}
+	if order := TopologicalSort(graph); !reflect.DeepEqual(order, wantOrder) {
+		t.Errorf("TopologicalSort() = %v, want %v", order, wantOrder)
+	}
+}
+
+func TestBuildServiceGraphDeclaredErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		services map[string]Service
+	}{
+		{name: "unknown dependency", services: map[string]Service{"web": {DependsOn: []string{"missing"}}}},
+		{name: "declared cycle", services: map[string]Service{
+			"a": {Uses: []string{"b"}},
+			"b": {DependsOn: []string{"a"}},
+		}},
+		{name: "cycle through inputs", services: map[string]Service{
+			"a": {Inputs: map[string]string{"B_KEY": "b.key"}},
+			"b": {DependsOn: []string{"a"}},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := BuildServiceGraph(&AzureYaml{Services: tt.services}, t.TempDir()); err == nil {
+				t.Error("BuildServiceGraph() should fail")
+			}
+		})
+	}
+}
+
+func TestEnvReferences(t *testing.T) {
+	known := map[string]bool{"api": true, "api-gateway": true, "db": true, "cache": true}
+	tests := []struct {
+		name  string
+		key   string
+		value string
+		want  []string
+	}{
+		{name: "service variable name", key: "SERVICE_API_URL", value: "", want: []string{"api"}},
+		{name: "service variable in value", key: "BACKEND", value: "${SERVICE_API_GATEWAY_ENDPOINT}", want: []string{"api-gateway"}},
+		{name: "longer service variable", key: "SERVICE_API_GATEWAY_URL", value: "", want: []string{"api-gateway"}},
+		{name: "url host", key: "API", value: "https://user@API:8443/v1", want: []string{"api"}},
+		{name: "connection string", key: "DB", value: "Server=tcp:db,1433;Database=app", want: []string{"db"}},
+		{name: "host and port", key: "REDIS", value: "cache:6379", want: []string{"cache"}},
+		{name: "other host", key: "API", value: "https://api.contoso.com", want: []string{}},
+		{name: "plain value", key: "MODE", value: "db", want: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := envReferences(tt.key, tt.value, known); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("envReferences(%q, %q) = %v, want %v", tt.key, tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStartLevels(t *testing.T) {
+	runtimes := []*ServiceRuntime{
+		{Name: "web", DependsOn: []string{"api"}},
+		{Name: "api", DependsOn: []string{"db"}},
+		{Name: "worker", DependsOn: []string{"db", "running"}},
+		{Name: "admin", DependsOn: []string{"api", "worker"}},
+	}
+
+	var got [][]string
+	for _, level := range startLevels(runtimes) {
+		var names []string
+		for _, rt := range level {
+			names = append(names, rt.Name)
+		}
+		got = append(got, names)
+	}
+	want := [][]string{{"api", "worker"}, {"admin", "web"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("startLevels() = %v, want %v", got, want)
+	}
+}
+
+func TestOrchestrateServicesStopsWithContext(t *testing.T) {
+	t.Chdir(t.TempDir())
+	t.Setenv("AZD_APP_CACHE_DIR", t.TempDir())
+
+	deadline := errors.New("deadline of 1s exceeded")
+	ctx, cancel := context.WithCancelCause(context.Background())
+	cancel(deadline)
+
+	runtimes := []*ServiceRuntime{{Name: "api", Command: "sleep", Args: []string{"60"}, WorkingDir: t.TempDir()}}
+	result, err := OrchestrateServices(ctx, runtimes, nil, NewServiceLogger(false))
+	if err == nil || !errors.Is(err, deadline) {
+		t.Fatalf("OrchestrateServices() error = %v, want the cause of the canceled context", err)
+	}
+	if len(result.Processes) != 0 {
+		t.Errorf("services were started after the context was done: %v", result.Processes)
+	}
+}
@@ -3,17 +3,32 @@ package service
 import (
 	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
-	"time"
 
 	"github.com/jongio/azd-app/cli/src/internal/detector"
 	"github.com/jongio/azd-app/cli/src/internal/portmanager"
+	"github.com/jongio/azd-app/cli/src/internal/retry"
 	"github.com/jongio/azd-app/cli/src/internal/security"
+	"github.com/jongio/azd-app/cli/src/internal/types"
 )
 
 // DetectServiceRuntime determines how to run a service based on its configuration and project structure.
 func DetectServiceRuntime(serviceName string, service Service, usedPorts map[int]bool, azureYamlDir string, runtimeMode string) (*ServiceRuntime, error) {
+	return detectServiceRuntime(serviceName, service, usedPorts, azureYamlDir, runtimeMode, true)
+}
+
+// PreviewServiceRuntime determines how a service would be run like DetectServiceRuntime, but
+// uses the service's preferred port instead of assigning one, so nothing is reserved, stopped,
+// or prompted for. Used to show commands before they run.
+func PreviewServiceRuntime(serviceName string, service Service, azureYamlDir string, runtimeMode string) (*ServiceRuntime, error) {
+	return detectServiceRuntime(serviceName, service, make(map[int]bool), azureYamlDir, runtimeMode, false)
+}
+
+// detectServiceRuntime implements DetectServiceRuntime. The port is only assigned by the port
+// manager when assignPort is set.
+func detectServiceRuntime(serviceName string, service Service, usedPorts map[int]bool, azureYamlDir string, runtimeMode string, assignPort bool) (*ServiceRuntime, error) {
 	projectDir := service.Project
 	if projectDir == "" {
 		return nil, fmt.Errorf("service %s has no project directory", serviceName)
@@ -38,10 +53,9 @@ func DetectServiceRuntime(serviceName string, service Service, usedPorts map[int
 		Protocol:   "http",
 		Env:        make(map[string]string),
 		HealthCheck: HealthCheckConfig{
-			Type:     "http",
-			Path:     "/",
-			Timeout:  60 * time.Second,
-			Interval: 2 * time.Second,
+			Type:  "http",
+			Path:  "/",
+			Retry: retry.DefaultHealthCheck,
 		},
 	}
 
@@ -64,29 +78,60 @@ func DetectServiceRuntime(serviceName string, service Service, usedPorts map[int
 	runtime.Framework = framework
 	runtime.PackageManager = packageManager
 
+	// Function apps run under the Functions host, whatever their language
+	if service.Host == "function" && detector.DetectFunctionProject(projectDir) != nil {
+		framework = "Azure Functions"
+		runtime.Framework = framework
+	}
+
 	// Detect preferred port from config (and whether it's explicitly set in azure.yaml)
 	preferredPort, isExplicit, _ := DetectPort(serviceName, service, projectDir, framework, usedPorts)
 
 	// Use port manager to assign port (with automatic cleanup of stale processes)
-	portMgr := portmanager.GetPortManager(projectDir)
-	port, err := portMgr.AssignPort(serviceName, preferredPort, isExplicit, true) // isExplicit, cleanStale
-	if err != nil {
-		return nil, fmt.Errorf("failed to assign port: %w", err)
+	port := preferredPort
+	if assignPort {
+		portMgr := portmanager.GetPortManager(projectDir)
+		port, err = portMgr.AssignPort(serviceName, preferredPort, isExplicit, true) // isExplicit, cleanStale
+		if err != nil {
+			return nil, fmt.Errorf("failed to assign port: %w", err)
+		}
 	}
 	runtime.Port = port
 	usedPorts[port] = true
 
 	// Build command and args based on framework (AFTER port assignment)
-	if err := buildRunCommand(runtime, projectDir, service.Entrypoint, runtimeMode); err != nil {
+	if err := buildRunCommand(runtime, projectDir, service.Entrypoint, runtimeMode, LaunchProfileName(service)); err != nil {
 		return nil, fmt.Errorf("failed to build run command: %w", err)
 	}
+	if runtime.Language == "Python" {
+		wrapPythonCommand(runtime)
+	}
 
 	// Set health check configuration based on framework
 	configureHealthCheck(runtime)
 
+	runtime.Dockerfile = detectServiceDockerfile(projectDir, service.Docker)
+
 	return runtime, nil
 }
 
+// detectServiceDockerfile returns the Dockerfile of a service: the docker.path from azure.yaml,
+// relative to the project directory, or else a Dockerfile or Containerfile in the project directory.
+func detectServiceDockerfile(projectDir string, docker *DockerConfig) *types.Dockerfile {
+	if docker != nil && docker.Path != "" {
+		path := docker.Path
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(projectDir, path)
+		}
+		dockerfile, err := detector.ParseDockerfile(filepath.Clean(path))
+		if err != nil {
+			return nil
+		}
+		return dockerfile
+	}
+	return detector.DetectDockerfile(projectDir)
+}
+
 // detectLanguage determines the programming language used by the service.
 func detectLanguage(projectDir string, host string) (string, error) {
 	// Check for language indicators in priority order
@@ -101,13 +146,15 @@ func detectLanguage(projectDir string, host string) (string, error) {
 
 	// Python
 	if fileExists(projectDir, "requirements.txt") || fileExists(projectDir, "pyproject.toml") ||
-		fileExists(projectDir, "poetry.lock") || fileExists(projectDir, "uv.lock") {
+		fileExists(projectDir, "poetry.lock") || fileExists(projectDir, "uv.lock") ||
+		fileExists(projectDir, "Pipfile") || fileExists(projectDir, "setup.py") {
 		return "Python", nil
 	}
 
 	// .NET
 	if hasFileWithExt(projectDir, ".csproj") || hasFileWithExt(projectDir, ".sln") ||
-		hasFileWithExt(projectDir, ".fsproj") {
+		hasFileWithExt(projectDir, ".slnx") || hasFileWithExt(projectDir, ".fsproj") ||
+		hasFileWithExt(projectDir, ".vbproj") {
 		return ".NET", nil
 	}
 
@@ -133,7 +180,8 @@ func detectLanguage(projectDir string, host string) (string, error) {
 	}
 
 	// Docker
-	if fileExists(projectDir, "Dockerfile") || fileExists(projectDir, "docker-compose.yml") {
+	if fileExists(projectDir, "Dockerfile") || fileExists(projectDir, "Containerfile") ||
+		fileExists(projectDir, "docker-compose.yml") {
 		return "Docker", nil
 	}
 
@@ -171,80 +219,13 @@ func detectFrameworkAndPackageManager(projectDir string, language string) (strin
 
 // detectNodeFramework detects Node.js/TypeScript framework.
 func detectNodeFramework(projectDir string) (string, string, error) {
-	// Detect package manager
 	packageManager := detector.DetectNodePackageManagerWithBoundary(projectDir, projectDir)
-
-	// Detect framework
-	if fileExists(projectDir, "next.config.js") || fileExists(projectDir, "next.config.ts") ||
-		fileExists(projectDir, "next.config.mjs") {
-		return "Next.js", packageManager, nil
-	}
-
-	if fileExists(projectDir, "angular.json") {
-		return "Angular", packageManager, nil
-	}
-
-	if fileExists(projectDir, "nuxt.config.ts") || fileExists(projectDir, "nuxt.config.js") {
-		return "Nuxt", packageManager, nil
-	}
-
-	if fileExists(projectDir, "vite.config.ts") || fileExists(projectDir, "vite.config.js") {
-		return "React", packageManager, nil // Vite is commonly used with React
-	}
-
-	if fileExists(projectDir, "svelte.config.js") {
-		return "SvelteKit", packageManager, nil
-	}
-
-	if fileExists(projectDir, "remix.config.js") {
-		return "Remix", packageManager, nil
-	}
-
-	if fileExists(projectDir, "astro.config.mjs") {
-		return "Astro", packageManager, nil
-	}
-
-	if fileExists(projectDir, "nest-cli.json") {
-		return "NestJS", packageManager, nil
-	}
-
-	// Check package.json for framework hints
-	if framework := detectFrameworkFromPackageJSON(projectDir); framework != "" {
-		return framework, packageManager, nil
-	}
-
-	// Default to generic Node.js
-	return "Node.js", packageManager, nil
+	return detector.DetectNodeFramework(projectDir), packageManager, nil
 }
 
 // detectPythonFramework detects Python framework.
 func detectPythonFramework(projectDir string) (string, string, error) {
-	// Detect package manager
-	packageManager := detector.DetectPythonPackageManager(projectDir)
-
-	// Detect framework
-	if fileExists(projectDir, "manage.py") {
-		return "Django", packageManager, nil
-	}
-
-	if containsImport(projectDir, "FastAPI") {
-		return "FastAPI", packageManager, nil
-	}
-
-	if containsImport(projectDir, "Flask") {
-		return "Flask", packageManager, nil
-	}
-
-	if containsImport(projectDir, "streamlit") {
-		return "Streamlit", packageManager, nil
-	}
-
-	if containsImport(projectDir, "gradio") {
-		return "Gradio", packageManager, nil
-	}
-
-	// Default to generic Python
-	return "Python", packageManager, nil
+	return detector.DetectPythonFramework(projectDir), detector.DetectPythonPackageManager(projectDir), nil
 }
 
 // detectDotNetFramework detects .NET framework.
@@ -254,14 +235,13 @@ func detectDotNetFramework(projectDir string) (string, string, error) {
 		return "Aspire", "dotnet", nil
 	}
 
-	// Check for ASP.NET Core
-	if hasFileWithExt(projectDir, ".csproj") {
-		// Read csproj to detect Web SDK
-		csprojFiles, _ := filepath.Glob(filepath.Join(projectDir, "*.csproj"))
-		for _, csprojFile := range csprojFiles {
-			if containsText(csprojFile, "Microsoft.NET.Sdk.Web") {
-				return "ASP.NET Core", "dotnet", nil
-			}
+	// Check the project SDKs for an Aspire AppHost or ASP.NET Core
+	if project := findRunnableDotnetProject(projectDir); project != nil {
+		if project.IsAppHost {
+			return "Aspire", "dotnet", nil
+		}
+		if project.IsWeb() {
+			return "ASP.NET Core", "dotnet", nil
 		}
 	}
 
@@ -269,6 +249,41 @@ func detectDotNetFramework(projectDir string) (string, string, error) {
 	return ".NET", "dotnet", nil
 }
 
+// findRunnableDotnetProject returns the project file in projectDir to run: an Aspire AppHost,
+// then a web project, then any other runnable project, falling back to the first project file
+// when none can be identified as runnable. Returns nil if there is no project file.
+func findRunnableDotnetProject(projectDir string) *types.DotnetProject {
+	projectFiles := detector.FindDotnetProjectFiles(projectDir)
+	if len(projectFiles) == 0 {
+		return nil
+	}
+
+	var best *types.DotnetProject
+	bestRank := 0
+	for _, path := range projectFiles {
+		project, err := detector.ParseDotnetProject(path)
+		if err != nil {
+			continue
+		}
+		rank := 0
+		switch {
+		case project.IsAppHost:
+			rank = 3
+		case project.IsRunnable() && project.IsWeb():
+			rank = 2
+		case project.IsRunnable():
+			rank = 1
+		}
+		if best == nil || rank > bestRank {
+			best, bestRank = &project, rank
+		}
+	}
+	if best == nil {
+		best = &types.DotnetProject{Path: projectFiles[0]}
+	}
+	return best
+}
+
 // detectJavaFramework detects Java framework.
 func detectJavaFramework(projectDir string) (string, string, error) {
 	packageManager := "maven"
@@ -305,11 +320,32 @@ func detectPHPFramework(projectDir string) (string, string, error) {
 	return "PHP", "composer", nil
 }
 
+// wrapPythonCommand runs a Python command through the project's package manager
+// (uv run, poetry run, pipenv run) so it uses the managed environment.
+// pip projects and package managers that are not installed are left unchanged.
+func wrapPythonCommand(runtime *ServiceRuntime) {
+	switch runtime.PackageManager {
+	case "uv", "poetry", "pipenv":
+	default:
+		return
+	}
+	if runtime.Command == "" || runtime.Command == runtime.PackageManager {
+		return
+	}
+	if _, err := exec.LookPath(runtime.PackageManager); err != nil {
+		return
+	}
+
+	runtime.Args = append([]string{"run", runtime.Command}, runtime.Args...)
+	runtime.Command = runtime.PackageManager
+}
+
 // buildRunCommand builds the command and arguments to run the service.
 // If entrypoint is provided (from azure.yaml), it takes precedence over auto-detection.
-func buildRunCommand(runtime *ServiceRuntime, projectDir string, entrypoint string, runtimeMode string) error {
+// A .NET service runs with the launch profile named launchProfile, when set.
+func buildRunCommand(runtime *ServiceRuntime, projectDir string, entrypoint string, runtimeMode string, launchProfile string) error {
 	switch runtime.Framework {
-	case "Next.js", "React", "Vue", "Svelte", "SvelteKit", "Remix", "Astro", "Nuxt":
+	case "Next.js", "Vite", "React", "Vue", "Svelte", "SvelteKit", "Remix", "Astro", "Nuxt":
 		runtime.Command = runtime.PackageManager
 		runtime.Args = []string{"run", "dev"}
 
@@ -340,78 +376,91 @@ func buildRunCommand(runtime *ServiceRuntime, projectDir string, entrypoint stri
 		}
 
 	case "Django":
-		runtime.Command = "python"
-		runtime.Args = []string{"manage.py", "runserver", fmt.Sprintf("0.0.0.0:%d", runtime.Port)}
+		ep := detector.DetectPythonEntrypoint(projectDir, "Django")
+		switch {
+		case ep == nil || ep.File == "manage.py":
+			runtime.Command = "python"
+			runtime.Args = []string{"manage.py", "runserver", fmt.Sprintf("0.0.0.0:%d", runtime.Port)}
+		case strings.HasSuffix(ep.File, "asgi.py"):
+			runtime.Command = "uvicorn"
+			runtime.Args = []string{ep.Target(), "--reload", "--host", "0.0.0.0", "--port", fmt.Sprintf("%d", runtime.Port)}
+		default:
+			runtime.Command = "gunicorn"
+			runtime.Args = []string{ep.Target(), "--reload", "--bind", fmt.Sprintf("0.0.0.0:%d", runtime.Port)}
+		}
 
 	case "FastAPI":
 		runtime.Command = "uvicorn"
-		// Use entrypoint if provided, otherwise find the app file
-		appFile := entrypoint
-		if appFile == "" {
-			appFile = findPythonAppFile(projectDir)
-		}
-		// Validate that the entrypoint file exists
-		if err := validatePythonEntrypoint(projectDir, appFile); err != nil {
-			return err
+		// Use entrypoint if provided, otherwise detect the module and app object
+		target := ""
+		if entrypoint != "" {
+			if err := validatePythonEntrypoint(projectDir, entrypoint); err != nil {
+				return err
+			}
+			target = entrypoint + ":app"
+		} else if ep := detector.DetectPythonEntrypoint(projectDir, "FastAPI"); ep != nil {
+			target = ep.Target()
+		} else {
+			appFile := findPythonAppFile(projectDir)
+			if err := validatePythonEntrypoint(projectDir, appFile); err != nil {
+				return err
+			}
+			target = appFile + ":app"
 		}
-		runtime.Args = []string{appFile + ":app", "--reload", "--host", "0.0.0.0", "--port", fmt.Sprintf("%d", runtime.Port)}
+		runtime.Args = []string{target, "--reload", "--host", "0.0.0.0", "--port", fmt.Sprintf("%d", runtime.Port)}
 
 	case "Flask":
 		runtime.Command = "python"
 		runtime.Args = []string{"-m", "flask", "run", "--host", "0.0.0.0", "--port", fmt.Sprintf("%d", runtime.Port)}
-		// Use entrypoint if provided, otherwise find the app file
-		var appFile string
+		// Use entrypoint if provided, otherwise detect the module and app object
+		var flaskApp string
 		if entrypoint != "" {
-			appFile = entrypoint
-			runtime.Env["FLASK_APP"] = entrypoint
+			if err := validatePythonEntrypoint(projectDir, entrypoint); err != nil {
+				return err
+			}
+			flaskApp = entrypoint
+		} else if ep := detector.DetectPythonEntrypoint(projectDir, "Flask"); ep != nil {
+			// Flask finds an object named "app" on its own
+			flaskApp = ep.Module
+			if ep.App != "app" {
+				flaskApp = ep.Target()
+			}
 		} else {
-			appFile = findPythonAppFile(projectDir)
-			runtime.Env["FLASK_APP"] = appFile
-		}
-		// Validate that the entrypoint file exists
-		if err := validatePythonEntrypoint(projectDir, appFile); err != nil {
-			return err
+			flaskApp = findPythonAppFile(projectDir)
+			if err := validatePythonEntrypoint(projectDir, flaskApp); err != nil {
+				return err
+			}
 		}
+		runtime.Env["FLASK_APP"] = flaskApp
 		runtime.Env["FLASK_ENV"] = "development"
 
 	case "Streamlit":
 		runtime.Command = "streamlit"
-		// Use entrypoint if provided, otherwise find the app file
-		appFile := entrypoint
-		if appFile == "" {
-			appFile = findPythonAppFile(projectDir)
-		}
-		// Validate that the entrypoint file exists
-		if err := validatePythonEntrypoint(projectDir, appFile); err != nil {
+		appFile, err := resolvePythonScript(projectDir, entrypoint, "Streamlit")
+		if err != nil {
 			return err
 		}
-		runtime.Args = []string{"run", appFile + ".py", "--server.port", fmt.Sprintf("%d", runtime.Port)}
+		runtime.Args = []string{"run", appFile, "--server.port", fmt.Sprintf("%d", runtime.Port)}
 
-	case "Python":
+	case "Python", "Gradio":
 		runtime.Command = "python"
-		// Use entrypoint if provided, otherwise find the app file
-		appFile := entrypoint
-		if appFile == "" {
-			appFile = findPythonAppFile(projectDir)
-		}
-		// Validate that the entrypoint file exists
-		if err := validatePythonEntrypoint(projectDir, appFile); err != nil {
+		appFile, err := resolvePythonScript(projectDir, entrypoint, runtime.Framework)
+		if err != nil {
 			return err
 		}
-		runtime.Args = []string{appFile + ".py"}
+		runtime.Args = []string{appFile}
 
 	case "Aspire":
 		runtime.Command = "dotnet"
 		// Find AppHost.csproj
-		csprojFiles, _ := filepath.Glob(filepath.Join(projectDir, "*.csproj"))
-		if len(csprojFiles) > 0 {
+		if project := findRunnableDotnetProject(projectDir); project != nil {
 			// In aspire mode, use dotnet run to get native Aspire dashboard
 			// In azd mode, run individual services separately
 			if runtimeMode == "aspire" {
-				runtime.Args = []string{"run", "--project", csprojFiles[0]}
+				runtime.Args = []string{"run", "--project", project.Path}
 			} else {
 				// In azd mode, we run services individually, not the AppHost
-				runtime.Args = []string{"run", "--project", csprojFiles[0], "--no-launch-profile"}
+				runtime.Args = []string{"run", "--project", project.Path, "--no-launch-profile"}
 			}
 		} else {
 			runtime.Args = []string{"run"}
@@ -419,13 +468,28 @@ func buildRunCommand(runtime *ServiceRuntime, projectDir string, entrypoint stri
 
 	case "ASP.NET Core", ".NET":
 		runtime.Command = "dotnet"
-		// Find .csproj file
-		csprojFiles, _ := filepath.Glob(filepath.Join(projectDir, "*.csproj"))
-		if len(csprojFiles) > 0 {
-			runtime.Args = []string{"run", "--project", csprojFiles[0]}
+		// Find the project file, skipping class libraries and test projects
+		project := findRunnableDotnetProject(projectDir)
+		if project != nil {
+			runtime.Args = []string{"run", "--project", project.Path}
 		} else {
 			runtime.Args = []string{"run"}
 		}
+		if launchProfile != "" {
+			var profiles []types.LaunchProfile
+			if project != nil {
+				profiles = project.LaunchProfiles
+			}
+			if _, err := detector.FindLaunchProfile(profiles, launchProfile); err != nil {
+				return err
+			}
+			runtime.Args = append(runtime.Args, "--launch-profile", launchProfile)
+		}
+
+	case "Azure Functions":
+		// func start builds .NET apps and loads the language worker from FUNCTIONS_WORKER_RUNTIME
+		runtime.Command = "func"
+		runtime.Args = []string{"start", "--port", fmt.Sprintf("%d", runtime.Port)}
 
 	case "Spring Boot":
 		if runtime.PackageManager == "maven" {
@@ -519,49 +583,32 @@ func containsText(filePath string, text string) bool {
 	return strings.Contains(string(data), text)
 }
 
-func containsImport(projectDir string, importName string) bool {
-	// Check common Python entry points
-	for _, filename := range []string{"main.py", "app.py", "src/main.py", "src/app.py"} {
-		filePath := filepath.Join(projectDir, filename)
-		if containsText(filePath, importName) {
-			return true
-		}
+func hasScript(projectDir string, scriptName string) bool {
+	packageJSONPath := filepath.Join(projectDir, "package.json")
+	if containsText(packageJSONPath, fmt.Sprintf(`"%s"`, scriptName)) {
+		return true
 	}
 	return false
 }
 
-func detectFrameworkFromPackageJSON(projectDir string) string {
-	packageJSONPath := filepath.Join(projectDir, "package.json")
-	if err := security.ValidatePath(packageJSONPath); err != nil {
-		return ""
-	}
-
-	// #nosec G304 -- Path validated by security.ValidatePath
-	data, err := os.ReadFile(packageJSONPath)
-	if err != nil {
-		return ""
+// resolvePythonScript returns the script file to run for a Python project: the azure.yaml
+// entrypoint if provided, otherwise the detected entry file.
+func resolvePythonScript(projectDir string, entrypoint string, framework string) (string, error) {
+	if entrypoint == "" {
+		if ep := detector.DetectPythonEntrypoint(projectDir, framework); ep != nil {
+			return filepath.FromSlash(ep.File), nil
+		}
 	}
 
-	content := string(data)
-	if strings.Contains(content, "\"react\"") {
-		return "React"
-	}
-	if strings.Contains(content, "\"vue\"") {
-		return "Vue"
+	appFile := entrypoint
+	if appFile == "" {
+		appFile = findPythonAppFile(projectDir)
 	}
-	if strings.Contains(content, "\"express\"") {
-		return "Express"
+	// Validate that the entrypoint file exists
+	if err := validatePythonEntrypoint(projectDir, appFile); err != nil {
+		return "", err
 	}
-
-	return ""
-}
-
-func hasScript(projectDir string, scriptName string) bool {
-	packageJSONPath := filepath.Join(projectDir, "package.json")
-	if containsText(packageJSONPath, fmt.Sprintf(`"%s"`, scriptName)) {
-		return true
-	}
-	return false
+	return appFile + ".py", nil
 }
 
 func findPythonAppFile(projectDir string) string {
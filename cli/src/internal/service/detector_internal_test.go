@@ -0,0 +1,75 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestWrapPythonCommand(t *testing.T) {
+	binDir := t.TempDir()
+	name := "uv"
+	if runtime.GOOS == "windows" {
+		name = "uv.exe"
+	}
+	if err := os.WriteFile(filepath.Join(binDir, name), []byte("#!/bin/sh\n"), 0700); err != nil {
+		t.Fatalf("failed to write fake uv: %v", err)
+	}
+	t.Setenv("PATH", binDir)
+
+	tests := []struct {
+		name           string
+		packageManager string
+		wantCommand    string
+		wantArgs       []string
+	}{
+		{"uv installed", "uv", "uv", []string{"run", "uvicorn", "main:app"}},
+		{"poetry not installed", "poetry", "uvicorn", []string{"main:app"}},
+		{"pip unchanged", "pip", "uvicorn", []string{"main:app"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rt := &ServiceRuntime{Command: "uvicorn", Args: []string{"main:app"}, PackageManager: tt.packageManager}
+			wrapPythonCommand(rt)
+			if rt.Command != tt.wantCommand || strings.Join(rt.Args, " ") != strings.Join(tt.wantArgs, " ") {
+				t.Errorf("got %s %v, want %s %v", rt.Command, rt.Args, tt.wantCommand, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestFindRunnableDotnetProject(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	write("A.Shared.csproj", `<Project Sdk="Microsoft.NET.Sdk"><PropertyGroup><TargetFramework>net8.0</TargetFramework></PropertyGroup></Project>`)
+	write("B.Tests.csproj", `<Project Sdk="Microsoft.NET.Sdk"><PropertyGroup><OutputType>Exe</OutputType><IsTestProject>true</IsTestProject></PropertyGroup></Project>`)
+	if project := findRunnableDotnetProject(dir); project == nil || filepath.Base(project.Path) != "A.Shared.csproj" {
+		t.Errorf("expected fallback to first project, got %+v", project)
+	}
+
+	write("C.Worker.csproj", `<Project Sdk="Microsoft.NET.Sdk.Worker"></Project>`)
+	if project := findRunnableDotnetProject(dir); project == nil || filepath.Base(project.Path) != "C.Worker.csproj" {
+		t.Errorf("expected worker project, got %+v", project)
+	}
+
+	write("D.Api.csproj", `<Project Sdk="Microsoft.NET.Sdk.Web"></Project>`)
+	if project := findRunnableDotnetProject(dir); project == nil || filepath.Base(project.Path) != "D.Api.csproj" {
+		t.Errorf("expected web project, got %+v", project)
+	}
+
+	if framework, _, _ := detectDotNetFramework(dir); framework != "ASP.NET Core" {
+		t.Errorf("framework = %q, want ASP.NET Core", framework)
+	}
+
+	if project := findRunnableDotnetProject(t.TempDir()); project != nil {
+		t.Errorf("expected nil without project files, got %+v", project)
+	}
+}
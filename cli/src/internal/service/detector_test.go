@@ -3,6 +3,7 @@ package service_test
 import (
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"testing"
 
@@ -17,6 +18,36 @@ func TestEntrypointOverride(t *testing.T) {
 		projectFiles map[string]string // filename -> content
 		checkCmd     func(runtime *service.ServiceRuntime) error
 	}{
+		{
+			name:       "FastAPI with detected app variable",
+			framework:  "FastAPI",
+			entrypoint: "",
+			projectFiles: map[string]string{
+				"requirements.txt": "fastapi\nuvicorn",
+				"src/app.py":       "from fastapi import FastAPI\napi = FastAPI()",
+			},
+			checkCmd: func(runtime *service.ServiceRuntime) error {
+				if runtime.Command != "uvicorn" || runtime.Args[0] != "src.app:api" {
+					t.Errorf("Expected 'uvicorn src.app:api', got %q %v", runtime.Command, runtime.Args)
+				}
+				return nil
+			},
+		},
+		{
+			name:       "Django ASGI module without manage.py",
+			framework:  "Django",
+			entrypoint: "",
+			projectFiles: map[string]string{
+				"requirements.txt": "django\nuvicorn",
+				"mysite/asgi.py":   "from django.core.asgi import get_asgi_application\napplication = get_asgi_application()",
+			},
+			checkCmd: func(runtime *service.ServiceRuntime) error {
+				if runtime.Command != "uvicorn" || runtime.Args[0] != "mysite.asgi:application" {
+					t.Errorf("Expected 'uvicorn mysite.asgi:application', got %q %v", runtime.Command, runtime.Args)
+				}
+				return nil
+			},
+		},
 		{
 			name:       "FastAPI with custom entrypoint",
 			framework:  "FastAPI",
@@ -555,3 +586,150 @@ services:
 		})
 	}
 }
+
+func TestDetectServiceRuntimeFunctionApp(t *testing.T) {
+	tests := []struct {
+		name     string
+		host     string
+		files    map[string]string
+		wantPort int
+		wantFunc bool
+	}{
+		{
+			name: "node function app",
+			host: "function",
+			files: map[string]string{
+				"host.json":    `{"version": "2.0"}`,
+				"package.json": `{"scripts": {"start": "func start"}, "dependencies": {"@azure/functions": "^4.0.0"}}`,
+			},
+			wantFunc: true,
+		},
+		{
+			name: "python function app with local port",
+			host: "function",
+			files: map[string]string{
+				"host.json":           `{"version": "2.0"}`,
+				"function_app.py":     "import azure.functions as func\napp = func.FunctionApp()\n",
+				"requirements.txt":    "azure-functions\n",
+				"local.settings.json": `{"Values": {"FUNCTIONS_WORKER_RUNTIME": "python"}, "Host": {"LocalHttpPort": 7199}}`,
+			},
+			wantPort: 7199,
+			wantFunc: true,
+		},
+		{
+			name: "function app hosted as container app",
+			host: "containerapp",
+			files: map[string]string{
+				"host.json":    `{"version": "2.0"}`,
+				"package.json": `{"scripts": {"start": "node index.js"}, "dependencies": {"@azure/functions": "^4.0.0"}}`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			for name, content := range tt.files {
+				if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0600); err != nil {
+					t.Fatalf("Failed to create file %s: %v", name, err)
+				}
+			}
+
+			svc := service.Service{Project: ".", Host: tt.host}
+			runtime, err := service.DetectServiceRuntime("api", svc, map[int]bool{}, tmpDir, "azd")
+			if err != nil {
+				t.Fatalf("Failed to detect runtime: %v", err)
+			}
+
+			if !tt.wantFunc {
+				if runtime.Command == "func" {
+					t.Errorf("expected a non-Functions command, got %s %v", runtime.Command, runtime.Args)
+				}
+				return
+			}
+			if runtime.Framework != "Azure Functions" {
+				t.Errorf("Framework = %q, want Azure Functions", runtime.Framework)
+			}
+			if runtime.Command != "func" || len(runtime.Args) != 3 || runtime.Args[0] != "start" || runtime.Args[1] != "--port" {
+				t.Errorf("command = %s %v, want func start --port <port>", runtime.Command, runtime.Args)
+			}
+			if runtime.Args[2] != strconv.Itoa(runtime.Port) {
+				t.Errorf("--port %s does not match assigned port %d", runtime.Args[2], runtime.Port)
+			}
+			if tt.wantPort != 0 && runtime.Port != tt.wantPort {
+				t.Errorf("Port = %d, want %d", runtime.Port, tt.wantPort)
+			}
+		})
+	}
+}
+
+func TestDetectServiceRuntimeDockerfile(t *testing.T) {
+	tests := []struct {
+		name      string
+		files     map[string]string
+		docker    *service.DockerConfig
+		wantPath  string
+		wantPorts []int
+	}{
+		{
+			name: "no dockerfile",
+			files: map[string]string{
+				"package.json": `{"scripts": {"start": "node index.js"}}`,
+			},
+		},
+		{
+			name: "dockerfile in project",
+			files: map[string]string{
+				"package.json": `{"scripts": {"start": "node index.js"}}`,
+				"Dockerfile":   "FROM node:20-alpine\nEXPOSE 3000\n",
+			},
+			wantPath:  "Dockerfile",
+			wantPorts: []int{3000},
+		},
+		{
+			name: "docker path from azure.yaml",
+			files: map[string]string{
+				"package.json":           `{"scripts": {"start": "node index.js"}}`,
+				"Dockerfile":             "FROM node:20-alpine\n",
+				"docker.prod.Dockerfile": "FROM node:20-alpine\nEXPOSE 8080\n",
+			},
+			docker:    &service.DockerConfig{Path: "./docker.prod.Dockerfile"},
+			wantPath:  "docker.prod.Dockerfile",
+			wantPorts: []int{8080},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			for name, content := range tt.files {
+				if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0600); err != nil {
+					t.Fatalf("Failed to create file %s: %v", name, err)
+				}
+			}
+
+			svc := service.Service{Project: ".", Host: "containerapp", Docker: tt.docker}
+			runtime, err := service.DetectServiceRuntime("web", svc, map[int]bool{}, tmpDir, "azd")
+			if err != nil {
+				t.Fatalf("Failed to detect runtime: %v", err)
+			}
+
+			if tt.wantPath == "" {
+				if runtime.Dockerfile != nil {
+					t.Errorf("expected no Dockerfile, got %+v", runtime.Dockerfile)
+				}
+				return
+			}
+			if runtime.Dockerfile == nil {
+				t.Fatal("expected a Dockerfile")
+			}
+			if filepath.Base(runtime.Dockerfile.Path) != tt.wantPath {
+				t.Errorf("Dockerfile path = %s, want %s", runtime.Dockerfile.Path, tt.wantPath)
+			}
+			if len(runtime.Dockerfile.ExposedPorts) != len(tt.wantPorts) ||
+				(len(tt.wantPorts) > 0 && runtime.Dockerfile.ExposedPorts[0] != tt.wantPorts[0]) {
+				t.Errorf("ExposedPorts = %v, want %v", runtime.Dockerfile.ExposedPorts, tt.wantPorts)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/jongio/azd-app/cli/src/internal/output"
+)
+
+// WriteDiagnostics writes a snapshot of the running session to w: process and memory
+// statistics, the state of each service, and the stack of every goroutine.
+func WriteDiagnostics(w io.Writer, processes map[string]*ServiceProcess) error {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	now := time.Now()
+	fmt.Fprintf(w, "azd app diagnostics\n")
+	fmt.Fprintf(w, "Time:       %s\n", now.Format(time.RFC3339))
+	fmt.Fprintf(w, "PID:        %d\n", os.Getpid())
+	fmt.Fprintf(w, "Go:         %s %s/%s\n", runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	fmt.Fprintf(w, "Goroutines: %d\n", runtime.NumGoroutine())
+	fmt.Fprintf(w, "Memory:     %d KB allocated, %d KB from OS, %d GC cycles\n", mem.Alloc/1024, mem.Sys/1024, mem.NumGC)
+
+	names := make([]string, 0, len(processes))
+	for name := range processes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintf(w, "\nServices (%d):\n", len(names))
+	table := output.NewTable("NAME", "PID", "PORT", "READY", "UPTIME", "COMMAND")
+	table.Indent, table.MaxWidth = "  ", -1
+	for _, name := range names {
+		process := processes[name]
+		pid := "-"
+		if process.Process != nil {
+			pid = fmt.Sprintf("%d", process.Process.Pid)
+		}
+		uptime := "-"
+		if !process.StartTime.IsZero() {
+			uptime = now.Sub(process.StartTime).Round(time.Second).String()
+		}
+		command := process.Runtime.Command
+		for _, arg := range process.Runtime.Args {
+			command += " " + arg
+		}
+		table.AddRow(name, pid, strconv.Itoa(process.Port), strconv.FormatBool(process.Ready), uptime, command)
+	}
+	table.Render(w)
+
+	fmt.Fprintf(w, "\nGoroutines:\n")
+	profile := pprof.Lookup("goroutine")
+	if profile == nil {
+		return fmt.Errorf("goroutine profile is not available")
+	}
+	if err := profile.WriteTo(w, 2); err != nil {
+		return fmt.Errorf("failed to write goroutine stacks: %w", err)
+	}
+	return nil
+}
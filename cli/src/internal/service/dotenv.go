@@ -0,0 +1,70 @@
+package service
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DotEnvFiles returns the .env files of a service in serviceDir that exist, lowest
+// precedence first: the .env of the workspace in projectDir, then the .env, .env.local, and
+// .env.<environment> of the service. Without an environment name .env.<environment> is
+// skipped; a service in the workspace directory reads its .env once.
+func DotEnvFiles(projectDir, serviceDir, environment string) []string {
+	candidates := []string{filepath.Join(projectDir, ".env")}
+	names := []string{".env", ".env.local"}
+	if environment != "" && !strings.ContainsAny(environment, `/\`) && environment != "." && environment != ".." {
+		names = append(names, ".env."+environment)
+	}
+	for _, name := range names {
+		candidates = append(candidates, filepath.Join(serviceDir, name))
+	}
+
+	var files []string
+	seen := make(map[string]bool)
+	for _, path := range candidates {
+		if seen[path] {
+			continue
+		}
+		seen[path] = true
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			files = append(files, path)
+		}
+	}
+	return files
+}
+
+// LoadDotEnvFiles loads files in order, later files overriding the variables of earlier ones.
+func LoadDotEnvFiles(files []string) (map[string]string, error) {
+	env := make(map[string]string)
+	for _, path := range files {
+		vars, err := LoadDotEnv(path)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		for k, v := range vars {
+			env[k] = v
+		}
+	}
+	return env, nil
+}
+
+// ApplyDotEnvFiles sets the variables each runtime loads from the .env files of the
+// workspace in projectDir and of its service, as found by DotEnvFiles for environment.
+func ApplyDotEnvFiles(runtimes []*ServiceRuntime, projectDir, environment string) error {
+	for _, rt := range runtimes {
+		serviceDir := rt.WorkingDir
+		if serviceDir == "" {
+			serviceDir = projectDir
+		} else if !filepath.IsAbs(serviceDir) {
+			serviceDir = filepath.Join(projectDir, serviceDir)
+		}
+		env, err := LoadDotEnvFiles(DotEnvFiles(projectDir, serviceDir, environment))
+		if err != nil {
+			return fmt.Errorf("service %s: %w", rt.Name, err)
+		}
+		rt.DotEnv = env
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestApplyDotEnvFiles(t *testing.T) {
+	projectDir := t.TempDir()
+	apiDir := filepath.Join(projectDir, "api")
+	if err := os.MkdirAll(apiDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		filepath.Join(projectDir, ".env"):     "SHARED=workspace\nLEVEL=workspace\n",
+		filepath.Join(apiDir, ".env"):         "LEVEL=service\nLOCAL=service\nSTAGE=service\n",
+		filepath.Join(apiDir, ".env.local"):   "LOCAL=local\nSTAGE=local\n",
+		filepath.Join(apiDir, ".env.dev"):     "STAGE=dev\n",
+		filepath.Join(apiDir, ".env.prod"):    "STAGE=prod\n",
+		filepath.Join(projectDir, ".env.dev"): "WORKSPACE_STAGE=dev\n",
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	api := &ServiceRuntime{Name: "api", WorkingDir: apiDir}
+	web := &ServiceRuntime{Name: "web", WorkingDir: filepath.Join(projectDir, "web")}
+	root := &ServiceRuntime{Name: "root", WorkingDir: projectDir}
+	if err := ApplyDotEnvFiles([]*ServiceRuntime{api, web, root}, projectDir, "dev"); err != nil {
+		t.Fatalf("ApplyDotEnvFiles() error = %v", err)
+	}
+
+	want := map[string]string{"SHARED": "workspace", "LEVEL": "service", "LOCAL": "local", "STAGE": "dev"}
+	if !reflect.DeepEqual(api.DotEnv, want) {
+		t.Errorf("api DotEnv = %v, want %v", api.DotEnv, want)
+	}
+	// A service without .env files of its own gets those of the workspace
+	want = map[string]string{"SHARED": "workspace", "LEVEL": "workspace"}
+	if !reflect.DeepEqual(web.DotEnv, want) {
+		t.Errorf("web DotEnv = %v, want %v", web.DotEnv, want)
+	}
+	// The .env.<environment> of the workspace belongs to services in the workspace directory
+	want = map[string]string{"SHARED": "workspace", "LEVEL": "workspace", "WORKSPACE_STAGE": "dev"}
+	if !reflect.DeepEqual(root.DotEnv, want) {
+		t.Errorf("root DotEnv = %v, want %v", root.DotEnv, want)
+	}
+
+	if got := DotEnvFiles(projectDir, apiDir, ""); len(got) != 3 {
+		t.Errorf("DotEnvFiles() without an environment = %v, want .env, api/.env, and api/.env.local", got)
+	}
+	if got := DotEnvFiles(projectDir, apiDir, "../prod"); len(got) != 3 {
+		t.Errorf("DotEnvFiles() with a path as environment = %v, want no .env.<environment>", got)
+	}
+}
+
+func TestServiceEnvironmentPrecedence(t *testing.T) {
+	rt := &ServiceRuntime{
+		Name:   "api",
+		DotEnv: map[string]string{"A": "dotenv", "B": "dotenv", "C": "dotenv"},
+		Env:    map[string]string{"C": "runtime"},
+	}
+	got := serviceEnvironment(map[string]string{"B": "env-file", "C": "env-file"}, rt)
+	want := map[string]string{"A": "dotenv", "B": "env-file", "C": "runtime"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("serviceEnvironment() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,22 @@
+package service
+
+// ApplyEmulatorEnv sets the variables each runtime gets from the local emulators of the
+// resources its service uses in azure.yaml, given by resource name in env. A service that uses
+// several resources gets the variables of each, in the order of its uses.
+func ApplyEmulatorEnv(runtimes []*ServiceRuntime, azureYaml *AzureYaml, env map[string]map[string]string) {
+	for _, rt := range runtimes {
+		svc, ok := azureYaml.Services[rt.Name]
+		if !ok {
+			continue
+		}
+		vars := make(map[string]string)
+		for _, name := range svc.Uses {
+			for k, v := range env[name] {
+				vars[k] = v
+			}
+		}
+		if len(vars) > 0 {
+			rt.EmulatorEnv = vars
+		}
+	}
+}
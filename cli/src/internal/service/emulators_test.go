@@ -0,0 +1,34 @@
+package service
+
+import (
+	"testing"
+)
+
+func TestApplyEmulatorEnv(t *testing.T) {
+	azureYaml := &AzureYaml{
+		Services: map[string]Service{
+			"api": {Uses: []string{"db", "cache"}},
+			"web": {Uses: []string{"api"}},
+		},
+	}
+	env := map[string]map[string]string{
+		"db":    {"POSTGRES_URL": "postgres://local"},
+		"cache": {"REDIS_URL": "redis://local"},
+	}
+	api := &ServiceRuntime{Name: "api", Env: map[string]string{"REDIS_URL": "redis://runtime"}}
+	web := &ServiceRuntime{Name: "web"}
+	ApplyEmulatorEnv([]*ServiceRuntime{api, web}, azureYaml, env)
+
+	if len(api.EmulatorEnv) != 2 || api.EmulatorEnv["POSTGRES_URL"] != "postgres://local" {
+		t.Errorf("api EmulatorEnv = %v", api.EmulatorEnv)
+	}
+	if web.EmulatorEnv != nil {
+		t.Errorf("web EmulatorEnv = %v, want none", web.EmulatorEnv)
+	}
+
+	// Emulators win over the environment run is started with, and the runtime over emulators
+	got := serviceEnvironment(map[string]string{"POSTGRES_URL": "postgres://azure"}, api)
+	if got["POSTGRES_URL"] != "postgres://local" || got["REDIS_URL"] != "redis://runtime" {
+		t.Errorf("serviceEnvironment() = %v", got)
+	}
+}
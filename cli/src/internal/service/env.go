@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/jongio/azd-app/cli/src/internal/security"
@@ -84,6 +85,56 @@ func GenerateServiceURLs(processes map[string]*ServiceProcess) map[string]string
 	return urls
 }
 
+// DiscoveryEnv returns the variables that tell services where the services of a local run
+// listen, so that a frontend reaches the local backend without hand-edited .env files. Each
+// service with a port gets SERVICE_<NAME>_URL, the variable azd sets after deployment, and
+// services__<name>__<protocol>__0, which .NET Aspire service discovery reads. Services are
+// reached at localhost, or at their forwarded URL when it answers without signing in; a
+// private forwarded port would redirect calls from other services to a sign-in page.
+func DiscoveryEnv(runtimes []*ServiceRuntime) map[string]string {
+	env := make(map[string]string)
+	for _, rt := range runtimes {
+		if rt.Port <= 0 {
+			continue
+		}
+		protocol := rt.Protocol
+		if protocol != "https" {
+			protocol = "http"
+		}
+		url := fmt.Sprintf("%s://localhost:%d", protocol, rt.Port)
+		if rt.ForwardedURL != "" && rt.ForwardedOpen {
+			url = rt.ForwardedURL
+		}
+		setDiscoveryEnv(env, rt.Name, protocol, url)
+	}
+	return env
+}
+
+// mergeDiscoveryEnv returns env overlaid with the discovery variables of local services, and
+// the sorted names of variables of env they replaced with a different value. Local services
+// win, since a SERVICE_<NAME>_URL loaded from an azd environment points at the deployed service.
+func mergeDiscoveryEnv(env, discovery map[string]string) (map[string]string, []string) {
+	merged := make(map[string]string, len(env)+len(discovery))
+	for k, v := range env {
+		merged[k] = v
+	}
+	var overridden []string
+	for k, v := range discovery {
+		if old, ok := env[k]; ok && old != v {
+			overridden = append(overridden, k)
+		}
+		merged[k] = v
+	}
+	sort.Strings(overridden)
+	return merged, overridden
+}
+
+// setDiscoveryEnv sets the discovery variables of a service listening at url.
+func setDiscoveryEnv(env map[string]string, name, protocol, url string) {
+	env["SERVICE_"+strings.ToUpper(strings.ReplaceAll(name, "-", "_"))+"_URL"] = url
+	env[fmt.Sprintf("services__%s__%s__0", name, protocol)] = url
+}
+
 // LoadDotEnv loads environment variables from a .env file.
 func LoadDotEnv(path string) (map[string]string, error) {
 	if err := security.ValidatePath(path); err != nil {
@@ -2,21 +2,31 @@ package service
 
 import (
 	"bufio"
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
+	"syscall"
 	"time"
 
+	"github.com/jongio/azd-app/cli/src/internal/container"
 	"github.com/jongio/azd-app/cli/src/internal/executor"
+	"github.com/jongio/azd-app/cli/src/internal/sandbox"
 )
 
-// StartService starts a service and returns the process handle.
-func StartService(runtime *ServiceRuntime, env map[string]string, projectDir string) (*ServiceProcess, error) {
+// stopGracePeriod is how long StopService waits for a service to exit after interrupting it.
+var stopGracePeriod = 10 * time.Second
+
+// StartService starts a service and returns the process handle. ctx bounds the start, such as
+// building the image of a container service; the service keeps running until StopService.
+func StartService(ctx context.Context, runtime *ServiceRuntime, env map[string]string, projectDir string) (*ServiceProcess, error) {
 	process := &ServiceProcess{
 		Name:    runtime.Name,
 		Runtime: *runtime,
 		Ready:   false,
+		Env:     env,
 	}
 
 	// Build command
@@ -24,16 +34,9 @@ func StartService(runtime *ServiceRuntime, env map[string]string, projectDir str
 		return nil, fmt.Errorf("no command specified for service %s", runtime.Name)
 	}
 
-	// Create command
-	args := runtime.Args
-	// #nosec G204 -- Command and args come from azure.yaml service configuration, validated by service package
-	cmd := exec.Command(runtime.Command, args...)
-	cmd.Dir = runtime.WorkingDir
-
-	// Set environment variables
-	cmd.Env = os.Environ()
-	for key, value := range env {
-		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", key, value))
+	cmd, err := serviceCommand(ctx, runtime, env)
+	if err != nil {
+		return nil, err
 	}
 
 	// Create pipes for stdout and stderr
@@ -48,6 +51,7 @@ func StartService(runtime *ServiceRuntime, env map[string]string, projectDir str
 	}
 
 	// Start process
+	process.StartTime = time.Now()
 	if err := cmd.Start(); err != nil {
 		return nil, fmt.Errorf("failed to start service %s: %w", runtime.Name, err)
 	}
@@ -57,6 +61,21 @@ func StartService(runtime *ServiceRuntime, env map[string]string, projectDir str
 	process.Stderr = stderrPipe
 	process.Port = runtime.Port
 
+	// Reap the process as soon as it exits, so that it is not taken for running
+	process.Exited()
+
+	// A remote engine publishes the port on its own machine
+	if engine := runtime.containerEngine(); engine != nil && runtime.Port > 0 {
+		forwarder, err := engine.Forward([]int{runtime.Port})
+		if err != nil {
+			_ = killProcess(process.Process)
+			<-process.Exited()
+			runtime.removeContainer()
+			return nil, fmt.Errorf("failed to forward port of service %s: %w", runtime.Name, err)
+		}
+		process.forwarder = forwarder
+	}
+
 	// Start log collection
 	StartLogCollection(process, projectDir)
 
@@ -68,18 +87,152 @@ func StopService(process *ServiceProcess) error {
 	if process.Process == nil {
 		return fmt.Errorf("process not started")
 	}
+	process.stopping.Store(true)
 
 	// Try graceful shutdown first
-	if err := process.Process.Signal(os.Interrupt); err != nil {
+	if err := interruptProcess(process.Process); err != nil {
 		// If interrupt fails, force kill
-		if killErr := process.Process.Kill(); killErr != nil {
+		if killErr := killProcess(process.Process); killErr != nil {
+			return fmt.Errorf("failed to kill process: %w", killErr)
+		}
+	}
+
+	// Wait for process to exit, killing it if it ignores the interrupt
+	select {
+	case <-process.Exited():
+	case <-time.After(stopGracePeriod):
+		if killErr := killProcess(process.Process); killErr != nil {
 			return fmt.Errorf("failed to kill process: %w", killErr)
 		}
+		<-process.Exited()
+	}
+	// Processes the service spawned may outlive it and hold its port
+	_ = killProcess(process.Process)
+
+	// The container may outlive the docker CLI that started it
+	process.Runtime.removeContainer()
+	_ = process.forwarder.Close()
+	return nil
+}
+
+// Exited returns a channel that is closed once the process of the service has exited. The
+// process is awaited once, by the first call, since it can only be awaited once.
+func (p *ServiceProcess) Exited() <-chan struct{} {
+	p.waitOnce.Do(func() {
+		p.exited = make(chan struct{})
+		if p.Process == nil {
+			close(p.exited)
+			return
+		}
+		go func() {
+			state, _ := p.Process.Wait()
+			p.state = state
+			close(p.exited)
+		}()
+	})
+	return p.exited
+}
+
+// ExitCode returns the exit code of the exited process of the service, or -1 when it was
+// killed by a signal or has not exited.
+func (p *ServiceProcess) ExitCode() int {
+	select {
+	case <-p.Exited():
+	default:
+		return -1
 	}
+	if p.state == nil {
+		return -1
+	}
+	return p.state.ExitCode()
+}
 
-	// Wait for process to exit
-	_, err := process.Process.Wait()
-	return err
+// Stopping reports whether StopService was called for the service, so that its exit was
+// requested rather than a crash.
+func (p *ServiceProcess) Stopping() bool {
+	return p.stopping.Load()
+}
+
+// containerEngine returns the engine of the container the service runs in, or nil when it
+// runs on the host.
+func (runtime *ServiceRuntime) containerEngine() *container.Engine {
+	switch {
+	case runtime.Container != nil:
+		return &runtime.Container.Engine
+	case runtime.Sandbox != nil:
+		return &runtime.Sandbox.Engine
+	}
+	return nil
+}
+
+// removeContainer removes the container the service runs in, if any.
+func (runtime *ServiceRuntime) removeContainer() {
+	switch {
+	case runtime.Container != nil:
+		runtime.Container.remove()
+	case runtime.Sandbox != nil:
+		_ = runtime.Sandbox.RemoveContainer(runtime.Name)
+	}
+}
+
+// serviceCommand creates the command that runs a service: directly on the host, as a
+// container built from its Dockerfile, or in a sandbox container. Containers only receive env.
+func serviceCommand(ctx context.Context, runtime *ServiceRuntime, env map[string]string) (*exec.Cmd, error) {
+	// The command outlives ctx, which ends once services have started; StopService stops it
+	runCtx := context.WithoutCancel(ctx)
+	if c := runtime.Container; c != nil {
+		// A container left over from an earlier run would hold the name and port
+		c.remove()
+		if err := c.build(ctx); err != nil {
+			return nil, fmt.Errorf("failed to build the image of service %s: %w", runtime.Name, err)
+		}
+		args, vars := c.runArgs(runtime.Port, env)
+		cmd := c.Engine.Command(runCtx, args...)
+		cmd.Env = append(os.Environ(), vars...)
+		return cmd, nil
+	}
+	if runtime.Sandbox != nil {
+		var ports []int
+		if runtime.Port > 0 {
+			ports = []int{runtime.Port}
+		}
+		// A container left over from an earlier run would hold the name and port
+		_ = runtime.Sandbox.RemoveContainer(runtime.Name)
+		cmd, err := runtime.Sandbox.Exec(runCtx, sandbox.Command{
+			Label:  runtime.Name,
+			Dir:    runtime.WorkingDir,
+			Script: sandbox.Script(runtime.Command, runtime.Args),
+			Env:    env,
+			Ports:  ports,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to sandbox service %s: %w", runtime.Name, err)
+		}
+		return cmd, nil
+	}
+
+	// #nosec G204 -- Command and args come from azure.yaml service configuration, validated by service package
+	cmd := exec.Command(runtime.Command, runtime.Args...)
+	cmd.Dir = runtime.WorkingDir
+	setProcessGroup(cmd)
+
+	// Set environment variables
+	cmd.Env = os.Environ()
+	for key, value := range env {
+		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", key, value))
+	}
+	return cmd, nil
+}
+
+// RestartService stops a service and starts it again with the same runtime and environment.
+func RestartService(ctx context.Context, process *ServiceProcess, projectDir string) (*ServiceProcess, error) {
+	if process.Process != nil {
+		// The process may already be gone (e.g. killed), so a stop error is not fatal
+		_ = StopService(process)
+	}
+
+	runtime := process.Runtime
+	return StartService(ctx, &runtime, process.Env, projectDir)
 }
 
 // ReadServiceOutput reads and forwards output from a service.
@@ -122,9 +275,10 @@ func GetProcessStatus(process *ServiceProcess) string {
 		return "not-started"
 	}
 
-	// Check if process is still running
-	err := process.Process.Signal(nil)
-	if err != nil {
+	// Signal 0 checks that the process still exists without affecting it.
+	// Platforms that can't send it (Windows) report the process as running.
+	err := process.Process.Signal(syscall.Signal(0))
+	if errors.Is(err, os.ErrProcessDone) || errors.Is(err, syscall.ESRCH) {
 		return "stopped"
 	}
 
@@ -1,8 +1,14 @@
 package service
 
 import (
+	"context"
+	"fmt"
 	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
 	"strings"
+	"syscall"
 	"testing"
 	"time"
 )
@@ -96,8 +102,25 @@ func TestGetProcessStatus(t *testing.T) {
 		t.Errorf("GetProcessStatus() = %v, want not-started", got)
 	}
 
-	// We can't easily test running processes without actually starting one
-	// The integration test TestStartService_Success covers this
+	if runtime.GOOS == "windows" {
+		return
+	}
+
+	cmd := exec.Command("sleep", "5")
+	if err := cmd.Start(); err != nil {
+		t.Skipf("sleep unavailable: %v", err)
+	}
+	process.Process = cmd.Process
+	process.Ready = true
+	if got := GetProcessStatus(process); got != "ready" {
+		t.Errorf("GetProcessStatus() = %v, want ready", got)
+	}
+
+	_ = cmd.Process.Kill()
+	_ = cmd.Wait()
+	if got := GetProcessStatus(process); got != "stopped" {
+		t.Errorf("GetProcessStatus() after exit = %v, want stopped", got)
+	}
 }
 
 func TestStartService_InvalidCommand(t *testing.T) {
@@ -108,7 +131,7 @@ func TestStartService_InvalidCommand(t *testing.T) {
 		Language:   "shell",
 	}
 
-	_, err := StartService(runtime, nil, ".")
+	_, err := StartService(context.Background(), runtime, nil, ".")
 	if err == nil {
 		t.Error("StartService() expected error for empty command")
 	}
@@ -126,7 +149,7 @@ func TestStartService_NonexistentCommand(t *testing.T) {
 		Language:   "shell",
 	}
 
-	_, err := StartService(runtime, nil, ".")
+	_, err := StartService(context.Background(), runtime, nil, ".")
 	if err == nil {
 		t.Error("StartService() expected error for nonexistent command")
 	}
@@ -148,7 +171,7 @@ func TestStartService_Success(t *testing.T) {
 		Port:       8080,
 	}
 
-	process, err := StartService(runtime, map[string]string{"TEST_VAR": "value"}, tmpDir)
+	process, err := StartService(context.Background(), runtime, map[string]string{"TEST_VAR": "value"}, tmpDir)
 	if err != nil {
 		t.Fatalf("StartService() error = %v", err)
 	}
@@ -196,6 +219,71 @@ func TestStopService_NotStarted(t *testing.T) {
 	}
 }
 
+func TestStopService_StopsProcessGroup(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping process test in short mode")
+	}
+	if runtime.GOOS == "windows" {
+		t.Skip("process groups are Unix only")
+	}
+
+	original := stopGracePeriod
+	stopGracePeriod = 200 * time.Millisecond
+	t.Cleanup(func() { stopGracePeriod = original })
+
+	// The service ignores SIGINT and leaves a child behind, like a dev server under npm run dev
+	tmpDir := t.TempDir()
+	rt := &ServiceRuntime{
+		Name:       "test-stubborn",
+		WorkingDir: tmpDir,
+		Command:    "sh",
+		Args:       []string{"-c", `trap "" INT; sleep 30 & echo $! > child.pid; wait`},
+		Language:   "shell",
+	}
+	process, err := StartService(context.Background(), rt, nil, tmpDir)
+	if err != nil {
+		t.Fatalf("StartService() error = %v", err)
+	}
+	t.Cleanup(func() { _ = GetLogManager(tmpDir).RemoveBuffer(rt.Name) })
+
+	var childPID int
+	for deadline := time.Now().Add(5 * time.Second); childPID == 0 && time.Now().Before(deadline); {
+		if data, err := os.ReadFile(tmpDir + "/child.pid"); err == nil && strings.TrimSpace(string(data)) != "" {
+			childPID, _ = strconv.Atoi(strings.TrimSpace(string(data)))
+		} else {
+			time.Sleep(20 * time.Millisecond)
+		}
+	}
+	if childPID == 0 {
+		t.Fatal("service did not start its child")
+	}
+
+	start := time.Now()
+	_ = StopService(process)
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("StopService() took %s, want it to kill the service after the grace period", elapsed)
+	}
+	for deadline := time.Now().Add(2 * time.Second); processRunning(childPID); time.Sleep(20 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			_ = syscall.Kill(childPID, syscall.SIGKILL)
+			t.Fatal("StopService() left the child of the service running")
+		}
+	}
+}
+
+// processRunning reports whether pid is a live process, counting zombies as exited.
+func processRunning(pid int) bool {
+	if err := syscall.Kill(pid, 0); err != nil {
+		return false
+	}
+	stat, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return !os.IsNotExist(err)
+	}
+	fields := strings.Fields(string(stat[strings.LastIndexByte(string(stat), ')')+1:]))
+	return len(fields) == 0 || fields[0] != "Z"
+}
+
 func TestExecuteCommand(t *testing.T) {
 	tests := []struct {
 		name    string
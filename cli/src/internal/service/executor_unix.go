@@ -0,0 +1,34 @@
+//go:build !windows
+
+package service
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// setProcessGroup starts cmd in a process group of its own, so that stopping the service
+// reaches the processes it spawns, such as the dev server behind npm run dev, and a Ctrl+C
+// in the terminal reaches the service only through StopService.
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// interruptProcess sends SIGINT to the process group of p.
+func interruptProcess(p *os.Process) error {
+	if err := syscall.Kill(-p.Pid, syscall.SIGINT); err != nil {
+		return p.Signal(os.Interrupt)
+	}
+	return nil
+}
+
+// killProcess kills the process group of p, including processes left behind after p exited.
+func killProcess(p *os.Process) error {
+	err := syscall.Kill(-p.Pid, syscall.SIGKILL)
+	if errors.Is(err, syscall.ESRCH) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,27 @@
+//go:build windows
+
+package service
+
+import (
+	"os"
+	"os/exec"
+	"strconv"
+)
+
+// setProcessGroup does nothing on Windows; killProcess ends the process tree instead.
+func setProcessGroup(*exec.Cmd) {}
+
+// interruptProcess fails on Windows, which cannot send os.Interrupt to another process,
+// so StopService kills the service right away.
+func interruptProcess(p *os.Process) error {
+	return p.Signal(os.Interrupt)
+}
+
+// killProcess kills p and the processes it spawned.
+func killProcess(p *os.Process) error {
+	// #nosec G204 -- the PID is an integer from a process this package started
+	if err := exec.Command("taskkill", "/T", "/F", "/PID", strconv.Itoa(p.Pid)).Run(); err != nil {
+		return p.Kill()
+	}
+	return nil
+}
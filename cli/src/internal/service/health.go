@@ -1,53 +1,39 @@
 package service
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net"
-	"net/http"
 	"time"
+
+	"github.com/jongio/azd-app/cli/src/internal/httpclient"
+	"github.com/jongio/azd-app/cli/src/internal/retry"
 )
 
-// PerformHealthCheck verifies that a service is ready.
+// PerformHealthCheck verifies that a service is ready, probing it as often as its retry policy allows.
 func PerformHealthCheck(process *ServiceProcess) error {
 	config := process.Runtime.HealthCheck
 
-	startTime := time.Now()
-	ticker := time.NewTicker(config.Interval)
-	defer ticker.Stop()
-
-	timeout := time.After(config.Timeout)
-
-	for {
-		select {
-		case <-timeout:
-			elapsed := time.Since(startTime)
-			return fmt.Errorf("health check timed out after %v", elapsed.Round(time.Second))
-
-		case <-ticker.C:
-			var err error
-
-			switch config.Type {
-			case "http":
-				err = HTTPHealthCheck(process.Port, config.Path)
-			case "port":
-				err = PortHealthCheck(process.Port)
-			case "process":
-				err = ProcessHealthCheck(process)
-			default:
-				// Default to HTTP health check
-				err = HTTPHealthCheck(process.Port, config.Path)
-			}
-
-			if err == nil {
-				// Health check succeeded
-				process.Ready = true
-				return nil
-			}
-
-			// Health check failed, will retry after interval
+	err := retry.Do(context.Background(), config.Retry, func(ctx context.Context, attempt int) error {
+		switch config.Type {
+		case "http":
+			return HTTPHealthCheck(process.Port, config.Path)
+		case "port":
+			return PortHealthCheck(process.Port)
+		case "process":
+			return ProcessHealthCheck(process)
+		default:
+			// Default to HTTP health check
+			return HTTPHealthCheck(process.Port, config.Path)
 		}
+	})
+	if err != nil {
+		return fmt.Errorf("health check failed after %d attempts: %w", config.Retry.MaxAttempts, err)
 	}
+
+	process.Ready = true
+	return nil
 }
 
 // HTTPHealthCheck attempts HTTP requests to verify service is ready.
@@ -55,14 +41,8 @@ func HTTPHealthCheck(port int, path string) error {
 	// Build URL
 	url := fmt.Sprintf("http://localhost:%d%s", port, path)
 
-	// Create HTTP client with timeout
-	client := &http.Client{
-		Timeout: 5 * time.Second,
-		CheckRedirect: func(req *http.Request, via []*http.Request) error {
-			// Don't follow redirects
-			return http.ErrUseLastResponse
-		},
-	}
+	// Create HTTP client with timeout; redirects aren't followed
+	client := httpclient.NewLocal(5 * time.Second)
 
 	// Try HEAD request first (lightweight)
 	resp, err := client.Head(url)
@@ -8,6 +8,8 @@ import (
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/jongio/azd-app/cli/src/internal/retry"
 )
 
 func TestPortHealthCheck_Success(t *testing.T) {
@@ -227,9 +229,8 @@ func TestPerformHealthCheck_PortType(t *testing.T) {
 	runtime := ServiceRuntime{
 		Name: "test-service",
 		HealthCheck: HealthCheckConfig{
-			Type:     "port",
-			Timeout:  5 * time.Second,
-			Interval: 500 * time.Millisecond,
+			Type:  "port",
+			Retry: retry.Policy{MaxAttempts: 10, BaseDelay: 500 * time.Millisecond, Backoff: 1},
 		},
 	}
 
@@ -269,10 +270,9 @@ func TestPerformHealthCheck_HTTPType(t *testing.T) {
 	runtime := ServiceRuntime{
 		Name: "test-service",
 		HealthCheck: HealthCheckConfig{
-			Type:     "http",
-			Path:     "/health",
-			Timeout:  5 * time.Second,
-			Interval: 500 * time.Millisecond,
+			Type:  "http",
+			Path:  "/health",
+			Retry: retry.Policy{MaxAttempts: 10, BaseDelay: 500 * time.Millisecond, Backoff: 1},
 		},
 	}
 
@@ -297,9 +297,8 @@ func TestPerformHealthCheck_Timeout(t *testing.T) {
 	runtime := ServiceRuntime{
 		Name: "test-service",
 		HealthCheck: HealthCheckConfig{
-			Type:     "port",
-			Timeout:  1 * time.Second,
-			Interval: 200 * time.Millisecond,
+			Type:  "port",
+			Retry: retry.Policy{MaxAttempts: 5, BaseDelay: 200 * time.Millisecond, Backoff: 1},
 		},
 	}
 
@@ -335,9 +334,8 @@ func TestPerformHealthCheck_ProcessType(t *testing.T) {
 	runtime := ServiceRuntime{
 		Name: "test-service",
 		HealthCheck: HealthCheckConfig{
-			Type:     "process",
-			Timeout:  3 * time.Second,
-			Interval: 500 * time.Millisecond,
+			Type:  "process",
+			Retry: retry.Policy{MaxAttempts: 6, BaseDelay: 500 * time.Millisecond, Backoff: 1},
 		},
 	}
 
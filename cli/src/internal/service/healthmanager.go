@@ -0,0 +1,370 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jongio/azd-app/cli/src/internal/httpclient"
+	"github.com/jongio/azd-app/cli/src/internal/registry"
+)
+
+// Health states of a running service, as reported by the HealthManager.
+const (
+	HealthStarting = "starting" // Started, but not answering probes yet
+	HealthHealthy  = "healthy"  // Answering probes
+	HealthDegraded = "degraded" // Listening, but its health endpoint fails or doesn't answer in time
+	HealthCrashed  = "crashed"  // Answered probes before, but stopped listening
+)
+
+// Defaults of the probes of a running service.
+const (
+	DefaultHealthInterval = 10 * time.Second
+	DefaultProbeTimeout   = 2 * time.Second
+)
+
+// HealthStatus is the health of a service, as of its last probe.
+type HealthStatus struct {
+	Service     string    `json:"service"`
+	State       string    `json:"state"`
+	URL         string    `json:"url"`                // URL or address probed
+	Error       string    `json:"error,omitempty"`    // Why the last probe failed
+	Failures    int       `json:"failures,omitempty"` // Failed probes in a row
+	LastChecked time.Time `json:"lastChecked"`
+	Since       time.Time `json:"since"` // When the service entered State
+}
+
+// HealthChangeFunc is called when a service enters a new health state.
+type HealthChangeFunc func(status HealthStatus, previous string)
+
+// healthTarget is a service the HealthManager probes.
+type healthTarget struct {
+	name     string
+	port     int
+	path     string // Empty for port probes
+	strict   bool
+	interval time.Duration
+	timeout  time.Duration
+}
+
+// HealthManager probes the services of a project periodically while they run, and records
+// their health in the service registry. Services without a port are not probed.
+type HealthManager struct {
+	projectDir string
+	mu         sync.RWMutex
+	statuses   map[string]*HealthStatus // key: serviceName
+	cancel     context.CancelFunc
+	wg         sync.WaitGroup
+}
+
+var (
+	healthManagers   = make(map[string]*HealthManager)
+	healthManagersMu sync.Mutex
+)
+
+// GetHealthManager returns the health manager for a project directory.
+func GetHealthManager(projectDir string) *HealthManager {
+	if projectDir == "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			projectDir = "."
+		} else {
+			projectDir = cwd
+		}
+	}
+
+	// Normalize path
+	absPath, err := filepath.Abs(projectDir)
+	if err != nil {
+		absPath = projectDir
+	}
+
+	healthManagersMu.Lock()
+	defer healthManagersMu.Unlock()
+
+	if hm, exists := healthManagers[absPath]; exists {
+		return hm
+	}
+	hm := &HealthManager{
+		projectDir: absPath,
+		statuses:   make(map[string]*HealthStatus),
+	}
+	healthManagers[absPath] = hm
+	return hm
+}
+
+// Start probes processes until Stop is called, replacing the services probed before. Services
+// that were probed before keep their state; onChange, if not nil, is called on every change.
+func (m *HealthManager) Start(processes map[string]*ServiceProcess, onChange HealthChangeFunc) {
+	m.Stop()
+
+	var targets []healthTarget
+	for name, process := range processes {
+		if target, ok := newHealthTarget(name, process); ok {
+			targets = append(targets, target)
+		}
+	}
+
+	m.mu.Lock()
+	statuses := make(map[string]*HealthStatus, len(targets))
+	now := time.Now()
+	for _, target := range targets {
+		status, exists := m.statuses[target.name]
+		if !exists {
+			status = &HealthStatus{Service: target.name, State: HealthStarting, Since: now}
+		}
+		status.URL = target.address()
+		statuses[target.name] = status
+	}
+	m.statuses = statuses
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancel = cancel
+	m.mu.Unlock()
+
+	for _, target := range targets {
+		m.wg.Add(1)
+		go func() {
+			defer m.wg.Done()
+			m.run(ctx, target, onChange)
+		}()
+	}
+}
+
+// Stop stops probing and waits for probes in flight.
+func (m *HealthManager) Stop() {
+	m.mu.Lock()
+	cancel := m.cancel
+	m.cancel = nil
+	m.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		m.wg.Wait()
+	}
+}
+
+// Statuses returns the health of the probed services, sorted by name.
+func (m *HealthManager) Statuses() []HealthStatus {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	statuses := make([]HealthStatus, 0, len(m.statuses))
+	for _, status := range m.statuses {
+		statuses = append(statuses, *status)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Service < statuses[j].Service })
+	return statuses
+}
+
+// Status returns the health of the named service.
+func (m *HealthManager) Status(name string) (HealthStatus, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	status, exists := m.statuses[name]
+	if !exists {
+		return HealthStatus{}, false
+	}
+	return *status, true
+}
+
+// run probes target at its interval until ctx is done, starting at once.
+func (m *HealthManager) run(ctx context.Context, target healthTarget, onChange HealthChangeFunc) {
+	ticker := time.NewTicker(target.interval)
+	defer ticker.Stop()
+
+	for {
+		m.probe(target, onChange)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// probe probes target once and records the result.
+func (m *HealthManager) probe(target healthTarget, onChange HealthChangeFunc) {
+	m.mu.RLock()
+	previous := HealthStarting
+	if status, exists := m.statuses[target.name]; exists {
+		previous = status.State
+	}
+	m.mu.RUnlock()
+
+	state, err := target.check(previous)
+	now := time.Now()
+
+	m.mu.Lock()
+	status, exists := m.statuses[target.name]
+	if !exists {
+		// Replaced by a later Start
+		m.mu.Unlock()
+		return
+	}
+	changed := status.State != state
+	if changed {
+		status.State = state
+		status.Since = now
+	}
+	status.LastChecked = now
+	if err != nil {
+		status.Error = err.Error()
+		status.Failures++
+	} else {
+		status.Error, status.Failures = "", 0
+	}
+	snapshot := *status
+	m.mu.Unlock()
+
+	registryStatus := "running"
+	if state == HealthCrashed {
+		registryStatus = "error"
+	}
+	_ = registry.GetRegistry(m.projectDir).UpdateStatus(target.name, registryStatus, state)
+
+	if changed && onChange != nil {
+		onChange(snapshot, previous)
+	}
+}
+
+// newHealthTarget returns how the service of process is probed, if it listens on a port.
+func newHealthTarget(name string, process *ServiceProcess) (healthTarget, bool) {
+	config := process.Runtime.HealthCheck
+	if process.Port <= 0 || config.Type == "process" {
+		return healthTarget{}, false
+	}
+	target := healthTarget{
+		name:     name,
+		port:     process.Port,
+		strict:   config.Strict,
+		interval: config.Interval,
+		timeout:  config.ProbeTimeout,
+	}
+	// Services that signal readiness in their logs or ports are not expected to serve HTTP
+	probe := config.Probe
+	if probe == "" && (config.Type == "log" || config.Type == "port") {
+		probe = "port"
+	}
+	if probe != "port" {
+		target.path = config.Path
+		if target.path == "" {
+			target.path = "/"
+		}
+	}
+	if target.interval <= 0 {
+		target.interval = DefaultHealthInterval
+	}
+	if target.timeout <= 0 {
+		target.timeout = DefaultProbeTimeout
+	}
+	return target, true
+}
+
+// address returns the URL, or for port probes the address, that target probes.
+func (t healthTarget) address() string {
+	if t.path == "" {
+		return fmt.Sprintf("localhost:%d", t.port)
+	}
+	return fmt.Sprintf("http://localhost:%d%s", t.port, t.path)
+}
+
+// check probes the service once and returns its state, given its previous state, and why it
+// is not healthy. A service that doesn't listen is still starting unless it listened before.
+func (t healthTarget) check(previous string) (string, error) {
+	conn, err := net.DialTimeout("tcp", fmt.Sprintf("localhost:%d", t.port), t.timeout)
+	if err != nil {
+		err = fmt.Errorf("port %d not listening", t.port)
+		if previous == HealthStarting {
+			return HealthStarting, err
+		}
+		return HealthCrashed, err
+	}
+	_ = conn.Close()
+
+	if t.path == "" {
+		return HealthHealthy, nil
+	}
+	if err := t.checkHTTP(); err != nil {
+		return HealthDegraded, err
+	}
+	return HealthHealthy, nil
+}
+
+// checkHTTP requests the health endpoint of the service. Any response below 500 is healthy,
+// since not every service answers on /, unless the endpoint was configured with strict set,
+// when only 2xx and 3xx responses are.
+func (t healthTarget) checkHTTP() error {
+	resp, err := httpclient.NewLocal(t.timeout).Get(t.address())
+	if err != nil {
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			return fmt.Errorf("no response from %s within %s", t.path, t.timeout)
+		}
+		return fmt.Errorf("request to %s failed: %w", t.path, err)
+	}
+	defer resp.Body.Close()
+
+	limit := http.StatusInternalServerError
+	if t.strict {
+		limit = http.StatusBadRequest
+	}
+	if resp.StatusCode >= limit {
+		return fmt.Errorf("%s returned HTTP %d", t.path, resp.StatusCode)
+	}
+	return nil
+}
+
+// ApplyHealthSettings sets how the runtimes of services with a health section in azure.yaml
+// are probed while they run. A configured path makes only 2xx and 3xx responses healthy.
+func ApplyHealthSettings(runtimes []*ServiceRuntime, azureYaml *AzureYaml) error {
+	if azureYaml == nil {
+		return nil
+	}
+	for _, rt := range runtimes {
+		svc, ok := azureYaml.Services[rt.Name]
+		if !ok || svc.Health == nil {
+			continue
+		}
+		settings := svc.Health
+		path := "services." + rt.Name + ".health"
+
+		switch settings.Type {
+		case "":
+		case "http", "port":
+			rt.HealthCheck.Probe = settings.Type
+		default:
+			return fmt.Errorf("%s.type: must be 'http' or 'port', not %q", path, settings.Type)
+		}
+		if settings.Path != "" {
+			if !strings.HasPrefix(settings.Path, "/") {
+				return fmt.Errorf("%s.path: must start with '/', not %q", path, settings.Path)
+			}
+			rt.HealthCheck.Path = settings.Path
+			rt.HealthCheck.Strict = true
+		}
+		for _, d := range []struct {
+			name  string
+			value string
+			dest  *time.Duration
+		}{{"interval", settings.Interval, &rt.HealthCheck.Interval}, {"timeout", settings.Timeout, &rt.HealthCheck.ProbeTimeout}} {
+			if d.value == "" {
+				continue
+			}
+			parsed, err := time.ParseDuration(d.value)
+			if err != nil || parsed <= 0 {
+				return fmt.Errorf("%s.%s: invalid duration %q, use e.g. 500ms or 10s", path, d.name, d.value)
+			}
+			*d.dest = parsed
+		}
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+package service
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jongio/azd-app/cli/src/internal/registry"
+)
+
+func TestApplyHealthSettings(t *testing.T) {
+	tests := []struct {
+		name    string
+		health  *HealthSettings
+		want    HealthCheckConfig
+		wantErr string
+	}{
+		{name: "no health section", want: HealthCheckConfig{Type: "http", Path: "/"}},
+		{
+			name:   "all settings",
+			health: &HealthSettings{Type: "http", Path: "/healthz", Interval: "30s", Timeout: "500ms"},
+			want:   HealthCheckConfig{Type: "http", Path: "/healthz", Probe: "http", Strict: true, Interval: 30 * time.Second, ProbeTimeout: 500 * time.Millisecond},
+		},
+		{name: "port probe", health: &HealthSettings{Type: "port"}, want: HealthCheckConfig{Type: "http", Path: "/", Probe: "port"}},
+		{name: "invalid type", health: &HealthSettings{Type: "grpc"}, wantErr: "services.api.health.type"},
+		{name: "relative path", health: &HealthSettings{Path: "health"}, wantErr: "services.api.health.path"},
+		{name: "invalid interval", health: &HealthSettings{Interval: "often"}, wantErr: "services.api.health.interval"},
+		{name: "zero timeout", health: &HealthSettings{Timeout: "0s"}, wantErr: "services.api.health.timeout"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rt := &ServiceRuntime{Name: "api", HealthCheck: HealthCheckConfig{Type: "http", Path: "/"}}
+			azureYaml := &AzureYaml{Services: map[string]Service{"api": {Health: tt.health}}}
+
+			err := ApplyHealthSettings([]*ServiceRuntime{rt}, azureYaml)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ApplyHealthSettings() error = %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ApplyHealthSettings() error = %v", err)
+			}
+			if rt.HealthCheck != tt.want {
+				t.Errorf("health check = %+v, want %+v", rt.HealthCheck, tt.want)
+			}
+		})
+	}
+}
+
+// serverPort returns the port of a test server.
+func serverPort(t *testing.T, server *httptest.Server) int {
+	t.Helper()
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	port, err := strconv.Atoi(u.Port())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return port
+}
+
+// closedPort returns a port nothing listens on.
+func closedPort(t *testing.T) int {
+	t.Helper()
+	listener, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	_ = listener.Close()
+	return port
+}
+
+func TestHealthTargetCheck(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/health":
+			w.WriteHeader(http.StatusOK)
+		case "/broken":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	port, down := serverPort(t, server), closedPort(t)
+
+	tests := []struct {
+		name     string
+		target   healthTarget
+		previous string
+		want     string
+	}{
+		{name: "health endpoint", target: healthTarget{port: port, path: "/health", strict: true}, previous: HealthStarting, want: HealthHealthy},
+		{name: "server error", target: healthTarget{port: port, path: "/broken"}, previous: HealthHealthy, want: HealthDegraded},
+		{name: "not found on default path", target: healthTarget{port: port, path: "/"}, previous: HealthHealthy, want: HealthHealthy},
+		{name: "not found on configured path", target: healthTarget{port: port, path: "/missing", strict: true}, previous: HealthHealthy, want: HealthDegraded},
+		{name: "port probe", target: healthTarget{port: port}, previous: HealthStarting, want: HealthHealthy},
+		{name: "not listening yet", target: healthTarget{port: down, path: "/"}, previous: HealthStarting, want: HealthStarting},
+		{name: "stopped listening", target: healthTarget{port: down, path: "/"}, previous: HealthDegraded, want: HealthCrashed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.target.timeout = time.Second
+			got, err := tt.target.check(tt.previous)
+			if got != tt.want {
+				t.Errorf("check() = %s (%v), want %s", got, err, tt.want)
+			}
+			if (err == nil) != (got == HealthHealthy) {
+				t.Errorf("check() error = %v for state %s", err, got)
+			}
+		})
+	}
+}
+
+func TestHealthManager(t *testing.T) {
+	projectDir := t.TempDir()
+	healthy := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-healthy:
+		default:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+	port := serverPort(t, server)
+
+	reg := registry.GetRegistry(projectDir)
+	if err := reg.Register(&registry.ServiceRegistryEntry{Name: "api", Port: port, Status: "running", Health: "unknown"}); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	changes := make(chan string, 10)
+	manager := GetHealthManager(projectDir)
+	manager.Start(map[string]*ServiceProcess{
+		"api":    {Name: "api", Port: port, Runtime: ServiceRuntime{HealthCheck: HealthCheckConfig{Type: "http", Path: "/", Interval: 20 * time.Millisecond}}},
+		"worker": {Name: "worker", Runtime: ServiceRuntime{HealthCheck: HealthCheckConfig{Type: "process"}}},
+	}, func(status HealthStatus, previous string) {
+		changes <- previous + " -> " + status.State
+	})
+	defer manager.Stop()
+
+	expect := func(want string) {
+		t.Helper()
+		select {
+		case got := <-changes:
+			if got != want {
+				t.Errorf("change = %s, want %s", got, want)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("no change to %s", want)
+		}
+	}
+	expect("starting -> degraded")
+	close(healthy)
+	expect("degraded -> healthy")
+
+	statuses := manager.Statuses()
+	wantURL := "http://localhost:" + strconv.Itoa(port) + "/"
+	if len(statuses) != 1 || statuses[0].Service != "api" || statuses[0].URL != wantURL {
+		t.Errorf("Statuses() = %+v, want only api, probed at %s", statuses, wantURL)
+	}
+	manager.Stop()
+	if entry, _ := reg.GetService("api"); entry.Health != HealthHealthy || entry.Status != "running" {
+		t.Errorf("registry entry = %s/%s, want running/healthy", entry.Status, entry.Health)
+	}
+}
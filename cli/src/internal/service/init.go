@@ -0,0 +1,306 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/jongio/azd-app/cli/src/internal/detector"
+	"github.com/jongio/azd-app/cli/src/internal/types"
+)
+
+// Hosts suggested for detected projects.
+const (
+	hostContainerApp = "containerapp"
+	hostFunction     = "function"
+)
+
+// invalidServiceNameChars matches characters that are replaced in generated service names.
+var invalidServiceNameChars = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// DetectedService is a project found in a workspace that can become an azure.yaml service.
+// When a project could be more than one language, or could be hosted more than one way, the
+// candidates are listed with the suggested one first.
+type DetectedService struct {
+	Name      string   `json:"name"`
+	Dir       string   `json:"dir"`
+	Project   string   `json:"project"` // Project directory relative to azure.yaml
+	Framework string   `json:"framework,omitempty"`
+	Languages []string `json:"languages"`
+	Hosts     []string `json:"hosts"`
+	Dist      string   `json:"dist,omitempty"` // Build output folder, used when hosted on Static Web Apps
+
+	// A composite project, one directory with the files of several languages, is one service
+	// rather than one per language, with the archetype of each language and why the first
+	// language is suggested
+	Archetypes []Archetype `json:"archetypes,omitempty"`
+	Reason     string      `json:"reason,omitempty"`
+}
+
+// Ambiguous reports whether the project has more than one candidate language or host.
+func (d DetectedService) Ambiguous() bool {
+	return len(d.Languages) > 1 || len(d.Hosts) > 1
+}
+
+// Composite reports whether the directory of the project holds the files of several languages.
+func (d DetectedService) Composite() bool {
+	return len(d.Languages) > 1
+}
+
+// Entry returns the azure.yaml service for the project with the given language and host.
+func (d DetectedService) Entry(language, host string) Service {
+	svc := Service{Host: host, Language: language, Project: d.Project}
+	if host == detector.HostStaticWebApp && d.Dist != "" && d.Dist != "." {
+		svc.Dist = d.Dist
+	}
+	return svc
+}
+
+// detectedProject collects what the detectors found in one directory.
+type detectedProject struct {
+	dir        string
+	languages  []string
+	frameworks map[string]string // Framework of each language, when known
+	framework  string
+	function   *types.FunctionProject
+	staticApp  *types.StaticWebApp
+	dockerfile *types.Dockerfile
+}
+
+// DetectServices runs every project detector on rootDir, the directory azure.yaml is written to,
+// and returns a service per project, sorted by name. A directory with the files of several
+// languages is one composite project. Node.js workspace roots with members, solutions, test
+// projects, class libraries, and projects in tool or test folders, such as scripts/ or tests/,
+// are not services; when an Aspire AppHost is found it is the only .NET service, because it
+// starts the projects it references.
+func DetectServices(rootDir string) ([]DetectedService, error) {
+	rootDir, err := filepath.Abs(rootDir)
+	if err != nil {
+		return nil, err
+	}
+	scan, err := detector.ScanWorkspace(rootDir)
+	if err != nil {
+		return nil, err
+	}
+	functions, staticApps := scan.Functions, scan.StaticWebApps
+
+	projects := make(map[string]*detectedProject)
+	project := func(dir string) *detectedProject {
+		if p, ok := projects[dir]; ok {
+			return p
+		}
+		p := &detectedProject{dir: dir}
+		projects[dir] = p
+		return p
+	}
+	addLanguage := func(p *detectedProject, language, framework string) {
+		for _, existing := range p.languages {
+			if existing == language {
+				return
+			}
+		}
+		p.languages = append(p.languages, language)
+		if framework != "" {
+			if p.frameworks == nil {
+				p.frameworks = make(map[string]string)
+			}
+			p.frameworks[language] = framework
+		}
+		if p.framework == "" {
+			p.framework = framework
+		}
+	}
+
+	// Projects an AppHost references are its resources, which it runs
+	managed := make(map[string]bool)
+	for _, role := range detector.AspireRoles(scan.DotnetProjects) {
+		if role.Role == detector.AspireRoleManaged {
+			managed[role.Path] = true
+		}
+	}
+	functionDirs := make(map[string]bool, len(functions))
+	for _, fp := range functions {
+		functionDirs[fp.Dir] = true
+	}
+	for _, dp := range scan.DotnetProjects {
+		dir := filepath.Dir(dp.Path)
+		if !detector.IsDotnetProjectFile(dp.Path) || dp.IsTestProject || managed[dp.Path] {
+			continue
+		}
+		if !dp.IsRunnable() && !functionDirs[dir] {
+			continue
+		}
+		framework := ""
+		if dp.IsAppHost {
+			framework = "Aspire"
+		}
+		p := project(dir)
+		addLanguage(p, "dotnet", framework)
+		p.dockerfile = dp.Dockerfile
+	}
+	for _, pp := range scan.PythonProjects {
+		if pp.IsTestProject {
+			continue
+		}
+		p := project(pp.Dir)
+		addLanguage(p, "python", pp.Framework)
+		p.dockerfile = pp.Dockerfile
+	}
+	nodeMembers := make(map[string]bool)
+	for _, np := range scan.NodeProjects {
+		if np.WorkspaceRoot != "" {
+			nodeMembers[np.WorkspaceRoot] = true
+		}
+	}
+	for _, np := range scan.NodeProjects {
+		if (np.IsWorkspaceRoot && nodeMembers[np.Dir]) || np.IsTestProject {
+			continue
+		}
+		p := project(np.Dir)
+		addLanguage(p, nodeLanguage(np.Dir), np.Framework)
+		p.dockerfile = np.Dockerfile
+	}
+	for i := range functions {
+		p := project(functions[i].Dir)
+		p.function = &functions[i]
+		if language := functionLanguage(functions[i]); language != "" {
+			addLanguage(p, language, "")
+		}
+	}
+	for i := range staticApps {
+		app := &staticApps[i]
+		p := project(filepath.Join(app.Dir, app.AppLocation))
+		p.staticApp = app
+		if len(p.languages) == 0 {
+			addLanguage(p, "js", app.Framework)
+		}
+	}
+	// Folders with only a Dockerfile are container projects
+	for _, df := range scan.Dockerfiles {
+		dir := filepath.Dir(df.Path)
+		if strings.HasPrefix(filepath.Base(dir), ".") {
+			continue // e.g. .devcontainer
+		}
+		if p := project(dir); len(p.languages) == 0 {
+			addLanguage(p, "docker", "")
+			p.dockerfile = &df
+		}
+	}
+
+	var services []DetectedService
+	for _, p := range projects {
+		if len(p.languages) == 0 || detector.InToolOrTestDir(rootDir, p.dir) {
+			continue
+		}
+		rel, err := filepath.Rel(rootDir, p.dir)
+		if err != nil {
+			return nil, err
+		}
+		languages, reason := rankLanguages(p.dir, p.languages, p.dockerfile)
+		d := DetectedService{
+			Dir:       p.dir,
+			Project:   relativeProjectPath(rel),
+			Framework: p.framework,
+			Languages: languages,
+			Hosts:     candidateHosts(p),
+			Dist:      staticAppDist(p.staticApp),
+		}
+		if d.Composite() {
+			d.Archetypes = archetypes(p.dir, languages, p.frameworks)
+			d.Reason = reason
+			d.Framework = p.frameworks[languages[0]]
+		}
+		services = append(services, d)
+	}
+	nameServices(rootDir, services)
+	sort.Slice(services, func(i, j int) bool { return services[i].Name < services[j].Name })
+	return services, nil
+}
+
+// candidateHosts returns the hosts a project can be deployed to, suggested first. Functions
+// apps and frontends recognized by a Static Web Apps config file have a clear host; a frontend
+// recognized only by its framework could also be served from a container, as could a Functions
+// app with a Dockerfile.
+func candidateHosts(p *detectedProject) []string {
+	switch {
+	case p.function != nil && p.dockerfile != nil:
+		return []string{hostFunction, hostContainerApp}
+	case p.function != nil:
+		return []string{hostFunction}
+	case p.staticApp != nil && p.staticApp.Source != "package.json":
+		return []string{detector.HostStaticWebApp}
+	case p.staticApp != nil && p.dockerfile != nil:
+		return []string{hostContainerApp, detector.HostStaticWebApp}
+	case p.staticApp != nil:
+		return []string{detector.HostStaticWebApp, hostContainerApp}
+	default:
+		return []string{hostContainerApp}
+	}
+}
+
+// baseImageHints are substrings of the base images of each language.
+var baseImageHints = map[string]string{
+	"dotnet": "dotnet",
+	"python": "python",
+	"js":     "node",
+	"ts":     "node",
+}
+
+// nodeLanguage returns "ts" for TypeScript projects and "js" otherwise.
+func nodeLanguage(dir string) string {
+	if _, err := os.Stat(filepath.Join(dir, "tsconfig.json")); err == nil {
+		return "ts"
+	}
+	return "js"
+}
+
+// functionLanguage maps a Functions worker runtime to an azure.yaml language.
+func functionLanguage(fp types.FunctionProject) string {
+	switch fp.Runtime {
+	case detector.FunctionsRuntimeDotnet, detector.FunctionsRuntimeDotnetIsolated:
+		return "dotnet"
+	case detector.FunctionsRuntimeNode:
+		return nodeLanguage(fp.Dir)
+	case detector.FunctionsRuntimePython:
+		return "python"
+	}
+	return ""
+}
+
+// staticAppDist returns the build output folder of a Static Web Apps candidate.
+func staticAppDist(app *types.StaticWebApp) string {
+	if app == nil {
+		return ""
+	}
+	return filepath.ToSlash(app.OutputLocation)
+}
+
+// nameServices names each service after its directory, or after the workspace for a project at
+// its root. Directories with the same name are told apart by their path.
+func nameServices(rootDir string, services []DetectedService) {
+	counts := make(map[string]int, len(services))
+	for i := range services {
+		services[i].Name = serviceName(filepath.Base(services[i].Dir))
+		counts[services[i].Name]++
+	}
+	for i := range services {
+		if counts[services[i].Name] < 2 {
+			continue
+		}
+		if rel, err := filepath.Rel(rootDir, services[i].Dir); err == nil && rel != "." {
+			services[i].Name = serviceName(filepath.ToSlash(rel))
+		}
+	}
+}
+
+// serviceName turns a directory name into a service name of lowercase letters, digits, and
+// hyphens.
+func serviceName(name string) string {
+	name = strings.Trim(invalidServiceNameChars.ReplaceAllString(strings.ToLower(name), "-"), "-")
+	if name == "" {
+		return "app"
+	}
+	return name
+}
@@ -0,0 +1,193 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDetectServices(t *testing.T) {
+	files := map[string]string{
+		"api/requirements.txt":             "fastapi\n",
+		"api/main.py":                      "from fastapi import FastAPI\napp = FastAPI()\n",
+		"web/package.json":                 `{"scripts": {"build": "vite build"}, "devDependencies": {"vite": "^5.0.0"}}`,
+		"web/tsconfig.json":                "{}",
+		"docs/staticwebapp.config.json":    "{}",
+		"docs/index.html":                  "<html></html>",
+		"jobs/package.json":                `{"dependencies": {"@azure/functions": "^4.0.0"}}`,
+		"jobs/host.json":                   `{"version": "2.0"}`,
+		"worker/Worker.csproj":             `<Project Sdk="Microsoft.NET.Sdk.Worker"><PropertyGroup><TargetFramework>net8.0</TargetFramework></PropertyGroup></Project>`,
+		"lib/Lib.csproj":                   `<Project Sdk="Microsoft.NET.Sdk"><PropertyGroup><TargetFramework>net8.0</TargetFramework></PropertyGroup></Project>`,
+		"ml/requirements.txt":              "torch\n",
+		"ml/package.json":                  `{"dependencies": {"express": "^4.0.0"}}`,
+		"ml/Dockerfile":                    "FROM node:20\n",
+		"proxy/Dockerfile":                 "FROM nginx\n",
+		".devcontainer/Dockerfile":         "FROM mcr.microsoft.com/devcontainers/base\n",
+		"services/api/requirements.txt":    "flask\n",
+		"services/api/app.py":              "from flask import Flask\napp = Flask(__name__)\n",
+		"services/worker/requirements.txt": "celery\n",
+		"api.tests/Api.Tests.csproj":       `<Project Sdk="Microsoft.NET.Sdk"><PropertyGroup><OutputType>Exe</OutputType></PropertyGroup></Project>`,
+		"checks/requirements.txt":          "pytest\n",
+		"checks/test_api.py":               "def test_health(): pass\n",
+		"smoke/package.json":               `{"scripts": {"test": "playwright test"}}`,
+		"scripts/release/package.json":     `{"scripts": {"start": "node release.js"}}`,
+		"eng/Dockerfile":                   "FROM alpine\n",
+		"tests/fixtures/app/package.json":  `{"dependencies": {"express": "^4.0.0"}}`,
+	}
+	root := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	detected, err := DetectServices(root)
+	if err != nil {
+		t.Fatalf("DetectServices failed: %v", err)
+	}
+
+	type want struct {
+		project   string
+		languages []string
+		hosts     []string
+	}
+	wants := map[string]want{
+		"api":             {"./api", []string{"python"}, []string{"containerapp"}},
+		"docs":            {"./docs", []string{"js"}, []string{"staticwebapp"}},
+		"jobs":            {"./jobs", []string{"js"}, []string{"function"}},
+		"ml":              {"./ml", []string{"js", "python"}, []string{"containerapp"}},
+		"proxy":           {"./proxy", []string{"docker"}, []string{"containerapp"}},
+		"services-api":    {"./services/api", []string{"python"}, []string{"containerapp"}},
+		"services-worker": {"./services/worker", []string{"python"}, []string{"containerapp"}},
+		"web":             {"./web", []string{"ts"}, []string{"staticwebapp", "containerapp"}},
+		"worker":          {"./worker", []string{"dotnet"}, []string{"containerapp"}},
+	}
+	if len(detected) != len(wants) {
+		t.Errorf("detected %d services, want %d: %+v", len(detected), len(wants), detected)
+	}
+	for _, d := range detected {
+		w, ok := wants[d.Name]
+		if !ok {
+			t.Errorf("unexpected service %s (%s)", d.Name, d.Project)
+			continue
+		}
+		if d.Project != w.project || !reflect.DeepEqual(d.Languages, w.languages) || !reflect.DeepEqual(d.Hosts, w.hosts) {
+			t.Errorf("%s = %s %v %v, want %s %v %v", d.Name, d.Project, d.Languages, d.Hosts, w.project, w.languages, w.hosts)
+		}
+		if d.Ambiguous() != (len(w.languages) > 1 || len(w.hosts) > 1) {
+			t.Errorf("%s: Ambiguous() = %v", d.Name, d.Ambiguous())
+		}
+	}
+}
+
+func TestDetectServicesComposite(t *testing.T) {
+	files := map[string]string{
+		"site/package.json":     `{"scripts": {"build:css": "tailwindcss -o static/app.css"}, "devDependencies": {"tailwindcss": "^3.0.0"}}`,
+		"site/requirements.txt": "django\n",
+		"site/manage.py":        "import django\n",
+		"site/Site.csproj":      `<Project Sdk="Microsoft.NET.Sdk.Web"><PropertyGroup><TargetFramework>net8.0</TargetFramework></PropertyGroup></Project>`,
+		"api/requirements.txt":  "fastapi\n",
+	}
+	root := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	detected, err := DetectServices(root)
+	if err != nil {
+		t.Fatalf("DetectServices failed: %v", err)
+	}
+	if len(detected) != 2 {
+		t.Fatalf("detected %+v, want api and one composite site", detected)
+	}
+	api, site := detected[0], detected[1]
+	if api.Composite() || api.Archetypes != nil {
+		t.Errorf("api = %+v, want a project of one language", api)
+	}
+	if !site.Composite() || len(site.Archetypes) != 3 {
+		t.Fatalf("site = %+v, want a composite of three languages", site)
+	}
+	if site.Languages[0] == "js" || site.Reason != reasonToolingAnd {
+		t.Errorf("site languages = %v, reason %q, want Node.js demoted as tooling", site.Languages, site.Reason)
+	}
+	byLanguage := make(map[string][]string)
+	for _, archetype := range site.Archetypes {
+		byLanguage[archetype.Language] = archetype.Files
+	}
+	want := map[string][]string{"dotnet": {"Site.csproj"}, "python": {"requirements.txt"}, "js": {"package.json"}}
+	if !reflect.DeepEqual(byLanguage, want) {
+		t.Errorf("archetype files = %v, want %v", byLanguage, want)
+	}
+}
+
+func TestDetectedServiceEntry(t *testing.T) {
+	d := DetectedService{Name: "web", Project: "./web", Languages: []string{"ts"}, Hosts: []string{"staticwebapp", "containerapp"}, Dist: "dist"}
+
+	if got := d.Entry("ts", "staticwebapp"); got.Dist != "dist" || got.Host != "staticwebapp" || got.Project != "./web" {
+		t.Errorf("Entry(staticwebapp) = %+v", got)
+	}
+	if got := d.Entry("ts", "containerapp"); got.Dist != "" {
+		t.Errorf("Entry(containerapp) should not set dist: %+v", got)
+	}
+}
+
+func TestServiceName(t *testing.T) {
+	tests := map[string]string{
+		"api":          "api",
+		"My_App.Web":   "my-app-web",
+		"services/api": "services-api",
+		"___":          "app",
+	}
+	for name, want := range tests {
+		if got := serviceName(name); got != want {
+			t.Errorf("serviceName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestDetectServicesAspire(t *testing.T) {
+	files := map[string]string{
+		"Shop.AppHost/Shop.AppHost.csproj": `<Project Sdk="Microsoft.NET.Sdk">
+  <Sdk Name="Aspire.AppHost.Sdk" Version="9.1.0" />
+  <ItemGroup><ProjectReference Include="..\Shop.Api\Shop.Api.csproj" /></ItemGroup>
+</Project>`,
+		"Shop.AppHost/Program.cs":                          "",
+		"Shop.Api/Shop.Api.csproj":                         `<Project Sdk="Microsoft.NET.Sdk.Web"><ItemGroup><ProjectReference Include="..\Shop.ServiceDefaults\Shop.ServiceDefaults.csproj" /></ItemGroup></Project>`,
+		"Shop.ServiceDefaults/Shop.ServiceDefaults.csproj": `<Project Sdk="Microsoft.NET.Sdk"><PropertyGroup><IsAspireSharedProject>true</IsAspireSharedProject></PropertyGroup></Project>`,
+		"Admin/Admin.csproj":                               `<Project Sdk="Microsoft.NET.Sdk.Web"></Project>`,
+	}
+	root := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	detected, err := DetectServices(root)
+	if err != nil {
+		t.Fatalf("DetectServices failed: %v", err)
+	}
+	var projects []string
+	for _, d := range detected {
+		projects = append(projects, d.Project)
+	}
+	// The AppHost runs the API; the admin site isn't one of its resources
+	if want := []string{"./Admin", "./Shop.AppHost"}; !reflect.DeepEqual(projects, want) {
+		t.Errorf("projects = %v, want %v", projects, want)
+	}
+}
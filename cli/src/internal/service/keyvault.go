@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// KeyVaultScheme prefixes values that reference a secret of Azure Key Vault, e.g.
+// keyvault://my-vault/db-password, or keyvault://my-vault/db-password/<version> for a version.
+const KeyVaultScheme = "keyvault://"
+
+var (
+	keyVaultNamePattern    = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9-]{1,22}[a-zA-Z0-9]$`)
+	keyVaultSecretPattern  = regexp.MustCompile(`^[a-zA-Z0-9-]{1,127}$`)
+	keyVaultVersionPattern = regexp.MustCompile(`^[a-fA-F0-9]{32}$`)
+)
+
+// SecretRef is a reference to a secret of Azure Key Vault.
+type SecretRef struct {
+	Vault   string
+	Name    string
+	Version string // Empty for the current version
+}
+
+// String returns the reference as written in configuration.
+func (r SecretRef) String() string {
+	s := KeyVaultScheme + r.Vault + "/" + r.Name
+	if r.Version != "" {
+		s += "/" + r.Version
+	}
+	return s
+}
+
+// ParseSecretRef parses a keyvault:// reference. ok is false for values that aren't
+// references; err is set for references that are malformed.
+func ParseSecretRef(value string) (ref SecretRef, ok bool, err error) {
+	rest, ok := strings.CutPrefix(value, KeyVaultScheme)
+	if !ok {
+		return SecretRef{}, false, nil
+	}
+	parts := strings.Split(rest, "/")
+	if len(parts) < 2 || len(parts) > 3 {
+		return SecretRef{}, true, fmt.Errorf("invalid Key Vault reference %q (want %s<vault>/<secret>[/<version>])", value, KeyVaultScheme)
+	}
+	ref = SecretRef{Vault: parts[0], Name: parts[1]}
+	if len(parts) == 3 {
+		ref.Version = parts[2]
+	}
+	switch {
+	case !keyVaultNamePattern.MatchString(ref.Vault):
+		return SecretRef{}, true, fmt.Errorf("invalid Key Vault reference %q: invalid vault name %q", value, ref.Vault)
+	case !keyVaultSecretPattern.MatchString(ref.Name):
+		return SecretRef{}, true, fmt.Errorf("invalid Key Vault reference %q: invalid secret name %q", value, ref.Name)
+	case ref.Version != "" && !keyVaultVersionPattern.MatchString(ref.Version):
+		return SecretRef{}, true, fmt.Errorf("invalid Key Vault reference %q: invalid secret version %q", value, ref.Version)
+	}
+	return ref, true, nil
+}
+
+// SecretResolver replaces Key Vault references in environments with the values of the secrets.
+// Values are fetched once with the developer's Azure credential and kept in memory only, so
+// secrets are never written to .env files, the environment history, or the state directory.
+type SecretResolver struct {
+	mu      sync.Mutex
+	secrets map[SecretRef]*cachedSecret
+	fetch   func(ctx context.Context, ref SecretRef) (string, error)
+}
+
+// cachedSecret is a secret being fetched or fetched already.
+type cachedSecret struct {
+	once  sync.Once
+	value string
+	err   error
+}
+
+// NewSecretResolver creates a resolver that fetches secrets with the Azure CLI.
+func NewSecretResolver() *SecretResolver {
+	return &SecretResolver{secrets: make(map[SecretRef]*cachedSecret), fetch: fetchKeyVaultSecret}
+}
+
+// keyVaultSecrets resolves the Key Vault references of services started by OrchestrateServices.
+var keyVaultSecrets = NewSecretResolver()
+
+// Resolve replaces every value of env that is a Key Vault reference with the secret it names.
+// Services that share a secret fetch it once. Returns an error naming the variables whose
+// secrets couldn't be fetched.
+func (r *SecretResolver) Resolve(ctx context.Context, env map[string]string) error {
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var errs []error
+	for _, key := range keys {
+		ref, ok, err := ParseSecretRef(env[key])
+		if !ok {
+			continue
+		}
+		var value string
+		if err == nil {
+			value, err = r.secret(ctx, ref)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", key, err))
+			continue
+		}
+		env[key] = value
+	}
+	return errors.Join(errs...)
+}
+
+// secret returns the value of a secret, fetching it unless it is cached. Failures aren't
+// cached, so a later start retries after the developer signs in.
+func (r *SecretResolver) secret(ctx context.Context, ref SecretRef) (string, error) {
+	r.mu.Lock()
+	cached, ok := r.secrets[ref]
+	if !ok {
+		cached = &cachedSecret{}
+		r.secrets[ref] = cached
+	}
+	r.mu.Unlock()
+
+	cached.once.Do(func() {
+		cached.value, cached.err = r.fetch(ctx, ref)
+	})
+	if cached.err != nil {
+		r.mu.Lock()
+		if r.secrets[ref] == cached {
+			delete(r.secrets, ref)
+		}
+		r.mu.Unlock()
+	}
+	return cached.value, cached.err
+}
+
+// fetchKeyVaultSecret reads a secret with the Azure CLI, which uses the credential of 'az login'.
+func fetchKeyVaultSecret(ctx context.Context, ref SecretRef) (string, error) {
+	if _, err := exec.LookPath("az"); err != nil {
+		return "", fmt.Errorf("reading %s needs the Azure CLI (az); install it and sign in with 'az login'", ref)
+	}
+	// JSON keeps the value as stored, including trailing newlines, unlike tsv
+	args := []string{"keyvault", "secret", "show", "--vault-name", ref.Vault, "--name", ref.Name, "--query", "value", "--output", "json"}
+	if ref.Version != "" {
+		args = append(args, "--version", ref.Version)
+	}
+	// #nosec G204 -- az with a fixed subcommand; vault and secret names are validated by ParseSecretRef
+	cmd := exec.CommandContext(ctx, "az", args...)
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		detail := strings.TrimSpace(stderr.String())
+		if detail == "" {
+			detail = err.Error()
+		}
+		return "", fmt.Errorf("failed to read %s: %s", ref, detail)
+	}
+	var value string
+	if err := json.Unmarshal(out, &value); err != nil {
+		return "", fmt.Errorf("failed to read %s: unexpected output of az: %w", ref, err)
+	}
+	return value, nil
+}
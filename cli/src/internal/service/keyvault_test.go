@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestParseSecretRef(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    SecretRef
+		ok      bool
+		wantErr bool
+	}{
+		{value: "plain", ok: false},
+		{value: "https://my-vault.vault.azure.net", ok: false},
+		{value: "keyvault://my-vault/db-password", want: SecretRef{Vault: "my-vault", Name: "db-password"}, ok: true},
+		{value: "keyvault://my-vault/db-password/0123456789abcdef0123456789abcdef", want: SecretRef{Vault: "my-vault", Name: "db-password", Version: "0123456789abcdef0123456789abcdef"}, ok: true},
+		{value: "keyvault://my-vault", ok: true, wantErr: true},
+		{value: "keyvault://v/db-password", ok: true, wantErr: true},
+		{value: "keyvault://my-vault/db_password", ok: true, wantErr: true},
+		{value: "keyvault://my-vault/db-password/latest", ok: true, wantErr: true},
+		{value: "keyvault://my-vault/a/b/c", ok: true, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, ok, err := ParseSecretRef(tt.value)
+			if ok != tt.ok || (err != nil) != tt.wantErr {
+				t.Fatalf("ParseSecretRef(%q) = %v, %v, %v; want ok %v, error %v", tt.value, got, ok, err, tt.ok, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseSecretRef(%q) = %+v, want %+v", tt.value, got, tt.want)
+			}
+			if ok && err == nil && got.String() != tt.value {
+				t.Errorf("String() = %q, want %q", got.String(), tt.value)
+			}
+		})
+	}
+}
+
+func TestSecretResolverResolve(t *testing.T) {
+	var mu sync.Mutex
+	fetched := make(map[SecretRef]int)
+	resolver := NewSecretResolver()
+	resolver.fetch = func(ctx context.Context, ref SecretRef) (string, error) {
+		mu.Lock()
+		fetched[ref]++
+		mu.Unlock()
+		if ref.Vault == "locked-vault" {
+			return "", errors.New("forbidden")
+		}
+		return "secret-of-" + ref.Name, nil
+	}
+
+	env := map[string]string{
+		"DB_PASSWORD": "keyvault://my-vault/db-password",
+		"LOG_LEVEL":   "debug",
+	}
+	if err := resolver.Resolve(context.Background(), env); err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	if env["DB_PASSWORD"] != "secret-of-db-password" || env["LOG_LEVEL"] != "debug" {
+		t.Errorf("Resolve() env = %v", env)
+	}
+
+	// Another service referencing the same secret uses the cached value
+	other := map[string]string{"PASSWORD": "keyvault://my-vault/db-password"}
+	if err := resolver.Resolve(context.Background(), other); err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	if other["PASSWORD"] != "secret-of-db-password" {
+		t.Errorf("Resolve() env = %v", other)
+	}
+	if n := fetched[SecretRef{Vault: "my-vault", Name: "db-password"}]; n != 1 {
+		t.Errorf("secret fetched %d times, want once", n)
+	}
+
+	// Failures name the variable and aren't cached
+	failing := map[string]string{"API_KEY": "keyvault://locked-vault/api-key", "BAD": "keyvault://x"}
+	for i := 0; i < 2; i++ {
+		err := resolver.Resolve(context.Background(), failing)
+		if err == nil || !strings.Contains(err.Error(), "API_KEY: forbidden") || !strings.Contains(err.Error(), "BAD: invalid Key Vault reference") {
+			t.Errorf("Resolve() error = %v, want errors for API_KEY and BAD", err)
+		}
+	}
+	if n := fetched[SecretRef{Vault: "locked-vault", Name: "api-key"}]; n != 2 {
+		t.Errorf("failed secret fetched %d times, want a retry on each resolve", n)
+	}
+}
+
+func TestFetchKeyVaultSecretKeepsTrailingNewlines(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake az is a shell script")
+	}
+	binDir := t.TempDir()
+	// az prints the value as a JSON string, followed by a newline of its own
+	script := "#!/bin/sh\nprintf '\"-----BEGIN KEY-----\\\\nabc\\\\n\"\\n'\n"
+	if err := os.WriteFile(filepath.Join(binDir, "az"), []byte(script), 0700); err != nil {
+		t.Fatalf("failed to write fake az: %v", err)
+	}
+	t.Setenv("PATH", binDir)
+
+	value, err := fetchKeyVaultSecret(context.Background(), SecretRef{Vault: "vault", Name: "key"})
+	if err != nil {
+		t.Fatalf("fetchKeyVaultSecret() error = %v", err)
+	}
+	if want := "-----BEGIN KEY-----\nabc\n"; value != want {
+		t.Errorf("fetchKeyVaultSecret() = %q, want %q", value, want)
+	}
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"fmt"
+	"maps"
+	"path/filepath"
+	"strings"
+
+	"github.com/jongio/azd-app/cli/src/internal/detector"
+)
+
+// launchProfileKey is the key of the config of a .NET service in azure.yaml that selects the
+// launch profile of Properties/launchSettings.json it runs with.
+const launchProfileKey = "launchProfile"
+
+// LaunchProfileName returns the launch profile selected for service with config.launchProfile,
+// or "" to run with the profile dotnet run uses by default.
+func LaunchProfileName(service Service) string {
+	name, _ := service.Config[launchProfileKey].(string)
+	return strings.TrimSpace(name)
+}
+
+// ApplyLaunchProfiles returns services with the launch profiles of specs selected, as with
+// --launch-profile. A spec is service=profile for one service, or a bare profile for each .NET
+// service whose project has a profile of that name. It fails when a spec names a service that
+// isn't in services, or when no service has a bare profile.
+func ApplyLaunchProfiles(services map[string]Service, azureYamlDir string, specs []string) (map[string]Service, error) {
+	if len(specs) == 0 {
+		return services, nil
+	}
+	result := maps.Clone(services)
+	selectProfile := func(name, profile string) {
+		svc := result[name]
+		svc.Config = maps.Clone(svc.Config)
+		if svc.Config == nil {
+			svc.Config = make(map[string]interface{})
+		}
+		svc.Config[launchProfileKey] = profile
+		result[name] = svc
+	}
+
+	for _, spec := range specs {
+		name, profile, targeted := strings.Cut(spec, "=")
+		if !targeted {
+			name, profile = "", spec
+		}
+		name, profile = strings.TrimSpace(name), strings.TrimSpace(profile)
+		if profile == "" {
+			return nil, fmt.Errorf("invalid launch profile %q: want profile or service=profile", spec)
+		}
+		if targeted {
+			if _, ok := result[name]; !ok {
+				return nil, fmt.Errorf("invalid launch profile %q: no service %s", spec, name)
+			}
+			selectProfile(name, profile)
+			continue
+		}
+
+		var matched []string
+		for name, svc := range result {
+			if hasLaunchProfile(svc, azureYamlDir, profile) {
+				matched = append(matched, name)
+			}
+		}
+		if len(matched) == 0 {
+			return nil, fmt.Errorf("no .NET service has a launch profile %q", profile)
+		}
+		for _, name := range matched {
+			selectProfile(name, profile)
+		}
+	}
+	return result, nil
+}
+
+// hasLaunchProfile reports whether the .NET project of service has a launch profile named
+// profile that dotnet run can use.
+func hasLaunchProfile(service Service, azureYamlDir, profile string) bool {
+	if service.Project == "" {
+		return false
+	}
+	projectDir := service.Project
+	if !filepath.IsAbs(projectDir) {
+		projectDir = filepath.Join(azureYamlDir, projectDir)
+	}
+	project := findRunnableDotnetProject(filepath.Clean(projectDir))
+	if project == nil {
+		return false
+	}
+	_, err := detector.FindLaunchProfile(project.LaunchProfiles, profile)
+	return err == nil
+}
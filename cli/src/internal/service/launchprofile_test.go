@@ -0,0 +1,86 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// writeLaunchProject writes a web project with launch settings to dir/name.
+func writeLaunchProject(t *testing.T, dir, name, settings string) {
+	t.Helper()
+	projectDir := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Join(projectDir, "Properties"), 0750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(projectDir, name+".csproj"), []byte(`<Project Sdk="Microsoft.NET.Sdk.Web"></Project>`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(projectDir, "Properties", "launchSettings.json"), []byte(settings), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestApplyLaunchProfiles(t *testing.T) {
+	dir := t.TempDir()
+	writeLaunchProject(t, dir, "api", `{"profiles": {"https": {"commandName": "Project"}, "staging": {"commandName": "Project"}}}`)
+	writeLaunchProject(t, dir, "admin", `{"profiles": {"https": {"commandName": "Project"}}}`)
+	services := map[string]Service{
+		"api":   {Project: "./api", Language: "csharp"},
+		"admin": {Project: "./admin", Language: "csharp", Config: map[string]interface{}{"port": 5100}},
+		"web":   {Project: "./web", Language: "js"},
+	}
+
+	got, err := ApplyLaunchProfiles(services, dir, []string{"https", "api=staging"})
+	if err != nil {
+		t.Fatalf("ApplyLaunchProfiles() error = %v", err)
+	}
+	for name, want := range map[string]string{"api": "staging", "admin": "https", "web": ""} {
+		if profile := LaunchProfileName(got[name]); profile != want {
+			t.Errorf("%s launch profile = %q, want %q", name, profile, want)
+		}
+	}
+	if got["admin"].Config["port"] != 5100 {
+		t.Errorf("admin config = %v, want its port kept", got["admin"].Config)
+	}
+	if services["admin"].Config[launchProfileKey] != nil {
+		t.Error("ApplyLaunchProfiles() changed the config of the services it was given")
+	}
+
+	for _, specs := range [][]string{{"staging=https"}, {"missing"}, {"api="}} {
+		if _, err := ApplyLaunchProfiles(services, dir, specs); err == nil {
+			t.Errorf("ApplyLaunchProfiles(%v) succeeded", specs)
+		}
+	}
+}
+
+func TestDetectServiceRuntimeLaunchProfile(t *testing.T) {
+	dir := t.TempDir()
+	writeLaunchProject(t, dir, "api", `{"profiles": {
+  "https": {"commandName": "Project", "applicationUrl": "https://localhost:7001;http://localhost:5001"},
+  "staging": {"commandName": "Project", "applicationUrl": "http://localhost:5002"}
+}}`)
+
+	runtime, err := PreviewServiceRuntime("api", Service{Project: "./api", Language: "csharp"}, dir, "azd")
+	if err != nil {
+		t.Fatalf("PreviewServiceRuntime() error = %v", err)
+	}
+	if runtime.Port != 5001 || slices.Contains(runtime.Args, "--launch-profile") {
+		t.Errorf("default profile: port %d, args %v", runtime.Port, runtime.Args)
+	}
+
+	svc := Service{Project: "./api", Language: "csharp", Config: map[string]interface{}{launchProfileKey: "staging"}}
+	runtime, err = PreviewServiceRuntime("api", svc, dir, "azd")
+	if err != nil {
+		t.Fatalf("PreviewServiceRuntime() error = %v", err)
+	}
+	if runtime.Port != 5002 || !slices.Equal(runtime.Args[len(runtime.Args)-2:], []string{"--launch-profile", "staging"}) {
+		t.Errorf("staging profile: port %d, args %v", runtime.Port, runtime.Args)
+	}
+
+	svc.Config[launchProfileKey] = "missing"
+	if _, err := PreviewServiceRuntime("api", svc, dir, "azd"); err == nil {
+		t.Error("PreviewServiceRuntime() succeeded with a missing launch profile")
+	}
+}
@@ -8,6 +8,8 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/jongio/azd-app/cli/src/internal/statedir"
 )
 
 // LogBuffer is a circular buffer for storing service logs with pub/sub support.
@@ -22,6 +24,7 @@ type LogBuffer struct {
 	fileWriter  *bufio.Writer
 	file        *os.File
 	fileMu      sync.Mutex
+	sink        func(LogEntry) // Optional: receives every added entry; see LogManager.SetSink
 }
 
 // NewLogBuffer creates a new log buffer for a service.
@@ -35,7 +38,12 @@ func NewLogBuffer(serviceName string, maxSize int, enableFileLogging bool, proje
 
 	// Setup file logging if enabled
 	if enableFileLogging {
-		logsDir := filepath.Join(projectDir, ".azure", "logs")
+		// Each process logs to its own session directory so concurrent sessions never share files
+		sessionDir, err := statedir.SessionDir(projectDir)
+		if err != nil {
+			return nil, err
+		}
+		logsDir := filepath.Join(sessionDir, "logs")
 		if err := os.MkdirAll(logsDir, 0750); err != nil {
 			return nil, fmt.Errorf("failed to create logs directory: %w", err)
 		}
@@ -72,6 +80,10 @@ func (lb *LogBuffer) Add(entry LogEntry) {
 		lb.fileMu.Unlock()
 	}
 
+	if lb.sink != nil {
+		lb.sink(entry)
+	}
+
 	// Broadcast to subscribers
 	lb.broadcast(entry)
 }
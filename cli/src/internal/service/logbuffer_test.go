@@ -6,9 +6,12 @@ import (
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/jongio/azd-app/cli/src/internal/statedir"
 )
 
 func TestNewLogBuffer(t *testing.T) {
+	t.Setenv(statedir.RootEnvVar, t.TempDir())
 	tmpDir := t.TempDir()
 
 	tests := []struct {
@@ -67,6 +70,13 @@ func TestNewLogBuffer(t *testing.T) {
 					if buffer.file == nil {
 						t.Error("file is nil when file logging enabled")
 					}
+					sessionDir, err := statedir.SessionDir(tmpDir)
+					if err != nil {
+						t.Fatalf("SessionDir failed: %v", err)
+					}
+					if filepath.Dir(buffer.filePath) != filepath.Join(sessionDir, "logs") {
+						t.Errorf("filePath = %s, want a file in %s", buffer.filePath, filepath.Join(sessionDir, "logs"))
+					}
 				}
 			}
 		})
@@ -362,6 +372,7 @@ func TestLogBuffer_Clear(t *testing.T) {
 }
 
 func TestLogBuffer_FileLogging(t *testing.T) {
+	t.Setenv(statedir.RootEnvVar, t.TempDir())
 	tmpDir := t.TempDir()
 	serviceName := "file-test"
 
@@ -388,7 +399,11 @@ func TestLogBuffer_FileLogging(t *testing.T) {
 	}
 
 	// Verify file was created and contains entries
-	logPath := filepath.Join(tmpDir, ".azure", "logs", serviceName+".log")
+	sessionDir, err := statedir.SessionDir(tmpDir)
+	if err != nil {
+		t.Fatalf("SessionDir failed: %v", err)
+	}
+	logPath := filepath.Join(sessionDir, "logs", serviceName+".log")
 	content, err := os.ReadFile(logPath)
 	if err != nil {
 		t.Fatalf("Failed to read log file: %v", err)
@@ -403,6 +418,7 @@ func TestLogBuffer_FileLogging(t *testing.T) {
 }
 
 func TestLogBuffer_Close(t *testing.T) {
+	t.Setenv(statedir.RootEnvVar, t.TempDir())
 	tmpDir := t.TempDir()
 	buffer, err := NewLogBuffer("test", 100, true, tmpDir)
 	if err != nil {
@@ -0,0 +1,242 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jongio/azd-app/cli/src/internal/statedir"
+)
+
+// logFileTimeFormat is the timestamp format of log file lines, in local time.
+const logFileTimeFormat = "2006-01-02 15:04:05.000"
+
+// logFilePollInterval is how often FollowLogFiles checks log files for new lines.
+const logFilePollInterval = 250 * time.Millisecond
+
+// logLinePattern matches a log file line written by LogBuffer: [time] [LEVEL] [OUT|ERR] message.
+var logLinePattern = regexp.MustCompile(`^\[([0-9-]+ [0-9:.]+)\] \[([A-Z]+)\] \[(OUT|ERR)\] ?(.*)$`)
+
+// logLevels maps the level names of log file lines to levels.
+var logLevels = map[string]LogLevel{
+	"INFO":  LogLevelInfo,
+	"WARN":  LogLevelWarn,
+	"ERROR": LogLevelError,
+	"DEBUG": LogLevelDebug,
+}
+
+// LogSessionDirs returns the session directories whose service logs describe the current
+// state of projectDir: those of running sessions, so a second terminal sees what 'run' is
+// doing, or the most recent session when none is running, so logs can be read after a run.
+// Session directories of exited processes are kept until the next session starts.
+func LogSessionDirs(projectDir string) ([]string, error) {
+	ws, err := statedir.Get(projectDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var running []string
+	latest, latestTime := "", time.Time{}
+	for _, session := range ws.ListSessions() {
+		if session.Running {
+			running = append(running, session.Dir)
+		}
+		if modified := statedir.LastModified(session.Dir); latest == "" || modified.After(latestTime) {
+			latest, latestTime = session.Dir, modified
+		}
+	}
+	if len(running) > 0 {
+		sort.Strings(running)
+		return running, nil
+	}
+	if latest == "" {
+		return nil, nil
+	}
+	return []string{latest}, nil
+}
+
+// LogFiles returns the log files of each service in sessionDirs, keyed by service name.
+func LogFiles(sessionDirs []string) map[string][]string {
+	files := make(map[string][]string)
+	for _, dir := range sessionDirs {
+		paths, _ := filepath.Glob(filepath.Join(dir, "logs", "*.log"))
+		sort.Strings(paths)
+		for _, path := range paths {
+			name := strings.TrimSuffix(filepath.Base(path), ".log")
+			files[name] = append(files[name], path)
+		}
+	}
+	return files
+}
+
+// ParseLogLine parses a log file line of serviceName. Lines that don't start with a log
+// prefix, such as output written before the format was introduced, are returned as info
+// entries without a timestamp.
+func ParseLogLine(serviceName, line string) LogEntry {
+	match := logLinePattern.FindStringSubmatch(line)
+	if match == nil {
+		return LogEntry{Service: serviceName, Message: line, Level: LogLevelInfo}
+	}
+	entry := LogEntry{Service: serviceName, Message: match[4], IsStderr: match[3] == "ERR"}
+	entry.Level = logLevels[match[2]]
+	if ts, err := time.ParseInLocation(logFileTimeFormat, match[1], time.Local); err == nil {
+		entry.Timestamp = ts
+	}
+	return entry
+}
+
+// ReadLogFile returns the entries of a service log file.
+func ReadLogFile(path, serviceName string) ([]LogEntry, error) {
+	// #nosec G304 -- Log files in the workspace state directory
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var entries []LogEntry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		entry := ParseLogLine(serviceName, scanner.Text())
+		if entry.Timestamp.IsZero() && len(entries) > 0 {
+			entry.Timestamp = entries[len(entries)-1].Timestamp // Keep unprefixed lines in place when sorting
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return entries, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return entries, nil
+}
+
+// ReadLogFiles returns the entries of the service logs in sessionDirs, sorted by time. services
+// limits the services read; empty reads all. Entries are limited to those since since when it is
+// set, and otherwise to the last tail entries of each service.
+func ReadLogFiles(sessionDirs []string, services []string, tail int, since time.Time) ([]LogEntry, error) {
+	files := LogFiles(sessionDirs)
+	if len(services) == 0 {
+		for name := range files {
+			services = append(services, name)
+		}
+	}
+
+	var all []LogEntry
+	for _, name := range services {
+		var entries []LogEntry
+		for _, path := range files[name] {
+			fileEntries, err := ReadLogFile(path, name)
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, fileEntries...)
+		}
+		if !since.IsZero() {
+			recent := entries[:0]
+			for _, entry := range entries {
+				if !entry.Timestamp.Before(since) {
+					recent = append(recent, entry)
+				}
+			}
+			entries = recent
+		} else if tail > 0 && len(entries) > tail {
+			entries = entries[len(entries)-tail:]
+		}
+		all = append(all, entries...)
+	}
+
+	sort.SliceStable(all, func(i, j int) bool { return all[i].Timestamp.Before(all[j].Timestamp) })
+	return all, nil
+}
+
+// FollowLogFiles calls fn with every line appended to the service logs of sessionDirs until
+// ctx is done, including the logs of services that start later. Content present when it is
+// called is skipped. services limits the services followed; empty follows all.
+func FollowLogFiles(ctx context.Context, sessionDirs []string, services []string, fn func(LogEntry)) error {
+	wanted := make(map[string]bool, len(services))
+	for _, name := range services {
+		wanted[name] = true
+	}
+
+	offsets := make(map[string]int64)
+	partial := make(map[string]string)
+	scan := func(initial bool) {
+		files := LogFiles(sessionDirs)
+		names := make([]string, 0, len(files))
+		for name := range files {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if len(wanted) > 0 && !wanted[name] {
+				continue
+			}
+			for _, path := range files[name] {
+				if initial {
+					if info, err := os.Stat(path); err == nil {
+						offsets[path] = info.Size()
+					}
+					continue
+				}
+				lines, next := readAppended(path, offsets[path], partial[path])
+				offsets[path] = next.offset
+				partial[path] = next.partial
+				for _, line := range lines {
+					fn(ParseLogLine(name, strings.TrimSuffix(line, "\r")))
+				}
+			}
+		}
+	}
+
+	scan(true)
+	ticker := time.NewTicker(logFilePollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			scan(false)
+		}
+	}
+}
+
+// readPosition is where FollowLogFiles continues reading a log file.
+type readPosition struct {
+	offset  int64
+	partial string // Start of a line whose end has not been written yet
+}
+
+// readAppended returns the complete lines written to path after offset. A file that shrank
+// was recreated, for example by a restarted session, and is read from the start.
+func readAppended(path string, offset int64, partial string) ([]string, readPosition) {
+	// #nosec G304 -- Log files in the workspace state directory
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, readPosition{offset, partial}
+	}
+	defer file.Close()
+
+	if info, err := file.Stat(); err == nil && info.Size() < offset {
+		offset, partial = 0, ""
+	}
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return nil, readPosition{offset, partial}
+	}
+	data, err := io.ReadAll(file)
+	if err != nil || len(data) == 0 {
+		return nil, readPosition{offset, partial}
+	}
+
+	text := partial + string(data)
+	lines := strings.Split(text, "\n")
+	rest := lines[len(lines)-1]
+	return lines[:len(lines)-1], readPosition{offset + int64(len(data)), rest}
+}
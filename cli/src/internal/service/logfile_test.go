@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jongio/azd-app/cli/src/internal/statedir"
+)
+
+func TestParseLogLine(t *testing.T) {
+	entry := ParseLogLine("api", "[2024-05-01 10:20:30.456] [WARN] [ERR] disk almost full")
+	if entry.Service != "api" || entry.Message != "disk almost full" {
+		t.Errorf("ParseLogLine() = %+v", entry)
+	}
+	if entry.Level != LogLevelWarn || !entry.IsStderr {
+		t.Errorf("ParseLogLine() level = %v, stderr = %v, want WARN on stderr", entry.Level, entry.IsStderr)
+	}
+	want := time.Date(2024, 5, 1, 10, 20, 30, 456000000, time.Local)
+	if !entry.Timestamp.Equal(want) {
+		t.Errorf("ParseLogLine() timestamp = %v, want %v", entry.Timestamp, want)
+	}
+
+	plain := ParseLogLine("api", "  at main.go:12")
+	if plain.Message != "  at main.go:12" || plain.Level != LogLevelInfo || !plain.Timestamp.IsZero() {
+		t.Errorf("ParseLogLine() of unprefixed line = %+v", plain)
+	}
+}
+
+func TestReadLogFilesFromSession(t *testing.T) {
+	t.Setenv(statedir.RootEnvVar, t.TempDir())
+	projectDir := t.TempDir()
+
+	start := time.Now().Add(-time.Minute).Truncate(time.Millisecond)
+	for _, name := range []string{"api", "web"} {
+		buffer, err := NewLogBuffer(name, 100, true, projectDir)
+		if err != nil {
+			t.Fatalf("NewLogBuffer() error = %v", err)
+		}
+		for i := 0; i < 3; i++ {
+			buffer.Add(LogEntry{Service: name, Message: name + " line", Level: LogLevelInfo, Timestamp: start.Add(time.Duration(i) * time.Second)})
+		}
+		if err := buffer.Close(); err != nil {
+			t.Fatalf("Close() error = %v", err)
+		}
+	}
+
+	sessionDirs, err := LogSessionDirs(projectDir)
+	if err != nil {
+		t.Fatalf("LogSessionDirs() error = %v", err)
+	}
+	if len(sessionDirs) != 1 {
+		t.Fatalf("LogSessionDirs() = %v, want the session of this process", sessionDirs)
+	}
+
+	all, err := ReadLogFiles(sessionDirs, nil, 0, time.Time{})
+	if err != nil {
+		t.Fatalf("ReadLogFiles() error = %v", err)
+	}
+	if len(all) != 6 {
+		t.Fatalf("ReadLogFiles() returned %d entries, want 6", len(all))
+	}
+	for i := 1; i < len(all); i++ {
+		if all[i].Timestamp.Before(all[i-1].Timestamp) {
+			t.Errorf("ReadLogFiles() entries not sorted by time at %d", i)
+		}
+	}
+
+	tail, err := ReadLogFiles(sessionDirs, []string{"web"}, 2, time.Time{})
+	if err != nil {
+		t.Fatalf("ReadLogFiles() error = %v", err)
+	}
+	if len(tail) != 2 || tail[0].Service != "web" || !tail[1].Timestamp.Equal(start.Add(2*time.Second)) {
+		t.Errorf("ReadLogFiles() with tail = %+v, want the last 2 web entries", tail)
+	}
+
+	since, err := ReadLogFiles(sessionDirs, []string{"api"}, 0, start.Add(time.Second))
+	if err != nil {
+		t.Fatalf("ReadLogFiles() error = %v", err)
+	}
+	if len(since) != 2 {
+		t.Errorf("ReadLogFiles() with since returned %d entries, want 2", len(since))
+	}
+}
+
+func TestFollowLogFiles(t *testing.T) {
+	sessionDir := t.TempDir()
+	logDir := filepath.Join(sessionDir, "logs")
+	if err := os.MkdirAll(logDir, 0750); err != nil {
+		t.Fatal(err)
+	}
+	apiLog := filepath.Join(logDir, "api.log")
+	if err := os.WriteFile(apiLog, []byte("[2024-05-01 10:00:00.000] [INFO] [OUT] before\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	var mu sync.Mutex
+	var got []LogEntry
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- FollowLogFiles(ctx, []string{sessionDir}, nil, func(entry LogEntry) {
+			mu.Lock()
+			got = append(got, entry)
+			mu.Unlock()
+		})
+	}()
+	time.Sleep(2 * logFilePollInterval)
+
+	file, err := os.OpenFile(apiLog, os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = file.WriteString("[2024-05-01 10:00:01.000] [ERROR] [ERR] after\n[2024-05-01 10:00:02.000] [INFO] [OUT] part")
+	_ = file.Close()
+	if err := os.WriteFile(filepath.Join(logDir, "web.log"), []byte("[2024-05-01 10:00:03.000] [INFO] [OUT] new service\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		mu.Lock()
+		n := len(got)
+		mu.Unlock()
+		if n >= 2 {
+			break
+		}
+		time.Sleep(logFilePollInterval)
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("FollowLogFiles() error = %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(got) != 2 {
+		t.Fatalf("FollowLogFiles() delivered %+v, want the appended line and the new service's line", got)
+	}
+	messages := map[string]string{got[0].Service: got[0].Message, got[1].Service: got[1].Message}
+	if messages["api"] != "after" || messages["web"] != "new service" {
+		t.Errorf("FollowLogFiles() delivered %+v", got)
+	}
+}
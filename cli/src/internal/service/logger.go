@@ -5,6 +5,8 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/jongio/azd-app/cli/src/internal/output"
 )
 
 // ServiceLogger handles multiplexed log output from multiple services.
@@ -13,6 +15,7 @@ type ServiceLogger struct {
 	verbose    bool
 	colors     map[string]string
 	colorIndex int
+	records    *RecordWriter // Set by run, for JSON output and the run history
 }
 
 // ANSI color codes for service output
@@ -29,12 +32,6 @@ var colorCodes = []string{
 	"\033[92m", // Bright Green
 }
 
-const (
-	colorReset = "\033[0m"
-	colorBold  = "\033[1m"
-	colorGray  = "\033[90m"
-)
-
 // NewServiceLogger creates a new logger for service orchestration.
 func NewServiceLogger(verbose bool) *ServiceLogger {
 	return &ServiceLogger{
@@ -44,6 +41,20 @@ func NewServiceLogger(verbose bool) *ServiceLogger {
 	}
 }
 
+// SetRecords sets the writer that lifecycle events of services are written to as JSON records.
+func (l *ServiceLogger) SetRecords(records *RecordWriter) {
+	l.records = records
+}
+
+// Records returns the writer of JSON records, or nil when events are not written. Its methods
+// do nothing when it is nil.
+func (l *ServiceLogger) Records() *RecordWriter {
+	if l == nil {
+		return nil
+	}
+	return l.records
+}
+
 // getServiceColor returns a consistent color for a service.
 func (l *ServiceLogger) getServiceColor(serviceName string) string {
 	l.mu.Lock()
@@ -54,6 +65,9 @@ func (l *ServiceLogger) getServiceColor(serviceName string) string {
 // getServiceColorUnsafe returns a consistent color for a service without locking.
 // Must be called with mutex already held.
 func (l *ServiceLogger) getServiceColorUnsafe(serviceName string) string {
+	if !output.ColorEnabled() {
+		return ""
+	}
 	if color, exists := l.colors[serviceName]; exists {
 		return color
 	}
@@ -71,10 +85,10 @@ func (l *ServiceLogger) FormatLogEntry(serviceName string, message string) strin
 	color := l.getServiceColor(serviceName)
 
 	// Format: HH:MM:SS service-name │ message
-	return fmt.Sprintf("%s%s%s %s%-15s%s %s│%s %s",
-		colorGray, timestamp, colorReset,
-		color, serviceName, colorReset,
-		colorGray, colorReset,
+	return fmt.Sprintf("%s%s%s %s%s%s %s│%s %s",
+		output.Gray, timestamp, output.Reset,
+		color, output.PadRight(serviceName, 15), output.Reset,
+		output.Gray, output.Reset,
 		message)
 }
 
@@ -85,13 +99,13 @@ func (l *ServiceLogger) LogService(serviceName string, message string) {
 
 	// Format the message without calling getServiceColor again
 	timestamp := time.Now().Format("15:04:05")
-	formatted := fmt.Sprintf("%s%s%s %s%-15s%s %s│%s %s",
-		colorGray, timestamp, colorReset,
-		color, serviceName, colorReset,
-		colorGray, colorReset,
+	formatted := fmt.Sprintf("%s%s%s %s%s%s %s│%s %s",
+		output.Gray, timestamp, output.Reset,
+		color, output.PadRight(serviceName, 15), output.Reset,
+		output.Gray, output.Reset,
 		message)
 
-	fmt.Println(formatted)
+	fmt.Fprintln(output.Writer(), formatted)
 }
 
 // LogInfo logs an informational message (no service prefix).
@@ -100,7 +114,7 @@ func (l *ServiceLogger) LogInfo(message string) {
 	defer l.mu.Unlock()
 
 	timestamp := time.Now().Format("15:04:05")
-	fmt.Printf("%s%s%s %s\n", colorGray, timestamp, colorReset, message)
+	fmt.Fprintf(output.Writer(), "%s%s%s %s\n", output.Gray, timestamp, output.Reset, message)
 }
 
 // LogSuccess logs a success message with green color.
@@ -111,10 +125,10 @@ func (l *ServiceLogger) LogSuccess(serviceName string, message string) {
 	timestamp := time.Now().Format("15:04:05")
 	color := l.getServiceColorUnsafe(serviceName)
 
-	fmt.Printf("%s%s%s %s%-15s%s %s✓%s %s\n",
-		colorGray, timestamp, colorReset,
-		color, serviceName, colorReset,
-		"\033[92m", colorReset,
+	fmt.Fprintf(output.Writer(), "%s%s%s %s%s%s %s✓%s %s\n",
+		output.Gray, timestamp, output.Reset,
+		color, output.PadRight(serviceName, 15), output.Reset,
+		output.BrightGreen, output.Reset,
 		message)
 }
 
@@ -126,10 +140,10 @@ func (l *ServiceLogger) LogError(serviceName string, message string) {
 	timestamp := time.Now().Format("15:04:05")
 	color := l.getServiceColorUnsafe(serviceName)
 
-	fmt.Printf("%s%s%s %s%-15s%s %s✗%s %s\n",
-		colorGray, timestamp, colorReset,
-		color, serviceName, colorReset,
-		"\033[91m", colorReset,
+	fmt.Fprintf(output.ErrWriter(), "%s%s%s %s%s%s %s✗%s %s\n",
+		output.Gray, timestamp, output.Reset,
+		color, output.PadRight(serviceName, 15), output.Reset,
+		output.BrightRed, output.Reset,
 		message)
 }
 
@@ -141,10 +155,10 @@ func (l *ServiceLogger) LogWarning(serviceName string, message string) {
 	timestamp := time.Now().Format("15:04:05")
 	color := l.getServiceColorUnsafe(serviceName)
 
-	fmt.Printf("%s%s%s %s%-15s%s %s⚠%s  %s\n",
-		colorGray, timestamp, colorReset,
-		color, serviceName, colorReset,
-		"\033[93m", colorReset,
+	fmt.Fprintf(output.ErrWriter(), "%s%s%s %s%s%s %s⚠%s  %s\n",
+		output.Gray, timestamp, output.Reset,
+		color, output.PadRight(serviceName, 15), output.Reset,
+		output.BrightYellow, output.Reset,
 		message)
 }
 
@@ -162,43 +176,38 @@ func (l *ServiceLogger) LogStartup(serviceCount int) {
 	l.mu.Lock()
 	defer l.mu.Unlock()
 
-	fmt.Printf("\n%s🚀 Azure Developer CLI - App Extension%s\n", colorBold, colorReset)
-	fmt.Printf("%s━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━%s\n", "\033[90m", colorReset)
+	fmt.Fprintf(output.Writer(), "\n%s🚀 Azure Developer CLI - App Extension%s\n", output.Bold, output.Reset)
+	fmt.Fprintf(output.Writer(), "%s━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━%s\n", output.Gray, output.Reset)
 
 	serviceWord := "service"
 	if serviceCount != 1 {
 		serviceWord = "services"
 	}
 
-	fmt.Printf("\n%s⚡ Starting %d %s...%s\n\n", "\033[96m", serviceCount, serviceWord, colorReset)
+	fmt.Fprintf(output.Writer(), "\n%s⚡ Starting %d %s...%s\n\n", output.BrightCyan, serviceCount, serviceWord, output.Reset)
 }
 
 // LogSummary logs the final summary of service URLs.
 func (l *ServiceLogger) LogSummary(urls map[string]string) {
-	fmt.Printf("\n%s━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━%s\n", "\033[90m", colorReset)
-	fmt.Printf("%s✨ All services ready!%s\n", "\033[92m", colorReset)
-	fmt.Printf("%s━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━%s\n\n", "\033[90m", colorReset)
+	fmt.Fprintf(output.Writer(), "\n%s━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━%s\n", output.Gray, output.Reset)
+	fmt.Fprintf(output.Writer(), "%s✨ All services ready!%s\n", output.BrightGreen, output.Reset)
+	fmt.Fprintf(output.Writer(), "%s━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━%s\n\n", output.Gray, output.Reset)
 
 	if len(urls) > 0 {
-		fmt.Printf("%s📡 Service URLs:%s\n\n", colorBold, colorReset)
+		fmt.Fprintf(output.Writer(), "%s📡 Service URLs:%s\n\n", output.Bold, output.Reset)
 		for name, url := range urls {
 			color := l.getServiceColor(name)
-			// Extract just the service name without path for cleaner display
-			displayName := name
-			if len(displayName) > 15 {
-				displayName = displayName[:12] + "..."
-			}
-			fmt.Printf("   %s%-18s%s → %s%s%s\n", color, displayName, colorReset, "\033[94m", url, colorReset)
+			fmt.Fprintf(output.Writer(), "   %s%s%s → %s%s%s\n", color, output.PadRight(output.Truncate(name, 15), 18), output.Reset, output.BrightBlue, url, output.Reset)
 		}
-		fmt.Println()
+		fmt.Fprintln(output.Writer())
 	}
 }
 
 // LogReady logs the ready message without repeating URLs.
 func (l *ServiceLogger) LogReady() {
-	fmt.Printf("\n%s━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━%s\n", "\033[90m", colorReset)
-	fmt.Printf("%s✨ All services ready!%s\n", "\033[92m", colorReset)
-	fmt.Printf("%s━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━%s\n\n", "\033[90m", colorReset)
+	fmt.Fprintf(output.Writer(), "\n%s━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━%s\n", output.Gray, output.Reset)
+	fmt.Fprintf(output.Writer(), "%s✨ All services ready!%s\n", output.BrightGreen, output.Reset)
+	fmt.Fprintf(output.Writer(), "%s━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━%s\n\n", output.Gray, output.Reset)
 }
 
 // StreamLogs streams logs from multiple services to the console.
@@ -93,9 +93,17 @@ func TestServiceLogger_FormatLogEntry(t *testing.T) {
 }
 
 func captureStdout(f func()) string {
-	old := os.Stdout
+	return captureStream(&os.Stdout, f)
+}
+
+func captureStderr(f func()) string {
+	return captureStream(&os.Stderr, f)
+}
+
+func captureStream(stream **os.File, f func()) string {
+	old := *stream
 	r, w, _ := os.Pipe()
-	os.Stdout = w
+	*stream = w
 
 	outChan := make(chan string)
 	go func() {
@@ -107,7 +115,7 @@ func captureStdout(f func()) string {
 	f()
 
 	w.Close()
-	os.Stdout = old
+	*stream = old
 
 	return <-outChan
 }
@@ -187,7 +195,7 @@ func TestServiceLogger_LogError(t *testing.T) {
 	// Pre-allocate color to avoid mutex during capture
 	_ = logger.getServiceColor("test-service")
 
-	output := captureStdout(func() {
+	output := captureStderr(func() {
 		logger.LogError("test-service", "operation failed")
 	})
 
@@ -211,7 +219,7 @@ func TestServiceLogger_LogWarning(t *testing.T) {
 	// Pre-allocate color to avoid mutex during capture
 	_ = logger.getServiceColor("test-service")
 
-	output := captureStdout(func() {
+	output := captureStderr(func() {
 		logger.LogWarning("test-service", "warning message")
 	})
 
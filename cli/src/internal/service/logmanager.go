@@ -12,6 +12,7 @@ import (
 type LogManager struct {
 	projectDir string
 	buffers    map[string]*LogBuffer // key: serviceName
+	sink       func(LogEntry)        // Optional: receives every entry added to a buffer
 	mu         sync.RWMutex
 }
 
@@ -69,10 +70,25 @@ func (lm *LogManager) CreateBuffer(serviceName string, maxSize int, enableFileLo
 		return nil, fmt.Errorf("failed to create log buffer for %s: %w", serviceName, err)
 	}
 
+	buffer.sink = lm.sink
 	lm.buffers[serviceName] = buffer
 	return buffer, nil
 }
 
+// SetSink sets a function that receives every entry added to the buffers of the project, in
+// the order they are added to each buffer.
+func (lm *LogManager) SetSink(sink func(LogEntry)) {
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+
+	lm.sink = sink
+	for _, buffer := range lm.buffers {
+		buffer.mu.Lock()
+		buffer.sink = sink
+		buffer.mu.Unlock()
+	}
+}
+
 // GetBuffer retrieves a log buffer for a service.
 func (lm *LogManager) GetBuffer(serviceName string) (*LogBuffer, bool) {
 	lm.mu.RLock()
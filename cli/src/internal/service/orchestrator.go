@@ -1,14 +1,20 @@
 package service
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"sort"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/jongio/azd-app/cli/src/internal/envhistory"
 	"github.com/jongio/azd-app/cli/src/internal/output"
 	"github.com/jongio/azd-app/cli/src/internal/registry"
+	"github.com/jongio/azd-app/cli/src/internal/retry"
+	"github.com/jongio/azd-app/cli/src/internal/workerpool"
 )
 
 // OrchestrationResult contains the results of service orchestration.
@@ -19,8 +25,9 @@ type OrchestrationResult struct {
 	ReadyTime time.Time
 }
 
-// OrchestrateServices starts services in dependency order with parallel execution.
-func OrchestrateServices(runtimes []*ServiceRuntime, envVars map[string]string, logger *ServiceLogger) (*OrchestrationResult, error) {
+// OrchestrateServices starts services in dependency order with parallel execution. Services
+// not started, or not ready, when ctx is done, e.g. because --deadline passed, fail with its cause.
+func OrchestrateServices(ctx context.Context, runtimes []*ServiceRuntime, envVars map[string]string, logger *ServiceLogger) (*OrchestrationResult, error) {
 	result := &OrchestrationResult{
 		Processes: make(map[string]*ServiceProcess),
 		Errors:    make(map[string]error),
@@ -37,15 +44,32 @@ func OrchestrateServices(runtimes []*ServiceRuntime, envVars map[string]string,
 	projectDir, _ := os.Getwd()
 	reg := registry.GetRegistry(projectDir)
 
-	var mu sync.Mutex
-	var wg sync.WaitGroup
-	startErrors := make(map[string]error)
+	// Local URLs replace those of deployed services, including URLs loaded with --env-file;
+	// variables of the runtime still win, and services started here win over virtual services
+	// of the same name
+	discovery := virtualDiscoveryEnv(VirtualServices(projectDir))
+	for k, v := range DiscoveryEnv(runtimes) {
+		discovery[k] = v
+	}
+	baseEnv, overridden := mergeDiscoveryEnv(envVars, discovery)
+	if len(overridden) > 0 {
+		output.Warning("Using local service URLs instead of %s from the environment", strings.Join(overridden, ", "))
+	}
 
-	for _, runtime := range runtimes {
-		wg.Add(1)
-		go func(rt *ServiceRuntime) {
-			defer wg.Done()
+	// Start nothing unless every service has the variables it requires
+	if err := verifyEnvContracts(runtimes, baseEnv); err != nil {
+		return result, err
+	}
 
+	// Values of outputs, captured from services before the services that consume them start
+	outputs := make(map[string]map[string]string)
+	captured := make(map[string]bool)
+
+	var mu sync.Mutex
+	tasks := make(map[string]workerpool.Task, len(runtimes))
+	for _, runtime := range runtimes {
+		rt := runtime
+		tasks[rt.Name] = workerpool.Task{Name: rt.Name, Run: func(ctx context.Context) error {
 			// Extract Azure URL from environment variables if available
 			azureURL := ""
 			serviceNameUpper := strings.ToUpper(rt.Name)
@@ -69,28 +93,36 @@ func OrchestrateServices(runtimes []*ServiceRuntime, envVars map[string]string,
 				logger.LogService(rt.Name, fmt.Sprintf("Warning: failed to register service: %v", err))
 			}
 
-			// Resolve environment variables for this service
-			serviceEnv := make(map[string]string)
-			for k, v := range envVars {
+			// Resolve environment variables for this service, with the outputs it consumes
+			serviceEnv := serviceEnvironment(baseEnv, rt)
+			for k, v := range inputEnvironment(rt, outputs) {
 				serviceEnv[k] = v
 			}
-			// Merge runtime-specific env
-			for k, v := range rt.Env {
-				serviceEnv[k] = v
+
+			// Record the resolved environment so drift between runs can be inspected
+			if _, err := envhistory.Record(projectDir, rt.Name, serviceEnv); err != nil {
+				logger.LogService(rt.Name, fmt.Sprintf("Warning: failed to record environment history: %v", err))
 			}
 
-			// Start service
-			process, err := StartService(rt, serviceEnv, projectDir)
+			// Resolve Key Vault references after recording, so the history holds the references
+			// instead of the secrets, then start service
+			var process *ServiceProcess
+			err := keyVaultSecrets.Resolve(ctx, serviceEnv)
+			if err != nil {
+				err = fmt.Errorf("failed to resolve Key Vault secrets: %w", err)
+			} else {
+				process, err = StartService(ctx, rt, serviceEnv, projectDir)
+			}
 			if err != nil {
 				mu.Lock()
-				startErrors[rt.Name] = err
 				result.Errors[rt.Name] = err
 				mu.Unlock()
 				if err := reg.UpdateStatus(rt.Name, "error", "unknown"); err != nil {
 					logger.LogService(rt.Name, fmt.Sprintf("Warning: failed to update status: %v", err))
 				}
 				logger.LogService(rt.Name, fmt.Sprintf("Failed to start: %v", err))
-				return
+				logger.Records().Write(Record{Service: rt.Name, Event: EventFailed, Message: err.Error()})
+				return err
 			}
 
 			// Update registry with PID
@@ -107,7 +139,11 @@ func OrchestrateServices(runtimes []*ServiceRuntime, envVars map[string]string,
 
 			// Log service URL immediately with modern formatting
 			url := fmt.Sprintf("http://localhost:%d", process.Port)
-			output.ItemSuccess("%s%-15s%s → %s", output.Cyan, rt.Name, output.Reset, url)
+			if rt.ForwardedURL != "" {
+				url = rt.ForwardedURL
+			}
+			output.ItemSuccess("%s%s%s → %s", output.Cyan, output.PadRight(rt.Name, 15), output.Reset, url)
+			logger.Records().Write(Record{Service: rt.Name, Event: EventStarted, PID: process.Process.Pid, URL: url})
 
 			if err := reg.UpdateStatus(rt.Name, "running", "healthy"); err != nil {
 				logger.LogService(rt.Name, fmt.Sprintf("Warning: failed to update status: %v", err))
@@ -116,35 +152,199 @@ func OrchestrateServices(runtimes []*ServiceRuntime, envVars map[string]string,
 
 			// Note: Log collection is already handled by StartLogCollection in StartService
 			// which sets up goroutines to read from stdout/stderr and populate the log buffer
-		}(runtime)
+			return nil
+		}}
 	}
 
-	// Wait for all services to finish starting
-	wg.Wait()
-
-	// Check if any services failed to start
-	if len(startErrors) > 0 {
-		StopAllServices(result.Processes)
-		// Return the first error encountered
-		for name, err := range startErrors {
-			return result, fmt.Errorf("failed to start service %s: %w", name, err)
+	// Start the services of each level at once, after the levels they depend on
+	var failed []*workerpool.TaskError
+	waited := make(map[string]bool)
+	for _, level := range startLevels(runtimes) {
+		var deps []string
+		for _, rt := range level {
+			deps = append(deps, rt.DependsOn...)
+		}
+		if len(failed) == 0 {
+			failed = append(failed, waitForReady(ctx, deps, result.Processes, waited, projectDir, logger)...)
+		}
+		if len(failed) == 0 {
+			failed = append(failed, captureOutputs(ctx, deps, result.Processes, outputs, captured, projectDir)...)
 		}
+		if len(failed) > 0 {
+			for _, rt := range level {
+				failed = append(failed, &workerpool.TaskError{Name: rt.Name, Err: fmt.Errorf("not started: a service started before it failed")})
+			}
+			continue
+		}
+		levelTasks := make([]workerpool.Task, len(level))
+		for i, rt := range level {
+			levelTasks[i] = tasks[rt.Name]
+		}
+		failed = append(failed, startErrors(workerpool.Run(ctx, levelTasks, workerpool.Options{Workers: len(levelTasks)}))...)
+	}
+	// Services that wait for a log line are ready only once they log it, dependents or not
+	if len(failed) == 0 {
+		var gated []string
+		for _, rt := range runtimes {
+			if rt.HealthCheck.Type == "log" {
+				gated = append(gated, rt.Name)
+			}
+		}
+		failed = append(failed, waitForReady(ctx, gated, result.Processes, waited, projectDir, logger)...)
+	}
+	if len(failed) > 0 {
+		StopAllServices(result.Processes)
+		err := &workerpool.Error{Total: len(tasks), Succeeded: len(tasks) - len(failed), Failed: failed}
+		return result, fmt.Errorf("%d of %d services failed to start: %w", len(failed), len(tasks), err)
 	}
 
 	result.ReadyTime = time.Now()
 	return result, nil
 }
 
+// startLevels groups runtimes into levels that start one after another, each runtime in a later
+// level than the runtimes it depends on. Dependencies on services that are not in runtimes, such
+// as resources or services that are already running, are ignored. Levels are sorted by name.
+func startLevels(runtimes []*ServiceRuntime) [][]*ServiceRuntime {
+	byName := make(map[string]*ServiceRuntime, len(runtimes))
+	for _, rt := range runtimes {
+		byName[rt.Name] = rt
+	}
+
+	levels := make(map[string]int, len(runtimes))
+	visiting := make(map[string]bool)
+	var levelOf func(rt *ServiceRuntime) int
+	levelOf = func(rt *ServiceRuntime) int {
+		if level, ok := levels[rt.Name]; ok {
+			return level
+		}
+		// A cycle has no order; the dependency graph rejects them before runtimes are created
+		if visiting[rt.Name] {
+			return 0
+		}
+		visiting[rt.Name] = true
+		level := 0
+		for _, dep := range rt.DependsOn {
+			if depRuntime, ok := byName[dep]; ok {
+				level = max(level, levelOf(depRuntime)+1)
+			}
+		}
+		levels[rt.Name] = level
+		return level
+	}
+
+	var grouped [][]*ServiceRuntime
+	for _, rt := range runtimes {
+		level := levelOf(rt)
+		for len(grouped) <= level {
+			grouped = append(grouped, nil)
+		}
+		grouped[level] = append(grouped[level], rt)
+	}
+	for _, level := range grouped {
+		sort.Slice(level, func(i, j int) bool { return level[i].Name < level[j].Name })
+	}
+	return grouped
+}
+
+// waitForReady waits until the started services named in names are ready. Services with a log
+// health check are ready once they log a line matching its pattern, and fail when they don't
+// in time. Others are ready once they accept connections on their ports, probed as often as
+// their health check retry policy allows; one that never does is reported and counts as ready,
+// since not every service listens. waited records the services already waited for, so they are
+// not waited for again. Waiting ends when ctx is done.
+func waitForReady(ctx context.Context, names []string, processes map[string]*ServiceProcess, waited map[string]bool, projectDir string, logger *ServiceLogger) []*workerpool.TaskError {
+	var tasks []workerpool.Task
+	for _, name := range names {
+		process, ok := processes[name]
+		if !ok || waited[name] {
+			continue
+		}
+		waited[name] = true
+		if process.Runtime.HealthCheck.Type == "log" {
+			tasks = append(tasks, workerpool.Task{Name: name, Run: func(ctx context.Context) error {
+				return WaitForLogMatch(ctx, process, projectDir)
+			}})
+			continue
+		}
+		if process.Port == 0 {
+			continue
+		}
+		policy := process.Runtime.HealthCheck.Retry
+		tasks = append(tasks, workerpool.Task{Name: name, Run: func(ctx context.Context) error {
+			if err := retry.Do(ctx, policy, func(ctx context.Context, attempt int) error {
+				return PortHealthCheck(process.Port)
+			}); err != nil {
+				if ctx.Err() != nil {
+					return context.Cause(ctx)
+				}
+				logger.LogService(process.Name, fmt.Sprintf("Warning: not listening on port %d after %d attempts, starting the services that depend on it anyway%s", process.Port, policy.MaxAttempts, LogTail(projectDir, process.Name, readyDiagnosticLines)))
+			}
+			return nil
+		}})
+	}
+	return startErrors(workerpool.Run(ctx, tasks, workerpool.Options{Workers: len(tasks)}))
+}
+
+// serviceEnvironment returns the environment of the service of rt: the values of the azd
+// environment, overlaid with its secrets, the variables of its .env files, baseEnv, the
+// variables of the emulators of the resources it uses, and the variables of the runtime.
+func serviceEnvironment(baseEnv map[string]string, rt *ServiceRuntime) map[string]string {
+	env := make(map[string]string, len(rt.AzdEnv)+len(rt.Secrets)+len(rt.DotEnv)+len(baseEnv)+len(rt.EmulatorEnv)+len(rt.Env))
+	for k, v := range rt.AzdEnv {
+		env[k] = v
+	}
+	for k, v := range rt.Secrets {
+		env[k] = v
+	}
+	for k, v := range rt.DotEnv {
+		env[k] = v
+	}
+	for k, v := range baseEnv {
+		env[k] = v
+	}
+	for k, v := range rt.EmulatorEnv {
+		env[k] = v
+	}
+	for k, v := range rt.Env {
+		env[k] = v
+	}
+	return env
+}
+
+// startErrors returns the errors of the failed tasks in err, as returned by workerpool.Run,
+// followed by an error for each task skipped because the context was done.
+func startErrors(err error) []*workerpool.TaskError {
+	failed := workerpool.Errors(err)
+	var batchErr *workerpool.Error
+	if errors.As(err, &batchErr) {
+		for _, name := range batchErr.Skipped {
+			failed = append(failed, &workerpool.TaskError{Name: name, Err: fmt.Errorf("not started: %w", batchErr.Cause)})
+		}
+	}
+	return failed
+}
+
+// ApplyDependencies sets the services each runtime starts after from the dependency graph.
+// Without a graph the runtimes keep no dependencies and start at once.
+func ApplyDependencies(runtimes []*ServiceRuntime, graph *DependencyGraph) {
+	if graph == nil {
+		return
+	}
+	for _, rt := range runtimes {
+		rt.DependsOn = GetServiceDependencies(rt.Name, graph)
+	}
+}
+
 // StopAllServices stops all running services.
 func StopAllServices(processes map[string]*ServiceProcess) {
-	var wg sync.WaitGroup
 	projectDir, _ := os.Getwd()
 	reg := registry.GetRegistry(projectDir)
 
+	tasks := make([]workerpool.Task, 0, len(processes))
 	for name, process := range processes {
-		wg.Add(1)
-		go func(serviceName string, proc *ServiceProcess) {
-			defer wg.Done()
+		serviceName, proc := name, process
+		tasks = append(tasks, workerpool.Task{Name: serviceName, Run: func(ctx context.Context) error {
 
 			// Update status to stopping
 			if err := reg.UpdateStatus(serviceName, "stopping", "unknown"); err != nil {
@@ -160,10 +360,12 @@ func StopAllServices(processes map[string]*ServiceProcess) {
 			if err := reg.Unregister(serviceName); err != nil {
 				output.Error("Warning: failed to unregister service %s: %v", serviceName, err)
 			}
-		}(name, process)
+			return nil
+		}})
 	}
 
-	wg.Wait()
+	// Errors are reported above so that one failure does not keep the others running
+	_ = workerpool.Run(context.Background(), tasks, workerpool.Options{Workers: len(tasks)})
 }
 
 // WaitForServices waits for all services to exit.
@@ -171,12 +373,9 @@ func WaitForServices(processes map[string]*ServiceProcess) error {
 	// Wait for any service to exit
 	for name, process := range processes {
 		if process.Process != nil {
-			state, err := process.Process.Wait()
-			if err != nil {
-				return fmt.Errorf("service %s exited with error: %w", name, err)
-			}
-			if !state.Success() {
-				return fmt.Errorf("service %s exited with non-zero status: %s", name, state.String())
+			<-process.Exited()
+			if code := process.ExitCode(); code != 0 {
+				return fmt.Errorf("service %s exited with non-zero status: %d", name, code)
 			}
 		}
 	}
@@ -0,0 +1,245 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jongio/azd-app/cli/src/internal/workerpool"
+)
+
+// Types of outputs.
+const (
+	OutputTypeString = "string"
+	OutputTypeURL    = "url"
+	OutputTypeInt    = "int"
+)
+
+// OutputCapture is a value captured from what a service prints to stdout once it starts.
+type OutputCapture struct {
+	Name    string
+	Pattern *regexp.Regexp // Its first group is the value
+	Type    string
+	Timeout time.Duration
+}
+
+// OutputBinding sets a variable of a service to an output of another service.
+type OutputBinding struct {
+	Env     string // Variable of the consuming service
+	Service string // Service that produces the value
+	Output  string // Name of the output of Service
+}
+
+// ApplyOutputBindings sets the outputs each runtime produces and the variables it consumes
+// from the outputs sections and inputs sections of its service in azure.yaml. The dependency
+// graph starts a service after the services whose outputs it consumes, which must be in
+// runtimes too.
+func ApplyOutputBindings(runtimes []*ServiceRuntime, azureYaml *AzureYaml) error {
+	if azureYaml == nil {
+		return nil
+	}
+	byName := make(map[string]*ServiceRuntime, len(runtimes))
+	for _, rt := range runtimes {
+		byName[rt.Name] = rt
+	}
+
+	// Outputs first, so inputs can refer to outputs of services later in runtimes
+	for _, rt := range runtimes {
+		svc, ok := azureYaml.Services[rt.Name]
+		if !ok {
+			continue
+		}
+		rt.Outputs = nil
+		for _, name := range slices.Sorted(maps.Keys(svc.Outputs)) {
+			capture, err := parseOutput("services."+rt.Name+".outputs."+name, name, svc.Outputs[name])
+			if err != nil {
+				return err
+			}
+			rt.Outputs = append(rt.Outputs, capture)
+		}
+	}
+
+	for _, rt := range runtimes {
+		svc, ok := azureYaml.Services[rt.Name]
+		if !ok {
+			continue
+		}
+		rt.Inputs = nil
+		for _, env := range slices.Sorted(maps.Keys(svc.Inputs)) {
+			path := "services." + rt.Name + ".inputs." + env
+			if !envNamePattern.MatchString(env) {
+				return fmt.Errorf("%s: invalid variable name %q", path, env)
+			}
+			ref := svc.Inputs[env]
+			producer, output, ok := strings.Cut(ref, ".")
+			if !ok || producer == "" || output == "" {
+				return fmt.Errorf("%s: %q is not <service>.<output>", path, ref)
+			}
+			if producer == rt.Name {
+				return fmt.Errorf("%s: a service can't consume its own output %q", path, output)
+			}
+			source, ok := byName[producer]
+			if !ok {
+				return fmt.Errorf("%s: service %s is not started, start it with %s", path, producer, rt.Name)
+			}
+			if !hasOutput(source, output) {
+				return fmt.Errorf("%s: service %s has no output %q", path, producer, output)
+			}
+			rt.Inputs = append(rt.Inputs, OutputBinding{Env: env, Service: producer, Output: output})
+		}
+	}
+	return nil
+}
+
+// parseOutput validates the settings of the output name at path.
+func parseOutput(path, name string, settings OutputSettings) (OutputCapture, error) {
+	if !envNamePattern.MatchString(name) {
+		return OutputCapture{}, fmt.Errorf("%s: invalid output name %q", path, name)
+	}
+	if settings.Pattern == "" {
+		return OutputCapture{}, fmt.Errorf("%s.pattern is required", path)
+	}
+	pattern, err := regexp.Compile(settings.Pattern)
+	if err != nil {
+		return OutputCapture{}, fmt.Errorf("%s.pattern: invalid regular expression: %w", path, err)
+	}
+	if pattern.NumSubexp() == 0 {
+		return OutputCapture{}, fmt.Errorf("%s.pattern: needs a group around the value, e.g. 'key: (\\S+)'", path)
+	}
+	capture := OutputCapture{Name: name, Pattern: pattern, Type: settings.Type, Timeout: DefaultReadyTimeout}
+	switch capture.Type {
+	case "":
+		capture.Type = OutputTypeString
+	case OutputTypeString, OutputTypeURL, OutputTypeInt:
+	default:
+		return OutputCapture{}, fmt.Errorf("%s.type: unknown type %q, use string, url, or int", path, settings.Type)
+	}
+	if settings.Timeout != "" {
+		timeout, err := time.ParseDuration(settings.Timeout)
+		if err != nil || timeout <= 0 {
+			return OutputCapture{}, fmt.Errorf("%s.timeout: invalid duration %q, use e.g. 30s or 2m", path, settings.Timeout)
+		}
+		capture.Timeout = timeout
+	}
+	return capture, nil
+}
+
+// captureOutputs waits until each started service named in names has printed the outputs it
+// produces, and adds their values to values, by service and output. Each output fails when no
+// line on stdout matches its pattern in time, or when its value isn't of its type. captured
+// records the services already captured from, so they are not waited for again.
+func captureOutputs(ctx context.Context, names []string, processes map[string]*ServiceProcess, values map[string]map[string]string, captured map[string]bool, projectDir string) []*workerpool.TaskError {
+	var mu sync.Mutex
+	var tasks []workerpool.Task
+	for _, name := range names {
+		process, ok := processes[name]
+		if !ok || captured[name] || len(process.Runtime.Outputs) == 0 {
+			continue
+		}
+		captured[name] = true
+		tasks = append(tasks, workerpool.Task{Name: name, Run: func(ctx context.Context) error {
+			found, err := waitForOutputs(ctx, process, projectDir)
+			if err != nil {
+				return err
+			}
+			mu.Lock()
+			values[process.Name] = found
+			mu.Unlock()
+			return nil
+		}})
+	}
+	return startErrors(workerpool.Run(ctx, tasks, workerpool.Options{Workers: len(tasks)}))
+}
+
+// waitForOutputs returns the values of the outputs of process, by name, once it has printed
+// them all. The error of an output not printed in time ends with the last lines it logged.
+func waitForOutputs(ctx context.Context, process *ServiceProcess, projectDir string) (map[string]string, error) {
+	ticker := time.NewTicker(readyPollInterval)
+	defer ticker.Stop()
+
+	found := make(map[string]string, len(process.Runtime.Outputs))
+	for {
+		if buffer, ok := GetLogManager(projectDir).GetBuffer(process.Name); ok {
+			for _, entry := range buffer.GetSince(process.StartTime) {
+				if entry.IsStderr {
+					continue
+				}
+				for _, output := range process.Runtime.Outputs {
+					if _, ok := found[output.Name]; ok {
+						continue
+					}
+					if match := output.Pattern.FindStringSubmatch(entry.Message); match != nil {
+						value, err := convertOutput(output, match[1])
+						if err != nil {
+							return nil, err
+						}
+						found[output.Name] = value
+					}
+				}
+			}
+		}
+		if len(found) == len(process.Runtime.Outputs) {
+			return found, nil
+		}
+
+		elapsed := time.Since(process.StartTime)
+		for _, output := range process.Runtime.Outputs {
+			if _, ok := found[output.Name]; !ok && elapsed > output.Timeout {
+				return nil, fmt.Errorf("output %s: no line on stdout matched %q within %s%s", output.Name, output.Pattern, output.Timeout, LogTail(projectDir, process.Name, readyDiagnosticLines))
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// convertOutput returns value, captured for output, after checking that it is of the type of
+// the output. URLs must be absolute, and integers are written without leading zeros or signs.
+func convertOutput(output OutputCapture, value string) (string, error) {
+	value = strings.TrimSpace(value)
+	switch output.Type {
+	case OutputTypeURL:
+		u, err := url.Parse(value)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return "", fmt.Errorf("output %s: %q is not an absolute URL", output.Name, value)
+		}
+	case OutputTypeInt:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return "", fmt.Errorf("output %s: %q is not an integer", output.Name, value)
+		}
+		value = strconv.FormatInt(n, 10)
+	}
+	return value, nil
+}
+
+// inputEnvironment returns the variables rt consumes, set to the values of the outputs they
+// are bound to.
+func inputEnvironment(rt *ServiceRuntime, values map[string]map[string]string) map[string]string {
+	env := make(map[string]string, len(rt.Inputs))
+	for _, input := range rt.Inputs {
+		env[input.Env] = values[input.Service][input.Output]
+	}
+	return env
+}
+
+// hasOutput reports whether rt produces the output name.
+func hasOutput(rt *ServiceRuntime, name string) bool {
+	for _, output := range rt.Outputs {
+		if output.Name == name {
+			return true
+		}
+	}
+	return false
+}
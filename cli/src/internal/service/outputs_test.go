@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestApplyOutputBindings(t *testing.T) {
+	auth := &ServiceRuntime{Name: "auth"}
+	api := &ServiceRuntime{Name: "api", DependsOn: []string{"auth"}}
+	azureYaml := &AzureYaml{Services: map[string]Service{
+		"auth": {Outputs: map[string]OutputSettings{
+			"apiKey": {Pattern: `API key: (\S+)`},
+			"url":    {Pattern: `Listening on (\S+)`, Type: "url", Timeout: "10s"},
+		}},
+		"api": {Inputs: map[string]string{"AUTH_KEY": "auth.apiKey", "AUTH_URL": "auth.url"}},
+	}}
+
+	if err := ApplyOutputBindings([]*ServiceRuntime{api, auth}, azureYaml); err != nil {
+		t.Fatalf("ApplyOutputBindings() error = %v", err)
+	}
+	if len(auth.Outputs) != 2 || auth.Outputs[0].Name != "apiKey" || auth.Outputs[0].Type != OutputTypeString || auth.Outputs[0].Timeout != DefaultReadyTimeout {
+		t.Errorf("auth outputs = %+v, want apiKey, a string with the default timeout, first", auth.Outputs)
+	}
+	if auth.Outputs[1].Type != OutputTypeURL || auth.Outputs[1].Timeout != 10*time.Second {
+		t.Errorf("auth output url = %+v, want a url with a timeout of 10s", auth.Outputs[1])
+	}
+	wantInputs := []OutputBinding{
+		{Env: "AUTH_KEY", Service: "auth", Output: "apiKey"},
+		{Env: "AUTH_URL", Service: "auth", Output: "url"},
+	}
+	if !reflect.DeepEqual(api.Inputs, wantInputs) {
+		t.Errorf("api inputs = %+v, want %+v", api.Inputs, wantInputs)
+	}
+}
+
+func TestApplyOutputBindingsErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		outputs map[string]OutputSettings
+		inputs  map[string]string
+		wantErr string
+	}{
+		{name: "missing pattern", outputs: map[string]OutputSettings{"key": {}}, wantErr: "services.auth.outputs.key.pattern is required"},
+		{name: "invalid pattern", outputs: map[string]OutputSettings{"key": {Pattern: "key: ("}}, wantErr: "services.auth.outputs.key.pattern"},
+		{name: "pattern without group", outputs: map[string]OutputSettings{"key": {Pattern: `key: \S+`}}, wantErr: "needs a group"},
+		{name: "unknown type", outputs: map[string]OutputSettings{"key": {Pattern: `(\S+)`, Type: "bool"}}, wantErr: "services.auth.outputs.key.type"},
+		{name: "invalid timeout", outputs: map[string]OutputSettings{"key": {Pattern: `(\S+)`, Timeout: "soon"}}, wantErr: "services.auth.outputs.key.timeout"},
+		{name: "invalid reference", inputs: map[string]string{"KEY": "auth"}, wantErr: `"auth" is not <service>.<output>`},
+		{name: "unknown output", inputs: map[string]string{"KEY": "auth.secret"}, wantErr: `service auth has no output "secret"`},
+		{name: "service not started", inputs: map[string]string{"KEY": "worker.key"}, wantErr: "service worker is not started"},
+		{name: "own output", inputs: map[string]string{"KEY": "api.key"}, wantErr: "can't consume its own output"},
+		{name: "invalid variable", inputs: map[string]string{"AUTH-KEY": "auth.key"}, wantErr: `invalid variable name "AUTH-KEY"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outputs := tt.outputs
+			if outputs == nil {
+				outputs = map[string]OutputSettings{"key": {Pattern: `key: (\S+)`}}
+			}
+			azureYaml := &AzureYaml{Services: map[string]Service{
+				"auth": {Outputs: outputs},
+				"api":  {Inputs: tt.inputs},
+			}}
+			err := ApplyOutputBindings([]*ServiceRuntime{{Name: "auth"}, {Name: "api"}}, azureYaml)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ApplyOutputBindings() error = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCaptureOutputs(t *testing.T) {
+	projectDir := t.TempDir()
+	buffer, err := GetLogManager(projectDir).CreateBuffer("auth", 100, false)
+	if err != nil {
+		t.Fatalf("CreateBuffer() error = %v", err)
+	}
+	start := time.Now()
+	buffer.Add(LogEntry{Service: "auth", Message: "API key: stale", Timestamp: start.Add(-time.Minute)})
+	buffer.Add(LogEntry{Service: "auth", Message: "API key: from-stderr", Timestamp: start, IsStderr: true})
+	buffer.Add(LogEntry{Service: "auth", Message: "API key: s3cr3t", Timestamp: start})
+
+	process := &ServiceProcess{Name: "auth", StartTime: start, Runtime: ServiceRuntime{Outputs: []OutputCapture{
+		{Name: "apiKey", Pattern: regexp.MustCompile(`API key: (\S+)`), Type: OutputTypeString, Timeout: 5 * time.Second},
+		{Name: "port", Pattern: regexp.MustCompile(`port (\S+)`), Type: OutputTypeInt, Timeout: 5 * time.Second},
+	}}}
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		buffer.Add(LogEntry{Service: "auth", Message: "Listening on port 08080", Timestamp: time.Now()})
+	}()
+
+	values := make(map[string]map[string]string)
+	captured := make(map[string]bool)
+	processes := map[string]*ServiceProcess{"auth": process}
+	if failed := captureOutputs(context.Background(), []string{"auth", "db"}, processes, values, captured, projectDir); len(failed) > 0 {
+		t.Fatalf("captureOutputs() failed = %v", failed)
+	}
+	want := map[string]string{"apiKey": "s3cr3t", "port": "8080"}
+	if !reflect.DeepEqual(values["auth"], want) {
+		t.Errorf("values = %v, want %v, from stdout since the service started", values["auth"], want)
+	}
+
+	api := &ServiceRuntime{Name: "api", Inputs: []OutputBinding{{Env: "AUTH_KEY", Service: "auth", Output: "apiKey"}}}
+	if env := inputEnvironment(api, values); env["AUTH_KEY"] != "s3cr3t" {
+		t.Errorf("inputEnvironment() = %v, want AUTH_KEY set to the captured key", env)
+	}
+}
+
+func TestCaptureOutputsErrors(t *testing.T) {
+	projectDir := t.TempDir()
+	buffer, err := GetLogManager(projectDir).CreateBuffer("auth", 100, false)
+	if err != nil {
+		t.Fatalf("CreateBuffer() error = %v", err)
+	}
+	start := time.Now()
+	buffer.Add(LogEntry{Service: "auth", Message: "Listening on localhost", Timestamp: start})
+
+	tests := []struct {
+		name    string
+		output  OutputCapture
+		wantErr string
+	}{
+		{name: "not a url", output: OutputCapture{Name: "url", Pattern: regexp.MustCompile(`Listening on (\S+)`), Type: OutputTypeURL, Timeout: time.Second}, wantErr: `output url: "localhost" is not an absolute URL`},
+		{name: "not printed", output: OutputCapture{Name: "key", Pattern: regexp.MustCompile(`key: (\S+)`), Type: OutputTypeString, Timeout: 100 * time.Millisecond}, wantErr: "output key: no line on stdout matched"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			process := &ServiceProcess{Name: "auth", StartTime: start, Runtime: ServiceRuntime{Outputs: []OutputCapture{tt.output}}}
+			failed := captureOutputs(context.Background(), []string{"auth"}, map[string]*ServiceProcess{"auth": process}, map[string]map[string]string{}, map[string]bool{}, projectDir)
+			if len(failed) != 1 || !strings.Contains(failed[0].Err.Error(), tt.wantErr) {
+				t.Errorf("captureOutputs() failed = %v, want an error containing %q", failed, tt.wantErr)
+			}
+		})
+	}
+}
@@ -2,13 +2,11 @@ package service
 
 import (
 	"fmt"
-	"os"
 	"path/filepath"
+	"strings"
 
+	"github.com/jongio/azd-app/cli/src/internal/azureyaml"
 	"github.com/jongio/azd-app/cli/src/internal/detector"
-	"github.com/jongio/azd-app/cli/src/internal/security"
-
-	"gopkg.in/yaml.v3"
 )
 
 // ParseAzureYaml reads and parses the azure.yaml file.
@@ -22,26 +20,15 @@ func ParseAzureYaml(workingDir string) (*AzureYaml, error) {
 		return nil, fmt.Errorf("azure.yaml not found in %s or parent directories", workingDir)
 	}
 
-	// Validate path
-	if err := security.ValidatePath(azureYamlPath); err != nil {
-		return nil, fmt.Errorf("invalid azure.yaml path: %w", err)
-	}
-
-	// Read file
-	// #nosec G304 -- Path validated by security.ValidatePath
-	data, err := os.ReadFile(azureYamlPath)
+	// Load rejects service paths outside the workspace
+	doc, err := azureyaml.Load(azureYamlPath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read azure.yaml: %w", err)
-	}
-
-	// Parse YAML
-	var azureYaml AzureYaml
-	if err := yaml.Unmarshal(data, &azureYaml); err != nil {
-		return nil, fmt.Errorf("failed to parse azure.yaml: %w", err)
+		return nil, err
 	}
+	azureYaml := doc.Project
+	azureYamlDir := filepath.Dir(azureYamlPath)
 
 	// Resolve relative paths in service projects
-	azureYamlDir := filepath.Dir(azureYamlPath)
 	for name, svc := range azureYaml.Services {
 		if svc.Project != "" {
 			// Convert relative path to absolute
@@ -54,7 +41,14 @@ func ParseAzureYaml(workingDir string) (*AzureYaml, error) {
 		}
 	}
 
-	return &azureYaml, nil
+	return azureYaml, nil
+}
+
+// ValidateProjectPaths returns an error listing every service whose project or docker paths are
+// absolute or lead outside the workspace root, the directory of azure.yaml (see
+// azureyaml.Project.ValidatePaths).
+func ValidateProjectPaths(azureYaml *AzureYaml, workspaceRoot string) error {
+	return azureYaml.ValidatePaths(workspaceRoot)
 }
 
 // FilterServices returns only the services specified in the filter.
@@ -74,6 +68,55 @@ func FilterServices(azureYaml *AzureYaml, filter []string) map[string]Service {
 	return filtered
 }
 
+// SelectServices returns the services of azureYaml named in only, or all services when only is
+// empty, without those named in except. When graph is not nil, the services that selected
+// services depend on are selected too, transitively; resources are never selected. Names that
+// are not services of azureYaml, and selections that leave no services, are errors.
+func SelectServices(azureYaml *AzureYaml, graph *DependencyGraph, only, except []string) (map[string]Service, error) {
+	for _, name := range append(append([]string{}, only...), except...) {
+		if _, exists := azureYaml.Services[name]; !exists {
+			return nil, fmt.Errorf("unknown service '%s' (services: %s)", name, strings.Join(sortedNames(azureYaml.Services), ", "))
+		}
+	}
+
+	selected := make(map[string]bool)
+	var add func(name string)
+	add = func(name string) {
+		if selected[name] {
+			return
+		}
+		if _, isService := azureYaml.Services[name]; !isService {
+			return
+		}
+		selected[name] = true
+		if graph != nil {
+			for _, dep := range graph.Edges[name] {
+				add(dep)
+			}
+		}
+	}
+	if len(only) == 0 {
+		for name := range azureYaml.Services {
+			selected[name] = true
+		}
+	}
+	for _, name := range only {
+		add(name)
+	}
+	for _, name := range except {
+		delete(selected, name)
+	}
+
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("no services left to run after --only and --except")
+	}
+	services := make(map[string]Service, len(selected))
+	for name := range selected {
+		services[name] = azureYaml.Services[name]
+	}
+	return services, nil
+}
+
 // HasServices checks if azure.yaml has any services defined.
 func HasServices(azureYaml *AzureYaml) bool {
 	return azureYaml != nil && len(azureYaml.Services) > 0
@@ -10,6 +10,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/jongio/azd-app/cli/src/internal/detector"
 	"github.com/jongio/azd-app/cli/src/internal/security"
 
 	"gopkg.in/yaml.v3"
@@ -37,7 +38,7 @@ func DetectPort(serviceName string, service Service, projectDir string, framewor
 	}
 
 	// Priority 2: Framework-specific configuration files
-	if port, err := detectPortFromFrameworkConfig(projectDir, framework); err == nil && port > 0 {
+	if port, err := detectPortFromFrameworkConfig(projectDir, framework, LaunchProfileName(service)); err == nil && port > 0 {
 		return port, false, nil // isExplicit = false
 	}
 
@@ -60,16 +61,19 @@ func DetectPort(serviceName string, service Service, projectDir string, framewor
 }
 
 // detectPortFromFrameworkConfig reads framework-specific config files to find the port.
-func detectPortFromFrameworkConfig(projectDir string, framework string) (int, error) {
+// launchProfile is the launch profile a .NET service runs with, or "" for the default one.
+func detectPortFromFrameworkConfig(projectDir string, framework string, launchProfile string) (int, error) {
 	switch framework {
-	case "Next.js", "React", "Vue", "Angular", "Express", "NestJS":
+	case "Next.js", "Vite", "React", "Vue", "Angular", "Express", "NestJS":
 		return detectPortFromPackageJSON(projectDir)
 	case "ASP.NET Core", "Aspire":
-		return detectPortFromLaunchSettings(projectDir)
+		return detectPortFromLaunchSettings(projectDir, launchProfile)
 	case "Django":
 		return detectPortFromDjangoSettings(projectDir)
 	case "Spring Boot":
 		return detectPortFromSpringConfig(projectDir)
+	case "Azure Functions":
+		return detectPortFromFunctionsSettings(projectDir)
 	}
 
 	return 0, fmt.Errorf("no port detection for framework: %s", framework)
@@ -108,40 +112,60 @@ func detectPortFromPackageJSON(projectDir string) (int, error) {
 	return 0, fmt.Errorf("no port found in package.json scripts")
 }
 
-// detectPortFromLaunchSettings reads .NET launchSettings.json.
-func detectPortFromLaunchSettings(projectDir string) (int, error) {
-	launchSettingsPath := filepath.Join(projectDir, "Properties", "launchSettings.json")
-	if err := security.ValidatePath(launchSettingsPath); err != nil {
+// detectPortFromFunctionsSettings reads Host.LocalHttpPort from an Azure Functions local.settings.json.
+func detectPortFromFunctionsSettings(projectDir string) (int, error) {
+	settingsPath := filepath.Join(projectDir, "local.settings.json")
+	if err := security.ValidatePath(settingsPath); err != nil {
 		return 0, err
 	}
 
 	// #nosec G304 -- Path validated by security.ValidatePath
-	data, err := os.ReadFile(launchSettingsPath)
+	data, err := os.ReadFile(settingsPath)
 	if err != nil {
 		return 0, err
 	}
 
-	var launchSettings struct {
-		Profiles map[string]struct {
-			ApplicationURL string `json:"applicationUrl"`
-		} `json:"profiles"`
+	var settings struct {
+		Host struct {
+			LocalHTTPPort int `json:"LocalHttpPort"`
+		} `json:"Host"`
+	}
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return 0, err
 	}
+	if settings.Host.LocalHTTPPort <= 0 {
+		return 0, fmt.Errorf("no port found in local.settings.json")
+	}
+	return settings.Host.LocalHTTPPort, nil
+}
 
-	if err := json.Unmarshal(data, &launchSettings); err != nil {
+// detectPortFromLaunchSettings reads .NET launchSettings.json: the port of the launch profile
+// named launchProfile, or else of the profile dotnet run uses by default, or else the first
+// HTTP port of any profile.
+func detectPortFromLaunchSettings(projectDir string, launchProfile string) (int, error) {
+	profiles, err := detector.ParseLaunchSettings(projectDir)
+	if err != nil {
 		return 0, err
 	}
 
+	profile := detector.DefaultLaunchProfile(profiles)
+	if launchProfile != "" {
+		if profile, err = detector.FindLaunchProfile(profiles, launchProfile); err != nil {
+			return 0, err
+		}
+	}
+	if profile != nil {
+		if port := profile.Port(); port > 0 {
+			return port, nil
+		}
+	}
+
 	// Look for HTTP URL in profiles
-	for _, profile := range launchSettings.Profiles {
-		if profile.ApplicationURL != "" {
-			// Parse URLs like "http://localhost:5000;https://localhost:5001"
-			urls := strings.Split(profile.ApplicationURL, ";")
-			for _, url := range urls {
-				url = strings.TrimSpace(url)
-				if strings.HasPrefix(url, "http://") {
-					if port := extractPortFromURL(url); port > 0 {
-						return port, nil
-					}
+	for _, profile := range profiles {
+		for _, url := range profile.ApplicationURLs {
+			if strings.HasPrefix(url, "http://") {
+				if port := extractPortFromURL(url); port > 0 {
+					return port, nil
 				}
 			}
 		}
@@ -241,27 +265,30 @@ func detectPortFromEnv(serviceName string) int {
 func getFrameworkDefaultPort(framework string, language string) int {
 	// Check framework-specific defaults first
 	frameworkDefaults := map[string]int{
-		"Next.js":      3000,
-		"React":        5173,
-		"Vue":          5173,
-		"Angular":      4200,
-		"Express":      3000,
-		"NestJS":       3000,
-		"Svelte":       5173,
-		"Astro":        4321,
-		"Remix":        3000,
-		"Nuxt":         3000,
-		"Django":       8000,
-		"FastAPI":      8000,
-		"Flask":        5000,
-		"Streamlit":    8501,
-		"Gradio":       7860,
-		"ASP.NET Core": 5000,
-		"Aspire":       15888,
-		"Blazor":       5000,
-		"Spring Boot":  8080,
-		"Quarkus":      8080,
-		"Micronaut":    8080,
+		"Next.js":         3000,
+		"Vite":            5173,
+		"React":           5173,
+		"Vue":             5173,
+		"Angular":         4200,
+		"Express":         3000,
+		"NestJS":          3000,
+		"Svelte":          5173,
+		"SvelteKit":       5173,
+		"Astro":           4321,
+		"Remix":           3000,
+		"Nuxt":            3000,
+		"Django":          8000,
+		"FastAPI":         8000,
+		"Flask":           5000,
+		"Streamlit":       8501,
+		"Gradio":          7860,
+		"ASP.NET Core":    5000,
+		"Aspire":          15888,
+		"Blazor":          5000,
+		"Spring Boot":     8080,
+		"Quarkus":         8080,
+		"Micronaut":       8080,
+		"Azure Functions": 7071,
 	}
 
 	if port, exists := frameworkDefaults[framework]; exists {
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// DefaultReadyTimeout is how long a service with a ready log pattern has to log a matching line.
+const DefaultReadyTimeout = 60 * time.Second
+
+// readyPollInterval is how often the logs of a service are searched for its ready pattern.
+const readyPollInterval = 200 * time.Millisecond
+
+// readyDiagnosticLines is how many of its last log lines are shown when a service is not ready.
+const readyDiagnosticLines = 20
+
+// ApplyReadiness makes the runtimes of services with a ready section in azure.yaml count as
+// ready once they log a line matching its pattern.
+func ApplyReadiness(runtimes []*ServiceRuntime, azureYaml *AzureYaml) error {
+	if azureYaml == nil {
+		return nil
+	}
+	for _, rt := range runtimes {
+		svc, ok := azureYaml.Services[rt.Name]
+		if !ok || svc.Ready == nil {
+			continue
+		}
+		path := "services." + rt.Name + ".ready"
+		if svc.Ready.LogPattern == "" {
+			return fmt.Errorf("%s.logPattern is required", path)
+		}
+		if _, err := regexp.Compile(svc.Ready.LogPattern); err != nil {
+			return fmt.Errorf("%s.logPattern: invalid regular expression: %w", path, err)
+		}
+		timeout := DefaultReadyTimeout
+		if svc.Ready.Timeout != "" {
+			parsed, err := time.ParseDuration(svc.Ready.Timeout)
+			if err != nil || parsed <= 0 {
+				return fmt.Errorf("%s.timeout: invalid duration %q, use e.g. 30s or 2m", path, svc.Ready.Timeout)
+			}
+			timeout = parsed
+		}
+		rt.HealthCheck.Type = "log"
+		rt.HealthCheck.LogMatch = svc.Ready.LogPattern
+		rt.HealthCheck.Timeout = timeout
+	}
+	return nil
+}
+
+// WaitForLogMatch waits until the service of process logs a line matching the pattern of its
+// log health check, for up to its timeout. The error of a timeout ends with the last lines the
+// service logged.
+func WaitForLogMatch(ctx context.Context, process *ServiceProcess, projectDir string) error {
+	config := process.Runtime.HealthCheck
+	pattern, err := regexp.Compile(config.LogMatch)
+	if err != nil {
+		return fmt.Errorf("invalid ready log pattern: %w", err)
+	}
+	timeout := config.Timeout
+	if timeout <= 0 {
+		timeout = DefaultReadyTimeout
+	}
+
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	ticker := time.NewTicker(readyPollInterval)
+	defer ticker.Stop()
+
+	for {
+		if buffer, ok := GetLogManager(projectDir).GetBuffer(process.Name); ok {
+			for _, entry := range buffer.GetSince(process.StartTime) {
+				if pattern.MatchString(entry.Message) {
+					return nil
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-deadline.C:
+			return fmt.Errorf("not ready: no log line matched %q within %s%s", config.LogMatch, timeout, LogTail(projectDir, process.Name, readyDiagnosticLines))
+		case <-ticker.C:
+		}
+	}
+}
+
+// LogTail returns the last n lines the named service logged, indented on lines of their own
+// below a heading, for appending to a message. It returns "" when the service logged nothing.
+func LogTail(projectDir, name string, n int) string {
+	buffer, ok := GetLogManager(projectDir).GetBuffer(name)
+	if !ok {
+		return ""
+	}
+	entries := buffer.GetRecent(n)
+	if len(entries) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "\n    last %d log lines:", len(entries))
+	for _, entry := range entries {
+		b.WriteString("\n      ")
+		b.WriteString(entry.Message)
+	}
+	return b.String()
+}
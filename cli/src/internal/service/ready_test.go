@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestApplyReadiness(t *testing.T) {
+	tests := []struct {
+		name        string
+		ready       *ReadySettings
+		wantType    string
+		wantTimeout time.Duration
+		wantErr     string
+	}{
+		{name: "no ready section", wantType: "http"},
+		{name: "pattern", ready: &ReadySettings{LogPattern: "Compiled successfully"}, wantType: "log", wantTimeout: DefaultReadyTimeout},
+		{name: "pattern and timeout", ready: &ReadySettings{LogPattern: `Now listening on: http://\S+`, Timeout: "90s"}, wantType: "log", wantTimeout: 90 * time.Second},
+		{name: "missing pattern", ready: &ReadySettings{Timeout: "10s"}, wantErr: "services.web.ready.logPattern is required"},
+		{name: "invalid pattern", ready: &ReadySettings{LogPattern: "ready ("}, wantErr: "services.web.ready.logPattern"},
+		{name: "invalid timeout", ready: &ReadySettings{LogPattern: "ready", Timeout: "soon"}, wantErr: "services.web.ready.timeout"},
+		{name: "negative timeout", ready: &ReadySettings{LogPattern: "ready", Timeout: "-5s"}, wantErr: "services.web.ready.timeout"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rt := &ServiceRuntime{Name: "web", HealthCheck: HealthCheckConfig{Type: "http"}}
+			azureYaml := &AzureYaml{Services: map[string]Service{"web": {Ready: tt.ready}}}
+
+			err := ApplyReadiness([]*ServiceRuntime{rt}, azureYaml)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ApplyReadiness() error = %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ApplyReadiness() error = %v", err)
+			}
+			if rt.HealthCheck.Type != tt.wantType || rt.HealthCheck.Timeout != tt.wantTimeout {
+				t.Errorf("health check = %+v, want type %s and timeout %s", rt.HealthCheck, tt.wantType, tt.wantTimeout)
+			}
+		})
+	}
+}
+
+func TestWaitForLogMatch(t *testing.T) {
+	projectDir := t.TempDir()
+	buffer, err := GetLogManager(projectDir).CreateBuffer("web", 100, false)
+	if err != nil {
+		t.Fatalf("CreateBuffer() error = %v", err)
+	}
+	start := time.Now()
+	buffer.Add(LogEntry{Service: "web", Message: "Compiled successfully", Timestamp: start.Add(-time.Minute)})
+	buffer.Add(LogEntry{Service: "web", Message: "Starting the development server...", Timestamp: start})
+
+	process := &ServiceProcess{
+		Name:      "web",
+		StartTime: start,
+		Runtime: ServiceRuntime{HealthCheck: HealthCheckConfig{
+			Type:     "log",
+			LogMatch: `Compiled (successfully|with warnings)`,
+			Timeout:  300 * time.Millisecond,
+		}},
+	}
+
+	// The line logged before the service started belongs to an earlier run
+	err = WaitForLogMatch(context.Background(), process, projectDir)
+	if err == nil {
+		t.Fatal("WaitForLogMatch() matched a line logged before the service started")
+	}
+	for _, want := range []string{"not ready", "last 2 log lines", "Starting the development server..."} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("WaitForLogMatch() error = %q, want it to contain %q", err, want)
+		}
+	}
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		buffer.Add(LogEntry{Service: "web", Message: "Compiled with warnings", Timestamp: time.Now()})
+	}()
+	process.Runtime.HealthCheck.Timeout = 5 * time.Second
+	if err := WaitForLogMatch(context.Background(), process, projectDir); err != nil {
+		t.Errorf("WaitForLogMatch() error = %v", err)
+	}
+}
+
+func TestLogTail(t *testing.T) {
+	projectDir := t.TempDir()
+	if got := LogTail(projectDir, "api", 5); got != "" {
+		t.Errorf("LogTail() of a service without logs = %q, want empty", got)
+	}
+
+	buffer, err := GetLogManager(projectDir).CreateBuffer("api", 100, false)
+	if err != nil {
+		t.Fatalf("CreateBuffer() error = %v", err)
+	}
+	for _, line := range []string{"one", "two", "three"} {
+		buffer.Add(LogEntry{Service: "api", Message: line, Timestamp: time.Now()})
+	}
+	want := "\n    last 2 log lines:\n      two\n      three"
+	if got := LogTail(projectDir, "api", 2); got != want {
+		t.Errorf("LogTail() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// Lifecycle events of a service in the JSON output of run.
+const (
+	EventStarted = "started" // The process was started
+	EventReady   = "ready"   // The service passed its readiness checks
+	EventExited  = "exited"  // The process exited, on its own or when stopped
+	EventFailed  = "failed"  // The service could not be started
+)
+
+// Record is a line of the JSON output of run: a line a service wrote, or an event of its lifecycle.
+type Record struct {
+	Service   string    `json:"service"`
+	Timestamp time.Time `json:"ts"`
+	Stream    string    `json:"stream,omitempty"` // "stdout" or "stderr", for output lines
+	Line      string    `json:"line,omitempty"`   // The line, for output lines
+	Event     string    `json:"event,omitempty"`  // One of the Event constants, for events
+	PID       int       `json:"pid,omitempty"`
+	URL       string    `json:"url,omitempty"`
+	ExitCode  *int      `json:"exitCode,omitempty"` // Unset when the process was killed by a signal
+	Crashed   bool      `json:"crashed,omitempty"`  // The process exited without azd app stopping it
+	Message   string    `json:"message,omitempty"`  // Error of a failed service
+}
+
+// RecordWriter writes records as JSON lines, one record per line, so the output of a session
+// can be piped into jq or a log collector. Lifecycle events are also written to the event log,
+// when set, without the output lines.
+type RecordWriter struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+	events  *json.Encoder
+}
+
+// NewRecordWriter returns a writer of records to w, or of events to the event log only when
+// w is nil.
+func NewRecordWriter(w io.Writer) *RecordWriter {
+	writer := &RecordWriter{}
+	if w != nil {
+		writer.encoder = json.NewEncoder(w)
+	}
+	return writer
+}
+
+// SetEventLog sets the writer lifecycle events are also written to, e.g. the run history.
+func (w *RecordWriter) SetEventLog(events io.Writer) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.events = json.NewEncoder(events)
+}
+
+// Write writes r, with the current time when it has none.
+func (w *RecordWriter) Write(r Record) {
+	if w == nil {
+		return
+	}
+	if r.Timestamp.IsZero() {
+		r.Timestamp = time.Now()
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.encoder != nil {
+		_ = w.encoder.Encode(r)
+	}
+	if w.events != nil && r.Event != "" {
+		_ = w.events.Encode(r)
+	}
+}
+
+// WriteEntry writes a line of service output.
+func (w *RecordWriter) WriteEntry(entry LogEntry) {
+	if w == nil || w.encoder == nil {
+		return
+	}
+	stream := "stdout"
+	if entry.IsStderr {
+		stream = "stderr"
+	}
+	w.Write(Record{Service: entry.Service, Timestamp: entry.Timestamp, Stream: stream, Line: entry.Message})
+}
+
+// WriteExit writes the exited event of process, once, however many callers see it exit.
+func (w *RecordWriter) WriteExit(process *ServiceProcess) {
+	if w == nil || !process.exitRecorded.CompareAndSwap(false, true) {
+		return
+	}
+	r := Record{Service: process.Name, Event: EventExited, Crashed: !process.Stopping()}
+	if process.Process != nil {
+		r.PID = process.Process.Pid
+	}
+	if code := process.ExitCode(); code >= 0 {
+		r.ExitCode = &code
+	}
+	w.Write(r)
+}
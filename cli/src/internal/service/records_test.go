@@ -0,0 +1,94 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jongio/azd-app/cli/src/internal/statedir"
+)
+
+func TestRecordWriter(t *testing.T) {
+	var buf bytes.Buffer
+	records := NewRecordWriter(&buf)
+	ts := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	records.WriteEntry(LogEntry{Service: "api", Message: "listening on 3000", Timestamp: ts})
+	records.WriteEntry(LogEntry{Service: "api", Message: "deprecated option", Timestamp: ts, IsStderr: true})
+	records.Write(Record{Service: "api", Event: EventStarted, PID: 42, URL: "http://localhost:3000"})
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("wrote %d lines, want 3: %s", len(lines), buf.String())
+	}
+	if want := `{"service":"api","ts":"2024-05-01T10:00:00Z","stream":"stdout","line":"listening on 3000"}`; lines[0] != want {
+		t.Errorf("output line = %s, want %s", lines[0], want)
+	}
+
+	var stderr, event Record
+	if err := json.Unmarshal([]byte(lines[1]), &stderr); err != nil {
+		t.Fatal(err)
+	}
+	if stderr.Stream != "stderr" {
+		t.Errorf("stream = %q, want stderr", stderr.Stream)
+	}
+	if err := json.Unmarshal([]byte(lines[2]), &event); err != nil {
+		t.Fatal(err)
+	}
+	if event.Event != EventStarted || event.PID != 42 || event.Timestamp.IsZero() || event.Line != "" {
+		t.Errorf("event = %+v, want a timestamped started event", event)
+	}
+
+	// A nil writer writes nothing, so callers need no checks
+	var none *RecordWriter
+	none.Write(Record{Service: "api"})
+	none.WriteEntry(LogEntry{Service: "api"})
+}
+
+func TestRecordWriterEventLog(t *testing.T) {
+	var events bytes.Buffer
+	records := NewRecordWriter(nil)
+	records.SetEventLog(&events)
+
+	records.WriteEntry(LogEntry{Service: "api", Message: "listening on 3000"})
+	records.Write(Record{Service: "api", Event: EventStarted, PID: 42})
+	process := &ServiceProcess{Name: "api"}
+	records.WriteExit(process)
+	records.WriteExit(process)
+
+	lines := strings.Split(strings.TrimSpace(events.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("event log has %d lines, want the started and one exited event: %s", len(lines), events.String())
+	}
+	var exited Record
+	if err := json.Unmarshal([]byte(lines[1]), &exited); err != nil {
+		t.Fatal(err)
+	}
+	if exited.Event != EventExited || !exited.Crashed {
+		t.Errorf("exit = %+v, want a crash, as azd app didn't stop the service", exited)
+	}
+}
+
+func TestLogManagerSink(t *testing.T) {
+	t.Setenv(statedir.RootEnvVar, t.TempDir())
+	lm := &LogManager{projectDir: t.TempDir(), buffers: make(map[string]*LogBuffer)}
+
+	before, err := lm.CreateBuffer("api", 10, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	lm.SetSink(func(entry LogEntry) { got = append(got, entry.Service+": "+entry.Message) })
+	after, err := lm.CreateBuffer("web", 10, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	before.Add(LogEntry{Service: "api", Message: "one"})
+	after.Add(LogEntry{Service: "web", Message: "two"})
+	if strings.Join(got, ", ") != "api: one, web: two" {
+		t.Errorf("sink received %v, want entries of buffers created before and after it was set", got)
+	}
+}
@@ -0,0 +1,42 @@
+package service
+
+import (
+	"reflect"
+	"sort"
+)
+
+// ServiceChanges describes how the services of azure.yaml changed between two reads.
+type ServiceChanges struct {
+	Added   []string // Services that are new
+	Removed []string // Services that no longer exist
+	Changed []string // Services whose definition changed
+}
+
+// IsEmpty reports whether no service was added, removed, or changed.
+func (c ServiceChanges) IsEmpty() bool {
+	return len(c.Added) == 0 && len(c.Removed) == 0 && len(c.Changed) == 0
+}
+
+// DiffServices compares two sets of service definitions. Names in each list are sorted.
+func DiffServices(previous, current map[string]Service) ServiceChanges {
+	var changes ServiceChanges
+	for name, svc := range current {
+		old, exists := previous[name]
+		switch {
+		case !exists:
+			changes.Added = append(changes.Added, name)
+		case !reflect.DeepEqual(old, svc):
+			changes.Changed = append(changes.Changed, name)
+		}
+	}
+	for name := range previous {
+		if _, exists := current[name]; !exists {
+			changes.Removed = append(changes.Removed, name)
+		}
+	}
+
+	sort.Strings(changes.Added)
+	sort.Strings(changes.Removed)
+	sort.Strings(changes.Changed)
+	return changes
+}
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jongio/azd-app/cli/src/internal/retry"
+)
+
+// Restart modes of a service, set by restart in azure.yaml.
+const (
+	RestartNever     = "never"      // Left stopped when it exits (default)
+	RestartOnFailure = "on-failure" // Restarted when it exits with a non-zero code, up to the attempts of its restart retry policy
+	RestartAlways    = "always"     // Restarted whenever it exits, without limit
+)
+
+// RestartPolicy says whether and how quickly a service that exited is started again.
+type RestartPolicy struct {
+	Mode  string       // RestartNever, RestartOnFailure, or RestartAlways; empty means RestartNever
+	Retry retry.Policy // Delays between restarts; for RestartOnFailure, MaxAttempts limits the starts
+}
+
+// Enabled reports whether the service is ever restarted.
+func (p RestartPolicy) Enabled() bool {
+	return p.Mode == RestartOnFailure || p.Mode == RestartAlways
+}
+
+// ShouldRestart reports whether a service that exited with exitCode is restarted. A service
+// killed by a signal has exit code -1, which is a failure.
+func (p RestartPolicy) ShouldRestart(exitCode int) bool {
+	switch p.Mode {
+	case RestartAlways:
+		return true
+	case RestartOnFailure:
+		return exitCode != 0
+	default:
+		return false
+	}
+}
+
+// Exhausted reports whether a service that has been restarted restarts times may not be
+// restarted again. Only RestartOnFailure has a limit: the attempts of its retry policy, which
+// count the first start.
+func (p RestartPolicy) Exhausted(restarts int) bool {
+	return p.Mode == RestartOnFailure && restarts+1 >= p.Retry.MaxAttempts
+}
+
+// Delay returns the delay before restart n, counted from 1. Restarts beyond the attempts of
+// the retry policy, which only RestartAlways makes, wait as long as the last one.
+func (p RestartPolicy) Delay(n int) time.Duration {
+	if limit := p.Retry.MaxAttempts - 1; n > limit {
+		n = max(limit, 1)
+	}
+	return p.Retry.Delay(n)
+}
+
+// ApplyRestartPolicies sets the restart mode of each runtime from azure.yaml. The delays
+// between restarts are set by ApplyRetryPolicies.
+func ApplyRestartPolicies(runtimes []*ServiceRuntime, azureYaml *AzureYaml) error {
+	if azureYaml == nil {
+		return nil
+	}
+	for _, rt := range runtimes {
+		svc, ok := azureYaml.Services[rt.Name]
+		if !ok {
+			continue
+		}
+		switch svc.Restart {
+		case "":
+		case RestartNever, RestartOnFailure, RestartAlways:
+			rt.Restart.Mode = svc.Restart
+		default:
+			return fmt.Errorf("services.%s.restart: must be '%s', '%s', or '%s', not %q", rt.Name, RestartNever, RestartOnFailure, RestartAlways, svc.Restart)
+		}
+	}
+	return nil
+}
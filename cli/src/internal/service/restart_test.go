@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jongio/azd-app/cli/src/internal/retry"
+)
+
+func TestRestartPolicy(t *testing.T) {
+	tests := []struct {
+		mode          string
+		exitCode      int
+		wantRestart   bool
+		restarts      int
+		wantExhausted bool
+	}{
+		{mode: "", exitCode: 1, wantRestart: false},
+		{mode: RestartNever, exitCode: 1, wantRestart: false},
+		{mode: RestartOnFailure, exitCode: 0, wantRestart: false},
+		{mode: RestartOnFailure, exitCode: 1, wantRestart: true, restarts: 1},
+		{mode: RestartOnFailure, exitCode: -1, wantRestart: true, restarts: 2, wantExhausted: true},
+		{mode: RestartAlways, exitCode: 0, wantRestart: true, restarts: 100},
+	}
+
+	for _, tt := range tests {
+		policy := RestartPolicy{Mode: tt.mode, Retry: retry.Policy{MaxAttempts: 3, BaseDelay: time.Second, Backoff: 2}}
+		if got := policy.ShouldRestart(tt.exitCode); got != tt.wantRestart {
+			t.Errorf("%q.ShouldRestart(%d) = %v, want %v", tt.mode, tt.exitCode, got, tt.wantRestart)
+		}
+		if got := policy.Exhausted(tt.restarts); got != tt.wantExhausted {
+			t.Errorf("%q.Exhausted(%d) = %v, want %v", tt.mode, tt.restarts, got, tt.wantExhausted)
+		}
+	}
+}
+
+func TestRestartPolicyDelay(t *testing.T) {
+	policy := RestartPolicy{Mode: RestartAlways, Retry: retry.Policy{MaxAttempts: 3, BaseDelay: time.Second, Backoff: 2}}
+
+	for n, want := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 2 * time.Second, 50: 2 * time.Second} {
+		if got := policy.Delay(n); got != want {
+			t.Errorf("Delay(%d) = %s, want %s", n, got, want)
+		}
+	}
+
+	policy.Retry.MaxAttempts = 1
+	if got := policy.Delay(10); got != time.Second {
+		t.Errorf("Delay(10) with 1 attempt = %s, want the base delay", got)
+	}
+}
+
+func TestApplyRestartPolicies(t *testing.T) {
+	azureYaml := &AzureYaml{Services: map[string]Service{
+		"api":    {Restart: RestartOnFailure},
+		"worker": {Restart: RestartAlways},
+		"web":    {},
+	}}
+	runtimes := []*ServiceRuntime{{Name: "api"}, {Name: "worker"}, {Name: "web"}}
+
+	if err := ApplyRestartPolicies(runtimes, azureYaml); err != nil {
+		t.Fatalf("ApplyRestartPolicies() error = %v", err)
+	}
+	for i, want := range []string{RestartOnFailure, RestartAlways, ""} {
+		if got := runtimes[i].Restart.Mode; got != want {
+			t.Errorf("%s restart mode = %q, want %q", runtimes[i].Name, got, want)
+		}
+	}
+	if runtimes[2].Restart.Enabled() {
+		t.Error("web is restarted without a restart policy")
+	}
+
+	azureYaml.Services["web"] = Service{Restart: "sometimes"}
+	err := ApplyRestartPolicies(runtimes, azureYaml)
+	if err == nil || !strings.Contains(err.Error(), "services.web.restart") {
+		t.Errorf("ApplyRestartPolicies() error = %v, want it to locate the invalid restart mode", err)
+	}
+}
+
+func TestServiceProcessExited(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping process test in short mode")
+	}
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+
+	tmpDir := t.TempDir()
+	rt := &ServiceRuntime{Name: "test-exit", WorkingDir: tmpDir, Command: "sh", Args: []string{"-c", "exit 3"}, Language: "shell"}
+	process, err := StartService(context.Background(), rt, nil, tmpDir)
+	if err != nil {
+		t.Fatalf("StartService() error = %v", err)
+	}
+	t.Cleanup(func() { _ = GetLogManager(tmpDir).RemoveBuffer(rt.Name) })
+
+	select {
+	case <-process.Exited():
+	case <-time.After(5 * time.Second):
+		t.Fatal("Exited() was not closed after the service exited")
+	}
+	if got := process.ExitCode(); got != 3 {
+		t.Errorf("ExitCode() = %d, want 3", got)
+	}
+	if process.Stopping() {
+		t.Error("Stopping() = true for a service that exited by itself")
+	}
+	if got := GetProcessStatus(process); got != "stopped" {
+		t.Errorf("GetProcessStatus() = %s, want stopped once the service exited", got)
+	}
+
+	rt.Args = []string{"-c", "sleep 30"}
+	process, err = StartService(context.Background(), rt, nil, tmpDir)
+	if err != nil {
+		t.Fatalf("StartService() error = %v", err)
+	}
+	if got := process.ExitCode(); got != -1 {
+		t.Errorf("ExitCode() of a running service = %d, want -1", got)
+	}
+	_ = StopService(process)
+	if !process.Stopping() {
+		t.Error("Stopping() = false after StopService()")
+	}
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jongio/azd-app/cli/src/internal/retry"
+)
+
+// RetryPolicies are the retry policies of a service.
+type RetryPolicies struct {
+	HealthCheck retry.Policy // Probes of the service while it starts
+	Install     retry.Policy // Installs of the dependencies of its project
+	Restart     retry.Policy // Restarts of the service after it exited
+}
+
+// ResolveRetryPolicies returns the retry policies of the named service: the defaults, overridden
+// by the retry section of azure.yaml, overridden in turn by the retry section of the service.
+// An empty name, or one azure.yaml does not define, resolves the policies of the project.
+func ResolveRetryPolicies(azureYaml *AzureYaml, name string) (RetryPolicies, error) {
+	policies := RetryPolicies{HealthCheck: retry.DefaultHealthCheck, Install: retry.DefaultInstall, Restart: retry.DefaultRestart}
+	if azureYaml == nil {
+		return policies, nil
+	}
+
+	// Paths locate each retry section in azure.yaml for errors
+	sections := map[string]*RetrySettings{"retry": azureYaml.Retry}
+	paths := []string{"retry"}
+	if svc, ok := azureYaml.Services[name]; ok {
+		path := "services." + name + ".retry"
+		sections[path] = svc.Retry
+		paths = append(paths, path)
+	}
+
+	var err error
+	for _, path := range paths {
+		settings := sections[path]
+		if settings == nil {
+			continue
+		}
+		if policies.HealthCheck, err = applyRetryPolicy(policies.HealthCheck, settings.HealthCheck, path+".healthCheck"); err != nil {
+			return policies, err
+		}
+		if policies.Install, err = applyRetryPolicy(policies.Install, settings.Install, path+".install"); err != nil {
+			return policies, err
+		}
+		if policies.Restart, err = applyRetryPolicy(policies.Restart, settings.Restart, path+".restart"); err != nil {
+			return policies, err
+		}
+	}
+	return policies, nil
+}
+
+// NetworkRetryPolicy returns the retry policy of requests to remote services: the default,
+// overridden by the retry section of azure.yaml.
+func NetworkRetryPolicy(azureYaml *AzureYaml) (retry.Policy, error) {
+	if azureYaml == nil || azureYaml.Retry == nil {
+		return retry.DefaultNetwork, nil
+	}
+	return applyRetryPolicy(retry.DefaultNetwork, azureYaml.Retry.Network, "retry.network")
+}
+
+// ApplyRetryPolicies sets the health check and restart retry policies of each runtime from
+// azure.yaml.
+func ApplyRetryPolicies(runtimes []*ServiceRuntime, azureYaml *AzureYaml) error {
+	for _, rt := range runtimes {
+		policies, err := ResolveRetryPolicies(azureYaml, rt.Name)
+		if err != nil {
+			return err
+		}
+		rt.HealthCheck.Retry = policies.HealthCheck
+		rt.Restart.Retry = policies.Restart
+	}
+	return nil
+}
+
+// applyRetryPolicy returns policy with the settings that override sets. path locates override
+// in azure.yaml for errors.
+func applyRetryPolicy(policy retry.Policy, override *RetryPolicy, path string) (retry.Policy, error) {
+	if override == nil {
+		return policy, nil
+	}
+	if override.MaxAttempts != nil {
+		policy.MaxAttempts = *override.MaxAttempts
+	}
+	if override.Backoff != nil {
+		policy.Backoff = *override.Backoff
+	}
+	if override.Jitter != nil {
+		policy.Jitter = *override.Jitter
+	}
+	for _, d := range []struct {
+		name  string
+		value string
+		dest  *time.Duration
+	}{{"delay", override.Delay, &policy.BaseDelay}, {"maxDelay", override.MaxDelay, &policy.MaxDelay}} {
+		if d.value == "" {
+			continue
+		}
+		parsed, err := time.ParseDuration(d.value)
+		if err != nil {
+			return policy, fmt.Errorf("%s.%s: invalid duration %q, use e.g. 500ms or 2s", path, d.name, d.value)
+		}
+		*d.dest = parsed
+	}
+	if err := policy.Validate(); err != nil {
+		return policy, fmt.Errorf("%s: %w", path, err)
+	}
+	return policy, nil
+}
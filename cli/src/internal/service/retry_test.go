@@ -0,0 +1,82 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jongio/azd-app/cli/src/internal/retry"
+)
+
+func TestResolveRetryPolicies(t *testing.T) {
+	five, half, one := 5, 0.5, 1.0
+	azureYaml := &AzureYaml{
+		Retry: &RetrySettings{
+			HealthCheck: &RetryPolicy{MaxAttempts: &five, Delay: "1s"},
+			Install:     &RetryPolicy{MaxAttempts: &five},
+			Network:     &RetryPolicy{Jitter: &half, MaxDelay: "10s"},
+		},
+		Services: map[string]Service{
+			"api": {Retry: &RetrySettings{HealthCheck: &RetryPolicy{Delay: "250ms", Backoff: &one}}},
+			"web": {},
+		},
+	}
+
+	api, err := ResolveRetryPolicies(azureYaml, "api")
+	if err != nil {
+		t.Fatalf("ResolveRetryPolicies(api) error = %v", err)
+	}
+	wantHealth := retry.DefaultHealthCheck
+	wantHealth.MaxAttempts, wantHealth.BaseDelay = 5, 250*time.Millisecond
+	if api.HealthCheck != wantHealth {
+		t.Errorf("api health check = %+v, want %+v", api.HealthCheck, wantHealth)
+	}
+	if api.Install.MaxAttempts != 5 || api.Install.BaseDelay != retry.DefaultInstall.BaseDelay {
+		t.Errorf("api install = %+v, want the project's 5 attempts with the default delay", api.Install)
+	}
+	if api.Restart != retry.DefaultRestart {
+		t.Errorf("api restart = %+v, want the default", api.Restart)
+	}
+
+	web, err := ResolveRetryPolicies(azureYaml, "web")
+	if err != nil {
+		t.Fatalf("ResolveRetryPolicies(web) error = %v", err)
+	}
+	if web.HealthCheck.BaseDelay != time.Second {
+		t.Errorf("web health check delay = %s, want the project's 1s", web.HealthCheck.BaseDelay)
+	}
+
+	network, err := NetworkRetryPolicy(azureYaml)
+	if err != nil {
+		t.Fatalf("NetworkRetryPolicy() error = %v", err)
+	}
+	if network.Jitter != 0.5 || network.MaxDelay != 10*time.Second || network.MaxAttempts != retry.DefaultNetwork.MaxAttempts {
+		t.Errorf("network = %+v, want jitter 0.5 and max delay 10s over the defaults", network)
+	}
+
+	if defaults, err := ResolveRetryPolicies(nil, "api"); err != nil || defaults.Install != retry.DefaultInstall {
+		t.Errorf("ResolveRetryPolicies(nil) = %+v, %v; want the defaults", defaults, err)
+	}
+}
+
+func TestResolveRetryPoliciesInvalid(t *testing.T) {
+	zero, tooMuch := 0, 2.0
+	tests := []struct {
+		name     string
+		settings *RetrySettings
+		wantErr  string
+	}{
+		{name: "duration", settings: &RetrySettings{Install: &RetryPolicy{Delay: "soon"}}, wantErr: "services.api.retry.install.delay"},
+		{name: "attempts", settings: &RetrySettings{HealthCheck: &RetryPolicy{MaxAttempts: &zero}}, wantErr: "services.api.retry.healthCheck: maxAttempts"},
+		{name: "jitter", settings: &RetrySettings{Install: &RetryPolicy{Jitter: &tooMuch}}, wantErr: "jitter"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			azureYaml := &AzureYaml{Services: map[string]Service{"api": {Retry: tt.settings}}}
+			if _, err := ResolveRetryPolicies(azureYaml, "api"); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ResolveRetryPolicies() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jongio/azd-app/cli/src/internal/detector"
+	"github.com/jongio/azd-app/cli/src/internal/secretstore"
+	"github.com/jongio/azd-app/cli/src/internal/security"
+)
+
+// ApplySecrets sets the secrets each runtime gets: the user secrets of the .NET projects in
+// its directory, overridden by the secrets of the workspace in projectDir that 'azd app secret'
+// keeps for every service and then for the service itself.
+func ApplySecrets(runtimes []*ServiceRuntime, projectDir string) error {
+	store, err := secretstore.Open(projectDir)
+	if err != nil {
+		return err
+	}
+	for _, rt := range runtimes {
+		serviceDir := rt.WorkingDir
+		if serviceDir == "" {
+			serviceDir = projectDir
+		} else if !filepath.IsAbs(serviceDir) {
+			serviceDir = filepath.Join(projectDir, serviceDir)
+		}
+		env, err := LoadDotnetUserSecrets(serviceDir)
+		if err != nil {
+			return fmt.Errorf("service %s: %w", rt.Name, err)
+		}
+		stored, err := store.Env(rt.Name)
+		if err != nil {
+			return err
+		}
+		for k, v := range stored {
+			env[k] = v
+		}
+		rt.Secrets = env
+	}
+	return nil
+}
+
+// LoadDotnetUserSecrets returns the secrets 'dotnet user-secrets' keeps for the .NET projects
+// in dir that have a UserSecretsId, as environment variables: a key such as
+// ConnectionStrings:Db, or Db nested in ConnectionStrings, becomes ConnectionStrings__Db, the
+// variable .NET configuration reads as that key.
+func LoadDotnetUserSecrets(dir string) (map[string]string, error) {
+	env := make(map[string]string)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return env, nil
+	}
+	var ids []string
+	for _, entry := range entries {
+		if entry.IsDir() || !detector.IsDotnetProjectFile(entry.Name()) {
+			continue
+		}
+		project, err := detector.ParseDotnetProject(filepath.Join(dir, entry.Name()))
+		if err == nil && project.UserSecretsID != "" {
+			ids = append(ids, project.UserSecretsID)
+		}
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		path, err := UserSecretsPath(id)
+		if err != nil {
+			return nil, err
+		}
+		secrets, err := loadUserSecretsFile(path)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range secrets {
+			env[k] = v
+		}
+	}
+	return env, nil
+}
+
+// UserSecretsPath returns the secrets.json 'dotnet user-secrets' keeps for id: under
+// %APPDATA%\Microsoft\UserSecrets on Windows and ~/.microsoft/usersecrets elsewhere.
+func UserSecretsPath(id string) (string, error) {
+	if id == "" || strings.ContainsAny(id, `/\`) || id == "." || id == ".." {
+		return "", fmt.Errorf("invalid UserSecretsId %q", id)
+	}
+	if runtime.GOOS == "windows" {
+		appData := os.Getenv("APPDATA")
+		if appData == "" {
+			return "", errors.New("APPDATA is not set")
+		}
+		return filepath.Join(appData, "Microsoft", "UserSecrets", id, "secrets.json"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate home directory: %w", err)
+	}
+	return filepath.Join(home, ".microsoft", "usersecrets", id, "secrets.json"), nil
+}
+
+// loadUserSecretsFile reads a secrets.json of 'dotnet user-secrets'. A project that has no
+// secrets yet has no file.
+func loadUserSecretsFile(path string) (map[string]string, error) {
+	if err := security.ValidatePath(path); err != nil {
+		return nil, fmt.Errorf("invalid user secrets path: %w", err)
+	}
+	// #nosec G304 -- Path validated by security.ValidatePath
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read user secrets: %w", err)
+	}
+
+	// secrets.json may start with a byte order mark
+	decoder := json.NewDecoder(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+	decoder.UseNumber()
+	var doc map[string]interface{}
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	env := make(map[string]string)
+	flattenUserSecrets(env, "", doc)
+	return env, nil
+}
+
+// flattenUserSecrets adds the values under prefix to env, with the sections of nested objects,
+// arrays, and colon-separated keys joined by __.
+func flattenUserSecrets(env map[string]string, prefix string, value interface{}) {
+	join := func(key string) string {
+		key = strings.ReplaceAll(key, ":", "__")
+		if prefix == "" {
+			return key
+		}
+		return prefix + "__" + key
+	}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			flattenUserSecrets(env, join(key), child)
+		}
+	case []interface{}:
+		for i, child := range v {
+			flattenUserSecrets(env, join(strconv.Itoa(i)), child)
+		}
+	case nil:
+		env[prefix] = ""
+	default:
+		env[prefix] = fmt.Sprint(v)
+	}
+}
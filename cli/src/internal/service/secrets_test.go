@@ -0,0 +1,100 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+
+	"github.com/jongio/azd-app/cli/src/internal/secretstore"
+	"github.com/jongio/azd-app/cli/src/internal/statedir"
+)
+
+// setUserSecretsHome points UserSecretsPath at a temporary directory.
+func setUserSecretsHome(t *testing.T) string {
+	t.Helper()
+	home := t.TempDir()
+	if runtime.GOOS == "windows" {
+		t.Setenv("APPDATA", home)
+	} else {
+		t.Setenv("HOME", home)
+	}
+	return home
+}
+
+func TestApplySecrets(t *testing.T) {
+	setUserSecretsHome(t)
+	t.Setenv(statedir.RootEnvVar, t.TempDir())
+	projectDir := t.TempDir()
+
+	apiDir := filepath.Join(projectDir, "api")
+	if err := os.MkdirAll(apiDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	project := `<Project Sdk="Microsoft.NET.Sdk.Web"><PropertyGroup><UserSecretsId>api-1234</UserSecretsId></PropertyGroup></Project>`
+	if err := os.WriteFile(filepath.Join(apiDir, "Api.csproj"), []byte(project), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	secretsPath, err := UserSecretsPath("api-1234")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(secretsPath), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	secrets := "\xef\xbb\xbf" + `{
+  "ConnectionStrings:Db": "Server=local",
+  "Stripe": {"Key": "sk_user", "Retries": 3},
+  "Hosts": ["a", "b"]
+}`
+	if err := os.WriteFile(secretsPath, []byte(secrets), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := secretstore.Open(projectDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Set("api", "Stripe__Key", "sk_store"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Set("", "SHARED", "yes"); err != nil {
+		t.Fatal(err)
+	}
+
+	api := &ServiceRuntime{Name: "api", WorkingDir: "api"}
+	web := &ServiceRuntime{Name: "web", WorkingDir: "web"}
+	if err := ApplySecrets([]*ServiceRuntime{api, web}, projectDir); err != nil {
+		t.Fatalf("ApplySecrets() error = %v", err)
+	}
+
+	want := map[string]string{
+		"ConnectionStrings__Db": "Server=local",
+		"Stripe__Key":           "sk_store",
+		"Stripe__Retries":       "3",
+		"Hosts__0":              "a",
+		"Hosts__1":              "b",
+		"SHARED":                "yes",
+	}
+	if !reflect.DeepEqual(api.Secrets, want) {
+		t.Errorf("api secrets = %v, want %v", api.Secrets, want)
+	}
+	if !reflect.DeepEqual(web.Secrets, map[string]string{"SHARED": "yes"}) {
+		t.Errorf("web secrets = %v, want only shared secrets", web.Secrets)
+	}
+
+	// Secrets are below .env files in the environment of the service
+	api.DotEnv = map[string]string{"SHARED": "from-dotenv"}
+	if env := serviceEnvironment(nil, api); env["SHARED"] != "from-dotenv" || env["Stripe__Key"] != "sk_store" {
+		t.Errorf("serviceEnvironment() = %v", env)
+	}
+}
+
+func TestUserSecretsPathRejectsTraversal(t *testing.T) {
+	for _, id := range []string{"", "..", "../x", `a\b`} {
+		if _, err := UserSecretsPath(id); err == nil {
+			t.Errorf("UserSecretsPath(%q) succeeded, want error", id)
+		}
+	}
+}
@@ -1,11 +1,17 @@
 package service_test
 
 import (
+	"bytes"
 	"os"
 	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/jongio/azd-app/cli/src/internal/service"
+	"github.com/jongio/azd-app/cli/src/internal/types"
 )
 
 func TestParseAzureYaml(t *testing.T) {
@@ -89,6 +95,66 @@ func TestFilterServices(t *testing.T) {
 	}
 }
 
+func TestSelectServices(t *testing.T) {
+	azureYaml := &service.AzureYaml{
+		Services: map[string]service.Service{
+			"web":    {Project: "./web", Uses: []string{"api"}},
+			"api":    {Project: "./api", Uses: []string{"auth", "db"}},
+			"auth":   {Project: "./auth"},
+			"worker": {Project: "./worker", Uses: []string{"db"}},
+		},
+		Resources: map[string]service.Resource{
+			"db": {Type: "db.postgres"},
+		},
+	}
+	graph, err := service.BuildDependencyGraph(azureYaml.Services, azureYaml.Resources)
+	if err != nil {
+		t.Fatalf("BuildDependencyGraph() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		only    []string
+		except  []string
+		noDeps  bool
+		want    []string
+		wantErr bool
+	}{
+		{name: "all services", want: []string{"api", "auth", "web", "worker"}},
+		{name: "only with dependencies", only: []string{"web"}, want: []string{"api", "auth", "web"}},
+		{name: "only without dependencies", only: []string{"web"}, noDeps: true, want: []string{"web"}},
+		{name: "except", except: []string{"worker"}, want: []string{"api", "auth", "web"}},
+		{name: "except wins over dependencies", only: []string{"web"}, except: []string{"auth"}, want: []string{"api", "web"}},
+		{name: "unknown service", only: []string{"frontend"}, wantErr: true},
+		{name: "resource is not a service", except: []string{"db"}, wantErr: true},
+		{name: "nothing left", only: []string{"worker"}, except: []string{"worker"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := graph
+			if tt.noDeps {
+				g = nil
+			}
+			result, err := service.SelectServices(azureYaml, g, tt.only, tt.except)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SelectServices() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			var got []string
+			for name := range result {
+				got = append(got, name)
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SelectServices() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestHasServices(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -243,6 +309,82 @@ services:
 	}
 }
 
+func TestValidateProjectPaths(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "src", "api"), 0750); err != nil {
+		t.Fatal(err)
+	}
+	symlinks := true
+	if err := os.Symlink(outside, filepath.Join(root, "shared")); err != nil {
+		symlinks = false // e.g. Windows without developer mode
+	}
+
+	tests := []struct {
+		name     string
+		project  string
+		wantErr  string
+		symlinks bool
+	}{
+		{name: "relative", project: "./src/api"},
+		{name: "root", project: "."},
+		{name: "not created yet", project: "./src/web"},
+		{name: "dot-dot inside", project: "./src/../src/api"},
+		{name: "parent", project: "../other", wantErr: "api: project ../other is outside the workspace"},
+		{name: "escapes through subfolder", project: "./src/../../other", wantErr: "is outside the workspace"},
+		{name: "absolute", project: filepath.Join(root, "src", "api"), wantErr: "is an absolute path"},
+		{name: "rooted", project: "/srv/api", wantErr: "is an absolute path"},
+		{name: "symlink out", project: "./shared", wantErr: "api: project ./shared links outside the workspace", symlinks: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.symlinks && !symlinks {
+				t.Skip("symlinks not supported")
+			}
+			azureYaml := &service.AzureYaml{Services: map[string]service.Service{"api": {Host: "containerapp", Project: tt.project}}}
+			err := service.ValidateProjectPaths(azureYaml, root)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("ValidateProjectPaths() = %v, want no error", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ValidateProjectPaths() = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseAzureYaml_ProjectOutsideWorkspace(t *testing.T) {
+	tmpDir := t.TempDir()
+	content := `name: test
+services:
+  web:
+    project: ../web
+    host: containerapp
+  api:
+    project: ./api
+    host: containerapp
+  worker:
+    project: /opt/worker
+    host: containerapp
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, "azure.yaml"), []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := service.ParseAzureYaml(tmpDir)
+	if err == nil {
+		t.Fatal("Expected error for projects outside the workspace")
+	}
+	msg := err.Error()
+	if !strings.Contains(msg, "web: project ../web") || !strings.Contains(msg, "worker: project /opt/worker") || strings.Contains(msg, "api:") {
+		t.Errorf("error should list web and worker only: %v", err)
+	}
+}
+
 func TestFilterServices_EmptyFilter(t *testing.T) {
 	azureYaml := &service.AzureYaml{
 		Services: map[string]service.Service{
@@ -388,3 +530,191 @@ func TestParseAzureYaml_OnlyName(t *testing.T) {
 		t.Errorf("Expected no services, got %d", len(azureYaml.Services))
 	}
 }
+
+func TestNewDockerConfig(t *testing.T) {
+	projectDir := filepath.Join("repo", "src", "api")
+	tests := []struct {
+		name       string
+		dockerfile types.Dockerfile
+		wantPath   string
+		wantArgs   []string
+	}{
+		{
+			name:       "default path",
+			dockerfile: types.Dockerfile{Path: filepath.Join(projectDir, "Dockerfile")},
+		},
+		{
+			name:       "containerfile with args",
+			dockerfile: types.Dockerfile{Path: filepath.Join(projectDir, "Containerfile"), BuildArgs: []string{"NODE_VERSION=20", "API_URL"}},
+			wantPath:   "./Containerfile",
+			wantArgs:   []string{"API_URL"},
+		},
+		{
+			name:       "dockerfile outside the project",
+			dockerfile: types.Dockerfile{Path: filepath.Join("repo", "docker", "api.Dockerfile")},
+			wantPath:   "../../docker/api.Dockerfile",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := service.NewDockerConfig(projectDir, &tt.dockerfile)
+			if config.Path != tt.wantPath {
+				t.Errorf("Path = %q, want %q", config.Path, tt.wantPath)
+			}
+			if strings.Join(config.BuildArgs, ",") != strings.Join(tt.wantArgs, ",") {
+				t.Errorf("BuildArgs = %v, want %v", config.BuildArgs, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestDiffServices(t *testing.T) {
+	previous := map[string]service.Service{
+		"api":    {Host: "containerapp", Project: "./api"},
+		"web":    {Host: "containerapp", Project: "./web"},
+		"worker": {Host: "containerapp", Project: "./worker"},
+	}
+	current := map[string]service.Service{
+		"api":   {Host: "containerapp", Project: "./api"},
+		"web":   {Host: "containerapp", Project: "./web", Uses: []string{"api"}},
+		"admin": {Host: "staticwebapp", Project: "./admin"},
+	}
+
+	changes := service.DiffServices(previous, current)
+
+	if strings.Join(changes.Added, ",") != "admin" {
+		t.Errorf("Added = %v, want [admin]", changes.Added)
+	}
+	if strings.Join(changes.Removed, ",") != "worker" {
+		t.Errorf("Removed = %v, want [worker]", changes.Removed)
+	}
+	if strings.Join(changes.Changed, ",") != "web" {
+		t.Errorf("Changed = %v, want [web]", changes.Changed)
+	}
+	if changes.IsEmpty() {
+		t.Error("IsEmpty() = true, want false")
+	}
+	if !service.DiffServices(previous, previous).IsEmpty() {
+		t.Error("diff of identical services should be empty")
+	}
+}
+
+func TestWriteDiagnostics(t *testing.T) {
+	processes := map[string]*service.ServiceProcess{
+		"api": {
+			Name:      "api",
+			Port:      3000,
+			Ready:     true,
+			StartTime: time.Now().Add(-time.Minute),
+			Runtime:   service.ServiceRuntime{Command: "npm", Args: []string{"run", "dev"}},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := service.WriteDiagnostics(&buf, processes); err != nil {
+		t.Fatalf("WriteDiagnostics failed: %v", err)
+	}
+
+	dump := buf.String()
+	for _, want := range []string{"Services (1):", "api", "3000", "npm run dev", "Goroutines:", "goroutine "} {
+		if !strings.Contains(dump, want) {
+			t.Errorf("diagnostics missing %q:\n%s", want, dump)
+		}
+	}
+}
+
+func TestImportCompose(t *testing.T) {
+	root := t.TempDir()
+	project := &types.ComposeProject{
+		Path: filepath.Join(root, "deploy", "compose.yaml"),
+		Services: []types.ComposeService{
+			{
+				Name:         "api",
+				BuildContext: "../src/api",
+				Dockerfile:   "./Dockerfile",
+				Ports:        []types.ComposePort{{Container: 80}, {Host: 8080, Container: 8080}},
+				Environment:  map[string]string{"REDIS_HOST": "cache", "APP_ENV": "dev"},
+				DependsOn:    []string{"cache", "mail"},
+			},
+			{
+				Name:         "web",
+				BuildContext: "../src/web",
+				Dockerfile:   "docker/web.Dockerfile",
+				BuildArgs:    []string{"NODE_ENV=production"},
+				DependsOn:    []string{"api"},
+			},
+			{Name: "cache", Image: "docker.io/library/redis:7-alpine"},
+			{Name: "mail", Image: "mailhog/mailhog:latest"},
+		},
+	}
+
+	imported, err := service.ImportCompose(project, root)
+	if err != nil {
+		t.Fatalf("ImportCompose failed: %v", err)
+	}
+
+	api := imported.Services["api"]
+	if api.Host != "containerapp" || api.Project != "./src/api" || api.Docker != nil {
+		t.Errorf("api = %+v, want containerapp project ./src/api without docker settings", api)
+	}
+	if api.Config["port"] != 8080 {
+		t.Errorf("api port = %v, want the published port 8080", api.Config["port"])
+	}
+	if len(api.Env) != 2 || api.Env[0].Name != "APP_ENV" || api.Env[1].Value != "cache" {
+		t.Errorf("api env = %+v, want sorted APP_ENV and REDIS_HOST", api.Env)
+	}
+	if len(api.Uses) != 1 || api.Uses[0] != "cache" {
+		t.Errorf("api uses = %v, want [cache] without the skipped mail service", api.Uses)
+	}
+
+	web := imported.Services["web"]
+	if web.Docker == nil || web.Docker.Path != "./docker/web.Dockerfile" || len(web.Docker.BuildArgs) != 1 {
+		t.Errorf("web docker = %+v, want path ./docker/web.Dockerfile and one build arg", web.Docker)
+	}
+	if web.Config != nil {
+		t.Errorf("web config = %v, want none without ports", web.Config)
+	}
+
+	if imported.Resources["cache"].Type != "db.redis" {
+		t.Errorf("cache resource = %+v, want db.redis", imported.Resources["cache"])
+	}
+	if _, ok := imported.Skipped["mail"]; !ok {
+		t.Errorf("mail should be skipped: %v", imported.Skipped)
+	}
+
+	order := service.TopologicalSort(imported.Graph)
+	if len(order) != 2 || order[0][0] != "api" || order[1][0] != "web" {
+		t.Errorf("start order = %v, want [[api] [web]]", order)
+	}
+
+	project.Services[2].DependsOn = []string{"web"}
+	project.Services[2].BuildContext = "../cache"
+	if _, err := service.ImportCompose(project, root); err == nil {
+		t.Error("expected an error for circular depends_on")
+	}
+}
+
+func TestDiscoveryEnv(t *testing.T) {
+	runtimes := []*service.ServiceRuntime{
+		{Name: "api-gateway", Port: 5000, Protocol: "http"},
+		{Name: "web", Port: 3000, Protocol: "https"},
+		{Name: "worker"},
+		{Name: "admin", Port: 4000, Protocol: "http", ForwardedURL: "https://shiny-space-x7-4000.app.github.dev", ForwardedOpen: true},
+		{Name: "billing", Port: 4100, Protocol: "http", ForwardedURL: "https://shiny-space-x7-4100.app.github.dev"},
+	}
+
+	want := map[string]string{
+		"SERVICE_API_GATEWAY_URL":        "http://localhost:5000",
+		"services__api-gateway__http__0": "http://localhost:5000",
+		"SERVICE_WEB_URL":                "https://localhost:3000",
+		"services__web__https__0":        "https://localhost:3000",
+		"SERVICE_ADMIN_URL":              "https://shiny-space-x7-4000.app.github.dev",
+		"services__admin__http__0":       "https://shiny-space-x7-4000.app.github.dev",
+		"SERVICE_BILLING_URL":            "http://localhost:4100",
+		"services__billing__http__0":     "http://localhost:4100",
+	}
+	if got := service.DiscoveryEnv(runtimes); !reflect.DeepEqual(got, want) {
+		t.Errorf("DiscoveryEnv() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+)
+
+// Kinds of differences between azure.yaml and the projects in the workspace.
+const (
+	SyncMissingProject   = "missing-project"   // The project path of a service no longer exists
+	SyncNewProject       = "new-project"       // A detected project has no service
+	SyncLanguageMismatch = "language-mismatch" // The language of a service is not the detected one
+)
+
+// syncKindOrder orders changes by kind.
+var syncKindOrder = map[string]int{SyncMissingProject: 0, SyncLanguageMismatch: 1, SyncNewProject: 2}
+
+// SyncChange is a difference between azure.yaml and the workspace, and how it is fixed: a
+// service with a missing project is removed, a new project is added as a service with its
+// suggested language and host, and a language mismatch is resolved to the detected language.
+type SyncChange struct {
+	Kind     string `json:"kind"`
+	Service  string `json:"service"`
+	Project  string `json:"project"`
+	Language string `json:"language,omitempty"` // Language in azure.yaml
+	Detected string `json:"detected,omitempty"` // Detected language
+	Host     string `json:"host,omitempty"`     // Host of a new service
+}
+
+// DiffProjects compares the services of azureYaml, whose file is in azureYamlDir, with the
+// projects detected there. Services without a project, such as prebuilt images, are left alone.
+func DiffProjects(azureYaml *AzureYaml, azureYamlDir string, detected []DetectedService) []SyncChange {
+	var changes []SyncChange
+
+	declared := make(map[string]string, len(azureYaml.Services)) // Project directory to service
+	for name, svc := range azureYaml.Services {
+		if svc.Project == "" {
+			continue
+		}
+		dir := svc.Project
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(azureYamlDir, dir)
+		}
+		dir = filepath.Clean(dir)
+		info, err := os.Stat(dir)
+		if os.IsNotExist(err) {
+			changes = append(changes, SyncChange{Kind: SyncMissingProject, Service: name, Project: svc.Project, Language: svc.Language})
+			continue
+		}
+		if err == nil && !info.IsDir() {
+			dir = filepath.Dir(dir) // e.g. ./src/Api/Api.csproj
+		}
+		declared[dir] = name
+	}
+
+	taken := make(map[string]bool, len(azureYaml.Services))
+	for name := range azureYaml.Services {
+		taken[name] = true
+	}
+	for _, d := range detected {
+		name, ok := declared[filepath.Clean(d.Dir)]
+		if !ok {
+			name = uniqueServiceName(d.Name, taken)
+			taken[name] = true
+			changes = append(changes, SyncChange{Kind: SyncNewProject, Service: name, Project: d.Project, Detected: d.Languages[0], Host: d.Hosts[0]})
+			continue
+		}
+		svc := azureYaml.Services[name]
+		if svc.Language != "" && !languageDetected(svc.Language, d.Languages) {
+			changes = append(changes, SyncChange{Kind: SyncLanguageMismatch, Service: name, Project: svc.Project, Language: svc.Language, Detected: d.Languages[0]})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Kind != changes[j].Kind {
+			return syncKindOrder[changes[i].Kind] < syncKindOrder[changes[j].Kind]
+		}
+		return changes[i].Service < changes[j].Service
+	})
+	return changes
+}
+
+// ApplySync applies changes to azureYaml. References to removed services in uses are removed too.
+func ApplySync(azureYaml *AzureYaml, changes []SyncChange) {
+	removed := make(map[string]bool)
+	for _, change := range changes {
+		switch change.Kind {
+		case SyncMissingProject:
+			delete(azureYaml.Services, change.Service)
+			removed[change.Service] = true
+		case SyncNewProject:
+			if azureYaml.Services == nil {
+				azureYaml.Services = make(map[string]Service)
+			}
+			azureYaml.Services[change.Service] = Service{Host: change.Host, Language: change.Detected, Project: change.Project}
+		case SyncLanguageMismatch:
+			svc := azureYaml.Services[change.Service]
+			svc.Language = change.Detected
+			azureYaml.Services[change.Service] = svc
+		}
+	}
+
+	if len(removed) == 0 {
+		return
+	}
+	for name, svc := range azureYaml.Services {
+		var uses []string
+		for _, dep := range svc.Uses {
+			if !removed[dep] {
+				uses = append(uses, dep)
+			}
+		}
+		if len(uses) != len(svc.Uses) {
+			svc.Uses = uses
+			azureYaml.Services[name] = svc
+		}
+	}
+}
+
+// languageDetected reports whether language, as written in azure.yaml, is one of the detected
+// languages. JavaScript and TypeScript run the same way, and docker and custom services can
+// build any project, so those are not mismatches.
+func languageDetected(language string, detected []string) bool {
+	declared := syncLanguage(language)
+	if declared == "Docker" || declared == "custom" {
+		return true
+	}
+	for _, candidate := range detected {
+		if syncLanguage(candidate) == declared {
+			return true
+		}
+	}
+	return false
+}
+
+// syncLanguage normalizes a language for comparison.
+func syncLanguage(language string) string {
+	switch normalized := normalizeLanguage(language); normalized {
+	case "TypeScript":
+		return "JavaScript"
+	case "fsharp", "vb":
+		return ".NET"
+	default:
+		return normalized
+	}
+}
+
+// uniqueServiceName returns name, or name with a number appended if a service already has it.
+func uniqueServiceName(name string, taken map[string]bool) string {
+	if !taken[name] {
+		return name
+	}
+	for i := 2; ; i++ {
+		if candidate := name + "-" + strconv.Itoa(i); !taken[candidate] {
+			return candidate
+		}
+	}
+}
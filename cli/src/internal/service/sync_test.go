@@ -0,0 +1,84 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDiffProjects(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"api", "web", "worker", "admin", filepath.Join("src", "Orders")} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0750); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(root, "src", "Orders", "Orders.csproj"), []byte("<Project />"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	azureYaml := &AzureYaml{Services: map[string]Service{
+		"api":    {Host: "containerapp", Language: "py", Project: "./api"},
+		"web":    {Host: "staticwebapp", Language: "js", Project: "./web"},
+		"worker": {Host: "containerapp", Language: "python", Project: "./worker"},
+		"orders": {Host: "containerapp", Language: "csharp", Project: "./src/Orders/Orders.csproj"},
+		"old":    {Host: "containerapp", Language: "js", Project: "./old"},
+		"cache":  {Host: "containerapp", Image: "redis:7"},
+	}}
+	detected := []DetectedService{
+		{Name: "admin", Dir: filepath.Join(root, "admin"), Project: "./admin", Languages: []string{"python"}, Hosts: []string{"containerapp"}},
+		{Name: "api", Dir: filepath.Join(root, "api"), Project: "./api", Languages: []string{"python"}, Hosts: []string{"containerapp"}},
+		{Name: "orders", Dir: filepath.Join(root, "src", "Orders"), Project: "./src/Orders", Languages: []string{"dotnet"}, Hosts: []string{"containerapp"}},
+		{Name: "web", Dir: filepath.Join(root, "web"), Project: "./web", Languages: []string{"ts"}, Hosts: []string{"staticwebapp", "containerapp"}},
+		{Name: "worker", Dir: filepath.Join(root, "worker"), Project: "./worker", Languages: []string{"js"}, Hosts: []string{"containerapp"}},
+	}
+
+	got := DiffProjects(azureYaml, root, detected)
+	want := []SyncChange{
+		{Kind: SyncMissingProject, Service: "old", Project: "./old", Language: "js"},
+		{Kind: SyncLanguageMismatch, Service: "worker", Project: "./worker", Language: "python", Detected: "js"},
+		{Kind: SyncNewProject, Service: "admin", Project: "./admin", Detected: "python", Host: "containerapp"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DiffProjects() =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestDiffProjectsNameCollision(t *testing.T) {
+	root := t.TempDir()
+	azureYaml := &AzureYaml{Services: map[string]Service{"api": {Host: "containerapp", Image: "api:latest"}}}
+	detected := []DetectedService{{Name: "api", Dir: filepath.Join(root, "api"), Project: "./api", Languages: []string{"js"}, Hosts: []string{"containerapp"}}}
+
+	changes := DiffProjects(azureYaml, root, detected)
+	if len(changes) != 1 || changes[0].Service != "api-2" {
+		t.Errorf("DiffProjects() = %+v, want the new project named api-2", changes)
+	}
+}
+
+func TestApplySync(t *testing.T) {
+	azureYaml := &AzureYaml{Services: map[string]Service{
+		"web":    {Host: "containerapp", Language: "python", Project: "./web", Uses: []string{"old", "api"}},
+		"api":    {Host: "containerapp", Language: "python", Project: "./api"},
+		"old":    {Host: "containerapp", Project: "./old"},
+		"mobile": {Host: "containerapp", Project: "./mobile", Uses: []string{"old"}},
+	}}
+	ApplySync(azureYaml, []SyncChange{
+		{Kind: SyncMissingProject, Service: "old"},
+		{Kind: SyncLanguageMismatch, Service: "web", Detected: "ts"},
+		{Kind: SyncNewProject, Service: "admin", Project: "./admin", Detected: "python", Host: "containerapp"},
+	})
+
+	if _, ok := azureYaml.Services["old"]; ok {
+		t.Error("service with a missing project should be removed")
+	}
+	if web := azureYaml.Services["web"]; web.Language != "ts" || !reflect.DeepEqual(web.Uses, []string{"api"}) {
+		t.Errorf("web = %+v, want language ts and uses [api]", web)
+	}
+	if mobile := azureYaml.Services["mobile"]; mobile.Uses != nil {
+		t.Errorf("mobile uses = %v, want none", mobile.Uses)
+	}
+	if admin := azureYaml.Services["admin"]; admin.Project != "./admin" || admin.Language != "python" || admin.Host != "containerapp" {
+		t.Errorf("admin = %+v", admin)
+	}
+}
@@ -3,54 +3,53 @@ package service
 import (
 	"io"
 	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
-)
-
-// AzureYaml represents the parsed azure.yaml file.
-type AzureYaml struct {
-	Name      string                 `yaml:"name"`
-	Services  map[string]Service     `yaml:"services"`
-	Resources map[string]Resource    `yaml:"resources"`
-	Metadata  map[string]interface{} `yaml:"metadata,omitempty"`
-}
 
-// Service represents a service definition in azure.yaml.
-type Service struct {
-	Host       string                 `yaml:"host"`
-	Language   string                 `yaml:"language,omitempty"`
-	Project    string                 `yaml:"project,omitempty"`
-	Entrypoint string                 `yaml:"entrypoint,omitempty"` // Entry point file for Python/Node projects
-	Image      string                 `yaml:"image,omitempty"`
-	Docker     *DockerConfig          `yaml:"docker,omitempty"`
-	Config     map[string]interface{} `yaml:"config,omitempty"`
-	Env        []EnvVar               `yaml:"env,omitempty"`
-	Uses       []string               `yaml:"uses,omitempty"`
-}
-
-// DockerConfig represents Docker build configuration.
-type DockerConfig struct {
-	Path        string   `yaml:"path,omitempty"`
-	Context     string   `yaml:"context,omitempty"`
-	Platform    string   `yaml:"platform,omitempty"`
-	Registry    string   `yaml:"registry,omitempty"`
-	Image       string   `yaml:"image,omitempty"`
-	Tag         string   `yaml:"tag,omitempty"`
-	BuildArgs   []string `yaml:"buildArgs,omitempty"`
-	RemoteBuild bool     `yaml:"remoteBuild,omitempty"`
-}
+	"github.com/jongio/azd-app/cli/src/internal/azureyaml"
+	"github.com/jongio/azd-app/cli/src/internal/container"
+	"github.com/jongio/azd-app/cli/src/internal/retry"
+	"github.com/jongio/azd-app/cli/src/internal/sandbox"
+	"github.com/jongio/azd-app/cli/src/internal/types"
+)
 
-// EnvVar represents an environment variable.
-type EnvVar struct {
-	Name   string `yaml:"name"`
-	Value  string `yaml:"value,omitempty"`
-	Secret string `yaml:"secret,omitempty"`
-}
+// The azure.yaml model is defined by the azureyaml package.
+type (
+	AzureYaml      = azureyaml.Project
+	Service        = azureyaml.Service
+	DockerConfig   = azureyaml.DockerConfig
+	EnvVar         = azureyaml.EnvVar
+	Resource       = azureyaml.Resource
+	RetrySettings  = azureyaml.RetrySettings
+	RetryPolicy    = azureyaml.RetryPolicy
+	ReadySettings  = azureyaml.ReadySettings
+	HealthSettings = azureyaml.HealthSettings
+	RequiredEnvVar = azureyaml.RequiredEnvVar
+	OutputSettings = azureyaml.OutputSettings
+)
 
-// Resource represents a resource definition in azure.yaml.
-type Resource struct {
-	Type     string   `yaml:"type"`
-	Uses     []string `yaml:"uses,omitempty"`
-	Existing bool     `yaml:"existing,omitempty"`
+// NewDockerConfig returns the azure.yaml docker settings for building dockerfile as part of the
+// service in projectDir. The path is relative to projectDir and omitted for the default
+// ./Dockerfile. Build arguments are only listed when they declare no default, so that their
+// values are taken from the environment at build time.
+func NewDockerConfig(projectDir string, dockerfile *types.Dockerfile) *DockerConfig {
+	config := &DockerConfig{}
+	if rel, err := filepath.Rel(projectDir, dockerfile.Path); err == nil && rel != "Dockerfile" {
+		rel = filepath.ToSlash(rel)
+		if !strings.HasPrefix(rel, "../") {
+			rel = "./" + rel
+		}
+		config.Path = rel
+	}
+	for _, arg := range dockerfile.BuildArgs {
+		if !strings.Contains(arg, "=") {
+			config.BuildArgs = append(config.BuildArgs, arg)
+		}
+	}
+	return config
 }
 
 // ServiceRuntime contains the detected runtime information for a service.
@@ -65,17 +64,37 @@ type ServiceRuntime struct {
 	Port           int
 	Protocol       string
 	Env            map[string]string
+	AzdEnv         map[string]string // Values of the azd environment, with the variables azdEnv in azure.yaml maps; below Secrets
+	Secrets        map[string]string // dotnet user-secrets and the secrets of 'azd app secret'; below DotEnv
+	DotEnv         map[string]string // Variables of the .env files of the workspace and service, below those the service is started with
+	EmulatorEnv    map[string]string // Variables of the local emulators of the resources the service uses, over those the service is started with
 	HealthCheck    HealthCheckConfig
+	Dockerfile     *types.Dockerfile // Optional: Dockerfile of the service, for running it in a container
+	Sandbox        *sandbox.Sandbox  // Optional: runs the service command in a container (--sandbox)
+	Container      *ContainerRun     // Optional: runs the service as a container built from its Dockerfile (runner: docker)
+	DependsOn      []string          // Services and resources started before this one
+	ForwardedURL   string            // Optional: URL the port is forwarded to, e.g. in a codespace, shown instead of localhost
+	ForwardedOpen  bool              // ForwardedURL answers without signing in, so other services are given it instead of localhost
+	Restart        RestartPolicy     // Whether the service is started again when it exits
+	RequiredEnv    []EnvRequirement  // Variables that must be set before the service starts
+	Outputs        []OutputCapture   // Values captured from the output of the service once started
+	Inputs         []OutputBinding   // Variables set to outputs of other services
 }
 
-// HealthCheckConfig defines how to check if a service is ready.
+// HealthCheckConfig defines how to check if a service is ready, and healthy while it runs.
 type HealthCheckConfig struct {
 	Type     string        // "http", "port", "process", "log"
 	Path     string        // For HTTP health checks (e.g., "/health")
 	Port     int           // Port to check
-	Timeout  time.Duration // How long to wait for service to be ready
-	Interval time.Duration // How often to retry
-	LogMatch string        // For log-based checks (e.g., "Server started")
+	Retry    retry.Policy  // How often and how quickly to probe until the service is ready
+	LogMatch string        // For log-based checks, a regular expression (e.g., "Server started")
+	Timeout  time.Duration // For log-based checks, how long to wait for a matching line
+
+	// Probes of the running service by the HealthManager
+	Probe        string        // "http" or "port"; by default http, unless Type is "log" or "port"
+	Interval     time.Duration // Time between probes
+	ProbeTimeout time.Duration // Longest wait for one probe
+	Strict       bool          // Only 2xx and 3xx responses are healthy, rather than any below 500
 }
 
 // ServiceProcess represents a running service process.
@@ -92,6 +111,15 @@ type ServiceProcess struct {
 	Ready       bool
 	HealthCheck chan error
 	Env         map[string]string
+
+	// Exit of the process, awaited once for all callers of Exited
+	waitOnce     sync.Once
+	exited       chan struct{}
+	state        *os.ProcessState
+	stopping     atomic.Bool // Set by StopService, so that the exit is not taken for a crash
+	exitRecorded atomic.Bool // Set once the exited record of the process is written
+
+	forwarder *container.Forwarder // Forwards the port of a sandboxed service from a remote engine
 }
 
 // DependencyGraph represents service dependencies.
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jongio/azd-app/cli/src/internal/httpclient"
+	"github.com/jongio/azd-app/cli/src/internal/registry"
+)
+
+// virtualNamePattern matches the names virtual services may have, which become part of
+// environment variable names.
+var virtualNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]*$`)
+
+// virtualProbeTimeout bounds a single health probe of a virtual service.
+const virtualProbeTimeout = 2 * time.Second
+
+// VirtualService is a service that runs outside azd app, such as a process a library consumer
+// or a debugger started, which is listed and health checked with the services azd app runs,
+// and whose URL is injected into the services started after it is registered.
+type VirtualService struct {
+	Name       string `json:"name"`
+	URL        string `json:"url"`
+	HealthPath string `json:"healthPath,omitempty"` // Path below URL that answers when the service is ready; URL itself by default
+	Language   string `json:"language,omitempty"`
+	Framework  string `json:"framework,omitempty"`
+}
+
+// Validate checks that the service has a usable name and an http or https URL.
+func (v VirtualService) Validate() error {
+	if !virtualNamePattern.MatchString(v.Name) {
+		return fmt.Errorf("invalid service name '%s': use letters, digits, '-', and '_'", v.Name)
+	}
+	u, err := url.Parse(v.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid URL '%s' for service %s: must be an http or https URL", v.URL, v.Name)
+	}
+	if v.HealthPath != "" && !strings.HasPrefix(v.HealthPath, "/") {
+		return fmt.Errorf("invalid health path '%s' for service %s: must start with '/'", v.HealthPath, v.Name)
+	}
+	return nil
+}
+
+// healthURL returns the URL probed to check whether the service is ready.
+func (v VirtualService) healthURL() string {
+	if v.HealthPath == "" {
+		return v.URL
+	}
+	return strings.TrimSuffix(v.URL, "/") + v.HealthPath
+}
+
+// RegisterVirtualService adds a service started outside azd app to the services of the project
+// in projectDir, replacing an earlier registration of the same name. The service is probed
+// once, and its health is reported but does not prevent registration. A running service of
+// the same name that azd app started can't be replaced.
+func RegisterVirtualService(projectDir string, vs VirtualService) (*registry.ServiceRegistryEntry, error) {
+	if err := vs.Validate(); err != nil {
+		return nil, err
+	}
+
+	reg := registry.GetRegistry(projectDir)
+	if existing, exists := reg.GetService(vs.Name); exists && !existing.Virtual && existing.Status != "stopped" {
+		return nil, fmt.Errorf("service %s is already run by azd app", vs.Name)
+	}
+
+	u, _ := url.Parse(vs.URL)
+	port, _ := strconv.Atoi(u.Port())
+	if port == 0 && u.Scheme == "https" {
+		port = 443
+	} else if port == 0 {
+		port = 80
+	}
+
+	now := time.Now()
+	entry := &registry.ServiceRegistryEntry{
+		Name:       vs.Name,
+		ProjectDir: projectDir,
+		Port:       port,
+		URL:        strings.TrimSuffix(vs.URL, "/"),
+		HealthURL:  vs.healthURL(),
+		Language:   vs.Language,
+		Framework:  vs.Framework,
+		Status:     "running",
+		Health:     probeHealth(vs.healthURL()),
+		StartTime:  now,
+		Virtual:    true,
+	}
+	if err := reg.Register(entry); err != nil {
+		return nil, fmt.Errorf("failed to register service %s: %w", vs.Name, err)
+	}
+	return entry, nil
+}
+
+// UnregisterVirtualService removes a service registered with RegisterVirtualService.
+func UnregisterVirtualService(projectDir, name string) error {
+	reg := registry.GetRegistry(projectDir)
+	entry, exists := reg.GetService(name)
+	if !exists || !entry.Virtual {
+		return fmt.Errorf("no virtual service named %s", name)
+	}
+	return reg.Unregister(name)
+}
+
+// VirtualServices returns the virtual services registered for the project in projectDir,
+// sorted by name.
+func VirtualServices(projectDir string) []*registry.ServiceRegistryEntry {
+	var services []*registry.ServiceRegistryEntry
+	for _, entry := range registry.GetRegistry(projectDir).ListAll() {
+		if entry.Virtual {
+			services = append(services, entry)
+		}
+	}
+	sort.Slice(services, func(i, j int) bool { return services[i].Name < services[j].Name })
+	return services
+}
+
+// CheckVirtualServices probes the virtual services of the project in projectDir and records
+// their health.
+func CheckVirtualServices(projectDir string) {
+	reg := registry.GetRegistry(projectDir)
+	for _, entry := range VirtualServices(projectDir) {
+		healthURL := entry.HealthURL
+		if healthURL == "" {
+			healthURL = entry.URL
+		}
+		_ = reg.UpdateStatus(entry.Name, entry.Status, probeHealth(healthURL))
+	}
+}
+
+// probeHealth requests healthURL once and returns "healthy" for a 2xx or 3xx response and
+// "unhealthy" otherwise.
+func probeHealth(healthURL string) string {
+	resp, err := httpclient.NewLocal(virtualProbeTimeout).Get(healthURL)
+	if err != nil {
+		return "unhealthy"
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 400 {
+		return "healthy"
+	}
+	return "unhealthy"
+}
+
+// virtualDiscoveryEnv returns the discovery variables of DiscoveryEnv for virtual services.
+func virtualDiscoveryEnv(services []*registry.ServiceRegistryEntry) map[string]string {
+	env := make(map[string]string)
+	for _, entry := range services {
+		protocol := "http"
+		if strings.HasPrefix(entry.URL, "https://") {
+			protocol = "https"
+		}
+		setDiscoveryEnv(env, entry.Name, protocol, entry.URL)
+	}
+	return env
+}
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/jongio/azd-app/cli/src/internal/registry"
+)
+
+func TestVirtualServiceValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		vs      VirtualService
+		wantErr bool
+	}{
+		{name: "valid", vs: VirtualService{Name: "api", URL: "http://localhost:5000", HealthPath: "/health"}},
+		{name: "https", vs: VirtualService{Name: "auth_server", URL: "https://localhost:7001"}},
+		{name: "empty name", vs: VirtualService{URL: "http://localhost:5000"}, wantErr: true},
+		{name: "name with spaces", vs: VirtualService{Name: "my api", URL: "http://localhost:5000"}, wantErr: true},
+		{name: "no scheme", vs: VirtualService{Name: "api", URL: "localhost:5000"}, wantErr: true},
+		{name: "other scheme", vs: VirtualService{Name: "api", URL: "tcp://localhost:5000"}, wantErr: true},
+		{name: "relative health path", vs: VirtualService{Name: "api", URL: "http://localhost:5000", HealthPath: "health"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.vs.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRegisterVirtualService(t *testing.T) {
+	projectDir := t.TempDir()
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/health" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer backend.Close()
+
+	entry, err := RegisterVirtualService(projectDir, VirtualService{Name: "api", URL: backend.URL + "/", HealthPath: "/health", Language: "go"})
+	if err != nil {
+		t.Fatalf("RegisterVirtualService() error = %v", err)
+	}
+	if !entry.Virtual || entry.Health != "healthy" || entry.URL != backend.URL || entry.PID != 0 {
+		t.Errorf("entry = %+v, want a healthy virtual entry at %s without a PID", entry, backend.URL)
+	}
+
+	// A probe of the URL itself fails, so the second service is registered but unhealthy
+	entry, err = RegisterVirtualService(projectDir, VirtualService{Name: "worker", URL: backend.URL})
+	if err != nil {
+		t.Fatalf("RegisterVirtualService() error = %v", err)
+	}
+	if entry.Health != "unhealthy" {
+		t.Errorf("worker health = %s, want unhealthy", entry.Health)
+	}
+
+	var names []string
+	for _, vs := range VirtualServices(projectDir) {
+		names = append(names, vs.Name)
+	}
+	if want := []string{"api", "worker"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("VirtualServices() = %v, want %v", names, want)
+	}
+
+	if err := UnregisterVirtualService(projectDir, "worker"); err != nil {
+		t.Fatalf("UnregisterVirtualService() error = %v", err)
+	}
+	if err := UnregisterVirtualService(projectDir, "worker"); err == nil {
+		t.Error("UnregisterVirtualService() of an unknown service succeeded")
+	}
+	if got := len(VirtualServices(projectDir)); got != 1 {
+		t.Errorf("VirtualServices() after unregistering = %d services, want 1", got)
+	}
+}
+
+func TestRegisterVirtualService_RunningServiceConflict(t *testing.T) {
+	projectDir := t.TempDir()
+	reg := registry.GetRegistry(projectDir)
+	if err := reg.Register(&registry.ServiceRegistryEntry{Name: "web", Port: 3000, Status: "running", PID: 42}); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	if _, err := RegisterVirtualService(projectDir, VirtualService{Name: "web", URL: "http://localhost:3001"}); err == nil {
+		t.Error("RegisterVirtualService() replaced a service run by azd app")
+	}
+	if err := UnregisterVirtualService(projectDir, "web"); err == nil {
+		t.Error("UnregisterVirtualService() removed a service run by azd app")
+	}
+}
+
+func TestVirtualDiscoveryEnv(t *testing.T) {
+	env := virtualDiscoveryEnv([]*registry.ServiceRegistryEntry{
+		{Name: "auth-server", URL: "https://localhost:7001"},
+		{Name: "api", URL: "http://10.0.0.5:5000"},
+	})
+
+	want := map[string]string{
+		"SERVICE_AUTH_SERVER_URL":         "https://localhost:7001",
+		"services__auth-server__https__0": "https://localhost:7001",
+		"SERVICE_API_URL":                 "http://10.0.0.5:5000",
+		"services__api__http__0":          "http://10.0.0.5:5000",
+	}
+	if !reflect.DeepEqual(env, want) {
+		t.Errorf("virtualDiscoveryEnv() = %v, want %v", env, want)
+	}
+}
+
+func TestMergeDiscoveryEnv(t *testing.T) {
+	env := map[string]string{
+		"SERVICE_API_URL": "https://api.azurewebsites.net",
+		"SERVICE_WEB_URL": "http://localhost:3000",
+		"LOG_LEVEL":       "debug",
+	}
+	discovery := map[string]string{
+		"SERVICE_API_URL":        "http://localhost:5000",
+		"SERVICE_WEB_URL":        "http://localhost:3000",
+		"services__api__http__0": "http://localhost:5000",
+	}
+
+	merged, overridden := mergeDiscoveryEnv(env, discovery)
+	want := map[string]string{
+		"SERVICE_API_URL":        "http://localhost:5000",
+		"SERVICE_WEB_URL":        "http://localhost:3000",
+		"services__api__http__0": "http://localhost:5000",
+		"LOG_LEVEL":              "debug",
+	}
+	if !reflect.DeepEqual(merged, want) {
+		t.Errorf("merged = %v, want %v", merged, want)
+	}
+	if !reflect.DeepEqual(overridden, []string{"SERVICE_API_URL"}) {
+		t.Errorf("overridden = %v, want only SERVICE_API_URL", overridden)
+	}
+	if env["SERVICE_API_URL"] != "https://api.azurewebsites.net" {
+		t.Error("mergeDiscoveryEnv modified env")
+	}
+}
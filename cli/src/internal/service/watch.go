@@ -0,0 +1,110 @@
+package service
+
+import (
+	"slices"
+)
+
+// devServerFrameworks are Node.js frameworks whose dev servers reload on changes.
+var devServerFrameworks = map[string]bool{
+	"Next.js":   true,
+	"Vite":      true,
+	"React":     true,
+	"Vue":       true,
+	"Svelte":    true,
+	"SvelteKit": true,
+	"Remix":     true,
+	"Astro":     true,
+	"Nuxt":      true,
+	"Angular":   true,
+	"NestJS":    true,
+}
+
+// HasNativeWatch reports whether the command of runtime reloads the service on changes
+// to its source by itself, so that the service needs no restarts when watching.
+func HasNativeWatch(runtime *ServiceRuntime) bool {
+	tool, args := watchedCommand(runtime)
+
+	switch {
+	case devServerFrameworks[runtime.Framework]:
+		return true
+	case runtime.Language == "PHP":
+		// PHP reads the scripts again on every request
+		return true
+	case tool == "npm" || tool == "pnpm" || tool == "yarn":
+		// dev scripts conventionally run under a watcher such as nodemon or tsx watch
+		return len(args) >= 2 && args[0] == "run" && args[1] == "dev"
+	case tool == "dotnet":
+		return len(args) > 0 && args[0] == "watch"
+	case tool == "uvicorn" || tool == "gunicorn":
+		return slices.Contains(args, "--reload")
+	case tool == "streamlit":
+		return slices.Contains(args, "--server.runOnSave")
+	case isFlaskRun(tool, args):
+		return runtime.Env["FLASK_DEBUG"] == "1"
+	case isDjangoRunserver(args):
+		return !slices.Contains(args, "--noreload")
+	}
+	return false
+}
+
+// EnableNativeWatch switches runtime to the watcher built into its tooling, when there is
+// one: dotnet watch, uvicorn and gunicorn --reload, the Flask debug reloader, and Streamlit's
+// run on save. It reports whether the service now reloads on changes by itself.
+func EnableNativeWatch(runtime *ServiceRuntime) bool {
+	tool, args := watchedCommand(runtime)
+	prefix := runtime.Args[:len(runtime.Args)-len(args)]
+
+	switch {
+	case HasNativeWatch(runtime):
+		return true
+	case tool == "dotnet" && len(args) > 0 && args[0] == "run":
+		args = append([]string{"watch"}, args...)
+		// Restart instead of prompting when a change can't be applied while running
+		setEnv(runtime, "DOTNET_WATCH_RESTART_ON_RUDE_EDIT", "true")
+	case tool == "uvicorn" || tool == "gunicorn":
+		args = append(slices.Clone(args), "--reload")
+	case tool == "streamlit":
+		args = append(slices.Clone(args), "--server.runOnSave", "true")
+	case isFlaskRun(tool, args):
+		setEnv(runtime, "FLASK_DEBUG", "1")
+		return true
+	default:
+		return false
+	}
+
+	runtime.Args = append(slices.Clone(prefix), args...)
+	return true
+}
+
+// watchedCommand returns the tool that runs the service and its arguments, looking through
+// the package manager wrappers added by wrapPythonCommand.
+func watchedCommand(runtime *ServiceRuntime) (string, []string) {
+	switch runtime.Command {
+	case "uv", "poetry", "pipenv":
+		if len(runtime.Args) >= 2 && runtime.Args[0] == "run" {
+			return runtime.Args[1], runtime.Args[2:]
+		}
+	}
+	return runtime.Command, runtime.Args
+}
+
+// isFlaskRun reports whether the command is the Flask development server.
+func isFlaskRun(tool string, args []string) bool {
+	if tool == "flask" {
+		return len(args) > 0 && args[0] == "run"
+	}
+	return len(args) >= 3 && args[0] == "-m" && args[1] == "flask" && args[2] == "run"
+}
+
+// isDjangoRunserver reports whether the command is the Django development server.
+func isDjangoRunserver(args []string) bool {
+	return len(args) >= 2 && args[0] == "manage.py" && args[1] == "runserver"
+}
+
+// setEnv sets an environment variable of the service.
+func setEnv(runtime *ServiceRuntime, key, value string) {
+	if runtime.Env == nil {
+		runtime.Env = make(map[string]string)
+	}
+	runtime.Env[key] = value
+}
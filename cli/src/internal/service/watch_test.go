@@ -0,0 +1,113 @@
+package service_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jongio/azd-app/cli/src/internal/service"
+)
+
+func TestEnableNativeWatch(t *testing.T) {
+	tests := []struct {
+		name       string
+		runtime    service.ServiceRuntime
+		wantNative bool
+		wantArgs   []string
+		wantEnv    map[string]string
+	}{
+		{
+			name:       "vite dev server",
+			runtime:    service.ServiceRuntime{Framework: "Vite", Command: "npm", Args: []string{"run", "dev"}},
+			wantNative: true,
+			wantArgs:   []string{"run", "dev"},
+		},
+		{
+			name:       "express dev script",
+			runtime:    service.ServiceRuntime{Framework: "Express", Command: "pnpm", Args: []string{"run", "dev"}},
+			wantNative: true,
+			wantArgs:   []string{"run", "dev"},
+		},
+		{
+			name:       "express start script",
+			runtime:    service.ServiceRuntime{Framework: "Express", Command: "npm", Args: []string{"run", "start"}},
+			wantNative: false,
+			wantArgs:   []string{"run", "start"},
+		},
+		{
+			name:       "dotnet run becomes dotnet watch run",
+			runtime:    service.ServiceRuntime{Framework: "ASP.NET Core", Command: "dotnet", Args: []string{"run", "--project", "Api.csproj"}},
+			wantNative: true,
+			wantArgs:   []string{"watch", "run", "--project", "Api.csproj"},
+			wantEnv:    map[string]string{"DOTNET_WATCH_RESTART_ON_RUDE_EDIT": "true"},
+		},
+		{
+			name:       "uvicorn already reloading",
+			runtime:    service.ServiceRuntime{Framework: "FastAPI", Command: "uvicorn", Args: []string{"main:app", "--reload"}},
+			wantNative: true,
+			wantArgs:   []string{"main:app", "--reload"},
+		},
+		{
+			name:       "uvicorn through uv gets --reload",
+			runtime:    service.ServiceRuntime{Framework: "FastAPI", Command: "uv", Args: []string{"run", "uvicorn", "main:app"}},
+			wantNative: true,
+			wantArgs:   []string{"run", "uvicorn", "main:app", "--reload"},
+		},
+		{
+			name:       "flask gets the debug reloader",
+			runtime:    service.ServiceRuntime{Framework: "Flask", Command: "python", Args: []string{"-m", "flask", "run"}, Env: map[string]string{}},
+			wantNative: true,
+			wantArgs:   []string{"-m", "flask", "run"},
+			wantEnv:    map[string]string{"FLASK_DEBUG": "1"},
+		},
+		{
+			name:       "streamlit runs on save",
+			runtime:    service.ServiceRuntime{Framework: "Streamlit", Command: "streamlit", Args: []string{"run", "app.py"}},
+			wantNative: true,
+			wantArgs:   []string{"run", "app.py", "--server.runOnSave", "true"},
+		},
+		{
+			name:       "django runserver",
+			runtime:    service.ServiceRuntime{Framework: "Django", Command: "python", Args: []string{"manage.py", "runserver"}},
+			wantNative: true,
+			wantArgs:   []string{"manage.py", "runserver"},
+		},
+		{
+			name:       "php built-in server",
+			runtime:    service.ServiceRuntime{Language: "PHP", Framework: "PHP", Command: "php", Args: []string{"-S", "0.0.0.0:8000"}},
+			wantNative: true,
+			wantArgs:   []string{"-S", "0.0.0.0:8000"},
+		},
+		{
+			name:       "go run is restarted",
+			runtime:    service.ServiceRuntime{Framework: "Go", Command: "go", Args: []string{"run", "."}},
+			wantNative: false,
+			wantArgs:   []string{"run", "."},
+		},
+		{
+			name:       "functions host is restarted",
+			runtime:    service.ServiceRuntime{Framework: "Azure Functions", Command: "func", Args: []string{"start"}},
+			wantNative: false,
+			wantArgs:   []string{"start"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runtime := tt.runtime
+			if got := service.EnableNativeWatch(&runtime); got != tt.wantNative {
+				t.Errorf("EnableNativeWatch() = %v, want %v", got, tt.wantNative)
+			}
+			if !reflect.DeepEqual(runtime.Args, tt.wantArgs) {
+				t.Errorf("Args = %v, want %v", runtime.Args, tt.wantArgs)
+			}
+			for key, want := range tt.wantEnv {
+				if got := runtime.Env[key]; got != want {
+					t.Errorf("Env[%s] = %q, want %q", key, got, want)
+				}
+			}
+			if got := service.HasNativeWatch(&runtime); got != tt.wantNative {
+				t.Errorf("HasNativeWatch() after enabling = %v, want %v", got, tt.wantNative)
+			}
+		})
+	}
+}
@@ -61,6 +61,9 @@ func RefreshEnvironmentFromEvent(bicepOutputs map[string]interface{}) {
 type ServiceInfo struct {
 	Name string `json:"name"`
 
+	// Virtual services run outside azd app and are registered with it
+	Virtual bool `json:"virtual,omitempty"`
+
 	// Azure.yaml definition info
 	Language  string `json:"language,omitempty"`
 	Framework string `json:"framework,omitempty"`
@@ -79,7 +82,7 @@ type ServiceInfo struct {
 // LocalServiceInfo contains local development information.
 type LocalServiceInfo struct {
 	Status      string     `json:"status"` // "running", "not-running", "unknown"
-	Health      string     `json:"health"` // "healthy", "unhealthy", "unknown"
+	Health      string     `json:"health"` // "healthy", "unhealthy", "unknown", "starting", "degraded", "crashed"
 	URL         string     `json:"url,omitempty"`
 	Port        int        `json:"port,omitempty"`
 	PID         int        `json:"pid,omitempty"`
@@ -277,10 +280,19 @@ func mergeServiceInfo(azureYaml *service.AzureYaml, runningServices []*registry.
 		}
 	}
 
-	// Overlay running service information
+	// Overlay running service information; virtual services are listed even when they are
+	// not in azure.yaml
 	for _, runningSvc := range runningServices {
 		normalizedName := strings.ToLower(runningSvc.Name)
+		if _, exists := serviceMap[normalizedName]; !exists && runningSvc.Virtual {
+			serviceMap[normalizedName] = &ServiceInfo{
+				Name:      runningSvc.Name,
+				Language:  runningSvc.Language,
+				Framework: runningSvc.Framework,
+			}
+		}
 		if existing, exists := serviceMap[normalizedName]; exists {
+			existing.Virtual = runningSvc.Virtual
 			existing.Local = &LocalServiceInfo{
 				Status:      runningSvc.Status,
 				Health:      runningSvc.Health,
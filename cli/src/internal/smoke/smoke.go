@@ -0,0 +1,261 @@
+package smoke
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jongio/azd-app/cli/src/internal/security"
+	"github.com/jongio/azd-app/cli/src/internal/workerpool"
+)
+
+// DefaultTimeout is the maximum time a single browser check may take.
+const DefaultTimeout = 30 * time.Second
+
+// maxConcurrentChecks caps the browsers CheckAll runs at once.
+const maxConcurrentChecks = 4
+
+// frontendFrameworks lists frameworks that serve a browser UI.
+var frontendFrameworks = map[string]bool{
+	"Next.js":   true,
+	"Vite":      true,
+	"React":     true,
+	"Vue":       true,
+	"Angular":   true,
+	"SvelteKit": true,
+	"Svelte":    true,
+	"Remix":     true,
+	"Astro":     true,
+	"Nuxt":      true,
+}
+
+// servicePattern restricts service names to safe file names, since screenshots are named
+// after the service.
+var servicePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// chromeCandidates lists browser executables to look for on PATH, in priority order.
+var chromeCandidates = []string{
+	"google-chrome",
+	"google-chrome-stable",
+	"chromium",
+	"chromium-browser",
+	"chrome",
+	"msedge",
+	"microsoft-edge",
+}
+
+// consolePattern matches console messages logged by Chrome with --enable-logging=stderr.
+// Example: [1107/101010.123:INFO:CONSOLE(12)] "Uncaught TypeError: x is undefined", source: http://localhost:3000/main.js (12)
+var consolePattern = regexp.MustCompile(`:CONSOLE\(\d+\)\]\s+"(.*)",\s+source:\s+(\S*)`)
+
+// consoleErrorPattern identifies console messages that indicate a failure.
+var consoleErrorPattern = regexp.MustCompile(`(?i)(uncaught|error|failed to load|exception)`)
+
+// bodyPattern extracts the contents of the <body> element from a dumped DOM.
+var bodyPattern = regexp.MustCompile(`(?is)<body[^>]*>(.*)</body>`)
+
+// tagPattern matches HTML tags.
+var tagPattern = regexp.MustCompile(`(?s)<[^>]*>`)
+
+// scriptPattern matches script and style blocks, which don't render visible content.
+var scriptPattern = regexp.MustCompile(`(?is)<(script|style|noscript)[^>]*>.*?</(script|style|noscript)>`)
+
+// Result contains the outcome of a smoke check for a single service.
+type Result struct {
+	Service       string   `json:"service"`
+	URL           string   `json:"url"`
+	Screenshot    string   `json:"screenshot,omitempty"`
+	ConsoleErrors []string `json:"consoleErrors,omitempty"`
+	Blank         bool     `json:"blank"`
+	Error         string   `json:"error,omitempty"`
+}
+
+// Passed reports whether the page loaded with content and without console errors.
+func (r Result) Passed() bool {
+	return r.Error == "" && !r.Blank && len(r.ConsoleErrors) == 0
+}
+
+// IsFrontend reports whether the given framework serves a browser UI.
+func IsFrontend(framework string) bool {
+	return frontendFrameworks[framework]
+}
+
+// FindChrome locates a Chrome-compatible browser.
+// The CHROME_PATH environment variable takes precedence over PATH lookup.
+func FindChrome() (string, error) {
+	if path := os.Getenv("CHROME_PATH"); path != "" {
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+		return "", fmt.Errorf("CHROME_PATH is set but %s does not exist", path)
+	}
+
+	for _, name := range chromeCandidates {
+		if path, err := exec.LookPath(name); err == nil {
+			return path, nil
+		}
+	}
+
+	if runtime.GOOS == "darwin" {
+		macPath := "/Applications/Google Chrome.app/Contents/MacOS/Google Chrome"
+		if _, err := os.Stat(macPath); err == nil {
+			return macPath, nil
+		}
+	}
+
+	return "", fmt.Errorf("no headless browser found (install Chrome/Chromium or set CHROME_PATH)")
+}
+
+// Check loads a URL in a headless browser, captures a screenshot into outDir,
+// and reports console errors and whether the page rendered any content.
+func Check(ctx context.Context, chromePath, serviceName, url, outDir string) Result {
+	result := Result{
+		Service: serviceName,
+		URL:     url,
+	}
+
+	if !servicePattern.MatchString(serviceName) || strings.Contains(serviceName, "..") {
+		result.Error = fmt.Sprintf("invalid service name '%s'", serviceName)
+		return result
+	}
+	if err := security.ValidatePath(outDir); err != nil {
+		result.Error = fmt.Sprintf("invalid output directory: %v", err)
+		return result
+	}
+	if err := os.MkdirAll(outDir, 0750); err != nil {
+		result.Error = fmt.Sprintf("failed to create output directory: %v", err)
+		return result
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, DefaultTimeout)
+	defer cancel()
+
+	// Load the page once to capture the rendered DOM and console output
+	dom, logs, err := runChrome(ctx, chromePath, "--dump-dom", url)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.ConsoleErrors = ParseConsoleErrors(logs)
+	result.Blank = IsBlankDOM(dom)
+
+	// Capture a screenshot for the run summary
+	screenshot := filepath.Join(outDir, serviceName+".png")
+	if _, _, err := runChrome(ctx, chromePath, "--screenshot="+screenshot, url); err != nil {
+		result.Error = fmt.Sprintf("screenshot failed: %v", err)
+		return result
+	}
+	if _, err := os.Stat(screenshot); err == nil {
+		result.Screenshot = screenshot
+	}
+
+	return result
+}
+
+// CheckAll checks the URL of every service in urls, keyed by service name, a few browsers at
+// a time. Results are sorted by service name.
+func CheckAll(ctx context.Context, chromePath string, urls map[string]string, outDir string) []Result {
+	names := make([]string, 0, len(urls))
+	for name := range urls {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	results := make([]Result, len(names))
+	tasks := make([]workerpool.Task, len(names))
+	for i, name := range names {
+		tasks[i] = workerpool.Task{Name: name, Run: func(ctx context.Context) error {
+			results[i] = Check(ctx, chromePath, name, urls[name], outDir)
+			return nil
+		}}
+	}
+	if err := workerpool.Run(ctx, tasks, workerpool.Options{Workers: maxConcurrentChecks}); err != nil {
+		// Only a canceled context skips checks
+		for i := range results {
+			if results[i].Service == "" {
+				results[i] = Result{Service: names[i], URL: urls[names[i]], Error: err.Error()}
+			}
+		}
+	}
+	return results
+}
+
+// runChrome runs headless Chrome with the given mode flag and returns stdout and stderr.
+func runChrome(ctx context.Context, chromePath, modeFlag, url string) (string, string, error) {
+	args := []string{
+		"--headless=new",
+		"--disable-gpu",
+		"--no-first-run",
+		"--no-default-browser-check",
+		"--hide-scrollbars",
+		"--window-size=1280,800",
+		"--virtual-time-budget=5000",
+		"--enable-logging=stderr",
+		"--v=0",
+		modeFlag,
+		url,
+	}
+
+	// #nosec G204 -- chromePath comes from FindChrome and url is a local service URL
+	cmd := exec.CommandContext(ctx, chromePath, args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return "", "", fmt.Errorf("browser timed out loading %s", url)
+		}
+		return "", "", fmt.Errorf("browser failed: %w", err)
+	}
+
+	return stdout.String(), stderr.String(), nil
+}
+
+// ParseConsoleErrors extracts error-level console messages from Chrome's stderr log.
+func ParseConsoleErrors(logs string) []string {
+	var errors []string
+	seen := make(map[string]bool)
+
+	for _, line := range strings.Split(logs, "\n") {
+		matches := consolePattern.FindStringSubmatch(line)
+		if len(matches) < 2 {
+			continue
+		}
+		message := matches[1]
+		if !consoleErrorPattern.MatchString(message) || seen[message] {
+			continue
+		}
+		seen[message] = true
+		errors = append(errors, message)
+	}
+
+	return errors
+}
+
+// IsBlankDOM reports whether a dumped DOM has no visible text or media content.
+func IsBlankDOM(dom string) bool {
+	matches := bodyPattern.FindStringSubmatch(dom)
+	if len(matches) < 2 {
+		return true
+	}
+
+	body := scriptPattern.ReplaceAllString(matches[1], "")
+	lower := strings.ToLower(body)
+	for _, media := range []string{"<img", "<svg", "<canvas", "<video", "<iframe"} {
+		if strings.Contains(lower, media) {
+			return false
+		}
+	}
+
+	text := tagPattern.ReplaceAllString(body, "")
+	return strings.TrimSpace(text) == ""
+}
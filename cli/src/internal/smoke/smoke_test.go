@@ -0,0 +1,147 @@
+package smoke
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestIsFrontend(t *testing.T) {
+	tests := []struct {
+		framework string
+		expected  bool
+	}{
+		{"Next.js", true},
+		{"React", true},
+		{"Angular", true},
+		{"FastAPI", false},
+		{"ASP.NET Core", false},
+		{"Express", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.framework, func(t *testing.T) {
+			if got := IsFrontend(tt.framework); got != tt.expected {
+				t.Errorf("IsFrontend(%q) = %v, want %v", tt.framework, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseConsoleErrors(t *testing.T) {
+	logs := `[1107/101010.100:INFO:CONSOLE(1)] "Download the React DevTools", source: http://localhost:5173/main.js (1)
+[1107/101010.200:INFO:CONSOLE(12)] "Uncaught TypeError: x is undefined", source: http://localhost:5173/app.js (12)
+[1107/101010.300:WARNING:CONSOLE(3)] "Failed to load resource: the server responded with a status of 404", source: http://localhost:5173/logo.png (0)
+[1107/101010.400:INFO:CONSOLE(12)] "Uncaught TypeError: x is undefined", source: http://localhost:5173/app.js (12)
+[1107/101010.500:ERROR:gpu_init.cc(523)] Passthrough is not supported`
+
+	errors := ParseConsoleErrors(logs)
+
+	if len(errors) != 2 {
+		t.Fatalf("ParseConsoleErrors() returned %d errors, want 2: %v", len(errors), errors)
+	}
+	if errors[0] != "Uncaught TypeError: x is undefined" {
+		t.Errorf("errors[0] = %q", errors[0])
+	}
+	if errors[1] != "Failed to load resource: the server responded with a status of 404" {
+		t.Errorf("errors[1] = %q", errors[1])
+	}
+}
+
+func TestIsBlankDOM(t *testing.T) {
+	tests := []struct {
+		name     string
+		dom      string
+		expected bool
+	}{
+		{
+			name:     "empty root div",
+			dom:      `<html><head></head><body><div id="root"></div><script src="/main.js"></script></body></html>`,
+			expected: true,
+		},
+		{
+			name:     "rendered text",
+			dom:      `<html><body><div id="root"><h1>Hello</h1></div></body></html>`,
+			expected: false,
+		},
+		{
+			name:     "image only",
+			dom:      `<html><body><div><img src="/logo.png"></div></body></html>`,
+			expected: false,
+		},
+		{
+			name:     "noscript fallback only",
+			dom:      `<html><body><noscript>You need to enable JavaScript</noscript><div id="app"></div></body></html>`,
+			expected: true,
+		},
+		{
+			name:     "no body",
+			dom:      ``,
+			expected: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsBlankDOM(tt.dom); got != tt.expected {
+				t.Errorf("IsBlankDOM() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestResultPassed(t *testing.T) {
+	tests := []struct {
+		name     string
+		result   Result
+		expected bool
+	}{
+		{"clean", Result{}, true},
+		{"blank", Result{Blank: true}, false},
+		{"console errors", Result{ConsoleErrors: []string{"Uncaught"}}, false},
+		{"error", Result{Error: "timeout"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.result.Passed(); got != tt.expected {
+				t.Errorf("Passed() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFindChromeFromEnv(t *testing.T) {
+	tmpDir := t.TempDir()
+	fakeChrome := filepath.Join(tmpDir, "chrome")
+	if err := os.WriteFile(fakeChrome, []byte(""), 0600); err != nil {
+		t.Fatalf("failed to create fake chrome: %v", err)
+	}
+
+	t.Setenv("CHROME_PATH", fakeChrome)
+	path, err := FindChrome()
+	if err != nil {
+		t.Fatalf("FindChrome() error = %v", err)
+	}
+	if path != fakeChrome {
+		t.Errorf("FindChrome() = %q, want %q", path, fakeChrome)
+	}
+
+	t.Setenv("CHROME_PATH", filepath.Join(tmpDir, "missing"))
+	if _, err := FindChrome(); err == nil {
+		t.Error("FindChrome() expected error for missing CHROME_PATH")
+	}
+}
+
+func TestCheckRejectsUnsafeServiceNames(t *testing.T) {
+	outDir := t.TempDir()
+	for _, name := range []string{"../escape", "a/b", "..", ""} {
+		result := Check(context.Background(), "chrome-not-used", name, "http://localhost:3000", outDir)
+		if !strings.Contains(result.Error, "invalid service name") {
+			t.Errorf("Check(%q) error = %q, want invalid service name", name, result.Error)
+		}
+	}
+}
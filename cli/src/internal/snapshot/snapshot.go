@@ -0,0 +1,403 @@
+package snapshot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jongio/azd-app/cli/src/internal/container"
+	"github.com/jongio/azd-app/cli/src/internal/executor"
+	"github.com/jongio/azd-app/cli/src/internal/security"
+	"github.com/jongio/azd-app/cli/src/internal/statedir"
+)
+
+// dataKind names the workspace data directory that holds snapshots (see statedir.DataDir).
+const dataKind = "snapshots"
+
+// helperImage is the small image used to archive and extract volume contents.
+const helperImage = "alpine:3"
+
+// manifestFile is the name of the snapshot manifest inside each snapshot directory.
+const manifestFile = "manifest.json"
+
+// namePattern restricts snapshot and volume names to safe file names.
+// Docker volume names use the same character set.
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// composeNamePattern matches characters Docker Compose strips from project names.
+var composeNamePattern = regexp.MustCompile(`[^a-z0-9_-]`)
+
+// Manifest describes a saved snapshot.
+type Manifest struct {
+	Name    string    `json:"name"`
+	Project string    `json:"project"`
+	Created time.Time `json:"created"`
+	Volumes []string  `json:"volumes"`
+}
+
+// Dir returns the directory where snapshots for a project are stored, in the workspace state.
+// Snapshots that earlier versions saved under .azure/snapshots are moved there the first time;
+// when they cannot be moved (for example, to another volume), they are used where they are.
+func Dir(projectDir string) (string, error) {
+	dir, err := statedir.DataPath(projectDir, dataKind)
+	if err != nil {
+		return "", err
+	}
+	legacy := filepath.Join(projectDir, ".azure", "snapshots")
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		return dir, nil
+	}
+	if _, err := os.Stat(legacy); err != nil {
+		return dir, nil
+	}
+	if _, err := statedir.DataDir(projectDir, dataKind); err != nil {
+		return legacy, nil
+	}
+	// Rename needs the target not to exist on every platform
+	if err := os.Remove(dir); err != nil || os.Rename(legacy, dir) != nil {
+		_ = os.MkdirAll(dir, 0750)
+		return legacy, nil
+	}
+	return dir, nil
+}
+
+// namedDir returns the directory of the named snapshot.
+func namedDir(projectDir, name string) (string, error) {
+	dir, err := Dir(projectDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to locate snapshots: %w", err)
+	}
+	return filepath.Join(dir, name), nil
+}
+
+// ValidateName checks that a snapshot name is safe to use as a directory name.
+func ValidateName(name string) error {
+	if !namePattern.MatchString(name) || strings.Contains(name, "..") {
+		return fmt.Errorf("invalid snapshot name '%s' (use letters, digits, '.', '-', '_')", name)
+	}
+	return nil
+}
+
+// ComposeProjectName returns the default Docker Compose project name for a directory.
+func ComposeProjectName(projectDir string) string {
+	if name := os.Getenv("COMPOSE_PROJECT_NAME"); name != "" {
+		return name
+	}
+	return composeNamePattern.ReplaceAllString(strings.ToLower(filepath.Base(projectDir)), "")
+}
+
+// ListVolumes returns the Docker volumes created by a Compose project.
+func ListVolumes(ctx context.Context, project string) ([]string, error) {
+	out, err := runEngine(ctx, "volume", "ls", "--quiet", "--filter", "label=com.docker.compose.project="+project)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list docker volumes: %w", err)
+	}
+	return parseLines(string(out)), nil
+}
+
+// Save archives each volume into a new snapshot and writes its manifest.
+// Containers using the volumes are paused while they are archived so the data is consistent.
+func Save(ctx context.Context, projectDir, name, project string, volumes []string) (*Manifest, error) {
+	if err := ValidateName(name); err != nil {
+		return nil, err
+	}
+	if len(volumes) == 0 {
+		return nil, fmt.Errorf("no volumes to snapshot for project '%s'", project)
+	}
+
+	if _, err := statedir.DataDir(projectDir, dataKind); err != nil {
+		return nil, err
+	}
+	snapshotDir, err := namedDir(projectDir, name)
+	if err != nil {
+		return nil, err
+	}
+	if err := security.ValidatePath(snapshotDir); err != nil {
+		return nil, fmt.Errorf("invalid snapshot path: %w", err)
+	}
+	if _, err := os.Stat(snapshotDir); err == nil {
+		return nil, fmt.Errorf("snapshot '%s' already exists", name)
+	}
+	if err := os.MkdirAll(snapshotDir, 0750); err != nil {
+		return nil, fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+
+	for _, volume := range volumes {
+		if !namePattern.MatchString(volume) {
+			_ = os.RemoveAll(snapshotDir)
+			return nil, fmt.Errorf("invalid volume name '%s'", volume)
+		}
+		err := withContainersStopped(ctx, volume, "pause", "unpause", func() error {
+			return archiveVolume(ctx, volume, snapshotDir)
+		})
+		if err != nil {
+			_ = os.RemoveAll(snapshotDir)
+			return nil, fmt.Errorf("failed to snapshot volume %s: %w", volume, err)
+		}
+	}
+
+	manifest := &Manifest{
+		Name:    name,
+		Project: project,
+		Created: time.Now().UTC(),
+		Volumes: volumes,
+	}
+	if err := writeManifest(snapshotDir, manifest); err != nil {
+		_ = os.RemoveAll(snapshotDir)
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// Restore replaces the contents of each volume in a snapshot with the saved data.
+// Containers using a volume are stopped during the restore and started again afterwards.
+func Restore(ctx context.Context, projectDir, name string) (*Manifest, error) {
+	manifest, err := Load(projectDir, name)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshotDir, err := namedDir(projectDir, name)
+	if err != nil {
+		return nil, err
+	}
+	for _, volume := range manifest.Volumes {
+		if !namePattern.MatchString(volume) {
+			return nil, fmt.Errorf("invalid volume name '%s' in snapshot manifest", volume)
+		}
+		err := withContainersStopped(ctx, volume, "stop", "start", func() error {
+			return extractVolume(ctx, volume, snapshotDir)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to restore volume %s: %w", volume, err)
+		}
+	}
+	return manifest, nil
+}
+
+// Load reads the manifest of a saved snapshot.
+func Load(projectDir, name string) (*Manifest, error) {
+	if err := ValidateName(name); err != nil {
+		return nil, err
+	}
+
+	dir, err := namedDir(projectDir, name)
+	if err != nil {
+		return nil, err
+	}
+	path := filepath.Join(dir, manifestFile)
+	if err := security.ValidatePath(path); err != nil {
+		return nil, fmt.Errorf("invalid snapshot path: %w", err)
+	}
+	// #nosec G304 -- Path validated by security.ValidatePath
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("snapshot '%s' not found", name)
+		}
+		return nil, fmt.Errorf("failed to read snapshot manifest: %w", err)
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot manifest: %w", err)
+	}
+	return &manifest, nil
+}
+
+// List returns all saved snapshots, newest first.
+func List(projectDir string) ([]*Manifest, error) {
+	dir, err := Dir(projectDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate snapshots: %w", err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read snapshots: %w", err)
+	}
+
+	var manifests []*Manifest
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		manifest, err := Load(projectDir, entry.Name())
+		if err != nil {
+			continue // Skip incomplete or foreign directories
+		}
+		manifests = append(manifests, manifest)
+	}
+
+	sort.Slice(manifests, func(i, j int) bool {
+		return manifests[i].Created.After(manifests[j].Created)
+	})
+	return manifests, nil
+}
+
+// Delete removes a saved snapshot.
+func Delete(projectDir, name string) error {
+	if _, err := Load(projectDir, name); err != nil {
+		return err
+	}
+	dir, err := namedDir(projectDir, name)
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(dir)
+}
+
+// ArchiveCommand returns the helper container command that archives /data into /backup.
+func ArchiveCommand(volume string) []string {
+	return []string{"tar", "czf", "/backup/" + volume + ".tar.gz", "-C", "/data", "."}
+}
+
+// ExtractCommand returns the helper container command that replaces /data with the archive in /backup.
+func ExtractCommand(volume string) []string {
+	return []string{"sh", "-c", fmt.Sprintf("find /data -mindepth 1 -delete && tar xzf /backup/%s.tar.gz -C /data", volume)}
+}
+
+// archiveVolume archives volume into snapshotDir.
+func archiveVolume(ctx context.Context, volume, snapshotDir string) error {
+	engine, err := container.Current()
+	if err != nil {
+		return err
+	}
+	if !engine.IsRemote() {
+		return runHelper(ctx, engine, volume, snapshotDir, ArchiveCommand(volume))
+	}
+
+	// A remote engine can't mount the snapshot directory, so the archive is streamed from it
+	path := archivePath(snapshotDir, volume)
+	// #nosec G304 -- Path is built from the validated snapshot directory and volume name
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create archive: %w", err)
+	}
+	defer file.Close()
+	cmd := engine.Command(ctx, "run", "--rm", "-v", volume+":/data", helperImage, "tar", "czf", "-", "-C", "/data", ".")
+	cmd.Stdout = file
+	if out, err := combinedStderr(cmd); err != nil {
+		return fmt.Errorf("command failed: %w: %s", err, out)
+	}
+	return nil
+}
+
+// extractVolume replaces the contents of volume with its archive in snapshotDir.
+func extractVolume(ctx context.Context, volume, snapshotDir string) error {
+	engine, err := container.Current()
+	if err != nil {
+		return err
+	}
+	if !engine.IsRemote() {
+		return runHelper(ctx, engine, volume, snapshotDir, ExtractCommand(volume))
+	}
+
+	// A remote engine can't mount the snapshot directory, so the archive is streamed to it
+	path := archivePath(snapshotDir, volume)
+	// #nosec G304 -- Path is built from the validated snapshot directory and volume name
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer file.Close()
+	cmd := engine.Command(ctx, "run", "--rm", "-i", "-v", volume+":/data", helperImage, "sh", "-c", "find /data -mindepth 1 -delete && tar xzf - -C /data")
+	cmd.Stdin = file
+	if out, err := combinedStderr(cmd); err != nil {
+		return fmt.Errorf("command failed: %w: %s", err, out)
+	}
+	return nil
+}
+
+// archivePath returns the path of the archive of volume in snapshotDir.
+func archivePath(snapshotDir, volume string) string {
+	return filepath.Join(snapshotDir, volume+".tar.gz")
+}
+
+// combinedStderr runs cmd and returns its trimmed standard error.
+func combinedStderr(cmd *exec.Cmd) (string, error) {
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	return strings.TrimSpace(stderr.String()), err
+}
+
+// runHelper runs a throwaway container with the volume mounted at /data and the snapshot directory at /backup.
+func runHelper(ctx context.Context, engine container.Engine, volume, snapshotDir string, command []string) error {
+	args := []string{
+		"run", "--rm",
+		"-v", volume + ":/data",
+		"-v", snapshotDir + ":/backup",
+		helperImage,
+	}
+	args = append(args, command...)
+	_, err := executor.RunCommandWithOutput(ctx, engine.Name, engine.Args(args...), "")
+	return err
+}
+
+// runEngine runs a command of the container engine and returns its output.
+func runEngine(ctx context.Context, args ...string) ([]byte, error) {
+	engine, err := container.Current()
+	if err != nil {
+		return nil, err
+	}
+	return executor.RunCommandWithOutput(ctx, engine.Name, engine.Args(args...), "")
+}
+
+// withContainersStopped runs fn while the running containers that use a volume are
+// suspended with the given docker commands (e.g. "stop"/"start" or "pause"/"unpause").
+func withContainersStopped(ctx context.Context, volume, stopCmd, startCmd string, fn func() error) error {
+	out, err := runEngine(ctx, "ps", "--quiet", "--filter", "volume="+volume)
+	if err != nil {
+		return fmt.Errorf("failed to list containers: %w", err)
+	}
+	containers := parseLines(string(out))
+
+	if len(containers) > 0 {
+		if _, err := runEngine(ctx, append([]string{stopCmd}, containers...)...); err != nil {
+			return fmt.Errorf("failed to %s containers: %w", stopCmd, err)
+		}
+	}
+
+	fnErr := fn()
+
+	// Containers are resumed even when ctx ended, e.g. at --deadline, which stopped fn
+	if len(containers) > 0 {
+		if _, err := runEngine(context.WithoutCancel(ctx), append([]string{startCmd}, containers...)...); err != nil && fnErr == nil {
+			return fmt.Errorf("failed to %s containers: %w", startCmd, err)
+		}
+	}
+	return fnErr
+}
+
+// writeManifest saves a snapshot manifest.
+func writeManifest(snapshotDir string, manifest *Manifest) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode snapshot manifest: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(snapshotDir, manifestFile), data, 0600); err != nil {
+		return fmt.Errorf("failed to write snapshot manifest: %w", err)
+	}
+	return nil
+}
+
+// parseLines splits command output into non-empty trimmed lines.
+func parseLines(out string) []string {
+	var lines []string
+	for _, line := range strings.Split(out, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
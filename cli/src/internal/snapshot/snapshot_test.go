@@ -0,0 +1,143 @@
+package snapshot
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jongio/azd-app/cli/src/internal/statedir"
+)
+
+func TestValidateName(t *testing.T) {
+	tests := []struct {
+		name    string
+		wantErr bool
+	}{
+		{"seed-data", false},
+		{"v1.2_clean", false},
+		{"", true},
+		{"../escape", true},
+		{"a/b", true},
+		{".hidden", true},
+		{"with space", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateName(tt.name); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateName(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestComposeProjectName(t *testing.T) {
+	t.Setenv("COMPOSE_PROJECT_NAME", "")
+	if got := ComposeProjectName("/src/My.App-Gallery"); got != "myapp-gallery" {
+		t.Errorf("ComposeProjectName() = %q, want %q", got, "myapp-gallery")
+	}
+
+	t.Setenv("COMPOSE_PROJECT_NAME", "custom")
+	if got := ComposeProjectName("/src/anything"); got != "custom" {
+		t.Errorf("ComposeProjectName() = %q, want %q", got, "custom")
+	}
+}
+
+func TestListLoadDelete(t *testing.T) {
+	t.Setenv(statedir.RootEnvVar, t.TempDir())
+	projectDir := t.TempDir()
+	snapshotsDir, err := Dir(projectDir)
+	if err != nil {
+		t.Fatalf("Dir() error = %v", err)
+	}
+
+	older := &Manifest{Name: "seed", Project: "app", Created: time.Now().Add(-time.Hour), Volumes: []string{"app_pgdata"}}
+	newer := &Manifest{Name: "after-migration", Project: "app", Created: time.Now(), Volumes: []string{"app_pgdata"}}
+	for _, m := range []*Manifest{older, newer} {
+		dir := filepath.Join(snapshotsDir, m.Name)
+		if err := os.MkdirAll(dir, 0750); err != nil {
+			t.Fatalf("failed to create snapshot dir: %v", err)
+		}
+		if err := writeManifest(dir, m); err != nil {
+			t.Fatalf("writeManifest() error = %v", err)
+		}
+	}
+	// A directory without a manifest is ignored
+	if err := os.MkdirAll(filepath.Join(snapshotsDir, "partial"), 0750); err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+
+	manifests, err := List(projectDir)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(manifests) != 2 || manifests[0].Name != "after-migration" || manifests[1].Name != "seed" {
+		t.Fatalf("List() = %+v, want newest first", manifests)
+	}
+
+	loaded, err := Load(projectDir, "seed")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if loaded.Project != "app" || len(loaded.Volumes) != 1 || loaded.Volumes[0] != "app_pgdata" {
+		t.Errorf("Load() = %+v", loaded)
+	}
+
+	if _, err := Load(projectDir, "missing"); err == nil {
+		t.Error("Load() expected error for missing snapshot")
+	}
+
+	if err := Delete(projectDir, "seed"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if manifests, _ := List(projectDir); len(manifests) != 1 {
+		t.Errorf("List() after Delete = %+v, want 1", manifests)
+	}
+}
+
+func TestDirMovesLegacySnapshots(t *testing.T) {
+	t.Setenv(statedir.RootEnvVar, t.TempDir())
+	projectDir := t.TempDir()
+	legacy := filepath.Join(projectDir, ".azure", "snapshots", "seed")
+	if err := os.MkdirAll(legacy, 0750); err != nil {
+		t.Fatalf("failed to create snapshot dir: %v", err)
+	}
+	if err := writeManifest(legacy, &Manifest{Name: "seed", Project: "app", Created: time.Now()}); err != nil {
+		t.Fatalf("writeManifest() error = %v", err)
+	}
+
+	dir, err := Dir(projectDir)
+	if err != nil {
+		t.Fatalf("Dir() error = %v", err)
+	}
+	if want, _ := statedir.DataPath(projectDir, dataKind); dir != want {
+		t.Errorf("Dir() = %s, want %s", dir, want)
+	}
+	if _, err := os.Stat(legacy); !os.IsNotExist(err) {
+		t.Error("expected legacy snapshots to be moved")
+	}
+	if _, err := Load(projectDir, "seed"); err != nil {
+		t.Errorf("Load() after move error = %v", err)
+	}
+}
+
+func TestListWithoutSnapshots(t *testing.T) {
+	t.Setenv(statedir.RootEnvVar, t.TempDir())
+	manifests, err := List(t.TempDir())
+	if err != nil || len(manifests) != 0 {
+		t.Errorf("List() = %v, %v; want empty", manifests, err)
+	}
+}
+
+func TestHelperCommands(t *testing.T) {
+	archive := ArchiveCommand("app_pgdata")
+	if archive[0] != "tar" || archive[2] != "/backup/app_pgdata.tar.gz" {
+		t.Errorf("ArchiveCommand() = %v", archive)
+	}
+
+	extract := ExtractCommand("app_pgdata")
+	if extract[0] != "sh" || extract[2] != "find /data -mindepth 1 -delete && tar xzf /backup/app_pgdata.tar.gz -C /data" {
+		t.Errorf("ExtractCommand() = %v", extract)
+	}
+}
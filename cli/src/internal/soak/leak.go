@@ -0,0 +1,94 @@
+package soak
+
+import (
+	"sync"
+	"time"
+)
+
+// LeakThresholds decide when the memory of a service looks like a leak.
+type LeakThresholds struct {
+	GrowthPerHour uint64 // Trend of memory, in bytes per hour, above which a service is suspected
+	MinGrowth     uint64 // Bytes the service must have gained over the window, so noise of small services doesn't alert
+	Samples       int    // Samples the trend is taken over; memory must grow across all of them
+}
+
+// DefaultLeakThresholds alert on a service whose memory grew in each of 10 samples, by at
+// least 50 MB in total, at 100 MB an hour or more.
+var DefaultLeakThresholds = LeakThresholds{GrowthPerHour: 100 << 20, MinGrowth: 50 << 20, Samples: 10}
+
+// leakTolerance is the share of memory a sample may drop below the previous one and still
+// count as growing, since garbage collection makes memory jitter.
+const leakTolerance = 0.01
+
+// Leak is memory of a service that grew across the samples of a window.
+type Leak struct {
+	Service       string
+	RSS           uint64        // Memory at the last sample
+	Growth        uint64        // Bytes gained over the window
+	GrowthPerHour float64       // Trend over the window, by least squares
+	Over          time.Duration // Time the window spans
+}
+
+// LeakDetector watches the memory of services for steady growth.
+type LeakDetector struct {
+	mu         sync.Mutex
+	thresholds LeakThresholds
+	windows    map[string][]Sample
+}
+
+// NewLeakDetector creates a detector that reports leaks by thresholds.
+func NewLeakDetector(thresholds LeakThresholds) *LeakDetector {
+	if thresholds.Samples < 2 {
+		thresholds.Samples = 2
+	}
+	return &LeakDetector{thresholds: thresholds, windows: make(map[string][]Sample)}
+}
+
+// Add records a sample of the memory of the named service and returns a leak when its memory
+// grew across the last samples beyond the thresholds. Once a leak is reported, the window
+// starts over, so a service that keeps growing is reported again after as many samples. A
+// sample of another process, after a restart, also starts the window over.
+func (d *LeakDetector) Add(name string, sample Sample) *Leak {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	window := d.windows[name]
+	if sample.RSS == 0 {
+		return nil
+	}
+	if n := len(window); n > 0 {
+		last := window[n-1]
+		if last.PID != sample.PID || float64(sample.RSS) < float64(last.RSS)*(1-leakTolerance) {
+			window = nil
+		}
+	}
+	window = append(window, sample)
+	if len(window) > d.thresholds.Samples {
+		window = window[len(window)-d.thresholds.Samples:]
+	}
+	d.windows[name] = window
+	if len(window) < d.thresholds.Samples {
+		return nil
+	}
+
+	first, last := window[0], window[len(window)-1]
+	if last.RSS <= first.RSS || last.RSS-first.RSS < d.thresholds.MinGrowth {
+		return nil
+	}
+	points := make([]point, len(window))
+	for i, s := range window {
+		points[i] = point{x: s.Time.Sub(first.Time).Hours(), y: float64(s.RSS)}
+	}
+	rate := slope(points)
+	if rate < float64(d.thresholds.GrowthPerHour) {
+		return nil
+	}
+	d.windows[name] = window[len(window)-1:]
+	return &Leak{
+		Service:       name,
+		RSS:           last.RSS,
+		Growth:        last.RSS - first.RSS,
+		GrowthPerHour: rate,
+		Over:          last.Time.Sub(first.Time),
+	}
+}
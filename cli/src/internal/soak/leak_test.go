@@ -0,0 +1,65 @@
+package soak
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestLeakDetector(t *testing.T) {
+	const mb = 1 << 20
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	d := NewLeakDetector(LeakThresholds{GrowthPerHour: 100 * mb, MinGrowth: 20 * mb, Samples: 4})
+	sample := func(minute, pid int, rss uint64) Sample {
+		return Sample{Time: start.Add(time.Duration(minute) * time.Minute), PID: pid, RSS: rss}
+	}
+
+	// 10 MB a minute is 600 MB an hour; the fourth sample completes the window
+	var leak *Leak
+	for i, rss := range []uint64{100, 110, 120, 130} {
+		leak = d.Add("api", sample(i, 1, rss*mb))
+		if i < 3 && leak != nil {
+			t.Fatalf("Add() reported a leak after %d samples", i+1)
+		}
+	}
+	if leak == nil {
+		t.Fatal("Add() reported no leak for steady growth")
+	}
+	if leak.Service != "api" || leak.RSS != 130*mb || leak.Growth != 30*mb || leak.Over != 3*time.Minute {
+		t.Errorf("leak = %+v", leak)
+	}
+	if math.Abs(leak.GrowthPerHour-600*mb) > 1 {
+		t.Errorf("growth = %v bytes/hour, want 600 MB", leak.GrowthPerHour)
+	}
+
+	// The window starts over after a leak: another full window is needed to report it again
+	for i, rss := range []uint64{140, 150} {
+		if leak := d.Add("api", sample(4+i, 1, rss*mb)); leak != nil {
+			t.Fatalf("Add() reported the leak again after %d more samples", i+1)
+		}
+	}
+	if leak := d.Add("api", sample(6, 1, 160*mb)); leak == nil {
+		t.Error("Add() didn't report a service that kept growing")
+	}
+
+	// Memory that drops, grows too little, or belongs to a restarted process isn't a leak
+	tests := []struct {
+		name    string
+		samples []Sample
+	}{
+		{name: "drop", samples: []Sample{sample(0, 1, 100*mb), sample(1, 1, 120*mb), sample(2, 1, 90*mb), sample(3, 1, 130*mb)}},
+		{name: "small growth", samples: []Sample{sample(0, 1, 100*mb), sample(1, 1, 105*mb), sample(2, 1, 110*mb), sample(3, 1, 115*mb)}},
+		{name: "slow growth", samples: []Sample{sample(0, 1, 100*mb), sample(60, 1, 110*mb), sample(120, 1, 120*mb), sample(180, 1, 130*mb)}},
+		{name: "restart", samples: []Sample{sample(0, 1, 100*mb), sample(1, 1, 110*mb), sample(2, 2, 120*mb), sample(3, 2, 130*mb)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewLeakDetector(LeakThresholds{GrowthPerHour: 100 * mb, MinGrowth: 20 * mb, Samples: 4})
+			for _, s := range tt.samples {
+				if leak := d.Add("web", s); leak != nil {
+					t.Errorf("Add() reported a leak: %+v", leak)
+				}
+			}
+		})
+	}
+}
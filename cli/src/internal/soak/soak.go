@@ -0,0 +1,146 @@
+// Package soak records samples of the services of a long run session and reports how they
+// held up: restarts, memory growth, errors over time, and health. It also flags services whose
+// memory grows steadily, as leaks.
+package soak
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jongio/azd-app/cli/src/internal/service"
+)
+
+// States of a sample other than health states. Services without a port are never probed, so
+// they are "running" while their process is.
+const (
+	StateRunning = "running"
+	StateStopped = "stopped"
+	StateExited  = "exited"
+)
+
+// Sample is the state of a service at a point in the session.
+type Sample struct {
+	Time   time.Time `json:"time"`
+	PID    int       `json:"pid"`
+	State  string    `json:"state"`              // Health state, StateRunning, StateStopped, or StateExited
+	RSS    uint64    `json:"rssBytes,omitempty"` // Resident memory of the service and its processes; 0 when unknown
+	Errors int       `json:"errors"`             // Error lines logged since the previous sample
+}
+
+// Report summarizes the samples of a session.
+type Report struct {
+	Start    time.Time       `json:"start"`
+	End      time.Time       `json:"end"`
+	Interval string          `json:"interval"`
+	Services []ServiceReport `json:"services"`
+}
+
+// ServiceReport summarizes the samples of a service.
+type ServiceReport struct {
+	Service             string   `json:"service"`
+	Restarts            int      `json:"restarts"` // Times its process changed between samples
+	MemoryStart         uint64   `json:"memoryStartBytes,omitempty"`
+	MemoryEnd           uint64   `json:"memoryEndBytes,omitempty"`
+	MemoryPeak          uint64   `json:"memoryPeakBytes,omitempty"`
+	MemoryGrowthPerHour float64  `json:"memoryGrowthBytesPerHour"` // Trend of memory across samples, by least squares
+	Errors              int      `json:"errors"`
+	ErrorsPerHour       float64  `json:"errorsPerHour"`
+	HealthyPercent      float64  `json:"healthyPercent"` // Samples in which the service was healthy or running
+	Samples             []Sample `json:"samples"`
+}
+
+// Recorder collects samples of services.
+type Recorder struct {
+	mu       sync.Mutex
+	start    time.Time
+	interval time.Duration
+	samples  map[string][]Sample
+}
+
+// NewRecorder creates a recorder of a session started at start, sampled every interval.
+func NewRecorder(start time.Time, interval time.Duration) *Recorder {
+	return &Recorder{start: start, interval: interval, samples: make(map[string][]Sample)}
+}
+
+// Add records a sample of the named service.
+func (r *Recorder) Add(name string, sample Sample) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.samples[name] = append(r.samples[name], sample)
+}
+
+// Report summarizes the samples recorded until end, by service name.
+func (r *Recorder) Report(end time.Time) *Report {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	report := &Report{Start: r.start, End: end, Interval: r.interval.String()}
+	hours := end.Sub(r.start).Hours()
+	for name, samples := range r.samples {
+		summary := ServiceReport{Service: name, Samples: samples}
+		var healthy int
+		var points []point
+		for i, sample := range samples {
+			if i > 0 && sample.PID != samples[i-1].PID {
+				summary.Restarts++
+			}
+			summary.Errors += sample.Errors
+			if Healthy(sample.State) {
+				healthy++
+			}
+			if sample.RSS > 0 {
+				if summary.MemoryStart == 0 {
+					summary.MemoryStart = sample.RSS
+				}
+				summary.MemoryEnd = sample.RSS
+				summary.MemoryPeak = max(summary.MemoryPeak, sample.RSS)
+				points = append(points, point{x: sample.Time.Sub(r.start).Hours(), y: float64(sample.RSS)})
+			}
+		}
+		summary.MemoryGrowthPerHour = slope(points)
+		if hours > 0 {
+			summary.ErrorsPerHour = float64(summary.Errors) / hours
+		}
+		if len(samples) > 0 {
+			summary.HealthyPercent = 100 * float64(healthy) / float64(len(samples))
+		}
+		report.Services = append(report.Services, summary)
+	}
+	sort.Slice(report.Services, func(i, j int) bool { return report.Services[i].Service < report.Services[j].Service })
+	return report
+}
+
+// Healthy reports whether a service in state is up: answering its health probes, or running
+// without a port to probe.
+func Healthy(state string) bool {
+	return state == service.HealthHealthy || state == StateRunning
+}
+
+// point is a sample of memory: its time in hours since the start, and its bytes.
+type point struct {
+	x, y float64
+}
+
+// slope returns the slope of the least squares line through points, or 0 for fewer than two.
+func slope(points []point) float64 {
+	if len(points) < 2 {
+		return 0
+	}
+	var sumX, sumY float64
+	for _, p := range points {
+		sumX += p.x
+		sumY += p.y
+	}
+	n := float64(len(points))
+	meanX, meanY := sumX/n, sumY/n
+	var cov, variance float64
+	for _, p := range points {
+		cov += (p.x - meanX) * (p.y - meanY)
+		variance += (p.x - meanX) * (p.x - meanX)
+	}
+	if variance == 0 {
+		return 0
+	}
+	return cov / variance
+}
@@ -0,0 +1,48 @@
+package soak
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestRecorderReport(t *testing.T) {
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	r := NewRecorder(start, 30*time.Minute)
+	const mb = 1 << 20
+	api := []Sample{
+		{Time: start.Add(30 * time.Minute), PID: 10, State: "healthy", RSS: 100 * mb, Errors: 1},
+		{Time: start.Add(60 * time.Minute), PID: 10, State: "degraded", RSS: 150 * mb},
+		{Time: start.Add(90 * time.Minute), PID: 11, State: "healthy", RSS: 200 * mb, Errors: 3},
+		{Time: start.Add(120 * time.Minute), PID: 11, State: "healthy", RSS: 250 * mb},
+	}
+	for _, sample := range api {
+		r.Add("api", sample)
+	}
+	r.Add("worker", Sample{Time: start.Add(30 * time.Minute), PID: 20, State: StateRunning})
+	r.Add("worker", Sample{Time: start.Add(60 * time.Minute), PID: 20, State: StateExited})
+
+	report := r.Report(start.Add(2 * time.Hour))
+	if len(report.Services) != 2 || report.Services[0].Service != "api" || report.Interval != "30m0s" {
+		t.Fatalf("report = %+v, want api and worker", report)
+	}
+
+	got := report.Services[0]
+	if got.Restarts != 1 || got.Errors != 4 || got.ErrorsPerHour != 2 {
+		t.Errorf("restarts, errors, per hour = %d, %d, %v, want 1, 4, 2", got.Restarts, got.Errors, got.ErrorsPerHour)
+	}
+	if got.MemoryStart != 100*mb || got.MemoryEnd != 250*mb || got.MemoryPeak != 250*mb {
+		t.Errorf("memory = %d → %d, peak %d", got.MemoryStart, got.MemoryEnd, got.MemoryPeak)
+	}
+	if want := 100.0 * mb; math.Abs(got.MemoryGrowthPerHour-want) > 1 {
+		t.Errorf("growth = %v bytes/hour, want %v", got.MemoryGrowthPerHour, want)
+	}
+	if got.HealthyPercent != 75 {
+		t.Errorf("healthy = %v%%, want 75", got.HealthyPercent)
+	}
+
+	worker := report.Services[1]
+	if worker.MemoryGrowthPerHour != 0 || worker.HealthyPercent != 50 {
+		t.Errorf("worker = %+v, want no memory trend and 50%% healthy", worker)
+	}
+}
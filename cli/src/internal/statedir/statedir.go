@@ -0,0 +1,535 @@
+// Package statedir lays out the on-disk cache, log, and session state kept outside the project.
+//
+// Every workspace gets its own directory under the user cache directory, named after the
+// workspace folder and a hash of its absolute path, so two checkouts never share state.
+// Within a workspace, each running process gets its own session directory for logs, so
+// concurrent sessions never write to the same files:
+//
+//	<root>/workspaces/<name>-<hash>/workspace.json   workspace path and last use
+//	<root>/workspaces/<name>-<hash>/cache/            shared, content-addressed caches
+//	<root>/workspaces/<name>-<hash>/sessions/<pid>/   per-process logs
+//	<root>/workspaces/<name>-<hash>/data/<kind>/      records kept across sessions, e.g. env history
+//	<root>/trust.json                                  workspace trust decisions (package trust)
+//	<root>/secrets.key                                 key of the encrypted secret stores (package secretstore)
+//	<root>/http/                                       HTTP responses shared by all workspaces (package httpclient)
+package statedir
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/jongio/azd-app/cli/src/internal/security"
+)
+
+// RootEnvVar overrides the directory that holds all workspace state.
+const RootEnvVar = "AZD_APP_CACHE_DIR"
+
+const (
+	workspacesDirName = "workspaces"
+	cacheDirName      = "cache"
+	httpCacheDirName  = "http"
+	sessionsDirName   = "sessions"
+	dataDirName       = "data"
+	metadataFileName  = "workspace.json"
+)
+
+// Workspace describes the state kept for one workspace.
+type Workspace struct {
+	Path     string    `json:"path"`     // Workspace directory the state belongs to
+	Dir      string    `json:"dir"`      // State directory
+	Size     int64     `json:"size"`     // Total size of the state directory in bytes
+	LastUsed time.Time `json:"lastUsed"` // Last time a cache or session directory was opened
+	Sessions int       `json:"sessions"` // Number of sessions whose process is still running
+}
+
+// Session is the state directory of one azd app process in a workspace.
+type Session struct {
+	PID     int
+	Dir     string
+	Running bool
+}
+
+// metadata is stored in workspace.json so state directories can be traced back to their workspace.
+type metadata struct {
+	Path     string    `json:"path"`
+	LastUsed time.Time `json:"lastUsed"`
+}
+
+var (
+	sessionDirs   = make(map[string]string)
+	sessionDirsMu sync.Mutex
+)
+
+// Root returns the directory holding all workspace state: $AZD_APP_CACHE_DIR if set,
+// otherwise azd-app in the user cache directory.
+func Root() (string, error) {
+	if dir := os.Getenv(RootEnvVar); dir != "" {
+		return filepath.Abs(dir)
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate user cache directory: %w", err)
+	}
+	return filepath.Join(dir, "azd-app"), nil
+}
+
+// WorkspaceKey returns the name of the state directory for projectDir.
+// The folder name keeps it recognizable; the path hash keeps it unique.
+func WorkspaceKey(projectDir string) (string, error) {
+	absPath, err := filepath.Abs(projectDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve workspace path: %w", err)
+	}
+	if runtime.GOOS == "windows" {
+		// Paths are case-insensitive on Windows
+		absPath = strings.ToLower(absPath)
+	}
+	sum := sha256.Sum256([]byte(absPath))
+	return fmt.Sprintf("%s-%x", sanitizeName(filepath.Base(absPath)), sum[:6]), nil
+}
+
+// WorkspaceDir returns the state directory for projectDir without creating it.
+func WorkspaceDir(projectDir string) (string, error) {
+	root, err := Root()
+	if err != nil {
+		return "", err
+	}
+	key, err := WorkspaceKey(projectDir)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(root, workspacesDirName, key), nil
+}
+
+// CacheDir returns the cache directory for projectDir, creating it if needed.
+// The cache is shared by every session of the workspace, so entries must be
+// keyed by their inputs and written atomically.
+func CacheDir(projectDir string) (string, error) {
+	wsDir, err := ensureWorkspace(projectDir)
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(wsDir, cacheDirName)
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return "", fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	return dir, nil
+}
+
+// DataDir returns the directory for records of kind that projectDir keeps across sessions,
+// creating it if needed. Unlike cache entries they can't be recreated, so Clean keeps them,
+// and unlike project files they never end up in the repository.
+func DataDir(projectDir, kind string) (string, error) {
+	if _, err := ensureWorkspace(projectDir); err != nil {
+		return "", err
+	}
+	dir, err := DataPath(projectDir, kind)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return "", fmt.Errorf("failed to create %s directory: %w", kind, err)
+	}
+	return dir, nil
+}
+
+// DataPath returns the directory of DataDir without creating it, for reading records that
+// may not exist.
+func DataPath(projectDir, kind string) (string, error) {
+	wsDir, err := WorkspaceDir(projectDir)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(wsDir, dataDirName, sanitizeName(kind)), nil
+}
+
+// HTTPCacheDir returns the directory of the HTTP response cache, creating it if needed.
+// Remote responses do not depend on the workspace, so every workspace shares it.
+func HTTPCacheDir() (string, error) {
+	root, err := Root()
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(root, httpCacheDirName)
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return "", fmt.Errorf("failed to create HTTP cache directory: %w", err)
+	}
+	return dir, nil
+}
+
+// SessionDir returns the session directory of the current process for projectDir,
+// creating it on first use. Leftovers from an earlier process with the same PID are
+// removed, as are the directories of sessions whose process has exited.
+func SessionDir(projectDir string) (string, error) {
+	absPath, err := filepath.Abs(projectDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve workspace path: %w", err)
+	}
+
+	sessionDirsMu.Lock()
+	defer sessionDirsMu.Unlock()
+
+	if dir, ok := sessionDirs[absPath]; ok {
+		return dir, nil
+	}
+
+	wsDir, err := ensureWorkspace(absPath)
+	if err != nil {
+		return "", err
+	}
+	pruneSessions(wsDir)
+
+	dir := filepath.Join(wsDir, sessionsDirName, strconv.Itoa(os.Getpid()))
+	if err := os.RemoveAll(dir); err != nil {
+		return "", fmt.Errorf("failed to reset session directory: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return "", fmt.Errorf("failed to create session directory: %w", err)
+	}
+
+	sessionDirs[absPath] = dir
+	return dir, nil
+}
+
+// List returns the state of every known workspace, most recently used first.
+func List() ([]Workspace, error) {
+	root, err := Root()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(filepath.Join(root, workspacesDirName))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state directory: %w", err)
+	}
+
+	var workspaces []Workspace
+	for _, entry := range entries {
+		if entry.IsDir() {
+			workspaces = append(workspaces, describe(filepath.Join(root, workspacesDirName, entry.Name())))
+		}
+	}
+	sort.Slice(workspaces, func(i, j int) bool {
+		return workspaces[i].LastUsed.After(workspaces[j].LastUsed)
+	})
+	return workspaces, nil
+}
+
+// Get returns the state of projectDir's workspace. A workspace without state has size zero.
+func Get(projectDir string) (Workspace, error) {
+	wsDir, err := WorkspaceDir(projectDir)
+	if err != nil {
+		return Workspace{}, err
+	}
+	ws := describe(wsDir)
+	if ws.Path == "" {
+		ws.Path, _ = filepath.Abs(projectDir)
+	}
+	return ws, nil
+}
+
+// Clean removes the caches and logs of projectDir's workspace and returns the number of bytes
+// freed. Sessions whose process is still running, including the current one, are kept, as are
+// the records in DataDir.
+func Clean(projectDir string) (int64, error) {
+	wsDir, err := WorkspaceDir(projectDir)
+	if err != nil {
+		return 0, err
+	}
+	return clean(wsDir)
+}
+
+// CleanAll removes the caches and logs of every workspace and the HTTP cache, and returns the
+// number of bytes freed. Sessions whose process is still running are kept, as are records.
+func CleanAll() (int64, error) {
+	root, err := Root()
+	if err != nil {
+		return 0, err
+	}
+
+	var errs []error
+	httpDir := filepath.Join(root, httpCacheDirName)
+	freed := DirSize(httpDir)
+	if err := os.RemoveAll(httpDir); err != nil {
+		errs = append(errs, fmt.Errorf("failed to remove %s: %w", httpDir, err))
+	}
+
+	entries, err := os.ReadDir(filepath.Join(root, workspacesDirName))
+	if os.IsNotExist(err) {
+		return freed, errors.Join(errs...)
+	}
+	if err != nil {
+		return freed, fmt.Errorf("failed to read state directory: %w", err)
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		n, err := clean(filepath.Join(root, workspacesDirName, entry.Name()))
+		freed += n
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return freed, errors.Join(errs...)
+}
+
+// CacheDir returns the cache directory inside the workspace state directory.
+func (w Workspace) CacheDir() string {
+	return filepath.Join(w.Dir, cacheDirName)
+}
+
+// Clean removes the caches and logs of the workspace as Clean does, for a workspace whose
+// directory may no longer exist, and returns the number of bytes freed.
+func (w Workspace) Clean() (int64, error) {
+	return clean(w.Dir)
+}
+
+// CleanableSize returns the number of bytes Clean would free when no session is running:
+// everything, unless the workspace has records in DataDir, which are kept.
+func (w Workspace) CleanableSize() int64 {
+	if _, err := os.Stat(filepath.Join(w.Dir, dataDirName)); err != nil {
+		return w.Size
+	}
+	return DirSize(w.CacheDir()) + DirSize(filepath.Join(w.Dir, sessionsDirName))
+}
+
+// ListSessions returns the sessions recorded in the workspace state directory.
+func (w Workspace) ListSessions() []Session {
+	var sessions []Session
+	for _, pid := range sessionPIDs(w.Dir) {
+		sessions = append(sessions, Session{
+			PID:     pid,
+			Dir:     filepath.Join(w.Dir, sessionsDirName, strconv.Itoa(pid)),
+			Running: ProcessAlive(pid),
+		})
+	}
+	return sessions
+}
+
+// LastModified returns the newest modification time of dir and the files under it.
+func LastModified(dir string) time.Time {
+	var latest time.Time
+	_ = filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil // Skip unreadable entries
+		}
+		if info, err := d.Info(); err == nil && info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+		return nil
+	})
+	return latest
+}
+
+// DirSize returns the total size of the regular files under dir.
+func DirSize(dir string) int64 {
+	var size int64
+	_ = filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil // Skip unreadable entries
+		}
+		if d.Type().IsRegular() {
+			if info, err := d.Info(); err == nil {
+				size += info.Size()
+			}
+		}
+		return nil
+	})
+	return size
+}
+
+// FormatSize formats a byte count for display, e.g. "12.3 MB".
+func FormatSize(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
+
+// ensureWorkspace creates the state directory for projectDir and records its path and use.
+func ensureWorkspace(projectDir string) (string, error) {
+	absPath, err := filepath.Abs(projectDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve workspace path: %w", err)
+	}
+	wsDir, err := WorkspaceDir(absPath)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(wsDir, 0750); err != nil {
+		return "", fmt.Errorf("failed to create workspace state directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(metadata{Path: absPath, LastUsed: time.Now()}, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal workspace metadata: %w", err)
+	}
+	if err := WriteFile(filepath.Join(wsDir, metadataFileName), data); err != nil {
+		return "", fmt.Errorf("failed to write workspace metadata: %w", err)
+	}
+	return wsDir, nil
+}
+
+// describe reads the metadata, size, and live sessions of a workspace state directory.
+func describe(wsDir string) Workspace {
+	ws := Workspace{Dir: wsDir, Size: DirSize(wsDir)}
+
+	metaPath := filepath.Join(wsDir, metadataFileName)
+	if err := security.ValidatePath(metaPath); err == nil {
+		// #nosec G304 -- Path validated by security.ValidatePath
+		if data, err := os.ReadFile(metaPath); err == nil {
+			var meta metadata
+			if json.Unmarshal(data, &meta) == nil {
+				ws.Path = meta.Path
+				ws.LastUsed = meta.LastUsed
+			}
+		}
+	}
+	if ws.LastUsed.IsZero() {
+		if info, err := os.Stat(wsDir); err == nil {
+			ws.LastUsed = info.ModTime()
+		}
+	}
+
+	for _, pid := range sessionPIDs(wsDir) {
+		if ProcessAlive(pid) {
+			ws.Sessions++
+		}
+	}
+	return ws
+}
+
+// clean removes a workspace state directory, keeping the sessions of running processes and
+// the records of the data directory.
+func clean(wsDir string) (int64, error) {
+	before := DirSize(wsDir)
+
+	keep := false
+	if _, err := os.Stat(filepath.Join(wsDir, dataDirName)); err == nil {
+		keep = true
+	}
+	for _, pid := range sessionPIDs(wsDir) {
+		if ProcessAlive(pid) {
+			keep = true
+			break
+		}
+	}
+
+	var err error
+	if keep {
+		pruneSessions(wsDir)
+		err = os.RemoveAll(filepath.Join(wsDir, cacheDirName))
+	} else {
+		err = os.RemoveAll(wsDir)
+	}
+	if err != nil {
+		err = fmt.Errorf("failed to remove %s: %w", wsDir, err)
+	}
+	return before - DirSize(wsDir), err
+}
+
+// pruneSessions removes the session directories of processes that are no longer running.
+func pruneSessions(wsDir string) {
+	for _, pid := range sessionPIDs(wsDir) {
+		if pid != os.Getpid() && !ProcessAlive(pid) {
+			_ = os.RemoveAll(filepath.Join(wsDir, sessionsDirName, strconv.Itoa(pid)))
+		}
+	}
+}
+
+// sessionPIDs returns the process IDs that have a session directory in wsDir.
+func sessionPIDs(wsDir string) []int {
+	entries, err := os.ReadDir(filepath.Join(wsDir, sessionsDirName))
+	if err != nil {
+		return nil
+	}
+	var pids []int
+	for _, entry := range entries {
+		if pid, err := strconv.Atoi(entry.Name()); err == nil && entry.IsDir() {
+			pids = append(pids, pid)
+		}
+	}
+	return pids
+}
+
+// ProcessAlive reports whether a process with the given PID is running.
+func ProcessAlive(pid int) bool {
+	if pid == os.Getpid() {
+		return true
+	}
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		// FindProcess opens a handle on Windows, which fails for exited processes
+		_ = process.Release()
+		return true
+	}
+	err = process.Signal(syscall.Signal(0))
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
+// WriteFile writes data to a temporary file and renames it over path,
+// so concurrent sessions never see a partial file.
+func WriteFile(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmpName)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmpName)
+		return err
+	}
+	if err := os.Rename(tmpName, path); err != nil {
+		_ = os.Remove(tmpName)
+		return err
+	}
+	return nil
+}
+
+// sanitizeName keeps the characters of a folder name that are safe in a directory name.
+func sanitizeName(name string) string {
+	var b strings.Builder
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			b.WriteRune(r)
+		default:
+			b.WriteRune('_')
+		}
+	}
+	if s := strings.Trim(b.String(), "."); s != "" {
+		return s
+	}
+	return "workspace"
+}
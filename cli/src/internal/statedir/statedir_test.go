@@ -0,0 +1,268 @@
+package statedir
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestWorkspaceKey(t *testing.T) {
+	root := t.TempDir()
+	first := filepath.Join(root, "a", "my app")
+	second := filepath.Join(root, "b", "my app")
+
+	firstKey, err := WorkspaceKey(first)
+	if err != nil {
+		t.Fatalf("WorkspaceKey failed: %v", err)
+	}
+	secondKey, err := WorkspaceKey(second)
+	if err != nil {
+		t.Fatalf("WorkspaceKey failed: %v", err)
+	}
+
+	if !strings.HasPrefix(firstKey, "my_app-") {
+		t.Errorf("key %q should start with the sanitized folder name", firstKey)
+	}
+	if firstKey == secondKey {
+		t.Errorf("workspaces with the same folder name share key %q", firstKey)
+	}
+
+	again, _ := WorkspaceKey(filepath.Join(first, "sub", ".."))
+	if again != firstKey {
+		t.Errorf("key of equivalent path = %q, want %q", again, firstKey)
+	}
+}
+
+func TestRootOverride(t *testing.T) {
+	root := t.TempDir()
+	t.Setenv(RootEnvVar, root)
+
+	got, err := Root()
+	if err != nil {
+		t.Fatalf("Root failed: %v", err)
+	}
+	if got != root {
+		t.Errorf("Root() = %s, want %s", got, root)
+	}
+
+	wsDir, err := WorkspaceDir(t.TempDir())
+	if err != nil {
+		t.Fatalf("WorkspaceDir failed: %v", err)
+	}
+	if !strings.HasPrefix(wsDir, filepath.Join(root, workspacesDirName)) {
+		t.Errorf("WorkspaceDir() = %s, want it under %s", wsDir, root)
+	}
+	if _, err := os.Stat(wsDir); !os.IsNotExist(err) {
+		t.Error("WorkspaceDir should not create the directory")
+	}
+}
+
+func TestCacheDirPerWorkspace(t *testing.T) {
+	t.Setenv(RootEnvVar, t.TempDir())
+
+	first, err := CacheDir(t.TempDir())
+	if err != nil {
+		t.Fatalf("CacheDir failed: %v", err)
+	}
+	second, err := CacheDir(t.TempDir())
+	if err != nil {
+		t.Fatalf("CacheDir failed: %v", err)
+	}
+	if first == second {
+		t.Errorf("different workspaces share cache directory %s", first)
+	}
+	for _, dir := range []string{first, second} {
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			t.Errorf("cache directory %s was not created", dir)
+		}
+		if _, err := os.Stat(filepath.Join(filepath.Dir(dir), metadataFileName)); err != nil {
+			t.Errorf("workspace metadata was not written: %v", err)
+		}
+	}
+}
+
+func TestDataDir(t *testing.T) {
+	t.Setenv(RootEnvVar, t.TempDir())
+	project := t.TempDir()
+
+	dir, err := DataDir(project, "envhistory")
+	if err != nil {
+		t.Fatalf("DataDir failed: %v", err)
+	}
+	wsDir, err := WorkspaceDir(project)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(wsDir, dataDirName, "envhistory"); dir != want {
+		t.Errorf("DataDir() = %s, want %s", dir, want)
+	}
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		t.Errorf("data directory %s was not created", dir)
+	}
+}
+
+func TestSessionDir(t *testing.T) {
+	t.Setenv(RootEnvVar, t.TempDir())
+	project := t.TempDir()
+
+	wsDir, err := WorkspaceDir(project)
+	if err != nil {
+		t.Fatalf("WorkspaceDir failed: %v", err)
+	}
+
+	// A session left behind by a process that has exited
+	stale := filepath.Join(wsDir, sessionsDirName, strconv.Itoa(exitedPID(t)))
+	writeTestFile(t, filepath.Join(stale, "logs", "api.log"), "old")
+
+	dir, err := SessionDir(project)
+	if err != nil {
+		t.Fatalf("SessionDir failed: %v", err)
+	}
+	if filepath.Base(dir) != strconv.Itoa(os.Getpid()) {
+		t.Errorf("SessionDir() = %s, want a directory named after the current PID", dir)
+	}
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Error("session of an exited process was not pruned")
+	}
+
+	again, err := SessionDir(project)
+	if err != nil || again != dir {
+		t.Errorf("SessionDir() second call = %s, %v; want %s", again, err, dir)
+	}
+}
+
+func TestListAndClean(t *testing.T) {
+	t.Setenv(RootEnvVar, t.TempDir())
+	idle := t.TempDir()
+	active := t.TempDir()
+
+	idleCache, err := CacheDir(idle)
+	if err != nil {
+		t.Fatalf("CacheDir failed: %v", err)
+	}
+	writeTestFile(t, filepath.Join(idleCache, "reqs_cache.json"), strings.Repeat("x", 2048))
+
+	activeSession, err := SessionDir(active)
+	if err != nil {
+		t.Fatalf("SessionDir failed: %v", err)
+	}
+	writeTestFile(t, filepath.Join(activeSession, "logs", "api.log"), "running")
+	activeCache, err := CacheDir(active)
+	if err != nil {
+		t.Fatalf("CacheDir failed: %v", err)
+	}
+	writeTestFile(t, filepath.Join(activeCache, "reqs_cache.json"), "{}")
+
+	workspaces, err := List()
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(workspaces) != 2 {
+		t.Fatalf("List() returned %d workspaces, want 2", len(workspaces))
+	}
+	for _, ws := range workspaces {
+		switch ws.Path {
+		case idle:
+			if ws.Size < 2048 || ws.Sessions != 0 {
+				t.Errorf("idle workspace = %+v, want size >= 2048 and no sessions", ws)
+			}
+		case active:
+			if ws.Sessions != 1 {
+				t.Errorf("active workspace sessions = %d, want 1", ws.Sessions)
+			}
+		default:
+			t.Errorf("unexpected workspace path %s", ws.Path)
+		}
+	}
+
+	freed, err := Clean(idle)
+	if err != nil {
+		t.Fatalf("Clean failed: %v", err)
+	}
+	if freed < 2048 {
+		t.Errorf("Clean() freed %d bytes, want at least 2048", freed)
+	}
+	if ws, _ := Get(idle); ws.Size != 0 {
+		t.Errorf("idle workspace size after clean = %d, want 0", ws.Size)
+	}
+
+	// Records can't be recreated, so they survive cleaning
+	dataDir, err := DataDir(idle, "snapshots")
+	if err != nil {
+		t.Fatalf("DataDir failed: %v", err)
+	}
+	writeTestFile(t, filepath.Join(dataDir, "seed", "manifest.json"), "{}")
+	if _, err := CacheDir(idle); err != nil {
+		t.Fatalf("CacheDir failed: %v", err)
+	}
+	if _, err := Clean(idle); err != nil {
+		t.Fatalf("Clean failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dataDir, "seed", "manifest.json")); err != nil {
+		t.Errorf("Clean removed records: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(filepath.Dir(dataDir)), cacheDirName)); !os.IsNotExist(err) {
+		t.Error("Clean kept the cache of a workspace with records")
+	}
+
+	httpCache, err := HTTPCacheDir()
+	if err != nil {
+		t.Fatalf("HTTPCacheDir failed: %v", err)
+	}
+	writeTestFile(t, filepath.Join(httpCache, "response.json"), "{}")
+
+	if _, err := CleanAll(); err != nil {
+		t.Fatalf("CleanAll failed: %v", err)
+	}
+	if _, err := os.Stat(httpCache); !os.IsNotExist(err) {
+		t.Error("HTTP cache was not removed")
+	}
+	if _, err := os.Stat(filepath.Join(activeSession, "logs", "api.log")); err != nil {
+		t.Errorf("running session was removed: %v", err)
+	}
+	if _, err := os.Stat(activeCache); !os.IsNotExist(err) {
+		t.Error("cache of the active workspace was not removed")
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	tests := []struct {
+		bytes int64
+		want  string
+	}{
+		{0, "0 B"},
+		{512, "512 B"},
+		{1536, "1.5 KB"},
+		{5 * 1024 * 1024, "5.0 MB"},
+		{3 * 1024 * 1024 * 1024, "3.0 GB"},
+	}
+	for _, tt := range tests {
+		if got := FormatSize(tt.bytes); got != tt.want {
+			t.Errorf("FormatSize(%d) = %q, want %q", tt.bytes, got, tt.want)
+		}
+	}
+}
+
+// exitedPID returns a PID that is not in use by any running process.
+func exitedPID(t *testing.T) int {
+	t.Helper()
+	for pid := 999999; pid > 900000; pid-- {
+		if !ProcessAlive(pid) {
+			return pid
+		}
+	}
+	t.Skip("no unused PID found")
+	return 0
+}
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("failed to write %s: %v", path, err)
+	}
+}
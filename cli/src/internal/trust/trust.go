@@ -0,0 +1,128 @@
+// Package trust records whether the user trusts a workspace to run the commands its files
+// define, such as package install scripts, service start commands, and custom reqs checks.
+//
+// Decisions are kept in trust.json in the state root (see statedir.Root), keyed like the
+// workspace state directories, so they apply to one checkout and survive cleaning its cache.
+package trust
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/jongio/azd-app/cli/src/internal/security"
+	"github.com/jongio/azd-app/cli/src/internal/statedir"
+)
+
+// fileName is the file in the state root that holds all trust decisions.
+const fileName = "trust.json"
+
+// Decision is the user's answer for one workspace.
+type Decision struct {
+	Path      string    `json:"path"`      // Workspace directory
+	Trusted   bool      `json:"trusted"`   // Whether commands from the workspace may run
+	DecidedAt time.Time `json:"decidedAt"` // When the decision was made
+}
+
+// Get returns the decision recorded for workspace, and false if there is none.
+func Get(workspace string) (Decision, bool, error) {
+	key, err := statedir.WorkspaceKey(workspace)
+	if err != nil {
+		return Decision{}, false, err
+	}
+	decisions, err := load()
+	if err != nil {
+		return Decision{}, false, err
+	}
+	decision, ok := decisions[key]
+	return decision, ok, nil
+}
+
+// Set records whether workspace is trusted, replacing any earlier decision.
+func Set(workspace string, trusted bool) error {
+	absPath, err := filepath.Abs(workspace)
+	if err != nil {
+		return fmt.Errorf("failed to resolve workspace path: %w", err)
+	}
+	key, err := statedir.WorkspaceKey(absPath)
+	if err != nil {
+		return err
+	}
+
+	decisions, err := load()
+	if err != nil {
+		return err
+	}
+	decisions[key] = Decision{Path: absPath, Trusted: trusted, DecidedAt: time.Now()}
+	return save(decisions)
+}
+
+// List returns all recorded decisions sorted by workspace path.
+func List() ([]Decision, error) {
+	decisions, err := load()
+	if err != nil {
+		return nil, err
+	}
+	list := make([]Decision, 0, len(decisions))
+	for _, decision := range decisions {
+		list = append(list, decision)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Path < list[j].Path })
+	return list, nil
+}
+
+// path returns the location of trust.json.
+func path() (string, error) {
+	root, err := statedir.Root()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(root, fileName), nil
+}
+
+// load reads all decisions, keyed by workspace key. A missing file has no decisions.
+func load() (map[string]Decision, error) {
+	file, err := path()
+	if err != nil {
+		return nil, err
+	}
+	if err := security.ValidatePath(file); err != nil {
+		return nil, fmt.Errorf("invalid path: %w", err)
+	}
+
+	decisions := make(map[string]Decision)
+	// #nosec G304 -- Path validated by security.ValidatePath
+	data, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return decisions, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read trust decisions: %w", err)
+	}
+	if err := json.Unmarshal(data, &decisions); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", file, err)
+	}
+	return decisions, nil
+}
+
+// save writes all decisions atomically.
+func save(decisions map[string]Decision) error {
+	file, err := path()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0750); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+	data, err := json.MarshalIndent(decisions, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode trust decisions: %w", err)
+	}
+	if err := statedir.WriteFile(file, data); err != nil {
+		return fmt.Errorf("failed to write trust decisions: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package trust
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jongio/azd-app/cli/src/internal/statedir"
+)
+
+func TestSetAndGet(t *testing.T) {
+	t.Setenv(statedir.RootEnvVar, t.TempDir())
+	workspace := t.TempDir()
+
+	if _, ok, err := Get(workspace); err != nil || ok {
+		t.Fatalf("Get() = %v, %v; want no decision", ok, err)
+	}
+
+	if err := Set(workspace, false); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	decision, ok, err := Get(workspace)
+	if err != nil || !ok || decision.Trusted || decision.Path != workspace {
+		t.Fatalf("Get() = %+v, %v, %v; want untrusted %s", decision, ok, err, workspace)
+	}
+
+	if err := Set(workspace, true); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if decision, _, _ := Get(workspace); !decision.Trusted {
+		t.Error("workspace should be trusted after Set(true)")
+	}
+
+	other := t.TempDir()
+	if err := Set(other, false); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	decisions, err := List()
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(decisions) != 2 {
+		t.Fatalf("List() = %+v, want 2 decisions", decisions)
+	}
+	for _, d := range decisions {
+		if d.Trusted != (d.Path == workspace) {
+			t.Errorf("decision %+v has the wrong trust", d)
+		}
+	}
+}
+
+func TestLoadInvalidFile(t *testing.T) {
+	root := t.TempDir()
+	t.Setenv(statedir.RootEnvVar, root)
+	if err := os.WriteFile(filepath.Join(root, fileName), []byte("not json"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := Get(t.TempDir()); err == nil {
+		t.Error("expected an error for an invalid trust.json")
+	}
+}
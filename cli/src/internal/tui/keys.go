@@ -0,0 +1,82 @@
+package tui
+
+import "unicode/utf8"
+
+// key is a key the UI handles.
+type key int
+
+const (
+	keyRune key = iota
+	keyUp
+	keyDown
+	keyLeft
+	keyRight
+	keyPageUp
+	keyPageDown
+	keyHome
+	keyEnd
+	keyTab
+	keyBackTab
+	keyEnter
+	keyEscape
+	keyBackspace
+	keyCtrlC
+)
+
+// keyPress is a key read from the terminal; r is the character of keyRune.
+type keyPress struct {
+	key key
+	r   rune
+}
+
+// escapeKeys are the keys of escape sequences by what follows "\033[" or "\033O".
+var escapeKeys = map[string]key{
+	"A": keyUp, "B": keyDown, "C": keyRight, "D": keyLeft,
+	"H": keyHome, "F": keyEnd, "1~": keyHome, "7~": keyHome, "4~": keyEnd, "8~": keyEnd,
+	"5~": keyPageUp, "6~": keyPageDown, "Z": keyBackTab,
+}
+
+// parseKeys returns the keys in b, a read from a terminal in raw mode. Terminals write an
+// escape sequence at once, so an escape that ends b is the Escape key. Unknown sequences are
+// dropped.
+func parseKeys(b []byte) []keyPress {
+	var keys []keyPress
+	for len(b) > 0 {
+		switch c := b[0]; {
+		case c == '\033' && len(b) > 1 && (b[1] == '[' || b[1] == 'O'):
+			end := 2
+			for end < len(b) && (b[end] < 0x40 || b[end] > 0x7e) {
+				end++
+			}
+			if end < len(b) {
+				if k, ok := escapeKeys[string(b[2:end+1])]; ok {
+					keys = append(keys, keyPress{key: k})
+				}
+				end++
+			}
+			b = b[end:]
+		case c == '\033':
+			keys = append(keys, keyPress{key: keyEscape})
+			b = b[1:]
+		case c == 3:
+			keys = append(keys, keyPress{key: keyCtrlC})
+			b = b[1:]
+		case c == '\t':
+			keys = append(keys, keyPress{key: keyTab})
+			b = b[1:]
+		case c == '\r' || c == '\n':
+			keys = append(keys, keyPress{key: keyEnter})
+			b = b[1:]
+		case c == 8 || c == 0x7f:
+			keys = append(keys, keyPress{key: keyBackspace})
+			b = b[1:]
+		case c < ' ':
+			b = b[1:]
+		default:
+			r, size := utf8.DecodeRune(b)
+			keys = append(keys, keyPress{key: keyRune, r: r})
+			b = b[size:]
+		}
+	}
+	return keys
+}
@@ -0,0 +1,176 @@
+// Package tui shows the services of a run session in a full-screen terminal UI: a pane with
+// the recent output of each service, keys to restart and stop services, scroll back through
+// their output, and filter it, and a panel with the health of the workspace.
+package tui
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jongio/azd-app/cli/src/internal/console"
+	"github.com/jongio/azd-app/cli/src/internal/output"
+	"github.com/jongio/azd-app/cli/src/internal/service"
+)
+
+// refreshInterval is how often the screen is redrawn with new output.
+const refreshInterval = 200 * time.Millisecond
+
+// Size used when the terminal doesn't report its own, and the smallest frame drawn.
+const (
+	defaultWidth  = 80
+	defaultHeight = 24
+	minWidth      = 20
+	minHeight     = 5
+)
+
+// Controller gives the UI the services of a session and acts on them.
+type Controller interface {
+	Services() []Service                 // In the order their panes are shown
+	Logs(name string) []service.LogEntry // Recent output of the service, oldest first
+	Restart(name string) error           // Starts the service again, also when it is stopped
+	Stop(name string) error
+	Health() *Health // Health of the workspace, or nil while it is being rated
+}
+
+// Run shows the UI on the terminal of in and out until the user quits, returning nil, or ctx
+// is done, returning its error. While the UI is shown, human-readable output of the command,
+// such as messages about services that exited, is shown in its bottom row.
+func Run(ctx context.Context, in, out *os.File, ctl Controller) error {
+	restore, err := console.MakeRaw(in.Fd())
+	if err != nil {
+		return fmt.Errorf("the terminal UI needs a terminal: %w", err)
+	}
+	defer restore()
+
+	messages := &messageWriter{}
+	defer output.Redirect(messages)()
+
+	// The alternate screen keeps the UI out of the scrollback of the terminal
+	fmt.Fprint(out, "\033[?1049h\033[?25l")
+	defer fmt.Fprint(out, "\033[?25h\033[?1049l")
+
+	done := make(chan struct{})
+	defer close(done)
+	keys := make(chan []keyPress)
+	go readKeys(in, keys, done)
+
+	v := newView()
+	ticker := time.NewTicker(refreshInterval)
+	defer ticker.Stop()
+	for {
+		v.message = messages.last()
+		if v.showHealth {
+			v.health = ctl.Health()
+		}
+		draw(out, v, ctl)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case pressed, ok := <-keys:
+			if !ok {
+				return nil
+			}
+			for _, k := range pressed {
+				switch v.handle(k, ctl.Services()) {
+				case actionQuit:
+					return nil
+				case actionRestart:
+					go act(messages, "Restarting", "Restarted", v.selected, ctl.Restart)
+				case actionStop:
+					go act(messages, "Stopping", "Stopped", v.selected, ctl.Stop)
+				}
+			}
+		case <-ticker.C:
+		}
+	}
+}
+
+// draw writes a frame of the view to out, sized to the terminal.
+func draw(out *os.File, v *view, ctl Controller) {
+	width, height := output.TerminalSize(out.Fd())
+	if width == 0 || height == 0 {
+		width, height = defaultWidth, defaultHeight
+	}
+	width, height = max(width, minWidth), max(height, minHeight)
+
+	rows := v.render(width, height, ctl.Services(), ctl.Logs)
+	var frame strings.Builder
+	frame.WriteString("\033[H")
+	for i, row := range rows {
+		frame.WriteString(row)
+		frame.WriteString("\033[K")
+		if i < len(rows)-1 {
+			frame.WriteString("\n")
+		}
+	}
+	_, _ = io.WriteString(out, frame.String())
+}
+
+// readKeys sends the keys read from in to keys until in fails or done is closed, and then
+// closes keys.
+func readKeys(in io.Reader, keys chan<- []keyPress, done <-chan struct{}) {
+	defer close(keys)
+	buf := make([]byte, 256)
+	for {
+		n, err := in.Read(buf)
+		if n > 0 {
+			select {
+			case keys <- parseKeys(buf[:n]):
+			case <-done:
+				return
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// act runs fn for the service name, showing what it does and how it went.
+func act(messages *messageWriter, doing, done, name string, fn func(string) error) {
+	if name == "" {
+		return
+	}
+	messages.set(fmt.Sprintf("%s %s…", doing, name))
+	if err := fn(name); err != nil {
+		messages.set("✗ " + err.Error())
+		return
+	}
+	messages.set(fmt.Sprintf("✓ %s %s", done, name))
+}
+
+// messageWriter keeps the last line written to it, so output of the command can be shown in
+// the UI instead of drawn over it.
+type messageWriter struct {
+	mu      sync.Mutex
+	message string
+}
+
+func (w *messageWriter) Write(p []byte) (int, error) {
+	for _, line := range strings.Split(string(p), "\n") {
+		if line = strings.TrimSpace(sanitize(line)); line != "" {
+			w.set(line)
+		}
+	}
+	return len(p), nil
+}
+
+// set replaces the message.
+func (w *messageWriter) set(message string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.message = message
+}
+
+// last returns the message.
+func (w *messageWriter) last() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.message
+}
@@ -0,0 +1,354 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jongio/azd-app/cli/src/internal/output"
+	"github.com/jongio/azd-app/cli/src/internal/service"
+)
+
+// States of a service, as shown in the header of its pane.
+const (
+	StateRunning = "running"
+	StateStopped = "stopped"
+	StateExited  = "exited"
+)
+
+// minPaneHeight is the fewest rows a pane takes: its header and two lines of output. Panes
+// that don't fit are scrolled to, following the selected service.
+const minPaneHeight = 3
+
+// Service is a service shown in the UI.
+type Service struct {
+	Name  string
+	State string // StateRunning, StateStopped, or StateExited, optionally with details
+	URL   string
+}
+
+// Health is the health of the workspace, shown in a panel in place of the panes.
+type Health struct {
+	Score           int
+	Grade           string
+	Categories      []HealthCategory
+	Recommendations []Recommendation // Most important first
+}
+
+// HealthCategory is the score of a category of the health of the workspace.
+type HealthCategory struct {
+	Name    string
+	Score   int
+	Checked bool
+}
+
+// Recommendation is a problem with the workspace and how to fix it.
+type Recommendation struct {
+	Severity string // "error", "warning", or "info"
+	Message  string
+	Fix      string
+}
+
+// action is what a key asks the UI to do to the selected service, or to the UI itself.
+type action int
+
+const (
+	actionNone action = iota
+	actionQuit
+	actionRestart
+	actionStop
+)
+
+// view is the state of the UI: the selected service, whether it fills the screen, how far
+// each pane is scrolled back, and the filter. It renders frames and handles keys, without
+// touching the terminal.
+type view struct {
+	selected string
+	zoomed   bool
+	scroll   map[string]int // Lines scrolled back from the newest, by service; 0 follows new output
+	filter   string
+	typing   bool // The filter is being typed
+	message  string
+	page     int // Rows of output in a pane in the last frame, the step of PgUp and PgDn
+
+	showHealth bool    // The health panel is shown in place of the panes
+	health     *Health // Shown in the health panel; nil while it is being rated
+}
+
+// newView creates a view that selects the first service it is shown.
+func newView() *view {
+	return &view{scroll: make(map[string]int), page: 1}
+}
+
+// handle applies k to the view of services and returns what else it asks for.
+func (v *view) handle(k keyPress, services []Service) action {
+	if k.key == keyCtrlC {
+		return actionQuit
+	}
+	if v.typing {
+		switch k.key {
+		case keyEnter:
+			v.typing = false
+		case keyEscape:
+			v.typing, v.filter = false, ""
+		case keyBackspace:
+			if runes := []rune(v.filter); len(runes) > 0 {
+				v.filter = string(runes[:len(runes)-1])
+			}
+		case keyRune:
+			v.filter += string(k.r)
+		}
+		return actionNone
+	}
+
+	switch k.key {
+	case keyTab, keyRight:
+		v.move(services, 1)
+	case keyBackTab, keyLeft:
+		v.move(services, -1)
+	case keyUp:
+		v.scroll[v.selected]++
+	case keyDown:
+		v.scroll[v.selected]--
+	case keyPageUp:
+		v.scroll[v.selected] += v.page
+	case keyPageDown:
+		v.scroll[v.selected] -= v.page
+	case keyHome:
+		v.scroll[v.selected] = maxScroll
+	case keyEnd:
+		v.scroll[v.selected] = 0
+	case keyEnter:
+		v.zoomed = !v.zoomed
+	case keyEscape:
+		v.zoomed, v.filter, v.showHealth = false, "", false
+	case keyRune:
+		switch k.r {
+		case 'q':
+			return actionQuit
+		case 'r':
+			return actionRestart
+		case 's':
+			return actionStop
+		case '/':
+			v.typing = true
+		case 'h':
+			v.showHealth = !v.showHealth
+		}
+	}
+	if v.scroll[v.selected] < 0 {
+		v.scroll[v.selected] = 0
+	}
+	return actionNone
+}
+
+// maxScroll scrolls a pane back to its oldest output; render clamps it.
+const maxScroll = 1 << 30
+
+// move selects the service by steps after the selected one, wrapping around.
+func (v *view) move(services []Service, by int) {
+	if len(services) == 0 {
+		return
+	}
+	i := (v.index(services) + by + len(services)) % len(services)
+	v.selected = services[i].Name
+}
+
+// index returns the position of the selected service in services, selecting the first when
+// the selected one is gone.
+func (v *view) index(services []Service) int {
+	for i, svc := range services {
+		if svc.Name == v.selected {
+			return i
+		}
+	}
+	if len(services) > 0 {
+		v.selected = services[0].Name
+	}
+	return 0
+}
+
+// render returns the rows of a frame of width by height showing services, with the output
+// logs returns for each.
+func (v *view) render(width, height int, services []Service, logs func(name string) []service.LogEntry) []string {
+	rows := []string{v.title(width, len(services))}
+	body := height - 2
+	selected := v.index(services)
+
+	switch {
+	case v.showHealth:
+		rows = append(rows, v.healthPanel(width)...)
+	case len(services) == 0:
+		rows = append(rows, output.Dim+"No services are running"+output.Reset)
+	case v.zoomed:
+		rows = append(rows, v.pane(width, body, services[selected], true, logs)...)
+	default:
+		// Panes share the rows evenly; when they don't all fit, those around the selected show
+		visible := min(len(services), max(body/minPaneHeight, 1))
+		first := min(max(selected-visible/2, 0), len(services)-visible)
+		for i := first; i < first+visible; i++ {
+			h := body / visible
+			if i == first+visible-1 {
+				h = body - (visible-1)*(body/visible)
+			}
+			rows = append(rows, v.pane(width, h, services[i], i == selected, logs)...)
+		}
+	}
+
+	for len(rows) < height-1 {
+		rows = append(rows, "")
+	}
+	rows = append(rows[:height-1], v.footer(width))
+	return rows
+}
+
+// title returns the top row: the session and the keys.
+func (v *view) title(width, count int) string {
+	title := fmt.Sprintf("%sazd app run%s · %d service(s)", output.Bold, output.Reset, count)
+	if v.filter != "" {
+		title += fmt.Sprintf(" · filter %s%q%s", output.Yellow, v.filter, output.Reset)
+	}
+	keys := "Tab select · ↑↓ scroll · Enter zoom · r restart · s stop · / filter · h health · q quit"
+	return output.Truncate(title+"   "+output.Dim+keys+output.Reset, width)
+}
+
+// healthPanel returns the rows of the health panel: the score of the workspace and of each
+// category, and the recommendations, most important first.
+func (v *view) healthPanel(width int) []string {
+	if v.health == nil {
+		return []string{output.Dim + "Rating the health of the workspace…" + output.Reset}
+	}
+	h := v.health
+	color := output.Green
+	switch {
+	case h.Score < 70:
+		color = output.Red
+	case h.Score < 90:
+		color = output.Yellow
+	}
+	rows := []string{fmt.Sprintf("%sWorkspace health%s %s%d/100 (%s)%s", output.Bold, output.Reset, color, h.Score, h.Grade, output.Reset)}
+
+	categories := make([]string, 0, len(h.Categories))
+	for _, c := range h.Categories {
+		score := "-"
+		if c.Checked {
+			score = fmt.Sprintf("%d", c.Score)
+		}
+		categories = append(categories, fmt.Sprintf("%s %s", c.Name, score))
+	}
+	rows = append(rows, output.Truncate(output.Dim+strings.Join(categories, " · ")+output.Reset, width), "")
+
+	if len(h.Recommendations) == 0 {
+		return append(rows, output.Green+"✓ Nothing to recommend"+output.Reset)
+	}
+	for i, r := range h.Recommendations {
+		marker := output.Gray + "•" + output.Reset
+		switch r.Severity {
+		case "error":
+			marker = output.Red + "✗" + output.Reset
+		case "warning":
+			marker = output.Yellow + "⚠" + output.Reset
+		}
+		line := fmt.Sprintf("%s %d. %s", marker, i+1, r.Message)
+		if r.Fix != "" {
+			line += " " + output.Gray + "→ " + r.Fix + output.Reset
+		}
+		rows = append(rows, output.Truncate(line, width))
+	}
+	return rows
+}
+
+// footer returns the bottom row: the filter being typed, or the latest message.
+func (v *view) footer(width int) string {
+	if v.typing {
+		return output.Truncate("/"+v.filter+"█", width)
+	}
+	return output.Truncate(output.Dim+v.message+output.Reset, width)
+}
+
+// pane returns height rows showing svc: a header, and its newest output that matches the
+// filter, scrolled back as far as the user asked.
+func (v *view) pane(width, height int, svc Service, selected bool, logs func(name string) []service.LogEntry) []string {
+	var lines []string
+	for _, entry := range logs(svc.Name) {
+		message := sanitize(entry.Message)
+		if v.filter != "" && !strings.Contains(strings.ToLower(message), strings.ToLower(v.filter)) {
+			continue
+		}
+		color := ""
+		switch entry.Level {
+		case service.LogLevelError:
+			color = output.Red
+		case service.LogLevelWarn:
+			color = output.Yellow
+		}
+		line := fmt.Sprintf("%s%s%s %s%s%s", output.Gray, entry.Timestamp.Format("15:04:05"), output.Reset, color, message, output.Reset)
+		lines = append(lines, output.Truncate(line, width))
+	}
+
+	size := max(height-1, 0)
+	if selected {
+		v.page = max(size, 1)
+	}
+	scroll := min(v.scroll[svc.Name], max(len(lines)-size, 0))
+	v.scroll[svc.Name] = scroll
+	end := len(lines) - scroll
+	start := max(end-size, 0)
+
+	rows := []string{v.header(width, svc, selected, scroll)}
+	rows = append(rows, lines[start:end]...)
+	for len(rows) < height {
+		rows = append(rows, "")
+	}
+	return rows[:height]
+}
+
+// header returns the header row of the pane of svc.
+func (v *view) header(width int, svc Service, selected bool, scroll int) string {
+	marker, name := " ", output.Cyan+svc.Name+output.Reset
+	if selected {
+		marker, name = "▶", output.Bold+output.Cyan+svc.Name+output.Reset
+	}
+	color := output.Gray
+	switch {
+	case strings.HasPrefix(svc.State, StateRunning):
+		color = output.Green
+	case strings.HasPrefix(svc.State, StateExited):
+		color = output.Red
+	}
+	header := fmt.Sprintf("%s %s %s%s%s", marker, name, color, svc.State, output.Reset)
+	if svc.URL != "" {
+		header += " " + output.BrightBlue + svc.URL + output.Reset
+	}
+	if scroll > 0 {
+		header += fmt.Sprintf(" %s↑ %d newer line(s) below%s", output.Yellow, scroll, output.Reset)
+	}
+	if rule := width - output.Width(header) - 1; rule > 0 {
+		header += " " + output.Gray + strings.Repeat("─", rule) + output.Reset
+	}
+	return output.Truncate(header, width)
+}
+
+// sanitize makes message fit on one row: tabs become spaces, and escape codes and other
+// control characters are removed, so service colors can't bleed into the frame.
+func sanitize(message string) string {
+	var b strings.Builder
+	for i := 0; i < len(message); i++ {
+		c := message[i]
+		switch {
+		case c == '\t':
+			b.WriteString("    ")
+		case c == '\033':
+			// Skip a CSI sequence up to its final byte
+			if i+1 < len(message) && message[i+1] == '[' {
+				i += 2
+				for i < len(message) && (message[i] < 0x40 || message[i] > 0x7e) {
+					i++
+				}
+			}
+		case c < ' ' || c == 0x7f:
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,213 @@
+package tui
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jongio/azd-app/cli/src/internal/output"
+	"github.com/jongio/azd-app/cli/src/internal/service"
+)
+
+func TestParseKeys(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []keyPress
+	}{
+		{name: "characters", input: "r/é", want: []keyPress{{key: keyRune, r: 'r'}, {key: keyRune, r: '/'}, {key: keyRune, r: 'é'}}},
+		{name: "arrows", input: "\033[A\033OB\033[C\033[D", want: []keyPress{{key: keyUp}, {key: keyDown}, {key: keyRight}, {key: keyLeft}}},
+		{name: "paging", input: "\033[5~\033[6~\033[H\033[4~", want: []keyPress{{key: keyPageUp}, {key: keyPageDown}, {key: keyHome}, {key: keyEnd}}},
+		{name: "tabs", input: "\t\033[Z", want: []keyPress{{key: keyTab}, {key: keyBackTab}}},
+		{name: "control keys", input: "\r\x7f\x03\x01", want: []keyPress{{key: keyEnter}, {key: keyBackspace}, {key: keyCtrlC}}},
+		{name: "escape alone", input: "\033", want: []keyPress{{key: keyEscape}}},
+		{name: "unknown sequence", input: "\033[15~q", want: []keyPress{{key: keyRune, r: 'q'}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseKeys([]byte(tt.input)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseKeys(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestViewHandle(t *testing.T) {
+	services := []Service{{Name: "api"}, {Name: "web"}, {Name: "worker"}}
+	v := newView()
+	v.index(services)
+	press := func(keys ...keyPress) action {
+		var last action
+		for _, k := range keys {
+			last = v.handle(k, services)
+		}
+		return last
+	}
+
+	press(keyPress{key: keyTab}, keyPress{key: keyTab})
+	if v.selected != "worker" {
+		t.Errorf("selected = %q after two tabs, want worker", v.selected)
+	}
+	press(keyPress{key: keyTab})
+	if v.selected != "api" {
+		t.Errorf("selected = %q after wrapping, want api", v.selected)
+	}
+	press(keyPress{key: keyBackTab})
+	if v.selected != "worker" {
+		t.Errorf("selected = %q after shift+tab, want worker", v.selected)
+	}
+
+	if got := press(keyPress{key: keyRune, r: 'r'}); got != actionRestart {
+		t.Errorf("r = %v, want restart", got)
+	}
+	if got := press(keyPress{key: keyRune, r: 's'}); got != actionStop {
+		t.Errorf("s = %v, want stop", got)
+	}
+
+	// Typed keys go to the filter, not to commands
+	press(keyPress{key: keyRune, r: '/'}, keyPress{key: keyRune, r: 'q'}, keyPress{key: keyRune, r: 'x'}, keyPress{key: keyBackspace}, keyPress{key: keyRune, r: 'r'}, keyPress{key: keyEnter})
+	if v.filter != "qr" || v.typing {
+		t.Errorf("filter = %q, typing = %v, want qr and done typing", v.filter, v.typing)
+	}
+	press(keyPress{key: keyEscape})
+	if v.filter != "" {
+		t.Errorf("filter = %q after escape, want none", v.filter)
+	}
+
+	press(keyPress{key: keyDown})
+	if v.scroll["worker"] != 0 {
+		t.Errorf("scroll = %d, want it to stay at the newest line", v.scroll["worker"])
+	}
+	if got := press(keyPress{key: keyRune, r: 'q'}); got != actionQuit {
+		t.Errorf("q = %v, want quit", got)
+	}
+	if got := press(keyPress{key: keyCtrlC}); got != actionQuit {
+		t.Errorf("Ctrl+C = %v, want quit", got)
+	}
+}
+
+func TestViewRender(t *testing.T) {
+	output.SetColor(false)
+	defer output.SetColor(true)
+
+	services := []Service{
+		{Name: "api", State: StateRunning, URL: "http://localhost:3000"},
+		{Name: "web", State: "exited (code 1)"},
+	}
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	logs := func(name string) []service.LogEntry {
+		var entries []service.LogEntry
+		for i := range 10 {
+			entries = append(entries, service.LogEntry{Service: name, Message: fmt.Sprintf("%s line %d\t\033[31mred\033[0m", name, i), Timestamp: start})
+		}
+		return entries
+	}
+
+	v := newView()
+	rows := v.render(60, 12, services, logs)
+	if len(rows) != 12 {
+		t.Fatalf("render() = %d rows, want 12", len(rows))
+	}
+	for i, row := range rows {
+		if w := output.Width(row); w > 60 {
+			t.Errorf("row %d is %d columns wide: %q", i, w, row)
+		}
+	}
+	// Two panes of 5 rows: a header and the 4 newest lines
+	if !strings.HasPrefix(rows[1], "▶ api running http://localhost:3000 ─") {
+		t.Errorf("api header = %q", rows[1])
+	}
+	if rows[2] != "10:00:00 api line 6    red" || rows[5] != "10:00:00 api line 9    red" {
+		t.Errorf("api output = %q", rows[2:6])
+	}
+	if !strings.HasPrefix(rows[6], "  web exited (code 1)") {
+		t.Errorf("web header = %q", rows[6])
+	}
+
+	// Scrolled back, and filtered
+	v.handle(keyPress{key: keyPageUp}, services)
+	rows = v.render(60, 12, services, logs)
+	if rows[2] != "10:00:00 api line 2    red" || !strings.Contains(rows[1], "↑ 4 newer line(s) below") {
+		t.Errorf("scrolled rows = %q", rows[1:6])
+	}
+	v.handle(keyPress{key: keyHome}, services)
+	rows = v.render(60, 12, services, logs)
+	if rows[2] != "10:00:00 api line 0    red" || v.scroll["api"] != 6 {
+		t.Errorf("rows at the oldest line = %q, scroll = %d", rows[1:6], v.scroll["api"])
+	}
+	v.filter = "line 3"
+	rows = v.render(60, 12, services, logs)
+	if rows[2] != "10:00:00 api line 3    red" || rows[3] != "" {
+		t.Errorf("filtered rows = %q", rows[1:6])
+	}
+
+	// Zoomed, the selected service fills the screen
+	v.filter = ""
+	v.handle(keyPress{key: keyTab}, services)
+	v.handle(keyPress{key: keyEnter}, services)
+	rows = v.render(60, 12, services, logs)
+	if !strings.HasPrefix(rows[1], "▶ web") || rows[2] != "10:00:00 web line 1    red" || rows[10] != "10:00:00 web line 9    red" {
+		t.Errorf("zoomed rows = %q", rows)
+	}
+}
+
+func TestViewRenderManyServices(t *testing.T) {
+	output.SetColor(false)
+	defer output.SetColor(true)
+
+	var services []Service
+	for i := range 8 {
+		services = append(services, Service{Name: fmt.Sprintf("svc%d", i), State: StateRunning})
+	}
+	v := newView()
+	v.selected = "svc6"
+	rows := v.render(40, 11, services, func(string) []service.LogEntry { return nil })
+
+	// 9 rows fit three panes; the selected one is among them
+	var headers []string
+	for _, row := range rows {
+		if strings.HasPrefix(row, "▶") || strings.HasPrefix(row, "  svc") {
+			headers = append(headers, strings.Fields(strings.TrimPrefix(row, "▶"))[0])
+		}
+	}
+	if !reflect.DeepEqual(headers, []string{"svc5", "svc6", "svc7"}) {
+		t.Errorf("panes = %v, want svc5 to svc7", headers)
+	}
+}
+
+func TestViewRenderHealth(t *testing.T) {
+	output.SetColor(false)
+	defer output.SetColor(true)
+
+	services := []Service{{Name: "api", State: StateRunning}}
+	v := newView()
+	v.index(services)
+	v.handle(keyPress{key: keyRune, r: 'h'}, services)
+	noLogs := func(string) []service.LogEntry { return nil }
+
+	if rows := strings.Join(v.render(80, 12, services, noLogs), "\n"); !strings.Contains(rows, "Rating the health") {
+		t.Errorf("health panel before rating:\n%s", rows)
+	}
+
+	v.health = &Health{
+		Score:      72,
+		Grade:      "C",
+		Categories: []HealthCategory{{Name: "validation", Score: 100, Checked: true}, {Name: "audit"}},
+		Recommendations: []Recommendation{
+			{Severity: "error", Message: "1 service(s) point at projects that don't exist", Fix: "azd app sync --write"},
+		},
+	}
+	rows := strings.Join(v.render(80, 12, services, noLogs), "\n")
+	for _, want := range []string{"Workspace health 72/100 (C)", "validation 100 · audit -", "1. 1 service(s) point at projects that don't exist → azd app sync --write"} {
+		if !strings.Contains(rows, want) {
+			t.Errorf("health panel is missing %q:\n%s", want, rows)
+		}
+	}
+
+	v.handle(keyPress{key: keyEscape}, services)
+	if v.showHealth {
+		t.Error("Escape didn't close the health panel")
+	}
+}
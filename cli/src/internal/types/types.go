@@ -1,21 +1,186 @@
 package types
 
+import (
+	"net/url"
+	"strconv"
+)
+
 // PythonProject represents a detected Python project.
 type PythonProject struct {
 	Dir            string
-	PackageManager string // "uv", "poetry", or "pip"
-	Entrypoint     string // Optional: entry point file specified in azure.yaml
+	PackageManager string       // "uv", "poetry", "pipenv", or "pip"
+	Entrypoint     string       // Optional: entry point file specified in azure.yaml
+	Name           string       // Optional: project name from pyproject.toml or setup.py
+	PythonVersion  string       // Optional: Python version constraint, e.g. ">=3.11"
+	Framework      string       // e.g. "FastAPI", "Flask", "Django", "Streamlit", or "Python" when unknown
+	EntryFile      string       // Optional: detected entry file, e.g. "main.py" or "manage.py"
+	AppTarget      string       // Optional: detected ASGI/WSGI target, e.g. "main:app" or "mysite.asgi:application"
+	IsTestProject  bool         // Its Python files are only tests that pytest runs
+	VersionPins    []VersionPin // Versions of Python its files pin
+	Dockerfile     *Dockerfile  // Optional: Dockerfile or Containerfile in Dir
 }
 
 // NodeProject represents a detected Node.js project.
 type NodeProject struct {
-	Dir            string
-	PackageManager string // "npm", "pnpm", or "yarn"
+	Dir             string
+	PackageManager  string       // "npm", "pnpm", or "yarn"
+	Framework       string       // e.g. "Next.js", "Vite", "NestJS", or "Node.js" when unknown
+	IsWorkspaceRoot bool         // Declares pnpm, yarn, or npm workspaces
+	WorkspaceRoot   string       // Optional: root directory of the workspace this project is a member of
+	IsTestProject   bool         // Its scripts only run tests, lint, or format
+	VersionPins     []VersionPin // Versions of node and the package managers its files pin
+	Dockerfile      *Dockerfile  // Optional: Dockerfile or Containerfile in Dir
 }
 
 // DotnetProject represents a detected .NET project.
+// Metadata fields are only set for project files, not solutions.
 type DotnetProject struct {
-	Path string // Path to .csproj or .sln file
+	Path              string          // Path to .csproj or .sln file
+	TargetFrameworks  []string        // e.g. ["net8.0"], or several for multi-targeted projects
+	Sdk               string          // Project SDK, e.g. "Microsoft.NET.Sdk.Web" or "Microsoft.NET.Sdk.Worker"
+	OutputType        string          // "Exe", "WinExe", or "Library"
+	AssemblyName      string          // Defaults to the project file name
+	IsAppHost         bool            // Uses the Aspire AppHost SDK
+	IsTestProject     bool            // References Microsoft.NET.Test.Sdk or a test framework, sets IsTestProject, or is named like Api.Tests
+	IsServiceDefaults bool            // Sets IsAspireSharedProject or is named like Shop.ServiceDefaults: the shared service defaults of an Aspire solution
+	Packages          []string        // Names of referenced NuGet packages
+	ProjectReferences []string        // Paths of referenced project files; for an AppHost, only those that become resources
+	UserSecretsID     string          // Optional: UserSecretsId of the secrets 'dotnet user-secrets' keeps for the project
+	LaunchProfiles    []LaunchProfile // Profiles of Properties/launchSettings.json, in file order
+	VersionPins       []VersionPin    // Versions of the .NET SDK its files pin
+	Dockerfile        *Dockerfile     // Optional: Dockerfile or Containerfile next to the project file
+}
+
+// IsWeb reports whether the project uses the ASP.NET Core Web SDK.
+func (p DotnetProject) IsWeb() bool {
+	return p.Sdk == "Microsoft.NET.Sdk.Web"
+}
+
+// IsWorker reports whether the project uses the Worker Service SDK.
+func (p DotnetProject) IsWorker() bool {
+	return p.Sdk == "Microsoft.NET.Sdk.Worker"
+}
+
+// IsRunnable reports whether the project produces an application that can be started with
+// dotnet run, as opposed to a class library or test project.
+func (p DotnetProject) IsRunnable() bool {
+	if p.IsTestProject {
+		return false
+	}
+	return p.IsAppHost || p.OutputType == "Exe" || p.OutputType == "WinExe"
+}
+
+// VersionPin is a version of a tool that a file of a project pins, such as the engines of
+// package.json, .nvmrc, global.json, .python-version, or .tool-versions.
+type VersionPin struct {
+	Tool        string `json:"tool"`                  // e.g. "node", "pnpm", "python", or "dotnet"
+	Constraint  string `json:"constraint"`            // As the file writes it, e.g. ">=20", "3.12", or "8.0.100"
+	RollForward string `json:"rollForward,omitempty"` // Roll-forward policy of the .NET SDK, from global.json
+	Source      string `json:"source"`                // Path of the file that pins it
+}
+
+// LaunchProfile is a profile of the Properties/launchSettings.json of a .NET project, which
+// dotnet run applies when it starts the project.
+type LaunchProfile struct {
+	Name            string            `json:"name"`
+	CommandName     string            `json:"commandName"`               // "Project" for profiles dotnet run can use
+	ApplicationURLs []string          `json:"applicationUrls,omitempty"` // URLs the app listens on, from applicationUrl
+	Environment     map[string]string `json:"environment,omitempty"`     // From environmentVariables
+}
+
+// Port returns the port of the first http URL of the profile, or else of its first URL with a
+// port, or 0.
+func (p LaunchProfile) Port() int {
+	port := 0
+	for _, raw := range p.ApplicationURLs {
+		u, err := url.Parse(raw)
+		if err != nil {
+			continue
+		}
+		n, err := strconv.Atoi(u.Port())
+		if err != nil {
+			continue
+		}
+		if u.Scheme == "http" {
+			return n
+		}
+		if port == 0 {
+			port = n
+		}
+	}
+	return port
+}
+
+// FunctionProject represents a detected Azure Functions app.
+type FunctionProject struct {
+	Dir         string
+	Runtime     string // Worker runtime, e.g. "dotnet-isolated", "dotnet", "node", or "python"
+	IsIsolated  bool   // .NET app using the isolated worker model
+	ProjectFile string // Optional: .NET project file of the app
+}
+
+// StaticWebApp represents a frontend that can be hosted on Azure Static Web Apps.
+type StaticWebApp struct {
+	Dir            string
+	Source         string // File the app was recognized by, e.g. "staticwebapp.config.json" or "package.json"
+	Framework      string // Optional: frontend framework, e.g. "Vite" or "Angular"
+	AppLocation    string // App source folder, relative to Dir
+	OutputLocation string // Build output folder, relative to the app location
+	APILocation    string // Optional: Azure Functions API folder, relative to Dir
+	BuildCommand   string // Optional: command that builds the app, e.g. "npm run build"
+	DevServerURL   string // Optional: dev server the SWA CLI proxies to
+	Host           string // azure.yaml host the app is a candidate for: "staticwebapp"
+}
+
+// Dockerfile represents a parsed Dockerfile or Containerfile.
+type Dockerfile struct {
+	Path         string   // Path to the Dockerfile
+	BaseImage    string   // Image the final stage is built from, e.g. "node:20-alpine"
+	Stages       []string // Optional: names of the build stages, in order
+	ExposedPorts []int    // Ports declared by EXPOSE for the final stage
+	BuildArgs    []string // Build arguments declared by ARG, as NAME or NAME=default
+}
+
+// ComposeProject represents a parsed Docker Compose file.
+type ComposeProject struct {
+	Path     string           // Path to the compose file
+	Services []ComposeService // Services sorted by name
+}
+
+// ComposeService represents a service of a Docker Compose file.
+type ComposeService struct {
+	Name         string
+	Image        string            // Optional: image to run, or to tag the build with
+	BuildContext string            // Optional: build context, relative to the compose file
+	Dockerfile   string            // Optional: Dockerfile, relative to the build context
+	BuildArgs    []string          // Build arguments as NAME or NAME=value, sorted
+	Ports        []ComposePort     // Published and exposed ports
+	Environment  map[string]string // Environment variables; unset values are empty
+	DependsOn    []string          // Services this service starts after, sorted
+}
+
+// ComposePort is a port of a compose service.
+type ComposePort struct {
+	Host      int // Published port on the host, or 0 when the port is not published
+	Container int // Port inside the container
+}
+
+// InfraProject represents the infrastructure as code of a workspace.
+type InfraProject struct {
+	Provider       string           // "bicep" or "terraform"
+	Dir            string           // Infrastructure directory, e.g. infra/
+	Module         string           // Bicep module file, or the directory of the Terraform root module
+	ParameterFiles []string         // Optional: *.bicepparam, <module>.parameters.json, or *.tfvars files
+	Parameters     []InfraParameter // Declared parameters or variables, in declaration order
+	Outputs        []string         // Declared output names, in declaration order
+}
+
+// InfraParameter represents a Bicep parameter or Terraform variable.
+type InfraParameter struct {
+	Name     string
+	Type     string // Declared type, e.g. "string" or "object"; empty if not declared
+	Required bool   // True when the parameter has no default value
+	Secure   bool   // True for @secure() Bicep parameters and sensitive Terraform variables
 }
 
 // AspireProject represents a detected Aspire project.
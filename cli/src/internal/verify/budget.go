@@ -0,0 +1,166 @@
+package verify
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jongio/azd-app/cli/src/internal/azureyaml"
+	"github.com/jongio/azd-app/cli/src/internal/container"
+	"github.com/jongio/azd-app/cli/src/internal/detector"
+	"github.com/jongio/azd-app/cli/src/internal/gc"
+	"github.com/jongio/azd-app/cli/src/internal/registry"
+	"github.com/jongio/azd-app/cli/src/internal/statedir"
+)
+
+// Budget kinds.
+const (
+	BudgetImageSize = "imageSize"
+	BudgetStartup   = "startup"
+)
+
+// Budget is the resources a service may take, from the budget settings of azure.yaml.
+type Budget struct {
+	Service    string
+	Dockerfile string        // Dockerfile the image is built from; empty if the service has none
+	Context    string        // Build context of the image
+	ImageSize  int64         // Largest image size in bytes, or 0 for no limit
+	Startup    time.Duration // Longest startup time, or 0 for no limit
+}
+
+// BudgetResult is a budget of a service compared with what verify measured.
+type BudgetResult struct {
+	Service  string `json:"service"`
+	Kind     string `json:"kind"` // BudgetImageSize or BudgetStartup
+	Limit    string `json:"limit"`
+	Actual   string `json:"actual,omitempty"`
+	Exceeded bool   `json:"exceeded"`
+	Error    string `json:"error,omitempty"` // Why the value couldn't be measured
+}
+
+// Failed reports whether the budget was exceeded or couldn't be measured.
+func (r BudgetResult) Failed() bool {
+	return r.Exceeded || r.Error != ""
+}
+
+// String describes the result, e.g. "api startup 21.3s exceeds 15s".
+func (r BudgetResult) String() string {
+	switch {
+	case r.Error != "":
+		return fmt.Sprintf("%s %s: %s", r.Service, r.Kind, r.Error)
+	case r.Exceeded:
+		return fmt.Sprintf("%s %s %s exceeds %s", r.Service, r.Kind, r.Actual, r.Limit)
+	default:
+		return fmt.Sprintf("%s %s %s within %s", r.Service, r.Kind, r.Actual, r.Limit)
+	}
+}
+
+// LoadBudgets returns the budgets declared by the services of the azure.yaml in workspace,
+// sorted by service name. Services without a budget are left out.
+func LoadBudgets(workspace string) ([]Budget, error) {
+	doc, err := azureyaml.Load(filepath.Join(workspace, azureyaml.FileName))
+	if err != nil {
+		return nil, err
+	}
+
+	var budgets []Budget
+	for name, svc := range doc.Project.Services {
+		if svc.Budget == nil || (svc.Budget.ImageSize == "" && svc.Budget.Startup == "") {
+			continue
+		}
+		budget := Budget{Service: name}
+		if svc.Budget.ImageSize != "" {
+			if budget.ImageSize, err = gc.ParseSize(svc.Budget.ImageSize); err != nil {
+				return nil, fmt.Errorf("service %s: invalid budget.imageSize: %w", name, err)
+			}
+		}
+		if svc.Budget.Startup != "" {
+			if budget.Startup, err = time.ParseDuration(svc.Budget.Startup); err != nil || budget.Startup <= 0 {
+				return nil, fmt.Errorf("service %s: invalid budget.startup %q", name, svc.Budget.Startup)
+			}
+		}
+		budget.Dockerfile, budget.Context = serviceDockerfile(workspace, svc)
+		budgets = append(budgets, budget)
+	}
+	sort.Slice(budgets, func(i, j int) bool { return budgets[i].Service < budgets[j].Service })
+	return budgets, nil
+}
+
+// CheckStartup compares the startup budget with how long the service took from starting to
+// running, as recorded in its registry entry.
+func CheckStartup(budget Budget, entry *registry.ServiceRegistryEntry) BudgetResult {
+	result := BudgetResult{Service: budget.Service, Kind: BudgetStartup, Limit: budget.Startup.String()}
+	if entry == nil || entry.StartTime.IsZero() || entry.ReadyTime.IsZero() {
+		result.Error = "startup time not recorded"
+		return result
+	}
+	startup := entry.ReadyTime.Sub(entry.StartTime)
+	result.Actual = startup.Round(100 * time.Millisecond).String()
+	result.Exceeded = startup > budget.Startup
+	return result
+}
+
+// CheckImageSize builds the image of the service with the container engine, compares its size
+// with the budget, and removes it again.
+func CheckImageSize(ctx context.Context, engine container.Engine, budget Budget) BudgetResult {
+	result := BudgetResult{Service: budget.Service, Kind: BudgetImageSize, Limit: statedir.FormatSize(budget.ImageSize)}
+	if budget.Dockerfile == "" {
+		result.Error = "no Dockerfile to build"
+		return result
+	}
+	size, err := ImageSize(ctx, engine, budget.Dockerfile, budget.Context)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Actual = statedir.FormatSize(size)
+	result.Exceeded = size > budget.ImageSize
+	return result
+}
+
+// ImageSize builds dockerfile in contextDir and returns the size of the image in bytes.
+// The image is removed afterwards.
+func ImageSize(ctx context.Context, engine container.Engine, dockerfile, contextDir string) (int64, error) {
+	out, err := engine.Command(ctx, "build", "--quiet", "--file", dockerfile, contextDir).Output()
+	if err != nil {
+		return 0, fmt.Errorf("image build failed: %w", err)
+	}
+	lines := strings.Fields(string(out))
+	if len(lines) == 0 {
+		return 0, fmt.Errorf("image build printed no image ID")
+	}
+	id := lines[len(lines)-1]
+	defer func() { _ = engine.Command(context.Background(), "rmi", "--force", id).Run() }()
+
+	out, err = engine.Command(ctx, "image", "inspect", "--format", "{{.Size}}", id).Output()
+	if err != nil {
+		return 0, fmt.Errorf("failed to inspect image %s: %w", id, err)
+	}
+	size, err := strconv.ParseInt(strings.TrimSpace(string(out)), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("unexpected size of image %s: %q", id, strings.TrimSpace(string(out)))
+	}
+	return size, nil
+}
+
+// serviceDockerfile returns the Dockerfile of a service and its build context. docker.path and
+// docker.context are relative to the project of the service, as in azd; without docker.path a
+// Dockerfile or Containerfile in the project is used.
+func serviceDockerfile(workspace string, svc azureyaml.Service) (string, string) {
+	projectDir := filepath.Join(workspace, svc.Project)
+	contextDir := projectDir
+	if svc.Docker != nil && svc.Docker.Context != "" {
+		contextDir = filepath.Join(projectDir, svc.Docker.Context)
+	}
+	if svc.Docker != nil && svc.Docker.Path != "" {
+		return filepath.Join(projectDir, svc.Docker.Path), contextDir
+	}
+	if dockerfile := detector.DetectDockerfile(projectDir); dockerfile != nil {
+		return dockerfile.Path, contextDir
+	}
+	return "", contextDir
+}
@@ -0,0 +1,95 @@
+package verify
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jongio/azd-app/cli/src/internal/registry"
+)
+
+func TestLoadBudgets(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "azure.yaml"), `name: app
+services:
+  web:
+    host: containerapp
+    project: ./web
+    budget:
+      imageSize: 300MB
+      startup: 15s
+  api:
+    host: containerapp
+    project: ./api
+    docker:
+      path: ./docker/api.Dockerfile
+    budget:
+      startup: 5s
+  worker:
+    host: containerapp
+    project: ./worker
+`)
+	writeFile(t, filepath.Join(dir, "web", "Dockerfile"), "FROM node:20\n")
+
+	budgets, err := LoadBudgets(dir)
+	if err != nil {
+		t.Fatalf("LoadBudgets failed: %v", err)
+	}
+	if len(budgets) != 2 {
+		t.Fatalf("budgets = %+v, want api and web", budgets)
+	}
+
+	api, web := budgets[0], budgets[1]
+	if api.Service != "api" || api.Startup != 5*time.Second || api.ImageSize != 0 {
+		t.Errorf("api budget = %+v", api)
+	}
+	if api.Dockerfile != filepath.Join(dir, "api", "docker", "api.Dockerfile") {
+		t.Errorf("api Dockerfile = %s", api.Dockerfile)
+	}
+	if web.Service != "web" || web.ImageSize != 300<<20 || web.Startup != 15*time.Second {
+		t.Errorf("web budget = %+v", web)
+	}
+	if web.Dockerfile != filepath.Join(dir, "web", "Dockerfile") || web.Context != filepath.Join(dir, "web") {
+		t.Errorf("web Dockerfile = %s, context = %s", web.Dockerfile, web.Context)
+	}
+}
+
+func TestLoadBudgetsInvalid(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "azure.yaml"), `name: app
+services:
+  web:
+    host: containerapp
+    project: ./web
+    budget:
+      startup: soon
+`)
+	if _, err := LoadBudgets(dir); err == nil {
+		t.Error("expected error for invalid startup budget")
+	}
+}
+
+func TestCheckStartup(t *testing.T) {
+	start := time.Now()
+	budget := Budget{Service: "api", Startup: 10 * time.Second}
+
+	tests := []struct {
+		name     string
+		entry    *registry.ServiceRegistryEntry
+		exceeded bool
+		failed   bool
+	}{
+		{"within", &registry.ServiceRegistryEntry{StartTime: start, ReadyTime: start.Add(4 * time.Second)}, false, false},
+		{"exceeded", &registry.ServiceRegistryEntry{StartTime: start, ReadyTime: start.Add(12 * time.Second)}, true, true},
+		{"not ready", &registry.ServiceRegistryEntry{StartTime: start}, false, true},
+		{"not registered", nil, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := CheckStartup(budget, tt.entry)
+			if result.Exceeded != tt.exceeded || result.Failed() != tt.failed {
+				t.Errorf("CheckStartup() = %+v, want exceeded %v, failed %v", result, tt.exceeded, tt.failed)
+			}
+		})
+	}
+}
@@ -0,0 +1,319 @@
+package verify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jongio/azd-app/cli/src/internal/detector"
+	"github.com/jongio/azd-app/cli/src/internal/httpclient"
+	"github.com/jongio/azd-app/cli/src/internal/registry"
+	"github.com/jongio/azd-app/cli/src/internal/security"
+)
+
+// DefaultStartTimeout is how long to wait for services to start and respond.
+const DefaultStartTimeout = 5 * time.Minute
+
+// Step statuses.
+const (
+	StatusPassed  = "passed"
+	StatusFailed  = "failed"
+	StatusSkipped = "skipped"
+)
+
+// copySkipDirs are directories left out when copying a workspace that is not a git repository.
+// They hold installed dependencies, build output, or local state a new user would not have.
+var copySkipDirs = map[string]bool{
+	"node_modules": true,
+	".git":         true,
+	".azure":       true,
+	"bin":          true,
+	"obj":          true,
+	"venv":         true,
+	".venv":        true,
+	"__pycache__":  true,
+	"dist":         true,
+	".next":        true,
+}
+
+// Step is the outcome of one verification step.
+type Step struct {
+	Name     string        `json:"name"`
+	Status   string        `json:"status"`
+	Duration time.Duration `json:"duration"`
+	Detail   string        `json:"detail,omitempty"`
+	Error    string        `json:"error,omitempty"`
+	Log      string        `json:"log,omitempty"`
+}
+
+// Report is the result of a verification run.
+type Report struct {
+	Source    string         `json:"source"`
+	Workspace string         `json:"workspace"`
+	Clean     bool           `json:"clean"`
+	Steps     []Step         `json:"steps"`
+	Budgets   []BudgetResult `json:"budgets,omitempty"`
+}
+
+// Passed reports whether no step failed.
+func (r *Report) Passed() bool {
+	for _, step := range r.Steps {
+		if step.Status == StatusFailed {
+			return false
+		}
+	}
+	return true
+}
+
+// Command is an external command run in a directory.
+type Command struct {
+	Dir  string
+	Name string
+	Args []string
+	Env  []string // Variables added to the environment of the command, as NAME=value
+}
+
+// String returns the command line for display.
+func (c Command) String() string {
+	return strings.TrimSpace(c.Name + " " + strings.Join(c.Args, " "))
+}
+
+// CloneWorkspace copies the workspace at srcDir into destDir the way a new user would get it.
+// Inside a git repository the committed state is cloned; otherwise the tree is copied
+// without installed dependencies, build output, or local state.
+// Returns the path of srcDir's counterpart inside destDir.
+func CloneWorkspace(ctx context.Context, srcDir, destDir string) (string, error) {
+	srcDir, err := filepath.Abs(srcDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve workspace path: %w", err)
+	}
+
+	out, err := exec.CommandContext(ctx, "git", "-C", srcDir, "rev-parse", "--show-toplevel").Output()
+	if err == nil {
+		top := strings.TrimSpace(string(out))
+		rel, err := filepath.Rel(top, srcDir)
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve workspace path: %w", err)
+		}
+		// #nosec G204 -- git with fixed subcommand; paths come from the local filesystem
+		cmd := exec.CommandContext(ctx, "git", "clone", "--quiet", "--no-hardlinks", top, destDir)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			return "", fmt.Errorf("git clone failed: %w\n%s", err, strings.TrimSpace(string(output)))
+		}
+		return filepath.Join(destDir, rel), nil
+	}
+
+	if err := copyTree(srcDir, destDir); err != nil {
+		return "", err
+	}
+	return destDir, nil
+}
+
+// CacheEnv returns environment variables that point package manager caches at empty
+// directories under cacheDir, so nothing is reused from the developer's machine.
+func CacheEnv(cacheDir string) []string {
+	dir := func(name string) string { return filepath.Join(cacheDir, name) }
+	return []string{
+		"npm_config_cache=" + dir("npm"),
+		"npm_config_store_dir=" + dir("pnpm-store"),
+		"YARN_CACHE_FOLDER=" + dir("yarn"),
+		"PIP_NO_CACHE_DIR=1",
+		"UV_NO_CACHE=1",
+		"POETRY_CACHE_DIR=" + dir("poetry"),
+		"PIPENV_CACHE_DIR=" + dir("pipenv"),
+		"NUGET_PACKAGES=" + dir("nuget"),
+	}
+}
+
+// BuildCommands returns the build commands for the projects in a workspace:
+// the "build" script of Node.js projects and dotnet build for .NET solutions or projects.
+// Python projects have no build step.
+func BuildCommands(workspace string) ([]Command, error) {
+	scan, err := detector.ScanWorkspace(workspace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan projects: %w", err)
+	}
+
+	var commands []Command
+	for _, project := range scan.NodeProjects {
+		if hasNodeScript(project.Dir, "build") {
+			commands = append(commands, Command{Dir: project.Dir, Name: project.PackageManager, Args: []string{"run", "build"}})
+		}
+	}
+
+	// Build solutions when present, since they already include their projects
+	var solutions, projects []string
+	for _, project := range scan.DotnetProjects {
+		if detector.IsDotnetSolution(project.Path) {
+			solutions = append(solutions, project.Path)
+		} else {
+			projects = append(projects, project.Path)
+		}
+	}
+	if len(solutions) == 0 {
+		solutions = projects
+	}
+	for _, path := range solutions {
+		commands = append(commands, Command{Dir: filepath.Dir(path), Name: "dotnet", Args: []string{"build", filepath.Base(path)}})
+	}
+	return commands, nil
+}
+
+// WaitForServices waits until every service is registered as running in the workspace
+// and answers HTTP requests on its URL, if it has one. Returns the registry entries of the services.
+// exited is closed when the process running the services ends early.
+func WaitForServices(ctx context.Context, workspace string, names []string, timeout time.Duration, exited <-chan struct{}) (map[string]*registry.ServiceRegistryEntry, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	client := httpclient.NewLocal(5 * time.Second)
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	pending := ""
+	for {
+		entries, _ := readRegistry(workspace)
+		pending = ""
+		for _, name := range names {
+			entry, ok := entries[name]
+			if !ok || entry.Status != "running" || (entry.URL != "" && !respondsHTTP(ctx, client, entry.URL)) {
+				pending = name
+				break
+			}
+		}
+		if pending == "" {
+			return entries, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("service %s did not become reachable within %s", pending, timeout)
+		case <-exited:
+			return nil, fmt.Errorf("services exited before becoming ready")
+		case <-ticker.C:
+		}
+	}
+}
+
+// respondsHTTP reports whether a URL answers with a non-5xx response.
+func respondsHTTP(ctx context.Context, client *http.Client, url string) bool {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return false
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return false
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+	return resp.StatusCode < 500
+}
+
+// readRegistry reads the service registry file of a workspace without caching it,
+// since it is written by a separate process.
+func readRegistry(workspace string) (map[string]*registry.ServiceRegistryEntry, error) {
+	path, err := registry.Path(workspace)
+	if err != nil {
+		return nil, err
+	}
+	if err := security.ValidatePath(path); err != nil {
+		return nil, err
+	}
+	// #nosec G304 -- Path validated by security.ValidatePath
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	entries := make(map[string]*registry.ServiceRegistryEntry)
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// hasNodeScript reports whether package.json in dir defines a script.
+func hasNodeScript(dir, script string) bool {
+	path := filepath.Join(dir, "package.json")
+	if err := security.ValidatePath(path); err != nil {
+		return false
+	}
+	// #nosec G304 -- Path validated by security.ValidatePath
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	var pkg struct {
+		Scripts map[string]string `json:"scripts"`
+	}
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return false
+	}
+	_, ok := pkg.Scripts[script]
+	return ok
+}
+
+// copyTree copies srcDir into destDir, skipping copySkipDirs.
+func copyTree(srcDir, destDir string) error {
+	return filepath.WalkDir(srcDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(srcDir, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(destDir, rel)
+
+		switch {
+		case d.IsDir():
+			if path != srcDir && copySkipDirs[d.Name()] {
+				return filepath.SkipDir
+			}
+			return os.MkdirAll(target, 0750)
+		case d.Type()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		case d.Type().IsRegular():
+			return copyFile(path, target)
+		default:
+			return nil // Skip sockets, devices, and other special files
+		}
+	})
+}
+
+// copyFile copies a regular file, preserving its permissions.
+func copyFile(src, dest string) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	// #nosec G304 -- Source path comes from walking the workspace being verified
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	// #nosec G304 -- Destination is inside the temporary verification directory
+	out, err := os.OpenFile(dest, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,221 @@
+package verify
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jongio/azd-app/cli/src/internal/registry"
+	"github.com/jongio/azd-app/cli/src/internal/statedir"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("failed to write %s: %v", path, err)
+	}
+}
+
+func TestCloneWorkspaceCopiesWithoutInstalledState(t *testing.T) {
+	src := t.TempDir()
+	writeFile(t, filepath.Join(src, "azure.yaml"), "name: app\n")
+	writeFile(t, filepath.Join(src, "web", "package.json"), "{}")
+	writeFile(t, filepath.Join(src, "web", "node_modules", "lib", "index.js"), "")
+	writeFile(t, filepath.Join(src, "api", ".venv", "pyvenv.cfg"), "")
+	writeFile(t, filepath.Join(src, ".azure", "services.json"), "{}")
+
+	dest := filepath.Join(t.TempDir(), "workspace")
+	workspace, err := CloneWorkspace(context.Background(), src, dest)
+	if err != nil {
+		t.Fatalf("CloneWorkspace failed: %v", err)
+	}
+	if workspace != dest {
+		t.Errorf("workspace = %s, want %s", workspace, dest)
+	}
+
+	for _, path := range []string{"azure.yaml", "web/package.json"} {
+		if _, err := os.Stat(filepath.Join(dest, path)); err != nil {
+			t.Errorf("expected %s to be copied", path)
+		}
+	}
+	for _, path := range []string{"web/node_modules", "api/.venv", ".azure"} {
+		if _, err := os.Stat(filepath.Join(dest, path)); err == nil {
+			t.Errorf("expected %s to be skipped", path)
+		}
+	}
+}
+
+func TestCloneWorkspaceClonesGitRepository(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	repo := t.TempDir()
+	writeFile(t, filepath.Join(repo, "app", "azure.yaml"), "name: app\n")
+	writeFile(t, filepath.Join(repo, "app", "uncommitted.txt"), "")
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-C", repo, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %s failed: %v\n%s", strings.Join(args, " "), err, out)
+		}
+	}
+	git("init", "--quiet")
+	git("add", "app/azure.yaml")
+	git("commit", "--quiet", "-m", "init")
+
+	dest := filepath.Join(t.TempDir(), "workspace")
+	workspace, err := CloneWorkspace(context.Background(), filepath.Join(repo, "app"), dest)
+	if err != nil {
+		t.Fatalf("CloneWorkspace failed: %v", err)
+	}
+	if workspace != filepath.Join(dest, "app") {
+		t.Errorf("workspace = %s, want %s", workspace, filepath.Join(dest, "app"))
+	}
+	if _, err := os.Stat(filepath.Join(workspace, "azure.yaml")); err != nil {
+		t.Error("expected committed file in clone")
+	}
+	if _, err := os.Stat(filepath.Join(workspace, "uncommitted.txt")); err == nil {
+		t.Error("uncommitted file should not be in clone")
+	}
+}
+
+func TestCacheEnv(t *testing.T) {
+	env := CacheEnv("/tmp/cache")
+	want := map[string]string{
+		"npm_config_cache": filepath.Join("/tmp/cache", "npm"),
+		"PIP_NO_CACHE_DIR": "1",
+		"NUGET_PACKAGES":   filepath.Join("/tmp/cache", "nuget"),
+	}
+	got := make(map[string]string)
+	for _, kv := range env {
+		key, value, _ := strings.Cut(kv, "=")
+		got[key] = value
+	}
+	for key, value := range want {
+		if got[key] != value {
+			t.Errorf("%s = %q, want %q", key, got[key], value)
+		}
+	}
+}
+
+func TestBuildCommands(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  []string
+	}{
+		{
+			name: "node build script",
+			files: map[string]string{
+				"web/package.json":      `{"scripts": {"build": "vite build"}}`,
+				"web/package-lock.json": `{}`,
+				"api/package.json":      `{"scripts": {"start": "node index.js"}}`,
+			},
+			want: []string{"npm run build"},
+		},
+		{
+			name: "dotnet projects",
+			files: map[string]string{
+				"api/api.csproj": "<Project />",
+			},
+			want: []string{"dotnet build api.csproj"},
+		},
+		{
+			name: "dotnet solution preferred",
+			files: map[string]string{
+				"app.sln":        "",
+				"api/api.csproj": "<Project />",
+			},
+			want: []string{"dotnet build app.sln"},
+		},
+		{
+			name: "python has no build",
+			files: map[string]string{
+				"api/requirements.txt": "flask\n",
+			},
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for path, content := range tt.files {
+				writeFile(t, filepath.Join(dir, filepath.FromSlash(path)), content)
+			}
+
+			commands, err := BuildCommands(dir)
+			if err != nil {
+				t.Fatalf("BuildCommands failed: %v", err)
+			}
+			var got []string
+			for _, c := range commands {
+				got = append(got, c.String())
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("commands = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWaitForServices(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	t.Setenv(statedir.RootEnvVar, t.TempDir())
+	dir := t.TempDir()
+	registryPath, err := registry.Path(dir)
+	if err != nil {
+		t.Fatalf("registry.Path failed: %v", err)
+	}
+
+	t.Run("ready", func(t *testing.T) {
+		writeFile(t, registryPath, `{"web": {"status": "running", "url": "`+server.URL+`", "framework": "React"}, "worker": {"status": "running"}}`)
+		entries, err := WaitForServices(context.Background(), dir, []string{"web", "worker"}, 5*time.Second, nil)
+		if err != nil {
+			t.Fatalf("WaitForServices failed: %v", err)
+		}
+		if entries["web"].Framework != "React" {
+			t.Errorf("unexpected entry: %+v", entries["web"])
+		}
+	})
+
+	t.Run("exited", func(t *testing.T) {
+		writeFile(t, registryPath, `{"web": {"status": "starting"}}`)
+		exited := make(chan struct{})
+		close(exited)
+		if _, err := WaitForServices(context.Background(), dir, []string{"web"}, 5*time.Second, exited); err == nil {
+			t.Error("expected error when services exit")
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		writeFile(t, registryPath, `{"web": {"status": "starting"}}`)
+		if _, err := WaitForServices(context.Background(), dir, []string{"web"}, 100*time.Millisecond, nil); err == nil {
+			t.Error("expected timeout error")
+		}
+	})
+}
+
+func TestReportPassed(t *testing.T) {
+	report := &Report{Steps: []Step{{Status: StatusPassed}, {Status: StatusSkipped}}}
+	if !report.Passed() {
+		t.Error("expected report to pass")
+	}
+	report.Steps = append(report.Steps, Step{Status: StatusFailed})
+	if report.Passed() {
+		t.Error("expected report to fail")
+	}
+}
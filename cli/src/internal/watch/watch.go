@@ -0,0 +1,145 @@
+package watch
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jongio/azd-app/cli/src/internal/detector"
+)
+
+// DefaultInterval is the default time between scans of the watched directories.
+const DefaultInterval = 500 * time.Millisecond
+
+// skipDirs lists build output directories that change whenever a service builds itself,
+// in addition to the directories detectors never visit.
+var skipDirs = map[string]bool{
+	"target":  true, // maven
+	"build":   true, // gradle
+	".gradle": true,
+	"dist":    true,
+}
+
+// fileState is what a scan records about a file to notice that it changed.
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// Snapshot records the state of every watched file in a directory tree.
+type Snapshot map[string]fileState
+
+// Scan records the files below dir, honoring the same ignore rules as the detectors.
+func Scan(dir string) (Snapshot, error) {
+	snapshot := make(Snapshot)
+	err := detector.WalkFiles(dir, func(path string) {
+		if inSkipDir(dir, path) {
+			return
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return
+		}
+		snapshot[path] = fileState{modTime: info.ModTime(), size: info.Size()}
+	})
+	return snapshot, err
+}
+
+// inSkipDir reports whether path is below one of the skipped build output directories of root.
+func inSkipDir(root, path string) bool {
+	rel, err := filepath.Rel(root, filepath.Dir(path))
+	if err != nil || rel == "." {
+		return false
+	}
+	for _, part := range strings.Split(filepath.ToSlash(rel), "/") {
+		if skipDirs[part] {
+			return true
+		}
+	}
+	return false
+}
+
+// Changed returns the files that were added, modified, or removed between two snapshots, sorted.
+func Changed(before, after Snapshot) []string {
+	var changed []string
+	for path, state := range after {
+		if old, ok := before[path]; !ok || old != state {
+			changed = append(changed, path)
+		}
+	}
+	for path := range before {
+		if _, ok := after[path]; !ok {
+			changed = append(changed, path)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// Run polls dirs, keyed by name, until ctx is done. Changes to a directory are collected
+// until a scan finds no further changes, so that saving several files restarts a service
+// once, and are then passed to onChange. onChange runs on the polling goroutine, so no
+// directory is scanned while it runs.
+func Run(ctx context.Context, dirs map[string]string, interval time.Duration, onChange func(name string, files []string)) {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+
+	snapshots := make(map[string]Snapshot, len(dirs))
+	for name, dir := range dirs {
+		snapshots[name], _ = Scan(dir)
+	}
+	pending := make(map[string][]string)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		names := make([]string, 0, len(dirs))
+		for name := range dirs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			snapshot, err := Scan(dirs[name])
+			if err != nil {
+				continue
+			}
+			changed := Changed(snapshots[name], snapshot)
+			snapshots[name] = snapshot
+
+			switch {
+			case len(changed) > 0:
+				pending[name] = append(pending[name], changed...)
+			case len(pending[name]) > 0:
+				files := dedupe(pending[name])
+				delete(pending, name)
+				if ctx.Err() != nil {
+					return
+				}
+				onChange(name, files)
+			}
+		}
+	}
+}
+
+// dedupe returns the distinct entries of files, sorted.
+func dedupe(files []string) []string {
+	sort.Strings(files)
+	out := files[:0]
+	for i, file := range files {
+		if i == 0 || file != files[i-1] {
+			out = append(out, file)
+		}
+	}
+	return out
+}
@@ -0,0 +1,117 @@
+package watch
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("failed to write %s: %v", path, err)
+	}
+}
+
+func TestScan(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "main.go"), "package main")
+	writeFile(t, filepath.Join(dir, "internal", "app.go"), "package internal")
+	writeFile(t, filepath.Join(dir, "target", "classes", "App.class"), "")
+	writeFile(t, filepath.Join(dir, "build", "libs", "app.jar"), "")
+	writeFile(t, filepath.Join(dir, "bin", "app"), "")
+	writeFile(t, filepath.Join(dir, "logs", "app.log"), "")
+	writeFile(t, filepath.Join(dir, ".gitignore"), "logs/\n")
+
+	snapshot, err := Scan(dir)
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+
+	var got []string
+	for path := range snapshot {
+		rel, _ := filepath.Rel(dir, path)
+		got = append(got, filepath.ToSlash(rel))
+	}
+	want := []string{".gitignore", "internal/app.go", "main.go"}
+	if !reflect.DeepEqual(dedupe(got), want) {
+		t.Errorf("Scan() files = %v, want %v", got, want)
+	}
+}
+
+func TestChanged(t *testing.T) {
+	now := time.Now()
+	before := Snapshot{
+		"same":     {modTime: now, size: 1},
+		"modified": {modTime: now, size: 1},
+		"resized":  {modTime: now, size: 1},
+		"removed":  {modTime: now, size: 1},
+	}
+	after := Snapshot{
+		"same":     {modTime: now, size: 1},
+		"modified": {modTime: now.Add(time.Second), size: 1},
+		"resized":  {modTime: now, size: 2},
+		"added":    {modTime: now, size: 1},
+	}
+
+	want := []string{"added", "modified", "removed", "resized"}
+	if got := Changed(before, after); !reflect.DeepEqual(got, want) {
+		t.Errorf("Changed() = %v, want %v", got, want)
+	}
+	if got := Changed(after, after); len(got) != 0 {
+		t.Errorf("Changed() of identical snapshots = %v, want none", got)
+	}
+}
+
+func TestRun(t *testing.T) {
+	api := t.TempDir()
+	worker := t.TempDir()
+	writeFile(t, filepath.Join(api, "main.py"), "print('v1')")
+	writeFile(t, filepath.Join(worker, "main.go"), "package main")
+
+	var mu sync.Mutex
+	changes := make(map[string][]string)
+	notified := make(chan struct{}, 10)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		Run(ctx, map[string]string{"api": api, "worker": worker}, 20*time.Millisecond, func(name string, files []string) {
+			mu.Lock()
+			changes[name] = append(changes[name], files...)
+			mu.Unlock()
+			notified <- struct{}{}
+		})
+	}()
+
+	// Let the first scan record the initial state
+	time.Sleep(50 * time.Millisecond)
+	writeFile(t, filepath.Join(api, "main.py"), "print('version 2')")
+	writeFile(t, filepath.Join(api, "util.py"), "")
+
+	select {
+	case <-notified:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run() did not report the change")
+	}
+	cancel()
+	<-done
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := []string{filepath.Join(api, "main.py"), filepath.Join(api, "util.py")}
+	if !reflect.DeepEqual(changes["api"], want) {
+		t.Errorf("changes for api = %v, want %v", changes["api"], want)
+	}
+	if len(changes["worker"]) != 0 {
+		t.Errorf("changes for worker = %v, want none", changes["worker"])
+	}
+}
@@ -0,0 +1,194 @@
+// Package workerpool runs batches of independent tasks, such as installs, smoke checks, or
+// service starts, on a bounded number of goroutines.
+//
+// Every task runs unless the context is canceled, or StopOnError is set and a task failed.
+// Errors are collected per task and returned together, in task order.
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// Task is a named unit of work.
+type Task struct {
+	Name string
+	Run  func(ctx context.Context) error
+}
+
+// Event reports the progress of a batch. Started events are sent when a task begins; finished
+// events, with Started false, when it ends.
+type Event struct {
+	Name    string
+	Started bool
+	Err     error // Error of a finished task
+	Done    int   // Tasks finished so far, including this one
+	Total   int
+}
+
+// Options configures a batch.
+type Options struct {
+	Workers     int         // Tasks run at once; 0 uses the number of CPUs, capped at the number of tasks
+	StopOnError bool        // Cancel the tasks that are running and skip the rest after the first error
+	Progress    func(Event) // Optional: called for every event, from one goroutine at a time
+}
+
+// TaskError is the error of one task.
+type TaskError struct {
+	Name string
+	Err  error
+}
+
+func (e *TaskError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Name, e.Err)
+}
+
+func (e *TaskError) Unwrap() error {
+	return e.Err
+}
+
+// Error is the error of a batch in which tasks failed or were skipped.
+type Error struct {
+	Total     int          // Tasks in the batch
+	Succeeded int          // Tasks that ran without error
+	Failed    []*TaskError // Failed tasks, in task order
+	Skipped   []string     // Names of the tasks that did not run, in task order
+	Cause     error        // Why tasks were skipped, such as context.Canceled
+}
+
+func (e *Error) Error() string {
+	msgs := make([]string, 0, len(e.Failed)+1)
+	for _, taskErr := range e.Failed {
+		msgs = append(msgs, taskErr.Error())
+	}
+	if len(e.Skipped) > 0 {
+		msgs = append(msgs, fmt.Sprintf("%d skipped: %v", len(e.Skipped), e.Cause))
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Unwrap returns the errors of the failed tasks, followed by the cause of skipped tasks.
+func (e *Error) Unwrap() []error {
+	errs := make([]error, 0, len(e.Failed)+1)
+	for _, taskErr := range e.Failed {
+		errs = append(errs, taskErr)
+	}
+	if e.Cause != nil {
+		errs = append(errs, e.Cause)
+	}
+	return errs
+}
+
+// Partial reports whether some tasks of the batch succeeded.
+func (e *Error) Partial() bool {
+	return e.Succeeded > 0
+}
+
+// Run runs tasks and waits for them to finish. It returns nil when every task succeeded, and
+// otherwise an *Error with the error of each failed task and the tasks skipped because ctx was
+// canceled or, with StopOnError, an earlier task failed.
+func Run(ctx context.Context, tasks []Task, opts Options) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	workers = min(workers, len(tasks))
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	errs := make([]*TaskError, len(tasks))
+	ran := make([]bool, len(tasks))
+	var (
+		mu   sync.Mutex
+		done int
+	)
+	progress := func(e Event) {
+		if opts.Progress != nil {
+			opts.Progress(e)
+		}
+	}
+
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				task := tasks[i]
+				mu.Lock()
+				if ctx.Err() != nil {
+					mu.Unlock()
+					continue
+				}
+				ran[i] = true
+				progress(Event{Name: task.Name, Started: true, Done: done, Total: len(tasks)})
+				mu.Unlock()
+
+				err := task.Run(ctx)
+
+				mu.Lock()
+				done++
+				if err != nil {
+					errs[i] = &TaskError{Name: task.Name, Err: err}
+					if opts.StopOnError {
+						cancel()
+					}
+				}
+				progress(Event{Name: task.Name, Err: err, Done: done, Total: len(tasks)})
+				mu.Unlock()
+			}
+		}()
+	}
+
+dispatch:
+	for i := range tasks {
+		select {
+		case next <- i:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(next)
+	wg.Wait()
+
+	batchErr := &Error{Total: len(tasks)}
+	for i, task := range tasks {
+		switch {
+		case !ran[i]:
+			batchErr.Skipped = append(batchErr.Skipped, task.Name)
+		case errs[i] != nil:
+			batchErr.Failed = append(batchErr.Failed, errs[i])
+		default:
+			batchErr.Succeeded++
+		}
+	}
+	if len(batchErr.Skipped) > 0 {
+		batchErr.Cause = context.Cause(ctx)
+	}
+	if batchErr.Succeeded == len(tasks) {
+		return nil
+	}
+	return batchErr
+}
+
+// Errors returns the task errors in err, as returned by Run.
+func Errors(err error) []*TaskError {
+	var batchErr *Error
+	if errors.As(err, &batchErr) {
+		return batchErr.Failed
+	}
+	var taskErr *TaskError
+	if errors.As(err, &taskErr) {
+		return []*TaskError{taskErr}
+	}
+	return nil
+}
@@ -0,0 +1,208 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// sleepTasks returns n tasks that sleep briefly, tracking how many run at once in peak.
+func sleepTasks(n int, running, peak *atomic.Int32) []Task {
+	tasks := make([]Task, n)
+	for i := range tasks {
+		tasks[i] = Task{Name: fmt.Sprintf("task-%d", i), Run: func(ctx context.Context) error {
+			current := running.Add(1)
+			defer running.Add(-1)
+			for {
+				old := peak.Load()
+				if current <= old || peak.CompareAndSwap(old, current) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			return nil
+		}}
+	}
+	return tasks
+}
+
+func TestRunBoundsWorkers(t *testing.T) {
+	tests := []struct {
+		name     string
+		tasks    int
+		workers  int
+		wantPeak int32
+	}{
+		{name: "one worker", tasks: 5, workers: 1, wantPeak: 1},
+		{name: "bounded", tasks: 8, workers: 3, wantPeak: 3},
+		{name: "more workers than tasks", tasks: 2, workers: 10, wantPeak: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var running, peak atomic.Int32
+			if err := Run(context.Background(), sleepTasks(tt.tasks, &running, &peak), Options{Workers: tt.workers}); err != nil {
+				t.Fatalf("Run failed: %v", err)
+			}
+			if peak.Load() != tt.wantPeak {
+				t.Errorf("peak concurrency = %d, want %d", peak.Load(), tt.wantPeak)
+			}
+		})
+	}
+}
+
+func TestRunAggregatesErrors(t *testing.T) {
+	errBoom := errors.New("boom")
+	var ran atomic.Int32
+	tasks := []Task{
+		{Name: "a", Run: func(ctx context.Context) error { ran.Add(1); return nil }},
+		{Name: "b", Run: func(ctx context.Context) error { ran.Add(1); return errBoom }},
+		{Name: "c", Run: func(ctx context.Context) error { ran.Add(1); return nil }},
+		{Name: "d", Run: func(ctx context.Context) error { ran.Add(1); return errors.New("bust") }},
+	}
+
+	err := Run(context.Background(), tasks, Options{Workers: 2})
+	if ran.Load() != 4 {
+		t.Errorf("%d tasks ran, want all 4", ran.Load())
+	}
+	if !errors.Is(err, errBoom) {
+		t.Errorf("Run() = %v, want it to wrap the task error", err)
+	}
+	taskErrs := Errors(err)
+	if len(taskErrs) != 2 || taskErrs[0].Name != "b" || taskErrs[1].Name != "d" {
+		t.Errorf("Errors() = %v, want errors of b and d in task order", taskErrs)
+	}
+}
+
+func TestRunStopOnError(t *testing.T) {
+	var ran atomic.Int32
+	started, canceled := make(chan struct{}), make(chan struct{})
+	tasks := []Task{
+		{Name: "fails", Run: func(ctx context.Context) error {
+			ran.Add(1)
+			<-started
+			return errors.New("boom")
+		}},
+		{Name: "slow", Run: func(ctx context.Context) error {
+			ran.Add(1)
+			close(started)
+			select {
+			case <-ctx.Done():
+				close(canceled)
+				return ctx.Err()
+			case <-time.After(5 * time.Second):
+				return nil
+			}
+		}},
+	}
+	for i := 0; i < 5; i++ {
+		tasks = append(tasks, Task{Name: fmt.Sprintf("later-%d", i), Run: func(ctx context.Context) error { ran.Add(1); return nil }})
+	}
+
+	err := Run(context.Background(), tasks, Options{Workers: 2, StopOnError: true})
+	select {
+	case <-canceled:
+	default:
+		t.Error("running task should be canceled after another task fails")
+	}
+	if ran.Load() != 2 {
+		t.Errorf("%d tasks ran, want only the first 2", ran.Load())
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Run() = %v, want context.Canceled for the skipped tasks", err)
+	}
+	var batchErr *Error
+	if !errors.As(err, &batchErr) || len(batchErr.Skipped) != 5 || batchErr.Skipped[0] != "later-0" {
+		t.Errorf("Run() = %#v, want the 5 later tasks skipped", err)
+	}
+}
+
+func TestErrorPartial(t *testing.T) {
+	ok := func(ctx context.Context) error { return nil }
+	fail := func(ctx context.Context) error { return errors.New("boom") }
+	tests := []struct {
+		name        string
+		runs        []func(ctx context.Context) error
+		wantPartial bool
+	}{
+		{name: "some failed", runs: []func(ctx context.Context) error{ok, fail, ok}, wantPartial: true},
+		{name: "all failed", runs: []func(ctx context.Context) error{fail, fail}, wantPartial: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tasks := make([]Task, len(tt.runs))
+			for i, run := range tt.runs {
+				tasks[i] = Task{Name: fmt.Sprintf("task-%d", i), Run: run}
+			}
+			var batchErr *Error
+			if err := Run(context.Background(), tasks, Options{}); !errors.As(err, &batchErr) {
+				t.Fatalf("Run() = %v, want an *Error", err)
+			}
+			if batchErr.Partial() != tt.wantPartial || batchErr.Total != len(tasks) {
+				t.Errorf("Partial() = %v with total %d, want %v with total %d", batchErr.Partial(), batchErr.Total, tt.wantPartial, len(tasks))
+			}
+		})
+	}
+}
+
+func TestRunCanceledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var ran atomic.Int32
+	tasks := []Task{{Name: "a", Run: func(ctx context.Context) error { ran.Add(1); return nil }}}
+	if err := Run(ctx, tasks, Options{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("Run() = %v, want context.Canceled", err)
+	}
+	if ran.Load() != 0 {
+		t.Error("no task should run with a canceled context")
+	}
+}
+
+func TestRunProgress(t *testing.T) {
+	tasks := []Task{
+		{Name: "a", Run: func(ctx context.Context) error { return nil }},
+		{Name: "b", Run: func(ctx context.Context) error { return errors.New("boom") }},
+		{Name: "c", Run: func(ctx context.Context) error { return nil }},
+	}
+
+	var started, finished, failed int
+	lastDone := 0
+	err := Run(context.Background(), tasks, Options{Workers: 3, Progress: func(e Event) {
+		if e.Total != 3 {
+			t.Errorf("event total = %d, want 3", e.Total)
+		}
+		if e.Started {
+			started++
+			return
+		}
+		finished++
+		if e.Err != nil {
+			failed++
+		}
+		if e.Done != lastDone+1 {
+			t.Errorf("done = %d after %d, want it to count up by one", e.Done, lastDone)
+		}
+		lastDone = e.Done
+	}})
+
+	if err == nil {
+		t.Error("Run should return the failed task's error")
+	}
+	if started != 3 || finished != 3 || failed != 1 {
+		t.Errorf("started %d, finished %d, failed %d; want 3, 3, 1", started, finished, failed)
+	}
+}
+
+func TestRunNoTasks(t *testing.T) {
+	if err := Run(context.Background(), nil, Options{}); err != nil {
+		t.Errorf("Run(nil) = %v, want nil", err)
+	}
+	if taskErrs := Errors(nil); taskErrs != nil {
+		t.Errorf("Errors(nil) = %v, want nil", taskErrs)
+	}
+}
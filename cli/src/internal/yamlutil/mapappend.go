@@ -0,0 +1,115 @@
+package yamlutil
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// MapEntry is a key and value to add to a YAML mapping section.
+type MapEntry struct {
+	Key   string
+	Value interface{} // Marshaled with yaml.v3, so struct yaml tags apply
+}
+
+// AppendToMapSection adds entries to a top-level mapping section, such as the services of
+// azure.yaml, while preserving all comments, formatting, and other content in the file.
+// Entries whose key already exists in the section are skipped, and the section is created at
+// the end of the file if it is missing. Returns the new content and the number of entries added.
+func AppendToMapSection(content, sectionKey string, entries []MapEntry) (string, int, error) {
+	var root map[string]interface{}
+	if err := yaml.Unmarshal([]byte(content), &root); err != nil {
+		return "", 0, fmt.Errorf("failed to parse existing content: %w", err)
+	}
+	existing, _ := root[sectionKey].(map[string]interface{})
+
+	var toAdd []MapEntry
+	for _, entry := range entries {
+		if _, exists := existing[entry.Key]; !exists {
+			toAdd = append(toAdd, entry)
+		}
+	}
+	if len(toAdd) == 0 {
+		return content, 0, nil
+	}
+
+	lines := strings.Split(content, "\n")
+	headerIdx := -1
+	for i, line := range lines {
+		if strings.HasPrefix(line, sectionKey+":") {
+			headerIdx = i
+			break
+		}
+	}
+
+	if headerIdx < 0 {
+		text, err := buildMapEntriesYaml(toAdd, "  ")
+		if err != nil {
+			return "", 0, err
+		}
+		var builder strings.Builder
+		builder.WriteString(content)
+		if !strings.HasSuffix(content, "\n") {
+			builder.WriteString("\n")
+		}
+		if strings.TrimSpace(content) != "" {
+			builder.WriteString("\n")
+		}
+		builder.WriteString(sectionKey + ":\n")
+		builder.WriteString(text)
+		return builder.String(), len(toAdd), nil
+	}
+
+	header := strings.TrimSpace(strings.TrimPrefix(lines[headerIdx], sectionKey+":"))
+	if header != "" && !strings.HasPrefix(header, "#") {
+		return "", 0, fmt.Errorf("section %q is not a block mapping", sectionKey)
+	}
+
+	// The section ends at the next line that is not indented
+	lastIdx, entryIndent := headerIdx, ""
+	for i := headerIdx + 1; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		indent := getIndentation(lines[i])
+		if indent == "" {
+			break
+		}
+		if entryIndent == "" {
+			entryIndent = indent
+		}
+		lastIdx = i
+	}
+	if entryIndent == "" {
+		entryIndent = "  "
+	}
+
+	text, err := buildMapEntriesYaml(toAdd, entryIndent)
+	if err != nil {
+		return "", 0, err
+	}
+	return insertLines(lines, lastIdx, text), len(toAdd), nil
+}
+
+// buildMapEntriesYaml renders entries as YAML text indented by indent.
+func buildMapEntriesYaml(entries []MapEntry, indent string) (string, error) {
+	var builder strings.Builder
+	for _, entry := range entries {
+		var buf bytes.Buffer
+		encoder := yaml.NewEncoder(&buf)
+		encoder.SetIndent(len(indent))
+		if err := encoder.Encode(map[string]interface{}{entry.Key: entry.Value}); err != nil {
+			return "", fmt.Errorf("failed to render %s: %w", entry.Key, err)
+		}
+		if err := encoder.Close(); err != nil {
+			return "", fmt.Errorf("failed to render %s: %w", entry.Key, err)
+		}
+		for _, line := range strings.Split(strings.TrimRight(buf.String(), "\n"), "\n") {
+			builder.WriteString(indent + line + "\n")
+		}
+	}
+	return builder.String(), nil
+}
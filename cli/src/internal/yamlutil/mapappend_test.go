@@ -0,0 +1,106 @@
+package yamlutil
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAppendToMapSection(t *testing.T) {
+	type service struct {
+		Host    string `yaml:"host"`
+		Project string `yaml:"project,omitempty"`
+	}
+	entries := []MapEntry{
+		{Key: "api", Value: service{Host: "containerapp", Project: "./api"}},
+		{Key: "web", Value: service{Host: "containerapp", Project: "./web"}},
+	}
+
+	tests := []struct {
+		name      string
+		content   string
+		want      string
+		wantAdded int
+		wantErr   bool
+	}{
+		{
+			name: "appends to existing section and keeps comments",
+			content: `name: app
+# Services
+services:
+    api:
+        host: containerapp # existing
+resources:
+    db:
+        type: db.postgres
+`,
+			want: `name: app
+# Services
+services:
+    api:
+        host: containerapp # existing
+    web:
+        host: containerapp
+        project: ./web
+resources:
+    db:
+        type: db.postgres
+`,
+			wantAdded: 1,
+		},
+		{
+			name:    "creates missing section",
+			content: "name: app\n",
+			want: `name: app
+
+services:
+  api:
+    host: containerapp
+    project: ./api
+  web:
+    host: containerapp
+    project: ./web
+`,
+			wantAdded: 2,
+		},
+		{
+			name: "all keys exist",
+			content: `services:
+  api: {}
+  web: {}
+`,
+			want: `services:
+  api: {}
+  web: {}
+`,
+		},
+		{
+			name:    "flow mapping is rejected",
+			content: "services: {other: {}}\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, added, err := AppendToMapSection(tt.content, "services", entries)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("AppendToMapSection failed: %v", err)
+			}
+			if added != tt.wantAdded {
+				t.Errorf("added = %d, want %d", added, tt.wantAdded)
+			}
+			if got != tt.want {
+				t.Errorf("content mismatch\ngot:\n%s\nwant:\n%s", got, tt.want)
+			}
+			if strings.Count(got, "api:") != 1 {
+				t.Errorf("api should appear once:\n%s", got)
+			}
+		})
+	}
+}